@@ -0,0 +1,448 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// AnnotationFieldType is the type an annotation value is coerced to when it is moved to a spec field
+type AnnotationFieldType string
+
+const (
+	// AnnotationFieldTypeString copies the annotation value to a string field as-is
+	AnnotationFieldTypeString AnnotationFieldType = "string"
+	// AnnotationFieldTypeInt parses the annotation value as a base-10 integer
+	AnnotationFieldTypeInt AnnotationFieldType = "int"
+	// AnnotationFieldTypeFloat parses the annotation value as a floating-point number
+	AnnotationFieldTypeFloat AnnotationFieldType = "float"
+	// AnnotationFieldTypeBool parses the annotation value with strconv.ParseBool
+	AnnotationFieldTypeBool AnnotationFieldType = "bool"
+	// AnnotationFieldTypeJSON parses the annotation value as JSON, for objects, lists, or other structured fields
+	AnnotationFieldTypeJSON AnnotationFieldType = "json"
+)
+
+// AnnotationFieldMapping maps an annotation to a field of the spec of an object
+type AnnotationFieldMapping struct {
+	// Annotation is the annotation key, such as "grafana.app/title"
+	Annotation string
+	// Field is the dot-separated path of the field in the spec, such as "display.title" for spec.display.title.
+	// Intermediate objects are created as required when the field is set.
+	Field string
+	// Type is the type the annotation value is coerced to in the field. Defaults to AnnotationFieldTypeString.
+	Type AnnotationFieldType
+	// KeepAnnotation keeps the annotation on the object when it is moved to the field,
+	// and keeps the field in the spec when it is moved back to the annotation in place with MoveToAnnotations,
+	// which can be used while clients still rely on the annotation
+	KeepAnnotation bool
+}
+
+// ParseAnnotationFieldMapping parses an AnnotationFieldMapping from a string of the form "<annotation>=<field>[:<type>]",
+// such as "grafana.app/priority=scheduling.priority:int"
+func ParseAnnotationFieldMapping(mapping string) (AnnotationFieldMapping, error) {
+	annotation, field, ok := strings.Cut(mapping, "=")
+	if !ok {
+		return AnnotationFieldMapping{}, fmt.Errorf("invalid mapping '%s', must be of the form <annotation>=<field>[:<type>]", mapping)
+	}
+	m := AnnotationFieldMapping{
+		Annotation: annotation,
+		Field:      field,
+	}
+	if field, typ, ok := strings.Cut(field, ":"); ok {
+		m.Field = field
+		m.Type = AnnotationFieldType(typ)
+	}
+	return m, nil
+}
+
+// AnnotationFieldMapper moves values between annotations and spec fields, using a set of AnnotationFieldMappings.
+// It is intended for kinds which move data stored in annotations into proper spec fields in a new version:
+// Conversions between the versions can use WrapToFields and WrapToAnnotations (or CopyToFields and CopyToAnnotations)
+// instead of handling each field separately, and objects which are already stored can be migrated in place with MoveToFields,
+// such as with the `grafana-app-sdk migrate annotations` command.
+// It must be created with NewAnnotationFieldMapper to be valid.
+type AnnotationFieldMapper struct {
+	mappings []AnnotationFieldMapping
+}
+
+// NewAnnotationFieldMapper creates a new AnnotationFieldMapper for the mappings.
+// It returns an error if the annotation key, field path, or type of a mapping is invalid,
+// or if an annotation or field is used by more than one mapping.
+func NewAnnotationFieldMapper(mappings ...AnnotationFieldMapping) (*AnnotationFieldMapper, error) {
+	if len(mappings) == 0 {
+		return nil, errors.New("at least one mapping must be provided")
+	}
+	m := &AnnotationFieldMapper{
+		mappings: make([]AnnotationFieldMapping, 0, len(mappings)),
+	}
+	for _, mapping := range mappings {
+		if errs := validation.IsQualifiedName(mapping.Annotation); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid annotation '%s': %s", mapping.Annotation, strings.Join(errs, ", "))
+		}
+		if mapping.Field == "" || slices.Contains(strings.Split(mapping.Field, "."), "") {
+			return nil, fmt.Errorf("invalid field path '%s' for annotation '%s'", mapping.Field, mapping.Annotation)
+		}
+		switch mapping.Type {
+		case "":
+			mapping.Type = AnnotationFieldTypeString
+		case AnnotationFieldTypeString, AnnotationFieldTypeInt, AnnotationFieldTypeFloat, AnnotationFieldTypeBool, AnnotationFieldTypeJSON:
+		default:
+			return nil, fmt.Errorf("invalid type '%s' for annotation '%s'", mapping.Type, mapping.Annotation)
+		}
+		for _, existing := range m.mappings {
+			if existing.Annotation == mapping.Annotation {
+				return nil, fmt.Errorf("annotation '%s' is mapped more than once", mapping.Annotation)
+			}
+			if isFieldPathPrefix(existing.Field, mapping.Field) || isFieldPathPrefix(mapping.Field, existing.Field) {
+				return nil, fmt.Errorf("field '%s' of annotation '%s' overlaps field '%s' of annotation '%s'",
+					mapping.Field, mapping.Annotation, existing.Field, existing.Annotation)
+			}
+		}
+		m.mappings = append(m.mappings, mapping)
+	}
+	return m, nil
+}
+
+// MoveToFields moves the mapped annotations of obj into their spec fields, in place.
+// A field which is already set is not overwritten, but its annotation is still removed
+// (unless KeepAnnotation is set), so moving is idempotent. In an untyped spec (such as that of a resource.UntypedObject),
+// any value other than null is set, including false and 0, while in a typed spec, a field with its zero value is treated
+// as unset, as it cannot be told apart from a field which was never set. It returns whether obj was changed, and an error if an annotation
+// value cannot be coerced to the type of its mapping, or a field path goes through a value which is not an object.
+func (m *AnnotationFieldMapper) MoveToFields(obj resource.Object) (bool, error) {
+	return m.copyToFields(obj, obj)
+}
+
+// MoveToAnnotations moves the mapped spec fields of obj back into their annotations, in place, reversing MoveToFields.
+// Fields are removed from the spec unless KeepAnnotation is set, and unset fields leave their annotations as they are.
+// It returns whether obj was changed.
+func (m *AnnotationFieldMapper) MoveToAnnotations(obj resource.Object) (bool, error) {
+	return m.copyToAnnotations(obj, obj, true)
+}
+
+// CopyToFields sets the spec fields of dst from the mapped annotations of src, and removes the mapped annotations
+// from dst (unless KeepAnnotation is set). It is intended for use in a Conversion to a version with the fields,
+// after the rest of dst has been converted, as the metadata of src will already have been copied to dst.
+// Fields already set in dst are not overwritten, with the same rules for zero values as MoveToFields.
+func (m *AnnotationFieldMapper) CopyToFields(src, dst resource.Object) error {
+	_, err := m.copyToFields(src, dst)
+	return err
+}
+
+// CopyToAnnotations sets the mapped annotations of dst from the spec fields of src.
+// It is intended for use in a Conversion to a version without the fields, reversing CopyToFields.
+func (m *AnnotationFieldMapper) CopyToAnnotations(src, dst resource.Object) error {
+	_, err := m.copyToAnnotations(src, dst, false)
+	return err
+}
+
+// WrapToFields returns a Conversion which calls conversion (if it is not nil), and then CopyToFields
+func (m *AnnotationFieldMapper) WrapToFields(conversion Conversion) Conversion {
+	return func(ctx context.Context, src resource.Object, dst resource.Object) error {
+		if conversion != nil {
+			if err := conversion(ctx, src, dst); err != nil {
+				return err
+			}
+		}
+		return m.CopyToFields(src, dst)
+	}
+}
+
+// WrapToAnnotations returns a Conversion which calls conversion (if it is not nil), and then CopyToAnnotations
+func (m *AnnotationFieldMapper) WrapToAnnotations(conversion Conversion) Conversion {
+	return func(ctx context.Context, src resource.Object, dst resource.Object) error {
+		if conversion != nil {
+			if err := conversion(ctx, src, dst); err != nil {
+				return err
+			}
+		}
+		return m.CopyToAnnotations(src, dst)
+	}
+}
+
+func (m *AnnotationFieldMapper) copyToFields(src, dst resource.Object) (bool, error) {
+	srcAnnotations := src.GetAnnotations()
+	dstAnnotations := dst.GetAnnotations()
+	spec, err := specToMap(dst)
+	if err != nil {
+		return false, err
+	}
+	_, untyped := dst.GetSpec().(map[string]any)
+	specChanged := false
+	annotationsChanged := false
+	for _, mapping := range m.mappings {
+		value, ok := srcAnnotations[mapping.Annotation]
+		if !ok {
+			continue
+		}
+		path := strings.Split(mapping.Field, ".")
+		existing, set, err := getFieldPath(spec, path)
+		if err != nil {
+			return false, fmt.Errorf("unable to set field '%s' from annotation '%s': %w", mapping.Field, mapping.Annotation, err)
+		}
+		// Typed specs encode unset fields as their zero values, so zero values can only be treated as set in untyped specs
+		if !untyped && resource.IsZeroJSONValue(existing) {
+			set = false
+		}
+		if !set {
+			fieldValue, err := mapping.annotationToField(value)
+			if err != nil {
+				return false, err
+			}
+			// Setting a zero value over an existing zero value would not change the field
+			if existing == nil || !resource.IsZeroJSONValue(fieldValue) {
+				if err = setFieldPath(spec, path, fieldValue); err != nil {
+					return false, fmt.Errorf("unable to set field '%s' from annotation '%s': %w", mapping.Field, mapping.Annotation, err)
+				}
+				specChanged = true
+			}
+		}
+		if _, ok := dstAnnotations[mapping.Annotation]; ok && !mapping.KeepAnnotation {
+			delete(dstAnnotations, mapping.Annotation)
+			annotationsChanged = true
+		}
+	}
+	if specChanged {
+		if err = setSpecFromMap(dst, spec); err != nil {
+			return false, err
+		}
+	}
+	if annotationsChanged {
+		dst.SetAnnotations(dstAnnotations)
+	}
+	return specChanged || annotationsChanged, nil
+}
+
+func (m *AnnotationFieldMapper) copyToAnnotations(src, dst resource.Object, removeFields bool) (bool, error) {
+	spec, err := specToMap(src)
+	if err != nil {
+		return false, err
+	}
+	annotations := dst.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	specChanged := false
+	annotationsChanged := false
+	for _, mapping := range m.mappings {
+		path := strings.Split(mapping.Field, ".")
+		fieldValue, ok, err := getFieldPath(spec, path)
+		if err != nil {
+			return false, fmt.Errorf("unable to get field '%s' for annotation '%s': %w", mapping.Field, mapping.Annotation, err)
+		}
+		if !ok {
+			continue
+		}
+		value, err := mapping.fieldToAnnotation(fieldValue)
+		if err != nil {
+			return false, err
+		}
+		if existing, ok := annotations[mapping.Annotation]; !ok || existing != value {
+			annotations[mapping.Annotation] = value
+			annotationsChanged = true
+		}
+		if removeFields && !mapping.KeepAnnotation {
+			deleteFieldPath(spec, path)
+			specChanged = true
+		}
+	}
+	if annotationsChanged {
+		dst.SetAnnotations(annotations)
+	}
+	if specChanged {
+		if err = setSpecFromMap(dst, spec); err != nil {
+			return false, err
+		}
+	}
+	return specChanged || annotationsChanged, nil
+}
+
+func (mapping AnnotationFieldMapping) annotationToField(value string) (any, error) {
+	var (
+		fieldValue any
+		err        error
+	)
+	switch mapping.Type {
+	case AnnotationFieldTypeInt:
+		var i int64
+		i, err = strconv.ParseInt(value, 10, 64)
+		fieldValue = json.Number(strconv.FormatInt(i, 10))
+	case AnnotationFieldTypeFloat:
+		var f float64
+		f, err = strconv.ParseFloat(value, 64)
+		fieldValue = json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+	case AnnotationFieldTypeBool:
+		fieldValue, err = strconv.ParseBool(value)
+	case AnnotationFieldTypeJSON:
+		fieldValue, err = decodeJSONValue([]byte(value))
+	default:
+		fieldValue = value
+	}
+	if err != nil {
+		return nil, fmt.Errorf("annotation '%s' value '%s' is not a valid %s: %w", mapping.Annotation, value, mapping.Type, err)
+	}
+	return fieldValue, nil
+}
+
+func (mapping AnnotationFieldMapping) fieldToAnnotation(fieldValue any) (string, error) {
+	invalid := func() error {
+		return fmt.Errorf("field '%s' value '%v' for annotation '%s' is not a %s", mapping.Field, fieldValue, mapping.Annotation, mapping.Type)
+	}
+	switch mapping.Type {
+	case AnnotationFieldTypeInt:
+		n, ok := fieldValue.(json.Number)
+		if !ok {
+			return "", invalid()
+		}
+		i, err := n.Int64()
+		if err != nil {
+			return "", invalid()
+		}
+		return strconv.FormatInt(i, 10), nil
+	case AnnotationFieldTypeFloat:
+		n, ok := fieldValue.(json.Number)
+		if !ok {
+			return "", invalid()
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return "", invalid()
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+	case AnnotationFieldTypeBool:
+		b, ok := fieldValue.(bool)
+		if !ok {
+			return "", invalid()
+		}
+		return strconv.FormatBool(b), nil
+	case AnnotationFieldTypeJSON:
+		raw, err := json.Marshal(fieldValue)
+		if err != nil {
+			return "", fmt.Errorf("unable to marshal field '%s' for annotation '%s': %w", mapping.Field, mapping.Annotation, err)
+		}
+		return string(raw), nil
+	default:
+		s, ok := fieldValue.(string)
+		if !ok {
+			return "", invalid()
+		}
+		return s, nil
+	}
+}
+
+// specToMap returns the spec of obj as an untyped map, with numbers decoded as json.Number to keep their precision
+func specToMap(obj resource.Object) (map[string]any, error) {
+	raw, err := json.Marshal(obj.GetSpec())
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal spec: %w", err)
+	}
+	spec, err := decodeJSONValue(raw)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal spec: %w", err)
+	}
+	switch cast := spec.(type) {
+	case map[string]any:
+		return cast, nil
+	case nil:
+		return make(map[string]any), nil
+	default:
+		return nil, fmt.Errorf("spec must be an object, got %T", spec)
+	}
+}
+
+// setSpecFromMap sets the spec of obj from an untyped map, decoding it into a new value of the type of the existing spec
+func setSpecFromMap(obj resource.Object, spec map[string]any) error {
+	if _, ok := obj.GetSpec().(map[string]any); ok {
+		// Untyped specs keep the json.Number values, rather than losing precision by decoding them as float64
+		return obj.SetSpec(spec)
+	}
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("unable to marshal spec: %w", err)
+	}
+	specType := reflect.TypeOf(obj.GetSpec())
+	if specType == nil {
+		return obj.SetSpec(spec)
+	}
+	newSpec := reflect.New(specType)
+	if err = json.Unmarshal(raw, newSpec.Interface()); err != nil {
+		return fmt.Errorf("unable to unmarshal spec into %s: %w", specType.String(), err)
+	}
+	return obj.SetSpec(newSpec.Elem().Interface())
+}
+
+func decodeJSONValue(raw []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return value, nil
+}
+
+func getFieldPath(obj map[string]any, path []string) (any, bool, error) {
+	current := obj
+	for i, key := range path {
+		value, ok := current[key]
+		if !ok || value == nil {
+			return nil, false, nil
+		}
+		if i == len(path)-1 {
+			return value, true, nil
+		}
+		if current, ok = value.(map[string]any); !ok {
+			return nil, false, fmt.Errorf("'%s' is not an object", strings.Join(path[:i+1], "."))
+		}
+	}
+	return nil, false, nil
+}
+
+func setFieldPath(obj map[string]any, path []string, value any) error {
+	current := obj
+	for i, key := range path[:len(path)-1] {
+		next, ok := current[key]
+		if !ok || next == nil {
+			next = make(map[string]any)
+			current[key] = next
+		}
+		if current, ok = next.(map[string]any); !ok {
+			return fmt.Errorf("'%s' is not an object", strings.Join(path[:i+1], "."))
+		}
+	}
+	current[path[len(path)-1]] = value
+	return nil
+}
+
+// deleteFieldPath deletes the field at the path, and any objects on the path which are left empty
+func deleteFieldPath(obj map[string]any, path []string) {
+	if len(path) == 1 {
+		delete(obj, path[0])
+		return
+	}
+	next, ok := obj[path[0]].(map[string]any)
+	if !ok {
+		return
+	}
+	deleteFieldPath(next, path[1:])
+	if len(next) == 0 {
+		delete(obj, path[0])
+	}
+}
+
+func isFieldPathPrefix(prefix, field string) bool {
+	return prefix == field || strings.HasPrefix(field, prefix+".")
+}
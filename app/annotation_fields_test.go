@@ -0,0 +1,344 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+type annotationFieldsV1Spec struct {
+	Title string `json:"title"`
+}
+
+type annotationFieldsV2Spec struct {
+	Title     string                  `json:"title"`
+	Display   annotationFieldsDisplay `json:"display"`
+	Priority  int                     `json:"priority"`
+	Weight    float64                 `json:"weight,omitempty"`
+	Hidden    bool                    `json:"hidden"`
+	Tags      []string                `json:"tags,omitempty"`
+	Unrelated string                  `json:"unrelated,omitempty"`
+}
+
+type annotationFieldsDisplay struct {
+	Color string `json:"color,omitempty"`
+}
+
+type (
+	annotationFieldsV1 = resource.TypedSpecObject[annotationFieldsV1Spec]
+	annotationFieldsV2 = resource.TypedSpecObject[annotationFieldsV2Spec]
+)
+
+func newTestAnnotationFieldMapper(t *testing.T) *AnnotationFieldMapper {
+	m, err := NewAnnotationFieldMapper(
+		AnnotationFieldMapping{Annotation: "grafana.app/color", Field: "display.color"},
+		AnnotationFieldMapping{Annotation: "grafana.app/priority", Field: "priority", Type: AnnotationFieldTypeInt},
+		AnnotationFieldMapping{Annotation: "grafana.app/weight", Field: "weight", Type: AnnotationFieldTypeFloat},
+		AnnotationFieldMapping{Annotation: "grafana.app/hidden", Field: "hidden", Type: AnnotationFieldTypeBool},
+		AnnotationFieldMapping{Annotation: "grafana.app/tags", Field: "tags", Type: AnnotationFieldTypeJSON, KeepAnnotation: true},
+	)
+	require.Nil(t, err)
+	return m
+}
+
+func TestParseAnnotationFieldMapping(t *testing.T) {
+	m, err := ParseAnnotationFieldMapping("grafana.app/priority=scheduling.priority:int")
+	require.Nil(t, err)
+	assert.Equal(t, AnnotationFieldMapping{Annotation: "grafana.app/priority", Field: "scheduling.priority", Type: AnnotationFieldTypeInt}, m)
+	m, err = ParseAnnotationFieldMapping("grafana.app/title=title")
+	require.Nil(t, err)
+	assert.Equal(t, AnnotationFieldMapping{Annotation: "grafana.app/title", Field: "title"}, m)
+	_, err = ParseAnnotationFieldMapping("grafana.app/title")
+	assert.NotNil(t, err)
+}
+
+func TestNewAnnotationFieldMapper(t *testing.T) {
+	tests := []struct {
+		name     string
+		mappings []AnnotationFieldMapping
+		err      string
+	}{{
+		name: "no mappings",
+		err:  "at least one mapping must be provided",
+	}, {
+		name:     "invalid annotation",
+		mappings: []AnnotationFieldMapping{{Annotation: "not/a/key", Field: "foo"}},
+		err:      "invalid annotation 'not/a/key'",
+	}, {
+		name:     "invalid field",
+		mappings: []AnnotationFieldMapping{{Annotation: "foo", Field: "foo..bar"}},
+		err:      "invalid field path 'foo..bar' for annotation 'foo'",
+	}, {
+		name:     "invalid type",
+		mappings: []AnnotationFieldMapping{{Annotation: "foo", Field: "foo", Type: "time"}},
+		err:      "invalid type 'time' for annotation 'foo'",
+	}, {
+		name:     "duplicate annotation",
+		mappings: []AnnotationFieldMapping{{Annotation: "foo", Field: "foo"}, {Annotation: "foo", Field: "bar"}},
+		err:      "annotation 'foo' is mapped more than once",
+	}, {
+		name:     "overlapping fields",
+		mappings: []AnnotationFieldMapping{{Annotation: "foo", Field: "foo"}, {Annotation: "bar", Field: "foo.bar"}},
+		err:      "field 'foo.bar' of annotation 'bar' overlaps field 'foo' of annotation 'foo'",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewAnnotationFieldMapper(test.mappings...)
+			require.NotNil(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestAnnotationFieldMapper_MoveToFields(t *testing.T) {
+	m := newTestAnnotationFieldMapper(t)
+
+	t.Run("typed", func(t *testing.T) {
+		obj := &annotationFieldsV2{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				"grafana.app/color":    "blue",
+				"grafana.app/priority": "3",
+				"grafana.app/weight":   "0.5",
+				"grafana.app/hidden":   "true",
+				"grafana.app/tags":     `["a","b"]`,
+				"grafana.app/other":    "other",
+			}},
+			Spec: annotationFieldsV2Spec{
+				Title:     "foo",
+				Unrelated: "bar",
+			},
+		}
+		changed, err := m.MoveToFields(obj)
+		require.Nil(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, annotationFieldsV2Spec{
+			Title:     "foo",
+			Display:   annotationFieldsDisplay{Color: "blue"},
+			Priority:  3,
+			Weight:    0.5,
+			Hidden:    true,
+			Tags:      []string{"a", "b"},
+			Unrelated: "bar",
+		}, obj.Spec)
+		assert.Equal(t, map[string]string{
+			"grafana.app/tags":  `["a","b"]`,
+			"grafana.app/other": "other",
+		}, obj.GetAnnotations())
+
+		// Moving again is a no-op
+		changed, err = m.MoveToFields(obj)
+		require.Nil(t, err)
+		assert.False(t, changed)
+	})
+
+	t.Run("existing fields are not overwritten", func(t *testing.T) {
+		obj := &annotationFieldsV2{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				"grafana.app/priority": "3",
+				"grafana.app/hidden":   "false",
+			}},
+			Spec: annotationFieldsV2Spec{
+				Priority: 5,
+			},
+		}
+		changed, err := m.MoveToFields(obj)
+		require.Nil(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, annotationFieldsV2Spec{Priority: 5}, obj.Spec)
+		assert.Empty(t, obj.GetAnnotations())
+	})
+
+	t.Run("untyped", func(t *testing.T) {
+		obj := &resource.UntypedObject{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				"grafana.app/color":    "blue",
+				"grafana.app/priority": "12345678901234567",
+			}},
+			Spec: map[string]any{
+				"title": "foo",
+			},
+		}
+		changed, err := m.MoveToFields(obj)
+		require.Nil(t, err)
+		assert.True(t, changed)
+		assert.Empty(t, obj.GetAnnotations())
+		spec := obj.GetSpec().(map[string]any)
+		assert.Equal(t, "foo", spec["title"])
+		assert.Equal(t, map[string]any{"color": "blue"}, spec["display"])
+		assert.Equal(t, "12345678901234567", toString(t, spec["priority"]))
+	})
+
+	t.Run("untyped zero values are not overwritten", func(t *testing.T) {
+		obj := &resource.UntypedObject{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				"grafana.app/priority": "3",
+				"grafana.app/hidden":   "true",
+				"grafana.app/color":    "blue",
+			}},
+			Spec: map[string]any{
+				"priority": 0,
+				"hidden":   false,
+				"display":  map[string]any{"color": nil},
+			},
+		}
+		changed, err := m.MoveToFields(obj)
+		require.Nil(t, err)
+		assert.True(t, changed)
+		assert.Empty(t, obj.GetAnnotations())
+		spec := obj.GetSpec().(map[string]any)
+		assert.Equal(t, "0", toString(t, spec["priority"]))
+		assert.Equal(t, false, spec["hidden"])
+		// A null value is unset
+		assert.Equal(t, map[string]any{"color": "blue"}, spec["display"])
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for annotation, value := range map[string]string{
+			"grafana.app/priority": "high",
+			"grafana.app/weight":   "heavy",
+			"grafana.app/hidden":   "maybe",
+			"grafana.app/tags":     "[a",
+		} {
+			obj := &annotationFieldsV2{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{annotation: value}},
+			}
+			_, err := m.MoveToFields(obj)
+			assert.NotNil(t, err, annotation)
+			assert.Equal(t, map[string]string{annotation: value}, obj.GetAnnotations())
+		}
+	})
+
+	t.Run("field path through a non-object", func(t *testing.T) {
+		obj := &resource.UntypedObject{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"grafana.app/color": "blue"}},
+			Spec:       map[string]any{"display": "blue"},
+		}
+		_, err := m.MoveToFields(obj)
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), "'display' is not an object")
+	})
+}
+
+func TestAnnotationFieldMapper_MoveToAnnotations(t *testing.T) {
+	m := newTestAnnotationFieldMapper(t)
+	obj := &resource.UntypedObject{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			"grafana.app/other": "other",
+		}},
+		Spec: map[string]any{
+			"title":    "foo",
+			"display":  map[string]any{"color": "blue"},
+			"priority": 3,
+			"weight":   0.5,
+			"hidden":   false,
+			"tags":     []any{"a", "b"},
+		},
+	}
+	changed, err := m.MoveToAnnotations(obj)
+	require.Nil(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, map[string]string{
+		"grafana.app/color":    "blue",
+		"grafana.app/priority": "3",
+		"grafana.app/weight":   "0.5",
+		"grafana.app/hidden":   "false",
+		"grafana.app/tags":     `["a","b"]`,
+		"grafana.app/other":    "other",
+	}, obj.GetAnnotations())
+	// Fields are removed, except for those with KeepAnnotation, and objects left empty are removed
+	assert.Equal(t, map[string]any{
+		"title": "foo",
+		"tags":  []any{"a", "b"},
+	}, obj.GetSpec())
+
+	// Moving back to the fields restores the object
+	changed, err = m.MoveToFields(obj)
+	require.Nil(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, map[string]string{
+		"grafana.app/tags":  `["a","b"]`,
+		"grafana.app/other": "other",
+	}, obj.GetAnnotations())
+	spec := obj.GetSpec().(map[string]any)
+	assert.Equal(t, map[string]any{"color": "blue"}, spec["display"])
+	assert.Equal(t, "3", toString(t, spec["priority"]))
+	assert.Equal(t, "0.5", toString(t, spec["weight"]))
+	assert.Equal(t, false, spec["hidden"])
+
+	t.Run("type mismatch", func(t *testing.T) {
+		obj := &resource.UntypedObject{
+			Spec: map[string]any{"priority": "high"},
+		}
+		_, err := m.MoveToAnnotations(obj)
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), "field 'priority' value 'high' for annotation 'grafana.app/priority' is not a int")
+	})
+}
+
+func TestAnnotationFieldMapper_Conversion(t *testing.T) {
+	m, err := NewAnnotationFieldMapper(
+		AnnotationFieldMapping{Annotation: "grafana.app/color", Field: "display.color"},
+		AnnotationFieldMapping{Annotation: "grafana.app/priority", Field: "priority", Type: AnnotationFieldTypeInt},
+	)
+	require.Nil(t, err)
+	c, err := NewVersionConverter(
+		conversionTestKind("v1", &annotationFieldsV1{}),
+		conversionTestKind("v2", &annotationFieldsV2{}),
+	)
+	require.Nil(t, err)
+	require.Nil(t, c.AddConversion("v1", "v2", m.WrapToFields(func(_ context.Context, src resource.Object, dst resource.Object) error {
+		dst.(*annotationFieldsV2).Spec.Title = src.(*annotationFieldsV1).Spec.Title
+		return nil
+	})))
+	require.Nil(t, c.AddConversion("v2", "v1", m.WrapToAnnotations(func(_ context.Context, src resource.Object, dst resource.Object) error {
+		dst.(*annotationFieldsV1).Spec.Title = src.(*annotationFieldsV2).Spec.Title
+		return nil
+	})))
+
+	v1 := &annotationFieldsV1{
+		TypeMeta: metav1.TypeMeta{APIVersion: "test.grafana.app/v1", Kind: "Foo"},
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Annotations: map[string]string{
+			"grafana.app/color":    "blue",
+			"grafana.app/priority": "3",
+			"grafana.app/other":    "other",
+		}},
+		Spec: annotationFieldsV1Spec{Title: "foo"},
+	}
+	converted, err := c.Convert(context.Background(), ConversionRequest{
+		SourceGVK: v1.GroupVersionKind(),
+		TargetGVK: v1.GroupVersionKind().GroupKind().WithVersion("v2"),
+		Raw:       RawObject{Object: v1},
+	})
+	require.Nil(t, err)
+	v2 := converted.Object.(*annotationFieldsV2)
+	assert.Equal(t, annotationFieldsV2Spec{
+		Title:    "foo",
+		Display:  annotationFieldsDisplay{Color: "blue"},
+		Priority: 3,
+	}, v2.Spec)
+	assert.Equal(t, map[string]string{"grafana.app/other": "other"}, v2.GetAnnotations())
+	// The source is not modified
+	assert.Len(t, v1.GetAnnotations(), 3)
+
+	converted, err = c.Convert(context.Background(), ConversionRequest{
+		SourceGVK: v2.GroupVersionKind(),
+		TargetGVK: v1.GroupVersionKind(),
+		Raw:       RawObject{Object: v2},
+	})
+	require.Nil(t, err)
+	back := converted.Object.(*annotationFieldsV1)
+	assert.Equal(t, v1.Spec, back.Spec)
+	assert.Equal(t, v1.GetAnnotations(), back.GetAnnotations())
+}
+
+func toString(t *testing.T, value any) string {
+	t.Helper()
+	s, ok := value.(interface{ String() string })
+	require.True(t, ok, "%T is not a json.Number", value)
+	return s.String()
+}
@@ -60,6 +60,21 @@ type Config struct {
 	ManifestData ManifestData
 	// SpecificConfig is app-specific config (as opposed to generic config)
 	SpecificConfig SpecificConfig
+	// ConfigKind holds the current instance of the app's config kind, if a kind in ManifestData is marked as a config kind.
+	// It is kept up to date by the runner, and is nil if the app has no config kind. Use GetConfigKind for typed access.
+	ConfigKind *ConfigKindValue
+	// InformerFactory is a factory provided by the runner for informers which share their watches with the runner
+	// and the other apps it runs. It is nil if the runner does not provide one.
+	// operator.Runner provides an *operator.SharedInformerFactory (see operator.SharedInformerFactoryFromConfig).
+	InformerFactory InformerFactory
+}
+
+// InformerFactory is a factory provided by a runner for informers which share their watches.
+// Creating informers requires types specific to the runner, so apps should get the runner's concrete factory
+// from Config with the helper the runner provides (such as operator.SharedInformerFactoryFromConfig).
+type InformerFactory interface {
+	// SharedWatches returns a description of each list/watch the factory's informers currently share
+	SharedWatches() []string
 }
 
 // SpecificConfig is app-specific configuration which can vary from app to app
@@ -102,6 +117,10 @@ type App interface {
 	Validate(ctx context.Context, request *AdmissionRequest) error
 	// Mutate runs mutation on the incoming request, responding with a MutatingResponse on success, or an error on failure
 	Mutate(ctx context.Context, request *AdmissionRequest) (*MutatingResponse, error)
+	// Default sets default values on the object in the incoming request, responding with a MutatingResponse containing
+	// the defaulted object on success, or an error on failure. Runners call Default before Mutate for mutating admission.
+	// It returns ErrNotImplemented if the app has no defaulting for the request's kind.
+	Default(ctx context.Context, request *AdmissionRequest) (*MutatingResponse, error)
 	// Convert converts the object based on the ConversionRequest, returning a RawObject which MUST contain
 	// the converted bytes and encoding (Raw and Encoding respectively), and MAY contain the Object representation of those bytes.
 	// It returns an error if the conversion fails, or if the functionality is not supported by the app.
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana-app-sdk/metrics"
+)
+
+const sdkModulePath = "github.com/grafana/grafana-app-sdk"
+
+// These are set at build time with -ldflags, see BuildLDFlags
+var (
+	buildVersion = ""
+	buildCommit  = ""
+)
+
+var (
+	buildInfo     BuildInfo
+	buildInfoOnce sync.Once
+)
+
+// BuildInfo is the version information of a running app binary
+type BuildInfo struct {
+	// AppVersion is the version of the app
+	AppVersion string `json:"appVersion"`
+	// GitCommit is the git SHA the app was built from
+	GitCommit string `json:"gitCommit"`
+	// SDKVersion is the version of the grafana-app-sdk the app was built with
+	SDKVersion string `json:"sdkVersion"`
+	// GoVersion is the version of go the app was built with
+	GoVersion string `json:"goVersion"`
+}
+
+// GetBuildInfo returns the BuildInfo of the running binary.
+// AppVersion and GitCommit are the values set with the -ldflags from BuildLDFlags. If they were not set,
+// the main module version and the VCS revision embedded by `go build` are used instead, with "unknown" for unavailable values.
+func GetBuildInfo() BuildInfo {
+	buildInfoOnce.Do(func() {
+		buildInfo = BuildInfo{
+			AppVersion: buildVersion,
+			GitCommit:  buildCommit,
+			GoVersion:  runtime.Version(),
+		}
+		if info, ok := debug.ReadBuildInfo(); ok {
+			buildInfo = buildInfoFrom(buildInfo, info)
+		}
+		if buildInfo.AppVersion == "" {
+			buildInfo.AppVersion = "unknown"
+		}
+		if buildInfo.GitCommit == "" {
+			buildInfo.GitCommit = "unknown"
+		}
+		if buildInfo.SDKVersion == "" {
+			buildInfo.SDKVersion = "unknown"
+		}
+	})
+	return buildInfo
+}
+
+// buildInfoFrom fills empty fields in b from the module and VCS information in info
+func buildInfoFrom(b BuildInfo, info *debug.BuildInfo) BuildInfo {
+	if b.AppVersion == "" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		b.AppVersion = info.Main.Version
+	}
+	if b.GitCommit == "" {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" {
+				b.GitCommit = s.Value
+			}
+		}
+	}
+	if info.Main.Path == sdkModulePath {
+		b.SDKVersion = info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != sdkModulePath {
+			continue
+		}
+		b.SDKVersion = dep.Version
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			b.SDKVersion = dep.Replace.Version
+		}
+	}
+	return b
+}
+
+// BuildLDFlags returns the -ldflags value which sets the app version and git commit returned by GetBuildInfo,
+// for use by build tooling written in go (such as a magefile). The returned flags are
+//
+//	-X github.com/grafana/grafana-app-sdk/app.buildVersion=<version> -X github.com/grafana/grafana-app-sdk/app.buildCommit=<commit>
+//
+// which can also be set directly in a Makefile, as in the Makefile generated by `grafana-app-sdk project init`.
+func BuildLDFlags(version, commit string) string {
+	return fmt.Sprintf("-X %[1]s/app.buildVersion=%[2]s -X %[1]s/app.buildCommit=%[3]s", sdkModulePath, version, commit)
+}
+
+// NewBuildInfoCollector returns a prometheus gauge named build_info, which always has a value of 1,
+// and has the app_version, git_commit, sdk_version, and go_version labels from GetBuildInfo.
+func NewBuildInfoCollector(cfg metrics.Config) prometheus.Collector {
+	info := GetBuildInfo()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: cfg.Namespace,
+		Name:      "build_info",
+		Help:      "Build information of the app, with a value of 1.",
+		ConstLabels: prometheus.Labels{
+			"app_version": info.AppVersion,
+			"git_commit":  info.GitCommit,
+			"sdk_version": info.SDKVersion,
+			"go_version":  info.GoVersion,
+		},
+	})
+	gauge.Set(1)
+	return gauge
+}
+
+// BuildInfoHandler returns an http.Handler which responds with the JSON-encoded BuildInfo from GetBuildInfo
+func BuildInfoHandler() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := json.Marshal(GetBuildInfo())
+		if err != nil {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		//nolint:errcheck
+		writer.Write(body)
+	})
+}
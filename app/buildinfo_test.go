@@ -0,0 +1,78 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/metrics"
+)
+
+func TestBuildInfoFrom(t *testing.T) {
+	info := &debug.BuildInfo{
+		Main: debug.Module{Path: "github.com/grafana/my-app", Version: "v1.2.3"},
+		Deps: []*debug.Module{
+			{Path: "github.com/grafana/other", Version: "v0.0.1"},
+			{Path: sdkModulePath, Version: "v0.30.0"},
+		},
+		Settings: []debug.BuildSetting{{Key: "vcs.revision", Value: "abc123"}},
+	}
+
+	t.Run("from go build info", func(t *testing.T) {
+		assert.Equal(t, BuildInfo{
+			AppVersion: "v1.2.3",
+			GitCommit:  "abc123",
+			SDKVersion: "v0.30.0",
+			GoVersion:  "go1.23",
+		}, buildInfoFrom(BuildInfo{GoVersion: "go1.23"}, info))
+	})
+
+	t.Run("ldflags take precedence", func(t *testing.T) {
+		assert.Equal(t, BuildInfo{
+			AppVersion: "1.0.0",
+			GitCommit:  "def456",
+			SDKVersion: "v0.30.0",
+		}, buildInfoFrom(BuildInfo{AppVersion: "1.0.0", GitCommit: "def456"}, info))
+	})
+
+	t.Run("devel version is ignored", func(t *testing.T) {
+		info := &debug.BuildInfo{Main: debug.Module{Path: "github.com/grafana/my-app", Version: "(devel)"}}
+		assert.Equal(t, BuildInfo{}, buildInfoFrom(BuildInfo{}, info))
+	})
+}
+
+func TestBuildLDFlags(t *testing.T) {
+	assert.Equal(t,
+		"-X github.com/grafana/grafana-app-sdk/app.buildVersion=v1.0.0 -X github.com/grafana/grafana-app-sdk/app.buildCommit=abc",
+		BuildLDFlags("v1.0.0", "abc"))
+}
+
+func TestNewBuildInfoCollector(t *testing.T) {
+	info := GetBuildInfo()
+	expected := `# HELP foo_build_info Build information of the app, with a value of 1.
+# TYPE foo_build_info gauge
+foo_build_info{app_version="` + info.AppVersion + `",git_commit="` + info.GitCommit + `",go_version="` + info.GoVersion + `",sdk_version="` + info.SDKVersion + `"} 1
+`
+	require.Nil(t, testutil.CollectAndCompare(NewBuildInfoCollector(metrics.DefaultConfig("foo")), strings.NewReader(expected)))
+}
+
+func TestBuildInfoHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	BuildInfoHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	info := BuildInfo{}
+	require.Nil(t, json.Unmarshal(rec.Body.Bytes(), &info))
+	assert.Equal(t, GetBuildInfo(), info)
+
+	rec = httptest.NewRecorder()
+	BuildInfoHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/version", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// ErrConfigKindExists is returned by ValidateConfigKindAdmission when a request attempts to create a second
+// instance of a config kind.
+var ErrConfigKindExists = errors.New("an instance of the config kind already exists")
+
+// ConfigKindValue holds the current instance of an app's config kind, a cluster-scoped singleton kind
+// used to store the app's runtime settings. A ConfigKindValue is kept up to date by the runner,
+// which watches the config kind and calls Set whenever the instance is added, updated, or deleted.
+// It is safe for concurrent use.
+type ConfigKindValue struct {
+	mux       sync.RWMutex
+	obj       resource.Object
+	listeners []func(context.Context, resource.Object)
+}
+
+// NewConfigKindValue returns a new, empty ConfigKindValue
+func NewConfigKindValue() *ConfigKindValue {
+	return &ConfigKindValue{
+		listeners: make([]func(context.Context, resource.Object), 0),
+	}
+}
+
+// Get returns the current instance of the config kind, or nil if no instance exists
+func (v *ConfigKindValue) Get() resource.Object {
+	v.mux.RLock()
+	defer v.mux.RUnlock()
+	return v.obj
+}
+
+// Set sets the current instance of the config kind, and calls all listeners registered with OnChange.
+// Setting a nil object indicates that the instance has been deleted.
+func (v *ConfigKindValue) Set(ctx context.Context, obj resource.Object) {
+	v.mux.Lock()
+	v.obj = obj
+	listeners := make([]func(context.Context, resource.Object), len(v.listeners))
+	copy(listeners, v.listeners)
+	v.mux.Unlock()
+	for _, l := range listeners {
+		l(ctx, obj)
+	}
+}
+
+// OnChange registers a function which is called every time the config kind instance changes.
+// The object passed to the function is nil if the instance was deleted.
+func (v *ConfigKindValue) OnChange(listener func(context.Context, resource.Object)) {
+	v.mux.Lock()
+	defer v.mux.Unlock()
+	v.listeners = append(v.listeners, listener)
+}
+
+// GetConfigKind returns the current instance of the config kind in cfg as type T.
+// It returns false if cfg has no config kind, no instance currently exists, or the instance is not of type T.
+func GetConfigKind[T resource.Object](cfg Config) (T, bool) {
+	var zero T
+	if cfg.ConfigKind == nil {
+		return zero, false
+	}
+	obj := cfg.ConfigKind.Get()
+	if obj == nil {
+		return zero, false
+	}
+	cast, ok := obj.(T)
+	if !ok {
+		return zero, false
+	}
+	return cast, true
+}
+
+// ValidateConfigKindAdmission rejects a request to create an instance of a config kind if a different instance
+// already exists in current. All other requests are allowed.
+// Because current is populated from a watch, there is a brief window on startup where a second instance
+// may not be rejected.
+func ValidateConfigKindAdmission(current *ConfigKindValue, req *AdmissionRequest) error {
+	if current == nil || req == nil || req.Action != resource.AdmissionActionCreate || req.Object == nil {
+		return nil
+	}
+	existing := current.Get()
+	if existing == nil || existing.GetName() == req.Object.GetName() {
+		return nil
+	}
+	return fmt.Errorf("%w: %s '%s' must be deleted before '%s' can be created",
+		ErrConfigKindExists, req.Kind, existing.GetName(), req.Object.GetName())
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestConfigKindValue(t *testing.T) {
+	v := NewConfigKindValue()
+	assert.Nil(t, v.Get())
+
+	changes := make([]resource.Object, 0)
+	v.OnChange(func(_ context.Context, obj resource.Object) {
+		changes = append(changes, obj)
+	})
+
+	obj := &resource.UntypedObject{}
+	obj.SetName("config")
+	v.Set(context.Background(), obj)
+	assert.Equal(t, obj, v.Get())
+	v.Set(context.Background(), nil)
+	assert.Nil(t, v.Get())
+	require.Len(t, changes, 2)
+	assert.Equal(t, obj, changes[0])
+	assert.Nil(t, changes[1])
+}
+
+func TestGetConfigKind(t *testing.T) {
+	t.Run("no config kind", func(t *testing.T) {
+		_, ok := GetConfigKind[*resource.UntypedObject](Config{})
+		assert.False(t, ok)
+	})
+
+	t.Run("no instance", func(t *testing.T) {
+		_, ok := GetConfigKind[*resource.UntypedObject](Config{ConfigKind: NewConfigKindValue()})
+		assert.False(t, ok)
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		v := NewConfigKindValue()
+		v.Set(context.Background(), &resource.UntypedObject{})
+		_, ok := GetConfigKind[*resource.TypedSpecObject[string]](Config{ConfigKind: v})
+		assert.False(t, ok)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		v := NewConfigKindValue()
+		obj := &resource.UntypedObject{}
+		v.Set(context.Background(), obj)
+		cfg, ok := GetConfigKind[*resource.UntypedObject](Config{ConfigKind: v})
+		assert.True(t, ok)
+		assert.Equal(t, obj, cfg)
+	})
+}
+
+func TestValidateConfigKindAdmission(t *testing.T) {
+	existing := &resource.UntypedObject{}
+	existing.SetName("config")
+	other := &resource.UntypedObject{}
+	other.SetName("other")
+
+	v := NewConfigKindValue()
+	assert.Nil(t, ValidateConfigKindAdmission(v, &AdmissionRequest{Action: resource.AdmissionActionCreate, Object: other}))
+
+	v.Set(context.Background(), existing)
+	err := ValidateConfigKindAdmission(v, &AdmissionRequest{Action: resource.AdmissionActionCreate, Object: other})
+	assert.ErrorIs(t, err, ErrConfigKindExists)
+	assert.Nil(t, ValidateConfigKindAdmission(v, &AdmissionRequest{Action: resource.AdmissionActionCreate, Object: existing}))
+	assert.Nil(t, ValidateConfigKindAdmission(v, &AdmissionRequest{Action: resource.AdmissionActionUpdate, Object: other}))
+}
@@ -0,0 +1,544 @@
+// Package conformance contains a reusable test suite which verifies that an app.App implementation meets the contract
+// the SDK's runners expect of it, based on the app's manifest: every declared admission capability is implemented,
+// conversions round-trip between every pair of declared versions, custom routes exist and respond with their declared schemas,
+// and the app's watchers and reconcilers tolerate storms of concurrent resyncs.
+//
+// The suite is run from a test in the app's own module:
+//
+//	func TestConformance(t *testing.T) {
+//		a, err := myapp.New(app.Config{ManifestData: *generated.LocalManifest().ManifestData})
+//		require.NoError(t, err)
+//		conformance.Run(t, a, conformance.Config{
+//			Manifest: *generated.LocalManifest().ManifestData,
+//			Objects:  map[string]resource.Object{"MyKind/v1": exampleV1, "MyKind/v2": exampleV2},
+//		})
+//	}
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/operator"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+const (
+	defaultTimeout           = 10 * time.Second
+	defaultResyncCount       = 100
+	defaultResyncConcurrency = 10
+)
+
+// Config is the configuration for the conformance suite
+type Config struct {
+	// Manifest is the manifest of the app being tested
+	Manifest app.ManifestData
+	// Objects are valid example objects of the app's kinds, keyed by "<kind>/<version>" (such as "MyKind/v1").
+	// They are used in the admission, conversion, custom route, and resync requests made to the app.
+	// Checks which require an object for a kind version without one are skipped.
+	Objects map[string]resource.Object
+	// CustomRoutes are custom routes of the app's kinds that are checked by CustomRoutes, in addition to those declared
+	// in the manifest's ManifestKindVersion.CustomRoutes. A route with the same kind, version, method, and path
+	// as a declared route replaces it, such as to send a request body.
+	CustomRoutes []CustomRoute
+	// Reconcilers are the reconcilers for the app's kinds, keyed by "<kind>/<version>", which are checked by Resync.
+	// As app.App does not expose the reconcilers and watchers used by its runner, they must be provided explicitly.
+	Reconcilers map[string]operator.Reconciler
+	// Watchers are the watchers for the app's kinds, keyed by "<kind>/<version>", which are checked by Resync
+	Watchers map[string]operator.ResourceWatcher
+	// ResyncCount is the number of resync events sent to each reconciler and watcher by Resync. Defaults to 100.
+	ResyncCount int
+	// ResyncConcurrency is the number of resync events sent to each reconciler and watcher at once by Resync. Defaults to 10.
+	ResyncConcurrency int
+	// Timeout is the timeout for each call to the app. Defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+// CustomRoute is a custom route of a kind version which is checked by CustomRoutes
+type CustomRoute struct {
+	// Kind is the kind the route belongs to
+	Kind string
+	// Version is the version of the kind the route belongs to
+	Version string
+	// Path is the subresource path of the route
+	Path string
+	// Method is the HTTP method of the route. Defaults to GET.
+	Method string
+	// Body is the body sent in the request to the route
+	Body []byte
+	// ResponseSchema is the schema the JSON response body must match. If nil, the body is not checked.
+	ResponseSchema *openapi3.Schema
+}
+
+// Run runs every check of the suite (ManagedKinds, Admission, Conversion, CustomRoutes, and Resync) as subtests of t
+func Run(t *testing.T, a app.App, cfg Config) {
+	t.Run("ManagedKinds", func(t *testing.T) {
+		ManagedKinds(t, a, cfg)
+	})
+	t.Run("Admission", func(t *testing.T) {
+		Admission(t, a, cfg)
+	})
+	t.Run("Conversion", func(t *testing.T) {
+		Conversion(t, a, cfg)
+	})
+	t.Run("CustomRoutes", func(t *testing.T) {
+		CustomRoutes(t, a, cfg)
+	})
+	t.Run("Resync", func(t *testing.T) {
+		Resync(t, a, cfg)
+	})
+}
+
+// ManagedKinds checks that every kind version in the manifest is returned by the app's ManagedKinds,
+// with the manifest's group
+func ManagedKinds(t testing.TB, a app.App, cfg Config) {
+	managed := managedKinds(a)
+	for _, kind := range cfg.Manifest.Kinds {
+		for _, version := range kind.Versions {
+			k, ok := managed[key(kind.Kind, version.Name)]
+			if !ok {
+				t.Errorf("%s/%s is in the manifest, but is not managed by the app", kind.Kind, version.Name)
+				continue
+			}
+			if k.Group() != cfg.Manifest.Group {
+				t.Errorf("%s/%s has group %s, but the manifest group is %s", kind.Kind, version.Name, k.Group(), cfg.Manifest.Group)
+			}
+		}
+	}
+}
+
+// Admission checks that the app implements every admission capability declared in the manifest
+// (it does not return app.ErrNotImplemented for it), for every declared operation. Requests are made with the example
+// object of the kind version, and the app may deny them; only that the app responds without panicking,
+// within the timeout, is checked.
+func Admission(t testing.TB, a app.App, cfg Config) {
+	for _, kind := range cfg.Manifest.Kinds {
+		for _, version := range kind.Versions {
+			if version.Admission == nil {
+				continue
+			}
+			k := key(kind.Kind, version.Name)
+			obj, ok := cfg.Objects[k]
+			if !ok {
+				t.Logf("skipping admission checks for %s: no example object", k)
+				continue
+			}
+			newRequest := func(action resource.AdmissionAction) *app.AdmissionRequest {
+				req := &app.AdmissionRequest{
+					Action:  action,
+					Kind:    kind.Kind,
+					Group:   cfg.Manifest.Group,
+					Version: version.Name,
+					Object:  obj.Copy(),
+				}
+				if action == resource.AdmissionActionUpdate || action == resource.AdmissionActionDelete {
+					req.OldObject = obj.Copy()
+				}
+				if action == resource.AdmissionActionDelete {
+					req.Object = nil
+				}
+				return req
+			}
+			if version.Admission.Validation != nil {
+				for _, action := range actions(version.Admission.Validation.Operations) {
+					err := call(cfg, func(ctx context.Context) error {
+						return a.Validate(ctx, newRequest(action))
+					})
+					checkImplemented(t, err, "validation", k, action)
+				}
+			}
+			if version.Admission.Mutation != nil {
+				for _, action := range actions(version.Admission.Mutation.Operations) {
+					err := call(cfg, func(ctx context.Context) error {
+						_, err := a.Mutate(ctx, newRequest(action))
+						return err
+					})
+					checkImplemented(t, err, "mutation", k, action)
+				}
+			}
+			if version.Admission.Defaulting != nil {
+				for _, action := range actions(version.Admission.Defaulting.Operations) {
+					err := call(cfg, func(ctx context.Context) error {
+						_, err := a.Default(ctx, newRequest(action))
+						return err
+					})
+					checkImplemented(t, err, "defaulting", k, action)
+				}
+			}
+		}
+	}
+}
+
+// Conversion checks that, for every kind with conversion in the manifest, the example object of each version converts
+// to every other version, and back to the original version without changing its spec
+func Conversion(t testing.TB, a app.App, cfg Config) {
+	managed := managedKinds(a)
+	for _, kind := range cfg.Manifest.Kinds {
+		if !kind.Conversion {
+			continue
+		}
+		for _, src := range kind.Versions {
+			srcKey := key(kind.Kind, src.Name)
+			obj, ok := cfg.Objects[srcKey]
+			if !ok {
+				t.Logf("skipping conversion checks from %s: no example object", srcKey)
+				continue
+			}
+			srcKind, ok := managed[srcKey]
+			if !ok {
+				continue
+			}
+			for _, dst := range kind.Versions {
+				dstKind, ok := managed[key(kind.Kind, dst.Name)]
+				if !ok || dst.Name == src.Name {
+					continue
+				}
+				if err := roundTrip(cfg, a, srcKind, dstKind, obj); err != nil {
+					t.Errorf("conversion of %s to %s: %v", srcKey, dst.Name, err)
+				}
+			}
+		}
+	}
+}
+
+// CustomRoutes checks that each of the custom routes declared in the manifest and configured in cfg.CustomRoutes exists
+// (the app does not return app.ErrCustomRouteNotFound or app.ErrNotImplemented for it), responds with a status code below 500,
+// and responds with a body matching the route's ResponseSchema, if it has one.
+// Requests are made for the example object of the route's kind version.
+func CustomRoutes(t testing.TB, a app.App, cfg Config) {
+	managed := managedKinds(a)
+	routes, err := customRoutes(cfg)
+	if err != nil {
+		t.Errorf("%v", err)
+		return
+	}
+	for _, route := range routes {
+		k := key(route.Kind, route.Version)
+		method := route.Method
+		if method == "" {
+			method = http.MethodGet
+		}
+		name := fmt.Sprintf("%s %s %s", k, method, route.Path)
+		kind, ok := managed[k]
+		if !ok {
+			t.Errorf("%s: kind is not managed by the app", name)
+			continue
+		}
+		identifier := resource.FullIdentifier{
+			Group:   kind.Group(),
+			Version: kind.Version(),
+			Kind:    kind.Kind(),
+			Plural:  kind.Plural(),
+		}
+		if obj, ok := cfg.Objects[k]; ok {
+			identifier.Namespace = obj.GetNamespace()
+			identifier.Name = obj.GetName()
+		}
+		var resp *app.ResourceCustomRouteResponse
+		err := call(cfg, func(ctx context.Context) error {
+			var err error
+			resp, err = a.CallResourceCustomRoute(ctx, &app.ResourceCustomRouteRequest{
+				ResourceIdentifier: identifier,
+				SubresourcePath:    route.Path,
+				Method:             method,
+				Headers:            http.Header{"Content-Type": []string{"application/json"}},
+				Body:               route.Body,
+			})
+			return err
+		})
+		switch {
+		case errors.Is(err, app.ErrCustomRouteNotFound), errors.Is(err, app.ErrNotImplemented):
+			t.Errorf("%s: route is not implemented: %v", name, err)
+			continue
+		case err != nil:
+			t.Errorf("%s: %v", name, err)
+			continue
+		case resp == nil:
+			t.Errorf("%s: route returned a nil response", name)
+			continue
+		case resp.StatusCode >= http.StatusInternalServerError:
+			t.Errorf("%s: route responded with status code %d", name, resp.StatusCode)
+			continue
+		}
+		if route.ResponseSchema == nil {
+			continue
+		}
+		var body any
+		if err = json.Unmarshal(resp.Body, &body); err != nil {
+			t.Errorf("%s: response body is not JSON: %v", name, err)
+			continue
+		}
+		if err = route.ResponseSchema.VisitJSON(body); err != nil {
+			t.Errorf("%s: response body does not match the response schema: %v", name, err)
+		}
+	}
+}
+
+// customRoutes returns the custom routes declared in the manifest, followed by the configured routes which are not declared.
+// Configured routes replace the declared route with the same kind, version, method, and path.
+func customRoutes(cfg Config) ([]CustomRoute, error) {
+	routeKey := func(r CustomRoute) string {
+		method := r.Method
+		if method == "" {
+			method = http.MethodGet
+		}
+		return fmt.Sprintf("%s %s %s", key(r.Kind, r.Version), strings.ToUpper(method), r.Path)
+	}
+	configured := make(map[string]CustomRoute, len(cfg.CustomRoutes))
+	for _, route := range cfg.CustomRoutes {
+		configured[routeKey(route)] = route
+	}
+	routes := make([]CustomRoute, 0, len(cfg.CustomRoutes))
+	for _, kind := range cfg.Manifest.Kinds {
+		for _, version := range kind.Versions {
+			for _, declared := range version.CustomRoutes {
+				route := CustomRoute{
+					Kind:    kind.Kind,
+					Version: version.Name,
+					Path:    declared.Path,
+					Method:  declared.Method,
+				}
+				k := routeKey(route)
+				if override, ok := configured[k]; ok {
+					routes = append(routes, override)
+					delete(configured, k)
+					continue
+				}
+				if declared.ResponseSchema != nil {
+					op, err := declared.AsOpenAPI3()
+					if err != nil {
+						return nil, fmt.Errorf("%s: %w", k, err)
+					}
+					route.ResponseSchema = op.Responses.Status(http.StatusOK).Value.Content.Get("application/json").Schema.Value
+				}
+				routes = append(routes, route)
+			}
+		}
+	}
+	for _, route := range cfg.CustomRoutes {
+		if _, ok := configured[routeKey(route)]; ok {
+			routes = append(routes, route)
+		}
+	}
+	return routes, nil
+}
+
+// Resync checks that each configured reconciler and watcher handles a storm of concurrent resyncs of the example object
+// of its kind version (as sent by an informer's periodic resync, or a cache re-list) without returning an error,
+// panicking, or exceeding the timeout. Reconcilers receive ReconcileActionResynced requests, and watchers receive
+// Update calls where the old and new object are the same.
+func Resync(t testing.TB, _ app.App, cfg Config) {
+	for k, reconciler := range cfg.Reconcilers {
+		obj, ok := cfg.Objects[k]
+		if !ok {
+			t.Logf("skipping resync checks for the %s reconciler: no example object", k)
+			continue
+		}
+		if err := storm(cfg, func(ctx context.Context) error {
+			_, err := reconciler.Reconcile(ctx, operator.ReconcileRequest{
+				Action: operator.ReconcileActionResynced,
+				Object: obj.Copy(),
+			})
+			return err
+		}); err != nil {
+			t.Errorf("%s reconciler: %v", k, err)
+		}
+	}
+	for k, watcher := range cfg.Watchers {
+		obj, ok := cfg.Objects[k]
+		if !ok {
+			t.Logf("skipping resync checks for the %s watcher: no example object", k)
+			continue
+		}
+		if err := storm(cfg, func(ctx context.Context) error {
+			return watcher.Update(ctx, obj.Copy(), obj.Copy())
+		}); err != nil {
+			t.Errorf("%s watcher: %v", k, err)
+		}
+	}
+}
+
+func roundTrip(cfg Config, a app.App, srcKind, dstKind resource.Kind, obj resource.Object) error {
+	converted, err := convert(cfg, a, srcKind, dstKind, obj)
+	if err != nil {
+		return err
+	}
+	back, err := convert(cfg, a, dstKind, srcKind, converted)
+	if err != nil {
+		return fmt.Errorf("converting back: %w", err)
+	}
+	expected, err := specJSON(obj)
+	if err != nil {
+		return err
+	}
+	actual, err := specJSON(back)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		return fmt.Errorf("spec changed after converting back: expected %v, got %v", expected, actual)
+	}
+	return nil
+}
+
+func convert(cfg Config, a app.App, srcKind, dstKind resource.Kind, obj resource.Object) (resource.Object, error) {
+	buf := &bytes.Buffer{}
+	if err := srcKind.Write(obj, buf, resource.KindEncodingJSON); err != nil {
+		return nil, fmt.Errorf("unable to write object: %w", err)
+	}
+	var res *app.RawObject
+	err := call(cfg, func(ctx context.Context) error {
+		var err error
+		res, err = a.Convert(ctx, app.ConversionRequest{
+			SourceGVK: srcKind.GroupVersionKind(),
+			TargetGVK: dstKind.GroupVersionKind(),
+			Raw: app.RawObject{
+				Raw:      buf.Bytes(),
+				Object:   obj,
+				Encoding: resource.KindEncodingJSON,
+			},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res == nil || len(res.Raw) == 0 {
+		return nil, fmt.Errorf("converting to %s returned no object", dstKind.Version())
+	}
+	converted, err := dstKind.Read(bytes.NewReader(res.Raw), resource.KindEncodingJSON)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read object converted to %s: %w", dstKind.Version(), err)
+	}
+	if gvk := converted.GroupVersionKind(); gvk != dstKind.GroupVersionKind() {
+		return nil, fmt.Errorf("object converted to %s has group, version, and kind %s", dstKind.Version(), gvk.String())
+	}
+	return converted, nil
+}
+
+// specJSON returns the spec of obj as decoded JSON, so that specs of different types can be compared
+func specJSON(obj resource.Object) (any, error) {
+	b, err := json.Marshal(obj.GetSpec())
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal spec: %w", err)
+	}
+	var spec any
+	err = json.Unmarshal(b, &spec)
+	return spec, err
+}
+
+// storm calls fn cfg.ResyncCount times, cfg.ResyncConcurrency at a time, and returns the first error
+func storm(cfg Config, fn func(ctx context.Context) error) error {
+	count := cfg.ResyncCount
+	if count <= 0 {
+		count = defaultResyncCount
+	}
+	concurrency := cfg.ResyncConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultResyncConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	errs := make(chan error, count)
+	wg := sync.WaitGroup{}
+	for i := 0; i < count; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := call(cfg, fn); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// callFailure is returned by call when fn fails to return an error or response
+type callFailure struct {
+	reason string
+}
+
+func (c *callFailure) Error() string {
+	return c.reason
+}
+
+// call calls fn with a context with the configured timeout, and returns an error if fn panics or does not return in time
+func call(cfg Config, fn func(ctx context.Context) error) error {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	res := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				res <- &callFailure{reason: fmt.Sprintf("panicked: %v", r)}
+			}
+		}()
+		res <- fn(ctx)
+	}()
+	select {
+	case err := <-res:
+		return err
+	case <-ctx.Done():
+		return &callFailure{reason: fmt.Sprintf("did not return within %s", timeout)}
+	}
+}
+
+func checkImplemented(t testing.TB, err error, capability, kind string, action resource.AdmissionAction) {
+	t.Helper()
+	if errors.Is(err, app.ErrNotImplemented) {
+		t.Errorf("%s %s is declared in the manifest for %s, but is not implemented by the app", action, capability, kind)
+	} else if err != nil && !isAdmissionDenial(err) {
+		t.Errorf("%s %s for %s: %v", action, capability, kind, err)
+	}
+}
+
+// isAdmissionDenial returns true for errors which are a response to the request (a denial of it), rather than a failure
+// to respond, which are the panic and timeout errors returned by call
+func isAdmissionDenial(err error) bool {
+	var failure *callFailure
+	return !errors.As(err, &failure)
+}
+
+func actions(operations []app.AdmissionOperation) []resource.AdmissionAction {
+	res := make([]resource.AdmissionAction, 0, len(operations))
+	for _, op := range operations {
+		if op == app.AdmissionOperationAny {
+			return []resource.AdmissionAction{resource.AdmissionActionCreate, resource.AdmissionActionUpdate, resource.AdmissionActionDelete}
+		}
+		res = append(res, resource.AdmissionAction(op))
+	}
+	return res
+}
+
+func managedKinds(a app.App) map[string]resource.Kind {
+	kinds := make(map[string]resource.Kind)
+	for _, kind := range a.ManagedKinds() {
+		kinds[key(kind.Kind(), kind.Version())] = kind
+	}
+	return kinds
+}
+
+func key(kind, version string) string {
+	return fmt.Sprintf("%s/%s", kind, version)
+}
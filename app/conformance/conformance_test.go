@@ -0,0 +1,323 @@
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/operator"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// recorder records the errors reported by the checks, instead of failing the test
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (*recorder) Logf(string, ...any) {}
+
+func (*recorder) Helper() {}
+
+type testApp struct {
+	app.App
+	kinds    []resource.Kind
+	validate func(context.Context, *app.AdmissionRequest) error
+	mutate   func(context.Context, *app.AdmissionRequest) (*app.MutatingResponse, error)
+	convert  func(context.Context, app.ConversionRequest) (*app.RawObject, error)
+	route    func(context.Context, *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error)
+}
+
+func (a *testApp) ManagedKinds() []resource.Kind {
+	return a.kinds
+}
+
+func (a *testApp) Validate(ctx context.Context, req *app.AdmissionRequest) error {
+	return a.validate(ctx, req)
+}
+
+func (a *testApp) Mutate(ctx context.Context, req *app.AdmissionRequest) (*app.MutatingResponse, error) {
+	return a.mutate(ctx, req)
+}
+
+func (*testApp) Default(context.Context, *app.AdmissionRequest) (*app.MutatingResponse, error) {
+	return nil, app.ErrNotImplemented
+}
+
+func (a *testApp) Convert(ctx context.Context, req app.ConversionRequest) (*app.RawObject, error) {
+	return a.convert(ctx, req)
+}
+
+func (a *testApp) CallResourceCustomRoute(ctx context.Context, req *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error) {
+	return a.route(ctx, req)
+}
+
+func testKind(version string) resource.Kind {
+	return resource.Kind{
+		Schema: resource.NewSimpleSchema("test.ext.grafana.com", version, &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("Foo")),
+		Codecs: map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()},
+	}
+}
+
+func testObject(version string) resource.Object {
+	return &resource.UntypedObject{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "test.ext.grafana.com/" + version,
+			Kind:       "Foo",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+		Spec: map[string]any{"foo": "bar", "count": float64(1)},
+	}
+}
+
+// convertAPIVersion converts the raw object by changing its apiVersion, dropping the fields in drop from its spec
+func convertAPIVersion(req app.ConversionRequest, drop ...string) (*app.RawObject, error) {
+	obj := map[string]any{}
+	if err := json.Unmarshal(req.Raw.Raw, &obj); err != nil {
+		return nil, err
+	}
+	obj["apiVersion"] = req.TargetGVK.GroupVersion().String()
+	if spec, ok := obj["spec"].(map[string]any); ok {
+		for _, field := range drop {
+			delete(spec, field)
+		}
+	}
+	raw, err := json.Marshal(obj)
+	return &app.RawObject{Raw: raw, Encoding: resource.KindEncodingJSON}, err
+}
+
+func testConfig() Config {
+	return Config{
+		Manifest: app.ManifestData{
+			AppName: "test",
+			Group:   "test.ext.grafana.com",
+			Kinds: []app.ManifestKind{{
+				Kind:       "Foo",
+				Conversion: true,
+				Versions: []app.ManifestKindVersion{{
+					Name: "v1",
+					Admission: &app.AdmissionCapabilities{
+						Validation: &app.ValidationCapability{Operations: []app.AdmissionOperation{app.AdmissionOperationAny}},
+					},
+				}, {
+					Name: "v2",
+					Admission: &app.AdmissionCapabilities{
+						Validation: &app.ValidationCapability{Operations: []app.AdmissionOperation{app.AdmissionOperationCreate}},
+						Mutation:   &app.MutationCapability{Operations: []app.AdmissionOperation{app.AdmissionOperationCreate}},
+					},
+				}},
+			}},
+		},
+		Objects: map[string]resource.Object{
+			"Foo/v1": testObject("v1"),
+			"Foo/v2": testObject("v2"),
+		},
+		CustomRoutes: []CustomRoute{{
+			Kind:    "Foo",
+			Version: "v1",
+			Path:    "status",
+			ResponseSchema: openapi3.NewObjectSchema().
+				WithProperty("ready", openapi3.NewBoolSchema()).
+				WithRequired([]string{"ready"}),
+		}},
+		Timeout: time.Second,
+	}
+}
+
+func conformingApp() *testApp {
+	return &testApp{
+		kinds: []resource.Kind{testKind("v1"), testKind("v2")},
+		validate: func(_ context.Context, req *app.AdmissionRequest) error {
+			if req.Action == resource.AdmissionActionDelete {
+				// Denials are responses, and are allowed
+				return errors.New("deletion is not allowed")
+			}
+			return nil
+		},
+		mutate: func(_ context.Context, req *app.AdmissionRequest) (*app.MutatingResponse, error) {
+			return &app.MutatingResponse{UpdatedObject: req.Object}, nil
+		},
+		convert: func(_ context.Context, req app.ConversionRequest) (*app.RawObject, error) {
+			return convertAPIVersion(req)
+		},
+		route: func(_ context.Context, req *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error) {
+			if req.SubresourcePath != "status" || req.ResourceIdentifier.Name != "foo" {
+				return nil, app.ErrCustomRouteNotFound
+			}
+			return &app.ResourceCustomRouteResponse{StatusCode: http.StatusOK, Body: []byte(`{"ready":true}`)}, nil
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	calls := atomic.Int64{}
+	cfg := testConfig()
+	cfg.Reconcilers = map[string]operator.Reconciler{
+		"Foo/v1": &operator.TypedReconciler[*resource.UntypedObject]{
+			ReconcileFunc: func(_ context.Context, req operator.TypedReconcileRequest[*resource.UntypedObject]) (operator.ReconcileResult, error) {
+				assert.Equal(t, operator.ReconcileActionResynced, req.Action)
+				calls.Add(1)
+				return operator.ReconcileResult{}, nil
+			},
+		},
+	}
+	cfg.ResyncCount = 50
+	Run(t, conformingApp(), cfg)
+	assert.Equal(t, int64(50), calls.Load())
+}
+
+func TestChecks_Failures(t *testing.T) {
+	t.Run("missing kind", func(t *testing.T) {
+		a := conformingApp()
+		a.kinds = a.kinds[:1]
+		r := &recorder{TB: t}
+		ManagedKinds(r, a, testConfig())
+		assert.Equal(t, []string{"Foo/v2 is in the manifest, but is not managed by the app"}, r.errors)
+	})
+
+	t.Run("admission not implemented", func(t *testing.T) {
+		a := conformingApp()
+		a.mutate = func(context.Context, *app.AdmissionRequest) (*app.MutatingResponse, error) {
+			return nil, app.ErrNotImplemented
+		}
+		r := &recorder{TB: t}
+		Admission(r, a, testConfig())
+		assert.Equal(t, []string{"CREATE mutation is declared in the manifest for Foo/v2, but is not implemented by the app"}, r.errors)
+	})
+
+	t.Run("admission panics and times out", func(t *testing.T) {
+		a := conformingApp()
+		a.validate = func(ctx context.Context, req *app.AdmissionRequest) error {
+			if req.Version == "v1" {
+				panic("oops")
+			}
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}
+		cfg := testConfig()
+		cfg.Timeout = 20 * time.Millisecond
+		cfg.Manifest.Kinds[0].Versions[0].Admission.Validation.Operations = []app.AdmissionOperation{app.AdmissionOperationCreate}
+		r := &recorder{TB: t}
+		Admission(r, a, cfg)
+		assert.Equal(t, []string{
+			"CREATE validation for Foo/v1: panicked: oops",
+			"CREATE validation for Foo/v2: did not return within 20ms",
+		}, r.errors)
+	})
+
+	t.Run("lossy conversion", func(t *testing.T) {
+		a := conformingApp()
+		a.convert = func(_ context.Context, req app.ConversionRequest) (*app.RawObject, error) {
+			if req.TargetGVK.Version == "v2" {
+				return convertAPIVersion(req, "count")
+			}
+			return convertAPIVersion(req)
+		}
+		r := &recorder{TB: t}
+		Conversion(r, a, testConfig())
+		// The field is dropped whichever version the round trip starts from
+		require.Len(t, r.errors, 2)
+		assert.Contains(t, r.errors[0], "conversion of Foo/v1 to v2: spec changed after converting back")
+		assert.Contains(t, r.errors[1], "conversion of Foo/v2 to v1: spec changed after converting back")
+	})
+
+	t.Run("custom routes", func(t *testing.T) {
+		a := conformingApp()
+		a.route = func(_ context.Context, req *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error) {
+			if req.Method == http.MethodPost {
+				return nil, app.ErrCustomRouteNotFound
+			}
+			return &app.ResourceCustomRouteResponse{StatusCode: http.StatusOK, Body: []byte(`{"ready":"yes"}`)}, nil
+		}
+		cfg := testConfig()
+		cfg.CustomRoutes = append(cfg.CustomRoutes, CustomRoute{Kind: "Foo", Version: "v2", Path: "restart", Method: http.MethodPost})
+		r := &recorder{TB: t}
+		CustomRoutes(r, a, cfg)
+		require.Len(t, r.errors, 2)
+		assert.Contains(t, r.errors[0], "Foo/v1 GET status: response body does not match the response schema")
+		assert.Equal(t, "Foo/v2 POST restart: route is not implemented: custom route not found", r.errors[1])
+	})
+
+	t.Run("manifest custom routes", func(t *testing.T) {
+		a := conformingApp()
+		a.route = func(_ context.Context, req *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error) {
+			switch req.SubresourcePath {
+			case "rollback":
+				if string(req.Body) != `{"revision":1}` {
+					return &app.ResourceCustomRouteResponse{StatusCode: http.StatusInternalServerError}, nil
+				}
+				return &app.ResourceCustomRouteResponse{StatusCode: http.StatusOK, Body: []byte(`{}`)}, nil
+			case "history":
+				return &app.ResourceCustomRouteResponse{StatusCode: http.StatusOK, Body: []byte(`{"revisions":"1"}`)}, nil
+			}
+			return nil, app.ErrCustomRouteNotFound
+		}
+		cfg := testConfig()
+		cfg.CustomRoutes = []CustomRoute{{
+			Kind:    "Foo",
+			Version: "v2",
+			Path:    "rollback",
+			Method:  http.MethodPost,
+			Body:    []byte(`{"revision":1}`),
+		}}
+		cfg.Manifest.Kinds[0].Versions[1].CustomRoutes = []app.CustomRoute{{
+			Path:   "history",
+			Method: http.MethodGet,
+			Name:   "History",
+			ResponseSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"revisions": map[string]any{"type": "integer"},
+				},
+			},
+		}, {
+			Path:   "rollback",
+			Method: http.MethodPost,
+			Name:   "Rollback",
+		}, {
+			Path:   "restart",
+			Method: http.MethodPost,
+			Name:   "Restart",
+		}}
+		r := &recorder{TB: t}
+		CustomRoutes(r, a, cfg)
+		// The declared routes are checked without having to be configured, and the configured rollback route
+		// replaces the declared one, so that it is sent a body
+		require.Len(t, r.errors, 2)
+		assert.Contains(t, r.errors[0], "Foo/v2 GET history: response body does not match the response schema")
+		assert.Equal(t, "Foo/v2 POST restart: route is not implemented: custom route not found", r.errors[1])
+	})
+
+	t.Run("resync errors", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.Watchers = map[string]operator.ResourceWatcher{
+			"Foo/v2": &operator.SimpleWatcher{
+				UpdateFunc: func(context.Context, resource.Object, resource.Object) error {
+					return errors.New("resync failed")
+				},
+			},
+		}
+		r := &recorder{TB: t}
+		Resync(r, conformingApp(), cfg)
+		assert.Equal(t, []string{"Foo/v2 watcher: resync failed"}, r.errors)
+	})
+}
@@ -0,0 +1,226 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// Conversion converts src, an object of one version of a kind, into dst, a zero-value object of another version.
+// The metadata of src is copied to dst before Conversion is called, so a Conversion only needs to set
+// fields outside the metadata, such as the spec and status.
+type Conversion func(ctx context.Context, src resource.Object, dst resource.Object) error
+
+// VersionConverter converts objects of a kind between any of its versions, using conversions registered
+// for pairs of versions. A conversion between two versions without a registered Conversion is done by chaining
+// the conversions along the shortest path between them (for example, v1 -> v2 -> v3 with conversions
+// for v1 -> v2 and v2 -> v3). VersionConverter implements the Convert method of App, so an App can delegate
+// conversion requests for the kind to it.
+// It must be created with NewVersionConverter to be valid.
+type VersionConverter struct {
+	groupKind   schema.GroupKind
+	kinds       map[string]resource.Kind
+	conversions map[string]map[string]Conversion
+}
+
+// NewVersionConverter creates a new VersionConverter for the provided kinds, which must be versions
+// of the same group and kind. Conversions between the versions are registered with AddConversion
+// or AddTypedConversion.
+func NewVersionConverter(kinds ...resource.Kind) (*VersionConverter, error) {
+	if len(kinds) == 0 {
+		return nil, errors.New("at least one kind must be provided")
+	}
+	c := &VersionConverter{
+		groupKind: schema.GroupKind{
+			Group: kinds[0].Group(),
+			Kind:  kinds[0].Kind(),
+		},
+		kinds:       make(map[string]resource.Kind),
+		conversions: make(map[string]map[string]Conversion),
+	}
+	for _, kind := range kinds {
+		if kind.Group() != c.groupKind.Group || kind.Kind() != c.groupKind.Kind {
+			return nil, fmt.Errorf("kind %s.%s does not match %s", kind.Kind(), kind.Group(), c.groupKind.String())
+		}
+		if _, ok := c.kinds[kind.Version()]; ok {
+			return nil, fmt.Errorf("version %s of %s provided more than once", kind.Version(), c.groupKind.String())
+		}
+		c.kinds[kind.Version()] = kind
+	}
+	return c, nil
+}
+
+// GroupKind returns the group and kind converted by the VersionConverter
+func (c *VersionConverter) GroupKind() schema.GroupKind {
+	return c.groupKind
+}
+
+// AddConversion registers a Conversion from srcVersion to dstVersion. A conversion in the opposite direction
+// must be registered separately. It returns an error if either version was not provided to NewVersionConverter.
+func (c *VersionConverter) AddConversion(srcVersion, dstVersion string, conversion Conversion) error {
+	if conversion == nil {
+		return errors.New("conversion cannot be nil")
+	}
+	if _, ok := c.kinds[srcVersion]; !ok {
+		return fmt.Errorf("unknown version %s of %s", srcVersion, c.groupKind.String())
+	}
+	if _, ok := c.kinds[dstVersion]; !ok {
+		return fmt.Errorf("unknown version %s of %s", dstVersion, c.groupKind.String())
+	}
+	if srcVersion == dstVersion {
+		return errors.New("source and destination versions must be different")
+	}
+	if _, ok := c.conversions[srcVersion]; !ok {
+		c.conversions[srcVersion] = make(map[string]Conversion)
+	}
+	c.conversions[srcVersion][dstVersion] = conversion
+	return nil
+}
+
+// AddTypedConversion registers a conversion function between the Go types of srcVersion and dstVersion
+// with the VersionConverter. It returns an error if the ZeroValue of either version's kind is not of the
+// expected type.
+func AddTypedConversion[S resource.Object, D resource.Object](
+	c *VersionConverter, srcVersion, dstVersion string, conversion func(ctx context.Context, src S, dst D) error,
+) error {
+	if conversion == nil {
+		return errors.New("conversion cannot be nil")
+	}
+	if kind, ok := c.kinds[srcVersion]; ok {
+		if _, ok := kind.ZeroValue().(S); !ok {
+			return fmt.Errorf("version %s of %s is not of type %T", srcVersion, c.groupKind.String(), *new(S))
+		}
+	}
+	if kind, ok := c.kinds[dstVersion]; ok {
+		if _, ok := kind.ZeroValue().(D); !ok {
+			return fmt.Errorf("version %s of %s is not of type %T", dstVersion, c.groupKind.String(), *new(D))
+		}
+	}
+	return c.AddConversion(srcVersion, dstVersion, func(ctx context.Context, src resource.Object, dst resource.Object) error {
+		return conversion(ctx, src.(S), dst.(D))
+	})
+}
+
+// Path returns the versions an object is converted through to get from srcVersion to dstVersion,
+// including both srcVersion and dstVersion. It returns an error if there is no chain of registered
+// conversions between the two versions.
+func (c *VersionConverter) Path(srcVersion, dstVersion string) ([]string, error) {
+	if _, ok := c.kinds[srcVersion]; !ok {
+		return nil, fmt.Errorf("unknown version %s of %s", srcVersion, c.groupKind.String())
+	}
+	if _, ok := c.kinds[dstVersion]; !ok {
+		return nil, fmt.Errorf("unknown version %s of %s", dstVersion, c.groupKind.String())
+	}
+	// Breadth-first search, so the path with the fewest conversions is used.
+	// Versions are visited in sorted order to keep the path deterministic when there are several of the same length.
+	previous := map[string]string{
+		srcVersion: "",
+	}
+	queue := []string{srcVersion}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == dstVersion {
+			path := []string{current}
+			for current != srcVersion {
+				current = previous[current]
+				path = append(path, current)
+			}
+			slices.Reverse(path)
+			return path, nil
+		}
+		next := make([]string, 0, len(c.conversions[current]))
+		for v := range c.conversions[current] {
+			next = append(next, v)
+		}
+		slices.Sort(next)
+		for _, v := range next {
+			if _, ok := previous[v]; ok {
+				continue
+			}
+			previous[v] = current
+			queue = append(queue, v)
+		}
+	}
+	return nil, fmt.Errorf("no conversion path from %s to %s for %s", srcVersion, dstVersion, c.groupKind.String())
+}
+
+// Convert converts the object in the ConversionRequest to the target version, chaining registered conversions
+// as required. If req.Raw.Object is set, it is used as the source object instead of decoding req.Raw.Raw.
+func (c *VersionConverter) Convert(ctx context.Context, req ConversionRequest) (*RawObject, error) {
+	if req.SourceGVK.GroupKind() != c.groupKind || req.TargetGVK.GroupKind() != c.groupKind {
+		return nil, fmt.Errorf("cannot convert %s to %s with a converter for %s",
+			req.SourceGVK.GroupKind().String(), req.TargetGVK.GroupKind().String(), c.groupKind.String())
+	}
+	path, err := c.Path(req.SourceGVK.Version, req.TargetGVK.Version)
+	if err != nil {
+		return nil, err
+	}
+	encoding := req.Raw.Encoding
+	if encoding == "" {
+		encoding = resource.KindEncodingJSON
+	}
+	srcKind := c.kinds[path[0]]
+	obj := req.Raw.Object
+	if obj == nil {
+		obj, err = srcKind.Read(bytes.NewReader(req.Raw.Raw), encoding)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s object: %w", srcKind.GroupVersionKind().String(), err)
+		}
+	}
+	for i := 1; i < len(path); i++ {
+		dstKind := c.kinds[path[i]]
+		dst := dstKind.ZeroValue()
+		copyObjectMeta(obj, dst)
+		dst.SetGroupVersionKind(dstKind.GroupVersionKind())
+		if err = c.conversions[path[i-1]][path[i]](ctx, obj, dst); err != nil {
+			return nil, fmt.Errorf("unable to convert from %s to %s: %w", path[i-1], path[i], err)
+		}
+		obj = dst
+	}
+	if len(path) == 1 && req.Raw.Raw != nil {
+		// Nothing to convert
+		return &RawObject{
+			Raw:      req.Raw.Raw,
+			Object:   obj,
+			Encoding: encoding,
+		}, nil
+	}
+	dstKind := c.kinds[path[len(path)-1]]
+	buf := bytes.Buffer{}
+	if err = dstKind.Write(obj, &buf, encoding); err != nil {
+		return nil, fmt.Errorf("unable to write %s object: %w", dstKind.GroupVersionKind().String(), err)
+	}
+	return &RawObject{
+		Raw:      buf.Bytes(),
+		Object:   obj,
+		Encoding: encoding,
+	}, nil
+}
+
+// copyObjectMeta copies all kubernetes metadata from src to dst, so that changes to dst's maps and slices don't modify src
+func copyObjectMeta(src metav1.Object, dst metav1.Object) {
+	dst.SetName(src.GetName())
+	dst.SetGenerateName(src.GetGenerateName())
+	dst.SetNamespace(src.GetNamespace())
+	dst.SetSelfLink(src.GetSelfLink())
+	dst.SetUID(src.GetUID())
+	dst.SetResourceVersion(src.GetResourceVersion())
+	dst.SetGeneration(src.GetGeneration())
+	dst.SetCreationTimestamp(src.GetCreationTimestamp())
+	dst.SetDeletionTimestamp(src.GetDeletionTimestamp())
+	dst.SetDeletionGracePeriodSeconds(src.GetDeletionGracePeriodSeconds())
+	dst.SetLabels(maps.Clone(src.GetLabels()))
+	dst.SetAnnotations(maps.Clone(src.GetAnnotations()))
+	dst.SetOwnerReferences(slices.Clone(src.GetOwnerReferences()))
+	dst.SetFinalizers(slices.Clone(src.GetFinalizers()))
+	dst.SetManagedFields(slices.Clone(src.GetManagedFields()))
+}
@@ -0,0 +1,213 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+type conversionTestV1Spec struct {
+	Title string `json:"title"`
+}
+
+type conversionTestV2Spec struct {
+	Name string `json:"name"`
+}
+
+type conversionTestV3Spec struct {
+	DisplayName string `json:"displayName"`
+}
+
+type (
+	conversionTestV1 = resource.TypedSpecObject[conversionTestV1Spec]
+	conversionTestV2 = resource.TypedSpecObject[conversionTestV2Spec]
+	conversionTestV3 = resource.TypedSpecObject[conversionTestV3Spec]
+)
+
+func conversionTestKind[T resource.Object](version string, zero T) resource.Kind {
+	return resource.Kind{
+		Schema: resource.NewSimpleSchema("test.grafana.app", version, zero, &resource.TypedList[T]{}, resource.WithKind("Foo")),
+		Codecs: map[resource.KindEncoding]resource.Codec{
+			resource.KindEncodingJSON: resource.NewJSONCodec(),
+		},
+	}
+}
+
+func newTestVersionConverter(t *testing.T) *VersionConverter {
+	c, err := NewVersionConverter(
+		conversionTestKind("v1", &conversionTestV1{}),
+		conversionTestKind("v2", &conversionTestV2{}),
+		conversionTestKind("v3", &conversionTestV3{}),
+	)
+	require.Nil(t, err)
+	require.Nil(t, AddTypedConversion(c, "v1", "v2", func(_ context.Context, src *conversionTestV1, dst *conversionTestV2) error {
+		dst.Spec.Name = src.Spec.Title
+		return nil
+	}))
+	require.Nil(t, AddTypedConversion(c, "v2", "v1", func(_ context.Context, src *conversionTestV2, dst *conversionTestV1) error {
+		dst.Spec.Title = src.Spec.Name
+		return nil
+	}))
+	require.Nil(t, AddTypedConversion(c, "v2", "v3", func(_ context.Context, src *conversionTestV2, dst *conversionTestV3) error {
+		if src.Spec.Name == "" {
+			return errors.New("name is required")
+		}
+		dst.Spec.DisplayName = src.Spec.Name
+		return nil
+	}))
+	require.Nil(t, AddTypedConversion(c, "v3", "v2", func(_ context.Context, src *conversionTestV3, dst *conversionTestV2) error {
+		dst.Spec.Name = src.Spec.DisplayName
+		return nil
+	}))
+	return c
+}
+
+func TestNewVersionConverter(t *testing.T) {
+	t.Run("no kinds", func(t *testing.T) {
+		_, err := NewVersionConverter()
+		assert.Equal(t, errors.New("at least one kind must be provided"), err)
+	})
+
+	t.Run("mismatched kinds", func(t *testing.T) {
+		bar := resource.Kind{
+			Schema: resource.NewSimpleSchema("test.grafana.app", "v2", &conversionTestV2{}, &resource.TypedList[*conversionTestV2]{}, resource.WithKind("Bar")),
+		}
+		_, err := NewVersionConverter(conversionTestKind("v1", &conversionTestV1{}), bar)
+		assert.Equal(t, errors.New("kind Bar.test.grafana.app does not match Foo.test.grafana.app"), err)
+	})
+
+	t.Run("duplicate version", func(t *testing.T) {
+		_, err := NewVersionConverter(conversionTestKind("v1", &conversionTestV1{}), conversionTestKind("v1", &conversionTestV1{}))
+		assert.Equal(t, errors.New("version v1 of Foo.test.grafana.app provided more than once"), err)
+	})
+}
+
+func TestVersionConverter_AddConversion(t *testing.T) {
+	c, err := NewVersionConverter(conversionTestKind("v1", &conversionTestV1{}), conversionTestKind("v2", &conversionTestV2{}))
+	require.Nil(t, err)
+	noop := func(context.Context, resource.Object, resource.Object) error { return nil }
+	assert.Equal(t, errors.New("conversion cannot be nil"), c.AddConversion("v1", "v2", nil))
+	assert.Equal(t, errors.New("unknown version v3 of Foo.test.grafana.app"), c.AddConversion("v1", "v3", noop))
+	assert.Equal(t, errors.New("source and destination versions must be different"), c.AddConversion("v1", "v1", noop))
+	err = AddTypedConversion(c, "v1", "v2", func(context.Context, *conversionTestV2, *conversionTestV2) error { return nil })
+	assert.Equal(t, errors.New("version v1 of Foo.test.grafana.app is not of type *resource.TypedSpecObject[github.com/grafana/grafana-app-sdk/app.conversionTestV2Spec]"), err)
+}
+
+func TestVersionConverter_Path(t *testing.T) {
+	c := newTestVersionConverter(t)
+	tests := []struct {
+		src, dst string
+		path     []string
+		err      error
+	}{
+		{"v1", "v1", []string{"v1"}, nil},
+		{"v1", "v2", []string{"v1", "v2"}, nil},
+		{"v1", "v3", []string{"v1", "v2", "v3"}, nil},
+		{"v3", "v1", []string{"v3", "v2", "v1"}, nil},
+		{"v1", "v4", nil, errors.New("unknown version v4 of Foo.test.grafana.app")},
+	}
+	for _, test := range tests {
+		path, err := c.Path(test.src, test.dst)
+		assert.Equal(t, test.err, err)
+		assert.Equal(t, test.path, path)
+	}
+
+	t.Run("no path", func(t *testing.T) {
+		c, err := NewVersionConverter(conversionTestKind("v1", &conversionTestV1{}), conversionTestKind("v2", &conversionTestV2{}))
+		require.Nil(t, err)
+		_, err = c.Path("v1", "v2")
+		assert.Equal(t, errors.New("no conversion path from v1 to v2 for Foo.test.grafana.app"), err)
+	})
+}
+
+func TestVersionConverter_Convert(t *testing.T) {
+	c := newTestVersionConverter(t)
+	gvk := func(version string) schema.GroupVersionKind {
+		return schema.GroupVersionKind{Group: "test.grafana.app", Version: version, Kind: "Foo"}
+	}
+	src := &conversionTestV1{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "foo",
+			Namespace:       "default",
+			ResourceVersion: "12",
+			Labels:          map[string]string{"a": "b"},
+		},
+		Spec: conversionTestV1Spec{
+			Title: "bar",
+		},
+	}
+	src.SetGroupVersionKind(gvk("v1"))
+	raw, err := json.Marshal(src)
+	require.Nil(t, err)
+
+	t.Run("chained", func(t *testing.T) {
+		res, err := c.Convert(context.Background(), ConversionRequest{
+			SourceGVK: gvk("v1"),
+			TargetGVK: gvk("v3"),
+			Raw:       RawObject{Raw: raw},
+		})
+		require.Nil(t, err)
+		assert.Equal(t, resource.KindEncodingJSON, res.Encoding)
+		converted := &conversionTestV3{}
+		require.Nil(t, json.Unmarshal(res.Raw, converted))
+		assert.Equal(t, "test.grafana.app/v3", converted.APIVersion)
+		assert.Equal(t, "Foo", converted.Kind)
+		assert.Equal(t, "foo", converted.GetName())
+		assert.Equal(t, "default", converted.GetNamespace())
+		assert.Equal(t, "12", converted.GetResourceVersion())
+		assert.Equal(t, map[string]string{"a": "b"}, converted.GetLabels())
+		assert.Equal(t, conversionTestV3Spec{DisplayName: "bar"}, converted.Spec)
+		assert.Equal(t, converted.Spec, res.Object.(*conversionTestV3).Spec)
+	})
+
+	t.Run("from object", func(t *testing.T) {
+		res, err := c.Convert(context.Background(), ConversionRequest{
+			SourceGVK: gvk("v1"),
+			TargetGVK: gvk("v2"),
+			Raw:       RawObject{Object: src},
+		})
+		require.Nil(t, err)
+		assert.Equal(t, conversionTestV2Spec{Name: "bar"}, res.Object.(*conversionTestV2).Spec)
+		// Metadata is copied, so the source object isn't modified
+		res.Object.SetLabels(map[string]string{"c": "d"})
+		assert.Equal(t, map[string]string{"a": "b"}, src.GetLabels())
+	})
+
+	t.Run("same version", func(t *testing.T) {
+		res, err := c.Convert(context.Background(), ConversionRequest{
+			SourceGVK: gvk("v1"),
+			TargetGVK: gvk("v1"),
+			Raw:       RawObject{Raw: raw},
+		})
+		require.Nil(t, err)
+		assert.Equal(t, raw, res.Raw)
+	})
+
+	t.Run("conversion error", func(t *testing.T) {
+		empty, err := json.Marshal(&conversionTestV1{})
+		require.Nil(t, err)
+		_, err = c.Convert(context.Background(), ConversionRequest{
+			SourceGVK: gvk("v1"),
+			TargetGVK: gvk("v3"),
+			Raw:       RawObject{Raw: empty},
+		})
+		assert.Equal(t, "unable to convert from v2 to v3: name is required", err.Error())
+	})
+
+	t.Run("wrong kind", func(t *testing.T) {
+		_, err := c.Convert(context.Background(), ConversionRequest{
+			SourceGVK: schema.GroupVersionKind{Group: "test.grafana.app", Version: "v1", Kind: "Bar"},
+			TargetGVK: schema.GroupVersionKind{Group: "test.grafana.app", Version: "v2", Kind: "Bar"},
+			Raw:       RawObject{Raw: raw},
+		})
+		assert.Equal(t, "cannot convert Bar.test.grafana.app to Bar.test.grafana.app with a converter for Foo.test.grafana.app", err.Error())
+	})
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// TypedCustomRouteRequest is a request to a custom route, with the JSON request body decoded into Body
+type TypedCustomRouteRequest[Req any] struct {
+	ResourceIdentifier resource.FullIdentifier
+	SubresourcePath    string
+	Method             string
+	Headers            http.Header
+	// Body is the decoded request body, which is the zero value of Req if the request has no body
+	Body Req
+}
+
+// TypedCustomRouteResponse is a response to a custom route, with a Body which is encoded as JSON
+type TypedCustomRouteResponse[Resp any] struct {
+	Headers http.Header
+	// StatusCode is the status code of the response. If zero, http.StatusOK is used.
+	StatusCode int
+	Body       Resp
+}
+
+// TypedCustomRouteHandler handles requests to a custom route with typed request and response bodies.
+// A handler type is generated for each of the custom routes declared for a kind version in CUE,
+// with the go types generated from the schemas of the route.
+type TypedCustomRouteHandler[Req, Resp any] func(ctx context.Context, req *TypedCustomRouteRequest[Req]) (*TypedCustomRouteResponse[Resp], error)
+
+// NewTypedCustomRouteHandler returns a handler for ResourceCustomRouteRequests, which can be used as a simple.AppCustomRouteHandler.
+// The handler decodes the JSON request body into a Req and calls handler, then encodes the Body of the response as JSON.
+// If the request body cannot be decoded, it returns a BadRequest error without calling handler.
+// A nil response from handler is returned as an empty 200 response.
+func NewTypedCustomRouteHandler[Req, Resp any](handler TypedCustomRouteHandler[Req, Resp]) func(context.Context, *ResourceCustomRouteRequest) (*ResourceCustomRouteResponse, error) {
+	return func(ctx context.Context, req *ResourceCustomRouteRequest) (*ResourceCustomRouteResponse, error) {
+		typed := &TypedCustomRouteRequest[Req]{
+			ResourceIdentifier: req.ResourceIdentifier,
+			SubresourcePath:    req.SubresourcePath,
+			Method:             req.Method,
+			Headers:            req.Headers,
+		}
+		if len(req.Body) > 0 {
+			if err := json.Unmarshal(req.Body, &typed.Body); err != nil {
+				return nil, apierrors.NewBadRequest(fmt.Sprintf("unable to decode request body: %s", err.Error()))
+			}
+		}
+		resp, err := handler(ctx, typed)
+		if err != nil {
+			return nil, err
+		}
+		if resp == nil {
+			return &ResourceCustomRouteResponse{
+				StatusCode: http.StatusOK,
+			}, nil
+		}
+		body, err := json.Marshal(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode response body: %w", err)
+		}
+		headers := resp.Headers
+		if headers == nil {
+			headers = make(http.Header)
+		}
+		if headers.Get("Content-Type") == "" {
+			headers.Set("Content-Type", "application/json")
+		}
+		statusCode := resp.StatusCode
+		if statusCode == 0 {
+			statusCode = http.StatusOK
+		}
+		return &ResourceCustomRouteResponse{
+			Headers:    headers,
+			StatusCode: statusCode,
+			Body:       body,
+		}, nil
+	}
+}
+
+// CustomRoutesOpenAPIRoutePath is the subresource path of the app-level custom route which returns the OpenAPI document
+// of the custom routes of an app's kinds (see ManifestData.CustomRoutesOpenAPI3).
+// App-level routes are requested with a ResourceCustomRouteRequest whose ResourceIdentifier only sets the app's Group.
+const CustomRoutesOpenAPIRoutePath = "openapi"
+
+// NewCustomRoutesOpenAPIRouteHandler returns a handler for the CustomRoutesOpenAPIRoutePath route, which responds with
+// the JSON-encoded OpenAPI document of the custom routes in manifest, using plurals as the plural name of each kind.
+// The response body is encoded once, when the handler is created.
+func NewCustomRoutesOpenAPIRouteHandler(manifest ManifestData, plurals map[string]string) (func(context.Context, *ResourceCustomRouteRequest) (*ResourceCustomRouteResponse, error), error) {
+	doc, err := manifest.CustomRoutesOpenAPI3(plurals)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal OpenAPI document: %w", err)
+	}
+	return newJSONRouteHandler(body), nil
+}
+
+// newJSONRouteHandler returns a handler for a route which responds to GET and HEAD requests with the JSON body,
+// and to other methods with 405 Method Not Allowed
+func newJSONRouteHandler(body []byte) func(context.Context, *ResourceCustomRouteRequest) (*ResourceCustomRouteResponse, error) {
+	return func(_ context.Context, req *ResourceCustomRouteRequest) (*ResourceCustomRouteResponse, error) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			return &ResourceCustomRouteResponse{
+				StatusCode: http.StatusMethodNotAllowed,
+			}, nil
+		}
+		return &ResourceCustomRouteResponse{
+			Headers:    http.Header{"Content-Type": []string{"application/json"}},
+			StatusCode: http.StatusOK,
+			Body:       body,
+		}, nil
+	}
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+type testRouteRequest struct {
+	Reason string `json:"reason"`
+}
+
+type testRouteResponse struct {
+	Reconciled bool `json:"reconciled"`
+}
+
+func TestNewTypedCustomRouteHandler(t *testing.T) {
+	identifier := resource.FullIdentifier{Namespace: "default", Name: "foo", Kind: "Foo"}
+
+	t.Run("success", func(t *testing.T) {
+		handler := NewTypedCustomRouteHandler(func(_ context.Context, req *TypedCustomRouteRequest[testRouteRequest]) (*TypedCustomRouteResponse[testRouteResponse], error) {
+			assert.Equal(t, identifier, req.ResourceIdentifier)
+			assert.Equal(t, "reconcile", req.SubresourcePath)
+			assert.Equal(t, http.MethodPost, req.Method)
+			assert.Equal(t, "test", req.Body.Reason)
+			return &TypedCustomRouteResponse[testRouteResponse]{
+				StatusCode: http.StatusAccepted,
+				Body:       testRouteResponse{Reconciled: true},
+			}, nil
+		})
+		resp, err := handler(context.Background(), &ResourceCustomRouteRequest{
+			ResourceIdentifier: identifier,
+			SubresourcePath:    "reconcile",
+			Method:             http.MethodPost,
+			Body:               []byte(`{"reason":"test"}`),
+		})
+		require.Nil(t, err)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Headers.Get("Content-Type"))
+		assert.JSONEq(t, `{"reconciled":true}`, string(resp.Body))
+	})
+
+	t.Run("no body", func(t *testing.T) {
+		handler := NewTypedCustomRouteHandler(func(_ context.Context, req *TypedCustomRouteRequest[testRouteRequest]) (*TypedCustomRouteResponse[testRouteResponse], error) {
+			assert.Equal(t, testRouteRequest{}, req.Body)
+			return &TypedCustomRouteResponse[testRouteResponse]{}, nil
+		})
+		resp, err := handler(context.Background(), &ResourceCustomRouteRequest{Method: http.MethodGet})
+		require.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.JSONEq(t, `{"reconciled":false}`, string(resp.Body))
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		called := false
+		handler := NewTypedCustomRouteHandler(func(context.Context, *TypedCustomRouteRequest[testRouteRequest]) (*TypedCustomRouteResponse[testRouteResponse], error) {
+			called = true
+			return nil, nil
+		})
+		_, err := handler(context.Background(), &ResourceCustomRouteRequest{Body: []byte(`{"reason":1}`)})
+		assert.True(t, apierrors.IsBadRequest(err))
+		assert.False(t, called)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		handler := NewTypedCustomRouteHandler(func(context.Context, *TypedCustomRouteRequest[testRouteRequest]) (*TypedCustomRouteResponse[testRouteResponse], error) {
+			return nil, ErrCustomRouteNotFound
+		})
+		_, err := handler(context.Background(), &ResourceCustomRouteRequest{})
+		assert.True(t, errors.Is(err, ErrCustomRouteNotFound))
+	})
+}
+
+func TestNewCustomRoutesOpenAPIRouteHandler(t *testing.T) {
+	manifest := ManifestData{
+		AppName: "test",
+		Group:   "test.grafana.app",
+		Kinds: []ManifestKind{{
+			Kind:  "Foo",
+			Scope: "Namespaced",
+			Versions: []ManifestKindVersion{{
+				Name:         "v1",
+				CustomRoutes: []CustomRoute{{Path: "reconcile", Method: "POST", Name: "Reconcile"}},
+			}},
+		}},
+	}
+	handler, err := NewCustomRoutesOpenAPIRouteHandler(manifest, map[string]string{"Foo": "foos"})
+	require.Nil(t, err)
+	resp, err := handler(context.Background(), &ResourceCustomRouteRequest{
+		ResourceIdentifier: resource.FullIdentifier{Group: manifest.Group},
+		SubresourcePath:    CustomRoutesOpenAPIRoutePath,
+		Method:             http.MethodGet,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Headers.Get("Content-Type"))
+	doc, err := openapi3.NewLoader().LoadFromData(resp.Body)
+	require.Nil(t, err)
+	require.NotNil(t, doc.Paths.Value("/apis/test.grafana.app/v1/namespaces/{namespace}/foos/{name}/reconcile"))
+}
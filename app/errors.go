@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/metrics"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// ErrorClass is the broad category of an error in an ErrorReport, based on what produced it
+type ErrorClass string
+
+const (
+	// ErrorClassInformer is an error from an informer processing an event, such as an object which could not be decoded
+	ErrorClassInformer = ErrorClass("informer")
+	// ErrorClassWatcher is an error returned by a watcher's Add, Update, or Delete
+	ErrorClassWatcher = ErrorClass("watcher")
+	// ErrorClassReconciler is an error returned by a reconciler's Reconcile
+	ErrorClassReconciler = ErrorClass("reconciler")
+	// ErrorClassRunner is an error returned by a Runnable's Run
+	ErrorClassRunner = ErrorClass("runner")
+)
+
+// ErrorReport is a structured report of an error encountered by a component which continues running after the error
+// (for example, a watcher error which will be retried by the InformerController).
+type ErrorReport struct {
+	// Err is the error
+	Err error
+	// Class is the category of the error
+	Class ErrorClass
+	// Component is the name of the component which encountered the error, such as "InformerController"
+	Component string
+	// Kind is the kind of the object being processed when the error was encountered, if any
+	Kind string
+	// Object is the identifier of the object being processed when the error was encountered, if any.
+	// It is empty if the error is not for a specific object.
+	Object resource.Identifier
+}
+
+// ErrorReporter receives reports of errors from informers, controllers, and runners.
+// It is typically used for logging and metrics, as retry logic is handled by the components themselves.
+// ReportError may be called concurrently.
+type ErrorReporter interface {
+	ReportError(ctx context.Context, report ErrorReport)
+}
+
+// ErrorReporterFunc is a function which implements ErrorReporter
+type ErrorReporterFunc func(ctx context.Context, report ErrorReport)
+
+// ReportError calls the function
+func (f ErrorReporterFunc) ReportError(ctx context.Context, report ErrorReport) {
+	f(ctx, report)
+}
+
+// ErrorHandlerReporter returns an ErrorReporter which calls handler with the error from each report.
+// It can be used to pass an existing func(context.Context, error) error handler where an ErrorReporter is expected.
+func ErrorHandlerReporter(handler func(context.Context, error)) ErrorReporter {
+	return ErrorReporterFunc(func(ctx context.Context, report ErrorReport) {
+		handler(ctx, report.Err)
+	})
+}
+
+var _ ErrorReporter = &DefaultErrorReporter{}
+
+// DefaultErrorReporter is an ErrorReporter which logs each error with the logger in the context,
+// and counts errors in a prometheus counter by class, component, and kind.
+// It should be created with NewDefaultErrorReporter.
+type DefaultErrorReporter struct {
+	errors *prometheus.CounterVec
+}
+
+// NewDefaultErrorReporter creates a new DefaultErrorReporter using the provided metrics.Config
+func NewDefaultErrorReporter(cfg metrics.Config) *DefaultErrorReporter {
+	return &DefaultErrorReporter{
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Name:      "reported_errors_total",
+			Help:      "Total number of errors reported by informers, controllers, and runners.",
+		}, []string{"class", "component", "kind"}),
+	}
+}
+
+// ReportError logs the error, and increments the error counter for the report's class, component, and kind
+func (r *DefaultErrorReporter) ReportError(ctx context.Context, report ErrorReport) {
+	if report.Err == nil {
+		return
+	}
+	args := []any{"component", report.Component, "class", report.Class, "error", report.Err}
+	if report.Kind != "" {
+		args = append(args, "kind", report.Kind)
+	}
+	if report.Object.Name != "" {
+		args = append(args, "namespace", report.Object.Namespace, "name", report.Object.Name)
+	}
+	logging.FromContext(ctx).Error(report.Err.Error(), args...)
+	r.errors.WithLabelValues(string(report.Class), report.Component, report.Kind).Inc()
+}
+
+// PrometheusCollectors returns the prometheus metric collectors used by the DefaultErrorReporter
+func (r *DefaultErrorReporter) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{r.errors}
+}
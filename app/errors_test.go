@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/grafana-app-sdk/metrics"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestDefaultErrorReporter(t *testing.T) {
+	r := NewDefaultErrorReporter(metrics.Config{})
+	ctx := context.Background()
+	r.ReportError(ctx, ErrorReport{
+		Err:       errors.New("I AM ERROR"),
+		Class:     ErrorClassWatcher,
+		Component: "InformerController",
+		Kind:      "Foo",
+		Object:    resource.Identifier{Namespace: "ns", Name: "foo"},
+	})
+	r.ReportError(ctx, ErrorReport{
+		Err:       errors.New("JE SUIS ERROR"),
+		Class:     ErrorClassWatcher,
+		Component: "InformerController",
+		Kind:      "Foo",
+	})
+	// Reports without an error are ignored
+	r.ReportError(ctx, ErrorReport{
+		Class:     ErrorClassRunner,
+		Component: "MultiRunner",
+	})
+	assert.Equal(t, float64(2), testutil.ToFloat64(r.errors.WithLabelValues(string(ErrorClassWatcher), "InformerController", "Foo")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(r.errors.WithLabelValues(string(ErrorClassRunner), "MultiRunner", "")))
+	assert.Len(t, r.PrometheusCollectors(), 1)
+}
+
+func TestErrorHandlerReporter(t *testing.T) {
+	rerr := errors.New("I AM ERROR")
+	var handled error
+	ErrorHandlerReporter(func(ctx context.Context, err error) {
+		handled = err
+	}).ReportError(context.Background(), ErrorReport{Err: rerr, Class: ErrorClassRunner})
+	assert.Equal(t, rerr, handled)
+}
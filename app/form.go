@@ -0,0 +1,77 @@
+package app
+
+// FormFieldType is the type of value a FormField holds
+type FormFieldType string
+
+const (
+	FormFieldTypeString  FormFieldType = "string"
+	FormFieldTypeInteger FormFieldType = "integer"
+	FormFieldTypeNumber  FormFieldType = "number"
+	FormFieldTypeBoolean FormFieldType = "boolean"
+	// FormFieldTypeObject is an object with a fixed set of fields, described by FormField.Fields
+	FormFieldTypeObject FormFieldType = "object"
+	// FormFieldTypeArray is a list of values, each described by FormField.Items
+	FormFieldTypeArray FormFieldType = "array"
+	// FormFieldTypeMap is a map of string keys to values, each described by FormField.Items
+	FormFieldTypeMap FormFieldType = "map"
+	// FormFieldTypeAny is an arbitrary JSON value, which should be edited as raw JSON
+	FormFieldTypeAny FormFieldType = "any"
+)
+
+// FormDescriptor describes a create/edit form for a kind version, so that a UI can render the form without
+// interpreting the kind's schema itself. It is generated from the kind's CUE schema by `grafana-app-sdk generate`,
+// and covers the fields of the spec, in the order they are declared in the CUE.
+// Titles, groups, and widget hints can be set on CUE fields with a @form attribute, such as
+// `description: string @form(title="Description", widget="textarea", group="Details")`.
+type FormDescriptor struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	// Title is a human-readable title for the form, which defaults to the kind name
+	Title string `json:"title"`
+	// Groups are the names of the field groups used by Fields (from @form(group=...)), in the order they first appear
+	Groups []string `json:"groups,omitempty"`
+	// Fields are the fields of the spec, in display order
+	Fields []FormField `json:"fields"`
+}
+
+// FormField describes a single field of a FormDescriptor
+type FormField struct {
+	// Path is the dot-separated JSON path of the field in the object, such as "spec.inner.name".
+	// The values of array and map fields have a Path ending in "[]" or "{}".
+	Path string `json:"path"`
+	// Name is the JSON name of the field
+	Name string `json:"name"`
+	// Title is a human-readable title for the field, from @form(title=...), or derived from Name
+	Title string `json:"title"`
+	// Description is the description of the field, from its CUE comment
+	Description string        `json:"description,omitempty"`
+	Type        FormFieldType `json:"type"`
+	// Format is the OpenAPI format of the field, such as "date-time", if it has one
+	Format   string `json:"format,omitempty"`
+	Required bool   `json:"required,omitempty"`
+	// Default is the default value of the field, if it has one
+	Default any `json:"default,omitempty"`
+	// Enum is the list of allowed values of the field, if it is restricted to a set of values
+	Enum []any `json:"enum,omitempty"`
+	// Widget is a hint for how to render the field (such as "textarea" or "password"), from @form(widget=...)
+	Widget string `json:"widget,omitempty"`
+	// Group is the name of the group the field belongs to, from @form(group=...).
+	// Nested fields inherit the group of their parent if they do not set one.
+	Group string `json:"group,omitempty"`
+	// Placeholder is placeholder text for the field's input, from @form(placeholder=...)
+	Placeholder string `json:"placeholder,omitempty"`
+	// Hidden indicates the field should not be rendered, from @form(hidden)
+	Hidden bool `json:"hidden,omitempty"`
+	// ReadOnly indicates the field should be displayed, but not editable, from @form(readOnly)
+	ReadOnly  bool     `json:"readOnly,omitempty"`
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+	MinLength *int64   `json:"minLength,omitempty"`
+	MaxLength *int64   `json:"maxLength,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+	// Fields are the fields of an object field, in display order
+	Fields []FormField `json:"fields,omitempty"`
+	// Items describes the values of an array or map field
+	Items *FormField `json:"items,omitempty"`
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// KindsInfo is the summary of the kinds an app exposes, as returned by the KindsRoutePath route
+type KindsInfo struct {
+	AppName string     `json:"appName"`
+	Group   string     `json:"group"`
+	Kinds   []KindInfo `json:"kinds"`
+}
+
+// KindInfo is the summary of a kind in a KindsInfo, with the governance metadata of the kind from its ManifestKind
+type KindInfo struct {
+	Kind             string       `json:"kind"`
+	Scope            string       `json:"scope"`
+	Versions         []string     `json:"versions"`
+	Maturity         KindMaturity `json:"maturity,omitempty"`
+	Owner            string       `json:"owner,omitempty"`
+	DocumentationURL string       `json:"documentationURL,omitempty"`
+}
+
+// NewKindsInfo returns the KindsInfo for the kinds in manifest
+func NewKindsInfo(manifest ManifestData) KindsInfo {
+	info := KindsInfo{
+		AppName: manifest.AppName,
+		Group:   manifest.Group,
+		Kinds:   make([]KindInfo, 0, len(manifest.Kinds)),
+	}
+	for _, kind := range manifest.Kinds {
+		versions := make([]string, 0, len(kind.Versions))
+		for _, version := range kind.Versions {
+			versions = append(versions, version.Name)
+		}
+		info.Kinds = append(info.Kinds, KindInfo{
+			Kind:             kind.Kind,
+			Scope:            kind.Scope,
+			Versions:         versions,
+			Maturity:         kind.Maturity,
+			Owner:            kind.Owner,
+			DocumentationURL: kind.DocumentationURL,
+		})
+	}
+	return info
+}
+
+// KindsRoutePath is the subresource path of the app-level custom route which returns the KindsInfo of an app.
+// App-level routes are requested with a ResourceCustomRouteRequest whose ResourceIdentifier only sets the app's Group.
+const KindsRoutePath = "kinds"
+
+// NewKindsRouteHandler returns a handler for the KindsRoutePath route, which responds with the JSON-encoded KindsInfo
+// for manifest. The response body is encoded once, when the handler is created.
+func NewKindsRouteHandler(manifest ManifestData) (func(context.Context, *ResourceCustomRouteRequest) (*ResourceCustomRouteResponse, error), error) {
+	body, err := json.Marshal(NewKindsInfo(manifest))
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal kinds: %w", err)
+	}
+	return newJSONRouteHandler(body), nil
+}
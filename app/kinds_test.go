@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestNewKindsRouteHandler(t *testing.T) {
+	manifest := ManifestData{
+		AppName: "test-app",
+		Group:   "test.grafana.app",
+		Kinds: []ManifestKind{{
+			Kind:             "Foo",
+			Scope:            "Namespaced",
+			Versions:         []ManifestKindVersion{{Name: "v1alpha1"}, {Name: "v1"}},
+			Maturity:         KindMaturityStable,
+			Owner:            "test-team",
+			DocumentationURL: "https://example.com/docs/foo",
+		}, {
+			Kind:     "Bar",
+			Scope:    "Cluster",
+			Versions: []ManifestKindVersion{{Name: "v1"}},
+		}},
+	}
+
+	handler, err := NewKindsRouteHandler(manifest)
+	require.Nil(t, err)
+	resp, err := handler(context.Background(), &ResourceCustomRouteRequest{
+		ResourceIdentifier: resource.FullIdentifier{Group: manifest.Group},
+		SubresourcePath:    KindsRoutePath,
+		Method:             http.MethodGet,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Headers.Get("Content-Type"))
+	info := KindsInfo{}
+	require.Nil(t, json.Unmarshal(resp.Body, &info))
+	assert.Equal(t, KindsInfo{
+		AppName: "test-app",
+		Group:   "test.grafana.app",
+		Kinds: []KindInfo{{
+			Kind:             "Foo",
+			Scope:            "Namespaced",
+			Versions:         []string{"v1alpha1", "v1"},
+			Maturity:         KindMaturityStable,
+			Owner:            "test-team",
+			DocumentationURL: "https://example.com/docs/foo",
+		}, {
+			Kind:     "Bar",
+			Scope:    "Cluster",
+			Versions: []string{"v1"},
+		}},
+	}, info)
+	// Unset metadata is omitted
+	assert.NotContains(t, string(resp.Body), `"owner":""`)
+
+	resp, err = handler(context.Background(), &ResourceCustomRouteRequest{
+		ResourceIdentifier: resource.FullIdentifier{Group: manifest.Group},
+		SubresourcePath:    KindsRoutePath,
+		Method:             http.MethodPost,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
@@ -44,9 +44,20 @@ manifest: versions: v1alpha1: {
 		#MutationCapability: {
 			operations: [...#AdmissionOperation]
 		}
+		#DefaultingCapability: {
+			operations: [...#AdmissionOperation]
+		}
+		#AdmissionPolicyReference: {
+			// path is the path to the policy bundle in the runner's filesystem
+			path: string
+			// engine is the policy engine used to evaluate the bundle, defaulting to the runner's default engine
+			engine?: string
+		}
 		#AdmissionCapabilities: {
 			validation?: #ValidationCapability
 			mutation?: #MutationCapability
+			defaulting?: #DefaultingCapability
+			policy?: #AdmissionPolicyReference
 		}
 		#ManifestKindVersion: {
 			name: string
@@ -67,6 +78,8 @@ manifest: versions: v1alpha1: {
 			group: string
 			resource: string
 			actions: [...string]
+			// required indicates that the app depends on this kind, and should not start until it is served
+			required?: bool
 		}
 		spec: {
 			appName: string
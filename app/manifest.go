@@ -2,10 +2,19 @@ package app
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana-app-sdk/resource"
 )
 
 // NewEmbeddedManifest returns a Manifest which has the ManifestData embedded in it
@@ -87,8 +96,39 @@ type ManifestKind struct {
 	Versions []ManifestKindVersion `json:"versions" yaml:"versions"`
 	// Conversion is true if the app has a conversion capability for this kind
 	Conversion bool `json:"conversion" yaml:"conversion"`
+	// ShortNames is the list of short names for the kind which can be used by clients such as kubectl
+	ShortNames []string `json:"shortNames,omitempty" yaml:"shortNames,omitempty"`
+	// Categories is the list of grouped resources the kind belongs to (such as "all"), which can be used by clients such as kubectl
+	Categories []string `json:"categories,omitempty" yaml:"categories,omitempty"`
+	// ConfigKind is true if the kind is a cluster-scoped singleton which stores the app's runtime configuration.
+	// At most one kind in a manifest may be a config kind. Runners watch its preferred version (the version with
+	// the highest kube-aware priority, such as v2 over v2beta1), which the app must manage.
+	ConfigKind bool `json:"configKind,omitempty" yaml:"configKind,omitempty"`
+	// Maturity is the maturity level of the kind, or empty if it is unspecified
+	Maturity KindMaturity `json:"maturity,omitempty" yaml:"maturity,omitempty"`
+	// Owner is the team which owns the kind
+	Owner string `json:"owner,omitempty" yaml:"owner,omitempty"`
+	// DocumentationURL is a link to the documentation of the kind
+	DocumentationURL string `json:"documentationURL,omitempty" yaml:"documentationURL,omitempty"`
 }
 
+// KindMaturity is the maturity level of a kind, which catalogs of an app's kinds can display alongside it
+type KindMaturity string
+
+const (
+	KindMaturityAlpha  KindMaturity = "alpha"
+	KindMaturityBeta   KindMaturity = "beta"
+	KindMaturityStable KindMaturity = "stable"
+)
+
+// Annotations set on the generated CRD of a kind from its ManifestKind, so that the metadata is available
+// to clients which read kinds from CRDs rather than the app manifest
+const (
+	KindMaturityAnnotation         = "kinds.grafana.app/maturity"
+	KindOwnerAnnotation            = "kinds.grafana.app/owner"
+	KindDocumentationURLAnnotation = "kinds.grafana.app/documentation-url"
+)
+
 // ManifestKindVersion contains details for a version of a kind in a Manifest
 type ManifestKindVersion struct {
 	// Name is the version string name, such as "v1"
@@ -101,6 +141,277 @@ type ManifestKindVersion struct {
 	Schema *VersionSchema `json:"schema,omitempty" yaml:"schema,omitempty"`
 	// SelectableFields are the set of JSON paths in the schema which can be used as field selectors
 	SelectableFields []string `json:"selectableFields,omitempty" yaml:"selectableFields,omitempty"`
+	// AdditionalPrinterColumns is the list of additional columns to display for the version in clients such as kubectl
+	AdditionalPrinterColumns []AdditionalPrinterColumn `json:"additionalPrinterColumns,omitempty" yaml:"additionalPrinterColumns,omitempty"`
+	// SizeLimits are the limits on the size of objects of the version, which are enforced in validating admission.
+	// If nil, object sizes are not limited beyond the limits of the API server.
+	SizeLimits *SizeLimits `json:"sizeLimits,omitempty" yaml:"sizeLimits,omitempty"`
+	// Scale is the scale subresource of the version, which allows autoscalers such as the HorizontalPodAutoscaler
+	// to scale objects of the version. If nil, the version has no scale subresource.
+	Scale *ScaleSubresource `json:"scale,omitempty" yaml:"scale,omitempty"`
+	// CustomRoutes are the custom routes served at subresource paths of objects of the version,
+	// with the schemas of their request and response bodies
+	CustomRoutes []CustomRoute `json:"customRoutes,omitempty" yaml:"customRoutes,omitempty"`
+}
+
+// MaxSelectableFields is the maximum number of SelectableFields a kind version may have, which is the limit kubernetes has for CRDs
+const MaxSelectableFields = 8
+
+var selectableFieldPath = regexp.MustCompile(`^\.?[A-Za-z_][A-Za-z0-9_-]*(\.[A-Za-z_][A-Za-z0-9_-]*)+$`)
+
+// ValidateSelectableFields validates the SelectableFields of every version of every kind in the manifest
+// (see ManifestKindVersion.ValidateSelectableFields), and returns an error listing every invalid field.
+func (m *ManifestData) ValidateSelectableFields() error {
+	errs := make([]error, 0)
+	for _, kind := range m.Kinds {
+		for _, version := range kind.Versions {
+			if err := version.ValidateSelectableFields(); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", kind.Kind, version.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateSelectableFields checks the SelectableFields of the version against the restrictions kubernetes has for CRDs:
+// there may be at most MaxSelectableFields fields, each field must be a simple path (such as "spec.foo" or ".spec.foo",
+// without array indices), no field may be repeated, and each field must be a string, integer, or boolean in the Schema.
+// Fields in metadata cannot be selectable, as metadata.name and metadata.namespace are always selectable.
+// If the version has no Schema, field types are not checked.
+func (v ManifestKindVersion) ValidateSelectableFields() error {
+	if len(v.SelectableFields) > MaxSelectableFields {
+		return fmt.Errorf("too many selectable fields (%d), the maximum is %d", len(v.SelectableFields), MaxSelectableFields)
+	}
+	seen := make(map[string]bool, len(v.SelectableFields))
+	errs := make([]error, 0)
+	for _, field := range v.SelectableFields {
+		path := strings.TrimPrefix(field, ".")
+		switch {
+		case !selectableFieldPath.MatchString(field):
+			errs = append(errs, fmt.Errorf("selectable field '%s' must be a path to a field, such as 'spec.foo'", field))
+			continue
+		case seen[path]:
+			errs = append(errs, fmt.Errorf("selectable field '%s' is repeated", field))
+			continue
+		case strings.HasPrefix(path, "metadata."):
+			errs = append(errs, fmt.Errorf("selectable field '%s' cannot be a metadata field", field))
+			continue
+		}
+		seen[path] = true
+		if v.Schema == nil {
+			continue
+		}
+		if err := validateSelectableFieldSchema(v.Schema.AsMap(), strings.Split(path, ".")); err != nil {
+			errs = append(errs, fmt.Errorf("selectable field '%s' %w", field, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateSelectableFieldSchema checks that the field at path in the top-level schemas is a string, integer, or boolean
+func validateSelectableFieldSchema(schemas map[string]any, path []string) error {
+	schema, ok := schemas[path[0]].(map[string]any)
+	for _, part := range path[1:] {
+		if !ok {
+			break
+		}
+		props, _ := schema["properties"].(map[string]any)
+		schema, ok = props[part].(map[string]any)
+	}
+	if !ok {
+		return errors.New("does not exist in the schema")
+	}
+	switch typ, _ := schema["type"].(string); typ {
+	case "string", "integer", "boolean":
+		return nil
+	case "":
+		return errors.New("has no type, but must be a string, integer, or boolean")
+	default:
+		return fmt.Errorf("has type %s, but must be a string, integer, or boolean", typ)
+	}
+}
+
+// AdditionalPrinterColumn is an additional column to display for a kind version in clients such as kubectl
+type AdditionalPrinterColumn struct {
+	// Name is a human-readable name for the column
+	Name string `json:"name" yaml:"name"`
+	// Type is the OpenAPI type of the column
+	Type string `json:"type" yaml:"type"`
+	// Format is an optional OpenAPI format of the column
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+	// Description is a human-readable description of the column
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Priority is the relative importance of the column. Columns with a priority greater than 0
+	// may be omitted in limited space scenarios.
+	Priority int32 `json:"priority,omitempty" yaml:"priority,omitempty"`
+	// JSONPath is the simple JSON path evaluated against each resource to produce the value of the column
+	JSONPath string `json:"jsonPath" yaml:"jsonPath"`
+}
+
+// SizeLimits are limits on the size of the objects of a kind version, which are enforced in validating admission
+// by a SizeLimitEnforcer. Paths are dot-separated paths from the root of the object, such as "spec.targets",
+// where a "[*]" suffix on a part selects every item of an array, such as "spec.groups[*].members".
+type SizeLimits struct {
+	// MaxBytes is the maximum size of an object, in bytes of its JSON encoding. Zero means no limit.
+	MaxBytes int64 `json:"maxBytes,omitempty" yaml:"maxBytes,omitempty"`
+	// MaxItems maps paths to the maximum number of items of the array (or keys of the object) at the path
+	MaxItems map[string]int64 `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+	// MaxLength maps paths to the maximum length, in characters, of the string at the path
+	MaxLength map[string]int64 `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+}
+
+var sizeLimitPath = regexp.MustCompile(`^\.?[A-Za-z_][A-Za-z0-9_-]*(\[\*\])?(\.[A-Za-z_][A-Za-z0-9_-]*(\[\*\])?)*$`)
+
+// Validate checks that no limit is negative, and every path is valid, and returns an error listing every invalid limit
+func (l SizeLimits) Validate() error {
+	errs := make([]error, 0)
+	if l.MaxBytes < 0 {
+		errs = append(errs, fmt.Errorf("maxBytes cannot be negative, got %d", l.MaxBytes))
+	}
+	validatePaths := func(name string, limits map[string]int64) {
+		for _, path := range slices.Sorted(maps.Keys(limits)) {
+			if !sizeLimitPath.MatchString(path) {
+				errs = append(errs, fmt.Errorf("%s path '%s' must be a path to a field, such as 'spec.foo'", name, path))
+			}
+			if limits[path] < 0 {
+				errs = append(errs, fmt.Errorf("%s for '%s' cannot be negative, got %d", name, path, limits[path]))
+			}
+		}
+	}
+	validatePaths("maxItems", l.MaxItems)
+	validatePaths("maxLength", l.MaxLength)
+	return errors.Join(errs...)
+}
+
+// ScaleSubresource maps the replicas of the scale subresource of a kind version to fields of its objects.
+// Paths are simple JSON paths, such as ".spec.replicas".
+type ScaleSubresource struct {
+	// SpecReplicasPath is the path to the desired number of replicas in the spec, such as ".spec.replicas"
+	SpecReplicasPath string `json:"specReplicasPath" yaml:"specReplicasPath"`
+	// StatusReplicasPath is the path to the observed number of replicas in the status, such as ".status.replicas"
+	StatusReplicasPath string `json:"statusReplicasPath" yaml:"statusReplicasPath"`
+	// LabelSelectorPath is the path to the label selector of the replicas in the spec or status, in its serialized string form,
+	// such as ".status.selector". It is required for the HorizontalPodAutoscaler to scale objects of the kind.
+	LabelSelectorPath string `json:"labelSelectorPath,omitempty" yaml:"labelSelectorPath,omitempty"`
+}
+
+// Validate checks the paths against the restrictions kubernetes has for the scale subresource of a CRD:
+// SpecReplicasPath must be a path in the spec, StatusReplicasPath must be a path in the status,
+// and LabelSelectorPath, if set, must be a path in the spec or status. It returns an error listing every invalid path.
+func (s ScaleSubresource) Validate() error {
+	errs := make([]error, 0)
+	validatePath := func(name, path string, roots ...string) {
+		if !selectableFieldPath.MatchString(path) {
+			errs = append(errs, fmt.Errorf("%s '%s' must be a path to a field, such as '.%s.foo'", name, path, roots[0]))
+			return
+		}
+		root := strings.SplitN(strings.TrimPrefix(path, "."), ".", 2)[0]
+		if !slices.Contains(roots, root) {
+			errs = append(errs, fmt.Errorf("%s '%s' must be a path in .%s", name, path, strings.Join(roots, " or .")))
+		}
+	}
+	validatePath("specReplicasPath", s.SpecReplicasPath, "spec")
+	validatePath("statusReplicasPath", s.StatusReplicasPath, "status")
+	if s.LabelSelectorPath != "" {
+		validatePath("labelSelectorPath", s.LabelSelectorPath, "status", "spec")
+	}
+	return errors.Join(errs...)
+}
+
+// CustomRoute is a custom route of a kind version, which is served at a subresource path of objects of the kind
+type CustomRoute struct {
+	// Path is the subresource path of the route, such as "rollback"
+	Path string `json:"path" yaml:"path"`
+	// Method is the HTTP method of the route, such as "POST"
+	Method string `json:"method" yaml:"method"`
+	// Name is the name of the route, which its generated go types are named after, such as "Rollback"
+	Name string `json:"name" yaml:"name"`
+	// RequestSchema is the OpenAPI schema of the JSON request body, or nil if the request body is untyped
+	RequestSchema map[string]any `json:"requestSchema,omitempty" yaml:"requestSchema,omitempty"`
+	// ResponseSchema is the OpenAPI schema of the JSON response body, or nil if the response body is untyped
+	ResponseSchema map[string]any `json:"responseSchema,omitempty" yaml:"responseSchema,omitempty"`
+}
+
+// AsOpenAPI3 returns the route as an OpenAPI operation, with its request body and a 200 response with its response body.
+// The OperationID of the operation is the Name of the route.
+func (r CustomRoute) AsOpenAPI3() (*openapi3.Operation, error) {
+	toSchema := func(m map[string]any) (*openapi3.Schema, error) {
+		schema := openapi3.NewSchema()
+		if m == nil {
+			return schema, nil
+		}
+		raw, err := json.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		return schema, json.Unmarshal(raw, schema)
+	}
+	op := openapi3.NewOperation()
+	op.OperationID = r.Name
+	requestSchema, err := toSchema(r.RequestSchema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request schema for %s %s: %w", r.Method, r.Path, err)
+	}
+	responseSchema, err := toSchema(r.ResponseSchema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid response schema for %s %s: %w", r.Method, r.Path, err)
+	}
+	if r.RequestSchema != nil {
+		op.RequestBody = &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchema(requestSchema),
+		}
+	}
+	op.Responses = openapi3.NewResponses(openapi3.WithStatus(http.StatusOK, &openapi3.ResponseRef{
+		Value: openapi3.NewResponse().WithDescription("OK").WithJSONSchema(responseSchema),
+	}))
+	return op, nil
+}
+
+// CustomRoutesOpenAPI3 returns an OpenAPI document with an operation for each custom route of each kind version in the manifest,
+// at the subresource path of an object of the kind, such as /apis/<group>/<version>/namespaces/{namespace}/<plural>/{name}/<path>.
+// plurals maps the name of each kind to its plural name, and the routes of kinds which are not in plurals are omitted.
+func (m ManifestData) CustomRoutesOpenAPI3(plurals map[string]string) (*openapi3.T, error) {
+	doc := &openapi3.T{
+		OpenAPI: "3.0.0",
+		Info: &openapi3.Info{
+			Title:   fmt.Sprintf("%s custom routes", m.AppName),
+			Version: "1.0.0",
+		},
+		Paths: openapi3.NewPaths(),
+	}
+	for _, kind := range m.Kinds {
+		plural, ok := plurals[kind.Kind]
+		if !ok {
+			continue
+		}
+		for _, version := range kind.Versions {
+			prefix := fmt.Sprintf("/apis/%s/%s/namespaces/{namespace}/%s/{name}", m.Group, version.Name, plural)
+			params := openapi3.Parameters{
+				{Value: openapi3.NewPathParameter("namespace").WithSchema(openapi3.NewStringSchema())},
+				{Value: openapi3.NewPathParameter("name").WithSchema(openapi3.NewStringSchema())},
+			}
+			if kind.Scope == string(resource.ClusterScope) {
+				prefix = fmt.Sprintf("/apis/%s/%s/%s/{name}", m.Group, version.Name, plural)
+				params = params[1:]
+			}
+			for _, route := range version.CustomRoutes {
+				op, err := route.AsOpenAPI3()
+				if err != nil {
+					return nil, fmt.Errorf("%s/%s: %w", kind.Kind, version.Name, err)
+				}
+				path := fmt.Sprintf("%s/%s", prefix, route.Path)
+				item := doc.Paths.Value(path)
+				if item == nil {
+					item = &openapi3.PathItem{
+						Parameters: params,
+					}
+					doc.Paths.Set(path, item)
+				}
+				item.SetOperation(strings.ToUpper(route.Method), op)
+			}
+		}
+	}
+	return doc, nil
 }
 
 // AdmissionCapabilities is the collection of admission capabilities of a kind
@@ -109,6 +420,21 @@ type AdmissionCapabilities struct {
 	Validation *ValidationCapability `json:"validation,omitempty" yaml:"validation,omitempty"`
 	// Mutation contains the mutation capability details. If nil, the kind does not have a mutation capability.
 	Mutation *MutationCapability `json:"mutation,omitempty" yaml:"mutation,omitempty"`
+	// Defaulting contains the defaulting capability details. If nil, the kind does not have a defaulting capability.
+	// Defaulting is run as part of mutating admission, before the app's mutation.
+	Defaulting *DefaultingCapability `json:"defaulting,omitempty" yaml:"defaulting,omitempty"`
+	// Policy is an optional reference to a policy bundle which is evaluated by the runner for validation and mutation,
+	// in addition to the app's own validation and mutation. Policies are reloaded when the bundle changes,
+	// allowing admission logic to be updated without redeploying the app.
+	Policy *AdmissionPolicyReference `json:"policy,omitempty" yaml:"policy,omitempty"`
+}
+
+// AdmissionPolicyReference is a reference to an admission policy bundle
+type AdmissionPolicyReference struct {
+	// Path is the path to the policy bundle, in the filesystem used by the runner
+	Path string `json:"path" yaml:"path"`
+	// Engine is the name of the policy engine used to evaluate the bundle. If empty, the runner's default engine is used.
+	Engine string `json:"engine,omitempty" yaml:"engine,omitempty"`
 }
 
 // SupportsAnyValidation returns true if the list of operations for validation is not empty.
@@ -120,6 +446,20 @@ func (c AdmissionCapabilities) SupportsAnyValidation() bool {
 	return len(c.Validation.Operations) > 0
 }
 
+// SupportsValidation returns true if validation is used for the provided operation,
+// either by listing it or by listing AdmissionOperationAny.
+func (c AdmissionCapabilities) SupportsValidation(operation AdmissionOperation) bool {
+	if c.Validation == nil {
+		return false
+	}
+	for _, op := range c.Validation.Operations {
+		if op == operation || op == AdmissionOperationAny {
+			return true
+		}
+	}
+	return false
+}
+
 // SupportsAnyMutation returns true if the list of operations for mutation is not empty.
 // This is a convenience method to avoid having to make several nil and length checks.
 func (c AdmissionCapabilities) SupportsAnyMutation() bool {
@@ -129,6 +469,15 @@ func (c AdmissionCapabilities) SupportsAnyMutation() bool {
 	return len(c.Mutation.Operations) > 0
 }
 
+// SupportsAnyDefaulting returns true if the list of operations for defaulting is not empty.
+// This is a convenience method to avoid having to make several nil and length checks.
+func (c AdmissionCapabilities) SupportsAnyDefaulting() bool {
+	if c.Defaulting == nil {
+		return false
+	}
+	return len(c.Defaulting.Operations) > 0
+}
+
 // ValidationCapability is the details of a validation capability for a kind's admission control
 type ValidationCapability struct {
 	// Operations is the list of operations that the validation capability is used for.
@@ -143,6 +492,13 @@ type MutationCapability struct {
 	Operations []AdmissionOperation `json:"operations,omitempty" yaml:"operations,omitempty"`
 }
 
+// DefaultingCapability is the details of a defaulting capability for a kind's admission control
+type DefaultingCapability struct {
+	// Operations is the list of operations that the defaulting capability is used for, typically CREATE and UPDATE.
+	// If this list if empty or nil, this is equivalent to the app having no defaulting capability.
+	Operations []AdmissionOperation `json:"operations,omitempty" yaml:"operations,omitempty"`
+}
+
 type AdmissionOperation string
 
 const (
@@ -163,6 +519,8 @@ type KindPermission struct {
 	Group    string                 `json:"group" yaml:"group"`
 	Resource string                 `json:"resource" yaml:"resource"`
 	Actions  []KindPermissionAction `json:"actions,omitempty" yaml:"actions,omitempty"`
+	// Required indicates that the app depends on this kind, and should not start until the kind is served by the API server.
+	Required bool `json:"required,omitempty" yaml:"required,omitempty"`
 }
 
 func VersionSchemaFromMap(openAPISchema map[string]any) (*VersionSchema, error) {
@@ -281,6 +639,79 @@ func (v *VersionSchema) AsOpenAPI3() (*openapi3.Components, error) {
 	return oT.Components, nil
 }
 
+// ValidationRules returns the CEL validation rules (x-kubernetes-validations) declared in the schema,
+// with the path of the field each rule is declared on. Rules are returned sorted by path,
+// and in declaration order for each path.
+func (v *VersionSchema) ValidationRules() []ValidationRule {
+	rules := make([]ValidationRule, 0)
+	keys := make([]string, 0, len(v.raw))
+	for k := range v.raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if schema, ok := v.raw[k].(map[string]any); ok {
+			rules = appendValidationRules(rules, schema, k)
+		}
+	}
+	return rules
+}
+
+// ValidationRule is a CEL validation rule of a field in a VersionSchema.
+// Rules are enforced by the API server for kinds backed by a CRD,
+// see https://kubernetes.io/docs/tasks/extend-kubernetes/custom-resources/custom-resource-definitions/#validation-rules
+type ValidationRule struct {
+	// Path is the path of the field the rule is declared on, such as "spec.inner".
+	// Array items are denoted by "[]", and map values by "{}".
+	Path              string `json:"path"`
+	Rule              string `json:"rule"`
+	Message           string `json:"message,omitempty"`
+	MessageExpression string `json:"messageExpression,omitempty"`
+	Reason            string `json:"reason,omitempty"`
+	FieldPath         string `json:"fieldPath,omitempty"`
+	OptionalOldSelf   bool   `json:"optionalOldSelf,omitempty"`
+}
+
+func appendValidationRules(rules []ValidationRule, schema map[string]any, path string) []ValidationRule {
+	if validations, ok := schema["x-kubernetes-validations"].([]any); ok {
+		for _, val := range validations {
+			cast, ok := val.(map[string]any)
+			if !ok {
+				continue
+			}
+			rule := ValidationRule{
+				Path: path,
+			}
+			rule.Rule, _ = cast["rule"].(string)
+			rule.Message, _ = cast["message"].(string)
+			rule.MessageExpression, _ = cast["messageExpression"].(string)
+			rule.Reason, _ = cast["reason"].(string)
+			rule.FieldPath, _ = cast["fieldPath"].(string)
+			rule.OptionalOldSelf, _ = cast["optionalOldSelf"].(bool)
+			rules = append(rules, rule)
+		}
+	}
+	if props, ok := schema["properties"].(map[string]any); ok {
+		keys := make([]string, 0, len(props))
+		for k := range props {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if prop, ok := props[k].(map[string]any); ok {
+				rules = appendValidationRules(rules, prop, path+"."+k)
+			}
+		}
+	}
+	if items, ok := schema["items"].(map[string]any); ok {
+		rules = appendValidationRules(rules, items, path+"[]")
+	}
+	if additional, ok := schema["additionalProperties"].(map[string]any); ok {
+		rules = appendValidationRules(rules, additional, path+"{}")
+	}
+	return rules
+}
+
 // func (v *VersionSchema) AsKubeOpenAPI(kindName string, ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 // TODO convert AsOpenAPI to kube-openapi?
 //	return nil
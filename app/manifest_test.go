@@ -0,0 +1,309 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestVersionSchema_ValidationRules(t *testing.T) {
+	crdSchema := `{
+	"openAPIV3Schema": {
+		"properties": {
+			"spec": {
+				"type": "object",
+				"properties": {
+					"min": {"type": "integer"},
+					"max": {"type": "integer"},
+					"tags": {
+						"type": "array",
+						"items": {
+							"type": "string",
+							"x-kubernetes-validations": [{"rule": "self != ''"}]
+						}
+					}
+				},
+				"x-kubernetes-validations": [
+					{"rule": "self.min <= self.max", "message": "min must not be greater than max"},
+					{"rule": "self.min == oldSelf.min", "reason": "FieldValueForbidden", "fieldPath": ".min", "optionalOldSelf": true}
+				]
+			},
+			"status": {
+				"type": "object",
+				"additionalProperties": {
+					"type": "string",
+					"x-kubernetes-validations": [{"rule": "size(self) < 10", "messageExpression": "'too long: ' + self"}]
+				}
+			}
+		}
+	}
+}`
+	expected := []ValidationRule{{
+		Path:    "spec",
+		Rule:    "self.min <= self.max",
+		Message: "min must not be greater than max",
+	}, {
+		Path:            "spec",
+		Rule:            "self.min == oldSelf.min",
+		Reason:          "FieldValueForbidden",
+		FieldPath:       ".min",
+		OptionalOldSelf: true,
+	}, {
+		Path: "spec.tags[]",
+		Rule: "self != ''",
+	}, {
+		Path:              "status{}",
+		Rule:              "size(self) < 10",
+		MessageExpression: "'too long: ' + self",
+	}}
+
+	t.Run("json", func(t *testing.T) {
+		vs := &VersionSchema{}
+		require.Nil(t, json.Unmarshal([]byte(crdSchema), vs))
+		assert.Equal(t, expected, vs.ValidationRules())
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		// JSON is valid YAML
+		vs := &VersionSchema{}
+		require.Nil(t, yaml.Unmarshal([]byte(crdSchema), vs))
+		assert.Equal(t, expected, vs.ValidationRules())
+	})
+
+	t.Run("none", func(t *testing.T) {
+		vs, err := VersionSchemaFromMap(map[string]any{
+			"spec": map[string]any{
+				"type": "object",
+			},
+		})
+		require.Nil(t, err)
+		assert.Equal(t, []ValidationRule{}, vs.ValidationRules())
+	})
+}
+
+func TestManifestKindVersion_ValidateSelectableFields(t *testing.T) {
+	schema, err := VersionSchemaFromMap(map[string]any{
+		"spec": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name":    map[string]any{"type": "string"},
+				"count":   map[string]any{"type": "integer"},
+				"enabled": map[string]any{"type": "boolean"},
+				"tags":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			},
+		},
+	})
+	require.Nil(t, err)
+
+	tests := []struct {
+		name   string
+		fields []string
+		errs   []string
+	}{{
+		name:   "valid",
+		fields: []string{".spec.name", "spec.count", "spec.enabled"},
+	}, {
+		name:   "invalid paths",
+		fields: []string{"spec", "spec.tags[0]", ".spec..name"},
+		errs: []string{
+			"selectable field 'spec' must be a path to a field, such as 'spec.foo'",
+			"selectable field 'spec.tags[0]' must be a path to a field, such as 'spec.foo'",
+			"selectable field '.spec..name' must be a path to a field, such as 'spec.foo'",
+		},
+	}, {
+		name:   "repeated",
+		fields: []string{"spec.name", ".spec.name"},
+		errs:   []string{"selectable field '.spec.name' is repeated"},
+	}, {
+		name:   "metadata",
+		fields: []string{"metadata.name"},
+		errs:   []string{"selectable field 'metadata.name' cannot be a metadata field"},
+	}, {
+		name:   "schema",
+		fields: []string{"spec.missing", "spec.tags", "status.foo"},
+		errs: []string{
+			"selectable field 'spec.missing' does not exist in the schema",
+			"selectable field 'spec.tags' has type array, but must be a string, integer, or boolean",
+			"selectable field 'status.foo' does not exist in the schema",
+		},
+	}, {
+		name:   "too many",
+		fields: []string{"spec.a", "spec.b", "spec.c", "spec.d", "spec.e", "spec.f", "spec.g", "spec.h", "spec.i"},
+		errs:   []string{"too many selectable fields (9), the maximum is 8"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ManifestKindVersion{
+				Name:             "v1",
+				Schema:           schema,
+				SelectableFields: test.fields,
+			}.ValidateSelectableFields()
+			if len(test.errs) == 0 {
+				assert.Nil(t, err)
+				return
+			}
+			require.NotNil(t, err)
+			assert.Equal(t, strings.Join(test.errs, "\n"), err.Error())
+		})
+	}
+
+	t.Run("manifest", func(t *testing.T) {
+		data := ManifestData{
+			Kinds: []ManifestKind{{
+				Kind: "Foo",
+				Versions: []ManifestKindVersion{{
+					Name:             "v1",
+					SelectableFields: []string{"spec.name"},
+				}, {
+					Name:             "v2",
+					SelectableFields: []string{"spec"},
+				}},
+			}},
+		}
+		err := data.ValidateSelectableFields()
+		require.NotNil(t, err)
+		assert.Equal(t, "Foo v2: selectable field 'spec' must be a path to a field, such as 'spec.foo'", err.Error())
+	})
+}
+
+func TestScaleSubresource_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		scale ScaleSubresource
+		errs  []string
+	}{{
+		name: "valid",
+		scale: ScaleSubresource{
+			SpecReplicasPath:   ".spec.replicas",
+			StatusReplicasPath: "status.replicas",
+			LabelSelectorPath:  ".status.selector",
+		},
+	}, {
+		name: "no label selector",
+		scale: ScaleSubresource{
+			SpecReplicasPath:   ".spec.replicas",
+			StatusReplicasPath: ".status.replicas",
+		},
+	}, {
+		name: "invalid paths",
+		scale: ScaleSubresource{
+			SpecReplicasPath:  "replicas",
+			LabelSelectorPath: ".status.selectors[0]",
+		},
+		errs: []string{
+			"specReplicasPath 'replicas' must be a path to a field, such as '.spec.foo'",
+			"statusReplicasPath '' must be a path to a field, such as '.status.foo'",
+			"labelSelectorPath '.status.selectors[0]' must be a path to a field, such as '.status.foo'",
+		},
+	}, {
+		name: "wrong roots",
+		scale: ScaleSubresource{
+			SpecReplicasPath:   ".status.replicas",
+			StatusReplicasPath: ".spec.replicas",
+			LabelSelectorPath:  ".metadata.selector",
+		},
+		errs: []string{
+			"specReplicasPath '.status.replicas' must be a path in .spec",
+			"statusReplicasPath '.spec.replicas' must be a path in .status",
+			"labelSelectorPath '.metadata.selector' must be a path in .status or .spec",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.scale.Validate()
+			if len(test.errs) == 0 {
+				assert.Nil(t, err)
+				return
+			}
+			require.NotNil(t, err)
+			assert.Equal(t, strings.Join(test.errs, "\n"), err.Error())
+		})
+	}
+}
+
+func TestCustomRoute_AsOpenAPI3(t *testing.T) {
+	route := CustomRoute{
+		Path:   "reconcile",
+		Method: "POST",
+		Name:   "Reconcile",
+		RequestSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"reason": map[string]any{"type": "string"}},
+			"required":   []any{"reason"},
+		},
+		ResponseSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"reconciled": map[string]any{"type": "boolean"}},
+		},
+	}
+	op, err := route.AsOpenAPI3()
+	require.Nil(t, err)
+	assert.Equal(t, "Reconcile", op.OperationID)
+	require.NotNil(t, op.RequestBody)
+	requestSchema := op.RequestBody.Value.Content.Get("application/json").Schema.Value
+	assert.Equal(t, []string{"reason"}, requestSchema.Required)
+	assert.Contains(t, requestSchema.Properties, "reason")
+	responseSchema := op.Responses.Status(200).Value.Content.Get("application/json").Schema.Value
+	assert.Contains(t, responseSchema.Properties, "reconciled")
+
+	// Untyped bodies have no request body, and an empty response schema
+	op, err = CustomRoute{Path: "history", Method: "GET", Name: "GetHistory"}.AsOpenAPI3()
+	require.Nil(t, err)
+	assert.Nil(t, op.RequestBody)
+	assert.Empty(t, op.Responses.Status(200).Value.Content.Get("application/json").Schema.Value.Properties)
+}
+
+func TestManifestData_CustomRoutesOpenAPI3(t *testing.T) {
+	manifest := ManifestData{
+		AppName: "test",
+		Group:   "test.grafana.app",
+		Kinds: []ManifestKind{{
+			Kind:  "Foo",
+			Scope: "Namespaced",
+			Versions: []ManifestKindVersion{{
+				Name: "v1",
+				CustomRoutes: []CustomRoute{
+					{Path: "history", Method: "GET", Name: "GetHistory"},
+					{Path: "history", Method: "DELETE", Name: "DeleteHistory"},
+				},
+			}},
+		}, {
+			Kind:  "Bar",
+			Scope: "Cluster",
+			Versions: []ManifestKindVersion{{
+				Name:         "v1",
+				CustomRoutes: []CustomRoute{{Path: "reconcile", Method: "POST", Name: "Reconcile"}},
+			}},
+		}, {
+			Kind:  "Baz",
+			Scope: "Namespaced",
+			Versions: []ManifestKindVersion{{
+				Name:         "v1",
+				CustomRoutes: []CustomRoute{{Path: "reconcile", Method: "POST", Name: "Reconcile"}},
+			}},
+		}},
+	}
+	// Baz has no plural, so its routes are omitted
+	doc, err := manifest.CustomRoutesOpenAPI3(map[string]string{"Foo": "foos", "Bar": "bars"})
+	require.Nil(t, err)
+	require.Nil(t, doc.Validate(context.Background()))
+	assert.Len(t, doc.Paths.Map(), 2)
+
+	history := doc.Paths.Value("/apis/test.grafana.app/v1/namespaces/{namespace}/foos/{name}/history")
+	require.NotNil(t, history)
+	assert.Equal(t, "GetHistory", history.Get.OperationID)
+	assert.Equal(t, "DeleteHistory", history.Delete.OperationID)
+	assert.Len(t, history.Parameters, 2)
+
+	reconcile := doc.Paths.Value("/apis/test.grafana.app/v1/bars/{name}/reconcile")
+	require.NotNil(t, reconcile)
+	assert.Equal(t, "Reconcile", reconcile.Post.OperationID)
+	assert.Len(t, reconcile.Parameters, 1)
+}
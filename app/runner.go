@@ -35,6 +35,9 @@ type MultiRunner struct {
 	// the context will be canceled and all other Runners will also be prompted to exit.
 	// If ErrorHandler is nil, RunnableCollectorDefaultErrorHandler is used.
 	ErrorHandler func(context.Context, error) bool
+	// ErrorReporter, if non-nil, is sent a report of each error returned by one of the Runners, before ErrorHandler is called.
+	// Unlike ErrorHandler, it does not decide whether the other Runners are stopped.
+	ErrorReporter ErrorReporter
 	// ExitWait is how long to wait for Runners to exit after ErrorHandler returns true or the context is canceled
 	// before stopping execution and returning a timeout error instead of exiting gracefully.
 	// If ExitWait is nil, Run execution will always block until all Runners have exited.
@@ -71,6 +74,7 @@ func (m *MultiRunner) Run(ctx context.Context) error {
 	for {
 		select {
 		case err := <-errs:
+			reportRunnerError(propagatedContext, m.ErrorReporter, "MultiRunner", err)
 			handler := m.ErrorHandler
 			if handler == nil {
 				handler = RunnableCollectorDefaultErrorHandler
@@ -223,6 +227,9 @@ type DynamicMultiRunner struct {
 	// the context will be canceled and all other Runners will also be prompted to exit.
 	// If ErrorHandler is nil, RunnableCollectorDefaultErrorHandler is used.
 	ErrorHandler func(context.Context, error) bool
+	// ErrorReporter, if non-nil, is sent a report of each error returned by one of the Runners, before ErrorHandler is called.
+	// Unlike ErrorHandler, it does not decide whether the other Runners are stopped.
+	ErrorReporter ErrorReporter
 	// ExitWait is how long to wait for Runners to exit after ErrorHandler returns true or the context is canceled
 	// before stopping execution and returning a timeout error instead of exiting gracefully.
 	// If ExitWait is nil, Run execution will always block until all Runners have exited.
@@ -264,6 +271,7 @@ func (d *DynamicMultiRunner) Run(ctx context.Context) error {
 	for {
 		select {
 		case err := <-d.errs:
+			reportRunnerError(d.runCtx, d.ErrorReporter, "DynamicMultiRunner", err)
 			handler := d.ErrorHandler
 			if handler == nil {
 				handler = RunnableCollectorDefaultErrorHandler
@@ -360,3 +368,14 @@ func (d *DynamicMultiRunner) runTuple(tpl *dynamicMultiRunnerTuple) {
 		}
 	}()
 }
+
+func reportRunnerError(ctx context.Context, reporter ErrorReporter, component string, err error) {
+	if reporter == nil {
+		return
+	}
+	reporter.ReportError(ctx, ErrorReport{
+		Err:       err,
+		Class:     ErrorClassRunner,
+		Component: component,
+	})
+}
@@ -119,6 +119,23 @@ func TestMultiRunner_Run(t *testing.T) {
 		assert.True(t, errorHandled)
 	})
 
+	t.Run("runner error, reported", func(t *testing.T) {
+		r := NewMultiRunner()
+		runnerError := errors.New("run error")
+		var report ErrorReport
+		r.ErrorReporter = ErrorReporterFunc(func(ctx context.Context, rep ErrorReport) {
+			report = rep
+		})
+		r.AddRunnable(&testRunnable{
+			RunFunc: func(ctx context.Context) error {
+				return runnerError
+			},
+		})
+		err := runOrTimeout(context.Background(), r, time.Second*5)
+		assert.Equal(t, runnerError, err)
+		assert.Equal(t, ErrorReport{Err: runnerError, Class: ErrorClassRunner, Component: "MultiRunner"}, report)
+	})
+
 	t.Run("runner error, stop", func(t *testing.T) {
 		r := NewMultiRunner()
 		errorHandled := false
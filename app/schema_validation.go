@@ -0,0 +1,172 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// SchemaValidator validates objects against the VersionSchema of a kind version, in the same way that kubernetes
+// validates custom resources against their CRD schema. Each top-level field of the object which has a schema
+// (such as spec and status) is validated against that schema. Fields without a schema (including metadata) are not validated.
+// SchemaValidator contains unexported fields, and must be created with NewSchemaValidator.
+type SchemaValidator struct {
+	// fields are the top-level fields with a schema, sorted so errors are returned in a consistent order
+	fields  []string
+	schemas map[string]*openapi3.Schema
+}
+
+// NewSchemaValidator creates a new SchemaValidator for the provided VersionSchema
+func NewSchemaValidator(schema *VersionSchema) (*SchemaValidator, error) {
+	if schema == nil {
+		return nil, errors.New("schema cannot be nil")
+	}
+	components, err := schema.AsOpenAPI3()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load schema: %w", err)
+	}
+	v := &SchemaValidator{
+		fields:  make([]string, 0, len(components.Schemas)),
+		schemas: make(map[string]*openapi3.Schema, len(components.Schemas)),
+	}
+	for field, ref := range components.Schemas {
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		v.fields = append(v.fields, field)
+		v.schemas[field] = ref.Value
+	}
+	sort.Strings(v.fields)
+	return v, nil
+}
+
+// ValidateObject validates obj against the schema. If obj does not match the schema,
+// it returns a *SchemaValidationError with every field which failed validation.
+func (v *SchemaValidator) ValidateObject(obj resource.Object) error {
+	buf := &bytes.Buffer{}
+	if err := resource.NewJSONCodec().Write(buf, obj); err != nil {
+		return fmt.Errorf("unable to encode object: %w", err)
+	}
+	raw := make(map[string]any)
+	if err := json.Unmarshal(buf.Bytes(), &raw); err != nil {
+		return fmt.Errorf("unable to decode object: %w", err)
+	}
+	fieldErrs := make([]SchemaFieldError, 0)
+	for _, field := range v.fields {
+		val, ok := raw[field]
+		if !ok || val == nil {
+			continue
+		}
+		err := v.schemas[field].VisitJSON(val, openapi3.MultiErrors(), openapi3.EnableFormatValidation())
+		if err != nil {
+			fieldErrs = append(fieldErrs, schemaFieldErrors(field, err)...)
+		}
+	}
+	if len(fieldErrs) > 0 {
+		return &SchemaValidationError{
+			Kind:   obj.GroupVersionKind().Kind,
+			Name:   obj.GetName(),
+			Fields: fieldErrs,
+		}
+	}
+	return nil
+}
+
+// Validate validates the object in a create or update admission request against the schema.
+// Other requests are always allowed. It implements resource.ValidatingAdmissionController,
+// so it can be used with a k8s.WebhookServer, or to wrap another ValidatingAdmissionController (see ValidateWith).
+func (v *SchemaValidator) Validate(_ context.Context, req *resource.AdmissionRequest) error {
+	if req == nil || req.Object == nil {
+		return nil
+	}
+	if req.Action != resource.AdmissionActionCreate && req.Action != resource.AdmissionActionUpdate {
+		return nil
+	}
+	return v.ValidateObject(req.Object)
+}
+
+// ValidateWith returns a resource.ValidatingAdmissionController which validates the request against the schema,
+// and then calls validator if the schema validation succeeds. validator may be nil.
+func (v *SchemaValidator) ValidateWith(validator resource.ValidatingAdmissionController) resource.ValidatingAdmissionController {
+	return &resource.SimpleValidatingAdmissionController{
+		ValidateFunc: func(ctx context.Context, req *resource.AdmissionRequest) error {
+			if err := v.Validate(ctx, req); err != nil {
+				return err
+			}
+			if validator == nil {
+				return nil
+			}
+			return validator.Validate(ctx, req)
+		},
+	}
+}
+
+// Compile-time interface compliance check
+var _ resource.ValidatingAdmissionController = &SchemaValidator{}
+
+// SchemaFieldError is a single field of an object which does not match its schema
+type SchemaFieldError struct {
+	// Path is the dot-separated path to the field, such as "spec.title"
+	Path string
+	// Reason is a human-readable description of why the field is invalid
+	Reason string
+}
+
+// SchemaValidationError is returned by SchemaValidator when an object does not match the schema.
+// It implements resource.AdmissionError, rejecting the request with a 422 (Unprocessable Entity) status code,
+// as kubernetes does for invalid custom resources.
+type SchemaValidationError struct {
+	Kind   string
+	Name   string
+	Fields []SchemaFieldError
+}
+
+func (e *SchemaValidationError) Error() string {
+	fields := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		fields[i] = fmt.Sprintf("%s: %s", f.Path, f.Reason)
+	}
+	return fmt.Sprintf("%s %q is invalid: %s", e.Kind, e.Name, strings.Join(fields, ", "))
+}
+
+// StatusCode returns http.StatusUnprocessableEntity
+func (*SchemaValidationError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+// Reason returns "Invalid", the kubernetes status reason for invalid objects
+func (*SchemaValidationError) Reason() string {
+	return "Invalid"
+}
+
+// schemaFieldErrors converts an error returned by openapi3.Schema.VisitJSON for the top-level field into SchemaFieldErrors
+func schemaFieldErrors(field string, err error) []SchemaFieldError {
+	// Type assertions are used instead of errors.As, as a SchemaError can wrap the MultiError of a nested schema,
+	// and unwrapping it would lose the path of the outer error
+	if multi, ok := err.(openapi3.MultiError); ok {
+		fieldErrs := make([]SchemaFieldError, 0, len(multi))
+		for _, e := range multi {
+			fieldErrs = append(fieldErrs, schemaFieldErrors(field, e)...)
+		}
+		return fieldErrs
+	}
+	if schemaErr, ok := err.(*openapi3.SchemaError); ok {
+		return []SchemaFieldError{{
+			Path:   strings.Join(append([]string{field}, schemaErr.JSONPointer()...), "."),
+			Reason: schemaErr.Reason,
+		}}
+	}
+	return []SchemaFieldError{{
+		Path:   field,
+		Reason: err.Error(),
+	}}
+}
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+var schemaTestGVK = schema.GroupVersionKind{Group: "test.grafana.app", Version: "v1", Kind: "Foo"}
+
+func newTestSchemaValidator(t *testing.T) *SchemaValidator {
+	vs, err := VersionSchemaFromMap(map[string]any{
+		"spec": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"title": map[string]any{
+					"type": "string",
+				},
+				"count": map[string]any{
+					"type":    "integer",
+					"minimum": 0,
+				},
+				"dueDate": map[string]any{
+					"type":   "string",
+					"format": "date-time",
+				},
+			},
+			"required": []any{"title"},
+		},
+		"status": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"state": map[string]any{
+					"type": "string",
+					"enum": []any{"open", "closed"},
+				},
+			},
+		},
+	})
+	require.Nil(t, err)
+	v, err := NewSchemaValidator(vs)
+	require.Nil(t, err)
+	return v
+}
+
+func newTestSchemaObject(spec, status map[string]any) *resource.UntypedObject {
+	obj := &resource.UntypedObject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "foo",
+		},
+		Spec: spec,
+	}
+	obj.SetGroupVersionKind(schemaTestGVK)
+	if status != nil {
+		obj.Subresources = map[string]json.RawMessage{}
+		raw, _ := json.Marshal(status)
+		obj.Subresources["status"] = raw
+	}
+	return obj
+}
+
+func TestNewSchemaValidator(t *testing.T) {
+	_, err := NewSchemaValidator(nil)
+	assert.Equal(t, errors.New("schema cannot be nil"), err)
+}
+
+func TestSchemaValidator_ValidateObject(t *testing.T) {
+	v := newTestSchemaValidator(t)
+
+	t.Run("valid", func(t *testing.T) {
+		err := v.ValidateObject(newTestSchemaObject(map[string]any{
+			"title":   "foo",
+			"count":   2,
+			"dueDate": "2024-01-01T00:00:00Z",
+			"extra":   true,
+		}, map[string]any{
+			"state": "open",
+		}))
+		assert.Nil(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		err := v.ValidateObject(newTestSchemaObject(map[string]any{
+			"count":   -1,
+			"dueDate": "tomorrow",
+		}, map[string]any{
+			"state": "pending",
+		}))
+		require.NotNil(t, err)
+		cast, ok := err.(*SchemaValidationError)
+		require.True(t, ok)
+		assert.Equal(t, "Foo", cast.Kind)
+		assert.Equal(t, "foo", cast.Name)
+		paths := make([]string, len(cast.Fields))
+		for i, f := range cast.Fields {
+			paths[i] = f.Path
+		}
+		assert.ElementsMatch(t, []string{"spec.title", "spec.count", "spec.dueDate", "status.state"}, paths)
+		assert.Equal(t, http.StatusUnprocessableEntity, cast.StatusCode())
+		assert.Equal(t, "Invalid", cast.Reason())
+	})
+}
+
+func TestSchemaValidator_Validate(t *testing.T) {
+	v := newTestSchemaValidator(t)
+	invalid := newTestSchemaObject(map[string]any{}, nil)
+
+	t.Run("create", func(t *testing.T) {
+		err := v.Validate(context.Background(), &resource.AdmissionRequest{
+			Action: resource.AdmissionActionCreate,
+			Object: invalid,
+		})
+		assert.Equal(t, `Foo "foo" is invalid: spec.title: property "title" is missing`, err.Error())
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		err := v.Validate(context.Background(), &resource.AdmissionRequest{
+			Action:    resource.AdmissionActionDelete,
+			OldObject: invalid,
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("with validator", func(t *testing.T) {
+		called := false
+		validator := v.ValidateWith(&resource.SimpleValidatingAdmissionController{
+			ValidateFunc: func(context.Context, *resource.AdmissionRequest) error {
+				called = true
+				return nil
+			},
+		})
+		err := validator.Validate(context.Background(), &resource.AdmissionRequest{
+			Action: resource.AdmissionActionUpdate,
+			Object: invalid,
+		})
+		assert.NotNil(t, err)
+		assert.False(t, called)
+		err = validator.Validate(context.Background(), &resource.AdmissionRequest{
+			Action: resource.AdmissionActionUpdate,
+			Object: newTestSchemaObject(map[string]any{"title": "foo"}, nil),
+		})
+		assert.Nil(t, err)
+		assert.True(t, called)
+	})
+}
@@ -0,0 +1,310 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana-app-sdk/metrics"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+const (
+	SizeLimitMaxBytes  = "maxBytes"
+	SizeLimitMaxItems  = "maxItems"
+	SizeLimitMaxLength = "maxLength"
+)
+
+// SizeLimitEnforcer enforces the SizeLimits of the kind versions in a manifest in validating admission requests,
+// and records the distribution of the size of admitted objects of those kind versions.
+// Creates are rejected if the object exceeds any limit. Updates are only rejected for limits which the update
+// exceeds by more than the existing object does, so that objects which predate a limit can still be updated
+// (and have their finalizers removed) as long as they don't grow. Updates of objects which are being deleted are not checked.
+// SizeLimitEnforcer contains unexported fields, and must be created with NewSizeLimitEnforcer.
+type SizeLimitEnforcer struct {
+	// limits are keyed by "<kind>/<version>"
+	limits     map[string]*compiledSizeLimits
+	objectSize *prometheus.HistogramVec
+	rejections *prometheus.CounterVec
+}
+
+type compiledSizeLimits struct {
+	maxBytes int64
+	fields   []sizeLimitField
+}
+
+type sizeLimitField struct {
+	limit string
+	path  []sizeLimitPathPart
+	max   int64
+}
+
+type sizeLimitPathPart struct {
+	name string
+	// each is true if the part selects every item of the array at name
+	each bool
+}
+
+// NewSizeLimitEnforcer creates a new SizeLimitEnforcer for the SizeLimits of every kind version in manifest.
+// It returns an error if any SizeLimits are invalid (see SizeLimits.Validate).
+func NewSizeLimitEnforcer(manifest ManifestData, cfg metrics.Config) (*SizeLimitEnforcer, error) {
+	e := &SizeLimitEnforcer{
+		limits: make(map[string]*compiledSizeLimits),
+		objectSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:                       cfg.Namespace,
+			Subsystem:                       "admission",
+			Name:                            "object_size_bytes",
+			Help:                            "Size (in bytes of JSON) of objects in create and update admission requests for kinds with size limits.",
+			Buckets:                         prometheus.ExponentialBuckets(256, 4, 9),
+			NativeHistogramBucketFactor:     cfg.NativeHistogramBucketFactor,
+			NativeHistogramMaxBucketNumber:  cfg.NativeHistogramMaxBucketNumber,
+			NativeHistogramMinResetDuration: time.Hour,
+		}, []string{"kind", "version"}),
+		rejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Subsystem: "admission",
+			Name:      "size_limit_rejections_total",
+			Help:      "Total number of admission requests rejected for exceeding a size limit, by the limit exceeded.",
+		}, []string{"kind", "version", "limit"}),
+	}
+	for _, kind := range manifest.Kinds {
+		for _, version := range kind.Versions {
+			if version.SizeLimits == nil {
+				continue
+			}
+			if err := version.SizeLimits.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid size limits for %s/%s: %w", kind.Kind, version.Name, err)
+			}
+			e.limits[fmt.Sprintf("%s/%s", kind.Kind, version.Name)] = compileSizeLimits(*version.SizeLimits)
+		}
+	}
+	return e, nil
+}
+
+func compileSizeLimits(limits SizeLimits) *compiledSizeLimits {
+	compiled := &compiledSizeLimits{
+		maxBytes: limits.MaxBytes,
+		fields:   make([]sizeLimitField, 0, len(limits.MaxItems)+len(limits.MaxLength)),
+	}
+	add := func(limit string, paths map[string]int64) {
+		for _, path := range slices.Sorted(maps.Keys(paths)) {
+			parts := strings.Split(strings.TrimPrefix(path, "."), ".")
+			field := sizeLimitField{
+				limit: limit,
+				path:  make([]sizeLimitPathPart, len(parts)),
+				max:   paths[path],
+			}
+			for i, part := range parts {
+				name, each := strings.CutSuffix(part, "[*]")
+				field.path[i] = sizeLimitPathPart{name: name, each: each}
+			}
+			compiled.fields = append(compiled.fields, field)
+		}
+	}
+	add(SizeLimitMaxItems, limits.MaxItems)
+	add(SizeLimitMaxLength, limits.MaxLength)
+	return compiled
+}
+
+// Enforces returns true if the kind version has size limits
+func (e *SizeLimitEnforcer) Enforces(kind, version string) bool {
+	_, ok := e.limits[fmt.Sprintf("%s/%s", kind, version)]
+	return ok
+}
+
+// Validate checks the object in a create or update admission request against the size limits of its kind version.
+// If the object exceeds any limits, it returns a *SizeLimitError with every limit exceeded.
+// Requests for other actions, or kind versions without size limits, are always allowed.
+// It implements resource.ValidatingAdmissionController.
+func (e *SizeLimitEnforcer) Validate(_ context.Context, req *resource.AdmissionRequest) error {
+	if req == nil || req.Object == nil {
+		return nil
+	}
+	if req.Action != resource.AdmissionActionCreate && req.Action != resource.AdmissionActionUpdate {
+		return nil
+	}
+	limits, ok := e.limits[fmt.Sprintf("%s/%s", req.Kind, req.Version)]
+	if !ok {
+		return nil
+	}
+	size, violations, err := limits.check(req.Object)
+	if err != nil {
+		return err
+	}
+	e.objectSize.WithLabelValues(req.Kind, req.Version).Observe(float64(size))
+	if len(violations) > 0 && req.Action == resource.AdmissionActionUpdate && req.OldObject != nil {
+		if req.Object.GetDeletionTimestamp() != nil {
+			return nil
+		}
+		_, existing, err := limits.check(req.OldObject)
+		if err != nil {
+			return err
+		}
+		violations = slices.DeleteFunc(violations, func(v SizeLimitViolation) bool {
+			return slices.ContainsFunc(existing, func(old SizeLimitViolation) bool {
+				return old.Limit == v.Limit && old.Path == v.Path && old.Actual >= v.Actual
+			})
+		})
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	for _, v := range violations {
+		e.rejections.WithLabelValues(req.Kind, req.Version, v.Limit).Inc()
+	}
+	return &SizeLimitError{
+		Kind:       req.Kind,
+		Name:       req.Object.GetName(),
+		Violations: violations,
+	}
+}
+
+// PrometheusCollectors returns the prometheus metric collectors used by the SizeLimitEnforcer
+func (e *SizeLimitEnforcer) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{e.objectSize, e.rejections}
+}
+
+// Compile-time interface compliance check
+var _ resource.ValidatingAdmissionController = &SizeLimitEnforcer{}
+
+// check returns the size of obj in bytes, and every limit obj exceeds
+func (c *compiledSizeLimits) check(obj resource.Object) (int64, []SizeLimitViolation, error) {
+	buf := &bytes.Buffer{}
+	if err := resource.NewJSONCodec().Write(buf, obj); err != nil {
+		return 0, nil, fmt.Errorf("unable to encode object: %w", err)
+	}
+	size := int64(buf.Len())
+	violations := make([]SizeLimitViolation, 0)
+	if c.maxBytes > 0 && size > c.maxBytes {
+		violations = append(violations, SizeLimitViolation{
+			Limit:  SizeLimitMaxBytes,
+			Max:    c.maxBytes,
+			Actual: size,
+		})
+	}
+	if len(c.fields) == 0 {
+		return size, violations, nil
+	}
+	raw := make(map[string]any)
+	if err := json.Unmarshal(buf.Bytes(), &raw); err != nil {
+		return 0, nil, fmt.Errorf("unable to decode object: %w", err)
+	}
+	for _, field := range c.fields {
+		visitSizeLimitPath(raw, field.path, "", func(path string, val any) {
+			var actual int64
+			switch field.limit {
+			case SizeLimitMaxItems:
+				switch cast := val.(type) {
+				case []any:
+					actual = int64(len(cast))
+				case map[string]any:
+					actual = int64(len(cast))
+				default:
+					return
+				}
+			case SizeLimitMaxLength:
+				str, ok := val.(string)
+				if !ok {
+					return
+				}
+				actual = int64(utf8.RuneCountInString(str))
+			}
+			if actual > field.max {
+				violations = append(violations, SizeLimitViolation{
+					Limit:  field.limit,
+					Path:   path,
+					Max:    field.max,
+					Actual: actual,
+				})
+			}
+		})
+	}
+	return size, violations, nil
+}
+
+// visitSizeLimitPath calls visit with each value at path in val, and its concrete path (with array indices)
+func visitSizeLimitPath(val any, path []sizeLimitPathPart, prefix string, visit func(path string, val any)) {
+	if len(path) == 0 {
+		visit(prefix, val)
+		return
+	}
+	obj, ok := val.(map[string]any)
+	if !ok {
+		return
+	}
+	child, ok := obj[path[0].name]
+	if !ok || child == nil {
+		return
+	}
+	if prefix != "" {
+		prefix += "."
+	}
+	prefix += path[0].name
+	if !path[0].each {
+		visitSizeLimitPath(child, path[1:], prefix, visit)
+		return
+	}
+	items, _ := child.([]any)
+	for i, item := range items {
+		visitSizeLimitPath(item, path[1:], fmt.Sprintf("%s[%d]", prefix, i), visit)
+	}
+}
+
+// SizeLimitViolation is a single size limit exceeded by an object
+type SizeLimitViolation struct {
+	// Limit is the limit which was exceeded: SizeLimitMaxBytes, SizeLimitMaxItems, or SizeLimitMaxLength
+	Limit string
+	// Path is the path to the field which exceeded the limit, such as "spec.targets[2].name".
+	// It is empty for SizeLimitMaxBytes.
+	Path string
+	// Max is the value of the limit
+	Max int64
+	// Actual is the size of the object or field
+	Actual int64
+}
+
+func (v SizeLimitViolation) String() string {
+	switch v.Limit {
+	case SizeLimitMaxBytes:
+		return fmt.Sprintf("object is %d bytes, the maximum is %d", v.Actual, v.Max)
+	case SizeLimitMaxItems:
+		return fmt.Sprintf("%s has %d items, the maximum is %d", v.Path, v.Actual, v.Max)
+	default:
+		return fmt.Sprintf("%s is %d characters long, the maximum is %d", v.Path, v.Actual, v.Max)
+	}
+}
+
+// SizeLimitError is returned by SizeLimitEnforcer when an object exceeds the size limits of its kind version.
+// It implements resource.AdmissionError, rejecting the request with a 422 (Unprocessable Entity) status code.
+type SizeLimitError struct {
+	Kind       string
+	Name       string
+	Violations []SizeLimitViolation
+}
+
+func (e *SizeLimitError) Error() string {
+	violations := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		violations[i] = v.String()
+	}
+	return fmt.Sprintf("%s %q exceeds size limits: %s", e.Kind, e.Name, strings.Join(violations, "; "))
+}
+
+// StatusCode returns http.StatusUnprocessableEntity
+func (*SizeLimitError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+// Reason returns "Invalid", the kubernetes status reason for invalid objects
+func (*SizeLimitError) Reason() string {
+	return "Invalid"
+}
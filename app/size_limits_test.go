@@ -0,0 +1,162 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana-app-sdk/metrics"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func newTestSizeLimitEnforcer(t *testing.T, limits SizeLimits) *SizeLimitEnforcer {
+	e, err := NewSizeLimitEnforcer(ManifestData{
+		Kinds: []ManifestKind{{
+			Kind: "Foo",
+			Versions: []ManifestKindVersion{{
+				Name:       "v1",
+				SizeLimits: &limits,
+			}, {
+				Name: "v2",
+			}},
+		}},
+	}, metrics.DefaultConfig(""))
+	require.Nil(t, err)
+	return e
+}
+
+func TestSizeLimits_Validate(t *testing.T) {
+	assert.Nil(t, SizeLimits{
+		MaxBytes:  1024,
+		MaxItems:  map[string]int64{"spec.groups[*].members": 10, ".spec.labels": 5},
+		MaxLength: map[string]int64{"spec.title": 64},
+	}.Validate())
+	err := SizeLimits{
+		MaxBytes:  -1,
+		MaxItems:  map[string]int64{"spec.items[0]": 10},
+		MaxLength: map[string]int64{"spec.title": -5},
+	}.Validate()
+	assert.EqualError(t, err, "maxBytes cannot be negative, got -1\n"+
+		"maxItems path 'spec.items[0]' must be a path to a field, such as 'spec.foo'\n"+
+		"maxLength for 'spec.title' cannot be negative, got -5")
+
+	_, err = NewSizeLimitEnforcer(ManifestData{
+		Kinds: []ManifestKind{{
+			Kind:     "Foo",
+			Versions: []ManifestKindVersion{{Name: "v1", SizeLimits: &SizeLimits{MaxBytes: -1}}},
+		}},
+	}, metrics.DefaultConfig(""))
+	assert.EqualError(t, err, "invalid size limits for Foo/v1: maxBytes cannot be negative, got -1")
+}
+
+func TestSizeLimitEnforcer_Validate(t *testing.T) {
+	e := newTestSizeLimitEnforcer(t, SizeLimits{
+		MaxBytes: 512,
+		MaxItems: map[string]int64{
+			"spec.groups":            2,
+			"spec.groups[*].members": 2,
+		},
+		MaxLength: map[string]int64{
+			"spec.title": 5,
+		},
+	})
+	assert.True(t, e.Enforces("Foo", "v1"))
+	assert.False(t, e.Enforces("Foo", "v2"))
+
+	valid := newTestSchemaObject(map[string]any{
+		"title": "héllo",
+		"groups": []any{
+			map[string]any{"members": []any{"a", "b"}},
+		},
+	}, nil)
+	invalid := newTestSchemaObject(map[string]any{
+		"title": "too long",
+		"groups": []any{
+			map[string]any{"members": []any{"a"}},
+			map[string]any{"members": []any{"a", "b", "c"}},
+			map[string]any{},
+		},
+		"padding": strings.Repeat("x", 512),
+	}, nil)
+
+	t.Run("create", func(t *testing.T) {
+		err := e.Validate(context.Background(), &resource.AdmissionRequest{
+			Action:  resource.AdmissionActionCreate,
+			Kind:    "Foo",
+			Version: "v1",
+			Object:  valid,
+		})
+		assert.Nil(t, err)
+
+		err = e.Validate(context.Background(), &resource.AdmissionRequest{
+			Action:  resource.AdmissionActionCreate,
+			Kind:    "Foo",
+			Version: "v1",
+			Object:  invalid,
+		})
+		require.NotNil(t, err)
+		cast, ok := err.(*SizeLimitError)
+		require.True(t, ok)
+		require.Len(t, cast.Violations, 4)
+		assert.Equal(t, SizeLimitMaxBytes, cast.Violations[0].Limit)
+		assert.Equal(t, `Foo "foo" exceeds size limits: `+cast.Violations[0].String()+"; "+
+			"spec.groups has 3 items, the maximum is 2; "+
+			"spec.groups[1].members has 3 items, the maximum is 2; "+
+			"spec.title is 8 characters long, the maximum is 5", err.Error())
+		assert.Equal(t, http.StatusUnprocessableEntity, cast.StatusCode())
+	})
+
+	t.Run("other kind versions and actions", func(t *testing.T) {
+		err := e.Validate(context.Background(), &resource.AdmissionRequest{
+			Action:  resource.AdmissionActionCreate,
+			Kind:    "Foo",
+			Version: "v2",
+			Object:  invalid,
+		})
+		assert.Nil(t, err)
+		err = e.Validate(context.Background(), &resource.AdmissionRequest{
+			Action:    resource.AdmissionActionDelete,
+			Kind:      "Foo",
+			Version:   "v1",
+			OldObject: invalid,
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		// Limits exceeded by the existing object only reject updates which grow past them further
+		err := e.Validate(context.Background(), &resource.AdmissionRequest{
+			Action:    resource.AdmissionActionUpdate,
+			Kind:      "Foo",
+			Version:   "v1",
+			Object:    newTestSchemaObject(map[string]any{"title": "too long"}, nil),
+			OldObject: newTestSchemaObject(map[string]any{"title": "much too long"}, nil),
+		})
+		assert.Nil(t, err)
+		err = e.Validate(context.Background(), &resource.AdmissionRequest{
+			Action:    resource.AdmissionActionUpdate,
+			Kind:      "Foo",
+			Version:   "v1",
+			Object:    newTestSchemaObject(map[string]any{"title": "much too long"}, nil),
+			OldObject: newTestSchemaObject(map[string]any{"title": "too long"}, nil),
+		})
+		assert.EqualError(t, err, `Foo "foo" exceeds size limits: spec.title is 13 characters long, the maximum is 5`)
+
+		// Objects being deleted can always be updated, so finalizers can be removed
+		deleting := newTestSchemaObject(map[string]any{"title": "much too long"}, nil)
+		deleting.SetDeletionTimestamp(&metav1.Time{})
+		err = e.Validate(context.Background(), &resource.AdmissionRequest{
+			Action:    resource.AdmissionActionUpdate,
+			Kind:      "Foo",
+			Version:   "v1",
+			Object:    deleting,
+			OldObject: newTestSchemaObject(map[string]any{"title": "ok"}, nil),
+		})
+		assert.Nil(t, err)
+	})
+}
@@ -0,0 +1,376 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// versionSchemaExcludedFields are the top-level fields of a kind which are not part of a VersionSchema,
+// as they are consistent between every kind.
+var versionSchemaExcludedFields = map[string]struct{}{
+	"apiVersion": {},
+	"kind":       {},
+	"metadata":   {},
+}
+
+// openAPISchemaTyper is implemented by types which describe their own OpenAPI type and format,
+// such as metav1.Time and resource.Quantity. It matches the interface used by kube-openapi.
+type openAPISchemaTyper interface {
+	OpenAPISchemaType() []string
+	OpenAPISchemaFormat() string
+}
+
+var (
+	timeType             = reflect.TypeOf(time.Time{})
+	rawMessageType       = reflect.TypeOf(json.RawMessage{})
+	schemaTyperType      = reflect.TypeOf((*openAPISchemaTyper)(nil)).Elem()
+	definitionGetterType = reflect.TypeOf((*common.OpenAPIDefinitionGetter)(nil)).Elem()
+)
+
+// VersionSchemaFromGoType returns the VersionSchema of a kind version defined as a Go type, such as
+// a struct with embedded metav1.TypeMeta and metav1.ObjectMeta, and Spec and Status fields. obj may be a value or a pointer.
+// Each top-level JSON field of the type other than apiVersion, kind, and metadata becomes a schema in the VersionSchema.
+//
+// The schema is derived from the JSON encoding of the type: fields are named by their json tag, embedded structs
+// without a json name are inlined, and fields are required unless they are pointers or have omitempty or omitzero.
+// Types which implement OpenAPISchemaType and OpenAPISchemaFormat (such as metav1.Time and resource.Quantity),
+// or OpenAPIDefinition, describe their own schema, as they do for kube-openapi.
+// Fields can be further constrained with an openapi tag, which is a comma-separated list of:
+//   - optional or required, to override whether the field is required
+//   - enum=a|b|c, to restrict a string field to a set of values
+//   - format=<format>, pattern=<regex>, minimum=<n>, or maximum=<n>
+//
+// Go doc comments are not available at runtime, so schemas have no descriptions.
+// Use VersionSchemaFromOpenAPIDefinitions with the output of openapi-gen for a schema with descriptions.
+func VersionSchemaFromGoType(obj any) (*VersionSchema, error) {
+	typ := reflect.TypeOf(obj)
+	if typ == nil {
+		return nil, fmt.Errorf("obj cannot be nil")
+	}
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("obj must be a struct, got %s", typ)
+	}
+	r := goTypeSchemaReflector{
+		inProgress: map[reflect.Type]struct{}{typ: {}},
+	}
+	raw := make(map[string]any)
+	if err := r.addFields(typ, raw, new([]any), versionSchemaExcludedFields); err != nil {
+		return nil, err
+	}
+	return VersionSchemaFromMap(raw)
+}
+
+// goTypeSchemaReflector builds OpenAPI schemas for Go types
+type goTypeSchemaReflector struct {
+	// inProgress are the struct types whose schemas are being built, used to detect recursive types
+	inProgress map[reflect.Type]struct{}
+}
+
+//nolint:gocyclo
+func (r *goTypeSchemaReflector) schema(typ reflect.Type) (map[string]any, error) {
+	switch typ.Kind() {
+	case reflect.Pointer:
+		return r.schema(typ.Elem())
+	case reflect.Interface:
+		return map[string]any{"x-kubernetes-preserve-unknown-fields": true}, nil
+	default:
+	}
+
+	// Check both the type and a pointer to it, as the methods may use either receiver
+	for _, val := range []reflect.Value{reflect.New(typ).Elem(), reflect.New(typ)} {
+		if val.Type().Implements(definitionGetterType) {
+			return openAPISchemaToMap(val.Interface().(common.OpenAPIDefinitionGetter).OpenAPIDefinition().Schema)
+		}
+		if val.Type().Implements(schemaTyperType) {
+			typer := val.Interface().(openAPISchemaTyper)
+			if typer.OpenAPISchemaFormat() == "int-or-string" {
+				return map[string]any{"x-kubernetes-int-or-string": true}, nil
+			}
+			sch := make(map[string]any)
+			if types := typer.OpenAPISchemaType(); len(types) == 1 {
+				sch["type"] = types[0]
+			}
+			if format := typer.OpenAPISchemaFormat(); format != "" {
+				sch["format"] = format
+			}
+			return sch, nil
+		}
+	}
+
+	switch {
+	case typ == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}, nil
+	case typ == rawMessageType:
+		return map[string]any{"x-kubernetes-preserve-unknown-fields": true}, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return map[string]any{"type": "integer", "format": "int32"}, nil
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}, nil
+	case reflect.Float32:
+		return map[string]any{"type": "number", "format": "float"}, nil
+	case reflect.Float64:
+		return map[string]any{"type": "number", "format": "double"}, nil
+	case reflect.Slice, reflect.Array:
+		if typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8 {
+			// []byte is encoded as a base64 string, but byte arrays are encoded as arrays of numbers
+			return map[string]any{"type": "string", "format": "byte"}, nil
+		}
+		items, err := r.schema(typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+		schema := map[string]any{"type": "array", "items": items}
+		if typ.Kind() == reflect.Array {
+			// Arrays are always encoded with all of their elements
+			schema["minItems"] = typ.Len()
+			schema["maxItems"] = typ.Len()
+		}
+		return schema, nil
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map %s must have string keys", typ)
+		}
+		values, err := r.schema(typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		return r.structSchema(typ)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", typ)
+	}
+}
+
+func (r *goTypeSchemaReflector) structSchema(typ reflect.Type) (map[string]any, error) {
+	if _, ok := r.inProgress[typ]; ok {
+		return nil, fmt.Errorf("recursive type %s is not supported", typ)
+	}
+	r.inProgress[typ] = struct{}{}
+	defer delete(r.inProgress, typ)
+
+	props := make(map[string]any)
+	// required is []any, as it is in schemas decoded from JSON or YAML
+	required := make([]any, 0)
+	if err := r.addFields(typ, props, &required, nil); err != nil {
+		return nil, err
+	}
+	sch := map[string]any{
+		"type": "object",
+	}
+	if len(props) > 0 {
+		sch["properties"] = props
+	}
+	if len(required) > 0 {
+		sch["required"] = required
+	}
+	return sch, nil
+}
+
+// addFields adds the schema of each JSON-encoded field of the struct typ to props, including those of inlined structs,
+// except for the fields named in exclude
+func (r *goTypeSchemaReflector) addFields(typ reflect.Type, props map[string]any, required *[]any, exclude map[string]struct{}) error {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := r.addFields(embedded, props, required, exclude); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, ok := exclude[name]; ok {
+			continue
+		}
+		sch, err := r.schema(field.Type)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		isRequired := field.Type.Kind() != reflect.Pointer && !strings.Contains(opts, "omitempty") && !strings.Contains(opts, "omitzero")
+		isRequired, err = applyOpenAPITag(sch, field.Tag.Get("openapi"), isRequired)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		props[name] = sch
+		if isRequired {
+			*required = append(*required, name)
+		}
+	}
+	return nil
+}
+
+// applyOpenAPITag applies the constraints in an openapi struct tag to sch, and returns whether the field is required
+func applyOpenAPITag(sch map[string]any, tag string, required bool) (bool, error) {
+	if tag == "" {
+		return required, nil
+	}
+	for _, opt := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch key {
+		case "optional":
+			required = false
+		case "required":
+			required = true
+		case "enum":
+			values := strings.Split(value, "|")
+			enum := make([]any, len(values))
+			for i, v := range values {
+				enum[i] = v
+			}
+			sch["enum"] = enum
+		case "format", "pattern":
+			sch[key] = value
+		case "minimum", "maximum":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return false, fmt.Errorf("invalid openapi tag %s: %w", key, err)
+			}
+			sch[key] = n
+		default:
+			return false, fmt.Errorf("unknown openapi tag option '%s'", key)
+		}
+	}
+	return required, nil
+}
+
+// VersionSchemaFromOpenAPIDefinitions returns the VersionSchema of the kind version named name (such as
+// "github.com/example/pkg/apis/foo/v1.Foo") in the definitions from getDefinitions, which is usually
+// the GetOpenAPIDefinitions function generated by openapi-gen. References to other definitions are inlined,
+// as a VersionSchema is self-contained. Each top-level property of the kind other than apiVersion, kind, and metadata
+// (such as spec and status) becomes a schema in the VersionSchema.
+func VersionSchemaFromOpenAPIDefinitions(getDefinitions common.GetOpenAPIDefinitions, name string) (*VersionSchema, error) {
+	defs := getDefinitions(func(path string) spec.Ref {
+		return spec.MustCreateRef("#/definitions/" + common.EscapeJsonPointer(path))
+	})
+	def, ok := defs[name]
+	if !ok {
+		return nil, fmt.Errorf("no definition for %s", name)
+	}
+	object, err := openAPISchemaToMap(def.Schema)
+	if err != nil {
+		return nil, err
+	}
+	resolver := definitionResolver{
+		definitions: defs,
+		inProgress:  map[string]struct{}{name: {}},
+	}
+	raw := make(map[string]any)
+	props, _ := object["properties"].(map[string]any)
+	for key, prop := range props {
+		if _, ok := versionSchemaExcludedFields[key]; ok {
+			continue
+		}
+		resolved, err := resolver.resolve(prop)
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", key, err)
+		}
+		raw[key] = resolved
+	}
+	return VersionSchemaFromMap(raw)
+}
+
+// definitionResolver inlines references to OpenAPI definitions
+type definitionResolver struct {
+	definitions map[string]common.OpenAPIDefinition
+	// inProgress are the definitions being inlined, used to detect recursive definitions
+	inProgress map[string]struct{}
+}
+
+// resolve returns v with each reference to a definition replaced by the definition
+func (d *definitionResolver) resolve(v any) (any, error) {
+	switch cast := v.(type) {
+	case []any:
+		out := make([]any, len(cast))
+		for i, item := range cast {
+			resolved, err := d.resolve(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	case map[string]any:
+		out := make(map[string]any, len(cast))
+		if ref, ok := cast["$ref"].(string); ok {
+			name := strings.ReplaceAll(strings.ReplaceAll(strings.TrimPrefix(ref, "#/definitions/"), "~1", "/"), "~0", "~")
+			if _, ok := d.inProgress[name]; ok {
+				return nil, fmt.Errorf("recursive definition %s is not supported", name)
+			}
+			def, ok := d.definitions[name]
+			if !ok {
+				return nil, fmt.Errorf("no definition for %s", name)
+			}
+			sch, err := openAPISchemaToMap(def.Schema)
+			if err != nil {
+				return nil, err
+			}
+			d.inProgress[name] = struct{}{}
+			resolved, err := d.resolve(sch)
+			delete(d.inProgress, name)
+			if err != nil {
+				return nil, err
+			}
+			for key, value := range resolved.(map[string]any) {
+				out[key] = value
+			}
+		}
+		for key, value := range cast {
+			// Properties next to a reference (such as a description or default) take precedence over the definition's
+			if key == "$ref" {
+				continue
+			}
+			resolved, err := d.resolve(value)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = resolved
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// openAPISchemaToMap returns the JSON representation of sch as a map[string]any
+func openAPISchemaToMap(sch spec.Schema) (map[string]any, error) {
+	b, err := json.Marshal(sch)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]any)
+	err = json.Unmarshal(b, &m)
+	return m, err
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+type testGoKind struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              testGoKindSpec    `json:"spec"`
+	Status            *testGoKindStatus `json:"status,omitempty"`
+}
+
+type testGoKindSpec struct {
+	testGoKindCommon
+	Title    string             `json:"title" openapi:"pattern=^[a-z]+$"`
+	Mode     string             `json:"mode,omitempty" openapi:"enum=a|b"`
+	Replicas int32              `json:"replicas" openapi:"optional,minimum=1"`
+	Port     intstr.IntOrString `json:"port"`
+	Labels   map[string]string  `json:"labels,omitempty"`
+	Data     []byte             `json:"data,omitempty"`
+	Checksum [4]byte            `json:"checksum,omitempty"`
+	Extra    any                `json:"extra,omitempty"`
+	Ignored  string             `json:"-"`
+	internal string
+}
+
+type testGoKindCommon struct {
+	Tags []string `json:"tags,omitempty"`
+}
+
+type testGoKindStatus struct {
+	LastUpdated metav1.Time `json:"lastUpdated"`
+}
+
+type testRecursiveGoKind struct {
+	Spec testRecursiveGoKindSpec `json:"spec"`
+}
+
+type testRecursiveGoKindSpec struct {
+	Children []testRecursiveGoKindSpec `json:"children"`
+}
+
+func TestVersionSchemaFromGoType(t *testing.T) {
+	vs, err := VersionSchemaFromGoType(&testGoKind{})
+	require.Nil(t, err)
+	assert.Equal(t, map[string]any{
+		"spec": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"tags":     map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+				"title":    map[string]any{"type": "string", "pattern": "^[a-z]+$"},
+				"mode":     map[string]any{"type": "string", "enum": []any{"a", "b"}},
+				"replicas": map[string]any{"type": "integer", "format": "int32", "minimum": float64(1)},
+				"port":     map[string]any{"x-kubernetes-int-or-string": true},
+				"labels":   map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+				"data":     map[string]any{"type": "string", "format": "byte"},
+				"checksum": map[string]any{
+					"type":     "array",
+					"items":    map[string]any{"type": "integer", "format": "int32"},
+					"minItems": 4,
+					"maxItems": 4,
+				},
+				"extra": map[string]any{"x-kubernetes-preserve-unknown-fields": true},
+			},
+			"required": []any{"title", "port"},
+		},
+		"status": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"lastUpdated": map[string]any{"type": "string", "format": "date-time"},
+			},
+			"required": []any{"lastUpdated"},
+		},
+	}, vs.AsMap())
+	// The schema can be used like any other manifest schema
+	_, err = vs.AsOpenAPI3()
+	assert.Nil(t, err)
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := VersionSchemaFromGoType(nil)
+		assert.Equal(t, errors.New("obj cannot be nil"), err)
+		_, err = VersionSchemaFromGoType("foo")
+		assert.EqualError(t, err, "obj must be a struct, got string")
+		_, err = VersionSchemaFromGoType(testRecursiveGoKind{})
+		assert.EqualError(t, err, "field Spec: field Children: recursive type app.testRecursiveGoKindSpec is not supported")
+		_, err = VersionSchemaFromGoType(struct {
+			Spec struct {
+				Foo string `openapi:"bar"`
+			} `json:"spec"`
+		}{})
+		assert.EqualError(t, err, "field Spec: field Foo: unknown openapi tag option 'bar'")
+	})
+}
+
+func TestVersionSchemaFromOpenAPIDefinitions(t *testing.T) {
+	getDefinitions := func(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
+		return map[string]common.OpenAPIDefinition{
+			"example.com/apis/v1.Foo": {
+				Schema: spec.Schema{
+					SchemaProps: spec.SchemaProps{
+						Type: []string{"object"},
+						Properties: map[string]spec.Schema{
+							"metadata": {SchemaProps: spec.SchemaProps{Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta")}},
+							"spec": {SchemaProps: spec.SchemaProps{
+								Description: "Spec of the Foo",
+								Ref:         ref("example.com/apis/v1.FooSpec"),
+							}},
+						},
+					},
+				},
+			},
+			"example.com/apis/v1.FooSpec": {
+				Schema: spec.Schema{
+					SchemaProps: spec.SchemaProps{
+						Description: "FooSpec is the spec of a Foo",
+						Type:        []string{"object"},
+						Required:    []string{"bar"},
+						Properties: map[string]spec.Schema{
+							"bar": {SchemaProps: spec.SchemaProps{Type: []string{"string"}}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	vs, err := VersionSchemaFromOpenAPIDefinitions(getDefinitions, "example.com/apis/v1.Foo")
+	require.Nil(t, err)
+	assert.Equal(t, map[string]any{
+		"spec": map[string]any{
+			"description": "Spec of the Foo",
+			"type":        "object",
+			"required":    []any{"bar"},
+			"properties": map[string]any{
+				"bar": map[string]any{"type": "string"},
+			},
+		},
+	}, vs.AsMap())
+
+	_, err = VersionSchemaFromOpenAPIDefinitions(getDefinitions, "example.com/apis/v1.Bar")
+	assert.EqualError(t, err, "no definition for example.com/apis/v1.Bar")
+}
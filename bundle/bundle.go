@@ -0,0 +1,282 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// IndexFile is the path of the bundle's index in the archive, which lists every file in the bundle with its digest
+	IndexFile = "bundle.json"
+	// SignatureFile is the path of the bundle's signature in the archive, which is the ed25519 signature of the IndexFile
+	SignatureFile = "bundle.sig"
+	// IndexVersion is the version of the index format written by Bundle.Write
+	IndexVersion = 1
+
+	// maxFileSize is the maximum size of a file in a bundle index
+	maxFileSize = 64 << 20
+	// maxIndexSize is the maximum size of the IndexFile read from an archive, which is read before it is verified
+	maxIndexSize = 4 << 20
+)
+
+// File is a file in a Bundle
+type File struct {
+	// Path is the slash-separated path of the file in the bundle, such as "crds/foo.example.com.yaml"
+	Path string
+	// Data is the contents of the file
+	Data []byte
+}
+
+// Bundle is everything an app needs to be installed without access to its source or a registry, such as the app manifest,
+// CRDs, RBAC, webhook configurations, and dashboards. It is written as a signed archive with Write, and read with Read,
+// which verifies the signature and the digest of every file.
+type Bundle struct {
+	// AppName is the name of the app the bundle installs
+	AppName string
+	// Files are the files in the bundle. Paths must be unique, relative, and cannot be IndexFile or SignatureFile.
+	Files []File
+}
+
+// Index is the index of a bundle archive, which is signed to verify the contents of the bundle
+type Index struct {
+	Version int          `json:"version"`
+	AppName string       `json:"appName"`
+	Files   []IndexEntry `json:"files"`
+}
+
+// IndexEntry is a single file in an Index
+type IndexEntry struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// Validate checks that the bundle has an AppName, and that every file has a valid and unique path
+func (b *Bundle) Validate() error {
+	if b.AppName == "" {
+		return errors.New("bundle AppName cannot be empty")
+	}
+	seen := make(map[string]struct{}, len(b.Files))
+	errs := make([]error, 0)
+	for _, f := range b.Files {
+		if err := validatePath(f.Path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, ok := seen[f.Path]; ok {
+			errs = append(errs, fmt.Errorf("file '%s' is repeated", f.Path))
+		}
+		seen[f.Path] = struct{}{}
+	}
+	return errors.Join(errs...)
+}
+
+// Index returns the index of the bundle
+func (b *Bundle) Index() Index {
+	index := Index{
+		Version: IndexVersion,
+		AppName: b.AppName,
+		Files:   make([]IndexEntry, len(b.Files)),
+	}
+	for i, f := range b.Files {
+		sum := sha256.Sum256(f.Data)
+		index.Files[i] = IndexEntry{
+			Path:   f.Path,
+			SHA256: hex.EncodeToString(sum[:]),
+			Size:   int64(len(f.Data)),
+		}
+	}
+	return index
+}
+
+// Write writes the bundle to w as a gzipped tar archive, signed with key. The archive contains the IndexFile,
+// the SignatureFile, and then each of the files in the bundle. File modification times are not included,
+// so the same bundle and key always produce the same archive.
+func (b *Bundle) Write(w io.Writer, key ed25519.PrivateKey) error {
+	if len(key) != ed25519.PrivateKeySize {
+		return errors.New("invalid private key")
+	}
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	index, err := json.MarshalIndent(b.Index(), "", "  ")
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	write := func(name string, data []byte) error {
+		err := tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0o644,
+			Size:     int64(len(data)),
+			ModTime:  time.Unix(0, 0),
+			Typeflag: tar.TypeReg,
+			Format:   tar.FormatPAX,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = tw.Write(data)
+		return err
+	}
+	if err = write(IndexFile, index); err != nil {
+		return err
+	}
+	if err = write(SignatureFile, ed25519.Sign(key, index)); err != nil {
+		return err
+	}
+	for _, f := range b.Files {
+		if err = write(f.Path, f.Data); err != nil {
+			return err
+		}
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Read reads a bundle archive written by Bundle.Write from r, and verifies it with key.
+// It returns an error if the index is not signed by key, if any file doesn't match its digest in the index,
+// or if the archive has any files which are missing from the index (or is missing any files in it).
+// The index and signature must be the first entries of the archive, so that the index is verified before any files
+// are read, and each file is checked against its signed size as it is read, so unsigned data is never buffered.
+func Read(r io.Reader, key ed25519.PublicKey) (*Bundle, error) {
+	if len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key")
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("bundle is not a gzipped archive: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	rawIndex, err := readEntry(tr, IndexFile, maxIndexSize)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := readEntry(tr, SignatureFile, ed25519.SignatureSize)
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(key, rawIndex, signature) {
+		return nil, errors.New("bundle signature is not valid for the public key")
+	}
+	index := Index{}
+	if err = json.Unmarshal(rawIndex, &index); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", IndexFile, err)
+	}
+	if index.Version != IndexVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", index.Version)
+	}
+	entries := make(map[string]IndexEntry, len(index.Files))
+	for _, entry := range index.Files {
+		if entry.Size < 0 || entry.Size > maxFileSize {
+			return nil, fmt.Errorf("file '%s' is larger than %d bytes", entry.Path, maxFileSize)
+		}
+		entries[entry.Path] = entry
+	}
+
+	contents := make(map[string][]byte, len(index.Files))
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read bundle archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("bundle archive entry '%s' is not a regular file", header.Name)
+		}
+		entry, ok := entries[header.Name]
+		if !ok {
+			return nil, fmt.Errorf("file '%s' is in the archive, but not the bundle index", header.Name)
+		}
+		if _, ok := contents[header.Name]; ok {
+			return nil, fmt.Errorf("bundle archive entry '%s' is repeated", header.Name)
+		}
+		if header.Size != entry.Size {
+			return nil, fmt.Errorf("file '%s' does not match its digest in the bundle index", entry.Path)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, entry.Size))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read bundle archive entry '%s': %w", header.Name, err)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != entry.SHA256 || int64(len(data)) != entry.Size {
+			return nil, fmt.Errorf("file '%s' does not match its digest in the bundle index", entry.Path)
+		}
+		contents[header.Name] = data
+	}
+
+	b := &Bundle{
+		AppName: index.AppName,
+		Files:   make([]File, 0, len(index.Files)),
+	}
+	for _, entry := range index.Files {
+		data, ok := contents[entry.Path]
+		if !ok {
+			return nil, fmt.Errorf("file '%s' is in the bundle index, but not the archive", entry.Path)
+		}
+		b.Files = append(b.Files, File{
+			Path: entry.Path,
+			Data: data,
+		})
+	}
+	if err = b.Validate(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// readEntry reads the next entry of tr, which must be the regular file name, and no larger than maxSize
+func readEntry(tr *tar.Reader, name string, maxSize int64) ([]byte, error) {
+	header, err := tr.Next()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("bundle has no %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read bundle archive: %w", err)
+	}
+	if header.Name != name || header.Typeflag != tar.TypeReg {
+		return nil, fmt.Errorf("bundle archive must begin with %s and %s, not '%s'", IndexFile, SignatureFile, header.Name)
+	}
+	if header.Size > maxSize {
+		return nil, fmt.Errorf("bundle archive entry '%s' is larger than %d bytes", name, maxSize)
+	}
+	data, err := io.ReadAll(io.LimitReader(tr, maxSize))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read bundle archive entry '%s': %w", name, err)
+	}
+	return data, nil
+}
+
+// Verify reads and verifies the bundle archive in data with key. See Read.
+func Verify(data []byte, key ed25519.PublicKey) (*Bundle, error) {
+	return Read(bytes.NewReader(data), key)
+}
+
+func validatePath(p string) error {
+	switch {
+	case p == "":
+		return errors.New("file path cannot be empty")
+	case p == IndexFile || p == SignatureFile:
+		return fmt.Errorf("file path '%s' is reserved", p)
+	case path.IsAbs(p) || strings.Contains(p, "\\") || path.Clean(p) != p || p == ".." || strings.HasPrefix(p, "../"):
+		return fmt.Errorf("file path '%s' must be a clean, relative, slash-separated path", p)
+	}
+	return nil
+}
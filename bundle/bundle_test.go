@@ -0,0 +1,175 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBundle() *Bundle {
+	return &Bundle{
+		AppName: "test-app",
+		Files: []File{{
+			Path: "crds/foo.test.grafana.app.yaml",
+			Data: []byte("apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: foos.test.grafana.app\n"),
+		}, {
+			Path: "dashboards/test-app.json",
+			Data: []byte(`{"title":"test-app"}`),
+		}},
+	}
+}
+
+func testKeys(t *testing.T) (ed25519.PrivateKey, ed25519.PublicKey) {
+	privatePEM, publicPEM, err := GenerateKey()
+	require.Nil(t, err)
+	private, err := ParsePrivateKey(privatePEM)
+	require.Nil(t, err)
+	public, err := ParsePublicKey(publicPEM)
+	require.Nil(t, err)
+	return private, public
+}
+
+func TestBundle_WriteRead(t *testing.T) {
+	private, public := testKeys(t)
+	b := testBundle()
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, b.Write(buf, private))
+	read, err := Verify(buf.Bytes(), public)
+	require.Nil(t, err)
+	assert.Equal(t, b, read)
+
+	// The archive is reproducible
+	again := &bytes.Buffer{}
+	require.Nil(t, b.Write(again, private))
+	assert.Equal(t, buf.Bytes(), again.Bytes())
+
+	t.Run("wrong key", func(t *testing.T) {
+		_, other := testKeys(t)
+		_, err := Verify(buf.Bytes(), other)
+		assert.EqualError(t, err, "bundle signature is not valid for the public key")
+	})
+
+	t.Run("modified file", func(t *testing.T) {
+		data := rewriteArchive(t, buf.Bytes(), func(name string, data []byte) []byte {
+			if name == "dashboards/test-app.json" {
+				return []byte(`{"title":"other"}`)
+			}
+			return data
+		}, nil)
+		_, err := Verify(data, public)
+		assert.EqualError(t, err, "file 'dashboards/test-app.json' does not match its digest in the bundle index")
+	})
+
+	t.Run("extra file", func(t *testing.T) {
+		data := rewriteArchive(t, buf.Bytes(), nil, &File{Path: "rbac/extra.yaml", Data: []byte("kind: List")})
+		_, err := Verify(data, public)
+		assert.EqualError(t, err, "file 'rbac/extra.yaml' is in the archive, but not the bundle index")
+	})
+
+	t.Run("file larger than its index entry", func(t *testing.T) {
+		data := rewriteArchive(t, buf.Bytes(), func(name string, data []byte) []byte {
+			if name == "dashboards/test-app.json" {
+				return bytes.Repeat([]byte("a"), 1<<20)
+			}
+			return data
+		}, nil)
+		_, err := Verify(data, public)
+		assert.EqualError(t, err, "file 'dashboards/test-app.json' does not match its digest in the bundle index")
+	})
+
+	t.Run("files before the index", func(t *testing.T) {
+		data := writeTestArchive(t, b.Files[0])
+		_, err := Verify(data, public)
+		assert.EqualError(t, err, "bundle archive must begin with bundle.json and bundle.sig, not 'crds/foo.test.grafana.app.yaml'")
+	})
+
+	t.Run("not an archive", func(t *testing.T) {
+		_, err := Verify([]byte("foo"), public)
+		assert.ErrorContains(t, err, "bundle is not a gzipped archive")
+	})
+}
+
+func TestBundle_Validate(t *testing.T) {
+	assert.EqualError(t, (&Bundle{}).Validate(), "bundle AppName cannot be empty")
+	err := (&Bundle{
+		AppName: "foo",
+		Files: []File{
+			{Path: "a.yaml"},
+			{Path: "a.yaml"},
+			{Path: "../a.yaml"},
+			{Path: "/a.yaml"},
+			{Path: "a/./b.yaml"},
+			{Path: IndexFile},
+		},
+	}).Validate()
+	assert.EqualError(t, err, "file 'a.yaml' is repeated\n"+
+		"file path '../a.yaml' must be a clean, relative, slash-separated path\n"+
+		"file path '/a.yaml' must be a clean, relative, slash-separated path\n"+
+		"file path 'a/./b.yaml' must be a clean, relative, slash-separated path\n"+
+		"file path 'bundle.json' is reserved")
+}
+
+func TestParseKeys(t *testing.T) {
+	privatePEM, publicPEM, err := GenerateKey()
+	require.Nil(t, err)
+	_, err = ParsePrivateKey(publicPEM)
+	assert.EqualError(t, err, "key is not a PEM-encoded PRIVATE KEY")
+	_, err = ParsePublicKey(privatePEM)
+	assert.EqualError(t, err, "key is not a PEM-encoded PUBLIC KEY")
+}
+
+// writeTestArchive writes a gzipped tar archive of files, in order
+func writeTestArchive(t *testing.T, files ...File) []byte {
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	for _, f := range files {
+		require.Nil(t, tw.WriteHeader(&tar.Header{Name: f.Path, Mode: 0o644, Size: int64(len(f.Data)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(f.Data)
+		require.Nil(t, err)
+	}
+	require.Nil(t, tw.Close())
+	require.Nil(t, gw.Close())
+	return buf.Bytes()
+}
+
+// rewriteArchive rewrites a bundle archive, replacing each file's contents with the result of replace, and appending extra
+func rewriteArchive(t *testing.T, archive []byte, replace func(name string, data []byte) []byte, extra *File) []byte {
+	gr, err := gzip.NewReader(bytes.NewReader(archive))
+	require.Nil(t, err)
+	tr := tar.NewReader(gr)
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	write := func(name string, data []byte) {
+		require.Nil(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(data)
+		require.Nil(t, err)
+	}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err)
+		data, err := io.ReadAll(tr)
+		require.Nil(t, err)
+		if replace != nil {
+			data = replace(header.Name, data)
+		}
+		write(header.Name, data)
+	}
+	if extra != nil {
+		write(extra.Path, extra.Data)
+	}
+	require.Nil(t, tw.Close())
+	require.Nil(t, gw.Close())
+	return buf.Bytes()
+}
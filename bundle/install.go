@@ -0,0 +1,311 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+)
+
+// DefaultFieldManager is the field manager used by a DynamicApplier if none is provided
+const DefaultFieldManager = "grafana-app-sdk-bundle"
+
+const defaultEstablishTimeout = time.Minute
+
+var crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// Applier applies kubernetes objects from a bundle to a cluster. Apply must be idempotent,
+// so installing the same bundle more than once leaves the cluster in the same state.
+type Applier interface {
+	Apply(ctx context.Context, obj *unstructured.Unstructured) error
+}
+
+// CRDWaiter is an Applier which can wait for the CustomResourceDefinitions it applies to be established,
+// so that custom resources of their kinds can be applied.
+type CRDWaiter interface {
+	Applier
+	// WaitForEstablished blocks until crd is established, or returns an error if it is not established in time
+	WaitForEstablished(ctx context.Context, crd *unstructured.Unstructured) error
+}
+
+// InstallResult is the result of a successful Install
+type InstallResult struct {
+	// Applied are the objects which were applied, in the order they were applied
+	Applied []*unstructured.Unstructured
+	// Skipped are the paths of files in the bundle which don't contain kubernetes objects, such as dashboards
+	Skipped []string
+	// Unserved are the optional objects which were not applied, as the cluster doesn't serve their kind.
+	// The only optional kind is the AppManifest, which requires the AppManifest CRD to be installed separately.
+	Unserved []*unstructured.Unstructured
+}
+
+// Install applies every kubernetes object in the bundle with applier. Files are decoded as YAML or JSON documents,
+// and objects of kind List are expanded into their items. Files which don't contain kubernetes objects are skipped.
+// CustomResourceDefinitions are applied first, so that custom resources in the bundle can be applied after them.
+// If applier is a CRDWaiter, it waits for each CRD to be established before any other objects are applied.
+// Webhook configurations are applied last, so that they don't intercept requests for the rest of the bundle's objects.
+// The bundle does not contain the AppManifest CRD, so if the cluster doesn't serve the AppManifest kind,
+// the app manifest is not applied, and is returned in InstallResult.Unserved.
+func Install(ctx context.Context, b *Bundle, applier Applier) (*InstallResult, error) {
+	if applier == nil {
+		return nil, errors.New("applier cannot be nil")
+	}
+	result := &InstallResult{
+		Applied:  make([]*unstructured.Unstructured, 0),
+		Skipped:  make([]string, 0),
+		Unserved: make([]*unstructured.Unstructured, 0),
+	}
+	objs := make([]*unstructured.Unstructured, 0)
+	for _, f := range b.Files {
+		decoded, err := decodeObjects(f)
+		if err != nil {
+			return nil, err
+		}
+		if len(decoded) == 0 {
+			result.Skipped = append(result.Skipped, f.Path)
+			continue
+		}
+		objs = append(objs, decoded...)
+	}
+	slices.SortStableFunc(objs, func(a, b *unstructured.Unstructured) int {
+		return installOrder(a) - installOrder(b)
+	})
+	waiter, _ := applier.(CRDWaiter)
+	crds := make([]*unstructured.Unstructured, 0)
+	for _, obj := range objs {
+		if waiter != nil && len(crds) > 0 && installOrder(obj) > 0 {
+			for _, crd := range crds {
+				logging.FromContext(ctx).Debug("waiting for CRD to be established", "name", crd.GetName())
+				if err := waiter.WaitForEstablished(ctx, crd); err != nil {
+					return nil, fmt.Errorf("CustomResourceDefinition %q was not established: %w", crd.GetName(), err)
+				}
+			}
+			crds = crds[:0]
+		}
+		logging.FromContext(ctx).Debug("applying bundle object", "kind", obj.GetKind(), "name", obj.GetName(), "namespace", obj.GetNamespace())
+		err := applier.Apply(ctx, obj)
+		if err != nil && meta.IsNoMatchError(err) && isOptionalObject(obj) {
+			logging.FromContext(ctx).Warn("skipping bundle object, as the cluster doesn't serve its kind",
+				"kind", obj.GetKind(), "name", obj.GetName())
+			result.Unserved = append(result.Unserved, obj)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to apply %s %q: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		result.Applied = append(result.Applied, obj)
+		if obj.GroupVersionKind().GroupKind() == crdGroupKind {
+			crds = append(crds, obj)
+		}
+	}
+	return result, nil
+}
+
+var crdGroupKind = schema.GroupKind{Group: crdResource.Group, Kind: "CustomResourceDefinition"}
+
+// isOptionalObject returns true if obj can be left out of an install when the cluster doesn't serve its kind
+func isOptionalObject(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return gvk.Group == "apps.grafana.com" && gvk.Kind == "AppManifest"
+}
+
+// installOrder returns the order in which obj should be applied, relative to other objects
+func installOrder(obj *unstructured.Unstructured) int {
+	switch obj.GetKind() {
+	case "CustomResourceDefinition", "Namespace":
+		return 0
+	case "ValidatingWebhookConfiguration", "MutatingWebhookConfiguration":
+		return 2
+	default:
+		return 1
+	}
+}
+
+// decodeObjects decodes every kubernetes object in f. Documents without an apiVersion and kind are ignored.
+func decodeObjects(f File) ([]*unstructured.Unstructured, error) {
+	switch path.Ext(f.Path) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return nil, nil
+	}
+	objs := make([]*unstructured.Unstructured, 0)
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(f.Data), 4096)
+	for {
+		raw := make(map[string]any)
+		err := decoder.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode '%s': %w", f.Path, err)
+		}
+		obj := &unstructured.Unstructured{Object: raw}
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+			continue
+		}
+		if !obj.IsList() {
+			objs = append(objs, obj)
+			continue
+		}
+		err = obj.EachListItem(func(item runtime.Object) error {
+			cast, ok := item.(*unstructured.Unstructured)
+			if !ok {
+				return fmt.Errorf("list item is %T, not an object", item)
+			}
+			if cast.GetAPIVersion() != "" && cast.GetKind() != "" {
+				objs = append(objs, cast)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode '%s': %w", f.Path, err)
+		}
+	}
+	return objs, nil
+}
+
+// DynamicApplierConfig is the configuration used for a DynamicApplier
+type DynamicApplierConfig struct {
+	// FieldManager is the field manager used for server-side apply. Defaults to DefaultFieldManager.
+	FieldManager string
+	// Namespace is the namespace used for namespaced objects which don't have one. Defaults to "default".
+	Namespace string
+	// EstablishTimeout is the maximum time to wait for an applied CustomResourceDefinition to be established.
+	// Defaults to one minute.
+	EstablishTimeout time.Duration
+}
+
+// DynamicApplier is an Applier which uses server-side apply to create or update objects of any kind,
+// using discovery to find the resource for each object's kind. Conflicting fields are taken from other field managers,
+// so the objects in the cluster always match the bundle after they are applied.
+type DynamicApplier struct {
+	client       dynamic.Interface
+	mapper       *restmapper.DeferredDiscoveryRESTMapper
+	fieldManager     string
+	namespace        string
+	establishTimeout time.Duration
+}
+
+// NewDynamicApplier creates a new DynamicApplier using the provided rest.Config for its clients
+func NewDynamicApplier(cfg *rest.Config, applierConfig DynamicApplierConfig) (*DynamicApplier, error) {
+	client, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating dynamic client: %w", err)
+	}
+	disc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating discovery client: %w", err)
+	}
+	if applierConfig.FieldManager == "" {
+		applierConfig.FieldManager = DefaultFieldManager
+	}
+	if applierConfig.Namespace == "" {
+		applierConfig.Namespace = "default"
+	}
+	if applierConfig.EstablishTimeout <= 0 {
+		applierConfig.EstablishTimeout = defaultEstablishTimeout
+	}
+	return &DynamicApplier{
+		client:           client,
+		mapper:           restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(disc)),
+		fieldManager:     applierConfig.FieldManager,
+		namespace:        applierConfig.Namespace,
+		establishTimeout: applierConfig.EstablishTimeout,
+	}, nil
+}
+
+// Apply creates or updates obj with server-side apply.
+// If the kind of obj is unknown, discovery is refreshed once before failing, as it may have been added by a CRD
+// which was applied earlier in the same install.
+func (d *DynamicApplier) Apply(ctx context.Context, obj *unstructured.Unstructured) error {
+	gvk := obj.GroupVersionKind()
+	mapping, err := d.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		d.mapper.Reset()
+		mapping, err = d.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	if err != nil {
+		return err
+	}
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	var client dynamic.ResourceInterface = d.client.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		namespace := obj.GetNamespace()
+		if namespace == "" {
+			namespace = d.namespace
+		}
+		client = d.client.Resource(mapping.Resource).Namespace(namespace)
+	}
+	force := true
+	_, err = client.Patch(ctx, obj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		FieldManager: d.fieldManager,
+		Force:        &force,
+	})
+	return err
+}
+
+// WaitForEstablished polls crd until its Established condition is true, for up to the configured EstablishTimeout.
+// It returns an error right away if the CRD's names are not accepted, as it will never be established.
+// Discovery is refreshed once the CRD is established, so that its kind can be applied.
+func (d *DynamicApplier) WaitForEstablished(ctx context.Context, crd *unstructured.Unstructured) error {
+	err := wait.PollUntilContextTimeout(ctx, 500*time.Millisecond, d.establishTimeout, true, func(ctx context.Context) (bool, error) {
+		current, err := d.client.Resource(crdResource).Get(ctx, crd.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return crdEstablished(current)
+	})
+	if err != nil {
+		return err
+	}
+	d.mapper.Reset()
+	return nil
+}
+
+// crdEstablished returns true if the Established condition of crd is true,
+// or an error if its NamesAccepted condition is false
+func crdEstablished(crd *unstructured.Unstructured) (bool, error) {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	established := false
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		switch condition["type"] {
+		case "Established":
+			established = condition["status"] == string(metav1.ConditionTrue)
+		case "NamesAccepted":
+			if condition["status"] == string(metav1.ConditionFalse) {
+				return false, fmt.Errorf("names not accepted: %v", condition["message"])
+			}
+		}
+	}
+	return established, nil
+}
+
+// Compile-time interface compliance check
+var _ CRDWaiter = &DynamicApplier{}
@@ -0,0 +1,159 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type testApplier struct {
+	applied []string
+	err     error
+	// unserved are the kinds which return a NoKindMatchError
+	unserved []string
+}
+
+func (a *testApplier) Apply(_ context.Context, obj *unstructured.Unstructured) error {
+	if a.err != nil {
+		return a.err
+	}
+	if slices.Contains(a.unserved, obj.GetKind()) {
+		gvk := obj.GroupVersionKind()
+		return &meta.NoKindMatchError{GroupKind: gvk.GroupKind(), SearchedVersions: []string{gvk.Version}}
+	}
+	a.applied = append(a.applied, obj.GetKind()+"/"+obj.GetName())
+	return nil
+}
+
+// testCRDWaiter is a testApplier which records when it waits for CRDs
+type testCRDWaiter struct {
+	testApplier
+	waitErr error
+}
+
+func (w *testCRDWaiter) WaitForEstablished(_ context.Context, crd *unstructured.Unstructured) error {
+	w.applied = append(w.applied, "wait:"+crd.GetName())
+	return w.waitErr
+}
+
+func TestInstall(t *testing.T) {
+	b := &Bundle{
+		AppName: "test-app",
+		Files: []File{{
+			Path: "webhooks/test-app.yaml",
+			Data: []byte(`apiVersion: v1
+kind: List
+items:
+- apiVersion: admissionregistration.k8s.io/v1
+  kind: ValidatingWebhookConfiguration
+  metadata:
+    name: test-app-validation
+`),
+		}, {
+			Path: "manifest/test-app.json",
+			Data: []byte(`{"apiVersion":"apps.grafana.com/v1","kind":"AppManifest","metadata":{"name":"test-app"}}`),
+		}, {
+			Path: "rbac/test-app.yaml",
+			Data: []byte(`apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: test-app
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: test-app
+`),
+		}, {
+			Path: "crds/foo.test.grafana.app.yaml",
+			Data: []byte(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: foos.test.grafana.app
+`),
+		}, {
+			Path: "dashboards/test-app.json",
+			Data: []byte(`{"title":"test-app","panels":[]}`),
+		}, {
+			Path: "README.md",
+			Data: []byte("# test-app"),
+		}},
+	}
+
+	applier := &testApplier{}
+	result, err := Install(context.Background(), b, applier)
+	require.Nil(t, err)
+	assert.Equal(t, []string{
+		"CustomResourceDefinition/foos.test.grafana.app",
+		"AppManifest/test-app",
+		"ServiceAccount/test-app",
+		"ClusterRole/test-app",
+		"ValidatingWebhookConfiguration/test-app-validation",
+	}, applier.applied)
+	assert.Len(t, result.Applied, 5)
+	assert.Equal(t, []string{"dashboards/test-app.json", "README.md"}, result.Skipped)
+
+	_, err = Install(context.Background(), b, &testApplier{err: errors.New("I AM ERROR")})
+	assert.EqualError(t, err, `unable to apply CustomResourceDefinition "foos.test.grafana.app": I AM ERROR`)
+
+	_, err = Install(context.Background(), &Bundle{
+		AppName: "test-app",
+		Files:   []File{{Path: "bad.yaml", Data: []byte("foo: [")}},
+	}, applier)
+	assert.ErrorContains(t, err, "unable to decode 'bad.yaml'")
+
+	t.Run("waits for CRDs", func(t *testing.T) {
+		waiter := &testCRDWaiter{}
+		_, err := Install(context.Background(), b, waiter)
+		require.Nil(t, err)
+		assert.Equal(t, []string{
+			"CustomResourceDefinition/foos.test.grafana.app",
+			"wait:foos.test.grafana.app",
+			"AppManifest/test-app",
+			"ServiceAccount/test-app",
+			"ClusterRole/test-app",
+			"ValidatingWebhookConfiguration/test-app-validation",
+		}, waiter.applied)
+
+		_, err = Install(context.Background(), b, &testCRDWaiter{waitErr: errors.New("timed out")})
+		assert.EqualError(t, err, `CustomResourceDefinition "foos.test.grafana.app" was not established: timed out`)
+	})
+
+	t.Run("unserved AppManifest", func(t *testing.T) {
+		applier := &testApplier{unserved: []string{"AppManifest"}}
+		result, err := Install(context.Background(), b, applier)
+		require.Nil(t, err)
+		assert.Len(t, result.Applied, 4)
+		require.Len(t, result.Unserved, 1)
+		assert.Equal(t, "test-app", result.Unserved[0].GetName())
+
+		// Other kinds are not optional
+		_, err = Install(context.Background(), b, &testApplier{unserved: []string{"ClusterRole"}})
+		assert.ErrorContains(t, err, `unable to apply ClusterRole "test-app"`)
+	})
+}
+
+func TestCRDEstablished(t *testing.T) {
+	crd := func(conditions ...any) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"status": map[string]any{"conditions": conditions},
+		}}
+	}
+	established, err := crdEstablished(crd())
+	assert.Nil(t, err)
+	assert.False(t, established)
+	established, err = crdEstablished(crd(
+		map[string]any{"type": "NamesAccepted", "status": "True"},
+		map[string]any{"type": "Established", "status": "True"},
+	))
+	assert.Nil(t, err)
+	assert.True(t, established)
+	_, err = crdEstablished(crd(map[string]any{"type": "NamesAccepted", "status": "False", "message": "conflict"}))
+	assert.EqualError(t, err, "names not accepted: conflict")
+}
@@ -0,0 +1,68 @@
+package bundle
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+const (
+	pemTypePrivateKey = "PRIVATE KEY"
+	pemTypePublicKey  = "PUBLIC KEY"
+)
+
+// GenerateKey generates a new ed25519 key pair for signing bundles,
+// and returns the PEM-encoded private and public keys.
+func GenerateKey() (privateKeyPEM []byte, publicKeyPEM []byte, err error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypePrivateKey, Bytes: privateDER}),
+		pem.EncodeToMemory(&pem.Block{Type: pemTypePublicKey, Bytes: publicDER}), nil
+}
+
+// ParsePrivateKey parses a PEM-encoded PKCS #8 ed25519 private key, such as one returned by GenerateKey
+func ParsePrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemTypePrivateKey {
+		return nil, fmt.Errorf("key is not a PEM-encoded %s", pemTypePrivateKey)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	cast, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("key is not an ed25519 private key")
+	}
+	return cast, nil
+}
+
+// ParsePublicKey parses a PEM-encoded PKIX ed25519 public key, such as one returned by GenerateKey
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemTypePublicKey {
+		return nil, fmt.Errorf("key is not a PEM-encoded %s", pemTypePublicKey)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	cast, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("key is not an ed25519 public key")
+	}
+	return cast, nil
+}
@@ -0,0 +1,16 @@
+// Command grafana-app-sdk-vet reports common mistakes in app code with the analyzers in the vet package.
+// It can be run directly on packages, or as a go vet tool:
+//
+//	grafana-app-sdk-vet ./...
+//	go vet -vettool=$(which grafana-app-sdk-vet) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/multichecker"
+
+	"github.com/grafana/grafana-app-sdk/vet"
+)
+
+func main() {
+	multichecker.Main(vet.Analyzers...)
+}
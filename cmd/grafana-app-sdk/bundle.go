@@ -0,0 +1,375 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/grafana/codejen"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/grafana/grafana-app-sdk/bundle"
+	"github.com/grafana/grafana-app-sdk/codegen"
+	"github.com/grafana/grafana-app-sdk/codegen/cuekind"
+	"github.com/grafana/grafana-app-sdk/codegen/jennies"
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle <command>",
+	Short: "Commands for creating, verifying, and installing signed offline app bundles",
+	Long: `Commands for creating, verifying, and installing signed offline app bundles.
+A bundle is a single archive with everything needed to install an app without access to its source or a registry:
+the app manifest, CRDs, RBAC, webhook configurations, and dashboards. Bundles are signed with an ed25519 key,
+and are verified with the matching public key before their contents are extracted or installed.`,
+}
+
+var bundleKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Generate a key pair for signing and verifying bundles",
+	RunE:  bundleKeygenCmdFunc,
+}
+
+var bundleCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a signed bundle for the app",
+	Long: `Create a signed bundle for the app, which contains the app manifest, a CRD for each kind,
+RBAC and webhook configurations for the app's operator, and a Grafana dashboard for the operator.`,
+	RunE: bundleCreateCmdFunc,
+}
+
+var bundleVerifyCmd = &cobra.Command{
+	Use:   "verify <bundle>",
+	Short: "Verify the signature and contents of a bundle",
+	Args:  cobra.ExactArgs(1),
+	RunE:  bundleVerifyCmdFunc,
+}
+
+var bundleInstallCmd = &cobra.Command{
+	Use:   "install <bundle>",
+	Short: "Verify a bundle, and apply its contents to a kubernetes cluster",
+	Long: `Verify a bundle, and apply its contents to a kubernetes cluster with server-side apply.
+CRDs are applied first, and webhook configurations last. Installing a bundle is idempotent,
+so the same bundle can be installed again (or a newer bundle installed over it) safely.`,
+	Args: cobra.ExactArgs(1),
+	RunE: bundleInstallCmdFunc,
+}
+
+func setupBundleCmd() {
+	bundleKeygenCmd.Flags().String("key", "bundle.key", "Path where the PEM-encoded private key will be created")
+	bundleKeygenCmd.Flags().String("public-key", "bundle.pub", "Path where the PEM-encoded public key will be created")
+
+	bundleCreateCmd.Flags().String("key", "bundle.key", "Path to the PEM-encoded private key the bundle is signed with")
+	bundleCreateCmd.Flags().String("bundlepath", "", "Path where the bundle will be created. Defaults to '<app name>.bundle.tar.gz'")
+	bundleCreateCmd.Flags().String("namespace", "default", "Namespace of the operator's ServiceAccount and Service")
+	bundleCreateCmd.Flags().String("serviceaccount", "operator", "Name of the operator's ServiceAccount")
+	bundleCreateCmd.Flags().String("service", "", "Name of the operator's Service. Defaults to '<app name>-operator'")
+	bundleCreateCmd.Flags().String("metricsnamespace", "", "Prometheus namespace the operator's metrics are exposed with")
+	bundleCreateCmd.Flags().String("selectorlabel", "job", "Prometheus label used to select the app's metrics in the dashboard")
+
+	bundleVerifyCmd.Flags().String("public-key", "bundle.pub", "Path to the PEM-encoded public key the bundle is verified with")
+	bundleVerifyCmd.Flags().String("extract", "", "Path to a directory where the contents of the bundle will be extracted after it is verified")
+
+	bundleInstallCmd.Flags().String("public-key", "bundle.pub", "Path to the PEM-encoded public key the bundle is verified with")
+	bundleInstallCmd.Flags().String("kubeconfig", "", "Path to the kubeconfig of the cluster to install into. Defaults to $KUBECONFIG or ~/.kube/config")
+	bundleInstallCmd.Flags().String("namespace", "default", "Namespace for namespaced objects in the bundle which don't have one")
+	bundleInstallCmd.Flags().String("field-manager", bundle.DefaultFieldManager, "Field manager used for server-side apply")
+
+	bundleCmd.AddCommand(bundleKeygenCmd)
+	bundleCmd.AddCommand(bundleCreateCmd)
+	bundleCmd.AddCommand(bundleVerifyCmd)
+	bundleCmd.AddCommand(bundleInstallCmd)
+
+	// Don't show "usage" information when an error is returned form the command,
+	// because our errors are not command-usage-based
+	for _, c := range bundleCmd.Commands() {
+		c.SilenceUsage = true
+	}
+}
+
+// bundleResult is the machine-readable result of the bundle verify and install commands
+type bundleResult struct {
+	AppName string   `json:"appName" yaml:"appName"`
+	Files   []string `json:"files,omitempty" yaml:"files,omitempty"`
+	Applied []string `json:"applied,omitempty" yaml:"applied,omitempty"`
+	Skipped []string `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+	// Unserved are the objects which were not applied, as the cluster doesn't serve their kind
+	Unserved []string `json:"unserved,omitempty" yaml:"unserved,omitempty"`
+}
+
+func bundleKeygenCmdFunc(cmd *cobra.Command, _ []string) error {
+	keyPath, err := cmd.Flags().GetString("key")
+	if err != nil {
+		return err
+	}
+	publicKeyPath, err := cmd.Flags().GetString("public-key")
+	if err != nil {
+		return err
+	}
+	if _, err = os.Stat(keyPath); err == nil {
+		return fmt.Errorf("%s already exists, and will not be overwritten", keyPath)
+	}
+	private, public, err := bundle.GenerateKey()
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(keyPath, private, 0o600); err != nil {
+		return err
+	}
+	cmdOutput.fileWritten(keyPath)
+	return writeFile(publicKeyPath, public)
+}
+
+//nolint:funlen
+func bundleCreateCmdFunc(cmd *cobra.Command, _ []string) error {
+	sourcePath, err := cmd.Flags().GetString(sourceFlag)
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString(formatFlag)
+	if err != nil {
+		return err
+	}
+	selector, err := cmd.Flags().GetString(selectorFlag)
+	if err != nil {
+		return err
+	}
+	keyPath, err := cmd.Flags().GetString("key")
+	if err != nil {
+		return err
+	}
+	bundlePath, err := cmd.Flags().GetString("bundlepath")
+	if err != nil {
+		return err
+	}
+	namespace, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		return err
+	}
+	serviceAccount, err := cmd.Flags().GetString("serviceaccount")
+	if err != nil {
+		return err
+	}
+	service, err := cmd.Flags().GetString("service")
+	if err != nil {
+		return err
+	}
+	metricsNamespace, err := cmd.Flags().GetString("metricsnamespace")
+	if err != nil {
+		return err
+	}
+	selectorLabel, err := cmd.Flags().GetString("selectorlabel")
+	if err != nil {
+		return err
+	}
+
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return fmt.Errorf("unable to read --key: %w", err)
+	}
+	key, err := bundle.ParsePrivateKey(keyPEM)
+	if err != nil {
+		return fmt.Errorf("unable to parse --key: %w", err)
+	}
+
+	kindParser, manifestParser, err := kindParsers(format, sourcePath)
+	if err != nil {
+		return err
+	}
+	kindGenerator, err := codegen.NewGenerator[codegen.Kind](kindParser, os.DirFS(sourcePath))
+	if err != nil {
+		return err
+	}
+	manifestGenerator, err := codegen.NewGenerator[codegen.AppManifest](manifestParser, os.DirFS(sourcePath))
+	if err != nil {
+		return err
+	}
+
+	// Each generator's files are added to the bundle in the directory for their type
+	generators := []struct {
+		dir      string
+		generate func() (codejen.Files, error)
+	}{{
+		dir: "manifest",
+		generate: func() (codejen.Files, error) {
+			return manifestGenerator.Generate(cuekind.ManifestGenerator(yaml.Marshal, "yaml"), selector)
+		},
+	}, {
+		dir: "crds",
+		generate: func() (codejen.Files, error) {
+			return kindGenerator.Generate(cuekind.CRDGenerator(yaml.Marshal, "yaml"), selector)
+		},
+	}, {
+		dir: "rbac",
+		generate: func() (codejen.Files, error) {
+			return manifestGenerator.Generate(cuekind.RBACGenerator(yaml.Marshal, "yaml", namespace, serviceAccount, false), selector)
+		},
+	}, {
+		dir: "webhooks",
+		generate: func() (codejen.Files, error) {
+			return manifestGenerator.Generate(cuekind.WebhookConfigurationGenerator(yaml.Marshal, "yaml", jennies.WebhookClientOptions{
+				Namespace:   namespace,
+				ServiceName: service,
+			}), selector)
+		},
+	}, {
+		dir: "dashboards",
+		generate: func() (codejen.Files, error) {
+			return manifestGenerator.Generate(cuekind.DashboardGenerator(metricsNamespace, selectorLabel), selector)
+		},
+	}}
+	b := &bundle.Bundle{}
+	for _, g := range generators {
+		files, err := g.generate()
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			b.Files = append(b.Files, bundle.File{
+				Path: path.Join(g.dir, filepath.ToSlash(f.RelativePath)),
+				Data: f.Data,
+			})
+		}
+		if g.dir != "manifest" {
+			continue
+		}
+		for _, f := range files {
+			manifest := struct {
+				Spec struct {
+					AppName string `yaml:"appName"`
+				} `yaml:"spec"`
+			}{}
+			if err = yaml.Unmarshal(f.Data, &manifest); err != nil {
+				return err
+			}
+			b.AppName = manifest.Spec.AppName
+		}
+	}
+	if b.AppName == "" {
+		return errors.New("no app manifest found")
+	}
+
+	if bundlePath == "" {
+		bundlePath = b.AppName + ".bundle.tar.gz"
+	}
+	buf := &bytes.Buffer{}
+	if err = b.Write(buf, key); err != nil {
+		return err
+	}
+	return writeFile(bundlePath, buf.Bytes())
+}
+
+func bundleVerifyCmdFunc(cmd *cobra.Command, args []string) error {
+	extractPath, err := cmd.Flags().GetString("extract")
+	if err != nil {
+		return err
+	}
+	b, err := readBundle(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	result := bundleResult{
+		AppName: b.AppName,
+		Files:   make([]string, len(b.Files)),
+	}
+	cmdOutput.Printf("Bundle %s for app '%s' is valid, and contains:\n", args[0], b.AppName)
+	for i, f := range b.Files {
+		result.Files[i] = f.Path
+		if extractPath == "" {
+			cmdOutput.Printf(" * %s\n", f.Path)
+			continue
+		}
+		if err = writeFile(filepath.Join(extractPath, filepath.FromSlash(f.Path)), f.Data); err != nil {
+			return err
+		}
+	}
+	if extractPath == "" {
+		cmdOutput.setResult(result)
+	}
+	return nil
+}
+
+func bundleInstallCmdFunc(cmd *cobra.Command, args []string) error {
+	kubeConfigPath, err := cmd.Flags().GetString("kubeconfig")
+	if err != nil {
+		return err
+	}
+	namespace, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		return err
+	}
+	fieldManager, err := cmd.Flags().GetString("field-manager")
+	if err != nil {
+		return err
+	}
+	b, err := readBundle(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeConfigPath
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("unable to load kubeconfig: %w", err)
+	}
+	applier, err := bundle.NewDynamicApplier(restConfig, bundle.DynamicApplierConfig{
+		FieldManager: fieldManager,
+		Namespace:    namespace,
+	})
+	if err != nil {
+		return err
+	}
+	installed, err := bundle.Install(context.Background(), b, applier)
+	if err != nil {
+		return err
+	}
+
+	result := bundleResult{
+		AppName: b.AppName,
+		Applied: make([]string, len(installed.Applied)),
+		Skipped: installed.Skipped,
+	}
+	cmdOutput.Printf("Installed app '%s':\n", b.AppName)
+	for i, obj := range installed.Applied {
+		result.Applied[i] = fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+		cmdOutput.Printf(" * Applied %s\n", result.Applied[i])
+	}
+	for _, skipped := range installed.Skipped {
+		cmdOutput.Printf(" * Skipped %s, which has no kubernetes objects\n", skipped)
+	}
+	for _, obj := range installed.Unserved {
+		unserved := fmt.Sprintf("%s/%s", obj.GetKind(), obj.GetName())
+		result.Unserved = append(result.Unserved, unserved)
+		cmdOutput.Printf(" * Skipped %s, as the cluster doesn't serve its kind (install the %s CRD to apply it)\n", unserved, obj.GetKind())
+	}
+	cmdOutput.setResult(result)
+	return nil
+}
+
+// readBundle reads the bundle at bundlePath, and verifies it with the --public-key of cmd
+func readBundle(cmd *cobra.Command, bundlePath string) (*bundle.Bundle, error) {
+	publicKeyPath, err := cmd.Flags().GetString("public-key")
+	if err != nil {
+		return nil, err
+	}
+	publicKeyPEM, err := os.ReadFile(publicKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read --public-key: %w", err)
+	}
+	publicKey, err := bundle.ParsePublicKey(publicKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse --public-key: %w", err)
+	}
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return bundle.Read(f, publicKey)
+}
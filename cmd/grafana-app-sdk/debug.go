@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana-app-sdk/operator"
+)
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Commands for debugging a running operator",
+}
+
+var debugTailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Print live reconcile events from a running operator",
+	Long: `Print live reconcile events from a running operator, until interrupted.
+The operator must be run by an operator.Runner with RunnerDebugConfig.Enabled, which streams reconcile events
+from its metrics server. Use kubectl port-forward to reach the metrics server of an operator running in a cluster.
+
+With --output json, each event is written to stdout as a single line of JSON.
+With --output yaml, each event is written to stdout as a YAML document.`,
+	RunE:         debugTail,
+	SilenceUsage: true,
+}
+
+const (
+	debugEndpointFlag   = "endpoint"
+	debugKindFlag       = "kind"
+	debugNamespaceFlag  = "namespace"
+	debugNameFlag       = "name"
+	debugErrorsOnlyFlag = "errors-only"
+)
+
+func setupDebugCmd() {
+	debugTailCmd.Flags().String(debugEndpointFlag, "http://localhost:9090", "Address of the operator's metrics server")
+	debugTailCmd.Flags().String(debugKindFlag, "", "Only print events for this kind (case-insensitive)")
+	debugTailCmd.Flags().StringP(debugNamespaceFlag, "n", "", "Only print events for objects in this namespace")
+	debugTailCmd.Flags().String(debugNameFlag, "", "Only print events for objects with this name")
+	debugTailCmd.Flags().Bool(debugErrorsOnlyFlag, false, "Only print events for reconciles which returned an error")
+
+	debugCmd.AddCommand(debugTailCmd)
+}
+
+//nolint:revive
+func debugTail(cmd *cobra.Command, args []string) error {
+	endpoint, _ := cmd.Flags().GetString(debugEndpointFlag)
+	kind, _ := cmd.Flags().GetString(debugKindFlag)
+	namespace, _ := cmd.Flags().GetString(debugNamespaceFlag)
+	name, _ := cmd.Flags().GetString(debugNameFlag)
+	errorsOnly, _ := cmd.Flags().GetBool(debugErrorsOnlyFlag)
+
+	u, err := reconcileEventsURL(endpoint, operator.ReconcileEventFilter{
+		Kind:       kind,
+		Namespace:  namespace,
+		Name:       name,
+		ErrorsOnly: errorsOnly,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to connect to operator: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("operator at %s does not serve reconcile events, enable them with RunnerDebugConfig.Enabled", endpoint)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected response from operator (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	// Events are streamed as they happen, rather than written as a single document once the command completes
+	cmdOutput.streamed = true
+	cmdOutput.Printf("Tailing reconcile events from %s\n", endpoint)
+	err = printReconcileEvents(resp.Body, cmdOutput.stdout, cmdOutput.format)
+	if ctx.Err() != nil {
+		// Interrupted
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return errors.New("operator closed the connection")
+}
+
+// reconcileEventsURL returns the URL of the reconcile events endpoint of the metrics server at endpoint, with filter applied
+func reconcileEventsURL(endpoint string, filter operator.ReconcileEventFilter) (string, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid --%s: %w", debugEndpointFlag, err)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + operator.ReconcileEventsPath
+	query := u.Query()
+	if filter.Kind != "" {
+		query.Set("kind", filter.Kind)
+	}
+	if filter.Namespace != "" {
+		query.Set("namespace", filter.Namespace)
+	}
+	if filter.Name != "" {
+		query.Set("name", filter.Name)
+	}
+	if filter.ErrorsOnly {
+		query.Set("errorsOnly", "true")
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// printReconcileEvents prints each newline-delimited JSON event read from in to out in format, until in is closed
+func printReconcileEvents(in io.Reader, out io.Writer, format string) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		evt := operator.ReconcileEvent{}
+		if err := json.Unmarshal(scanner.Bytes(), &evt); err != nil {
+			return fmt.Errorf("unable to parse event: %w", err)
+		}
+		var err error
+		switch format {
+		case OutputJSON:
+			_, err = fmt.Fprintf(out, "%s\n", scanner.Bytes())
+		case OutputYAML:
+			var b []byte
+			if b, err = yaml.Marshal(evt); err == nil {
+				_, err = fmt.Fprintf(out, "---\n%s", b)
+			}
+		default:
+			_, err = fmt.Fprintln(out, formatReconcileEvent(evt))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// formatReconcileEvent returns a single-line human-readable description of evt
+func formatReconcileEvent(evt operator.ReconcileEvent) string {
+	object := evt.Name
+	if evt.Namespace != "" {
+		object = evt.Namespace + "/" + evt.Name
+	}
+	action := evt.Action
+	if evt.Retry {
+		action += " (retry)"
+	}
+	line := fmt.Sprintf("%s  %-16s %-40s %-16s %-8s %s", evt.Time.Format(time.RFC3339), evt.Kind, object, action, evt.Outcome,
+		evt.Duration.Round(time.Microsecond))
+	if evt.RequeueAfter != nil {
+		line += fmt.Sprintf("  requeue after %s", *evt.RequeueAfter)
+	}
+	if evt.Error != "" {
+		line += "  error: " + evt.Error
+	}
+	return line
+}
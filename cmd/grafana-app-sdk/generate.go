@@ -6,6 +6,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/grafana/codejen"
 	"github.com/spf13/cobra"
@@ -13,6 +14,8 @@ import (
 
 	"github.com/grafana/grafana-app-sdk/codegen"
 	"github.com/grafana/grafana-app-sdk/codegen/cuekind"
+	"github.com/grafana/grafana-app-sdk/codegen/jennies"
+	"github.com/grafana/grafana-app-sdk/codegen/pipeline"
 )
 
 const (
@@ -33,20 +36,135 @@ func setupGenerateCmd() {
 		"Path to directory where generated go code will reside")
 	generateCmd.PersistentFlags().StringP("tsgenpath", "t", "plugin/src/generated/",
 		"Path to directory where generated TypeScript code will reside")
+	generateCmd.Flags().String("tsvalidators", "", `Validators to generate alongside the TypeScript types of each kind version (in the tsgenpath).
+Allowed values are 'zod', or empty for no validators.`)
+	generateCmd.Flags().String("pygenpath", "",
+		"Path to directory where generated Python models and client will reside. If empty, no Python code is generated")
+	generateCmd.Flags().String("protogenpath", "",
+		"Path to directory where generated protobuf (.proto) definitions of each kind version will reside. If empty, no protobuf definitions are generated")
 	generateCmd.Flags().String("defencoding", "json", `Encoding for Custom Resource Definition 
 files. Allowed values are 'json', 'yaml', and 'none'. Use 'none' to turn off CRD generation.`)
 	generateCmd.Flags().String("defpath", "definitions", `Path where Custom Resource 
 Definitions will be created. Only applicable if type=kubernetes`)
 	generateCmd.Flags().String("grouping", kindGroupingKind, `Kind go package grouping.
 Allowed values are 'group' and 'kind'. Dictates the packaging of go kinds, where 'group' places all kinds with the same group in the same package, and 'kind' creates separate packages per kind (packaging will always end with the version)`)
+	generateCmd.Flags().Bool("clientset", false, "Whether to generate a typed client for each kind version, and a Clientset for each generated go package.")
+	generateCmd.Flags().Lookup("clientset").NoOptDefVal = "true"
 	generateCmd.Flags().Bool("postprocess", false, "Whether to run post-processing on the generated files after they are written to disk. Post-processing includes code generation based on +k8s comments on types. Post-processing will fail if the dependencies required by the generated code are absent from go.mod.")
 	generateCmd.Flags().Lookup("postprocess").NoOptDefVal = "true"
+	generateCmd.Flags().StringArray("plugin", nil, `Codegen plugin to run after the built-in generators, which may be repeated.
+A plugin is a go main package directory (run with 'go run') or an executable, which calls pipeline.ServePlugin with the project's custom generators.`)
+
+	generateDashboardsCmd.Flags().String("dashboardpath", "dashboards", "Path where generated Grafana dashboard JSON files will be created")
+	generateDashboardsCmd.Flags().String("metricsnamespace", "", "Prometheus namespace the operator's metrics are exposed with")
+	generateDashboardsCmd.Flags().String("selectorlabel", "job", `Prometheus label used to select the app's metrics.
+The dashboard's 'app' variable filters on this label, and defaults to the app name.`)
+	generateDashboardsCmd.SilenceUsage = true
+	generateCmd.AddCommand(generateDashboardsCmd)
+
+	generateAlertsCmd.Flags().String("alertspath", "alerts", "Path where generated PrometheusRule files will be created")
+	generateAlertsCmd.Flags().String("encoding", "yaml", "Encoding for generated PrometheusRule files. Allowed values are 'json' and 'yaml'.")
+	generateAlertsCmd.Flags().String("metricsnamespace", "", "Prometheus namespace the operator's metrics are exposed with")
+	generateAlertsCmd.Flags().String("selectorlabel", "job", "Prometheus label used to select the app's metrics. Alerts select metrics where this label is equal to the app name.")
+	generateAlertsCmd.Flags().Float64("reconcile-error-ratio", 0.1, "Ratio of reconciler actions returning an error above which an alert fires")
+	generateAlertsCmd.Flags().Float64("webhook-error-ratio", 0.05, "Ratio of failed admission requests above which an alert fires")
+	generateAlertsCmd.Flags().Int("retry-queue-size", 100, "Number of queued retries above which the retry queue is considered saturated")
+	generateAlertsCmd.Flags().Int("terminal-watch-errors", 5, "Number of terminal list/watch errors for a kind within --for above which an alert fires")
+	generateAlertsCmd.Flags().Duration("for", 15*time.Minute, "How long a condition must hold before an alert fires")
+	generateAlertsCmd.Flags().Duration("sync-stalled-for", 10*time.Minute, "How long an informer may go without completing its initial sync before an alert fires")
+	generateAlertsCmd.SilenceUsage = true
+	generateCmd.AddCommand(generateAlertsCmd)
+
+	generateRBACCmd.Flags().String("rbacpath", "rbac", "Path where the generated RBAC file will be created")
+	generateRBACCmd.Flags().String("encoding", "yaml", "Encoding for the generated RBAC file. Allowed values are 'json' and 'yaml'.")
+	generateRBACCmd.Flags().String("namespace", "default", "Namespace of the operator's ServiceAccount")
+	generateRBACCmd.Flags().String("serviceaccount", "operator", "Name of the operator's ServiceAccount")
+	generateRBACCmd.Flags().Bool("namespaced", false, `Whether to grant permissions with a Role and RoleBinding in --namespace, for an operator which only watches its own namespace.
+Permissions for cluster-scoped kinds are always granted with a ClusterRole.`)
+	generateRBACCmd.Flags().Lookup("namespaced").NoOptDefVal = "true"
+	generateRBACCmd.SilenceUsage = true
+	generateCmd.AddCommand(generateRBACCmd)
+
+	generateWebhooksCmd.Flags().String("webhookspath", "webhooks", "Path where the generated webhook configurations file will be created")
+	generateWebhooksCmd.Flags().String("encoding", "yaml", "Encoding for the generated webhook configurations file. Allowed values are 'json' and 'yaml'.")
+	generateWebhooksCmd.Flags().String("namespace", "default", "Namespace of the operator's Service")
+	generateWebhooksCmd.Flags().String("service", "", "Name of the operator's Service. Defaults to '<app name>-operator'")
+	generateWebhooksCmd.Flags().Int32("service-port", 443, "Port of the operator's Service which serves webhooks")
+	generateWebhooksCmd.Flags().String("url", "", "Base URL of the operator's webhook server, to call instead of the Service")
+	generateWebhooksCmd.Flags().String("validate-path", "", "Base path validating webhooks are served on, which must match RunnerWebhookConfig.Paths. Defaults to '/validate'")
+	generateWebhooksCmd.Flags().String("mutate-path", "", "Base path mutating webhooks are served on, which must match RunnerWebhookConfig.Paths. Defaults to '/mutate'")
+	generateWebhooksCmd.Flags().String("ca-bundle", "", "Path to a PEM-encoded CA bundle which signed the webhook server's certificate, to add to each webhook")
+	generateWebhooksCmd.Flags().String("ca-inject-from", "", `cert-manager Certificate ('<namespace>/<name>') to inject the CA bundle from, with the cert-manager CA injector.
+Cannot be used with --ca-bundle.`)
+	generateWebhooksCmd.Flags().String("failure-policy", "Fail", "Policy when a webhook call fails. Allowed values are 'Fail' and 'Ignore'.")
+	generateWebhooksCmd.SilenceUsage = true
+	generateCmd.AddCommand(generateWebhooksCmd)
+
+	generateAPIServerManifestsCmd.Flags().String("apiserverpath", "apiserver", "Path where the generated aggregated API server resources file will be created")
+	generateAPIServerManifestsCmd.Flags().String("encoding", "yaml", "Encoding for the generated aggregated API server resources file. Allowed values are 'json' and 'yaml'.")
+	generateAPIServerManifestsCmd.Flags().String("namespace", "default", "Namespace the aggregated API server is deployed in")
+	generateAPIServerManifestsCmd.Flags().String("name", "", "Name of the aggregated API server's Deployment, Service, ServiceAccount, and Certificate. Defaults to '<app name>-apiserver'")
+	generateAPIServerManifestsCmd.Flags().String("image", "", "Container image of the aggregated API server. Defaults to '<app name>-apiserver:latest'")
+	generateAPIServerManifestsCmd.Flags().Int32("replicas", 1, "Number of replicas of the aggregated API server's Deployment")
+	generateAPIServerManifestsCmd.Flags().Int32("port", 6443, "Port the aggregated API server serves on in its container")
+	generateAPIServerManifestsCmd.Flags().Int32("group-priority-minimum", 1000, "Priority of the app's group in the API server's discovery")
+	generateAPIServerManifestsCmd.Flags().String("ca-bundle", "", `Path to a PEM-encoded CA bundle which signed the aggregated API server's certificate, to add to each APIService.
+No cert-manager resources are generated if it is provided.`)
+	generateAPIServerManifestsCmd.Flags().String("ca-inject-from", "", `Existing cert-manager Certificate ('<namespace>/<name>') to inject the CA bundle of each APIService from.
+No cert-manager resources are generated if it is provided. Cannot be used with --ca-bundle.`)
+	generateAPIServerManifestsCmd.Flags().StringSlice("etcd-servers", []string{"http://localhost:2379"}, "URLs of the etcd servers the aggregated API server stores its resources in")
+	generateAPIServerManifestsCmd.Flags().String("etcd-prefix", "", "Prefix of the aggregated API server's keys in etcd. Defaults to '/registry/<group>'")
+	generateAPIServerManifestsCmd.SilenceUsage = true
+	generateCmd.AddCommand(generateAPIServerManifestsCmd)
 
 	// Don't show "usage" information when an error is returned form the command,
 	// because our errors are not command-usage-based
 	generateCmd.SilenceUsage = true
 }
 
+var generateDashboardsCmd = &cobra.Command{
+	Use:   "dashboards",
+	Short: "Generate Grafana dashboards for observing the app's operator",
+	Long: `Generate a Grafana dashboard JSON file for the app's operator, with panels for reconcile rates, latency, and errors per kind,
+watch event rates, kubernetes client request latency, and admission webhook rejection rates.`,
+	RunE: generateDashboardsCmdFunc,
+}
+
+var generateAlertsCmd = &cobra.Command{
+	Use:   "alerts",
+	Short: "Generate prometheus alert rules for common operator failure modes",
+	Long: `Generate a PrometheusRule for the app's operator, with alerts for a high reconcile error ratio, informers which have not synced
+or are repeatedly restarting their watch, a high admission webhook error ratio, and a saturated retry queue.`,
+	RunE: generateAlertsCmdFunc,
+}
+
+var generateRBACCmd = &cobra.Command{
+	Use:   "rbac",
+	Short: "Generate kubernetes RBAC objects for the app's operator",
+	Long: `Generate a ClusterRole (or Role) and binding for the app's operator ServiceAccount, granting access to the kinds in the app manifest,
+and to the kinds listed in the manifest's extraPermissions.`,
+	RunE: generateRBACCmdFunc,
+}
+
+var generateWebhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "Generate kubernetes admission webhook configurations for the app's operator",
+	Long: `Generate a ValidatingWebhookConfiguration and MutatingWebhookConfiguration for the app's operator, with a webhook for each kind version
+with validation, mutation, or defaulting capabilities in the app manifest. Each webhook calls the per-kind path for its kind version,
+such as /validate/<group>/<version>/<plural>, so webhooks can be adjusted per kind version after generation.`,
+	RunE: generateWebhooksCmdFunc,
+}
+
+var generateAPIServerManifestsCmd = &cobra.Command{
+	Use:   "apiserver-manifests",
+	Short: "Generate kubernetes resources for running the app as an aggregated API server",
+	Long: `Generate an APIService for each version of the app's group, and a Deployment, Service, and ServiceAccount for the app's aggregated API server.
+The ServiceAccount is bound to the roles the API server needs to delegate authentication and authorization to the kubernetes API server.
+Unless --ca-bundle or --ca-inject-from is provided, a self-signed cert-manager Issuer and a Certificate for the Service are also generated,
+and the CA bundle of each APIService is injected from the Certificate.`,
+	RunE: generateAPIServerManifestsCmdFunc,
+}
+
 //nolint:funlen,revive
 func generateCmdFunc(cmd *cobra.Command, _ []string) error {
 	// Global flags
@@ -74,6 +192,24 @@ func generateCmdFunc(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
+	tsValidators, err := cmd.Flags().GetString("tsvalidators")
+	if err != nil {
+		return err
+	}
+	if tsValidators != "" && tsValidators != pipeline.TSValidatorsZod {
+		return fmt.Errorf("--tsvalidators must be one of 'zod'|''")
+	}
+
+	pyGenPath, err := cmd.Flags().GetString("pygenpath")
+	if err != nil {
+		return err
+	}
+
+	protoGenPath, err := cmd.Flags().GetString("protogenpath")
+	if err != nil {
+		return err
+	}
+
 	encType, err := cmd.Flags().GetString("defencoding")
 	if err != nil {
 		return err
@@ -95,158 +231,503 @@ func generateCmdFunc(cmd *cobra.Command, _ []string) error {
 	if err != nil {
 		return err
 	}
+	clientset, err := cmd.Flags().GetBool("clientset")
+	if err != nil {
+		return err
+	}
 
-	var files codejen.Files
-	switch format {
-	case FormatCUE:
-		files, err = generateKindsCue(os.DirFS(sourcePath), kindGenConfig{
-			GoGenBasePath: goGenPath,
-			TSGenBasePath: tsGenPath,
-			CRDEncoding:   encType,
-			CRDPath:       defPath,
-			GroupKinds:    grouping == kindGroupingGroup,
-		}, selector)
+	kindParser, manifestParser, err := kindParsers(format, sourcePath)
+	if err != nil {
+		return err
+	}
+
+	plugins, err := cmd.Flags().GetStringArray("plugin")
+	if err != nil {
+		return err
+	}
+
+	genConfig := pipeline.Config{
+		GoGenBasePath:    goGenPath,
+		TSGenBasePath:    tsGenPath,
+		TSValidators:     tsValidators,
+		PyGenBasePath:    pyGenPath,
+		ProtoGenBasePath: protoGenPath,
+		ProtoPrevious:    os.DirFS(protoGenPath),
+		CRDEncoding:      encType,
+		CRDPath:          defPath,
+		GroupKinds:       grouping == kindGroupingGroup,
+		Clientset:        clientset,
+	}
+	files, err := pipeline.Generate(kindParser, manifestParser, os.DirFS(sourcePath), genConfig, selector)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		err = writeFile(f.RelativePath, f.Data)
 		if err != nil {
 			return err
 		}
-	default:
-		return fmt.Errorf("unknown kind format '%s'", format)
 	}
 
-	for _, f := range files {
-		err = writeFile(f.RelativePath, f.Data)
+	// Project-specific generators
+	for _, plugin := range plugins {
+		files, err = pipeline.RunPlugin(cmd.Context(), plugin, pipeline.PluginRequest{
+			SourcePath: sourcePath,
+			Format:     format,
+			Selectors:  []string{selector},
+			Config:     genConfig,
+		})
 		if err != nil {
 			return err
 		}
+		for _, f := range files {
+			err = writeFile(f.RelativePath, f.Data)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	// Jennies that need to be run post-file-write
 	if postProcess {
-		if format == FormatCUE {
-			files, err = postGenerateFilesCue(os.DirFS(sourcePath), kindGenConfig{
-				GoGenBasePath: goGenPath,
-				TSGenBasePath: tsGenPath,
-				CRDEncoding:   encType,
-				CRDPath:       defPath,
-				GroupKinds:    grouping == kindGroupingGroup,
-			}, selector)
+		files, err = postGenerateFiles(kindParser, os.DirFS(sourcePath), pipeline.Config{
+			GoGenBasePath: goGenPath,
+			TSGenBasePath: tsGenPath,
+			CRDEncoding:   encType,
+			CRDPath:       defPath,
+			GroupKinds:    grouping == kindGroupingGroup,
+		}, selector)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			err = writeFile(f.RelativePath, f.Data)
 			if err != nil {
 				return err
 			}
-			for _, f := range files {
-				err = writeFile(f.RelativePath, f.Data)
-				if err != nil {
-					return err
-				}
-			}
 		}
 	}
 
 	return nil
 }
 
-type kindGenConfig struct {
-	GoGenBasePath string
-	TSGenBasePath string
-	CRDEncoding   string
-	CRDPath       string
-	GroupKinds    bool
+func generateDashboardsCmdFunc(cmd *cobra.Command, _ []string) error {
+	sourcePath, err := cmd.Flags().GetString(sourceFlag)
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString(formatFlag)
+	if err != nil {
+		return err
+	}
+	selector, err := cmd.Flags().GetString(selectorFlag)
+	if err != nil {
+		return err
+	}
+	dashboardPath, err := cmd.Flags().GetString("dashboardpath")
+	if err != nil {
+		return err
+	}
+	metricsNamespace, err := cmd.Flags().GetString("metricsnamespace")
+	if err != nil {
+		return err
+	}
+	selectorLabel, err := cmd.Flags().GetString("selectorlabel")
+	if err != nil {
+		return err
+	}
+
+	_, manifestParser, err := kindParsers(format, sourcePath)
+	if err != nil {
+		return err
+	}
+	generator, err := codegen.NewGenerator[codegen.AppManifest](manifestParser, os.DirFS(sourcePath))
+	if err != nil {
+		return err
+	}
+	files, err := generator.Generate(cuekind.DashboardGenerator(metricsNamespace, selectorLabel), selector)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		err = writeFile(filepath.Join(dashboardPath, f.RelativePath), f.Data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-//nolint:funlen,goconst
-func generateKindsCue(modFS fs.FS, cfg kindGenConfig, selectors ...string) (codejen.Files, error) {
-	parser, err := cuekind.NewParser()
+//nolint:funlen
+func generateAlertsCmdFunc(cmd *cobra.Command, _ []string) error {
+	sourcePath, err := cmd.Flags().GetString(sourceFlag)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	// Slightly hacky multiple generators as an intermediary while we move to a better system.
-	// Both still source from a Manifest, but generatorForKinds supplies []Kind to jennies, vs AppManifest
-	generatorForKinds, err := codegen.NewGenerator[codegen.Kind](parser.KindParser(true), modFS)
+	format, err := cmd.Flags().GetString(formatFlag)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	generatorForManifest, err := codegen.NewGenerator[codegen.AppManifest](parser.ManifestParser(), modFS)
+	selector, err := cmd.Flags().GetString(selectorFlag)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	// Resource
-	resourceFiles, err := generatorForKinds.Generate(cuekind.ResourceGenerator(cfg.GroupKinds), selectors...)
+	alertsPath, err := cmd.Flags().GetString("alertspath")
 	if err != nil {
-		return nil, err
+		return err
 	}
-	for i, f := range resourceFiles {
-		resourceFiles[i].RelativePath = filepath.Join(cfg.GoGenBasePath, f.RelativePath)
+	encoding, err := cmd.Flags().GetString("encoding")
+	if err != nil {
+		return err
 	}
-	tsResourceFiles, err := generatorForKinds.Generate(cuekind.TypeScriptResourceGenerator(), selectors...)
+	metricsNamespace, err := cmd.Flags().GetString("metricsnamespace")
 	if err != nil {
-		return nil, err
+		return err
 	}
-	for i, f := range tsResourceFiles {
-		tsResourceFiles[i].RelativePath = filepath.Join(cfg.TSGenBasePath, f.RelativePath)
+	selectorLabel, err := cmd.Flags().GetString("selectorlabel")
+	if err != nil {
+		return err
+	}
+	thresholds := jennies.AlertThresholds{}
+	if thresholds.ReconcileErrorRatio, err = cmd.Flags().GetFloat64("reconcile-error-ratio"); err != nil {
+		return err
 	}
-	// CRD
-	var crdFiles codejen.Files
-	if cfg.CRDEncoding != "none" {
-		encFunc := json.Marshal
-		if cfg.CRDEncoding == "yaml" {
-			encFunc = yaml.Marshal
+	if thresholds.WebhookErrorRatio, err = cmd.Flags().GetFloat64("webhook-error-ratio"); err != nil {
+		return err
+	}
+	if thresholds.RetryQueueSize, err = cmd.Flags().GetInt("retry-queue-size"); err != nil {
+		return err
+	}
+	if thresholds.TerminalWatchErrors, err = cmd.Flags().GetInt("terminal-watch-errors"); err != nil {
+		return err
+	}
+	if thresholds.For, err = cmd.Flags().GetDuration("for"); err != nil {
+		return err
+	}
+	if thresholds.InformerSyncStalledFor, err = cmd.Flags().GetDuration("sync-stalled-for"); err != nil {
+		return err
+	}
+
+	var encFunc jennies.ManifestOutputEncoder
+	switch encoding {
+	case "json":
+		encFunc = func(v any) ([]byte, error) {
+			return json.MarshalIndent(v, "", "    ")
 		}
-		crdFiles, err = generatorForKinds.Generate(cuekind.CRDGenerator(encFunc, cfg.CRDEncoding), selectors...)
+	case "yaml":
+		encFunc = yaml.Marshal
+	default:
+		return fmt.Errorf("--encoding must be one of 'json'|'yaml'")
+	}
+
+	_, manifestParser, err := kindParsers(format, sourcePath)
+	if err != nil {
+		return err
+	}
+	generator, err := codegen.NewGenerator[codegen.AppManifest](manifestParser, os.DirFS(sourcePath))
+	if err != nil {
+		return err
+	}
+	files, err := generator.Generate(cuekind.AlertRulesGenerator(encFunc, encoding, metricsNamespace, selectorLabel, thresholds), selector)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		err = writeFile(filepath.Join(alertsPath, f.RelativePath), f.Data)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		for i, f := range crdFiles {
-			crdFiles[i].RelativePath = filepath.Join(cfg.CRDPath, f.RelativePath)
+	}
+	return nil
+}
+
+//nolint:funlen
+func generateRBACCmdFunc(cmd *cobra.Command, _ []string) error {
+	sourcePath, err := cmd.Flags().GetString(sourceFlag)
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString(formatFlag)
+	if err != nil {
+		return err
+	}
+	selector, err := cmd.Flags().GetString(selectorFlag)
+	if err != nil {
+		return err
+	}
+	rbacPath, err := cmd.Flags().GetString("rbacpath")
+	if err != nil {
+		return err
+	}
+	encoding, err := cmd.Flags().GetString("encoding")
+	if err != nil {
+		return err
+	}
+	namespace, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		return err
+	}
+	serviceAccount, err := cmd.Flags().GetString("serviceaccount")
+	if err != nil {
+		return err
+	}
+	namespaced, err := cmd.Flags().GetBool("namespaced")
+	if err != nil {
+		return err
+	}
+
+	var encFunc jennies.ManifestOutputEncoder
+	switch encoding {
+	case "json":
+		encFunc = func(v any) ([]byte, error) {
+			return json.MarshalIndent(v, "", "    ")
 		}
+	case "yaml":
+		encFunc = yaml.Marshal
+	default:
+		return fmt.Errorf("--encoding must be one of 'json'|'yaml'")
 	}
 
-	// Manifest
-	goManifestFiles, err := generatorForManifest.Generate(cuekind.ManifestGoGenerator(filepath.Base(cfg.GoGenBasePath)), selectors...)
+	_, manifestParser, err := kindParsers(format, sourcePath)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	generator, err := codegen.NewGenerator[codegen.AppManifest](manifestParser, os.DirFS(sourcePath))
+	if err != nil {
+		return err
 	}
-	for i, f := range goManifestFiles {
-		goManifestFiles[i].RelativePath = filepath.Join(cfg.GoGenBasePath, f.RelativePath)
+	files, err := generator.Generate(cuekind.RBACGenerator(encFunc, encoding, namespace, serviceAccount, namespaced), selector)
+	if err != nil {
+		return err
 	}
 
-	// Manifest CRD
-	var manifestFiles codejen.Files
-	if cfg.CRDEncoding != "none" {
-		encFunc := func(v any) ([]byte, error) {
+	for _, f := range files {
+		err = writeFile(filepath.Join(rbacPath, f.RelativePath), f.Data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//nolint:funlen,revive
+func generateWebhooksCmdFunc(cmd *cobra.Command, _ []string) error {
+	sourcePath, err := cmd.Flags().GetString(sourceFlag)
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString(formatFlag)
+	if err != nil {
+		return err
+	}
+	selector, err := cmd.Flags().GetString(selectorFlag)
+	if err != nil {
+		return err
+	}
+	webhooksPath, err := cmd.Flags().GetString("webhookspath")
+	if err != nil {
+		return err
+	}
+	encoding, err := cmd.Flags().GetString("encoding")
+	if err != nil {
+		return err
+	}
+	client := jennies.WebhookClientOptions{}
+	if client.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+	if client.ServiceName, err = cmd.Flags().GetString("service"); err != nil {
+		return err
+	}
+	if client.ServicePort, err = cmd.Flags().GetInt32("service-port"); err != nil {
+		return err
+	}
+	if client.URL, err = cmd.Flags().GetString("url"); err != nil {
+		return err
+	}
+	if client.Paths.Validate, err = cmd.Flags().GetString("validate-path"); err != nil {
+		return err
+	}
+	if client.Paths.Mutate, err = cmd.Flags().GetString("mutate-path"); err != nil {
+		return err
+	}
+	if client.CAInjectFrom, err = cmd.Flags().GetString("ca-inject-from"); err != nil {
+		return err
+	}
+	if client.FailurePolicy, err = cmd.Flags().GetString("failure-policy"); err != nil {
+		return err
+	}
+	caBundlePath, err := cmd.Flags().GetString("ca-bundle")
+	if err != nil {
+		return err
+	}
+	if caBundlePath != "" {
+		if client.CABundle, err = os.ReadFile(caBundlePath); err != nil {
+			return fmt.Errorf("unable to read --ca-bundle: %w", err)
+		}
+	}
+
+	var encFunc jennies.ManifestOutputEncoder
+	switch encoding {
+	case "json":
+		encFunc = func(v any) ([]byte, error) {
 			return json.MarshalIndent(v, "", "    ")
 		}
-		if cfg.CRDEncoding == "yaml" {
-			encFunc = yaml.Marshal
+	case "yaml":
+		encFunc = yaml.Marshal
+	default:
+		return fmt.Errorf("--encoding must be one of 'json'|'yaml'")
+	}
+
+	_, manifestParser, err := kindParsers(format, sourcePath)
+	if err != nil {
+		return err
+	}
+	generator, err := codegen.NewGenerator[codegen.AppManifest](manifestParser, os.DirFS(sourcePath))
+	if err != nil {
+		return err
+	}
+	files, err := generator.Generate(cuekind.WebhookConfigurationGenerator(encFunc, encoding, client), selector)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		err = writeFile(filepath.Join(webhooksPath, f.RelativePath), f.Data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//nolint:funlen
+func generateAPIServerManifestsCmdFunc(cmd *cobra.Command, _ []string) error {
+	sourcePath, err := cmd.Flags().GetString(sourceFlag)
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString(formatFlag)
+	if err != nil {
+		return err
+	}
+	selector, err := cmd.Flags().GetString(selectorFlag)
+	if err != nil {
+		return err
+	}
+	apiServerPath, err := cmd.Flags().GetString("apiserverpath")
+	if err != nil {
+		return err
+	}
+	encoding, err := cmd.Flags().GetString("encoding")
+	if err != nil {
+		return err
+	}
+	options := jennies.APIServerOptions{}
+	if options.Namespace, err = cmd.Flags().GetString("namespace"); err != nil {
+		return err
+	}
+	if options.Name, err = cmd.Flags().GetString("name"); err != nil {
+		return err
+	}
+	if options.Image, err = cmd.Flags().GetString("image"); err != nil {
+		return err
+	}
+	if options.Replicas, err = cmd.Flags().GetInt32("replicas"); err != nil {
+		return err
+	}
+	if options.Port, err = cmd.Flags().GetInt32("port"); err != nil {
+		return err
+	}
+	if options.GroupPriorityMinimum, err = cmd.Flags().GetInt32("group-priority-minimum"); err != nil {
+		return err
+	}
+	if options.CAInjectFrom, err = cmd.Flags().GetString("ca-inject-from"); err != nil {
+		return err
+	}
+	if options.EtcdServers, err = cmd.Flags().GetStringSlice("etcd-servers"); err != nil {
+		return err
+	}
+	if options.EtcdPrefix, err = cmd.Flags().GetString("etcd-prefix"); err != nil {
+		return err
+	}
+	caBundlePath, err := cmd.Flags().GetString("ca-bundle")
+	if err != nil {
+		return err
+	}
+	if caBundlePath != "" {
+		if options.CABundle, err = os.ReadFile(caBundlePath); err != nil {
+			return fmt.Errorf("unable to read --ca-bundle: %w", err)
 		}
+	}
+
+	var encFunc jennies.ManifestOutputEncoder
+	switch encoding {
+	case "json":
+		encFunc = func(v any) ([]byte, error) {
+			return json.MarshalIndent(v, "", "    ")
+		}
+	case "yaml":
+		encFunc = yaml.Marshal
+	default:
+		return fmt.Errorf("--encoding must be one of 'json'|'yaml'")
+	}
 
-		manifestFiles, err = generatorForManifest.Generate(cuekind.ManifestGenerator(encFunc, cfg.CRDEncoding), selectors...)
+	_, manifestParser, err := kindParsers(format, sourcePath)
+	if err != nil {
+		return err
+	}
+	generator, err := codegen.NewGenerator[codegen.AppManifest](manifestParser, os.DirFS(sourcePath))
+	if err != nil {
+		return err
+	}
+	files, err := generator.Generate(cuekind.APIServerManifestsGenerator(encFunc, encoding, options), selector)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		err = writeFile(filepath.Join(apiServerPath, f.RelativePath), f.Data)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		for i, f := range manifestFiles {
-			manifestFiles[i].RelativePath = filepath.Join(cfg.CRDPath, f.RelativePath)
+	}
+	return nil
+}
+
+// kindParsers returns the kind and manifest parsers for the kind source format.
+// For the CUE format, it also vendors the shared schema imports of the CUE module at sourcePath.
+func kindParsers(format, sourcePath string) (codegen.Parser[codegen.Kind], codegen.Parser[codegen.AppManifest], error) {
+	if format == FormatCUE {
+		if err := vendorCUEImports(sourcePath); err != nil {
+			return nil, nil, err
 		}
 	}
+	return pipeline.Parsers(format)
+}
 
-	allFiles := append(make(codejen.Files, 0), resourceFiles...)
-	allFiles = append(allFiles, tsResourceFiles...)
-	allFiles = append(allFiles, crdFiles...)
-	allFiles = append(allFiles, manifestFiles...)
-	allFiles = append(allFiles, goManifestFiles...)
-	return allFiles, nil
+// vendorCUEImports vendors the shared schema imports declared in the CUE module at sourcePath, if there are any
+func vendorCUEImports(sourcePath string) error {
+	imports, err := cuekind.LoadSchemaImports(sourcePath)
+	if err != nil {
+		return err
+	}
+	if imports == nil {
+		return nil
+	}
+	return cuekind.VendorSchemaImports(sourcePath, imports)
 }
 
-func postGenerateFilesCue(modFS fs.FS, cfg kindGenConfig, selectors ...string) (codejen.Files, error) {
+func postGenerateFiles(kindParser codegen.Parser[codegen.Kind], modFS fs.FS, cfg pipeline.Config, selectors ...string) (codejen.Files, error) {
 	// Get the repo from the go.mod file
 	repo, err := getGoModule(cfg.GoGenBasePath)
 	if err != nil {
 		return nil, err
 	}
-	parser, err := cuekind.NewParser()
-	if err != nil {
-		return nil, err
-	}
-	generator, err := codegen.NewGenerator[codegen.Kind](parser.KindParser(true), modFS)
+	generator, err := codegen.NewGenerator[codegen.Kind](kindParser, modFS)
 	if err != nil {
 		return nil, err
 	}
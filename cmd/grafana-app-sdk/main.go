@@ -4,17 +4,24 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+
+	"github.com/grafana/grafana-app-sdk/codegen/pipeline"
 )
 
 const (
-	FormatCUE  = "cue"
-	FormatNone = "none"
+	FormatCUE     = pipeline.FormatCUE
+	FormatOpenAPI = pipeline.FormatOpenAPI
+	FormatProto   = pipeline.FormatProto
+	FormatNone    = "none"
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "grafana-app-sdk <command>",
 	Short: "A tool for working with grafana apps, used for generating code from CUE kinds, creating project boilerplate, and running local deployments",
 	Long:  "A tool for working with grafana apps, used for generating code from CUE kinds, creating project boilerplate, and running local deployments",
+
+	PersistentPreRunE:  setupOutput,
+	PersistentPostRunE: writeOutput,
 }
 
 // Persistent flags for all commands
@@ -26,19 +33,28 @@ const (
 
 func main() {
 	rootCmd.PersistentFlags().StringP(sourceFlag, "s", "kinds", "Path to directory with your codegen source files (such as a CUE module)")
-	rootCmd.PersistentFlags().StringP(formatFlag, "f", FormatCUE, "Format in which kinds are written for this project (currently allowed values are 'cue')")
+	rootCmd.PersistentFlags().StringP(formatFlag, "f", FormatCUE, "Format in which kinds are written for this project (currently allowed values are 'cue', 'openapi', and 'proto')")
 	rootCmd.PersistentFlags().String(selectorFlag, "manifest", "Path selector to use for the manifest")
+	rootCmd.PersistentFlags().String(outputFlag, OutputText, `Format of the command's output. Allowed values are 'text', 'json', and 'yaml'.
+With 'json' and 'yaml', the result of the command is written to stdout as a single document, and all other messages are written to stderr.`)
 
 	setupVersionCmd()
 	setupGenerateCmd()
 	setupProjectCmd()
+	setupDebugCmd()
+	setupBundleCmd()
+	setupMigrateCmd()
 
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(generateCmd)
 	rootCmd.AddCommand(projectCmd)
+	rootCmd.AddCommand(debugCmd)
+	rootCmd.AddCommand(bundleCmd)
+	rootCmd.AddCommand(migrateCmd)
 
 	err := rootCmd.Execute()
 	if err != nil {
+		_ = cmdOutput.writeError(err)
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/k8s"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+const migrateListPageSize = 100
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate <command>",
+	Short: "Migrate objects stored in a kubernetes cluster",
+}
+
+var migrateAnnotationsCmd = &cobra.Command{
+	Use:   "annotations --group <group> --version <version> --kind <kind> --mapping <annotation>=<field>[:<type>]",
+	Short: "Move annotation values of all objects of a kind into spec fields",
+	Long: `Move annotation values of all objects of a kind into spec fields, updating each object in the cluster.
+Each mapping is of the form <annotation>=<field>[:<type>], where <field> is the dot-separated path of the field in the spec,
+and <type> is one of 'string' (the default), 'int', 'float', 'bool', or 'json'. Fields which are already set are not overwritten.
+The same mappings can be used with app.AnnotationFieldMapper in the kind's conversions between versions.
+With --reverse, the fields are moved back into the annotations instead.`,
+	RunE: migrateAnnotationsCmdFunc,
+}
+
+func setupMigrateCmd() {
+	migrateAnnotationsCmd.Flags().String("kubeconfig", "", "Path to the kubeconfig of the cluster. Defaults to $KUBECONFIG or ~/.kube/config")
+	migrateAnnotationsCmd.Flags().String("group", "", "Group of the kind")
+	migrateAnnotationsCmd.Flags().String("version", "", "Version of the kind the objects are read and updated as")
+	migrateAnnotationsCmd.Flags().String("kind", "", "Name of the kind")
+	migrateAnnotationsCmd.Flags().String("plural", "", "Plural name of the kind. Defaults to the lowercase kind name with an 's' appended")
+	migrateAnnotationsCmd.Flags().String("namespace", "", "Namespace of the objects to migrate. Defaults to all namespaces")
+	migrateAnnotationsCmd.Flags().StringArray("mapping", nil, "Mapping of an annotation to a spec field, of the form <annotation>=<field>[:<type>]. May be repeated")
+	migrateAnnotationsCmd.Flags().Bool("keep-annotations", false, "Keep the annotations on the objects after moving them to the fields (or keep the fields with --reverse)")
+	migrateAnnotationsCmd.Flags().Bool("reverse", false, "Move the fields back into the annotations")
+	migrateAnnotationsCmd.Flags().Bool("dry-run", false, "Report the objects which would be migrated, without updating them")
+	for _, flag := range []string{"group", "version", "kind", "mapping"} {
+		_ = migrateAnnotationsCmd.MarkFlagRequired(flag)
+	}
+
+	migrateCmd.AddCommand(migrateAnnotationsCmd)
+
+	// Don't show "usage" information when an error is returned form the command,
+	// because our errors are not command-usage-based
+	for _, c := range migrateCmd.Commands() {
+		c.SilenceUsage = true
+	}
+}
+
+// migrateResult is the machine-readable result of the migrate annotations command
+type migrateResult struct {
+	Migrated  []string `json:"migrated,omitempty" yaml:"migrated,omitempty"`
+	Unchanged int      `json:"unchanged" yaml:"unchanged"`
+	DryRun    bool     `json:"dryRun" yaml:"dryRun"`
+}
+
+//nolint:funlen
+func migrateAnnotationsCmdFunc(cmd *cobra.Command, _ []string) error {
+	kubeConfigPath, err := cmd.Flags().GetString("kubeconfig")
+	if err != nil {
+		return err
+	}
+	group, err := cmd.Flags().GetString("group")
+	if err != nil {
+		return err
+	}
+	version, err := cmd.Flags().GetString("version")
+	if err != nil {
+		return err
+	}
+	kindName, err := cmd.Flags().GetString("kind")
+	if err != nil {
+		return err
+	}
+	plural, err := cmd.Flags().GetString("plural")
+	if err != nil {
+		return err
+	}
+	namespace, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		return err
+	}
+	mappingStrings, err := cmd.Flags().GetStringArray("mapping")
+	if err != nil {
+		return err
+	}
+	keepAnnotations, err := cmd.Flags().GetBool("keep-annotations")
+	if err != nil {
+		return err
+	}
+	reverse, err := cmd.Flags().GetBool("reverse")
+	if err != nil {
+		return err
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+
+	mappings := make([]app.AnnotationFieldMapping, 0, len(mappingStrings))
+	for _, m := range mappingStrings {
+		mapping, err := app.ParseAnnotationFieldMapping(m)
+		if err != nil {
+			return err
+		}
+		mapping.KeepAnnotation = keepAnnotations
+		mappings = append(mappings, mapping)
+	}
+	mapper, err := app.NewAnnotationFieldMapper(mappings...)
+	if err != nil {
+		return err
+	}
+	move := mapper.MoveToFields
+	if reverse {
+		move = mapper.MoveToAnnotations
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeConfigPath
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("unable to load kubeconfig: %w", err)
+	}
+	restConfig.APIPath = "/apis"
+	schemaOpts := []resource.SimpleSchemaOption{resource.WithKind(kindName)}
+	if plural != "" {
+		schemaOpts = append(schemaOpts, resource.WithPlural(plural))
+	}
+	kind := resource.Kind{
+		Schema: resource.NewSimpleSchema(group, version, &resource.UntypedObject{}, &resource.UntypedList{}, schemaOpts...),
+		Codecs: map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()},
+	}
+	client, err := k8s.NewClientRegistry(*restConfig, k8s.DefaultClientConfig()).ClientFor(kind)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	result := migrateResult{
+		DryRun: dryRun,
+	}
+	failures := make([]error, 0)
+	iter := resource.NewListIterator(client, namespace, resource.ListOptions{Limit: migrateListPageSize})
+	for iter.Next(ctx) {
+		obj := iter.Object()
+		name := obj.GetName()
+		if obj.GetNamespace() != "" {
+			name = fmt.Sprintf("%s/%s", obj.GetNamespace(), obj.GetName())
+		}
+		changed, err := move(obj)
+		if err == nil && changed && !dryRun {
+			// The ResourceVersion of the listed object is used, so objects changed since they were listed fail with a conflict
+			_, err = client.Update(ctx, resource.Identifier{Namespace: obj.GetNamespace(), Name: obj.GetName()}, obj, resource.UpdateOptions{
+				ResourceVersion: obj.GetResourceVersion(),
+			})
+		}
+		switch {
+		case err != nil:
+			failures = append(failures, fmt.Errorf("%s: %w", name, err))
+			cmdOutput.Printf(" * Failed to migrate %s: %s\n", name, err.Error())
+		case changed:
+			result.Migrated = append(result.Migrated, name)
+			if dryRun {
+				cmdOutput.Printf(" * Would migrate %s\n", name)
+			} else {
+				cmdOutput.Printf(" * Migrated %s\n", name)
+			}
+		default:
+			result.Unchanged++
+		}
+	}
+	if err = iter.Err(); err != nil {
+		return fmt.Errorf("unable to list %s: %w", kind.GroupVersionKind().String(), err)
+	}
+
+	if dryRun {
+		cmdOutput.Printf("Dry run: %d objects would be migrated, %d are unchanged\n", len(result.Migrated), result.Unchanged)
+	} else {
+		cmdOutput.Printf("Migrated %d objects, %d are unchanged\n", len(result.Migrated), result.Unchanged)
+	}
+	if len(failures) > 0 {
+		// Objects which were migrated are not rolled back, and re-running the command only updates the objects which failed
+		return fmt.Errorf("failed to migrate %d objects: %w", len(failures), errors.Join(failures...))
+	}
+	cmdOutput.setResult(result)
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	outputFlag = "output"
+
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+)
+
+// cmdOutput is the output of the command being run
+var cmdOutput = &commandOutput{
+	format: OutputText,
+	stdout: os.Stdout,
+	stderr: os.Stderr,
+}
+
+// commandOutput handles the output of a command. With text output, messages are printed to stdout as they happen.
+// With JSON or YAML output, messages are printed to stderr, and the command's result is written to stdout
+// as a single document once the command completes, so that stdout can be parsed by other tools.
+type commandOutput struct {
+	format string
+	stdout io.Writer
+	stderr io.Writer
+	// files are the paths of the files written by the command, in the order they were written
+	files []string
+	// result is the result of the command, if it has one other than the files it writes
+	result any
+	// streamed is true if the command has already written its result to stdout as it ran
+	streamed bool
+}
+
+// filesResult is the machine-readable result of commands which write files
+type filesResult struct {
+	Files []string `json:"files" yaml:"files"`
+}
+
+// errorResult is the machine-readable result of a command which returns an error
+type errorResult struct {
+	Error string `json:"error" yaml:"error"`
+}
+
+// versionResult is the machine-readable result of the version command
+type versionResult struct {
+	Version string `json:"version" yaml:"version"`
+	Source  string `json:"source" yaml:"source"`
+	Commit  string `json:"commit" yaml:"commit"`
+	Date    string `json:"date" yaml:"date"`
+}
+
+// setupOutput is the PersistentPreRunE of rootCmd, which sets the output format for the command from the --output flag
+func setupOutput(cmd *cobra.Command, _ []string) error {
+	format, err := cmd.Flags().GetString(outputFlag)
+	if err != nil {
+		return err
+	}
+	if format != OutputText && format != OutputJSON && format != OutputYAML {
+		return fmt.Errorf("--%s must be one of 'text'|'json'|'yaml'", outputFlag)
+	}
+	cmdOutput.format = format
+	return nil
+}
+
+// writeOutput is the PersistentPostRunE of rootCmd, which writes the result of the command for JSON and YAML output
+func writeOutput(_ *cobra.Command, _ []string) error {
+	return cmdOutput.write()
+}
+
+// Printf prints a human-readable message
+func (o *commandOutput) Printf(format string, args ...any) {
+	w := o.stdout
+	if o.format != OutputText {
+		w = o.stderr
+	}
+	fmt.Fprintf(w, format, args...)
+}
+
+// Println prints a human-readable message, followed by a newline
+func (o *commandOutput) Println(args ...any) {
+	o.Printf("%s", fmt.Sprintln(args...))
+}
+
+// fileWritten records that the file at path was written
+func (o *commandOutput) fileWritten(path string) {
+	o.files = append(o.files, path)
+	o.Printf(" * Writing file %s\n", path)
+}
+
+// setResult sets the result of the command, which is written instead of the list of written files
+func (o *commandOutput) setResult(result any) {
+	o.result = result
+}
+
+// text returns true if the output is human-readable text
+func (o *commandOutput) text() bool {
+	return o.format == OutputText
+}
+
+// writeError writes err as the result of the command for JSON and YAML output
+func (o *commandOutput) writeError(err error) error {
+	o.result = errorResult{
+		Error: err.Error(),
+	}
+	return o.write()
+}
+
+func (o *commandOutput) write() error {
+	if o.text() || o.streamed {
+		return nil
+	}
+	result := o.result
+	if result == nil {
+		files := o.files
+		if files == nil {
+			files = make([]string, 0)
+		}
+		result = filesResult{
+			Files: files,
+		}
+	}
+	var (
+		b   []byte
+		err error
+	)
+	if o.format == OutputJSON {
+		b, err = json.MarshalIndent(result, "", "  ")
+		b = append(b, '\n')
+	} else {
+		b, err = yaml.Marshal(result)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = o.stdout.Write(b)
+	return err
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/grafana/codejen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/codegen/codegentest"
+)
+
+// outputGoldenPath is the directory of the golden files for the machine-readable output of each command.
+// Update them by running the tests with the codegentest.UpdateEnvVar environment variable set.
+const outputGoldenPath = "testdata/output"
+
+func TestCommandOutput_Golden(t *testing.T) {
+	tests := []struct {
+		name string
+		// run sets the result of the command on o, the way the command would
+		run func(o *commandOutput) error
+	}{{
+		name: "generate",
+		run: func(o *commandOutput) error {
+			o.fileWritten("pkg/generated/resource/foo/v1/foo_object_gen.go")
+			o.fileWritten("definitions/foo.foo.ext.grafana.com.json")
+			return o.write()
+		},
+	}, {
+		name: "no-files",
+		run: func(o *commandOutput) error {
+			return o.write()
+		},
+	}, {
+		name: "error",
+		run: func(o *commandOutput) error {
+			return o.writeError(errors.New("unable to parse kinds: field not allowed"))
+		},
+	}, {
+		name: "version",
+		run: func(o *commandOutput) error {
+			o.setResult(versionResult{
+				Version: "v0.30.0",
+				Source:  "module",
+				Commit:  "0123456789abcdef",
+				Date:    "2026-01-02T03:04:05Z",
+			})
+			return o.write()
+		},
+	}, {
+		name: "bundle-verify",
+		run: func(o *commandOutput) error {
+			o.setResult(bundleResult{
+				AppName: "foo",
+				Files:   []string{"manifest.json", "crds/foos.foo.ext.grafana.com.json", "operator/deployment.yaml"},
+			})
+			return o.write()
+		},
+	}, {
+		name: "bundle-install",
+		run: func(o *commandOutput) error {
+			o.setResult(bundleResult{
+				AppName:  "foo",
+				Applied:  []string{"CustomResourceDefinition/foos.foo.ext.grafana.com", "Deployment/foo-operator"},
+				Skipped:  []string{"manifest.json"},
+				Unserved: []string{"ServiceMonitor/foo-operator"},
+			})
+			return o.write()
+		},
+	}, {
+		name: "migrate-annotations",
+		run: func(o *commandOutput) error {
+			o.setResult(migrateResult{
+				Migrated:  []string{"default/foo", "default/bar"},
+				Unchanged: 3,
+				DryRun:    true,
+			})
+			return o.write()
+		},
+	}}
+
+	for _, format := range []string{OutputJSON, OutputYAML} {
+		files := make(codejen.Files, 0, len(tests))
+		for _, test := range tests {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			o := &commandOutput{
+				format: format,
+				stdout: stdout,
+				stderr: stderr,
+			}
+			require.Nil(t, test.run(o), test.name)
+			files = append(files, codejen.File{
+				RelativePath: test.name + "." + format,
+				Data:         stdout.Bytes(),
+			})
+		}
+		codegentest.AssertGolden(t, files, outputGoldenPath, "")
+	}
+}
+
+func TestCommandOutput_Text(t *testing.T) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	o := &commandOutput{
+		format: OutputText,
+		stdout: stdout,
+		stderr: stderr,
+	}
+	o.fileWritten("foo.go")
+	o.setResult(versionResult{Version: "v0.30.0"})
+	require.Nil(t, o.write())
+	// Text output is only the human-readable messages
+	assert.Equal(t, " * Writing file foo.go\n", stdout.String())
+	assert.Empty(t, stderr.String())
+
+	// With machine-readable output, the messages go to stderr
+	stdout.Reset()
+	o.format = OutputJSON
+	o.Printf("Generating %d files\n", 1)
+	assert.Empty(t, stdout.String())
+	assert.Equal(t, "Generating 1 files\n", stderr.String())
+}
+
+func TestPrintReconcileEvents_Golden(t *testing.T) {
+	events := strings.Join([]string{
+		`{"time":"2026-01-02T03:04:05Z","kind":"Foo","namespace":"default","name":"foo","action":"CREATE","outcome":"success","duration":1500000}`,
+		``,
+		`{"time":"2026-01-02T03:04:06Z","kind":"Foo","namespace":"default","name":"bar","action":"UPDATE","retry":true,"outcome":"error","duration":2000000,"error":"I AM ERROR","requeueAfter":5000000000}`,
+	}, "\n")
+	files := make(codejen.Files, 0, 2)
+	for _, format := range []string{OutputJSON, OutputYAML} {
+		out := &bytes.Buffer{}
+		require.Nil(t, printReconcileEvents(strings.NewReader(events), out, format))
+		files = append(files, codejen.File{
+			RelativePath: "debug-reconcile-events." + format,
+			Data:         out.Bytes(),
+		})
+	}
+	codegentest.AssertGolden(t, files, outputGoldenPath, "")
+}
@@ -93,7 +93,7 @@ Allowed values are 'group' and 'kind'. This should match the flag used in the 'g
 //nolint:revive,lll,funlen
 func projectInit(cmd *cobra.Command, args []string) error {
 	if len(args) != 1 {
-		fmt.Println("Usage: grafana-app-sdk project init [options] <module_name>")
+		cmdOutput.Println("Usage: grafana-app-sdk project init [options] <module_name>")
 		os.Exit(1)
 	}
 
@@ -224,14 +224,14 @@ func projectWriteGoModule(path, moduleName string, overwrite bool) (string, erro
 					return moduleName, err
 				}
 			} else {
-				fmt.Println("Not initializing go module")
+				cmdOutput.Println("Not initializing go module")
 			}
 		} else if mod != moduleName {
 			if promptYN(fmt.Sprintf("Go module already exists at '%s', with diffing module name '%s'. Use existing module name '%s'?", goModPath, mod, mod), true) {
-				fmt.Printf("Using new module name '%s'.\n", mod)
+				cmdOutput.Printf("Using new module name '%s'.\n", mod)
 				moduleName = mod
 			} else {
-				fmt.Printf("Continuing to use provided module name '%s'.\n", moduleName)
+				cmdOutput.Printf("Continuing to use provided module name '%s'.\n", moduleName)
 			}
 			if promptYN("Do you want to overwrite the existing go.mod file?", false) {
 				err = writeFile(goModPath, goModContents)
@@ -265,7 +265,7 @@ func projectWriteGoModule(path, moduleName string, overwrite bool) (string, erro
 //nolint:revive,funlen
 func projectAddKind(cmd *cobra.Command, args []string) error {
 	if len(args) < 1 {
-		fmt.Println(`Usage: grafana-app-sdk project add kind [options] <Human-Readable Kind Name>
+		cmdOutput.Println(`Usage: grafana-app-sdk project add kind [options] <Human-Readable Kind Name>
 	example:
 		grafana-app-sdk project add kind "MyKind"`)
 		os.Exit(1)
@@ -363,7 +363,7 @@ func projectAddKind(cmd *cobra.Command, args []string) error {
 //nolint:revive,funlen,gocyclo
 func projectAddComponent(cmd *cobra.Command, args []string) error {
 	if len(args) < 1 {
-		fmt.Println(`Usage: grafana-app-sdk project add component [options] <components>
+		cmdOutput.Println(`Usage: grafana-app-sdk project add component [options] <components>
 	where <components> are one or more of:
 		backend
 		frontend
@@ -411,21 +411,13 @@ func projectAddComponent(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create the generator (used for generating non-static code)
-	var generator any
-	var manifestParser codegen.Parser[codegen.AppManifest]
-	switch format {
-	case FormatCUE:
-		parser, err := cuekind.NewParser()
-		if err != nil {
-			return err
-		}
-		generator, err = codegen.NewGenerator[codegen.Kind](parser.KindParser(true), os.DirFS(sourcePath))
-		if err != nil {
-			return err
-		}
-		manifestParser = parser.ManifestParser()
-	default:
-		return fmt.Errorf("unknown kind format '%s'", format)
+	kindParser, manifestParser, err := kindParsers(format, sourcePath)
+	if err != nil {
+		return err
+	}
+	generator, err := codegen.NewGenerator[codegen.Kind](kindParser, os.DirFS(sourcePath))
+	if err != nil {
+		return err
 	}
 
 	manifests, err := manifestParser.Parse(os.DirFS(sourcePath), selector)
@@ -441,31 +433,24 @@ func projectAddComponent(cmd *cobra.Command, args []string) error {
 	for _, component := range args {
 		switch component {
 		case "backend":
-			switch format {
-			case FormatCUE:
-				err = addComponentBackend(path, generator.(*codegen.Generator[codegen.Kind]), []string{selector}, manifest.Properties().Group, kindGrouping == kindGroupingGroup)
-			default:
-				return fmt.Errorf("unknown kind format '%s'", format)
-			}
+			err = addComponentBackend(path, generator, []string{selector}, manifest.Properties().Group, kindGrouping == kindGroupingGroup)
 			if err != nil {
-				fmt.Printf("%s\n", err.Error())
+				cmdOutput.Printf("%s\n", err.Error())
+				_ = cmdOutput.writeError(err)
 				os.Exit(1)
 			}
 		case "frontend":
 			err = addComponentFrontend(path, manifest.Properties().Group)
 			if err != nil {
-				fmt.Printf("%s\n", err.Error())
+				cmdOutput.Printf("%s\n", err.Error())
+				_ = cmdOutput.writeError(err)
 				os.Exit(1)
 			}
 		case "operator":
-			switch format {
-			case FormatCUE:
-				err = addComponentOperator(path, generator.(*codegen.Generator[codegen.Kind]), []string{selector}, kindGrouping == kindGroupingGroup, !overwrite)
-			default:
-				return fmt.Errorf("unknown kind format '%s'", format)
-			}
+			err = addComponentOperator(path, generator, []string{selector}, kindGrouping == kindGroupingGroup, !overwrite)
 			if err != nil {
-				fmt.Printf("%s\n", err.Error())
+				cmdOutput.Printf("%s\n", err.Error())
+				_ = cmdOutput.writeError(err)
 				os.Exit(1)
 			}
 		default:
@@ -635,12 +620,12 @@ func addComponentFrontend(projectRootPath string, manifestGroup string) error {
 	if err != nil {
 		return err
 	}
-	fmt.Println("Creating plugin frontend using `\033[0;32myarn create @grafana/plugin\033[0m` (this may take a moment)...")
+	cmdOutput.Println("Creating plugin frontend using `\033[0;32myarn create @grafana/plugin\033[0m` (this may take a moment)...")
 	err = cmd.Wait()
 	if err != nil {
 		// Only print command output on error
-		fmt.Println(buf.String())
-		fmt.Println(ebuf.String())
+		cmdOutput.Println(buf.String())
+		cmdOutput.Println(ebuf.String())
 		return err
 	}
 
@@ -199,22 +199,18 @@ func projectLocalEnvGenerate(cmd *cobra.Command, _ []string) error {
 
 	// Generate the k8s YAML bundle
 	parseFunc := func() (codejen.Files, error) {
-		switch format {
-		case FormatCUE:
-			parser, err := cuekind.NewParser()
-			if err != nil {
-				return nil, err
-			}
-			generator, err := codegen.NewGenerator[codegen.Kind](parser.KindParser(true), os.DirFS(sourcePath))
-			if err != nil {
-				return nil, err
-			}
-			return generator.Generate(cuekind.CRDGenerator(yaml.Marshal, "yaml"), selector)
-		case FormatNone:
+		if format == FormatNone {
 			return codejen.Files{}, nil
-		default:
-			return nil, fmt.Errorf("unknown kind format '%s'", format)
 		}
+		kindParser, _, err := kindParsers(format, sourcePath)
+		if err != nil {
+			return nil, err
+		}
+		generator, err := codegen.NewGenerator[codegen.Kind](kindParser, os.DirFS(sourcePath))
+		if err != nil {
+			return nil, err
+		}
+		return generator.Generate(cuekind.CRDGenerator(yaml.Marshal, "yaml"), selector)
 	}
 
 	k8sYAML, genProps, err := generateKubernetesYAML(parseFunc, pluginID, *config)
@@ -763,12 +759,12 @@ func updateLocalConfigFromManifest(config *localEnvConfig, format string, cuePat
 		Kind string           `json:"kind"`
 		Spec app.ManifestData `json:"spec"`
 	}
-	if format == FormatCUE {
-		parser, err := cuekind.NewParser()
+	if format != FormatNone {
+		_, manifestParser, err := kindParsers(format, cuePath)
 		if err != nil {
 			return err
 		}
-		generator, err := codegen.NewGenerator[codegen.AppManifest](parser.ManifestParser(), os.DirFS(cuePath))
+		generator, err := codegen.NewGenerator[codegen.AppManifest](manifestParser, os.DirFS(cuePath))
 		if err != nil {
 			return err
 		}
@@ -793,7 +789,8 @@ func updateLocalConfigFromManifest(config *localEnvConfig, format string, cuePat
 					if v.Admission != nil && v.Admission.SupportsAnyValidation() {
 						config.Webhooks.Validating = true
 					}
-					if v.Admission != nil && v.Admission.SupportsAnyMutation() {
+					// Defaulting is served by the mutating webhook
+					if v.Admission != nil && (v.Admission.SupportsAnyMutation() || v.Admission.SupportsAnyDefaulting()) {
 						config.Webhooks.Mutating = true
 					}
 				}
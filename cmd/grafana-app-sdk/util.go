@@ -37,7 +37,7 @@ func writeFile(path string, contents []byte) error {
 			return err
 		}
 	}
-	fmt.Printf(" * Writing file %s\n", path)
+	cmdOutput.fileWritten(path)
 	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
 	if err != nil {
 		return err
@@ -66,7 +66,7 @@ func writeExecutableFile(path string, contents []byte) error {
 			return err
 		}
 	}
-	fmt.Printf(" * Writing file %s\n", path)
+	cmdOutput.fileWritten(path)
 	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0777)
 	if err != nil {
 		return err
@@ -90,7 +90,7 @@ func promptYN(prompt string, defaultAnswer bool) bool {
 	}
 	input := make([]byte, 1)
 	for {
-		fmt.Printf("%s [%s/%s]: ", prompt, y, n)
+		cmdOutput.Printf("%s [%s/%s]: ", prompt, y, n)
 		_, err := bufio.NewReader(os.Stdin).Read(input)
 		if err != nil {
 			panic(err)
@@ -104,7 +104,7 @@ func promptYN(prompt string, defaultAnswer bool) bool {
 		if input[0] == 'n' || input[0] == 'N' {
 			return false
 		}
-		fmt.Printf("Could not parse input beginning with '%s', please try again:\n", string(input[0]))
+		cmdOutput.Printf("Could not parse input beginning with '%s', please try again:\n", string(input[0]))
 	}
 }
 
@@ -51,6 +51,15 @@ func getVersion(cmd *cobra.Command, args []string) error {
 			source = "unknown"
 		}
 	}
+	if !cmdOutput.text() {
+		cmdOutput.setResult(versionResult{
+			Version: version,
+			Source:  source,
+			Commit:  commit,
+			Date:    date,
+		})
+		return nil
+	}
 	if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
 		fmt.Printf(versionOutputTemplateVerbose, version, source, commit, date)
 	} else {
@@ -0,0 +1,104 @@
+// Package codegentest provides helpers for testing that generated code is up to date with the kinds it is generated from.
+// It regenerates a project's files in memory, as the `grafana-app-sdk generate` command would,
+// and compares them against the committed (golden) files, so that a test fails if the kinds change without
+// regenerating, or if a new SDK version generates different code.
+//
+// Golden files are updated by running the test with the UpdateEnvVar environment variable set:
+//
+//	UPDATE_GOLDEN=1 go test ./... -run TestGenerated
+package codegentest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/codejen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/codegen/pipeline"
+)
+
+// UpdateEnvVar is the environment variable which, if set to a non-empty value, makes AssertGolden
+// update golden files with the generated output, instead of comparing against them
+const UpdateEnvVar = "UPDATE_GOLDEN"
+
+// Options are the options for generating and comparing files with Check
+type Options struct {
+	// SourcePath is the path to the directory with the kinds, relative to the test's working directory.
+	// This is the --source flag of the generate command.
+	SourcePath string
+	// Format is the format of the kinds, one of pipeline.FormatCUE, pipeline.FormatOpenAPI, or pipeline.FormatProto.
+	// Defaults to pipeline.FormatCUE.
+	Format string
+	// Selectors are the manifests to generate from. This is the --manifest flag of the generate command.
+	Selectors []string
+	// Config is the configuration of the built-in generators, which should match the flags the project generates with.
+	// The paths in Config are relative to GoldenPath.
+	Config pipeline.Config
+	// Hooks are the project's custom generators, if it has any
+	Hooks pipeline.Hooks
+	// GoldenPath is the path to the directory which the paths of generated files are relative to,
+	// relative to the test's working directory. For a project's own generated files, this is the project root.
+	GoldenPath string
+	// Suffix is appended to the path of each generated file to get the path of its golden file.
+	// It can be used to keep golden files from being compiled, such as with ".txt".
+	Suffix string
+}
+
+// Check generates files as described by opts, and compares them to their golden files with AssertGolden
+func Check(t testing.TB, opts Options) {
+	t.Helper()
+	AssertGolden(t, Generate(t, opts), opts.GoldenPath, opts.Suffix)
+}
+
+// Generate generates files as described by opts, with the built-in generators and opts.Hooks.
+// The relative paths of the returned files are the same as the generate command would write them to.
+// Post-processing and codegen plugins are not run. Generate fails the test if generation fails.
+// Generate only reads SourcePath: CUE schema imports are not fetched or vendored, so the vendored imports
+// (in cue.mod/pkg) must be committed, or vendored by running the generate command first.
+func Generate(t testing.TB, opts Options) codejen.Files {
+	t.Helper()
+	format := opts.Format
+	if format == "" {
+		format = pipeline.FormatCUE
+	}
+	kindParser, manifestParser, err := pipeline.Parsers(format)
+	require.Nil(t, err)
+	modFS := os.DirFS(opts.SourcePath)
+	files, err := pipeline.Generate(kindParser, manifestParser, modFS, opts.Config, opts.Selectors...)
+	require.Nil(t, err, "unable to generate files")
+	if !opts.Hooks.Empty() {
+		hookFiles, err := pipeline.GenerateHooks(kindParser, manifestParser, modFS, opts.Hooks, opts.Selectors...)
+		require.Nil(t, err, "unable to generate files")
+		files = append(files, hookFiles...)
+	}
+	return files
+}
+
+// AssertGolden compares each file in files to its golden file, found at the file's relative path (with suffix appended)
+// in goldenPath. It reports an error for each file which differs from, or has no, golden file.
+// Golden files which have no generated file (such as for a removed kind) are not reported.
+// If UpdateEnvVar is set, the golden files are written with the contents of files instead.
+func AssertGolden(t testing.TB, files codejen.Files, goldenPath, suffix string) {
+	t.Helper()
+	update := os.Getenv(UpdateEnvVar) != ""
+	for _, f := range files {
+		path := filepath.Join(goldenPath, f.RelativePath+suffix)
+		if update {
+			require.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.Nil(t, os.WriteFile(path, f.Data, 0600), "unable to update golden file %s", path)
+			continue
+		}
+		golden, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			t.Errorf("golden file %s does not exist for generated file %s; run the test with %s set to create it", path, f.RelativePath, UpdateEnvVar)
+			continue
+		}
+		require.Nil(t, err, "unable to read golden file %s", path)
+		// Compare as strings for a readable diff
+		assert.Equal(t, string(golden), string(f.Data), "generated file %s differs from golden file %s; "+
+			"if the change is expected, run the test with %s set", f.RelativePath, path, UpdateEnvVar)
+	}
+}
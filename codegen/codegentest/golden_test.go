@@ -0,0 +1,111 @@
+package codegentest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/grafana/codejen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/codegen/pipeline"
+)
+
+// TestGenerate_SDKGoldenFiles checks the SDK's own generated output for its test kinds
+// against the golden files written by scripts/regenerate_golden_test_files.sh
+func TestGenerate_SDKGoldenFiles(t *testing.T) {
+	files := Generate(t, Options{
+		SourcePath: "../cuekind/testing",
+		Selectors:  []string{"customManifest"},
+		Config: pipeline.Config{
+			GoGenBasePath:    "go/groupbykind",
+			TSGenBasePath:    "typescript/versioned",
+			TSValidators:     pipeline.TSValidatorsZod,
+			PyGenBasePath:    "python",
+			ProtoGenBasePath: "protobuf",
+			CRDEncoding:      "json",
+			CRDPath:          "crd",
+			Clientset:        true,
+		},
+	})
+	// The manifest go file's golden file is generated with --grouping=group, so it is checked by the cuekind tests instead.
+	// CRDs and the manifest are generated in the same directory, but their golden files are kept in separate directories.
+	compare := make(codejen.Files, 0, len(files))
+	for _, f := range files {
+		switch {
+		case f.RelativePath == filepath.Join("go", "groupbykind", "customapp_manifest.go"):
+			continue
+		case strings.HasSuffix(f.RelativePath, "-manifest.json"):
+			f.RelativePath = filepath.Join("manifest", filepath.Base(f.RelativePath))
+		}
+		compare = append(compare, f)
+	}
+	// 20 go, 12 typescript, 8 python, 2 protobuf, 1 CRD, and 1 manifest file
+	assert.Len(t, compare, 44)
+	assert.True(t, slices.ContainsFunc(compare, func(f codejen.File) bool {
+		return f.RelativePath == filepath.Join("crd", "customkind.customapp.ext.grafana.com.json")
+	}), "CRD should be compared")
+	assert.True(t, slices.ContainsFunc(compare, func(f codejen.File) bool {
+		return f.RelativePath == filepath.Join("manifest", "custom-app-manifest.json")
+	}), "manifest should be compared")
+	AssertGolden(t, compare, "../testing/golden_generated", ".txt")
+}
+
+func TestAssertGolden(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, "foo"), 0755))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "foo", "bar.go.txt"), []byte("bar"), 0600))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "unused.txt"), []byte("unused"), 0600))
+
+	t.Run("match", func(t *testing.T) {
+		rec := &recorder{TB: t}
+		AssertGolden(rec, codejen.Files{{RelativePath: "foo/bar.go", Data: []byte("bar")}}, dir, ".txt")
+		assert.Empty(t, rec.errors)
+	})
+
+	t.Run("mismatch and missing", func(t *testing.T) {
+		rec := &recorder{TB: t}
+		AssertGolden(rec, codejen.Files{
+			{RelativePath: "foo/bar.go", Data: []byte("baz")},
+			{RelativePath: "foo/new.go", Data: []byte("new")},
+		}, dir, ".txt")
+		require.Len(t, rec.errors, 2)
+		assert.Contains(t, rec.errors[0], "generated file foo/bar.go differs from golden file "+filepath.Join(dir, "foo", "bar.go.txt"))
+		assert.Equal(t, fmt.Sprintf("golden file %s does not exist for generated file foo/new.go; run the test with UPDATE_GOLDEN set to create it",
+			filepath.Join(dir, "foo", "new.go.txt")), rec.errors[1])
+	})
+
+	t.Run("update", func(t *testing.T) {
+		t.Setenv(UpdateEnvVar, "1")
+		rec := &recorder{TB: t}
+		AssertGolden(rec, codejen.Files{
+			{RelativePath: "foo/bar.go", Data: []byte("baz")},
+			{RelativePath: "foo/new/new.go", Data: []byte("new")},
+		}, dir, ".txt")
+		assert.Empty(t, rec.errors)
+		contents, err := os.ReadFile(filepath.Join(dir, "foo", "bar.go.txt"))
+		require.Nil(t, err)
+		assert.Equal(t, "baz", string(contents))
+		contents, err = os.ReadFile(filepath.Join(dir, "foo", "new", "new.go.txt"))
+		require.Nil(t, err)
+		assert.Equal(t, "new", string(contents))
+		// Golden files without a generated file are left alone
+		contents, err = os.ReadFile(filepath.Join(dir, "unused.txt"))
+		require.Nil(t, err)
+		assert.Equal(t, "unused", string(contents))
+	})
+}
+
+// recorder is a testing.TB which records errors instead of failing the test
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
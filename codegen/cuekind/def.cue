@@ -70,6 +70,47 @@ Schema: {
 	_specIsNonEmpty: spec & struct.MinFields(0)
 }
 
+// ProgressStatus is unified with the schema of each version of a kind which has reportsProgress set.
+// percent is bounded by >-1 rather than >=0, as CUE simplifies a non-negative int to uint, which generates an unsigned go type.
+ProgressStatus: {
+	status: {
+		// progress is the progress of the latest long-running reconcile of the object
+		progress?: {
+			// phase is a short, machine-readable description of the current stage of the reconcile
+			phase: string
+			// percent is the completion percentage of the reconcile
+			percent: int & >-1 & <=100
+			// message is an optional human-readable message describing the current progress
+			message?: string
+			// lastUpdateTime is the time the progress was last reported
+			lastUpdateTime: string & time.Time
+		}
+	}
+}
+
+// ConditionsStatus is unified with the schema of each version of a kind which has reportsConditions set.
+// Its conditions are compatible with metav1.Condition, and can be managed with the resource/conditions package.
+ConditionsStatus: {
+	status: {
+		#Condition: {
+			// type is the type of the condition, in CamelCase
+			type: string
+			// status is the status of the condition
+			status: "True" | "False" | "Unknown"
+			// observedGeneration is the metadata.generation of the object when the condition was set
+			observedGeneration?: int64 & >=0
+			// lastTransitionTime is the last time the status of the condition changed
+			lastTransitionTime: string & time.Time
+			// reason is a machine-readable, CamelCase reason for the last transition of the condition
+			reason: string
+			// message is a human-readable message with details about the last transition of the condition
+			message: string
+		}
+		// conditions are the current observations of the state of the object, such as whether it is "Ready"
+		conditions?: [...#Condition]
+	}
+}
+
 #AdmissionCapability: {
 	operations: [...string]
 }
@@ -95,6 +136,38 @@ Schema: {
 	jsonPath: string
 }
 
+#SizeLimits: {
+	// maxBytes is the maximum size of an object, in bytes of its JSON encoding
+	maxBytes?: int & >0
+	// maxItems maps dot-separated paths (such as "spec.targets") to the maximum number of items of the array
+	// (or keys of the object) at the path. A "[*]" suffix on a part of the path selects every item of an array,
+	// such as "spec.groups[*].members".
+	maxItems?: [string]: int & >=0
+	// maxLength maps dot-separated paths to the maximum length, in characters, of the string at the path
+	maxLength?: [string]: int & >=0
+}
+
+#Scale: {
+	// specReplicasPath is the JSON path to the desired number of replicas in the spec, such as ".spec.replicas"
+	specReplicasPath: string
+	// statusReplicasPath is the JSON path to the observed number of replicas in the status, such as ".status.replicas"
+	statusReplicasPath: string
+	// labelSelectorPath is the JSON path to the serialized label selector of the replicas in the spec or status,
+	// such as ".status.selector". It is required for the HorizontalPodAutoscaler to scale objects of the kind.
+	labelSelectorPath?: string
+}
+
+// #CustomRoute is a custom route of a kind version. `request` and `response` are the schemas of the JSON request
+// and response bodies of the route. If either is absent, that body is untyped.
+// (They are not commented individually, as comments would become part of each route's schemas.)
+#CustomRoute: {
+	// name is the name of the route, which its generated go types and handler are named after, such as "Rollback".
+	// It defaults to the method and path, such as "PostRollback" for a POST on the "rollback" path.
+	name?: =~"^[A-Z][a-zA-Z0-9]*$"
+	request?: _
+	response?: _
+}
+
 // Kind represents an arbitrary kind which can be used for code generation
 Kind: S={
 	kind: =~"^([A-Z][a-zA-Z0-9-]{0,61}[a-zA-Z0-9])$"
@@ -114,6 +187,11 @@ Kind: S={
 	mutation: #AdmissionCapability | *{
 		operations: []
 	}
+	// defaulting determines whether there is code-based defaulting for this kind, which sets default values
+	// on objects as part of mutating admission, before mutation.
+	defaulting: #AdmissionCapability | *{
+		operations: []
+	}
 	// conversion determines whether there is code-based conversion for this kind.
 	conversion: bool | *false
 	// conversionWebhookProps is a temporary way of specifying the service webhook information
@@ -121,6 +199,32 @@ Kind: S={
 	conversionWebhookProps: {
 		url: string | *""
 	}
+	// configKind indicates that this kind is a singleton which stores the app's runtime configuration.
+	// Config kinds must be cluster-scoped, and only one instance of a config kind may exist.
+	// At most one kind in an app may be a config kind.
+	configKind: bool | *false
+	if configKind {
+		scope: "Cluster"
+	}
+	// reportsProgress indicates that the operator for this kind reports reconcile progress into status.progress.
+	// When true, the progress block is added to the status of each version, along with "Phase" and "Progress" printer columns.
+	reportsProgress: bool | *false
+	// reportsConditions indicates that the operator for this kind reports standard conditions (such as "Ready") into status.conditions.
+	// When true, the conditions list is added to the status of each version.
+	reportsConditions: bool | *false
+	// shortNames is a list of short names for the kind, which can be used in place of the plural name by clients such as kubectl
+	shortNames?: [...=~"^([a-z][a-z0-9]*)$"]
+	// categories is a list of grouped resources this kind belongs to (such as "all"), which can be used by clients such as kubectl
+	categories?: [...=~"^([a-z][a-z0-9-]*)$"]
+	// maturity is the maturity level of the kind, for display in catalogs of the kinds an app exposes
+	maturity?: "alpha" | "beta" | "stable"
+	// owner is the team which owns the kind
+	owner?: =~"^[^\n]+$"
+	// documentationURL is a link to the documentation of the kind
+	documentationURL?: =~"^https?://.+"
+	// sizeLimits are limits on the size of objects of this kind, which are enforced in validating admission.
+	// At the root level of the kind, it sets the default for the `sizeLimits` field of all entries in `versions`.
+	sizeLimits: #SizeLimits | *{}
 	versions: {
 		[V=string]: {
 			// Version must be the key in the map, but is pulled into the value of the map for ease-of-access when dealing with the resulting value
@@ -141,8 +245,18 @@ Kind: S={
 			selectableFields: [...string]
 			validation: #AdmissionCapability | *S.validation
 			mutation: #AdmissionCapability | *S.mutation
+			defaulting: #AdmissionCapability | *S.defaulting
 			// additionalPrinterColumns is a list of additional columns to be printed in kubectl output
 			additionalPrinterColumns?: [...#AdditionalPrinterColumns]
+			// sizeLimits are limits on the size of objects of this version, which are enforced in validating admission
+			sizeLimits: #SizeLimits | *S.sizeLimits
+			// scale enables the scale subresource for this version, which allows autoscalers such as the HorizontalPodAutoscaler
+			// to scale objects of the kind
+			scale?: #Scale
+			// customRoutes are custom routes served at subresource paths of objects of this version,
+			// as a map of subresource path to HTTP method to route. Go request and response types and a typed handler
+			// are generated for each route, and the request and response schemas are added to the manifest.
+			customRoutes?: [string]: [=~"^(GET|POST|PUT|PATCH|DELETE)$"]: #CustomRoute
 		}
 	}
 	machineName: strings.ToLower(strings.Replace(S.kind, "-", "_", -1))
@@ -165,6 +279,8 @@ Kind: S={
 	group: string
 	resource: string
 	actions: [...string]
+	// required indicates that the app depends on this kind, and should not start until it is served by the API server
+	required: bool | *false
 }
 
 Manifest: S={
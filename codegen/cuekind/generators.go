@@ -1,6 +1,7 @@
 package cuekind
 
 import (
+	"io/fs"
 	"strings"
 
 	"github.com/grafana/codejen"
@@ -45,10 +46,27 @@ func ResourceGenerator(groupKinds bool) *codejen.JennyList[codegen.Kind] {
 		&jennies.Constants{
 			GroupByKind: !groupKinds,
 		},
+		&jennies.FormDescriptorGenerator{
+			GroupByKind: !groupKinds,
+		},
+		&jennies.CustomRouteGenerator{
+			GroupByKind: !groupKinds,
+		},
 	)
 	return g
 }
 
+// ClientsetGenerator returns a collection of jennies which generate a typed client for each kind version,
+// and a Clientset for each generated package. If `groupKinds` is true, kinds within the same group
+// will exist in the same package, and each group version's Clientset contains all of its kinds.
+func ClientsetGenerator(groupKinds bool) *codejen.JennyList[codegen.Kind] {
+	g := codejen.JennyListWithNamer(namerFunc)
+	g.Append(&jennies.ClientsetGenerator{
+		GroupByKind: !groupKinds,
+	})
+	return g
+}
+
 // BackendPluginGenerator returns a Generator which will produce boilerplate backend plugin code
 func BackendPluginGenerator(projectRepo, generatedAPIPath string, groupKinds bool) *codejen.JennyList[codegen.Kind] {
 	pluginSecurePkgFiles, _ := templates.GetBackendPluginSecurePackageFiles()
@@ -79,7 +97,33 @@ func TypeScriptResourceGenerator() *codejen.JennyList[codegen.Kind] {
 	g := codejen.JennyListWithNamer(namerFunc)
 	g.Append(&jennies.TypeScriptTypes{
 		Depth: 1,
-	}, &jennies.TypeScriptResourceTypes{})
+	}, &jennies.TypeScriptResourceTypes{}, &jennies.TypeScriptFormDescriptors{})
+	return g
+}
+
+// TypeScriptZodGenerator returns a Generator which generates zod schemas for validating each kind version in TypeScript.
+func TypeScriptZodGenerator() *codejen.JennyList[codegen.Kind] {
+	g := codejen.JennyListWithNamer(namerFunc)
+	g.Append(&jennies.TypeScriptZodValidators{})
+	return g
+}
+
+// PythonGenerator returns a Generator which generates python dataclass models for each kind version,
+// and a requests-based client for the kinds.
+func PythonGenerator() *codejen.JennyList[codegen.Kind] {
+	g := codejen.JennyListWithNamer(namerFunc)
+	g.Append(&jennies.PythonModels{}, &jennies.PythonPackage{})
+	return g
+}
+
+// ProtobufGenerator returns a Generator which will produce a proto3 file of messages for each kind version.
+// previous contains the previously generated files, whose field numbers are kept (see jennies.ProtobufGenerator.Previous),
+// and may be nil.
+func ProtobufGenerator(previous fs.FS) *codejen.JennyList[codegen.Kind] {
+	g := codejen.JennyListWithNamer(namerFunc)
+	g.Append(&jennies.ProtobufGenerator{
+		Previous: previous,
+	})
 	return g
 }
 
@@ -140,6 +184,74 @@ func ManifestGoGenerator(pkg string) *codejen.JennyList[codegen.AppManifest] {
 	return g
 }
 
+// DashboardGenerator returns a Generator which will create a Grafana dashboard JSON file for observing the app's operator.
+// metricsNamespace is the prometheus namespace the operator's metrics are exposed with,
+// and selectorLabel is the prometheus label used to select the app's metrics (defaulting to "job" if empty).
+func DashboardGenerator(metricsNamespace, selectorLabel string) *codejen.JennyList[codegen.AppManifest] {
+	g := codejen.JennyListWithNamer[codegen.AppManifest](namerFuncManifest)
+	g.Append(&jennies.DashboardGenerator{
+		MetricsNamespace: metricsNamespace,
+		SelectorLabel:    selectorLabel,
+	})
+	return g
+}
+
+// AlertRulesGenerator returns a Generator which will create a PrometheusRule file with alert rules for common
+// operator failure modes. metricsNamespace and selectorLabel are used in the same way as in DashboardGenerator.
+func AlertRulesGenerator(encoder jennies.ManifestOutputEncoder, extension string, metricsNamespace, selectorLabel string, thresholds jennies.AlertThresholds) *codejen.JennyList[codegen.AppManifest] {
+	g := codejen.JennyListWithNamer[codegen.AppManifest](namerFuncManifest)
+	g.Append(&jennies.AlertRulesGenerator{
+		Encoder:          encoder,
+		FileExtension:    extension,
+		MetricsNamespace: metricsNamespace,
+		SelectorLabel:    selectorLabel,
+		Thresholds:       thresholds,
+	})
+	return g
+}
+
+// RBACGenerator returns a Generator which will create a kubernetes List of the RBAC objects for the app's operator,
+// granting the ServiceAccount serviceAccount in namespace access to the app's kinds, and to the kinds in the manifest's
+// extra permissions. If namespaced is true, permissions are granted with a Role in namespace where possible,
+// instead of a ClusterRole.
+func RBACGenerator(encoder jennies.ManifestOutputEncoder, extension string, namespace, serviceAccount string, namespaced bool) *codejen.JennyList[codegen.AppManifest] {
+	g := codejen.JennyListWithNamer[codegen.AppManifest](namerFuncManifest)
+	g.Append(&jennies.RBACGenerator{
+		Encoder:        encoder,
+		FileExtension:  extension,
+		Namespace:      namespace,
+		ServiceAccount: serviceAccount,
+		Namespaced:     namespaced,
+	})
+	return g
+}
+
+// WebhookConfigurationGenerator returns a Generator which will create a kubernetes List of the ValidatingWebhookConfiguration
+// and MutatingWebhookConfiguration for the app's operator, with a webhook for each kind version with admission capabilities.
+// client describes how the API server reaches the operator's webhook server.
+func WebhookConfigurationGenerator(encoder jennies.ManifestOutputEncoder, extension string, client jennies.WebhookClientOptions) *codejen.JennyList[codegen.AppManifest] {
+	g := codejen.JennyListWithNamer[codegen.AppManifest](namerFuncManifest)
+	g.Append(&jennies.WebhookConfigurationGenerator{
+		Encoder:       encoder,
+		FileExtension: extension,
+		Client:        client,
+	})
+	return g
+}
+
+// APIServerManifestsGenerator returns a Generator which will create a kubernetes List of the resources for running the app
+// as an aggregated API server: an APIService for each version of the app's group, and the API server's Deployment,
+// Service, ServiceAccount, and (unless options provides a CA bundle or Certificate) cert-manager Issuer and Certificate.
+func APIServerManifestsGenerator(encoder jennies.ManifestOutputEncoder, extension string, options jennies.APIServerOptions) *codejen.JennyList[codegen.AppManifest] {
+	g := codejen.JennyListWithNamer[codegen.AppManifest](namerFuncManifest)
+	g.Append(&jennies.APIServerManifestsGenerator{
+		Encoder:       encoder,
+		FileExtension: extension,
+		Options:       options,
+	})
+	return g
+}
+
 func namerFunc(k codegen.Kind) string {
 	if k == nil {
 		return "nil"
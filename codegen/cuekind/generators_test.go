@@ -5,11 +5,15 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/grafana/codejen"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana-app-sdk/codegen"
+	"github.com/grafana/grafana-app-sdk/codegen/jennies"
 )
 
 const (
@@ -60,8 +64,8 @@ func TestResourceGenerator(t *testing.T) {
 		files, err := ResourceGenerator(false).Generate(kinds...)
 		require.Nil(t, err)
 		// Check number of files generated
-		// 14 (7 -> object, spec, metadata, status, schema, codec, constants) * 2 versions
-		assert.Len(t, files, 14, "should be 14 files generated, got %d", len(files))
+		// 16 (8 -> object, spec, metadata, status, schema, codec, constants, form) * 2 versions
+		assert.Len(t, files, 16, "should be 16 files generated, got %d", len(files))
 		// Check content against the golden files
 		compareToGolden(t, files, "go/groupbykind")
 	})
@@ -70,8 +74,8 @@ func TestResourceGenerator(t *testing.T) {
 		files, err := ResourceGenerator(true).Generate(kinds...)
 		require.Nil(t, err)
 		// Check number of files generated
-		// 14 (7 -> object, spec, metadata, status, schema, codec, constants) * 2 versions
-		assert.Len(t, files, 14, "should be 14 files generated, got %d", len(files))
+		// 16 (8 -> object, spec, metadata, status, schema, codec, constants, form) * 2 versions
+		assert.Len(t, files, 16, "should be 16 files generated, got %d", len(files))
 		// Check content against the golden files
 		compareToGolden(t, files, "go/groupbygroup")
 	})
@@ -80,12 +84,38 @@ func TestResourceGenerator(t *testing.T) {
 		files, err := ResourceGenerator(true).Generate(sameGroupKinds...)
 		require.Nil(t, err)
 		// Check number of files generated
-		assert.Len(t, files, 20, "should be 20 files generated, got %d", len(files))
+		// 23, plus 4 (routes, and 3 request and response types) for the custom routes of TestKind v2
+		assert.Len(t, files, 27, "should be 27 files generated, got %d", len(files))
 		// Check content against the golden files
 		compareToGolden(t, files, "go/groupbygroup")
 	})
 }
 
+func TestClientsetGenerator(t *testing.T) {
+	parser, err := NewParser()
+	require.Nil(t, err)
+	kinds, err := parser.KindParser(true).Parse(os.DirFS(TestCUEDirectory), "customManifest")
+	require.Nil(t, err)
+	sameGroupKinds, err := parser.KindParser(true).Parse(os.DirFS(TestCUEDirectory), "testManifest")
+	require.Nil(t, err)
+
+	t.Run("group by kind", func(t *testing.T) {
+		files, err := ClientsetGenerator(false).Generate(kinds...)
+		require.Nil(t, err)
+		// 2 (client, clientset) * 2 versions
+		assert.Len(t, files, 4, "should be 4 files generated, got %d", len(files))
+		compareToGolden(t, files, "go/groupbykind")
+	})
+
+	t.Run("group by group, multiple kinds", func(t *testing.T) {
+		files, err := ClientsetGenerator(true).Generate(sameGroupKinds...)
+		require.Nil(t, err)
+		// 3 kind version clients, and a clientset for each of the 2 versions
+		assert.Len(t, files, 5, "should be 5 files generated, got %d", len(files))
+		compareToGolden(t, files, "go/groupbygroup")
+	})
+}
+
 func TestTypeScriptResourceGenerator(t *testing.T) {
 	// Ideally, we test only that this outputs the right jennies,
 	// but right now we just test the whole pipeline from thema -> written files
@@ -99,12 +129,88 @@ func TestTypeScriptResourceGenerator(t *testing.T) {
 		files, err := TypeScriptResourceGenerator().Generate(kinds...)
 		require.Nil(t, err)
 		// Check number of files generated
-		assert.Len(t, files, 8)
+		assert.Len(t, files, 10)
 		// Check content against the golden files
 		compareToGolden(t, files, "typescript/versioned")
 	})
 }
 
+func TestTypeScriptZodGenerator(t *testing.T) {
+	parser, err := NewParser()
+	require.Nil(t, err)
+
+	kinds, err := parser.KindParser(true).Parse(os.DirFS(TestCUEDirectory), "customManifest")
+	require.Nil(t, err)
+	files, err := TypeScriptZodGenerator().Generate(kinds...)
+	require.Nil(t, err)
+	// One file per version
+	assert.Len(t, files, 2)
+	compareToGolden(t, files, "typescript/versioned")
+}
+
+func TestPythonGenerator(t *testing.T) {
+	parser, err := NewParser()
+	require.Nil(t, err)
+
+	kinds, err := parser.KindParser(true).Parse(os.DirFS(TestCUEDirectory), "customManifest")
+	require.Nil(t, err)
+	files, err := PythonGenerator().Generate(kinds...)
+	require.Nil(t, err)
+	// Check number of files generated
+	// 2 (models, __init__) * 2 versions, the kind's __init__, and the package _base, client, and __init__
+	assert.Len(t, files, 8)
+	// Check content against the golden files
+	compareToGolden(t, files, "python")
+}
+
+func TestProtobufGenerator(t *testing.T) {
+	parser, err := NewParser()
+	require.Nil(t, err)
+
+	kinds, err := parser.KindParser(true).Parse(os.DirFS(TestCUEDirectory), "customManifest")
+	require.Nil(t, err)
+	files, err := ProtobufGenerator(nil).Generate(kinds...)
+	require.Nil(t, err)
+	// Check number of files generated
+	// 1 per version
+	assert.Len(t, files, 2)
+	// Check content against the golden files
+	compareToGolden(t, files, "protobuf")
+
+	t.Run("keeps previous field numbers", func(t *testing.T) {
+		previous := fstest.MapFS{
+			"customkind/v0_0/customkind.proto": &fstest.MapFile{
+				Data: []byte(`syntax = "proto3";
+
+package com.grafana.ext.customapp.v0_0;
+
+message CustomKindSpec {
+  reserved 5;
+  string removed_field = 1;
+  string deprecated_field = 3;
+}
+
+message CustomKindStatus {
+  message OperatorStatesValue {
+    string state = 1;
+  }
+}
+`),
+			},
+		}
+		files, err := ProtobufGenerator(previous).Generate(kinds...)
+		require.Nil(t, err)
+		require.Len(t, files, 2)
+		contents := string(files[0].Data)
+		assert.Contains(t, contents, "message CustomKindSpec {\n  reserved 1, 5;\n  string field1 = 2;\n  string deprecated_field = 3;\n}")
+		assert.Contains(t, contents, "    string last_evaluation = 2;\n")
+		assert.Contains(t, contents, "    string state = 1;\n")
+		assert.Contains(t, contents, "    optional string descriptive_state = 3;\n")
+		// Versions without a previous file are numbered in declaration order
+		compareToGolden(t, files[1:], "protobuf")
+	})
+}
+
 func TestManifestGenerator(t *testing.T) {
 	parser, err := NewParser()
 	require.Nil(t, err)
@@ -115,8 +221,8 @@ func TestManifestGenerator(t *testing.T) {
 		files, err := ManifestGenerator(yaml.Marshal, "yaml").Generate(kinds...)
 		require.Nil(t, err)
 		// Check number of files generated
-		// 5 -> object, spec, metadata, status, schema
-		assert.Len(t, files, 1)
+		// 2 -> manifest, custom routes OpenAPI
+		assert.Len(t, files, 2)
 		// Check content against the golden files
 		compareToGolden(t, files, "manifest")
 	})
@@ -139,6 +245,104 @@ func TestManifestGoGenerator(t *testing.T) {
 	})
 }
 
+func TestDashboardGenerator(t *testing.T) {
+	parser, err := NewParser()
+	require.Nil(t, err)
+
+	manifests, err := parser.ManifestParser().Parse(os.DirFS(TestCUEDirectory), "testManifest")
+	require.Nil(t, err)
+	files, err := DashboardGenerator("", "").Generate(manifests...)
+	require.Nil(t, err)
+	assert.Len(t, files, 1)
+	compareToGolden(t, files, "dashboard")
+}
+
+func TestAlertRulesGenerator(t *testing.T) {
+	parser, err := NewParser()
+	require.Nil(t, err)
+
+	manifests, err := parser.ManifestParser().Parse(os.DirFS(TestCUEDirectory), "testManifest")
+	require.Nil(t, err)
+	files, err := AlertRulesGenerator(yaml.Marshal, "yaml", "", "", jennies.AlertThresholds{}).Generate(manifests...)
+	require.Nil(t, err)
+	assert.Len(t, files, 1)
+	compareToGolden(t, files, "alerts")
+}
+
+func TestRBACGenerator(t *testing.T) {
+	parser, err := NewParser()
+	require.Nil(t, err)
+
+	manifests, err := parser.ManifestParser().Parse(os.DirFS(TestCUEDirectory), "testManifest")
+	require.Nil(t, err)
+	files, err := RBACGenerator(yaml.Marshal, "yaml", "", "", false).Generate(manifests...)
+	require.Nil(t, err)
+	assert.Len(t, files, 1)
+	compareToGolden(t, files, "rbac")
+}
+
+func TestWebhookConfigurationGenerator(t *testing.T) {
+	parser, err := NewParser()
+	require.Nil(t, err)
+
+	manifests, err := parser.ManifestParser().Parse(os.DirFS(TestCUEDirectory), "testManifest")
+	require.Nil(t, err)
+	files, err := WebhookConfigurationGenerator(yaml.Marshal, "yaml", jennies.WebhookClientOptions{}).Generate(manifests...)
+	require.Nil(t, err)
+	assert.Len(t, files, 1)
+	compareToGolden(t, files, "webhooks")
+}
+
+func TestAPIServerManifestsGenerator(t *testing.T) {
+	parser, err := NewParser()
+	require.Nil(t, err)
+
+	manifests, err := parser.ManifestParser().Parse(os.DirFS(TestCUEDirectory), "testManifest")
+	require.Nil(t, err)
+	files, err := APIServerManifestsGenerator(yaml.Marshal, "yaml", jennies.APIServerOptions{}).Generate(manifests...)
+	require.Nil(t, err)
+	assert.Len(t, files, 1)
+	compareToGolden(t, files, "apiserver")
+
+	t.Run("version priority", func(t *testing.T) {
+		// Versions are prioritized by their stability, not their order in the manifest
+		names := []string{"v2", "v1beta1", "v1", "v1alpha1"}
+		kinds := make([]codegen.Kind, 0)
+		for _, k := range manifests[0].Kinds() {
+			versions := make([]codegen.KindVersion, 0, len(names))
+			for _, name := range names {
+				v := k.Versions()[0]
+				v.Version = name
+				versions = append(versions, v)
+			}
+			kinds = append(kinds, &codegen.AnyKind{Props: k.Properties(), AllVersions: versions})
+		}
+		files, err := APIServerManifestsGenerator(json.Marshal, "json", jennies.APIServerOptions{}).Generate(&codegen.SimpleManifest{
+			Props:    manifests[0].Properties(),
+			AllKinds: kinds,
+		})
+		require.Nil(t, err)
+		require.Len(t, files, 1)
+		list := struct {
+			Items []struct {
+				Kind string `json:"kind"`
+				Spec struct {
+					Version         string `json:"version"`
+					VersionPriority int    `json:"versionPriority"`
+				} `json:"spec"`
+			} `json:"items"`
+		}{}
+		require.Nil(t, json.Unmarshal(files[0].Data, &list))
+		priorities := make(map[string]int)
+		for _, item := range list.Items {
+			if item.Kind == "APIService" {
+				priorities[item.Spec.Version] = item.Spec.VersionPriority
+			}
+		}
+		assert.Equal(t, map[string]int{"v1alpha1": 10, "v1beta1": 20, "v1": 30, "v2": 40}, priorities)
+	})
+}
+
 func compareToGolden(t *testing.T, files codejen.Files, pathPrefix string) {
 	for _, f := range files {
 		// Check if there's a golden generated file to compare against
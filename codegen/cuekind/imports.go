@@ -0,0 +1,336 @@
+package cuekind
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ImportsFile is the path, relative to the CUE module root, of the file which declares shared schema imports
+	ImportsFile = "cue.mod/imports.yaml"
+	// ImportsLockFile is the path, relative to the CUE module root, of the file which records the vendored
+	// version and content checksum of each shared schema import
+	ImportsLockFile = "cue.mod/imports.lock"
+	// vendorDir is the directory, relative to the CUE module root, where CUE resolves non-local imports from
+	vendorDir = "cue.mod/pkg"
+)
+
+// SchemaImport is a shared CUE schema package used by the kinds in a CUE module.
+// Once vendored, its packages are imported by their Module path, for example
+// `import "github.com/example/schemas/common"` for a Module of "github.com/example/schemas"
+// containing a "common" directory.
+type SchemaImport struct {
+	// Module is the CUE import path prefix of the vendored packages
+	Module string `yaml:"module"`
+	// Version is the pinned version. For git sources, this is the tag, branch, or commit to check out,
+	// and the commit it resolves to is recorded in the lock file, so that a branch is not followed once vendored.
+	// For local sources, it is recorded in the lock file, but not checked.
+	Version string `yaml:"version"`
+	// Source is either a git repository URL, or a local directory path (relative to the CUE module root)
+	Source string `yaml:"source"`
+	// Path is an optional subdirectory of Source which contains the CUE packages
+	Path string `yaml:"path,omitempty"`
+}
+
+// SchemaImports is the contents of an ImportsFile
+type SchemaImports struct {
+	Imports []SchemaImport `yaml:"imports"`
+}
+
+// SchemaImportLock is the locked state of a single vendored SchemaImport
+type SchemaImportLock struct {
+	Module  string `yaml:"module"`
+	Version string `yaml:"version"`
+	Source  string `yaml:"source"`
+	Path    string `yaml:"path,omitempty"`
+	// Commit is the git commit Version resolved to when the import was vendored. It is empty for local sources.
+	Commit string `yaml:"commit,omitempty"`
+	// Sum is the sha256 checksum of the vendored files
+	Sum string `yaml:"sum"`
+}
+
+// SchemaImportsLock is the contents of an ImportsLockFile
+type SchemaImportsLock struct {
+	Imports []SchemaImportLock `yaml:"imports"`
+}
+
+// LoadSchemaImports loads the ImportsFile from the CUE module at moduleRoot.
+// If the module has no ImportsFile, it returns nil and no error.
+func LoadSchemaImports(moduleRoot string) (*SchemaImports, error) {
+	contents, err := os.ReadFile(filepath.Join(moduleRoot, ImportsFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	imports := &SchemaImports{}
+	if err = yaml.Unmarshal(contents, imports); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", ImportsFile, err)
+	}
+	seen := make(map[string]struct{}, len(imports.Imports))
+	for _, imp := range imports.Imports {
+		if imp.Module == "" || imp.Source == "" || imp.Version == "" {
+			return nil, fmt.Errorf("%s: each import must have a module, version, and source", ImportsFile)
+		}
+		if _, ok := seen[imp.Module]; ok {
+			return nil, fmt.Errorf("%s: module '%s' is imported more than once", ImportsFile, imp.Module)
+		}
+		if _, err := vendorPath(moduleRoot, imp.Module); err != nil {
+			return nil, fmt.Errorf("%s: %w", ImportsFile, err)
+		}
+		if imp.Path != "" && !isLocalPath(imp.Path) {
+			return nil, fmt.Errorf("%s: path '%s' of module '%s' must be a relative path within the source", ImportsFile, imp.Path, imp.Module)
+		}
+		if strings.HasPrefix(imp.Version, "-") || strings.HasPrefix(imp.Source, "-") {
+			return nil, fmt.Errorf("%s: version and source of module '%s' cannot begin with '-'", ImportsFile, imp.Module)
+		}
+		seen[imp.Module] = struct{}{}
+	}
+	return imports, nil
+}
+
+// VendorSchemaImports copies the CUE files of each import in imports into the vendor directory (cue.mod/pkg)
+// of the CUE module at moduleRoot, where the CUE loader resolves them, and writes the ImportsLockFile.
+// Imports whose version and source match the lock file, and whose vendored files still match the locked checksum,
+// are not fetched again. If the vendored files of a locked git import have changed, the locked commit is fetched again
+// (rather than the commit Version currently resolves to), and its files must match the locked checksum.
+// Previously-vendored imports which are no longer in imports are removed.
+func VendorSchemaImports(moduleRoot string, imports *SchemaImports) error {
+	lock, err := loadSchemaImportsLock(moduleRoot)
+	if err != nil {
+		return err
+	}
+	locked := make(map[string]SchemaImportLock, len(lock.Imports))
+	for _, l := range lock.Imports {
+		locked[l.Module] = l
+	}
+
+	newLock := SchemaImportsLock{
+		Imports: make([]SchemaImportLock, 0, len(imports.Imports)),
+	}
+	for _, imp := range imports.Imports {
+		dest, err := vendorPath(moduleRoot, imp.Module)
+		if err != nil {
+			return err
+		}
+		ref := imp.Version
+		l, ok := locked[imp.Module]
+		pinned := ok && l.Version == imp.Version && l.Source == imp.Source && l.Path == imp.Path
+		if pinned {
+			if sum, err := dirChecksum(dest); err == nil && sum == l.Sum {
+				newLock.Imports = append(newLock.Imports, l)
+				delete(locked, imp.Module)
+				continue
+			}
+			if l.Commit != "" {
+				ref = l.Commit
+			}
+		}
+		commit, sum, err := vendorSchemaImport(moduleRoot, imp, ref, dest)
+		if err != nil {
+			return fmt.Errorf("unable to vendor '%s@%s': %w", imp.Module, imp.Version, err)
+		}
+		if pinned && l.Commit != "" {
+			if commit != l.Commit {
+				return fmt.Errorf("unable to vendor '%s@%s': fetched commit %s, but %s is locked", imp.Module, imp.Version, commit, l.Commit)
+			}
+			if sum != l.Sum {
+				return fmt.Errorf("unable to vendor '%s@%s': checksum %s of commit %s does not match locked checksum %s",
+					imp.Module, imp.Version, sum, commit, l.Sum)
+			}
+		}
+		newLock.Imports = append(newLock.Imports, SchemaImportLock{
+			Module:  imp.Module,
+			Version: imp.Version,
+			Source:  imp.Source,
+			Path:    imp.Path,
+			Commit:  commit,
+			Sum:     sum,
+		})
+		delete(locked, imp.Module)
+	}
+	// Anything left in locked is no longer imported
+	for module := range locked {
+		dest, err := vendorPath(moduleRoot, module)
+		if err != nil {
+			return fmt.Errorf("%s: %w", ImportsLockFile, err)
+		}
+		if err := os.RemoveAll(dest); err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(newLock.Imports, func(i, j int) bool {
+		return newLock.Imports[i].Module < newLock.Imports[j].Module
+	})
+	contents, err := yaml.Marshal(newLock)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(moduleRoot, ImportsLockFile), contents, 0644)
+}
+
+func loadSchemaImportsLock(moduleRoot string) (*SchemaImportsLock, error) {
+	lock := &SchemaImportsLock{}
+	contents, err := os.ReadFile(filepath.Join(moduleRoot, ImportsLockFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return lock, nil
+		}
+		return nil, err
+	}
+	if err = yaml.Unmarshal(contents, lock); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", ImportsLockFile, err)
+	}
+	return lock, nil
+}
+
+// vendorSchemaImport fetches imp at ref and copies its CUE files to dest, returning the commit ref resolved to
+// (for git sources), and the checksum of the vendored files
+func vendorSchemaImport(moduleRoot string, imp SchemaImport, ref string, dest string) (string, string, error) {
+	src := imp.Source
+	commit := ""
+	if isGitSource(imp.Source) {
+		// The source and ref are passed to git as arguments, so they must not be interpreted as options
+		if strings.HasPrefix(imp.Source, "-") || strings.HasPrefix(ref, "-") {
+			return "", "", fmt.Errorf("invalid source '%s' or ref '%s': cannot begin with '-'", imp.Source, ref)
+		}
+		tmp, err := os.MkdirTemp("", "cue-import-")
+		if err != nil {
+			return "", "", err
+		}
+		defer os.RemoveAll(tmp)
+		// Fetching the single ref (rather than cloning with --branch) works for commits as well as tags and branches
+		for _, args := range [][]string{
+			{"init", "--quiet"},
+			{"fetch", "--quiet", "--depth", "1", "--", imp.Source, ref},
+			{"checkout", "--quiet", "FETCH_HEAD"},
+		} {
+			if _, err = runGit(tmp, args...); err != nil {
+				return "", "", err
+			}
+		}
+		if commit, err = runGit(tmp, "rev-parse", "HEAD"); err != nil {
+			return "", "", err
+		}
+		src = tmp
+	} else if !filepath.IsAbs(src) {
+		src = filepath.Join(moduleRoot, src)
+	}
+	src = filepath.Join(src, filepath.FromSlash(imp.Path))
+
+	if err := os.RemoveAll(dest); err != nil {
+		return "", "", err
+	}
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// Don't vendor the source's own module metadata or VCS directories
+			if path != src && (d.Name() == "cue.mod" || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".cue" {
+			return nil
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(target, contents, 0644)
+	})
+	if err != nil {
+		return "", "", err
+	}
+	sum, err := dirChecksum(dest)
+	return commit, sum, err
+}
+
+// runGit runs git with args in dir, returning its trimmed stdout
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// vendorPath returns the directory module is vendored in, within the vendor directory of the CUE module at moduleRoot.
+// It returns an error if module is absolute, or would resolve to a directory outside the vendor directory.
+func vendorPath(moduleRoot, module string) (string, error) {
+	if !isLocalPath(module) {
+		return "", fmt.Errorf("invalid module '%s': must be a relative import path", module)
+	}
+	root := filepath.Join(moduleRoot, vendorDir)
+	dest := filepath.Join(root, filepath.FromSlash(module))
+	if rel, err := filepath.Rel(root, dest); err != nil || rel == "." {
+		return "", fmt.Errorf("invalid module '%s': must be a relative import path", module)
+	}
+	return dest, nil
+}
+
+// isLocalPath returns true if p is a slash-separated relative path which does not escape the directory it is relative to
+func isLocalPath(p string) bool {
+	return !strings.HasPrefix(p, "/") && filepath.IsLocal(filepath.FromSlash(p))
+}
+
+// dirChecksum returns a sha256 checksum of the relative paths and contents of all files in dir
+func dirChecksum(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.ToSlash(rel), len(contents))
+		h.Write(contents)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func isGitSource(source string) bool {
+	return strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://") ||
+		strings.HasPrefix(source, "ssh://") || strings.HasPrefix(source, "git@") || strings.HasSuffix(source, ".git")
+}
@@ -0,0 +1,183 @@
+package cuekind
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/load"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestVendorSchemaImports(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "shared", "schemas", "audit", "audit.cue"),
+		"package audit\n\n#AuditFields: {\n\tcreatedBy: string\n}\n")
+	writeTestFile(t, filepath.Join(root, "shared", "schemas", "README.md"), "not vendored")
+	module := filepath.Join(root, "kinds")
+	writeTestFile(t, filepath.Join(module, "cue.mod", "module.cue"),
+		"module: \"example.grafana.app/kinds\"\nlanguage: version: \"v0.8.2\"\n")
+	writeTestFile(t, filepath.Join(module, ImportsFile),
+		"imports:\n  - module: example.com/schemas\n    version: v1.0.0\n    source: ../shared\n    path: schemas\n")
+	writeTestFile(t, filepath.Join(module, "kind.cue"),
+		"package kinds\n\nimport \"example.com/schemas/audit\"\n\nfoo: audit.#AuditFields & {createdBy: \"me\"}\n")
+
+	imports, err := LoadSchemaImports(module)
+	require.Nil(t, err)
+	require.NotNil(t, imports)
+	require.Nil(t, VendorSchemaImports(module, imports))
+	assert.FileExists(t, filepath.Join(module, vendorDir, "example.com", "schemas", "audit", "audit.cue"))
+	assert.NoFileExists(t, filepath.Join(module, vendorDir, "example.com", "schemas", "README.md"))
+
+	lock, err := loadSchemaImportsLock(module)
+	require.Nil(t, err)
+	require.Len(t, lock.Imports, 1)
+	assert.Equal(t, "v1.0.0", lock.Imports[0].Version)
+	assert.NotEmpty(t, lock.Imports[0].Sum)
+
+	// The vendored package must be resolvable by the loader the parser uses
+	overlay := make(map[string]load.Source)
+	require.Nil(t, ToOverlay("/example.grafana.app/kinds", os.DirFS(module), overlay))
+	inst := load.Instances(nil, &load.Config{
+		Overlay:    overlay,
+		ModuleRoot: filepath.FromSlash("/example.grafana.app/kinds"),
+		Module:     "example.grafana.app/kinds",
+		Dir:        filepath.FromSlash("/example.grafana.app/kinds"),
+	})
+	val := cuecontext.New().BuildInstance(inst[0])
+	require.Nil(t, val.Err())
+	createdBy, err := val.LookupPath(cue.ParsePath("foo.createdBy")).String()
+	require.Nil(t, err)
+	assert.Equal(t, "me", createdBy)
+
+	// Tampered vendored files are re-vendored
+	writeTestFile(t, filepath.Join(module, vendorDir, "example.com", "schemas", "audit", "audit.cue"), "package audit\n")
+	require.Nil(t, VendorSchemaImports(module, imports))
+	contents, err := os.ReadFile(filepath.Join(module, vendorDir, "example.com", "schemas", "audit", "audit.cue"))
+	require.Nil(t, err)
+	assert.Contains(t, string(contents), "#AuditFields")
+
+	// Removed imports are removed from the vendor directory
+	require.Nil(t, VendorSchemaImports(module, &SchemaImports{}))
+	assert.NoDirExists(t, filepath.Join(module, vendorDir, "example.com", "schemas"))
+}
+
+func TestVendorSchemaImports_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	repo := filepath.Join(root, "schemas.git")
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := runGit(repo, append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		require.Nil(t, err)
+		return out
+	}
+	require.Nil(t, os.MkdirAll(repo, 0755))
+	git("init", "--quiet", "--initial-branch", "main")
+	writeTestFile(t, filepath.Join(repo, "audit", "audit.cue"), "package audit\n\n#AuditFields: {\n\tcreatedBy: string\n}\n")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "first")
+	first := git("rev-parse", "HEAD")
+	writeTestFile(t, filepath.Join(repo, "audit", "audit.cue"), "package audit\n\n#AuditFields: {\n\tupdatedBy: string\n}\n")
+	git("commit", "--quiet", "-am", "second")
+	second := git("rev-parse", "HEAD")
+
+	module := filepath.Join(root, "kinds")
+	vendored := filepath.Join(module, vendorDir, "example.com", "schemas", "audit", "audit.cue")
+	readVendored := func() string {
+		t.Helper()
+		contents, err := os.ReadFile(vendored)
+		require.Nil(t, err)
+		return string(contents)
+	}
+
+	// A commit can be used as the version
+	imports := &SchemaImports{Imports: []SchemaImport{{Module: "example.com/schemas", Version: first, Source: repo}}}
+	require.Nil(t, VendorSchemaImports(module, imports))
+	assert.Contains(t, readVendored(), "createdBy")
+	lock, err := loadSchemaImportsLock(module)
+	require.Nil(t, err)
+	require.Len(t, lock.Imports, 1)
+	assert.Equal(t, first, lock.Imports[0].Commit)
+
+	// A branch is resolved to its commit, which stays pinned when the branch moves
+	imports.Imports[0].Version = "main"
+	require.Nil(t, VendorSchemaImports(module, imports))
+	assert.Contains(t, readVendored(), "updatedBy")
+	lock, err = loadSchemaImportsLock(module)
+	require.Nil(t, err)
+	assert.Equal(t, second, lock.Imports[0].Commit)
+	writeTestFile(t, filepath.Join(repo, "audit", "audit.cue"), "package audit\n\n#AuditFields: {\n\tdeletedBy: string\n}\n")
+	git("commit", "--quiet", "-am", "third")
+	require.Nil(t, os.RemoveAll(filepath.Join(module, vendorDir)))
+	require.Nil(t, VendorSchemaImports(module, imports))
+	assert.Contains(t, readVendored(), "updatedBy")
+	lock, err = loadSchemaImportsLock(module)
+	require.Nil(t, err)
+	assert.Equal(t, second, lock.Imports[0].Commit)
+
+	// Re-vendoring the locked commit must match the locked checksum
+	lock.Imports[0].Sum = "sha256:0000"
+	contents, err := yaml.Marshal(lock)
+	require.Nil(t, err)
+	writeTestFile(t, filepath.Join(module, ImportsLockFile), string(contents))
+	err = VendorSchemaImports(module, imports)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "does not match locked checksum")
+
+	// A locked commit which looks like an option is not passed to git
+	lock.Imports[0].Commit = "--upload-pack=false"
+	contents, err = yaml.Marshal(lock)
+	require.Nil(t, err)
+	writeTestFile(t, filepath.Join(module, ImportsLockFile), string(contents))
+	err = VendorSchemaImports(module, imports)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cannot begin with '-'")
+}
+
+func TestLoadSchemaImports(t *testing.T) {
+	module := t.TempDir()
+	imports, err := LoadSchemaImports(module)
+	assert.Nil(t, err)
+	assert.Nil(t, imports)
+
+	writeTestFile(t, filepath.Join(module, ImportsFile), "imports:\n  - module: example.com/schemas\n    source: ../shared\n")
+	_, err = LoadSchemaImports(module)
+	assert.NotNil(t, err)
+
+	for _, invalid := range []string{"/etc", "../../outside", "example.com/../..", "."} {
+		writeTestFile(t, filepath.Join(module, ImportsFile),
+			"imports:\n  - module: "+invalid+"\n    version: v1.0.0\n    source: ../shared\n")
+		_, err = LoadSchemaImports(module)
+		assert.NotNil(t, err, invalid)
+	}
+	writeTestFile(t, filepath.Join(module, ImportsFile),
+		"imports:\n  - module: example.com/schemas\n    version: v1.0.0\n    source: ../shared\n    path: ../..\n")
+	_, err = LoadSchemaImports(module)
+	assert.NotNil(t, err)
+	// Versions and sources are passed to git, so cannot look like options
+	for _, imp := range []string{"version: --upload-pack=touch /tmp/x\n    source: ../shared", "version: v1.0.0\n    source: --upload-pack=x.git"} {
+		writeTestFile(t, filepath.Join(module, ImportsFile), "imports:\n  - module: example.com/schemas\n    "+imp+"\n")
+		_, err = LoadSchemaImports(module)
+		assert.NotNil(t, err, imp)
+	}
+
+	// Modules in the lock file are validated before their vendored directories are removed
+	writeTestFile(t, filepath.Join(module, ImportsLockFile), "imports:\n  - module: ../../outside\n    version: v1.0.0\n    source: ../shared\n")
+	err = VendorSchemaImports(module, &SchemaImports{})
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid module '../../outside'")
+}
+
+func writeTestFile(t *testing.T, path, contents string) {
+	t.Helper()
+	require.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.Nil(t, os.WriteFile(path, []byte(contents), 0644))
+}
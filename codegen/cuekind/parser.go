@@ -27,9 +27,11 @@ func NewParser() (*Parser, error) {
 }
 
 type Parser struct {
-	kindDef     *cue.Value
-	schemaDef   *cue.Value
-	manifestDef *cue.Value
+	kindDef       *cue.Value
+	schemaDef     *cue.Value
+	manifestDef   *cue.Value
+	progressDef   *cue.Value
+	conditionsDef *cue.Value
 }
 
 type parser[T any] struct {
@@ -128,7 +130,12 @@ func (p *Parser) ParseManifest(files fs.FS, manifestSelector string) (codegen.Ap
 	if manifestSelector != "" {
 		val = root.LookupPath(cue.MakePath(cue.Str(manifestSelector)))
 	}
+	return p.ParseManifestValue(val)
+}
 
+// ParseManifestValue parses an already-loaded CUE value as an app manifest, returning the parsed codegen.AppManifest object or an error.
+// It is used by ParseManifest, and by parsers for other source formats which convert their manifests into CUE.
+func (p *Parser) ParseManifestValue(val cue.Value) (codegen.AppManifest, error) {
 	// Load the kind definition (this function does this only once regardless of how many times the user calls Parse())
 	kindDef, schemaDef, manifestDef, err := p.getKindDefinition()
 	if err != nil {
@@ -243,7 +250,7 @@ func (p *Parser) ParseKinds(files fs.FS, selectors ...string) ([]codegen.Kind, e
 	return kinds, nil
 }
 
-func (*Parser) parseKind(val cue.Value, kindDef, schemaDef cue.Value) (codegen.Kind, error) {
+func (p *Parser) parseKind(val cue.Value, kindDef, schemaDef cue.Value) (codegen.Kind, error) {
 	// Start by unifying the provided cue.Value with the cue.Value that contains our Kind definition.
 	// This gives us default values for all fields that weren't filled out,
 	// and will create errors for required fields that may be missing.
@@ -287,6 +294,28 @@ func (*Parser) parseKind(val cue.Value, kindDef, schemaDef cue.Value) (codegen.K
 		if v.Schema.Err() != nil {
 			return nil, v.Schema.Err()
 		}
+		if props.ReportsProgress {
+			v.Schema = v.Schema.Unify(*p.progressDef)
+			if v.Schema.Err() != nil {
+				return nil, v.Schema.Err()
+			}
+			v.AdditionalPrinterColumns = withProgressPrinterColumns(v.AdditionalPrinterColumns)
+		}
+		if props.ReportsConditions {
+			v.Schema = v.Schema.Unify(*p.conditionsDef)
+			if v.Schema.Err() != nil {
+				return nil, v.Schema.Err()
+			}
+		}
+		// Route schemas are left as cue.Values, the same as the version schema
+		for routePath, methods := range v.CustomRoutes {
+			for method, route := range methods {
+				routeVal := val.LookupPath(cue.MakePath(cue.Str("versions"), cue.Str(k), cue.Str("customRoutes"), cue.Str(routePath), cue.Str(method)))
+				route.Request = routeVal.LookupPath(cue.MakePath(cue.Str("request")))
+				route.Response = routeVal.LookupPath(cue.MakePath(cue.Str("response")))
+				methods[method] = route
+			}
+		}
 		someKind.AllVersions = append(someKind.AllVersions, v)
 	}
 	// Now we need to sort AllVersions, as map key order is random
@@ -326,12 +355,44 @@ func (p *Parser) getKindDefinition() (cue.Value, cue.Value, cue.Value, error) {
 	if manifestDef.Err() != nil {
 		return cue.Value{}, cue.Value{}, cue.Value{}, manifestDef.Err()
 	}
+	progressDef := inst.LookupPath(cue.MakePath(cue.Str("ProgressStatus")))
+	if progressDef.Err() != nil {
+		return cue.Value{}, cue.Value{}, cue.Value{}, progressDef.Err()
+	}
+	conditionsDef := inst.LookupPath(cue.MakePath(cue.Str("ConditionsStatus")))
+	if conditionsDef.Err() != nil {
+		return cue.Value{}, cue.Value{}, cue.Value{}, conditionsDef.Err()
+	}
 	p.kindDef = &kindDef
 	p.schemaDef = &schemaDef
 	p.manifestDef = &manifestDef
+	p.progressDef = &progressDef
+	p.conditionsDef = &conditionsDef
 	return *p.kindDef, *p.schemaDef, *p.manifestDef, nil
 }
 
+// withProgressPrinterColumns appends the "Phase" and "Progress" printer columns to columns,
+// unless columns already has a column with the same JSON path.
+func withProgressPrinterColumns(columns []codegen.AdditionalPrinterColumn) []codegen.AdditionalPrinterColumn {
+	progressColumns := []codegen.AdditionalPrinterColumn{{
+		Name:     "Phase",
+		Type:     "string",
+		JSONPath: ".status.progress.phase",
+	}, {
+		Name:     "Progress",
+		Type:     "integer",
+		JSONPath: ".status.progress.percent",
+	}}
+	for _, col := range progressColumns {
+		if !slices.ContainsFunc(columns, func(c codegen.AdditionalPrinterColumn) bool {
+			return c.JSONPath == col.JSONPath
+		}) {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
 func ToOverlay(prefix string, vfs fs.FS, overlay map[string]load.Source) error {
 	// TODO why not just stick the prefix on automatically...?
 	if !filepath.IsAbs(prefix) {
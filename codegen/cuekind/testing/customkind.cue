@@ -23,7 +23,7 @@ customKind: {
 			schema: {
 				#InnerObject1: {
                     innerField1: string
-                    innerField2: [...string]
+                    innerField2: [...string] @cel(rule="self.all(s, s != '')", message="innerField2 must not contain empty strings")
                     innerField3: [...#InnerObject2]
                 }
                 #InnerObject2: {
@@ -44,7 +44,7 @@ customKind: {
                 }
                 #UnionType: #Type1 | #Type2
                 spec: {
-                    field1: string
+                    field1: string @form(title="Field One", widget="textarea", group="General", placeholder="Enter a value")
                     inner: #InnerObject1
                     union: #UnionType
                     map: {
@@ -53,10 +53,10 @@ customKind: {
                     timestamp: string & time.Time
                     enum: "val1" | "val2" | "val3" | "val4" | *"default"
                     i32: int32 & <= 123456
-                    i64: int64 & >= 123456
+                    i64: int64 & >= 123456 @form(group="Advanced", readOnly)
                     boolField: bool | *false
                     floatField: float64
-                }
+                } @cel(rule="self.i32 <= self.i64", message="i32 must not be greater than i64")
                 status: {
                     statusField1: string
                 }
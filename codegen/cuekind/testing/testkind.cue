@@ -10,6 +10,7 @@ testManifest: {
 			group: "foo.bar"
 			resource: "foos"
 			actions: ["get","list","watch"]
+			required: true
 		}]
 	}
 }
@@ -20,6 +21,8 @@ testKind: {
 	validation: operations: ["create","update"]
 	conversion: true
 	conversionWebhookProps: url: "http://foo.bar/convert"
+	shortNames: ["tk"]
+	categories: ["test"]
 	current: "v1"
 	codegen: frontend: false
 	versions: {
@@ -40,6 +43,8 @@ testKind: {
 				}
 			}
 			mutation: operations: ["create","update"]
+			defaulting: operations: ["create","update"]
+			selectableFields: [".spec.stringField", "spec.intField"]
 			additionalPrinterColumns: [
                 {
                     jsonPath: ".spec.stringField"
@@ -47,6 +52,29 @@ testKind: {
                     type: "string"
                 }
             ]
+			customRoutes: {
+				"reconcile": {
+					"POST": {
+						name: "Reconcile"
+						request: {
+							reason: string
+							force?: bool
+						}
+						response: {
+							reconciled: bool
+							message?: string
+						}
+					}
+				}
+				"history": {
+					"GET": {
+						response: {
+							stringFields: [...string]
+							lastUpdated: string & time.Time
+						}
+					}
+				}
+			}
 		}
 	}
-}
\ No newline at end of file
+}
@@ -5,13 +5,32 @@ testKind2: {
 	plural: "testkind2s"
 	current: "v1"
 	codegen: frontend: false
+	reportsProgress: true
+	reportsConditions: true
+	maturity: "beta"
+	owner: "app-platform"
+	documentationURL: "https://grafana.com/docs/testkind2"
+	sizeLimits: {
+		maxBytes: 262144
+		maxLength: "spec.testField": 256
+	}
 	versions: {
 		"v1": {
 			schema: {
 				spec: {
 					testField: string
+					replicas: int32
+				}
+				status: {
+					replicas?: int32
+					selector?: string
 				}
 			}
+			scale: {
+				specReplicasPath: "spec.replicas"
+				statusReplicasPath: ".status.replicas"
+				labelSelectorPath: ".status.selector"
+			}
 		}
 	}
 }
\ No newline at end of file
@@ -0,0 +1,193 @@
+package jennies
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/codejen"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana-app-sdk/codegen"
+)
+
+const (
+	defaultAlertReconcileErrorRatio    = 0.1
+	defaultAlertWebhookErrorRatio      = 0.05
+	defaultAlertRetryQueueSize         = 100
+	defaultAlertTerminalWatchErrors    = 5
+	defaultAlertFor                    = 15 * time.Minute
+	defaultAlertInformerSyncStalledFor = 10 * time.Minute
+)
+
+// AlertThresholds are the thresholds used by the alert rules generated by AlertRulesGenerator.
+// Zero values use the defaults described on each field.
+type AlertThresholds struct {
+	// ReconcileErrorRatio is the ratio of reconciler actions returning an error (per kind) above which an alert fires.
+	// Defaults to 0.1.
+	ReconcileErrorRatio float64
+	// WebhookErrorRatio is the ratio of admission requests the webhook server fails to handle (per kind)
+	// above which an alert fires. Rejected requests are not errors. Defaults to 0.05.
+	WebhookErrorRatio float64
+	// RetryQueueSize is the number of queued watcher and reconciler retries above which the retry queue is considered saturated.
+	// Defaults to 100.
+	RetryQueueSize int
+	// TerminalWatchErrors is the number of terminal list/watch errors for a kind within the For duration above which
+	// the informer is considered to be stuck restarting. Defaults to 5.
+	TerminalWatchErrors int
+	// For is how long a condition must hold before an alert fires. Defaults to 15 minutes.
+	For time.Duration
+	// InformerSyncStalledFor is how long an informer may go without completing its initial sync before an alert fires.
+	// Defaults to 10 minutes.
+	InformerSyncStalledFor time.Duration
+}
+
+// AlertRulesGenerator generates a PrometheusRule with alert rules for common operator failure modes,
+// using the prometheus metrics exposed by the SDK.
+type AlertRulesGenerator struct {
+	Encoder       ManifestOutputEncoder
+	FileExtension string
+	// MetricsNamespace is the prometheus namespace the app's metrics are exposed with
+	// (operator.RunnerMetricsConfig.Namespace). It may be empty.
+	MetricsNamespace string
+	// SelectorLabel is the prometheus label which identifies the app's metrics. Alerts select metrics where
+	// this label is equal to the app name. Defaults to "job".
+	SelectorLabel string
+	// Thresholds are the thresholds used by the alert rules
+	Thresholds AlertThresholds
+}
+
+func (*AlertRulesGenerator) JennyName() string {
+	return "AlertRulesGenerator"
+}
+
+// Generate creates a single PrometheusRule file for the provided AppManifest
+func (a *AlertRulesGenerator) Generate(appManifest codegen.AppManifest) (codejen.Files, error) {
+	appName := appManifest.Properties().AppName
+	if appName == "" {
+		return nil, fmt.Errorf("app manifest must have an app name")
+	}
+	label := a.SelectorLabel
+	if label == "" {
+		label = defaultDashboardSelectorLabel
+	}
+	t := a.thresholds()
+	b := dashboardBuilder{
+		namespace: a.MetricsNamespace,
+		selector:  fmt.Sprintf(`%s="%s"`, label, appName),
+	}
+	forDuration := model.Duration(t.For).String()
+	window := "5m"
+
+	rules := []alertRule{{
+		Alert: "ReconcileErrorRatioHigh",
+		Expr: fmt.Sprintf(`sum by (kind) (rate(%s{%s}[%s])) / sum by (kind) (rate(%s{%s}[%s])) > %g`,
+			b.metric("reconciler", "errors_total"), b.selector, window,
+			b.metric("reconciler", "process_duration_seconds_count"), b.selector, window, t.ReconcileErrorRatio),
+		For: forDuration,
+		Annotations: map[string]string{
+			"summary":     "Reconcile error ratio is high",
+			"description": fmt.Sprintf("More than %g%% of reconciler actions for {{ $labels.kind }} in %s are returning errors.", t.ReconcileErrorRatio*100, appName),
+		},
+	}, {
+		Alert: "InformerSyncStalled",
+		Expr:  fmt.Sprintf(`min by (kind) (%s{%s}) == 0`, b.metric("informer", "synced"), b.selector),
+		For:   model.Duration(t.InformerSyncStalledFor).String(),
+		Annotations: map[string]string{
+			"summary":     "Informer has not synced",
+			"description": fmt.Sprintf("The informer for {{ $labels.kind }} in %s has not completed its initial list for %s.", appName, model.Duration(t.InformerSyncStalledFor)),
+		},
+	}, {
+		Alert: "InformerWatchRestarting",
+		Expr: fmt.Sprintf(`sum by (kind) (increase(%s{%s}[%s])) > %d`,
+			b.metric("informer", "terminal_watch_errors_total"), b.selector, forDuration, t.TerminalWatchErrors),
+		Annotations: map[string]string{
+			"summary":     "Informer is repeatedly restarting its watch",
+			"description": fmt.Sprintf("The informer for {{ $labels.kind }} in %s has restarted its list/watch after more than %d terminal errors in %s.", appName, t.TerminalWatchErrors, forDuration),
+		},
+	}, {
+		Alert: "WebhookErrorRatioHigh",
+		Expr: fmt.Sprintf(`sum by (kind, webhook) (rate(%[1]s{%[3]s}[%[4]s])) / (sum by (kind, webhook) (rate(%[1]s{%[3]s}[%[4]s])) + sum by (kind, webhook) (rate(%[2]s{%[3]s}[%[4]s]))) > %[5]g`,
+			b.metric("webhook", "admission_errors_total"), b.metric("webhook", "admission_requests_total"), b.selector, window, t.WebhookErrorRatio),
+		For: forDuration,
+		Annotations: map[string]string{
+			"summary":     "Admission webhook error ratio is high",
+			"description": fmt.Sprintf("More than %g%% of {{ $labels.webhook }} admission requests for {{ $labels.kind }} in %s are failing.", t.WebhookErrorRatio*100, appName),
+		},
+	}, {
+		Alert: "RetryQueueSaturated",
+		Expr:  fmt.Sprintf(`max(%s{%s}) > %d`, b.metric("informer", "retry_queue_size"), b.selector, t.RetryQueueSize),
+		For:   forDuration,
+		Annotations: map[string]string{
+			"summary":     "Retry queue is saturated",
+			"description": fmt.Sprintf("More than %d watcher and reconciler actions in %s are waiting to be retried.", t.RetryQueueSize, appName),
+		},
+	}}
+	for i := range rules {
+		rules[i].Labels = map[string]string{
+			"app":      appName,
+			"severity": "warning",
+		}
+	}
+
+	output := map[string]any{
+		"apiVersion": "monitoring.coreos.com/v1",
+		"kind":       "PrometheusRule",
+		"metadata": map[string]any{
+			"name": fmt.Sprintf("%s-operator", appName),
+			"labels": map[string]string{
+				"app": appName,
+			},
+		},
+		"spec": map[string]any{
+			"groups": []alertRuleGroup{{
+				Name:  fmt.Sprintf("%s-operator", appName),
+				Rules: rules,
+			}},
+		},
+	}
+	out, err := a.Encoder(output)
+	if err != nil {
+		return nil, err
+	}
+	return codejen.Files{{
+		RelativePath: fmt.Sprintf("%s-alerts.%s", appName, a.FileExtension),
+		Data:         out,
+		From:         []codejen.NamedJenny{a},
+	}}, nil
+}
+
+func (a *AlertRulesGenerator) thresholds() AlertThresholds {
+	t := a.Thresholds
+	if t.ReconcileErrorRatio <= 0 {
+		t.ReconcileErrorRatio = defaultAlertReconcileErrorRatio
+	}
+	if t.WebhookErrorRatio <= 0 {
+		t.WebhookErrorRatio = defaultAlertWebhookErrorRatio
+	}
+	if t.RetryQueueSize <= 0 {
+		t.RetryQueueSize = defaultAlertRetryQueueSize
+	}
+	if t.TerminalWatchErrors <= 0 {
+		t.TerminalWatchErrors = defaultAlertTerminalWatchErrors
+	}
+	if t.For <= 0 {
+		t.For = defaultAlertFor
+	}
+	if t.InformerSyncStalledFor <= 0 {
+		t.InformerSyncStalledFor = defaultAlertInformerSyncStalledFor
+	}
+	return t
+}
+
+type alertRuleGroup struct {
+	Name  string      `json:"name" yaml:"name"`
+	Rules []alertRule `json:"rules" yaml:"rules"`
+}
+
+type alertRule struct {
+	Alert       string            `json:"alert" yaml:"alert"`
+	Expr        string            `json:"expr" yaml:"expr"`
+	For         string            `json:"for,omitempty" yaml:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
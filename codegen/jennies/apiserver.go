@@ -0,0 +1,320 @@
+package jennies
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grafana/codejen"
+	kubeversion "k8s.io/apimachinery/pkg/version"
+
+	"github.com/grafana/grafana-app-sdk/codegen"
+)
+
+const (
+	defaultAPIServerPort                 = 6443
+	defaultAPIServerServicePort          = 443
+	defaultAPIServerGroupPriorityMinimum = 1000
+	apiServerCertificateMountPath        = "/var/run/apiserver/tls"
+	defaultAPIServerEtcdServer           = "http://localhost:2379"
+)
+
+// APIServerOptions describe how an app's aggregated API server is deployed,
+// for the resources created by APIServerManifestsGenerator.
+type APIServerOptions struct {
+	// Namespace is the namespace the API server is deployed in. Defaults to "default".
+	Namespace string
+	// Name is the name of the API server's Deployment, Service, ServiceAccount, and Certificate. Defaults to "<app name>-apiserver".
+	Name string
+	// Image is the container image of the API server. Defaults to "<app name>-apiserver:latest".
+	Image string
+	// Replicas is the number of replicas of the API server's Deployment. Defaults to 1.
+	Replicas int32
+	// Port is the port the API server serves on in its container. Defaults to 6443.
+	Port int32
+	// GroupPriorityMinimum is the priority of the app's group in the API server's discovery. Defaults to 1000.
+	GroupPriorityMinimum int32
+	// CABundle is the PEM-encoded CA bundle the kubernetes API server uses to verify the aggregated API server's certificate.
+	// If set, no cert-manager resources are generated, and a TLS Secret named "<name>-tls" must be created separately.
+	CABundle []byte
+	// CAInjectFrom is the "<namespace>/<certificate>" of an existing cert-manager Certificate, which the cert-manager CA injector
+	// uses to set the CA bundle of the APIServices. If set, no cert-manager resources are generated,
+	// and the Certificate must write its key pair to a Secret named "<name>-tls".
+	CAInjectFrom string
+	// EtcdServers are the URLs of the etcd servers the API server stores its resources in.
+	// Defaults to "http://localhost:2379", for an etcd container added to the API server's pod.
+	EtcdServers []string
+	// EtcdPrefix is the prefix of the API server's keys in etcd. Defaults to "/registry/<group>".
+	EtcdPrefix string
+}
+
+// APIServerManifestsGenerator generates the kubernetes resources for running an app as an aggregated API server:
+// an APIService for each version of the app's group, and a ServiceAccount, Deployment, and Service for the API server.
+// The ServiceAccount is bound to the system:auth-delegator ClusterRole, and to the extension-apiserver-authentication-reader
+// Role in kube-system, so the API server can delegate authentication and authorization of proxied requests.
+// Unless the options provide a CA bundle or an existing Certificate, a self-signed cert-manager Issuer and a Certificate
+// for the Service are also generated, and the CA injector sets the CA bundle of the APIServices from the Certificate.
+// Admission and conversion for the app's kinds are handled by the aggregated API server itself,
+// so no webhook configurations are generated. The resources are written as a single kubernetes List.
+type APIServerManifestsGenerator struct {
+	Encoder       ManifestOutputEncoder
+	FileExtension string
+	Options       APIServerOptions
+}
+
+func (*APIServerManifestsGenerator) JennyName() string {
+	return "APIServerManifestsGenerator"
+}
+
+// Generate creates a single file with the aggregated API server resources for the provided AppManifest
+//
+//nolint:funlen
+func (g *APIServerManifestsGenerator) Generate(appManifest codegen.AppManifest) (codejen.Files, error) {
+	appName := appManifest.Properties().AppName
+	if appName == "" {
+		return nil, fmt.Errorf("app manifest must have an app name")
+	}
+	if len(g.Options.CABundle) > 0 && g.Options.CAInjectFrom != "" {
+		return nil, fmt.Errorf("only one of a CA bundle or a cert-manager certificate to inject the CA from may be provided")
+	}
+	manifestData, err := buildManifestData(appManifest)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifestData.Kinds) == 0 {
+		return nil, fmt.Errorf("app manifest must have at least one kind")
+	}
+	opts := g.Options
+	if opts.Namespace == "" {
+		opts.Namespace = defaultRBACNamespace
+	}
+	if opts.Name == "" {
+		opts.Name = fmt.Sprintf("%s-apiserver", appName)
+	}
+	if opts.Image == "" {
+		opts.Image = fmt.Sprintf("%s-apiserver:latest", appName)
+	}
+	if opts.Replicas == 0 {
+		opts.Replicas = 1
+	}
+	if opts.Port == 0 {
+		opts.Port = defaultAPIServerPort
+	}
+	if opts.GroupPriorityMinimum == 0 {
+		opts.GroupPriorityMinimum = defaultAPIServerGroupPriorityMinimum
+	}
+	if len(opts.EtcdServers) == 0 {
+		opts.EtcdServers = []string{defaultAPIServerEtcdServer}
+	}
+	if opts.EtcdPrefix == "" {
+		opts.EtcdPrefix = fmt.Sprintf("/registry/%s", manifestData.Group)
+	}
+	secretName := fmt.Sprintf("%s-tls", opts.Name)
+	caInjectFrom := opts.CAInjectFrom
+	generateCertificate := len(opts.CABundle) == 0 && caInjectFrom == ""
+	if generateCertificate {
+		caInjectFrom = fmt.Sprintf("%s/%s", opts.Namespace, opts.Name)
+	}
+	labels := map[string]string{
+		"app": appName,
+	}
+	selector := map[string]string{
+		"app":       appName,
+		"component": "apiserver",
+	}
+	metadata := func(name string, namespaced bool) map[string]any {
+		md := map[string]any{
+			"name":   name,
+			"labels": labels,
+		}
+		if namespaced {
+			md["namespace"] = opts.Namespace
+		}
+		return md
+	}
+
+	// Versions are ordered from the least to the most stable (such as v1alpha1, v1beta1, v1, v2), like kubernetes orders
+	// versions in discovery, and more stable versions get a higher priority, so they are preferred
+	versions := make([]string, 0)
+	for _, kind := range manifestData.Kinds {
+		for _, v := range kind.Versions {
+			if !slices.Contains(versions, v.Name) {
+				versions = append(versions, v.Name)
+			}
+		}
+	}
+	slices.SortFunc(versions, kubeversion.CompareKubeAwareVersionStrings)
+	items := make([]map[string]any, 0, len(versions)+7)
+	for i, version := range versions {
+		apiService := map[string]any{
+			"apiVersion": "apiregistration.k8s.io/v1",
+			"kind":       "APIService",
+			"metadata":   metadata(fmt.Sprintf("%s.%s", version, manifestData.Group), false),
+			"spec": map[string]any{
+				"group":                manifestData.Group,
+				"version":              version,
+				"groupPriorityMinimum": opts.GroupPriorityMinimum,
+				"versionPriority":      10 * (i + 1),
+				"service": map[string]any{
+					"name":      opts.Name,
+					"namespace": opts.Namespace,
+					"port":      defaultAPIServerServicePort,
+				},
+			},
+		}
+		if caInjectFrom != "" {
+			apiService["metadata"].(map[string]any)["annotations"] = map[string]string{
+				certManagerInjectCAAnnotation: caInjectFrom,
+			}
+		}
+		if len(opts.CABundle) > 0 {
+			apiService["spec"].(map[string]any)["caBundle"] = base64.StdEncoding.EncodeToString(opts.CABundle)
+		}
+		items = append(items, apiService)
+	}
+	serviceAccountSubjects := []map[string]any{{
+		"kind":      "ServiceAccount",
+		"name":      opts.Name,
+		"namespace": opts.Namespace,
+	}}
+	authReaderMetadata := metadata(fmt.Sprintf("%s-auth-reader", opts.Name), false)
+	authReaderMetadata["namespace"] = "kube-system"
+	args := []string{
+		fmt.Sprintf("--secure-port=%d", opts.Port),
+		fmt.Sprintf("--tls-cert-file=%s/tls.crt", apiServerCertificateMountPath),
+		fmt.Sprintf("--tls-private-key-file=%s/tls.key", apiServerCertificateMountPath),
+		fmt.Sprintf("--etcd-servers=%s", strings.Join(opts.EtcdServers, ",")),
+		fmt.Sprintf("--etcd-prefix=%s", opts.EtcdPrefix),
+	}
+	items = append(items, map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ServiceAccount",
+		"metadata":   metadata(opts.Name, true),
+	}, map[string]any{
+		// Allows the API server to delegate authentication and authorization to the kubernetes API server
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "ClusterRoleBinding",
+		"metadata":   metadata(fmt.Sprintf("%s:system:auth-delegator", opts.Name), false),
+		"roleRef": map[string]any{
+			"apiGroup": "rbac.authorization.k8s.io",
+			"kind":     "ClusterRole",
+			"name":     "system:auth-delegator",
+		},
+		"subjects": serviceAccountSubjects,
+	}, map[string]any{
+		// Allows the API server to read the client CA and request header configuration for authenticating proxied requests
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "RoleBinding",
+		"metadata":   authReaderMetadata,
+		"roleRef": map[string]any{
+			"apiGroup": "rbac.authorization.k8s.io",
+			"kind":     "Role",
+			"name":     "extension-apiserver-authentication-reader",
+		},
+		"subjects": serviceAccountSubjects,
+	}, map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   metadata(opts.Name, true),
+		"spec": map[string]any{
+			"replicas": opts.Replicas,
+			"selector": map[string]any{
+				"matchLabels": selector,
+			},
+			"template": map[string]any{
+				"metadata": map[string]any{
+					"labels": selector,
+				},
+				"spec": map[string]any{
+					"serviceAccountName": opts.Name,
+					"containers": []map[string]any{{
+						"name":  "apiserver",
+						"image": opts.Image,
+						"args":  args,
+						"ports": []map[string]any{{
+							"name":          "https",
+							"containerPort": opts.Port,
+						}},
+						"readinessProbe": map[string]any{
+							"httpGet": map[string]any{
+								"path":   "/readyz",
+								"port":   "https",
+								"scheme": "HTTPS",
+							},
+						},
+						"livenessProbe": map[string]any{
+							"httpGet": map[string]any{
+								"path":   "/livez",
+								"port":   "https",
+								"scheme": "HTTPS",
+							},
+						},
+						"volumeMounts": []map[string]any{{
+							"name":      "tls",
+							"mountPath": apiServerCertificateMountPath,
+							"readOnly":  true,
+						}},
+					}},
+					"volumes": []map[string]any{{
+						"name": "tls",
+						"secret": map[string]any{
+							"secretName": secretName,
+						},
+					}},
+				},
+			},
+		},
+	}, map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   metadata(opts.Name, true),
+		"spec": map[string]any{
+			"selector": selector,
+			"ports": []map[string]any{{
+				"name":       "https",
+				"port":       defaultAPIServerServicePort,
+				"targetPort": "https",
+			}},
+		},
+	})
+	if generateCertificate {
+		items = append(items, map[string]any{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Issuer",
+			"metadata":   metadata(opts.Name, true),
+			"spec": map[string]any{
+				"selfSigned": map[string]any{},
+			},
+		}, map[string]any{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+			"metadata":   metadata(opts.Name, true),
+			"spec": map[string]any{
+				"secretName": secretName,
+				"dnsNames": []string{
+					fmt.Sprintf("%s.%s.svc", opts.Name, opts.Namespace),
+					fmt.Sprintf("%s.%s.svc.cluster.local", opts.Name, opts.Namespace),
+				},
+				"issuerRef": map[string]any{
+					"kind": "Issuer",
+					"name": opts.Name,
+				},
+			},
+		})
+	}
+
+	out, err := g.Encoder(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codejen.Files{{
+		RelativePath: fmt.Sprintf("%s-apiserver.%s", appName, g.FileExtension),
+		Data:         out,
+		From:         []codejen.NamedJenny{g},
+	}}, nil
+}
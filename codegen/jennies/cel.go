@@ -0,0 +1,102 @@
+package jennies
+
+import (
+	"fmt"
+
+	"cuelang.org/go/cue"
+)
+
+// CELAttribute is the CUE attribute used to add a CEL validation rule to a field, which is emitted as an
+// x-kubernetes-validations entry in the field's schema, such as
+// `@cel(rule="self.min <= self.max", message="min must not be greater than max")`.
+// A field may have several @cel attributes. The supported arguments are rule (required), message, messageExpression,
+// reason, fieldPath, and optionalOldSelf, which have the same meaning as in a kubernetes CRD.
+const CELAttribute = "cel"
+
+var celRuleReasons = map[string]bool{
+	"FieldValueInvalid":   true,
+	"FieldValueForbidden": true,
+	"FieldValueRequired":  true,
+	"FieldValueDuplicate": true,
+}
+
+// applyCELValidations adds the rules of the @cel attributes in v to the x-kubernetes-validations of each
+// matching schema in props, recursing into nested properties, array items, and map values.
+func applyCELValidations(props map[string]any, v cue.Value, path string) error {
+	order, values := orderedProperties(props, v)
+	for _, name := range order {
+		schema, ok := props[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		if err := applyCELValidationsToSchema(schema, values[name], path+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyCELValidationsToSchema(schema map[string]any, v cue.Value, path string) error {
+	if !v.Exists() {
+		return nil
+	}
+	rules, err := celValidationRules(v)
+	if err != nil {
+		return fmt.Errorf("invalid @%s attribute for field %s: %w", CELAttribute, path, err)
+	}
+	if len(rules) > 0 {
+		existing, _ := schema["x-kubernetes-validations"].([]any)
+		schema["x-kubernetes-validations"] = append(existing, rules...)
+	}
+	if props, ok := schema["properties"].(map[string]any); ok {
+		if err := applyCELValidations(props, v, path+"."); err != nil {
+			return err
+		}
+	}
+	if items, ok := schema["items"].(map[string]any); ok {
+		if err := applyCELValidationsToSchema(items, lookupFormValue(v, cue.AnyIndex), path+"[]"); err != nil {
+			return err
+		}
+	}
+	if additional, ok := schema["additionalProperties"].(map[string]any); ok {
+		if err := applyCELValidationsToSchema(additional, lookupFormValue(v, cue.AnyString), path+"{}"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// celValidationRules returns the x-kubernetes-validations entries for the @cel attributes of v, in declaration order
+func celValidationRules(v cue.Value) ([]any, error) {
+	rules := make([]any, 0)
+	for _, attr := range v.Attributes(cue.ValueAttr) {
+		if attr.Name() != CELAttribute {
+			continue
+		}
+		if attr.Err() != nil {
+			return nil, attr.Err()
+		}
+		rule := make(map[string]any)
+		for i := 0; i < attr.NumArgs(); i++ {
+			key, value := attr.Arg(i)
+			switch key {
+			case "rule", "message", "messageExpression", "fieldPath":
+				rule[key] = value
+			case "reason":
+				if !celRuleReasons[value] {
+					return nil, fmt.Errorf("unknown reason '%s'", value)
+				}
+				rule[key] = value
+			case "optionalOldSelf":
+				rule[key] = value == "" || value == "true"
+			default:
+				return nil, fmt.Errorf("unknown argument '%s'", key)
+			}
+		}
+		if r, _ := rule["rule"].(string); r == "" {
+			return nil, fmt.Errorf("rule is required")
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
@@ -0,0 +1,110 @@
+package jennies
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"sort"
+
+	"github.com/grafana/codejen"
+
+	"github.com/grafana/grafana-app-sdk/codegen"
+	"github.com/grafana/grafana-app-sdk/codegen/templates"
+)
+
+// ClientsetGenerator is a Jenny which creates a typed client for each kind version (<kind>_client_gen.go),
+// and a Clientset with the typed clients of all kinds in each generated package (clientset_gen.go).
+// Typed clients wrap a resource.Client, such as a *k8s.Client, and accept and return the generated go types of the kind.
+type ClientsetGenerator struct {
+	// GroupByKind determines whether kinds are grouped by GroupVersionKind or just GroupVersion.
+	// If GroupByKind is true, generated paths are <kind>/<version>/<file>, instead of the default <version>/<file>,
+	// and each Clientset contains only a single kind.
+	GroupByKind bool
+}
+
+func (*ClientsetGenerator) JennyName() string {
+	return "ClientsetGenerator"
+}
+
+type clientsetFileParams struct {
+	group   string
+	version string
+	kinds   []templates.ClientMetadata
+}
+
+// Generate creates the typed client files for each kind version, and a clientset file for each generated package
+func (c *ClientsetGenerator) Generate(kinds ...codegen.Kind) (codejen.Files, error) {
+	files := make(codejen.Files, 0)
+	packages := make(map[string]*clientsetFileParams)
+	for _, k := range kinds {
+		prefix := ""
+		if !c.GroupByKind {
+			prefix = exportField(k.Name())
+		}
+		for _, v := range k.Versions() {
+			if !v.Codegen.Backend {
+				continue
+			}
+			md := templates.ClientMetadata{
+				Package:     ToPackageName(v.Version),
+				Kind:        k.Properties().Kind,
+				MachineName: k.Properties().MachineName,
+				PluralName:  exportField(k.Properties().PluralName),
+				FuncPrefix:  prefix,
+			}
+			path := GetGeneratedPath(c.GroupByKind, k, v.Version)
+			b := bytes.Buffer{}
+			if err := templates.WriteClient(md, &b); err != nil {
+				return nil, err
+			}
+			formatted, err := format.Source(b.Bytes())
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, codejen.File{
+				RelativePath: filepath.Join(path, fmt.Sprintf("%s_client_gen.go", k.Properties().MachineName)),
+				From:         []codejen.NamedJenny{c},
+				Data:         formatted,
+			})
+			if _, ok := packages[path]; !ok {
+				packages[path] = &clientsetFileParams{
+					group:   k.Properties().Group,
+					version: v.Version,
+				}
+			}
+			packages[path].kinds = append(packages[path].kinds, md)
+		}
+	}
+	paths := make([]string, 0, len(packages))
+	for path := range packages {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		params := packages[path]
+		sort.Slice(params.kinds, func(i, j int) bool {
+			return params.kinds[i].Kind < params.kinds[j].Kind
+		})
+		b := bytes.Buffer{}
+		err := templates.WriteClientset(templates.ClientsetMetadata{
+			Package: ToPackageName(params.version),
+			Group:   params.group,
+			Version: params.version,
+			Kinds:   params.kinds,
+		}, &b)
+		if err != nil {
+			return nil, err
+		}
+		formatted, err := format.Source(b.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, codejen.File{
+			RelativePath: filepath.Join(path, "clientset_gen.go"),
+			From:         []codejen.NamedJenny{c},
+			Data:         formatted,
+		})
+	}
+	return files, nil
+}
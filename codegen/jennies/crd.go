@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/codejen"
 	goyaml "gopkg.in/yaml.v3"
 
+	"github.com/grafana/grafana-app-sdk/app"
 	"github.com/grafana/grafana-app-sdk/codegen"
 	"github.com/grafana/grafana-app-sdk/k8s"
 )
@@ -40,14 +41,17 @@ func (c *crdGenerator) Generate(kind codegen.Kind) (*codejen.File, error) {
 		APIVersion: "apiextensions.k8s.io/v1",
 		Kind:       "CustomResourceDefinition",
 		Metadata: customResourceDefinitionMetadata{
-			Name: fmt.Sprintf("%s.%s", props.PluralMachineName, props.Group),
+			Name:        fmt.Sprintf("%s.%s", props.PluralMachineName, props.Group),
+			Annotations: kindMetadataAnnotations(props),
 		},
 		Spec: k8s.CustomResourceDefinitionSpec{
 			Group: props.Group,
 			Scope: props.Scope,
 			Names: k8s.CustomResourceDefinitionSpecNames{
-				Kind:   props.Kind,
-				Plural: props.PluralMachineName,
+				Kind:       props.Kind,
+				Plural:     props.PluralMachineName,
+				ShortNames: props.ShortNames,
+				Categories: props.Categories,
 			},
 			Versions: make([]k8s.CustomResourceDefinitionSpecVersion, 0),
 		},
@@ -107,8 +111,8 @@ func KindVersionToCRDSpecVersion(kv codegen.KindVersion, kindName string, stored
 		Subresources: make(map[string]any),
 	}
 	if len(kv.SelectableFields) > 0 {
-		sf := make([]k8s.CustomResourceDefinitionSelectableField, len(kv.SelectableFields))
-		for i, field := range kv.SelectableFields {
+		fields := make([]string, 0, len(kv.SelectableFields))
+		for _, field := range kv.SelectableFields {
 			field = strings.Trim(field, " ")
 			if field == "" {
 				continue
@@ -116,11 +120,27 @@ func KindVersionToCRDSpecVersion(kv codegen.KindVersion, kindName string, stored
 			if field[0] != '.' {
 				field = fmt.Sprintf(".%s", field)
 			}
-			sf[i] = k8s.CustomResourceDefinitionSelectableField{
+			fields = append(fields, field)
+		}
+		// Check the fields against the schema now, rather than when the CRD is applied
+		schema, err := app.VersionSchemaFromMap(props)
+		if err != nil {
+			return k8s.CustomResourceDefinitionSpecVersion{}, err
+		}
+		err = app.ManifestKindVersion{
+			Name:             kv.Version,
+			Schema:           schema,
+			SelectableFields: fields,
+		}.ValidateSelectableFields()
+		if err != nil {
+			return k8s.CustomResourceDefinitionSpecVersion{}, fmt.Errorf("version %s: %w", kv.Version, err)
+		}
+		def.SelectableFields = make([]k8s.CustomResourceDefinitionSelectableField, len(fields))
+		for i, field := range fields {
+			def.SelectableFields[i] = k8s.CustomResourceDefinitionSelectableField{
 				JSONPath: field,
 			}
 		}
-		def.SelectableFields = sf
 	}
 
 	if len(kv.AdditionalPrinterColumns) > 0 {
@@ -138,6 +158,21 @@ func KindVersionToCRDSpecVersion(kv codegen.KindVersion, kindName string, stored
 		def.AdditionalPrinterColumns = apc
 	}
 
+	if kv.Scale != nil {
+		scale, err := toManifestScale(*kv.Scale)
+		if err != nil {
+			return k8s.CustomResourceDefinitionSpecVersion{}, fmt.Errorf("version %s: %w", kv.Version, err)
+		}
+		crdScale := k8s.CustomResourceDefinitionSubresourceScale{
+			SpecReplicasPath:   scale.SpecReplicasPath,
+			StatusReplicasPath: scale.StatusReplicasPath,
+		}
+		if scale.LabelSelectorPath != "" {
+			crdScale.LabelSelectorPath = &scale.LabelSelectorPath
+		}
+		def.Subresources["scale"] = crdScale
+	}
+
 	for k := range props {
 		if k != "spec" {
 			def.Subresources[k] = struct{}{}
@@ -147,6 +182,27 @@ func KindVersionToCRDSpecVersion(kv codegen.KindVersion, kindName string, stored
 	return def, nil
 }
 
+// toManifestScale converts the scale subresource of a kind version into an app.ScaleSubresource,
+// adding the leading '.' kubernetes requires to each path, and validates it
+func toManifestScale(scale codegen.KindScale) (*app.ScaleSubresource, error) {
+	withDot := func(path string) string {
+		path = strings.TrimSpace(path)
+		if path != "" && path[0] != '.' {
+			path = "." + path
+		}
+		return path
+	}
+	s := app.ScaleSubresource{
+		SpecReplicasPath:   withDot(scale.SpecReplicasPath),
+		StatusReplicasPath: withDot(scale.StatusReplicasPath),
+		LabelSelectorPath:  withDot(scale.LabelSelectorPath),
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("scale subresource error: %w", err)
+	}
+	return &s, nil
+}
+
 // customResourceDefinition differs from k8s.CustomResourceDefinition in that it doesn't use the metav1
 // TypeMeta and CommonMeta, as those do not contain YAML tags and get improperly serialized to YAML.
 // Since we don't need to use it with the kubernetes go-client, we don't need the extra functionality attached.
@@ -160,7 +216,8 @@ type customResourceDefinition struct {
 }
 
 type customResourceDefinitionMetadata struct {
-	Name string `json:"name,omitempty" yaml:"name" protobuf:"bytes,1,opt,name=name"`
+	Name        string            `json:"name,omitempty" yaml:"name" protobuf:"bytes,1,opt,name=name"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty" protobuf:"bytes,12,rep,name=annotations"`
 	// TODO: other fields as necessary for codegen
 }
 
@@ -212,6 +269,11 @@ func CUEToCRDOpenAPI(v cue.Value, name, version string) (map[string]any, error)
 	// CRDs have a problem with openness and the "additionalProperties: {}", we need to _instead_ use "x-kubernetes-preserve-unknown-fields": true
 	replaceAdditionalProperties(schemaProps)
 
+	// Add x-kubernetes-validations for any @cel attributes, which the CUE OpenAPI encoder ignores
+	if err = applyCELValidations(schemaProps, v, ""); err != nil {
+		return nil, err
+	}
+
 	return schemaProps, nil
 }
 
@@ -249,3 +311,22 @@ func replaceAdditionalProperties(props map[string]any) {
 		}
 	}
 }
+
+// kindMetadataAnnotations returns the CRD annotations for the maturity, owner, and documentation URL of a kind,
+// or nil if none are set
+func kindMetadataAnnotations(props codegen.KindProperties) map[string]string {
+	annotations := make(map[string]string)
+	if props.Maturity != "" {
+		annotations[app.KindMaturityAnnotation] = props.Maturity
+	}
+	if props.Owner != "" {
+		annotations[app.KindOwnerAnnotation] = props.Owner
+	}
+	if props.DocumentationURL != "" {
+		annotations[app.KindDocumentationURLAnnotation] = props.DocumentationURL
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
+}
@@ -0,0 +1,248 @@
+package jennies
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"github.com/grafana/codejen"
+	goyaml "gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/codegen"
+	"github.com/grafana/grafana-app-sdk/codegen/templates"
+)
+
+var (
+	customRouteMethods   = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+	customRoutePathWords = regexp.MustCompile(`[A-Za-z0-9]+`)
+)
+
+// CustomRouteGenerator is a one-to-many jenny which generates go code for the custom routes of each kind version:
+// a request and response type for each route with a request or response schema, and a routes file with
+// the method and path of each route, a typed handler type, and a function which turns a typed handler into
+// an untyped custom route handler (see app.NewTypedCustomRouteHandler).
+type CustomRouteGenerator struct {
+	// GroupByKind determines whether kinds are grouped by GroupVersionKind or just GroupVersion.
+	// If GroupByKind is true, generated paths are <kind>/<version>/<file>, instead of the default <version>/<file>.
+	// When GroupByKind is false, the generated types, constants, and functions are prefixed with the kind name.
+	GroupByKind bool
+}
+
+var _ codejen.OneToMany[codegen.Kind] = &CustomRouteGenerator{}
+
+func (*CustomRouteGenerator) JennyName() string {
+	return "CustomRouteGenerator"
+}
+
+//nolint:funlen
+func (c *CustomRouteGenerator) Generate(kind codegen.Kind) (codejen.Files, error) {
+	prefix := ""
+	if !c.GroupByKind {
+		prefix = exportField(kind.Name())
+	}
+	files := make(codejen.Files, 0)
+	for _, ver := range kind.Versions() {
+		if !ver.Codegen.Backend || len(ver.CustomRoutes) == 0 {
+			continue
+		}
+		routes, err := kindVersionCustomRoutes(ver)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", kind.Name(), ver.Version, err)
+		}
+		pathPrefix := GetGeneratedPath(c.GroupByKind, kind, ver.Version)
+		md := templates.CustomRoutesMetadata{
+			Package: ToPackageName(ver.Version),
+			Kind:    kind.Properties().Kind,
+			Version: ver.Version,
+			Prefix:  prefix,
+			Routes:  make([]templates.CustomRouteMetadata, 0, len(routes)),
+		}
+		for _, route := range routes {
+			rmd := templates.CustomRouteMetadata{
+				Name:         route.Name,
+				Method:       route.Method,
+				Path:         route.Path,
+				RequestType:  "any",
+				ResponseType: "any",
+			}
+			for _, body := range []struct {
+				suffix string
+				schema cue.Value
+				typ    *string
+			}{{"Request", route.Route.Request, &rmd.RequestType}, {"Response", route.Route.Response, &rmd.ResponseType}} {
+				if !body.schema.Exists() {
+					continue
+				}
+				goBytes, err := GoTypesFromCUE(body.schema, CUEGoConfig{
+					PackageName:                    md.Package,
+					Name:                           route.Name + body.suffix,
+					NamePrefix:                     prefix,
+					AddKubernetesOpenAPIGenComment: true,
+					AnyAsInterface:                 true,
+				}, len(body.schema.Path().Selectors()))
+				if err != nil {
+					return nil, fmt.Errorf("unable to generate %s %s %s type: %w", kind.Name(), ver.Version, route.Name+body.suffix, err)
+				}
+				files = append(files, codejen.File{
+					RelativePath: filepath.Join(pathPrefix, fmt.Sprintf("%s_%s_%s_gen.go", kind.Properties().MachineName, toSnakeCase(route.Name), strings.ToLower(body.suffix))),
+					Data:         goBytes,
+					From:         []codejen.NamedJenny{c},
+				})
+				*body.typ = prefix + route.Name + body.suffix
+			}
+			md.Routes = append(md.Routes, rmd)
+		}
+		b := bytes.Buffer{}
+		if err = templates.WriteCustomRoutes(md, &b); err != nil {
+			return nil, err
+		}
+		formatted, err := format.Source(b.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, codejen.File{
+			RelativePath: filepath.Join(pathPrefix, fmt.Sprintf("%s_routes_gen.go", kind.Properties().MachineName)),
+			Data:         formatted,
+			From:         []codejen.NamedJenny{c},
+		})
+	}
+	return files, nil
+}
+
+// kindCustomRoute is a codegen.KindCustomRoute with its path, method, and resolved name
+type kindCustomRoute struct {
+	Path   string
+	Method string
+	Name   string
+	Route  codegen.KindCustomRoute
+}
+
+// kindVersionCustomRoutes returns the custom routes of the version sorted by path and method,
+// with the name of each route defaulted from its method and path.
+// It returns an error if a method is not allowed, or two routes have the same name.
+func kindVersionCustomRoutes(ver codegen.KindVersion) ([]kindCustomRoute, error) {
+	routes := make([]kindCustomRoute, 0)
+	for path, methods := range ver.CustomRoutes {
+		path = strings.Trim(path, "/")
+		if path == "" {
+			return nil, fmt.Errorf("custom route path cannot be empty")
+		}
+		for method, route := range methods {
+			if !slices.Contains(customRouteMethods, method) {
+				return nil, fmt.Errorf("custom route '%s' has invalid method '%s', must be one of %s", path, method, strings.Join(customRouteMethods, ", "))
+			}
+			name := route.Name
+			if name == "" {
+				name = exportField(strings.ToLower(method))
+				for _, word := range customRoutePathWords.FindAllString(path, -1) {
+					name += exportField(word)
+				}
+			}
+			routes = append(routes, kindCustomRoute{
+				Path:   path,
+				Method: method,
+				Name:   name,
+				Route:  route,
+			})
+		}
+	}
+	slices.SortFunc(routes, func(a, b kindCustomRoute) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Method, b.Method)
+	})
+	for i := 1; i < len(routes); i++ {
+		for j := 0; j < i; j++ {
+			if routes[i].Name == routes[j].Name {
+				return nil, fmt.Errorf("custom routes '%s %s' and '%s %s' have the same name '%s'",
+					routes[j].Method, routes[j].Path, routes[i].Method, routes[i].Path, routes[i].Name)
+			}
+		}
+	}
+	return routes, nil
+}
+
+// toManifestCustomRoutes converts the custom routes of the version into app.CustomRoutes,
+// with the request and response schemas converted to OpenAPI
+func toManifestCustomRoutes(ver codegen.KindVersion) ([]app.CustomRoute, error) {
+	routes, err := kindVersionCustomRoutes(ver)
+	if err != nil {
+		return nil, err
+	}
+	if len(routes) == 0 {
+		return nil, nil
+	}
+	converted := make([]app.CustomRoute, 0, len(routes))
+	for _, route := range routes {
+		r := app.CustomRoute{
+			Path:   route.Path,
+			Method: route.Method,
+			Name:   route.Name,
+		}
+		if route.Route.Request.Exists() {
+			if r.RequestSchema, err = CUEToOpenAPISchema(route.Route.Request, route.Name+"Request"); err != nil {
+				return nil, fmt.Errorf("custom route %s request schema error: %w", route.Name, err)
+			}
+		}
+		if route.Route.Response.Exists() {
+			if r.ResponseSchema, err = CUEToOpenAPISchema(route.Route.Response, route.Name+"Response"); err != nil {
+				return nil, fmt.Errorf("custom route %s response schema error: %w", route.Name, err)
+			}
+		}
+		converted = append(converted, r)
+	}
+	return converted, nil
+}
+
+// CUEToOpenAPISchema converts a CUE schema into a single OpenAPI schema, with references expanded,
+// such as {"type": "object", "properties": {...}, "required": [...]} for a struct
+func CUEToOpenAPISchema(v cue.Value, name string) (map[string]any, error) {
+	oyaml, err := CUEValueToOAPIYAML(v, CUEOpenAPIConfig{
+		Name: name,
+		NameFunc: func(_ cue.Value, _ cue.Path) string {
+			return ""
+		},
+		ExpandReferences: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	back := cueOpenAPIEncoded{}
+	if err = goyaml.Unmarshal(oyaml, &back); err != nil {
+		return nil, err
+	}
+	if len(back.Components.Schemas) != 1 {
+		return nil, fmt.Errorf("expected one schema, got %d", len(back.Components.Schemas))
+	}
+	for _, s := range back.Components.Schemas {
+		schema, ok := s.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("error generating openapi schema - generated schema has invalid type")
+		}
+		replaceAdditionalProperties(map[string]any{"": schema})
+		return schema, nil
+	}
+	return nil, nil
+}
+
+// toSnakeCase converts a CamelCase name into snake_case, such as "GetHistory" to "get_history"
+func toSnakeCase(name string) string {
+	b := strings.Builder{}
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteRune('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,235 @@
+package jennies
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/codejen"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana-app-sdk/codegen"
+)
+
+const defaultDashboardSelectorLabel = "job"
+
+// DashboardGenerator generates a Grafana dashboard JSON file for observing an app's operator,
+// using the prometheus metrics exposed by the SDK (informers, reconcilers, kubernetes clients, and webhooks).
+type DashboardGenerator struct {
+	// MetricsNamespace is the prometheus namespace the app's metrics are exposed with
+	// (operator.RunnerMetricsConfig.Namespace). It may be empty.
+	MetricsNamespace string
+	// SelectorLabel is the prometheus label which identifies the app's metrics (such as "job" or "app").
+	// The dashboard's "app" variable filters on this label, and defaults to the app name. Defaults to "job".
+	SelectorLabel string
+}
+
+func (*DashboardGenerator) JennyName() string {
+	return "DashboardGenerator"
+}
+
+// Generate creates a single dashboard JSON file for the provided AppManifest
+func (d *DashboardGenerator) Generate(appManifest codegen.AppManifest) (codejen.Files, error) {
+	appName := appManifest.Properties().AppName
+	if appName == "" {
+		return nil, fmt.Errorf("app manifest must have an app name")
+	}
+	label := d.SelectorLabel
+	if label == "" {
+		label = defaultDashboardSelectorLabel
+	}
+	b := dashboardBuilder{
+		namespace: d.MetricsNamespace,
+		selector:  fmt.Sprintf(`%s=~"$app", kind=~"$kind"`, label),
+	}
+
+	panels := []dashboardPanel{
+		b.row("Reconciliation"),
+		b.timeseries("Reconcile rate", "ops", dashboardTarget{
+			Expr:         fmt.Sprintf(`sum by (kind, event_type) (rate(%s{%s}[$__rate_interval]))`, b.metric("reconciler", "process_duration_seconds_count"), b.selector),
+			LegendFormat: "{{kind}} {{event_type}}",
+		}),
+		b.timeseries("Reconcile latency", "s", b.quantile(0.5, "informer", "reconcile_duration_seconds"), b.quantile(0.95, "informer", "reconcile_duration_seconds")),
+		b.timeseries("Reconcile errors", "ops", dashboardTarget{
+			Expr:         fmt.Sprintf(`sum by (kind, event_type) (rate(%s{%s}[$__rate_interval]))`, b.metric("reconciler", "errors_total"), b.selector),
+			LegendFormat: "{{kind}} {{event_type}}",
+		}),
+		b.timeseries("Ongoing reconcile events", "short", dashboardTarget{
+			Expr:         fmt.Sprintf(`sum by (kind) (%s{%s})`, b.metric("", "ongoing_reconcile_events"), b.selector),
+			LegendFormat: "{{kind}}",
+		}),
+		b.row("Watches"),
+		b.timeseries("Watch events", "ops", dashboardTarget{
+			Expr:         fmt.Sprintf(`sum by (kind, event_type) (rate(%s{%s}[$__rate_interval]))`, b.metric("informer", "events_total"), b.selector),
+			LegendFormat: "{{kind}} {{event_type}}",
+		}),
+		b.timeseries("Terminal watch errors", "ops", dashboardTarget{
+			Expr:         fmt.Sprintf(`sum by (kind, status_code) (rate(%s{%s}[$__rate_interval]))`, b.metric("informer", "terminal_watch_errors_total"), b.selector),
+			LegendFormat: "{{kind}} {{status_code}}",
+		}),
+		b.row("Kubernetes client"),
+		b.timeseries("Client request rate", "reqps", dashboardTarget{
+			Expr:         fmt.Sprintf(`sum by (kind, verb, status_code) (rate(%s{%s}[$__rate_interval]))`, b.metric("kubernetes_client", "requests_total"), b.selector),
+			LegendFormat: "{{kind}} {{verb}} {{status_code}}",
+		}),
+		b.timeseries("Client request latency", "s", b.quantile(0.5, "kubernetes_client", "request_duration_seconds"), b.quantile(0.95, "kubernetes_client", "request_duration_seconds")),
+		b.row("Admission webhooks"),
+		b.timeseries("Admission request rate", "reqps", dashboardTarget{
+			Expr:         fmt.Sprintf(`sum by (kind, webhook, operation) (rate(%s{%s}[$__rate_interval]))`, b.metric("webhook", "admission_requests_total"), b.selector),
+			LegendFormat: "{{kind}} {{webhook}} {{operation}}",
+		}),
+		b.timeseries("Admission rejection rate", "percentunit", dashboardTarget{
+			Expr: fmt.Sprintf(`sum by (kind, webhook) (rate(%[1]s{%[2]s, allowed="false"}[$__rate_interval])) / sum by (kind, webhook) (rate(%[1]s{%[2]s}[$__rate_interval]))`,
+				b.metric("webhook", "admission_requests_total"), b.selector),
+			LegendFormat: "{{kind}} {{webhook}}",
+		}),
+	}
+	b.layout(panels)
+
+	dashboard := map[string]any{
+		"title":         fmt.Sprintf("%s operator", appName),
+		"uid":           fmt.Sprintf("%s-operator", appName),
+		"tags":          []string{"grafana-app-sdk", appName},
+		"editable":      true,
+		"schemaVersion": 39,
+		"time": map[string]string{
+			"from": "now-6h",
+			"to":   "now",
+		},
+		"refresh": "1m",
+		"templating": map[string]any{
+			"list": []map[string]any{{
+				"name":  "datasource",
+				"label": "Data source",
+				"type":  "datasource",
+				"query": "prometheus",
+			}, {
+				"name":  "app",
+				"label": "App",
+				"type":  "textbox",
+				"query": appName,
+				"current": map[string]string{
+					"text":  appName,
+					"value": appName,
+				},
+			}, {
+				"name":       "kind",
+				"label":      "Kind",
+				"type":       "query",
+				"datasource": dashboardDatasource,
+				"query":      fmt.Sprintf(`label_values(%s{%s=~"$app"}, kind)`, b.metric("informer", "events_total"), label),
+				"refresh":    2,
+				"multi":      true,
+				"includeAll": true,
+				"allValue":   ".*",
+				"current": map[string]any{
+					"text":  "All",
+					"value": "$__all",
+				},
+			}},
+		},
+		"panels": panels,
+	}
+	out, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return codejen.Files{{
+		RelativePath: fmt.Sprintf("%s-dashboard.json", appName),
+		Data:         append(out, '\n'),
+		From:         []codejen.NamedJenny{d},
+	}}, nil
+}
+
+var dashboardDatasource = map[string]string{
+	"type": "prometheus",
+	"uid":  "${datasource}",
+}
+
+type dashboardPanel struct {
+	ID         int               `json:"id"`
+	Type       string            `json:"type"`
+	Title      string            `json:"title"`
+	GridPos    dashboardGridPos  `json:"gridPos"`
+	Datasource map[string]string `json:"datasource,omitempty"`
+	Targets    []dashboardTarget `json:"targets,omitempty"`
+	FieldConf  map[string]any    `json:"fieldConfig,omitempty"`
+	Collapsed  *bool             `json:"collapsed,omitempty"`
+}
+
+type dashboardGridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type dashboardTarget struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat"`
+}
+
+type dashboardBuilder struct {
+	namespace string
+	selector  string
+}
+
+func (d *dashboardBuilder) metric(subsystem, name string) string {
+	return prometheus.BuildFQName(d.namespace, subsystem, name)
+}
+
+func (d *dashboardBuilder) quantile(q float64, subsystem, name string) dashboardTarget {
+	return dashboardTarget{
+		Expr: fmt.Sprintf(`histogram_quantile(%g, sum by (kind, le) (rate(%s{%s}[$__rate_interval])))`,
+			q, d.metric(subsystem, name+"_bucket"), d.selector),
+		LegendFormat: fmt.Sprintf("{{kind}} p%g", q*100),
+	}
+}
+
+func (*dashboardBuilder) row(title string) dashboardPanel {
+	collapsed := false
+	return dashboardPanel{
+		Type:      "row",
+		Title:     title,
+		Collapsed: &collapsed,
+	}
+}
+
+func (*dashboardBuilder) timeseries(title, unit string, targets ...dashboardTarget) dashboardPanel {
+	for i := range targets {
+		targets[i].RefID = string(rune('A' + i))
+	}
+	return dashboardPanel{
+		Type:       "timeseries",
+		Title:      title,
+		Datasource: dashboardDatasource,
+		Targets:    targets,
+		FieldConf: map[string]any{
+			"defaults": map[string]any{
+				"unit": unit,
+			},
+		},
+	}
+}
+
+// layout assigns IDs and grid positions to panels, placing two timeseries panels per line below each row
+func (*dashboardBuilder) layout(panels []dashboardPanel) {
+	const panelWidth, panelHeight = 12, 8
+	x, y := 0, 0
+	for i := range panels {
+		panels[i].ID = i + 1
+		if panels[i].Type == "row" {
+			if x > 0 {
+				y += panelHeight
+			}
+			panels[i].GridPos = dashboardGridPos{H: 1, W: 24, X: 0, Y: y}
+			x, y = 0, y+1
+			continue
+		}
+		panels[i].GridPos = dashboardGridPos{H: panelHeight, W: panelWidth, X: x, Y: y}
+		x += panelWidth
+		if x >= 24 {
+			x, y = 0, y+panelHeight
+		}
+	}
+}
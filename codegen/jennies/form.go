@@ -0,0 +1,330 @@
+package jennies
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"math"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"github.com/grafana/codejen"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/codegen"
+	"github.com/grafana/grafana-app-sdk/codegen/templates"
+)
+
+// FormAttribute is the CUE attribute used to set form hints on a field, such as
+// `@form(title="Name", widget="textarea", group="Details", placeholder="...", hidden, readOnly)`
+const FormAttribute = "form"
+
+// FormDescriptorGenerator is a one-to-many jenny which generates a go file for each kind version,
+// containing the kind version's app.FormDescriptor (see BuildFormDescriptor).
+type FormDescriptorGenerator struct {
+	// GroupByKind determines whether kinds are grouped by GroupVersionKind or just GroupVersion.
+	// If GroupByKind is true, generated paths are <kind>/<version>/<file>, instead of the default <version>/<file>.
+	// When GroupByKind is false, the FormDescriptor() function is prefixed with the kind name.
+	GroupByKind bool
+}
+
+var _ codejen.OneToMany[codegen.Kind] = &FormDescriptorGenerator{}
+
+func (*FormDescriptorGenerator) JennyName() string {
+	return "FormDescriptorGenerator"
+}
+
+func (f *FormDescriptorGenerator) Generate(kind codegen.Kind) (codejen.Files, error) {
+	prefix := ""
+	if !f.GroupByKind {
+		prefix = exportField(kind.Name())
+	}
+	files := make(codejen.Files, 0)
+	for _, ver := range kind.Versions() {
+		if !ver.Codegen.Backend {
+			continue
+		}
+		descriptor, err := BuildFormDescriptor(kind, ver)
+		if err != nil {
+			return nil, fmt.Errorf("unable to build form descriptor for %s %s: %w", kind.Name(), ver.Version, err)
+		}
+		encoded, err := json.MarshalIndent(descriptor, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		b := bytes.Buffer{}
+		err = templates.WriteFormDescriptorGo(templates.FormDescriptorMetadata{
+			Package:        ToPackageName(ver.Version),
+			Kind:           kind.Properties().Kind,
+			Version:        ver.Version,
+			FuncPrefix:     prefix,
+			FormDescriptor: goStringLiteral(encoded),
+		}, &b)
+		if err != nil {
+			return nil, err
+		}
+		formatted, err := format.Source(b.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, codejen.File{
+			RelativePath: filepath.Join(GetGeneratedPath(f.GroupByKind, kind, ver.Version), fmt.Sprintf("%s_form_gen.go", kind.Properties().MachineName)),
+			Data:         formatted,
+			From:         []codejen.NamedJenny{f},
+		})
+	}
+	return files, nil
+}
+
+// TypeScriptFormDescriptors is a one-to-many jenny which generates a TypeScript file for each kind version
+// where codegen.frontend is true, exporting the kind version's form descriptor (see BuildFormDescriptor)
+// and the TypeScript types for it.
+type TypeScriptFormDescriptors struct{}
+
+var _ codejen.OneToMany[codegen.Kind] = &TypeScriptFormDescriptors{}
+
+func (*TypeScriptFormDescriptors) JennyName() string {
+	return "TypeScriptFormDescriptors"
+}
+
+func (t *TypeScriptFormDescriptors) Generate(kind codegen.Kind) (codejen.Files, error) {
+	files := make(codejen.Files, 0)
+	for _, ver := range kind.Versions() {
+		if !ver.Codegen.Frontend {
+			continue
+		}
+		descriptor, err := BuildFormDescriptor(kind, ver)
+		if err != nil {
+			return nil, fmt.Errorf("unable to build form descriptor for %s %s: %w", kind.Name(), ver.Version, err)
+		}
+		encoded, err := json.MarshalIndent(descriptor, "", "    ")
+		if err != nil {
+			return nil, err
+		}
+		b := bytes.Buffer{}
+		err = templates.WriteFormDescriptorTS(templates.FormDescriptorMetadata{
+			Kind:           exportField(kind.Name()),
+			Version:        ver.Version,
+			FormDescriptor: string(encoded),
+		}, &b)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, codejen.File{
+			RelativePath: path.Join(kind.Properties().MachineName, ver.Version, fmt.Sprintf("%s_form_gen.ts", kind.Properties().MachineName)),
+			Data:         b.Bytes(),
+			From:         []codejen.NamedJenny{t},
+		})
+	}
+	return files, nil
+}
+
+// BuildFormDescriptor builds the app.FormDescriptor for the spec of a kind version.
+// Field types, descriptions, defaults, enums, and constraints come from the same OpenAPI schema as the kind's CRD
+// (with references expanded). Field order and the hints in @form attributes come from the CUE schema.
+func BuildFormDescriptor(kind codegen.Kind, ver codegen.KindVersion) (*app.FormDescriptor, error) {
+	props, err := CUEToCRDOpenAPI(ver.Schema, kind.Name(), ver.Version)
+	if err != nil {
+		return nil, err
+	}
+	b := &formDescriptorBuilder{
+		groups: make(map[string]bool),
+	}
+	descriptor := &app.FormDescriptor{
+		Group:   kind.Properties().Group,
+		Version: ver.Version,
+		Kind:    kind.Properties().Kind,
+		Title:   kind.Properties().Kind,
+		Fields:  make([]app.FormField, 0),
+	}
+	if spec, ok := props["spec"].(map[string]any); ok {
+		descriptor.Fields = b.fields(spec, ver.Schema.LookupPath(cue.MakePath(cue.Str("spec"))), "spec", "")
+	}
+	descriptor.Groups = b.groupOrder
+	return descriptor, nil
+}
+
+type formDescriptorBuilder struct {
+	groups     map[string]bool
+	groupOrder []string
+}
+
+// fields returns the form fields for the properties of the object schema, in the order they are declared in v
+func (b *formDescriptorBuilder) fields(schema map[string]any, v cue.Value, parentPath, parentGroup string) []app.FormField {
+	parsed := parseOpenAPISchema(schema)
+	order, values := orderedProperties(parsed.properties, v)
+	fields := make([]app.FormField, 0, len(order))
+	for _, name := range order {
+		prop, _ := parsed.properties[name].(map[string]any)
+		fields = append(fields, b.field(prop, values[name], name, parentPath+"."+name, parsed.required[name], parentGroup))
+	}
+	return fields
+}
+
+// orderedProperties returns the names of props in the order they are declared in v, along with the CUE value of each
+// declared property. Properties which are not in v, such as those merged from a disjunction, are added after in sorted order.
+func orderedProperties(props map[string]any, v cue.Value) ([]string, map[string]cue.Value) {
+	order := make([]string, 0, len(props))
+	values := make(map[string]cue.Value)
+	if v.Exists() {
+		if it, err := v.Fields(cue.Optional(true)); err == nil {
+			for it.Next() {
+				if it.Selector().LabelType() != cue.StringLabel {
+					continue
+				}
+				name := it.Selector().Unquoted()
+				if _, ok := props[name]; ok {
+					order = append(order, name)
+					values[name] = it.Value()
+				}
+			}
+		}
+	}
+	for _, name := range sortedKeys(props) {
+		if _, ok := values[name]; !ok {
+			order = append(order, name)
+		}
+	}
+	return order, values
+}
+
+//nolint:gocyclo
+func (b *formDescriptorBuilder) field(schema map[string]any, v cue.Value, name, fieldPath string, required bool, parentGroup string) app.FormField {
+	f := app.FormField{
+		Path:     fieldPath,
+		Name:     name,
+		Title:    formTitle(name),
+		Required: required,
+		Group:    parentGroup,
+		Type:     app.FormFieldTypeAny,
+	}
+	if schema == nil {
+		return f
+	}
+	f.Description, _ = schema["description"].(string)
+	f.Format, _ = schema["format"].(string)
+	f.Pattern, _ = schema["pattern"].(string)
+	f.Default = schema["default"]
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		f.Enum = enum
+	}
+	f.Minimum = formFloat(schema["minimum"])
+	f.Maximum = formFloat(schema["maximum"])
+	f.MinLength = formInt(schema["minLength"])
+	f.MaxLength = formInt(schema["maxLength"])
+	if v.Exists() {
+		b.applyAttribute(&f, v)
+	}
+	if f.Group != "" && !b.groups[f.Group] {
+		b.groups[f.Group] = true
+		b.groupOrder = append(b.groupOrder, f.Group)
+	}
+
+	parsed := parseOpenAPISchema(schema)
+	switch parsed.kind {
+	case SchemaKindString:
+		f.Type = app.FormFieldTypeString
+	case SchemaKindInteger:
+		f.Type = app.FormFieldTypeInteger
+	case SchemaKindNumber:
+		f.Type = app.FormFieldTypeNumber
+	case SchemaKindBoolean:
+		f.Type = app.FormFieldTypeBoolean
+	case SchemaKindArray:
+		f.Type = app.FormFieldTypeArray
+		item := b.field(parsed.elem, lookupFormValue(v, cue.AnyIndex), "", fieldPath+"[]", false, f.Group)
+		item.Title = f.Title
+		f.Items = &item
+	case SchemaKindObject:
+		f.Type = app.FormFieldTypeObject
+		f.Fields = b.fields(schema, v, fieldPath, f.Group)
+	case SchemaKindMap:
+		f.Type = app.FormFieldTypeMap
+		item := b.field(parsed.elem, lookupFormValue(v, cue.AnyString), "", fieldPath+"{}", false, f.Group)
+		item.Title = f.Title
+		f.Items = &item
+	}
+	return f
+}
+
+// applyAttribute sets the hints of the @form attribute of v (if it has one) on f
+func (*formDescriptorBuilder) applyAttribute(f *app.FormField, v cue.Value) {
+	attr := v.Attribute(FormAttribute)
+	if attr.Err() != nil {
+		return
+	}
+	for i := 0; i < attr.NumArgs(); i++ {
+		key, value := attr.Arg(i)
+		switch key {
+		case "title":
+			f.Title = value
+		case "widget":
+			f.Widget = value
+		case "group":
+			f.Group = value
+		case "placeholder":
+			f.Placeholder = value
+		case "hidden":
+			f.Hidden = value == "" || value == "true"
+		case "readOnly":
+			f.ReadOnly = value == "" || value == "true"
+		}
+	}
+}
+
+// lookupFormValue returns the value of the elements (for cue.AnyIndex) or pattern values (for cue.AnyString) of v
+func lookupFormValue(v cue.Value, sel cue.Selector) cue.Value {
+	if !v.Exists() {
+		return v
+	}
+	return v.LookupPath(cue.MakePath(sel))
+}
+
+var formTitleBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
+// formTitle converts a JSON field name into a title, such as "innerField1" to "Inner Field1"
+func formTitle(name string) string {
+	title := strings.NewReplacer("_", " ", "-", " ").Replace(formTitleBoundary.ReplaceAllString(name, "${1} ${2}"))
+	return exportField(title)
+}
+
+func formFloat(v any) *float64 {
+	switch cast := v.(type) {
+	case int:
+		f := float64(cast)
+		return &f
+	case int64:
+		f := float64(cast)
+		return &f
+	case uint64:
+		f := float64(cast)
+		return &f
+	case float64:
+		return &cast
+	}
+	return nil
+}
+
+func formInt(v any) *int64 {
+	switch cast := v.(type) {
+	case int:
+		i := int64(cast)
+		return &i
+	case int64:
+		return &cast
+	case uint64:
+		if cast > math.MaxInt64 {
+			return nil
+		}
+		i := int64(cast)
+		return &i
+	case float64:
+		i := int64(cast)
+		return &i
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"fmt"
 	"go/format"
+	"slices"
 	"strings"
 
 	"github.com/grafana/codejen"
@@ -17,6 +18,8 @@ import (
 type ManifestOutputEncoder func(any) ([]byte, error)
 
 // ManifestGenerator generates a JSON/YAML App Manifest.
+// If any kind version declares custom routes, it also generates a JSON/YAML OpenAPI document of the routes
+// (see app.ManifestData.CustomRoutesOpenAPI3).
 type ManifestGenerator struct {
 	Encoder       ManifestOutputEncoder
 	FileExtension string
@@ -63,6 +66,26 @@ func (m *ManifestGenerator) Generate(appManifest codegen.AppManifest) (codejen.F
 		From:         []codejen.NamedJenny{m},
 	})
 
+	plurals := make(map[string]string)
+	for _, kind := range appManifest.Kinds() {
+		plurals[kind.Name()] = kind.Properties().PluralMachineName
+	}
+	routes, err := manifestData.CustomRoutesOpenAPI3(plurals)
+	if err != nil {
+		return nil, err
+	}
+	if routes.Paths.Len() > 0 {
+		out, err = m.Encoder(routes)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, codejen.File{
+			RelativePath: fmt.Sprintf("%s-custom-routes-openapi.%s", manifestData.AppName, m.FileExtension),
+			Data:         out,
+			From:         []codejen.NamedJenny{m},
+		})
+	}
+
 	return files, nil
 }
 
@@ -120,6 +143,7 @@ func buildManifestData(m codegen.AppManifest) (*app.ManifestData, error) {
 	manifest.AppName = m.Name()
 	manifest.Group = m.Properties().FullGroup
 
+	configKinds := 0
 	for _, kind := range m.Kinds() {
 		// TODO
 		if manifest.AppName == "" {
@@ -136,10 +160,22 @@ func buildManifestData(m codegen.AppManifest) (*app.ManifestData, error) {
 		}
 
 		mkind := app.ManifestKind{
-			Kind:       kind.Name(),
-			Scope:      kind.Properties().Scope,
-			Conversion: kind.Properties().Conversion,
-			Versions:   make([]app.ManifestKindVersion, 0),
+			Kind:             kind.Name(),
+			Scope:            kind.Properties().Scope,
+			Conversion:       kind.Properties().Conversion,
+			ShortNames:       kind.Properties().ShortNames,
+			Categories:       kind.Properties().Categories,
+			ConfigKind:       kind.Properties().ConfigKind,
+			Maturity:         app.KindMaturity(kind.Properties().Maturity),
+			Owner:            kind.Properties().Owner,
+			DocumentationURL: kind.Properties().DocumentationURL,
+			Versions:         make([]app.ManifestKindVersion, 0),
+		}
+		if mkind.ConfigKind {
+			configKinds++
+			if configKinds > 1 {
+				return nil, fmt.Errorf("only one kind may be a config kind, but %s is the second", mkind.Kind)
+			}
 		}
 
 		for _, version := range kind.Versions() {
@@ -169,6 +205,34 @@ func buildManifestData(m codegen.AppManifest) (*app.ManifestData, error) {
 					Operations: operations,
 				}
 			}
+			if len(version.Defaulting.Operations) > 0 {
+				if mver.Admission == nil {
+					mver.Admission = &app.AdmissionCapabilities{}
+				}
+				operations, err := sanitizeAdmissionOperations(version.Defaulting.Operations)
+				if err != nil {
+					return nil, fmt.Errorf("defaulting operations error: %w", err)
+				}
+				mver.Admission.Defaulting = &app.DefaultingCapability{
+					Operations: operations,
+				}
+			}
+			if mkind.ConfigKind {
+				// Config kinds are validated on create to ensure only one instance exists
+				addValidationOperations(&mver, app.AdmissionOperationCreate)
+			}
+			if !version.SizeLimits.IsEmpty() {
+				mver.SizeLimits = &app.SizeLimits{
+					MaxBytes:  version.SizeLimits.MaxBytes,
+					MaxItems:  version.SizeLimits.MaxItems,
+					MaxLength: version.SizeLimits.MaxLength,
+				}
+				if err := mver.SizeLimits.Validate(); err != nil {
+					return nil, fmt.Errorf("%s %s size limits error: %w", mkind.Kind, version.Version, err)
+				}
+				// Size limits are enforced by validating creates and updates
+				addValidationOperations(&mver, app.AdmissionOperationCreate, app.AdmissionOperationUpdate)
+			}
 			crd, err := KindVersionToCRDSpecVersion(version, mkind.Kind, true)
 			if err != nil {
 				return nil, err
@@ -178,10 +242,24 @@ func buildManifestData(m codegen.AppManifest) (*app.ManifestData, error) {
 				return nil, fmt.Errorf("version schema error: %w", err)
 			}
 			mver.SelectableFields = version.SelectableFields
+			mver.AdditionalPrinterColumns = toManifestPrinterColumns(version.AdditionalPrinterColumns)
+			if version.Scale != nil {
+				mver.Scale, err = toManifestScale(*version.Scale)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s %w", mkind.Kind, version.Version, err)
+				}
+			}
+			mver.CustomRoutes, err = toManifestCustomRoutes(version)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s %w", mkind.Kind, version.Version, err)
+			}
 			mkind.Versions = append(mkind.Versions, mver)
 		}
 		manifest.Kinds = append(manifest.Kinds, mkind)
 	}
+	if err := manifest.ValidateSelectableFields(); err != nil {
+		return nil, err
+	}
 
 	if len(m.Properties().ExtraPermissions.AccessKinds) > 0 {
 		perms := make([]app.KindPermission, len(m.Properties().ExtraPermissions.AccessKinds))
@@ -190,6 +268,7 @@ func buildManifestData(m codegen.AppManifest) (*app.ManifestData, error) {
 				Group:    p.Group,
 				Resource: p.Resource,
 				Actions:  toKindPermissionActions(p.Actions),
+				Required: p.Required,
 			}
 		}
 		manifest.ExtraPermissions = &app.Permissions{
@@ -230,3 +309,45 @@ func toKindPermissionActions(actions []string) []app.KindPermissionAction {
 	}
 	return a
 }
+
+func toManifestPrinterColumns(columns []codegen.AdditionalPrinterColumn) []app.AdditionalPrinterColumn {
+	if len(columns) == 0 {
+		return nil
+	}
+	c := make([]app.AdditionalPrinterColumn, len(columns))
+	for i, col := range columns {
+		c[i] = app.AdditionalPrinterColumn{
+			Name:     col.Name,
+			Type:     col.Type,
+			JSONPath: col.JSONPath,
+		}
+		if col.Format != nil {
+			c[i].Format = *col.Format
+		}
+		if col.Description != nil {
+			c[i].Description = *col.Description
+		}
+		if col.Priority != nil {
+			c[i].Priority = *col.Priority
+		}
+	}
+	return c
+}
+
+// addValidationOperations adds each of operations to the validation capability of version which it doesn't already have
+func addValidationOperations(version *app.ManifestKindVersion, operations ...app.AdmissionOperation) {
+	if version.Admission == nil {
+		version.Admission = &app.AdmissionCapabilities{}
+	}
+	if version.Admission.Validation == nil {
+		version.Admission.Validation = &app.ValidationCapability{}
+	}
+	if slices.Contains(version.Admission.Validation.Operations, app.AdmissionOperationAny) {
+		return
+	}
+	for _, op := range operations {
+		if !slices.Contains(version.Admission.Validation.Operations, op) {
+			version.Admission.Validation.Operations = append(version.Admission.Validation.Operations, op)
+		}
+	}
+}
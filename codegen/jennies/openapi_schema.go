@@ -0,0 +1,96 @@
+package jennies
+
+// SchemaKind is the kind of value described by an OpenAPI schema, which determines how the generators
+// which walk OpenAPI schemas (such as the zod, python, form, and protobuf generators) represent it.
+type SchemaKind int
+
+const (
+	// SchemaKindAny is a schema which can be any value, such as one without a type
+	SchemaKindAny SchemaKind = iota
+	SchemaKindString
+	SchemaKindInteger
+	SchemaKindNumber
+	SchemaKindBoolean
+	// SchemaKindArray is an array, whose items have the schema of the "items" keyword
+	SchemaKindArray
+	// SchemaKindObject is an object with properties
+	SchemaKindObject
+	// SchemaKindMap is an object without properties, whose values have the schema of the "additionalProperties" keyword
+	SchemaKindMap
+	// SchemaKindUntypedObject is an object with neither properties nor an additionalProperties schema
+	SchemaKindUntypedObject
+)
+
+// SchemaKindOf returns the SchemaKind of a schema with the type t (which is empty if the schema has no type),
+// depending on whether it has properties or an additionalProperties schema. A schema without a type is an object
+// if it has properties or an additionalProperties schema. Unknown types are SchemaKindAny.
+func SchemaKindOf(t string, hasProperties, hasAdditionalProperties bool) SchemaKind {
+	switch t {
+	case "string":
+		return SchemaKindString
+	case "integer":
+		return SchemaKindInteger
+	case "number":
+		return SchemaKindNumber
+	case "boolean":
+		return SchemaKindBoolean
+	case "array":
+		return SchemaKindArray
+	case "object", "":
+		switch {
+		case hasProperties:
+			return SchemaKindObject
+		case hasAdditionalProperties:
+			return SchemaKindMap
+		case t == "object":
+			return SchemaKindUntypedObject
+		}
+	}
+	return SchemaKindAny
+}
+
+// openAPISchema is an OpenAPI schema (as generated for the CRD of a kind) split into the parts the generators walk
+type openAPISchema struct {
+	kind SchemaKind
+	// properties are the properties of a SchemaKindObject
+	properties map[string]any
+	// required contains the names of the required properties of a SchemaKindObject
+	required map[string]bool
+	// elem is the schema of the items of a SchemaKindArray, or of the values of a SchemaKindMap.
+	// It is nil if the schema does not set it.
+	elem map[string]any
+}
+
+// parseOpenAPISchema splits schema into the parts the generators walk. A nil schema, and int-or-string schemas,
+// are SchemaKindAny.
+func parseOpenAPISchema(schema map[string]any) openAPISchema {
+	parsed := openAPISchema{
+		required: make(map[string]bool),
+	}
+	if schema == nil {
+		return parsed
+	}
+	if intOrString, ok := schema["x-kubernetes-int-or-string"].(bool); ok && intOrString {
+		return parsed
+	}
+	t, _ := schema["type"].(string)
+	props, _ := schema["properties"].(map[string]any)
+	additional, _ := schema["additionalProperties"].(map[string]any)
+	parsed.kind = SchemaKindOf(t, len(props) > 0, len(additional) > 0)
+	switch parsed.kind {
+	case SchemaKindArray:
+		parsed.elem, _ = schema["items"].(map[string]any)
+	case SchemaKindObject:
+		parsed.properties = props
+		if req, ok := schema["required"].([]any); ok {
+			for _, r := range req {
+				if s, ok := r.(string); ok {
+					parsed.required[s] = true
+				}
+			}
+		}
+	case SchemaKindMap:
+		parsed.elem = additional
+	}
+	return parsed
+}
@@ -0,0 +1,507 @@
+package jennies
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"github.com/emicklei/proto"
+	"github.com/grafana/codejen"
+
+	"github.com/grafana/grafana-app-sdk/codegen"
+)
+
+// ProtobufAttribute is the CUE attribute used to set the field number of a field in generated .proto files,
+// such as `@protobuf(3)`. Fields without the attribute keep their number from the previously generated file
+// (see ProtobufGenerator.Previous), and new fields are given the lowest number which is not used or reserved.
+const ProtobufAttribute = "protobuf"
+
+const protobufHeader = "// This file was generated by grafana-app-sdk. DO NOT EDIT.\n"
+
+// ProtobufGenerator is a one-to-many jenny which generates a proto3 file for each version of a kind,
+// containing a message for the kind and messages for each of its top-level fields (such as spec and status).
+// The messages are generated from the same OpenAPI schema as the kind's CRD, and use the JSON names of the fields.
+// The protobuf JSON encoding of a message is not always the JSON of the kind: 64-bit integers (int64 and uint64 fields)
+// are encoded as strings in protobuf JSON, and fields with default values may be omitted.
+type ProtobufGenerator struct {
+	// Previous contains the previously generated files, with the same relative paths as the generated files.
+	// Fields keep their number from the previous file of their message, and the numbers of removed fields are reserved,
+	// so that regenerating after adding, removing, or reordering fields does not change the number of any field.
+	// If nil, or a file does not exist, fields are numbered in the order they are declared.
+	Previous fs.FS
+}
+
+var _ codejen.OneToMany[codegen.Kind] = &ProtobufGenerator{}
+
+func (*ProtobufGenerator) JennyName() string {
+	return "ProtobufGenerator"
+}
+
+func (p *ProtobufGenerator) Generate(kind codegen.Kind) (codejen.Files, error) {
+	files := make(codejen.Files, 0)
+	for _, ver := range kind.Versions() {
+		relativePath := path.Join(ToPackageName(kind.Properties().MachineName), ToPackageName(ver.Version), kind.Properties().MachineName+".proto")
+		previous, err := p.previous(relativePath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read previous protobuf definitions for %s %s: %w", kind.Name(), ver.Version, err)
+		}
+		b, err := generateProtobuf(kind, ver, previous)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate protobuf definitions for %s %s: %w", kind.Name(), ver.Version, err)
+		}
+		files = append(files, codejen.File{
+			RelativePath: relativePath,
+			Data:         b,
+			From:         []codejen.NamedJenny{p},
+		})
+	}
+	return files, nil
+}
+
+// previous returns the field numbers of each message in the previously generated file at relativePath,
+// or nil if there is no previous file
+func (p *ProtobufGenerator) previous(relativePath string) (map[string]*previousProtobufMessage, error) {
+	if p.Previous == nil {
+		return nil, nil
+	}
+	contents, err := fs.ReadFile(p.Previous, relativePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	parser := proto.NewParser(bytes.NewReader(contents))
+	parser.Filename(relativePath)
+	def, err := parser.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", relativePath, err)
+	}
+	messages := make(map[string]*previousProtobufMessage)
+	var add func(elements []proto.Visitee, scope string)
+	add = func(elements []proto.Visitee, scope string) {
+		for _, e := range elements {
+			msg, ok := e.(*proto.Message)
+			if !ok {
+				continue
+			}
+			msgPath := protobufMessagePath(scope, msg.Name)
+			prev := &previousProtobufMessage{
+				fields:   make(map[string]int64),
+				reserved: make(map[int64]bool),
+			}
+			for _, me := range msg.Elements {
+				switch cast := me.(type) {
+				case *proto.NormalField:
+					prev.addField(cast.Field)
+				case *proto.MapField:
+					prev.addField(cast.Field)
+				case *proto.Reserved:
+					for _, r := range cast.Ranges {
+						for n := r.From; n <= r.To && !r.Max; n++ {
+							prev.reserved[int64(n)] = true
+						}
+					}
+				}
+			}
+			messages[msgPath] = prev
+			add(msg.Elements, msgPath)
+		}
+	}
+	add(def.Elements, "")
+	return messages, nil
+}
+
+// previousProtobufMessage contains the field numbers of a message in a previously generated file
+type previousProtobufMessage struct {
+	// fields maps the JSON name of each field to its number
+	fields map[string]int64
+	// reserved are the reserved field numbers
+	reserved map[int64]bool
+}
+
+func (m *previousProtobufMessage) addField(f *proto.Field) {
+	jsonName := protobufDefaultJSONName(f.Name)
+	for _, opt := range f.Options {
+		if opt.Name == "json_name" {
+			jsonName = opt.Constant.Source
+		}
+	}
+	m.fields[jsonName] = int64(f.Sequence)
+}
+
+func protobufMessagePath(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+func generateProtobuf(kind codegen.Kind, ver codegen.KindVersion, previous map[string]*previousProtobufMessage) ([]byte, error) {
+	props, err := CUEToCRDOpenAPI(ver.Schema, kind.Name(), ver.Version)
+	if err != nil {
+		return nil, err
+	}
+	typeName := exportField(sanitizeLabelString(kind.Name()))
+	g := &protobufGenerator{
+		imports:  make(map[string]bool),
+		previous: previous,
+	}
+	kindMessage, err := g.message(typeName, map[string]any{
+		"description": fmt.Sprintf("%s is the %s version of the %s kind.", typeName, ver.Version, kind.Properties().Kind),
+		"properties":  props,
+	}, ver.Schema, typeName, &g.messages, "")
+	if err != nil {
+		return nil, err
+	}
+	g.messages = append([]*protobufMessage{kindMessage}, g.messages...)
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(protobufHeader)
+	buf.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(buf, "package %s;\n", protobufPackage(kind.Properties().Group, ver.Version))
+	if len(g.imports) > 0 {
+		imports := make([]string, 0, len(g.imports))
+		for imp := range g.imports {
+			imports = append(imports, imp)
+		}
+		sort.Strings(imports)
+		buf.WriteString("\n")
+		for _, imp := range imports {
+			fmt.Fprintf(buf, "import %q;\n", imp)
+		}
+	}
+	for _, m := range g.messages {
+		buf.WriteString("\n")
+		m.write(buf, "")
+	}
+	return buf.Bytes(), nil
+}
+
+type protobufMessage struct {
+	name        string
+	description string
+	// reserved are the numbers of fields which were removed since the previously generated file, sorted
+	reserved []int64
+	fields   []protobufField
+	nested   []*protobufMessage
+}
+
+type protobufField struct {
+	description string
+	// label is "repeated", "optional", or empty
+	label    string
+	typ      string
+	name     string
+	jsonName string
+	number   int64
+}
+
+func (m *protobufMessage) write(buf *bytes.Buffer, indent string) {
+	writeProtobufComment(buf, m.description, indent)
+	fmt.Fprintf(buf, "%smessage %s {\n", indent, m.name)
+	if len(m.reserved) > 0 {
+		numbers := make([]string, len(m.reserved))
+		for i, n := range m.reserved {
+			numbers[i] = strconv.FormatInt(n, 10)
+		}
+		fmt.Fprintf(buf, "%s  reserved %s;\n", indent, strings.Join(numbers, ", "))
+	}
+	for _, f := range m.fields {
+		writeProtobufComment(buf, f.description, indent+"  ")
+		buf.WriteString(indent + "  ")
+		if f.label != "" {
+			buf.WriteString(f.label + " ")
+		}
+		fmt.Fprintf(buf, "%s %s = %d", f.typ, f.name, f.number)
+		if protobufDefaultJSONName(f.name) != f.jsonName {
+			fmt.Fprintf(buf, " [json_name = %q]", f.jsonName)
+		}
+		buf.WriteString(";\n")
+	}
+	for _, n := range m.nested {
+		buf.WriteString("\n")
+		n.write(buf, indent+"  ")
+	}
+	fmt.Fprintf(buf, "%s}\n", indent)
+}
+
+func writeProtobufComment(buf *bytes.Buffer, comment, indent string) {
+	if comment == "" {
+		return
+	}
+	for _, line := range strings.Split(comment, "\n") {
+		fmt.Fprintf(buf, "%s// %s\n", indent, line)
+	}
+}
+
+// protobufGenerator builds protobuf messages from an OpenAPI schema.
+// Top-level fields of the kind are generated as top-level messages, and other objects as nested messages.
+type protobufGenerator struct {
+	messages []*protobufMessage
+	imports  map[string]bool
+	// previous are the messages of the previously generated file, by their path (see protobufMessagePath)
+	previous map[string]*previousProtobufMessage
+}
+
+// message returns the message for the object schema, whose fields are declared in v, and which is declared in scope
+// (the path of its parent message, or empty for top-level messages).
+// The messages of object fields are named with prefix and the field name, and added to container,
+// or nested in the returned message if container is nil.
+//
+//nolint:gocyclo
+func (g *protobufGenerator) message(
+	name string, schema map[string]any, v cue.Value, prefix string, container *[]*protobufMessage, scope string,
+) (*protobufMessage, error) {
+	msg := &protobufMessage{
+		name: name,
+	}
+	msgPath := protobufMessagePath(scope, name)
+	if container == nil {
+		container = &msg.nested
+		scope = msgPath
+	}
+	msg.description, _ = schema["description"].(string)
+	parsed := parseOpenAPISchema(schema)
+	props, required := parsed.properties, parsed.required
+	order, values := orderedProperties(props, v)
+
+	// Numbers set with the protobuf attribute are reserved before numbering the other fields
+	numbers := make(map[string]int64)
+	used := make(map[int64]string)
+	for _, propName := range order {
+		if !values[propName].Exists() {
+			continue
+		}
+		attr := values[propName].Attribute(ProtobufAttribute)
+		if attr.Err() != nil {
+			continue
+		}
+		number, err := attr.Int(0)
+		if err != nil || number < 1 {
+			return nil, fmt.Errorf("invalid @%s attribute for field %s: must be a positive field number", ProtobufAttribute, propName)
+		}
+		if other, ok := used[number]; ok {
+			return nil, fmt.Errorf("fields %s and %s have the same field number %d", other, propName, number)
+		}
+		numbers[propName] = number
+		used[number] = propName
+	}
+
+	// Fields without the attribute keep their previous number, unless it has since been set on another field
+	previous := g.previous[msgPath]
+	if previous != nil {
+		for _, propName := range order {
+			if _, ok := numbers[propName]; ok {
+				continue
+			}
+			number, ok := previous.fields[propName]
+			if !ok || used[number] != "" {
+				continue
+			}
+			numbers[propName] = number
+			used[number] = propName
+		}
+		// The numbers of removed fields stay reserved, so that they are never given to new fields
+		reserved := make(map[int64]bool)
+		for n := range previous.reserved {
+			reserved[n] = true
+		}
+		for _, n := range previous.fields {
+			reserved[n] = true
+		}
+		for n := range reserved {
+			if used[n] != "" {
+				continue
+			}
+			msg.reserved = append(msg.reserved, n)
+			used[n] = "reserved"
+		}
+		slices.Sort(msg.reserved)
+	}
+
+	names := make(map[string]bool)
+	next := int64(1)
+	for _, propName := range order {
+		prop, _ := props[propName].(map[string]any)
+		typ, repeated, err := g.fieldType(prop, values[propName], prefix+exportField(sanitizeLabelString(propName)), container, scope)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", propName, err)
+		}
+		f := protobufField{
+			typ:      typ,
+			jsonName: propName,
+			number:   numbers[propName],
+		}
+		f.description, _ = prop["description"].(string)
+		if enum, ok := prop["enum"].([]any); ok && len(enum) > 0 {
+			allowed := make([]string, 0, len(enum))
+			for _, e := range enum {
+				allowed = append(allowed, fmt.Sprintf("%v", e))
+			}
+			f.description = strings.TrimSpace(f.description + "\nAllowed values: " + strings.Join(allowed, ", "))
+		}
+		switch {
+		case repeated:
+			f.label = "repeated"
+		case !required[propName] && protobufScalarTypes[typ]:
+			f.label = "optional"
+		}
+		base := protobufFieldName(propName)
+		f.name = base
+		for i := 2; names[f.name]; i++ {
+			f.name = fmt.Sprintf("%s_%d", base, i)
+		}
+		names[f.name] = true
+		if f.number == 0 {
+			for used[next] != "" {
+				next++
+			}
+			f.number = next
+			used[next] = propName
+		}
+		msg.fields = append(msg.fields, f)
+	}
+	return msg, nil
+}
+
+var protobufScalarTypes = map[string]bool{
+	"string": true, "bytes": true, "bool": true, "double": true, "float": true,
+	"int32": true, "int64": true, "uint32": true, "uint64": true,
+}
+
+// fieldType returns the protobuf type for the schema, and whether the field is repeated.
+// Objects with properties are generated as a message named name, which is added to container
+// (whose messages are declared in scope).
+//
+//nolint:gocyclo
+func (g *protobufGenerator) fieldType(
+	schema map[string]any, v cue.Value, name string, container *[]*protobufMessage, scope string,
+) (string, bool, error) {
+	parsed := parseOpenAPISchema(schema)
+	switch parsed.kind {
+	case SchemaKindString:
+		switch schema["format"] {
+		case "date-time":
+			return g.wellKnown("Timestamp", "timestamp"), false, nil
+		case "byte":
+			return "bytes", false, nil
+		}
+		return "string", false, nil
+	case SchemaKindInteger:
+		switch format, _ := schema["format"].(string); format {
+		case "int32", "uint32", "uint64":
+			return format, false, nil
+		}
+		// Sized CUE integers (such as int32) have bounds instead of a format in the OpenAPI schema
+		if minimum, maximum := formFloat(schema["minimum"]), formFloat(schema["maximum"]); minimum != nil && maximum != nil &&
+			*minimum >= math.MinInt32 && *maximum <= math.MaxInt32 {
+			return "int32", false, nil
+		}
+		return "int64", false, nil
+	case SchemaKindNumber:
+		if schema["format"] == "float" {
+			return "float", false, nil
+		}
+		return "double", false, nil
+	case SchemaKindBoolean:
+		return "bool", false, nil
+	case SchemaKindArray:
+		item, repeated, err := g.fieldType(parsed.elem, lookupFormValue(v, cue.AnyIndex), name+"Item", container, scope)
+		if err != nil {
+			return "", false, err
+		}
+		// Fields cannot be repeated more than once, or be a repeated map
+		if repeated || strings.HasPrefix(item, "map<") {
+			return g.wellKnown("ListValue", "struct"), false, nil
+		}
+		return item, true, nil
+	case SchemaKindObject:
+		msg, err := g.message(name, schema, v, "", nil, scope)
+		if err != nil {
+			return "", false, err
+		}
+		*container = append(*container, msg)
+		return name, false, nil
+	case SchemaKindMap:
+		value, repeated, err := g.fieldType(parsed.elem, lookupFormValue(v, cue.AnyString), name+"Value", container, scope)
+		if err != nil {
+			return "", false, err
+		}
+		// Map values cannot be repeated or maps
+		if repeated || strings.HasPrefix(value, "map<") {
+			value = g.wellKnown("Value", "struct")
+		}
+		return fmt.Sprintf("map<string, %s>", value), false, nil
+	case SchemaKindUntypedObject:
+		return g.wellKnown("Struct", "struct"), false, nil
+	default:
+		return g.wellKnown("Value", "struct"), false, nil
+	}
+}
+
+// wellKnown returns the name of the google.protobuf well-known type typeName, adding the import of its file
+func (g *protobufGenerator) wellKnown(typeName, file string) string {
+	g.imports["google/protobuf/"+file+".proto"] = true
+	return "google.protobuf." + typeName
+}
+
+var protobufIdentifierChars = regexp.MustCompile(`[^a-z0-9_]`)
+
+// protobufPackage returns the protobuf package for a kind version, which is the reversed group followed by the version,
+// such as "com.grafana.ext.foo.v1" for the group "foo.ext.grafana.com"
+func protobufPackage(group, version string) string {
+	segments := strings.Split(group, ".")
+	parts := make([]string, 0, len(segments)+1)
+	for i := len(segments) - 1; i >= 0; i-- {
+		parts = append(parts, protobufIdentifier(segments[i]))
+	}
+	return strings.Join(append(parts, protobufIdentifier(version)), ".")
+}
+
+func protobufIdentifier(s string) string {
+	s = protobufIdentifierChars.ReplaceAllString(strings.ToLower(s), "_")
+	if s == "" || (s[0] >= '0' && s[0] <= '9') || s[0] == '_' {
+		s = "x" + s
+	}
+	return s
+}
+
+// protobufFieldName converts a JSON field name into a snake_case protobuf field name
+func protobufFieldName(jsonName string) string {
+	name := pythonAcronymBoundary.ReplaceAllString(jsonName, "${1}_${2}")
+	name = pythonCamelBoundary.ReplaceAllString(name, "${1}_${2}")
+	name = strings.ToLower(pythonFieldNameRegex.ReplaceAllString(name, "_"))
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "f_" + name
+	}
+	return name
+}
+
+// protobufDefaultJSONName returns the JSON name protoc derives from a field name, which is the name in lowerCamelCase
+func protobufDefaultJSONName(name string) string {
+	b := strings.Builder{}
+	upper := false
+	for _, r := range name {
+		switch {
+		case r == '_':
+			upper = true
+		case upper && r >= 'a' && r <= 'z':
+			b.WriteRune(r - 'a' + 'A')
+			upper = false
+		default:
+			b.WriteRune(r)
+			upper = false
+		}
+	}
+	return b.String()
+}
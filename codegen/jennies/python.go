@@ -0,0 +1,346 @@
+package jennies
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grafana/codejen"
+
+	"github.com/grafana/grafana-app-sdk/codegen"
+	"github.com/grafana/grafana-app-sdk/codegen/templates"
+)
+
+const pythonHeader = "# This file was generated by grafana-app-sdk. DO NOT EDIT.\n"
+
+// PythonModels is a one-to-many jenny which generates a python module of dataclass models for each version of a kind.
+// The models are generated from the same OpenAPI schema as the kind's CRD, and import their base classes
+// from the package generated by PythonPackage.
+type PythonModels struct{}
+
+var _ codejen.OneToMany[codegen.Kind] = &PythonModels{}
+
+func (*PythonModels) JennyName() string {
+	return "PythonModels"
+}
+
+func (p *PythonModels) Generate(kind codegen.Kind) (codejen.Files, error) {
+	files := make(codejen.Files, 0)
+	for _, ver := range kind.Versions() {
+		b, err := generatePythonModels(kind, ver)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate python models for %s %s: %w", kind.Name(), ver.Version, err)
+		}
+		dir := path.Join(ToPackageName(kind.Properties().MachineName), ToPackageName(ver.Version))
+		files = append(files, codejen.File{
+			RelativePath: path.Join(dir, "models.py"),
+			Data:         b,
+			From:         []codejen.NamedJenny{p},
+		}, codejen.File{
+			RelativePath: path.Join(dir, "__init__.py"),
+			Data:         []byte(pythonHeader + "from .models import *  # noqa: F401,F403\n"),
+			From:         []codejen.NamedJenny{p},
+		})
+	}
+	files = append(files, codejen.File{
+		RelativePath: path.Join(ToPackageName(kind.Properties().MachineName), "__init__.py"),
+		Data:         []byte(pythonHeader),
+		From:         []codejen.NamedJenny{p},
+	})
+	return files, nil
+}
+
+// PythonPackage is a many-to-many jenny which generates the base classes, the requests-based client,
+// and the package __init__.py for the python models generated by PythonModels.
+type PythonPackage struct{}
+
+var _ codejen.ManyToMany[codegen.Kind] = &PythonPackage{}
+
+func (*PythonPackage) JennyName() string {
+	return "PythonPackage"
+}
+
+func (p *PythonPackage) Generate(kinds ...codegen.Kind) (codejen.Files, error) {
+	md := templates.PythonPackageMetadata{
+		KindVersions: make([]templates.PythonKindVersionMetadata, 0),
+	}
+	for _, kind := range kinds {
+		if md.Group == "" {
+			md.Group = kind.Properties().Group
+		}
+		for _, ver := range kind.Versions() {
+			md.KindVersions = append(md.KindVersions, templates.PythonKindVersionMetadata{
+				Package:  ToPackageName(kind.Properties().MachineName),
+				Version:  ToPackageName(ver.Version),
+				TypeName: exportField(sanitizeLabelString(kind.Name())),
+			})
+		}
+	}
+
+	base := bytes.Buffer{}
+	if err := templates.WritePythonBase(&base); err != nil {
+		return nil, err
+	}
+	client := bytes.Buffer{}
+	if err := templates.WritePythonClient(md, &client); err != nil {
+		return nil, err
+	}
+	init := bytes.Buffer{}
+	if err := templates.WritePythonInit(md, &init); err != nil {
+		return nil, err
+	}
+	return codejen.Files{{
+		RelativePath: "_base.py",
+		Data:         base.Bytes(),
+		From:         []codejen.NamedJenny{p},
+	}, {
+		RelativePath: "client.py",
+		Data:         client.Bytes(),
+		From:         []codejen.NamedJenny{p},
+	}, {
+		RelativePath: "__init__.py",
+		Data:         init.Bytes(),
+		From:         []codejen.NamedJenny{p},
+	}}, nil
+}
+
+func generatePythonModels(kind codegen.Kind, ver codegen.KindVersion) ([]byte, error) {
+	props, err := CUEToCRDOpenAPI(ver.Schema, kind.Name(), ver.Version)
+	if err != nil {
+		return nil, err
+	}
+	typeName := exportField(sanitizeLabelString(kind.Name()))
+	g := &pythonModelGenerator{
+		names: make(map[string]bool),
+	}
+	g.names[typeName] = true
+
+	// The kind model is written by hand rather than with writeClass, as it is a Resource with class variables
+	kindClass := &bytes.Buffer{}
+	fmt.Fprintf(kindClass, "@dataclass(kw_only=True)\nclass %s(Resource):\n", typeName)
+	fmt.Fprintf(kindClass, "    \"\"\"%s is the %s version of the %s kind.\"\"\"\n\n", typeName, ver.Version, kind.Properties().Kind)
+	fmt.Fprintf(kindClass, "    GROUP: ClassVar[str] = %s\n", pythonString(kind.Properties().Group))
+	fmt.Fprintf(kindClass, "    VERSION: ClassVar[str] = %s\n", pythonString(ver.Version))
+	fmt.Fprintf(kindClass, "    KIND: ClassVar[str] = %s\n", pythonString(kind.Properties().Kind))
+	fmt.Fprintf(kindClass, "    PLURAL: ClassVar[str] = %s\n", pythonString(kind.Properties().PluralMachineName))
+	fmt.Fprintf(kindClass, "    NAMESPACED: ClassVar[bool] = %s\n\n", pythonBool(kind.Properties().Scope != "Cluster"))
+	for _, name := range sortedKeys(props) {
+		prop, _ := props[name].(map[string]any)
+		fieldType := g.typeFor(prop, typeName+exportField(sanitizeLabelString(name)))
+		g.writeField(kindClass, name, fieldType, prop, name == "spec")
+	}
+	g.classes = append(g.classes, kindClass.String())
+	g.exports = append(g.exports, typeName)
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(pythonHeader)
+	buf.WriteString("from __future__ import annotations\n\n")
+	buf.WriteString("from dataclasses import dataclass, field\n")
+	buf.WriteString("from typing import Any, ClassVar, Dict, List, Literal, Optional\n\n")
+	buf.WriteString("from ..._base import Model, Resource\n\n")
+	sort.Strings(g.exports)
+	buf.WriteString("__all__ = [\n")
+	for _, e := range g.exports {
+		fmt.Fprintf(buf, "    %s,\n", pythonString(e))
+	}
+	buf.WriteString("]\n")
+	for _, c := range g.classes {
+		buf.WriteString("\n\n")
+		buf.WriteString(c)
+	}
+	return buf.Bytes(), nil
+}
+
+// pythonModelGenerator builds dataclass definitions from an OpenAPI schema.
+// Classes are appended to classes after the classes they depend on, so they can be written in order.
+type pythonModelGenerator struct {
+	classes []string
+	exports []string
+	names   map[string]bool
+}
+
+// typeFor returns the python type annotation for the schema, generating a class for it (named name) if it is an object
+func (g *pythonModelGenerator) typeFor(schema map[string]any, name string) string {
+	if schema == nil {
+		return "Any"
+	}
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		values := make([]string, 0, len(enum))
+		for _, v := range enum {
+			lit, ok := pythonLiteral(v)
+			if !ok {
+				return "Any"
+			}
+			values = append(values, lit)
+		}
+		return fmt.Sprintf("Literal[%s]", strings.Join(values, ", "))
+	}
+	parsed := parseOpenAPISchema(schema)
+	switch parsed.kind {
+	case SchemaKindString:
+		return "str"
+	case SchemaKindInteger:
+		return "int"
+	case SchemaKindNumber:
+		return "float"
+	case SchemaKindBoolean:
+		return "bool"
+	case SchemaKindArray:
+		return fmt.Sprintf("List[%s]", g.typeFor(parsed.elem, name))
+	case SchemaKindObject:
+		return g.writeClass(name, schema, parsed)
+	case SchemaKindMap:
+		return fmt.Sprintf("Dict[str, %s]", g.typeFor(parsed.elem, name))
+	case SchemaKindUntypedObject:
+		return "Dict[str, Any]"
+	default:
+		return "Any"
+	}
+}
+
+func (g *pythonModelGenerator) writeClass(name string, schema map[string]any, parsed openAPISchema) string {
+	className := name
+	for i := 2; g.names[className]; i++ {
+		className = fmt.Sprintf("%s%d", name, i)
+	}
+	g.names[className] = true
+
+	// Generate the field types first, so that the classes they depend on are written before this one
+	type pyField struct {
+		name     string
+		typ      string
+		schema   map[string]any
+		required bool
+	}
+	fields := make([]pyField, 0, len(parsed.properties))
+	for _, propName := range sortedKeys(parsed.properties) {
+		prop, _ := parsed.properties[propName].(map[string]any)
+		fields = append(fields, pyField{
+			name:     propName,
+			typ:      g.typeFor(prop, className+exportField(sanitizeLabelString(propName))),
+			schema:   prop,
+			required: parsed.required[propName],
+		})
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "@dataclass(kw_only=True)\nclass %s(Model):\n", className)
+	if desc, ok := schema["description"].(string); ok && desc != "" {
+		fmt.Fprintf(buf, "    \"\"\"%s\"\"\"\n\n", pythonDocstring(desc))
+	}
+	for _, f := range fields {
+		g.writeField(buf, f.name, f.typ, f.schema, f.required)
+	}
+	g.classes = append(g.classes, buf.String())
+	g.exports = append(g.exports, className)
+	return className
+}
+
+func (*pythonModelGenerator) writeField(buf *bytes.Buffer, jsonName, typ string, schema map[string]any, required bool) {
+	if desc, ok := schema["description"].(string); ok && desc != "" {
+		for _, line := range strings.Split(desc, "\n") {
+			fmt.Fprintf(buf, "    # %s\n", line)
+		}
+	}
+	pyName := pythonFieldName(jsonName)
+	metadata := ""
+	if pyName != jsonName {
+		metadata = fmt.Sprintf("metadata={\"json\": %s}", pythonString(jsonName))
+	}
+	defaultValue, hasDefault := "", false
+	if def, ok := schema["default"]; ok {
+		defaultValue, hasDefault = pythonLiteral(def)
+	}
+	switch {
+	case hasDefault:
+	case required:
+		if metadata == "" {
+			fmt.Fprintf(buf, "    %s: %s\n", pyName, typ)
+		} else {
+			fmt.Fprintf(buf, "    %s: %s = field(%s)\n", pyName, typ, metadata)
+		}
+		return
+	default:
+		typ = fmt.Sprintf("Optional[%s]", typ)
+		defaultValue = "None"
+	}
+	if metadata == "" {
+		fmt.Fprintf(buf, "    %s: %s = %s\n", pyName, typ, defaultValue)
+	} else {
+		fmt.Fprintf(buf, "    %s: %s = field(default=%s, %s)\n", pyName, typ, defaultValue, metadata)
+	}
+}
+
+var (
+	pythonFieldNameRegex  = regexp.MustCompile(`[^A-Za-z0-9_]`)
+	pythonCamelBoundary   = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+	pythonAcronymBoundary = regexp.MustCompile(`([A-Z]+)([A-Z][a-z])`)
+	pythonReservedNames   = map[string]bool{
+		"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true, "async": true,
+		"await": true, "break": true, "class": true, "continue": true, "def": true, "del": true, "elif": true,
+		"else": true, "except": true, "finally": true, "for": true, "from": true, "global": true, "if": true,
+		"import": true, "in": true, "is": true, "lambda": true, "nonlocal": true, "not": true, "or": true,
+		"pass": true, "raise": true, "return": true, "try": true, "while": true, "with": true, "yield": true,
+		// Names used in the generated modules, which a field would shadow in the class body
+		"bool": true, "dataclass": true, "field": true, "float": true, "int": true, "str": true,
+		// Methods of Model and Resource
+		"to_dict": true, "from_dict": true, "api_version": true,
+	}
+)
+
+// pythonFieldName converts a JSON field name into a snake_case python identifier
+func pythonFieldName(jsonName string) string {
+	name := pythonAcronymBoundary.ReplaceAllString(jsonName, "${1}_${2}")
+	name = pythonCamelBoundary.ReplaceAllString(name, "${1}_${2}")
+	name = strings.ToLower(pythonFieldNameRegex.ReplaceAllString(name, "_"))
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "f_" + name
+	}
+	if pythonReservedNames[name] {
+		name += "_"
+	}
+	return name
+}
+
+// pythonLiteral returns the python literal for a JSON scalar value, and false if the value is not a scalar
+func pythonLiteral(v any) (string, bool) {
+	switch cast := v.(type) {
+	case string:
+		return pythonString(cast), true
+	case bool:
+		return pythonBool(cast), true
+	case int, int64, float64, uint64:
+		return fmt.Sprintf("%v", cast), true
+	}
+	return "", false
+}
+
+func pythonString(s string) string {
+	// JSON string literals are valid python string literals
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func pythonBool(b bool) string {
+	if b {
+		return "True"
+	}
+	return "False"
+}
+
+func pythonDocstring(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `"""`, `\"\"\"`)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,203 @@
+package jennies
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grafana/codejen"
+
+	"github.com/grafana/grafana-app-sdk/codegen"
+)
+
+const (
+	defaultRBACNamespace      = "default"
+	defaultRBACServiceAccount = "operator"
+)
+
+// managedKindVerbs are the verbs the operator is granted for the kinds managed by the app
+var managedKindVerbs = []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+
+// eventsRule grants the operator permission to record Events (see operator.KubernetesEventRecorder),
+// which are created, and then patched when repeated events are aggregated
+var eventsRule = rbacPolicyRule{
+	APIGroups: []string{""},
+	Resources: []string{"events"},
+	Verbs:     []string{"create", "patch"},
+}
+
+// RBACGenerator generates the kubernetes RBAC objects for an app's operator: a role with permissions for
+// the kinds managed by the app and the kinds in the manifest's extra permissions, and to record Events,
+// and a binding of that role to the operator's ServiceAccount. The objects are written as a single kubernetes List.
+type RBACGenerator struct {
+	Encoder       ManifestOutputEncoder
+	FileExtension string
+	// Namespace is the namespace of the operator's ServiceAccount. Defaults to "default".
+	Namespace string
+	// ServiceAccount is the name of the operator's ServiceAccount. Defaults to "operator".
+	ServiceAccount string
+	// Namespaced generates a Role and RoleBinding in Namespace, for an operator which only watches its own namespace,
+	// rather than a ClusterRole and ClusterRoleBinding. Permissions for cluster-scoped kinds managed by the app
+	// are still granted with a ClusterRole, as a Role cannot grant them.
+	Namespaced bool
+}
+
+func (*RBACGenerator) JennyName() string {
+	return "RBACGenerator"
+}
+
+// Generate creates a single file with the RBAC objects for the provided AppManifest
+func (r *RBACGenerator) Generate(appManifest codegen.AppManifest) (codejen.Files, error) {
+	appName := appManifest.Properties().AppName
+	if appName == "" {
+		return nil, fmt.Errorf("app manifest must have an app name")
+	}
+	namespace := r.Namespace
+	if namespace == "" {
+		namespace = defaultRBACNamespace
+	}
+	serviceAccount := r.ServiceAccount
+	if serviceAccount == "" {
+		serviceAccount = defaultRBACServiceAccount
+	}
+
+	namespacedRules := make([]rbacPolicyRule, 0)
+	clusterRules := make([]rbacPolicyRule, 0)
+	for _, kind := range appManifest.Kinds() {
+		rule := rbacPolicyRule{
+			APIGroups: []string{kind.Properties().Group},
+			Resources: []string{kind.Properties().PluralMachineName, kind.Properties().PluralMachineName + "/status"},
+			Verbs:     managedKindVerbs,
+		}
+		if r.Namespaced && kind.Properties().Scope != "Cluster" {
+			namespacedRules = appendRBACRule(namespacedRules, rule)
+		} else {
+			clusterRules = appendRBACRule(clusterRules, rule)
+		}
+	}
+	// The scope of kinds which are not managed by the app is unknown, so the extra permissions are granted
+	// with the same role as the app's namespaced kinds
+	for _, perm := range appManifest.Properties().ExtraPermissions.AccessKinds {
+		verbs := make([]string, len(perm.Actions))
+		for i, action := range perm.Actions {
+			verbs[i] = strings.ToLower(action)
+		}
+		rule := rbacPolicyRule{
+			APIGroups: []string{perm.Group},
+			Resources: []string{perm.Resource},
+			Verbs:     verbs,
+		}
+		if r.Namespaced {
+			namespacedRules = appendRBACRule(namespacedRules, rule)
+		} else {
+			clusterRules = appendRBACRule(clusterRules, rule)
+		}
+	}
+	// Events for cluster-scoped objects are recorded in the default namespace, which a Role in Namespace may not cover
+	if r.Namespaced {
+		namespacedRules = appendRBACRule(namespacedRules, eventsRule)
+	}
+	if !r.Namespaced || len(clusterRules) > 0 {
+		clusterRules = appendRBACRule(clusterRules, eventsRule)
+	}
+
+	name := fmt.Sprintf("%s-operator", appName)
+	labels := map[string]string{
+		"app": appName,
+	}
+	subjects := []map[string]string{{
+		"kind":      "ServiceAccount",
+		"name":      serviceAccount,
+		"namespace": namespace,
+	}}
+	items := make([]map[string]any, 0, 4)
+	if r.Namespaced {
+		items = append(items, map[string]any{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       "Role",
+			"metadata": map[string]any{
+				"name":      name,
+				"namespace": namespace,
+				"labels":    labels,
+			},
+			"rules": namespacedRules,
+		}, map[string]any{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       "RoleBinding",
+			"metadata": map[string]any{
+				"name":      name,
+				"namespace": namespace,
+				"labels":    labels,
+			},
+			"roleRef": map[string]string{
+				"apiGroup": "rbac.authorization.k8s.io",
+				"kind":     "Role",
+				"name":     name,
+			},
+			"subjects": subjects,
+		})
+	}
+	if !r.Namespaced || len(clusterRules) > 0 {
+		items = append(items, map[string]any{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       "ClusterRole",
+			"metadata": map[string]any{
+				"name":   name,
+				"labels": labels,
+			},
+			"rules": clusterRules,
+		}, map[string]any{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       "ClusterRoleBinding",
+			"metadata": map[string]any{
+				"name":   name,
+				"labels": labels,
+			},
+			"roleRef": map[string]string{
+				"apiGroup": "rbac.authorization.k8s.io",
+				"kind":     "ClusterRole",
+				"name":     name,
+			},
+			"subjects": subjects,
+		})
+	}
+
+	out, err := r.Encoder(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codejen.Files{{
+		RelativePath: fmt.Sprintf("%s-rbac.%s", appName, r.FileExtension),
+		Data:         out,
+		From:         []codejen.NamedJenny{r},
+	}}, nil
+}
+
+// appendRBACRule appends rule to rules, merging it into an existing rule with the same API groups and verbs
+func appendRBACRule(rules []rbacPolicyRule, rule rbacPolicyRule) []rbacPolicyRule {
+	for i, existing := range rules {
+		if slices.Equal(existing.APIGroups, rule.APIGroups) && slices.Equal(existing.Verbs, rule.Verbs) {
+			for _, resource := range rule.Resources {
+				if !slices.Contains(existing.Resources, resource) {
+					rules[i].Resources = append(rules[i].Resources, resource)
+				}
+			}
+			return rules
+		}
+	}
+	return append(rules, rbacPolicyRule{
+		APIGroups: rule.APIGroups,
+		Resources: slices.Clone(rule.Resources),
+		Verbs:     rule.Verbs,
+	})
+}
+
+type rbacPolicyRule struct {
+	APIGroups []string `json:"apiGroups" yaml:"apiGroups"`
+	Resources []string `json:"resources" yaml:"resources"`
+	Verbs     []string `json:"verbs" yaml:"verbs"`
+}
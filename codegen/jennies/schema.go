@@ -3,6 +3,7 @@ package jennies
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"go/format"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"cuelang.org/go/cue"
 	"github.com/grafana/codejen"
 
+	"github.com/grafana/grafana-app-sdk/app"
 	"github.com/grafana/grafana-app-sdk/codegen"
 	"github.com/grafana/grafana-app-sdk/codegen/templates"
 	"github.com/grafana/grafana-app-sdk/resource"
@@ -49,6 +51,10 @@ func (s *SchemaGenerator) Generate(kind codegen.Kind) (codejen.Files, error) {
 		if err != nil {
 			return nil, err
 		}
+		oapi, err := s.getOpenAPISchema(&ver, meta.Kind)
+		if err != nil {
+			return nil, err
+		}
 		b := bytes.Buffer{}
 		err = templates.WriteSchema(templates.SchemaMetadata{
 			Package:          ToPackageName(ver.Version),
@@ -59,6 +65,10 @@ func (s *SchemaGenerator) Generate(kind codegen.Kind) (codejen.Files, error) {
 			Scope:            meta.Scope,
 			SelectableFields: sf,
 			FuncPrefix:       prefix,
+			OpenAPISchema:    oapi,
+			Maturity:         meta.Maturity,
+			Owner:            meta.Owner,
+			DocumentationURL: meta.DocumentationURL,
 		}, &b)
 		if err != nil {
 			return nil, err
@@ -77,6 +87,25 @@ func (s *SchemaGenerator) Generate(kind codegen.Kind) (codejen.Files, error) {
 	return files, nil
 }
 
+// getOpenAPISchema returns the OpenAPI schema for the version as a go string literal of the JSON-encoded app.VersionSchema,
+// so the generated code has the same schema as the manifest
+func (*SchemaGenerator) getOpenAPISchema(ver *codegen.KindVersion, kindName string) (string, error) {
+	crd, err := KindVersionToCRDSpecVersion(*ver, kindName, true)
+	if err != nil {
+		return "", err
+	}
+	vs, err := app.VersionSchemaFromMap(crd.Schema)
+	if err != nil {
+		return "", fmt.Errorf("version schema error: %w", err)
+	}
+	// Marshal the map (rather than the VersionSchema) for indentation, map keys are sorted so the output is deterministic
+	encoded, err := json.MarshalIndent(vs.AsMap(), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return goStringLiteral(encoded), nil
+}
+
 func (*SchemaGenerator) getSelectableFields(ver *codegen.KindVersion) ([]templates.SchemaMetadataSeletableField, error) {
 	fields := make([]templates.SchemaMetadataSeletableField, 0)
 	if len(ver.SelectableFields) == 0 {
@@ -104,11 +133,13 @@ func (*SchemaGenerator) getSelectableFields(ver *codegen.KindVersion) ([]templat
 				fields = append(fields, templates.SchemaMetadataSeletableField{
 					Field:    s,
 					Optional: false,
+					Type:     selectableFieldType(lookup),
 				})
 			} else if optional := val.LookupPath(cue.MakePath(cue.Str(field).Optional())); optional.Exists() {
 				fields = append(fields, templates.SchemaMetadataSeletableField{
 					Field:    s,
 					Optional: true,
+					Type:     selectableFieldType(optional),
 				})
 			} else {
 				return nil, fmt.Errorf("invalid selectable field path: %s", fieldPath)
@@ -117,3 +148,19 @@ func (*SchemaGenerator) getSelectableFields(ver *codegen.KindVersion) ([]templat
 	}
 	return fields, nil
 }
+
+// selectableFieldType returns the SchemaMetadataSeletableField Type for the CUE value of a selectable field
+func selectableFieldType(v cue.Value) string {
+	switch v.IncompleteKind() {
+	case cue.IntKind:
+		return templates.SelectableFieldTypeInteger
+	case cue.BoolKind:
+		return templates.SelectableFieldTypeBoolean
+	case cue.StringKind:
+		// Disjunctions of string literals are generated as named string types
+		if op, _ := v.Expr(); op == cue.OrOp {
+			return templates.SelectableFieldTypeEnum
+		}
+	}
+	return templates.SelectableFieldTypeString
+}
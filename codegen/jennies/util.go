@@ -3,6 +3,8 @@ package jennies
 import (
 	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/grafana/grafana-app-sdk/codegen"
 )
@@ -29,3 +31,11 @@ func GetGeneratedPath(groupByKind bool, kind codegen.Kind, version string) strin
 	}
 	return filepath.Join(ToPackageName(grp), ToPackageName(version))
 }
+
+// goStringLiteral returns b as a go string literal, using a raw string literal unless b contains a backtick
+func goStringLiteral(b []byte) string {
+	if strings.Contains(string(b), "`") {
+		return strconv.Quote(string(b))
+	}
+	return "`" + string(b) + "`"
+}
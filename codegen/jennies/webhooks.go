@@ -0,0 +1,234 @@
+package jennies
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grafana/codejen"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/codegen"
+	"github.com/grafana/grafana-app-sdk/k8s"
+)
+
+const (
+	defaultWebhookServicePort   = 443
+	defaultWebhookFailurePolicy = "Fail"
+	// certManagerInjectCAAnnotation is the annotation the cert-manager CA injector uses to set the caBundle of webhooks
+	certManagerInjectCAAnnotation = "cert-manager.io/inject-ca-from"
+)
+
+// WebhookClientOptions describe how the kubernetes API server reaches the operator's webhook server,
+// for the webhook configurations created by WebhookConfigurationGenerator.
+type WebhookClientOptions struct {
+	// Namespace is the namespace of the operator's Service. Defaults to "default".
+	Namespace string
+	// ServiceName is the name of the operator's Service. Defaults to "<app name>-operator".
+	ServiceName string
+	// ServicePort is the port of the operator's Service which serves webhooks. Defaults to 443.
+	ServicePort int32
+	// URL, if non-empty, is the base URL of the webhook server, which is used instead of the Service.
+	URL string
+	// Paths are the base paths the webhook server serves webhooks on, which must match the operator's
+	// RunnerWebhookConfig.Paths. Empty paths use the path from k8s.DefaultWebhookPaths.
+	Paths k8s.WebhookPaths
+	// CABundle is the PEM-encoded CA bundle the API server uses to verify the webhook server's certificate.
+	CABundle []byte
+	// CAInjectFrom is the "<namespace>/<certificate>" of a cert-manager Certificate, which the cert-manager CA injector
+	// uses to set the CA bundle of the webhooks. It can be used instead of CABundle.
+	CAInjectFrom string
+	// FailurePolicy is the policy for when a webhook call fails, either "Fail" or "Ignore". Defaults to "Fail".
+	FailurePolicy string
+}
+
+// WebhookConfigurationGenerator generates a ValidatingWebhookConfiguration and MutatingWebhookConfiguration for an app,
+// with a webhook for each kind version with validation, mutation, or defaulting capabilities.
+// Each webhook calls the per-kind path for its kind version (see k8s.WebhookPaths), so each can be configured separately.
+// The configurations are written as a single kubernetes List, which is empty if no kind versions have admission capabilities.
+type WebhookConfigurationGenerator struct {
+	Encoder       ManifestOutputEncoder
+	FileExtension string
+	Client        WebhookClientOptions
+}
+
+func (*WebhookConfigurationGenerator) JennyName() string {
+	return "WebhookConfigurationGenerator"
+}
+
+// Generate creates a single file with the webhook configurations for the provided AppManifest
+//
+//nolint:funlen
+func (g *WebhookConfigurationGenerator) Generate(appManifest codegen.AppManifest) (codejen.Files, error) {
+	appName := appManifest.Properties().AppName
+	if appName == "" {
+		return nil, fmt.Errorf("app manifest must have an app name")
+	}
+	failurePolicy := g.Client.FailurePolicy
+	if failurePolicy == "" {
+		failurePolicy = defaultWebhookFailurePolicy
+	}
+	if failurePolicy != "Fail" && failurePolicy != "Ignore" {
+		return nil, fmt.Errorf("failure policy must be one of 'Fail'|'Ignore'")
+	}
+	if len(g.Client.CABundle) > 0 && g.Client.CAInjectFrom != "" {
+		return nil, fmt.Errorf("only one of a CA bundle or a cert-manager certificate to inject the CA from may be provided")
+	}
+	// buildManifestData resolves the admission operations of each version, including the validation of config kinds
+	manifestData, err := buildManifestData(appManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	validating := make([]map[string]any, 0)
+	mutating := make([]map[string]any, 0)
+	for i, kind := range appManifest.Kinds() {
+		props := kind.Properties()
+		for _, version := range manifestData.Kinds[i].Versions {
+			if version.Admission == nil {
+				continue
+			}
+			name := fmt.Sprintf("%s.%s.%s", version.Name, props.PluralMachineName, props.Group)
+			if version.Admission.Validation != nil {
+				validating = append(validating, g.webhook(
+					name, g.Client.Paths.ValidatePath(props.Group, version.Name, props.PluralMachineName), appName, failurePolicy,
+					webhookRule(props, version.Name, version.Admission.Validation.Operations)))
+			}
+			operations := make([]app.AdmissionOperation, 0)
+			if version.Admission.Defaulting != nil {
+				// Defaulting is served by the mutating webhook
+				operations = appendAdmissionOperations(operations, version.Admission.Defaulting.Operations)
+			}
+			if version.Admission.Mutation != nil {
+				operations = appendAdmissionOperations(operations, version.Admission.Mutation.Operations)
+			}
+			if len(operations) > 0 {
+				mutating = append(mutating, g.webhook(
+					name, g.Client.Paths.MutatePath(props.Group, version.Name, props.PluralMachineName), appName, failurePolicy,
+					webhookRule(props, version.Name, operations)))
+			}
+		}
+	}
+
+	metadata := func(name string) map[string]any {
+		md := map[string]any{
+			"name": name,
+			"labels": map[string]string{
+				"app": appName,
+			},
+		}
+		if g.Client.CAInjectFrom != "" {
+			md["annotations"] = map[string]string{
+				certManagerInjectCAAnnotation: g.Client.CAInjectFrom,
+			}
+		}
+		return md
+	}
+	items := make([]map[string]any, 0, 2)
+	if len(validating) > 0 {
+		items = append(items, map[string]any{
+			"apiVersion": "admissionregistration.k8s.io/v1",
+			"kind":       "ValidatingWebhookConfiguration",
+			"metadata":   metadata(fmt.Sprintf("%s-operator-val-webhook", appName)),
+			"webhooks":   validating,
+		})
+	}
+	if len(mutating) > 0 {
+		items = append(items, map[string]any{
+			"apiVersion": "admissionregistration.k8s.io/v1",
+			"kind":       "MutatingWebhookConfiguration",
+			"metadata":   metadata(fmt.Sprintf("%s-operator-mut-webhook", appName)),
+			"webhooks":   mutating,
+		})
+	}
+
+	out, err := g.Encoder(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codejen.Files{{
+		RelativePath: fmt.Sprintf("%s-webhooks.%s", appName, g.FileExtension),
+		Data:         out,
+		From:         []codejen.NamedJenny{g},
+	}}, nil
+}
+
+// webhook returns a webhook which calls path on the webhook server for requests matching rule
+func (g *WebhookConfigurationGenerator) webhook(name, path, appName, failurePolicy string, rule map[string]any) map[string]any {
+	clientConfig := make(map[string]any)
+	if g.Client.URL != "" {
+		clientConfig["url"] = strings.TrimSuffix(g.Client.URL, "/") + path
+	} else {
+		serviceName := g.Client.ServiceName
+		if serviceName == "" {
+			serviceName = fmt.Sprintf("%s-operator", appName)
+		}
+		namespace := g.Client.Namespace
+		if namespace == "" {
+			namespace = defaultRBACNamespace
+		}
+		port := g.Client.ServicePort
+		if port == 0 {
+			port = defaultWebhookServicePort
+		}
+		clientConfig["service"] = map[string]any{
+			"name":      serviceName,
+			"namespace": namespace,
+			"path":      path,
+			"port":      port,
+		}
+	}
+	if len(g.Client.CABundle) > 0 {
+		clientConfig["caBundle"] = base64.StdEncoding.EncodeToString(g.Client.CABundle)
+	}
+	return map[string]any{
+		"name":                    name,
+		"admissionReviewVersions": []string{"v1", "v1beta1"},
+		"clientConfig":            clientConfig,
+		"failurePolicy":           failurePolicy,
+		// Requests are routed by kind version, so they must not be converted to another version of the kind
+		"matchPolicy": "Exact",
+		"rules":       []map[string]any{rule},
+		"sideEffects": "None",
+	}
+}
+
+// webhookRule returns the rule for admission requests with operations for the version of the kind
+func webhookRule(props codegen.KindProperties, version string, operations []app.AdmissionOperation) map[string]any {
+	ops := make([]string, len(operations))
+	for i, op := range operations {
+		ops[i] = string(op)
+	}
+	scope := props.Scope
+	if scope == "" {
+		scope = "Namespaced"
+	}
+	return map[string]any{
+		"apiGroups":   []string{props.Group},
+		"apiVersions": []string{version},
+		"operations":  ops,
+		"resources":   []string{props.PluralMachineName},
+		"scope":       scope,
+	}
+}
+
+// appendAdmissionOperations appends each operation in add which isn't already in operations
+func appendAdmissionOperations(operations, add []app.AdmissionOperation) []app.AdmissionOperation {
+	for _, op := range add {
+		if slices.Contains(operations, app.AdmissionOperationAny) {
+			return operations
+		}
+		if op == app.AdmissionOperationAny {
+			return []app.AdmissionOperation{app.AdmissionOperationAny}
+		}
+		if !slices.Contains(operations, op) {
+			operations = append(operations, op)
+		}
+	}
+	return operations
+}
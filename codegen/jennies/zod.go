@@ -0,0 +1,286 @@
+package jennies
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/grafana/codejen"
+
+	"github.com/grafana/grafana-app-sdk/codegen"
+)
+
+const typeScriptHeader = "/*\n * This file was generated by grafana-app-sdk. DO NOT EDIT.\n */\n"
+
+// TypeScriptZodValidators is a one-to-many jenny which generates a TypeScript file of zod schemas for each
+// version of a kind where codegen.frontend is true. The schemas are generated from the same OpenAPI schema
+// as the kind's CRD (with references expanded), so they validate objects the same way as the API server.
+// The file is placed next to the types generated by TypeScriptTypes and TypeScriptResourceTypes.
+type TypeScriptZodValidators struct{}
+
+var _ codejen.OneToMany[codegen.Kind] = &TypeScriptZodValidators{}
+
+func (*TypeScriptZodValidators) JennyName() string {
+	return "TypeScriptZodValidators"
+}
+
+func (z *TypeScriptZodValidators) Generate(kind codegen.Kind) (codejen.Files, error) {
+	files := make(codejen.Files, 0)
+	for _, ver := range kind.Versions() {
+		if !ver.Codegen.Frontend {
+			continue
+		}
+		b, err := generateZodSchemas(kind, ver)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate zod schemas for %s %s: %w", kind.Name(), ver.Version, err)
+		}
+		files = append(files, codejen.File{
+			RelativePath: path.Join(kind.Properties().MachineName, ver.Version, fmt.Sprintf("%s_validators_gen.ts", kind.Properties().MachineName)),
+			Data:         b,
+			From:         []codejen.NamedJenny{z},
+		})
+	}
+	return files, nil
+}
+
+func generateZodSchemas(kind codegen.Kind, ver codegen.KindVersion) ([]byte, error) {
+	props, err := CUEToCRDOpenAPI(ver.Schema, kind.Name(), ver.Version)
+	if err != nil {
+		return nil, err
+	}
+	typeName := exportField(sanitizeLabelString(kind.Name()))
+	g := &zodSchemaGenerator{
+		names: make(map[string]bool),
+	}
+	g.names[typeName+"Schema"] = true
+	g.names[typeName+"MetadataSchema"] = true
+
+	// The kind schema is written by hand, as its apiVersion, kind, and metadata are not part of the OpenAPI schema
+	kindSchema := &bytes.Buffer{}
+	fmt.Fprintf(kindSchema, "// %sMetadataSchema validates the metadata of a %s. Fields other than name and namespace are not validated.\n", typeName, kind.Properties().Kind)
+	fmt.Fprintf(kindSchema, "export const %sMetadataSchema = z.object({\n", typeName)
+	kindSchema.WriteString("    name: z.string(),\n")
+	if kind.Properties().Scope == "Cluster" {
+		kindSchema.WriteString("    namespace: z.string().optional(),\n")
+	} else {
+		kindSchema.WriteString("    namespace: z.string(),\n")
+	}
+	kindSchema.WriteString("}).passthrough();\n\n")
+	fmt.Fprintf(kindSchema, "// %sSchema validates a %s %s object.\n", typeName, kind.Properties().Kind, ver.Version)
+	fmt.Fprintf(kindSchema, "export const %sSchema = z.object({\n", typeName)
+	fmt.Fprintf(kindSchema, "    apiVersion: z.literal(%s),\n", typeScriptString(kind.Properties().Group+"/"+ver.Version))
+	fmt.Fprintf(kindSchema, "    kind: z.literal(%s),\n", typeScriptString(kind.Properties().Kind))
+	fmt.Fprintf(kindSchema, "    metadata: %sMetadataSchema,\n", typeName)
+	for _, name := range sortedKeys(props) {
+		prop, _ := props[name].(map[string]any)
+		expr := g.schemaFor(prop, typeName+exportField(sanitizeLabelString(name)))
+		g.writeField(kindSchema, name, expr, prop, name == "spec")
+	}
+	kindSchema.WriteString("});\n")
+	g.schemas = append(g.schemas, kindSchema.String())
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(typeScriptHeader)
+	buf.WriteString("import { z } from 'zod';\n")
+	for _, s := range g.schemas {
+		buf.WriteString("\n")
+		buf.WriteString(s)
+	}
+	return buf.Bytes(), nil
+}
+
+// zodSchemaGenerator builds zod schemas from an OpenAPI schema.
+// Named object schemas are appended to schemas after the schemas they depend on, so they can be written in order.
+type zodSchemaGenerator struct {
+	schemas []string
+	names   map[string]bool
+}
+
+// schemaFor returns the zod expression for the schema, generating a named schema for it (named name+"Schema")
+// if it is an object with properties
+func (g *zodSchemaGenerator) schemaFor(schema map[string]any, name string) string {
+	if schema == nil {
+		return "z.any()"
+	}
+	expr := g.baseSchemaFor(schema, name)
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		expr += ".nullable()"
+	}
+	return expr
+}
+
+//nolint:gocyclo
+func (g *zodSchemaGenerator) baseSchemaFor(schema map[string]any, name string) string {
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		values := make([]string, 0, len(enum))
+		allStrings := true
+		for _, v := range enum {
+			lit, ok := typeScriptLiteral(v)
+			if !ok {
+				return "z.any()"
+			}
+			if _, ok := v.(string); !ok {
+				allStrings = false
+			}
+			values = append(values, lit)
+		}
+		if allStrings {
+			return fmt.Sprintf("z.enum([%s])", strings.Join(values, ", "))
+		}
+		if len(values) == 1 {
+			return fmt.Sprintf("z.literal(%s)", values[0])
+		}
+		literals := make([]string, 0, len(values))
+		for _, v := range values {
+			literals = append(literals, fmt.Sprintf("z.literal(%s)", v))
+		}
+		return fmt.Sprintf("z.union([%s])", strings.Join(literals, ", "))
+	}
+	parsed := parseOpenAPISchema(schema)
+	switch parsed.kind {
+	case SchemaKindString:
+		expr := "z.string()"
+		if v, ok := schema["minLength"]; ok {
+			expr += fmt.Sprintf(".min(%v)", v)
+		}
+		if v, ok := schema["maxLength"]; ok {
+			expr += fmt.Sprintf(".max(%v)", v)
+		}
+		if v, ok := schema["pattern"].(string); ok {
+			expr += fmt.Sprintf(".regex(new RegExp(%s))", typeScriptString(v))
+		}
+		return expr
+	case SchemaKindInteger, SchemaKindNumber:
+		expr := "z.number()"
+		if parsed.kind == SchemaKindInteger {
+			expr += ".int()"
+		}
+		if v, ok := schema["minimum"]; ok {
+			if exclusive, _ := schema["exclusiveMinimum"].(bool); exclusive {
+				expr += fmt.Sprintf(".gt(%v)", v)
+			} else {
+				expr += fmt.Sprintf(".gte(%v)", v)
+			}
+		}
+		if v, ok := schema["maximum"]; ok {
+			if exclusive, _ := schema["exclusiveMaximum"].(bool); exclusive {
+				expr += fmt.Sprintf(".lt(%v)", v)
+			} else {
+				expr += fmt.Sprintf(".lte(%v)", v)
+			}
+		}
+		return expr
+	case SchemaKindBoolean:
+		return "z.boolean()"
+	case SchemaKindArray:
+		expr := fmt.Sprintf("z.array(%s)", g.schemaFor(parsed.elem, name))
+		if v, ok := schema["minItems"]; ok {
+			expr += fmt.Sprintf(".min(%v)", v)
+		}
+		if v, ok := schema["maxItems"]; ok {
+			expr += fmt.Sprintf(".max(%v)", v)
+		}
+		return expr
+	case SchemaKindObject:
+		return g.writeObject(name, schema, parsed)
+	case SchemaKindMap:
+		return fmt.Sprintf("z.record(z.string(), %s)", g.schemaFor(parsed.elem, name))
+	case SchemaKindUntypedObject:
+		return "z.record(z.string(), z.any())"
+	default:
+		return "z.any()"
+	}
+}
+
+func (g *zodSchemaGenerator) writeObject(name string, schema map[string]any, parsed openAPISchema) string {
+	schemaName := name + "Schema"
+	for i := 2; g.names[schemaName]; i++ {
+		schemaName = fmt.Sprintf("%s%dSchema", name, i)
+	}
+	g.names[schemaName] = true
+
+	// Generate the field schemas first, so that the schemas they depend on are written before this one
+	type zodField struct {
+		name     string
+		expr     string
+		schema   map[string]any
+		required bool
+	}
+	fields := make([]zodField, 0, len(parsed.properties))
+	for _, propName := range sortedKeys(parsed.properties) {
+		prop, _ := parsed.properties[propName].(map[string]any)
+		fields = append(fields, zodField{
+			name:     propName,
+			expr:     g.schemaFor(prop, strings.TrimSuffix(schemaName, "Schema")+exportField(sanitizeLabelString(propName))),
+			schema:   prop,
+			required: parsed.required[propName],
+		})
+	}
+
+	buf := &bytes.Buffer{}
+	if desc, ok := schema["description"].(string); ok && desc != "" {
+		for _, line := range strings.Split(desc, "\n") {
+			fmt.Fprintf(buf, "// %s\n", line)
+		}
+	}
+	fmt.Fprintf(buf, "export const %s = z.object({\n", schemaName)
+	for _, f := range fields {
+		g.writeField(buf, f.name, f.expr, f.schema, f.required)
+	}
+	buf.WriteString("});\n")
+	g.schemas = append(g.schemas, buf.String())
+	return schemaName
+}
+
+func (*zodSchemaGenerator) writeField(buf *bytes.Buffer, jsonName, expr string, schema map[string]any, required bool) {
+	if desc, ok := schema["description"].(string); ok && desc != "" {
+		for _, line := range strings.Split(desc, "\n") {
+			fmt.Fprintf(buf, "    // %s\n", line)
+		}
+	}
+	if def, ok := schema["default"]; ok {
+		if lit, ok := typeScriptLiteral(def); ok {
+			expr += fmt.Sprintf(".default(%s)", lit)
+		} else if !required {
+			expr += ".optional()"
+		}
+	} else if !required {
+		expr += ".optional()"
+	}
+	fmt.Fprintf(buf, "    %s: %s,\n", typeScriptPropertyName(jsonName), expr)
+}
+
+// typeScriptLiteral returns the TypeScript literal for a JSON scalar value, and false if the value is not a scalar
+func typeScriptLiteral(v any) (string, bool) {
+	switch cast := v.(type) {
+	case string:
+		return typeScriptString(cast), true
+	case bool:
+		return fmt.Sprintf("%t", cast), true
+	case int, int64, float64, uint64:
+		return fmt.Sprintf("%v", cast), true
+	}
+	return "", false
+}
+
+func typeScriptString(s string) string {
+	// JSON string literals are valid TypeScript string literals
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// typeScriptPropertyName returns jsonName as an object literal property name, quoting it if it is not an identifier
+func typeScriptPropertyName(jsonName string) string {
+	for i, r := range jsonName {
+		if r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return typeScriptString(jsonName)
+	}
+	if jsonName == "" {
+		return `""`
+	}
+	return jsonName
+}
@@ -32,10 +32,29 @@ type KindProperties struct {
 	Scope                  string                      `json:"scope"`
 	Validation             KindAdmissionCapability     `json:"validation"`
 	Mutation               KindAdmissionCapability     `json:"mutation"`
+	Defaulting             KindAdmissionCapability     `json:"defaulting"`
 	Conversion             bool                        `json:"conversion"`
 	ConversionWebhookProps ConversionWebhookProperties `json:"conversionWebhookProps"`
 	// Codegen contains code-generation directives for the codegen pipeline
 	Codegen KindCodegenProperties `json:"codegen"`
+	// ShortNames is a list of short names for the kind, used by clients such as kubectl
+	ShortNames []string `json:"shortNames"`
+	// Categories is a list of grouped resources the kind belongs to, used by clients such as kubectl
+	Categories []string `json:"categories"`
+	// Maturity is the maturity level of the kind ("alpha", "beta", or "stable"), or empty if unspecified
+	Maturity string `json:"maturity"`
+	// Owner is the team which owns the kind
+	Owner string `json:"owner"`
+	// DocumentationURL is a link to the documentation of the kind
+	DocumentationURL string `json:"documentationURL"`
+	// ConfigKind indicates that the kind is a cluster-scoped singleton used to store the app's runtime configuration
+	ConfigKind bool `json:"configKind"`
+	// ReportsProgress indicates that the kind's status contains a progress block written by its operator
+	ReportsProgress bool `json:"reportsProgress"`
+	// ReportsConditions indicates that the kind's status contains a list of conditions written by its operator
+	ReportsConditions bool `json:"reportsConditions"`
+	// SizeLimits are the default size limits for objects of each version of the kind
+	SizeLimits KindSizeLimits `json:"sizeLimits"`
 }
 
 type ConversionWebhookProperties struct {
@@ -62,6 +81,35 @@ type KindCodegenProperties struct {
 	Backend  bool `json:"backend"`
 }
 
+// KindSizeLimits are limits on the size of objects of a kind version, enforced in validating admission
+type KindSizeLimits struct {
+	MaxBytes  int64            `json:"maxBytes,omitempty"`
+	MaxItems  map[string]int64 `json:"maxItems,omitempty"`
+	MaxLength map[string]int64 `json:"maxLength,omitempty"`
+}
+
+// IsEmpty returns true if no limits are set
+func (l KindSizeLimits) IsEmpty() bool {
+	return l.MaxBytes == 0 && len(l.MaxItems) == 0 && len(l.MaxLength) == 0
+}
+
+// KindCustomRoute is a custom route of a kind version, served at a subresource path of objects of the kind
+type KindCustomRoute struct {
+	// Name is the name of the route, which its generated types are named after. If empty, it is derived from the method and path.
+	Name string `json:"name,omitempty"`
+	// Request is the CUE schema of the JSON request body, which does not exist if the request body is untyped
+	Request cue.Value `json:"-"`
+	// Response is the CUE schema of the JSON response body, which does not exist if the response body is untyped
+	Response cue.Value `json:"-"`
+}
+
+// KindScale maps the replicas of the scale subresource of a kind version to fields of its objects
+type KindScale struct {
+	SpecReplicasPath   string `json:"specReplicasPath"`
+	StatusReplicasPath string `json:"statusReplicasPath"`
+	LabelSelectorPath  string `json:"labelSelectorPath,omitempty"`
+}
+
 type AdditionalPrinterColumn struct {
 	Name        string  `json:"name"`
 	Type        string  `json:"type"`
@@ -81,7 +129,12 @@ type KindVersion struct {
 	SelectableFields         []string                  `json:"selectableFields"`
 	Validation               KindAdmissionCapability   `json:"validation"`
 	Mutation                 KindAdmissionCapability   `json:"mutation"`
+	Defaulting               KindAdmissionCapability   `json:"defaulting"`
 	AdditionalPrinterColumns []AdditionalPrinterColumn `json:"additionalPrinterColumns"`
+	SizeLimits               KindSizeLimits            `json:"sizeLimits"`
+	Scale                    *KindScale                `json:"scale,omitempty"`
+	// CustomRoutes are the custom routes of the version, as a map of subresource path -> HTTP method -> route
+	CustomRoutes map[string]map[string]KindCustomRoute `json:"customRoutes,omitempty"`
 }
 
 // AnyKind is a simple implementation of Kind
@@ -21,6 +21,7 @@ type AppManifestKindPermission struct {
 	Group    string   `json:"group"`
 	Resource string   `json:"resource"`
 	Actions  []string `json:"actions"`
+	Required bool     `json:"required"`
 }
 
 type SimpleManifest struct {
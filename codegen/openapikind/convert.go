@@ -0,0 +1,761 @@
+package openapikind
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/format"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana-app-sdk/codegen/jennies"
+)
+
+// manifestField is the field of the generated CUE which holds the converted manifest
+const manifestField = "manifest"
+
+// converter converts a YAML or JSON manifest, and the OpenAPI schemas of its kinds, into CUE source
+type converter struct {
+	files   fs.FS
+	loaders map[string]DocumentLoader
+	docs    map[string]*yaml.Node
+	imports map[string]bool
+}
+
+func newConverter(files fs.FS, loaders map[string]DocumentLoader) *converter {
+	return &converter{
+		files:   files,
+		loaders: loaders,
+		docs:    make(map[string]*yaml.Node),
+		imports: make(map[string]bool),
+	}
+}
+
+// document returns the parsed contents of the file at filePath, loading it only once.
+// Files are loaded with the DocumentLoader registered for their extension, if there is one.
+func (c *converter) document(filePath string) (*yaml.Node, error) {
+	if doc, ok := c.docs[filePath]; ok {
+		return doc, nil
+	}
+	load := loadDocument
+	if loader, ok := c.loaders[path.Ext(filePath)]; ok {
+		load = loader
+	}
+	doc, err := load(c.files, filePath)
+	if err != nil {
+		return nil, err
+	}
+	c.docs[filePath] = doc
+	return doc, nil
+}
+
+// convertManifest returns CUE source where the manifestField contains the manifest at manifestPath
+func (c *converter) convertManifest(manifestPath string) (string, error) {
+	root, err := c.document(manifestPath)
+	if err != nil {
+		return "", err
+	}
+	if root.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("manifest must be an object")
+	}
+	fields, kinds := splitField(root, "kinds")
+	props, err := jsonValue(fields)
+	if err != nil {
+		return "", err
+	}
+	body := &bytes.Buffer{}
+	fmt.Fprintf(body, "%s: %s\n", manifestField, props)
+	fmt.Fprintf(body, "%s: kinds: [\n", manifestField)
+	if kinds != nil {
+		if kinds.Kind != yaml.SequenceNode {
+			return "", fmt.Errorf("kinds must be a list")
+		}
+		for i, kind := range kinds.Content {
+			expr, err := c.convertKind(manifestPath, resolveAlias(kind))
+			if err != nil {
+				return "", fmt.Errorf("kinds[%d]: %w", i, err)
+			}
+			fmt.Fprintf(body, "%s,\n", expr)
+		}
+	}
+	body.WriteString("]\n")
+
+	src := &bytes.Buffer{}
+	imports := make([]string, 0, len(c.imports))
+	for imp := range c.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	for _, imp := range imports {
+		fmt.Fprintf(src, "import %q\n", imp)
+	}
+	src.WriteString("\n")
+	src.Write(body.Bytes())
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return "", err
+	}
+	return string(formatted), nil
+}
+
+func (c *converter) convertKind(doc string, kind *yaml.Node) (string, error) {
+	if kind.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("kind must be an object")
+	}
+	fields, versions := splitField(kind, "versions")
+	props, err := jsonValue(fields)
+	if err != nil {
+		return "", err
+	}
+	if versions == nil {
+		return props, nil
+	}
+	if versions.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("versions must be an object")
+	}
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%s & {\nversions: {\n", props)
+	for i := 0; i+1 < len(versions.Content); i += 2 {
+		name := versions.Content[i].Value
+		expr, err := c.convertVersion(doc, resolveAlias(versions.Content[i+1]))
+		if err != nil {
+			return "", fmt.Errorf("version %s: %w", name, err)
+		}
+		fmt.Fprintf(buf, "%s: %s\n", cueString(name), expr)
+	}
+	buf.WriteString("}\n}")
+	return buf.String(), nil
+}
+
+func (c *converter) convertVersion(doc string, version *yaml.Node) (string, error) {
+	if version.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("version must be an object")
+	}
+	fields, schema := splitField(version, "schema")
+	props, err := jsonValue(fields)
+	if err != nil {
+		return "", err
+	}
+	if schema == nil {
+		return props, nil
+	}
+	if schema.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("schema must be an object of top-level fields to OpenAPI schemas")
+	}
+	s := &schemaConverter{
+		converter: c,
+		refs:      make(map[string]string),
+		names:     make(map[string]bool),
+		resolving: make(map[string]bool),
+	}
+	// Top-level fields are written as required fields, the same way they are declared in a CUE kind
+	fieldsBuf := &bytes.Buffer{}
+	for i := 0; i+1 < len(schema.Content); i += 2 {
+		name := schema.Content[i].Value
+		field := resolveAlias(schema.Content[i+1])
+		expr, err := s.topLevelExpr(doc, field)
+		if err != nil {
+			return "", fmt.Errorf("schema.%s: %w", name, err)
+		}
+		writeComment(fieldsBuf, stringField(field, "description"))
+		fmt.Fprintf(fieldsBuf, "%s: %s\n", cueLabel(name), expr)
+	}
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%s & {\nschema: {\n", props)
+	for _, def := range s.defs {
+		buf.WriteString(def)
+	}
+	buf.Write(fieldsBuf.Bytes())
+	buf.WriteString("}\n}")
+	return buf.String(), nil
+}
+
+// schemaConverter converts the OpenAPI schemas of a single kind version into CUE expressions.
+// Each referenced schema is converted once, into a definition in the version's schema.
+type schemaConverter struct {
+	*converter
+	// refs maps a resolved reference (<file>#<pointer>) to the name of its definition
+	refs map[string]string
+	// names contains the definition names in use
+	names map[string]bool
+	// resolving contains the references which are being converted, to detect recursive references
+	resolving map[string]bool
+	// defs are the converted definitions, in an order where each definition follows those it depends on
+	defs []string
+}
+
+// expr returns the CUE expression for the OpenAPI schema n, located in the file doc
+//
+//nolint:gocyclo
+func (s *schemaConverter) expr(doc string, n *yaml.Node) (string, error) {
+	n = resolveAlias(n)
+	if n.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("schema must be an object")
+	}
+	// As in OpenAPI 3.0, the siblings of a $ref are ignored
+	if ref := stringField(n, "$ref"); ref != "" {
+		return s.ref(doc, ref)
+	}
+
+	conjuncts := make([]string, 0)
+	nullable := boolField(n, "nullable")
+	hasDefault := false
+	if enum := field(n, "enum"); enum != nil && enum.Kind == yaml.SequenceNode {
+		def := ""
+		if d := field(n, "default"); d != nil {
+			def, _ = literal(d)
+		}
+		values := make([]string, 0, len(enum.Content))
+		for _, v := range enum.Content {
+			lit, err := literal(v)
+			if err != nil {
+				return "", fmt.Errorf("invalid enum value: %w", err)
+			}
+			if lit == "null" {
+				nullable = true
+				continue
+			}
+			if lit == def && !hasDefault {
+				hasDefault = true
+				lit = "*" + lit
+			}
+			values = append(values, lit)
+		}
+		conjuncts = append(conjuncts, strings.Join(values, " | "))
+	} else {
+		base, isNullable, err := s.typeExpr(doc, n)
+		if err != nil {
+			return "", err
+		}
+		nullable = nullable || isNullable
+		if base != "" {
+			conjuncts = append(conjuncts, base)
+		}
+	}
+	if allOf := field(n, "allOf"); allOf != nil && allOf.Kind == yaml.SequenceNode {
+		for i, sub := range allOf.Content {
+			expr, err := s.expr(doc, sub)
+			if err != nil {
+				return "", fmt.Errorf("allOf[%d]: %w", i, err)
+			}
+			conjuncts = append(conjuncts, parenthesize(expr))
+		}
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		alternatives := field(n, key)
+		if alternatives == nil || alternatives.Kind != yaml.SequenceNode {
+			continue
+		}
+		exprs := make([]string, 0, len(alternatives.Content))
+		for i, sub := range alternatives.Content {
+			expr, err := s.expr(doc, sub)
+			if err != nil {
+				return "", fmt.Errorf("%s[%d]: %w", key, i, err)
+			}
+			exprs = append(exprs, expr)
+		}
+		conjuncts = append(conjuncts, "("+strings.Join(exprs, " | ")+")")
+	}
+
+	expr := "_"
+	if len(conjuncts) == 1 {
+		expr = conjuncts[0]
+	} else if len(conjuncts) > 1 {
+		for i, c := range conjuncts {
+			conjuncts[i] = parenthesize(c)
+		}
+		expr = strings.Join(conjuncts, " & ")
+	}
+	if d := field(n, "default"); d != nil && !hasDefault {
+		lit, err := literal(d)
+		if err != nil {
+			return "", fmt.Errorf("invalid default: %w", err)
+		}
+		expr = "*" + lit + " | " + expr
+	}
+	if nullable {
+		expr += " | null"
+	}
+	return expr, nil
+}
+
+// typeExpr returns the CUE expression for the type and type-specific constraints of n,
+// and whether the list of types of n (in OpenAPI 3.1 style) includes "null"
+func (s *schemaConverter) typeExpr(doc string, n *yaml.Node) (string, bool, error) {
+	types := make([]string, 0)
+	nullable := false
+	if t := field(n, "type"); t != nil {
+		switch t.Kind {
+		case yaml.ScalarNode:
+			types = append(types, t.Value)
+		case yaml.SequenceNode:
+			for _, v := range t.Content {
+				if v.Value == "null" {
+					nullable = true
+					continue
+				}
+				types = append(types, v.Value)
+			}
+		default:
+			return "", false, fmt.Errorf("type must be a string or list of strings")
+		}
+	} else if jennies.SchemaKindOf("", field(n, "properties") != nil, field(n, "additionalProperties") != nil) != jennies.SchemaKindAny {
+		types = append(types, "object")
+	}
+	exprs := make([]string, 0, len(types))
+	for _, t := range types {
+		expr, err := s.singleTypeExpr(doc, n, t)
+		if err != nil {
+			return "", false, err
+		}
+		exprs = append(exprs, expr)
+	}
+	if len(exprs) > 1 {
+		return "(" + strings.Join(exprs, " | ") + ")", nullable, nil
+	}
+	return strings.Join(exprs, ""), nullable, nil
+}
+
+//nolint:gocyclo
+func (s *schemaConverter) singleTypeExpr(doc string, n *yaml.Node, t string) (string, error) {
+	parts := make([]string, 0)
+	kind := jennies.SchemaKindOf(t, field(n, "properties") != nil, field(n, "additionalProperties") != nil)
+	switch kind {
+	case jennies.SchemaKindString:
+		parts = append(parts, "string")
+		if stringField(n, "format") == "date-time" {
+			s.imports["time"] = true
+			parts = append(parts, "time.Time")
+		}
+		if pattern := stringField(n, "pattern"); pattern != "" {
+			parts = append(parts, "=~"+cueString(pattern))
+		}
+		if v := field(n, "minLength"); v != nil {
+			s.imports["strings"] = true
+			parts = append(parts, fmt.Sprintf("strings.MinRunes(%s)", v.Value))
+		}
+		if v := field(n, "maxLength"); v != nil {
+			s.imports["strings"] = true
+			parts = append(parts, fmt.Sprintf("strings.MaxRunes(%s)", v.Value))
+		}
+	case jennies.SchemaKindInteger, jennies.SchemaKindNumber:
+		switch format := stringField(n, "format"); {
+		case field(n, "default") != nil:
+			// CUE's OpenAPI encoder cannot encode a default for a sized or bounded number,
+			// so the format and bounds are dropped from numbers with a default
+			if kind == jennies.SchemaKindInteger {
+				parts = append(parts, "int")
+			} else {
+				parts = append(parts, "number")
+			}
+			return parts[0], nil
+		case kind == jennies.SchemaKindInteger && (format == "int32" || format == "int64" || format == "uint32" || format == "uint64"):
+			parts = append(parts, format)
+		case kind == jennies.SchemaKindInteger:
+			parts = append(parts, "int")
+		case format == "float":
+			parts = append(parts, "float32")
+		case format == "double":
+			parts = append(parts, "float64")
+		default:
+			parts = append(parts, "number")
+		}
+		bounds, err := numericBounds(n)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, bounds...)
+	case jennies.SchemaKindBoolean:
+		parts = append(parts, "bool")
+	case jennies.SchemaKindArray:
+		item := "_"
+		if items := field(n, "items"); items != nil {
+			expr, err := s.expr(doc, items)
+			if err != nil {
+				return "", fmt.Errorf("items: %w", err)
+			}
+			item = expr
+		}
+		parts = append(parts, "[..."+item+"]")
+		if v := field(n, "minItems"); v != nil {
+			s.imports["list"] = true
+			parts = append(parts, fmt.Sprintf("list.MinItems(%s)", v.Value))
+		}
+		if v := field(n, "maxItems"); v != nil {
+			s.imports["list"] = true
+			parts = append(parts, fmt.Sprintf("list.MaxItems(%s)", v.Value))
+		}
+	case jennies.SchemaKindObject, jennies.SchemaKindMap, jennies.SchemaKindUntypedObject:
+		// A CUE struct can have both properties and a pattern constraint for additional properties
+		expr, err := s.structExpr(doc, n)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, expr)
+	default:
+		return "", fmt.Errorf("unsupported type '%s'", t)
+	}
+	return strings.Join(parts, " & "), nil
+}
+
+// structExpr returns the CUE struct for the object schema n, with properties in the order they are declared
+func (s *schemaConverter) structExpr(doc string, n *yaml.Node) (string, error) {
+	required := make(map[string]bool)
+	if req := field(n, "required"); req != nil && req.Kind == yaml.SequenceNode {
+		for _, r := range req.Content {
+			required[r.Value] = true
+		}
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString("{\n")
+	props := field(n, "properties")
+	if props != nil && props.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(props.Content); i += 2 {
+			name := props.Content[i].Value
+			prop := resolveAlias(props.Content[i+1])
+			expr, err := s.expr(doc, prop)
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", name, err)
+			}
+			writeComment(buf, stringField(prop, "description"))
+			optional := "?"
+			if required[name] {
+				optional = ""
+			}
+			fmt.Fprintf(buf, "%s%s: %s\n", cueLabel(name), optional, expr)
+		}
+	}
+	additional := resolveAlias(field(n, "additionalProperties"))
+	switch {
+	case additional != nil && additional.Kind == yaml.MappingNode:
+		expr, err := s.expr(doc, additional)
+		if err != nil {
+			return "", fmt.Errorf("additionalProperties: %w", err)
+		}
+		fmt.Fprintf(buf, "[string]: %s\n", expr)
+	case additional != nil && additional.Value == "true",
+		boolField(n, "x-kubernetes-preserve-unknown-fields"),
+		additional == nil && (props == nil || len(props.Content) == 0):
+		buf.WriteString("[string]: _\n")
+	}
+	buf.WriteString("}")
+	return buf.String(), nil
+}
+
+// topLevelExpr returns the CUE expression for the schema n of a top-level field of the kind, located in the file doc.
+// A referenced schema is inlined rather than converted into a definition, as definitions are closed,
+// and the SDK adds its own fields to some top-level fields (such as status).
+func (s *schemaConverter) topLevelExpr(doc string, n *yaml.Node) (string, error) {
+	ref := stringField(resolveAlias(n), "$ref")
+	if ref == "" {
+		return s.expr(doc, n)
+	}
+	target, pointer, node, err := s.resolveRef(doc, ref)
+	if err != nil {
+		return "", err
+	}
+	key := target + "#" + pointer
+	if s.resolving[key] {
+		return "", fmt.Errorf("recursive reference '%s' is not supported", ref)
+	}
+	// Add a placeholder reference while converting, so that recursive references to the schema are detected
+	s.refs[key] = ""
+	s.resolving[key] = true
+	expr, err := s.topLevelExpr(target, node)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", ref, err)
+	}
+	delete(s.resolving, key)
+	delete(s.refs, key)
+	return expr, nil
+}
+
+// resolveRef returns the file and pointer referenced by ref from doc, and the schema they point to
+func (s *schemaConverter) resolveRef(doc, ref string) (string, string, *yaml.Node, error) {
+	file, pointer, _ := strings.Cut(ref, "#")
+	target := doc
+	if file != "" {
+		target = path.Join(path.Dir(doc), file)
+	}
+	root, err := s.document(target)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("unable to resolve reference '%s': %w", ref, err)
+	}
+	node, err := resolvePointer(root, pointer)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("unable to resolve reference '%s': %w", ref, err)
+	}
+	return target, pointer, node, nil
+}
+
+// ref returns the name of the definition for the schema referenced by ref from doc, converting it if necessary
+func (s *schemaConverter) ref(doc, ref string) (string, error) {
+	file, pointer, _ := strings.Cut(ref, "#")
+	target := doc
+	if file != "" {
+		target = path.Join(path.Dir(doc), file)
+	}
+	key := target + "#" + pointer
+	if name, ok := s.refs[key]; ok {
+		if s.resolving[key] {
+			return "", fmt.Errorf("recursive reference '%s' is not supported", ref)
+		}
+		return name, nil
+	}
+	_, _, node, err := s.resolveRef(doc, ref)
+	if err != nil {
+		return "", err
+	}
+	name := s.definitionName(target, pointer)
+	s.refs[key] = name
+	s.resolving[key] = true
+	expr, err := s.expr(target, node)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", ref, err)
+	}
+	delete(s.resolving, key)
+	buf := &bytes.Buffer{}
+	writeComment(buf, stringField(node, "description"))
+	fmt.Fprintf(buf, "%s: %s\n", name, expr)
+	s.defs = append(s.defs, buf.String())
+	return name, nil
+}
+
+var nonIdentifierChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// definitionName returns an unused definition name for the schema at pointer in file,
+// based on the last segment of pointer (or the file name, if pointer is empty)
+func (s *schemaConverter) definitionName(file, pointer string) string {
+	segments := pointerSegments(pointer)
+	base := strings.TrimSuffix(path.Base(file), path.Ext(file))
+	if len(segments) > 0 {
+		base = segments[len(segments)-1]
+	}
+	base = nonIdentifierChars.ReplaceAllString(base, "")
+	if base == "" || (base[0] >= '0' && base[0] <= '9') {
+		base = "Schema" + base
+	}
+	base = "#" + strings.ToUpper(base[:1]) + base[1:]
+	name := base
+	for i := 2; s.names[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	s.names[name] = true
+	return name
+}
+
+// resolvePointer returns the node at the JSON pointer in root
+func resolvePointer(root *yaml.Node, pointer string) (*yaml.Node, error) {
+	n := resolveAlias(root)
+	for _, segment := range pointerSegments(pointer) {
+		switch n.Kind {
+		case yaml.MappingNode:
+			n = resolveAlias(field(n, segment))
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(n.Content) {
+				return nil, fmt.Errorf("invalid index '%s'", segment)
+			}
+			n = resolveAlias(n.Content[i])
+		default:
+			n = nil
+		}
+		if n == nil {
+			return nil, fmt.Errorf("'%s' not found", segment)
+		}
+	}
+	return n, nil
+}
+
+func pointerSegments(pointer string) []string {
+	pointer = strings.TrimPrefix(pointer, "/")
+	if pointer == "" {
+		return nil
+	}
+	segments := strings.Split(pointer, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+	}
+	return segments
+}
+
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// field returns the value of key in the mapping node n, or nil if n is not a mapping or does not contain key
+func field(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return resolveAlias(n.Content[i+1])
+		}
+	}
+	return nil
+}
+
+func stringField(n *yaml.Node, key string) string {
+	if v := field(n, key); v != nil && v.Kind == yaml.ScalarNode {
+		return v.Value
+	}
+	return ""
+}
+
+func boolField(n *yaml.Node, key string) bool {
+	v := field(n, key)
+	if v == nil || v.Kind != yaml.ScalarNode {
+		return false
+	}
+	b, _ := strconv.ParseBool(v.Value)
+	return b
+}
+
+// splitField returns a copy of the mapping node n without key, and the value of key
+func splitField(n *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	rest := &yaml.Node{
+		Kind: yaml.MappingNode,
+		Tag:  "!!map",
+	}
+	var value *yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			value = resolveAlias(n.Content[i+1])
+			continue
+		}
+		rest.Content = append(rest.Content, n.Content[i], n.Content[i+1])
+	}
+	return rest, value
+}
+
+// numericBounds returns the CUE bounds for the minimum and maximum of n,
+// supporting both boolean (OpenAPI 3.0) and numeric (OpenAPI 3.1) exclusiveMinimum and exclusiveMaximum
+func numericBounds(n *yaml.Node) ([]string, error) {
+	bounds := make([]string, 0, 2)
+	for _, b := range []struct {
+		inclusive, exclusive, op, exclusiveOp string
+	}{
+		{"minimum", "exclusiveMinimum", ">=", ">"},
+		{"maximum", "exclusiveMaximum", "<=", "<"},
+	} {
+		exclusive := field(n, b.exclusive)
+		if v := field(n, b.inclusive); v != nil {
+			lit, err := literal(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", b.inclusive, err)
+			}
+			op := b.op
+			if exclusive != nil && exclusive.Value == "true" {
+				op = b.exclusiveOp
+			}
+			bounds = append(bounds, op+lit)
+		} else if exclusive != nil && exclusive.Tag != "!!bool" {
+			lit, err := literal(exclusive)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", b.exclusive, err)
+			}
+			bounds = append(bounds, b.exclusiveOp+lit)
+		}
+	}
+	return bounds, nil
+}
+
+// literal returns the CUE literal for the YAML value n
+func literal(n *yaml.Node) (string, error) {
+	n = resolveAlias(n)
+	if n.Kind != yaml.ScalarNode {
+		return jsonValue(n)
+	}
+	switch n.ShortTag() {
+	case "!!null":
+		return "null", nil
+	case "!!bool", "!!int":
+		var v any
+		if err := n.Decode(&v); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%v", v), nil
+	case "!!float":
+		var f float64
+		if err := n.Decode(&f); err != nil {
+			return "", err
+		}
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return "", fmt.Errorf("'%s' is not a finite number", n.Value)
+		}
+		lit := strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(lit, ".e") {
+			lit += ".0"
+		}
+		return lit, nil
+	}
+	return cueString(n.Value), nil
+}
+
+// jsonValue returns the JSON encoding of the YAML value n, which is also a valid CUE expression
+func jsonValue(n *yaml.Node) (string, error) {
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func cueString(s string) string {
+	// JSON string literals are valid CUE string literals
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSpace(buf.String())
+}
+
+var identifier = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// cueKeywords are identifiers which must be quoted to be used as a field label
+var cueKeywords = map[string]bool{
+	"package": true, "import": true, "for": true, "in": true, "if": true, "let": true,
+	"true": true, "false": true, "null": true, "div": true, "mod": true, "quo": true, "rem": true,
+}
+
+// cueLabel returns name as a CUE field label, quoting it if it is not a plain identifier
+func cueLabel(name string) string {
+	if identifier.MatchString(name) && !cueKeywords[name] {
+		return name
+	}
+	return cueString(name)
+}
+
+// parenthesize wraps expr in parentheses if it is a disjunction
+func parenthesize(expr string) string {
+	if strings.Contains(expr, "|") {
+		return "(" + expr + ")"
+	}
+	return expr
+}
+
+func writeComment(buf *bytes.Buffer, description string) {
+	if description == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(description, "\n"), "\n") {
+		fmt.Fprintf(buf, "// %s\n", line)
+	}
+}
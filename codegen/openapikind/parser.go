@@ -0,0 +1,167 @@
+package openapikind
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana-app-sdk/codegen"
+	"github.com/grafana/grafana-app-sdk/codegen/cuekind"
+)
+
+// DefaultManifestSelector is the name (without extension) of the manifest file which is parsed if no selector is provided
+const DefaultManifestSelector = "manifest"
+
+// manifestExtensions are the extensions tried, in order, when looking up a manifest file from a selector
+var manifestExtensions = []string{".yaml", ".yml", ".json"}
+
+// DocumentLoader loads the file at filePath in files as a YAML node, so that its contents can be referenced
+// from the schemas of a manifest with `$ref`. Loaders can be registered (by file extension) with
+// Parser.RegisterDocumentLoader to reference schemas in files which are not YAML or JSON.
+type DocumentLoader func(files fs.FS, filePath string) (*yaml.Node, error)
+
+// NewParser returns a new Parser
+func NewParser() (*Parser, error) {
+	cueParser, err := cuekind.NewParser()
+	if err != nil {
+		return nil, err
+	}
+	return &Parser{
+		cueParser: cueParser,
+		loaders:   make(map[string]DocumentLoader),
+	}, nil
+}
+
+// Parser parses app manifests written in YAML or JSON, where the schema of each kind version is an OpenAPI (3.0) schema.
+// The manifest has the same fields as a CUE manifest, and each kind version's `schema` is a map of top-level fields
+// (such as `spec` and `status`) to OpenAPI schemas. Schemas may use `$ref` to refer to other parts of the manifest file
+// (`#/path/to/schema`), or to schemas in other YAML or JSON files, such as an existing OpenAPI document
+// (`openapi.yaml#/components/schemas/Foo`), using paths relative to the file containing the reference.
+//
+// The manifest is converted into CUE and parsed with the same definitions as a CUE manifest,
+// so defaults and validation are identical to the CUE format.
+type Parser struct {
+	cueParser *cuekind.Parser
+	loaders   map[string]DocumentLoader
+}
+
+// RegisterDocumentLoader registers loader to load referenced files with the extension ext (such as ".proto"),
+// instead of parsing them as YAML or JSON. It replaces any loader already registered for ext.
+func (p *Parser) RegisterDocumentLoader(ext string, loader DocumentLoader) {
+	p.loaders[ext] = loader
+}
+
+type parser[T any] struct {
+	parseFunc func(fs.FS, ...string) ([]T, error)
+}
+
+func (p *parser[T]) Parse(f fs.FS, args ...string) ([]T, error) {
+	return p.parseFunc(f, args...)
+}
+
+// ManifestParser returns a Parser that returns a list of codegen.AppManifest, one for each selector provided to Parse
+// (or DefaultManifestSelector if no selectors are present).
+func (p *Parser) ManifestParser() codegen.Parser[codegen.AppManifest] {
+	return &parser[codegen.AppManifest]{
+		parseFunc: func(f fs.FS, s ...string) ([]codegen.AppManifest, error) {
+			if len(s) == 0 {
+				s = []string{DefaultManifestSelector}
+			}
+			manifests := make([]codegen.AppManifest, 0, len(s))
+			for _, selector := range s {
+				m, err := p.ParseManifest(f, selector)
+				if err != nil {
+					return nil, err
+				}
+				manifests = append(manifests, m)
+			}
+			return manifests, nil
+		},
+	}
+}
+
+// KindParser returns a Parser that returns a list of codegen.Kind, containing the kinds of the manifests
+// provided by the selector(s) in Parse (or DefaultManifestSelector if no selectors are present).
+func (p *Parser) KindParser() codegen.Parser[codegen.Kind] {
+	return &parser[codegen.Kind]{
+		parseFunc: func(f fs.FS, s ...string) ([]codegen.Kind, error) {
+			if len(s) == 0 {
+				s = []string{DefaultManifestSelector}
+			}
+			kinds := make([]codegen.Kind, 0)
+			for _, selector := range s {
+				m, err := p.ParseManifest(f, selector)
+				if err != nil {
+					return nil, err
+				}
+				kinds = append(kinds, m.Kinds()...)
+			}
+			return kinds, nil
+		},
+	}
+}
+
+// ParseManifest parses the manifest file identified by manifestSelector in files, returning the parsed
+// codegen.AppManifest object or an error. manifestSelector is the path of the file, either with its extension,
+// or without one, in which case `.yaml`, `.yml`, and `.json` are tried in order.
+func (p *Parser) ParseManifest(files fs.FS, manifestSelector string) (codegen.AppManifest, error) {
+	if manifestSelector == "" {
+		manifestSelector = DefaultManifestSelector
+	}
+	manifestPath, err := findManifest(files, manifestSelector)
+	if err != nil {
+		return nil, err
+	}
+	conv := newConverter(files, p.loaders)
+	src, err := conv.convertManifest(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert manifest %s: %w", manifestPath, err)
+	}
+	root := cuecontext.New().CompileString(src, cue.Filename(manifestPath+".cue"))
+	if root.Err() != nil {
+		return nil, fmt.Errorf("unable to convert manifest %s: %w", manifestPath, root.Err())
+	}
+	manifest, err := p.cueParser.ParseManifestValue(root.LookupPath(cue.MakePath(cue.Str(manifestField))))
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", manifestPath, err)
+	}
+	return manifest, nil
+}
+
+// findManifest returns the path of the manifest file for selector in files
+func findManifest(files fs.FS, selector string) (string, error) {
+	if path.Ext(selector) != "" {
+		if _, err := fs.Stat(files, selector); err == nil {
+			return selector, nil
+		}
+	}
+	for _, ext := range manifestExtensions {
+		if _, err := fs.Stat(files, selector+ext); err == nil {
+			return selector + ext, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("no manifest file found for '%s' (tried %s.yaml, %s.yml, and %s.json)", selector, selector, selector, selector)
+}
+
+// loadDocument reads and parses the YAML or JSON file at filePath in files
+func loadDocument(files fs.FS, filePath string) (*yaml.Node, error) {
+	contents, err := fs.ReadFile(files, filePath)
+	if err != nil {
+		return nil, err
+	}
+	doc := &yaml.Node{}
+	if err = yaml.Unmarshal(contents, doc); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", filePath, err)
+	}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		return doc.Content[0], nil
+	}
+	return doc, nil
+}
@@ -0,0 +1,128 @@
+package openapikind
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/codegen/jennies"
+)
+
+const testDirectory = "./testing"
+
+func TestParser_ParseManifest(t *testing.T) {
+	parser, err := NewParser()
+	require.Nil(t, err)
+	manifest, err := parser.ParseManifest(os.DirFS(testDirectory), DefaultManifestSelector)
+	require.Nil(t, err)
+
+	assert.Equal(t, "openapi-app", manifest.Properties().AppName)
+	assert.Equal(t, "openapiapp.ext.grafana.com", manifest.Properties().FullGroup)
+	require.Len(t, manifest.Kinds(), 1)
+	kind := manifest.Kinds()[0]
+	assert.Equal(t, "Widget", kind.Properties().Kind)
+	assert.Equal(t, "widgets", kind.Properties().PluralMachineName)
+	assert.Equal(t, "Namespaced", kind.Properties().Scope)
+	require.Len(t, kind.Versions(), 2)
+	assert.Equal(t, "v1", kind.Versions()[0].Version)
+	assert.Equal(t, "v2", kind.Versions()[1].Version)
+	require.Len(t, kind.Version("v1").AdditionalPrinterColumns, 1)
+	assert.Equal(t, ".spec.color", kind.Version("v1").AdditionalPrinterColumns[0].JSONPath)
+
+	t.Run("external references", func(t *testing.T) {
+		props, err := jennies.CUEToCRDOpenAPI(kind.Version("v1").Schema, kind.Name(), "v1")
+		require.Nil(t, err)
+		spec := props["spec"].(map[string]any)
+		assert.ElementsMatch(t, []any{"color", "dimensions"}, spec["required"])
+		specProps := spec["properties"].(map[string]any)
+		assert.Equal(t, map[string]any{
+			"type":        "string",
+			"description": "Color of the widget",
+			"enum":        []any{"red", "green", "blue"},
+			"default":     "red",
+		}, specProps["color"])
+		assert.Equal(t, map[string]any{
+			"type":   "string",
+			"format": "date-time",
+		}, specProps["createdAt"])
+		dimensions := specProps["dimensions"].(map[string]any)
+		assert.ElementsMatch(t, []any{"width", "height"}, dimensions["required"])
+		assert.Equal(t, "int64", dimensions["properties"].(map[string]any)["width"].(map[string]any)["format"])
+		status := props["status"].(map[string]any)
+		assert.Contains(t, status["required"], "state")
+	})
+
+	t.Run("local references and constraints", func(t *testing.T) {
+		props, err := jennies.CUEToCRDOpenAPI(kind.Version("v2").Schema, kind.Name(), "v2")
+		require.Nil(t, err)
+		spec := props["spec"].(map[string]any)
+		assert.Equal(t, "Spec of a Widget", spec["description"])
+		specProps := spec["properties"].(map[string]any)
+		assert.Equal(t, 1, specProps["title"].(map[string]any)["minLength"])
+		assert.Equal(t, 10, specProps["size"].(map[string]any)["default"])
+		assert.Equal(t, map[string]any{"type": "string"}, specProps["labels"].(map[string]any)["additionalProperties"])
+		part := specProps["parts"].(map[string]any)["items"].(map[string]any)
+		assert.Equal(t, []any{"name"}, part["required"])
+		assert.Equal(t, true, part["properties"].(map[string]any)["weight"].(map[string]any)["nullable"])
+	})
+}
+
+func TestParser_KindParser(t *testing.T) {
+	parser, err := NewParser()
+	require.Nil(t, err)
+	kinds, err := parser.KindParser().Parse(os.DirFS(testDirectory), "manifest.yaml")
+	require.Nil(t, err)
+	require.Len(t, kinds, 1)
+	assert.Equal(t, "Widget", kinds[0].Name())
+}
+
+func TestParser_ParseManifest_Errors(t *testing.T) {
+	parser, err := NewParser()
+	require.Nil(t, err)
+	kind := func(schema string) string {
+		return "appName: test-app\nkinds:\n  - kind: Foo\n    current: v1\n    versions:\n      v1:\n        schema:\n          spec:\n" + schema
+	}
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		selector string
+		err      string
+	}{{
+		name:     "missing manifest",
+		files:    fstest.MapFS{},
+		selector: "manifest",
+		err:      "no manifest file found for 'manifest' (tried manifest.yaml, manifest.yml, and manifest.json)",
+	}, {
+		name: "recursive reference",
+		files: fstest.MapFS{
+			"manifest.yml": {Data: []byte(kind("            $ref: '#/node'\nnode:\n  type: object\n  properties:\n    child:\n      $ref: '#/node'\n"))},
+		},
+		selector: "manifest",
+		err:      "recursive reference '#/node' is not supported",
+	}, {
+		name: "missing reference",
+		files: fstest.MapFS{
+			"manifest.yaml": {Data: []byte(kind("            $ref: 'other.yaml#/components/schemas/Foo'\n"))},
+			"other.yaml":    {Data: []byte("components:\n  schemas: {}\n")},
+		},
+		selector: "manifest",
+		err:      "unable to resolve reference 'other.yaml#/components/schemas/Foo': 'Foo' not found",
+	}, {
+		name: "unsupported type",
+		files: fstest.MapFS{
+			"manifest.yaml": {Data: []byte(kind("            type: object\n            properties:\n              foo:\n                type: file\n"))},
+		},
+		selector: "manifest",
+		err:      "unsupported type 'file'",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parser.ParseManifest(test.files, test.selector)
+			require.NotNil(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package pipeline
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/grafana/codejen"
+
+	"github.com/grafana/grafana-app-sdk/codegen"
+)
+
+// VersionGenerator generates files for a single version of a kind
+type VersionGenerator interface {
+	JennyName() string
+	Generate(kind codegen.Kind, version *codegen.KindVersion) (codejen.Files, error)
+}
+
+// Hooks are the custom generators a project runs alongside the built-in generators.
+// Each generator receives the parsed kinds or manifest, and the paths of the files it returns
+// are relative to the project root.
+type Hooks struct {
+	// Kinds are called once for each kind
+	Kinds []codejen.OneToMany[codegen.Kind]
+	// Versions are called once for each version of each kind
+	Versions []VersionGenerator
+	// Manifest are called once for the app manifest, after all kinds and versions have been generated
+	Manifest []codejen.OneToMany[codegen.AppManifest]
+}
+
+// Empty returns true if there are no generators in the Hooks
+func (h Hooks) Empty() bool {
+	return len(h.Kinds) == 0 && len(h.Versions) == 0 && len(h.Manifest) == 0
+}
+
+// GenerateHooks runs the generators in hooks on the kinds parsed by kindParser and the manifest parsed by manifestParser
+// from modFS, and returns the generated files.
+func GenerateHooks(
+	kindParser codegen.Parser[codegen.Kind], manifestParser codegen.Parser[codegen.AppManifest], modFS fs.FS, hooks Hooks, selectors ...string,
+) (codejen.Files, error) {
+	files := make(codejen.Files, 0)
+	if len(hooks.Kinds) > 0 || len(hooks.Versions) > 0 {
+		kinds, err := kindParser.Parse(modFS, selectors...)
+		if err != nil {
+			return nil, err
+		}
+		for _, kind := range kinds {
+			for _, g := range hooks.Kinds {
+				generated, err := g.Generate(kind)
+				if err != nil {
+					return nil, fmt.Errorf("%s: kind %s: %w", g.JennyName(), kind.Name(), err)
+				}
+				files = append(files, withFrom(generated, g)...)
+			}
+			for i := range kind.Versions() {
+				version := &kind.Versions()[i]
+				for _, g := range hooks.Versions {
+					generated, err := g.Generate(kind, version)
+					if err != nil {
+						return nil, fmt.Errorf("%s: kind %s version %s: %w", g.JennyName(), kind.Name(), version.Version, err)
+					}
+					files = append(files, withFrom(generated, g)...)
+				}
+			}
+		}
+	}
+	if len(hooks.Manifest) > 0 {
+		manifests, err := manifestParser.Parse(modFS, selectors...)
+		if err != nil {
+			return nil, err
+		}
+		for _, manifest := range manifests {
+			for _, g := range hooks.Manifest {
+				generated, err := g.Generate(manifest)
+				if err != nil {
+					return nil, fmt.Errorf("%s: manifest %s: %w", g.JennyName(), manifest.Name(), err)
+				}
+				files = append(files, withFrom(generated, g)...)
+			}
+		}
+	}
+	return files, nil
+}
+
+// withFrom sets the generator of files which don't already have one to g
+func withFrom(files codejen.Files, g codejen.NamedJenny) codejen.Files {
+	for i, f := range files {
+		if len(f.From) == 0 {
+			files[i].From = []codejen.NamedJenny{g}
+		}
+	}
+	return files
+}
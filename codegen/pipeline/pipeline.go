@@ -0,0 +1,207 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/grafana/codejen"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana-app-sdk/codegen"
+	"github.com/grafana/grafana-app-sdk/codegen/cuekind"
+	"github.com/grafana/grafana-app-sdk/codegen/openapikind"
+	"github.com/grafana/grafana-app-sdk/codegen/protokind"
+)
+
+const (
+	FormatCUE     = "cue"
+	FormatOpenAPI = "openapi"
+	FormatProto   = "proto"
+
+	// TSValidatorsZod is the Config.TSValidators value for generating zod schemas alongside the TypeScript types
+	TSValidatorsZod = "zod"
+)
+
+// Config is the configuration of the built-in generators run by Generate
+type Config struct {
+	// GoGenBasePath is the path to the directory where generated go code will reside
+	GoGenBasePath string `json:"goGenBasePath"`
+	// TSGenBasePath is the path to the directory where generated TypeScript code will reside
+	TSGenBasePath string `json:"tsGenBasePath"`
+	// TSValidators are the validators to generate alongside the TypeScript types. Allowed values are TSValidatorsZod, or empty for none.
+	TSValidators string `json:"tsValidators"`
+	// PyGenBasePath is the path to the directory where generated Python code will reside. If empty, no Python code is generated.
+	PyGenBasePath string `json:"pyGenBasePath"`
+	// ProtoGenBasePath is the path to the directory where generated protobuf definitions will reside.
+	// If empty, no protobuf definitions are generated.
+	ProtoGenBasePath string `json:"protoGenBasePath"`
+	// ProtoPrevious contains the previously generated protobuf definitions, relative to ProtoGenBasePath.
+	// Fields keep their numbers from the previous definitions, so that regenerating does not renumber them.
+	ProtoPrevious fs.FS `json:"-"`
+	// CRDEncoding is the encoding of the generated CRD and manifest files, either "json" or "yaml".
+	// Use "none" to turn off CRD and manifest file generation.
+	CRDEncoding string `json:"crdEncoding"`
+	// CRDPath is the path to the directory where generated CRD and manifest files will reside
+	CRDPath string `json:"crdPath"`
+	// GroupKinds places all kinds with the same group in the same go package, rather than a package per kind
+	GroupKinds bool `json:"groupKinds"`
+	// Clientset generates a typed client for each kind version, and a Clientset for each generated go package
+	Clientset bool `json:"clientset"`
+}
+
+// Parsers returns the kind and manifest parsers for the kind source format (FormatCUE, FormatOpenAPI, or FormatProto).
+// Shared schema imports of a CUE module must already be vendored (see cuekind.VendorSchemaImports).
+func Parsers(format string) (codegen.Parser[codegen.Kind], codegen.Parser[codegen.AppManifest], error) {
+	switch format {
+	case FormatCUE:
+		parser, err := cuekind.NewParser()
+		if err != nil {
+			return nil, nil, err
+		}
+		return parser.KindParser(true), parser.ManifestParser(), nil
+	case FormatOpenAPI:
+		parser, err := openapikind.NewParser()
+		if err != nil {
+			return nil, nil, err
+		}
+		return parser.KindParser(), parser.ManifestParser(), nil
+	case FormatProto:
+		parser, err := protokind.NewParser()
+		if err != nil {
+			return nil, nil, err
+		}
+		return parser.KindParser(), parser.ManifestParser(), nil
+	default:
+		return nil, nil, fmt.Errorf("unknown kind format '%s'", format)
+	}
+}
+
+// Generate runs the SDK's built-in generators on the kinds parsed by kindParser and the manifest parsed by manifestParser
+// from modFS, and returns the generated files. These are the files written by the `grafana-app-sdk generate` command.
+//
+//nolint:funlen,goconst
+func Generate(kindParser codegen.Parser[codegen.Kind], manifestParser codegen.Parser[codegen.AppManifest], modFS fs.FS, cfg Config, selectors ...string) (codejen.Files, error) {
+	// Slightly hacky multiple generators as an intermediary while we move to a better system.
+	// Both still source from a Manifest, but generatorForKinds supplies []Kind to jennies, vs AppManifest
+	generatorForKinds, err := codegen.NewGenerator[codegen.Kind](kindParser, modFS)
+	if err != nil {
+		return nil, err
+	}
+	generatorForManifest, err := codegen.NewGenerator[codegen.AppManifest](manifestParser, modFS)
+	if err != nil {
+		return nil, err
+	}
+	// Resource
+	resourceFiles, err := generatorForKinds.Generate(cuekind.ResourceGenerator(cfg.GroupKinds), selectors...)
+	if err != nil {
+		return nil, err
+	}
+	for i, f := range resourceFiles {
+		resourceFiles[i].RelativePath = filepath.Join(cfg.GoGenBasePath, f.RelativePath)
+	}
+	// Typed clients (optional)
+	var clientsetFiles codejen.Files
+	if cfg.Clientset {
+		clientsetFiles, err = generatorForKinds.Generate(cuekind.ClientsetGenerator(cfg.GroupKinds), selectors...)
+		if err != nil {
+			return nil, err
+		}
+		for i, f := range clientsetFiles {
+			clientsetFiles[i].RelativePath = filepath.Join(cfg.GoGenBasePath, f.RelativePath)
+		}
+	}
+	tsResourceFiles, err := generatorForKinds.Generate(cuekind.TypeScriptResourceGenerator(), selectors...)
+	if err != nil {
+		return nil, err
+	}
+	for i, f := range tsResourceFiles {
+		tsResourceFiles[i].RelativePath = filepath.Join(cfg.TSGenBasePath, f.RelativePath)
+	}
+	if cfg.TSValidators == TSValidatorsZod {
+		zodFiles, err := generatorForKinds.Generate(cuekind.TypeScriptZodGenerator(), selectors...)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zodFiles {
+			f.RelativePath = filepath.Join(cfg.TSGenBasePath, f.RelativePath)
+			tsResourceFiles = append(tsResourceFiles, f)
+		}
+	}
+	// Python (optional)
+	var pyFiles codejen.Files
+	if cfg.PyGenBasePath != "" {
+		pyFiles, err = generatorForKinds.Generate(cuekind.PythonGenerator(), selectors...)
+		if err != nil {
+			return nil, err
+		}
+		for i, f := range pyFiles {
+			pyFiles[i].RelativePath = filepath.Join(cfg.PyGenBasePath, f.RelativePath)
+		}
+	}
+	// Protobuf (optional)
+	var protoFiles codejen.Files
+	if cfg.ProtoGenBasePath != "" {
+		protoFiles, err = generatorForKinds.Generate(cuekind.ProtobufGenerator(cfg.ProtoPrevious), selectors...)
+		if err != nil {
+			return nil, err
+		}
+		for i, f := range protoFiles {
+			protoFiles[i].RelativePath = filepath.Join(cfg.ProtoGenBasePath, f.RelativePath)
+		}
+	}
+	// CRD
+	var crdFiles codejen.Files
+	if cfg.CRDEncoding != "none" {
+		encFunc := json.Marshal
+		if cfg.CRDEncoding == "yaml" {
+			encFunc = yaml.Marshal
+		}
+		crdFiles, err = generatorForKinds.Generate(cuekind.CRDGenerator(encFunc, cfg.CRDEncoding), selectors...)
+		if err != nil {
+			return nil, err
+		}
+		for i, f := range crdFiles {
+			crdFiles[i].RelativePath = filepath.Join(cfg.CRDPath, f.RelativePath)
+		}
+	}
+
+	// Manifest
+	goManifestFiles, err := generatorForManifest.Generate(cuekind.ManifestGoGenerator(filepath.Base(cfg.GoGenBasePath)), selectors...)
+	if err != nil {
+		return nil, err
+	}
+	for i, f := range goManifestFiles {
+		goManifestFiles[i].RelativePath = filepath.Join(cfg.GoGenBasePath, f.RelativePath)
+	}
+
+	// Manifest CRD
+	var manifestFiles codejen.Files
+	if cfg.CRDEncoding != "none" {
+		encFunc := func(v any) ([]byte, error) {
+			return json.MarshalIndent(v, "", "    ")
+		}
+		if cfg.CRDEncoding == "yaml" {
+			encFunc = yaml.Marshal
+		}
+
+		manifestFiles, err = generatorForManifest.Generate(cuekind.ManifestGenerator(encFunc, cfg.CRDEncoding), selectors...)
+		if err != nil {
+			return nil, err
+		}
+		for i, f := range manifestFiles {
+			manifestFiles[i].RelativePath = filepath.Join(cfg.CRDPath, f.RelativePath)
+		}
+	}
+
+	allFiles := append(make(codejen.Files, 0), resourceFiles...)
+	allFiles = append(allFiles, clientsetFiles...)
+	allFiles = append(allFiles, tsResourceFiles...)
+	allFiles = append(allFiles, pyFiles...)
+	allFiles = append(allFiles, protoFiles...)
+	allFiles = append(allFiles, crdFiles...)
+	allFiles = append(allFiles, manifestFiles...)
+	allFiles = append(allFiles, goManifestFiles...)
+	return allFiles, nil
+}
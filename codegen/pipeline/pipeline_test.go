@@ -0,0 +1,144 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/grafana/codejen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/codegen"
+)
+
+const testCUEDirectory = "../cuekind/testing"
+
+func TestGenerateHooks(t *testing.T) {
+	kindParser, manifestParser, err := Parsers(FormatCUE)
+	require.Nil(t, err)
+
+	t.Run("all hooks", func(t *testing.T) {
+		files, err := GenerateHooks(kindParser, manifestParser, os.DirFS(testCUEDirectory), Hooks{
+			Kinds:    []codejen.OneToMany[codegen.Kind]{&testKindGenerator{}},
+			Versions: []VersionGenerator{&testVersionGenerator{}},
+			Manifest: []codejen.OneToMany[codegen.AppManifest]{&testManifestGenerator{}},
+		}, "customManifest")
+		require.Nil(t, err)
+		paths := make([]string, len(files))
+		for i, f := range files {
+			paths[i] = f.RelativePath
+			require.Len(t, f.From, 1)
+		}
+		assert.Equal(t, []string{"kinds/CustomKind.txt", "versions/CustomKind/v0-0.txt", "versions/CustomKind/v1-0.txt", "manifest/custom-app.txt"}, paths)
+		assert.Equal(t, "testVersionGenerator", files[1].From[0].JennyName())
+		assert.Equal(t, []byte("customapp.ext.grafana.com"), files[0].Data)
+		assert.Equal(t, []byte("CustomKind"), files[3].Data)
+	})
+
+	t.Run("no hooks", func(t *testing.T) {
+		files, err := GenerateHooks(kindParser, manifestParser, os.DirFS(testCUEDirectory), Hooks{}, "customManifest")
+		require.Nil(t, err)
+		assert.Empty(t, files)
+	})
+
+	t.Run("generator error", func(t *testing.T) {
+		_, err := GenerateHooks(kindParser, manifestParser, os.DirFS(testCUEDirectory), Hooks{
+			Versions: []VersionGenerator{&testVersionGenerator{err: errors.New("I AM ERROR")}},
+		}, "customManifest")
+		assert.Equal(t, "testVersionGenerator: kind CustomKind version v0-0: I AM ERROR", err.Error())
+	})
+}
+
+func TestServePlugin(t *testing.T) {
+	hooks := Hooks{
+		Manifest: []codejen.OneToMany[codegen.AppManifest]{&testManifestGenerator{}},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		req, err := json.Marshal(PluginRequest{
+			SourcePath: testCUEDirectory,
+			Format:     FormatCUE,
+			Selectors:  []string{"testManifest"},
+		})
+		require.Nil(t, err)
+		out := bytes.Buffer{}
+		require.Nil(t, servePlugin(bytes.NewReader(req), &out, hooks))
+		resp := PluginResponse{}
+		require.Nil(t, json.Unmarshal(out.Bytes(), &resp))
+		assert.Equal(t, PluginResponse{
+			Files: []PluginFile{{
+				Path:      "manifest/test-app.txt",
+				Data:      []byte("TestKind,TestKind2"),
+				Generator: "testManifestGenerator",
+			}},
+		}, resp)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		req, err := json.Marshal(PluginRequest{
+			SourcePath: testCUEDirectory,
+			Format:     "foo",
+		})
+		require.Nil(t, err)
+		out := bytes.Buffer{}
+		err = servePlugin(bytes.NewReader(req), &out, hooks)
+		assert.Equal(t, errors.New("unknown kind format 'foo'"), err)
+		resp := PluginResponse{}
+		require.Nil(t, json.Unmarshal(out.Bytes(), &resp))
+		assert.Equal(t, "unknown kind format 'foo'", resp.Error)
+	})
+}
+
+type testKindGenerator struct{}
+
+func (*testKindGenerator) JennyName() string {
+	return "testKindGenerator"
+}
+
+func (*testKindGenerator) Generate(kind codegen.Kind) (codejen.Files, error) {
+	return codejen.Files{{
+		RelativePath: fmt.Sprintf("kinds/%s.txt", kind.Name()),
+		Data:         []byte(kind.Properties().Group),
+	}}, nil
+}
+
+type testVersionGenerator struct {
+	err error
+}
+
+func (*testVersionGenerator) JennyName() string {
+	return "testVersionGenerator"
+}
+
+func (g *testVersionGenerator) Generate(kind codegen.Kind, version *codegen.KindVersion) (codejen.Files, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	return codejen.Files{{
+		RelativePath: fmt.Sprintf("versions/%s/%s.txt", kind.Name(), version.Version),
+	}}, nil
+}
+
+type testManifestGenerator struct{}
+
+func (*testManifestGenerator) JennyName() string {
+	return "testManifestGenerator"
+}
+
+func (*testManifestGenerator) Generate(manifest codegen.AppManifest) (codejen.Files, error) {
+	names := ""
+	for i, kind := range manifest.Kinds() {
+		if i > 0 {
+			names += ","
+		}
+		names += kind.Name()
+	}
+	return codejen.Files{{
+		RelativePath: fmt.Sprintf("manifest/%s.txt", manifest.Properties().AppName),
+		Data:         []byte(names),
+	}}, nil
+}
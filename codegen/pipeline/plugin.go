@@ -0,0 +1,138 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/grafana/codejen"
+)
+
+// PluginRequest is the request sent to a codegen plugin on its stdin by RunPlugin
+type PluginRequest struct {
+	// SourcePath is the path to the directory with the project's codegen source files, relative to the working directory
+	SourcePath string `json:"sourcePath"`
+	// Format is the format the project's kinds are written in
+	Format string `json:"format"`
+	// Selectors are the manifest selectors to parse
+	Selectors []string `json:"selectors"`
+	// Config is the configuration used for the built-in generators
+	Config Config `json:"config"`
+}
+
+// PluginResponse is the response written by a codegen plugin to its stdout
+type PluginResponse struct {
+	Files []PluginFile `json:"files"`
+	// Error is set if the plugin failed to generate its files
+	Error string `json:"error,omitempty"`
+}
+
+// PluginFile is a file generated by a codegen plugin
+type PluginFile struct {
+	// Path is the path of the file, relative to the project root
+	Path string `json:"path"`
+	Data []byte `json:"data"`
+	// Generator is the name of the generator which created the file
+	Generator string `json:"generator"`
+}
+
+// ServePlugin runs hooks as a codegen plugin: it reads a PluginRequest from stdin, parses the kinds and manifest
+// of the project it describes, runs hooks on them, and writes a PluginResponse with the generated files to stdout.
+// It is intended to be the whole of a plugin's main function:
+//
+//	func main() {
+//		if err := pipeline.ServePlugin(pipeline.Hooks{...}); err != nil {
+//			os.Exit(1)
+//		}
+//	}
+func ServePlugin(hooks Hooks) error {
+	return servePlugin(os.Stdin, os.Stdout, hooks)
+}
+
+func servePlugin(in io.Reader, out io.Writer, hooks Hooks) error {
+	resp, err := handlePluginRequest(in, hooks)
+	if err != nil {
+		resp = PluginResponse{
+			Error: err.Error(),
+		}
+	}
+	if encErr := json.NewEncoder(out).Encode(resp); encErr != nil {
+		return errors.Join(err, encErr)
+	}
+	return err
+}
+
+func handlePluginRequest(in io.Reader, hooks Hooks) (PluginResponse, error) {
+	req := PluginRequest{}
+	if err := json.NewDecoder(in).Decode(&req); err != nil {
+		return PluginResponse{}, fmt.Errorf("unable to read plugin request: %w", err)
+	}
+	kindParser, manifestParser, err := Parsers(req.Format)
+	if err != nil {
+		return PluginResponse{}, err
+	}
+	files, err := GenerateHooks(kindParser, manifestParser, os.DirFS(req.SourcePath), hooks, req.Selectors...)
+	if err != nil {
+		return PluginResponse{}, err
+	}
+	resp := PluginResponse{
+		Files: make([]PluginFile, len(files)),
+	}
+	for i, f := range files {
+		resp.Files[i] = PluginFile{
+			Path: f.RelativePath,
+			Data: f.Data,
+		}
+		if len(f.From) > 0 {
+			resp.Files[i].Generator = f.From[0].JennyName()
+		}
+	}
+	return resp, nil
+}
+
+// RunPlugin runs the codegen plugin at path and returns the files it generates. If path is a directory,
+// it is run as a go main package with `go run`, otherwise it is run as an executable.
+// The plugin's stderr is passed through to stderr.
+func RunPlugin(ctx context.Context, path string, req PluginRequest) (codejen.Files, error) {
+	var cmd *exec.Cmd
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		cmd = exec.CommandContext(ctx, "go", "run", path)
+	} else {
+		cmd = exec.CommandContext(ctx, path)
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	stdout := bytes.Buffer{}
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	runErr := cmd.Run()
+	resp := PluginResponse{}
+	if err = json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("plugin %s failed: %w", path, runErr)
+		}
+		return nil, fmt.Errorf("unable to read response from plugin %s: %w", path, err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("plugin %s failed: %s", path, resp.Error)
+	}
+	if runErr != nil {
+		return nil, fmt.Errorf("plugin %s failed: %w", path, runErr)
+	}
+	files := make(codejen.Files, len(resp.Files))
+	for i, f := range resp.Files {
+		files[i] = codejen.File{
+			RelativePath: f.Path,
+			Data:         f.Data,
+		}
+	}
+	return files, nil
+}
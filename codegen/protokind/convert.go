@@ -0,0 +1,434 @@
+package protokind
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/emicklei/proto"
+	"gopkg.in/yaml.v3"
+)
+
+const wellKnownPackage = "google.protobuf."
+
+// scalarTypes maps protobuf scalar types to OpenAPI types and formats, following the CUE protobuf mapping
+var scalarTypes = map[string][2]string{
+	"double":   {"number", "double"},
+	"float":    {"number", "float"},
+	"int32":    {"integer", "int32"},
+	"sint32":   {"integer", "int32"},
+	"sfixed32": {"integer", "int32"},
+	"int64":    {"integer", "int64"},
+	"sint64":   {"integer", "int64"},
+	"sfixed64": {"integer", "int64"},
+	"uint32":   {"integer", "uint32"},
+	"fixed32":  {"integer", "uint32"},
+	"uint64":   {"integer", "uint64"},
+	"fixed64":  {"integer", "uint64"},
+	"bool":     {"boolean", ""},
+	"string":   {"string", ""},
+	"bytes":    {"string", "byte"},
+}
+
+// wellKnownType returns the OpenAPI schema for the well-known type name (without the google.protobuf. prefix),
+// based on its JSON mapping
+func wellKnownType(name string) (*yaml.Node, bool) {
+	switch name {
+	case "Timestamp":
+		return mapping("type", scalar("string"), "format", scalar("date-time")), true
+	case "Duration", "FieldMask":
+		return mapping("type", scalar("string")), true
+	case "Struct", "Any":
+		return mapping("type", scalar("object"), "x-kubernetes-preserve-unknown-fields", boolean(true)), true
+	case "Value", "NullValue":
+		return mapping(), true
+	case "ListValue":
+		return mapping("type", scalar("array")), true
+	case "Empty":
+		return mapping("type", scalar("object")), true
+	case "BoolValue", "StringValue", "BytesValue", "Int32Value", "Int64Value", "UInt32Value", "UInt64Value", "FloatValue", "DoubleValue":
+		t := scalarTypes[strings.ToLower(strings.TrimSuffix(name, "Value"))]
+		n := mapping("type", scalar(t[0]))
+		if t[1] != "" {
+			n.Content = append(n.Content, scalar("format"), scalar(t[1]))
+		}
+		n.Content = append(n.Content, scalar("nullable"), boolean(true))
+		return n, true
+	}
+	return nil, false
+}
+
+// LoadDocument is an openapikind.DocumentLoader which loads the .proto file at filePath in files as a YAML node
+// of OpenAPI schemas, keyed by the name of each message and enum relative to the file's package
+// (such as `Widget` or `Widget.Part` for a message nested in `Widget`). References to messages and enums in other
+// files are resolved using the file's imports, which are relative to the directory of the importing file or the root
+// of files. The google/protobuf well-known types are converted according to their JSON mapping, and, like other
+// imports under google/, do not need to be present in files.
+func LoadDocument(files fs.FS, filePath string) (*yaml.Node, error) {
+	file, err := parseFile(files, filePath)
+	if err != nil {
+		return nil, err
+	}
+	c := &fileConverter{
+		file:    file,
+		symbols: make(map[string]symbol),
+		defs:    mapping(),
+	}
+	if err = c.addSymbols(files, file, make(map[string]bool)); err != nil {
+		return nil, err
+	}
+	for _, e := range file.proto.Elements {
+		if err = c.convertElement(e, file.pkg, ""); err != nil {
+			return nil, err
+		}
+	}
+	return c.defs, nil
+}
+
+type protoFile struct {
+	path  string
+	pkg   string
+	proto *proto.Proto
+}
+
+func parseFile(files fs.FS, filePath string) (*protoFile, error) {
+	contents, err := fs.ReadFile(files, filePath)
+	if err != nil {
+		return nil, err
+	}
+	parser := proto.NewParser(bytes.NewReader(contents))
+	parser.Filename(filePath)
+	def, err := parser.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", filePath, err)
+	}
+	file := &protoFile{
+		path:  filePath,
+		proto: def,
+	}
+	for _, e := range def.Elements {
+		if pkg, ok := e.(*proto.Package); ok {
+			file.pkg = pkg.Name
+		}
+	}
+	return file, nil
+}
+
+// symbol is a message or enum which can be used as the type of a field
+type symbol struct {
+	// file is the path of the file which declares the symbol
+	file string
+	// name is the name of the symbol relative to the package of the file
+	name string
+}
+
+type fileConverter struct {
+	file *protoFile
+	// symbols maps the fully-qualified name of each message and enum in the file and its imports to its symbol
+	symbols map[string]symbol
+	defs    *yaml.Node
+}
+
+// addSymbols adds the messages and enums of file, and (recursively) those of the files it imports, to c.symbols
+func (c *fileConverter) addSymbols(files fs.FS, file *protoFile, visited map[string]bool) error {
+	visited[file.path] = true
+	var add func(elements []proto.Visitee, prefix string)
+	add = func(elements []proto.Visitee, prefix string) {
+		for _, e := range elements {
+			switch cast := e.(type) {
+			case *proto.Message:
+				if cast.IsExtend {
+					continue
+				}
+				c.symbols[qualify(file.pkg, prefix+cast.Name)] = symbol{file: file.path, name: prefix + cast.Name}
+				add(cast.Elements, prefix+cast.Name+".")
+			case *proto.Enum:
+				c.symbols[qualify(file.pkg, prefix+cast.Name)] = symbol{file: file.path, name: prefix + cast.Name}
+			}
+		}
+	}
+	add(file.proto.Elements, "")
+	for _, e := range file.proto.Elements {
+		imp, ok := e.(*proto.Import)
+		// The google/ imports are well-known types and annotations (such as google/api/field_behavior.proto),
+		// which do not declare types used by fields
+		if !ok || strings.HasPrefix(imp.Filename, "google/") {
+			continue
+		}
+		importPath, err := resolveImport(files, file.path, imp.Filename)
+		if err != nil {
+			return err
+		}
+		if visited[importPath] {
+			continue
+		}
+		imported, err := parseFile(files, importPath)
+		if err != nil {
+			return err
+		}
+		if err = c.addSymbols(files, imported, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveImport returns the path in files of the import of importName from the file at from
+func resolveImport(files fs.FS, from, importName string) (string, error) {
+	for _, candidate := range []string{path.Join(path.Dir(from), importName), path.Clean(importName)} {
+		if _, err := fs.Stat(files, candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("unable to resolve import '%s' in %s", importName, from)
+}
+
+func (c *fileConverter) convertElement(e proto.Visitee, scope, prefix string) error {
+	switch cast := e.(type) {
+	case *proto.Message:
+		if cast.IsExtend {
+			return nil
+		}
+		schema, err := c.messageSchema(cast, qualify(scope, cast.Name), prefix+cast.Name)
+		if err != nil {
+			return fmt.Errorf("message %s: %w", prefix+cast.Name, err)
+		}
+		c.defs.Content = append(c.defs.Content, scalar(prefix+cast.Name), schema)
+	case *proto.Enum:
+		c.defs.Content = append(c.defs.Content, scalar(prefix+cast.Name), enumSchema(cast))
+	}
+	return nil
+}
+
+// messageSchema returns the OpenAPI object schema for msg, whose fully-qualified name is scope,
+// and adds the schemas of its nested messages and enums to c.defs (with names prefixed by name)
+func (c *fileConverter) messageSchema(msg *proto.Message, scope, name string) (*yaml.Node, error) {
+	props := mapping()
+	required := &yaml.Node{Kind: yaml.SequenceNode}
+	var addFields func(elements []proto.Visitee) error
+	addFields = func(elements []proto.Visitee) error {
+		for _, e := range elements {
+			var (
+				f      *proto.Field
+				schema *yaml.Node
+				err    error
+			)
+			switch cast := e.(type) {
+			case *proto.NormalField:
+				f = cast.Field
+				schema, err = c.typeSchema(scope, cast.Type)
+				if err == nil && cast.Repeated {
+					schema = mapping("type", scalar("array"), "items", schema)
+				}
+				if cast.Required || isRequired(cast.Options) {
+					required.Content = append(required.Content, scalar(jsonName(cast.Field)))
+				}
+			case *proto.MapField:
+				f = cast.Field
+				schema, err = c.typeSchema(scope, cast.Type)
+				if err == nil {
+					schema = mapping("type", scalar("object"), "additionalProperties", schema)
+				}
+			case *proto.OneOfField:
+				f = cast.Field
+				schema, err = c.typeSchema(scope, cast.Type)
+			case *proto.Oneof:
+				if err := addFields(cast.Elements); err != nil {
+					return err
+				}
+				continue
+			case *proto.Group:
+				return fmt.Errorf("group %s: groups are not supported", cast.Name)
+			case *proto.Message, *proto.Enum:
+				if err := c.convertElement(cast, scope, name+"."); err != nil {
+					return err
+				}
+				continue
+			default:
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			if desc := comment(f.Comment, f.InlineComment); desc != "" {
+				schema = withDescription(schema, desc)
+			}
+			props.Content = append(props.Content, scalar(jsonName(f)), schema)
+		}
+		return nil
+	}
+	if err := addFields(msg.Elements); err != nil {
+		return nil, err
+	}
+	schema := mapping("type", scalar("object"))
+	if desc := comment(msg.Comment, nil); desc != "" {
+		schema.Content = append(schema.Content, scalar("description"), scalar(desc))
+	}
+	schema.Content = append(schema.Content, scalar("properties"), props)
+	if len(required.Content) > 0 {
+		schema.Content = append(schema.Content, scalar("required"), required)
+	}
+	return schema, nil
+}
+
+// typeSchema returns the OpenAPI schema for a field of type typeName, declared in the message scope
+func (c *fileConverter) typeSchema(scope, typeName string) (*yaml.Node, error) {
+	if t, ok := scalarTypes[typeName]; ok {
+		n := mapping("type", scalar(t[0]))
+		if t[1] != "" {
+			n.Content = append(n.Content, scalar("format"), scalar(t[1]))
+		}
+		return n, nil
+	}
+	if name := strings.TrimPrefix(typeName, "."); strings.HasPrefix(name, wellKnownPackage) {
+		if n, ok := wellKnownType(strings.TrimPrefix(name, wellKnownPackage)); ok {
+			return n, nil
+		}
+		return nil, fmt.Errorf("unsupported type '%s'", typeName)
+	}
+	sym, ok := c.resolve(scope, typeName)
+	if !ok {
+		return nil, fmt.Errorf("unknown type '%s'", typeName)
+	}
+	ref := "#/" + sym.name
+	if sym.file != c.file.path {
+		ref = relativePath(path.Dir(c.file.path), sym.file) + ref
+	}
+	return mapping("$ref", scalar(ref)), nil
+}
+
+// resolve returns the symbol for typeName referenced from scope, using protobuf scoping rules:
+// the innermost scope is searched first, and a leading '.' makes typeName fully-qualified
+func (c *fileConverter) resolve(scope, typeName string) (symbol, bool) {
+	if strings.HasPrefix(typeName, ".") {
+		sym, ok := c.symbols[typeName[1:]]
+		return sym, ok
+	}
+	for {
+		if sym, ok := c.symbols[qualify(scope, typeName)]; ok {
+			return sym, true
+		}
+		if scope == "" {
+			return symbol{}, false
+		}
+		if i := strings.LastIndex(scope, "."); i >= 0 {
+			scope = scope[:i]
+		} else {
+			scope = ""
+		}
+	}
+}
+
+func enumSchema(enum *proto.Enum) *yaml.Node {
+	values := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, e := range enum.Elements {
+		if f, ok := e.(*proto.EnumField); ok {
+			values.Content = append(values.Content, scalar(f.Name))
+		}
+	}
+	schema := mapping("type", scalar("string"))
+	if desc := comment(enum.Comment, nil); desc != "" {
+		schema.Content = append(schema.Content, scalar("description"), scalar(desc))
+	}
+	schema.Content = append(schema.Content, scalar("enum"), values)
+	return schema
+}
+
+// jsonName returns the JSON name of the field, which is the json_name option if present,
+// or the field name converted to lowerCamelCase
+func jsonName(f *proto.Field) string {
+	for _, o := range f.Options {
+		if o.Name == "json_name" {
+			return o.Constant.Source
+		}
+	}
+	b := strings.Builder{}
+	upper := false
+	for _, r := range f.Name {
+		switch {
+		case r == '_':
+			upper = true
+		case upper && r >= 'a' && r <= 'z':
+			b.WriteRune(r - 'a' + 'A')
+			upper = false
+		default:
+			b.WriteRune(r)
+			upper = false
+		}
+	}
+	return b.String()
+}
+
+// isRequired returns true if the options contain `(google.api.field_behavior) = REQUIRED`
+func isRequired(options []*proto.Option) bool {
+	for _, o := range options {
+		if o.Name == "(google.api.field_behavior)" && o.Constant.Source == "REQUIRED" {
+			return true
+		}
+	}
+	return false
+}
+
+// comment returns the text of c, or of inline if c is nil
+func comment(c, inline *proto.Comment) string {
+	if c == nil {
+		c = inline
+	}
+	if c == nil {
+		return ""
+	}
+	lines := make([]string, 0, len(c.Lines))
+	for _, line := range c.Lines {
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// withDescription returns schema with a description. As the siblings of a $ref are ignored,
+// references are wrapped in an allOf.
+func withDescription(schema *yaml.Node, desc string) *yaml.Node {
+	if schema.Content[0].Value == "$ref" {
+		schema = mapping("allOf", &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{schema}})
+	}
+	schema.Content = append(schema.Content, scalar("description"), scalar(desc))
+	return schema
+}
+
+func qualify(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// relativePath returns the path of target relative to the directory dir
+func relativePath(dir, target string) string {
+	if dir == "." {
+		return target
+	}
+	dirParts := strings.Split(dir, "/")
+	targetParts := strings.Split(target, "/")
+	i := 0
+	for i < len(dirParts) && i < len(targetParts)-1 && dirParts[i] == targetParts[i] {
+		i++
+	}
+	return strings.Repeat("../", len(dirParts)-i) + strings.Join(targetParts[i:], "/")
+}
+
+func mapping(pairs ...any) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		n.Content = append(n.Content, scalar(pairs[i].(string)), pairs[i+1].(*yaml.Node))
+	}
+	return n
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func boolean(value bool) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprintf("%t", value)}
+}
@@ -0,0 +1,53 @@
+package protokind
+
+import (
+	"io/fs"
+
+	"github.com/grafana/grafana-app-sdk/codegen"
+	"github.com/grafana/grafana-app-sdk/codegen/openapikind"
+)
+
+// DefaultManifestSelector is the name (without extension) of the manifest file which is parsed if no selector is provided
+const DefaultManifestSelector = openapikind.DefaultManifestSelector
+
+// NewParser returns a new Parser
+func NewParser() (*Parser, error) {
+	openapiParser, err := openapikind.NewParser()
+	if err != nil {
+		return nil, err
+	}
+	openapiParser.RegisterDocumentLoader(".proto", LoadDocument)
+	return &Parser{
+		openapiParser: openapiParser,
+	}, nil
+}
+
+// Parser parses app manifests written in YAML or JSON, where the schemas of kind versions are protobuf messages.
+// The manifest has the same format as the openapikind format, and each top-level field of a kind version's `schema`
+// references a message in a .proto file with `$ref`, such as `$ref: widget.proto#/WidgetSpec`, where the path
+// is relative to the manifest and the pointer is the name of the message relative to the file's package
+// (see LoadDocument). Message fields become optional fields named with their JSON name, unless they have the proto2
+// `required` label or the `(google.api.field_behavior) = REQUIRED` option. Schemas may also be written in OpenAPI,
+// and mix both.
+type Parser struct {
+	openapiParser *openapikind.Parser
+}
+
+// ManifestParser returns a Parser that returns a list of codegen.AppManifest, one for each selector provided to Parse
+// (or DefaultManifestSelector if no selectors are present).
+func (p *Parser) ManifestParser() codegen.Parser[codegen.AppManifest] {
+	return p.openapiParser.ManifestParser()
+}
+
+// KindParser returns a Parser that returns a list of codegen.Kind, containing the kinds of the manifests
+// provided by the selector(s) in Parse (or DefaultManifestSelector if no selectors are present).
+func (p *Parser) KindParser() codegen.Parser[codegen.Kind] {
+	return p.openapiParser.KindParser()
+}
+
+// ParseManifest parses the manifest file identified by manifestSelector in files, returning the parsed
+// codegen.AppManifest object or an error. manifestSelector is the path of the file, either with its extension,
+// or without one, in which case `.yaml`, `.yml`, and `.json` are tried in order.
+func (p *Parser) ParseManifest(files fs.FS, manifestSelector string) (codegen.AppManifest, error) {
+	return p.openapiParser.ParseManifest(files, manifestSelector)
+}
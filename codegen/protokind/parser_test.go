@@ -0,0 +1,138 @@
+package protokind
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/codegen/cuekind"
+	"github.com/grafana/grafana-app-sdk/codegen/jennies"
+)
+
+const testDirectory = "./testing"
+
+func TestParser_ParseManifest(t *testing.T) {
+	parser, err := NewParser()
+	require.Nil(t, err)
+	manifest, err := parser.ParseManifest(os.DirFS(testDirectory), DefaultManifestSelector)
+	require.Nil(t, err)
+
+	assert.Equal(t, "proto-app", manifest.Properties().AppName)
+	require.Len(t, manifest.Kinds(), 1)
+	kind := manifest.Kinds()[0]
+	assert.Equal(t, "Gadget", kind.Properties().Kind)
+	require.Len(t, kind.Versions(), 1)
+
+	props, err := jennies.CUEToCRDOpenAPI(kind.Version("v1").Schema, kind.Name(), "v1")
+	require.Nil(t, err)
+	spec := props["spec"].(map[string]any)
+	assert.Equal(t, []any{"title"}, spec["required"])
+	specProps := spec["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"type":        "string",
+		"description": "Title of the gadget",
+	}, specProps["title"])
+	assert.Equal(t, "int32", specProps["size"].(map[string]any)["format"])
+	assert.Equal(t, map[string]any{"type": "string"}, specProps["labels"].(map[string]any)["additionalProperties"])
+	assert.Equal(t, map[string]any{"type": "string", "format": "date-time"}, specProps["createdAt"])
+	assert.Equal(t, true, specProps["extra"].(map[string]any)["x-kubernetes-preserve-unknown-fields"])
+	// oneof fields are flattened into the message
+	assert.Contains(t, specProps, "url")
+	assert.Contains(t, specProps, "data")
+
+	t.Run("nested messages and json_name", func(t *testing.T) {
+		part := specProps["parts"].(map[string]any)["items"].(map[string]any)
+		partProps := part["properties"].(map[string]any)
+		assert.Contains(t, partProps, "partName")
+		assert.Equal(t, "double", partProps["weight"].(map[string]any)["format"])
+	})
+
+	t.Run("imported messages", func(t *testing.T) {
+		owner := specProps["owner"].(map[string]any)
+		assert.Contains(t, owner["properties"], "name")
+		assert.Contains(t, owner["properties"], "email")
+	})
+
+	t.Run("enums", func(t *testing.T) {
+		status := props["status"].(map[string]any)
+		assert.Equal(t, map[string]any{
+			"type": "string",
+			"enum": []any{"STATE_UNSPECIFIED", "STATE_READY"},
+		}, status["properties"].(map[string]any)["state"])
+	})
+}
+
+func TestParser_KindParser(t *testing.T) {
+	parser, err := NewParser()
+	require.Nil(t, err)
+	kinds, err := parser.KindParser().Parse(os.DirFS(testDirectory), "manifest.yaml")
+	require.Nil(t, err)
+	require.Len(t, kinds, 1)
+	assert.Equal(t, "Gadget", kinds[0].Name())
+	// The status message is combined with the status fields added by the SDK
+	files, err := cuekind.ResourceGenerator(false).Generate(kinds...)
+	require.Nil(t, err)
+	assert.NotEmpty(t, files)
+}
+
+func TestParser_ParseManifest_Errors(t *testing.T) {
+	parser, err := NewParser()
+	require.Nil(t, err)
+	manifest := []byte("appName: test-app\nkinds:\n  - kind: Foo\n    current: v1\n    versions:\n      v1:\n        schema:\n          spec:\n            $ref: foo.proto#/FooSpec\n")
+	tests := []struct {
+		name  string
+		proto string
+		err   string
+	}{{
+		name:  "unknown type",
+		proto: "syntax = \"proto3\";\nmessage FooSpec {\n  Bar bar = 1;\n}\n",
+		err:   "message FooSpec: field bar: unknown type 'Bar'",
+	}, {
+		name:  "unresolved import",
+		proto: "syntax = \"proto3\";\nimport \"bar.proto\";\nmessage FooSpec {}\n",
+		err:   "unable to resolve import 'bar.proto' in foo.proto",
+	}, {
+		name:  "recursive message",
+		proto: "syntax = \"proto3\";\nmessage FooSpec {\n  FooSpec child = 1;\n}\n",
+		err:   "recursive reference '#/FooSpec' is not supported",
+	}, {
+		name:  "invalid proto",
+		proto: "syntax = \"proto3\";\nmessage FooSpec {",
+		err:   "unable to parse foo.proto",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parser.ParseManifest(fstest.MapFS{
+				"manifest.yaml": {Data: manifest},
+				"foo.proto":     {Data: []byte(test.proto)},
+			}, DefaultManifestSelector)
+			require.NotNil(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestLoadDocument_GeneratedProto(t *testing.T) {
+	// Definitions generated by jennies.ProtobufGenerator can be used as a kind source
+	generated, err := os.ReadFile("../testing/golden_generated/protobuf/customkind/v1_0/customkind.proto.txt")
+	require.Nil(t, err)
+	doc, err := LoadDocument(fstest.MapFS{"customkind.proto": {Data: generated}}, "customkind.proto")
+	require.Nil(t, err)
+	names := make([]string, 0)
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		names = append(names, doc.Content[i].Value)
+	}
+	assert.Equal(t, []string{
+		"CustomKind",
+		"CustomKindSpec.Inner.InnerField3Item",
+		"CustomKindSpec.Inner",
+		"CustomKindSpec.Union",
+		"CustomKindSpec.MapValue",
+		"CustomKindSpec",
+		"CustomKindStatus.OperatorStatesValue",
+		"CustomKindStatus",
+	}, names)
+}
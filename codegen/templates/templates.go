@@ -15,7 +15,7 @@ import (
 	"github.com/grafana/grafana-app-sdk/codegen"
 )
 
-//go:embed *.tmpl plugin/*.tmpl secure/*.tmpl operator/*.tmpl app/*.tmpl
+//go:embed *.tmpl plugin/*.tmpl secure/*.tmpl operator/*.tmpl app/*.tmpl python/*.tmpl
 var templates embed.FS
 
 var (
@@ -27,6 +27,11 @@ var (
 	templateWrappedType, _    = template.ParseFS(templates, "wrappedtype.tmpl")
 	templateTSType, _         = template.ParseFS(templates, "tstype.tmpl")
 	templateConstants, _      = template.ParseFS(templates, "constants.tmpl")
+	templateClient, _         = template.ParseFS(templates, "client.tmpl")
+	templateClientset, _      = template.ParseFS(templates, "clientset.tmpl")
+	templateFormGo, _         = template.ParseFS(templates, "form_go.tmpl")
+	templateFormTS, _         = template.ParseFS(templates, "form_ts.tmpl")
+	templateCustomRoutes, _   = template.ParseFS(templates, "customroutes_go.tmpl")
 
 	templateBackendPluginRouter, _          = template.ParseFS(templates, "plugin/plugin.tmpl")
 	templateBackendPluginResourceHandler, _ = template.ParseFS(templates, "plugin/handler_resource.tmpl")
@@ -41,6 +46,10 @@ var (
 	templateOperatorConfig, _     = template.ParseFS(templates, "operator/config.tmpl")
 
 	templateManifestGoFile, _ = template.ParseFS(templates, "manifest_go.tmpl")
+
+	templatePythonBase, _   = template.ParseFS(templates, "python/base.tmpl")
+	templatePythonClient, _ = template.ParseFS(templates, "python/client.tmpl")
+	templatePythonInit, _   = template.ParseFS(templates, "python/init.tmpl")
 )
 
 var (
@@ -121,11 +130,43 @@ type SchemaMetadata struct {
 	Scope            string
 	SelectableFields []SchemaMetadataSeletableField
 	FuncPrefix       string
+	// OpenAPISchema is the go string literal of the JSON-encoded OpenAPI schema for the kind version
+	OpenAPISchema string
+	// Maturity, Owner, and DocumentationURL are the optional metadata of the kind, added to the Kind doc comment
+	Maturity         string
+	Owner            string
+	DocumentationURL string
 }
 
 type SchemaMetadataSeletableField struct {
 	Field    string
 	Optional bool
+	// Type is one of the SelectableFieldType constants, and determines how the field value is converted to a string
+	Type string
+}
+
+const (
+	SelectableFieldTypeString  = "string"
+	SelectableFieldTypeEnum    = "enum"
+	SelectableFieldTypeInteger = "integer"
+	SelectableFieldTypeBoolean = "boolean"
+)
+
+// SelectableFieldValue returns the go expression for the string value of the selectable field f of an object named cast
+func (s SchemaMetadata) SelectableFieldValue(f SchemaMetadataSeletableField) string {
+	val := "cast." + s.ToObjectPath(f.Field)
+	if f.Optional {
+		val = "*" + val
+	}
+	switch f.Type {
+	case SelectableFieldTypeEnum:
+		return fmt.Sprintf("string(%s)", val)
+	case SelectableFieldTypeInteger:
+		return fmt.Sprintf("fmt.Sprintf(\"%%d\", %s)", val)
+	case SelectableFieldTypeBoolean:
+		return fmt.Sprintf("fmt.Sprintf(\"%%t\", %s)", val)
+	}
+	return val
 }
 
 func (SchemaMetadata) ToObjectPath(s string) string {
@@ -491,6 +532,113 @@ func WriteConstantsFile(metadata ConstantsMetadata, out io.Writer) error {
 	return templateConstants.Execute(out, metadata)
 }
 
+// ClientMetadata is the metadata used by the typed client template for a single kind version
+type ClientMetadata struct {
+	Package string
+	Kind    string
+	// MachineName is the machine name of the kind, used for unexported identifiers
+	MachineName string
+	// PluralName is the plural of Kind, used for the kind's Clientset accessor
+	PluralName string
+	// FuncPrefix is the prefix of the package's Kind() function for the kind
+	FuncPrefix string
+}
+
+// WriteClient executes the typed client template, and writes out the generated go code to out
+func WriteClient(metadata ClientMetadata, out io.Writer) error {
+	return templateClient.Execute(out, metadata)
+}
+
+// ClientsetMetadata is the metadata used by the clientset template for all kinds in a generated package
+type ClientsetMetadata struct {
+	Package string
+	Group   string
+	Version string
+	Kinds   []ClientMetadata
+}
+
+// FormDescriptorMetadata is the metadata used by the form descriptor templates for a kind version
+type FormDescriptorMetadata struct {
+	Package string
+	Kind    string
+	Version string
+	// FuncPrefix is the prefix of the generated go functions
+	FuncPrefix string
+	// FormDescriptor is the JSON-encoded app.FormDescriptor, as a go string literal for the go template
+	FormDescriptor string
+}
+
+// WriteFormDescriptorGo executes the go form descriptor template, and writes out the generated go code to out
+func WriteFormDescriptorGo(metadata FormDescriptorMetadata, out io.Writer) error {
+	return templateFormGo.Execute(out, metadata)
+}
+
+// WriteFormDescriptorTS executes the TypeScript form descriptor template, and writes out the generated code to out
+func WriteFormDescriptorTS(metadata FormDescriptorMetadata, out io.Writer) error {
+	return templateFormTS.Execute(out, metadata)
+}
+
+// CustomRoutesMetadata is the metadata used by the custom routes template
+type CustomRoutesMetadata struct {
+	Package string
+	Kind    string
+	Version string
+	// Prefix is the prefix of the generated go types, constants, and functions
+	Prefix string
+	Routes []CustomRouteMetadata
+}
+
+// CustomRouteMetadata is the metadata of a single route for the custom routes template
+type CustomRouteMetadata struct {
+	Name   string
+	Method string
+	Path   string
+	// RequestType and ResponseType are the go types of the request and response bodies
+	RequestType  string
+	ResponseType string
+}
+
+// WriteCustomRoutes executes the custom routes template, and writes out the generated go code to out
+func WriteCustomRoutes(metadata CustomRoutesMetadata, out io.Writer) error {
+	return templateCustomRoutes.Execute(out, metadata)
+}
+
+// WriteClientset executes the clientset template, and writes out the generated go code to out
+func WriteClientset(metadata ClientsetMetadata, out io.Writer) error {
+	return templateClientset.Execute(out, metadata)
+}
+
+// PythonPackageMetadata is the metadata used by the python package templates
+type PythonPackageMetadata struct {
+	Group        string
+	KindVersions []PythonKindVersionMetadata
+}
+
+// PythonKindVersionMetadata describes the generated python module for a single version of a kind
+type PythonKindVersionMetadata struct {
+	// Package is the python package name of the kind
+	Package string
+	// Version is the python package name of the version
+	Version string
+	// TypeName is the name of the kind's model class
+	TypeName string
+}
+
+// WritePythonBase writes the python module containing the base classes of the generated models
+func WritePythonBase(out io.Writer) error {
+	return templatePythonBase.Execute(out, nil)
+}
+
+// WritePythonClient writes the python module containing the requests-based client
+func WritePythonClient(metadata PythonPackageMetadata, out io.Writer) error {
+	return templatePythonClient.Execute(out, metadata)
+}
+
+// WritePythonInit writes the __init__.py of the generated python package
+func WritePythonInit(metadata PythonPackageMetadata, out io.Writer) error {
+	return templatePythonInit.Execute(out, metadata)
+}
+
 // ToPackageName sanitizes an input into a deterministic allowed go package name.
 // It is used to turn kind names or versions into package names when performing go code generation.
 func ToPackageName(input string) string {
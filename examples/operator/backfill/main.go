@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/k8s"
+	"github.com/grafana/grafana-app-sdk/operator"
+	"github.com/grafana/grafana-app-sdk/resource"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Schema and Kind are typically generated, but can be crafted by hand as seen here.
+// This is the same kind as the one used in the simple operator examples.
+var (
+	schema = resource.NewSimpleSchema("example.grafana.com", "v1", &resource.TypedSpecObject[BasicModel]{}, &resource.TypedList[*resource.TypedSpecObject[BasicModel]]{}, resource.WithKind("BasicCustomResource"))
+	kind   = resource.Kind{
+		Schema: schema,
+		Codecs: map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()},
+	}
+)
+
+type BasicModel struct {
+	Number int    `json:"numField"`
+	String string `json:"stringField"`
+}
+
+func main() {
+	kubeCfgFile := flag.String("kubecfg", "", "kube config path")
+	workers := flag.Int("workers", 4, "number of objects to process concurrently")
+	qps := flag.Float64("qps", 10, "maximum number of objects to process per second")
+	flag.Parse()
+	if kubeCfgFile == nil || *kubeCfgFile == "" {
+		fmt.Println("--kubecfg must be set to the path of your kubernetes config file")
+		os.Exit(1)
+	}
+
+	// Kubernetes configuration for all our interactions
+	kubeConfig, err := clientcmd.BuildConfigFromFlags("", *kubeCfgFile)
+	if err != nil {
+		panic(err)
+	}
+	kubeConfig.APIPath = "/apis"
+
+	client, err := k8s.NewClientRegistry(*kubeConfig, k8s.DefaultClientConfig()).ClientFor(kind)
+	if err != nil {
+		panic(fmt.Errorf("unable to create client: %w", err))
+	}
+
+	// The backfill sets a default stringField on every BasicCustomResource which doesn't have one.
+	// Each processed object is annotated with backfill.grafana.app/default-string-field,
+	// so if the backfill is stopped and run again, it picks up where it left off.
+	backfill, err := operator.NewBackfill(client, func(ctx context.Context, object resource.Object) error {
+		cast, ok := object.(*resource.TypedSpecObject[BasicModel])
+		if !ok {
+			return fmt.Errorf("unexpected object type %T", object)
+		}
+		if cast.Spec.String != "" {
+			return nil
+		}
+		log.Printf("Setting default stringField for %s/%s", cast.GetNamespace(), cast.GetName())
+		cast.Spec.String = "default"
+		_, err := client.Update(ctx, cast.GetStaticMetadata().Identifier(), cast, resource.UpdateOptions{
+			ResourceVersion: cast.GetResourceVersion(),
+		})
+		return err
+	}, operator.BackfillConfig{
+		Name:     "default-string-field",
+		PageSize: 50,
+		Workers:  *workers,
+		QPS:      *qps,
+	})
+	if err != nil {
+		panic(fmt.Errorf("unable to create backfill: %w", err))
+	}
+
+	// Set up a signal handler
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	// Log progress while the backfill runs
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				status := backfill.Status()
+				log.Printf("Progress: %d processed, %d skipped, %d failed", status.Processed, status.Skipped, status.Failed)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	log.Print("\u001B[1;32mStarting Backfill\u001B[0m")
+	err = backfill.Run(ctx)
+	status := backfill.Status()
+	log.Printf("Backfill finished: %d processed, %d skipped, %d failed", status.Processed, status.Skipped, status.Failed)
+	if err != nil {
+		panic(fmt.Errorf("error running backfill: %w", err))
+	}
+}
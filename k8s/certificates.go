@@ -0,0 +1,244 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+)
+
+// defaultCertificateRetryInterval is how long to wait before retrying after a CertificateProvider fails,
+// or returns a certificate which is already due for renewal.
+const defaultCertificateRetryInterval = 30 * time.Second
+
+// defaultCertificateWatchInterval is how often certificate files are checked for changes if TLSConfig.WatchInterval is zero
+const defaultCertificateWatchInterval = 10 * time.Second
+
+// CertificateProvider provides the TLS certificate for an HTTPS server, such as the WebhookServer.
+// It is the integration point for certificates issued by an external issuer, such as Vault PKI,
+// or a cert-manager Certificate (see NewFileCertificateProvider for a Secret mounted as files).
+type CertificateProvider interface {
+	// Certificate returns a valid certificate. It is called on startup, and again when the current certificate
+	// is due for renewal, so implementations which issue certificates should issue a new one on each call.
+	Certificate(ctx context.Context) (*tls.Certificate, error)
+}
+
+// CertificateProviderFunc is a function which implements CertificateProvider
+type CertificateProviderFunc func(ctx context.Context) (*tls.Certificate, error)
+
+// Certificate calls the function
+func (f CertificateProviderFunc) Certificate(ctx context.Context) (*tls.Certificate, error) {
+	return f(ctx)
+}
+
+// NewFileCertificateProvider returns a CertificateProvider which loads the certificate and key from the provided paths
+// each time it is called. When the files are updated in-place (such as a kubernetes Secret volume managed by cert-manager),
+// the new certificate is picked up once the previous one is due for renewal. Servers configured with TLSConfig.CertPath
+// and TLSConfig.KeyPath instead also watch the files, and pick up changes immediately (see TLSConfig.WatchInterval).
+func NewFileCertificateProvider(certPath, keyPath string) CertificateProvider {
+	return CertificateProviderFunc(func(context.Context) (*tls.Certificate, error) {
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return nil, err
+		}
+		return &cert, nil
+	})
+}
+
+// certificateRotator serves the current certificate from a CertificateProvider, and fetches a new certificate from the
+// provider before the current one expires. As certificates are served via tls.Config.GetCertificate,
+// new certificates are used for new connections without restarting the listener.
+type certificateRotator struct {
+	provider      CertificateProvider
+	renewBefore   time.Duration
+	retryInterval time.Duration
+	current       atomic.Pointer[tls.Certificate]
+	// expiry is the expiry of the current certificate, set only by the goroutine running the rotator
+	expiry time.Time
+	// files are checked for changes every watchInterval, and the certificate is reloaded when any of them change
+	files         []string
+	watchInterval time.Duration
+	// fileState is the last observed state of files, set only by the goroutine running the rotator
+	fileState string
+}
+
+func newCertificateRotator(provider CertificateProvider, renewBefore time.Duration) *certificateRotator {
+	return &certificateRotator{
+		provider:      provider,
+		renewBefore:   renewBefore,
+		retryInterval: defaultCertificateRetryInterval,
+	}
+}
+
+// newTLSConfigRotator returns a certificateRotator for the certificate described by cfg,
+// which must have either a CertificateProvider or a CertPath and KeyPath.
+func newTLSConfigRotator(cfg TLSConfig) *certificateRotator {
+	if cfg.CertificateProvider != nil {
+		return newCertificateRotator(cfg.CertificateProvider, cfg.RenewBefore)
+	}
+	rotator := newCertificateRotator(NewFileCertificateProvider(cfg.CertPath, cfg.KeyPath), cfg.RenewBefore)
+	rotator.files = []string{cfg.CertPath, cfg.KeyPath}
+	rotator.watchInterval = cfg.WatchInterval
+	if rotator.watchInterval == 0 {
+		rotator.watchInterval = defaultCertificateWatchInterval
+	}
+	return rotator
+}
+
+// start loads the initial certificate, and then renews it in the background until ctx is canceled
+func (c *certificateRotator) start(ctx context.Context) error {
+	// Record the state of the files before loading them, so that changes made while loading are picked up by run
+	c.filesChanged()
+	renewAt, _, err := c.load(ctx)
+	if err != nil {
+		return err
+	}
+	go c.run(ctx, renewAt)
+	return nil
+}
+
+// load fetches a certificate from the provider and makes it the current certificate.
+// It returns the time at which the certificate should be renewed, and whether the certificate changed.
+func (c *certificateRotator) load(ctx context.Context) (time.Time, bool, error) {
+	cert, err := c.provider.Certificate(ctx)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if cert == nil || len(cert.Certificate) == 0 {
+		return time.Time{}, false, errors.New("certificate provider returned an empty certificate")
+	}
+	leaf := cert.Leaf
+	if leaf == nil {
+		leaf, err = x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("unable to parse certificate: %w", err)
+		}
+	}
+	previous := c.current.Swap(cert)
+	changed := previous == nil || !bytes.Equal(previous.Certificate[0], cert.Certificate[0])
+	c.expiry = leaf.NotAfter
+	renewBefore := c.renewBefore
+	if renewBefore <= 0 {
+		// Default to renewing after two-thirds of the certificate's lifetime, which is also the cert-manager default
+		renewBefore = leaf.NotAfter.Sub(leaf.NotBefore) / 3
+	}
+	return leaf.NotAfter.Add(-renewBefore), changed, nil
+}
+
+// run renews the current certificate, which was loaded with load and is due for renewal at renewAt,
+// until ctx is canceled. If the rotator watches files, the certificate is also reloaded when they change.
+// The current certificate continues to be served if renewal fails.
+func (c *certificateRotator) run(ctx context.Context, renewAt time.Time) {
+	var watch <-chan time.Time
+	if len(c.files) > 0 && c.watchInterval > 0 {
+		ticker := time.NewTicker(c.watchInterval)
+		defer ticker.Stop()
+		watch = ticker.C
+	}
+	var err error
+	for {
+		wait := time.Until(renewAt)
+		if err != nil {
+			logging.FromContext(ctx).Error("unable to renew TLS certificate, retrying", "error", err, "expiry", c.expiry)
+			wait = c.retryInterval
+		} else if wait <= 0 {
+			// The provider returned a certificate which is already due for renewal (such as a file which hasn't been
+			// updated yet), so check again after the retry interval
+			wait = c.retryInterval
+		}
+		timer := time.NewTimer(wait)
+		for reload := false; !reload; {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				reload = true
+			case <-watch:
+				reload = c.filesChanged()
+			}
+		}
+		timer.Stop()
+		var changed bool
+		renewAt, changed, err = c.load(ctx)
+		if changed {
+			logging.FromContext(ctx).Info("renewed TLS certificate", "expiry", c.expiry)
+		}
+	}
+}
+
+// filesChanged returns whether the modification time or size of any watched file changed since it was last called.
+// Files in kubernetes Secret volumes are replaced by swapping a symlink, so the files are stat-ed through any symlinks.
+func (c *certificateRotator) filesChanged() bool {
+	state := strings.Builder{}
+	for _, file := range c.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			fmt.Fprintf(&state, "%s:missing;", file)
+			continue
+		}
+		fmt.Fprintf(&state, "%s:%d:%d;", file, info.ModTime().UnixNano(), info.Size())
+	}
+	changed := state.String() != c.fileState
+	c.fileState = state.String()
+	return changed
+}
+
+// GetCertificate returns the current certificate, and can be used as tls.Config.GetCertificate
+func (c *certificateRotator) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert := c.current.Load()
+	if cert == nil {
+		return nil, errors.New("no TLS certificate has been loaded")
+	}
+	return cert, nil
+}
+
+// CertificateWatcher serves the certificate described by a TLSConfig to an HTTPS server, via tls.Config.GetCertificate.
+// Once started, it renews the certificate before it expires, and reloads it when CertPath or KeyPath change,
+// so servers using it pick up new certificates without a restart. The WebhookServer uses the same mechanism for its certificate.
+type CertificateWatcher struct {
+	rotator *certificateRotator
+}
+
+// NewCertificateWatcher creates a new CertificateWatcher for the certificate described by cfg.
+// cfg must have either a CertificateProvider, or a CertPath and KeyPath.
+func NewCertificateWatcher(cfg TLSConfig) (*CertificateWatcher, error) {
+	if cfg.CertificateProvider == nil {
+		if cfg.CertPath == "" {
+			return nil, errors.New("TLSConfig.CertPath is required")
+		}
+		if cfg.KeyPath == "" {
+			return nil, errors.New("TLSConfig.KeyPath is required")
+		}
+	}
+	return &CertificateWatcher{
+		rotator: newTLSConfigRotator(cfg),
+	}, nil
+}
+
+// Start loads the certificate, returning an error if it cannot be loaded,
+// and then renews and reloads it in the background until ctx is canceled.
+func (w *CertificateWatcher) Start(ctx context.Context) error {
+	return w.rotator.start(ctx)
+}
+
+// GetCertificate returns the current certificate, and can be used as tls.Config.GetCertificate
+func (w *CertificateWatcher) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return w.rotator.GetCertificate(hello)
+}
+
+// TLSConfig returns a tls.Config for an HTTPS server which serves the current certificate
+func (w *CertificateWatcher) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: w.rotator.GetCertificate,
+	}
+}
@@ -0,0 +1,251 @@
+package k8s
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertificateRotator_Load(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	cert := generateTestCertificate(t, 1, now, now.Add(90*time.Hour))
+
+	t.Run("default renew before", func(t *testing.T) {
+		rotator := newCertificateRotator(CertificateProviderFunc(func(context.Context) (*tls.Certificate, error) {
+			return cert, nil
+		}), 0)
+		renewAt, changed, err := rotator.load(context.Background())
+		require.Nil(t, err)
+		assert.True(t, changed)
+		assert.True(t, now.Add(60*time.Hour).Equal(renewAt))
+		served, err := rotator.GetCertificate(nil)
+		require.Nil(t, err)
+		assert.Equal(t, cert, served)
+
+		// The same certificate again is not a change
+		_, changed, err = rotator.load(context.Background())
+		require.Nil(t, err)
+		assert.False(t, changed)
+	})
+
+	t.Run("renew before", func(t *testing.T) {
+		rotator := newCertificateRotator(CertificateProviderFunc(func(context.Context) (*tls.Certificate, error) {
+			return cert, nil
+		}), time.Hour)
+		renewAt, _, err := rotator.load(context.Background())
+		require.Nil(t, err)
+		assert.True(t, now.Add(89*time.Hour).Equal(renewAt))
+	})
+
+	t.Run("provider error", func(t *testing.T) {
+		perr := errors.New("I AM ERROR")
+		rotator := newCertificateRotator(CertificateProviderFunc(func(context.Context) (*tls.Certificate, error) {
+			return nil, perr
+		}), 0)
+		_, _, err := rotator.load(context.Background())
+		assert.Equal(t, perr, err)
+		_, err = rotator.GetCertificate(nil)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestCertificateRotator_Run(t *testing.T) {
+	now := time.Now()
+	// The first certificate is already due for renewal
+	first := generateTestCertificate(t, 1, now.Add(-time.Hour), now.Add(time.Minute))
+	second := generateTestCertificate(t, 2, now, now.Add(time.Hour))
+	calls := atomic.Int32{}
+	rotator := newCertificateRotator(CertificateProviderFunc(func(context.Context) (*tls.Certificate, error) {
+		switch calls.Add(1) {
+		case 1:
+			return first, nil
+		case 2:
+			// Failed renewals keep serving the current certificate
+			return nil, errors.New("unavailable")
+		default:
+			return second, nil
+		}
+	}), 0)
+	rotator.retryInterval = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	renewAt, _, err := rotator.load(ctx)
+	require.Nil(t, err)
+	go rotator.run(ctx, renewAt)
+
+	assert.Eventually(t, func() bool {
+		cert, err := rotator.GetCertificate(nil)
+		return err == nil && cert == second
+	}, time.Second, 5*time.Millisecond)
+	assert.GreaterOrEqual(t, calls.Load(), int32(3))
+}
+
+func TestNewFileCertificateProvider(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "tls.crt")
+	keyPath := filepath.Join(dir, "tls.key")
+	provider := NewFileCertificateProvider(certPath, keyPath)
+
+	_, err := provider.Certificate(context.Background())
+	assert.NotNil(t, err)
+
+	now := time.Now()
+	writeTestCertificate(t, generateTestCertificate(t, 1, now, now.Add(time.Hour)), certPath, keyPath)
+	cert, err := provider.Certificate(context.Background())
+	require.Nil(t, err)
+	require.NotEmpty(t, cert.Certificate)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), leaf.SerialNumber.Int64())
+
+	// Files updated in-place are picked up on the next call
+	writeTestCertificate(t, generateTestCertificate(t, 2, now, now.Add(time.Hour)), certPath, keyPath)
+	cert, err = provider.Certificate(context.Background())
+	require.Nil(t, err)
+	leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), leaf.SerialNumber.Int64())
+}
+
+func TestCertificateWatcher(t *testing.T) {
+	_, err := NewCertificateWatcher(TLSConfig{})
+	assert.Equal(t, errors.New("TLSConfig.CertPath is required"), err)
+	_, err = NewCertificateWatcher(TLSConfig{CertPath: "tls.crt"})
+	assert.Equal(t, errors.New("TLSConfig.KeyPath is required"), err)
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "tls.crt")
+	keyPath := filepath.Join(dir, "tls.key")
+	watcher, err := NewCertificateWatcher(TLSConfig{
+		CertPath:      certPath,
+		KeyPath:       keyPath,
+		WatchInterval: 10 * time.Millisecond,
+	})
+	require.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The certificate must exist to start
+	assert.NotNil(t, watcher.Start(ctx))
+
+	now := time.Now()
+	writeTestCertificate(t, generateTestCertificate(t, 1, now, now.Add(time.Hour)), certPath, keyPath)
+	require.Nil(t, watcher.Start(ctx))
+	serial := func() int64 {
+		cert, err := watcher.TLSConfig().GetCertificate(nil)
+		require.Nil(t, err)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.Nil(t, err)
+		return leaf.SerialNumber.Int64()
+	}
+	assert.Equal(t, int64(1), serial())
+
+	// Updated files are picked up before the current certificate is due for renewal
+	writeTestCertificate(t, generateTestCertificate(t, 2, now, now.Add(time.Hour)), certPath, keyPath)
+	later := now.Add(time.Minute)
+	require.Nil(t, os.Chtimes(certPath, later, later))
+	require.Nil(t, os.Chtimes(keyPath, later, later))
+	assert.Eventually(t, func() bool {
+		return serial() == 2
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestWebhookServer_Run_CertificateProvider(t *testing.T) {
+	now := time.Now()
+	cert := generateTestCertificate(t, 42, now, now.Add(time.Hour))
+	port := getFreePort(t)
+	srv, err := NewWebhookServer(WebhookServerConfig{
+		Port: port,
+		TLSConfig: TLSConfig{
+			CertificateProvider: CertificateProviderFunc(func(context.Context) (*tls.Certificate, error) {
+				return cert, nil
+			}),
+		},
+	})
+	require.Nil(t, err)
+	stopCh := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Run(stopCh)
+	}()
+
+	var served *x509.Certificate
+	assert.Eventually(t, func() bool {
+		conn, err := tls.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), &tls.Config{
+			//nolint:gosec
+			InsecureSkipVerify: true,
+		})
+		if err != nil {
+			return false
+		}
+		defer conn.Close()
+		served = conn.ConnectionState().PeerCertificates[0]
+		return true
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NotNil(t, served)
+	assert.Equal(t, int64(42), served.SerialNumber.Int64())
+	close(stopCh)
+	<-errCh
+}
+
+func TestWebhookServer_Run_CertificateError(t *testing.T) {
+	srv, err := NewWebhookServer(WebhookServerConfig{
+		Port: getFreePort(t),
+		TLSConfig: TLSConfig{
+			CertificateProvider: CertificateProviderFunc(func(context.Context) (*tls.Certificate, error) {
+				return nil, errors.New("no cert")
+			}),
+		},
+	})
+	require.Nil(t, err)
+	assert.EqualError(t, srv.Run(make(chan struct{})), "unable to load TLS certificate: no cert")
+}
+
+func generateTestCertificate(t *testing.T, serial int64, notBefore, notAfter time.Time) *tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.Nil(t, err)
+	return &tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	}
+}
+
+func writeTestCertificate(t *testing.T, cert *tls.Certificate, certPath, keyPath string) {
+	t.Helper()
+	key, err := x509.MarshalECPrivateKey(cert.PrivateKey.(*ecdsa.PrivateKey))
+	require.Nil(t, err)
+	require.Nil(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0600))
+	require.Nil(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: key}), 0600))
+}
+
+func getFreePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
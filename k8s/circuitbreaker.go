@@ -0,0 +1,326 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	defaultCircuitBreakerFailureRateThreshold = 0.5
+	defaultCircuitBreakerMinimumRequests      = 20
+	defaultCircuitBreakerWindow               = 30 * time.Second
+	defaultCircuitBreakerOpenDuration         = 30 * time.Second
+	defaultCircuitBreakerHalfOpenProbes       = 1
+)
+
+// ErrCircuitOpen is returned (wrapped in a *CircuitOpenError) by client requests which are rejected
+// because the circuit breaker for their GroupVersion is open. Check for it with errors.Is.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreakerConfig configures the circuit breaker shared by all clients of a GroupVersion (see ClientConfig.CircuitBreaker).
+// While the breaker is closed, requests are made as usual, and it opens once the rate of failed requests
+// (5xx and 429 responses, and requests which fail without a response, such as timeouts) in the current window
+// reaches FailureRateThreshold. While it is open, requests fail immediately with a *CircuitOpenError.
+// After OpenDuration, the breaker is half-open, and allows HalfOpenProbes requests through:
+// if they all succeed, the breaker closes, and if any of them fails, it opens again.
+type CircuitBreakerConfig struct {
+	// FailureRateThreshold is the rate of failed requests in a window (from 0 to 1) at which the breaker opens. Defaults to 0.5.
+	FailureRateThreshold float64
+	// MinimumRequests is the minimum number of requests in a window before the breaker can open, so that a few failures
+	// while there is little traffic don't open it. Defaults to 20.
+	MinimumRequests int
+	// Window is the length of the window the failure rate is measured over. Defaults to 30 seconds.
+	Window time.Duration
+	// OpenDuration is how long the breaker stays open before requests are allowed through to probe the API server.
+	// Defaults to 30 seconds.
+	OpenDuration time.Duration
+	// HalfOpenProbes is the number of requests allowed through while the breaker is half-open,
+	// all of which must succeed for the breaker to close. Defaults to 1.
+	HalfOpenProbes int
+}
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState int
+
+const (
+	// CircuitStateClosed is the state of a breaker which allows all requests
+	CircuitStateClosed CircuitState = iota
+	// CircuitStateHalfOpen is the state of a breaker which allows a limited number of requests through to probe the API server
+	CircuitStateHalfOpen
+	// CircuitStateOpen is the state of a breaker which rejects all requests
+	CircuitStateOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitStateClosed:
+		return "closed"
+	case CircuitStateHalfOpen:
+		return "half-open"
+	case CircuitStateOpen:
+		return "open"
+	default:
+		return fmt.Sprintf("unknown (%d)", s)
+	}
+}
+
+// CircuitOpenError is the error returned by client requests rejected by an open (or half-open) circuit breaker.
+// It wraps ErrCircuitOpen.
+type CircuitOpenError struct {
+	// GroupVersion is the GroupVersion of the breaker which rejected the request
+	GroupVersion schema.GroupVersion
+	// RetryAfter is the time left before the breaker allows requests to probe the API server.
+	// It is zero if the breaker is half-open, and all probe requests are in flight.
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: %s, retry after %s", e.GroupVersion.String(), ErrCircuitOpen.Error(), e.RetryAfter.String())
+}
+
+// Unwrap returns ErrCircuitOpen
+func (*CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// CircuitBreaker is a circuit breaker for the requests made to a single GroupVersion of the API server.
+// It is created by a ClientRegistry or SchemalessClient when ClientConfig.CircuitBreaker is set,
+// and shared by all clients for the GroupVersion. It is safe for concurrent use.
+type CircuitBreaker struct {
+	groupVersion schema.GroupVersion
+	config       CircuitBreakerConfig
+	metrics      *circuitBreakerMetrics
+	mux          sync.Mutex
+	state        CircuitState
+	// generation is incremented on each state change, so that the results of requests
+	// allowed in a previous state are ignored
+	generation  uint64
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probes      int
+	successes   int
+	now         func() time.Time
+}
+
+// NewCircuitBreaker creates a new, closed CircuitBreaker for requests to the GroupVersion.
+// Empty values in config use the defaults described in CircuitBreakerConfig.
+func NewCircuitBreaker(gv schema.GroupVersion, config CircuitBreakerConfig) *CircuitBreaker {
+	return newCircuitBreaker(gv, config, nil)
+}
+
+func newCircuitBreaker(gv schema.GroupVersion, config CircuitBreakerConfig, m *circuitBreakerMetrics) *CircuitBreaker {
+	if config.FailureRateThreshold <= 0 {
+		config.FailureRateThreshold = defaultCircuitBreakerFailureRateThreshold
+	}
+	if config.MinimumRequests <= 0 {
+		config.MinimumRequests = defaultCircuitBreakerMinimumRequests
+	}
+	if config.Window <= 0 {
+		config.Window = defaultCircuitBreakerWindow
+	}
+	if config.OpenDuration <= 0 {
+		config.OpenDuration = defaultCircuitBreakerOpenDuration
+	}
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = defaultCircuitBreakerHalfOpenProbes
+	}
+	b := &CircuitBreaker{
+		groupVersion: gv,
+		config:       config,
+		metrics:      m,
+		now:          time.Now,
+	}
+	b.windowStart = b.now()
+	if m != nil {
+		m.state.WithLabelValues(gv.String()).Set(float64(CircuitStateClosed))
+	}
+	return b
+}
+
+// GroupVersion returns the GroupVersion the breaker is for
+func (b *CircuitBreaker) GroupVersion() schema.GroupVersion {
+	return b.groupVersion
+}
+
+// State returns the current state of the breaker
+func (b *CircuitBreaker) State() CircuitState {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.checkOpenDuration()
+	return b.state
+}
+
+// allow returns the generation to pass to done once the request has completed,
+// or a *CircuitOpenError if the request is rejected. allow and done are no-ops on a nil breaker.
+func (b *CircuitBreaker) allow() (uint64, error) {
+	if b == nil {
+		return 0, nil
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.checkOpenDuration()
+	switch b.state {
+	case CircuitStateOpen:
+		return 0, b.reject(b.config.OpenDuration - b.now().Sub(b.openedAt))
+	case CircuitStateHalfOpen:
+		if b.probes >= b.config.HalfOpenProbes {
+			return 0, b.reject(0)
+		}
+		b.probes++
+	default:
+	}
+	return b.generation, nil
+}
+
+// done records the result of a request allowed with generation
+func (b *CircuitBreaker) done(generation uint64, statusCode int, err error) {
+	if b == nil {
+		return
+	}
+	failure, counted := isCircuitBreakerFailure(statusCode, err)
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if generation != b.generation {
+		return
+	}
+	switch b.state {
+	case CircuitStateClosed:
+		if b.now().Sub(b.windowStart) >= b.config.Window {
+			b.windowStart = b.now()
+			b.requests = 0
+			b.failures = 0
+		}
+		if !counted {
+			return
+		}
+		b.requests++
+		if failure {
+			b.failures++
+		}
+		if b.requests >= b.config.MinimumRequests &&
+			float64(b.failures)/float64(b.requests) >= b.config.FailureRateThreshold {
+			b.setState(CircuitStateOpen)
+		}
+	case CircuitStateHalfOpen:
+		switch {
+		case !counted:
+			// Release the probe, so another request can be made in its place
+			b.probes--
+		case failure:
+			b.setState(CircuitStateOpen)
+		default:
+			b.successes++
+			if b.successes >= b.config.HalfOpenProbes {
+				b.setState(CircuitStateClosed)
+			}
+		}
+	default:
+	}
+}
+
+// checkOpenDuration moves an open breaker to half-open once it has been open for OpenDuration.
+// It must be called with the lock held.
+func (b *CircuitBreaker) checkOpenDuration() {
+	if b.state == CircuitStateOpen && b.now().Sub(b.openedAt) >= b.config.OpenDuration {
+		b.setState(CircuitStateHalfOpen)
+	}
+}
+
+// setState must be called with the lock held
+func (b *CircuitBreaker) setState(state CircuitState) {
+	b.state = state
+	b.generation++
+	b.probes = 0
+	b.successes = 0
+	switch state {
+	case CircuitStateOpen:
+		b.openedAt = b.now()
+	case CircuitStateClosed:
+		b.windowStart = b.now()
+		b.requests = 0
+		b.failures = 0
+	default:
+	}
+	if b.metrics != nil {
+		b.metrics.state.WithLabelValues(b.groupVersion.String()).Set(float64(state))
+		b.metrics.transitions.WithLabelValues(b.groupVersion.String(), state.String()).Inc()
+	}
+}
+
+func (b *CircuitBreaker) reject(retryAfter time.Duration) error {
+	if b.metrics != nil {
+		b.metrics.rejectedRequests.WithLabelValues(b.groupVersion.String()).Inc()
+	}
+	return &CircuitOpenError{
+		GroupVersion: b.groupVersion,
+		RetryAfter:   max(retryAfter, 0),
+	}
+}
+
+// isCircuitBreakerFailure returns whether a request with the status code and error is a failure,
+// and whether it should be counted by the breaker at all. Requests canceled by the caller are not counted.
+func isCircuitBreakerFailure(statusCode int, err error) (failure bool, counted bool) {
+	if statusCode == 0 && err != nil {
+		statusErr := &k8serrors.StatusError{}
+		if errors.As(err, &statusErr) {
+			statusCode = int(statusErr.ErrStatus.Code)
+		}
+	}
+	switch {
+	case statusCode >= http.StatusInternalServerError, statusCode == http.StatusTooManyRequests:
+		return true, true
+	case statusCode > 0:
+		return false, true
+	case err == nil:
+		return false, true
+	case errors.Is(err, context.Canceled):
+		return false, false
+	default:
+		// No response, such as a timeout or a connection error
+		return true, true
+	}
+}
+
+// circuitBreakerMetrics are the metrics shared by the circuit breakers of a ClientRegistry or SchemalessClient
+type circuitBreakerMetrics struct {
+	state            *prometheus.GaugeVec
+	transitions      *prometheus.CounterVec
+	rejectedRequests *prometheus.CounterVec
+}
+
+func newCircuitBreakerMetrics(namespace string) *circuitBreakerMetrics {
+	return &circuitBreakerMetrics{
+		state: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "kubernetes_client",
+			Name:      "circuit_breaker_state",
+			Help:      "Current state of the circuit breaker for a GroupVersion (0 is closed, 1 is half-open, and 2 is open).",
+		}, []string{"group_version"}),
+		transitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "kubernetes_client",
+			Name:      "circuit_breaker_transitions_total",
+			Help:      "Total number of circuit breaker state changes, by the state changed to.",
+		}, []string{"group_version", "state"}),
+		rejectedRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "kubernetes_client",
+			Name:      "circuit_breaker_rejected_requests_total",
+			Help:      "Total number of requests rejected by an open or half-open circuit breaker.",
+		}, []string{"group_version"}),
+	}
+}
+
+func (m *circuitBreakerMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.state, m.transitions, m.rejectedRequests}
+}
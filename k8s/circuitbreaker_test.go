@@ -0,0 +1,206 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	gv := schema.GroupVersion{Group: "foo.grafana.app", Version: "v1"}
+	newBreaker := func() (*CircuitBreaker, *circuitBreakerMetrics, *time.Time) {
+		m := newCircuitBreakerMetrics("")
+		b := newCircuitBreaker(gv, CircuitBreakerConfig{
+			FailureRateThreshold: 0.5,
+			MinimumRequests:      4,
+			Window:               10 * time.Second,
+			OpenDuration:         30 * time.Second,
+			HalfOpenProbes:       2,
+		}, m)
+		now := time.Now()
+		b.now = func() time.Time { return now }
+		b.windowStart = now
+		return b, m, &now
+	}
+	request := func(t *testing.T, b *CircuitBreaker, statusCode int, err error) {
+		t.Helper()
+		gen, aerr := b.allow()
+		require.Nil(t, aerr)
+		b.done(gen, statusCode, err)
+	}
+	open := func(t *testing.T, b *CircuitBreaker) {
+		t.Helper()
+		for i := 0; i < 4; i++ {
+			request(t, b, http.StatusServiceUnavailable, errors.New("unavailable"))
+		}
+		require.Equal(t, CircuitStateOpen, b.State())
+	}
+
+	t.Run("opens at failure rate", func(t *testing.T) {
+		b, m, now := newBreaker()
+		request(t, b, http.StatusOK, nil)
+		request(t, b, http.StatusNotFound, errors.New("not found"))
+		request(t, b, http.StatusTooManyRequests, errors.New("slow down"))
+		assert.Equal(t, CircuitStateClosed, b.State())
+		request(t, b, 0, context.DeadlineExceeded)
+		assert.Equal(t, CircuitStateOpen, b.State())
+
+		*now = now.Add(10 * time.Second)
+		_, err := b.allow()
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		openErr := &CircuitOpenError{}
+		require.True(t, errors.As(err, &openErr))
+		assert.Equal(t, gv, openErr.GroupVersion)
+		assert.Equal(t, 20*time.Second, openErr.RetryAfter)
+		assert.Equal(t, float64(CircuitStateOpen), testutil.ToFloat64(m.state.WithLabelValues(gv.String())))
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.rejectedRequests.WithLabelValues(gv.String())))
+	})
+
+	t.Run("minimum requests and window", func(t *testing.T) {
+		b, _, now := newBreaker()
+		request(t, b, http.StatusInternalServerError, errors.New("oops"))
+		request(t, b, http.StatusInternalServerError, errors.New("oops"))
+		request(t, b, http.StatusInternalServerError, errors.New("oops"))
+		assert.Equal(t, CircuitStateClosed, b.State())
+		// The failures are in the previous window
+		*now = now.Add(10 * time.Second)
+		request(t, b, http.StatusInternalServerError, errors.New("oops"))
+		request(t, b, http.StatusOK, nil)
+		request(t, b, http.StatusOK, nil)
+		request(t, b, http.StatusOK, nil)
+		assert.Equal(t, CircuitStateClosed, b.State())
+		// Canceled requests are not counted
+		request(t, b, 0, context.Canceled)
+		request(t, b, 0, fmt.Errorf("request failed: %w", context.Canceled))
+		assert.Equal(t, CircuitStateClosed, b.State())
+	})
+
+	t.Run("half-open probes close", func(t *testing.T) {
+		b, m, now := newBreaker()
+		open(t, b)
+		*now = now.Add(30 * time.Second)
+		assert.Equal(t, CircuitStateHalfOpen, b.State())
+		gen1, err := b.allow()
+		require.Nil(t, err)
+		gen2, err := b.allow()
+		require.Nil(t, err)
+		_, err = b.allow()
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		b.done(gen1, http.StatusOK, nil)
+		assert.Equal(t, CircuitStateHalfOpen, b.State())
+		b.done(gen2, http.StatusOK, nil)
+		assert.Equal(t, CircuitStateClosed, b.State())
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.transitions.WithLabelValues(gv.String(), "closed")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.transitions.WithLabelValues(gv.String(), "half-open")))
+	})
+
+	t.Run("half-open probe failure reopens", func(t *testing.T) {
+		b, _, now := newBreaker()
+		open(t, b)
+		*now = now.Add(30 * time.Second)
+		gen, err := b.allow()
+		require.Nil(t, err)
+		// A canceled probe releases its slot
+		b.done(gen, 0, context.Canceled)
+		assert.Equal(t, CircuitStateHalfOpen, b.State())
+		gen, err = b.allow()
+		require.Nil(t, err)
+		b.done(gen, http.StatusOK, nil)
+		gen, err = b.allow()
+		require.Nil(t, err)
+		b.done(gen, 0, k8serrors.NewServiceUnavailable("unavailable"))
+		assert.Equal(t, CircuitStateOpen, b.State())
+		_, err = b.allow()
+		openErr := &CircuitOpenError{}
+		require.True(t, errors.As(err, &openErr))
+		assert.Equal(t, 30*time.Second, openErr.RetryAfter)
+	})
+
+	t.Run("stale results are ignored", func(t *testing.T) {
+		b, _, now := newBreaker()
+		stale, err := b.allow()
+		require.Nil(t, err)
+		open(t, b)
+		*now = now.Add(30 * time.Second)
+		assert.Equal(t, CircuitStateHalfOpen, b.State())
+		b.done(stale, http.StatusInternalServerError, errors.New("oops"))
+		assert.Equal(t, CircuitStateHalfOpen, b.State())
+	})
+
+	t.Run("nil breaker", func(t *testing.T) {
+		var b *CircuitBreaker
+		gen, err := b.allow()
+		assert.Nil(t, err)
+		b.done(gen, http.StatusInternalServerError, errors.New("oops"))
+	})
+}
+
+func TestClientRegistry_CircuitBreaker(t *testing.T) {
+	requests := atomic.Int64{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	kind := func(group, version, kind string) resource.Kind {
+		return resource.Kind{
+			Schema: resource.NewSimpleSchema(group, version, &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind(kind)),
+			Codecs: map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()},
+		}
+	}
+	registry := NewClientRegistry(rest.Config{Host: srv.URL, APIPath: "/apis"}, ClientConfig{
+		CircuitBreaker: &CircuitBreakerConfig{
+			MinimumRequests: 2,
+		},
+	})
+	foos, err := registry.ClientFor(kind("foo.grafana.app", "v1", "Foo"))
+	require.Nil(t, err)
+	bars, err := registry.ClientFor(kind("foo.grafana.app", "v1", "Bar"))
+	require.Nil(t, err)
+	v2Foos, err := registry.ClientFor(kind("foo.grafana.app", "v2", "Foo"))
+	require.Nil(t, err)
+	ctx := context.Background()
+	id := resource.Identifier{Namespace: "ns", Name: "foo"}
+
+	_, err = foos.Get(ctx, id)
+	require.NotNil(t, err)
+	assert.NotErrorIs(t, err, ErrCircuitOpen)
+	err = bars.Delete(ctx, id, resource.DeleteOptions{})
+	require.NotNil(t, err)
+	assert.NotErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int64(2), requests.Load())
+	assert.Equal(t, CircuitStateOpen, registry.CircuitBreakerFor(schema.GroupVersion{Group: "foo.grafana.app", Version: "v1"}).State())
+
+	// The breaker is shared by all kinds in the GroupVersion, and covers watches
+	_, err = foos.Get(ctx, id)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	_, err = bars.List(ctx, "ns", resource.ListOptions{})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	_, err = bars.Watch(ctx, "ns", resource.WatchOptions{})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int64(2), requests.Load())
+
+	// Other GroupVersions have their own breaker
+	_, err = v2Foos.Get(ctx, id)
+	assert.NotErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int64(3), requests.Load())
+	assert.Equal(t, CircuitStateClosed, registry.CircuitBreakerFor(schema.GroupVersion{Group: "foo.grafana.app", Version: "v2"}).State())
+
+	// No breaker without a CircuitBreakerConfig
+	assert.Nil(t, NewClientRegistry(rest.Config{}, ClientConfig{}).CircuitBreakerFor(schema.GroupVersion{Group: "foo.grafana.app", Version: "v1"}))
+}
@@ -3,10 +3,13 @@ package k8s
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
 
 	"github.com/grafana/grafana-app-sdk/metrics"
@@ -41,11 +44,66 @@ type ClientConfig struct {
 
 	MetricsConfig metrics.Config
 
+	// SlowRequestThresholds is an optional map of request verb (GET, LIST, CREATE, UPDATE, PATCH, or DELETE)
+	// to the latency at or above which a request is considered slow. Slow requests are logged at the warn level
+	// with their kind, namespace, name, latency, and status code, and observed in the slow request duration summary metric.
+	// The key SlowRequestThresholdAnyVerb applies to verbs without their own entry.
+	// If empty, slow request logging is disabled.
+	SlowRequestThresholds map[string]time.Duration
+
+	// OperationTimeout is the maximum duration of each request made by the client, other than watch requests,
+	// which are long-lived. A request which exceeds it is aborted, and returns a context deadline exceeded error.
+	// A shorter deadline on the context passed to a client call still applies. If zero, no timeout is applied.
+	OperationTimeout time.Duration
+
+	// DeadlineReserve is reserved from the deadline of the context passed to each client call, other than watch requests,
+	// so that a request ends with DeadlineReserve left for the caller to handle its response or error before the caller's
+	// own deadline (see resource.WithDeadlineBudget). If zero, requests may run until the deadline of the context.
+	DeadlineReserve time.Duration
+
+	// PropagateDeadline, if true, sends the time left before the deadline of each request's context to the API server
+	// as the timeout of the request, so that the API server stops working on requests the client has stopped waiting for.
+	// Watch requests are not affected.
+	PropagateDeadline bool
+
+	// Pruners is an optional map of kind to resource.Pruner. When an object of a kind with a Pruner is written
+	// with a create, update (including subresource updates), or server-side apply request, fields not present in the kind's schema
+	// are removed from the request body before it is sent. The object passed to the call is not modified.
+	// This applies to writes made by a resource.Store or resource.TypedStore using the client as well.
+	Pruners map[schema.GroupVersionKind]*resource.Pruner
+
+	// OnPrune, if non-nil, is called with the identifier of the written object and the paths of the fields pruned
+	// from it by a Pruner, whenever any fields are pruned. If nil, pruned fields are logged at the warn level.
+	OnPrune func(ctx context.Context, identifier resource.FullIdentifier, paths []string)
+
+	// ValidateFieldSelectors enables client-side validation of the FieldSelectors in list and watch requests.
+	// When true, a request with a field selector on a field which is not selectable for the kind
+	// (see resource.SelectableFieldNames) returns a *resource.InvalidFieldSelectorError without making a request.
+	// Clients returned by ClientRegistry.DynamicClientFor do not validate field selectors, as their selectable fields are unknown.
+	ValidateFieldSelectors bool
+
+	// UseCBOR enables the CBOR wire encoding, supported by kubernetes 1.32+ (behind the CBORServingAndStorage feature gate),
+	// for smaller request and response payloads. When true, create and update request bodies are sent as CBOR,
+	// and get, list, create, update, and patch requests accept a CBOR response, falling back to JSON if the server
+	// doesn't support CBOR. Objects are still encoded and decoded with the kind's JSON codec, and transcoded to and from CBOR.
+	// Patch requests and watches always use JSON.
+	UseCBOR bool
+
+	// CircuitBreaker, if non-nil, enables a circuit breaker for the requests made to each GroupVersion,
+	// shared by all clients for the GroupVersion created by the same ClientRegistry or SchemalessClient.
+	// While the breaker for a GroupVersion is open, requests fail immediately with a *CircuitOpenError (see ErrCircuitOpen)
+	// instead of adding load to an API server which is already failing. See CircuitBreakerConfig for how the breaker opens and closes.
+	// Informers which list and watch with the clients back off until the breaker allows requests again.
+	CircuitBreaker *CircuitBreakerConfig
+
 	// NegotiatedSerializerProvider is a function which provides a runtime.NegotiatedSerializer for the underlying
 	// kubernetes rest.RESTClient, if defined.
 	NegotiatedSerializerProvider func(kind resource.Kind) runtime.NegotiatedSerializer
 }
 
+// SlowRequestThresholdAnyVerb is the key in ClientConfig.SlowRequestThresholds used for any verb without its own threshold
+const SlowRequestThresholdAnyVerb = "*"
+
 // DefaultClientConfig returns a ClientConfig using defaults that assume you have used the SDK codegen tooling
 func DefaultClientConfig() ClientConfig {
 	return ClientConfig{
@@ -61,27 +119,38 @@ func DefaultClientConfig() ClientConfig {
 
 // List lists resources in the provided namespace.
 // For resources with a schema.Scope() of ClusterScope, `namespace` must be resource.NamespaceAll
+// If the kind uses strict decoding and some items have unknown fields, List returns the full list
+// along with a *resource.StrictDecodingListError.
 func (c *Client) List(ctx context.Context, namespace string, options resource.ListOptions) (
 	resource.ListObject, error) {
+	if err := c.validateFieldSelectors(options.FieldSelectors); err != nil {
+		return nil, err
+	}
 	into := resource.UntypedList{}
 	err := c.client.list(ctx, namespace, c.schema.Plural(), &into, options, func(raw []byte) (resource.Object, error) {
 		into := c.schema.ZeroValue()
 		err := c.codec.Read(bytes.NewReader(raw), into)
 		return into, err
 	})
-	if err != nil {
+	var strictErr *resource.StrictDecodingListError
+	if err != nil && !errors.As(err, &strictErr) {
 		return nil, err
 	}
 	return &into, err
 }
 
 // ListInto lists resources in the provided namespace, and unmarshals the response into the provided resource.ListObject
+// If the kind uses strict decoding and some items have unknown fields, every item is still unmarshaled into `into`,
+// and a *resource.StrictDecodingListError is returned.
 func (c *Client) ListInto(ctx context.Context, namespace string, options resource.ListOptions,
 	into resource.ListObject) error {
 	if c.schema.Scope() == resource.ClusterScope && namespace != resource.NamespaceAll {
 		return fmt.Errorf("cannot list resources with schema scope \"%s\" in namespace \"%s\", must be NamespaceAll (\"%s\")",
 			resource.ClusterScope, namespace, resource.NamespaceAll)
 	}
+	if err := c.validateFieldSelectors(options.FieldSelectors); err != nil {
+		return err
+	}
 	return c.client.list(ctx, namespace, c.schema.Plural(), into, options,
 		func(raw []byte) (resource.Object, error) {
 			into := c.schema.ZeroValue()
@@ -195,7 +264,9 @@ func (c *Client) UpdateInto(ctx context.Context, identifier resource.Identifier,
 	return c.client.update(ctx, c.schema.Plural(), obj, into, options, c.codec)
 }
 
-// Patch performs a JSON Patch on the provided resource, and returns the updated object
+// Patch performs a patch on the provided resource, and returns the updated object.
+// By default, the patch is a JSON Patch, but merge, strategic merge, and server-side apply patches can be made by setting
+// patch.Type (see resource.PatchBuilder).
 func (c *Client) Patch(ctx context.Context, identifier resource.Identifier, patch resource.PatchRequest,
 	options resource.PatchOptions) (resource.Object, error) {
 	into := c.schema.ZeroValue()
@@ -206,10 +277,54 @@ func (c *Client) Patch(ctx context.Context, identifier resource.Identifier, patc
 	return into, nil
 }
 
-// PatchInto performs a JSON Patch on the provided resource, and marshals the updated version into the `into` field
+// PatchInto performs a patch on the provided resource, and marshals the updated version into the `into` field
 func (c *Client) PatchInto(ctx context.Context, identifier resource.Identifier, patch resource.PatchRequest,
 	options resource.PatchOptions, into resource.Object) error {
-	return c.client.patch(ctx, identifier, c.schema.Plural(), patch, into, options, c.codec)
+	gvk := schema.GroupVersionKind{Group: c.schema.Group(), Version: c.schema.Version(), Kind: c.schema.Kind()}
+	return c.client.patch(ctx, identifier, gvk, c.schema.Plural(), patch, into, options, c.codec)
+}
+
+// Apply performs a server-side apply of obj, creating the resource if it does not exist, and returns the resulting resource.
+// The field manager in options takes ownership of every field present in obj, so obj should only contain the fields
+// the caller intends to manage. If obj has a ResourceVersion set, the apply fails if it does not match the current one.
+func (c *Client) Apply(ctx context.Context, identifier resource.Identifier, obj resource.Object,
+	options resource.ApplyOptions) (resource.Object, error) {
+	into := c.schema.ZeroValue()
+	err := c.ApplyInto(ctx, identifier, obj, options, into)
+	if err != nil {
+		return nil, err
+	}
+	return into, nil
+}
+
+// ApplyInto performs a server-side apply of obj, and marshals the resulting resource into `into`
+func (c *Client) ApplyInto(ctx context.Context, identifier resource.Identifier, obj resource.Object,
+	options resource.ApplyOptions, into resource.Object) error {
+	if obj == nil {
+		return fmt.Errorf("obj cannot be nil")
+	}
+	if into == nil {
+		return fmt.Errorf("into cannot be nil")
+	}
+	if options.FieldManager == "" {
+		return fmt.Errorf("apply requires a field manager")
+	}
+	// Set identifying metadata without modifying the caller's object
+	toApply := obj.Copy()
+	toApply.SetStaticMetadata(resource.StaticMetadata{
+		Namespace: identifier.Namespace,
+		Name:      identifier.Name,
+		Group:     c.schema.Group(),
+		Version:   c.schema.Version(),
+		Kind:      c.schema.Kind(),
+	})
+	return c.PatchInto(ctx, identifier, resource.PatchRequest{
+		Type:   resource.PatchTypeApply,
+		Object: toApply,
+	}, resource.PatchOptions{
+		FieldManager: options.FieldManager,
+		Force:        options.ForceConflicts,
+	}, into)
 }
 
 // Delete deletes the specified resource
@@ -225,9 +340,19 @@ func (c *Client) Watch(ctx context.Context, namespace string, options resource.W
 		return nil, fmt.Errorf("cannot watch resources with schema scope \"%s\" in namespace \"%s\", must be NamespaceAll (\"%s\")",
 			resource.ClusterScope, namespace, resource.NamespaceAll)
 	}
+	if err := c.validateFieldSelectors(options.FieldSelectors); err != nil {
+		return nil, err
+	}
 	return c.client.watch(ctx, namespace, c.schema.Plural(), c.schema.ZeroValue(), options, c.codec)
 }
 
+func (c *Client) validateFieldSelectors(selectors []string) error {
+	if !c.config.ValidateFieldSelectors {
+		return nil
+	}
+	return resource.ValidateFieldSelectors(c.schema, selectors)
+}
+
 // Metrics returns the prometheus collectors used by this Client for registration with a prometheus exporter
 func (c *Client) PrometheusCollectors() []prometheus.Collector {
 	return c.client.metrics()
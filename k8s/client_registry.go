@@ -2,11 +2,13 @@ package k8s
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
 
 	"github.com/grafana/grafana-app-sdk/metrics"
@@ -22,6 +24,8 @@ func NewClientRegistry(kubeCconfig rest.Config, clientConfig ClientConfig) *Clie
 
 	return &ClientRegistry{
 		clients:      make(map[schema.GroupVersionKind]rest.Interface),
+		dynamicKinds: make(map[schema.GroupVersionKind]resource.Kind),
+		breakers:     make(map[schema.GroupVersion]*CircuitBreaker),
 		cfg:          kubeCconfig,
 		clientConfig: clientConfig,
 		requestDurations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
@@ -40,6 +44,8 @@ func NewClientRegistry(kubeCconfig rest.Config, clientConfig ClientConfig) *Clie
 			Namespace: clientConfig.MetricsConfig.Namespace,
 			Help:      "Total number of kubernetes requests",
 		}, []string{"status_code", "verb", "kind", "subresource"}),
+		slowRequests:   newSlowRequestsSummary(clientConfig.MetricsConfig.Namespace),
+		breakerMetrics: newCircuitBreakerMetrics(clientConfig.MetricsConfig.Namespace),
 	}
 }
 
@@ -47,11 +53,16 @@ func NewClientRegistry(kubeCconfig rest.Config, clientConfig ClientConfig) *Clie
 // GroupVersion (the largest unit a kubernetes rest.RESTClient can work with).
 type ClientRegistry struct {
 	clients          map[schema.GroupVersionKind]rest.Interface
+	dynamicKinds     map[schema.GroupVersionKind]resource.Kind
+	discovery        discovery.DiscoveryInterface
 	cfg              rest.Config
 	clientConfig     ClientConfig
 	mutex            sync.Mutex
 	requestDurations *prometheus.HistogramVec
 	totalRequests    *prometheus.CounterVec
+	slowRequests     *prometheus.SummaryVec
+	breakers         map[schema.GroupVersion]*CircuitBreaker
+	breakerMetrics   *circuitBreakerMetrics
 }
 
 // ClientFor returns a Client with the underlying rest.Interface being a cached one for the Schema's GroupVersion.
@@ -72,6 +83,8 @@ func (c *ClientRegistry) ClientFor(sch resource.Kind) (resource.Client, error) {
 			config:           c.clientConfig,
 			requestDurations: c.requestDurations,
 			totalRequests:    c.totalRequests,
+			slowRequests:     c.slowRequests,
+			breaker:          c.CircuitBreakerFor(schema.GroupVersion{Group: sch.Group(), Version: sch.Version()}),
 		},
 		schema: sch,
 		codec:  codec,
@@ -79,11 +92,88 @@ func (c *ClientRegistry) ClientFor(sch resource.Kind) (resource.Client, error) {
 	}, nil
 }
 
+// DynamicClientFor returns a Client for the provided GroupVersionKind, for use when there is no compile-time resource.Kind
+// for the kind (such as kinds discovered from an app manifest at runtime). Objects returned by the client are
+// *resource.UntypedObject, and lists are *resource.UntypedList. The plural and scope of the kind are looked up
+// from the API server with discovery on the first call for each GroupVersionKind, and cached for subsequent calls.
+// The client otherwise behaves like one returned by ClientFor, using the same JSON codec and ClientConfig
+// (other than ClientConfig.ValidateFieldSelectors, which is ignored).
+func (c *ClientRegistry) DynamicClientFor(gvk schema.GroupVersionKind) (resource.Client, error) {
+	kind, err := c.getDynamicKind(gvk)
+	if err != nil {
+		return nil, err
+	}
+	client, err := c.ClientFor(kind)
+	if err != nil {
+		return nil, err
+	}
+	// The selectable fields of a kind aren't available from discovery, so selectors are left to the API server to validate
+	cast := client.(*Client)
+	cast.config.ValidateFieldSelectors = false
+	return cast, nil
+}
+
+func (c *ClientRegistry) getDynamicKind(gvk schema.GroupVersionKind) (resource.Kind, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if kind, ok := c.dynamicKinds[gvk]; ok {
+		return kind, nil
+	}
+
+	if c.discovery == nil {
+		disc, err := discovery.NewDiscoveryClientForConfig(&c.cfg)
+		if err != nil {
+			return resource.Kind{}, fmt.Errorf("error creating discovery client: %w", err)
+		}
+		c.discovery = disc
+	}
+	resources, err := c.discovery.ServerResourcesForGroupVersion(gvk.GroupVersion().String())
+	if err != nil {
+		return resource.Kind{}, fmt.Errorf("unable to discover resources for %s: %w", gvk.GroupVersion(), err)
+	}
+	for _, res := range resources.APIResources {
+		// Skip subresources, which have the same kind as their parent resource
+		if res.Kind != gvk.Kind || strings.Contains(res.Name, "/") {
+			continue
+		}
+		scope := resource.ClusterScope
+		if res.Namespaced {
+			scope = resource.NamespacedScope
+		}
+		kind := resource.Kind{
+			Schema: resource.NewSimpleSchema(gvk.Group, gvk.Version, &resource.UntypedObject{}, &resource.UntypedList{},
+				resource.WithKind(gvk.Kind), resource.WithPlural(res.Name), resource.WithScope(scope)),
+			Codecs: map[resource.KindEncoding]resource.Codec{
+				resource.KindEncodingJSON: resource.NewJSONCodec(),
+			},
+		}
+		c.dynamicKinds[gvk] = kind
+		return kind, nil
+	}
+	return resource.Kind{}, fmt.Errorf("kind '%s' is not served by the API server for %s", gvk.Kind, gvk.GroupVersion())
+}
+
+// CircuitBreakerFor returns the CircuitBreaker shared by all clients for the GroupVersion generated by this ClientRegistry,
+// creating it if it doesn't exist yet. It returns nil if ClientConfig.CircuitBreaker is nil.
+func (c *ClientRegistry) CircuitBreakerFor(gv schema.GroupVersion) *CircuitBreaker {
+	if c.clientConfig.CircuitBreaker == nil {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if b, ok := c.breakers[gv]; ok {
+		return b
+	}
+	b := newCircuitBreaker(gv, *c.clientConfig.CircuitBreaker, c.breakerMetrics)
+	c.breakers[gv] = b
+	return b
+}
+
 // PrometheusCollectors returns the prometheus metric collectors used by all clients generated by this ClientRegistry to allow for registration
 func (c *ClientRegistry) PrometheusCollectors() []prometheus.Collector {
-	return []prometheus.Collector{
-		c.totalRequests, c.requestDurations,
-	}
+	return append([]prometheus.Collector{
+		c.totalRequests, c.requestDurations, c.slowRequests,
+	}, c.breakerMetrics.collectors()...)
 }
 
 func (c *ClientRegistry) getClient(sch resource.Kind) (rest.Interface, error) {
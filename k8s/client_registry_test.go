@@ -0,0 +1,84 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestClientRegistry_DynamicClientFor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apis/foo.grafana.app/v1/namespaces/ns/foos/bar" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		//nolint:errcheck
+		w.Write([]byte(`{"apiVersion":"foo.grafana.app/v1","kind":"Foo","metadata":{"name":"bar","namespace":"ns"},"spec":{"value":"baz"}}`))
+	}))
+	defer srv.Close()
+
+	fake := &clienttesting.Fake{}
+	fake.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "foo.grafana.app/v1",
+		APIResources: []metav1.APIResource{{
+			Name:       "foos/status",
+			Namespaced: true,
+			Kind:       "Foo",
+		}, {
+			Name:       "foos",
+			Namespaced: true,
+			Kind:       "Foo",
+		}, {
+			Name: "clusterfoos",
+			Kind: "ClusterFoo",
+		}},
+	}}
+	registry := NewClientRegistry(rest.Config{Host: srv.URL, APIPath: "/apis"}, ClientConfig{})
+	registry.discovery = &fakediscovery.FakeDiscovery{Fake: fake}
+
+	t.Run("namespaced kind", func(t *testing.T) {
+		client, err := registry.DynamicClientFor(schema.GroupVersionKind{Group: "foo.grafana.app", Version: "v1", Kind: "Foo"})
+		require.Nil(t, err)
+		cast, ok := client.(*Client)
+		require.True(t, ok)
+		assert.Equal(t, "foos", cast.schema.Plural())
+		assert.Equal(t, resource.NamespacedScope, cast.schema.Scope())
+
+		obj, err := client.Get(context.Background(), resource.Identifier{Namespace: "ns", Name: "bar"})
+		require.Nil(t, err)
+		untyped, ok := obj.(*resource.UntypedObject)
+		require.True(t, ok)
+		assert.Equal(t, "bar", untyped.GetName())
+		assert.Equal(t, map[string]any{"value": "baz"}, untyped.Spec)
+	})
+
+	t.Run("cluster-scoped kind", func(t *testing.T) {
+		client, err := registry.DynamicClientFor(schema.GroupVersionKind{Group: "foo.grafana.app", Version: "v1", Kind: "ClusterFoo"})
+		require.Nil(t, err)
+		assert.Equal(t, resource.ClusterScope, client.(*Client).schema.Scope())
+	})
+
+	t.Run("kind not served", func(t *testing.T) {
+		_, err := registry.DynamicClientFor(schema.GroupVersionKind{Group: "foo.grafana.app", Version: "v1", Kind: "Bar"})
+		assert.EqualError(t, err, "kind 'Bar' is not served by the API server for foo.grafana.app/v1")
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		fake.ClearActions()
+		_, err := registry.DynamicClientFor(schema.GroupVersionKind{Group: "foo.grafana.app", Version: "v1", Kind: "Foo"})
+		require.Nil(t, err)
+		assert.Empty(t, fake.Actions())
+	})
+}
@@ -3,12 +3,14 @@ package k8s
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -88,6 +90,75 @@ func TestClient_Get(t *testing.T) {
 		assert.Equal(t, responseObj.GetSpec(), resp.GetSpec())
 		assert.Equal(t, responseObj.GetSubresources(), resp.GetSubresources())
 	})
+
+	t.Run("operation timeout", func(t *testing.T) {
+		client.client.config = ClientConfig{
+			OperationTimeout: 50 * time.Millisecond,
+		}
+		defer func() {
+			client.client.config = ClientConfig{}
+		}()
+		release := make(chan struct{})
+		exited := make(chan struct{})
+		server.responseFunc = func(writer http.ResponseWriter, r *http.Request) {
+			defer close(exited)
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}
+
+		start := time.Now()
+		resp, err := client.Get(ctx, id)
+		assert.Nil(t, resp)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected context.DeadlineExceeded, got %v", err)
+		// The in-flight request is aborted when the timeout is reached
+		assert.Less(t, time.Since(start), time.Second)
+		// Wait for the handler to exit, so that later subtests can safely replace server.responseFunc
+		close(release)
+		<-exited
+	})
+
+	t.Run("deadline reserve and propagation", func(t *testing.T) {
+		client.client.config = ClientConfig{
+			DeadlineReserve:   20 * time.Second,
+			PropagateDeadline: true,
+		}
+		defer func() {
+			client.client.config = ClientConfig{}
+		}()
+		var timeout time.Duration
+		server.responseFunc = func(writer http.ResponseWriter, r *http.Request) {
+			var err error
+			timeout, err = time.ParseDuration(r.URL.Query().Get("timeout"))
+			assert.Nil(t, err)
+			writer.Write(responseBytes)
+		}
+
+		deadlineCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+		_, err := client.Get(deadlineCtx, id)
+		require.Nil(t, err)
+		// The API server gets the time left before the caller's deadline, less the reserve
+		assert.InDelta(t, 40*time.Second, timeout, float64(time.Second))
+
+		// Without a deadline, no timeout is sent
+		server.responseFunc = func(writer http.ResponseWriter, r *http.Request) {
+			assert.False(t, r.URL.Query().Has("timeout"))
+			writer.Write(responseBytes)
+		}
+		_, err = client.Get(ctx, id)
+		require.Nil(t, err)
+
+		// If less than the reserve is left, the request is not made
+		server.responseFunc = func(writer http.ResponseWriter, r *http.Request) {
+			assert.Fail(t, "HTTP request should not be made without enough time left")
+		}
+		shortCtx, shortCancel := context.WithTimeout(ctx, time.Second)
+		defer shortCancel()
+		_, err = client.Get(shortCtx, id)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected context.DeadlineExceeded, got %v", err)
+	})
 }
 
 func TestClient_GetInto(t *testing.T) {
@@ -193,6 +264,48 @@ func TestClient_Create(t *testing.T) {
 		assert.Equal(t, responseObj.GetSpec(), resp.GetSpec())
 		assert.Equal(t, responseObj.GetSubresources(), resp.GetSubresources())
 	})
+
+	t.Run("pruned", func(t *testing.T) {
+		pruner, err := resource.NewPruner(map[string]any{
+			"spec": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"Test1": map[string]any{"type": "string"},
+				},
+			},
+		})
+		require.Nil(t, err)
+		var prunedPaths []string
+		client.client.config = ClientConfig{
+			Pruners: map[schema.GroupVersionKind]*resource.Pruner{
+				{Group: testSchema.Group(), Version: testSchema.Version(), Kind: testSchema.Kind()}: pruner,
+			},
+			OnPrune: func(_ context.Context, identifier resource.FullIdentifier, paths []string) {
+				assert.Equal(t, id.Name, identifier.Name)
+				prunedPaths = paths
+			},
+		}
+		defer func() {
+			client.client.config = ClientConfig{}
+		}()
+		server.responseFunc = func(writer http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.Nil(t, err)
+			posted := submittedObj{}
+			require.Nil(t, json.Unmarshal(body, &posted))
+			assert.Equal(t, id.Name, posted.ObjectMetadata.Name)
+			assert.Equal(t, testSpec{Test1: "111"}, posted.Spec)
+			writer.Write(responseBytes)
+			writer.WriteHeader(http.StatusOK)
+		}
+
+		obj := getTestObject()
+		_, err = client.Create(ctx, id, obj, resource.CreateOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"spec.Test2"}, prunedPaths)
+		// The provided object is not modified
+		assert.Equal(t, "test", obj.Spec.Test2)
+	})
 }
 
 func TestClient_CreateInto(t *testing.T) {
@@ -565,6 +678,126 @@ func TestClient_Delete(t *testing.T) {
 	})
 }
 
+func TestClient_Patch(t *testing.T) {
+	client, server := getClientTestSetup(testKind)
+	defer server.Close()
+	id := resource.Identifier{
+		Namespace: "ns",
+		Name:      "testo",
+	}
+	ctx := context.TODO()
+
+	t.Run("json patch", func(t *testing.T) {
+		server.responseFunc = func(writer http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPatch, r.Method)
+			assert.Equal(t, string(types.JSONPatchType), r.URL.Query().Get("patchType"))
+			body, err := io.ReadAll(r.Body)
+			require.Nil(t, err)
+			assert.JSONEq(t, `[{"path":"/spec/Test1","op":"replace","value":"foo"}]`, string(body))
+			writer.Write(responseBytes)
+		}
+		patch, opts, err := resource.NewPatchBuilder().Replace("/spec/Test1", "foo").Build()
+		require.Nil(t, err)
+		_, err = client.Patch(ctx, id, patch, opts)
+		assert.Nil(t, err)
+	})
+
+	t.Run("merge patch", func(t *testing.T) {
+		server.responseFunc = func(writer http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, string(types.MergePatchType), r.URL.Query().Get("patchType"))
+			assert.Equal(t, "", r.URL.Query().Get("fieldManager"))
+			m := make(map[string]any)
+			require.Nil(t, json.NewDecoder(r.Body).Decode(&m))
+			assert.Equal(t, map[string]any{"Test1": "foo", "Test2": ""}, m["spec"])
+			writer.Write(responseBytes)
+		}
+		patch, opts, err := resource.NewPatchBuilder().MergePatch(&resource.TypedSpecObject[testSpec]{
+			Spec: testSpec{Test1: "foo"},
+		}).Build()
+		require.Nil(t, err)
+		_, err = client.Patch(ctx, id, patch, opts)
+		assert.Nil(t, err)
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		server.responseFunc = func(writer http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, string(types.ApplyPatchType), r.URL.Query().Get("patchType"))
+			assert.Equal(t, "my-operator", r.URL.Query().Get("fieldManager"))
+			assert.Equal(t, "true", r.URL.Query().Get("force"))
+			m := make(map[string]any)
+			require.Nil(t, json.NewDecoder(r.Body).Decode(&m))
+			// apiVersion and kind are set from the client's schema when the object doesn't have them
+			assert.Equal(t, "group/version", m["apiVersion"])
+			assert.Equal(t, "test", m["kind"])
+			writer.Write(responseBytes)
+		}
+		obj := &resource.TypedSpecObject[testSpec]{
+			Spec: testSpec{Test1: "foo"},
+		}
+		obj.SetName(id.Name)
+		patch, opts, err := resource.NewPatchBuilder().Apply(obj).FieldManager("my-operator").ForceConflicts().Build()
+		require.Nil(t, err)
+		_, err = client.Patch(ctx, id, patch, opts)
+		assert.Nil(t, err)
+		// The caller's object is not modified
+		assert.True(t, obj.GetObjectKind().GroupVersionKind().Empty())
+	})
+}
+
+func TestClient_Apply(t *testing.T) {
+	client, server := getClientTestSetup(testKind)
+	defer server.Close()
+	id := resource.Identifier{
+		Namespace: "ns",
+		Name:      "testo",
+	}
+	ctx := context.TODO()
+
+	t.Run("no field manager", func(t *testing.T) {
+		server.responseFunc = func(writer http.ResponseWriter, r *http.Request) {
+			assert.Fail(t, "HTTP request should not be made without a field manager")
+		}
+
+		resp, err := client.Apply(ctx, id, getTestObject(), resource.ApplyOptions{})
+		assert.Nil(t, resp)
+		assert.Equal(t, fmt.Errorf("apply requires a field manager"), err)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		server.responseFunc = func(writer http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPatch, r.Method)
+			assert.Equal(t, string(types.ApplyPatchType), r.URL.Query().Get("patchType"))
+			assert.Equal(t, "my-manager", r.URL.Query().Get("fieldManager"))
+			assert.Equal(t, "true", r.URL.Query().Get("force"))
+			assert.Equal(t, fmt.Sprintf("/namespaces/%s/%s/%s", id.Namespace, testSchema.Plural(), id.Name), r.URL.Path)
+			body, err := io.ReadAll(r.Body)
+			require.Nil(t, err)
+			applied := submittedObj{}
+			require.Nil(t, json.Unmarshal(body, &applied))
+			// The identifier and kind are set, and managed fields are removed
+			assert.Equal(t, id.Namespace, applied.ObjectMetadata.Namespace)
+			assert.Equal(t, id.Name, applied.ObjectMetadata.Name)
+			assert.Equal(t, testSchema.Kind(), applied.Kind)
+			assert.Nil(t, applied.ObjectMetadata.ManagedFields)
+			assert.Equal(t, responseObj.Spec, applied.Spec)
+			writer.Write(responseBytes)
+			writer.WriteHeader(http.StatusOK)
+		}
+
+		obj := getTestObject()
+		obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "other"}})
+		resp, err := client.Apply(ctx, id, obj, resource.ApplyOptions{
+			FieldManager:   "my-manager",
+			ForceConflicts: true,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, responseObj.GetSpec(), resp.GetSpec())
+		// The provided object is not modified
+		assert.Equal(t, "name", obj.GetName())
+		assert.Len(t, obj.GetManagedFields(), 1)
+	})
+}
+
 func TestClient_List(t *testing.T) {
 	client, server := getClientTestSetup(testKind)
 	defer server.Close()
@@ -667,6 +900,105 @@ func TestClient_List(t *testing.T) {
 		assert.Equal(t, responseObj.GetSpec(), item.GetSpec())
 		assert.Equal(t, responseObj.GetSubresources(), item.GetSubresources())
 	})
+	t.Run("success, with resource version and timeout", func(t *testing.T) {
+		server.responseFunc = func(writer http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "0", r.URL.Query().Get("resourceVersion"))
+			assert.Equal(t, resource.ResourceVersionMatchNotOlderThan, r.URL.Query().Get("resourceVersionMatch"))
+			assert.Equal(t, "30", r.URL.Query().Get("timeoutSeconds"))
+			listBytes, err := json.Marshal(listResp)
+			assert.Nil(t, err)
+			writer.Write(listBytes)
+		}
+
+		list, err := client.List(ctx, ns, resource.ListOptions{
+			ResourceVersion:      "0",
+			ResourceVersionMatch: resource.ResourceVersionMatchNotOlderThan,
+			TimeoutSeconds:       30,
+		})
+		assert.Nil(t, err)
+		assert.Len(t, list.GetItems(), 1)
+	})
+	t.Run("field selector validation", func(t *testing.T) {
+		client.config.ValidateFieldSelectors = true
+		defer func() {
+			client.config.ValidateFieldSelectors = false
+		}()
+		server.responseFunc = func(writer http.ResponseWriter, r *http.Request) {
+			assert.Fail(t, "no request should be made for an invalid field selector")
+		}
+
+		list, err := client.List(ctx, ns, resource.ListOptions{
+			FieldSelectors: []string{"metadata.name=foo", "spec.foo=bar"},
+		})
+		assert.Nil(t, list)
+		var cast *resource.InvalidFieldSelectorError
+		require.ErrorAs(t, err, &cast)
+		assert.Equal(t, "spec.foo", cast.Field)
+	})
+}
+
+func TestClient_CBOR(t *testing.T) {
+	client, server := getClientTestSetup(testKind)
+	defer server.Close()
+	client.client.config = ClientConfig{UseCBOR: true}
+	id := resource.Identifier{
+		Namespace: "ns",
+		Name:      "testo",
+	}
+	ctx := context.TODO()
+	cborResponse, err := resource.JSONToCBOR(responseBytes)
+	require.Nil(t, err)
+
+	t.Run("create sends and accepts CBOR", func(t *testing.T) {
+		server.responseFunc = func(writer http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "application/cbor", r.Header.Get("Content-Type"))
+			assert.Equal(t, cborAccept, r.Header.Get("Accept"))
+			body, err := io.ReadAll(r.Body)
+			require.Nil(t, err)
+			require.True(t, resource.IsCBOR(body))
+			j, err := resource.CBORToJSON(body)
+			require.Nil(t, err)
+			posted := submittedObj{}
+			require.Nil(t, json.Unmarshal(j, &posted))
+			assert.Equal(t, id.Name, posted.ObjectMetadata.Name)
+			assert.Equal(t, responseObj.Spec, posted.Spec)
+			writer.Header().Set("Content-Type", "application/cbor")
+			writer.Write(cborResponse)
+		}
+
+		resp, err := client.Create(ctx, id, getTestObject(), resource.CreateOptions{})
+		require.Nil(t, err)
+		assert.Equal(t, responseObj.GetStaticMetadata(), resp.GetStaticMetadata())
+		assert.Equal(t, responseObj.GetSpec(), resp.GetSpec())
+	})
+
+	t.Run("get JSON fallback", func(t *testing.T) {
+		server.responseFunc = func(writer http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, cborAccept, r.Header.Get("Accept"))
+			writer.Header().Set("Content-Type", "application/json")
+			writer.Write(responseBytes)
+		}
+
+		resp, err := client.Get(ctx, id)
+		require.Nil(t, err)
+		assert.Equal(t, responseObj.GetSpec(), resp.GetSpec())
+	})
+
+	t.Run("CBOR error response", func(t *testing.T) {
+		status, err := resource.JSONToCBOR([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","message":"not found","reason":"NotFound","code":404}`))
+		require.Nil(t, err)
+		server.responseFunc = func(writer http.ResponseWriter, r *http.Request) {
+			writer.Header().Set("Content-Type", "application/cbor")
+			writer.WriteHeader(http.StatusNotFound)
+			writer.Write(status)
+		}
+
+		_, err = client.Get(ctx, id)
+		require.NotNil(t, err)
+		cast, ok := err.(*ServerResponseError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusNotFound, cast.StatusCode())
+	})
 }
 
 func TestClient_Client(t *testing.T) {
@@ -1,5 +1,7 @@
 package k8s
 
+import "context"
+
 // Converter describes a type which can convert a kubernetes kind from one API version to another.
 // Typically there is one converter per-kind, but a single converter can also handle multiple kinds.
 type Converter interface {
@@ -10,6 +12,14 @@ type Converter interface {
 	Convert(obj RawKind, targetAPIVersion string) ([]byte, error)
 }
 
+// ContextConverter is a Converter which can also convert using a context.
+// If a Converter added to a WebhookServer implements ContextConverter, ConvertWithContext is called instead of Convert,
+// with a context carrying the resource.AdmissionContext of the conversion request.
+type ContextConverter interface {
+	Converter
+	ConvertWithContext(ctx context.Context, obj RawKind, targetAPIVersion string) ([]byte, error)
+}
+
 // RawKind represents a raw kubernetes object with basic kind information parsed out of it
 type RawKind struct {
 	// Kind is the parsed kind string
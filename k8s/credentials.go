@@ -0,0 +1,99 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"k8s.io/client-go/rest"
+)
+
+// TokenSource returns the current bearer token to use when authenticating with the API server.
+type TokenSource func(ctx context.Context) (string, error)
+
+// BearerTokenFileSource returns a TokenSource which reads the bearer token from the file at path,
+// such as a projected service account token.
+func BearerTokenFileSource(path string) TokenSource {
+	return func(context.Context) (string, error) {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("unable to read bearer token file: %w", err)
+		}
+		return strings.TrimSpace(string(contents)), nil
+	}
+}
+
+// RefreshableCredentials authenticates requests made with a rest.Config using a bearer token that can be
+// refreshed at runtime, for example after the API server starts rejecting requests following credential rotation.
+// It should be created with NewRefreshableCredentials.
+type RefreshableCredentials struct {
+	source TokenSource
+	token  string
+	mux    sync.RWMutex
+}
+
+// NewRefreshableCredentials fetches a token from source, and wraps the transport of cfg to authenticate with it.
+// cfg's BearerToken and BearerTokenFile are cleared, as the token is supplied by the source instead.
+// Clients created from cfg (or copies of cfg made after this call) will use the latest token
+// after each call to RefreshCredentials.
+func NewRefreshableCredentials(ctx context.Context, cfg *rest.Config, source TokenSource) (*RefreshableCredentials, error) {
+	if cfg == nil {
+		return nil, errors.New("cfg cannot be nil")
+	}
+	if source == nil {
+		return nil, errors.New("source cannot be nil")
+	}
+	creds := &RefreshableCredentials{
+		source: source,
+	}
+	if err := creds.RefreshCredentials(ctx); err != nil {
+		return nil, err
+	}
+	cfg.BearerToken = ""
+	cfg.BearerTokenFile = ""
+	cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &bearerTokenRoundTripper{
+			creds: creds,
+			next:  rt,
+		}
+	})
+	return creds, nil
+}
+
+// RefreshCredentials fetches a new token from the TokenSource. If the source returns an error,
+// the existing token continues to be used.
+func (c *RefreshableCredentials) RefreshCredentials(ctx context.Context) error {
+	token, err := c.source(ctx)
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return errors.New("token source returned an empty token")
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.token = token
+	return nil
+}
+
+func (c *RefreshableCredentials) currentToken() string {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.token
+}
+
+type bearerTokenRoundTripper struct {
+	creds *RefreshableCredentials
+	next  http.RoundTripper
+}
+
+func (b *bearerTokenRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the original request
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+b.creds.currentToken())
+	return b.next.RoundTrip(req)
+}
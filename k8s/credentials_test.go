@@ -0,0 +1,67 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func TestRefreshableCredentials(t *testing.T) {
+	var lastAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.Nil(t, os.WriteFile(tokenFile, []byte("first\n"), 0600))
+	cfg := &rest.Config{
+		Host:        server.URL,
+		BearerToken: "static",
+	}
+	creds, err := NewRefreshableCredentials(context.Background(), cfg, BearerTokenFileSource(tokenFile))
+	require.Nil(t, err)
+	assert.Empty(t, cfg.BearerToken)
+	// Copies of the config made after wrapping (such as the one held by ClientRegistry) use the refreshed token
+	client, err := rest.HTTPClientFor(cfg)
+	require.Nil(t, err)
+
+	doRequest := func() {
+		resp, err := client.Get(server.URL)
+		require.Nil(t, err)
+		resp.Body.Close()
+	}
+	doRequest()
+	assert.Equal(t, "Bearer first", lastAuth)
+
+	require.Nil(t, os.WriteFile(tokenFile, []byte("second"), 0600))
+	require.Nil(t, creds.RefreshCredentials(context.Background()))
+	doRequest()
+	assert.Equal(t, "Bearer second", lastAuth)
+
+	// A failed refresh keeps the existing token
+	require.Nil(t, os.Remove(tokenFile))
+	assert.NotNil(t, creds.RefreshCredentials(context.Background()))
+	doRequest()
+	assert.Equal(t, "Bearer second", lastAuth)
+}
+
+func TestNewRefreshableCredentials(t *testing.T) {
+	_, err := NewRefreshableCredentials(context.Background(), &rest.Config{}, func(context.Context) (string, error) {
+		return "", errors.New("unavailable")
+	})
+	assert.EqualError(t, err, "unavailable")
+	_, err = NewRefreshableCredentials(context.Background(), &rest.Config{}, func(context.Context) (string, error) {
+		return "", nil
+	})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,94 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+const (
+	// AnnotationDeletionProtected is the annotation which, when set to "true", protects an object from deletion
+	// by a DeletionProtectionAdmissionController
+	AnnotationDeletionProtected = AnnotationPrefix + "deletion-protected"
+	// AnnotationDeletionProtectionOverride is the annotation which, when set to "true", allows a protected object
+	// to be deleted. It must be set on the object (with an update) before the object is deleted.
+	AnnotationDeletionProtectionOverride = AnnotationPrefix + "deletion-protection-override"
+	// ErrReasonDeletionProtected is the "deletion protected" admission error reason string
+	ErrReasonDeletionProtected = "deletion_protected"
+)
+
+// DeletionProtectionConfig configures which objects are protected from deletion, and who may delete them anyway.
+type DeletionProtectionConfig struct {
+	// ProtectAll protects all objects from deletion, rather than only objects with the AnnotationDeletionProtected
+	// annotation set to "true". Setting the annotation to "false" removes the protection from an object.
+	ProtectAll bool
+	// AllowedUsers are the usernames which may delete protected objects without an override annotation,
+	// such as the service account of the app itself.
+	AllowedUsers []string
+	// AllowedGroups are the groups whose members may delete protected objects without an override annotation.
+	AllowedGroups []string
+}
+
+// CheckDeletionProtection returns an error which satisfies resource.AdmissionError if request is a delete
+// of a protected object (see DeletionProtectionConfig.ProtectAll) by a user who is not allowed to delete it.
+// The deletion is allowed if the object has the AnnotationDeletionProtectionOverride annotation set to "true",
+// or if the user is in config.AllowedUsers or config.AllowedGroups. Requests which are not deletes are always allowed.
+func CheckDeletionProtection(request *resource.AdmissionRequest, config DeletionProtectionConfig) error {
+	// For deletes, the object being deleted is in OldObject
+	if request.Action != resource.AdmissionActionDelete || request.OldObject == nil {
+		return nil
+	}
+	annotations := request.OldObject.GetAnnotations()
+	protected := annotations[AnnotationDeletionProtected] == "true"
+	if config.ProtectAll {
+		protected = annotations[AnnotationDeletionProtected] != "false"
+	}
+	if !protected || annotations[AnnotationDeletionProtectionOverride] == "true" {
+		return nil
+	}
+	if slices.Contains(config.AllowedUsers, request.UserInfo.Username) {
+		return nil
+	}
+	for _, group := range request.UserInfo.Groups {
+		if slices.Contains(config.AllowedGroups, group) {
+			return nil
+		}
+	}
+	return NewAdmissionError(fmt.Errorf("%s '%s' is protected from deletion, set the annotation %s to \"true\" to delete it",
+		request.Kind, request.OldObject.GetName(), AnnotationDeletionProtectionOverride), http.StatusForbidden, ErrReasonDeletionProtected)
+}
+
+// DeletionProtectionAdmissionController is a ValidatingAdmissionController which rejects deletes of protected objects
+// (see CheckDeletionProtection), before calling the underlying ValidatingAdmissionController, if non-nil.
+// Deletes are only sent to it if the kind's validation webhook includes the DELETE operation.
+type DeletionProtectionAdmissionController struct {
+	Underlying resource.ValidatingAdmissionController
+	Config     DeletionProtectionConfig
+}
+
+// Validate rejects deletes of protected objects, and calls Validate on Underlying (if non-nil) for all other requests.
+// If a delete is rejected, Validate is never called on Underlying.
+func (d *DeletionProtectionAdmissionController) Validate(ctx context.Context, request *resource.AdmissionRequest) error {
+	if err := CheckDeletionProtection(request, d.Config); err != nil {
+		return err
+	}
+	if d.Underlying != nil {
+		return d.Underlying.Validate(ctx, request)
+	}
+	return nil
+}
+
+// NewDeletionProtectionAdmissionController returns a new DeletionProtectionAdmissionController which wraps the provided
+// ValidatingAdmissionController. If `wrap` is nil, only deletion protection is validated.
+func NewDeletionProtectionAdmissionController(wrap resource.ValidatingAdmissionController, config DeletionProtectionConfig) *DeletionProtectionAdmissionController {
+	return &DeletionProtectionAdmissionController{
+		Underlying: wrap,
+		Config:     config,
+	}
+}
+
+// Compile-time interface compliance check
+var _ resource.ValidatingAdmissionController = &DeletionProtectionAdmissionController{}
@@ -0,0 +1,108 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestCheckDeletionProtection(t *testing.T) {
+	newObj := func(annotations map[string]string) resource.Object {
+		obj := &resource.UntypedObject{}
+		obj.SetName("foo")
+		obj.SetAnnotations(annotations)
+		return obj
+	}
+	deleteReq := func(obj resource.Object, user resource.AdmissionUserInfo) *resource.AdmissionRequest {
+		return &resource.AdmissionRequest{
+			Action:    resource.AdmissionActionDelete,
+			Kind:      "Foo",
+			UserInfo:  user,
+			OldObject: obj,
+		}
+	}
+	protected := newObj(map[string]string{AnnotationDeletionProtected: "true"})
+	user := resource.AdmissionUserInfo{Username: "user", Groups: []string{"viewers"}}
+
+	tests := []struct {
+		name    string
+		request *resource.AdmissionRequest
+		config  DeletionProtectionConfig
+		blocked bool
+	}{{
+		name:    "protected",
+		request: deleteReq(protected, user),
+		blocked: true,
+	}, {
+		name:    "not protected",
+		request: deleteReq(newObj(nil), user),
+	}, {
+		name:    "not a delete",
+		request: &resource.AdmissionRequest{Action: resource.AdmissionActionUpdate, Object: protected, OldObject: protected},
+	}, {
+		name:    "override annotation",
+		request: deleteReq(newObj(map[string]string{AnnotationDeletionProtected: "true", AnnotationDeletionProtectionOverride: "true"}), user),
+	}, {
+		name:    "allowed user",
+		request: deleteReq(protected, user),
+		config:  DeletionProtectionConfig{AllowedUsers: []string{"user"}},
+	}, {
+		name:    "allowed group",
+		request: deleteReq(protected, user),
+		config:  DeletionProtectionConfig{AllowedGroups: []string{"admins", "viewers"}},
+	}, {
+		name:    "protect all",
+		request: deleteReq(newObj(nil), user),
+		config:  DeletionProtectionConfig{ProtectAll: true},
+		blocked: true,
+	}, {
+		name:    "protect all, unprotected by annotation",
+		request: deleteReq(newObj(map[string]string{AnnotationDeletionProtected: "false"}), user),
+		config:  DeletionProtectionConfig{ProtectAll: true},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckDeletionProtection(test.request, test.config)
+			if !test.blocked {
+				assert.Nil(t, err)
+				return
+			}
+			var admErr resource.AdmissionError
+			require.ErrorAs(t, err, &admErr)
+			assert.Equal(t, http.StatusForbidden, admErr.StatusCode())
+			assert.Equal(t, ErrReasonDeletionProtected, admErr.Reason())
+		})
+	}
+}
+
+func TestDeletionProtectionAdmissionController_Validate(t *testing.T) {
+	protected := &resource.UntypedObject{}
+	protected.SetAnnotations(map[string]string{AnnotationDeletionProtected: "true"})
+	underlyingErr := errors.New("underlying")
+	calls := 0
+	controller := NewDeletionProtectionAdmissionController(&resource.SimpleValidatingAdmissionController{
+		ValidateFunc: func(context.Context, *resource.AdmissionRequest) error {
+			calls++
+			return underlyingErr
+		},
+	}, DeletionProtectionConfig{})
+
+	err := controller.Validate(context.Background(), &resource.AdmissionRequest{Action: resource.AdmissionActionDelete, OldObject: protected})
+	assert.NotNil(t, err)
+	assert.NotErrorIs(t, err, underlyingErr)
+	assert.Equal(t, 0, calls)
+
+	err = controller.Validate(context.Background(), &resource.AdmissionRequest{Action: resource.AdmissionActionCreate, Object: protected})
+	assert.ErrorIs(t, err, underlyingErr)
+	assert.Equal(t, 1, calls)
+
+	assert.Nil(t, NewDeletionProtectionAdmissionController(nil, DeletionProtectionConfig{}).Validate(context.Background(),
+		&resource.AdmissionRequest{Action: resource.AdmissionActionCreate, Object: protected}))
+}
@@ -9,7 +9,6 @@ import (
 	"golang.org/x/sync/singleflight"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
@@ -59,29 +58,39 @@ func (d *DynamicKindPatcher) Patch(ctx context.Context, identifier resource.Iden
 	return d.patcher.Patch(ctx, d.groupKind, identifier, patch, options)
 }
 
-func (d *DynamicPatcher) Patch(ctx context.Context, groupKind schema.GroupKind, identifier resource.Identifier, patch resource.PatchRequest, _ resource.PatchOptions) (*resource.UnstructuredWrapper, error) {
+func (d *DynamicPatcher) Patch(ctx context.Context, groupKind schema.GroupKind, identifier resource.Identifier, patch resource.PatchRequest, options resource.PatchOptions) (*resource.UnstructuredWrapper, error) {
 	preferred, err := d.getPreferred(groupKind)
 	if err != nil {
 		return nil, err
 	}
 	logging.FromContext(ctx).Debug("patching with dynamic client", "group", groupKind.Group, "version", preferred.Version, "kind", groupKind.Kind, "plural", preferred.Name)
-	data, err := marshalJSONPatch(patch)
+	patchType, data, err := marshalPatch(patch, schema.GroupVersionKind{
+		Group:   groupKind.Group,
+		Version: preferred.Version,
+		Kind:    groupKind.Kind,
+	}, resource.NewJSONCodec())
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal patch: %w", err)
 	}
+	patchOptions := metav1.PatchOptions{
+		FieldManager: options.FieldManager,
+	}
+	if options.Force {
+		patchOptions.Force = &options.Force
+	}
 	res := d.client.Resource(schema.GroupVersionResource{
 		Group:    preferred.Group,
 		Version:  preferred.Version,
 		Resource: preferred.Name,
 	})
 	if preferred.Namespaced {
-		resp, err := res.Namespace(identifier.Namespace).Patch(ctx, identifier.Name, types.JSONPatchType, data, metav1.PatchOptions{})
+		resp, err := res.Namespace(identifier.Namespace).Patch(ctx, identifier.Name, patchType, data, patchOptions)
 		if err != nil {
 			return nil, err
 		}
 		return resource.NewUnstructuredWrapper(resp), nil
 	}
-	resp, err := res.Patch(ctx, identifier.Name, types.JSONPatchType, data, metav1.PatchOptions{})
+	resp, err := res.Patch(ctx, identifier.Name, patchType, data, patchOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,133 @@
+package envtest
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/k8s"
+)
+
+// ManifestCRDs returns the Custom Resource Definitions for all kinds in the provided manifest.
+// plurals is a map of kind name to the plural used for the kind's CRD, and kinds which are not present in the map
+// use the default lowercase "<kind>s" plural. The last version of each kind is used as the storage version.
+//
+// Versions with a schema in the manifest use the schema's top-level properties (such as 'spec' and 'status') in the CRD,
+// and have a status subresource if the schema has a 'status' property. Versions without a schema,
+// or with a schema which is a full OpenAPI document, have a schema which preserves unknown fields, and a status subresource.
+func ManifestCRDs(manifest app.ManifestData, plurals map[string]string) ([]k8s.CustomResourceDefinition, error) {
+	crds := make([]k8s.CustomResourceDefinition, 0, len(manifest.Kinds))
+	for _, kind := range manifest.Kinds {
+		if len(kind.Versions) == 0 {
+			return nil, fmt.Errorf("kind %s has no versions", kind.Kind)
+		}
+		plural := strings.ToLower(kind.Kind) + "s"
+		if p, ok := plurals[kind.Kind]; ok && p != "" {
+			plural = p
+		}
+		scope := kind.Scope
+		if scope == "" {
+			scope = "Namespaced"
+		}
+		crd := k8s.CustomResourceDefinition{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "apiextensions.k8s.io/v1",
+				Kind:       "CustomResourceDefinition",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("%s.%s", plural, manifest.Group),
+			},
+			Spec: k8s.CustomResourceDefinitionSpec{
+				Group: manifest.Group,
+				Names: k8s.CustomResourceDefinitionSpecNames{
+					Kind:       kind.Kind,
+					Plural:     plural,
+					ShortNames: kind.ShortNames,
+					Categories: kind.Categories,
+				},
+				Scope:    scope,
+				Versions: make([]k8s.CustomResourceDefinitionSpecVersion, 0, len(kind.Versions)),
+			},
+		}
+		for i, version := range kind.Versions {
+			v := manifestCRDVersion(version)
+			v.Storage = i == len(kind.Versions)-1
+			crd.Spec.Versions = append(crd.Spec.Versions, v)
+		}
+		crds = append(crds, crd)
+	}
+	return crds, nil
+}
+
+func manifestCRDVersion(version app.ManifestKindVersion) k8s.CustomResourceDefinitionSpecVersion {
+	v := k8s.CustomResourceDefinitionSpecVersion{
+		Name:         version.Name,
+		Served:       true,
+		Subresources: make(map[string]any),
+	}
+	var props map[string]any
+	if version.Schema != nil {
+		props = version.Schema.AsMap()
+	}
+	if _, ok := props["schemas"]; ok || len(props) == 0 {
+		v.Schema = map[string]any{
+			"openAPIV3Schema": map[string]any{
+				"type":                                 "object",
+				"x-kubernetes-preserve-unknown-fields": true,
+			},
+		}
+		v.Subresources["status"] = struct{}{}
+	} else {
+		v.Schema = map[string]any{
+			"openAPIV3Schema": map[string]any{
+				"type":       "object",
+				"properties": props,
+			},
+		}
+		if _, ok := props["status"]; ok {
+			v.Subresources["status"] = struct{}{}
+		}
+	}
+	for _, field := range version.SelectableFields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if field[0] != '.' {
+			field = "." + field
+		}
+		v.SelectableFields = append(v.SelectableFields, k8s.CustomResourceDefinitionSelectableField{
+			JSONPath: field,
+		})
+	}
+	if version.Scale != nil {
+		scale := k8s.CustomResourceDefinitionSubresourceScale{
+			SpecReplicasPath:   version.Scale.SpecReplicasPath,
+			StatusReplicasPath: version.Scale.StatusReplicasPath,
+		}
+		if version.Scale.LabelSelectorPath != "" {
+			scale.LabelSelectorPath = &version.Scale.LabelSelectorPath
+		}
+		v.Subresources["scale"] = scale
+	}
+	for _, col := range version.AdditionalPrinterColumns {
+		c := k8s.CustomResourceDefinitionAdditionalPrinterColumn{
+			Name:     col.Name,
+			Type:     col.Type,
+			JSONPath: col.JSONPath,
+		}
+		if col.Format != "" {
+			c.Format = &col.Format
+		}
+		if col.Description != "" {
+			c.Description = &col.Description
+		}
+		if col.Priority != 0 {
+			c.Priority = &col.Priority
+		}
+		v.AdditionalPrinterColumns = append(v.AdditionalPrinterColumns, c)
+	}
+	return v
+}
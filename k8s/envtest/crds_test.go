@@ -0,0 +1,87 @@
+package envtest
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/k8s"
+)
+
+func TestManifestCRDs(t *testing.T) {
+	schema := app.VersionSchema{}
+	require.NoError(t, json.Unmarshal([]byte(`{"spec":{"type":"object","properties":{"foo":{"type":"string"}}},"status":{"type":"object"}}`), &schema))
+	manifest := app.ManifestData{
+		AppName: "test",
+		Group:   "test.ext.grafana.com",
+		Kinds: []app.ManifestKind{{
+			Kind:       "Foo",
+			Scope:      "Cluster",
+			ShortNames: []string{"f"},
+			Categories: []string{"all"},
+			Versions: []app.ManifestKindVersion{{
+				Name: "v1",
+			}, {
+				Name:             "v2",
+				Schema:           &schema,
+				SelectableFields: []string{"spec.foo"},
+				AdditionalPrinterColumns: []app.AdditionalPrinterColumn{{
+					Name:     "Foo",
+					Type:     "string",
+					Priority: 1,
+					JSONPath: ".spec.foo",
+				}},
+				Scale: &app.ScaleSubresource{
+					SpecReplicasPath:   ".spec.replicas",
+					StatusReplicasPath: ".status.replicas",
+					LabelSelectorPath:  ".status.selector",
+				},
+			}},
+		}, {
+			Kind:     "Bar",
+			Versions: []app.ManifestKindVersion{{Name: "v1"}},
+		}},
+	}
+
+	crds, err := ManifestCRDs(manifest, map[string]string{"Bar": "barries"})
+	require.NoError(t, err)
+	require.Len(t, crds, 2)
+
+	foo := crds[0]
+	assert.Equal(t, "foos.test.ext.grafana.com", foo.Name)
+	assert.Equal(t, "apiextensions.k8s.io/v1", foo.APIVersion)
+	assert.Equal(t, "Cluster", foo.Spec.Scope)
+	assert.Equal(t, k8s.CustomResourceDefinitionSpecNames{Kind: "Foo", Plural: "foos", ShortNames: []string{"f"}, Categories: []string{"all"}}, foo.Spec.Names)
+	require.Len(t, foo.Spec.Versions, 2)
+	v1, v2 := foo.Spec.Versions[0], foo.Spec.Versions[1]
+	assert.False(t, v1.Storage)
+	assert.True(t, v2.Storage)
+	// Versions without a schema preserve unknown fields
+	assert.Equal(t, map[string]any{"openAPIV3Schema": map[string]any{"type": "object", "x-kubernetes-preserve-unknown-fields": true}}, v1.Schema)
+	assert.Contains(t, v1.Subresources, "status")
+	assert.Equal(t, map[string]any{"openAPIV3Schema": map[string]any{"type": "object", "properties": schema.AsMap()}}, v2.Schema)
+	assert.Contains(t, v2.Subresources, "status")
+	selector := ".status.selector"
+	assert.Equal(t, k8s.CustomResourceDefinitionSubresourceScale{
+		SpecReplicasPath:   ".spec.replicas",
+		StatusReplicasPath: ".status.replicas",
+		LabelSelectorPath:  &selector,
+	}, v2.Subresources["scale"])
+	assert.NotContains(t, v1.Subresources, "scale")
+	assert.Equal(t, []k8s.CustomResourceDefinitionSelectableField{{JSONPath: ".spec.foo"}}, v2.SelectableFields)
+	require.Len(t, v2.AdditionalPrinterColumns, 1)
+	assert.Equal(t, ".spec.foo", v2.AdditionalPrinterColumns[0].JSONPath)
+	assert.Equal(t, int32(1), *v2.AdditionalPrinterColumns[0].Priority)
+	assert.Nil(t, v2.AdditionalPrinterColumns[0].Format)
+
+	bar := crds[1]
+	assert.Equal(t, "barries.test.ext.grafana.com", bar.Name)
+	assert.Equal(t, "Namespaced", bar.Spec.Scope)
+	assert.True(t, bar.Spec.Versions[0].Storage)
+
+	_, err = ManifestCRDs(app.ManifestData{Kinds: []app.ManifestKind{{Kind: "Empty"}}}, nil)
+	assert.EqualError(t, err, "kind Empty has no versions")
+}
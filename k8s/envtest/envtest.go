@@ -0,0 +1,421 @@
+// Package envtest starts a local kubernetes control plane (etcd and kube-apiserver) for integration tests,
+// registers the kinds of an app's manifest as CRDs in it, and returns a *rest.Config for it,
+// so that operators and apps can be tested against a real API server in CI without a full cluster.
+//
+// The etcd and kube-apiserver binaries are not downloaded by this package. They are found in a binary assets directory,
+// which is usually set with the KUBEBUILDER_ASSETS environment variable (for example, using the output of
+// `setup-envtest use -p path`). Tests should skip when the binaries are not available:
+//
+//	env, err := envtest.Start(ctx, envtest.Config{Manifests: []app.ManifestData{manifest}})
+//	if errors.Is(err, envtest.ErrBinaryAssetsNotFound) {
+//		t.Skip(err)
+//	}
+//
+// An in-process API server is not supported, as it would require the k8s.io/apiserver module as a dependency of the SDK.
+package envtest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/k8s"
+)
+
+const (
+	// BinaryAssetsEnvVar is the environment variable used for the binary assets directory
+	// if Config.BinaryAssetsDirectory is empty
+	BinaryAssetsEnvVar = "KUBEBUILDER_ASSETS"
+
+	defaultStartTimeout = time.Minute
+	defaultStopTimeout  = 20 * time.Second
+	adminUser           = "envtest-admin"
+)
+
+// ErrBinaryAssetsNotFound is returned by Start if the binary assets directory is not set,
+// or does not contain the etcd and kube-apiserver binaries
+var ErrBinaryAssetsNotFound = errors.New("envtest binary assets not found")
+
+// Config is the configuration for Start
+type Config struct {
+	// BinaryAssetsDirectory is the directory which contains the etcd and kube-apiserver binaries.
+	// If empty, the value of the KUBEBUILDER_ASSETS environment variable is used.
+	BinaryAssetsDirectory string
+	// Manifests are the manifests of the apps whose kinds are registered as CRDs once the API server has started
+	Manifests []app.ManifestData
+	// Plurals is a map of kind name to the plural used for the kind's CRD name.
+	// Kinds which are not present in the map use the default lowercase "<kind>s" plural.
+	Plurals map[string]string
+	// StartTimeout is the timeout for the API server to become ready, and for the CRDs to be served.
+	// Defaults to one minute if zero.
+	StartTimeout time.Duration
+	// StopTimeout is the time the processes have to exit after being terminated by Stop, before they are killed.
+	// Defaults to 20 seconds if zero.
+	StopTimeout time.Duration
+	// APIServerFlags are additional flags for kube-apiserver, in the form "--flag=value"
+	APIServerFlags []string
+	// Output, if non-nil, is written the output of the etcd and kube-apiserver processes
+	Output io.Writer
+}
+
+// Environment is a running local control plane, started by Start
+type Environment struct {
+	// Config is the config for the API server, which authenticates as a user in the system:masters group
+	Config *rest.Config
+	// CRDs are the Custom Resource Definitions registered from the Config's Manifests
+	CRDs []k8s.CustomResourceDefinition
+
+	dir         string
+	etcd        *exec.Cmd
+	apiServer   *exec.Cmd
+	stopTimeout time.Duration
+}
+
+// Start starts etcd and kube-apiserver, waits for the API server to be ready, and registers the kinds from cfg.Manifests as CRDs.
+// It returns an error wrapping ErrBinaryAssetsNotFound if the binaries cannot be found.
+// The returned Environment must be stopped with Stop once it is no longer needed.
+func Start(ctx context.Context, cfg Config) (*Environment, error) {
+	etcdPath, apiServerPath, err := findBinaries(cfg.BinaryAssetsDirectory)
+	if err != nil {
+		return nil, err
+	}
+	crds := make([]k8s.CustomResourceDefinition, 0)
+	for _, manifest := range cfg.Manifests {
+		c, err := ManifestCRDs(manifest, cfg.Plurals)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create CRDs for app %s: %w", manifest.AppName, err)
+		}
+		crds = append(crds, c...)
+	}
+	if cfg.StartTimeout <= 0 {
+		cfg.StartTimeout = defaultStartTimeout
+	}
+	if cfg.StopTimeout <= 0 {
+		cfg.StopTimeout = defaultStopTimeout
+	}
+	if cfg.Output == nil {
+		cfg.Output = io.Discard
+	}
+	ctx, cancel := context.WithTimeout(ctx, cfg.StartTimeout)
+	defer cancel()
+
+	dir, err := os.MkdirTemp("", "envtest-")
+	if err != nil {
+		return nil, err
+	}
+	env := &Environment{
+		CRDs:        crds,
+		dir:         dir,
+		stopTimeout: cfg.StopTimeout,
+	}
+	if err = env.start(ctx, cfg, etcdPath, apiServerPath); err != nil {
+		_ = env.Stop()
+		return nil, err
+	}
+	return env, nil
+}
+
+func (e *Environment) start(ctx context.Context, cfg Config, etcdPath, apiServerPath string) error {
+	ports, err := freePorts(3)
+	if err != nil {
+		return fmt.Errorf("unable to find free ports: %w", err)
+	}
+	etcdURL := fmt.Sprintf("http://127.0.0.1:%d", ports[0])
+	e.etcd = exec.Command(etcdPath,
+		"--data-dir="+filepath.Join(e.dir, "etcd"),
+		"--listen-client-urls="+etcdURL,
+		"--advertise-client-urls="+etcdURL,
+		fmt.Sprintf("--listen-peer-urls=http://127.0.0.1:%d", ports[1]),
+		"--unsafe-no-fsync=true",
+	)
+	e.etcd.Stdout, e.etcd.Stderr = cfg.Output, cfg.Output
+	if err = e.etcd.Start(); err != nil {
+		return fmt.Errorf("unable to start etcd: %w", err)
+	}
+
+	caCert, err := e.writeCertificates()
+	if err != nil {
+		return fmt.Errorf("unable to create certificates: %w", err)
+	}
+	token, err := e.writeTokenFile()
+	if err != nil {
+		return fmt.Errorf("unable to create token file: %w", err)
+	}
+	args := []string{
+		"--etcd-servers=" + etcdURL,
+		"--bind-address=127.0.0.1",
+		"--secure-port=" + strconv.Itoa(ports[2]),
+		"--cert-dir=" + e.dir,
+		"--tls-cert-file=" + filepath.Join(e.dir, "apiserver.crt"),
+		"--tls-private-key-file=" + filepath.Join(e.dir, "apiserver.key"),
+		"--token-auth-file=" + filepath.Join(e.dir, "tokens.csv"),
+		"--authorization-mode=RBAC",
+		"--service-account-issuer=https://127.0.0.1:" + strconv.Itoa(ports[2]),
+		"--service-account-key-file=" + filepath.Join(e.dir, "sa.key"),
+		"--service-account-signing-key-file=" + filepath.Join(e.dir, "sa.key"),
+		"--service-cluster-ip-range=10.0.0.0/24",
+		"--disable-admission-plugins=ServiceAccount",
+		"--allow-privileged=true",
+	}
+	e.apiServer = exec.Command(apiServerPath, append(args, cfg.APIServerFlags...)...)
+	e.apiServer.Stdout, e.apiServer.Stderr = cfg.Output, cfg.Output
+	if err = e.apiServer.Start(); err != nil {
+		return fmt.Errorf("unable to start kube-apiserver: %w", err)
+	}
+
+	e.Config = &rest.Config{
+		Host:        "https://127.0.0.1:" + strconv.Itoa(ports[2]),
+		BearerToken: token,
+		TLSClientConfig: rest.TLSClientConfig{
+			CAData: caCert,
+		},
+	}
+	if err = waitForReady(ctx, caCert, e.Config.Host, token); err != nil {
+		return fmt.Errorf("kube-apiserver did not become ready: %w", err)
+	}
+	return e.registerCRDs(ctx)
+}
+
+// Stop stops the API server and etcd, and removes their data
+func (e *Environment) Stop() error {
+	errs := make([]error, 0)
+	for _, cmd := range []*exec.Cmd{e.apiServer, e.etcd} {
+		if err := stopProcess(cmd, e.stopTimeout); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := os.RemoveAll(e.dir); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (e *Environment) registerCRDs(ctx context.Context) error {
+	if len(e.CRDs) == 0 {
+		return nil
+	}
+	manager, err := k8s.NewManager(*e.Config)
+	if err != nil {
+		return err
+	}
+	for i := range e.CRDs {
+		if err = manager.CreateCustomResourceDefinition(ctx, &e.CRDs[i]); err != nil {
+			return fmt.Errorf("unable to create CRD %s: %w", e.CRDs[i].Name, err)
+		}
+	}
+	// Wait for every version of every CRD to be served, so that clients can be used as soon as Start returns
+	disc, err := discovery.NewDiscoveryClientForConfig(e.Config)
+	if err != nil {
+		return err
+	}
+	t := time.NewTicker(100 * time.Millisecond)
+	defer t.Stop()
+	for _, crd := range e.CRDs {
+		for _, version := range crd.Spec.Versions {
+			for !servesResource(disc, crd.Spec.Group+"/"+version.Name, crd.Spec.Names.Plural) {
+				select {
+				case <-t.C:
+				case <-ctx.Done():
+					return fmt.Errorf("CRD %s version %s was not served: %w", crd.Name, version.Name, ctx.Err())
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func servesResource(disc discovery.DiscoveryInterface, groupVersion, plural string) bool {
+	list, err := disc.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		return false
+	}
+	for _, res := range list.APIResources {
+		if res.Name == plural {
+			return true
+		}
+	}
+	return false
+}
+
+// writeCertificates writes a serving certificate and key for the API server, and a service account signing key,
+// to the Environment's directory, and returns the PEM-encoded serving certificate, which is its own CA
+func (e *Environment) writeCertificates() ([]byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "envtest"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err = os.WriteFile(filepath.Join(e.dir, "apiserver.crt"), certPEM, 0600); err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(filepath.Join(e.dir, "apiserver.key"), keyPEM, 0600); err != nil {
+		return nil, err
+	}
+
+	saKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	saPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(saKey)})
+	if err = os.WriteFile(filepath.Join(e.dir, "sa.key"), saPEM, 0600); err != nil {
+		return nil, err
+	}
+	return certPEM, nil
+}
+
+// writeTokenFile writes a static token file for the API server with a random token for an admin user, and returns the token
+func (e *Environment) writeTokenFile() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	contents := fmt.Sprintf("%s,%s,%s,\"system:masters\"\n", token, adminUser, adminUser)
+	return token, os.WriteFile(filepath.Join(e.dir, "tokens.csv"), []byte(contents), 0600)
+}
+
+func findBinaries(dir string) (etcd string, apiServer string, err error) {
+	if dir == "" {
+		dir = os.Getenv(BinaryAssetsEnvVar)
+	}
+	if dir == "" {
+		return "", "", fmt.Errorf("%w: binary assets directory is not set, set it in the config or with the %s environment variable",
+			ErrBinaryAssetsNotFound, BinaryAssetsEnvVar)
+	}
+	etcd = filepath.Join(dir, "etcd")
+	apiServer = filepath.Join(dir, "kube-apiserver")
+	for _, path := range []string{etcd, apiServer} {
+		if _, err = os.Stat(path); err != nil {
+			return "", "", fmt.Errorf("%w: %w", ErrBinaryAssetsNotFound, err)
+		}
+	}
+	return etcd, apiServer, nil
+}
+
+// freePorts returns n distinct free ports on 127.0.0.1
+func freePorts(n int) ([]int, error) {
+	ports := make([]int, 0, n)
+	listeners := make([]net.Listener, 0, n)
+	defer func() {
+		for _, l := range listeners {
+			_ = l.Close()
+		}
+	}()
+	for i := 0; i < n; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, err
+		}
+		listeners = append(listeners, l)
+		ports = append(ports, l.Addr().(*net.TCPAddr).Port)
+	}
+	return ports, nil
+}
+
+func waitForReady(ctx context.Context, caCert []byte, host, token string) error {
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(caCert)
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:    pool,
+				MinVersion: tls.VersionTLS12,
+			},
+		},
+		Timeout: 5 * time.Second,
+	}
+	t := time.NewTicker(100 * time.Millisecond)
+	defer t.Stop()
+	var lastErr error
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, host+"/readyz", nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := client.Do(req)
+		if err == nil {
+			body, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+			lastErr = fmt.Errorf("readyz responded with status code %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+		} else {
+			lastErr = err
+		}
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return errors.Join(ctx.Err(), lastErr)
+		}
+	}
+}
+
+func stopProcess(cmd *exec.Cmd, timeout time.Duration) error {
+	if cmd == nil || cmd.Process == nil {
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		// The exit error is expected, as the process is terminated
+		_ = cmd.Wait()
+		close(done)
+	}()
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return err
+		}
+		<-done
+		return nil
+	}
+}
@@ -0,0 +1,64 @@
+package envtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/grafana/grafana-app-sdk/app"
+)
+
+func TestStart_BinaryAssetsNotFound(t *testing.T) {
+	t.Setenv(BinaryAssetsEnvVar, "")
+	_, err := Start(context.Background(), Config{})
+	assert.True(t, errors.Is(err, ErrBinaryAssetsNotFound))
+
+	_, err = Start(context.Background(), Config{BinaryAssetsDirectory: t.TempDir()})
+	assert.True(t, errors.Is(err, ErrBinaryAssetsNotFound))
+}
+
+func TestStart(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	env, err := Start(ctx, Config{
+		Manifests: []app.ManifestData{{
+			AppName: "test",
+			Group:   "test.ext.grafana.com",
+			Kinds: []app.ManifestKind{{
+				Kind:     "Foo",
+				Scope:    "Namespaced",
+				Versions: []app.ManifestKindVersion{{Name: "v1"}},
+			}},
+		}},
+	})
+	if errors.Is(err, ErrBinaryAssetsNotFound) {
+		t.Skip(err)
+	}
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, env.Stop())
+	}()
+
+	client, err := dynamic.NewForConfig(env.Config)
+	require.NoError(t, err)
+	gvr := schema.GroupVersionResource{Group: "test.ext.grafana.com", Version: "v1", Resource: "foos"}
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "test.ext.grafana.com/v1",
+		"kind":       "Foo",
+		"metadata":   map[string]any{"name": "foo"},
+		"spec":       map[string]any{"bar": "baz"},
+	}}
+	_, err = client.Resource(gvr).Namespace("default").Create(ctx, obj, metav1.CreateOptions{})
+	require.NoError(t, err)
+	got, err := client.Resource(gvr).Namespace("default").Get(ctx, "foo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "baz", got.Object["spec"].(map[string]any)["bar"])
+}
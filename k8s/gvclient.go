@@ -17,11 +17,12 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/rest"
 
 	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/metrics"
 	"github.com/grafana/grafana-app-sdk/resource"
 )
 
@@ -30,6 +31,10 @@ const (
 
 	// AnnotationPrefix is the prefix used in annotations which contain grafana kind metadata
 	AnnotationPrefix = "grafana.com/"
+
+	// cborAccept is the Accept header used by clients with ClientConfig.UseCBOR,
+	// which falls back to JSON for servers which don't support CBOR
+	cborAccept = "application/cbor, application/json;q=0.9"
 )
 
 // groupVersionClient is the underlying client both Client and SchemalessClient use.
@@ -41,20 +46,24 @@ type groupVersionClient struct {
 	config           ClientConfig
 	requestDurations *prometheus.HistogramVec
 	totalRequests    *prometheus.CounterVec
+	slowRequests     *prometheus.SummaryVec
+	breaker          *CircuitBreaker
 }
 
 func (g *groupVersionClient) get(ctx context.Context, identifier resource.Identifier, plural string,
 	into resource.Object, codec resource.Codec) error {
 	ctx, span := GetTracer().Start(ctx, "kubernetes-get")
 	defer span.End()
+	ctx, cancel := g.operationContext(ctx)
+	defer cancel()
 	sc := 0
 	request := g.client.Get().Resource(plural).Name(identifier.Name)
 	if strings.TrimSpace(identifier.Namespace) != "" {
 		request = request.Namespace(identifier.Namespace)
 	}
 	start := time.Now()
-	raw, err := request.Do(ctx).StatusCode(&sc).Raw()
-	g.logRequestDuration(time.Since(start), sc, "GET", plural, "spec")
+	raw, err := g.do(ctx, request, &sc)
+	g.logRequestDuration(ctx, time.Since(start), sc, "GET", plural, "spec", identifier)
 	span.SetAttributes(
 		attribute.Int("http.response.status_code", sc),
 		attribute.String("http.request.method", http.MethodGet),
@@ -62,7 +71,7 @@ func (g *groupVersionClient) get(ctx context.Context, identifier resource.Identi
 		attribute.String("server.port", request.URL().Port()),
 		attribute.String("url.full", request.URL().String()),
 	)
-	g.incRequestCounter(sc, "GET", plural, "spec")
+	g.incRequestCounter(ctx, sc, "GET", plural, "spec")
 	if err != nil {
 		err = parseKubernetesError(raw, sc, err)
 		span.SetStatus(codes.Error, err.Error())
@@ -84,14 +93,16 @@ func (g *groupVersionClient) getMetadata(ctx context.Context, identifier resourc
 	*metadataObject, error) {
 	ctx, span := GetTracer().Start(ctx, "kubernetes-getmetadata")
 	defer span.End()
+	ctx, cancel := g.operationContext(ctx)
+	defer cancel()
 	sc := 0
 	request := g.client.Get().Resource(plural).Name(identifier.Name)
 	if strings.TrimSpace(identifier.Namespace) != "" {
 		request = request.Namespace(identifier.Namespace)
 	}
 	start := time.Now()
-	raw, err := request.Do(ctx).StatusCode(&sc).Raw()
-	g.logRequestDuration(time.Since(start), sc, "GET", plural, "spec")
+	raw, err := g.do(ctx, request, &sc)
+	g.logRequestDuration(ctx, time.Since(start), sc, "GET", plural, "spec", identifier)
 	span.SetAttributes(
 		attribute.Int("http.response.status_code", sc),
 		attribute.String("http.request.method", http.MethodGet),
@@ -99,7 +110,7 @@ func (g *groupVersionClient) getMetadata(ctx context.Context, identifier resourc
 		attribute.String("server.port", request.URL().Port()),
 		attribute.String("url.full", request.URL().String()),
 	)
-	g.incRequestCounter(sc, "GET", plural, "spec")
+	g.incRequestCounter(ctx, sc, "GET", plural, "spec")
 	if err != nil {
 		err = parseKubernetesError(raw, sc, err)
 		span.SetStatus(codes.Error, err.Error())
@@ -119,14 +130,16 @@ func (g *groupVersionClient) exists(ctx context.Context, identifier resource.Ide
 	bool, error) {
 	ctx, span := GetTracer().Start(ctx, "kubernetes-exists")
 	defer span.End()
+	ctx, cancel := g.operationContext(ctx)
+	defer cancel()
 	sc := 0
 	request := g.client.Get().Resource(plural).Name(identifier.Name)
 	if strings.TrimSpace(identifier.Namespace) != "" {
 		request = request.Namespace(identifier.Namespace)
 	}
 	start := time.Now()
-	err := request.Do(ctx).StatusCode(&sc).Error()
-	g.logRequestDuration(time.Since(start), sc, "GET", plural, "spec")
+	err := g.doError(ctx, request, &sc)
+	g.logRequestDuration(ctx, time.Since(start), sc, "GET", plural, "spec", identifier)
 	span.SetAttributes(
 		attribute.Int("http.response.status_code", sc),
 		attribute.String("http.request.method", http.MethodGet),
@@ -134,7 +147,7 @@ func (g *groupVersionClient) exists(ctx context.Context, identifier resource.Ide
 		attribute.String("server.port", request.URL().Port()),
 		attribute.String("url.full", request.URL().String()),
 	)
-	g.incRequestCounter(sc, "GET", plural, "spec")
+	g.incRequestCounter(ctx, sc, "GET", plural, "spec")
 	if err != nil {
 		// HTTP error?
 		if sc == http.StatusNotFound {
@@ -154,6 +167,8 @@ func (g *groupVersionClient) create(ctx context.Context, plural string, obj reso
 	into resource.Object, codec resource.Codec) error {
 	ctx, span := GetTracer().Start(ctx, "kubernetes-create")
 	defer span.End()
+	ctx, cancel := g.operationContext(ctx)
+	defer cancel()
 	addLabels(obj, map[string]string{
 		versionLabel: g.version,
 	})
@@ -163,15 +178,24 @@ func (g *groupVersionClient) create(ctx context.Context, plural string, obj reso
 		span.SetStatus(codes.Error, fmt.Sprintf("error marshaling kubernetes JSON: %s", err.Error()))
 		return err
 	}
+	body, err := g.pruneBody(ctx, obj.GetStaticMetadata().FullIdentifier(), buf.Bytes())
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
 
 	sc := 0
-	request := g.client.Post().Resource(plural).Body(buf.Bytes())
+	request, err := g.setBody(g.client.Post().Resource(plural), body)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
 	if strings.TrimSpace(obj.GetNamespace()) != "" {
 		request = request.Namespace(obj.GetNamespace())
 	}
 	start := time.Now()
-	raw, err := request.Do(ctx).StatusCode(&sc).Raw()
-	g.logRequestDuration(time.Since(start), sc, "CREATE", plural, "spec")
+	raw, err := g.do(ctx, request, &sc)
+	g.logRequestDuration(ctx, time.Since(start), sc, "CREATE", plural, "spec", resource.Identifier{Namespace: obj.GetNamespace(), Name: obj.GetName()})
 	span.SetAttributes(
 		attribute.Int("http.response.status_code", sc),
 		attribute.String("http.request.method", http.MethodPost),
@@ -179,7 +203,7 @@ func (g *groupVersionClient) create(ctx context.Context, plural string, obj reso
 		attribute.String("server.port", request.URL().Port()),
 		attribute.String("url.full", request.URL().String()),
 	)
-	g.incRequestCounter(sc, "CREATE", plural, "spec")
+	g.incRequestCounter(ctx, sc, "CREATE", plural, "spec")
 	if err != nil {
 		err = parseKubernetesError(raw, sc, err)
 		span.SetStatus(codes.Error, err.Error())
@@ -197,6 +221,8 @@ func (g *groupVersionClient) update(ctx context.Context, plural string, obj reso
 	into resource.Object, _ resource.UpdateOptions, codec resource.Codec) error {
 	ctx, span := GetTracer().Start(ctx, "kubernetes-update")
 	defer span.End()
+	ctx, cancel := g.operationContext(ctx)
+	defer cancel()
 	addLabels(obj, map[string]string{
 		versionLabel: g.version,
 	})
@@ -206,16 +232,24 @@ func (g *groupVersionClient) update(ctx context.Context, plural string, obj reso
 		span.SetStatus(codes.Error, fmt.Sprintf("error marshaling kubernetes JSON: %s", err.Error()))
 		return err
 	}
+	body, err := g.pruneBody(ctx, obj.GetStaticMetadata().FullIdentifier(), buf.Bytes())
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
 
-	req := g.client.Put().Resource(plural).
-		Name(obj.GetName()).Body(buf.Bytes())
+	req, err := g.setBody(g.client.Put().Resource(plural).Name(obj.GetName()), body)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
 	if strings.TrimSpace(obj.GetNamespace()) != "" {
 		req = req.Namespace(obj.GetNamespace())
 	}
 	sc := 0
 	start := time.Now()
-	raw, err := req.Do(ctx).StatusCode(&sc).Raw()
-	g.logRequestDuration(time.Since(start), sc, "UPDATE", plural, "spec")
+	raw, err := g.do(ctx, req, &sc)
+	g.logRequestDuration(ctx, time.Since(start), sc, "UPDATE", plural, "spec", resource.Identifier{Namespace: obj.GetNamespace(), Name: obj.GetName()})
 	span.SetAttributes(
 		attribute.Int("http.response.status_code", sc),
 		attribute.String("http.request.method", http.MethodPut),
@@ -223,7 +257,7 @@ func (g *groupVersionClient) update(ctx context.Context, plural string, obj reso
 		attribute.String("server.port", req.URL().Port()),
 		attribute.String("url.full", req.URL().String()),
 	)
-	g.incRequestCounter(sc, "UPDATE", plural, "spec")
+	g.incRequestCounter(ctx, sc, "UPDATE", plural, "spec")
 	if err != nil {
 		err = parseKubernetesError(raw, sc, err)
 		span.SetStatus(codes.Error, err.Error())
@@ -241,6 +275,8 @@ func (g *groupVersionClient) updateSubresource(ctx context.Context, plural, subr
 	into resource.Object, _ resource.UpdateOptions, codec resource.Codec) error {
 	ctx, span := GetTracer().Start(ctx, "kubernetes-update-subresource")
 	defer span.End()
+	ctx, cancel := g.operationContext(ctx)
+	defer cancel()
 	addLabels(obj, map[string]string{
 		versionLabel: g.version,
 	})
@@ -250,16 +286,24 @@ func (g *groupVersionClient) updateSubresource(ctx context.Context, plural, subr
 		span.SetStatus(codes.Error, fmt.Sprintf("error marshaling kubernetes JSON: %s", err.Error()))
 		return err
 	}
+	body, err := g.pruneBody(ctx, obj.GetStaticMetadata().FullIdentifier(), buf.Bytes())
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
 
-	req := g.client.Put().Resource(plural).SubResource(subresource).
-		Name(obj.GetName()).Body(buf.Bytes())
+	req, err := g.setBody(g.client.Put().Resource(plural).SubResource(subresource).Name(obj.GetName()), body)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
 	if strings.TrimSpace(obj.GetNamespace()) != "" {
 		req = req.Namespace(obj.GetNamespace())
 	}
 	sc := 0
 	start := time.Now()
-	raw, err := req.Do(ctx).StatusCode(&sc).Raw()
-	g.logRequestDuration(time.Since(start), sc, "UPDATE", plural, subresource)
+	raw, err := g.do(ctx, req, &sc)
+	g.logRequestDuration(ctx, time.Since(start), sc, "UPDATE", plural, subresource, resource.Identifier{Namespace: obj.GetNamespace(), Name: obj.GetName()})
 	span.SetAttributes(
 		attribute.Int("http.response.status_code", sc),
 		attribute.String("http.request.method", http.MethodPut),
@@ -267,7 +311,7 @@ func (g *groupVersionClient) updateSubresource(ctx context.Context, plural, subr
 		attribute.String("server.port", req.URL().Port()),
 		attribute.String("url.full", req.URL().String()),
 	)
-	g.incRequestCounter(sc, "UPDATE", plural, subresource)
+	g.incRequestCounter(ctx, sc, "UPDATE", plural, subresource)
 	if err != nil {
 		err = parseKubernetesError(raw, sc, err)
 		span.SetStatus(codes.Error, err.Error())
@@ -282,23 +326,41 @@ func (g *groupVersionClient) updateSubresource(ctx context.Context, plural, subr
 }
 
 //nolint:revive,unused
-func (g *groupVersionClient) patch(ctx context.Context, identifier resource.Identifier, plural string,
-	patch resource.PatchRequest, into resource.Object, _ resource.PatchOptions, codec resource.Codec) error {
+func (g *groupVersionClient) patch(ctx context.Context, identifier resource.Identifier, gvk schema.GroupVersionKind, plural string,
+	patch resource.PatchRequest, into resource.Object, options resource.PatchOptions, codec resource.Codec) error {
 	ctx, span := GetTracer().Start(ctx, "kubernetes-patch")
 	defer span.End()
-	patchBytes, err := marshalJSONPatch(patch)
+	ctx, cancel := g.operationContext(ctx)
+	defer cancel()
+	patchType, patchBytes, err := marshalPatch(patch, gvk, codec)
 	if err != nil {
 		return err
 	}
-	req := g.client.Patch(types.JSONPatchType).Resource(plural).
+	if patch.Type == resource.PatchTypeApply && patch.Object != nil {
+		patchBytes, err = g.pruneBody(ctx, resource.FullIdentifier{
+			Namespace: identifier.Namespace,
+			Name:      identifier.Name,
+			Group:     gvk.Group,
+			Version:   gvk.Version,
+			Kind:      gvk.Kind,
+		}, patchBytes)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+	}
+	req := g.client.Patch(patchType).Resource(plural).
 		Name(identifier.Name).Body(patchBytes)
+	for k, v := range patchParams(options) {
+		req = req.Param(k, v)
+	}
 	if strings.TrimSpace(identifier.Namespace) != "" {
 		req = req.Namespace(identifier.Namespace)
 	}
 	sc := 0
 	start := time.Now()
-	raw, err := req.Do(ctx).StatusCode(&sc).Raw()
-	g.logRequestDuration(time.Since(start), sc, "PATCH", plural, "spec")
+	raw, err := g.do(ctx, req, &sc)
+	g.logRequestDuration(ctx, time.Since(start), sc, "PATCH", plural, "spec", identifier)
 	span.SetAttributes(
 		attribute.Int("http.response.status_code", sc),
 		attribute.String("http.request.method", http.MethodPatch),
@@ -306,7 +368,7 @@ func (g *groupVersionClient) patch(ctx context.Context, identifier resource.Iden
 		attribute.String("server.port", req.URL().Port()),
 		attribute.String("url.full", req.URL().String()),
 	)
-	g.incRequestCounter(sc, "PATCH", plural, "spec")
+	g.incRequestCounter(ctx, sc, "PATCH", plural, "spec")
 	if err != nil {
 		err = parseKubernetesError(raw, sc, err)
 		span.SetStatus(codes.Error, err.Error())
@@ -323,6 +385,8 @@ func (g *groupVersionClient) patch(ctx context.Context, identifier resource.Iden
 func (g *groupVersionClient) delete(ctx context.Context, identifier resource.Identifier, plural string, options resource.DeleteOptions) error {
 	ctx, span := GetTracer().Start(ctx, "kubernetes-delete")
 	defer span.End()
+	ctx, cancel := g.operationContext(ctx)
+	defer cancel()
 	sc := 0
 	request := g.client.Delete().Resource(plural).Name(identifier.Name)
 	if strings.TrimSpace(identifier.Namespace) != "" {
@@ -338,8 +402,8 @@ func (g *groupVersionClient) delete(ctx context.Context, identifier resource.Ide
 		request = request.Param("propagationPolicy", string(options.PropagationPolicy))
 	}
 	start := time.Now()
-	err := request.Do(ctx).StatusCode(&sc).Error()
-	g.logRequestDuration(time.Since(start), sc, "DELETE", plural, "spec")
+	err := g.doError(ctx, request, &sc)
+	g.logRequestDuration(ctx, time.Since(start), sc, "DELETE", plural, "spec", identifier)
 	span.SetAttributes(
 		attribute.Int("http.response.status_code", sc),
 		attribute.String("http.request.method", http.MethodDelete),
@@ -347,7 +411,7 @@ func (g *groupVersionClient) delete(ctx context.Context, identifier resource.Ide
 		attribute.String("server.port", request.URL().Port()),
 		attribute.String("url.full", request.URL().String()),
 	)
-	g.incRequestCounter(sc, "DELETE", plural, "spec")
+	g.incRequestCounter(ctx, sc, "DELETE", plural, "spec")
 	if err != nil && sc >= 300 {
 		return NewServerResponseError(err, sc)
 	}
@@ -358,6 +422,8 @@ func (g *groupVersionClient) list(ctx context.Context, namespace, plural string,
 	options resource.ListOptions, itemParser func([]byte) (resource.Object, error)) error {
 	ctx, span := GetTracer().Start(ctx, "kubernetes-list")
 	defer span.End()
+	ctx, cancel := g.operationContext(ctx)
+	defer cancel()
 	req := g.client.Get().Resource(plural)
 	if strings.TrimSpace(namespace) != "" {
 		req = req.Namespace(namespace)
@@ -377,10 +443,16 @@ func (g *groupVersionClient) list(ctx context.Context, namespace, plural string,
 	if options.ResourceVersion != "" {
 		req = req.Param("resourceVersion", options.ResourceVersion)
 	}
+	if options.ResourceVersionMatch != "" {
+		req = req.Param("resourceVersionMatch", options.ResourceVersionMatch)
+	}
+	if options.TimeoutSeconds > 0 {
+		req = req.Param("timeoutSeconds", strconv.FormatInt(options.TimeoutSeconds, 10))
+	}
 	sc := 0
 	start := time.Now()
-	raw, err := req.Do(ctx).StatusCode(&sc).Raw()
-	g.logRequestDuration(time.Since(start), sc, "LIST", plural, "spec")
+	raw, err := g.do(ctx, req, &sc)
+	g.logRequestDuration(ctx, time.Since(start), sc, "LIST", plural, "spec", resource.Identifier{Namespace: namespace})
 	span.SetAttributes(
 		attribute.Int("http.response.status_code", sc),
 		attribute.String("http.request.method", http.MethodGet),
@@ -388,7 +460,7 @@ func (g *groupVersionClient) list(ctx context.Context, namespace, plural string,
 		attribute.String("server.port", req.URL().Port()),
 		attribute.String("url.full", req.URL().String()),
 	)
-	g.incRequestCounter(sc, "LIST", plural, "spec")
+	g.incRequestCounter(ctx, sc, "LIST", plural, "spec")
 	if err != nil {
 		err = parseKubernetesError(raw, sc, err)
 		span.SetStatus(codes.Error, err.Error())
@@ -417,7 +489,19 @@ func (g *groupVersionClient) watch(ctx context.Context, namespace, plural string
 	if options.ResourceVersion != "" {
 		req = req.Param("resourceVersion", options.ResourceVersion)
 	}
+	if options.ResourceVersionMatch != "" {
+		req = req.Param("resourceVersionMatch", options.ResourceVersionMatch)
+	}
+	if options.TimeoutSeconds > 0 {
+		req = req.Param("timeoutSeconds", strconv.FormatInt(options.TimeoutSeconds, 10))
+	}
+	generation, err := g.breaker.allow()
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
 	resp, err := req.Watch(ctx)
+	g.breaker.done(generation, 0, err)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
@@ -429,7 +513,7 @@ func (g *groupVersionClient) watch(ctx context.Context, namespace, plural string
 		attribute.String("server.port", req.URL().Port()),
 		attribute.String("url.full", req.URL().String()),
 	)
-	g.incRequestCounter(http.StatusOK, "WATCH", plural, "spec")
+	g.incRequestCounter(ctx, http.StatusOK, "WATCH", plural, "spec")
 	channelBufferSize := options.EventBufferSize
 	if channelBufferSize <= 0 {
 		channelBufferSize = 1
@@ -444,28 +528,172 @@ func (g *groupVersionClient) watch(ctx context.Context, namespace, plural string
 	return w, nil
 }
 
-func (g *groupVersionClient) incRequestCounter(statusCode int, verb, kind, subresource string) {
+func (g *groupVersionClient) incRequestCounter(ctx context.Context, statusCode int, verb, kind, subresource string) {
 	if g.totalRequests == nil {
 		return
 	}
 
-	g.totalRequests.WithLabelValues(strconv.Itoa(statusCode), verb, kind, subresource).Inc()
+	metrics.IncWithTraceExemplar(ctx, g.totalRequests.WithLabelValues(strconv.Itoa(statusCode), verb, kind, subresource))
+}
+
+// pruneBody removes fields not present in the kind's schema from the JSON body of a create, update, or apply request,
+// if the client has a Pruner for the kind in identifier
+func (g *groupVersionClient) pruneBody(ctx context.Context, identifier resource.FullIdentifier, body []byte) ([]byte, error) {
+	pruner := g.config.Pruners[schema.GroupVersionKind{Group: identifier.Group, Version: identifier.Version, Kind: identifier.Kind}]
+	if pruner == nil {
+		return body, nil
+	}
+	pruned, paths, err := pruner.PruneJSON(body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to prune object: %w", err)
+	}
+	if len(paths) > 0 {
+		if g.config.OnPrune != nil {
+			g.config.OnPrune(ctx, identifier, paths)
+		} else {
+			logging.FromContext(ctx).Warn("pruned fields not present in the kind's schema",
+				"kind", identifier.Kind, "namespace", identifier.Namespace, "name", identifier.Name, "fields", paths)
+		}
+	}
+	return pruned, nil
+}
+
+// setBody sets body as the body of req. If the client uses CBOR, the JSON body is transcoded to CBOR.
+// Patch bodies are always sent as JSON, as patch content types are JSON-based.
+func (g *groupVersionClient) setBody(req *rest.Request, body []byte) (*rest.Request, error) {
+	if !g.config.UseCBOR {
+		return req.Body(body), nil
+	}
+	encoded, err := resource.JSONToCBOR(body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode request body as CBOR: %w", err)
+	}
+	return req.Body(encoded).SetHeader("Content-Type", string(resource.KindEncodingCBOR)), nil
+}
+
+// operationContext returns the context for a single request made with ctx, which ends DeadlineReserve before
+// the deadline of ctx, and is bounded by the OperationTimeout
+func (g *groupVersionClient) operationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	budgetCtx, budgetCancel := resource.WithDeadlineBudget(ctx, g.config.DeadlineReserve)
+	opCtx, opCancel := resource.WithOperationTimeout(budgetCtx, g.config.OperationTimeout)
+	return opCtx, func() {
+		opCancel()
+		budgetCancel()
+	}
+}
+
+// withDeadline sets the timeout of req to the time left before the deadline of ctx, if the client propagates deadlines
+func (g *groupVersionClient) withDeadline(ctx context.Context, req *rest.Request) *rest.Request {
+	if !g.config.PropagateDeadline {
+		return req
+	}
+	if remaining, ok := resource.RemainingBudget(ctx); ok && remaining > 0 {
+		return req.Timeout(remaining)
+	}
+	return req
+}
+
+// do executes req, and returns the raw response body, recording the response status code in statusCode.
+// If the client uses CBOR, the request accepts a CBOR response, and a CBOR response body is transcoded to JSON,
+// so callers can always handle the body as JSON. If the client has a circuit breaker which is open,
+// req is not executed, and a *CircuitOpenError is returned.
+func (g *groupVersionClient) do(ctx context.Context, req *rest.Request, statusCode *int) ([]byte, error) {
+	generation, err := g.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := g.doRaw(ctx, req, statusCode)
+	g.breaker.done(generation, *statusCode, err)
+	return raw, err
+}
+
+// doError executes req like do, but only returns the error of the request, without reading the response body
+func (g *groupVersionClient) doError(ctx context.Context, req *rest.Request, statusCode *int) error {
+	generation, err := g.breaker.allow()
+	if err != nil {
+		return err
+	}
+	err = g.withDeadline(ctx, req).Do(ctx).StatusCode(statusCode).Error()
+	g.breaker.done(generation, *statusCode, err)
+	return err
+}
+
+func (g *groupVersionClient) doRaw(ctx context.Context, req *rest.Request, statusCode *int) ([]byte, error) {
+	req = g.withDeadline(ctx, req)
+	if !g.config.UseCBOR {
+		return req.Do(ctx).StatusCode(statusCode).Raw()
+	}
+	contentType := ""
+	raw, err := req.SetHeader("Accept", cborAccept).Do(ctx).StatusCode(statusCode).ContentType(&contentType).Raw()
+	if *statusCode == 0 {
+		// An error response whose content type can't be decoded by the rest client carries its status code in the error
+		statusErr := &k8serrors.StatusError{}
+		if errors.As(err, &statusErr) {
+			*statusCode = int(statusErr.ErrStatus.Code)
+		}
+	}
+	if len(raw) > 0 && (strings.HasPrefix(contentType, string(resource.KindEncodingCBOR)) || resource.IsCBOR(raw)) {
+		transcoded, cerr := resource.CBORToJSON(raw)
+		if cerr != nil {
+			if err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("unable to transcode CBOR response: %w", cerr)
+		}
+		raw = transcoded
+	}
+	return raw, err
 }
 
-func (g *groupVersionClient) logRequestDuration(dur time.Duration, statusCode int, verb, kind, subresource string) {
+func (g *groupVersionClient) logRequestDuration(ctx context.Context, dur time.Duration, statusCode int, verb, kind, subresource string,
+	identifier resource.Identifier) {
+	g.logSlowRequest(ctx, dur, statusCode, verb, kind, subresource, identifier)
 	if g.requestDurations == nil {
 		return
 	}
 
-	g.requestDurations.WithLabelValues(strconv.Itoa(statusCode), verb, kind, subresource).Observe(dur.Seconds())
+	metrics.ObserveWithTraceExemplar(ctx, g.requestDurations.WithLabelValues(strconv.Itoa(statusCode), verb, kind, subresource), dur.Seconds())
+}
+
+// logSlowRequest logs and observes the request if dur exceeds the configured slow request threshold for the verb
+func (g *groupVersionClient) logSlowRequest(ctx context.Context, dur time.Duration, statusCode int, verb, kind, subresource string,
+	identifier resource.Identifier) {
+	threshold, ok := g.config.SlowRequestThresholds[verb]
+	if !ok {
+		threshold, ok = g.config.SlowRequestThresholds[SlowRequestThresholdAnyVerb]
+	}
+	if !ok || threshold <= 0 || dur < threshold {
+		return
+	}
+	logging.FromContext(ctx).Warn("slow kubernetes request",
+		"verb", verb,
+		"kind", kind,
+		"subresource", subresource,
+		"namespace", identifier.Namespace,
+		"name", identifier.Name,
+		"latency", dur.String(),
+		"threshold", threshold.String(),
+		"statusCode", statusCode)
+	if g.slowRequests != nil {
+		metrics.ObserveWithTraceExemplar(ctx, g.slowRequests.WithLabelValues(strconv.Itoa(statusCode), verb, kind, subresource), dur.Seconds())
+	}
 }
 
 func (g *groupVersionClient) metrics() []prometheus.Collector {
 	return []prometheus.Collector{
-		g.totalRequests, g.requestDurations,
+		g.totalRequests, g.requestDurations, g.slowRequests,
 	}
 }
 
+func newSlowRequestsSummary(namespace string) *prometheus.SummaryVec {
+	return prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace: namespace,
+		Subsystem: "kubernetes_client",
+		Name:      "slow_request_duration_seconds",
+		Help:      "Time (in seconds) spent serving HTTP requests which exceeded the configured slow request threshold.",
+	}, []string{"status_code", "verb", "kind", "subresource"})
+}
+
 // WatchResponse wraps a kubernetes watch.Interface in order to implement resource.WatchResponse.
 // The underlying watch.Interface can be accessed with KubernetesWatch().
 type WatchResponse struct {
@@ -1,13 +1,21 @@
 package k8s
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/resource"
 )
 
 func TestParseKubernetesError(t *testing.T) {
@@ -86,3 +94,35 @@ func TestParseKubernetesError(t *testing.T) {
 		})
 	}
 }
+
+func TestGroupVersionClient_logSlowRequest(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ctx := logging.Context(context.Background(), logging.NewSLogLogger(slog.NewTextHandler(buf, nil)))
+	client := &groupVersionClient{
+		config: ClientConfig{
+			SlowRequestThresholds: map[string]time.Duration{
+				"LIST":                      time.Second,
+				SlowRequestThresholdAnyVerb: time.Minute,
+			},
+		},
+		slowRequests: newSlowRequestsSummary(""),
+	}
+
+	client.logSlowRequest(ctx, 500*time.Millisecond, http.StatusOK, "LIST", "foos", "spec", resource.Identifier{Namespace: "ns"})
+	client.logSlowRequest(ctx, 2*time.Second, http.StatusOK, "GET", "foos", "spec", resource.Identifier{Namespace: "ns", Name: "foo"})
+	assert.Empty(t, buf.String())
+	assert.Equal(t, 0, testutil.CollectAndCount(client.slowRequests))
+
+	client.logSlowRequest(ctx, 2*time.Second, http.StatusOK, "LIST", "foos", "spec", resource.Identifier{Namespace: "ns"})
+	assert.Contains(t, buf.String(), "slow kubernetes request")
+	assert.Contains(t, buf.String(), "verb=LIST")
+	assert.Contains(t, buf.String(), "namespace=ns")
+	assert.Contains(t, buf.String(), "latency=2s")
+	assert.Equal(t, 1, testutil.CollectAndCount(client.slowRequests))
+
+	// No thresholds disables slow request logging
+	buf.Reset()
+	client.config.SlowRequestThresholds = nil
+	client.logSlowRequest(ctx, time.Hour, http.StatusOK, "LIST", "foos", "spec", resource.Identifier{Namespace: "ns"})
+	assert.Empty(t, buf.String())
+}
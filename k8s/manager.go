@@ -14,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	kschema "k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
 
 	"github.com/grafana/grafana-app-sdk/resource"
@@ -73,6 +74,8 @@ func (m *ResourceManager) WaitForAvailability(ctx context.Context, schema resour
 // with the new version.
 func (m *ResourceManager) RegisterSchema(ctx context.Context, schema resource.Schema,
 	options resource.RegisterSchemaOptions) error {
+	ctx, cancel := resource.WithOperationTimeout(ctx, options.Timeout)
+	defer cancel()
 	name := fmt.Sprintf("%s.%s", schema.Plural(), schema.Group())
 
 	// First, check if the CRD already exists
@@ -135,6 +138,52 @@ func (m *ResourceManager) RegisterSchema(ctx context.Context, schema resource.Sc
 	return nil
 }
 
+// GetCustomResourceDefinition gets the Custom Resource Definition with the provided name
+// (in the format <plural>.<group>) from the API server.
+func (m *ResourceManager) GetCustomResourceDefinition(ctx context.Context, name string) (*CustomResourceDefinition, error) {
+	sc := 0
+	crd := CustomResourceDefinition{}
+	err := m.client.Get().Resource("customresourcedefinitions").Name(name).
+		Do(ctx).StatusCode(&sc).Into(&crd)
+	if err != nil {
+		if sc >= 300 {
+			return nil, NewServerResponseError(err, sc)
+		}
+		return nil, err
+	}
+	return &crd, nil
+}
+
+// CreateCustomResourceDefinition creates the provided Custom Resource Definition in the API server.
+func (m *ResourceManager) CreateCustomResourceDefinition(ctx context.Context, crd *CustomResourceDefinition) error {
+	bytes, err := json.Marshal(crd)
+	if err != nil {
+		return err
+	}
+	sc := 0
+	err = m.client.Post().Resource("customresourcedefinitions").Body(bytes).Do(ctx).StatusCode(&sc).Error()
+	if err != nil && sc >= 300 {
+		return NewServerResponseError(err, sc)
+	}
+	return err
+}
+
+// PatchCustomResourceDefinition applies the provided JSON patch to the Custom Resource Definition with the provided name
+// (in the format <plural>.<group>).
+func (m *ResourceManager) PatchCustomResourceDefinition(ctx context.Context, name string, patch resource.PatchRequest) error {
+	bytes, err := json.Marshal(patch.Operations)
+	if err != nil {
+		return err
+	}
+	sc := 0
+	err = m.client.Patch(types.JSONPatchType).Resource("customresourcedefinitions").Name(name).Body(bytes).
+		Do(ctx).StatusCode(&sc).Error()
+	if err != nil && sc >= 300 {
+		return NewServerResponseError(err, sc)
+	}
+	return err
+}
+
 func (m *ResourceManager) create(ctx context.Context, schema resource.Schema, name string) error {
 	crd := CustomResourceDefinition{
 		TypeMeta: metav1.TypeMeta{
@@ -314,7 +363,8 @@ func getFieldKey(field *reflect.StructField) string {
 type CustomResourceDefinition struct {
 	metav1.TypeMeta   `json:",inline" yaml:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
-	Spec              CustomResourceDefinitionSpec `json:"spec"`
+	Spec              CustomResourceDefinitionSpec    `json:"spec"`
+	Status            *CustomResourceDefinitionStatus `json:"status,omitempty" yaml:"status,omitempty"`
 }
 
 // DeepCopyObject implements runtime.Object.
@@ -331,6 +381,13 @@ type CustomResourceDefinitionSpec struct {
 	Scope      string                                  `json:"scope" yaml:"scope"`
 }
 
+// CustomResourceDefinitionStatus is the status of a kubernetes Custom Resource Definition
+type CustomResourceDefinitionStatus struct {
+	// StoredVersions are the versions objects of the CRD have ever been stored in. The API server adds each storage version
+	// to the list, but versions are only removed from it by a client, once every object has been migrated to a newer version.
+	StoredVersions []string `json:"storedVersions,omitempty" yaml:"storedVersions,omitempty"`
+}
+
 type CustomResourceDefinitionSpecConversion struct {
 	Strategy string                                         `json:"strategy" yaml:"strategy"`
 	Webhook  *CustomResourceDefinitionSpecConversionWebhook `json:"webhook,omitempty" yaml:"webhook,omitempty"`
@@ -361,12 +418,18 @@ type CustomResourceDefinitionSpecVersion struct {
 	Subresources             map[string]any                                    `json:"subresources,omitempty" yaml:"subresources,omitempty"`
 	SelectableFields         []CustomResourceDefinitionSelectableField         `json:"selectableFields,omitempty" yaml:"selectableFields,omitempty"`
 	AdditionalPrinterColumns []CustomResourceDefinitionAdditionalPrinterColumn `json:"additionalPrinterColumns,omitempty" yaml:"additionalPrinterColumns,omitempty"`
+	// Deprecated indicates that the API server returns a warning for requests made with this version
+	Deprecated bool `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	// DeprecationWarning overrides the default warning returned for requests made with a deprecated version
+	DeprecationWarning *string `json:"deprecationWarning,omitempty" yaml:"deprecationWarning,omitempty"`
 }
 
-// CustomResourceDefinitionSpecNames is the struct representing the names (kind and plural) of a kubernetes CRD
+// CustomResourceDefinitionSpecNames is the struct representing the names (kind, plural, and presentation names) of a kubernetes CRD
 type CustomResourceDefinitionSpecNames struct {
-	Kind   string `json:"kind" yaml:"kind"`
-	Plural string `json:"plural" yaml:"plural"`
+	Kind       string   `json:"kind" yaml:"kind"`
+	Plural     string   `json:"plural" yaml:"plural"`
+	ShortNames []string `json:"shortNames,omitempty" yaml:"shortNames,omitempty"`
+	Categories []string `json:"categories,omitempty" yaml:"categories,omitempty"`
 }
 
 // CustomResourceDefinitionSelectableField is the struct representing a selectable field in a kubernetes CRD.
@@ -376,6 +439,16 @@ type CustomResourceDefinitionSelectableField struct {
 	JSONPath string `json:"jsonPath" yaml:"jsonPath"`
 }
 
+// CustomResourceDefinitionSubresourceScale is the struct representing the scale subresource of a version of a kubernetes CRD,
+// which is set as the "scale" key of CustomResourceDefinitionSpecVersion.Subresources.
+// This is a copy of https://pkg.go.dev/k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1#CustomResourceSubresourceScale
+// with YAML tags attached to the field.
+type CustomResourceDefinitionSubresourceScale struct {
+	SpecReplicasPath   string  `json:"specReplicasPath" yaml:"specReplicasPath"`
+	StatusReplicasPath string  `json:"statusReplicasPath" yaml:"statusReplicasPath"`
+	LabelSelectorPath  *string `json:"labelSelectorPath,omitempty" yaml:"labelSelectorPath,omitempty"`
+}
+
 // CustomResourceDefinitionAdditionalPrinterColumn is the struct representing an additional printer column in a kubernetes CRD.
 // This is a copy of https://pkg.go.dev/k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1#CustomResourceDefinitionAdditionalPrinterColumn
 type CustomResourceDefinitionAdditionalPrinterColumn struct {
@@ -203,10 +203,11 @@ func TestResourceManager_RegisterSchema(t *testing.T) {
 }
 
 func TestResourceManager_WaitForAvailability(t *testing.T) {
-	manager, server := getTestManagerAndServer()
-	defer server.Close()
-
+	// Each subtest uses its own server, as requests aborted by the context deadline
+	// may still be handled after the subtest returns
 	t.Run("unknown error", func(t *testing.T) {
+		manager, server := getTestManagerAndServer()
+		defer server.Close()
 		server.responseFunc = func(writer http.ResponseWriter, request *http.Request) {
 			writer.WriteHeader(http.StatusBadRequest)
 		}
@@ -216,6 +217,8 @@ func TestResourceManager_WaitForAvailability(t *testing.T) {
 	})
 
 	t.Run("timeout reached", func(t *testing.T) {
+		manager, server := getTestManagerAndServer()
+		defer server.Close()
 		requestCount := 0
 		server.responseFunc = func(writer http.ResponseWriter, request *http.Request) {
 			requestCount++
@@ -229,6 +232,8 @@ func TestResourceManager_WaitForAvailability(t *testing.T) {
 	})
 
 	t.Run("success", func(t *testing.T) {
+		manager, server := getTestManagerAndServer()
+		defer server.Close()
 		requestCount := 0
 		server.responseFunc = func(writer http.ResponseWriter, request *http.Request) {
 			if requestCount > 0 {
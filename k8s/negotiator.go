@@ -2,7 +2,6 @@ package k8s
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"io"
 
@@ -61,26 +60,27 @@ func (*GenericJSONDecoder) Decode(data []byte, defaults *schema.GroupVersionKind
 	if into != nil {
 		// We shouldn't encounter this
 		// TODO: make better
-		err := json.Unmarshal(data, into)
+		err := resource.DefaultJSONImplementation().Unmarshal(data, into)
 		return into, defaults, err
 	}
 
 	// Determine what kind of object we have the raw bytes for
 	// We do this by unmarshalling into a superset of a few possible types, then narrowing down
 	// TODO: this seems very naive, check how apimachinery does it typically
+	impl := resource.DefaultJSONImplementation()
 	chk := check{}
-	err := json.Unmarshal(data, &chk)
+	err := impl.Unmarshal(data, &chk)
 	if chk.Type != "" {
 		// Watch response
 		w := &UntypedWatchObject{}
-		err = json.Unmarshal(data, w)
+		err = impl.Unmarshal(data, w)
 		into = w
 	} else if chk.Items != nil {
 		// List
 		// TODO
 	} else if chk.Kind != "" {
 		o := &UntypedObjectWrapper{}
-		err = json.Unmarshal(data, o)
+		err = impl.Unmarshal(data, o)
 		o.object = data
 		into = o
 	}
@@ -90,7 +90,7 @@ func (*GenericJSONDecoder) Decode(data []byte, defaults *schema.GroupVersionKind
 // Encode json-encodes the provided object
 func (*GenericJSONDecoder) Encode(obj runtime.Object, w io.Writer) error {
 	// TODO: check compliance with resource.Object and use marshalJSON in that case
-	b, e := json.Marshal(obj)
+	b, e := resource.DefaultJSONImplementation().Marshal(obj)
 	if e != nil {
 		return e
 	}
@@ -124,12 +124,27 @@ func (k *KindNegotiatedSerializer) SupportedMediaTypes() []runtime.SerializerInf
 		// Framer is used for the stream serializer
 		switch encoding {
 		case resource.KindEncodingJSON:
-			serializer.Decoder = json.Unmarshal
+			// Decode non-Object responses with the same JSONImplementation as the Kind's codec
+			impl := resource.DefaultJSONImplementation()
+			if jsonCodec, ok := codec.(*resource.JSONCodec); ok {
+				impl = jsonCodec.JSONImplementation()
+			}
+			serializer.Decoder = impl.Unmarshal
 			info.Serializer = serializer
 			info.StreamSerializer = &runtime.StreamSerializerInfo{
 				Serializer: serializer,
 				Framer:     jsonserializer.Framer,
 			}
+		case resource.KindEncodingCBOR:
+			// Decode non-Object responses (such as a Status) by transcoding them to JSON
+			serializer.Decoder = func(data []byte, into any) error {
+				j, err := resource.CBORToJSON(data)
+				if err != nil {
+					return err
+				}
+				return resource.DefaultJSONImplementation().Unmarshal(j, into)
+			}
+			info.Serializer = serializer
 		case resource.KindEncodingYAML:
 			// TODO: YAML framer
 			//	framer = yamlserializer.Framer <- doesn't exist
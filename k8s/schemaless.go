@@ -41,6 +41,8 @@ type SchemalessClient struct {
 	// prometheus collectors for the client
 	requestDurations *prometheus.HistogramVec
 	totalRequests    *prometheus.CounterVec
+	slowRequests     *prometheus.SummaryVec
+	breakerMetrics   *circuitBreakerMetrics
 }
 
 // NewSchemalessClient creates a new SchemalessClient using the provided rest.Config and ClientConfig.
@@ -72,6 +74,8 @@ func NewSchemalessClientWithCodec(kubeConfig rest.Config, clientConfig ClientCon
 			Namespace: clientConfig.MetricsConfig.Namespace,
 			Help:      "Total number of kubernetes requests",
 		}, []string{"status_code", "verb", "kind", "subresource"}),
+		slowRequests:   newSlowRequestsSummary(clientConfig.MetricsConfig.Namespace),
+		breakerMetrics: newCircuitBreakerMetrics(clientConfig.MetricsConfig.Namespace),
 	}
 }
 
@@ -159,7 +163,8 @@ func (s *SchemalessClient) Update(ctx context.Context, identifier resource.FullI
 	return client.update(ctx, s.getPlural(identifier), obj, into, options, s.codec)
 }
 
-// Patch performs a JSON Patch on the provided resource, and marshals the updated version into the `into` field
+// Patch performs a patch on the provided resource, and marshals the updated version into the `into` field.
+// By default, the patch is a JSON Patch (see k8s.Client.Patch for other patch types).
 func (s *SchemalessClient) Patch(ctx context.Context, identifier resource.FullIdentifier, patch resource.PatchRequest,
 	options resource.PatchOptions, into resource.Object) error {
 	client, err := s.getClient(identifier)
@@ -170,6 +175,10 @@ func (s *SchemalessClient) Patch(ctx context.Context, identifier resource.FullId
 	return client.patch(ctx, resource.Identifier{
 		Namespace: identifier.Namespace,
 		Name:      identifier.Name,
+	}, schema.GroupVersionKind{
+		Group:   identifier.Group,
+		Version: identifier.Version,
+		Kind:    identifier.Kind,
 	}, s.getPlural(identifier), patch, into, options, s.codec)
 }
 
@@ -222,9 +231,9 @@ func (s *SchemalessClient) Watch(ctx context.Context, identifier resource.FullId
 
 // PrometheusCollectors returns the prometheus metric collectors used by this client to allow for registration
 func (s *SchemalessClient) PrometheusCollectors() []prometheus.Collector {
-	return []prometheus.Collector{
-		s.totalRequests, s.requestDurations,
-	}
+	return append([]prometheus.Collector{
+		s.totalRequests, s.requestDurations, s.slowRequests,
+	}, s.breakerMetrics.collectors()...)
 }
 
 func (s *SchemalessClient) getClient(identifier resource.FullIdentifier) (*groupVersionClient, error) {
@@ -244,12 +253,19 @@ func (s *SchemalessClient) getClient(identifier resource.FullIdentifier) (*group
 	if err != nil {
 		return nil, err
 	}
+	// Clients are per-GroupVersion, so each client has its own circuit breaker
+	var breaker *CircuitBreaker
+	if s.clientConfig.CircuitBreaker != nil {
+		breaker = newCircuitBreaker(gv, *s.clientConfig.CircuitBreaker, s.breakerMetrics)
+	}
 	s.clients[gv.Identifier()] = &groupVersionClient{
 		client:           client,
 		version:          identifier.Version,
 		config:           s.clientConfig,
 		requestDurations: s.requestDurations,
 		totalRequests:    s.totalRequests,
+		slowRequests:     s.slowRequests,
+		breaker:          breaker,
 	}
 	return s.clients[gv.Identifier()], nil
 }
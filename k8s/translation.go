@@ -3,12 +3,16 @@ package k8s
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
 
 	admission "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/grafana/grafana-app-sdk/resource"
 )
@@ -29,9 +33,20 @@ func rawToListWithParser(raw []byte, into resource.ListObject, itemParser func([
 	// Attempt to parse all items in the list before setting the parsed metadata,
 	// as the parser could return an error, and we don't want to _partially_ unmarshal the list (just metadata) into `into`
 	items := make([]resource.Object, 0)
+	var strictErr *resource.StrictDecodingListError
 	for _, item := range um.Items {
 		parsed, err := itemParser(item)
-		if err != nil {
+		// Items with unknown fields are still read, so they are kept, and reported together once the list is set
+		var cast *resource.StrictDecodingError
+		if err != nil && parsed != nil && errors.As(err, &cast) {
+			if strictErr == nil {
+				strictErr = &resource.StrictDecodingListError{}
+			}
+			strictErr.Objects = append(strictErr.Objects, resource.StrictDecodingObjectError{
+				Identifier: resource.Identifier{Namespace: parsed.GetNamespace(), Name: parsed.GetName()},
+				Err:        cast,
+			})
+		} else if err != nil {
 			return err
 		}
 		items = append(items, parsed)
@@ -42,6 +57,9 @@ func rawToListWithParser(raw []byte, into resource.ListObject, itemParser func([
 	into.SetContinue(um.Metadata.Continue)
 	into.SetRemainingItemCount(um.Metadata.GetRemainingItemCount())
 	into.SetItems(items)
+	if strictErr != nil {
+		return strictErr
+	}
 	return nil
 }
 
@@ -92,6 +110,62 @@ func marshalJSONPatch(patch resource.PatchRequest) ([]byte, error) {
 	return json.Marshal(patch.Operations)
 }
 
+// marshalPatch returns the kubernetes patch type and body for the PatchRequest.
+// JSON patches are marshaled with marshalJSONPatch, and other patch types use the encoded patch.Object
+// (using codec, and gvk if the object has no GroupVersionKind set), or patch.Body if no object is set.
+func marshalPatch(patch resource.PatchRequest, gvk schema.GroupVersionKind, codec resource.Codec) (types.PatchType, []byte, error) {
+	var patchType types.PatchType
+	switch patch.Type {
+	case "", resource.PatchTypeJSONPatch:
+		body, err := marshalJSONPatch(patch)
+		return types.JSONPatchType, body, err
+	case resource.PatchTypeMergePatch:
+		patchType = types.MergePatchType
+	case resource.PatchTypeStrategicMergePatch:
+		patchType = types.StrategicMergePatchType
+	case resource.PatchTypeApply:
+		patchType = types.ApplyPatchType
+	default:
+		return "", nil, fmt.Errorf("unknown patch type '%s'", patch.Type)
+	}
+	if patch.Object == nil {
+		if len(patch.Body) == 0 {
+			return "", nil, fmt.Errorf("%s patch must have an object or body", patch.Type)
+		}
+		return patchType, patch.Body, nil
+	}
+	obj := patch.Object
+	if obj.GetObjectKind().GroupVersionKind().Empty() {
+		// Apply patches must contain apiVersion and kind, so set them without modifying the caller's object
+		obj = obj.Copy()
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+	if patch.Type == resource.PatchTypeApply && obj.GetManagedFields() != nil {
+		// The API server rejects apply patches which include managed fields, which objects returned by a Get will have
+		if obj == patch.Object {
+			obj = obj.Copy()
+		}
+		obj.SetManagedFields(nil)
+	}
+	buf := &bytes.Buffer{}
+	if err := codec.Write(buf, obj); err != nil {
+		return "", nil, err
+	}
+	return patchType, buf.Bytes(), nil
+}
+
+// patchParams returns the request parameters for the PatchOptions
+func patchParams(options resource.PatchOptions) map[string]string {
+	params := make(map[string]string)
+	if options.FieldManager != "" {
+		params["fieldManager"] = options.FieldManager
+	}
+	if options.Force {
+		params["force"] = "true"
+	}
+	return params
+}
+
 func getV1ObjectMetaFields() map[string]struct{} {
 	fields := make(map[string]struct{})
 	typ := reflect.TypeOf(metav1.ObjectMeta{})
@@ -125,42 +199,92 @@ func translateKubernetesAdmissionRequest(req *admission.AdmissionRequest, sch re
 	var err error
 	var obj, old resource.Object
 
+	// Only the object being written is strictly decoded (if the kind uses strict decoding). The stored object is not,
+	// so that an object with unknown fields can still be updated (such as to remove the fields) or deleted.
+	lenient := resource.LenientDecodingKind(sch)
 	if len(req.Object.Raw) > 0 {
-		obj, err = sch.Read(bytes.NewReader(req.Object.Raw), resource.KindEncodingJSON)
+		objKind := sch
+		if req.Operation == admission.Delete {
+			objKind = lenient
+		}
+		obj, err = objKind.Read(bytes.NewReader(req.Object.Raw), resource.KindEncodingJSON)
 		if err != nil {
 			return nil, err
 		}
 	}
 	if len(req.OldObject.Raw) > 0 {
-		old, err = sch.Read(bytes.NewReader(req.OldObject.Raw), resource.KindEncodingJSON)
+		old, err = lenient.Read(bytes.NewReader(req.OldObject.Raw), resource.KindEncodingJSON)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	var action resource.AdmissionAction
-	switch req.Operation {
+	return &resource.AdmissionRequest{
+		Action:    translateKubernetesAdmissionOperation(req.Operation),
+		Kind:      req.Kind.Kind,
+		Group:     req.Kind.Group,
+		Version:   req.Kind.Version,
+		UserInfo:  translateKubernetesUserInfo(req.UserInfo),
+		Object:    obj,
+		OldObject: old,
+	}, nil
+}
+
+func translateKubernetesAdmissionOperation(op admission.Operation) resource.AdmissionAction {
+	switch op {
 	case admission.Create:
-		action = resource.AdmissionActionCreate
+		return resource.AdmissionActionCreate
 	case admission.Update:
-		action = resource.AdmissionActionUpdate
+		return resource.AdmissionActionUpdate
 	case admission.Delete:
-		action = resource.AdmissionActionDelete
+		return resource.AdmissionActionDelete
 	case admission.Connect:
-		action = resource.AdmissionActionConnect
+		return resource.AdmissionActionConnect
+	default:
+		return ""
 	}
+}
 
-	return &resource.AdmissionRequest{
-		Action:  action,
-		Kind:    req.Kind.Kind,
-		Group:   req.Kind.Group,
-		Version: req.Kind.Version,
-		UserInfo: resource.AdmissionUserInfo{
-			Username: req.UserInfo.Username,
-			UID:      req.UserInfo.UID,
-			Groups:   req.UserInfo.Groups,
-		},
-		Object:    obj,
-		OldObject: old,
-	}, nil
+func translateKubernetesUserInfo(info authenticationv1.UserInfo) resource.AdmissionUserInfo {
+	userInfo := resource.AdmissionUserInfo{
+		Username: info.Username,
+		UID:      info.UID,
+		Groups:   info.Groups,
+	}
+	if len(info.Extra) > 0 {
+		userInfo.Extra = make(map[string]any, len(info.Extra))
+		for k, v := range info.Extra {
+			userInfo.Extra[k] = []string(v)
+		}
+	}
+	return userInfo
+}
+
+// admissionRequestOptions contains the fields used for AdmissionOptions from any of the kubernetes
+// CreateOptions, UpdateOptions, PatchOptions, or DeleteOptions sent in an admission request
+type admissionRequestOptions struct {
+	FieldManager string `json:"fieldManager"`
+	Force        *bool  `json:"force"`
+}
+
+// translateKubernetesAdmissionContext returns the resource.AdmissionContext for a kubernetes admission request.
+// Options which cannot be parsed are left empty, as they are informational and should not fail the request.
+func translateKubernetesAdmissionContext(req *admission.AdmissionRequest) resource.AdmissionContext {
+	admCtx := resource.AdmissionContext{
+		RequestUID:  string(req.UID),
+		Action:      translateKubernetesAdmissionOperation(req.Operation),
+		UserInfo:    translateKubernetesUserInfo(req.UserInfo),
+		SubResource: req.SubResource,
+	}
+	if req.DryRun != nil {
+		admCtx.DryRun = *req.DryRun
+	}
+	if len(req.Options.Raw) > 0 {
+		opts := admissionRequestOptions{}
+		if err := json.Unmarshal(req.Options.Raw, &opts); err == nil {
+			admCtx.Options.FieldManager = opts.FieldManager
+			admCtx.Options.Force = opts.Force != nil && *opts.Force
+		}
+	}
+	return admCtx
 }
@@ -82,6 +82,44 @@ func TestRawToListWithParser(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		{
+			name: "strict decoding errors",
+			raw:  completeListJSON,
+			into: &resource.UntypedList{},
+			parser: func(bytes []byte) (resource.Object, error) {
+				obj := &resource.TypedSpecObject[[]byte]{
+					Spec: bytes,
+				}
+				obj.SetName(string(bytes[2:3]))
+				if string(bytes) == `["b"]` {
+					return obj, &resource.StrictDecodingError{UnknownFields: []string{"spec.foo"}}
+				}
+				return obj, nil
+			},
+			expectedList: &resource.UntypedList{
+				ListMeta: metav1.ListMeta{
+					ResourceVersion:    completeList.Metadata.ResourceVersion,
+					Continue:           completeList.Metadata.Continue,
+					RemainingItemCount: completeList.Metadata.RemainingItemCount,
+				},
+				Items: []resource.Object{
+					&resource.TypedSpecObject[[]byte]{
+						ObjectMeta: metav1.ObjectMeta{Name: "a"},
+						Spec:       []byte(`["a"]`),
+					}, &resource.TypedSpecObject[[]byte]{
+						ObjectMeta: metav1.ObjectMeta{Name: "b"},
+						Spec:       []byte(`["b"]`),
+					}, &resource.TypedSpecObject[[]byte]{
+						ObjectMeta: metav1.ObjectMeta{Name: "c"},
+						Spec:       []byte(`["c"]`),
+					},
+				},
+			},
+			expectedError: &resource.StrictDecodingListError{Objects: []resource.StrictDecodingObjectError{{
+				Identifier: resource.Identifier{Name: "b"},
+				Err:        &resource.StrictDecodingError{UnknownFields: []string{"spec.foo"}},
+			}}},
+		},
 	}
 
 	for _, test := range tests {
@@ -3,12 +3,17 @@ package k8s
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"gomodules.xyz/jsonpatch/v2"
 	admission "k8s.io/api/admission/v1beta1"
 	conversion "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -16,13 +21,18 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/metrics"
 	"github.com/grafana/grafana-app-sdk/resource"
 )
 
 // WebhookServerConfig is the configuration object for a WebhookServer, used with NewWebhookServer.
 type WebhookServerConfig struct {
-	// The Port to run the HTTPS server on
+	// The Port to run the HTTPS server on. It is ignored if Listener is non-nil.
 	Port int
+	// Listener, if non-nil, is the listener the HTTPS server accepts connections on, instead of listening on Port.
+	// This can be used to serve webhooks on a Unix socket, or on a socket inherited from the parent process.
+	// The WebhookServer closes the Listener when it stops.
+	Listener net.Listener
 	// TLSConfig contains cert information for running the HTTPS server
 	TLSConfig TLSConfig
 	// ValidatingControllers is a map of schemas to their corresponding ValidatingAdmissionController.
@@ -38,17 +48,89 @@ type WebhookServerConfig struct {
 	// DefaultMutatingController is called for any /validate requests received which don't have an entry in MutatingControllers.
 	// If left nil, an error will be returned to the caller instead.
 	DefaultMutatingController resource.MutatingAdmissionController
+	// MetricsConfig is the configuration for the prometheus metrics exposed by the WebhookServer
+	MetricsConfig metrics.Config
+	// Paths are the base paths the webhooks are served on. Empty paths use the path from DefaultWebhookPaths.
+	Paths WebhookPaths
+	// DeadlineReserve is the part of the API server's webhook timeout which is reserved for writing the admission response.
+	// Admission controllers are called with a context whose deadline is DeadlineReserve earlier than the API server's
+	// timeout, so that downstream calls made by the controller end in time for the server to respond.
+	// If zero, the whole timeout is given to the controller.
+	DeadlineReserve time.Duration
 }
 
-// TLSConfig describes a set of TLS files
+// DefaultWebhookPaths are the WebhookPaths used by a WebhookServer with no configured paths
+var DefaultWebhookPaths = WebhookPaths{
+	Validate: "/validate",
+	Mutate:   "/mutate",
+	Convert:  "/convert",
+}
+
+// WebhookPaths are the base paths a WebhookServer serves validating, mutating, and conversion webhooks on.
+// Each base path accepts requests for any kind, and the WebhookServer also serves per-kind paths under each base path
+// (see ValidatePath, MutatePath, and ConvertPath), so that each kind version can have its own webhook configuration.
+type WebhookPaths struct {
+	Validate string
+	Mutate   string
+	Convert  string
+}
+
+// ValidatePath returns the path validating admission requests for the kind version are routed by,
+// <Validate>/<group>/<version>/<plural>.
+func (p WebhookPaths) ValidatePath(group, version, plural string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", p.withDefaults().Validate, group, version, plural)
+}
+
+// MutatePath returns the path mutating admission requests for the kind version are routed by,
+// <Mutate>/<group>/<version>/<plural>.
+func (p WebhookPaths) MutatePath(group, version, plural string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", p.withDefaults().Mutate, group, version, plural)
+}
+
+// ConvertPath returns the conversion path for the kind, <Convert>/<group>/<plural>.
+// Conversion requests contain objects of a single kind, so requests to this path are handled the same way
+// as requests to the Convert base path, but it allows the path to identify the kind in logs and proxies.
+func (p WebhookPaths) ConvertPath(group, plural string) string {
+	return fmt.Sprintf("%s/%s/%s", p.withDefaults().Convert, group, plural)
+}
+
+func (p WebhookPaths) withDefaults() WebhookPaths {
+	if p.Validate == "" {
+		p.Validate = DefaultWebhookPaths.Validate
+	}
+	if p.Mutate == "" {
+		p.Mutate = DefaultWebhookPaths.Mutate
+	}
+	if p.Convert == "" {
+		p.Convert = DefaultWebhookPaths.Convert
+	}
+	p.Validate = strings.TrimSuffix(p.Validate, "/")
+	p.Mutate = strings.TrimSuffix(p.Mutate, "/")
+	p.Convert = strings.TrimSuffix(p.Convert, "/")
+	return p
+}
+
+// TLSConfig describes a set of TLS files, or a CertificateProvider to get the certificate from
 type TLSConfig struct {
 	// CertPath is the path to the on-disk cert file
 	CertPath string
 	// KeyPath is the path to the on-disk key file for the cert
 	KeyPath string
+	// CertificateProvider, if non-nil, is used to get the certificate instead of CertPath and KeyPath.
+	// Certificates are renewed from the provider before they expire, and served to new connections without a restart.
+	// If nil, the certificate is loaded from CertPath and KeyPath, and reloaded from them when they change or it is due for renewal.
+	CertificateProvider CertificateProvider
+	// RenewBefore is how long before the certificate expires to get a new one.
+	// If zero, a new certificate is requested after two-thirds of the current certificate's lifetime.
+	RenewBefore time.Duration
+	// WatchInterval is how often CertPath and KeyPath are checked for changes (such as a Secret volume updated by cert-manager),
+	// so that an updated certificate is served without a restart. If zero, the files are checked every 10 seconds,
+	// and if negative, they are only reloaded when the certificate is due for renewal. It is ignored with a CertificateProvider.
+	WatchInterval time.Duration
 }
 
-// WebhookServer is a kubernetes webhook server, which exposes /validate and /mutate HTTPS endpoints.
+// WebhookServer is a kubernetes webhook server, which exposes /validate, /mutate, and /convert HTTPS endpoints,
+// along with per-kind endpoints under each of them (see WebhookPaths).
 // It implements operator.Controller and can be run as a controller in an operator, or as a standalone process.
 type WebhookServer struct {
 	// DefaultValidatingController is the default ValidatingAdmissionController to use if one is not defined for the schema in the request.
@@ -60,22 +142,32 @@ type WebhookServer struct {
 	validatingControllers     map[string]validatingAdmissionControllerTuple
 	mutatingControllers       map[string]mutatingAdmissionControllerTuple
 	converters                map[string]Converter
+	validatingRoutes          map[string]string
+	mutatingRoutes            map[string]string
+	paths                     WebhookPaths
 	port                      int
+	listener                  net.Listener
 	tlsConfig                 TLSConfig
+	certificates              *certificateRotator
+	deadlineReserve           time.Duration
+	admissionRequests         *prometheus.CounterVec
+	admissionErrors           *prometheus.CounterVec
 }
 
 // NewWebhookServer creates a new WebhookServer using the provided configuration.
-// The only required parts of the config are the Port and TLSConfig, as all other parts
+// The only required parts of the config are the Port (or Listener) and TLSConfig, as all other parts
 // (default controllers, schema-specific controllers) can be set post-initialization.
 func NewWebhookServer(config WebhookServerConfig) (*WebhookServer, error) {
-	if config.Port < 1 || config.Port > 65536 {
+	if config.Listener == nil && (config.Port < 1 || config.Port > 65536) {
 		return nil, fmt.Errorf("config.Port must be a valid port number (between 1 and 65536)")
 	}
-	if config.TLSConfig.CertPath == "" {
-		return nil, fmt.Errorf("config.TLSConfig.CertPath is required")
-	}
-	if config.TLSConfig.KeyPath == "" {
-		return nil, fmt.Errorf("config.TLSConfig.KeyPath is required")
+	if config.TLSConfig.CertificateProvider == nil {
+		if config.TLSConfig.CertPath == "" {
+			return nil, fmt.Errorf("config.TLSConfig.CertPath is required")
+		}
+		if config.TLSConfig.KeyPath == "" {
+			return nil, fmt.Errorf("config.TLSConfig.KeyPath is required")
+		}
 	}
 
 	ws := WebhookServer{
@@ -84,8 +176,26 @@ func NewWebhookServer(config WebhookServerConfig) (*WebhookServer, error) {
 		validatingControllers:       make(map[string]validatingAdmissionControllerTuple),
 		mutatingControllers:         make(map[string]mutatingAdmissionControllerTuple),
 		converters:                  make(map[string]Converter),
+		validatingRoutes:            make(map[string]string),
+		mutatingRoutes:              make(map[string]string),
+		paths:                       config.Paths.withDefaults(),
 		port:                        config.Port,
+		listener:                    config.Listener,
 		tlsConfig:                   config.TLSConfig,
+		certificates:                newTLSConfigRotator(config.TLSConfig),
+		deadlineReserve:             config.DeadlineReserve,
+		admissionRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: config.MetricsConfig.Namespace,
+			Subsystem: "webhook",
+			Name:      "admission_requests_total",
+			Help:      "Total number of admission requests handled by the webhook server, by webhook, kind, operation, and whether the request was allowed.",
+		}, []string{"webhook", "kind", "operation", "allowed"}),
+		admissionErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: config.MetricsConfig.Namespace,
+			Subsystem: "webhook",
+			Name:      "admission_errors_total",
+			Help:      "Total number of admission requests which the webhook server failed to handle, by webhook and kind.",
+		}, []string{"webhook", "kind"}),
 	}
 
 	for sch, controller := range config.ValidatingControllers {
@@ -110,11 +220,16 @@ func (w *WebhookServer) AddValidatingAdmissionController(controller resource.Val
 	if w.validatingControllers == nil {
 		w.validatingControllers = make(map[string]validatingAdmissionControllerTuple)
 	}
-	w.validatingControllers[gvk(&metav1.GroupVersionKind{
+	if w.validatingRoutes == nil {
+		w.validatingRoutes = make(map[string]string)
+	}
+	key := gvk(&metav1.GroupVersionKind{
 		Group:   kind.Group(),
 		Version: kind.Version(),
 		Kind:    kind.Kind(),
-	})] = validatingAdmissionControllerTuple{
+	})
+	w.validatingRoutes[kindRoute(kind)] = key
+	w.validatingControllers[key] = validatingAdmissionControllerTuple{
 		schema:     kind,
 		controller: controller,
 	}
@@ -127,11 +242,16 @@ func (w *WebhookServer) AddMutatingAdmissionController(controller resource.Mutat
 	if w.mutatingControllers == nil {
 		w.mutatingControllers = make(map[string]mutatingAdmissionControllerTuple)
 	}
-	w.mutatingControllers[gvk(&metav1.GroupVersionKind{
+	if w.mutatingRoutes == nil {
+		w.mutatingRoutes = make(map[string]string)
+	}
+	key := gvk(&metav1.GroupVersionKind{
 		Group:   kind.Group(),
 		Version: kind.Version(),
 		Kind:    kind.Kind(),
-	})] = mutatingAdmissionControllerTuple{
+	})
+	w.mutatingRoutes[kindRoute(kind)] = key
+	w.mutatingControllers[key] = mutatingAdmissionControllerTuple{
 		schema:     kind,
 		controller: controller,
 	}
@@ -145,22 +265,49 @@ func (w *WebhookServer) AddConverter(converter Converter, groupKind metav1.Group
 	w.converters[gk(groupKind.Group, groupKind.Kind)] = converter
 }
 
-// Run establishes an HTTPS server on the configured port and exposes `/validate` and `/mutate` paths for kubernetes
-// validating and mutating webhooks, respectively. It will block until either closeChan is closed (in which case it returns nil),
+// PrometheusCollectors returns the prometheus metric collectors used by the WebhookServer
+func (w *WebhookServer) PrometheusCollectors() []prometheus.Collector {
+	if w.admissionRequests == nil || w.admissionErrors == nil {
+		return nil
+	}
+	return []prometheus.Collector{w.admissionRequests, w.admissionErrors}
+}
+
+// Run establishes an HTTPS server on the configured port (or listener) and exposes the validate, mutate, and convert paths
+// (`/validate`, `/mutate`, and `/convert` by default) for kubernetes validating, mutating, and conversion webhooks, respectively.
+// It will block until either closeChan is closed (in which case it returns nil),
 // or the server encounters an unrecoverable error (in which case it returns the error).
 func (w *WebhookServer) Run(closeChan <-chan struct{}) error {
+	paths := w.paths.withDefaults()
 	mux := http.NewServeMux()
-	mux.HandleFunc("/validate", w.HandleValidateHTTP)
-	mux.HandleFunc("/mutate", w.HandleMutateHTTP)
-	mux.HandleFunc("/convert", w.HandleConvertHTTP)
+	mux.HandleFunc(paths.Validate, w.HandleValidateHTTP)
+	mux.HandleFunc(paths.Validate+"/", w.HandleValidateHTTP)
+	mux.HandleFunc(paths.Mutate, w.HandleMutateHTTP)
+	mux.HandleFunc(paths.Mutate+"/", w.HandleMutateHTTP)
+	mux.HandleFunc(paths.Convert, w.HandleConvertHTTP)
+	mux.HandleFunc(paths.Convert+"/", w.HandleConvertHTTP)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := w.certificates.start(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to load TLS certificate: %w", err)
+	}
 	server := &http.Server{
 		Addr:              fmt.Sprintf(":%d", w.port),
 		Handler:           mux,
 		ReadHeaderTimeout: 5 * time.Second,
+		TLSConfig: &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: w.certificates.GetCertificate,
+		},
 	}
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- server.ListenAndServeTLS(w.tlsConfig.CertPath, w.tlsConfig.KeyPath)
+		if w.listener != nil {
+			errCh <- server.ServeTLS(w.listener, "", "")
+			return
+		}
+		errCh <- server.ListenAndServeTLS("", "")
 	}()
 	go func() {
 		for range closeChan {
@@ -171,11 +318,30 @@ func (w *WebhookServer) Run(closeChan <-chan struct{}) error {
 		defer cancelFunc()
 		errCh <- server.Shutdown(ctx)
 	}()
-	err := <-errCh
+	err = <-errCh
 	return err
 }
 
-// HandleValidateHTTP is the HTTP HandlerFunc for a kubernetes validating webhook call
+// admissionContext returns the context for an admission controller call, which is canceled when the API server's
+// webhook timeout (sent as the "timeout" query parameter) is exceeded, as the API server stops waiting for a response then.
+// The deadline of the context is the server's deadline reserve earlier than the timeout, to leave time to write the response.
+// The context carries the resource.AdmissionContext for admReq.
+func (w *WebhookServer) admissionContext(req *http.Request, admReq *admission.AdmissionRequest) (context.Context, context.CancelFunc) {
+	timeout, err := time.ParseDuration(req.URL.Query().Get("timeout"))
+	if err != nil {
+		timeout = 0
+	}
+	ctx, cancelTimeout := resource.WithOperationTimeout(resource.WithAdmissionContext(req.Context(), translateKubernetesAdmissionContext(admReq)), timeout)
+	ctx, cancelBudget := resource.WithDeadlineBudget(ctx, w.deadlineReserve)
+	return ctx, func() {
+		cancelBudget()
+		cancelTimeout()
+	}
+}
+
+// HandleValidateHTTP is the HTTP HandlerFunc for a kubernetes validating webhook call.
+// Requests to a per-kind path (see WebhookPaths.ValidatePath) use the controller for that kind version,
+// and other requests use the controller for the kind of the request.
 // nolint:errcheck,revive,funlen
 func (w *WebhookServer) HandleValidateHTTP(writer http.ResponseWriter, req *http.Request) {
 	// Only POST is allowed
@@ -197,6 +363,7 @@ func (w *WebhookServer) HandleValidateHTTP(writer http.ResponseWriter, req *http
 	// Unmarshal the admission review
 	admRev, err := unmarshalKubernetesAdmissionReview(body, resource.WireFormatJSON)
 	if err != nil {
+		w.recordAdmissionError("validate", "")
 		writer.WriteHeader(http.StatusBadRequest)
 		logging.FromContext(req.Context()).Error("Couldn't unmarshal", "error", err)
 		return
@@ -205,7 +372,7 @@ func (w *WebhookServer) HandleValidateHTTP(writer http.ResponseWriter, req *http
 	// Look up the schema and controller
 	var schema resource.Kind
 	var controller resource.ValidatingAdmissionController
-	if tpl, ok := w.validatingControllers[gvk(admRev.Request.RequestKind)]; ok {
+	if tpl, ok := w.validatingControllers[w.controllerKey(req.URL.Path, w.paths.withDefaults().Validate, w.validatingRoutes, admRev.Request)]; ok {
 		schema = tpl.schema
 		controller = tpl.controller
 	} else if w.DefaultValidatingController != nil {
@@ -217,6 +384,7 @@ func (w *WebhookServer) HandleValidateHTTP(writer http.ResponseWriter, req *http
 
 	// If we didn't get a controller, return a failure
 	if controller == nil {
+		w.recordAdmissionError("validate", admRev.Request.RequestKind.Kind)
 		writer.WriteHeader(http.StatusInternalServerError)
 		writer.Write([]byte(fmt.Sprintf(errStringNoAdmissionControllerDefined, "validating", admRev.Request.RequestKind.Group, admRev.Request.RequestKind.Kind)))
 		logging.FromContext(req.Context()).Error("No controller", "error", err)
@@ -226,6 +394,11 @@ func (w *WebhookServer) HandleValidateHTTP(writer http.ResponseWriter, req *http
 	// Translate the kubernetes admission request to one with a resource.Object in it, using the schema
 	admReq, err := translateKubernetesAdmissionRequest(admRev.Request, schema)
 	if err != nil {
+		w.recordAdmissionError("validate", admRev.Request.RequestKind.Kind)
+		if resource.IsStrictDecodingError(err) {
+			writeStrictDecodingDenial(writer, admRev, err)
+			return
+		}
 		// TODO: different error?
 		writer.WriteHeader(http.StatusBadRequest)
 		logging.FromContext(req.Context()).Error("Couldn't translate request", "error", err)
@@ -233,7 +406,9 @@ func (w *WebhookServer) HandleValidateHTTP(writer http.ResponseWriter, req *http
 	}
 
 	// Run the controller
-	err = controller.Validate(req.Context(), admReq)
+	ctx, cancel := w.admissionContext(req, admRev.Request)
+	defer cancel()
+	err = controller.Validate(ctx, admReq)
 	adResp := admission.AdmissionResponse{
 		UID:     admRev.Request.UID,
 		Allowed: true,
@@ -241,12 +416,14 @@ func (w *WebhookServer) HandleValidateHTTP(writer http.ResponseWriter, req *http
 	if err != nil {
 		addAdmissionError(&adResp, err)
 	}
+	w.recordAdmission("validate", admRev.Request, adResp.Allowed)
 	bytes, err := json.Marshal(&admission.AdmissionReview{
 		TypeMeta: admRev.TypeMeta,
 		Response: &adResp,
 	})
 	if err != nil {
 		// Bad news
+		w.recordAdmissionError("validate", admRev.Request.RequestKind.Kind)
 		writer.WriteHeader(http.StatusInternalServerError)
 		writer.Write([]byte(err.Error())) // TODO: better
 		return
@@ -255,7 +432,9 @@ func (w *WebhookServer) HandleValidateHTTP(writer http.ResponseWriter, req *http
 	writer.Write(bytes)
 }
 
-// HandleMutateHTTP is the HTTP HandlerFunc for a kubernetes mutating webhook call
+// HandleMutateHTTP is the HTTP HandlerFunc for a kubernetes mutating webhook call.
+// Requests to a per-kind path (see WebhookPaths.MutatePath) use the controller for that kind version,
+// and other requests use the controller for the kind of the request.
 // nolint:errcheck,revive,funlen
 func (w *WebhookServer) HandleMutateHTTP(writer http.ResponseWriter, req *http.Request) {
 	// Only POST is allowed
@@ -275,6 +454,7 @@ func (w *WebhookServer) HandleMutateHTTP(writer http.ResponseWriter, req *http.R
 	// Unmarshal the admission review
 	admRev, err := unmarshalKubernetesAdmissionReview(body, resource.WireFormatJSON)
 	if err != nil {
+		w.recordAdmissionError("mutate", "")
 		writer.WriteHeader(http.StatusBadRequest)
 		return
 	}
@@ -282,7 +462,7 @@ func (w *WebhookServer) HandleMutateHTTP(writer http.ResponseWriter, req *http.R
 	// Look up the schema and controller
 	var schema resource.Kind
 	var controller resource.MutatingAdmissionController
-	if tpl, ok := w.mutatingControllers[gvk(admRev.Request.RequestKind)]; ok {
+	if tpl, ok := w.mutatingControllers[w.controllerKey(req.URL.Path, w.paths.withDefaults().Mutate, w.mutatingRoutes, admRev.Request)]; ok {
 		schema = tpl.schema
 		controller = tpl.controller
 	} else if w.DefaultMutatingController != nil {
@@ -294,6 +474,7 @@ func (w *WebhookServer) HandleMutateHTTP(writer http.ResponseWriter, req *http.R
 
 	// If we didn't get a controller, return a failure
 	if controller == nil {
+		w.recordAdmissionError("mutate", admRev.Request.RequestKind.Kind)
 		writer.WriteHeader(http.StatusInternalServerError)
 		writer.Write([]byte(fmt.Sprintf(errStringNoAdmissionControllerDefined, "mutating", admRev.Request.RequestKind.Group, admRev.Request.RequestKind.Kind)))
 		return
@@ -302,13 +483,20 @@ func (w *WebhookServer) HandleMutateHTTP(writer http.ResponseWriter, req *http.R
 	// Translate the kubernetes admission request to one with a resource.Object in it, using the schema
 	admReq, err := translateKubernetesAdmissionRequest(admRev.Request, schema)
 	if err != nil {
+		w.recordAdmissionError("mutate", admRev.Request.RequestKind.Kind)
+		if resource.IsStrictDecodingError(err) {
+			writeStrictDecodingDenial(writer, admRev, err)
+			return
+		}
 		// TODO: different error?
 		writer.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
 	// Run the controller
-	mResp, err := controller.Mutate(req.Context(), admReq)
+	ctx, cancel := w.admissionContext(req, admRev.Request)
+	defer cancel()
+	mResp, err := controller.Mutate(ctx, admReq)
 	adResp := admission.AdmissionResponse{
 		UID:     admRev.Request.UID,
 		Allowed: true,
@@ -322,12 +510,14 @@ func (w *WebhookServer) HandleMutateHTTP(writer http.ResponseWriter, req *http.R
 	if err != nil {
 		addAdmissionError(&adResp, err)
 	}
+	w.recordAdmission("mutate", admRev.Request, adResp.Allowed)
 	bytes, err := json.Marshal(&admission.AdmissionReview{
 		TypeMeta: admRev.TypeMeta,
 		Response: &adResp,
 	})
 	if err != nil {
 		// Bad news
+		w.recordAdmissionError("mutate", admRev.Request.RequestKind.Kind)
 		writer.WriteHeader(http.StatusInternalServerError)
 		writer.Write([]byte(err.Error())) // TODO: better
 		return
@@ -372,6 +562,9 @@ func (w *WebhookServer) HandleConvertHTTP(writer http.ResponseWriter, req *http.
 	rev.Response.Result.Code = http.StatusOK
 	rev.Response.Result.Status = metav1.StatusSuccess
 
+	ctx := resource.WithAdmissionContext(req.Context(), resource.AdmissionContext{
+		RequestUID: string(rev.Request.UID),
+	})
 	// Go through each object in the request
 	for _, obj := range rev.Request.Objects {
 		// Partly unmarshal to find the kind and APIVersion
@@ -396,13 +589,19 @@ func (w *WebhookServer) HandleConvertHTTP(writer http.ResponseWriter, req *http.
 		}
 		// Do the conversion
 		// Partial unmarshal to get kind and APIVersion
-		res, err := conv.Convert(RawKind{
+		raw := RawKind{
 			Kind:       tm.Kind,
 			APIVersion: tm.APIVersion,
 			Group:      tm.GroupVersionKind().Group,
 			Version:    tm.GroupVersionKind().Version,
 			Raw:        obj.Raw,
-		}, rev.Request.DesiredAPIVersion)
+		}
+		var res []byte
+		if cc, ok := conv.(ContextConverter); ok {
+			res, err = cc.ConvertWithContext(ctx, raw, rev.Request.DesiredAPIVersion)
+		} else {
+			res, err = conv.Convert(raw, rev.Request.DesiredAPIVersion)
+		}
 		if err != nil {
 			// Conversion error
 			rev.Response.Result.Status = metav1.StatusFailure
@@ -439,6 +638,20 @@ func (*WebhookServer) generatePatch(admRev *admission.AdmissionReview, alteredOb
 	return json.Marshal(patch)
 }
 
+func (w *WebhookServer) recordAdmission(webhook string, req *admission.AdmissionRequest, allowed bool) {
+	if w.admissionRequests == nil {
+		return
+	}
+	w.admissionRequests.WithLabelValues(webhook, req.RequestKind.Kind, string(req.Operation), strconv.FormatBool(allowed)).Inc()
+}
+
+func (w *WebhookServer) recordAdmissionError(webhook string, kind string) {
+	if w.admissionErrors == nil {
+		return
+	}
+	w.admissionErrors.WithLabelValues(webhook, kind).Inc()
+}
+
 type validatingAdmissionControllerTuple struct {
 	schema     resource.Kind
 	controller resource.ValidatingAdmissionController
@@ -449,6 +662,20 @@ type mutatingAdmissionControllerTuple struct {
 	controller resource.MutatingAdmissionController
 }
 
+// controllerKey returns the key of the admission controller for an admission request received on path.
+// If path is a per-kind path under base, the key is the one routed to by the path, otherwise it's the request's kind.
+func (*WebhookServer) controllerKey(path, base string, routes map[string]string, req *admission.AdmissionRequest) string {
+	if route, ok := strings.CutPrefix(path, base+"/"); ok {
+		return routes[route]
+	}
+	return gvk(req.RequestKind)
+}
+
+// kindRoute returns the part of the per-kind webhook paths for kind which follows the base path
+func kindRoute(kind resource.Kind) string {
+	return fmt.Sprintf("%s/%s/%s", kind.Group(), kind.Version(), kind.Plural())
+}
+
 func gk(group, kind string) string {
 	return fmt.Sprintf("%s.%s", kind, group)
 }
@@ -457,6 +684,33 @@ func gvk(kind *metav1.GroupVersionKind) string {
 	return kind.String()
 }
 
+// writeStrictDecodingDenial writes an AdmissionReview which denies the request of admRev, because its object has fields
+// which are unknown to a kind with strict decoding (see resource.StrictDecodingKind). Denying the request,
+// rather than failing the webhook call, returns the unknown fields to the user who made the request.
+// nolint:errcheck
+func writeStrictDecodingDenial(writer http.ResponseWriter, admRev *admission.AdmissionReview, err error) {
+	bytes, marshalErr := json.Marshal(&admission.AdmissionReview{
+		TypeMeta: admRev.TypeMeta,
+		Response: &admission.AdmissionResponse{
+			UID:     admRev.Request.UID,
+			Allowed: false,
+			Result: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: err.Error(),
+				Reason:  metav1.StatusReasonInvalid,
+				Code:    http.StatusUnprocessableEntity,
+			},
+		},
+	})
+	if marshalErr != nil {
+		writer.WriteHeader(http.StatusInternalServerError)
+		writer.Write([]byte(marshalErr.Error()))
+		return
+	}
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(bytes)
+}
+
 //nolint:gosec
 func addAdmissionError(resp *admission.AdmissionResponse, err error) {
 	if err == nil || resp == nil {
@@ -3,14 +3,19 @@ package k8s
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-app-sdk/resource"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	admission "k8s.io/api/admission/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -56,6 +61,21 @@ func TestNewWebhookServer(t *testing.T) {
 		}, srv.tlsConfig)
 	})
 
+	t.Run("listener instead of port", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.Nil(t, err)
+		defer listener.Close()
+		srv, err := NewWebhookServer(WebhookServerConfig{
+			Listener: listener,
+			TLSConfig: TLSConfig{
+				CertPath: "foo",
+				KeyPath:  "bar",
+			},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, listener, srv.listener)
+	})
+
 	t.Run("set controllers", func(t *testing.T) {
 		defVal := &testValidatingAdmissionController{}
 		defMut := &testMutatingAdmissionController{}
@@ -477,6 +497,8 @@ func TestWebhookServer_HandleValidateHTTP(t *testing.T) {
 
 			if test.expectedStatusCode == http.StatusOK {
 				assert.JSONEq(t, string(test.expectedResponse), resp.Body.String())
+				// Each handled admission request is recorded in the admission requests metric
+				assert.Equal(t, 1, testutil.CollectAndCount(srv.admissionRequests))
 			} else {
 				assert.Equal(t, test.expectedResponse, resp.Body.Bytes())
 			}
@@ -485,6 +507,315 @@ func TestWebhookServer_HandleValidateHTTP(t *testing.T) {
 	}
 }
 
+func TestWebhookServer_AdmissionContext(t *testing.T) {
+	kind := resource.Kind{
+		Schema: resource.NewSimpleSchema("foo", "v1", &TestResourceObject{}, &TestResourceObjectList{}, resource.WithKind("bar")),
+		Codecs: map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()},
+	}
+	payload := []byte(`{
+	"request": {
+		"uid": "foo",
+		"requestKind": {
+			"group": "foo",
+			"version": "v1",
+			"kind": "bar"
+		},
+		"subResource": "status",
+		"operation": "UPDATE",
+		"userInfo": {
+			"username": "user",
+			"uid": "1",
+			"groups": ["admins"],
+			"extra": {"scopes": ["a", "b"]}
+		},
+		"object": ` + admissionRequestObjectBytes.String() + `,
+		"oldObject": ` + admissionRequestObjectBytes.String() + `,
+		"dryRun": true,
+		"options": {
+			"kind": "PatchOptions",
+			"apiVersion": "meta.k8s.io/v1",
+			"fieldManager": "my-manager",
+			"force": true
+		}
+	}
+}`)
+	expected := resource.AdmissionContext{
+		RequestUID: "foo",
+		Action:     resource.AdmissionActionUpdate,
+		UserInfo: resource.AdmissionUserInfo{
+			Username: "user",
+			UID:      "1",
+			Groups:   []string{"admins"},
+			Extra:    map[string]any{"scopes": []string{"a", "b"}},
+		},
+		DryRun:      true,
+		SubResource: "status",
+		Options: resource.AdmissionOptions{
+			FieldManager: "my-manager",
+			Force:        true,
+		},
+	}
+
+	t.Run("validate", func(t *testing.T) {
+		var admCtx resource.AdmissionContext
+		srv, err := NewWebhookServer(WebhookServerConfig{
+			Port:      8443,
+			TLSConfig: TLSConfig{CertPath: "foo", KeyPath: "bar"},
+			ValidatingControllers: map[*resource.Kind]resource.ValidatingAdmissionController{
+				&kind: &testValidatingAdmissionController{
+					ValidateFunc: func(ctx context.Context, request *resource.AdmissionRequest) error {
+						var ok bool
+						admCtx, ok = resource.AdmissionContextFromContext(ctx)
+						assert.True(t, ok)
+						assert.Equal(t, admCtx.UserInfo, request.UserInfo)
+						return nil
+					},
+				},
+			},
+		})
+		require.Nil(t, err)
+		resp := httptest.NewRecorder()
+		srv.HandleValidateHTTP(resp, httptest.NewRequest(http.MethodPost, "http://localhost/validate", bytes.NewBuffer(payload)))
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, expected, admCtx)
+	})
+
+	t.Run("mutate", func(t *testing.T) {
+		var admCtx resource.AdmissionContext
+		srv, err := NewWebhookServer(WebhookServerConfig{
+			Port:      8443,
+			TLSConfig: TLSConfig{CertPath: "foo", KeyPath: "bar"},
+			MutatingControllers: map[*resource.Kind]resource.MutatingAdmissionController{
+				&kind: &testMutatingAdmissionController{
+					MutateFunc: func(ctx context.Context, request *resource.AdmissionRequest) (*resource.MutatingResponse, error) {
+						admCtx, _ = resource.AdmissionContextFromContext(ctx)
+						return nil, nil
+					},
+				},
+			},
+		})
+		require.Nil(t, err)
+		resp := httptest.NewRecorder()
+		srv.HandleMutateHTTP(resp, httptest.NewRequest(http.MethodPost, "http://localhost/mutate", bytes.NewBuffer(payload)))
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, expected, admCtx)
+	})
+
+	t.Run("convert", func(t *testing.T) {
+		var admCtx resource.AdmissionContext
+		srv, err := NewWebhookServer(WebhookServerConfig{
+			Port:      8443,
+			TLSConfig: TLSConfig{CertPath: "foo", KeyPath: "bar"},
+		})
+		require.Nil(t, err)
+		srv.AddConverter(&testContextConverter{
+			convertFunc: func(ctx context.Context, obj RawKind, _ string) ([]byte, error) {
+				admCtx, _ = resource.AdmissionContextFromContext(ctx)
+				return obj.Raw, nil
+			},
+		}, metav1.GroupKind{Group: "foo", Kind: "Test"})
+		review := []byte(`{"request":{"uid":"bar","desiredAPIVersion":"foo/v2","objects":[{"kind":"Test","apiVersion":"foo/v1","metadata":{}}]}}`)
+		resp := httptest.NewRecorder()
+		srv.HandleConvertHTTP(resp, httptest.NewRequest(http.MethodPost, "http://localhost/convert", bytes.NewBuffer(review)))
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, resource.AdmissionContext{RequestUID: "bar"}, admCtx)
+	})
+}
+
+func TestWebhookServer_DeadlineReserve(t *testing.T) {
+	kind := resource.Kind{
+		Schema: resource.NewSimpleSchema("foo", "v1", &TestResourceObject{}, &TestResourceObjectList{}, resource.WithKind("bar")),
+		Codecs: map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()},
+	}
+	var remaining time.Duration
+	var hasDeadline bool
+	srv, err := NewWebhookServer(WebhookServerConfig{
+		Port:            8443,
+		TLSConfig:       TLSConfig{CertPath: "foo", KeyPath: "bar"},
+		DeadlineReserve: 2 * time.Second,
+		ValidatingControllers: map[*resource.Kind]resource.ValidatingAdmissionController{
+			&kind: &testValidatingAdmissionController{
+				ValidateFunc: func(ctx context.Context, _ *resource.AdmissionRequest) error {
+					remaining, hasDeadline = resource.RemainingBudget(ctx)
+					return nil
+				},
+			},
+		},
+	})
+	require.Nil(t, err)
+
+	// The controller gets the API server's timeout, less the reserve
+	resp := httptest.NewRecorder()
+	srv.HandleValidateHTTP(resp, httptest.NewRequest(http.MethodPost, "http://localhost/validate?timeout=10s", bytes.NewBuffer(admissionRequestBytes)))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.True(t, hasDeadline)
+	assert.InDelta(t, 8*time.Second, remaining, float64(time.Second))
+
+	// Without a timeout, there is no deadline to reserve time from
+	resp = httptest.NewRecorder()
+	srv.HandleValidateHTTP(resp, httptest.NewRequest(http.MethodPost, "http://localhost/validate", bytes.NewBuffer(admissionRequestBytes)))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.False(t, hasDeadline)
+}
+
+func TestWebhookServer_StrictDecoding(t *testing.T) {
+	kind := resource.StrictDecodingKind(resource.Kind{
+		Schema: resource.NewSimpleSchema("foo", "v1", &TestResourceObject{}, &TestResourceObjectList{}, resource.WithKind("bar")),
+		Codecs: map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()},
+	})
+	called := false
+	srv, err := NewWebhookServer(WebhookServerConfig{
+		Port:      8443,
+		TLSConfig: TLSConfig{CertPath: "foo", KeyPath: "bar"},
+		ValidatingControllers: map[*resource.Kind]resource.ValidatingAdmissionController{
+			&kind: &testValidatingAdmissionController{
+				ValidateFunc: func(context.Context, *resource.AdmissionRequest) error {
+					called = true
+					return nil
+				},
+			},
+		},
+		MutatingControllers: map[*resource.Kind]resource.MutatingAdmissionController{
+			&kind: &testMutatingAdmissionController{
+				MutateFunc: func(context.Context, *resource.AdmissionRequest) (*resource.MutatingResponse, error) {
+					called = true
+					return nil, nil
+				},
+			},
+		},
+	})
+	require.Nil(t, err)
+	payload := []byte(`{"request":{"uid":"foo","requestKind":{"group":"foo","version":"v1","kind":"bar"},"operation":"CREATE",` +
+		`"object":{"apiVersion":"foo/v1","kind":"bar","metadata":{"name":"a"},"spec":{"unknownField":"value"}}}}`)
+
+	for path, handler := range map[string]http.HandlerFunc{"validate": srv.HandleValidateHTTP, "mutate": srv.HandleMutateHTTP} {
+		t.Run(path, func(t *testing.T) {
+			called = false
+			resp := httptest.NewRecorder()
+			handler(resp, httptest.NewRequest(http.MethodPost, "http://localhost/"+path, bytes.NewBuffer(payload)))
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.False(t, called)
+			rev := admission.AdmissionReview{}
+			require.Nil(t, json.Unmarshal(resp.Body.Bytes(), &rev))
+			require.NotNil(t, rev.Response)
+			assert.False(t, rev.Response.Allowed)
+			assert.Equal(t, int32(http.StatusUnprocessableEntity), rev.Response.Result.Code)
+			assert.Equal(t, `strict decoding error: unknown field "spec.unknownField"`, rev.Response.Result.Message)
+		})
+	}
+
+	// A stored object with unknown fields can be updated to remove them, and deleted
+	drifted := `{"apiVersion":"foo/v1","kind":"bar","metadata":{"name":"a"},"spec":{"unknownField":"value"}}`
+	lenientPayloads := map[string][]byte{
+		"update": []byte(`{"request":{"uid":"foo","requestKind":{"group":"foo","version":"v1","kind":"bar"},"operation":"UPDATE",` +
+			`"object":{"apiVersion":"foo/v1","kind":"bar","metadata":{"name":"a"},"spec":{}},"oldObject":` + drifted + `}}`),
+		"delete": []byte(`{"request":{"uid":"foo","requestKind":{"group":"foo","version":"v1","kind":"bar"},"operation":"DELETE",` +
+			`"object":` + drifted + `,"oldObject":` + drifted + `}}`),
+	}
+	for name, payload := range lenientPayloads {
+		t.Run(name, func(t *testing.T) {
+			called = false
+			resp := httptest.NewRecorder()
+			srv.HandleValidateHTTP(resp, httptest.NewRequest(http.MethodPost, "http://localhost/validate", bytes.NewBuffer(payload)))
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.True(t, called)
+			rev := admission.AdmissionReview{}
+			require.Nil(t, json.Unmarshal(resp.Body.Bytes(), &rev))
+			require.NotNil(t, rev.Response)
+			assert.True(t, rev.Response.Allowed)
+		})
+	}
+}
+
+func TestWebhookPaths(t *testing.T) {
+	assert.Equal(t, "/validate/foo/v1/bars", WebhookPaths{}.ValidatePath("foo", "v1", "bars"))
+	assert.Equal(t, "/mutate/foo/v1/bars", WebhookPaths{}.MutatePath("foo", "v1", "bars"))
+	assert.Equal(t, "/convert/foo/bars", WebhookPaths{}.ConvertPath("foo", "bars"))
+	paths := WebhookPaths{
+		Validate: "/webhooks/validate/",
+		Mutate:   "/webhooks/mutate",
+	}
+	assert.Equal(t, "/webhooks/validate/foo/v1/bars", paths.ValidatePath("foo", "v1", "bars"))
+	assert.Equal(t, "/webhooks/mutate/foo/v1/bars", paths.MutatePath("foo", "v1", "bars"))
+	assert.Equal(t, "/convert/foo/bars", paths.ConvertPath("foo", "bars"))
+}
+
+func TestWebhookServer_KindRoutes(t *testing.T) {
+	kind := resource.Kind{
+		Schema: resource.NewSimpleSchema("foo", "v1", &TestResourceObject{}, &TestResourceObjectList{}, resource.WithKind("bar"), resource.WithPlural("bars")),
+		Codecs: map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()},
+	}
+	srv, err := NewWebhookServer(WebhookServerConfig{
+		Port:      8443,
+		TLSConfig: TLSConfig{CertPath: "foo", KeyPath: "bar"},
+		Paths:     WebhookPaths{Validate: "/admission/validate"},
+	})
+	require.Nil(t, err)
+	srv.AddValidatingAdmissionController(&testValidatingAdmissionController{
+		ValidateFunc: func(context.Context, *resource.AdmissionRequest) error {
+			return nil
+		},
+	}, kind)
+	srv.AddMutatingAdmissionController(&testMutatingAdmissionController{
+		MutateFunc: func(context.Context, *resource.AdmissionRequest) (*resource.MutatingResponse, error) {
+			return nil, nil
+		},
+	}, kind)
+
+	tests := []struct {
+		name               string
+		path               string
+		handler            http.HandlerFunc
+		expectedStatusCode int
+	}{{
+		name:               "validate kind route",
+		path:               srv.paths.ValidatePath("foo", "v1", "bars"),
+		handler:            srv.HandleValidateHTTP,
+		expectedStatusCode: http.StatusOK,
+	}, {
+		name:               "validate base path",
+		path:               "/admission/validate",
+		handler:            srv.HandleValidateHTTP,
+		expectedStatusCode: http.StatusOK,
+	}, {
+		name:               "validate unknown kind route",
+		path:               srv.paths.ValidatePath("foo", "v2", "bars"),
+		handler:            srv.HandleValidateHTTP,
+		expectedStatusCode: http.StatusInternalServerError,
+	}, {
+		name:               "mutate kind route",
+		path:               "/mutate/foo/v1/bars",
+		handler:            srv.HandleMutateHTTP,
+		expectedStatusCode: http.StatusOK,
+	}, {
+		name:               "mutate unknown kind route",
+		path:               "/mutate/bar/v1/foos",
+		handler:            srv.HandleMutateHTTP,
+		expectedStatusCode: http.StatusInternalServerError,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://localhost"+test.path, bytes.NewBuffer(admissionRequestBytes))
+			resp := httptest.NewRecorder()
+			test.handler(resp, req)
+			assert.Equal(t, test.expectedStatusCode, resp.Code)
+		})
+	}
+}
+
+type testContextConverter struct {
+	convertFunc func(context.Context, RawKind, string) ([]byte, error)
+}
+
+func (c *testContextConverter) Convert(obj RawKind, targetAPIVersion string) ([]byte, error) {
+	return c.ConvertWithContext(context.Background(), obj, targetAPIVersion)
+}
+
+func (c *testContextConverter) ConvertWithContext(ctx context.Context, obj RawKind, targetAPIVersion string) ([]byte, error) {
+	return c.convertFunc(ctx, obj, targetAPIVersion)
+}
+
 type testValidatingAdmissionController struct {
 	ValidateFunc func(context.Context, *resource.AdmissionRequest) error
 }
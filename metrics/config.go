@@ -1,12 +1,24 @@
 package metrics
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"crypto/tls"
+	"net"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 // ExporterConfig is the configuration used for the Exporter
 type ExporterConfig struct {
 	Registerer prometheus.Registerer
 	Gatherer   prometheus.Gatherer
 	Port       int
+	// Listener, if non-nil, is the listener the Exporter's HTTP server accepts connections on, instead of listening on Port.
+	// This can be used to serve metrics on a Unix socket, or on a socket inherited from the parent process.
+	// The Exporter closes the Listener when it stops.
+	Listener net.Listener
+	// TLSConfig, if non-nil, makes the Exporter serve HTTPS with it instead of HTTP.
+	// Use k8s.CertificateWatcher.TLSConfig to serve a certificate which is reloaded when it changes.
+	TLSConfig *tls.Config
 }
 
 // Config is the general set of configuration options for creating prometheus Collectors
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ExemplarTraceIDLabel is the exemplar label which contains the trace ID of the span an observation was made in.
+const ExemplarTraceIDLabel = "traceID"
+
+// ObserveWithTraceExemplar observes value with the provided observer. If ctx contains a sampled OpenTelemetry span,
+// and the observer supports exemplars, the span's trace ID is attached to the observation as an exemplar,
+// allowing a latency observation to be linked to a representative trace.
+func ObserveWithTraceExemplar(ctx context.Context, observer prometheus.Observer, value float64) {
+	if eo, ok := observer.(prometheus.ExemplarObserver); ok {
+		if labels := TraceExemplarLabels(ctx); labels != nil {
+			eo.ObserveWithExemplar(value, labels)
+			return
+		}
+	}
+	observer.Observe(value)
+}
+
+// IncWithTraceExemplar increments the provided counter. If ctx contains a sampled OpenTelemetry span,
+// and the counter supports exemplars, the span's trace ID is attached to the increment as an exemplar.
+func IncWithTraceExemplar(ctx context.Context, counter prometheus.Counter) {
+	if ea, ok := counter.(prometheus.ExemplarAdder); ok {
+		if labels := TraceExemplarLabels(ctx); labels != nil {
+			ea.AddWithExemplar(1, labels)
+			return
+		}
+	}
+	counter.Inc()
+}
+
+// TraceExemplarLabels returns the exemplar labels for the span contained in ctx,
+// or nil if ctx has no span, or the span is not sampled.
+func TraceExemplarLabels(ctx context.Context) prometheus.Labels {
+	if ctx == nil {
+		return nil
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() || !sc.IsSampled() {
+		return nil
+	}
+	return prometheus.Labels{
+		ExemplarTraceIDLabel: sc.TraceID().String(),
+	}
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestObserveWithTraceExemplar(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+
+	t.Run("no span", func(t *testing.T) {
+		h := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test", Buckets: []float64{1}})
+		ObserveWithTraceExemplar(context.Background(), h, 0.5)
+		m := writeMetric(t, h)
+		assert.Equal(t, uint64(1), m.GetHistogram().GetSampleCount())
+		assert.Nil(t, m.GetHistogram().GetBucket()[0].GetExemplar())
+	})
+
+	t.Run("unsampled span", func(t *testing.T) {
+		h := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test", Buckets: []float64{1}})
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  spanID,
+		}))
+		ObserveWithTraceExemplar(ctx, h, 0.5)
+		m := writeMetric(t, h)
+		assert.Equal(t, uint64(1), m.GetHistogram().GetSampleCount())
+		assert.Nil(t, m.GetHistogram().GetBucket()[0].GetExemplar())
+	})
+
+	t.Run("sampled span", func(t *testing.T) {
+		h := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test", Buckets: []float64{1}})
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+		}))
+		ObserveWithTraceExemplar(ctx, h, 0.5)
+		m := writeMetric(t, h)
+		assert.Equal(t, uint64(1), m.GetHistogram().GetSampleCount())
+		ex := m.GetHistogram().GetBucket()[0].GetExemplar()
+		require.NotNil(t, ex)
+		require.Len(t, ex.GetLabel(), 1)
+		assert.Equal(t, ExemplarTraceIDLabel, ex.GetLabel()[0].GetName())
+		assert.Equal(t, traceID.String(), ex.GetLabel()[0].GetValue())
+	})
+}
+
+func TestIncWithTraceExemplar(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	c := prometheus.NewCounter(prometheus.CounterOpts{Name: "test"})
+	IncWithTraceExemplar(ctx, c)
+	m := writeMetric(t, c)
+	assert.Equal(t, float64(1), m.GetCounter().GetValue())
+	require.NotNil(t, m.GetCounter().GetExemplar())
+	assert.Equal(t, traceID.String(), m.GetCounter().GetExemplar().GetLabel()[0].GetValue())
+}
+
+func writeMetric(t *testing.T, m prometheus.Metric) *dto.Metric {
+	out := &dto.Metric{}
+	require.Nil(t, m.Write(out))
+	return out
+}
@@ -2,7 +2,9 @@ package metrics
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
@@ -33,6 +35,8 @@ func NewExporter(cfg ExporterConfig) *Exporter {
 		Registerer: cfg.Registerer,
 		Gatherer:   cfg.Gatherer,
 		Port:       cfg.Port,
+		Listener:   cfg.Listener,
+		TLSConfig:  cfg.TLSConfig,
 	}
 }
 
@@ -46,6 +50,27 @@ type Exporter struct {
 	Registerer prometheus.Registerer
 	Gatherer   prometheus.Gatherer
 	Port       int
+	// Listener, if non-nil, is used by Run instead of listening on Port
+	Listener net.Listener
+	// TLSConfig, if non-nil, is used by Run to serve HTTPS instead of HTTP
+	TLSConfig *tls.Config
+	handlers  map[string]http.Handler
+}
+
+// Handle registers an additional handler for the pattern on the Exporter's HTTP server, such as a /version endpoint.
+// Handlers must be registered before Run is called, and the pattern cannot be /metrics.
+func (e *Exporter) Handle(pattern string, handler http.Handler) error {
+	if pattern == "/metrics" {
+		return fmt.Errorf("pattern %s is reserved for metrics", pattern)
+	}
+	if e.handlers == nil {
+		e.handlers = make(map[string]http.Handler)
+	}
+	if _, ok := e.handlers[pattern]; ok {
+		return fmt.Errorf("a handler for pattern %s already exists", pattern)
+	}
+	e.handlers[pattern] = handler
+	return nil
 }
 
 // RegisterCollectors registers the provided collectors with the Exporter's Registerer.
@@ -60,26 +85,53 @@ func (e *Exporter) RegisterCollectors(metrics ...prometheus.Collector) error {
 	return nil
 }
 
-// Run creates an HTTP server which exposes a /metrics endpoint on the configured port (if <=0, uses the default 9090)
+// Run creates an HTTP server which exposes a /metrics endpoint (and any handlers registered with Handle)
+// on the configured port (if <=0, uses the default 9090), or on Listener if it is non-nil.
+// If TLSConfig is non-nil, the server serves HTTPS.
 func (e *Exporter) Run(stopCh <-chan struct{}) error {
 	mux := http.NewServeMux()
+	// OpenMetrics must be enabled for exemplars (such as the trace IDs attached by ObserveWithTraceExemplar) to be exposed.
+	// Scrapers which don't request the OpenMetrics format will still receive the standard text format.
 	mux.Handle("/metrics", promhttp.InstrumentMetricHandler(
-		e.Registerer, promhttp.HandlerFor(e.Gatherer, promhttp.HandlerOpts{}),
+		e.Registerer, promhttp.HandlerFor(e.Gatherer, promhttp.HandlerOpts{
+			EnableOpenMetrics: true,
+		}),
 	))
+	for pattern, handler := range e.handlers {
+		mux.Handle(pattern, handler)
+	}
+	// Request contexts are canceled when the server stops, so that long-lived requests (such as streams) don't block shutdown
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
 	server := &http.Server{
 		Addr:              fmt.Sprintf(":%d", e.Port),
 		Handler:           mux,
 		ReadHeaderTimeout: 5 * time.Second,
+		TLSConfig:         e.TLSConfig,
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
 	}
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- server.ListenAndServe()
+		switch {
+		case e.Listener != nil && e.TLSConfig != nil:
+			// The certificate comes from TLSConfig, so no cert or key files are provided
+			errCh <- server.ServeTLS(e.Listener, "", "")
+		case e.Listener != nil:
+			errCh <- server.Serve(e.Listener)
+		case e.TLSConfig != nil:
+			errCh <- server.ListenAndServeTLS("", "")
+		default:
+			errCh <- server.ListenAndServe()
+		}
 	}()
 	go func() {
 		for range stopCh {
 			// do nothing until closeCh is closed or receives a message
 			break
 		}
+		cancelBase()
 		ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second)
 		defer cancelFunc()
 		errCh <- server.Shutdown(ctx)
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExporter_Handle(t *testing.T) {
+	e := NewExporter(ExporterConfig{})
+	handler := http.NotFoundHandler()
+	assert.Nil(t, e.Handle("/version", handler))
+	assert.EqualError(t, e.Handle("/version", handler), "a handler for pattern /version already exists")
+	assert.EqualError(t, e.Handle("/metrics", handler), "pattern /metrics is reserved for metrics")
+}
+
+func TestExporter_Run_Listener(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.sock")
+	listener, err := net.Listen("unix", path)
+	require.Nil(t, err)
+	e := NewExporter(ExporterConfig{
+		Registerer: prometheus.NewRegistry(),
+		Gatherer:   prometheus.NewRegistry(),
+		Listener:   listener,
+	})
+	stopCh := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- e.Run(stopCh)
+	}()
+
+	client := http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		},
+	}
+	resp, err := client.Get("http://unix/metrics")
+	require.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	close(stopCh)
+	// As with ListenAndServe, Run returns the error from Serve once the server is shut down
+	assert.ErrorIs(t, <-errCh, http.ErrServerClosed)
+}
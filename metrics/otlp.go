@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	otelprometheus "go.opentelemetry.io/contrib/bridges/prometheus"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	otelresource "go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// OTLPProtocol is the protocol an OTLPExporter uses to send metrics
+type OTLPProtocol string
+
+const (
+	OTLPProtocolGRPC = OTLPProtocol("grpc")
+	OTLPProtocolHTTP = OTLPProtocol("http")
+)
+
+const defaultOTLPTimeout = 10 * time.Second
+
+// OTLPExporterConfig is the configuration used for an OTLPExporter
+type OTLPExporterConfig struct {
+	// Endpoint is the host:port of the OTLP receiver, such as an OpenTelemetry collector
+	Endpoint string
+	// Protocol is the protocol used to send metrics. Defaults to OTLPProtocolGRPC.
+	Protocol OTLPProtocol
+	// Insecure, if true, sends metrics without TLS
+	Insecure bool
+	// ServiceName is the service.name resource attribute of the exported metrics
+	ServiceName string
+	// Headers are additional headers (or gRPC metadata) sent with each export, such as for authentication
+	Headers map[string]string
+	// Timeout is the maximum time a single export may take, including retries. Defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+// OTLPExporter is a PushExporter which converts gathered prometheus metrics to OpenTelemetry metrics
+// with the OpenTelemetry prometheus bridge, and sends them to an OTLP receiver with the OpenTelemetry
+// otlpmetricgrpc or otlpmetrichttp exporter. Counters are exported as cumulative monotonic sums,
+// gauges as gauges, histograms as explicit-bucket (or, for native histograms, exponential) histograms,
+// and summaries as summaries.
+type OTLPExporter struct {
+	config   OTLPExporterConfig
+	exporter sdkmetric.Exporter
+	resource *otelresource.Resource
+}
+
+// NewOTLPExporter creates a new OTLPExporter using the provided config.
+// For gRPC, the connection is established lazily, so NewOTLPExporter doesn't fail if the receiver is unavailable.
+// The exporter should be shut down with Shutdown once it is no longer used (a Pusher does this when it stops).
+func NewOTLPExporter(cfg OTLPExporterConfig) (*OTLPExporter, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("endpoint cannot be empty")
+	}
+	if cfg.Protocol == "" {
+		cfg.Protocol = OTLPProtocolGRPC
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultOTLPTimeout
+	}
+	var (
+		exporter sdkmetric.Exporter
+		err      error
+	)
+	switch cfg.Protocol {
+	case OTLPProtocolGRPC:
+		opts := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithEndpoint(cfg.Endpoint),
+			otlpmetricgrpc.WithHeaders(cfg.Headers),
+			otlpmetricgrpc.WithTimeout(cfg.Timeout),
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		exporter, err = otlpmetricgrpc.New(context.Background(), opts...)
+	case OTLPProtocolHTTP:
+		opts := []otlpmetrichttp.Option{
+			otlpmetrichttp.WithEndpoint(cfg.Endpoint),
+			otlpmetrichttp.WithHeaders(cfg.Headers),
+			otlpmetrichttp.WithTimeout(cfg.Timeout),
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		exporter, err = otlpmetrichttp.New(context.Background(), opts...)
+	default:
+		return nil, fmt.Errorf("unknown protocol '%s'", cfg.Protocol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter for %s: %w", cfg.Endpoint, err)
+	}
+	return &OTLPExporter{
+		config:   cfg,
+		exporter: exporter,
+		resource: otelresource.NewSchemaless(semconv.ServiceName(cfg.ServiceName)),
+	}, nil
+}
+
+// Export converts the families to OpenTelemetry metrics, and sends them to the OTLP receiver.
+// The export is canceled if it takes longer than the configured Timeout.
+func (o *OTLPExporter) Export(ctx context.Context, families []*dto.MetricFamily) error {
+	producer := otelprometheus.NewMetricProducer(otelprometheus.WithGatherer(prometheus.GathererFunc(
+		func() ([]*dto.MetricFamily, error) {
+			return families, nil
+		},
+	)))
+	scopeMetrics, err := producer.Produce(ctx)
+	if err != nil && len(scopeMetrics) == 0 {
+		return err
+	}
+	perBucketCounts(scopeMetrics)
+	ctx, cancel := context.WithTimeout(ctx, o.config.Timeout)
+	defer cancel()
+	// Produce returns the metrics it could convert along with an error for the rest, which are still worth exporting
+	return errors.Join(err, o.exporter.Export(ctx, &metricdata.ResourceMetrics{
+		Resource:     o.resource,
+		ScopeMetrics: scopeMetrics,
+	}))
+}
+
+// perBucketCounts converts the cumulative bucket counts of the histograms produced by the prometheus bridge
+// (which does not convert them until v0.60.0) to the per-bucket counts OTLP expects
+func perBucketCounts(scopeMetrics []metricdata.ScopeMetrics) {
+	for _, sm := range scopeMetrics {
+		for _, m := range sm.Metrics {
+			histogram, ok := m.Data.(metricdata.Histogram[float64])
+			if !ok {
+				continue
+			}
+			for _, point := range histogram.DataPoints {
+				for i := len(point.BucketCounts) - 1; i > 0; i-- {
+					point.BucketCounts[i] -= point.BucketCounts[i-1]
+				}
+			}
+		}
+	}
+}
+
+// Shutdown closes the exporter's connection to the OTLP receiver. The exporter cannot be used once it is shut down.
+func (o *OTLPExporter) Shutdown(ctx context.Context) error {
+	return o.exporter.Shutdown(ctx)
+}
+
+// Close shuts down the exporter (see Shutdown), waiting at most the configured Timeout
+func (o *OTLPExporter) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), o.config.Timeout)
+	defer cancel()
+	return o.Shutdown(ctx)
+}
@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNewOTLPExporter(t *testing.T) {
+	_, err := NewOTLPExporter(OTLPExporterConfig{})
+	assert.Equal(t, errors.New("endpoint cannot be empty"), err)
+	_, err = NewOTLPExporter(OTLPExporterConfig{Endpoint: "localhost:4317", Protocol: "foo"})
+	assert.EqualError(t, err, "unknown protocol 'foo'")
+	exporter, err := NewOTLPExporter(OTLPExporterConfig{Endpoint: "localhost:4317", Insecure: true})
+	require.Nil(t, err)
+	assert.Nil(t, exporter.Close())
+}
+
+func TestOTLPExporter_Export_HTTP(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total", Help: "Requests"}, []string{"code"})
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "queue_size"})
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "latency_seconds", Buckets: []float64{.1, 1}})
+	registry.MustRegister(counter, gauge, histogram)
+	counter.WithLabelValues("200").Add(3)
+	gauge.Set(7)
+	histogram.Observe(.05)
+	histogram.Observe(.5)
+	histogram.Observe(5)
+
+	received := make(chan *collectormetrics.ExportMetricsServiceRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/metrics", r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+		body, err := io.ReadAll(r.Body)
+		require.Nil(t, err)
+		req := &collectormetrics.ExportMetricsServiceRequest{}
+		require.Nil(t, proto.Unmarshal(body, req))
+		received <- req
+	}))
+	defer server.Close()
+
+	exporter, err := NewOTLPExporter(OTLPExporterConfig{
+		Endpoint:    strings.TrimPrefix(server.URL, "http://"),
+		Protocol:    OTLPProtocolHTTP,
+		Insecure:    true,
+		ServiceName: "test",
+		Headers:     map[string]string{"Authorization": "secret"},
+	})
+	require.Nil(t, err)
+	families, err := registry.Gather()
+	require.Nil(t, err)
+	require.Nil(t, exporter.Export(context.Background(), families))
+
+	req := <-received
+	require.Len(t, req.GetResourceMetrics(), 1)
+	assert.Equal(t, "service.name", req.GetResourceMetrics()[0].GetResource().GetAttributes()[0].GetKey())
+	assert.Equal(t, "test", req.GetResourceMetrics()[0].GetResource().GetAttributes()[0].GetValue().GetStringValue())
+	metrics := req.GetResourceMetrics()[0].GetScopeMetrics()[0].GetMetrics()
+	require.Len(t, metrics, 3)
+	// Families are gathered in name order
+	assert.Equal(t, "latency_seconds", metrics[0].GetName())
+	point := metrics[0].GetHistogram().GetDataPoints()[0]
+	assert.Equal(t, []float64{.1, 1}, point.GetExplicitBounds())
+	assert.Equal(t, []uint64{1, 1, 1}, point.GetBucketCounts())
+	assert.Equal(t, uint64(3), point.GetCount())
+	assert.Equal(t, "queue_size", metrics[1].GetName())
+	assert.Equal(t, float64(7), metrics[1].GetGauge().GetDataPoints()[0].GetAsDouble())
+	assert.Equal(t, "requests_total", metrics[2].GetName())
+	assert.Equal(t, "Requests", metrics[2].GetDescription())
+	assert.True(t, metrics[2].GetSum().GetIsMonotonic())
+	sum := metrics[2].GetSum().GetDataPoints()[0]
+	assert.Equal(t, float64(3), sum.GetAsDouble())
+	assert.Equal(t, "code", sum.GetAttributes()[0].GetKey())
+	assert.NotZero(t, sum.GetStartTimeUnixNano())
+
+	t.Run("error response", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("bad metrics"))
+		}))
+		defer failing.Close()
+		exporter, err := NewOTLPExporter(OTLPExporterConfig{
+			Endpoint: strings.TrimPrefix(failing.URL, "http://"),
+			Protocol: OTLPProtocolHTTP,
+			Insecure: true,
+		})
+		require.Nil(t, err)
+		err = exporter.Export(context.Background(), families)
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), "400")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		blocking := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			<-release
+		}))
+		defer blocking.Close()
+		defer close(release)
+		exporter, err := NewOTLPExporter(OTLPExporterConfig{
+			Endpoint: strings.TrimPrefix(blocking.URL, "http://"),
+			Protocol: OTLPProtocolHTTP,
+			Insecure: true,
+			Timeout:  50 * time.Millisecond,
+		})
+		require.Nil(t, err)
+		start := time.Now()
+		assert.NotNil(t, exporter.Export(context.Background(), families))
+		assert.Less(t, time.Since(start), 5*time.Second)
+		require.Nil(t, exporter.Shutdown(context.Background()))
+		// The exporter cannot be used once it is shut down
+		assert.NotNil(t, exporter.Export(context.Background(), families))
+	})
+}
@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+)
+
+const defaultPushInterval = 30 * time.Second
+
+// PushExporter exports metrics to an external system, such as an OpenTelemetry collector, when they are pushed by a Pusher.
+// It is the extension point for push-based metrics exports, as an alternative to scraping the Exporter's /metrics endpoint.
+// PushExporters which hold resources, such as connections, should also implement ShutdownPushExporter.
+type PushExporter interface {
+	// Export exports the gathered metric families
+	Export(ctx context.Context, families []*dto.MetricFamily) error
+}
+
+// ShutdownPushExporter is a PushExporter which must be shut down once it is no longer used.
+// A Pusher shuts down its exporter after its final push when it stops.
+type ShutdownPushExporter interface {
+	PushExporter
+	// Shutdown releases the exporter's resources. The exporter is not used again after it is shut down.
+	Shutdown(ctx context.Context) error
+}
+
+// PusherConfig is the configuration used for a Pusher
+type PusherConfig struct {
+	// Gatherer is the source of the pushed metrics. Defaults to prometheus.DefaultGatherer.
+	Gatherer prometheus.Gatherer
+	// Exporter is sent the gathered metrics
+	Exporter PushExporter
+	// Interval is how often metrics are pushed. Defaults to 30 seconds.
+	Interval time.Duration
+}
+
+// Pusher gathers metrics on an interval, and pushes them to a PushExporter.
+// Metrics are pushed once more when the Pusher stops, so the last values before shutdown are not lost,
+// and then the exporter is shut down if it is a ShutdownPushExporter.
+type Pusher struct {
+	gatherer prometheus.Gatherer
+	exporter PushExporter
+	interval time.Duration
+}
+
+// NewPusher creates a new Pusher using the provided config
+func NewPusher(cfg PusherConfig) (*Pusher, error) {
+	if cfg.Exporter == nil {
+		return nil, errors.New("exporter cannot be nil")
+	}
+	if cfg.Gatherer == nil {
+		cfg.Gatherer = prometheus.DefaultGatherer
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultPushInterval
+	}
+	return &Pusher{
+		gatherer: cfg.Gatherer,
+		exporter: cfg.Exporter,
+		interval: cfg.Interval,
+	}, nil
+}
+
+// Run pushes metrics every interval until ctx is canceled. Failed pushes are logged, and retried on the next interval.
+func (p *Pusher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// ctx is already canceled, so the final push needs its own deadline
+			pushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			defer cancel()
+			if err := p.Push(pushCtx); err != nil {
+				logging.FromContext(ctx).Error("unable to push metrics", "error", err)
+			}
+			if cast, ok := p.exporter.(ShutdownPushExporter); ok {
+				return cast.Shutdown(pushCtx)
+			}
+			return nil
+		case <-ticker.C:
+			if err := p.Push(ctx); err != nil {
+				logging.FromContext(ctx).Error("unable to push metrics", "error", err)
+			}
+		}
+	}
+}
+
+// Push gathers the current metrics and exports them
+func (p *Pusher) Push(ctx context.Context) error {
+	families, err := p.gatherer.Gather()
+	if err != nil && len(families) == 0 {
+		return err
+	}
+	// Gather returns the metrics it could gather along with an error for the rest, which are still worth pushing
+	if exportErr := p.exporter.Export(ctx, families); exportErr != nil {
+		return exportErr
+	}
+	return err
+}
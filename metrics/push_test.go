@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPusher(t *testing.T) {
+	_, err := NewPusher(PusherConfig{})
+	assert.Equal(t, errors.New("exporter cannot be nil"), err)
+}
+
+func TestPusher_Run(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_total"})
+	registry.MustRegister(counter)
+	exporter := &testPushExporter{}
+	pusher, err := NewPusher(PusherConfig{
+		Gatherer: registry,
+		Exporter: exporter,
+		Interval: 10 * time.Millisecond,
+	})
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- pusher.Run(ctx)
+	}()
+	assert.Eventually(t, func() bool {
+		return exporter.count() > 0
+	}, time.Second, 5*time.Millisecond)
+
+	// The latest values are pushed when the pusher stops
+	counter.Add(5)
+	cancel()
+	require.Nil(t, <-errCh)
+	last := exporter.last()
+	require.Len(t, last, 1)
+	assert.Equal(t, float64(5), last[0].GetMetric()[0].GetCounter().GetValue())
+	// And then the exporter is shut down
+	assert.True(t, exporter.shutdown)
+}
+
+type testPushExporter struct {
+	mux      sync.Mutex
+	pushes   [][]*dto.MetricFamily
+	shutdown bool
+}
+
+func (e *testPushExporter) Shutdown(context.Context) error {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.shutdown = true
+	return nil
+}
+
+func (e *testPushExporter) Export(_ context.Context, families []*dto.MetricFamily) error {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.pushes = append(e.pushes, families)
+	return nil
+}
+
+func (e *testPushExporter) count() int {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	return len(e.pushes)
+}
+
+func (e *testPushExporter) last() []*dto.MetricFamily {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	return e.pushes[len(e.pushes)-1]
+}
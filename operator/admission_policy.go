@@ -0,0 +1,502 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"google.golang.org/protobuf/types/known/structpb"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana-app-sdk/k8s"
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+const (
+	// AdmissionPolicyEngineCEL is the name of the built-in AdmissionPolicyEngine, which evaluates CEL expressions.
+	// It is used when an app.AdmissionPolicyReference does not specify an engine.
+	AdmissionPolicyEngineCEL = "cel"
+
+	// ErrReasonPolicyDenied is the admission error reason returned when a policy validation fails
+	ErrReasonPolicyDenied = "policy_denied"
+
+	defaultAdmissionPolicyTimeout        = time.Second
+	defaultAdmissionPolicyReloadInterval = 30 * time.Second
+	defaultAdmissionPolicyMaxBytes       = 1 << 20
+	defaultAdmissionPolicyMaxConcurrent  = 32
+
+	// celAdmissionPolicyCostLimit is the maximum cost of evaluating a single CEL expression,
+	// matching the per-expression limit of kubernetes' ValidatingAdmissionPolicy
+	celAdmissionPolicyCostLimit = 1000000
+	// celAdmissionPolicyInterruptCheckFrequency is the number of comprehension iterations between checks
+	// for the cancellation of the evaluation context
+	celAdmissionPolicyInterruptCheckFrequency = 100
+)
+
+// ErrAdmissionPolicyTimeout is returned when evaluating an admission policy takes longer than its configured timeout
+var ErrAdmissionPolicyTimeout = errors.New("admission policy evaluation timed out")
+
+// AdmissionPolicy is a compiled admission policy bundle, used to validate and mutate admission requests
+// alongside (and before) an app's own validation and mutation.
+type AdmissionPolicy interface {
+	// Validate returns an error if the request is denied by the policy
+	Validate(ctx context.Context, request *resource.AdmissionRequest) error
+	// Mutate returns the mutated request object. If the policy has no mutations for the request,
+	// the returned MutatingResponse contains the unmodified request object.
+	Mutate(ctx context.Context, request *resource.AdmissionRequest) (*resource.MutatingResponse, error)
+}
+
+// AdmissionPolicyEngine compiles policy bundles into an AdmissionPolicy.
+// Engines execute untrusted policy logic, and must not allow it access to the host (filesystem, network, etc.).
+// The AdmissionPolicy returned by an engine must stop evaluating and return when its context is canceled.
+// Engines for other policy languages (such as WASM modules) can be added via RunnerAdmissionPolicyConfig.Engines.
+type AdmissionPolicyEngine interface {
+	Compile(source []byte) (AdmissionPolicy, error)
+}
+
+// AdmissionPolicyEngineFunc is a function which implements AdmissionPolicyEngine
+type AdmissionPolicyEngineFunc func(source []byte) (AdmissionPolicy, error)
+
+// Compile calls the function
+func (f AdmissionPolicyEngineFunc) Compile(source []byte) (AdmissionPolicy, error) {
+	return f(source)
+}
+
+// PolicyAdmissionControllerConfig is the configuration for a PolicyAdmissionController
+type PolicyAdmissionControllerConfig struct {
+	// Filesystem is the fs.FS to load the policy bundle from
+	Filesystem fs.FS
+	// Path is the path of the policy bundle in Filesystem
+	Path string
+	// Engine is the AdmissionPolicyEngine used to compile the policy bundle
+	Engine AdmissionPolicyEngine
+	// Timeout is the maximum time a single Validate or Mutate call may take, including time spent waiting
+	// for an evaluation slot (see MaxConcurrentEvaluations). Defaults to one second.
+	Timeout time.Duration
+	// MaxConcurrentEvaluations is the maximum number of Validate or Mutate calls evaluated at once.
+	// Further calls wait for an evaluation to finish. Defaults to 32.
+	MaxConcurrentEvaluations int
+	// ReloadInterval is the interval at which the bundle is checked for changes when running. Defaults to 30 seconds.
+	ReloadInterval time.Duration
+	// MaxBundleBytes is the maximum size of the policy bundle. Defaults to 1MiB.
+	MaxBundleBytes int
+}
+
+// PolicyAdmissionController is a resource.ValidatingAdmissionController and resource.MutatingAdmissionController
+// which evaluates a policy bundle loaded from a filesystem. While running, it reloads the bundle whenever its
+// contents change, so policy can be updated without restarting the operator.
+// If a changed bundle fails to compile, the previously-loaded policy continues to be used.
+// It should be instantiated with NewPolicyAdmissionController.
+type PolicyAdmissionController struct {
+	config PolicyAdmissionControllerConfig
+	sem    chan struct{}
+	mux    sync.RWMutex
+	policy AdmissionPolicy
+	sum    [sha256.Size]byte
+}
+
+// NewPolicyAdmissionController returns a new PolicyAdmissionController, with the policy bundle loaded and compiled
+func NewPolicyAdmissionController(cfg PolicyAdmissionControllerConfig) (*PolicyAdmissionController, error) {
+	if cfg.Filesystem == nil {
+		return nil, errors.New("config.Filesystem cannot be nil")
+	}
+	if cfg.Engine == nil {
+		return nil, errors.New("config.Engine cannot be nil")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultAdmissionPolicyTimeout
+	}
+	if cfg.ReloadInterval <= 0 {
+		cfg.ReloadInterval = defaultAdmissionPolicyReloadInterval
+	}
+	if cfg.MaxBundleBytes <= 0 {
+		cfg.MaxBundleBytes = defaultAdmissionPolicyMaxBytes
+	}
+	if cfg.MaxConcurrentEvaluations <= 0 {
+		cfg.MaxConcurrentEvaluations = defaultAdmissionPolicyMaxConcurrent
+	}
+	p := &PolicyAdmissionController{
+		config: cfg,
+		sem:    make(chan struct{}, cfg.MaxConcurrentEvaluations),
+	}
+	if _, err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Reload reloads the policy bundle, compiling it if its contents have changed.
+// It returns true if a new policy was loaded.
+func (p *PolicyAdmissionController) Reload() (bool, error) {
+	contents, err := fs.ReadFile(p.config.Filesystem, p.config.Path)
+	if err != nil {
+		return false, fmt.Errorf("unable to read admission policy '%s': %w", p.config.Path, err)
+	}
+	if len(contents) > p.config.MaxBundleBytes {
+		return false, fmt.Errorf("admission policy '%s' is %d bytes, which exceeds the maximum of %d",
+			p.config.Path, len(contents), p.config.MaxBundleBytes)
+	}
+	sum := sha256.Sum256(contents)
+	p.mux.RLock()
+	unchanged := p.policy != nil && sum == p.sum
+	p.mux.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+	policy, err := p.config.Engine.Compile(contents)
+	if err != nil {
+		return false, fmt.Errorf("unable to compile admission policy '%s': %w", p.config.Path, err)
+	}
+	p.mux.Lock()
+	p.policy = policy
+	p.sum = sum
+	p.mux.Unlock()
+	return true, nil
+}
+
+// Validate evaluates the validations of the current policy against the request
+func (p *PolicyAdmissionController) Validate(ctx context.Context, request *resource.AdmissionRequest) error {
+	ctx, span := GetTracer().Start(ctx, "admission-policy-validate")
+	defer span.End()
+	_, err := evaluatePolicy(ctx, p, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, p.current().Validate(ctx, request)
+	})
+	return err
+}
+
+// Mutate evaluates the mutations of the current policy against the request
+func (p *PolicyAdmissionController) Mutate(ctx context.Context, request *resource.AdmissionRequest) (*resource.MutatingResponse, error) {
+	ctx, span := GetTracer().Start(ctx, "admission-policy-mutate")
+	defer span.End()
+	return evaluatePolicy(ctx, p, func(ctx context.Context) (*resource.MutatingResponse, error) {
+		return p.current().Mutate(ctx, request)
+	})
+}
+
+// Run reloads the policy bundle every ReloadInterval until ctx is canceled
+func (p *PolicyAdmissionController) Run(ctx context.Context) error {
+	t := time.NewTicker(p.config.ReloadInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			reloaded, err := p.Reload()
+			if err != nil {
+				logging.FromContext(ctx).Error("error reloading admission policy", "path", p.config.Path, "error", err)
+			} else if reloaded {
+				logging.FromContext(ctx).Info("reloaded admission policy", "path", p.config.Path)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (p *PolicyAdmissionController) current() AdmissionPolicy {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+	return p.policy
+}
+
+// Compile-time interface compliance checks
+var (
+	_ resource.ValidatingAdmissionController = &PolicyAdmissionController{}
+	_ resource.MutatingAdmissionController   = &PolicyAdmissionController{}
+)
+
+// evaluatePolicy waits for an evaluation slot of p, and calls fn with a context which is canceled after p's timeout.
+// It returns ErrAdmissionPolicyTimeout if the timeout elapses before a slot is free or fn returns.
+func evaluatePolicy[T any](ctx context.Context, p *PolicyAdmissionController, fn func(context.Context) (T, error)) (T, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+	var zero T
+	select {
+	case p.sem <- struct{}{}:
+		defer func() { <-p.sem }()
+	case <-ctx.Done():
+		return zero, policyContextError(ctx)
+	}
+	val, err := fn(ctx)
+	if err != nil && ctx.Err() != nil {
+		return zero, policyContextError(ctx)
+	}
+	return val, err
+}
+
+func policyContextError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrAdmissionPolicyTimeout
+	}
+	return ctx.Err()
+}
+
+// CELAdmissionPolicyEngine returns the built-in AdmissionPolicyEngine, which compiles YAML policy bundles
+// of CEL (https://cel.dev) expressions. CEL expressions have no access to the host, always terminate,
+// and are limited in the cost they may incur evaluating a single request. Evaluation is aborted
+// when the request's context is canceled. A bundle has the form:
+//
+//	validations:
+//	  - expression: 'object.spec.replicas <= 10'   # must evaluate to a bool; false denies the request
+//	    message: 'spec.replicas cannot exceed 10'
+//	    operations: [CREATE, UPDATE]               # optional, defaults to all operations
+//	mutations:
+//	  - path: /metadata/labels/team                # JSON pointer of the field to set
+//	    expression: '"unassigned"'                 # the value to set
+//	    condition: '!has(object.metadata.labels) || !("team" in object.metadata.labels)' # optional, only mutate if true
+//	    operations: [CREATE]
+//
+// Expressions can use the variables `object` and `oldObject` (the request objects as JSON maps, or null),
+// `operation` (the request action, such as "CREATE"), and `user` (with `username` and `groups`).
+func CELAdmissionPolicyEngine() AdmissionPolicyEngine {
+	return AdmissionPolicyEngineFunc(compileCELPolicy)
+}
+
+type celPolicyBundle struct {
+	Validations []struct {
+		Expression string   `yaml:"expression"`
+		Message    string   `yaml:"message"`
+		Operations []string `yaml:"operations"`
+	} `yaml:"validations"`
+	Mutations []struct {
+		Path       string   `yaml:"path"`
+		Expression string   `yaml:"expression"`
+		Condition  string   `yaml:"condition"`
+		Operations []string `yaml:"operations"`
+	} `yaml:"mutations"`
+}
+
+type celValidation struct {
+	program    cel.Program
+	message    string
+	operations []string
+}
+
+type celMutation struct {
+	path       []string
+	program    cel.Program
+	condition  cel.Program
+	operations []string
+}
+
+type celPolicy struct {
+	validations []celValidation
+	mutations   []celMutation
+}
+
+func compileCELPolicy(source []byte) (AdmissionPolicy, error) {
+	bundle := celPolicyBundle{}
+	if err := yaml.Unmarshal(source, &bundle); err != nil {
+		return nil, err
+	}
+	env, err := cel.NewEnv(
+		cel.Variable("object", cel.DynType),
+		cel.Variable("oldObject", cel.DynType),
+		cel.Variable("operation", cel.StringType),
+		cel.Variable("user", cel.MapType(cel.StringType, cel.DynType)),
+		cel.CrossTypeNumericComparisons(true),
+	)
+	if err != nil {
+		return nil, err
+	}
+	policy := &celPolicy{}
+	for i, v := range bundle.Validations {
+		program, err := compileCELExpression(env, v.Expression, true)
+		if err != nil {
+			return nil, fmt.Errorf("validations[%d]: %w", i, err)
+		}
+		msg := v.Message
+		if msg == "" {
+			msg = fmt.Sprintf("failed policy validation '%s'", v.Expression)
+		}
+		policy.validations = append(policy.validations, celValidation{
+			program:    program,
+			message:    msg,
+			operations: v.Operations,
+		})
+	}
+	for i, m := range bundle.Mutations {
+		path, err := parseJSONPointer(m.Path)
+		if err != nil {
+			return nil, fmt.Errorf("mutations[%d]: %w", i, err)
+		}
+		program, err := compileCELExpression(env, m.Expression, false)
+		if err != nil {
+			return nil, fmt.Errorf("mutations[%d]: %w", i, err)
+		}
+		mut := celMutation{
+			path:       path,
+			program:    program,
+			operations: m.Operations,
+		}
+		if m.Condition != "" {
+			mut.condition, err = compileCELExpression(env, m.Condition, true)
+			if err != nil {
+				return nil, fmt.Errorf("mutations[%d] condition: %w", i, err)
+			}
+		}
+		policy.mutations = append(policy.mutations, mut)
+	}
+	return policy, nil
+}
+
+// compileCELExpression compiles expression into a cost-limited, interruptible program.
+// If isBool is true, the expression must evaluate to a bool (or a dynamic value, checked when evaluated).
+func compileCELExpression(env *cel.Env, expression string, isBool bool) (cel.Program, error) {
+	ast, iss := env.Compile(expression)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if isBool && ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("expression '%s' must evaluate to a bool, not %s", expression, ast.OutputType())
+	}
+	return env.Program(ast,
+		cel.CostLimit(celAdmissionPolicyCostLimit),
+		cel.InterruptCheckFrequency(celAdmissionPolicyInterruptCheckFrequency),
+	)
+}
+
+func (p *celPolicy) Validate(ctx context.Context, request *resource.AdmissionRequest) error {
+	vars, err := celPolicyVars(request)
+	if err != nil {
+		return err
+	}
+	for _, v := range p.validations {
+		if !appliesToOperation(v.operations, request.Action) {
+			continue
+		}
+		allowed, err := evalCELBool(ctx, v.program, vars)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return k8s.NewAdmissionError(errors.New(v.message), http.StatusBadRequest, ErrReasonPolicyDenied)
+		}
+	}
+	return nil
+}
+
+func (p *celPolicy) Mutate(ctx context.Context, request *resource.AdmissionRequest) (*resource.MutatingResponse, error) {
+	vars, err := celPolicyVars(request)
+	if err != nil {
+		return nil, err
+	}
+	obj, _ := vars["object"].(map[string]any)
+	mutated := false
+	for _, m := range p.mutations {
+		if obj == nil || !appliesToOperation(m.operations, request.Action) {
+			continue
+		}
+		if m.condition != nil {
+			ok, err := evalCELBool(ctx, m.condition, vars)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		out, _, err := m.program.ContextEval(ctx, vars)
+		if err != nil {
+			return nil, err
+		}
+		val, err := out.ConvertToNative(reflect.TypeOf(&structpb.Value{}))
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert result of mutation for '%s': %w", strings.Join(m.path, "/"), err)
+		}
+		setAtPath(obj, m.path, val.(*structpb.Value).AsInterface())
+		mutated = true
+	}
+	if !mutated {
+		return &resource.MutatingResponse{UpdatedObject: request.Object}, nil
+	}
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	updated := request.Object.Copy()
+	if err = json.NewDecoder(bytes.NewReader(raw)).Decode(updated); err != nil {
+		return nil, fmt.Errorf("unable to apply policy mutations to object: %w", err)
+	}
+	return &resource.MutatingResponse{UpdatedObject: updated}, nil
+}
+
+func evalCELBool(ctx context.Context, program cel.Program, vars map[string]any) (bool, error) {
+	out, _, err := program.ContextEval(ctx, vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression evaluated to %s, not a bool", out.Type())
+	}
+	return b, nil
+}
+
+func celPolicyVars(request *resource.AdmissionRequest) (map[string]any, error) {
+	vars := map[string]any{
+		"operation": string(request.Action),
+		"user": map[string]any{
+			"username": request.UserInfo.Username,
+			"groups":   request.UserInfo.Groups,
+		},
+		"object":    nil,
+		"oldObject": nil,
+	}
+	for key, obj := range map[string]resource.Object{"object": request.Object, "oldObject": request.OldObject} {
+		if obj == nil {
+			continue
+		}
+		raw, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]any)
+		if err = json.Unmarshal(raw, &m); err != nil {
+			return nil, err
+		}
+		vars[key] = m
+	}
+	return vars, nil
+}
+
+func appliesToOperation(operations []string, action resource.AdmissionAction) bool {
+	return len(operations) == 0 || slices.Contains(operations, string(action)) || slices.Contains(operations, "*")
+}
+
+// parseJSONPointer parses an RFC 6901 JSON pointer into its unescaped segments
+func parseJSONPointer(pointer string) ([]string, error) {
+	if !strings.HasPrefix(pointer, "/") || len(pointer) < 2 {
+		return nil, fmt.Errorf("invalid path '%s': must be a JSON pointer to a field", pointer)
+	}
+	segments := strings.Split(pointer[1:], "/")
+	for i, s := range segments {
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(s, "~1", "/"), "~0", "~")
+	}
+	return segments, nil
+}
+
+// setAtPath sets val at path in obj, creating intermediate objects as required
+func setAtPath(obj map[string]any, path []string, val any) {
+	cur := obj
+	for _, key := range path[:len(path)-1] {
+		next, ok := cur[key].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			cur[key] = next
+		}
+		cur = next
+	}
+	cur[path[len(path)-1]] = val
+}
@@ -0,0 +1,211 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+const testPolicyBundle = `
+validations:
+  - expression: 'object.spec.replicas <= 10'
+    message: 'spec.replicas cannot exceed 10'
+    operations: [CREATE, UPDATE]
+  - expression: 'oldObject == null || object.spec.owner == oldObject.spec.owner'
+    message: 'spec.owner cannot be changed'
+    operations: [UPDATE]
+mutations:
+  - path: /metadata/labels/team
+    expression: '"unassigned"'
+    condition: '!has(object.metadata.labels) || !("team" in object.metadata.labels)'
+    operations: [CREATE]
+`
+
+func TestPolicyAdmissionController(t *testing.T) {
+	newObj := func(replicas int, owner string) *resource.UntypedObject {
+		obj := &resource.UntypedObject{}
+		obj.SetName("foo")
+		obj.Spec = map[string]any{"replicas": replicas, "owner": owner}
+		return obj
+	}
+	files := fstest.MapFS{"policy.yaml": &fstest.MapFile{Data: []byte(testPolicyBundle)}}
+	p, err := NewPolicyAdmissionController(PolicyAdmissionControllerConfig{
+		Filesystem: files,
+		Path:       "policy.yaml",
+		Engine:     CELAdmissionPolicyEngine(),
+	})
+	require.Nil(t, err)
+
+	t.Run("validate", func(t *testing.T) {
+		assert.Nil(t, p.Validate(context.Background(), &resource.AdmissionRequest{
+			Action: resource.AdmissionActionCreate,
+			Object: newObj(5, "me"),
+		}))
+		err := p.Validate(context.Background(), &resource.AdmissionRequest{
+			Action: resource.AdmissionActionCreate,
+			Object: newObj(11, "me"),
+		})
+		assert.EqualError(t, err, "spec.replicas cannot exceed 10")
+		err = p.Validate(context.Background(), &resource.AdmissionRequest{
+			Action:    resource.AdmissionActionUpdate,
+			Object:    newObj(5, "you"),
+			OldObject: newObj(5, "me"),
+		})
+		assert.EqualError(t, err, "spec.owner cannot be changed")
+		// Validations not applicable to the operation are skipped
+		assert.Nil(t, p.Validate(context.Background(), &resource.AdmissionRequest{
+			Action: resource.AdmissionActionDelete,
+			Object: newObj(11, "me"),
+		}))
+	})
+
+	t.Run("mutate", func(t *testing.T) {
+		resp, err := p.Mutate(context.Background(), &resource.AdmissionRequest{
+			Action: resource.AdmissionActionCreate,
+			Object: newObj(5, "me"),
+		})
+		require.Nil(t, err)
+		assert.Equal(t, "unassigned", resp.UpdatedObject.GetLabels()["team"])
+
+		labeled := newObj(5, "me")
+		labeled.SetLabels(map[string]string{"team": "a"})
+		resp, err = p.Mutate(context.Background(), &resource.AdmissionRequest{
+			Action: resource.AdmissionActionCreate,
+			Object: labeled,
+		})
+		require.Nil(t, err)
+		assert.Equal(t, "a", resp.UpdatedObject.GetLabels()["team"])
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		files["policy.yaml"] = &fstest.MapFile{Data: []byte("validations:\n  - expression: 'false'\n    message: denied\n")}
+		reloaded, err := p.Reload()
+		require.Nil(t, err)
+		assert.True(t, reloaded)
+		assert.EqualError(t, p.Validate(context.Background(), &resource.AdmissionRequest{
+			Action: resource.AdmissionActionCreate,
+			Object: newObj(5, "me"),
+		}), "denied")
+
+		reloaded, err = p.Reload()
+		require.Nil(t, err)
+		assert.False(t, reloaded)
+
+		// A bundle which fails to compile leaves the previous policy in place
+		files["policy.yaml"] = &fstest.MapFile{Data: []byte("validations:\n  - expression: '1 +'\n")}
+		_, err = p.Reload()
+		assert.NotNil(t, err)
+		assert.EqualError(t, p.Validate(context.Background(), &resource.AdmissionRequest{
+			Action: resource.AdmissionActionCreate,
+			Object: newObj(5, "me"),
+		}), "denied")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		slow, err := NewPolicyAdmissionController(PolicyAdmissionControllerConfig{
+			Filesystem: files,
+			Path:       "policy.yaml",
+			Engine: AdmissionPolicyEngineFunc(func([]byte) (AdmissionPolicy, error) {
+				return &slowPolicy{}, nil
+			}),
+			Timeout: 10 * time.Millisecond,
+		})
+		require.Nil(t, err)
+		assert.ErrorIs(t, slow.Validate(context.Background(), &resource.AdmissionRequest{}), ErrAdmissionPolicyTimeout)
+	})
+
+	t.Run("concurrency limit", func(t *testing.T) {
+		blocking := &blockingPolicy{started: make(chan struct{}), release: make(chan struct{})}
+		limited, err := NewPolicyAdmissionController(PolicyAdmissionControllerConfig{
+			Filesystem: files,
+			Path:       "policy.yaml",
+			Engine: AdmissionPolicyEngineFunc(func([]byte) (AdmissionPolicy, error) {
+				return blocking, nil
+			}),
+			Timeout:                  50 * time.Millisecond,
+			MaxConcurrentEvaluations: 1,
+		})
+		require.Nil(t, err)
+		errs := make(chan error, 1)
+		go func() {
+			errs <- limited.Validate(context.Background(), &resource.AdmissionRequest{})
+		}()
+		<-blocking.started
+		// The only evaluation slot is taken, so this call times out without evaluating the policy
+		assert.ErrorIs(t, limited.Validate(context.Background(), &resource.AdmissionRequest{}), ErrAdmissionPolicyTimeout)
+		assert.Equal(t, int32(1), blocking.calls.Load())
+		close(blocking.release)
+		assert.Nil(t, <-errs)
+	})
+
+	t.Run("cost limit", func(t *testing.T) {
+		items := make([]string, 200)
+		for i := range items {
+			items[i] = fmt.Sprint(i)
+		}
+		expensive, err := NewPolicyAdmissionController(PolicyAdmissionControllerConfig{
+			Filesystem: fstest.MapFS{"policy.yaml": &fstest.MapFile{Data: []byte(
+				"validations:\n  - expression: 'object.spec.items.all(x, object.spec.items.all(y, object.spec.items.all(z, z != \"-\")))'\n",
+			)}},
+			Path:   "policy.yaml",
+			Engine: CELAdmissionPolicyEngine(),
+			// The timeout is long enough that the cost limit is always reached first, even in slow (such as -race) runs
+			Timeout: time.Minute,
+		})
+		require.Nil(t, err)
+		obj := &resource.UntypedObject{}
+		obj.Spec = map[string]any{"items": items}
+		err = expensive.Validate(context.Background(), &resource.AdmissionRequest{
+			Action: resource.AdmissionActionCreate,
+			Object: obj,
+		})
+		require.NotNil(t, err)
+		assert.True(t, strings.Contains(err.Error(), "cost limit exceeded"), err.Error())
+	})
+}
+
+type slowPolicy struct{}
+
+func (*slowPolicy) Validate(ctx context.Context, _ *resource.AdmissionRequest) error {
+	select {
+	case <-time.After(100 * time.Millisecond):
+	case <-ctx.Done():
+	}
+	return ctx.Err()
+}
+
+func (*slowPolicy) Mutate(ctx context.Context, _ *resource.AdmissionRequest) (*resource.MutatingResponse, error) {
+	select {
+	case <-time.After(100 * time.Millisecond):
+	case <-ctx.Done():
+	}
+	return nil, ctx.Err()
+}
+
+// blockingPolicy blocks each evaluation until release is closed
+type blockingPolicy struct {
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingPolicy) Validate(context.Context, *resource.AdmissionRequest) error {
+	if b.calls.Add(1) == 1 {
+		close(b.started)
+	}
+	<-b.release
+	return nil
+}
+
+func (b *blockingPolicy) Mutate(context.Context, *resource.AdmissionRequest) (*resource.MutatingResponse, error) {
+	return nil, nil
+}
@@ -0,0 +1,326 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/metrics"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+const (
+	// BackfillAnnotationPrefix is the prefix of the annotation used by the annotation BackfillTracker
+	// to mark objects which have been processed by a backfill. The full annotation key is BackfillAnnotationPrefix + name.
+	BackfillAnnotationPrefix = "backfill.grafana.app/"
+
+	defaultBackfillPageSize = 100
+)
+
+var _ app.Runnable = &Backfill{}
+
+// BackfillProcessFunc processes a single object in a Backfill
+type BackfillProcessFunc func(ctx context.Context, object resource.Object) error
+
+// BackfillTracker durably tracks which objects have been processed by a Backfill,
+// so that a Backfill which is restarted skips objects it has already processed.
+type BackfillTracker interface {
+	// IsProcessed returns true if the object has already been processed
+	IsProcessed(ctx context.Context, object resource.Object) (bool, error)
+	// MarkProcessed records that the object has been processed
+	MarkProcessed(ctx context.Context, object resource.Object) error
+}
+
+// BackfillConfig is the configuration for a Backfill
+type BackfillConfig struct {
+	// Name uniquely identifies the backfill. It is used by the annotation BackfillTracker as the name part of its
+	// annotation key, so it must be a valid qualified name (63 characters or fewer, alphanumeric, '-', '_', or '.',
+	// starting and ending with an alphanumeric character), even if another Tracker is used.
+	// To process every object again, use a new Name.
+	Name string
+	// ListWatchOptions are the namespace, label filters, and field selectors used to list the objects to process.
+	// An empty Namespace lists objects in all namespaces.
+	ListWatchOptions ListWatchOptions
+	// PageSize is the number of objects to request in each list page. Defaults to 100.
+	PageSize int
+	// Workers is the number of objects processed concurrently. Defaults to 1.
+	Workers int
+	// QPS limits the rate at which objects are processed. Objects which are skipped as already processed do not count
+	// towards the limit. If zero, the rate is not limited.
+	QPS float64
+	// Burst is the maximum burst of objects processed above QPS. If zero, the burst is max(1, QPS).
+	Burst int
+	// Tracker records which objects have been processed. If nil, an annotation on each object is used,
+	// which requires the client to be able to patch the objects' metadata.
+	Tracker BackfillTracker
+	// MetricsConfig is the configuration for the backfill's metrics
+	MetricsConfig metrics.Config
+}
+
+// BackfillStatus is a snapshot of the progress of a Backfill
+type BackfillStatus struct {
+	// Processed is the number of objects which were processed successfully
+	Processed int64
+	// Skipped is the number of objects which were skipped as already processed
+	Skipped int64
+	// Failed is the number of objects whose processing, or marking as processed, failed
+	Failed int64
+	// Done is true once every listed object has been processed, skipped, or failed
+	Done bool
+}
+
+// Backfill processes every object of a kind once, for flows such as data migrations. It lists objects with pagination,
+// processes them with a pool of workers at a limited rate, and tracks processed objects with a BackfillTracker,
+// so that it can be restarted (by re-running the process) without processing objects again.
+//
+// Objects which fail to process are not marked as processed, and are counted in BackfillStatus.Failed.
+// Run returns an error if any object failed, so the backfill can be run again to retry them.
+// Backfill implements app.Runnable, so it can be run alongside an app's other runners.
+// Backfill contains unexported fields, and must be created with NewBackfill.
+type Backfill struct {
+	name      string
+	client    resource.Client
+	process   BackfillProcessFunc
+	tracker   BackfillTracker
+	options   ListWatchOptions
+	pageSize  int
+	workers   int
+	limiter   *rate.Limiter
+	processed atomic.Int64
+	skipped   atomic.Int64
+	failed    atomic.Int64
+	done      atomic.Bool
+	objects   *prometheus.CounterVec
+}
+
+// NewBackfill creates a new Backfill which lists objects with client, and calls process for each one which has
+// not already been processed according to cfg.Tracker.
+func NewBackfill(client resource.Client, process BackfillProcessFunc, cfg BackfillConfig) (*Backfill, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+	if process == nil {
+		return nil, fmt.Errorf("process cannot be nil")
+	}
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("name cannot be empty")
+	}
+	if errs := validation.IsQualifiedName(BackfillAnnotationPrefix + cfg.Name); len(errs) > 0 {
+		return nil, fmt.Errorf("name %s is not a valid annotation name: %s", cfg.Name, strings.Join(errs, ", "))
+	}
+	b := &Backfill{
+		name:     cfg.Name,
+		client:   client,
+		process:  process,
+		tracker:  cfg.Tracker,
+		options:  cfg.ListWatchOptions,
+		pageSize: cfg.PageSize,
+		workers:  cfg.Workers,
+		objects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.MetricsConfig.Namespace,
+			Subsystem: "backfill",
+			Name:      "objects_total",
+			Help:      "Total number of objects handled by a backfill, by result (processed, skipped, or failed).",
+		}, []string{"backfill", "result"}),
+	}
+	if b.tracker == nil {
+		b.tracker = NewAnnotationBackfillTracker(client, cfg.Name)
+	}
+	if b.pageSize <= 0 {
+		b.pageSize = defaultBackfillPageSize
+	}
+	if b.workers <= 0 {
+		b.workers = 1
+	}
+	if cfg.QPS > 0 {
+		burst := cfg.Burst
+		if burst <= 0 {
+			burst = max(1, int(cfg.QPS))
+		}
+		b.limiter = rate.NewLimiter(rate.Limit(cfg.QPS), burst)
+	}
+	return b, nil
+}
+
+// Run lists and processes every object, blocking until all objects have been handled or ctx is canceled.
+// If the list is interrupted because its continue token expired, listing restarts from the beginning,
+// and objects which were already handled by this Run (processed, skipped, or failed) are ignored, so that each
+// object is handled and counted once per Run.
+// It returns an error if listing fails, if ctx is canceled, or if any object failed to process.
+func (b *Backfill) Run(ctx context.Context) error {
+	logger := logging.FromContext(ctx).With("component", "Backfill", "backfill", b.name)
+	logger.Info("Starting backfill")
+	handled := make(map[string]struct{})
+	for {
+		err := b.runList(ctx, handled)
+		if err != nil && isGone(err) && ctx.Err() == nil {
+			logger.Info("Backfill list continue token expired, restarting list", "error", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		break
+	}
+	b.done.Store(true)
+	status := b.Status()
+	logger.Info("Backfill complete", "processed", status.Processed, "skipped", status.Skipped, "failed", status.Failed)
+	if status.Failed > 0 {
+		return fmt.Errorf("backfill '%s' failed to process %d objects", b.name, status.Failed)
+	}
+	return nil
+}
+
+// Status returns the current progress of the backfill
+func (b *Backfill) Status() BackfillStatus {
+	return BackfillStatus{
+		Processed: b.processed.Load(),
+		Skipped:   b.skipped.Load(),
+		Failed:    b.failed.Load(),
+		Done:      b.done.Load(),
+	}
+}
+
+// PrometheusCollectors returns the prometheus metric collectors used by the backfill
+func (b *Backfill) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{b.objects}
+}
+
+// runList lists every object once, distributing the objects which are not in handled to the workers
+// (and adding them to handled), and waits for the workers to finish
+func (b *Backfill) runList(ctx context.Context, handled map[string]struct{}) error {
+	queue := make(chan resource.Object)
+	wg := sync.WaitGroup{}
+	for i := 0; i < b.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for obj := range queue {
+				b.handle(ctx, obj)
+			}
+		}()
+	}
+	iter := resource.NewListIterator(b.client, b.options.Namespace, resource.ListOptions{
+		Limit:          b.pageSize,
+		LabelFilters:   b.options.LabelFilters,
+		FieldSelectors: b.options.FieldSelectors,
+	})
+	for iter.Next(ctx) {
+		obj := iter.Object()
+		key := backfillObjectKey(obj)
+		if _, ok := handled[key]; ok {
+			continue
+		}
+		select {
+		case queue <- obj:
+			handled[key] = struct{}{}
+		case <-ctx.Done():
+		}
+	}
+	close(queue)
+	wg.Wait()
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+func (b *Backfill) handle(ctx context.Context, obj resource.Object) {
+	if ctx.Err() != nil {
+		return
+	}
+	logger := logging.FromContext(ctx).With("component", "Backfill", "backfill", b.name, "namespace", obj.GetNamespace(), "name", obj.GetName())
+	processed, err := b.tracker.IsProcessed(ctx, obj)
+	if err != nil {
+		logger.Error("Unable to check whether object has been processed", "error", err)
+		b.record("failed", &b.failed)
+		return
+	}
+	if processed {
+		b.record("skipped", &b.skipped)
+		return
+	}
+	if b.limiter != nil {
+		if err = b.limiter.Wait(ctx); err != nil {
+			return
+		}
+	}
+	if err = b.process(ctx, obj); err != nil {
+		logger.Error("Backfill processing failed for object", "error", err)
+		b.record("failed", &b.failed)
+		return
+	}
+	if err = b.tracker.MarkProcessed(ctx, obj); err != nil {
+		logger.Error("Unable to mark object as processed", "error", err)
+		b.record("failed", &b.failed)
+		return
+	}
+	b.record("processed", &b.processed)
+}
+
+// backfillObjectKey returns the key of obj used to ignore objects which were already handled,
+// which is its UID, or its namespace and name if it has no UID
+func backfillObjectKey(obj resource.Object) string {
+	if uid := obj.GetUID(); uid != "" {
+		return string(uid)
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
+
+func (b *Backfill) record(result string, count *atomic.Int64) {
+	count.Add(1)
+	b.objects.WithLabelValues(b.name, result).Inc()
+}
+
+func isGone(err error) bool {
+	var cast resource.APIServerResponseError
+	return errors.As(err, &cast) && cast.StatusCode() == http.StatusGone
+}
+
+// NewAnnotationBackfillTracker returns a BackfillTracker which marks processed objects with the annotation
+// BackfillAnnotationPrefix + name, set to the time the object was processed. The annotation is added with a merge patch.
+func NewAnnotationBackfillTracker(client PatchClient, name string) BackfillTracker {
+	return &annotationBackfillTracker{
+		client:     client,
+		annotation: BackfillAnnotationPrefix + name,
+	}
+}
+
+type annotationBackfillTracker struct {
+	client     PatchClient
+	annotation string
+}
+
+func (a *annotationBackfillTracker) IsProcessed(_ context.Context, object resource.Object) (bool, error) {
+	_, ok := object.GetAnnotations()[a.annotation]
+	return ok, nil
+}
+
+func (a *annotationBackfillTracker) MarkProcessed(ctx context.Context, object resource.Object) error {
+	body, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{
+				a.annotation: time.Now().UTC().Format(time.RFC3339),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return a.client.PatchInto(ctx, object.GetStaticMetadata().Identifier(), resource.PatchRequest{
+		Type: resource.PatchTypeMergePatch,
+		Body: body,
+	}, resource.PatchOptions{}, object)
+}
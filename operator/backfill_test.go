@@ -0,0 +1,248 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestNewBackfill(t *testing.T) {
+	process := func(context.Context, resource.Object) error { return nil }
+	client := &testBackfillClient{}
+
+	_, err := NewBackfill(nil, process, BackfillConfig{Name: "foo"})
+	assert.Equal(t, errors.New("client cannot be nil"), err)
+	_, err = NewBackfill(client, nil, BackfillConfig{Name: "foo"})
+	assert.Equal(t, errors.New("process cannot be nil"), err)
+	_, err = NewBackfill(client, process, BackfillConfig{})
+	assert.Equal(t, errors.New("name cannot be empty"), err)
+	_, err = NewBackfill(client, process, BackfillConfig{Name: "migrate/v2"})
+	assert.ErrorContains(t, err, "name migrate/v2 is not a valid annotation name")
+	_, err = NewBackfill(client, process, BackfillConfig{Name: strings.Repeat("a", 64)})
+	assert.ErrorContains(t, err, "is not a valid annotation name")
+	b, err := NewBackfill(client, process, BackfillConfig{Name: "foo"})
+	require.Nil(t, err)
+	assert.Equal(t, defaultBackfillPageSize, b.pageSize)
+	assert.Equal(t, 1, b.workers)
+	assert.Nil(t, b.limiter)
+}
+
+func TestBackfill_Run(t *testing.T) {
+	t.Run("processes every object once, and skips processed objects on rerun", func(t *testing.T) {
+		client := newTestBackfillClient(10)
+		mux := sync.Mutex{}
+		seen := make(map[string]int)
+		process := func(ctx context.Context, object resource.Object) error {
+			mux.Lock()
+			defer mux.Unlock()
+			seen[object.GetName()]++
+			return nil
+		}
+		b, err := NewBackfill(client, process, BackfillConfig{
+			Name:     "migrate",
+			PageSize: 3,
+			Workers:  4,
+			QPS:      1000,
+		})
+		require.Nil(t, err)
+		require.Nil(t, b.Run(context.Background()))
+		assert.Equal(t, BackfillStatus{Processed: 10, Done: true}, b.Status())
+		assert.Len(t, seen, 10)
+		for name, count := range seen {
+			assert.Equal(t, 1, count, name)
+		}
+		assert.Equal(t, []int{3, 3, 3, 3}, client.limits())
+		assert.Equal(t, float64(10), testutil.ToFloat64(b.objects.WithLabelValues("migrate", "processed")))
+
+		// A new backfill with the same name (such as after a restart) skips every object
+		b, err = NewBackfill(client, process, BackfillConfig{Name: "migrate"})
+		require.Nil(t, err)
+		require.Nil(t, b.Run(context.Background()))
+		assert.Equal(t, BackfillStatus{Skipped: 10, Done: true}, b.Status())
+		assert.Len(t, seen, 10)
+	})
+
+	t.Run("failed objects are not marked", func(t *testing.T) {
+		client := newTestBackfillClient(5)
+		b, err := NewBackfill(client, func(ctx context.Context, object resource.Object) error {
+			if object.GetName() == "obj-2" {
+				return errors.New("I AM ERROR")
+			}
+			return nil
+		}, BackfillConfig{Name: "migrate"})
+		require.Nil(t, err)
+		assert.Equal(t, errors.New("backfill 'migrate' failed to process 1 objects"), b.Run(context.Background()))
+		assert.Equal(t, BackfillStatus{Processed: 4, Failed: 1, Done: true}, b.Status())
+		_, marked := client.objects[2].GetAnnotations()[BackfillAnnotationPrefix+"migrate"]
+		assert.False(t, marked)
+	})
+
+	t.Run("restarts list when continue token expires", func(t *testing.T) {
+		client := newTestBackfillClient(4)
+		client.expireOnce = true
+		count := 0
+		b, err := NewBackfill(client, func(ctx context.Context, object resource.Object) error {
+			count++
+			return nil
+		}, BackfillConfig{Name: "migrate", PageSize: 2})
+		require.Nil(t, err)
+		require.Nil(t, b.Run(context.Background()))
+		// The first page is processed before the token expires, and ignored after the list restarts
+		assert.Equal(t, 4, count)
+		assert.Equal(t, BackfillStatus{Processed: 4, Done: true}, b.Status())
+	})
+
+	t.Run("failed objects are not retried after the list restarts", func(t *testing.T) {
+		client := newTestBackfillClient(4)
+		client.expireOnce = true
+		attempts := make(map[string]int)
+		b, err := NewBackfill(client, func(ctx context.Context, object resource.Object) error {
+			attempts[object.GetName()]++
+			if object.GetName() == "obj-0" {
+				return errors.New("I AM ERROR")
+			}
+			return nil
+		}, BackfillConfig{Name: "migrate", PageSize: 2})
+		require.Nil(t, err)
+		assert.Equal(t, errors.New("backfill 'migrate' failed to process 1 objects"), b.Run(context.Background()))
+		assert.Equal(t, 1, attempts["obj-0"])
+		assert.Equal(t, BackfillStatus{Processed: 3, Failed: 1, Done: true}, b.Status())
+	})
+
+	t.Run("list error", func(t *testing.T) {
+		client := newTestBackfillClient(0)
+		client.listErr = errors.New("JE SUIS ERROR")
+		b, err := NewBackfill(client, func(context.Context, resource.Object) error { return nil }, BackfillConfig{Name: "migrate"})
+		require.Nil(t, err)
+		assert.Equal(t, client.listErr, b.Run(context.Background()))
+		assert.False(t, b.Status().Done)
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		client := newTestBackfillClient(5)
+		ctx, cancel := context.WithCancel(context.Background())
+		b, err := NewBackfill(client, func(context.Context, resource.Object) error {
+			cancel()
+			return nil
+		}, BackfillConfig{Name: "migrate", PageSize: 1})
+		require.Nil(t, err)
+		assert.Equal(t, context.Canceled, b.Run(ctx))
+		assert.Equal(t, int64(1), b.Status().Processed)
+	})
+}
+
+type testBackfillClient struct {
+	resource.Client
+	mux         sync.Mutex
+	objects     []resource.Object
+	listLimits  []int
+	listErr     error
+	expireOnce  bool
+	patchedKeys []string
+}
+
+func newTestBackfillClient(size int) *testBackfillClient {
+	client := &testBackfillClient{
+		objects: make([]resource.Object, size),
+	}
+	for i := 0; i < size; i++ {
+		client.objects[i] = &resource.UntypedObject{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns",
+				Name:      fmt.Sprintf("obj-%d", i),
+			},
+		}
+	}
+	return client
+}
+
+func (c *testBackfillClient) limits() []int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.listLimits
+}
+
+func (c *testBackfillClient) List(_ context.Context, _ string, options resource.ListOptions) (resource.ListObject, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.listErr != nil {
+		return nil, c.listErr
+	}
+	c.listLimits = append(c.listLimits, options.Limit)
+	start := 0
+	if options.Continue != "" {
+		if c.expireOnce {
+			c.expireOnce = false
+			return nil, &testBackfillAPIError{statusCode: http.StatusGone}
+		}
+		start, _ = strconv.Atoi(options.Continue)
+	}
+	end := len(c.objects)
+	if options.Limit > 0 {
+		end = min(start+options.Limit, len(c.objects))
+	}
+	list := &resource.UntypedList{
+		ListMeta: metav1.ListMeta{ResourceVersion: "1"},
+	}
+	for _, obj := range c.objects[start:end] {
+		list.Items = append(list.Items, obj.Copy())
+	}
+	if end < len(c.objects) {
+		list.Continue = strconv.Itoa(end)
+	}
+	return list, nil
+}
+
+func (c *testBackfillClient) PatchInto(_ context.Context, identifier resource.Identifier, patch resource.PatchRequest, _ resource.PatchOptions, into resource.Object) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	body := struct {
+		Metadata struct {
+			Annotations map[string]string `json:"annotations"`
+		} `json:"metadata"`
+	}{}
+	if err := json.Unmarshal(patch.Body, &body); err != nil {
+		return err
+	}
+	for _, obj := range c.objects {
+		if obj.GetStaticMetadata().Identifier() != identifier {
+			continue
+		}
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		for k, v := range body.Metadata.Annotations {
+			annotations[k] = v
+		}
+		obj.SetAnnotations(annotations)
+		into.SetAnnotations(annotations)
+		return nil
+	}
+	return &testBackfillAPIError{statusCode: http.StatusNotFound}
+}
+
+type testBackfillAPIError struct {
+	statusCode int
+}
+
+func (e *testBackfillAPIError) Error() string {
+	return http.StatusText(e.statusCode)
+}
+
+func (e *testBackfillAPIError) StatusCode() int {
+	return e.statusCode
+}
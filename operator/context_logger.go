@@ -0,0 +1,151 @@
+package operator
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+type requestIDContextKey struct{}
+
+type attemptContextKey struct{}
+
+// RequestIDFromContext returns the request ID of the informer event a watcher or reconciler was called for,
+// or an empty string if ctx is not from an InformerController call. Every watcher and reconciler call for the same
+// event, including retries, shares the event's request ID, so it can be used to correlate their logs.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// AttemptFromContext returns the attempt number of a watcher or reconciler call made by an InformerController,
+// which is 0 for the first call for an event, and n for its nth retry.
+func AttemptFromContext(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptContextKey{}).(int)
+	return attempt
+}
+
+func withRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptContextKey{}, attempt)
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// LoggingReconciler wraps a Reconciler, and adds a logging.Logger to the context passed to it with the request ID,
+// attempt, action, and kind, namespace, and name of the object being reconciled (see RequestIDFromContext and
+// AttemptFromContext), so the Reconciler can log with logging.FromContext without adding those attributes itself.
+// It should be instantiated with NewLoggingReconciler.
+type LoggingReconciler struct {
+	Reconciler Reconciler
+	// Logger is the logger the attributes are added to. If nil, the logger from the context (see logging.FromContext) is used.
+	Logger logging.Logger
+}
+
+// NewLoggingReconciler returns a new LoggingReconciler which wraps reconciler
+func NewLoggingReconciler(reconciler Reconciler) (*LoggingReconciler, error) {
+	if reconciler == nil {
+		return nil, fmt.Errorf("reconciler cannot be nil")
+	}
+	return &LoggingReconciler{
+		Reconciler: reconciler,
+	}, nil
+}
+
+// Reconcile adds a logger for the request to ctx, and then calls the wrapped Reconciler
+func (l *LoggingReconciler) Reconcile(ctx context.Context, req ReconcileRequest) (ReconcileResult, error) {
+	ctx = contextWithObjectLogger(ctx, l.Logger, req.Object, "action", reconcileActionName(req.Action))
+	return l.Reconciler.Reconcile(ctx, req)
+}
+
+// Wrap replaces the wrapped Reconciler with reconciler
+func (l *LoggingReconciler) Wrap(reconciler Reconciler) {
+	l.Reconciler = reconciler
+}
+
+// LoggingWatcher wraps a ResourceWatcher, and adds a logging.Logger to the context passed to it, like LoggingReconciler.
+// If the wrapped ResourceWatcher has a Sync method (such as simple.SyncWatcher), it is also called with the logger.
+// It should be instantiated with NewLoggingWatcher.
+type LoggingWatcher struct {
+	Watcher ResourceWatcher
+	// Logger is the logger the attributes are added to. If nil, the logger from the context (see logging.FromContext) is used.
+	Logger logging.Logger
+}
+
+// NewLoggingWatcher returns a new LoggingWatcher which wraps watcher
+func NewLoggingWatcher(watcher ResourceWatcher) (*LoggingWatcher, error) {
+	if watcher == nil {
+		return nil, fmt.Errorf("watcher cannot be nil")
+	}
+	return &LoggingWatcher{
+		Watcher: watcher,
+	}, nil
+}
+
+// Add adds a logger for the event to ctx, and then calls the wrapped ResourceWatcher's Add
+func (l *LoggingWatcher) Add(ctx context.Context, object resource.Object) error {
+	return l.Watcher.Add(contextWithObjectLogger(ctx, l.Logger, object, "action", string(ResourceActionCreate)), object)
+}
+
+// Update adds a logger for the event to ctx, and then calls the wrapped ResourceWatcher's Update
+func (l *LoggingWatcher) Update(ctx context.Context, src resource.Object, tgt resource.Object) error {
+	return l.Watcher.Update(contextWithObjectLogger(ctx, l.Logger, tgt, "action", string(ResourceActionUpdate)), src, tgt)
+}
+
+// Delete adds a logger for the event to ctx, and then calls the wrapped ResourceWatcher's Delete
+func (l *LoggingWatcher) Delete(ctx context.Context, object resource.Object) error {
+	return l.Watcher.Delete(contextWithObjectLogger(ctx, l.Logger, object, "action", string(ResourceActionDelete)), object)
+}
+
+// Sync adds a logger for the event to ctx, and then calls the wrapped ResourceWatcher's Sync,
+// or its Add if it doesn't have a Sync method
+func (l *LoggingWatcher) Sync(ctx context.Context, object resource.Object) error {
+	ctx = contextWithObjectLogger(ctx, l.Logger, object, "action", "SYNC")
+	if cast, ok := l.Watcher.(interface {
+		Sync(context.Context, resource.Object) error
+	}); ok {
+		return cast.Sync(ctx, object)
+	}
+	return l.Watcher.Add(ctx, object)
+}
+
+// Wrap replaces the wrapped ResourceWatcher with watcher
+func (l *LoggingWatcher) Wrap(watcher ResourceWatcher) {
+	l.Watcher = watcher
+}
+
+// contextWithObjectLogger returns ctx with logger (or the logger from ctx, if logger is nil) added,
+// with the request ID, attempt, and object attributes, along with any extra attributes in args.
+// If ctx has no request ID, a new one is generated, so that calls outside an InformerController are still correlated.
+func contextWithObjectLogger(ctx context.Context, logger logging.Logger, object resource.Object, args ...any) context.Context {
+	if logger == nil {
+		logger = logging.FromContext(ctx)
+	}
+	id := RequestIDFromContext(ctx)
+	if id == "" {
+		id = newRequestID()
+		ctx = withRequestID(ctx, id)
+	}
+	attrs := []any{"requestID", id, "attempt", AttemptFromContext(ctx)}
+	if object != nil {
+		attrs = append(attrs, "kind", object.GetStaticMetadata().Kind, "namespace", object.GetNamespace(), "name", object.GetName())
+	}
+	return logging.Context(ctx, logger.With(append(attrs, args...)...))
+}
+
+// Compile-time interface compliance checks
+var (
+	_ Reconciler      = &LoggingReconciler{}
+	_ ResourceWatcher = &LoggingWatcher{}
+)
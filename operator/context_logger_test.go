@@ -0,0 +1,160 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestLoggingReconciler_Reconcile(t *testing.T) {
+	_, err := NewLoggingReconciler(nil)
+	assert.EqualError(t, err, "reconciler cannot be nil")
+
+	obj := newListerTestObject("ns", "foo")
+	logger := &testAttrLogger{}
+	var attrs map[string]any
+	r, err := NewLoggingReconciler(&SimpleReconciler{
+		ReconcileFunc: func(ctx context.Context, _ ReconcileRequest) (ReconcileResult, error) {
+			attrs = logging.FromContext(ctx).(*testAttrLogger).attrs()
+			return ReconcileResult{}, nil
+		},
+	})
+	require.Nil(t, err)
+	r.Logger = logger
+	_, err = r.Reconcile(context.Background(), ReconcileRequest{Action: ReconcileActionCreated, Object: obj})
+	require.Nil(t, err)
+	// Outside of an InformerController, a request ID is generated for the call
+	assert.NotEmpty(t, attrs["requestID"])
+	assert.Equal(t, 0, attrs["attempt"])
+	assert.Equal(t, "CREATE", attrs["action"])
+	assert.Equal(t, "ns", attrs["namespace"])
+	assert.Equal(t, "foo", attrs["name"])
+
+	// Without a Logger, the logger from the context is used
+	r.Logger = nil
+	ctxLogger := &testAttrLogger{}
+	_, err = r.Reconcile(logging.Context(withRequestID(context.Background(), "abc"), ctxLogger), ReconcileRequest{Action: ReconcileActionUpdated, Object: obj})
+	require.Nil(t, err)
+	assert.Equal(t, "abc", attrs["requestID"])
+	assert.Equal(t, "UPDATE", attrs["action"])
+}
+
+func TestLoggingWatcher(t *testing.T) {
+	_, err := NewLoggingWatcher(nil)
+	assert.EqualError(t, err, "watcher cannot be nil")
+
+	calls := make([]string, 0)
+	record := func(call string) func(ctx context.Context) {
+		return func(ctx context.Context) {
+			attrs := logging.FromContext(ctx).(*testAttrLogger).attrs()
+			calls = append(calls, call+":"+attrs["action"].(string)+":"+attrs["name"].(string))
+		}
+	}
+	w, err := NewLoggingWatcher(&SimpleWatcher{
+		AddFunc: func(ctx context.Context, _ resource.Object) error {
+			record("add")(ctx)
+			return nil
+		},
+		UpdateFunc: func(ctx context.Context, _, _ resource.Object) error {
+			record("update")(ctx)
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, _ resource.Object) error {
+			record("delete")(ctx)
+			return nil
+		},
+	})
+	require.Nil(t, err)
+	w.Logger = &testAttrLogger{}
+	obj := newListerTestObject("ns", "foo")
+	require.Nil(t, w.Add(context.Background(), obj))
+	require.Nil(t, w.Update(context.Background(), obj, obj))
+	require.Nil(t, w.Delete(context.Background(), obj))
+	// The wrapped watcher has no Sync method, so Sync calls Add
+	require.Nil(t, w.Sync(context.Background(), obj))
+	assert.Equal(t, []string{"add:CREATE:foo", "update:UPDATE:foo", "delete:DELETE:foo", "add:SYNC:foo"}, calls)
+}
+
+func TestInformerController_RequestIDAndAttempt(t *testing.T) {
+	type call struct {
+		requestID string
+		attempt   int
+	}
+	calls := make(chan call, 10)
+	c := NewInformerController(InformerControllerConfig{
+		RetryPolicy: func(_ error, attempt int) (bool, time.Duration) {
+			return attempt < 1, 0
+		},
+	})
+	c.retryTickerInterval = 10 * time.Millisecond
+	inf := &testInformer{}
+	require.Nil(t, c.AddInformer(inf, "foo"))
+	require.Nil(t, c.AddReconciler(&SimpleReconciler{
+		ReconcileFunc: func(ctx context.Context, _ ReconcileRequest) (ReconcileResult, error) {
+			calls <- call{RequestIDFromContext(ctx), AttemptFromContext(ctx)}
+			return ReconcileResult{}, errors.New("I AM ERROR")
+		},
+	}, "foo"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+	inf.FireAdd(context.Background(), newListerTestObject("ns", "a"))
+	inf.FireAdd(context.Background(), newListerTestObject("ns", "b"))
+
+	received := make([]call, 0, 4)
+	for len(received) < 4 {
+		select {
+		case c := <-calls:
+			received = append(received, c)
+		case <-time.After(time.Second):
+			t.Fatalf("expected 4 reconciles, got %d", len(received))
+		}
+	}
+	// Each event has its own request ID, which its retry shares
+	assert.Equal(t, 0, received[0].attempt)
+	assert.Equal(t, 0, received[1].attempt)
+	assert.NotEmpty(t, received[0].requestID)
+	assert.NotEqual(t, received[0].requestID, received[1].requestID)
+	retries := map[string]int{received[2].requestID: received[2].attempt, received[3].requestID: received[3].attempt}
+	assert.Equal(t, map[string]int{received[0].requestID: 1, received[1].requestID: 1}, retries)
+}
+
+// testAttrLogger is a logging.Logger which records the attributes added with With
+type testAttrLogger struct {
+	mux  sync.Mutex
+	args []any
+}
+
+func (*testAttrLogger) Debug(string, ...any) {}
+func (*testAttrLogger) Info(string, ...any)  {}
+func (*testAttrLogger) Warn(string, ...any)  {}
+func (*testAttrLogger) Error(string, ...any) {}
+
+func (l *testAttrLogger) With(args ...any) logging.Logger {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return &testAttrLogger{args: append(append([]any{}, l.args...), args...)}
+}
+
+func (l *testAttrLogger) WithContext(context.Context) logging.Logger {
+	return l
+}
+
+func (l *testAttrLogger) attrs() map[string]any {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	attrs := make(map[string]any)
+	for i := 0; i+1 < len(l.args); i += 2 {
+		attrs[l.args[i].(string)] = l.args[i+1]
+	}
+	return attrs
+}
@@ -0,0 +1,324 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/k8s"
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/metrics"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+const (
+	// CRDPresentationFieldShortNames is the CRDPresentationDrift.Field for the short names of a CRD
+	CRDPresentationFieldShortNames = "shortNames"
+	// CRDPresentationFieldCategories is the CRDPresentationDrift.Field for the categories of a CRD
+	CRDPresentationFieldCategories = "categories"
+	// CRDPresentationFieldAdditionalPrinterColumns is the CRDPresentationDrift.Field for the printer columns of a CRD version
+	CRDPresentationFieldAdditionalPrinterColumns = "additionalPrinterColumns"
+	// CRDPresentationFieldSelectableFields is the CRDPresentationDrift.Field for the selectable fields of a CRD version
+	CRDPresentationFieldSelectableFields = "selectableFields"
+)
+
+// CustomResourceDefinitionClient is a client which can get and patch Custom Resource Definitions.
+// It is implemented by k8s.ResourceManager.
+type CustomResourceDefinitionClient interface {
+	GetCustomResourceDefinition(ctx context.Context, name string) (*k8s.CustomResourceDefinition, error)
+	PatchCustomResourceDefinition(ctx context.Context, name string, patch resource.PatchRequest) error
+}
+
+var _ CustomResourceDefinitionClient = &k8s.ResourceManager{}
+
+// CRDPresentationDrift describes a single presentation field of a live CRD which differs from the app manifest.
+type CRDPresentationDrift struct {
+	// CRD is the name of the CRD, in the format <plural>.<group>
+	CRD string
+	// Version is the version of the CRD the field belongs to. It is empty for fields which are not version-specific.
+	Version string
+	// Field is the drifted field, one of the CRDPresentationField* constants
+	Field string
+	// Expected is the value of the field in the manifest
+	Expected any
+	// Actual is the value of the field in the live CRD
+	Actual any
+}
+
+// CRDDriftDetectorConfig is the configuration for a CRDDriftDetector
+type CRDDriftDetectorConfig struct {
+	// Interval is the interval at which to check for drift. If zero, the check is only performed once, on startup.
+	Interval time.Duration
+	// AutoRepair, if true, will patch any drifted CRD presentation fields back to the values in the manifest.
+	AutoRepair bool
+	// Plurals is a map of kind name to the plural used for the kind's CRD name.
+	// Kinds which are not present in the map use the default lowercase "<kind>s" plural.
+	Plurals map[string]string
+	// MetricsConfig is the configuration of the drift and repair metrics (see CRDDriftDetector.PrometheusCollectors)
+	MetricsConfig metrics.Config
+}
+
+// CRDDriftDetector compares the presentation fields (categories, shortNames, printer columns, and selectable fields)
+// of live CRDs against an app manifest, and logs and records a metric for any drift it finds
+// (for example, from a CRD being manually edited by a cluster admin). It can optionally repair any drift it finds.
+// CRDDriftDetector implements app.Runnable and metrics.Provider.
+type CRDDriftDetector struct {
+	client   CustomResourceDefinitionClient
+	manifest app.ManifestData
+	config   CRDDriftDetectorConfig
+	drifted  *prometheus.GaugeVec
+	repairs  *prometheus.CounterVec
+}
+
+// NewCRDDriftDetector creates a new CRDDriftDetector which checks the CRDs for all kinds in the provided manifest
+func NewCRDDriftDetector(client CustomResourceDefinitionClient, manifest app.ManifestData, cfg CRDDriftDetectorConfig) (*CRDDriftDetector, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+	return &CRDDriftDetector{
+		client:   client,
+		manifest: manifest,
+		config:   cfg,
+		drifted: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: cfg.MetricsConfig.Namespace,
+			Subsystem: "crd",
+			Name:      "presentation_drift",
+			Help:      "Whether a CRD presentation field differs from the app manifest (1 if drifted, 0 if not).",
+		}, []string{"crd", "version", "field"}),
+		repairs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.MetricsConfig.Namespace,
+			Subsystem: "crd",
+			Name:      "presentation_repairs_total",
+			Help:      "Total number of CRD presentation repairs attempted, by result.",
+		}, []string{"crd", "result"}),
+	}, nil
+}
+
+// Run performs a drift check on startup, and then again every Interval (if non-zero) until ctx is canceled.
+// Errors from individual checks are logged rather than returned, so that a transient API server error does not stop the app.
+func (d *CRDDriftDetector) Run(ctx context.Context) error {
+	d.checkAndLog(ctx)
+	if d.config.Interval <= 0 {
+		<-ctx.Done()
+		return nil
+	}
+	t := time.NewTicker(d.config.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			d.checkAndLog(ctx)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// PrometheusCollectors returns the prometheus metric collectors used by the CRDDriftDetector
+func (d *CRDDriftDetector) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{d.drifted, d.repairs}
+}
+
+// Check compares the live CRD of each kind in the manifest against the manifest, and returns all drifted fields.
+// If AutoRepair is enabled, Check will also patch each drifted CRD. An error getting or repairing the CRD of one kind
+// does not stop the check of the other kinds: Check returns the drift of every CRD it could get,
+// along with the errors for all kinds joined with errors.Join.
+func (d *CRDDriftDetector) Check(ctx context.Context) ([]CRDPresentationDrift, error) {
+	ctx, span := GetTracer().Start(ctx, "crd-drift-check")
+	defer span.End()
+	all := make([]CRDPresentationDrift, 0)
+	errs := make([]error, 0)
+	for _, kind := range d.manifest.Kinds {
+		name := fmt.Sprintf("%s.%s", d.plural(kind.Kind), d.manifest.Group)
+		crd, err := d.client.GetCustomResourceDefinition(ctx, name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to get CRD %s: %w", name, err))
+			continue
+		}
+		drift, patch := diffCRDPresentation(name, kind, crd)
+		d.recordDrift(name, kind, drift)
+		all = append(all, drift...)
+		if len(drift) == 0 || !d.config.AutoRepair {
+			continue
+		}
+		err = d.client.PatchCustomResourceDefinition(ctx, name, patch)
+		if err != nil {
+			d.repairs.WithLabelValues(name, "error").Inc()
+			errs = append(errs, fmt.Errorf("unable to repair CRD %s: %w", name, err))
+			continue
+		}
+		d.repairs.WithLabelValues(name, "success").Inc()
+	}
+	return all, errors.Join(errs...)
+}
+
+func (d *CRDDriftDetector) checkAndLog(ctx context.Context) {
+	logger := logging.FromContext(ctx)
+	drift, err := d.Check(ctx)
+	for _, dr := range drift {
+		logger.Warn("CRD presentation field differs from app manifest", "crd", dr.CRD, "version", dr.Version,
+			"field", dr.Field, "expected", dr.Expected, "actual", dr.Actual, "autoRepair", d.config.AutoRepair)
+	}
+	if err != nil {
+		logger.Error("error checking CRD presentation drift", "error", err)
+	}
+}
+
+func (d *CRDDriftDetector) recordDrift(crd string, kind app.ManifestKind, drift []CRDPresentationDrift) {
+	// Reset all known fields to 0 so that repaired drift is no longer reported
+	d.drifted.WithLabelValues(crd, "", CRDPresentationFieldShortNames).Set(0)
+	d.drifted.WithLabelValues(crd, "", CRDPresentationFieldCategories).Set(0)
+	for _, v := range kind.Versions {
+		d.drifted.WithLabelValues(crd, v.Name, CRDPresentationFieldAdditionalPrinterColumns).Set(0)
+		d.drifted.WithLabelValues(crd, v.Name, CRDPresentationFieldSelectableFields).Set(0)
+	}
+	for _, dr := range drift {
+		d.drifted.WithLabelValues(crd, dr.Version, dr.Field).Set(1)
+	}
+}
+
+func (d *CRDDriftDetector) plural(kind string) string {
+	return kindPlural(d.config.Plurals, kind)
+}
+
+// kindPlural returns the plural of kind from plurals, or the default lowercase "<kind>s" plural if it is not present
+func kindPlural(plurals map[string]string, kind string) string {
+	if p, ok := plurals[kind]; ok && p != "" {
+		return p
+	}
+	return strings.ToLower(kind) + "s"
+}
+
+// diffCRDPresentation returns the presentation fields of crd which differ from kind,
+// and a JSON patch which will update crd to match kind.
+func diffCRDPresentation(name string, kind app.ManifestKind, crd *k8s.CustomResourceDefinition) ([]CRDPresentationDrift, resource.PatchRequest) {
+	drift := make([]CRDPresentationDrift, 0)
+	patch := resource.PatchRequest{
+		Operations: []resource.PatchOperation{{
+			// Guard against overwriting changes made after the CRD was retrieved
+			Operation: resource.PatchOpTest,
+			Path:      "/metadata/resourceVersion",
+			Value:     crd.ResourceVersion,
+		}},
+	}
+	add := func(version, field, path string, expected, actual any) {
+		drift = append(drift, CRDPresentationDrift{
+			CRD:      name,
+			Version:  version,
+			Field:    field,
+			Expected: expected,
+			Actual:   actual,
+		})
+		op := resource.PatchOperation{
+			Operation: resource.PatchOpAdd,
+			Path:      path,
+			Value:     expected,
+		}
+		if reflect.ValueOf(expected).Len() == 0 {
+			op.Operation = resource.PatchOpRemove
+			op.Value = nil
+		}
+		patch.Operations = append(patch.Operations, op)
+	}
+
+	if !slices.Equal(kind.ShortNames, crd.Spec.Names.ShortNames) {
+		add("", CRDPresentationFieldShortNames, "/spec/names/shortNames", kind.ShortNames, crd.Spec.Names.ShortNames)
+	}
+	if !slices.Equal(kind.Categories, crd.Spec.Names.Categories) {
+		add("", CRDPresentationFieldCategories, "/spec/names/categories", kind.Categories, crd.Spec.Names.Categories)
+	}
+	for _, mv := range kind.Versions {
+		for idx, cv := range crd.Spec.Versions {
+			if cv.Name != mv.Name {
+				continue
+			}
+			expectedCols := toCRDPrinterColumns(mv.AdditionalPrinterColumns)
+			if !slices.Equal(mv.AdditionalPrinterColumns, fromCRDPrinterColumns(cv.AdditionalPrinterColumns)) {
+				add(mv.Name, CRDPresentationFieldAdditionalPrinterColumns,
+					fmt.Sprintf("/spec/versions/%d/additionalPrinterColumns", idx), expectedCols, cv.AdditionalPrinterColumns)
+			}
+			expectedFields := toCRDSelectableFields(mv.SelectableFields)
+			if !slices.Equal(expectedFields, cv.SelectableFields) {
+				add(mv.Name, CRDPresentationFieldSelectableFields,
+					fmt.Sprintf("/spec/versions/%d/selectableFields", idx), expectedFields, cv.SelectableFields)
+			}
+			break
+		}
+	}
+	return drift, patch
+}
+
+func toCRDSelectableFields(fields []string) []k8s.CustomResourceDefinitionSelectableField {
+	if len(fields) == 0 {
+		return nil
+	}
+	sf := make([]k8s.CustomResourceDefinitionSelectableField, 0, len(fields))
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if field[0] != '.' {
+			field = "." + field
+		}
+		sf = append(sf, k8s.CustomResourceDefinitionSelectableField{
+			JSONPath: field,
+		})
+	}
+	return sf
+}
+
+func toCRDPrinterColumns(columns []app.AdditionalPrinterColumn) []k8s.CustomResourceDefinitionAdditionalPrinterColumn {
+	if len(columns) == 0 {
+		return nil
+	}
+	c := make([]k8s.CustomResourceDefinitionAdditionalPrinterColumn, len(columns))
+	for i, col := range columns {
+		c[i] = k8s.CustomResourceDefinitionAdditionalPrinterColumn{
+			Name:     col.Name,
+			Type:     col.Type,
+			JSONPath: col.JSONPath,
+		}
+		if col.Format != "" {
+			c[i].Format = &col.Format
+		}
+		if col.Description != "" {
+			c[i].Description = &col.Description
+		}
+		if col.Priority != 0 {
+			c[i].Priority = &col.Priority
+		}
+	}
+	return c
+}
+
+func fromCRDPrinterColumns(columns []k8s.CustomResourceDefinitionAdditionalPrinterColumn) []app.AdditionalPrinterColumn {
+	if len(columns) == 0 {
+		return nil
+	}
+	c := make([]app.AdditionalPrinterColumn, len(columns))
+	for i, col := range columns {
+		c[i] = app.AdditionalPrinterColumn{
+			Name:     col.Name,
+			Type:     col.Type,
+			JSONPath: col.JSONPath,
+		}
+		if col.Format != nil {
+			c[i].Format = *col.Format
+		}
+		if col.Description != nil {
+			c[i].Description = *col.Description
+		}
+		if col.Priority != nil {
+			c[i].Priority = *col.Priority
+		}
+	}
+	return c
+}
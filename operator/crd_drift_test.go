@@ -0,0 +1,162 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/k8s"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestCRDDriftDetector_Check(t *testing.T) {
+	manifest := app.ManifestData{
+		Group: "foo.ext.grafana.com",
+		Kinds: []app.ManifestKind{{
+			Kind:       "Foo",
+			ShortNames: []string{"f"},
+			Categories: []string{"all"},
+			Versions: []app.ManifestKindVersion{{
+				Name:             "v1",
+				SelectableFields: []string{"spec.bar"},
+				AdditionalPrinterColumns: []app.AdditionalPrinterColumn{{
+					Name:     "BAR",
+					Type:     "string",
+					JSONPath: ".spec.bar",
+				}},
+			}},
+		}},
+	}
+	inSync := func() *k8s.CustomResourceDefinition {
+		return &k8s.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "foos.foo.ext.grafana.com",
+				ResourceVersion: "1",
+			},
+			Spec: k8s.CustomResourceDefinitionSpec{
+				Names: k8s.CustomResourceDefinitionSpecNames{
+					Kind:       "Foo",
+					Plural:     "foos",
+					ShortNames: []string{"f"},
+					Categories: []string{"all"},
+				},
+				Versions: []k8s.CustomResourceDefinitionSpecVersion{{
+					Name: "v1",
+					SelectableFields: []k8s.CustomResourceDefinitionSelectableField{{
+						JSONPath: ".spec.bar",
+					}},
+					AdditionalPrinterColumns: []k8s.CustomResourceDefinitionAdditionalPrinterColumn{{
+						Name:     "BAR",
+						Type:     "string",
+						JSONPath: ".spec.bar",
+					}},
+				}},
+			},
+		}
+	}
+
+	t.Run("no drift", func(t *testing.T) {
+		client := newTestCRDClient(t, inSync())
+		d, err := NewCRDDriftDetector(client, manifest, CRDDriftDetectorConfig{AutoRepair: true})
+		require.Nil(t, err)
+		drift, err := d.Check(context.Background())
+		require.Nil(t, err)
+		assert.Empty(t, drift)
+		assert.Empty(t, client.patches())
+	})
+
+	t.Run("drift without repair", func(t *testing.T) {
+		crd := inSync()
+		crd.Spec.Names.ShortNames = []string{"f", "fo"}
+		crd.Spec.Versions[0].AdditionalPrinterColumns = nil
+		client := newTestCRDClient(t, crd)
+		d, err := NewCRDDriftDetector(client, manifest, CRDDriftDetectorConfig{})
+		require.Nil(t, err)
+		drift, err := d.Check(context.Background())
+		require.Nil(t, err)
+		require.Len(t, drift, 2)
+		assert.Equal(t, CRDPresentationFieldShortNames, drift[0].Field)
+		assert.Equal(t, "", drift[0].Version)
+		assert.Equal(t, CRDPresentationFieldAdditionalPrinterColumns, drift[1].Field)
+		assert.Equal(t, "v1", drift[1].Version)
+		assert.Empty(t, client.patches())
+	})
+
+	t.Run("drift with repair", func(t *testing.T) {
+		crd := inSync()
+		crd.Spec.Names.Categories = nil
+		crd.Spec.Versions[0].SelectableFields = nil
+		client := newTestCRDClient(t, crd)
+		d, err := NewCRDDriftDetector(client, manifest, CRDDriftDetectorConfig{AutoRepair: true})
+		require.Nil(t, err)
+		drift, err := d.Check(context.Background())
+		require.Nil(t, err)
+		require.Len(t, drift, 2)
+		require.Len(t, client.patches(), 1)
+		ops := client.patches()[0].Operations
+		require.Len(t, ops, 3)
+		assert.Equal(t, resource.PatchOpTest, ops[0].Operation)
+		assert.Equal(t, "1", ops[0].Value)
+		assert.Equal(t, resource.PatchOpAdd, ops[1].Operation)
+		assert.Equal(t, "/spec/names/categories", ops[1].Path)
+		assert.Equal(t, []string{"all"}, ops[1].Value)
+		assert.Equal(t, "/spec/versions/0/selectableFields", ops[2].Path)
+
+		// The repaired CRD no longer drifts
+		drift, err = d.Check(context.Background())
+		require.Nil(t, err)
+		assert.Empty(t, drift)
+	})
+
+	t.Run("extra fields are removed on repair", func(t *testing.T) {
+		m := app.ManifestData{
+			Group: "foo.ext.grafana.com",
+			Kinds: []app.ManifestKind{{Kind: "Foo", Versions: []app.ManifestKindVersion{{Name: "v1"}}}},
+		}
+		crd := inSync()
+		crd.Spec.Versions[0].SelectableFields = nil
+		crd.Spec.Versions[0].AdditionalPrinterColumns = nil
+		client := newTestCRDClient(t, crd)
+		d, err := NewCRDDriftDetector(client, m, CRDDriftDetectorConfig{AutoRepair: true})
+		require.Nil(t, err)
+		drift, err := d.Check(context.Background())
+		require.Nil(t, err)
+		require.Len(t, drift, 2)
+		ops := client.patches()[0].Operations
+		require.Len(t, ops, 3)
+		assert.Equal(t, resource.PatchOpRemove, ops[1].Operation)
+		assert.Equal(t, resource.PatchOpRemove, ops[2].Operation)
+	})
+
+	t.Run("custom plural and get error", func(t *testing.T) {
+		client := newTestCRDClient(t, inSync())
+		d, err := NewCRDDriftDetector(client, manifest, CRDDriftDetectorConfig{
+			Plurals: map[string]string{"Foo": "fooes"},
+		})
+		require.Nil(t, err)
+		_, err = d.Check(context.Background())
+		assert.True(t, resource.IsNotFound(err), err)
+	})
+
+	t.Run("errors do not stop other kinds", func(t *testing.T) {
+		m := app.ManifestData{
+			Group: manifest.Group,
+			Kinds: []app.ManifestKind{{Kind: "Bar"}, manifest.Kinds[0], {Kind: "Baz"}},
+		}
+		crd := inSync()
+		crd.Spec.Names.ShortNames = nil
+		client := newTestCRDClient(t, crd)
+		d, err := NewCRDDriftDetector(client, m, CRDDriftDetectorConfig{})
+		require.Nil(t, err)
+		drift, err := d.Check(context.Background())
+		assert.True(t, resource.IsNotFound(err), err)
+		assert.ErrorContains(t, err, "bars.foo.ext.grafana.com")
+		assert.ErrorContains(t, err, "bazs.foo.ext.grafana.com")
+		require.Len(t, drift, 1)
+		assert.Equal(t, CRDPresentationFieldShortNames, drift[0].Field)
+	})
+}
@@ -0,0 +1,68 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// DeadLetterEventReason is the reason of the Kubernetes Events emitted by NewKubernetesEventDeadLetterHandler
+const DeadLetterEventReason = "RetriesExhausted"
+
+// DeadLetter is an event which an InformerController stopped retrying, because its RetryPolicy returned false
+// for the most recent error returned by the ResourceWatcher or Reconciler call.
+type DeadLetter struct {
+	// Action is the action of the event
+	Action ResourceAction
+	// Object is the object of the event. For update events, it is the updated object.
+	Object resource.Object
+	// Errors are the errors returned by every attempt of the call, starting with the first call.
+	// The last error is the one the RetryPolicy declined to retry.
+	Errors []error
+	// FirstFailure is the time of the first failed attempt
+	FirstFailure time.Time
+	// RequestID is the request ID of the event (see RequestIDFromContext)
+	RequestID string
+}
+
+// Err returns the last error of the DeadLetter, or nil if it has no errors
+func (d DeadLetter) Err() error {
+	if len(d.Errors) == 0 {
+		return nil
+	}
+	return d.Errors[len(d.Errors)-1]
+}
+
+// DeadLetterHandler is a function which is called with each event an InformerController has stopped retrying.
+// It is called synchronously, by the goroutine which processes retries, so it should return promptly.
+type DeadLetterHandler func(ctx context.Context, letter DeadLetter)
+
+// NewKubernetesEventDeadLetterHandler returns a DeadLetterHandler which emits a Warning Kubernetes Event
+// for the object of each DeadLetter, with the reason DeadLetterEventReason and the number of attempts and last error
+// as its message, so that failures are visible with `kubectl describe` or `kubectl get events`.
+// Events for cluster-scoped objects are created in the default namespace.
+// component is the source component of the Events, and is typically the app name.
+// Events are recorded with a KubernetesEventRecorder, so they are sent in the background and repeated Events are aggregated.
+func NewKubernetesEventDeadLetterHandler(client corev1client.EventsGetter, component string) (DeadLetterHandler, error) {
+	recorder, err := NewKubernetesEventRecorder(client, component)
+	if err != nil {
+		return nil, err
+	}
+	return EventRecorderDeadLetterHandler(recorder), nil
+}
+
+// EventRecorderDeadLetterHandler returns a DeadLetterHandler which records a Warning event with recorder
+// for the object of each DeadLetter, like NewKubernetesEventDeadLetterHandler.
+func EventRecorderDeadLetterHandler(recorder EventRecorder) DeadLetterHandler {
+	return func(ctx context.Context, letter DeadLetter) {
+		message := fmt.Sprintf("%s failed after %d attempt(s)", letter.Action, len(letter.Errors))
+		if err := letter.Err(); err != nil {
+			message = fmt.Sprintf("%s: %s", message, err.Error())
+		}
+		recorder.Event(ctx, letter.Object, EventTypeWarning, DeadLetterEventReason, message)
+	}
+}
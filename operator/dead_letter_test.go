@@ -0,0 +1,111 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestInformerController_DeadLetterHandler(t *testing.T) {
+	letters := make(chan DeadLetter, 10)
+	c := NewInformerController(InformerControllerConfig{
+		RetryPolicy: func(_ error, attempt int) (bool, time.Duration) {
+			return attempt < 2, 0
+		},
+		DeadLetterHandler: func(ctx context.Context, letter DeadLetter) {
+			assert.Equal(t, letter.RequestID, RequestIDFromContext(ctx))
+			letters <- letter
+		},
+	})
+	c.retryTickerInterval = 10 * time.Millisecond
+	inf := &testInformer{}
+	require.Nil(t, c.AddInformer(inf, "foo"))
+	calls := 0
+	require.Nil(t, c.AddReconciler(&SimpleReconciler{
+		ReconcileFunc: func(context.Context, ReconcileRequest) (ReconcileResult, error) {
+			calls++
+			return ReconcileResult{}, fmt.Errorf("attempt %d", calls)
+		},
+	}, "foo"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+	obj := newListerTestObject("ns", "a")
+	inf.FireAdd(context.Background(), obj)
+
+	select {
+	case letter := <-letters:
+		assert.Equal(t, ResourceActionCreate, letter.Action)
+		assert.Equal(t, obj, letter.Object)
+		assert.NotEmpty(t, letter.RequestID)
+		// The first call, and two retries
+		assert.Equal(t, []error{errors.New("attempt 1"), errors.New("attempt 2"), errors.New("attempt 3")}, letter.Errors)
+		assert.EqualError(t, letter.Err(), "attempt 3")
+	case <-time.After(time.Second):
+		t.Fatal("expected a dead letter")
+	}
+
+	t.Run("not retried", func(t *testing.T) {
+		c.RetryPolicy = func(error, int) (bool, time.Duration) {
+			return false, 0
+		}
+		calls = 0
+		inf.FireUpdate(context.Background(), obj, obj)
+		select {
+		case letter := <-letters:
+			assert.Equal(t, ResourceActionUpdate, letter.Action)
+			assert.Equal(t, []error{errors.New("attempt 1")}, letter.Errors)
+		default:
+			t.Fatal("expected a dead letter")
+		}
+	})
+}
+
+func TestNewKubernetesEventDeadLetterHandler(t *testing.T) {
+	_, err := NewKubernetesEventDeadLetterHandler(nil, "test-app")
+	assert.EqualError(t, err, "client cannot be nil")
+
+	client := fake.NewSimpleClientset()
+	handler, err := NewKubernetesEventDeadLetterHandler(client.CoreV1(), "test-app")
+	require.Nil(t, err)
+	obj := newListerTestObject("ns", "foo")
+	obj.SetUID("abc")
+	obj.SetResourceVersion("1")
+	obj.SetGroupVersionKind(schema.GroupVersionKind{Group: "foo.grafana.app", Version: "v1", Kind: "Foo"})
+	handler(context.Background(), DeadLetter{
+		Action: ResourceActionCreate,
+		Object: obj,
+		Errors: []error{errors.New("first"), errors.New("I AM ERROR")},
+	})
+
+	// Events are sent in the background
+	var events *corev1.EventList
+	require.Eventually(t, func() bool {
+		events, err = client.CoreV1().Events("ns").List(context.Background(), metav1.ListOptions{})
+		require.Nil(t, err)
+		return len(events.Items) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	event := events.Items[0]
+	assert.Equal(t, DeadLetterEventReason, event.Reason)
+	assert.Equal(t, corev1.EventTypeWarning, event.Type)
+	assert.Equal(t, "CREATE failed after 2 attempt(s): I AM ERROR", event.Message)
+	assert.Equal(t, "test-app", event.Source.Component)
+	assert.Equal(t, corev1.ObjectReference{
+		APIVersion:      "foo.grafana.app/v1",
+		Kind:            "Foo",
+		Namespace:       "ns",
+		Name:            "foo",
+		UID:             "abc",
+		ResourceVersion: "1",
+	}, event.InvolvedObject)
+}
@@ -0,0 +1,356 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/logging"
+)
+
+const (
+	defaultDependencyInitialBackoff         = time.Second
+	defaultDependencyMaxBackoff             = 30 * time.Second
+	defaultForeignKindInformerCheckInterval = 30 * time.Second
+)
+
+// ErrDependencyNotServed is returned when a kind the app depends on is not served by the API server
+var ErrDependencyNotServed = errors.New("required kind is not served by the API server")
+
+// ServedKindChecker checks whether a kind, identified by its group and plural resource name, is served by the API server.
+type ServedKindChecker interface {
+	IsServed(ctx context.Context, group, resource string) (bool, error)
+}
+
+// ServedKindCheckerFunc is a function which implements ServedKindChecker
+type ServedKindCheckerFunc func(ctx context.Context, group, resource string) (bool, error)
+
+// IsServed calls the function
+func (f ServedKindCheckerFunc) IsServed(ctx context.Context, group, resource string) (bool, error) {
+	return f(ctx, group, resource)
+}
+
+// NewDiscoveryServedKindChecker returns a ServedKindChecker which uses the API server's discovery endpoints
+// to check whether any version of a kind is served.
+func NewDiscoveryServedKindChecker(client discovery.DiscoveryInterface) ServedKindChecker {
+	return ServedKindCheckerFunc(func(_ context.Context, group, resource string) (bool, error) {
+		groups, err := client.ServerGroups()
+		if err != nil {
+			return false, err
+		}
+		for _, g := range groups.Groups {
+			if g.Name != group {
+				continue
+			}
+			for _, v := range g.Versions {
+				resources, err := client.ServerResourcesForGroupVersion(v.GroupVersion)
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				if err != nil {
+					return false, err
+				}
+				for _, r := range resources.APIResources {
+					if r.Name == resource {
+						return true, nil
+					}
+				}
+			}
+		}
+		return false, nil
+	})
+}
+
+// DependencyGateConfig is the configuration for a DependencyGate
+type DependencyGateConfig struct {
+	// Wait, if true, makes DependencyGate.Wait retry with an exponential backoff until all required kinds are served.
+	// If false, Wait returns an error immediately if any required kind is not served.
+	Wait bool
+	// Timeout is the maximum time Wait will wait for required kinds to be served. If zero, it waits until the context is canceled.
+	Timeout time.Duration
+	// InitialBackoff is the initial time between checks when waiting. Defaults to one second.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum time between checks when waiting. Defaults to 30 seconds.
+	MaxBackoff time.Duration
+}
+
+// DependencyGate verifies that the kinds owned by other apps which an app depends on are served by the API server.
+// Dependencies are the app.KindPermission entries in the app's ExtraPermissions which have Required set.
+// It should be created with NewDependencyGate.
+type DependencyGate struct {
+	checker      ServedKindChecker
+	dependencies []app.KindPermission
+	config       DependencyGateConfig
+}
+
+// NewDependencyGate returns a new DependencyGate for the required kinds in permissions, which may be nil.
+func NewDependencyGate(checker ServedKindChecker, permissions *app.Permissions, cfg DependencyGateConfig) (*DependencyGate, error) {
+	if checker == nil {
+		return nil, errors.New("checker cannot be nil")
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaultDependencyInitialBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultDependencyMaxBackoff
+	}
+	deps := make([]app.KindPermission, 0)
+	if permissions != nil {
+		for _, p := range permissions.AccessKinds {
+			if p.Required {
+				deps = append(deps, p)
+			}
+		}
+	}
+	return &DependencyGate{
+		checker:      checker,
+		dependencies: deps,
+		config:       cfg,
+	}, nil
+}
+
+// Dependencies returns the required kinds the DependencyGate checks
+func (g *DependencyGate) Dependencies() []app.KindPermission {
+	return g.dependencies
+}
+
+// Check returns the required kinds which are not currently served
+func (g *DependencyGate) Check(ctx context.Context) ([]app.KindPermission, error) {
+	missing := make([]app.KindPermission, 0)
+	for _, dep := range g.dependencies {
+		served, err := g.checker.IsServed(ctx, dep.Group, dep.Resource)
+		if err != nil {
+			return nil, fmt.Errorf("unable to check whether %s.%s is served: %w", dep.Resource, dep.Group, err)
+		}
+		if !served {
+			missing = append(missing, dep)
+		}
+	}
+	return missing, nil
+}
+
+// Wait blocks until all required kinds are served. If DependencyGateConfig.Wait is false, it checks only once.
+// If required kinds are still not served when Wait gives up, it returns an error wrapping ErrDependencyNotServed.
+func (g *DependencyGate) Wait(ctx context.Context) error {
+	if len(g.dependencies) == 0 {
+		return nil
+	}
+	if g.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.config.Timeout)
+		defer cancel()
+	}
+	backoff := g.config.InitialBackoff
+	for {
+		missing, err := g.Check(ctx)
+		if err == nil && len(missing) == 0 {
+			return nil
+		}
+		if !g.config.Wait {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: %s", ErrDependencyNotServed, formatKindPermissions(missing))
+		}
+		if err != nil {
+			logging.FromContext(ctx).Warn("unable to check required kinds, retrying", "error", err, "backoff", backoff)
+		} else {
+			logging.FromContext(ctx).Info("waiting for required kinds to be served", "kinds", formatKindPermissions(missing), "backoff", backoff)
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: %s", ErrDependencyNotServed, formatKindPermissions(missing))
+		}
+		backoff = min(backoff*2, g.config.MaxBackoff)
+	}
+}
+
+func formatKindPermissions(perms []app.KindPermission) string {
+	names := make([]string, len(perms))
+	for i, p := range perms {
+		names[i] = p.Resource + "." + p.Group
+	}
+	return strings.Join(names, ", ")
+}
+
+// ForeignKindInformerConfig is the configuration for a ForeignKindInformer
+type ForeignKindInformerConfig struct {
+	// Group is the group of the foreign kind
+	Group string
+	// Resource is the plural resource name of the foreign kind
+	Resource string
+	// NewInformer returns a new Informer for the foreign kind. A new Informer is created each time the kind
+	// is served again after not being served, as informers cannot be restarted once stopped.
+	NewInformer func() (Informer, error)
+	// CheckInterval is the interval at which to check whether the kind is served. Defaults to 30 seconds.
+	CheckInterval time.Duration
+	// OnPause, if non-nil, is called when the kind stops being served and the informer is stopped.
+	OnPause func(context.Context)
+	// OnResume, if non-nil, is called when the kind becomes served and the informer is started,
+	// including the first time it is started.
+	OnResume func(context.Context)
+}
+
+// ForeignKindInformer is an Informer for a kind owned by another app, which degrades gracefully when the kind's CRD
+// is removed (or not yet installed). While the kind is not served, the underlying informer is stopped and
+// the ForeignKindInformer is paused, so event handlers (and any dependents which check Paused) receive no events
+// instead of the informer repeatedly failing its list/watch.
+// It should be created with NewForeignKindInformer.
+type ForeignKindInformer struct {
+	checker  ServedKindChecker
+	config   ForeignKindInformerConfig
+	handlers []ResourceWatcher
+	current  Informer
+	paused   bool
+	mux      sync.RWMutex
+}
+
+// NewForeignKindInformer returns a new ForeignKindInformer which uses checker to check whether the foreign kind is served
+func NewForeignKindInformer(checker ServedKindChecker, cfg ForeignKindInformerConfig) (*ForeignKindInformer, error) {
+	if checker == nil {
+		return nil, errors.New("checker cannot be nil")
+	}
+	if cfg.NewInformer == nil {
+		return nil, errors.New("NewInformer cannot be nil")
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = defaultForeignKindInformerCheckInterval
+	}
+	return &ForeignKindInformer{
+		checker:  checker,
+		config:   cfg,
+		handlers: make([]ResourceWatcher, 0),
+		// Paused until Run finds the kind is served
+		paused: true,
+	}, nil
+}
+
+// AddEventHandler adds a ResourceWatcher as an event handler for the foreign kind.
+// Handlers are added to each underlying informer created when the kind is served.
+func (f *ForeignKindInformer) AddEventHandler(handler ResourceWatcher) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.handlers = append(f.handlers, handler)
+	if f.current != nil {
+		return f.current.AddEventHandler(handler)
+	}
+	return nil
+}
+
+// Paused returns true if the foreign kind is not currently served, and the underlying informer is stopped
+func (f *ForeignKindInformer) Paused() bool {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	return f.paused
+}
+
+// Run checks whether the foreign kind is served every CheckInterval, running the underlying informer while it is,
+// and pausing while it is not. It blocks until ctx is canceled, or the underlying informer returns an error.
+func (f *ForeignKindInformer) Run(ctx context.Context) error {
+	var (
+		cancel  context.CancelFunc
+		errs    = make(chan error, 1)
+		stopped = make(chan struct{})
+	)
+	stop := func() {
+		if cancel == nil {
+			return
+		}
+		cancel()
+		<-stopped
+		cancel = nil
+	}
+	defer stop()
+
+	check := func() error {
+		served, err := f.checker.IsServed(ctx, f.config.Group, f.config.Resource)
+		if err != nil {
+			// Don't change state on a failed check, as discovery errors are often transient
+			logging.FromContext(ctx).Warn("unable to check whether foreign kind is served", "group", f.config.Group, "resource", f.config.Resource, "error", err)
+			return nil
+		}
+		switch {
+		case served && cancel == nil:
+			inf, err := f.newInformer()
+			if err != nil {
+				return err
+			}
+			var infCtx context.Context
+			infCtx, cancel = context.WithCancel(ctx)
+			stopped = make(chan struct{})
+			go func() {
+				defer close(stopped)
+				if err := inf.Run(infCtx); err != nil {
+					errs <- err
+				}
+			}()
+			if f.setPaused(false) && f.config.OnResume != nil {
+				f.config.OnResume(ctx)
+			}
+		case !served && cancel != nil:
+			logging.FromContext(ctx).Warn("foreign kind is no longer served, pausing informer", "group", f.config.Group, "resource", f.config.Resource)
+			stop()
+			f.mux.Lock()
+			f.current = nil
+			f.mux.Unlock()
+			if f.setPaused(true) && f.config.OnPause != nil {
+				f.config.OnPause(ctx)
+			}
+		}
+		return nil
+	}
+
+	if err := check(); err != nil {
+		return err
+	}
+	t := time.NewTicker(f.config.CheckInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if err := check(); err != nil {
+				return err
+			}
+		case err := <-errs:
+			return err
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (f *ForeignKindInformer) newInformer() (Informer, error) {
+	inf, err := f.config.NewInformer()
+	if err != nil {
+		return nil, err
+	}
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	for _, handler := range f.handlers {
+		if err := inf.AddEventHandler(handler); err != nil {
+			return nil, err
+		}
+	}
+	f.current = inf
+	return inf, nil
+}
+
+// setPaused sets the paused state, and returns true if it changed
+func (f *ForeignKindInformer) setPaused(paused bool) bool {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	changed := f.paused != paused
+	f.paused = paused
+	return changed
+}
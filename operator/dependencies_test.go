@@ -0,0 +1,180 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/grafana/grafana-app-sdk/app"
+)
+
+func TestNewDiscoveryServedKindChecker(t *testing.T) {
+	disc := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{
+		Resources: []*metav1.APIResourceList{{
+			GroupVersion: "foo.bar/v1",
+			APIResources: []metav1.APIResource{{Name: "foos", Kind: "Foo"}},
+		}},
+	}}
+	checker := NewDiscoveryServedKindChecker(disc)
+	served, err := checker.IsServed(context.Background(), "foo.bar", "foos")
+	require.Nil(t, err)
+	assert.True(t, served)
+	served, err = checker.IsServed(context.Background(), "foo.bar", "bars")
+	require.Nil(t, err)
+	assert.False(t, served)
+	served, err = checker.IsServed(context.Background(), "bar.baz", "foos")
+	require.Nil(t, err)
+	assert.False(t, served)
+}
+
+func TestDependencyGate_Wait(t *testing.T) {
+	permissions := &app.Permissions{
+		AccessKinds: []app.KindPermission{{
+			Group:    "foo.bar",
+			Resource: "foos",
+			Required: true,
+		}, {
+			Group:    "foo.bar",
+			Resource: "bars",
+		}},
+	}
+
+	t.Run("no wait", func(t *testing.T) {
+		checked := make([]string, 0)
+		gate, err := NewDependencyGate(ServedKindCheckerFunc(func(_ context.Context, _, resource string) (bool, error) {
+			checked = append(checked, resource)
+			return false, nil
+		}), permissions, DependencyGateConfig{})
+		require.Nil(t, err)
+		err = gate.Wait(context.Background())
+		assert.ErrorIs(t, err, ErrDependencyNotServed)
+		assert.EqualError(t, err, "required kind is not served by the API server: foos.foo.bar")
+		// Kinds which are not required are not checked
+		assert.Equal(t, []string{"foos"}, checked)
+	})
+
+	t.Run("wait until served", func(t *testing.T) {
+		calls := 0
+		gate, err := NewDependencyGate(ServedKindCheckerFunc(func(context.Context, string, string) (bool, error) {
+			calls++
+			if calls == 1 {
+				return false, errors.New("discovery unavailable")
+			}
+			return calls > 2, nil
+		}), permissions, DependencyGateConfig{
+			Wait:           true,
+			InitialBackoff: time.Millisecond,
+		})
+		require.Nil(t, err)
+		assert.Nil(t, gate.Wait(context.Background()))
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("wait timeout", func(t *testing.T) {
+		gate, err := NewDependencyGate(ServedKindCheckerFunc(func(context.Context, string, string) (bool, error) {
+			return false, nil
+		}), permissions, DependencyGateConfig{
+			Wait:           true,
+			Timeout:        20 * time.Millisecond,
+			InitialBackoff: time.Millisecond,
+		})
+		require.Nil(t, err)
+		assert.ErrorIs(t, gate.Wait(context.Background()), ErrDependencyNotServed)
+	})
+
+	t.Run("no dependencies", func(t *testing.T) {
+		gate, err := NewDependencyGate(ServedKindCheckerFunc(func(context.Context, string, string) (bool, error) {
+			return false, nil
+		}), nil, DependencyGateConfig{})
+		require.Nil(t, err)
+		assert.Nil(t, gate.Wait(context.Background()))
+	})
+}
+
+func TestForeignKindInformer_Run(t *testing.T) {
+	served := atomic.Bool{}
+	served.Store(true)
+	created := make([]*testForeignInformer, 0)
+	createdMux := sync.Mutex{}
+	paused := make(chan struct{}, 1)
+	resumed := make(chan struct{}, 1)
+	inf, err := NewForeignKindInformer(ServedKindCheckerFunc(func(context.Context, string, string) (bool, error) {
+		return served.Load(), nil
+	}), ForeignKindInformerConfig{
+		Group:    "foo.bar",
+		Resource: "foos",
+		NewInformer: func() (Informer, error) {
+			createdMux.Lock()
+			defer createdMux.Unlock()
+			i := &testForeignInformer{running: &atomic.Bool{}}
+			created = append(created, i)
+			return i, nil
+		},
+		CheckInterval: 5 * time.Millisecond,
+		OnPause: func(context.Context) {
+			paused <- struct{}{}
+		},
+		OnResume: func(context.Context) {
+			resumed <- struct{}{}
+		},
+	})
+	require.Nil(t, err)
+	require.Nil(t, inf.AddEventHandler(&SimpleWatcher{}))
+	assert.True(t, inf.Paused())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go inf.Run(ctx)
+
+	waitFor := func(ch chan struct{}) {
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for callback")
+		}
+	}
+	waitFor(resumed)
+	assert.False(t, inf.Paused())
+
+	served.Store(false)
+	waitFor(paused)
+	assert.True(t, inf.Paused())
+
+	served.Store(true)
+	waitFor(resumed)
+	assert.False(t, inf.Paused())
+
+	createdMux.Lock()
+	defer createdMux.Unlock()
+	require.Len(t, created, 2)
+	// The first informer is stopped when the kind stops being served, and each informer has the event handler
+	assert.False(t, created[0].running.Load())
+	assert.Len(t, created[0].handlers, 1)
+	assert.Len(t, created[1].handlers, 1)
+}
+
+type testForeignInformer struct {
+	handlers []ResourceWatcher
+	running  *atomic.Bool
+}
+
+func (i *testForeignInformer) AddEventHandler(handler ResourceWatcher) error {
+	i.handlers = append(i.handlers, handler)
+	return nil
+}
+
+func (i *testForeignInformer) Run(ctx context.Context) error {
+	i.running.Store(true)
+	<-ctx.Done()
+	i.running.Store(false)
+	return nil
+}
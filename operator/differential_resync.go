@@ -0,0 +1,172 @@
+package operator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// DesiredStateHashAnnotation is the annotation a DriftDetectingReconciler sets to the hash of the desired state
+// of an object (see DesiredStateHashFunc) after it has been successfully reconciled.
+const DesiredStateHashAnnotation = "grafana.app/desired-state-hash"
+
+// DesiredStateHashFunc returns a hash of the state of an object that a Reconciler acts on.
+// When the hash of an object is unchanged since it was last reconciled, the object has not drifted.
+type DesiredStateHashFunc func(obj resource.Object) (string, error)
+
+// DefaultDesiredStateHash is a DesiredStateHashFunc which returns the hex-encoded SHA-256 hash of the JSON
+// of the spec and subresources (such as status) of the object.
+func DefaultDesiredStateHash(obj resource.Object) (string, error) {
+	state, err := json.Marshal(map[string]any{
+		"spec":         obj.GetSpec(),
+		"subresources": obj.GetSubresources(),
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(state)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// DriftDetectingReconciler wraps a Reconciler, and skips reconciling objects which have not drifted
+// since they were last reconciled, when the informer cache is periodically resynced.
+// After each successful reconcile of an object, the hash of its desired state (from HashFunc) is stored on the object
+// in the DesiredStateHashAnnotation annotation. The Predicate returned by Predicate() filters out update events
+// from cache resyncs (where the resource version is unchanged) for objects whose stored hash matches their current hash,
+// as well as the update events caused by storing the hash. The Predicate must be attached to the reconciler
+// (such as with ReconcilerOptions.Predicates) for resyncs to be skipped.
+// It should be instantiated with NewDriftDetectingReconciler.
+type DriftDetectingReconciler struct {
+	Reconciler Reconciler
+	// HashFunc is the function used to hash the desired state of objects. Defaults to DefaultDesiredStateHash.
+	HashFunc DesiredStateHashFunc
+	client   PatchClient
+}
+
+// NewDriftDetectingReconciler returns a new DriftDetectingReconciler which wraps reconciler,
+// using client to store the desired state hash annotation on reconciled objects.
+func NewDriftDetectingReconciler(reconciler Reconciler, client PatchClient) (*DriftDetectingReconciler, error) {
+	if reconciler == nil {
+		return nil, fmt.Errorf("reconciler cannot be nil")
+	}
+	if client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+	return &DriftDetectingReconciler{
+		Reconciler: reconciler,
+		HashFunc:   DefaultDesiredStateHash,
+		client:     client,
+	}, nil
+}
+
+// Reconcile calls the wrapped Reconciler, and, if it succeeds without requesting a requeue,
+// stores the desired state hash of the request's object on the object. Failing to store the hash is logged but
+// does not fail the reconcile, as the object will be reconciled again at the next resync.
+func (d *DriftDetectingReconciler) Reconcile(ctx context.Context, req ReconcileRequest) (ReconcileResult, error) {
+	res, err := d.Reconciler.Reconcile(ctx, req)
+	if err != nil || res.RequeueAfter != nil || req.Object == nil ||
+		req.Action == ReconcileActionDeleted || req.Object.GetDeletionTimestamp() != nil {
+		return res, err
+	}
+	hash, hashErr := d.hash(req.Object)
+	if hashErr != nil {
+		logging.FromContext(ctx).Warn("unable to hash desired state of object", "namespace", req.Object.GetNamespace(), "name", req.Object.GetName(), "error", hashErr)
+		return res, nil
+	}
+	if req.Object.GetAnnotations()[DesiredStateHashAnnotation] == hash {
+		return res, nil
+	}
+	if patchErr := d.storeHash(ctx, req.Object, hash); patchErr != nil {
+		logging.FromContext(ctx).Warn("unable to store desired state hash of object", "namespace", req.Object.GetNamespace(), "name", req.Object.GetName(), "error", patchErr)
+	}
+	return res, nil
+}
+
+// Wrap replaces the wrapped Reconciler with reconciler
+func (d *DriftDetectingReconciler) Wrap(reconciler Reconciler) {
+	d.Reconciler = reconciler
+}
+
+// Predicate returns a Predicate which filters out update events for objects which have not drifted since they were
+// last reconciled by the DriftDetectingReconciler: periodic resyncs of objects with an unchanged resource version,
+// and updates which only changed the desired state hash annotation. Add and delete events are always accepted,
+// as are update events for objects without a stored hash.
+func (d *DriftDetectingReconciler) Predicate() Predicate {
+	return Predicate{
+		UpdateFunc: func(oldObj, newObj resource.Object) bool {
+			if oldObj == nil {
+				return true
+			}
+			stored, ok := newObj.GetAnnotations()[DesiredStateHashAnnotation]
+			if !ok {
+				return true
+			}
+			hash, err := d.hash(newObj)
+			if err != nil || hash != stored {
+				return true
+			}
+			if oldObj.GetResourceVersion() == newObj.GetResourceVersion() {
+				return false
+			}
+			return !d.onlyHashStored(oldObj, newObj)
+		},
+	}
+}
+
+// onlyHashStored returns true if the only change from oldObj to newObj was storing the desired state hash annotation
+func (d *DriftDetectingReconciler) onlyHashStored(oldObj, newObj resource.Object) bool {
+	if oldObj.GetAnnotations()[DesiredStateHashAnnotation] == newObj.GetAnnotations()[DesiredStateHashAnnotation] {
+		return false
+	}
+	if oldObj.GetGeneration() != newObj.GetGeneration() ||
+		(oldObj.GetDeletionTimestamp() == nil) != (newObj.GetDeletionTimestamp() == nil) ||
+		!slices.Equal(oldObj.GetFinalizers(), newObj.GetFinalizers()) ||
+		!maps.Equal(oldObj.GetLabels(), newObj.GetLabels()) {
+		return false
+	}
+	oldAnnotations := maps.Clone(oldObj.GetAnnotations())
+	newAnnotations := maps.Clone(newObj.GetAnnotations())
+	delete(oldAnnotations, DesiredStateHashAnnotation)
+	delete(newAnnotations, DesiredStateHashAnnotation)
+	if !maps.Equal(oldAnnotations, newAnnotations) {
+		return false
+	}
+	oldHash, err := d.hash(oldObj)
+	return err == nil && oldHash == newObj.GetAnnotations()[DesiredStateHashAnnotation]
+}
+
+func (d *DriftDetectingReconciler) hash(obj resource.Object) (string, error) {
+	if d.HashFunc != nil {
+		return d.HashFunc(obj)
+	}
+	return DefaultDesiredStateHash(obj)
+}
+
+// storeHash sets the desired state hash annotation on object with a merge patch.
+// The patch response is decoded into a copy, so the object in the request is not modified.
+func (d *DriftDetectingReconciler) storeHash(ctx context.Context, object resource.Object, hash string) error {
+	body, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{
+				DesiredStateHashAnnotation: hash,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return d.client.PatchInto(ctx, object.GetStaticMetadata().Identifier(), resource.PatchRequest{
+		Type: resource.PatchTypeMergePatch,
+		Body: body,
+	}, resource.PatchOptions{}, object.Copy())
+}
+
+// Compile-time interface compliance check
+var _ Reconciler = &DriftDetectingReconciler{}
@@ -0,0 +1,164 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func newDriftTestObject(resourceVersion string, spec map[string]any) *resource.UntypedObject {
+	obj := newOwnerTestObject("a", "uid-a")
+	obj.SetNamespace("ns")
+	obj.SetResourceVersion(resourceVersion)
+	obj.Spec = spec
+	return obj
+}
+
+func TestNewDriftDetectingReconciler(t *testing.T) {
+	_, err := NewDriftDetectingReconciler(nil, &mockPatchClient{})
+	assert.Equal(t, errors.New("reconciler cannot be nil"), err)
+	_, err = NewDriftDetectingReconciler(&SimpleReconciler{}, nil)
+	assert.Equal(t, errors.New("client cannot be nil"), err)
+	r, err := NewDriftDetectingReconciler(&SimpleReconciler{}, &mockPatchClient{})
+	require.Nil(t, err)
+	assert.NotNil(t, r.HashFunc)
+}
+
+func TestDriftDetectingReconciler_Reconcile(t *testing.T) {
+	var patches []resource.PatchRequest
+	client := &mockPatchClient{
+		PatchIntoFunc: func(_ context.Context, _ resource.Identifier, req resource.PatchRequest, _ resource.PatchOptions, _ resource.Object) error {
+			patches = append(patches, req)
+			return nil
+		},
+	}
+	var result ReconcileResult
+	var reconcileErr error
+	r, err := NewDriftDetectingReconciler(&SimpleReconciler{
+		ReconcileFunc: func(context.Context, ReconcileRequest) (ReconcileResult, error) {
+			return result, reconcileErr
+		},
+	}, client)
+	require.Nil(t, err)
+
+	t.Run("stores hash after success", func(t *testing.T) {
+		patches = nil
+		obj := newDriftTestObject("1", map[string]any{"foo": "bar"})
+		_, err := r.Reconcile(context.Background(), ReconcileRequest{Action: ReconcileActionCreated, Object: obj})
+		require.Nil(t, err)
+		require.Len(t, patches, 1)
+		assert.Equal(t, resource.PatchTypeMergePatch, patches[0].Type)
+		hash, err := DefaultDesiredStateHash(obj)
+		require.Nil(t, err)
+		body := make(map[string]map[string]map[string]string)
+		require.Nil(t, json.Unmarshal(patches[0].Body, &body))
+		assert.Equal(t, hash, body["metadata"]["annotations"][DesiredStateHashAnnotation])
+		assert.Empty(t, obj.GetAnnotations(), "request object should not be modified")
+	})
+
+	t.Run("hash unchanged", func(t *testing.T) {
+		patches = nil
+		obj := newDriftTestObject("1", map[string]any{"foo": "bar"})
+		hash, _ := DefaultDesiredStateHash(obj)
+		obj.SetAnnotations(map[string]string{DesiredStateHashAnnotation: hash})
+		_, err := r.Reconcile(context.Background(), ReconcileRequest{Action: ReconcileActionUpdated, Object: obj})
+		require.Nil(t, err)
+		assert.Empty(t, patches)
+	})
+
+	t.Run("not stored", func(t *testing.T) {
+		deleting := newDriftTestObject("1", nil)
+		deleting.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+		requeue := time.Second
+		tests := []struct {
+			name   string
+			req    ReconcileRequest
+			result ReconcileResult
+			err    error
+		}{
+			{"error", ReconcileRequest{Action: ReconcileActionCreated, Object: newDriftTestObject("1", nil)}, ReconcileResult{}, errors.New("I AM ERROR")},
+			{"requeue", ReconcileRequest{Action: ReconcileActionCreated, Object: newDriftTestObject("1", nil)}, ReconcileResult{RequeueAfter: &requeue}, nil},
+			{"deleted", ReconcileRequest{Action: ReconcileActionDeleted, Object: newDriftTestObject("1", nil)}, ReconcileResult{}, nil},
+			{"deleting", ReconcileRequest{Action: ReconcileActionUpdated, Object: deleting}, ReconcileResult{}, nil},
+			{"no object", ReconcileRequest{Action: ReconcileActionResynced}, ReconcileResult{}, nil},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				patches = nil
+				result, reconcileErr = test.result, test.err
+				res, err := r.Reconcile(context.Background(), test.req)
+				assert.Equal(t, test.err, err)
+				assert.Equal(t, test.result, res)
+				assert.Empty(t, patches)
+			})
+		}
+		result, reconcileErr = ReconcileResult{}, nil
+	})
+
+	t.Run("patch error does not fail reconcile", func(t *testing.T) {
+		client.PatchIntoFunc = func(context.Context, resource.Identifier, resource.PatchRequest, resource.PatchOptions, resource.Object) error {
+			return errors.New("I AM ERROR")
+		}
+		_, err := r.Reconcile(context.Background(), ReconcileRequest{Action: ReconcileActionCreated, Object: newDriftTestObject("1", nil)})
+		assert.Nil(t, err)
+	})
+}
+
+func TestDriftDetectingReconciler_Predicate(t *testing.T) {
+	r, err := NewDriftDetectingReconciler(&SimpleReconciler{}, &mockPatchClient{})
+	require.Nil(t, err)
+	p := r.Predicate()
+
+	reconciled := func(resourceVersion string, spec map[string]any) *resource.UntypedObject {
+		obj := newDriftTestObject(resourceVersion, spec)
+		hash, _ := DefaultDesiredStateHash(obj)
+		obj.SetAnnotations(map[string]string{DesiredStateHashAnnotation: hash})
+		return obj
+	}
+	withAnnotation := func(obj *resource.UntypedObject, key, value string) *resource.UntypedObject {
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[key] = value
+		obj.SetAnnotations(annotations)
+		return obj
+	}
+	withLabel := func(obj *resource.UntypedObject) *resource.UntypedObject {
+		obj.SetLabels(map[string]string{"foo": "bar"})
+		return obj
+	}
+
+	assert.True(t, p.Create(reconciled("1", nil)))
+	assert.True(t, p.Delete(reconciled("1", nil)))
+
+	tests := []struct {
+		name     string
+		oldObj   resource.Object
+		newObj   resource.Object
+		expected bool
+	}{
+		{"resync without stored hash", newDriftTestObject("1", nil), newDriftTestObject("1", nil), true},
+		{"resync without drift", reconciled("1", map[string]any{"a": "b"}), reconciled("1", map[string]any{"a": "b"}), false},
+		{"resync with drift", reconciled("1", map[string]any{"a": "b"}), withAnnotation(reconciled("1", map[string]any{"a": "b"}), DesiredStateHashAnnotation, "stale"), true},
+		{"spec change", reconciled("1", map[string]any{"a": "b"}), withAnnotation(newDriftTestObject("2", map[string]any{"a": "c"}), DesiredStateHashAnnotation, "stale"), true},
+		{"hash stored", newDriftTestObject("1", map[string]any{"a": "b"}), reconciled("2", map[string]any{"a": "b"}), false},
+		{"hash stored with label change", newDriftTestObject("1", map[string]any{"a": "b"}), withLabel(reconciled("2", map[string]any{"a": "b"})), true},
+		{"hash stored with annotation change", newDriftTestObject("1", map[string]any{"a": "b"}), withAnnotation(reconciled("2", map[string]any{"a": "b"}), "foo", "bar"), true},
+		{"metadata change without drift", reconciled("1", map[string]any{"a": "b"}), withLabel(reconciled("2", map[string]any{"a": "b"})), true},
+		{"no old object", nil, reconciled("1", nil), true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, p.Update(test.oldObj, test.newObj))
+		})
+	}
+}
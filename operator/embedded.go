@@ -0,0 +1,223 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/rest"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/k8s"
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// EmbeddedOptions are the options for StartEmbedded
+type EmbeddedOptions struct {
+	// Logger is the logger used by the app, typically the logger of the host service.
+	// If nil, logging.DefaultLogger is used.
+	Logger logging.Logger
+	// Registerer is the prometheus registerer the app's metrics are registered with, typically the registry of the host service.
+	// If nil, the app's metrics are not registered.
+	Registerer prometheus.Registerer
+	// ClientConfig is the config for the clients returned by EmbeddedApp.ClientGenerator.
+	// If nil, k8s.DefaultClientConfig() is used.
+	ClientConfig *k8s.ClientConfig
+	// EventRecorder is the EventRecorder used by reconcilers of the app (see EventRecorderFromContext).
+	// If nil, a KubernetesEventRecorder is created from the kube config, and shut down when the app stops.
+	EventRecorder EventRecorder
+	// Filesystem is the filesystem used to read the app's manifest, if the manifest location is a file path.
+	// If nil, the working directory is used.
+	Filesystem fs.FS
+}
+
+// EmbeddedApp is an app running in-process inside another service, started by StartEmbedded.
+// It exposes the app and clients for the app's kinds to the host service, so that the host can read
+// and write the app's resources without a separate client setup.
+type EmbeddedApp struct {
+	app             app.App
+	manifest        app.ManifestData
+	clientGenerator *k8s.ClientRegistry
+	store           *resource.Store
+	registerer      prometheus.Registerer
+	collectors      []prometheus.Collector
+	cancel          context.CancelFunc
+	done            chan struct{}
+	err             error
+	stopOnce        sync.Once
+}
+
+// StartEmbedded creates the app from provider and runs it in the background, until ctx is canceled or Stop is called.
+// Unlike Runner, it only runs the app's main loop (its reconcilers and watchers) and the config kind watch,
+// and does not start a webhook server, a metrics server, or CRD drift detection, so it can be used to run a small app
+// inside an existing service, sharing the service's logger and metrics registry.
+// As there is no webhook server, the app's admission and conversion capabilities are not served,
+// and a warning is logged if the manifest declares any.
+func StartEmbedded(ctx context.Context, provider app.Provider, kubeConfig rest.Config, options EmbeddedOptions) (*EmbeddedApp, error) {
+	if provider == nil {
+		return nil, errors.New("provider cannot be nil")
+	}
+	logger := options.Logger
+	if logger == nil {
+		logger = logging.DefaultLogger
+	}
+	// The Runner is only used for its shared informer factory and manifest and config kind helpers
+	r := &Runner{
+		config: RunnerConfig{
+			KubeConfig: kubeConfig,
+			Filesystem: options.Filesystem,
+		},
+		informers: NewSharedInformerFactory(),
+	}
+	manifestData, err := r.getManifestData(provider)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get app manifest: %w", err)
+	}
+	appConfig := app.Config{
+		KubeConfig:      kubeConfig,
+		ManifestData:    *manifestData,
+		SpecificConfig:  provider.SpecificConfig(),
+		InformerFactory: r.informers,
+	}
+	configKind, err := getConfigKind(*manifestData)
+	if err != nil {
+		return nil, err
+	}
+	if configKind != nil {
+		appConfig.ConfigKind = app.NewConfigKindValue()
+	}
+	a, err := provider.NewApp(appConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	runner := app.NewMultiRunner()
+	if ar := a.Runner(); ar != nil {
+		runner.AddRunnable(ar)
+	}
+	if configKind != nil {
+		inf, err := r.newConfigKindInformer(a, *configKind, appConfig.ConfigKind)
+		if err != nil {
+			return nil, err
+		}
+		runner.AddRunnable(inf)
+	}
+	for _, kind := range manifestData.Kinds {
+		for _, version := range kind.Versions {
+			if kind.Conversion || (version.Admission != nil && (version.Admission.SupportsAnyValidation() ||
+				version.Admission.SupportsAnyMutation() || version.Admission.SupportsAnyDefaulting())) {
+				logger.Warn("embedded app has admission or conversion capabilities, which are not served in embedded mode",
+					"app", manifestData.AppName, "kind", kind.Kind, "version", version.Name)
+			}
+		}
+	}
+
+	clientConfig := k8s.DefaultClientConfig()
+	if options.ClientConfig != nil {
+		clientConfig = *options.ClientConfig
+	}
+	e := &EmbeddedApp{
+		app:             a,
+		manifest:        *manifestData,
+		clientGenerator: k8s.NewClientRegistry(kubeConfig, clientConfig),
+		registerer:      options.Registerer,
+		done:            make(chan struct{}),
+	}
+	e.store = resource.NewStore(e.clientGenerator)
+	for _, kind := range a.ManagedKinds() {
+		e.store.Register(kind)
+	}
+	if e.registerer != nil {
+		for _, collector := range runner.PrometheusCollectors() {
+			if err = e.registerer.Register(collector); err != nil {
+				e.unregisterCollectors()
+				return nil, fmt.Errorf("unable to register app metrics: %w", err)
+			}
+			e.collectors = append(e.collectors, collector)
+		}
+	}
+
+	recorder := options.EventRecorder
+	var kubeRecorder *KubernetesEventRecorder
+	if recorder == nil {
+		kubeRecorder, err = NewKubernetesEventRecorderForConfig(kubeConfig, manifestData.AppName)
+		if err != nil {
+			e.unregisterCollectors()
+			return nil, err
+		}
+		recorder = kubeRecorder
+	}
+
+	ctx, e.cancel = context.WithCancel(logging.Context(ctx, logger))
+	go func() {
+		defer close(e.done)
+		e.err = runner.Run(WithEventRecorder(ctx, recorder))
+		if kubeRecorder != nil {
+			kubeRecorder.Shutdown()
+		}
+		e.unregisterCollectors()
+	}()
+	return e, nil
+}
+
+// App returns the running app
+func (e *EmbeddedApp) App() app.App {
+	return e.app
+}
+
+// Manifest returns the manifest data of the running app
+func (e *EmbeddedApp) Manifest() app.ManifestData {
+	return e.manifest
+}
+
+// ClientGenerator returns a resource.ClientGenerator for clients of the app's kinds,
+// which can be used to create a resource.TypedStore for a kind
+func (e *EmbeddedApp) ClientGenerator() resource.ClientGenerator {
+	return e.clientGenerator
+}
+
+// Store returns a resource.Store with all kinds managed by the app registered
+func (e *EmbeddedApp) Store() *resource.Store {
+	return e.store
+}
+
+// Done returns a channel which is closed once the app has stopped running
+func (e *EmbeddedApp) Done() <-chan struct{} {
+	return e.done
+}
+
+// Err returns the error the app stopped running with, or nil if it is still running or stopped without an error
+func (e *EmbeddedApp) Err() error {
+	select {
+	case <-e.done:
+		return e.err
+	default:
+		return nil
+	}
+}
+
+// Stop stops the app, waits for it to stop running or for ctx to be canceled, and returns the error it stopped running with.
+// The app's metrics are unregistered from the Registerer once it has stopped.
+func (e *EmbeddedApp) Stop(ctx context.Context) error {
+	e.stopOnce.Do(e.cancel)
+	select {
+	case <-e.done:
+		return e.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *EmbeddedApp) unregisterCollectors() {
+	if e.registerer == nil {
+		return
+	}
+	for _, collector := range e.collectors {
+		e.registerer.Unregister(collector)
+	}
+	e.collectors = nil
+}
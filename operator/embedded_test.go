@@ -0,0 +1,141 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+type embeddedTestApp struct {
+	app.App
+	kinds  []resource.Kind
+	runner app.Runnable
+}
+
+func (a *embeddedTestApp) ManagedKinds() []resource.Kind {
+	return a.kinds
+}
+
+func (a *embeddedTestApp) Runner() app.Runnable {
+	return a.runner
+}
+
+type embeddedTestProvider struct {
+	manifest app.ManifestData
+	newApp   func(app.Config) (app.App, error)
+}
+
+func (p *embeddedTestProvider) Manifest() app.Manifest {
+	return app.NewEmbeddedManifest(p.manifest)
+}
+
+func (*embeddedTestProvider) SpecificConfig() app.SpecificConfig {
+	return nil
+}
+
+func (p *embeddedTestProvider) NewApp(cfg app.Config) (app.App, error) {
+	return p.newApp(cfg)
+}
+
+type embeddedTestRunnable struct {
+	started   chan context.Context
+	collector prometheus.Collector
+}
+
+func (r *embeddedTestRunnable) Run(ctx context.Context) error {
+	r.started <- ctx
+	<-ctx.Done()
+	return nil
+}
+
+func (r *embeddedTestRunnable) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{r.collector}
+}
+
+func TestStartEmbedded(t *testing.T) {
+	_, err := StartEmbedded(context.Background(), nil, rest.Config{}, EmbeddedOptions{})
+	assert.EqualError(t, err, "provider cannot be nil")
+
+	kind := resource.Kind{
+		Schema: resource.NewSimpleSchema("test.ext.grafana.com", "v1", &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("Foo")),
+		Codecs: map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()},
+	}
+	runnable := &embeddedTestRunnable{
+		started: make(chan context.Context, 1),
+		collector: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "embedded_test_total",
+		}),
+	}
+	var appConfig app.Config
+	provider := &embeddedTestProvider{
+		manifest: app.ManifestData{
+			AppName: "test",
+			Group:   "test.ext.grafana.com",
+			Kinds:   []app.ManifestKind{{Kind: "Foo", Versions: []app.ManifestKindVersion{{Name: "v1"}}}},
+		},
+		newApp: func(cfg app.Config) (app.App, error) {
+			appConfig = cfg
+			return &embeddedTestApp{kinds: []resource.Kind{kind}, runner: runnable}, nil
+		},
+	}
+	registry := prometheus.NewRegistry()
+	logs := &bytes.Buffer{}
+	logger := logging.NewSLogLogger(slog.NewTextHandler(logs, nil))
+	e, err := StartEmbedded(context.Background(), provider, rest.Config{Host: "http://localhost"}, EmbeddedOptions{
+		Logger:        logger,
+		Registerer:    registry,
+		EventRecorder: &noopEventRecorder{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "test", appConfig.ManifestData.AppName)
+	assert.NotNil(t, appConfig.InformerFactory)
+	assert.Equal(t, "test", e.Manifest().AppName)
+	assert.Equal(t, []resource.Kind{kind}, e.App().ManagedKinds())
+	client, err := e.ClientGenerator().ClientFor(kind)
+	require.NoError(t, err)
+	assert.NotNil(t, client)
+	assert.NotNil(t, e.Store())
+
+	var ctx context.Context
+	select {
+	case ctx = <-runnable.started:
+	case <-time.After(time.Second):
+		t.Fatal("app runner was not started")
+	}
+	// The app runs with the host's logger and the configured event recorder
+	logging.FromContext(ctx).Info("from embedded app")
+	assert.Contains(t, logs.String(), "from embedded app")
+	assert.IsType(t, &noopEventRecorder{}, EventRecorderFromContext(ctx))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	assert.Len(t, families, 1)
+	assert.Nil(t, e.Err())
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, e.Stop(stopCtx))
+	<-e.Done()
+	// Metrics are unregistered once the app has stopped, so it can be started again with the same registry
+	assert.False(t, registry.Unregister(runnable.collector))
+
+	t.Run("metrics already registered", func(t *testing.T) {
+		require.NoError(t, registry.Register(runnable.collector))
+		_, err := StartEmbedded(context.Background(), provider, rest.Config{}, EmbeddedOptions{
+			Registerer:    registry,
+			EventRecorder: &noopEventRecorder{},
+		})
+		assert.True(t, errors.As(err, &prometheus.AlreadyRegisteredError{}))
+	})
+}
@@ -0,0 +1,176 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// RelatedOwners is the conventional key in ReconcileRequest.Related for the owner chain resolved by OwnerChainResolver
+const RelatedOwners = "owners"
+
+// ErrNotInCache is returned by an ObjectGetter when the requested object does not exist in its cache
+var ErrNotInCache = errors.New("object not found in cache")
+
+// ObjectGetter gets a single object of a kind by its identifier. Implementations used for request enrichment
+// should be backed by a cache (such as KubernetesBasedInformer), so that enrichment does not issue API server requests.
+type ObjectGetter interface {
+	Get(ctx context.Context, identifier resource.Identifier) (resource.Object, error)
+}
+
+// RelatedObjectResolver resolves objects related to the object in a ReconcileRequest
+type RelatedObjectResolver interface {
+	Resolve(ctx context.Context, object resource.Object) ([]resource.Object, error)
+}
+
+// RelatedObjectResolverFunc is a function which implements RelatedObjectResolver
+type RelatedObjectResolverFunc func(ctx context.Context, object resource.Object) ([]resource.Object, error)
+
+// Resolve calls the function
+func (f RelatedObjectResolverFunc) Resolve(ctx context.Context, object resource.Object) ([]resource.Object, error) {
+	return f(ctx, object)
+}
+
+// EnrichingReconciler wraps a Reconciler, and before each Reconcile call, resolves related objects using Resolvers
+// and attaches them to ReconcileRequest.Related under the key of each resolver.
+// Resolving all related objects up front from a cache gives the wrapped Reconciler a consistent snapshot,
+// rather than making several reads throughout the reconcile which may observe different states.
+// It should be instantiated with NewEnrichingReconciler.
+type EnrichingReconciler struct {
+	Reconciler Reconciler
+	// Resolvers is a map of key in ReconcileRequest.Related to the RelatedObjectResolver which populates it
+	Resolvers map[string]RelatedObjectResolver
+	// FailOnResolveError, if true, will return an error (which is then retried according to the controller's
+	// RetryPolicy) if any resolver fails. Otherwise, resolver errors are logged and the key is omitted from Related.
+	FailOnResolveError bool
+}
+
+// NewEnrichingReconciler returns a new EnrichingReconciler which wraps reconciler
+func NewEnrichingReconciler(reconciler Reconciler, resolvers map[string]RelatedObjectResolver) (*EnrichingReconciler, error) {
+	if reconciler == nil {
+		return nil, fmt.Errorf("reconciler cannot be nil")
+	}
+	return &EnrichingReconciler{
+		Reconciler: reconciler,
+		Resolvers:  resolvers,
+	}, nil
+}
+
+// Reconcile resolves related objects for the request, and then calls the wrapped Reconciler
+func (e *EnrichingReconciler) Reconcile(ctx context.Context, req ReconcileRequest) (ReconcileResult, error) {
+	if len(e.Resolvers) > 0 && req.Object != nil {
+		ctx, span := GetTracer().Start(ctx, "enrich-reconcile-request")
+		related := make(map[string][]resource.Object, len(e.Resolvers))
+		for key, resolver := range e.Resolvers {
+			objs, err := resolver.Resolve(ctx, req.Object)
+			if err != nil {
+				if e.FailOnResolveError {
+					span.End()
+					return ReconcileResult{}, fmt.Errorf("unable to resolve related objects '%s': %w", key, err)
+				}
+				logging.FromContext(ctx).Warn("unable to resolve related objects", "key", key, "error", err)
+				continue
+			}
+			related[key] = objs
+		}
+		span.End()
+		req.Related = related
+	}
+	return e.Reconciler.Reconcile(ctx, req)
+}
+
+// Wrap replaces the wrapped Reconciler with reconciler
+func (e *EnrichingReconciler) Wrap(reconciler Reconciler) {
+	e.Reconciler = reconciler
+}
+
+// Compile-time interface compliance check
+var _ Reconciler = &EnrichingReconciler{}
+
+// OwnerChainResolver returns a RelatedObjectResolver which resolves the chain of owners of an object,
+// nearest owner first, by following the controller owner reference (or the first owner reference, if none is a controller).
+// Owners are looked up with the ObjectGetter for their GroupKind; the chain ends when an owner has no owner references,
+// its GroupKind has no getter, or maxDepth owners have been resolved (if maxDepth <= 0, the chain is limited to 10 owners).
+func OwnerChainResolver(getters map[schema.GroupKind]ObjectGetter, maxDepth int) RelatedObjectResolver {
+	if maxDepth <= 0 {
+		maxDepth = 10
+	}
+	return RelatedObjectResolverFunc(func(ctx context.Context, object resource.Object) ([]resource.Object, error) {
+		chain := make([]resource.Object, 0)
+		current := object
+		for len(chain) < maxDepth {
+			ref := primaryOwnerReference(current.GetOwnerReferences())
+			if ref == nil {
+				break
+			}
+			gv, err := schema.ParseGroupVersion(ref.APIVersion)
+			if err != nil {
+				return chain, fmt.Errorf("invalid owner reference apiVersion '%s': %w", ref.APIVersion, err)
+			}
+			getter, ok := getters[schema.GroupKind{Group: gv.Group, Kind: ref.Kind}]
+			if !ok {
+				break
+			}
+			owner, err := getter.Get(ctx, resource.Identifier{
+				Namespace: current.GetNamespace(),
+				Name:      ref.Name,
+			})
+			if err != nil {
+				return chain, fmt.Errorf("unable to get owner %s '%s': %w", ref.Kind, ref.Name, err)
+			}
+			chain = append(chain, owner)
+			current = owner
+		}
+		return chain, nil
+	})
+}
+
+// AnnotationReferenceResolver returns a RelatedObjectResolver which resolves objects referenced by name in the
+// provided annotation of an object, using getter. The annotation value is a comma-separated list of names,
+// which are looked up in the object's namespace unless given in the form "<namespace>/<name>".
+// Objects without the annotation resolve to an empty list.
+func AnnotationReferenceResolver(annotation string, getter ObjectGetter) RelatedObjectResolver {
+	return RelatedObjectResolverFunc(func(ctx context.Context, object resource.Object) ([]resource.Object, error) {
+		val := object.GetAnnotations()[annotation]
+		objs := make([]resource.Object, 0)
+		for _, ref := range strings.Split(val, ",") {
+			ref = strings.TrimSpace(ref)
+			if ref == "" {
+				continue
+			}
+			id := resource.Identifier{
+				Namespace: object.GetNamespace(),
+				Name:      ref,
+			}
+			if ns, name, ok := strings.Cut(ref, "/"); ok {
+				id.Namespace = ns
+				id.Name = name
+			}
+			obj, err := getter.Get(ctx, id)
+			if err != nil {
+				return objs, fmt.Errorf("unable to get referenced object '%s': %w", ref, err)
+			}
+			objs = append(objs, obj)
+		}
+		return objs, nil
+	})
+}
+
+func primaryOwnerReference(refs []metav1.OwnerReference) *metav1.OwnerReference {
+	if len(refs) == 0 {
+		return nil
+	}
+	for i, ref := range refs {
+		if ref.Controller != nil && *ref.Controller {
+			return &refs[i]
+		}
+	}
+	return &refs[0]
+}
@@ -0,0 +1,163 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestEnrichingReconciler_Reconcile(t *testing.T) {
+	obj := &resource.UntypedObject{}
+	obj.SetName("foo")
+
+	t.Run("attaches related objects", func(t *testing.T) {
+		related := &resource.UntypedObject{}
+		var got ReconcileRequest
+		r, err := NewEnrichingReconciler(&SimpleReconciler{
+			ReconcileFunc: func(_ context.Context, req ReconcileRequest) (ReconcileResult, error) {
+				got = req
+				return ReconcileResult{}, nil
+			},
+		}, map[string]RelatedObjectResolver{
+			"foo": RelatedObjectResolverFunc(func(context.Context, resource.Object) ([]resource.Object, error) {
+				return []resource.Object{related}, nil
+			}),
+		})
+		require.Nil(t, err)
+		_, err = r.Reconcile(context.Background(), ReconcileRequest{Object: obj})
+		require.Nil(t, err)
+		assert.Equal(t, map[string][]resource.Object{"foo": {related}}, got.Related)
+	})
+
+	t.Run("resolver error", func(t *testing.T) {
+		resolveErr := errors.New("I AM ERROR")
+		called := false
+		r, err := NewEnrichingReconciler(&SimpleReconciler{
+			ReconcileFunc: func(_ context.Context, req ReconcileRequest) (ReconcileResult, error) {
+				called = true
+				assert.NotContains(t, req.Related, "foo")
+				return ReconcileResult{}, nil
+			},
+		}, map[string]RelatedObjectResolver{
+			"foo": RelatedObjectResolverFunc(func(context.Context, resource.Object) ([]resource.Object, error) {
+				return nil, resolveErr
+			}),
+		})
+		require.Nil(t, err)
+		_, err = r.Reconcile(context.Background(), ReconcileRequest{Object: obj})
+		assert.Nil(t, err)
+		assert.True(t, called)
+
+		called = false
+		r.FailOnResolveError = true
+		_, err = r.Reconcile(context.Background(), ReconcileRequest{Object: obj})
+		assert.ErrorIs(t, err, resolveErr)
+		assert.False(t, called)
+	})
+}
+
+func TestOwnerChainResolver(t *testing.T) {
+	isController := true
+	grandparent := &resource.UntypedObject{}
+	grandparent.SetName("grandparent")
+	grandparent.SetNamespace("ns")
+	parent := &resource.UntypedObject{}
+	parent.SetName("parent")
+	parent.SetNamespace("ns")
+	parent.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "foo.grafana.com/v1", Kind: "Foo", Name: "grandparent"}})
+	child := &resource.UntypedObject{}
+	child.SetName("child")
+	child.SetNamespace("ns")
+	child.SetOwnerReferences([]metav1.OwnerReference{
+		{APIVersion: "bar.grafana.com/v1", Kind: "Bar", Name: "other"},
+		{APIVersion: "foo.grafana.com/v1", Kind: "Foo", Name: "parent", Controller: &isController},
+	})
+	getter := &testObjectGetter{objects: map[resource.Identifier]resource.Object{
+		{Namespace: "ns", Name: "parent"}:      parent,
+		{Namespace: "ns", Name: "grandparent"}: grandparent,
+	}}
+
+	t.Run("full chain", func(t *testing.T) {
+		chain, err := OwnerChainResolver(map[schema.GroupKind]ObjectGetter{{Group: "foo.grafana.com", Kind: "Foo"}: getter}, 0).
+			Resolve(context.Background(), child)
+		require.Nil(t, err)
+		assert.Equal(t, []resource.Object{parent, grandparent}, chain)
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		chain, err := OwnerChainResolver(map[schema.GroupKind]ObjectGetter{{Group: "foo.grafana.com", Kind: "Foo"}: getter}, 1).
+			Resolve(context.Background(), child)
+		require.Nil(t, err)
+		assert.Equal(t, []resource.Object{parent}, chain)
+	})
+
+	t.Run("no getter for owner kind", func(t *testing.T) {
+		chain, err := OwnerChainResolver(map[schema.GroupKind]ObjectGetter{}, 0).Resolve(context.Background(), child)
+		require.Nil(t, err)
+		assert.Empty(t, chain)
+	})
+}
+
+func TestAnnotationReferenceResolver(t *testing.T) {
+	a := &resource.UntypedObject{}
+	a.SetName("a")
+	b := &resource.UntypedObject{}
+	b.SetName("b")
+	getter := &testObjectGetter{objects: map[resource.Identifier]resource.Object{
+		{Namespace: "ns", Name: "a"}:    a,
+		{Namespace: "other", Name: "b"}: b,
+	}}
+	obj := &resource.UntypedObject{}
+	obj.SetNamespace("ns")
+	obj.SetAnnotations(map[string]string{"refs": "a, other/b"})
+
+	objs, err := AnnotationReferenceResolver("refs", getter).Resolve(context.Background(), obj)
+	require.Nil(t, err)
+	assert.Equal(t, []resource.Object{a, b}, objs)
+
+	obj.SetAnnotations(map[string]string{"refs": "c"})
+	_, err = AnnotationReferenceResolver("refs", getter).Resolve(context.Background(), obj)
+	assert.ErrorIs(t, err, ErrNotInCache)
+
+	obj.SetAnnotations(nil)
+	objs, err = AnnotationReferenceResolver("refs", getter).Resolve(context.Background(), obj)
+	require.Nil(t, err)
+	assert.Empty(t, objs)
+}
+
+func TestKubernetesBasedInformer_Get(t *testing.T) {
+	kind := resource.Kind{
+		Schema: resource.NewSimpleSchema("foo", "v1", &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("Foo")),
+	}
+	inf, err := NewKubernetesBasedInformer(kind, &nopListWatchClient{}, KubernetesBasedInformerOptions{})
+	require.Nil(t, err)
+	obj := &resource.UntypedObject{}
+	obj.SetName("foo")
+	obj.SetNamespace("bar")
+	require.Nil(t, inf.SharedIndexInformer.GetStore().Add(obj))
+
+	got, err := inf.Get(context.Background(), resource.Identifier{Namespace: "bar", Name: "foo"})
+	require.Nil(t, err)
+	assert.Equal(t, obj, got)
+	_, err = inf.Get(context.Background(), resource.Identifier{Namespace: "foo", Name: "foo"})
+	assert.ErrorIs(t, err, ErrNotInCache)
+}
+
+type testObjectGetter struct {
+	objects map[resource.Identifier]resource.Object
+}
+
+func (g *testObjectGetter) Get(_ context.Context, identifier resource.Identifier) (resource.Object, error) {
+	obj, ok := g.objects[identifier]
+	if !ok {
+		return nil, ErrNotInCache
+	}
+	return obj, nil
+}
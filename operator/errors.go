@@ -1,8 +1,10 @@
 package operator
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/grafana/grafana-app-sdk/app"
 	"github.com/grafana/grafana-app-sdk/resource"
 )
 
@@ -33,3 +35,29 @@ func NewCannotCastError(meta resource.StaticMetadata) *CannotCastError {
 }
 
 var _ error = NewCannotCastError(resource.StaticMetadata{})
+
+// objectErrorReport returns an app.ErrorReport for err, with the kind and identifier of object, if object is non-nil
+func objectErrorReport(err error, class app.ErrorClass, component string, object resource.Object) app.ErrorReport {
+	report := app.ErrorReport{
+		Err:       err,
+		Class:     class,
+		Component: component,
+	}
+	if object != nil {
+		report.Kind = object.GetStaticMetadata().Kind
+		report.Object = object.GetStaticMetadata().Identifier()
+	}
+	return report
+}
+
+// reportError sends report to reporter, or, if reporter is nil, calls the deprecated handler with the report's error.
+// Nothing is done if both are nil.
+func reportError(ctx context.Context, reporter app.ErrorReporter, handler func(context.Context, error), report app.ErrorReport) {
+	if reporter != nil {
+		reporter.ReportError(ctx, report)
+		return
+	}
+	if handler != nil {
+		handler(ctx, report.Err)
+	}
+}
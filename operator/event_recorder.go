@@ -0,0 +1,136 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+const (
+	// EventTypeNormal is the type of events which report normal progress, such as a successful reconcile
+	EventTypeNormal = corev1.EventTypeNormal
+	// EventTypeWarning is the type of events which report a problem with an object
+	EventTypeWarning = corev1.EventTypeWarning
+
+	// eventMessageMaxLength is the maximum length of an Event message, after which the API server rejects it
+	eventMessageMaxLength = 1024
+)
+
+// EventRecorder records Kubernetes Events about objects, which are shown with the object by `kubectl describe`.
+// Watchers and reconcilers can get the EventRecorder of their InformerController with EventRecorderFromContext.
+type EventRecorder interface {
+	// Event records an event for object. eventType is EventTypeNormal or EventTypeWarning, reason is a short,
+	// UpperCamelCase reason for the event (such as "Provisioned"), and message is a human-readable description.
+	Event(ctx context.Context, object resource.Object, eventType, reason, message string)
+	// Eventf is like Event, but formats the message with fmt.Sprintf
+	Eventf(ctx context.Context, object resource.Object, eventType, reason, messageFmt string, args ...any)
+}
+
+type eventRecorderContextKey struct{}
+
+// WithEventRecorder returns a copy of ctx which contains recorder, which can be retrieved with EventRecorderFromContext
+func WithEventRecorder(ctx context.Context, recorder EventRecorder) context.Context {
+	return context.WithValue(ctx, eventRecorderContextKey{}, recorder)
+}
+
+// EventRecorderFromContext returns the EventRecorder contained in ctx (see WithEventRecorder).
+// If ctx has no EventRecorder, an EventRecorder which discards all events is returned,
+// so callers can always record events without checking.
+func EventRecorderFromContext(ctx context.Context) EventRecorder {
+	if recorder, ok := ctx.Value(eventRecorderContextKey{}).(EventRecorder); ok && recorder != nil {
+		return recorder
+	}
+	return noopEventRecorder{}
+}
+
+// KubernetesEventRecorder is an EventRecorder which records core/v1 Events with client-go's record.EventBroadcaster.
+// Events are sent in the background, so Event does not block on the API server. Repeated events are aggregated
+// into a single Event with an increasing count, and the events of each object are rate-limited, the same as they are
+// for the events of built-in controllers. Errors sending events are logged by client-go.
+// It should be instantiated with NewKubernetesEventRecorder or NewKubernetesEventRecorderForConfig,
+// and Shutdown should be called once it is no longer used.
+type KubernetesEventRecorder struct {
+	broadcaster record.EventBroadcaster
+	recorder    record.EventRecorder
+}
+
+// NewKubernetesEventRecorder returns a new KubernetesEventRecorder which records events with client.
+// component is the source component of the events, and is typically the app name.
+func NewKubernetesEventRecorder(client corev1client.EventsGetter, component string) (*KubernetesEventRecorder, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&corev1client.EventSinkImpl{Interface: client.Events("")})
+	return &KubernetesEventRecorder{
+		broadcaster: broadcaster,
+		// Events are always recorded for an ObjectReference, so the scheme is never used to look up an object's kind
+		recorder: broadcaster.NewRecorder(runtime.NewScheme(), corev1.EventSource{Component: component}),
+	}, nil
+}
+
+// NewKubernetesEventRecorderForConfig returns a new KubernetesEventRecorder which records events with a client for cfg.
+// The operator's credentials must allow it to create and patch events in the namespaces of the objects
+// it records events for.
+func NewKubernetesEventRecorderForConfig(cfg rest.Config, component string) (*KubernetesEventRecorder, error) {
+	client, err := corev1client.NewForConfig(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create events client: %w", err)
+	}
+	return NewKubernetesEventRecorder(client, component)
+}
+
+// Event records an event for object. Events for cluster-scoped objects are recorded in the default namespace.
+func (r *KubernetesEventRecorder) Event(_ context.Context, object resource.Object, eventType, reason, message string) {
+	if object == nil {
+		return
+	}
+	if len(message) > eventMessageMaxLength {
+		message = message[:eventMessageMaxLength]
+	}
+	r.recorder.Event(objectReference(object), eventType, reason, message)
+}
+
+// Eventf records an event for object with the message formatted with fmt.Sprintf
+func (r *KubernetesEventRecorder) Eventf(ctx context.Context, object resource.Object, eventType, reason, messageFmt string, args ...any) {
+	r.Event(ctx, object, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// Shutdown stops recording events. Events which have not been sent yet are discarded.
+func (r *KubernetesEventRecorder) Shutdown() {
+	r.broadcaster.Shutdown()
+}
+
+// objectReference returns a reference to object. The reference is built from the object's static metadata,
+// as objects do not always have their kind set, and are not registered in a scheme.
+func objectReference(object resource.Object) *corev1.ObjectReference {
+	meta := object.GetStaticMetadata()
+	return &corev1.ObjectReference{
+		APIVersion:      schema.GroupVersion{Group: meta.Group, Version: meta.Version}.String(),
+		Kind:            meta.Kind,
+		Namespace:       meta.Namespace,
+		Name:            meta.Name,
+		UID:             object.GetUID(),
+		ResourceVersion: object.GetResourceVersion(),
+	}
+}
+
+type noopEventRecorder struct{}
+
+func (noopEventRecorder) Event(context.Context, resource.Object, string, string, string) {}
+
+func (noopEventRecorder) Eventf(context.Context, resource.Object, string, string, string, ...any) {}
+
+// Compile-time interface compliance checks
+var (
+	_ EventRecorder = &KubernetesEventRecorder{}
+	_ EventRecorder = noopEventRecorder{}
+)
@@ -0,0 +1,155 @@
+package operator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestKubernetesEventRecorder_Event(t *testing.T) {
+	_, err := NewKubernetesEventRecorder(nil, "test-app")
+	assert.EqualError(t, err, "client cannot be nil")
+
+	client := fake.NewSimpleClientset()
+	recorder, err := NewKubernetesEventRecorder(client.CoreV1(), "test-app")
+	require.Nil(t, err)
+	defer recorder.Shutdown()
+	// events returns the events in namespace once there are count of them
+	events := func(t *testing.T, namespace string, count int) []corev1.Event {
+		var list *corev1.EventList
+		require.Eventually(t, func() bool {
+			list, err = client.CoreV1().Events(namespace).List(context.Background(), metav1.ListOptions{})
+			require.Nil(t, err)
+			return len(list.Items) == count
+		}, 5*time.Second, 10*time.Millisecond)
+		return list.Items
+	}
+
+	t.Run("namespaced", func(t *testing.T) {
+		obj := newListerTestObject("ns", "foo")
+		obj.SetUID("abc")
+		obj.SetGroupVersionKind(schema.GroupVersionKind{Group: "foo.grafana.app", Version: "v1", Kind: "Foo"})
+		recorder.Eventf(context.Background(), obj, EventTypeNormal, "Provisioned", "provisioned %d resources", 3)
+
+		event := events(t, "ns", 1)[0]
+		assert.True(t, strings.HasPrefix(event.Name, "foo."))
+		assert.Equal(t, "Provisioned", event.Reason)
+		assert.Equal(t, corev1.EventTypeNormal, event.Type)
+		assert.Equal(t, "provisioned 3 resources", event.Message)
+		assert.Equal(t, "test-app", event.Source.Component)
+		assert.Equal(t, int32(1), event.Count)
+		assert.Equal(t, "foo.grafana.app/v1", event.InvolvedObject.APIVersion)
+		assert.Equal(t, "Foo", event.InvolvedObject.Kind)
+		assert.Equal(t, "abc", string(event.InvolvedObject.UID))
+
+		// A repeated event is aggregated into the existing one
+		recorder.Eventf(context.Background(), obj, EventTypeNormal, "Provisioned", "provisioned %d resources", 3)
+		require.Eventually(t, func() bool {
+			existing, err := client.CoreV1().Events("ns").Get(context.Background(), event.Name, metav1.GetOptions{})
+			require.Nil(t, err)
+			return existing.Count == 2
+		}, 5*time.Second, 10*time.Millisecond)
+		events(t, "ns", 1)
+	})
+
+	t.Run("cluster-scoped", func(t *testing.T) {
+		obj := newListerTestObject("", "bar")
+		recorder.Event(context.Background(), obj, EventTypeWarning, "Failed", strings.Repeat("a", 2*eventMessageMaxLength))
+
+		event := events(t, metav1.NamespaceDefault, 1)[0]
+		assert.Equal(t, "bar", event.InvolvedObject.Name)
+		assert.Empty(t, event.InvolvedObject.Namespace)
+		assert.Len(t, event.Message, eventMessageMaxLength)
+	})
+}
+
+func TestEventRecorderFromContext(t *testing.T) {
+	// Without an EventRecorder, events are discarded
+	assert.Equal(t, noopEventRecorder{}, EventRecorderFromContext(context.Background()))
+
+	recorder := &testEventRecorder{}
+	assert.Equal(t, recorder, EventRecorderFromContext(WithEventRecorder(context.Background(), recorder)))
+}
+
+func TestInformerController_EventRecorder(t *testing.T) {
+	recorder := &testEventRecorder{}
+	c := NewInformerController(InformerControllerConfig{
+		EventRecorder: recorder,
+	})
+	inf := &testInformer{}
+	require.Nil(t, c.AddInformer(inf, "foo"))
+	require.Nil(t, c.AddReconciler(&SimpleReconciler{
+		ReconcileFunc: func(ctx context.Context, req ReconcileRequest) (ReconcileResult, error) {
+			EventRecorderFromContext(ctx).Event(ctx, req.Object, EventTypeNormal, "Reconciled", "reconciled")
+			return ReconcileResult{}, nil
+		},
+	}, "foo"))
+	require.Nil(t, c.AddWatcher(&SimpleWatcher{
+		AddFunc: func(ctx context.Context, object resource.Object) error {
+			EventRecorderFromContext(ctx).Event(ctx, object, EventTypeNormal, "Added", "added")
+			return nil
+		},
+	}, "foo"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+	inf.FireAdd(context.Background(), newListerTestObject("ns", "a"))
+	assert.ElementsMatch(t, []string{"Added", "Reconciled"}, recorder.reasons())
+
+	t.Run("from context", func(t *testing.T) {
+		// An EventRecorder already in the context of the event takes precedence
+		ctxRecorder := &testEventRecorder{}
+		inf.FireAdd(WithEventRecorder(context.Background(), ctxRecorder), newListerTestObject("ns", "b"))
+		assert.ElementsMatch(t, []string{"Added", "Reconciled"}, ctxRecorder.reasons())
+		assert.Len(t, recorder.reasons(), 2)
+	})
+}
+
+func TestEventRecorderDeadLetterHandler(t *testing.T) {
+	recorder := &testEventRecorder{}
+	handler := EventRecorderDeadLetterHandler(recorder)
+	handler(context.Background(), DeadLetter{
+		Action: ResourceActionDelete,
+		Object: newListerTestObject("ns", "a"),
+	})
+	require.Len(t, recorder.events, 1)
+	assert.Equal(t, testEvent{EventTypeWarning, DeadLetterEventReason, "DELETE failed after 0 attempt(s)"}, recorder.events[0])
+}
+
+type testEvent struct {
+	eventType string
+	reason    string
+	message   string
+}
+
+// testEventRecorder is an EventRecorder which records the events it is called with
+type testEventRecorder struct {
+	events []testEvent
+}
+
+func (r *testEventRecorder) Event(_ context.Context, _ resource.Object, eventType, reason, message string) {
+	r.events = append(r.events, testEvent{eventType, reason, message})
+}
+
+func (r *testEventRecorder) Eventf(ctx context.Context, object resource.Object, eventType, reason, messageFmt string, _ ...any) {
+	r.Event(ctx, object, eventType, reason, messageFmt)
+}
+
+func (r *testEventRecorder) reasons() []string {
+	reasons := make([]string, 0, len(r.events))
+	for _, e := range r.events {
+		reasons = append(reasons, e.reason)
+	}
+	return reasons
+}
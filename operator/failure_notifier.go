@@ -0,0 +1,350 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// FailureNotificationType is the type of a FailureNotification
+type FailureNotificationType string
+
+const (
+	// FailureNotificationTypeDeadLettered is the type of notifications for events which an InformerController
+	// stopped retrying (see DeadLetter)
+	FailureNotificationTypeDeadLettered FailureNotificationType = "DeadLettered"
+	// FailureNotificationTypeSustainedFailure is the type of notifications for objects whose reconciles have failed
+	// consecutively for longer than the FailureThreshold
+	FailureNotificationTypeSustainedFailure FailureNotificationType = "SustainedFailure"
+	// FailureNotificationTypeResolved is the type of notifications for objects which were the subject of an earlier
+	// DeadLettered or SustainedFailure notification, and have since been reconciled successfully
+	FailureNotificationTypeResolved FailureNotificationType = "Resolved"
+)
+
+// defaultFailureNotificationBufferSize is the number of notifications an InformerController holds
+// for its FailureNotifier before dropping them
+const defaultFailureNotificationBufferSize = 100
+
+// FailureNotification is sent to a FailureNotifier when an object fails to be processed by an InformerController
+// in a way which may need operational escalation, and when that failure is resolved.
+type FailureNotification struct {
+	// Type is the type of the notification
+	Type FailureNotificationType `json:"type"`
+	// DedupKey identifies the handler and object of the notification (see FailureDedupKey). Every notification
+	// for the same object from the same handler has the same DedupKey, so that a notification system can group them
+	// into one incident, and resolve it with the FailureNotificationTypeResolved notification.
+	DedupKey string `json:"dedupKey"`
+	// Handler identifies the watcher or reconciler which failed to process the object, as "<kind>:<index>" for watchers
+	// and "reconcile:<kind>:<index>" for reconcilers, where <index> is the order it was added for the kind
+	// to the InformerController, starting from 0.
+	Handler   string `json:"handler"`
+	Group     string `json:"group"`
+	Version   string `json:"version"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Action is the action of the event which failed
+	Action ResourceAction `json:"action,omitempty"`
+	// Failures is the number of failed attempts. For Resolved notifications, it is the number of failed attempts
+	// before the successful one.
+	Failures int `json:"failures"`
+	// FirstFailure is the time of the first failed attempt, if known
+	FirstFailure time.Time `json:"firstFailure"`
+	// Error is the message of the last error. It is empty for Resolved notifications.
+	Error string `json:"error,omitempty"`
+	// RequestID is the request ID of the last failed event (see RequestIDFromContext)
+	RequestID string `json:"requestID,omitempty"`
+	// Time is the time the notification was created
+	Time time.Time `json:"time"`
+}
+
+// FailureNotifier is a sink for FailureNotifications, such as an incident or alerting system.
+// An InformerController calls Notify from a single goroutine, in the order notifications were created,
+// so it may block (for example, on a network request), but notifications which can't be held while it does are dropped.
+type FailureNotifier interface {
+	Notify(ctx context.Context, notification FailureNotification) error
+}
+
+// FailureNotifierFunc is a function which implements FailureNotifier
+type FailureNotifierFunc func(ctx context.Context, notification FailureNotification) error
+
+// Notify calls the function
+func (f FailureNotifierFunc) Notify(ctx context.Context, notification FailureNotification) error {
+	return f(ctx, notification)
+}
+
+// FailureThreshold is the threshold at which consecutive failed reconciles of an object are sent to a FailureNotifier
+// as a FailureNotificationTypeSustainedFailure notification. The notification is sent once, when the threshold is first reached,
+// and the failures are reset when the object is next reconciled successfully. If both fields are zero,
+// only dead-lettered events are sent to the FailureNotifier.
+type FailureThreshold struct {
+	// Failures is the number of consecutive failed reconciles of an object (including retries) which reaches the threshold
+	Failures int
+	// Duration is the time since the first of consecutive failed reconciles of an object which reaches the threshold,
+	// checked on each failed reconcile
+	Duration time.Duration
+}
+
+func (f FailureThreshold) reached(failures int, since time.Duration) bool {
+	return (f.Failures > 0 && failures >= f.Failures) || (f.Duration > 0 && since >= f.Duration)
+}
+
+// FailureDedupKey returns the dedup key of FailureNotifications from handler (see FailureNotification.Handler)
+// for an object with metadata, which is the handler, and the object's group, kind, namespace, and name.
+// Failures of the same object in different watchers or reconcilers have different dedup keys,
+// so that one handler succeeding does not resolve the failure of another.
+func FailureDedupKey(handler string, metadata resource.StaticMetadata) string {
+	if metadata.Namespace == "" {
+		return fmt.Sprintf("%s/%s/%s/%s", handler, metadata.Group, metadata.Kind, metadata.Name)
+	}
+	return fmt.Sprintf("%s/%s/%s/%s/%s", handler, metadata.Group, metadata.Kind, metadata.Namespace, metadata.Name)
+}
+
+// newFailureNotification returns a FailureNotification of notificationType from handler for object,
+// with the dedup key and metadata of object
+func newFailureNotification(notificationType FailureNotificationType, handler string, object resource.Object) FailureNotification {
+	md := object.GetStaticMetadata()
+	return FailureNotification{
+		Type:      notificationType,
+		DedupKey:  FailureDedupKey(handler, md),
+		Handler:   handler,
+		Group:     md.Group,
+		Version:   md.Version,
+		Kind:      md.Kind,
+		Namespace: md.Namespace,
+		Name:      md.Name,
+		Time:      time.Now(),
+	}
+}
+
+// objectFailures are the consecutive failed reconciles of an object
+type objectFailures struct {
+	count    int
+	first    time.Time
+	notified bool
+}
+
+// failureTracker tracks consecutive failed reconciles of objects, and queues FailureNotifications for a FailureNotifier
+type failureTracker struct {
+	notifier      FailureNotifier
+	threshold     FailureThreshold
+	mux           sync.Mutex
+	failures      map[string]*objectFailures
+	notifications chan FailureNotification
+}
+
+func newFailureTracker(notifier FailureNotifier, threshold FailureThreshold) *failureTracker {
+	return &failureTracker{
+		notifier:      notifier,
+		threshold:     threshold,
+		failures:      make(map[string]*objectFailures),
+		notifications: make(chan FailureNotification, defaultFailureNotificationBufferSize),
+	}
+}
+
+// observeReconcile records the result of a reconcile of object for action by handler, queueing a SustainedFailure notification
+// if err reaches the threshold, or a Resolved notification if err is nil and the object's failures have been notified
+func (f *failureTracker) observeReconcile(ctx context.Context, handler string, object resource.Object, action ResourceAction, err error) {
+	key := FailureDedupKey(handler, object.GetStaticMetadata())
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	current, ok := f.failures[key]
+	if err == nil {
+		if !ok {
+			return
+		}
+		delete(f.failures, key)
+		if current.notified {
+			n := newFailureNotification(FailureNotificationTypeResolved, handler, object)
+			n.Action = action
+			n.Failures = current.count
+			n.FirstFailure = current.first
+			f.queue(ctx, n)
+		}
+		return
+	}
+	if !ok {
+		current = &objectFailures{first: time.Now()}
+		f.failures[key] = current
+	}
+	current.count++
+	if current.notified || !f.threshold.reached(current.count, time.Since(current.first)) {
+		return
+	}
+	current.notified = true
+	n := newFailureNotification(FailureNotificationTypeSustainedFailure, handler, object)
+	n.Action = action
+	n.Failures = current.count
+	n.FirstFailure = current.first
+	n.Error = err.Error()
+	n.RequestID = RequestIDFromContext(ctx)
+	f.queue(ctx, n)
+}
+
+// deadLetter queues a DeadLettered notification for letter from handler
+func (f *failureTracker) deadLetter(ctx context.Context, handler string, letter DeadLetter) {
+	n := newFailureNotification(FailureNotificationTypeDeadLettered, handler, letter.Object)
+	n.Action = letter.Action
+	n.Failures = len(letter.Errors)
+	n.RequestID = letter.RequestID
+	if err := letter.Err(); err != nil {
+		n.Error = err.Error()
+	}
+	key := n.DedupKey
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	// Mark the object as notified, so that a later successful reconcile resolves the notification
+	current, ok := f.failures[key]
+	if !ok {
+		// Watchers' failures are only tracked by their retries, so the letter has the first failure
+		first := letter.FirstFailure
+		if first.IsZero() {
+			first = time.Now()
+		}
+		current = &objectFailures{count: n.Failures, first: first}
+		f.failures[key] = current
+	}
+	current.notified = true
+	n.FirstFailure = current.first
+	f.queue(ctx, n)
+}
+
+// queue queues n to be sent to the notifier, or drops it if the queue is full. The caller must hold f.mux.
+func (f *failureTracker) queue(ctx context.Context, n FailureNotification) {
+	select {
+	case f.notifications <- n:
+	default:
+		logging.FromContext(ctx).Warn("dropping failure notification, as the notification queue is full",
+			"type", n.Type, "dedupKey", n.DedupKey)
+	}
+}
+
+// run sends queued notifications to the notifier until ctx is canceled
+func (f *failureTracker) run(ctx context.Context) {
+	for {
+		select {
+		case n := <-f.notifications:
+			if err := f.notifier.Notify(ctx, n); err != nil {
+				logging.FromContext(ctx).Error("error sending failure notification",
+					"type", n.Type, "dedupKey", n.DedupKey, "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// WebhookFailureNotifierConfig is the configuration for a WebhookFailureNotifier
+type WebhookFailureNotifierConfig struct {
+	// URL is the URL which notifications are POSTed to
+	URL string
+	// Headers are added to every request, such as an Authorization header
+	Headers map[string]string
+	// Client is the client used to make requests. If nil, a client with a 10 second timeout is used.
+	Client *http.Client
+	// LinkFunc, if non-nil, returns a link to details of the object of a notification, such as a dashboard,
+	// which is sent as the link_to_upstream_details of the payload
+	LinkFunc func(FailureNotification) string
+}
+
+// WebhookFailureNotificationPayload is the JSON body of the requests made by a WebhookFailureNotifier.
+// The alert_uid, title, state, message, and link_to_upstream_details fields are those of a Grafana OnCall
+// formatted webhook integration, so that notifications for the same object (by DedupKey) are grouped into one alert group,
+// which is resolved by the FailureNotificationTypeResolved notification. The fields of the FailureNotification
+// are included for other receivers.
+type WebhookFailureNotificationPayload struct {
+	AlertUID string `json:"alert_uid"`
+	Title    string `json:"title"`
+	// State is "alerting" for failure notifications, and "ok" for Resolved notifications
+	State                 string `json:"state"`
+	Message               string `json:"message"`
+	LinkToUpstreamDetails string `json:"link_to_upstream_details,omitempty"`
+	FailureNotification
+}
+
+// WebhookFailureNotifier is a FailureNotifier which POSTs each notification as JSON to a webhook,
+// such as a Grafana OnCall or Grafana Incident webhook integration (see WebhookFailureNotificationPayload)
+type WebhookFailureNotifier struct {
+	config WebhookFailureNotifierConfig
+	client *http.Client
+}
+
+// NewWebhookFailureNotifier creates a new WebhookFailureNotifier with config
+func NewWebhookFailureNotifier(config WebhookFailureNotifierConfig) (*WebhookFailureNotifier, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("url cannot be empty")
+	}
+	if _, err := url.Parse(config.URL); err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	client := config.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookFailureNotifier{
+		config: config,
+		client: client,
+	}, nil
+}
+
+// Notify POSTs notification to the webhook. It returns an error if the webhook does not respond with a 2XX status code.
+func (w *WebhookFailureNotifier) Notify(ctx context.Context, notification FailureNotification) error {
+	payload := WebhookFailureNotificationPayload{
+		AlertUID:            notification.DedupKey,
+		State:               "alerting",
+		FailureNotification: notification,
+	}
+	object := notification.Kind + " " + notification.Name
+	if notification.Namespace != "" {
+		object = fmt.Sprintf("%s %s/%s", notification.Kind, notification.Namespace, notification.Name)
+	}
+	switch notification.Type {
+	case FailureNotificationTypeResolved:
+		payload.State = "ok"
+		payload.Title = fmt.Sprintf("%s is reconciling successfully", object)
+		payload.Message = fmt.Sprintf("%s succeeded after %d failed attempt(s)", notification.Action, notification.Failures)
+	case FailureNotificationTypeDeadLettered:
+		payload.Title = fmt.Sprintf("%s %s failed and will not be retried", object, notification.Action)
+		payload.Message = fmt.Sprintf("%s failed after %d attempt(s): %s", notification.Action, notification.Failures, notification.Error)
+	default:
+		payload.Title = fmt.Sprintf("%s is failing to reconcile", object)
+		payload.Message = fmt.Sprintf("%s has failed %d consecutive time(s) since %s: %s", notification.Action,
+			notification.Failures, notification.FirstFailure.Format(time.RFC3339), notification.Error)
+	}
+	if w.config.LinkFunc != nil {
+		payload.LinkToUpstreamDetails = w.config.LinkFunc(notification)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.config.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused
+	//nolint:errcheck
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status code %d", resp.StatusCode)
+	}
+	return nil
+}
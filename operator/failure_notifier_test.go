@@ -0,0 +1,215 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInformerController_FailureNotifier(t *testing.T) {
+	notifications := make(chan FailureNotification, 10)
+	c := NewInformerController(InformerControllerConfig{
+		RetryPolicy: func(_ error, attempt int) (bool, time.Duration) {
+			return attempt < 5, 0
+		},
+		FailureNotifier: FailureNotifierFunc(func(_ context.Context, n FailureNotification) error {
+			notifications <- n
+			return nil
+		}),
+		FailureThreshold: FailureThreshold{Failures: 3},
+	})
+	c.retryTickerInterval = 10 * time.Millisecond
+	inf := &testInformer{}
+	require.Nil(t, c.AddInformer(inf, "foo"))
+	mux := sync.Mutex{}
+	failUntil := 4
+	calls := 0
+	require.Nil(t, c.AddReconciler(&SimpleReconciler{
+		ReconcileFunc: func(context.Context, ReconcileRequest) (ReconcileResult, error) {
+			mux.Lock()
+			defer mux.Unlock()
+			calls++
+			if calls <= failUntil {
+				return ReconcileResult{}, fmt.Errorf("attempt %d", calls)
+			}
+			return ReconcileResult{}, nil
+		},
+	}, "foo"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+	obj := newListerTestObject("ns", "a")
+	inf.FireAdd(context.Background(), obj)
+
+	receive := func() FailureNotification {
+		select {
+		case n := <-notifications:
+			return n
+		case <-time.After(time.Second):
+			t.Fatal("expected a failure notification")
+			return FailureNotification{}
+		}
+	}
+
+	n := receive()
+	assert.Equal(t, FailureNotificationTypeSustainedFailure, n.Type)
+	assert.Equal(t, "reconcile:foo:0", n.Handler)
+	assert.Equal(t, FailureDedupKey("reconcile:foo:0", obj.GetStaticMetadata()), n.DedupKey)
+	assert.Equal(t, "ns", n.Namespace)
+	assert.Equal(t, "a", n.Name)
+	assert.Equal(t, ResourceActionCreate, n.Action)
+	assert.Equal(t, 3, n.Failures)
+	assert.Equal(t, "attempt 3", n.Error)
+	assert.False(t, n.FirstFailure.IsZero())
+
+	// The fourth failure doesn't send another notification, and the fifth call resolves it
+	resolved := receive()
+	assert.Equal(t, FailureNotificationTypeResolved, resolved.Type)
+	assert.Equal(t, n.DedupKey, resolved.DedupKey)
+	assert.Equal(t, 4, resolved.Failures)
+	assert.Empty(t, resolved.Error)
+
+	t.Run("dead lettered", func(t *testing.T) {
+		c.RetryPolicy = func(error, int) (bool, time.Duration) {
+			return false, 0
+		}
+		mux.Lock()
+		calls = 0
+		failUntil = 1
+		mux.Unlock()
+		inf.FireUpdate(context.Background(), obj, obj)
+		n := receive()
+		assert.Equal(t, FailureNotificationTypeDeadLettered, n.Type)
+		assert.Equal(t, ResourceActionUpdate, n.Action)
+		assert.Equal(t, 1, n.Failures)
+		assert.Equal(t, "attempt 1", n.Error)
+		assert.NotEmpty(t, n.RequestID)
+		assert.False(t, n.FirstFailure.IsZero())
+
+		// The next successful reconcile resolves the dead letter
+		inf.FireUpdate(context.Background(), obj, obj)
+		assert.Equal(t, FailureNotificationTypeResolved, receive().Type)
+	})
+}
+
+func TestFailureThreshold_Duration(t *testing.T) {
+	notifications := make(chan FailureNotification, 10)
+	tracker := newFailureTracker(FailureNotifierFunc(func(context.Context, FailureNotification) error {
+		return nil
+	}), FailureThreshold{Duration: 20 * time.Millisecond})
+	tracker.notifications = notifications
+	obj := newListerTestObject("ns", "a")
+
+	tracker.observeReconcile(context.Background(), "reconcile:foo:0", obj, ResourceActionCreate, fmt.Errorf("first"))
+	assert.Len(t, notifications, 0)
+	time.Sleep(25 * time.Millisecond)
+	tracker.observeReconcile(context.Background(), "reconcile:foo:0", obj, ResourceActionCreate, fmt.Errorf("second"))
+	require.Len(t, notifications, 1)
+	n := <-notifications
+	assert.Equal(t, FailureNotificationTypeSustainedFailure, n.Type)
+	assert.Equal(t, 2, n.Failures)
+
+	// Successful reconciles of objects without notified failures don't send notifications
+	other := newListerTestObject("ns", "b")
+	tracker.observeReconcile(context.Background(), "reconcile:foo:0", other, ResourceActionCreate, fmt.Errorf("first"))
+	tracker.observeReconcile(context.Background(), "reconcile:foo:0", other, ResourceActionCreate, nil)
+	assert.Len(t, notifications, 0)
+
+	// Failures are tracked separately for each handler, so another reconciler's success doesn't resolve them
+	tracker.observeReconcile(context.Background(), "reconcile:foo:1", obj, ResourceActionCreate, nil)
+	assert.Len(t, notifications, 0)
+	tracker.observeReconcile(context.Background(), "reconcile:foo:0", obj, ResourceActionCreate, nil)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, FailureNotificationTypeResolved, (<-notifications).Type)
+}
+
+func TestFailureTracker_DeadLetter(t *testing.T) {
+	notifications := make(chan FailureNotification, 10)
+	tracker := newFailureTracker(FailureNotifierFunc(func(context.Context, FailureNotification) error {
+		return nil
+	}), FailureThreshold{})
+	tracker.notifications = notifications
+	obj := newListerTestObject("ns", "a")
+	first := time.Now().Add(-time.Minute)
+
+	// A watcher's dead letter has no tracked failures, so its first failure comes from the letter
+	tracker.deadLetter(context.Background(), "foo:0", DeadLetter{
+		Action:       ResourceActionCreate,
+		Object:       obj,
+		Errors:       []error{fmt.Errorf("first"), fmt.Errorf("second")},
+		FirstFailure: first,
+	})
+	require.Len(t, notifications, 1)
+	n := <-notifications
+	assert.Equal(t, FailureNotificationTypeDeadLettered, n.Type)
+	assert.Equal(t, "foo:0", n.Handler)
+	assert.True(t, first.Equal(n.FirstFailure))
+	assert.Equal(t, 2, n.Failures)
+}
+
+func TestHandlerForRetryKey(t *testing.T) {
+	c := NewInformerController(InformerControllerConfig{})
+	obj := newListerTestObject("ns", "a")
+	assert.Equal(t, "foo:1", handlerForRetryKey(c.keyForWatcherEvent("foo", 1, obj)))
+	assert.Equal(t, "reconcile:foo:0", handlerForRetryKey(c.keyForReconcilerEvent("foo", 0, obj)))
+	assert.Equal(t, "reconcile:foo:0", handlerForRetryKey(c.keyForReconcilerEvent("foo", 0, newListerTestObject("", "a"))))
+}
+
+func TestWebhookFailureNotifier(t *testing.T) {
+	_, err := NewWebhookFailureNotifier(WebhookFailureNotifierConfig{})
+	assert.EqualError(t, err, "url cannot be empty")
+
+	payloads := make(chan WebhookFailureNotificationPayload, 10)
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		payload := WebhookFailureNotificationPayload{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		payloads <- payload
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	notifier, err := NewWebhookFailureNotifier(WebhookFailureNotifierConfig{
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		LinkFunc: func(n FailureNotification) string {
+			return "https://grafana.example/d/objects?var-name=" + n.Name
+		},
+	})
+	require.NoError(t, err)
+
+	notification := newFailureNotification(FailureNotificationTypeSustainedFailure, "reconcile:foo:0", newListerTestObject("ns", "a"))
+	notification.Action = ResourceActionCreate
+	notification.Failures = 3
+	notification.Error = "failed"
+	require.NoError(t, notifier.Notify(context.Background(), notification))
+	payload := <-payloads
+	assert.Equal(t, notification.DedupKey, payload.AlertUID)
+	assert.Equal(t, "alerting", payload.State)
+	assert.Contains(t, payload.Title, "ns/a")
+	assert.Contains(t, payload.Message, "failed")
+	assert.Equal(t, "https://grafana.example/d/objects?var-name=a", payload.LinkToUpstreamDetails)
+	assert.Equal(t, FailureNotificationTypeSustainedFailure, payload.Type)
+	assert.Equal(t, 3, payload.Failures)
+
+	notification.Type = FailureNotificationTypeResolved
+	require.NoError(t, notifier.Notify(context.Background(), notification))
+	payload = <-payloads
+	assert.Equal(t, notification.DedupKey, payload.AlertUID)
+	assert.Equal(t, "ok", payload.State)
+
+	status = http.StatusInternalServerError
+	assert.EqualError(t, notifier.Notify(context.Background(), notification), "webhook responded with status code 500")
+}
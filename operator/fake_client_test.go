@@ -0,0 +1,105 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/k8s"
+	"github.com/grafana/grafana-app-sdk/resource"
+	"github.com/grafana/grafana-app-sdk/resource/fake"
+)
+
+// newTestUntypedKind returns a Kind of UntypedObjects in the test.grafana.app group, with a JSON codec
+func newTestUntypedKind(kind, version string, scope resource.SchemaScope) resource.Kind {
+	return resource.Kind{
+		Schema: resource.NewSimpleSchema("test.grafana.app", version, &resource.UntypedObject{}, &resource.UntypedList{},
+			resource.WithKind(kind), resource.WithScope(scope)),
+		Codecs: map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()},
+	}
+}
+
+// newTestFakeClient returns a fake.Client for kind, seeded with copies of objects (which don't need their group,
+// version, and kind set), for tests of code which uses a resource.Client
+func newTestFakeClient(t *testing.T, kind resource.Kind, objects ...resource.Object) *fake.Client {
+	t.Helper()
+	seed := make([]resource.Object, 0, len(objects))
+	for _, obj := range objects {
+		cpy := obj.Copy()
+		cpy.GetObjectKind().SetGroupVersionKind(kind.GroupVersionKind())
+		seed = append(seed, cpy)
+	}
+	tracker, err := fake.NewTracker(seed...)
+	require.Nil(t, err)
+	client, err := fake.NewClient(kind, tracker)
+	require.Nil(t, err)
+	return client
+}
+
+// getTestObject gets the object with identifier from client, failing the test if it can't
+func getTestObject(t *testing.T, client resource.Client, identifier resource.Identifier) resource.Object {
+	t.Helper()
+	obj, err := client.Get(context.Background(), identifier)
+	require.Nil(t, err)
+	return obj
+}
+
+// testCRDClient is a CustomResourceDefinitionClient which stores CRDs as UntypedObjects in a fake.Client
+type testCRDClient struct {
+	*fake.Client
+}
+
+// newTestCRDClient returns a testCRDClient seeded with crds
+func newTestCRDClient(t *testing.T, crds ...*k8s.CustomResourceDefinition) *testCRDClient {
+	t.Helper()
+	kind := resource.Kind{
+		Schema: resource.NewSimpleSchema("apiextensions.k8s.io", "v1", &resource.UntypedObject{}, &resource.UntypedList{},
+			resource.WithKind("CustomResourceDefinition"), resource.WithPlural("customresourcedefinitions"), resource.WithScope(resource.ClusterScope)),
+		Codecs: map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()},
+	}
+	objects := make([]resource.Object, 0, len(crds))
+	for _, crd := range crds {
+		cpy := *crd
+		cpy.APIVersion = "apiextensions.k8s.io/v1"
+		cpy.Kind = "CustomResourceDefinition"
+		raw, err := json.Marshal(cpy)
+		require.Nil(t, err)
+		obj := &resource.UntypedObject{}
+		require.Nil(t, json.Unmarshal(raw, obj))
+		objects = append(objects, obj)
+	}
+	return &testCRDClient{
+		Client: newTestFakeClient(t, kind, objects...),
+	}
+}
+
+func (c *testCRDClient) GetCustomResourceDefinition(ctx context.Context, name string) (*k8s.CustomResourceDefinition, error) {
+	obj, err := c.Get(ctx, resource.Identifier{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	crd := &k8s.CustomResourceDefinition{}
+	return crd, json.Unmarshal(raw, crd)
+}
+
+func (c *testCRDClient) PatchCustomResourceDefinition(ctx context.Context, name string, patch resource.PatchRequest) error {
+	_, err := c.Patch(ctx, resource.Identifier{Name: name}, patch, resource.PatchOptions{})
+	return err
+}
+
+// patches returns the patches made to the CRDs
+func (c *testCRDClient) patches() []resource.PatchRequest {
+	patches := make([]resource.PatchRequest, 0)
+	for _, action := range c.Actions() {
+		if action.Verb == fake.VerbPatch {
+			patches = append(patches, action.Patch)
+		}
+	}
+	return patches
+}
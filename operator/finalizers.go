@@ -0,0 +1,211 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"k8s.io/utils/strings/slices"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// FinalizerCleanupFunc is the cleanup for a finalizer in a FinalizerSet,
+// which is called when an object with the finalizer is being deleted.
+type FinalizerCleanupFunc func(ctx context.Context, object resource.Object) error
+
+// FinalizerSet manages a set of named finalizers, each with its own cleanup func.
+// Registered finalizers are added to objects with EnsureFinalizers, and when an object is being deleted,
+// Finalize calls the cleanup for each registered finalizer on the object, and removes the finalizers
+// only once every cleanup has succeeded.
+//
+// A FinalizerSet can be used directly from a Reconciler or ResourceWatcher, or can wrap one with
+// WrapReconciler or WrapWatcher to handle finalizers automatically.
+// FinalizerSet contains unexported fields, and must be created with NewFinalizerSet.
+type FinalizerSet struct {
+	client     PatchClient
+	mux        sync.RWMutex
+	finalizers []string
+	cleanups   map[string]FinalizerCleanupFunc
+}
+
+// NewFinalizerSet creates a new, empty FinalizerSet which uses client to update object finalizers
+func NewFinalizerSet(client PatchClient) (*FinalizerSet, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+	return &FinalizerSet{
+		client:     client,
+		finalizers: make([]string, 0),
+		cleanups:   make(map[string]FinalizerCleanupFunc),
+	}, nil
+}
+
+// Register adds a finalizer to the set, with the cleanup func to call when an object with the finalizer is being deleted.
+// cleanup may be nil, in which case the finalizer is removed without any cleanup.
+// Cleanups are called in the order their finalizers were registered.
+func (f *FinalizerSet) Register(finalizer string, cleanup FinalizerCleanupFunc) error {
+	if finalizer == "" {
+		return fmt.Errorf("finalizer cannot be empty")
+	}
+	if len(finalizer) > 63 {
+		return fmt.Errorf("finalizer length cannot exceed 63 chars: %s", finalizer)
+	}
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if _, ok := f.cleanups[finalizer]; ok {
+		return fmt.Errorf("finalizer '%s' is already registered", finalizer)
+	}
+	f.finalizers = append(f.finalizers, finalizer)
+	f.cleanups[finalizer] = cleanup
+	return nil
+}
+
+// Finalizers returns the registered finalizers, in the order they were registered
+func (f *FinalizerSet) Finalizers() []string {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	return append([]string{}, f.finalizers...)
+}
+
+// Missing returns the registered finalizers which are not in the object's finalizer list
+func (f *FinalizerSet) Missing(object resource.Object) []string {
+	missing := make([]string, 0)
+	for _, finalizer := range f.Finalizers() {
+		if !slices.Contains(object.GetFinalizers(), finalizer) {
+			missing = append(missing, finalizer)
+		}
+	}
+	return missing
+}
+
+// Pending returns the registered finalizers which are in the object's finalizer list
+func (f *FinalizerSet) Pending(object resource.Object) []string {
+	pending := make([]string, 0)
+	for _, finalizer := range f.Finalizers() {
+		if slices.Contains(object.GetFinalizers(), finalizer) {
+			pending = append(pending, finalizer)
+		}
+	}
+	return pending
+}
+
+// EnsureFinalizers adds any missing registered finalizers to the object.
+// If the object is being deleted, or already has every registered finalizer, no update is made.
+// The object is updated in-place with the patched object returned by the API server.
+func (f *FinalizerSet) EnsureFinalizers(ctx context.Context, object resource.Object) error {
+	if object.GetDeletionTimestamp() != nil {
+		return nil
+	}
+	missing := f.Missing(object)
+	if len(missing) == 0 {
+		return nil
+	}
+	logging.FromContext(ctx).Debug("Adding missing finalizers", "component", "FinalizerSet", "finalizers", missing)
+	return f.client.PatchInto(ctx, object.GetStaticMetadata().Identifier(), resource.PatchRequest{
+		Operations: []resource.PatchOperation{{
+			Operation: resource.PatchOpAdd,
+			Path:      "/metadata/finalizers",
+			Value:     append(append([]string{}, object.GetFinalizers()...), missing...),
+		}},
+	}, resource.PatchOptions{}, object)
+}
+
+// Finalize calls the cleanup for each registered finalizer in the object's finalizer list,
+// then removes those finalizers from the object if every cleanup succeeded.
+// If any cleanup fails, no finalizers are removed, and the cleanup errors are returned joined together,
+// so the whole set of cleanups should be retried (cleanups must therefore be idempotent).
+// It returns nil without any changes if the object has none of the registered finalizers.
+// The object is updated in-place with the patched object returned by the API server.
+func (f *FinalizerSet) Finalize(ctx context.Context, object resource.Object) error {
+	pending := f.Pending(object)
+	if len(pending) == 0 {
+		return nil
+	}
+	logger := logging.FromContext(ctx).With("component", "FinalizerSet", "kind", object.GroupVersionKind().Kind, "namespace", object.GetNamespace(), "name", object.GetName())
+
+	errs := make([]error, 0)
+	for _, finalizer := range pending {
+		f.mux.RLock()
+		cleanup := f.cleanups[finalizer]
+		f.mux.RUnlock()
+		if cleanup == nil {
+			continue
+		}
+		if err := cleanup(ctx, object); err != nil {
+			logger.Debug("Finalizer cleanup failed", "finalizer", finalizer, "error", err)
+			errs = append(errs, fmt.Errorf("cleanup for finalizer '%s' failed: %w", finalizer, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	remaining := make([]string, 0)
+	for _, finalizer := range object.GetFinalizers() {
+		if !slices.Contains(pending, finalizer) {
+			remaining = append(remaining, finalizer)
+		}
+	}
+	logger.Debug("All finalizer cleanups succeeded, removing finalizers", "finalizers", pending)
+	return f.client.PatchInto(ctx, object.GetStaticMetadata().Identifier(), resource.PatchRequest{
+		Operations: []resource.PatchOperation{{
+			Operation: resource.PatchOpReplace,
+			Path:      "/metadata/finalizers",
+			Value:     remaining,
+		}},
+	}, resource.PatchOptions{}, object)
+}
+
+// WrapReconciler returns a Reconciler which manages the FinalizerSet's finalizers for reconciler.
+// Objects which are not being deleted have any missing finalizers added before reconciler is called.
+// Objects which are being deleted and still have registered finalizers are finalized with Finalize instead of being
+// passed to reconciler. All other requests, including deletes, are passed to reconciler unchanged.
+func (f *FinalizerSet) WrapReconciler(reconciler Reconciler) Reconciler {
+	return &SimpleReconciler{
+		ReconcileFunc: func(ctx context.Context, request ReconcileRequest) (ReconcileResult, error) {
+			if request.Action != ReconcileActionDeleted {
+				if request.Object.GetDeletionTimestamp() != nil && len(f.Pending(request.Object)) > 0 {
+					return ReconcileResult{}, f.Finalize(ctx, request.Object)
+				}
+				if err := f.EnsureFinalizers(ctx, request.Object); err != nil {
+					return ReconcileResult{}, fmt.Errorf("error adding finalizers: %w", err)
+				}
+			}
+			if reconciler == nil {
+				return ReconcileResult{}, nil
+			}
+			return reconciler.Reconcile(ctx, request)
+		},
+	}
+}
+
+// WrapWatcher returns a ResourceWatcher which manages the FinalizerSet's finalizers for watcher,
+// in the same way as WrapReconciler: missing finalizers are added before Add and Update are called,
+// and objects which are being deleted are finalized with Finalize instead of being passed to watcher. watcher cannot be nil.
+func (f *FinalizerSet) WrapWatcher(watcher ResourceWatcher) ResourceWatcher {
+	handle := func(ctx context.Context, object resource.Object, next func() error) error {
+		if object.GetDeletionTimestamp() != nil && len(f.Pending(object)) > 0 {
+			return f.Finalize(ctx, object)
+		}
+		if err := f.EnsureFinalizers(ctx, object); err != nil {
+			return fmt.Errorf("error adding finalizers: %w", err)
+		}
+		return next()
+	}
+	return &SimpleWatcher{
+		AddFunc: func(ctx context.Context, object resource.Object) error {
+			return handle(ctx, object, func() error {
+				return watcher.Add(ctx, object)
+			})
+		},
+		UpdateFunc: func(ctx context.Context, src resource.Object, tgt resource.Object) error {
+			return handle(ctx, tgt, func() error {
+				return watcher.Update(ctx, src, tgt)
+			})
+		},
+		DeleteFunc: watcher.Delete,
+	}
+}
@@ -0,0 +1,254 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestFinalizerSet_Register(t *testing.T) {
+	_, err := NewFinalizerSet(nil)
+	assert.EqualError(t, err, "client cannot be nil")
+
+	set, err := NewFinalizerSet(&mockPatchClient{})
+	require.Nil(t, err)
+	assert.EqualError(t, set.Register("", nil), "finalizer cannot be empty")
+	assert.NotNil(t, set.Register("a-very-long-finalizer-name-which-is-definitely-over-sixty-three-characters", nil))
+	assert.Nil(t, set.Register("foo", nil))
+	assert.Nil(t, set.Register("bar", nil))
+	assert.EqualError(t, set.Register("foo", nil), "finalizer 'foo' is already registered")
+	assert.Equal(t, []string{"foo", "bar"}, set.Finalizers())
+}
+
+func TestFinalizerSet_EnsureFinalizers(t *testing.T) {
+	client := &mockPatchClient{}
+	set, err := NewFinalizerSet(client)
+	require.Nil(t, err)
+	require.Nil(t, set.Register("foo", nil))
+	require.Nil(t, set.Register("bar", nil))
+
+	t.Run("adds missing", func(t *testing.T) {
+		obj := &resource.TypedSpecObject[string]{}
+		obj.SetFinalizers([]string{"other", "bar"})
+		patched := false
+		client.PatchIntoFunc = func(_ context.Context, _ resource.Identifier, req resource.PatchRequest, _ resource.PatchOptions, _ resource.Object) error {
+			patched = true
+			require.Len(t, req.Operations, 1)
+			assert.Equal(t, resource.PatchOpAdd, req.Operations[0].Operation)
+			assert.Equal(t, "/metadata/finalizers", req.Operations[0].Path)
+			assert.Equal(t, []string{"other", "bar", "foo"}, req.Operations[0].Value)
+			return nil
+		}
+		assert.Nil(t, set.EnsureFinalizers(context.Background(), obj))
+		assert.True(t, patched)
+	})
+
+	t.Run("nothing missing", func(t *testing.T) {
+		obj := &resource.TypedSpecObject[string]{}
+		obj.SetFinalizers([]string{"foo", "bar"})
+		client.PatchIntoFunc = func(context.Context, resource.Identifier, resource.PatchRequest, resource.PatchOptions, resource.Object) error {
+			assert.Fail(t, "patch should not be called")
+			return nil
+		}
+		assert.Nil(t, set.EnsureFinalizers(context.Background(), obj))
+	})
+
+	t.Run("being deleted", func(t *testing.T) {
+		obj := &resource.TypedSpecObject[string]{}
+		dt := metav1.NewTime(time.Now())
+		obj.SetDeletionTimestamp(&dt)
+		client.PatchIntoFunc = func(context.Context, resource.Identifier, resource.PatchRequest, resource.PatchOptions, resource.Object) error {
+			assert.Fail(t, "patch should not be called")
+			return nil
+		}
+		assert.Nil(t, set.EnsureFinalizers(context.Background(), obj))
+	})
+}
+
+func TestFinalizerSet_Finalize(t *testing.T) {
+	client := &mockPatchClient{}
+	set, err := NewFinalizerSet(client)
+	require.Nil(t, err)
+	var fooErr error
+	calls := make([]string, 0)
+	require.Nil(t, set.Register("foo", func(context.Context, resource.Object) error {
+		calls = append(calls, "foo")
+		return fooErr
+	}))
+	require.Nil(t, set.Register("bar", func(context.Context, resource.Object) error {
+		calls = append(calls, "bar")
+		return nil
+	}))
+	require.Nil(t, set.Register("baz", nil))
+	newObj := func() resource.Object {
+		obj := &resource.TypedSpecObject[string]{}
+		dt := metav1.NewTime(time.Now())
+		obj.SetDeletionTimestamp(&dt)
+		obj.SetFinalizers([]string{"foo", "other", "bar", "baz"})
+		return obj
+	}
+
+	t.Run("cleanup error", func(t *testing.T) {
+		calls = calls[:0]
+		fooErr = errors.New("I AM ERROR")
+		client.PatchIntoFunc = func(context.Context, resource.Identifier, resource.PatchRequest, resource.PatchOptions, resource.Object) error {
+			assert.Fail(t, "finalizers should not be removed if a cleanup fails")
+			return nil
+		}
+		err := set.Finalize(context.Background(), newObj())
+		assert.ErrorIs(t, err, fooErr)
+		// Every cleanup is still attempted
+		assert.Equal(t, []string{"foo", "bar"}, calls)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		calls = calls[:0]
+		fooErr = nil
+		patched := false
+		client.PatchIntoFunc = func(_ context.Context, _ resource.Identifier, req resource.PatchRequest, _ resource.PatchOptions, _ resource.Object) error {
+			patched = true
+			require.Len(t, req.Operations, 1)
+			assert.Equal(t, resource.PatchOpReplace, req.Operations[0].Operation)
+			assert.Equal(t, []string{"other"}, req.Operations[0].Value)
+			return nil
+		}
+		assert.Nil(t, set.Finalize(context.Background(), newObj()))
+		assert.True(t, patched)
+		assert.Equal(t, []string{"foo", "bar"}, calls)
+	})
+
+	t.Run("no registered finalizers", func(t *testing.T) {
+		calls = calls[:0]
+		obj := newObj()
+		obj.SetFinalizers([]string{"other"})
+		client.PatchIntoFunc = func(context.Context, resource.Identifier, resource.PatchRequest, resource.PatchOptions, resource.Object) error {
+			assert.Fail(t, "patch should not be called")
+			return nil
+		}
+		assert.Nil(t, set.Finalize(context.Background(), obj))
+		assert.Empty(t, calls)
+	})
+}
+
+func TestFinalizerSet_WrapReconciler(t *testing.T) {
+	client := &mockPatchClient{}
+	set, err := NewFinalizerSet(client)
+	require.Nil(t, err)
+	cleaned := false
+	require.Nil(t, set.Register("foo", func(context.Context, resource.Object) error {
+		cleaned = true
+		return nil
+	}))
+	reconciled := make([]ReconcileAction, 0)
+	reconciler := set.WrapReconciler(&SimpleReconciler{
+		ReconcileFunc: func(_ context.Context, req ReconcileRequest) (ReconcileResult, error) {
+			reconciled = append(reconciled, req.Action)
+			return ReconcileResult{}, nil
+		},
+	})
+
+	t.Run("create adds finalizer", func(t *testing.T) {
+		reconciled = reconciled[:0]
+		patched := false
+		client.PatchIntoFunc = func(context.Context, resource.Identifier, resource.PatchRequest, resource.PatchOptions, resource.Object) error {
+			patched = true
+			return nil
+		}
+		_, err := reconciler.Reconcile(context.Background(), ReconcileRequest{
+			Action: ReconcileActionCreated,
+			Object: &resource.TypedSpecObject[string]{},
+		})
+		assert.Nil(t, err)
+		assert.True(t, patched)
+		assert.Equal(t, []ReconcileAction{ReconcileActionCreated}, reconciled)
+	})
+
+	t.Run("patch error", func(t *testing.T) {
+		reconciled = reconciled[:0]
+		patchErr := errors.New("I AM ERROR")
+		client.PatchIntoFunc = func(context.Context, resource.Identifier, resource.PatchRequest, resource.PatchOptions, resource.Object) error {
+			return patchErr
+		}
+		_, err := reconciler.Reconcile(context.Background(), ReconcileRequest{
+			Action: ReconcileActionUpdated,
+			Object: &resource.TypedSpecObject[string]{},
+		})
+		assert.ErrorIs(t, err, patchErr)
+		assert.Empty(t, reconciled)
+	})
+
+	t.Run("deleting object is finalized", func(t *testing.T) {
+		reconciled = reconciled[:0]
+		obj := &resource.TypedSpecObject[string]{}
+		dt := metav1.NewTime(time.Now())
+		obj.SetDeletionTimestamp(&dt)
+		obj.SetFinalizers([]string{"foo"})
+		client.PatchIntoFunc = nil
+		_, err := reconciler.Reconcile(context.Background(), ReconcileRequest{
+			Action: ReconcileActionUpdated,
+			Object: obj,
+		})
+		assert.Nil(t, err)
+		assert.True(t, cleaned)
+		assert.Empty(t, reconciled)
+
+		// The delete is still passed through
+		_, err = reconciler.Reconcile(context.Background(), ReconcileRequest{
+			Action: ReconcileActionDeleted,
+			Object: obj,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []ReconcileAction{ReconcileActionDeleted}, reconciled)
+	})
+}
+
+func TestFinalizerSet_WrapWatcher(t *testing.T) {
+	client := &mockPatchClient{}
+	set, err := NewFinalizerSet(client)
+	require.Nil(t, err)
+	cleaned := false
+	require.Nil(t, set.Register("foo", func(context.Context, resource.Object) error {
+		cleaned = true
+		return nil
+	}))
+	calls := make([]string, 0)
+	watcher := set.WrapWatcher(&SimpleWatcher{
+		AddFunc: func(context.Context, resource.Object) error {
+			calls = append(calls, "add")
+			return nil
+		},
+		UpdateFunc: func(context.Context, resource.Object, resource.Object) error {
+			calls = append(calls, "update")
+			return nil
+		},
+		DeleteFunc: func(context.Context, resource.Object) error {
+			calls = append(calls, "delete")
+			return nil
+		},
+	})
+
+	patched := 0
+	client.PatchIntoFunc = func(context.Context, resource.Identifier, resource.PatchRequest, resource.PatchOptions, resource.Object) error {
+		patched++
+		return nil
+	}
+	assert.Nil(t, watcher.Add(context.Background(), &resource.TypedSpecObject[string]{}))
+	assert.Equal(t, 1, patched)
+
+	obj := &resource.TypedSpecObject[string]{}
+	dt := metav1.NewTime(time.Now())
+	obj.SetDeletionTimestamp(&dt)
+	obj.SetFinalizers([]string{"foo"})
+	assert.Nil(t, watcher.Update(context.Background(), &resource.TypedSpecObject[string]{}, obj))
+	assert.True(t, cleaned)
+	assert.Equal(t, 2, patched)
+	assert.Nil(t, watcher.Delete(context.Background(), obj))
+	assert.Equal(t, []string{"add", "delete"}, calls)
+}
@@ -0,0 +1,221 @@
+package operator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// IdempotencyAnnotationPrefix is the prefix of the annotations used by the IdempotencyLedger implementations
+// in this package to record side effects.
+const IdempotencyAnnotationPrefix = "idempotency.grafana.app/"
+
+// IdempotencyKey returns a stable key for a step of reconciling the current generation of object.
+// The key is derived from the object's UID, generation, and the step, so it is the same across retries and
+// operator restarts, changes when the object's spec changes, and differs for an object which is deleted and recreated
+// with the same name. It can be used as the idempotency key of a call to an external system, such as the
+// Idempotency-Key header of a billing API, so that the external system can deduplicate repeated calls.
+func IdempotencyKey(object resource.Object, step string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%d/%s", object.GetUID(), object.GetGeneration(), step)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// IdempotencyLedger durably records the side effects which have been performed for objects,
+// so that reconcilers can skip side effects which were already performed by an earlier attempt.
+type IdempotencyLedger interface {
+	// IsRecorded returns true if the side effect of step identified by key has been recorded for object
+	IsRecorded(ctx context.Context, object resource.Object, step, key string) (bool, error)
+	// Record records that the side effect of step identified by key has been performed for object
+	Record(ctx context.Context, object resource.Object, step, key string) error
+}
+
+// PerformOnce calls fn with the IdempotencyKey for step of object, unless ledger has a record of the key,
+// and records the key once fn returns successfully. It returns true if fn was called and succeeded.
+//
+// If the operator stops after fn returns but before the key is recorded, fn is called again with the same key
+// once the object is reconciled again. Non-idempotent external calls in fn should pass the key to the external system,
+// so it can deduplicate the repeated call. If recording the key fails, the error is returned and the reconcile
+// should be retried, as fn would be called again.
+func PerformOnce(
+	ctx context.Context, ledger IdempotencyLedger, object resource.Object, step string, fn func(ctx context.Context, key string) error,
+) (bool, error) {
+	key := IdempotencyKey(object, step)
+	recorded, err := ledger.IsRecorded(ctx, object, step, key)
+	if err != nil {
+		return false, fmt.Errorf("unable to check the idempotency ledger for step %s: %w", step, err)
+	}
+	if recorded {
+		return false, nil
+	}
+	if err = fn(ctx, key); err != nil {
+		return false, err
+	}
+	if err = ledger.Record(ctx, object, step, key); err != nil {
+		return true, fmt.Errorf("unable to record step %s in the idempotency ledger: %w", step, err)
+	}
+	return true, nil
+}
+
+// NewAnnotationIdempotencyLedger returns an IdempotencyLedger which records the key of the latest side effect of each step
+// in the annotation IdempotencyAnnotationPrefix + step on the object itself, so step must be a valid annotation name.
+// As keys change with the object's generation, only one record is kept for each step.
+//
+// The reconciled object may be a cached copy which predates a record, so if the object's annotation doesn't match,
+// the ledger reads the object from client before reporting that a key is not recorded.
+// Records are added with a merge patch of the object's metadata, which doesn't change the object's generation.
+// The object passed to Record is not modified, as it may be shared (such as an object from an informer's cache).
+func NewAnnotationIdempotencyLedger(client resource.Client) IdempotencyLedger {
+	return &annotationIdempotencyLedger{
+		client: client,
+	}
+}
+
+type annotationIdempotencyLedger struct {
+	client resource.Client
+}
+
+func (a *annotationIdempotencyLedger) IsRecorded(ctx context.Context, object resource.Object, step, key string) (bool, error) {
+	annotation, err := idempotencyAnnotation(step)
+	if err != nil {
+		return false, err
+	}
+	if object.GetAnnotations()[annotation] == key {
+		return true, nil
+	}
+	current, err := a.client.Get(ctx, object.GetStaticMetadata().Identifier())
+	if err != nil {
+		return false, err
+	}
+	return current.GetAnnotations()[annotation] == key, nil
+}
+
+func (a *annotationIdempotencyLedger) Record(ctx context.Context, object resource.Object, step, key string) error {
+	annotation, err := idempotencyAnnotation(step)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{
+				annotation: key,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = a.client.Patch(ctx, object.GetStaticMetadata().Identifier(), resource.PatchRequest{
+		Type: resource.PatchTypeMergePatch,
+		Body: body,
+	}, resource.PatchOptions{})
+	return err
+}
+
+// idempotencyAnnotation returns the annotation the annotation IdempotencyLedger uses for step
+func idempotencyAnnotation(step string) (string, error) {
+	annotation := IdempotencyAnnotationPrefix + step
+	if errs := validation.IsQualifiedName(annotation); len(errs) > 0 {
+		return "", fmt.Errorf("step %s is not a valid annotation name: %s", step, strings.Join(errs, ", "))
+	}
+	return annotation, nil
+}
+
+// NewCompanionIdempotencyLedger returns an IdempotencyLedger which records side effects in the annotations of a
+// companion object for each object, so that the reconciled objects are not modified. client is the client for the
+// kind of the companion objects, and newCompanion returns a new, empty object of that kind (including any required spec).
+//
+// The companion object of an object is named "idempotency-<object UID>", is created in the same namespace
+// the first time a side effect is recorded, and is owned by the object, so it is garbage collected when the object is deleted.
+// Each key is recorded with the object's generation, and keys from earlier generations are removed when a new key is recorded.
+func NewCompanionIdempotencyLedger(client resource.Client, newCompanion func() resource.Object) IdempotencyLedger {
+	return &companionIdempotencyLedger{
+		client:       client,
+		newCompanion: newCompanion,
+	}
+}
+
+type companionIdempotencyLedger struct {
+	client       resource.Client
+	newCompanion func() resource.Object
+}
+
+func (c *companionIdempotencyLedger) IsRecorded(ctx context.Context, object resource.Object, _, key string) (bool, error) {
+	companion, err := c.client.Get(ctx, companionIdentifier(object))
+	if resource.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	_, ok := companion.GetAnnotations()[IdempotencyAnnotationPrefix+key]
+	return ok, nil
+}
+
+func (c *companionIdempotencyLedger) Record(ctx context.Context, object resource.Object, _, key string) error {
+	identifier := companionIdentifier(object)
+	generation := strconv.FormatInt(object.GetGeneration(), 10)
+	companion, err := c.client.Get(ctx, identifier)
+	if resource.IsNotFound(err) {
+		companion = c.newCompanion()
+		companion.SetNamespace(identifier.Namespace)
+		companion.SetName(identifier.Name)
+		meta := object.GetStaticMetadata()
+		companion.SetOwnerReferences([]metav1.OwnerReference{{
+			APIVersion: schema.GroupVersion{Group: meta.Group, Version: meta.Version}.String(),
+			Kind:       meta.Kind,
+			Name:       object.GetName(),
+			UID:        object.GetUID(),
+		}})
+		companion.SetAnnotations(map[string]string{
+			IdempotencyAnnotationPrefix + key: generation,
+		})
+		_, err = c.client.Create(ctx, identifier, companion, resource.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	annotations := map[string]any{
+		IdempotencyAnnotationPrefix + key: generation,
+	}
+	for k, v := range companion.GetAnnotations() {
+		if !strings.HasPrefix(k, IdempotencyAnnotationPrefix) {
+			continue
+		}
+		// Keys from earlier generations can never be checked again, as keys change with the generation
+		if gen, err := strconv.ParseInt(v, 10, 64); err == nil && gen < object.GetGeneration() {
+			annotations[k] = nil
+		}
+	}
+	body, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.client.Patch(ctx, identifier, resource.PatchRequest{
+		Type: resource.PatchTypeMergePatch,
+		Body: body,
+	}, resource.PatchOptions{})
+	return err
+}
+
+// companionIdentifier returns the identifier of the companion object of object used by the companion IdempotencyLedger
+func companionIdentifier(object resource.Object) resource.Identifier {
+	return resource.Identifier{
+		Namespace: object.GetNamespace(),
+		Name:      fmt.Sprintf("idempotency-%s", object.GetUID()),
+	}
+}
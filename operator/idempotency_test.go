@@ -0,0 +1,115 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	obj := newTestIdempotencyObject("foo", "abc", 1)
+	key := IdempotencyKey(obj, "bill")
+	assert.Len(t, key, 32)
+	assert.Equal(t, key, IdempotencyKey(obj, "bill"))
+	assert.NotEqual(t, key, IdempotencyKey(obj, "notify"))
+	assert.NotEqual(t, key, IdempotencyKey(newTestIdempotencyObject("foo", "abc", 2), "bill"))
+	assert.NotEqual(t, key, IdempotencyKey(newTestIdempotencyObject("foo", "def", 1), "bill"))
+}
+
+func TestPerformOnce_AnnotationLedger(t *testing.T) {
+	obj := newTestIdempotencyObject("foo", "abc", 1)
+	client := newTestFakeClient(t, newTestUntypedKind("Foo", "v1", resource.NamespacedScope), obj)
+	ledger := NewAnnotationIdempotencyLedger(client)
+
+	calls := make([]string, 0)
+	fn := func(_ context.Context, key string) error {
+		calls = append(calls, key)
+		return nil
+	}
+	performed, err := PerformOnce(context.Background(), ledger, obj, "bill", fn)
+	require.Nil(t, err)
+	assert.True(t, performed)
+	assert.Equal(t, []string{IdempotencyKey(obj, "bill")}, calls)
+	identifier := resource.Identifier{Namespace: "default", Name: "foo"}
+	assert.Equal(t, IdempotencyKey(obj, "bill"), getTestObject(t, client, identifier).GetAnnotations()[IdempotencyAnnotationPrefix+"bill"])
+	// The reconciled object may be shared with the informer cache, so it is not modified
+	assert.Empty(t, obj.GetAnnotations())
+
+	// A stale copy of the object (such as a retry from the informer cache) is checked against the API server
+	stale := newTestIdempotencyObject("foo", "abc", 1)
+	performed, err = PerformOnce(context.Background(), ledger, stale, "bill", fn)
+	require.Nil(t, err)
+	assert.False(t, performed)
+	assert.Len(t, calls, 1)
+
+	// A new generation performs the step again
+	obj.SetGeneration(2)
+	performed, err = PerformOnce(context.Background(), ledger, obj, "bill", fn)
+	require.Nil(t, err)
+	assert.True(t, performed)
+	assert.Len(t, calls, 2)
+
+	// Failures are not recorded
+	errFailed := errors.New("I AM ERROR")
+	performed, err = PerformOnce(context.Background(), ledger, obj, "notify", func(context.Context, string) error {
+		return errFailed
+	})
+	assert.Equal(t, errFailed, err)
+	assert.False(t, performed)
+	_, ok := getTestObject(t, client, identifier).GetAnnotations()[IdempotencyAnnotationPrefix+"notify"]
+	assert.False(t, ok)
+
+	_, err = PerformOnce(context.Background(), ledger, obj, "not/valid", fn)
+	assert.NotNil(t, err)
+}
+
+func TestPerformOnce_CompanionLedger(t *testing.T) {
+	client := newTestFakeClient(t, newTestUntypedKind("Foo", "v1", resource.NamespacedScope))
+	ledger := NewCompanionIdempotencyLedger(client, func() resource.Object {
+		return &resource.UntypedObject{}
+	})
+	obj := newTestIdempotencyObject("foo", "abc", 1)
+
+	calls := 0
+	fn := func(context.Context, string) error {
+		calls++
+		return nil
+	}
+	for _, step := range []string{"bill", "notify", "bill"} {
+		_, err := PerformOnce(context.Background(), ledger, obj, step, fn)
+		require.Nil(t, err)
+	}
+	assert.Equal(t, 2, calls)
+	companionIdentifier := resource.Identifier{Namespace: "default", Name: "idempotency-abc"}
+	companion := getTestObject(t, client, companionIdentifier)
+	require.Len(t, companion.GetOwnerReferences(), 1)
+	assert.Equal(t, "foo", companion.GetOwnerReferences()[0].Name)
+	assert.Equal(t, types.UID("abc"), companion.GetOwnerReferences()[0].UID)
+	assert.Len(t, companion.GetAnnotations(), 2)
+	// The reconciled object is not modified
+	assert.Empty(t, obj.GetAnnotations())
+
+	// Records from earlier generations are removed when a new one is recorded
+	obj.SetGeneration(2)
+	performed, err := PerformOnce(context.Background(), ledger, obj, "bill", fn)
+	require.Nil(t, err)
+	assert.True(t, performed)
+	assert.Equal(t, map[string]string{
+		IdempotencyAnnotationPrefix + IdempotencyKey(obj, "bill"): "2",
+	}, getTestObject(t, client, companionIdentifier).GetAnnotations())
+}
+
+func newTestIdempotencyObject(name, uid string, generation int64) *resource.UntypedObject {
+	obj := &resource.UntypedObject{}
+	obj.SetNamespace("default")
+	obj.SetName(name)
+	obj.SetUID(types.UID(uid))
+	obj.SetGeneration(generation)
+	return obj
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"math"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
 
 	"github.com/grafana/grafana-app-sdk/app"
 	"github.com/grafana/grafana-app-sdk/logging"
@@ -31,6 +35,9 @@ var ErrNilObject = errors.New("object cannot be nil")
 // ErrInformerAlreadyAdded indicates that there is already an informer for the resource kind mapped
 var ErrInformerAlreadyAdded = errors.New("informer for resource kind already added")
 
+// waitForSyncInterval is how often InformerController.WaitForSync checks whether informers have synced
+const waitForSyncInterval = 100 * time.Millisecond
+
 // DefaultRetryPolicy is an Exponential Backoff RetryPolicy with an initial 5-second delay and a max of 5 attempts
 var DefaultRetryPolicy = ExponentialBackoffRetryPolicy(5*time.Second, 5)
 
@@ -97,42 +104,84 @@ var OpinionatedRetryDequeuePolicy = func(newAction ResourceAction, newObject res
 // Unlike adding a Watcher directly to an Informer with AddEventHandler, the InformerController
 // guarantees sequential execution of watchers, based on add order.
 type InformerController struct {
-	// ErrorHandler is a user-specified error handling function. This is typically for logging/metrics use,
-	// as retry logic is covered by the RetryPolicy.
+	// ErrorReporter is sent a report of each error returned by a ResourceWatcher or Reconciler call.
+	// This is typically for logging/metrics use, as retry logic is covered by the RetryPolicy.
+	// If nil, ErrorHandler is called instead.
+	ErrorReporter app.ErrorReporter
+	// ErrorHandler is a user-specified error handling function, which is called with each error returned by a
+	// ResourceWatcher or Reconciler call if ErrorReporter is nil.
+	//
+	// Deprecated: use ErrorReporter, which receives the class, kind, and object of the error.
 	ErrorHandler func(context.Context, error)
 	// RetryPolicy is a user-specified retry logic function which will be used when ResourceWatcher function calls fail.
 	RetryPolicy RetryPolicy
 	// RetryDequeuePolicy is a user-specified retry dequeue logic function which will be used for new informer actions
 	// when one or more retries for the object are still pending. If not present, existing retries are always dequeued.
-	RetryDequeuePolicy  RetryDequeuePolicy
-	informers           *ListMap[string, Informer]
-	watchers            *ListMap[string, ResourceWatcher]
-	reconcilers         *ListMap[string, Reconciler]
-	toRetry             *ListMap[string, retryInfo]
-	retryTickerInterval time.Duration
-	runner              *app.DynamicMultiRunner
-	totalEvents         *prometheus.CounterVec
-	reconcileLatency    *prometheus.HistogramVec
-	reconcilerLatency   *prometheus.HistogramVec
-	watcherLatency      *prometheus.HistogramVec
-	inflightActions     *prometheus.GaugeVec
-	inflightEvents      *prometheus.GaugeVec
+	RetryDequeuePolicy RetryDequeuePolicy
+	// DeadLetterHandler is called with each event which the RetryPolicy has stopped retrying. If nil, those events are dropped.
+	DeadLetterHandler DeadLetterHandler
+	// EventRecorder is added to the context of every watcher and reconciler call, so that they can record events
+	// about objects with EventRecorderFromContext. If nil, the context is left unchanged.
+	EventRecorder        EventRecorder
+	failures             *failureTracker
+	informers            *ListMap[string, Informer]
+	watchers             *ListMap[string, ResourceWatcher]
+	reconcilers          *ListMap[string, Reconciler]
+	toRetry              *ListMap[string, retryInfo]
+	retryTickerInterval  time.Duration
+	operationTimeout     time.Duration
+	reconcileConcurrency ReconcilerOptions
+	reconcileEvents      *ReconcileEventStream
+	runner               *app.DynamicMultiRunner
+	totalEvents          *prometheus.CounterVec
+	reconcileErrors      *prometheus.CounterVec
+	reconcileLatency     *prometheus.HistogramVec
+	reconcilerLatency    *prometheus.HistogramVec
+	watcherLatency       *prometheus.HistogramVec
+	inflightActions      *prometheus.GaugeVec
+	inflightEvents       *prometheus.GaugeVec
+	retryQueueSize       prometheus.GaugeFunc
+	reconcileQueueSize   prometheus.GaugeFunc
+	informerSynced       *informerSyncedCollector
+	reconcileDuration    *prometheus.HistogramVec
+	reconcileResults     *prometheus.CounterVec
+	reconcileRetries     *prometheus.CounterVec
+	queueDepth           *reconcileQueueDepthCollector
+	replayCache          bool
+	// runCtx is the context of the current call to Run, or nil if the controller is not running
+	runCtx context.Context
+	runMux sync.RWMutex
 }
 
 type retryInfo struct {
 	retryAfter time.Time
-	retryFunc  func() (*time.Duration, error)
-	attempt    int
-	action     ResourceAction
-	object     resource.Object
-	err        error
+	// retryFunc retries the call, and is passed the attempt number of the retry (1 for the first retry)
+	retryFunc func(attempt int) (*time.Duration, error)
+	attempt   int
+	action    ResourceAction
+	object    resource.Object
+	err       error
+	// errs are the errors of every previous attempt, including err
+	errs []error
+	// firstFailure is the time of the first failed attempt, if any attempt has failed
+	firstFailure time.Time
+	requestID    string
+	// queue is the reconcileQueue to run the retry on, if the retry is for a queued reconciler
+	queue *reconcileQueue
 }
 
 // InformerControllerConfig contains configuration options for an InformerController
 type InformerControllerConfig struct {
 	MetricsConfig metrics.Config
-	// ErrorHandler is a user-specified error handling function. This is typically for logging/metrics use,
-	// as retry logic is covered by the RetryPolicy. If left nil, DefaultErrorHandler will be used.
+	// ErrorReporter is sent a report of each error returned by a ResourceWatcher or Reconciler call.
+	// This is typically for logging/metrics use, as retry logic is covered by the RetryPolicy.
+	// If left nil, ErrorHandler is used instead. app.NewDefaultErrorReporter provides an ErrorReporter
+	// which logs and counts errors.
+	ErrorReporter app.ErrorReporter
+	// ErrorHandler is a user-specified error handling function, which is used if ErrorReporter is nil.
+	// If left nil, DefaultErrorHandler will be used.
+	//
+	// Deprecated: use ErrorReporter.
 	ErrorHandler func(context.Context, error)
 	// RetryPolicy is a user-specified retry logic function which will be used when ResourceWatcher function calls fail.
 	// If left nil, DefaultRetryPolicy will be used.
@@ -141,6 +190,86 @@ type InformerControllerConfig struct {
 	// when one or more retries for the object are still pending. If not present, existing retries are always dequeued.
 	// If left nil, no RetryDequeuePolicy will be used, and retries will only be dequeued when RetryPolicy returns false.
 	RetryDequeuePolicy RetryDequeuePolicy
+	// DeadLetterHandler is called with each event which the RetryPolicy has stopped retrying, along with the errors of
+	// every attempt. NewKubernetesEventDeadLetterHandler provides a DeadLetterHandler which emits Kubernetes Events.
+	// If left nil, events are dropped once they are no longer retried.
+	DeadLetterHandler DeadLetterHandler
+	// EventRecorder is added to the context passed to every watcher and reconciler call, so that they can record
+	// Kubernetes Events about objects with EventRecorderFromContext. NewKubernetesEventRecorder provides an EventRecorder
+	// which creates core/v1 Events. If left nil, the context of calls is unchanged, so an EventRecorder can instead be
+	// added to the context passed to Run with WithEventRecorder (as Runner does).
+	EventRecorder EventRecorder
+	// FailureNotifier, if non-nil, is sent a FailureNotification for each event which the RetryPolicy has stopped retrying,
+	// each time the consecutive failed reconciles of an object reach the FailureThreshold, and when an object with a
+	// notified failure is next reconciled successfully, so that failures can be escalated to an incident or alerting system.
+	// NewWebhookFailureNotifier provides a FailureNotifier which posts notifications to a webhook.
+	// Notifications are sent while the controller is running.
+	FailureNotifier FailureNotifier
+	// FailureThreshold is the threshold of consecutive failed reconciles of an object at which a
+	// FailureNotificationTypeSustainedFailure notification is sent to the FailureNotifier.
+	// If left empty, only dead-lettered events are sent.
+	FailureThreshold FailureThreshold
+	// OperationTimeout is the maximum duration of each ResourceWatcher and Reconciler call, including retries.
+	// The context passed to the call is canceled once it is exceeded, so calls which respect their context
+	// (such as requests made with a resource.Client) are aborted, and can be retried according to the RetryPolicy.
+	// If zero, calls are only canceled when the controller stops.
+	OperationTimeout time.Duration
+	// MaxConcurrentReconciles is the default ReconcilerOptions.MaxConcurrentReconciles for reconcilers added with AddReconciler.
+	// If zero, reconcilers are called sequentially for each event, as events are received from the informer.
+	MaxConcurrentReconciles int
+	// RequeueQPS is the default ReconcilerOptions.RequeueQPS for reconcilers added with AddReconciler.
+	RequeueQPS float64
+	// RequeueBurst is the default ReconcilerOptions.RequeueBurst for reconcilers added with AddReconciler.
+	RequeueBurst int
+	// FairQueueing is the default ReconcilerOptions.FairQueueing for reconcilers added with AddReconciler.
+	FairQueueing *FairQueueing
+	// ReconcileEvents is the stream a ReconcileEvent is published to for each Reconciler call, including retries.
+	// If nil, DefaultReconcileEventStream is used.
+	ReconcileEvents *ReconcileEventStream
+	// ReplayCache is the default ReconcilerOptions.ReplayCache and WatcherOptions.ReplayCache
+	// for reconcilers and watchers added with AddReconciler and AddWatcher.
+	ReplayCache bool
+}
+
+// ReconcilerOptions are options for how an InformerController runs a Reconciler
+type ReconcilerOptions struct {
+	// MaxConcurrentReconciles is the number of workers which call the Reconciler from its own work queue.
+	// Events for the same object are always reconciled one at a time, in the order they were received,
+	// but events for different objects are reconciled in parallel by up to MaxConcurrentReconciles workers.
+	// Retries and RequeueAfter requeues also run on the work queue. If zero, the Reconciler has no work queue,
+	// and it is called sequentially for each event as events are received from the informer.
+	MaxConcurrentReconciles int
+	// RequeueQPS limits the rate at which retries and requeues are added to the work queue.
+	// Due retries which exceed the limit wait until a later tick of the retry queue. If zero, requeues are not limited.
+	// It is ignored if MaxConcurrentReconciles is zero.
+	RequeueQPS float64
+	// RequeueBurst is the maximum burst of requeues allowed above RequeueQPS. If zero, the burst is max(1, RequeueQPS).
+	RequeueBurst int
+	// FairQueueing, if non-nil, shares the workers of the work queue between namespaces (and priorities),
+	// rather than running queued events in the order they were received, so that one namespace with many events
+	// cannot monopolize the workers. It is ignored if MaxConcurrentReconciles is zero.
+	FairQueueing *FairQueueing
+	// Predicates filter the events passed to the Reconciler. The Reconciler is only called for events
+	// which all Predicates accept. Filtered events are dropped before they are added to the work queue.
+	Predicates []Predicate
+	// ReplayCache, if true, passes every object in the cache of the informers for the kind to the Reconciler
+	// with ReconcileActionResynced when it is added to a running InformerController, so that a Reconciler added
+	// after the informers have synced starts from every existing object, rather than only seeing objects as they change.
+	// Objects which are being deleted, or which the Predicates reject as a create, are not replayed.
+	// Reconcilers added before the controller runs receive an add for every object from the informers' initial list,
+	// so nothing is replayed to them.
+	ReplayCache bool
+}
+
+// WatcherOptions are options for how an InformerController runs a ResourceWatcher
+type WatcherOptions struct {
+	// Predicates filter the events passed to the ResourceWatcher. The ResourceWatcher is only called for events
+	// which all Predicates accept.
+	Predicates []Predicate
+	// ReplayCache, if true, passes every object in the cache of the informers for the kind to the ResourceWatcher
+	// when it is added to a running InformerController, like ReconcilerOptions.ReplayCache. Objects are replayed
+	// with the watcher's Sync method if it has one (such as simple.SyncWatcher), and otherwise with Add.
+	ReplayCache bool
 }
 
 // DefaultInformerControllerConfig returns an InformerControllerConfig with default values
@@ -160,7 +289,16 @@ func NewInformerController(cfg InformerControllerConfig) *InformerController {
 		reconcilers:         NewListMap[Reconciler](),
 		toRetry:             NewListMap[retryInfo](),
 		retryTickerInterval: time.Second,
-		runner:              app.NewDynamicMultiRunner(),
+		operationTimeout:    cfg.OperationTimeout,
+		reconcileConcurrency: ReconcilerOptions{
+			MaxConcurrentReconciles: cfg.MaxConcurrentReconciles,
+			RequeueQPS:              cfg.RequeueQPS,
+			RequeueBurst:            cfg.RequeueBurst,
+			FairQueueing:            cfg.FairQueueing,
+		},
+		replayCache:     cfg.ReplayCache,
+		reconcileEvents: cfg.ReconcileEvents,
+		runner:          app.NewDynamicMultiRunner(),
 		reconcileLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace:                       cfg.MetricsConfig.Namespace,
 			Subsystem:                       "informer",
@@ -197,6 +335,12 @@ func NewInformerController(cfg InformerControllerConfig) *InformerController {
 			Namespace: cfg.MetricsConfig.Namespace,
 			Help:      "Total number of informer events",
 		}, []string{"event_type", "kind"}),
+		reconcileErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:      "errors_total",
+			Subsystem: "reconciler",
+			Namespace: cfg.MetricsConfig.Namespace,
+			Help:      "Total number of reconciler actions which returned an error",
+		}, []string{"event_type", "kind"}),
 		inflightActions: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name:      "ongoing_reconcile_processes",
 			Namespace: cfg.MetricsConfig.Namespace,
@@ -207,7 +351,69 @@ func NewInformerController(cfg InformerControllerConfig) *InformerController {
 			Namespace: cfg.MetricsConfig.Namespace,
 			Help:      "Current number of events which have active reconcile processes",
 		}, []string{"event_type", "kind"}),
+		reconcileDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:                       cfg.MetricsConfig.Namespace,
+			Subsystem:                       "reconciler",
+			Name:                            "reconcile_duration_seconds",
+			Help:                            "Time (in seconds) spent in each call to a reconciler, including retries and requeues.",
+			Buckets:                         metrics.LatencyBuckets,
+			NativeHistogramBucketFactor:     cfg.MetricsConfig.NativeHistogramBucketFactor,
+			NativeHistogramMaxBucketNumber:  cfg.MetricsConfig.NativeHistogramMaxBucketNumber,
+			NativeHistogramMinResetDuration: time.Hour,
+		}, []string{"kind", "namespace"}),
+		reconcileResults: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:      "results_total",
+			Subsystem: "reconciler",
+			Namespace: cfg.MetricsConfig.Namespace,
+			Help:      "Total number of calls to a reconciler by result: success, error, or requeue (success with a RequeueAfter)",
+		}, []string{"kind", "namespace", "result"}),
+		reconcileRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:      "retries_total",
+			Subsystem: "reconciler",
+			Namespace: cfg.MetricsConfig.Namespace,
+			Help:      "Total number of calls to a reconciler which retried an earlier failed or requeued call",
+		}, []string{"kind", "namespace"}),
 	}
+	inf.retryQueueSize = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:      "retry_queue_size",
+		Subsystem: "informer",
+		Namespace: cfg.MetricsConfig.Namespace,
+		Help:      "Current number of watcher and reconciler actions waiting to be retried",
+	}, func() float64 {
+		size := 0
+		inf.toRetry.RangeAll(func(string, int, retryInfo) {
+			size++
+		})
+		return float64(size)
+	})
+	inf.reconcileQueueSize = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:      "reconcile_queue_size",
+		Subsystem: "informer",
+		Namespace: cfg.MetricsConfig.Namespace,
+		Help:      "Current number of reconciler actions waiting in reconciler work queues",
+	}, func() float64 {
+		size := 0
+		inf.reconcilers.RangeAll(func(_ string, _ int, value Reconciler) {
+			if cast, ok := unwrapReconciler(value).(*queuedReconciler); ok {
+				size += cast.queue.len()
+			}
+		})
+		return float64(size)
+	})
+	inf.informerSynced = &informerSyncedCollector{
+		informers: inf.informers,
+		desc: prometheus.NewDesc(prometheus.BuildFQName(cfg.MetricsConfig.Namespace, "informer", "synced"),
+			"Whether the informer for a kind has synced all events from its initial list request (1) or not (0)",
+			[]string{"kind"}, nil),
+	}
+	inf.queueDepth = &reconcileQueueDepthCollector{
+		retries: inf.toRetry,
+		work:    make(map[string]int),
+		desc: prometheus.NewDesc(prometheus.BuildFQName(cfg.MetricsConfig.Namespace, "reconciler", "queue_depth"),
+			"Current number of reconciles for a kind waiting in reconciler work queues (queue=\"work\") or to be retried (queue=\"retry\")",
+			[]string{"kind", "queue"}, nil),
+	}
+	inf.ErrorReporter = cfg.ErrorReporter
 	if cfg.ErrorHandler != nil {
 		inf.ErrorHandler = cfg.ErrorHandler
 	}
@@ -217,6 +423,14 @@ func NewInformerController(cfg InformerControllerConfig) *InformerController {
 	if cfg.RetryDequeuePolicy != nil {
 		inf.RetryDequeuePolicy = cfg.RetryDequeuePolicy
 	}
+	inf.DeadLetterHandler = cfg.DeadLetterHandler
+	inf.EventRecorder = cfg.EventRecorder
+	if cfg.FailureNotifier != nil {
+		inf.failures = newFailureTracker(cfg.FailureNotifier, cfg.FailureThreshold)
+	}
+	if inf.reconcileEvents == nil {
+		inf.reconcileEvents = DefaultReconcileEventStream()
+	}
 	return inf
 }
 
@@ -229,6 +443,9 @@ func NewInformerController(cfg InformerControllerConfig) *InformerController {
 // The most common usage of this is to have informers partitioned by namespace or labels for the same resource kind,
 // which share a watcher.
 //
+// If the informer implements ScopeAwareInformer, its ListWatchOptions are validated against the scope of its schema,
+// and an error wrapping ErrNamespaceScopeMismatch is returned if they are incompatible.
+//
 //nolint:gocognit,funlen,dupl
 func (c *InformerController) AddInformer(informer Informer, resourceKind string) error {
 	if informer == nil {
@@ -237,6 +454,11 @@ func (c *InformerController) AddInformer(informer Informer, resourceKind string)
 	if resourceKind == "" {
 		return fmt.Errorf("resourceKind cannot be empty")
 	}
+	if cast, ok := informer.(ScopeAwareInformer); ok {
+		if err := ValidateListWatchOptions(cast.Schema(), cast.ListWatchOptions()); err != nil {
+			return err
+		}
+	}
 
 	err := informer.AddEventHandler(&SimpleWatcher{
 		AddFunc:    c.informerAddFunc(resourceKind),
@@ -252,6 +474,49 @@ func (c *InformerController) AddInformer(informer Informer, resourceKind string)
 	return nil
 }
 
+// AddMappedInformer adds an informer for objects which are not reconciled themselves, but map to objects of resourceKind
+// which should be reconciled when they change, such as objects owned by a kind (see OwnerReferenceMapper).
+// The informer is tracked under informerKind, and its events are not passed to watchers or reconcilers for informerKind.
+// Instead, for each add, update, or delete, the object (and, for updates, its previous state) is mapped with mapper,
+// and the mapped objects are reconciled as if passed to Enqueue.
+// To map the events of an informer which is also used by watchers or reconcilers, use AddEventMapper.
+func (c *InformerController) AddMappedInformer(informer Informer, informerKind string, resourceKind string, mapper ObjectMapper) error {
+	if informer == nil {
+		return fmt.Errorf("informer cannot be nil")
+	}
+	if informerKind == "" || resourceKind == "" {
+		return fmt.Errorf("informerKind and resourceKind cannot be empty")
+	}
+	if mapper == nil {
+		return fmt.Errorf("mapper cannot be nil")
+	}
+	err := informer.AddEventHandler(c.mappingWatcher(resourceKind, mapper))
+	if err != nil {
+		return err
+	}
+
+	c.runner.AddRunnable(informer)
+	c.informers.AddItem(informerKind, informer)
+	return nil
+}
+
+// AddEventMapper maps the events of the informers for informerKind to reconciles of objects of resourceKind,
+// such as reconciling every object which references a ConfigMap when that ConfigMap changes.
+// Like AddMappedInformer, each add, update, or delete of an object of informerKind is mapped with mapper
+// (for updates, along with its previous state), and the mapped objects are reconciled as if passed to Enqueue.
+// Unlike AddMappedInformer, it uses the informers already added (or added later) for informerKind,
+// and their events are still passed to any watchers and reconcilers for informerKind.
+// Any predicates are applied to the events of informerKind before they are mapped.
+func (c *InformerController) AddEventMapper(informerKind string, resourceKind string, mapper ObjectMapper, predicates ...Predicate) error {
+	if informerKind == "" || resourceKind == "" {
+		return fmt.Errorf("informerKind and resourceKind cannot be empty")
+	}
+	if mapper == nil {
+		return fmt.Errorf("mapper cannot be nil")
+	}
+	return c.AddWatcher(c.mappingWatcher(resourceKind, mapper), informerKind, predicates...)
+}
+
 // RemoveInformer removes the provided informer, stopping it if it is currently running.
 func (c *InformerController) RemoveInformer(informer Informer, resourceKind string) {
 	c.runner.RemoveRunnable(informer)
@@ -260,30 +525,76 @@ func (c *InformerController) RemoveInformer(informer Informer, resourceKind stri
 	})
 }
 
+// Lister returns a Lister which reads objects of resourceKind from the caches of the informers added for it,
+// so that watchers and reconcilers can read objects without making requests to the API server.
+// If several informers were added for resourceKind, the Lister reads from all of them.
+// It returns an error if no informer for resourceKind implements Lister.
+// The Lister's results are only complete once the informers have synced (see WaitForSync).
+func (c *InformerController) Lister(resourceKind string) (Lister, error) {
+	listers := make(multiLister, 0)
+	c.informers.Range(resourceKind, func(_ int, value Informer) {
+		if cast, ok := value.(Lister); ok {
+			listers = append(listers, cast)
+		}
+	})
+	if len(listers) == 0 {
+		return nil, fmt.Errorf("no informer which supports cache reads has been added for resource kind '%s'", resourceKind)
+	}
+	return listers, nil
+}
+
 // AddWatcher adds an observer to an informer with a matching `resourceKind`.
 // Any time the informer sees an add, update, or delete, it will call the observer's corresponding method.
 // Multiple watchers can exist for the same resource kind.
 // They will be run in the order they were added to the informer.
-func (c *InformerController) AddWatcher(watcher ResourceWatcher, resourceKind string) error {
+// If any predicates are provided, the watcher is only called for events which all predicates accept.
+// The watcher is run with the ReplayCache option from the InformerControllerConfig,
+// to use different options, use AddWatcherWithOptions.
+func (c *InformerController) AddWatcher(watcher ResourceWatcher, resourceKind string, predicates ...Predicate) error {
+	return c.AddWatcherWithOptions(watcher, resourceKind, WatcherOptions{
+		Predicates:  predicates,
+		ReplayCache: c.replayCache,
+	})
+}
+
+// AddWatcherWithOptions adds a watcher to an informer with a matching `resourceKind`, like AddWatcher,
+// using the provided WatcherOptions.
+func (c *InformerController) AddWatcherWithOptions(watcher ResourceWatcher, resourceKind string, options WatcherOptions) error {
 	if watcher == nil {
 		return fmt.Errorf("watcher cannot be nil")
 	}
 	if resourceKind == "" {
 		return fmt.Errorf("resourceKind cannot be empty")
 	}
+	if len(options.Predicates) > 0 {
+		watcher = &predicateWatcher{
+			ResourceWatcher: watcher,
+			predicates:      options.Predicates,
+		}
+	}
 	c.watchers.AddItem(resourceKind, watcher)
+	if options.ReplayCache {
+		c.replay(resourceKind, watcher, nil)
+	}
 	return nil
 }
 
 // RemoveWatcher removes the given ResourceWatcher from the list for the resourceKind, provided it exists in the list.
+// Pending retries of calls to the watcher are also removed.
 func (c *InformerController) RemoveWatcher(watcher ResourceWatcher, resourceKind string) {
+	c.watchers.Range(resourceKind, func(idx int, w ResourceWatcher) {
+		if watcher == unwrapWatcher(w) {
+			c.removeRetries(fmt.Sprintf("%s:%d:", resourceKind, idx))
+		}
+	})
 	c.watchers.RemoveItem(resourceKind, func(w ResourceWatcher) bool {
-		return watcher == w
+		return watcher == unwrapWatcher(w)
 	})
 }
 
-// RemoveAllWatchersForResource removes all watchers for a specific resourceKind
+// RemoveAllWatchersForResource removes all watchers for a specific resourceKind, along with their pending retries
 func (c *InformerController) RemoveAllWatchersForResource(resourceKind string) {
+	c.removeRetries(resourceKind + ":")
 	c.watchers.RemoveKey(resourceKind)
 }
 
@@ -291,26 +602,96 @@ func (c *InformerController) RemoveAllWatchersForResource(resourceKind string) {
 // Any time the informer sees an add, update, or delete, it will call reconciler.Reconcile.
 // Multiple reconcilers can exist for the same resource kind. If multiple reconcilers exist,
 // they will be run in the order they were added to the informer.
-func (c *InformerController) AddReconciler(reconciler Reconciler, resourceKind string) error {
+// The reconciler is run with the ReconcilerOptions from the InformerControllerConfig,
+// to use different options, use AddReconcilerWithOptions.
+// If any predicates are provided, the reconciler is only called for events which all predicates accept.
+func (c *InformerController) AddReconciler(reconciler Reconciler, resourceKind string, predicates ...Predicate) error {
+	options := c.reconcileConcurrency
+	options.Predicates = predicates
+	options.ReplayCache = c.replayCache
+	return c.AddReconcilerWithOptions(reconciler, resourceKind, options)
+}
+
+// AddReconcilerWithOptions adds a reconciler to an informer with a matching `resourceKind`, like AddReconciler,
+// using the provided ReconcilerOptions. If options.MaxConcurrentReconciles is greater than zero,
+// the reconciler is called from its own work queue, so reconciler calls no longer block the informer's other
+// watchers and reconcilers, and are no longer run in the order reconcilers were added for an event.
+func (c *InformerController) AddReconcilerWithOptions(reconciler Reconciler, resourceKind string, options ReconcilerOptions) error {
 	if reconciler == nil {
 		return fmt.Errorf("reconciler cannot be nil")
 	}
 	if resourceKind == "" {
 		return fmt.Errorf("resourceKind cannot be empty")
 	}
+	if options.MaxConcurrentReconciles < 0 {
+		return fmt.Errorf("options.MaxConcurrentReconciles cannot be negative")
+	}
+	if err := options.FairQueueing.validate(); err != nil {
+		return fmt.Errorf("invalid options.FairQueueing: %w", err)
+	}
+	if options.MaxConcurrentReconciles > 0 {
+		var limiter *rate.Limiter
+		if options.RequeueQPS > 0 {
+			burst := options.RequeueBurst
+			if burst <= 0 {
+				burst = int(math.Max(1, options.RequeueQPS))
+			}
+			limiter = rate.NewLimiter(rate.Limit(options.RequeueQPS), burst)
+		}
+		queued := &queuedReconciler{
+			Reconciler: reconciler,
+			queue:      newReconcileQueue(options.MaxConcurrentReconciles, limiter, options.FairQueueing),
+		}
+		c.runner.AddRunnable(queued.queue)
+		reconciler = queued
+	}
+	if len(options.Predicates) > 0 {
+		reconciler = &predicateReconciler{
+			Reconciler: reconciler,
+			predicates: options.Predicates,
+		}
+	}
 	c.reconcilers.AddItem(resourceKind, reconciler)
+	if options.ReplayCache {
+		c.replay(resourceKind, nil, reconciler)
+	}
 	return nil
 }
 
 // RemoveReconciler removes the given Reconciler from the list for the resourceKind, provided it exists in the list.
+// Pending retries of calls to the reconciler are also removed.
 func (c *InformerController) RemoveReconciler(reconciler Reconciler, resourceKind string) {
-	c.reconcilers.RemoveItem(resourceKind, func(r Reconciler) bool {
+	matches := func(r Reconciler) bool {
+		r = unwrapReconciler(r)
+		if cast, ok := r.(*queuedReconciler); ok && cast.Reconciler == reconciler {
+			return true
+		}
 		return reconciler == r
+	}
+	c.reconcilers.Range(resourceKind, func(idx int, r Reconciler) {
+		if matches(r) {
+			c.removeRetries(fmt.Sprintf("reconcile:%s:%d:", resourceKind, idx))
+		}
+	})
+	c.reconcilers.RemoveItem(resourceKind, func(r Reconciler) bool {
+		if !matches(r) {
+			return false
+		}
+		if cast, ok := unwrapReconciler(r).(*queuedReconciler); ok {
+			c.runner.RemoveRunnable(cast.queue)
+		}
+		return true
 	})
 }
 
-// RemoveAllReconcilersForResource removes all Reconcilers for a specific resourceKind
+// RemoveAllReconcilersForResource removes all Reconcilers for a specific resourceKind, along with their pending retries
 func (c *InformerController) RemoveAllReconcilersForResource(resourceKind string) {
+	c.removeRetries("reconcile:" + resourceKind + ":")
+	c.reconcilers.Range(resourceKind, func(_ int, r Reconciler) {
+		if cast, ok := unwrapReconciler(r).(*queuedReconciler); ok {
+			c.runner.RemoveRunnable(cast.queue)
+		}
+	})
 	c.reconcilers.RemoveKey(resourceKind)
 }
 
@@ -322,15 +703,55 @@ func (c *InformerController) Run(ctx context.Context) error {
 	derivedCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	c.runMux.Lock()
+	c.runCtx = derivedCtx
+	c.runMux.Unlock()
+	defer func() {
+		c.runMux.Lock()
+		c.runCtx = nil
+		c.runMux.Unlock()
+	}()
+
 	go c.retryTicker(derivedCtx)
+	if c.failures != nil {
+		go c.failures.run(derivedCtx)
+	}
 	return c.runner.Run(ctx)
 }
 
+// WaitForSync blocks until every informer in the controller which exposes a HasSynced method
+// (such as KubernetesBasedInformer and CustomCacheInformer) has completed its initial list, or until ctx is canceled,
+// in which case it returns ctx.Err(). Use resource.WithOperationTimeout to bound the wait.
+func (c *InformerController) WaitForSync(ctx context.Context) error {
+	ticker := time.NewTicker(waitForSyncInterval)
+	defer ticker.Stop()
+	for !c.informersSynced() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (c *InformerController) informersSynced() bool {
+	synced := true
+	c.informers.RangeAll(func(_ string, _ int, value Informer) {
+		if cast, ok := value.(interface{ HasSynced() bool }); ok && !cast.HasSynced() {
+			synced = false
+		}
+	})
+	return synced
+}
+
 // PrometheusCollectors returns the prometheus metric collectors used by this informer, as well as collectors used by
 // any registered informer or watcher which implements metrics.Provider, to allow for registration
 func (c *InformerController) PrometheusCollectors() []prometheus.Collector {
 	collectors := []prometheus.Collector{
 		c.totalEvents, c.reconcileLatency, c.inflightEvents, c.inflightActions, c.reconcilerLatency, c.watcherLatency,
+		c.reconcileErrors, c.retryQueueSize, c.reconcileQueueSize, c.informerSynced,
+		c.reconcileDuration, c.reconcileResults, c.reconcileRetries, c.queueDepth,
 	}
 	c.informers.RangeAll(func(_ string, _ int, value Informer) {
 		if cast, ok := value.(metrics.Provider); ok {
@@ -352,14 +773,21 @@ func (c *InformerController) informerAddFunc(resourceKind string) func(context.C
 			return ErrNilObject
 		}
 
-		// Metrics for the whole reconcile process
-		eventStart := c.startEvent(string(ResourceActionCreate), obj.GetStaticMetadata().Kind)
-		defer c.completeEvent(string(ResourceActionCreate), obj.GetStaticMetadata().Kind, eventStart)
-
-		ctx, span := GetTracer().Start(ctx, "controller-event-add")
+		// Each event gets its own request ID, which is shared by every watcher and reconciler call (and retry) for it
+		ctx, span := GetTracer().Start(c.eventContext(ctx), "controller-event-add")
 		defer span.End()
+		setObjectSpanAttributes(span, string(ResourceActionCreate), obj)
+
+		// Metrics for the whole reconcile process
+		eventStart := c.startEvent(ctx, string(ResourceActionCreate), obj.GetStaticMetadata().Kind)
+		defer c.completeEvent(ctx, string(ResourceActionCreate), obj.GetStaticMetadata().Kind, eventStart)
 		// Handle all watchers for the add for this resource kind
 		c.watchers.Range(resourceKind, func(idx int, watcher ResourceWatcher) {
+			// Skip the watcher if its predicates filter out the event
+			if cast, ok := watcher.(*predicateWatcher); ok && !cast.predicates.create(obj) {
+				return
+			}
+
 			// Generate the unique key for this object
 			retryKey := c.keyForWatcherEvent(resourceKind, idx, obj)
 
@@ -367,22 +795,32 @@ func (c *InformerController) informerAddFunc(resourceKind string) func(context.C
 			c.dequeueIfRequired(retryKey, obj, ResourceActionCreate)
 
 			// Do the watcher's Add, check for error
-			c.wrapWatcherCall(string(ResourceActionCreate), obj.GetStaticMetadata().Kind, func() {
-				err := watcher.Add(ctx, obj)
-				if err != nil && c.ErrorHandler != nil {
-					c.ErrorHandler(ctx, err) // TODO: improve ErrorHandler
+			c.wrapWatcherCall(ctx, string(ResourceActionCreate), obj.GetStaticMetadata().Kind, func(callCtx context.Context) {
+				err := watcher.Add(callCtx, obj)
+				if err != nil {
+					c.reportError(ctx, objectErrorReport(err, app.ErrorClassWatcher, "InformerController", obj))
 				}
 				if err != nil && c.RetryPolicy != nil {
-					c.queueRetry(retryKey, err, func() (*time.Duration, error) {
-						ctx, span := GetTracer().Start(ctx, "controller-retry")
+					c.queueRetry(ctx, retryKey, err, func(attempt int) (*time.Duration, error) {
+						ctx, span := GetTracer().Start(withAttempt(ctx, attempt), "controller-retry")
 						defer span.End()
+						ctx, cancel := c.operationContext(ctx)
+						defer cancel()
 						return nil, watcher.Add(ctx, obj)
-					}, ResourceActionCreate, obj)
+					}, ResourceActionCreate, obj, nil)
 				}
 			})
 		})
 		// Handle all reconcilers for the add for this resource kind
 		c.reconcilers.Range(resourceKind, func(idx int, reconciler Reconciler) {
+			// Skip the reconciler if its predicates filter out the event
+			if cast, ok := reconciler.(*predicateReconciler); ok {
+				if !cast.predicates.create(obj) {
+					return
+				}
+				reconciler = cast.Reconciler
+			}
+
 			// Generate the unique key for this object
 			retryKey := c.keyForReconcilerEvent(resourceKind, idx, obj)
 
@@ -394,7 +832,7 @@ func (c *InformerController) informerAddFunc(resourceKind string) func(context.C
 				Action: ReconcileActionCreated,
 				Object: obj,
 			}
-			c.doReconcile(ctx, reconciler, req, retryKey)
+			c.reconcile(ctx, reconciler, req, retryKey)
 		})
 		return nil
 	}
@@ -407,14 +845,21 @@ func (c *InformerController) informerUpdateFunc(resourceKind string) func(contex
 			return ErrNilObject
 		}
 
-		// Metrics for the whole reconcile process
-		eventStart := c.startEvent(string(ResourceActionUpdate), newObj.GetStaticMetadata().Kind)
-		defer c.completeEvent(string(ResourceActionUpdate), newObj.GetStaticMetadata().Kind, eventStart)
-
-		ctx, span := GetTracer().Start(ctx, "controller-event-update")
+		// Each event gets its own request ID, which is shared by every watcher and reconciler call (and retry) for it
+		ctx, span := GetTracer().Start(c.eventContext(ctx), "controller-event-update")
 		defer span.End()
+		setObjectSpanAttributes(span, string(ResourceActionUpdate), newObj)
+
+		// Metrics for the whole reconcile process
+		eventStart := c.startEvent(ctx, string(ResourceActionUpdate), newObj.GetStaticMetadata().Kind)
+		defer c.completeEvent(ctx, string(ResourceActionUpdate), newObj.GetStaticMetadata().Kind, eventStart)
 		// Handle all watchers for the update for this resource kind
 		c.watchers.Range(resourceKind, func(idx int, watcher ResourceWatcher) {
+			// Skip the watcher if its predicates filter out the event
+			if cast, ok := watcher.(*predicateWatcher); ok && !cast.predicates.update(oldObj, newObj) {
+				return
+			}
+
 			// Generate the unique key for this object
 			retryKey := c.keyForWatcherEvent(resourceKind, idx, newObj)
 
@@ -422,22 +867,32 @@ func (c *InformerController) informerUpdateFunc(resourceKind string) func(contex
 			c.dequeueIfRequired(retryKey, newObj, ResourceActionUpdate)
 
 			// Do the watcher's Update, check for error
-			c.wrapWatcherCall(string(ResourceActionUpdate), newObj.GetStaticMetadata().Kind, func() {
-				err := watcher.Update(ctx, oldObj, newObj)
-				if err != nil && c.ErrorHandler != nil {
-					c.ErrorHandler(ctx, err)
+			c.wrapWatcherCall(ctx, string(ResourceActionUpdate), newObj.GetStaticMetadata().Kind, func(callCtx context.Context) {
+				err := watcher.Update(callCtx, oldObj, newObj)
+				if err != nil {
+					c.reportError(ctx, objectErrorReport(err, app.ErrorClassWatcher, "InformerController", newObj))
 				}
 				if err != nil && c.RetryPolicy != nil {
-					c.queueRetry(retryKey, err, func() (*time.Duration, error) {
-						ctx, span := GetTracer().Start(ctx, "controller-retry")
+					c.queueRetry(ctx, retryKey, err, func(attempt int) (*time.Duration, error) {
+						ctx, span := GetTracer().Start(withAttempt(ctx, attempt), "controller-retry")
 						defer span.End()
+						ctx, cancel := c.operationContext(ctx)
+						defer cancel()
 						return nil, watcher.Update(ctx, oldObj, newObj)
-					}, ResourceActionUpdate, newObj)
+					}, ResourceActionUpdate, newObj, nil)
 				}
 			})
 		})
 		// Handle all reconcilers for the update for this resource kind
 		c.reconcilers.Range(resourceKind, func(index int, reconciler Reconciler) {
+			// Skip the reconciler if its predicates filter out the event
+			if cast, ok := reconciler.(*predicateReconciler); ok {
+				if !cast.predicates.update(oldObj, newObj) {
+					return
+				}
+				reconciler = cast.Reconciler
+			}
+
 			// Generate the unique key for this object
 			retryKey := c.keyForReconcilerEvent(resourceKind, index, newObj)
 
@@ -449,7 +904,7 @@ func (c *InformerController) informerUpdateFunc(resourceKind string) func(contex
 				Action: ReconcileActionUpdated,
 				Object: newObj,
 			}
-			c.doReconcile(ctx, reconciler, req, retryKey)
+			c.reconcile(ctx, reconciler, req, retryKey)
 		})
 		return nil
 	}
@@ -462,14 +917,21 @@ func (c *InformerController) informerDeleteFunc(resourceKind string) func(contex
 			return ErrNilObject
 		}
 
-		// Metrics for the whole reconcile process
-		eventStart := c.startEvent(string(ResourceActionDelete), obj.GetStaticMetadata().Kind)
-		defer c.completeEvent(string(ResourceActionDelete), obj.GetStaticMetadata().Kind, eventStart)
-
-		ctx, span := GetTracer().Start(ctx, "controller-event-delete")
+		// Each event gets its own request ID, which is shared by every watcher and reconciler call (and retry) for it
+		ctx, span := GetTracer().Start(c.eventContext(ctx), "controller-event-delete")
 		defer span.End()
+		setObjectSpanAttributes(span, string(ResourceActionDelete), obj)
+
+		// Metrics for the whole reconcile process
+		eventStart := c.startEvent(ctx, string(ResourceActionDelete), obj.GetStaticMetadata().Kind)
+		defer c.completeEvent(ctx, string(ResourceActionDelete), obj.GetStaticMetadata().Kind, eventStart)
 		// Handle all watchers for the add for this resource kind
 		c.watchers.Range(resourceKind, func(idx int, watcher ResourceWatcher) {
+			// Skip the watcher if its predicates filter out the event
+			if cast, ok := watcher.(*predicateWatcher); ok && !cast.predicates.delete(obj) {
+				return
+			}
+
 			// Generate the unique key for this object
 			retryKey := c.keyForWatcherEvent(resourceKind, idx, obj)
 
@@ -480,22 +942,32 @@ func (c *InformerController) informerDeleteFunc(resourceKind string) func(contex
 			defer c.inflightActions.WithLabelValues(string(ResourceActionUpdate), obj.GetStaticMetadata().Kind).Dec()
 
 			// Do the watcher's Delete, check for error
-			c.wrapWatcherCall(string(ResourceActionDelete), obj.GetStaticMetadata().Kind, func() {
-				err := watcher.Delete(ctx, obj)
-				if err != nil && c.ErrorHandler != nil {
-					c.ErrorHandler(ctx, err) // TODO: improve ErrorHandler
+			c.wrapWatcherCall(ctx, string(ResourceActionDelete), obj.GetStaticMetadata().Kind, func(callCtx context.Context) {
+				err := watcher.Delete(callCtx, obj)
+				if err != nil {
+					c.reportError(ctx, objectErrorReport(err, app.ErrorClassWatcher, "InformerController", obj))
 				}
 				if err != nil && c.RetryPolicy != nil {
-					c.queueRetry(retryKey, err, func() (*time.Duration, error) {
-						ctx, span := GetTracer().Start(ctx, "controller-retry")
+					c.queueRetry(ctx, retryKey, err, func(attempt int) (*time.Duration, error) {
+						ctx, span := GetTracer().Start(withAttempt(ctx, attempt), "controller-retry")
 						defer span.End()
+						ctx, cancel := c.operationContext(ctx)
+						defer cancel()
 						return nil, watcher.Delete(ctx, obj)
-					}, ResourceActionDelete, obj)
+					}, ResourceActionDelete, obj, nil)
 				}
 			})
 		})
 		// Handle all reconcilers for the add for this resource kind
 		c.reconcilers.Range(resourceKind, func(idx int, reconciler Reconciler) {
+			// Skip the reconciler if its predicates filter out the event
+			if cast, ok := reconciler.(*predicateReconciler); ok {
+				if !cast.predicates.delete(obj) {
+					return
+				}
+				reconciler = cast.Reconciler
+			}
+
 			// Generate the unique key for this object
 			retryKey := c.keyForReconcilerEvent(resourceKind, idx, obj)
 
@@ -508,12 +980,216 @@ func (c *InformerController) informerDeleteFunc(resourceKind string) func(contex
 				Object: obj,
 			}
 
-			c.doReconcile(ctx, reconciler, req, retryKey)
+			c.reconcile(ctx, reconciler, req, retryKey)
 		})
 		return nil
 	}
 }
 
+// mappingWatcher returns a ResourceWatcher which maps the objects of each event with mapper, and enqueues the mapped objects of resourceKind
+func (c *InformerController) mappingWatcher(resourceKind string, mapper ObjectMapper) ResourceWatcher {
+	return &SimpleWatcher{
+		AddFunc: func(ctx context.Context, obj resource.Object) error {
+			return c.reconcileMapped(ctx, resourceKind, mapper, obj)
+		},
+		UpdateFunc: func(ctx context.Context, oldObj resource.Object, newObj resource.Object) error {
+			return c.reconcileMapped(ctx, resourceKind, mapper, newObj, oldObj)
+		},
+		DeleteFunc: func(ctx context.Context, obj resource.Object) error {
+			return c.reconcileMapped(ctx, resourceKind, mapper, obj)
+		},
+	}
+}
+
+// reconcileMapped maps objs with mapper, and enqueues each (distinct) mapped object of resourceKind
+func (c *InformerController) reconcileMapped(ctx context.Context, resourceKind string, mapper ObjectMapper, objs ...resource.Object) error {
+	if len(objs) == 0 || objs[0] == nil {
+		return ErrNilObject
+	}
+
+	ctx, span := GetTracer().Start(ctx, "controller-event-mapped")
+	defer span.End()
+
+	ids := make([]resource.Identifier, 0)
+	seen := make(map[resource.Identifier]struct{})
+	for _, obj := range objs {
+		if obj == nil {
+			continue
+		}
+		mapped, err := mapper.Map(ctx, obj)
+		if err != nil {
+			c.reportError(ctx, objectErrorReport(fmt.Errorf("unable to map object to %s: %w", resourceKind, err), app.ErrorClassReconciler, "InformerController", obj))
+		}
+		for _, id := range mapped {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := c.Enqueue(ctx, resourceKind, ids...); err != nil {
+		c.reportError(ctx, objectErrorReport(err, app.ErrorClassReconciler, "InformerController", objs[0]))
+	}
+	return nil
+}
+
+// Enqueue requests that the objects of resourceKind with the provided identifiers be reconciled, outside of any
+// informer event, such as when a change to an object of another kind affects them. Each object is read from the cache
+// of the informers for resourceKind (see Lister) and passed to the reconcilers for resourceKind, with the action
+// ReconcileActionResynced. Objects which are not in the cache, or which are being deleted, are skipped.
+// Predicates of the reconcilers are not applied to enqueued requests, and enqueued requests share retries
+// with the events of the object they reconcile, but do not dequeue them.
+// Reconcilers without a work queue (see ReconcilerOptions.MaxConcurrentReconciles) are called before Enqueue returns.
+// Enqueue returns an error if no informer for resourceKind supports cache reads.
+func (c *InformerController) Enqueue(ctx context.Context, resourceKind string, identifiers ...resource.Identifier) error {
+	lister, err := c.Lister(resourceKind)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range identifiers {
+		obj, err := lister.Get(ctx, id)
+		if errors.Is(err, ErrNotInCache) {
+			continue
+		}
+		if err != nil {
+			c.reportError(ctx, app.ErrorReport{
+				Err:       fmt.Errorf("unable to get enqueued %s '%s/%s': %w", resourceKind, id.Namespace, id.Name, err),
+				Class:     app.ErrorClassReconciler,
+				Component: "InformerController",
+				Object:    id,
+			})
+			continue
+		}
+		// Objects being deleted are handled by their own events, and reconciling them again could repeat their deletion
+		if obj.GetDeletionTimestamp() != nil {
+			continue
+		}
+		// Objects from the cache must not be modified, and reconcilers may update the object in the request
+		obj = obj.Copy()
+		// Requests enqueued for another event (such as by AddEventMapper) keep its request ID
+		reqCtx := c.eventContext(ctx)
+		c.reconcilers.Range(resourceKind, func(idx int, reconciler Reconciler) {
+			req := ReconcileRequest{
+				Action: ReconcileActionResynced,
+				Object: obj,
+			}
+			c.reconcile(reqCtx, unwrapReconciler(reconciler), req, c.keyForReconcilerEvent(resourceKind, idx, obj))
+		})
+	}
+	return nil
+}
+
+// replay passes every object in the cache of the informers for resourceKind to the newly added watcher or reconciler,
+// if the controller is running. Objects are replayed in the background, as the cache may be large.
+// Objects which are not in the cache yet (because an informer has not synced) don't need to be replayed,
+// as the handler is already added to receive their event from the informer.
+func (c *InformerController) replay(resourceKind string, watcher ResourceWatcher, reconciler Reconciler) {
+	c.runMux.RLock()
+	ctx := c.runCtx
+	c.runMux.RUnlock()
+	if ctx == nil {
+		return
+	}
+	lister, err := c.Lister(resourceKind)
+	if err != nil {
+		logging.FromContext(ctx).Warn("unable to replay informer cache for new handler", "kind", resourceKind, "error", err)
+		return
+	}
+	tracer := GetTracer()
+	go func() {
+		ctx, span := tracer.Start(ctx, "controller-replay")
+		defer span.End()
+		objs, err := lister.List(ctx, resource.NamespaceAll)
+		if err != nil {
+			class := app.ErrorClassWatcher
+			if reconciler != nil {
+				class = app.ErrorClassReconciler
+			}
+			c.reportError(ctx, app.ErrorReport{
+				Err:       fmt.Errorf("unable to list %s to replay: %w", resourceKind, err),
+				Class:     class,
+				Component: "InformerController",
+			})
+			return
+		}
+		for _, obj := range objs {
+			if obj.GetDeletionTimestamp() != nil {
+				continue
+			}
+			// Objects from the cache must not be modified, and handlers may update the object they are passed
+			obj = obj.Copy()
+			objCtx := c.eventContext(ctx)
+			if watcher != nil {
+				c.replayToWatcher(objCtx, resourceKind, watcher, obj)
+			} else {
+				c.replayToReconciler(objCtx, resourceKind, reconciler, obj)
+			}
+		}
+	}()
+}
+
+func (c *InformerController) replayToWatcher(ctx context.Context, resourceKind string, watcher ResourceWatcher, obj resource.Object) {
+	idx := -1
+	c.watchers.Range(resourceKind, func(i int, value ResourceWatcher) {
+		if value == watcher {
+			idx = i
+		}
+	})
+	// The watcher was removed before obj was replayed
+	if idx < 0 {
+		return
+	}
+	if cast, ok := watcher.(*predicateWatcher); ok && !cast.predicates.create(obj) {
+		return
+	}
+	call := unwrapWatcher(watcher).Add
+	if cast, ok := unwrapWatcher(watcher).(interface {
+		Sync(context.Context, resource.Object) error
+	}); ok {
+		call = cast.Sync
+	}
+	retryKey := c.keyForWatcherEvent(resourceKind, idx, obj)
+	c.wrapWatcherCall(ctx, string(ResourceActionCreate), obj.GetStaticMetadata().Kind, func(callCtx context.Context) {
+		err := call(callCtx, obj)
+		if err != nil {
+			c.reportError(ctx, objectErrorReport(err, app.ErrorClassWatcher, "InformerController", obj))
+		}
+		if err != nil && c.RetryPolicy != nil {
+			c.queueRetry(ctx, retryKey, err, func(attempt int) (*time.Duration, error) {
+				ctx, cancel := c.operationContext(withAttempt(ctx, attempt))
+				defer cancel()
+				return nil, call(ctx, obj)
+			}, ResourceActionCreate, obj, nil)
+		}
+	})
+}
+
+func (c *InformerController) replayToReconciler(ctx context.Context, resourceKind string, reconciler Reconciler, obj resource.Object) {
+	idx := -1
+	c.reconcilers.Range(resourceKind, func(i int, value Reconciler) {
+		if value == reconciler {
+			idx = i
+		}
+	})
+	// The reconciler was removed before obj was replayed
+	if idx < 0 {
+		return
+	}
+	if cast, ok := reconciler.(*predicateReconciler); ok && !cast.predicates.create(obj) {
+		return
+	}
+	req := ReconcileRequest{
+		Action: ReconcileActionResynced,
+		Object: obj,
+	}
+	c.reconcile(ctx, unwrapReconciler(reconciler), req, c.keyForReconcilerEvent(resourceKind, idx, obj))
+}
+
 func (c *InformerController) dequeueIfRequired(retryKey string, currentObjectState resource.Object, action ResourceAction) {
 	if c.RetryDequeuePolicy != nil {
 		c.toRetry.RemoveItems(retryKey, func(info retryInfo) bool {
@@ -525,6 +1201,20 @@ func (c *InformerController) dequeueIfRequired(retryKey string, currentObjectSta
 	}
 }
 
+// reconcile calls doReconcile for the reconciler, or queues the call if the reconciler has a work queue
+func (c *InformerController) reconcile(ctx context.Context, reconciler Reconciler, req ReconcileRequest, retryKey string) {
+	if cast, ok := reconciler.(*queuedReconciler); ok {
+		kind := req.Object.GetStaticMetadata().Kind
+		c.queueDepth.addWork(kind, 1)
+		cast.queue.add(retryKey, req.Object, func() {
+			c.queueDepth.addWork(kind, -1)
+			c.doReconcile(ctx, cast, req, retryKey)
+		})
+		return
+	}
+	c.doReconcile(ctx, reconciler, req, retryKey)
+}
+
 func (c *InformerController) doReconcile(ctx context.Context, reconciler Reconciler, req ReconcileRequest, retryKey string) {
 	// Metrics for the reconcile action
 	action := ResourceActionFromReconcileAction(req.Action)
@@ -535,38 +1225,106 @@ func (c *InformerController) doReconcile(ctx context.Context, reconciler Reconci
 	if c.reconcilerLatency != nil {
 		start := time.Now()
 		defer func() {
-			c.reconcilerLatency.WithLabelValues(string(action), req.Object.GetStaticMetadata().Kind).Observe(time.Since(start).Seconds())
+			metrics.ObserveWithTraceExemplar(ctx, c.reconcilerLatency.WithLabelValues(string(action), req.Object.GetStaticMetadata().Kind), time.Since(start).Seconds())
 		}()
 	}
 
 	ctx, span := GetTracer().Start(ctx, "controller-event-reconcile")
 	defer span.End()
 	// Do the reconcile
-	res, err := reconciler.Reconcile(ctx, req)
+	callCtx, cancel := c.operationContext(ctx)
+	defer cancel()
+	res, err := c.callReconciler(callCtx, reconciler, req, retryKey, false)
+	if err != nil {
+		if c.reconcileErrors != nil {
+			c.reconcileErrors.WithLabelValues(string(action), req.Object.GetStaticMetadata().Kind).Inc()
+		}
+		c.reportError(ctx, objectErrorReport(err, app.ErrorClassReconciler, "InformerController", req.Object))
+	}
 	// If the response contains a state, add it to the request for future retries
 	if res.State != nil {
 		req.State = res.State
 	}
+	var queue *reconcileQueue
+	if cast, ok := reconciler.(*queuedReconciler); ok {
+		queue = cast.queue
+	}
 	if res.RequeueAfter != nil {
 		// If RequeueAfter is non-nil, add a retry to the queue for now+RequeueAfter
+		var (
+			errs         []error
+			firstFailure time.Time
+		)
+		if err != nil {
+			errs = []error{err}
+			firstFailure = time.Now()
+		}
 		c.toRetry.AddItem(retryKey, retryInfo{
 			retryAfter: time.Now().Add(*res.RequeueAfter),
-			retryFunc: func() (*time.Duration, error) {
-				res, err := reconciler.Reconcile(ctx, req)
+			retryFunc: func(attempt int) (*time.Duration, error) {
+				ctx, cancel := c.operationContext(withAttempt(ctx, attempt))
+				defer cancel()
+				res, err := c.callReconciler(ctx, reconciler, req, retryKey, true)
 				return res.RequeueAfter, err
 			},
-			action: ResourceActionFromReconcileAction(req.Action),
-			object: req.Object,
-			err:    err,
+			action:       ResourceActionFromReconcileAction(req.Action),
+			object:       req.Object,
+			err:          err,
+			errs:         errs,
+			firstFailure: firstFailure,
+			requestID:    RequestIDFromContext(ctx),
+			queue:        queue,
 		})
 	} else if err != nil {
 		// Otherwise, if err is non-nil, queue a retry according to the RetryPolicy
-		c.queueRetry(retryKey, err, func() (*time.Duration, error) {
-			ctx, span := GetTracer().Start(ctx, "controller-retry")
+		c.queueRetry(ctx, retryKey, err, func(attempt int) (*time.Duration, error) {
+			ctx, span := GetTracer().Start(withAttempt(ctx, attempt), "controller-retry")
 			defer span.End()
-			res, err := reconciler.Reconcile(ctx, req)
+			ctx, cancel := c.operationContext(ctx)
+			defer cancel()
+			res, err := c.callReconciler(ctx, reconciler, req, retryKey, true)
 			return res.RequeueAfter, err
-		}, ResourceActionFromReconcileAction(req.Action), req.Object)
+		}, ResourceActionFromReconcileAction(req.Action), req.Object, queue)
+	}
+}
+
+// callReconciler calls reconciler.Reconcile with req, and publishes a ReconcileEvent for the call
+func (c *InformerController) callReconciler(ctx context.Context, reconciler Reconciler, req ReconcileRequest, retryKey string, retry bool) (ReconcileResult, error) {
+	ctx, span := startReconcilerSpan(ctx, req, retry)
+	defer span.End()
+	start := time.Now()
+	res, err := reconciler.Reconcile(ctx, req)
+	setSpanError(span, err)
+	if c.reconcileEvents != nil {
+		c.reconcileEvents.Publish(newReconcileEvent(req, start, res, err, retry))
+	}
+	c.observeReconcile(ctx, req.Object, time.Since(start), res, err, retry)
+	if c.failures != nil && req.Object != nil {
+		c.failures.observeReconcile(ctx, handlerForRetryKey(retryKey), req.Object, ResourceActionFromReconcileAction(req.Action), err)
+	}
+	return res, err
+}
+
+// observeReconcile records the duration and result of a call to a reconciler for object
+func (c *InformerController) observeReconcile(
+	ctx context.Context, object resource.Object, duration time.Duration, res ReconcileResult, err error, retry bool,
+) {
+	kind := object.GetStaticMetadata().Kind
+	namespace := object.GetNamespace()
+	if c.reconcileDuration != nil {
+		metrics.ObserveWithTraceExemplar(ctx, c.reconcileDuration.WithLabelValues(kind, namespace), duration.Seconds())
+	}
+	if c.reconcileResults != nil {
+		result := "success"
+		if err != nil {
+			result = "error"
+		} else if res.RequeueAfter != nil {
+			result = "requeue"
+		}
+		c.reconcileResults.WithLabelValues(kind, namespace, result).Inc()
+	}
+	if retry && c.reconcileRetries != nil {
+		c.reconcileRetries.WithLabelValues(kind, namespace).Inc()
 	}
 }
 
@@ -580,35 +1338,39 @@ func (c *InformerController) retryTicker(ctx context.Context) {
 		select {
 		case t := <-ticker.C:
 			for _, key := range c.toRetry.Keys() {
+				// Stop processing retries promptly if the controller is stopped mid-tick
+				if ctx.Err() != nil {
+					return
+				}
 				// To be simple, we retry all retries which should be done now, and remove them from the list
 				// We then add back in retries which failed and need to be retried again
 				toAdd := make([]retryInfo, 0)
 				c.toRetry.RemoveItems(key, func(val retryInfo) bool {
-					if t.After(val.retryAfter) {
-						specifiedRetry, err := val.retryFunc()
-						if specifiedRetry != nil {
-							toAdd = append(toAdd, retryInfo{
-								attempt:    val.attempt, // TODO: whether or not this should trigger an attempt increase
-								retryAfter: t.Add(*specifiedRetry),
-								retryFunc:  val.retryFunc,
-								action:     val.action,
-								object:     val.object,
-							})
-						} else if err != nil && c.RetryPolicy != nil {
-							ok, after := c.RetryPolicy(err, val.attempt+1)
-							if ok {
-								toAdd = append(toAdd, retryInfo{
-									attempt:    val.attempt + 1,
-									retryAfter: t.Add(after),
-									retryFunc:  val.retryFunc,
-									action:     val.action,
-									object:     val.object,
-								})
-							}
+					if !t.After(val.retryAfter) {
+						return false
+					}
+					if val.queue != nil {
+						// Retries for queued reconcilers run on the reconciler's work queue, behind any queued events for the object.
+						// If the requeue rate limit is exceeded, the retry is left for the next tick.
+						if !val.queue.allowRequeue() {
+							return false
 						}
+						kind := val.object.GetStaticMetadata().Kind
+						c.queueDepth.addWork(kind, 1)
+						val.queue.add(key, val.object, func() {
+							c.queueDepth.addWork(kind, -1)
+							specifiedRetry, err := val.retryFunc(val.attempt + 1)
+							if next, ok := c.nextRetry(ctx, key, val, time.Now(), specifiedRetry, err); ok {
+								c.toRetry.AddItem(key, next)
+							}
+						})
 						return true
 					}
-					return false
+					specifiedRetry, err := val.retryFunc(val.attempt + 1)
+					if next, ok := c.nextRetry(ctx, key, val, t, specifiedRetry, err); ok {
+						toAdd = append(toAdd, next)
+					}
+					return true
 				}, -1)
 				for _, inf := range toAdd {
 					c.toRetry.AddItem(key, inf)
@@ -620,9 +1382,61 @@ func (c *InformerController) retryTicker(ctx context.Context) {
 	}
 }
 
-func (c *InformerController) startEvent(eventType string, resourceKind string) time.Time {
+// nextRetry returns the retry to queue after a retry of val at time t, and false if it should not be retried again.
+// If the retry failed, and the RetryPolicy does not retry it again, the event is passed to the DeadLetterHandler.
+func (c *InformerController) nextRetry(
+	ctx context.Context, key string, val retryInfo, t time.Time, specifiedRetry *time.Duration, err error,
+) (retryInfo, bool) {
+	errs := val.errs
+	firstFailure := val.firstFailure
+	if err != nil {
+		errs = append(append(make([]error, 0, len(val.errs)+1), val.errs...), err)
+		if firstFailure.IsZero() {
+			firstFailure = t
+		}
+	}
+	if specifiedRetry != nil {
+		return retryInfo{
+			attempt:    val.attempt, // TODO: whether or not this should trigger an attempt increase
+			retryAfter: t.Add(*specifiedRetry),
+			retryFunc:    val.retryFunc,
+			action:       val.action,
+			object:       val.object,
+			errs:         errs,
+			firstFailure: firstFailure,
+			requestID:    val.requestID,
+			queue:        val.queue,
+		}, true
+	}
+	if err != nil && c.RetryPolicy != nil {
+		next := retryInfo{
+			attempt:      val.attempt + 1,
+			retryFunc:    val.retryFunc,
+			action:       val.action,
+			object:       val.object,
+			err:          err,
+			errs:         errs,
+			firstFailure: firstFailure,
+			requestID:    val.requestID,
+			queue:        val.queue,
+		}
+		if ok, after := c.RetryPolicy(err, val.attempt+1); ok {
+			next.retryAfter = t.Add(after)
+			return next, true
+		}
+		c.deadLetter(ctx, key, next)
+	}
+	return retryInfo{}, false
+}
+
+// reportError sends report to the ErrorReporter, or calls the ErrorHandler if there is no ErrorReporter
+func (c *InformerController) reportError(ctx context.Context, report app.ErrorReport) {
+	reportError(ctx, c.ErrorReporter, c.ErrorHandler, report)
+}
+
+func (c *InformerController) startEvent(ctx context.Context, eventType string, resourceKind string) time.Time {
 	if c.totalEvents != nil {
-		c.totalEvents.WithLabelValues(eventType, resourceKind).Inc()
+		metrics.IncWithTraceExemplar(ctx, c.totalEvents.WithLabelValues(eventType, resourceKind))
 	}
 	if c.inflightEvents != nil {
 		c.inflightEvents.WithLabelValues(eventType, resourceKind).Inc()
@@ -630,27 +1444,36 @@ func (c *InformerController) startEvent(eventType string, resourceKind string) t
 	return time.Now()
 }
 
-func (c *InformerController) completeEvent(eventType string, resourceKind string, startTime time.Time) {
+func (c *InformerController) completeEvent(ctx context.Context, eventType string, resourceKind string, startTime time.Time) {
 	if c.inflightEvents != nil {
 		c.inflightEvents.WithLabelValues(eventType, resourceKind).Dec()
 	}
 	if c.reconcileLatency != nil {
-		c.reconcileLatency.WithLabelValues(eventType, resourceKind).Observe(time.Since(startTime).Seconds())
+		metrics.ObserveWithTraceExemplar(ctx, c.reconcileLatency.WithLabelValues(eventType, resourceKind), time.Since(startTime).Seconds())
 	}
 }
 
-func (c *InformerController) wrapWatcherCall(eventType string, resourceKind string, f func()) {
+func (c *InformerController) wrapWatcherCall(ctx context.Context, eventType string, resourceKind string, f func(context.Context)) {
 	if c.inflightActions != nil {
 		c.inflightActions.WithLabelValues(eventType, resourceKind).Inc()
 		defer c.inflightActions.WithLabelValues(eventType, resourceKind).Dec()
 	}
 	start := time.Now()
-	f()
+	callCtx, span := startWatcherSpan(ctx, eventType, resourceKind)
+	defer span.End()
+	callCtx, cancel := c.operationContext(callCtx)
+	defer cancel()
+	f(callCtx)
 	if c.watcherLatency != nil {
-		c.watcherLatency.WithLabelValues(eventType, resourceKind).Observe(time.Since(start).Seconds())
+		metrics.ObserveWithTraceExemplar(ctx, c.watcherLatency.WithLabelValues(eventType, resourceKind), time.Since(start).Seconds())
 	}
 }
 
+// operationContext returns the context for a single watcher or reconciler call, bounded by the OperationTimeout
+func (c *InformerController) operationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return resource.WithOperationTimeout(ctx, c.operationTimeout)
+}
+
 func (*InformerController) keyForWatcherEvent(resourceKind string, watcherIndex int, obj resource.Object) string {
 	if obj == nil {
 		return fmt.Sprintf("%s:%d:nil:nil", resourceKind, watcherIndex)
@@ -665,18 +1488,160 @@ func (*InformerController) keyForReconcilerEvent(resourceKind string, reconciler
 	return fmt.Sprintf("reconcile:%s:%d:%s:%s", resourceKind, reconcilerIndex, obj.GetNamespace(), obj.GetName())
 }
 
-func (c *InformerController) queueRetry(key string, err error, toRetry func() (*time.Duration, error), action ResourceAction, obj resource.Object) {
+// handlerForRetryKey returns the part of a key from keyForWatcherEvent or keyForReconcilerEvent which identifies
+// the watcher or reconciler, by removing the namespace and name (which cannot contain ':')
+func handlerForRetryKey(key string) string {
+	for range 2 {
+		if idx := strings.LastIndex(key, ":"); idx >= 0 {
+			key = key[:idx]
+		}
+	}
+	return key
+}
+
+// eventContext returns the context for the watcher and reconciler calls for an event: ctx with a new request ID
+// (unless ctx already has one), and the controller's EventRecorder (if it has one, and ctx does not already have one).
+func (c *InformerController) eventContext(ctx context.Context) context.Context {
+	if RequestIDFromContext(ctx) == "" {
+		ctx = withRequestID(ctx, newRequestID())
+	}
+	if c.EventRecorder != nil {
+		if _, ok := ctx.Value(eventRecorderContextKey{}).(EventRecorder); !ok {
+			ctx = WithEventRecorder(ctx, c.EventRecorder)
+		}
+	}
+	return ctx
+}
+
+// removeRetries removes all pending retries with keys which start with prefix
+func (c *InformerController) removeRetries(prefix string) {
+	for _, key := range c.toRetry.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			c.toRetry.RemoveKey(key)
+		}
+	}
+}
+
+func (c *InformerController) queueRetry(
+	ctx context.Context, key string, err error, toRetry func(attempt int) (*time.Duration, error), action ResourceAction, obj resource.Object, queue *reconcileQueue,
+) {
 	if c.RetryPolicy == nil {
 		return
 	}
 
+	info := retryInfo{
+		retryFunc:    toRetry,
+		action:       action,
+		object:       obj,
+		err:          err,
+		errs:         []error{err},
+		firstFailure: time.Now(),
+		requestID:    RequestIDFromContext(ctx),
+		queue:        queue,
+	}
 	if ok, after := c.RetryPolicy(err, 0); ok {
-		c.toRetry.AddItem(key, retryInfo{
-			retryAfter: time.Now().Add(after),
-			retryFunc:  toRetry,
-			action:     action,
-			object:     obj,
-			err:        err,
-		})
+		info.retryAfter = time.Now().Add(after)
+		c.toRetry.AddItem(key, info)
+		return
+	}
+	c.deadLetter(ctx, key, info)
+}
+
+// deadLetter calls the DeadLetterHandler, if there is one, with the event of info, which is no longer being retried,
+// and notifies the FailureNotifier, if there is one
+func (c *InformerController) deadLetter(ctx context.Context, retryKey string, info retryInfo) {
+	if c.DeadLetterHandler == nil && c.failures == nil {
+		return
+	}
+	if info.requestID != "" {
+		ctx = withRequestID(ctx, info.requestID)
+	}
+	letter := DeadLetter{
+		Action:       info.action,
+		Object:       info.object,
+		Errors:       info.errs,
+		FirstFailure: info.firstFailure,
+		RequestID:    info.requestID,
+	}
+	if c.DeadLetterHandler != nil {
+		c.DeadLetterHandler(ctx, letter)
+	}
+	if c.failures != nil && letter.Object != nil {
+		c.failures.deadLetter(ctx, handlerForRetryKey(retryKey), letter)
+	}
+}
+
+// informerSyncedCollector is a prometheus.Collector which reports whether the informers for each kind have synced,
+// for informers which expose a HasSynced method (such as KubernetesBasedInformer and CustomCacheInformer).
+// A kind is only reported as synced if all of its informers have synced.
+type informerSyncedCollector struct {
+	informers *ListMap[string, Informer]
+	desc      *prometheus.Desc
+}
+
+func (c *informerSyncedCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *informerSyncedCollector) Collect(ch chan<- prometheus.Metric) {
+	synced := make(map[string]bool)
+	c.informers.RangeAll(func(kind string, _ int, value Informer) {
+		cast, ok := value.(interface{ HasSynced() bool })
+		if !ok {
+			return
+		}
+		if s, ok := synced[kind]; ok && !s {
+			return
+		}
+		synced[kind] = cast.HasSynced()
+	})
+	for kind, s := range synced {
+		val := 0.0
+		if s {
+			val = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, val, kind)
+	}
+}
+
+// reconcileQueueDepthCollector is a prometheus.Collector which reports the number of reconciles waiting for each kind,
+// both in the work queues of reconcilers with MaxConcurrentReconciles, and in the retry queue.
+type reconcileQueueDepthCollector struct {
+	retries *ListMap[string, retryInfo]
+	desc    *prometheus.Desc
+	mux     sync.Mutex
+	// work is the number of reconciles in work queues, by kind
+	work map[string]int
+}
+
+// addWork adds delta to the number of reconciles for kind in work queues
+func (c *reconcileQueueDepthCollector) addWork(kind string, delta int) {
+	if c == nil {
+		return
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.work[kind] += delta
+}
+
+func (c *reconcileQueueDepthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *reconcileQueueDepthCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mux.Lock()
+	work := maps.Clone(c.work)
+	c.mux.Unlock()
+	retries := make(map[string]int)
+	c.retries.RangeAll(func(_ string, _ int, value retryInfo) {
+		if value.object != nil {
+			retries[value.object.GetStaticMetadata().Kind]++
+		}
+	})
+	for kind, depth := range work {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(depth), kind, "work")
+	}
+	for kind, depth := range retries {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(depth), kind, "retry")
 	}
 }
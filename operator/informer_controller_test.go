@@ -5,14 +5,21 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-app-sdk/app"
 	"github.com/grafana/grafana-app-sdk/resource"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestInformerController_AddWatcher(t *testing.T) {
@@ -91,6 +98,21 @@ func TestInformerController_RemoveWatcher(t *testing.T) {
 		assert.Equal(t, 0, c.watchers.KeySize(k))
 	})
 
+	t.Run("pending retries", func(t *testing.T) {
+		c := NewInformerController(InformerControllerConfig{})
+		inf := &testInformer{}
+		require.Nil(t, c.AddInformer(inf, "foo"))
+		w1 := &SimpleWatcher{AddFunc: func(context.Context, resource.Object) error { return errors.New("I AM ERROR") }}
+		w2 := &SimpleWatcher{AddFunc: func(context.Context, resource.Object) error { return errors.New("I AM ERROR") }}
+		require.Nil(t, c.AddWatcher(w1, "foo"))
+		require.Nil(t, c.AddWatcher(w2, "foo"))
+		inf.FireAdd(context.Background(), newListerTestObject("ns", "a"))
+		assert.Equal(t, 2, c.toRetry.Size())
+		// Only the retries for the removed watcher are removed
+		c.RemoveWatcher(w1, "foo")
+		assert.Equal(t, []string{"foo:1:ns:a"}, c.toRetry.Keys())
+	})
+
 	t.Run("preserve order", func(t *testing.T) {
 		w1 := &SimpleWatcher{}
 		w2 := &SimpleWatcher{}
@@ -233,6 +255,20 @@ func TestInformerController_RemoveReconciler(t *testing.T) {
 		assert.Equal(t, 0, c.reconcilers.KeySize(k))
 	})
 
+	t.Run("pending retries", func(t *testing.T) {
+		c := NewInformerController(InformerControllerConfig{})
+		inf := &testInformer{}
+		require.Nil(t, c.AddInformer(inf, "foo"))
+		r := &SimpleReconciler{ReconcileFunc: func(context.Context, ReconcileRequest) (ReconcileResult, error) {
+			return ReconcileResult{}, errors.New("I AM ERROR")
+		}}
+		require.Nil(t, c.AddReconciler(r, "foo"))
+		inf.FireAdd(context.Background(), newListerTestObject("ns", "a"))
+		assert.Equal(t, 1, c.toRetry.Size())
+		c.RemoveReconciler(r, "foo")
+		assert.Equal(t, 0, c.toRetry.Size())
+	})
+
 	t.Run("preserve order", func(t *testing.T) {
 		r1 := &SimpleReconciler{}
 		r2 := &SimpleReconciler{}
@@ -1053,6 +1089,418 @@ func TestInformerController_Run_WithRetriesAndDequeuePolicy(t *testing.T) {
 	})
 }
 
+func TestInformerController_PrometheusCollectors(t *testing.T) {
+	c := NewInformerController(InformerControllerConfig{})
+	synced := &testSyncedInformer{}
+	require.Nil(t, c.AddInformer(synced, "foo"))
+	require.Nil(t, c.AddInformer(&testInformer{}, "bar"))
+
+	// Only informers which expose HasSynced are reported
+	assert.Equal(t, 1, testutil.CollectAndCount(c.informerSynced))
+	assert.Equal(t, float64(0), collectGaugeValue(t, c.informerSynced))
+	synced.synced = true
+	assert.Equal(t, float64(1), collectGaugeValue(t, c.informerSynced))
+
+	assert.Equal(t, float64(0), testutil.ToFloat64(c.retryQueueSize))
+	c.toRetry.AddItem("foo", retryInfo{}, retryInfo{})
+	c.toRetry.AddItem("bar", retryInfo{})
+	assert.Equal(t, float64(3), testutil.ToFloat64(c.retryQueueSize))
+}
+
+func TestInformerController_ReconcileMetrics(t *testing.T) {
+	c := NewInformerController(InformerControllerConfig{})
+	obj := &resource.UntypedObject{}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{Group: "foo", Version: "v1", Kind: "Foo"})
+	obj.SetNamespace("ns")
+	requeue := time.Minute
+	results := []struct {
+		res ReconcileResult
+		err error
+	}{
+		{ReconcileResult{}, nil},
+		{ReconcileResult{RequeueAfter: &requeue}, nil},
+		{ReconcileResult{}, errors.New("I AM ERROR")},
+	}
+	for i, r := range results {
+		reconciler := &SimpleReconciler{
+			ReconcileFunc: func(context.Context, ReconcileRequest) (ReconcileResult, error) {
+				return r.res, r.err
+			},
+		}
+		_, _ = c.callReconciler(context.Background(), reconciler, ReconcileRequest{Object: obj}, "reconcile:foo:0:ns:a", i > 0)
+	}
+	assert.Equal(t, float64(1), testutil.ToFloat64(c.reconcileResults.WithLabelValues("Foo", "ns", "success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(c.reconcileResults.WithLabelValues("Foo", "ns", "requeue")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(c.reconcileResults.WithLabelValues("Foo", "ns", "error")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(c.reconcileRetries.WithLabelValues("Foo", "ns")))
+	assert.Equal(t, 1, testutil.CollectAndCount(c.reconcileDuration))
+
+	// Queue depth is reported by kind for work queues and retries
+	assert.Equal(t, 0, testutil.CollectAndCount(c.queueDepth))
+	c.toRetry.AddItem("foo", retryInfo{object: obj}, retryInfo{object: obj})
+	c.queueDepth.addWork("Foo", 1)
+	assert.Nil(t, testutil.CollectAndCompare(c.queueDepth, strings.NewReader(`
+# HELP reconciler_queue_depth Current number of reconciles for a kind waiting in reconciler work queues (queue="work") or to be retried (queue="retry")
+# TYPE reconciler_queue_depth gauge
+reconciler_queue_depth{kind="Foo",queue="retry"} 2
+reconciler_queue_depth{kind="Foo",queue="work"} 1
+`)))
+}
+
+func collectGaugeValue(t *testing.T, collector prometheus.Collector) float64 {
+	ch := make(chan prometheus.Metric, 1)
+	collector.Collect(ch)
+	close(ch)
+	m := dto.Metric{}
+	require.Nil(t, (<-ch).Write(&m))
+	return m.GetGauge().GetValue()
+}
+
+func TestInformerController_OperationTimeout(t *testing.T) {
+	kind := "foo"
+	inf := &testInformer{}
+	c := NewInformerController(InformerControllerConfig{
+		OperationTimeout: 50 * time.Millisecond,
+	})
+	var watcherErr, reconcileErr error
+	c.AddWatcher(&SimpleWatcher{
+		AddFunc: func(ctx context.Context, object resource.Object) error {
+			<-ctx.Done()
+			watcherErr = ctx.Err()
+			return nil
+		},
+	}, kind)
+	c.AddReconciler(&SimpleReconciler{
+		ReconcileFunc: func(ctx context.Context, request ReconcileRequest) (ReconcileResult, error) {
+			<-ctx.Done()
+			reconcileErr = ctx.Err()
+			return ReconcileResult{}, nil
+		},
+	}, kind)
+	require.Nil(t, c.AddInformer(inf, kind))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+	start := time.Now()
+	inf.FireAdd(context.Background(), emptyObject)
+	// Each call is canceled once it exceeds the timeout
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, context.DeadlineExceeded, watcherErr)
+	assert.Equal(t, context.DeadlineExceeded, reconcileErr)
+}
+
+func TestInformerController_ErrorReporter(t *testing.T) {
+	kind := "foo"
+	obj := &resource.TypedSpecObject[string]{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "Foo",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "bar",
+		},
+	}
+	watcherErr := errors.New("watcher error")
+	reconcilerErr := errors.New("reconciler error")
+	setup := func(cfg InformerControllerConfig) (*InformerController, *testInformer) {
+		cfg.RetryPolicy = func(err error, attempt int) (bool, time.Duration) {
+			return false, 0
+		}
+		inf := &testInformer{}
+		c := NewInformerController(cfg)
+		c.AddWatcher(&SimpleWatcher{
+			AddFunc: func(ctx context.Context, object resource.Object) error {
+				return watcherErr
+			},
+		}, kind)
+		c.AddReconciler(&SimpleReconciler{
+			ReconcileFunc: func(ctx context.Context, request ReconcileRequest) (ReconcileResult, error) {
+				return ReconcileResult{}, reconcilerErr
+			},
+		}, kind)
+		require.Nil(t, c.AddInformer(inf, kind))
+		return c, inf
+	}
+
+	t.Run("reports watcher and reconciler errors", func(t *testing.T) {
+		reports := make([]app.ErrorReport, 0)
+		handled := 0
+		_, inf := setup(InformerControllerConfig{
+			ErrorReporter: app.ErrorReporterFunc(func(ctx context.Context, report app.ErrorReport) {
+				reports = append(reports, report)
+			}),
+			ErrorHandler: func(ctx context.Context, err error) {
+				handled++
+			},
+		})
+		inf.FireAdd(context.Background(), obj)
+		assert.Equal(t, []app.ErrorReport{{
+			Err:       watcherErr,
+			Class:     app.ErrorClassWatcher,
+			Component: "InformerController",
+			Kind:      "Foo",
+			Object:    resource.Identifier{Namespace: "ns", Name: "bar"},
+		}, {
+			Err:       reconcilerErr,
+			Class:     app.ErrorClassReconciler,
+			Component: "InformerController",
+			Kind:      "Foo",
+			Object:    resource.Identifier{Namespace: "ns", Name: "bar"},
+		}}, reports)
+		// ErrorHandler is not used when there is an ErrorReporter
+		assert.Equal(t, 0, handled)
+	})
+
+	t.Run("falls back to ErrorHandler", func(t *testing.T) {
+		handled := make([]error, 0)
+		_, inf := setup(InformerControllerConfig{
+			ErrorHandler: func(ctx context.Context, err error) {
+				handled = append(handled, err)
+			},
+		})
+		inf.FireAdd(context.Background(), obj)
+		assert.Equal(t, []error{watcherErr, reconcilerErr}, handled)
+	})
+}
+
+func TestInformerController_WaitForSync(t *testing.T) {
+	c := NewInformerController(InformerControllerConfig{})
+	synced := &testSyncedInformer{}
+	require.Nil(t, c.AddInformer(synced, "foo"))
+	require.Nil(t, c.AddInformer(&testInformer{}, "bar"))
+
+	ctx, cancel := resource.WithOperationTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, c.WaitForSync(ctx))
+
+	// Informers which don't expose HasSynced are ignored
+	synced.synced = true
+	assert.Nil(t, c.WaitForSync(context.Background()))
+}
+
+func TestInformerController_ReplayCache(t *testing.T) {
+	existing := newListerTestObject("ns", "a")
+	deleting := newListerTestObject("ns", "b")
+	deleting.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	inf := &testCacheInformer{
+		Lister: newTestListerInformer(t, existing, deleting),
+	}
+	c := NewInformerController(InformerControllerConfig{})
+	require.Nil(t, c.AddInformer(inf, "foo"))
+
+	// Reconcilers added before the controller runs receive the initial list from the informer, so nothing is replayed
+	early := make(chan ReconcileRequest, 10)
+	require.Nil(t, c.AddReconcilerWithOptions(&SimpleReconciler{
+		ReconcileFunc: func(_ context.Context, req ReconcileRequest) (ReconcileResult, error) {
+			early <- req
+			return ReconcileResult{}, nil
+		},
+	}, "foo", ReconcilerOptions{ReplayCache: true}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+	assert.Eventually(t, func() bool {
+		c.runMux.RLock()
+		defer c.runMux.RUnlock()
+		return c.runCtx != nil
+	}, time.Second, 5*time.Millisecond)
+
+	reconciled := make(chan ReconcileRequest, 10)
+	require.Nil(t, c.AddReconcilerWithOptions(&SimpleReconciler{
+		ReconcileFunc: func(_ context.Context, req ReconcileRequest) (ReconcileResult, error) {
+			reconciled <- req
+			return ReconcileResult{}, nil
+		},
+	}, "foo", ReconcilerOptions{ReplayCache: true, MaxConcurrentReconciles: 1}))
+	filtered := make(chan ReconcileRequest, 10)
+	require.Nil(t, c.AddReconcilerWithOptions(&SimpleReconciler{
+		ReconcileFunc: func(_ context.Context, req ReconcileRequest) (ReconcileResult, error) {
+			filtered <- req
+			return ReconcileResult{}, nil
+		},
+	}, "foo", ReconcilerOptions{ReplayCache: true, Predicates: []Predicate{NewPredicateFunc(func(resource.Object) bool { return false })}}))
+	notReplayed := make(chan ReconcileRequest, 10)
+	require.Nil(t, c.AddReconciler(&SimpleReconciler{
+		ReconcileFunc: func(_ context.Context, req ReconcileRequest) (ReconcileResult, error) {
+			notReplayed <- req
+			return ReconcileResult{}, nil
+		},
+	}, "foo"))
+	synced := make(chan resource.Object, 10)
+	require.Nil(t, c.AddWatcherWithOptions(&testReplaySyncWatcher{
+		SimpleWatcher: SimpleWatcher{AddFunc: func(context.Context, resource.Object) error {
+			t.Error("Add should not be called for watchers with a Sync method")
+			return nil
+		}},
+		syncFunc: func(_ context.Context, obj resource.Object) error {
+			synced <- obj
+			return nil
+		},
+	}, "foo", WatcherOptions{ReplayCache: true}))
+
+	select {
+	case req := <-reconciled:
+		assert.Equal(t, ReconcileActionResynced, req.Action)
+		assert.Equal(t, "a", req.Object.GetName())
+		// The request must not contain the object from the cache
+		assert.NotSame(t, existing, req.Object)
+	case <-time.After(time.Second):
+		t.Fatal("cache was not replayed to the reconciler")
+	}
+	select {
+	case obj := <-synced:
+		assert.Equal(t, "a", obj.GetName())
+	case <-time.After(time.Second):
+		t.Fatal("cache was not replayed to the watcher")
+	}
+	// Wait briefly for any unexpected replays
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, reconciled, 0)
+	assert.Len(t, synced, 0)
+	assert.Len(t, filtered, 0)
+	assert.Len(t, notReplayed, 0)
+	assert.Len(t, early, 0)
+}
+
+type testCacheInformer struct {
+	testInformer
+	Lister
+}
+
+type testReplaySyncWatcher struct {
+	SimpleWatcher
+	syncFunc func(context.Context, resource.Object) error
+}
+
+func (w *testReplaySyncWatcher) Sync(ctx context.Context, obj resource.Object) error {
+	return w.syncFunc(ctx, obj)
+}
+
+func TestInformerController_MaxConcurrentReconciles(t *testing.T) {
+	kind := "foo"
+	newObj := func(name string) resource.Object {
+		return &resource.TypedSpecObject[string]{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+		}
+	}
+
+	t.Run("objects reconciled in parallel", func(t *testing.T) {
+		inf := &testInformer{}
+		c := NewInformerController(InformerControllerConfig{
+			MaxConcurrentReconciles: 2,
+		})
+		// Each reconcile waits for the other object's reconcile to start, so this only completes if both run at once
+		started := sync.WaitGroup{}
+		started.Add(2)
+		reconciled := make(chan string, 2)
+		require.Nil(t, c.AddReconciler(&SimpleReconciler{
+			ReconcileFunc: func(ctx context.Context, request ReconcileRequest) (ReconcileResult, error) {
+				started.Done()
+				started.Wait()
+				reconciled <- request.Object.GetName()
+				return ReconcileResult{}, nil
+			},
+		}, kind))
+		require.Nil(t, c.AddInformer(inf, kind))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go c.Run(ctx)
+		// FireAdd does not block on the reconciler
+		inf.FireAdd(context.Background(), newObj("a"))
+		inf.FireAdd(context.Background(), newObj("b"))
+		names := make([]string, 0)
+		for i := 0; i < 2; i++ {
+			select {
+			case name := <-reconciled:
+				names = append(names, name)
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for reconciles")
+			}
+		}
+		assert.ElementsMatch(t, []string{"a", "b"}, names)
+	})
+
+	t.Run("same object reconciled sequentially, with retries", func(t *testing.T) {
+		inf := &testInformer{}
+		c := NewInformerController(InformerControllerConfig{
+			MaxConcurrentReconciles: 2,
+		})
+		c.retryTickerInterval = 10 * time.Millisecond
+		c.RetryPolicy = func(err error, attempt int) (bool, time.Duration) {
+			return attempt < 2, time.Millisecond
+		}
+		running := atomic.Int32{}
+		overlapped := atomic.Bool{}
+		calls := atomic.Int32{}
+		require.Nil(t, c.AddReconciler(&SimpleReconciler{
+			ReconcileFunc: func(ctx context.Context, request ReconcileRequest) (ReconcileResult, error) {
+				defer running.Add(-1)
+				if running.Add(1) > 1 {
+					overlapped.Store(true)
+				}
+				time.Sleep(5 * time.Millisecond)
+				if calls.Add(1) == 1 {
+					return ReconcileResult{}, errors.New("I AM ERROR")
+				}
+				return ReconcileResult{}, nil
+			},
+		}, kind))
+		require.Nil(t, c.AddInformer(inf, kind))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go c.Run(ctx)
+		inf.FireAdd(context.Background(), newObj("a"))
+		inf.FireUpdate(context.Background(), newObj("a"), newObj("a"))
+		// Add, update, and the retry of the failed add
+		assert.Eventually(t, func() bool {
+			return calls.Load() == 3
+		}, time.Second, 5*time.Millisecond)
+		assert.False(t, overlapped.Load())
+	})
+
+	t.Run("remove reconciler", func(t *testing.T) {
+		c := NewInformerController(InformerControllerConfig{
+			MaxConcurrentReconciles: 1,
+		})
+		reconciler := &SimpleReconciler{}
+		require.Nil(t, c.AddReconciler(reconciler, kind))
+		require.Nil(t, c.AddReconcilerWithOptions(&SimpleReconciler{}, kind, ReconcilerOptions{}))
+		assert.Equal(t, 2, c.reconcilers.KeySize(kind))
+		c.RemoveReconciler(reconciler, kind)
+		assert.Equal(t, 1, c.reconcilers.KeySize(kind))
+		assert.NotNil(t, c.AddReconcilerWithOptions(reconciler, kind, ReconcilerOptions{MaxConcurrentReconciles: -1}))
+	})
+
+	t.Run("invalid fair queueing", func(t *testing.T) {
+		c := NewInformerController(InformerControllerConfig{})
+		err := c.AddReconcilerWithOptions(&SimpleReconciler{}, kind, ReconcilerOptions{
+			MaxConcurrentReconciles: 1,
+			FairQueueing: &FairQueueing{
+				PriorityLabel:   "priority",
+				PriorityWeights: map[string]int{"low": 0},
+			},
+		})
+		assert.EqualError(t, err, "invalid options.FairQueueing: weight of priority 'low' must be positive")
+		assert.Equal(t, 0, c.reconcilers.KeySize(kind))
+	})
+}
+
+type testSyncedInformer struct {
+	testInformer
+	synced bool
+}
+
+func (i *testSyncedInformer) HasSynced() bool {
+	return i.synced
+}
+
 func TestOpinionatedRetryDequeuePolicy(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -17,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/tools/cache"
 
+	"github.com/grafana/grafana-app-sdk/app"
 	"github.com/grafana/grafana-app-sdk/logging"
 	"github.com/grafana/grafana-app-sdk/metrics"
 	"github.com/grafana/grafana-app-sdk/resource"
@@ -31,8 +32,13 @@ type CustomCacheInformer struct {
 	// This is distinct from a full resync, as no information is fetched from the API server.
 	// Changes to this value after run() is called will not take effect.
 	CacheResyncInterval time.Duration
+	// ErrorReporter, if non-nil, is sent a report of each error the informer encounters which does not stop it from running,
+	// but may stop it from processing a given event. If nil, ErrorHandler is called instead.
+	ErrorReporter app.ErrorReporter
 	// ErrorHandler is called if the informer encounters an error which does not stop the informer from running,
-	// but may stop it from processing a given event.
+	// but may stop it from processing a given event. It is only called if ErrorReporter is nil.
+	//
+	// Deprecated: use ErrorReporter, which receives the kind and object the error was encountered for.
 	ErrorHandler func(context.Context, error)
 
 	started           bool
@@ -43,6 +49,7 @@ type CustomCacheInformer struct {
 	objectType        resource.Object
 	processor         *informerProcessor
 	objectTransformer func(any) (resource.Object, error)
+	kind              string
 	runContext        context.Context
 }
 
@@ -74,6 +81,7 @@ func NewCustomCacheInformer(store cache.Store, lw cache.ListerWatcher, kind reso
 		// We can enable the k8s.KindNegotiatedSerializer for this, but it would be used by all clients then
 		// objectType:    kind.ZeroValue(),
 		processor: newInformerProcessor(),
+		kind:      kind.Kind(),
 		objectTransformer: func(a any) (resource.Object, error) {
 			return toResourceObject(a, kind)
 		},
@@ -196,15 +204,34 @@ func (c *CustomCacheInformer) OnDelete(obj any) {
 	})
 }
 
-func (c *CustomCacheInformer) errorHandler(ctx context.Context, err error) {
-	if c.ErrorHandler != nil {
-		c.ErrorHandler(ctx, err)
+func (c *CustomCacheInformer) errorHandler(ctx context.Context, err error, object resource.Object) {
+	report := objectErrorReport(err, app.ErrorClassInformer, "CustomCacheInformer", object)
+	if report.Kind == "" {
+		report.Kind = c.kind
 	}
+	reportError(ctx, c.ErrorReporter, c.ErrorHandler, report)
 }
 
 // NewListerWatcher returns a cache.ListerWatcher for the provided resource.Schema that uses the given ListWatchClient.
 // The List and Watch requests will always use the provided namespace and labelFilters.
+// If the client returns a *resource.StrictDecodingListError from a list, the list is still used,
+// and the error of each object is logged.
 func NewListerWatcher(client ListWatchClient, sch resource.Schema, filterOptions ListWatchOptions) cache.ListerWatcher {
+	return newListerWatcher(client, sch, filterOptions, nil)
+}
+
+// newListerWatcher returns a cache.ListerWatcher like NewListerWatcher, which passes the error of each object
+// with unknown fields in a list to decodingErrorHandler, if it is non-nil, instead of logging it
+func newListerWatcher(
+	client ListWatchClient, sch resource.Schema, filterOptions ListWatchOptions,
+	decodingErrorHandler func(context.Context, error, resource.Object),
+) cache.ListerWatcher {
+	if decodingErrorHandler == nil {
+		decodingErrorHandler = func(ctx context.Context, err error, obj resource.Object) {
+			logging.FromContext(ctx).Warn("listed object has unknown fields", "kind", sch.Kind(),
+				"namespace", obj.GetNamespace(), "name", obj.GetName(), "error", err)
+		}
+	}
 	return &cache.ListWatch{
 		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
 			ctx, span := GetTracer().Start(context.Background(), "informer-list")
@@ -217,12 +244,25 @@ func NewListerWatcher(client ListWatchClient, sch resource.Schema, filterOptions
 			)
 			resp := resource.UntypedList{}
 			err := client.ListInto(ctx, filterOptions.Namespace, resource.ListOptions{
-				LabelFilters:    filterOptions.LabelFilters,
-				FieldSelectors:  filterOptions.FieldSelectors,
-				Continue:        options.Continue,
-				Limit:           int(options.Limit),
-				ResourceVersion: options.ResourceVersion,
+				LabelFilters:         filterOptions.LabelFilters,
+				FieldSelectors:       filterOptions.FieldSelectors,
+				Continue:             options.Continue,
+				Limit:                int(options.Limit),
+				ResourceVersion:      options.ResourceVersion,
+				ResourceVersionMatch: string(options.ResourceVersionMatch),
 			}, &resp)
+			// One object with unknown fields should not fail the whole list, so it is reported on its own
+			var strictErr *resource.StrictDecodingListError
+			if errors.As(err, &strictErr) {
+				items := make(map[resource.Identifier]resource.Object, len(resp.GetItems()))
+				for _, item := range resp.GetItems() {
+					items[resource.Identifier{Namespace: item.GetNamespace(), Name: item.GetName()}] = item
+				}
+				for _, objErr := range strictErr.Objects {
+					decodingErrorHandler(ctx, objErr.Err, items[objErr.Identifier])
+				}
+				err = nil
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -243,13 +283,10 @@ func NewListerWatcher(client ListWatchClient, sch resource.Schema, filterOptions
 				LabelFilters:         filterOptions.LabelFilters,
 				FieldSelectors:       filterOptions.FieldSelectors,
 			}
-			// TODO: can't defer the cancel call for the context, because it should only be canceled if the
-			// _caller_ of WatchFunc finishes with the WatchResponse before the timeout elapses...
-			// Seems to be a limitation of the kubernetes implementation here
-			/* if options.TimeoutSeconds != nil {
-				timeout := time.Duration(*options.TimeoutSeconds) * time.Second
-				ctx, cancel = context.WithTimeout(ctx, timeout)
-			}*/
+			// The timeout is enforced by the server, which ends the watch once it elapses
+			if options.TimeoutSeconds != nil {
+				opts.TimeoutSeconds = *options.TimeoutSeconds
+			}
 			watchResp, err := client.Watch(ctx, filterOptions.Namespace, opts)
 			if err != nil {
 				return nil, err
@@ -268,7 +305,7 @@ func NewListerWatcher(client ListWatchClient, sch resource.Schema, filterOptions
 	}
 }
 
-func toResourceEventHandlerFuncs(handler ResourceWatcher, transformer func(any) (resource.Object, error), errorHandler func(context.Context, error), contextProvider func() context.Context) *cache.ResourceEventHandlerFuncs {
+func toResourceEventHandlerFuncs(handler ResourceWatcher, transformer func(any) (resource.Object, error), errorHandler func(context.Context, error, resource.Object), contextProvider func() context.Context) *cache.ResourceEventHandlerFuncs {
 	return &cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj any) {
 			ctx, span := GetTracer().Start(contextProvider(), "informer-event-add")
@@ -276,7 +313,7 @@ func toResourceEventHandlerFuncs(handler ResourceWatcher, transformer func(any)
 			cast, err := transformer(obj)
 			if err != nil {
 				span.SetStatus(codes.Error, err.Error())
-				errorHandler(ctx, err)
+				errorHandler(ctx, err, nil)
 				return
 			}
 			gvk := cast.GroupVersionKind()
@@ -290,7 +327,7 @@ func toResourceEventHandlerFuncs(handler ResourceWatcher, transformer func(any)
 			err = handler.Add(ctx, cast)
 			if err != nil {
 				span.SetStatus(codes.Error, err.Error())
-				errorHandler(ctx, err)
+				errorHandler(ctx, err, cast)
 			}
 		},
 		UpdateFunc: func(oldObj, newObj any) {
@@ -299,7 +336,7 @@ func toResourceEventHandlerFuncs(handler ResourceWatcher, transformer func(any)
 			cOld, err := transformer(oldObj)
 			if err != nil {
 				span.SetStatus(codes.Error, err.Error())
-				errorHandler(ctx, err)
+				errorHandler(ctx, err, nil)
 				return
 			}
 			// None of these should change between old and new, so we can set them here with old's values
@@ -314,13 +351,13 @@ func toResourceEventHandlerFuncs(handler ResourceWatcher, transformer func(any)
 			cNew, err := transformer(newObj)
 			if err != nil {
 				span.SetStatus(codes.Error, err.Error())
-				errorHandler(ctx, err)
+				errorHandler(ctx, err, nil)
 				return
 			}
 			err = handler.Update(ctx, cOld, cNew)
 			if err != nil {
 				span.SetStatus(codes.Error, err.Error())
-				errorHandler(ctx, err)
+				errorHandler(ctx, err, cNew)
 			}
 		},
 		DeleteFunc: func(obj any) {
@@ -329,7 +366,7 @@ func toResourceEventHandlerFuncs(handler ResourceWatcher, transformer func(any)
 			cast, err := transformer(obj)
 			if err != nil {
 				span.SetStatus(codes.Error, err.Error())
-				errorHandler(ctx, err)
+				errorHandler(ctx, err, nil)
 				return
 			}
 			gvk := cast.GroupVersionKind()
@@ -343,7 +380,7 @@ func toResourceEventHandlerFuncs(handler ResourceWatcher, transformer func(any)
 			err = handler.Delete(ctx, cast)
 			if err != nil {
 				span.SetStatus(codes.Error, err.Error())
-				errorHandler(ctx, err)
+				errorHandler(ctx, err, cast)
 			}
 		},
 	}
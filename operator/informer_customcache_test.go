@@ -364,3 +364,37 @@ func (u *unsafeCache) Replace([]any, string) error {
 func (u *unsafeCache) Resync() error {
 	return nil
 }
+
+func TestNewListerWatcher_StrictDecodingErrors(t *testing.T) {
+	objs := []resource.Object{newListerTestObject("a", "one"), newListerTestObject("a", "two")}
+	client := &strictListTestClient{
+		namespacesTestClient: newNamespacesTestClient(map[string][]resource.Object{"a": objs}),
+		err: &resource.StrictDecodingListError{Objects: []resource.StrictDecodingObjectError{{
+			Identifier: resource.Identifier{Namespace: "a", Name: "two"},
+			Err:        &resource.StrictDecodingError{UnknownFields: []string{"spec.foo"}},
+		}}},
+	}
+	reported := make([]resource.Object, 0)
+	lw := newListerWatcher(client, listerTestKind(), ListWatchOptions{Namespace: "a"}, func(_ context.Context, err error, obj resource.Object) {
+		assert.True(t, resource.IsStrictDecodingError(err))
+		reported = append(reported, obj)
+	})
+	// The list is not failed by an object with unknown fields, which is reported on its own
+	list, err := lw.List(metav1.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, list.(*resource.UntypedList).Items, 2)
+	assert.Equal(t, []resource.Object{objs[1]}, reported)
+}
+
+// strictListTestClient is a namespacesTestClient which returns err from its lists
+type strictListTestClient struct {
+	*namespacesTestClient
+	err error
+}
+
+func (c *strictListTestClient) ListInto(ctx context.Context, namespace string, options resource.ListOptions, into resource.ListObject) error {
+	if err := c.namespacesTestClient.ListInto(ctx, namespace, options, into); err != nil {
+		return err
+	}
+	return c.err
+}
@@ -4,32 +4,58 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/tools/cache"
 
+	"github.com/grafana/grafana-app-sdk/app"
 	"github.com/grafana/grafana-app-sdk/resource"
 )
 
-var _ Informer = &KubernetesBasedInformer{}
+var (
+	_ ScopeAwareInformer = &KubernetesBasedInformer{}
+	_ ObjectGetter       = &KubernetesBasedInformer{}
+	_ Lister             = &KubernetesBasedInformer{}
+)
 
 // KubernetesBasedInformer is a k8s apimachinery-based informer. It wraps a k8s cache.SharedIndexInformer,
 // and works most optimally with a client that has a Watch response that implements KubernetesCompatibleWatch.
 type KubernetesBasedInformer struct {
+	// ErrorReporter, if non-nil, is sent a report of each error the informer encounters while processing an event.
+	// If nil, ErrorHandler is called instead.
+	ErrorReporter app.ErrorReporter
+	// ErrorHandler is called with each error the informer encounters while processing an event,
+	// if ErrorReporter is nil. It defaults to DefaultErrorHandler.
+	//
+	// Deprecated: use ErrorReporter, which receives the kind and object the error was encountered for.
 	ErrorHandler        func(context.Context, error)
 	SharedIndexInformer cache.SharedIndexInformer
 	schema              resource.Kind
+	listWatchOptions    ListWatchOptions
+	namespaces          *namespacesListerWatcher
 	runContext          context.Context
+	restartBackoff      *terminalErrorBackoff
 }
 
 type KubernetesBasedInformerOptions struct {
 	// ListWatchOptions are the options for filtering the watch based on namespace and other compatible filters.
 	ListWatchOptions ListWatchOptions
+	// Namespaces, if non-nil, is the initial set of namespaces to watch, instead of ListWatchOptions.Namespace
+	// (which must then be empty). Each namespace is listed and watched separately, with the label and field filters
+	// of ListWatchOptions, and their objects share the informer's cache. Namespaces can be added and removed while
+	// the informer is running with AddNamespace and RemoveNamespace, so Namespaces can be empty to start with none.
+	// Namespaces cannot be used with cluster-scoped kinds.
+	Namespaces []string
 	// CacheResyncInterval is the interval at which the informer will emit CacheResync events for all resources in the cache.
 	// This is distinct from a full resync, as no information is fetched from the API server.
 	// An empty value will disable cache resyncs.
 	CacheResyncInterval time.Duration
+	// RestartOptions configure the backoff and credential refresh used when the informer restarts its list/watch
+	// after terminal errors from the API server. Empty values use the defaults described in InformerRestartOptions.
+	RestartOptions InformerRestartOptions
 }
 
 // NewKubernetesBasedInformer creates a new KubernetesBasedInformer for the provided kind and options,
@@ -40,17 +66,44 @@ func NewKubernetesBasedInformer(sch resource.Kind, client ListWatchClient, optio
 		return nil, fmt.Errorf("client cannot be nil")
 	}
 
-	return &KubernetesBasedInformer{
-		schema:       sch,
-		ErrorHandler: DefaultErrorHandler,
+	var inf *KubernetesBasedInformer
+	// Objects with unknown fields in a list are reported with the informer's ErrorReporter, which is set after it is created
+	decodingErrorHandler := func(ctx context.Context, err error, obj resource.Object) {
+		inf.errorHandler(ctx, err, obj)
+	}
+	lw := newListerWatcher(client, sch, options.ListWatchOptions, decodingErrorHandler)
+	var namespaces *namespacesListerWatcher
+	if options.Namespaces != nil {
+		if options.ListWatchOptions.Namespace != "" {
+			return nil, fmt.Errorf("please provide either ListWatchOptions.Namespace or Namespaces, not both")
+		}
+		if sch.Scope() == resource.ClusterScope {
+			return nil, fmt.Errorf("%w: kind %s is %s-scoped, but namespaces were provided",
+				ErrNamespaceScopeMismatch, sch.Kind(), resource.ClusterScope)
+		}
+		namespaces = newNamespacesListerWatcher(client, sch, options.ListWatchOptions, options.Namespaces, decodingErrorHandler)
+		lw = namespaces
+	}
+
+	inf = &KubernetesBasedInformer{
+		schema:           sch,
+		listWatchOptions: options.ListWatchOptions,
+		namespaces:       namespaces,
+		ErrorHandler:     DefaultErrorHandler,
 		SharedIndexInformer: cache.NewSharedIndexInformer(
-			NewListerWatcher(client, sch, options.ListWatchOptions),
+			lw,
 			nil,
 			options.CacheResyncInterval,
 			cache.Indexers{
 				cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
 			}),
-	}, nil
+		restartBackoff: newTerminalErrorBackoff(sch.Kind(), options.RestartOptions),
+	}
+	err := inf.SharedIndexInformer.SetWatchErrorHandler(inf.watchErrorHandler)
+	if err != nil {
+		return nil, err
+	}
+	return inf, nil
 }
 
 // AddEventHandler adds a ResourceWatcher as an event handler for watch events from the informer.
@@ -81,21 +134,137 @@ func (k *KubernetesBasedInformer) Run(ctx context.Context) error {
 	return nil
 }
 
+// HasSynced returns true if the informer has synced all events from the initial list request.
+func (k *KubernetesBasedInformer) HasSynced() bool {
+	return k.SharedIndexInformer.HasSynced()
+}
+
 // Schema returns the resource.Schema this informer is set up for
 func (k *KubernetesBasedInformer) Schema() resource.Schema {
 	return k.schema
 }
 
+// ListWatchOptions returns the ListWatchOptions this informer uses for its List and Watch requests.
+// For informers created with KubernetesBasedInformerOptions.Namespaces, the namespace is empty, and the watched
+// namespaces are returned by Namespaces.
+func (k *KubernetesBasedInformer) ListWatchOptions() ListWatchOptions {
+	return k.listWatchOptions
+}
+
+// Namespaces returns the namespaces the informer watches, sorted, if it was created with
+// KubernetesBasedInformerOptions.Namespaces, or nil otherwise
+func (k *KubernetesBasedInformer) Namespaces() []string {
+	if k.namespaces == nil {
+		return nil
+	}
+	return k.namespaces.list()
+}
+
+// AddNamespace starts watching namespace, which can be done while the informer is running.
+// The objects in namespace are sent to the informer's event handlers as add events, and then watched.
+// Adding a namespace which is already watched does nothing. It returns an error if the informer
+// was not created with KubernetesBasedInformerOptions.Namespaces.
+func (k *KubernetesBasedInformer) AddNamespace(namespace string) error {
+	if k.namespaces == nil {
+		return fmt.Errorf("informer was not created with Namespaces")
+	}
+	if namespace == resource.NamespaceAll {
+		return fmt.Errorf("namespace cannot be empty")
+	}
+	k.namespaces.add(namespace)
+	return nil
+}
+
+// RemoveNamespace stops watching namespace, which can be done while the informer is running.
+// The informer then lists the remaining namespaces again, and the objects in namespace are removed from its cache,
+// with delete events sent to its handlers, the same as objects which no longer match a label filter.
+// Until that list completes, the objects in namespace remain in the cache, but no further events are sent for them.
+// Removing a namespace which is not watched does nothing. It returns an error if the informer
+// was not created with KubernetesBasedInformerOptions.Namespaces.
+func (k *KubernetesBasedInformer) RemoveNamespace(namespace string) error {
+	if k.namespaces == nil {
+		return fmt.Errorf("informer was not created with Namespaces")
+	}
+	k.namespaces.remove(namespace)
+	return nil
+}
+
+// Get returns the object with the provided identifier from the informer's cache, or an error wrapping ErrNotInCache
+// if it does not exist in the cache. The namespace of the identifier is ignored for cluster-scoped kinds.
+func (k *KubernetesBasedInformer) Get(_ context.Context, identifier resource.Identifier) (resource.Object, error) {
+	key := identifier.Name
+	if k.schema.Scope() != resource.ClusterScope && identifier.Namespace != "" {
+		key = identifier.Namespace + "/" + identifier.Name
+	}
+	obj, exists, err := k.SharedIndexInformer.GetStore().GetByKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrNotInCache, key)
+	}
+	return k.toResourceObject(obj)
+}
+
+// List returns all objects in the informer's cache in the provided namespace which match all labelFilters.
+// An empty namespace (resource.NamespaceAll) lists objects in all namespaces, and is ignored for cluster-scoped kinds.
+func (k *KubernetesBasedInformer) List(_ context.Context, namespace string, labelFilters ...string) ([]resource.Object, error) {
+	selector := labels.Everything()
+	if len(labelFilters) > 0 {
+		var err error
+		selector, err = labels.Parse(strings.Join(labelFilters, ","))
+		if err != nil {
+			return nil, fmt.Errorf("invalid label filters: %w", err)
+		}
+	}
+	var items []any
+	if namespace != resource.NamespaceAll && k.schema.Scope() != resource.ClusterScope {
+		var err error
+		items, err = k.SharedIndexInformer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		items = k.SharedIndexInformer.GetStore().List()
+	}
+	objects := make([]resource.Object, 0, len(items))
+	for _, item := range items {
+		obj, err := k.toResourceObject(item)
+		if err != nil {
+			return nil, err
+		}
+		if !selector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
 func (k *KubernetesBasedInformer) toResourceObject(obj any) (resource.Object, error) {
 	return toResourceObject(obj, k.schema)
 }
 
-func (k *KubernetesBasedInformer) errorHandler(ctx context.Context, err error) {
-	if k.ErrorHandler != nil {
-		k.ErrorHandler(ctx, err)
+// watchErrorHandler is called by the underlying reflector after its list/watch fails, before it restarts the list/watch.
+// Terminal errors block for the restart backoff here, as the reflector's own backoff does not distinguish them.
+func (k *KubernetesBasedInformer) watchErrorHandler(r *cache.Reflector, err error) {
+	ctx := k.runContext
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !k.restartBackoff.handle(ctx, err) {
+		cache.DefaultWatchErrorHandler(r, err)
 	}
 }
 
+func (k *KubernetesBasedInformer) errorHandler(ctx context.Context, err error, object resource.Object) {
+	report := objectErrorReport(err, app.ErrorClassInformer, "KubernetesBasedInformer", object)
+	if report.Kind == "" {
+		report.Kind = k.schema.Kind()
+	}
+	reportError(ctx, k.ErrorReporter, k.ErrorHandler, report)
+}
+
 func toResourceObject(obj any, kind resource.Kind) (resource.Object, error) {
 	// Nil check
 	if obj == nil {
@@ -107,6 +276,11 @@ func toResourceObject(obj any, kind resource.Kind) (resource.Object, error) {
 		return cast, nil
 	}
 
+	// Deletes which the informer did not observe (such as for objects which are no longer listed) are tombstones
+	if cast, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return toResourceObject(cast.Obj, kind)
+	}
+
 	// Is this an instance of ResourceObjectWrapper? Unwrap it if so
 	if cast, ok := obj.(ResourceObjectWrapper); ok {
 		return cast.ResourceObject(), nil
@@ -0,0 +1,349 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// namespacesListerWatcher is a cache.ListerWatcher which lists and watches each of a set of namespaces separately,
+// and merges them into one list and watch, so that a single informer (and cache) can watch several namespaces.
+// Namespaces can be added and removed while it is being watched. Added namespaces are listed, and their objects
+// are sent as Added events on the current watch, before they are watched. Removing a namespace ends the current watch
+// with an expired error, so that the reflector lists the remaining namespaces again, and its DeltaFIFO deletes the
+// removed namespace's objects from the cache (sending delete events for them) the same way it would for objects deleted
+// while it was not watching.
+type namespacesListerWatcher struct {
+	client  ListWatchClient
+	sch     resource.Schema
+	options ListWatchOptions
+	// decodingErrorHandler is passed the error of each object with unknown fields in a list of a namespace
+	decodingErrorHandler func(context.Context, error, resource.Object)
+	// mux guards namespaces, watch, relist, and the state of each namespace
+	mux        sync.Mutex
+	namespaces map[string]*namespaceState
+	watch      *namespacesWatch
+	// relist is true if a namespace was removed after the last list started, while there was no current watch,
+	// so that the next watch must make the reflector list again
+	relist bool
+}
+
+// namespaceState is the state of the list/watch of a single namespace
+type namespaceState struct {
+	listerWatcher cache.ListerWatcher
+	// listed is true once the namespace has been listed, so its watch can resume from resourceVersion
+	listed bool
+	// resourceVersion is the resource version of the last list or watch event for the namespace
+	resourceVersion string
+}
+
+func newNamespacesListerWatcher(
+	client ListWatchClient, sch resource.Schema, options ListWatchOptions, namespaces []string,
+	decodingErrorHandler func(context.Context, error, resource.Object),
+) *namespacesListerWatcher {
+	lw := &namespacesListerWatcher{
+		client:               client,
+		sch:                  sch,
+		options:              options,
+		namespaces:           make(map[string]*namespaceState),
+		decodingErrorHandler: decodingErrorHandler,
+	}
+	for _, ns := range namespaces {
+		lw.add(ns)
+	}
+	return lw
+}
+
+// add adds namespace to the watched namespaces, and starts watching it if there is a current watch.
+// It returns false if the namespace is already watched.
+func (lw *namespacesListerWatcher) add(namespace string) bool {
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+	if _, ok := lw.namespaces[namespace]; ok {
+		return false
+	}
+	opts := lw.options
+	opts.Namespace = namespace
+	state := &namespaceState{
+		listerWatcher: newListerWatcher(lw.client, lw.sch, opts, lw.decodingErrorHandler),
+	}
+	lw.namespaces[namespace] = state
+	if lw.watch != nil {
+		lw.watch.start(namespace, state)
+	}
+	return true
+}
+
+// remove removes namespace from the watched namespaces, stops its watch, and ends the current watch
+// (or the next one, if there is no current watch) so that the reflector lists the remaining namespaces again.
+// It returns false if the namespace is not watched.
+func (lw *namespacesListerWatcher) remove(namespace string) bool {
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+	if _, ok := lw.namespaces[namespace]; !ok {
+		return false
+	}
+	delete(lw.namespaces, namespace)
+	if lw.watch != nil {
+		lw.watch.stopNamespace(namespace)
+		lw.watch.expire(namespace)
+	} else {
+		lw.relist = true
+	}
+	return true
+}
+
+// list returns the watched namespaces, sorted
+func (lw *namespacesListerWatcher) list() []string {
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+	namespaces := make([]string, 0, len(lw.namespaces))
+	for ns := range lw.namespaces {
+		namespaces = append(namespaces, ns)
+	}
+	slices.Sort(namespaces)
+	return namespaces
+}
+
+// List lists every watched namespace, and returns a list of all of their objects.
+// Lists are not paginated, as continue tokens cannot span namespaces.
+func (lw *namespacesListerWatcher) List(options metav1.ListOptions) (runtime.Object, error) {
+	lw.mux.Lock()
+	states := make(map[string]*namespaceState, len(lw.namespaces))
+	for ns, state := range lw.namespaces {
+		states[ns] = state
+	}
+	lw.relist = false
+	lw.mux.Unlock()
+
+	// A resource version is only meaningful for the namespace it came from, so only "0" (any version) is passed on
+	rv := ""
+	if options.ResourceVersion == "0" {
+		rv = "0"
+	}
+	merged := &resource.UntypedList{}
+	for ns, state := range states {
+		list, err := listNamespace(state, rv)
+		if err != nil {
+			return nil, err
+		}
+		merged.Items = append(merged.Items, list.Items...)
+		if merged.ResourceVersion == "" {
+			merged.ResourceVersion = list.ResourceVersion
+		}
+		lw.setResourceVersion(ns, state, list.ResourceVersion)
+	}
+	return merged, nil
+}
+
+// Watch watches every watched namespace from the resource version of its last list or event,
+// ignoring the resource version in options, and merges their events. The merged watch ends when any
+// namespace's watch ends, so that the reflector starts a new one (or lists again, if the watch ended with an error).
+// If a namespace was removed since the last list started, the merged watch ends with an expired error right away.
+func (lw *namespacesListerWatcher) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+	w := &namespacesWatch{
+		lw:             lw,
+		timeoutSeconds: options.TimeoutSeconds,
+		ch:             make(chan watch.Event),
+		done:           make(chan struct{}),
+		stops:          make(map[string]chan struct{}),
+	}
+	if lw.relist {
+		w.expire("")
+		return w, nil
+	}
+	for ns, state := range lw.namespaces {
+		w.start(ns, state)
+	}
+	lw.watch = w
+	return w, nil
+}
+
+// setResourceVersion records the resource version of a list or event for namespace, if state is still its current state
+func (lw *namespacesListerWatcher) setResourceVersion(namespace string, state *namespaceState, resourceVersion string) {
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+	if lw.namespaces[namespace] != state {
+		return
+	}
+	state.listed = true
+	if resourceVersion != "" {
+		state.resourceVersion = resourceVersion
+	}
+}
+
+func listNamespace(state *namespaceState, resourceVersion string) (*resource.UntypedList, error) {
+	obj, err := state.listerWatcher.List(metav1.ListOptions{ResourceVersion: resourceVersion})
+	if err != nil {
+		return nil, err
+	}
+	list, ok := obj.(*resource.UntypedList)
+	if !ok {
+		return nil, fmt.Errorf("unexpected list type %T", obj)
+	}
+	return list, nil
+}
+
+// namespacesWatch is a watch.Interface which merges the watches of each namespace of a namespacesListerWatcher
+type namespacesWatch struct {
+	lw             *namespacesListerWatcher
+	timeoutSeconds *int64
+	ch             chan watch.Event
+	done           chan struct{}
+	stopOnce       sync.Once
+	wg             sync.WaitGroup
+	// stops holds a channel for each namespace, which is closed to stop watching it. It is guarded by lw.mux.
+	stops map[string]chan struct{}
+}
+
+// start starts watching namespace. The caller must hold lw.mux.
+func (w *namespacesWatch) start(namespace string, state *namespaceState) {
+	select {
+	case <-w.done:
+		return
+	default:
+	}
+	stop := make(chan struct{})
+	w.stops[namespace] = stop
+	w.wg.Add(1)
+	go w.run(namespace, state, stop)
+}
+
+// stopNamespace stops watching namespace. The caller must hold lw.mux.
+func (w *namespacesWatch) stopNamespace(namespace string) {
+	if stop, ok := w.stops[namespace]; ok {
+		close(stop)
+		delete(w.stops, namespace)
+	}
+}
+
+// run lists namespace (if it has not been listed yet), and sends its events until stop or w.done is closed
+func (w *namespacesWatch) run(namespace string, state *namespaceState, stop chan struct{}) {
+	defer w.wg.Done()
+	w.lw.mux.Lock()
+	listed, rv := state.listed, state.resourceVersion
+	w.lw.mux.Unlock()
+	if !listed {
+		list, err := listNamespace(state, "")
+		if err != nil {
+			w.fail(namespace, err, stop)
+			return
+		}
+		for _, item := range list.Items {
+			if !w.send(watch.Event{Type: watch.Added, Object: item}, stop) {
+				return
+			}
+		}
+		rv = list.ResourceVersion
+		w.lw.setResourceVersion(namespace, state, rv)
+	}
+	src, err := state.listerWatcher.Watch(metav1.ListOptions{
+		ResourceVersion:     rv,
+		TimeoutSeconds:      w.timeoutSeconds,
+		AllowWatchBookmarks: true,
+	})
+	if err != nil {
+		w.fail(namespace, err, stop)
+		return
+	}
+	defer src.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-w.done:
+			return
+		case event, ok := <-src.ResultChan():
+			if !ok {
+				// The namespace's watch ended (such as when its timeout elapsed), so end the merged watch,
+				// and let the reflector start a new one
+				w.Stop()
+				return
+			}
+			if cast, ok := event.Object.(interface{ GetResourceVersion() string }); ok && event.Type != watch.Error {
+				w.lw.setResourceVersion(namespace, state, cast.GetResourceVersion())
+			}
+			if !w.send(event, stop) {
+				return
+			}
+		}
+	}
+}
+
+// expire sends an expired error for the removal of namespace in the background, which makes the reflector list
+// the remaining namespaces again. The caller must hold lw.mux.
+func (w *namespacesWatch) expire(namespace string) {
+	msg := "watched namespaces changed"
+	if namespace != "" {
+		msg = fmt.Sprintf("namespace '%s' is no longer watched", namespace)
+	}
+	select {
+	case <-w.done:
+		// The reflector may start a new watch without listing first, so make it expire instead
+		w.lw.relist = true
+		return
+	default:
+	}
+	status := apierrors.NewResourceExpired(msg).ErrStatus
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.send(watch.Event{Type: watch.Error, Object: &status}, nil)
+	}()
+}
+
+// fail sends an expired error for namespace, which makes the reflector list all namespaces again
+func (w *namespacesWatch) fail(namespace string, err error, stop chan struct{}) {
+	status := apierrors.NewResourceExpired(fmt.Sprintf("unable to list and watch namespace '%s': %s", namespace, err.Error())).ErrStatus
+	w.send(watch.Event{Type: watch.Error, Object: &status}, stop)
+}
+
+// send sends event, unless the watch or namespace is stopped first. It returns false if it was stopped.
+// Events for a namespace which has already been stopped are always dropped.
+func (w *namespacesWatch) send(event watch.Event, stop chan struct{}) bool {
+	select {
+	case <-stop:
+		return false
+	default:
+	}
+	select {
+	case w.ch <- event:
+		return true
+	case <-w.done:
+		return false
+	case <-stop:
+		return false
+	}
+}
+
+// Stop stops watching all namespaces, and closes the result channel once all events in flight are dropped
+func (w *namespacesWatch) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+		w.lw.mux.Lock()
+		if w.lw.watch == w {
+			w.lw.watch = nil
+		}
+		w.lw.mux.Unlock()
+		go func() {
+			w.wg.Wait()
+			close(w.ch)
+		}()
+	})
+}
+
+// ResultChan returns the channel of merged events
+func (w *namespacesWatch) ResultChan() <-chan watch.Event {
+	return w.ch
+}
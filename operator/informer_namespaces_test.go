@@ -0,0 +1,241 @@
+package operator
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestNewKubernetesBasedInformer_Namespaces(t *testing.T) {
+	_, err := NewKubernetesBasedInformer(listerTestKind(), &nopListWatchClient{}, KubernetesBasedInformerOptions{
+		ListWatchOptions: ListWatchOptions{Namespace: "foo"},
+		Namespaces:       []string{"bar"},
+	})
+	assert.EqualError(t, err, "please provide either ListWatchOptions.Namespace or Namespaces, not both")
+
+	clusterKind := resource.Kind{
+		Schema: resource.NewSimpleSchema("foo", "v1", &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("Foo"), resource.WithScope(resource.ClusterScope)),
+	}
+	_, err = NewKubernetesBasedInformer(clusterKind, &nopListWatchClient{}, KubernetesBasedInformerOptions{
+		Namespaces: []string{"bar"},
+	})
+	assert.ErrorIs(t, err, ErrNamespaceScopeMismatch)
+
+	inf, err := NewKubernetesBasedInformer(listerTestKind(), &nopListWatchClient{}, KubernetesBasedInformerOptions{})
+	require.Nil(t, err)
+	assert.Nil(t, inf.Namespaces())
+	assert.EqualError(t, inf.AddNamespace("bar"), "informer was not created with Namespaces")
+	assert.EqualError(t, inf.RemoveNamespace("bar"), "informer was not created with Namespaces")
+
+	inf, err = NewKubernetesBasedInformer(listerTestKind(), &nopListWatchClient{}, KubernetesBasedInformerOptions{
+		Namespaces: []string{},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, []string{}, inf.Namespaces())
+	assert.EqualError(t, inf.AddNamespace(""), "namespace cannot be empty")
+}
+
+func TestKubernetesBasedInformer_Namespaces(t *testing.T) {
+	client := newNamespacesTestClient(map[string][]resource.Object{
+		"a": {newListerTestObject("a", "one")},
+		"b": {newListerTestObject("b", "two")},
+		"c": {newListerTestObject("c", "three")},
+	})
+	inf, err := NewKubernetesBasedInformer(listerTestKind(), client, KubernetesBasedInformerOptions{
+		Namespaces: []string{"b", "a"},
+	})
+	require.Nil(t, err)
+	events := make(chan string, 10)
+	require.Nil(t, inf.AddEventHandler(&SimpleWatcher{
+		AddFunc: func(_ context.Context, obj resource.Object) error {
+			events <- "add:" + obj.GetNamespace() + "/" + obj.GetName()
+			return nil
+		},
+		DeleteFunc: func(_ context.Context, obj resource.Object) error {
+			events <- "delete:" + obj.GetNamespace() + "/" + obj.GetName()
+			return nil
+		},
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go inf.Run(ctx)
+	assert.ElementsMatch(t, []string{"add:a/one", "add:b/two"}, receiveEvents(t, events, 2))
+	assert.Equal(t, []string{"a", "b"}, inf.Namespaces())
+
+	// Events from each namespace's watch are sent to the handlers
+	client.watch(t, "a").Add(newListerTestObject("a", "four"))
+	assert.Equal(t, []string{"add:a/four"}, receiveEvents(t, events, 1))
+
+	t.Run("add namespace", func(t *testing.T) {
+		require.Nil(t, inf.AddNamespace("c"))
+		assert.Equal(t, []string{"add:c/three"}, receiveEvents(t, events, 1))
+		client.watch(t, "c").Delete(newListerTestObject("c", "three"))
+		assert.Equal(t, []string{"delete:c/three"}, receiveEvents(t, events, 1))
+		assert.Equal(t, []string{"a", "b", "c"}, inf.Namespaces())
+	})
+
+	t.Run("remove namespace", func(t *testing.T) {
+		watcher := client.watch(t, "a")
+		require.Nil(t, inf.RemoveNamespace("a"))
+		assert.Equal(t, []string{"b", "c"}, inf.Namespaces())
+		assert.Eventually(t, watcher.IsStopped, time.Second, 10*time.Millisecond)
+		// The remaining namespaces are listed again, and the objects in the removed namespace are deleted
+		deletes := make([]string, 0)
+		require.Eventually(t, func() bool {
+			for {
+				select {
+				case e := <-events:
+					if strings.HasPrefix(e, "delete:") {
+						deletes = append(deletes, e)
+					}
+				default:
+					return len(deletes) >= 2
+				}
+			}
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.ElementsMatch(t, []string{"delete:a/one", "delete:a/four"}, deletes)
+		objs, err := inf.List(context.Background(), "a")
+		require.Nil(t, err)
+		assert.Empty(t, objs)
+		objs, err = inf.List(context.Background(), "b")
+		require.Nil(t, err)
+		assert.Len(t, objs, 1)
+		// Removing a namespace which is not watched does nothing
+		assert.Nil(t, inf.RemoveNamespace("a"))
+	})
+}
+
+func TestNamespacesListerWatcher_Remove(t *testing.T) {
+	client := newNamespacesTestClient(map[string][]resource.Object{
+		"a": {newListerTestObject("a", "one")},
+		"b": {newListerTestObject("b", "two")},
+	})
+	lw := newNamespacesListerWatcher(client, listerTestKind(), ListWatchOptions{}, []string{"a", "b"}, nil)
+	list, err := lw.List(metav1.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, list.(*resource.UntypedList).Items, 2)
+
+	// A namespace removed between the list and the watch makes the watch expire right away
+	assert.True(t, lw.remove("a"))
+	assert.False(t, lw.remove("a"))
+	w, err := lw.Watch(metav1.ListOptions{})
+	require.Nil(t, err)
+	select {
+	case e := <-w.ResultChan():
+		require.Equal(t, watch.Error, e.Type)
+		assert.True(t, apierrors.IsResourceExpired(apierrors.FromObject(e.Object)))
+	case <-time.After(time.Second):
+		t.Fatal("expected an expired error")
+	}
+	w.Stop()
+
+	list, err = lw.List(metav1.ListOptions{})
+	require.Nil(t, err)
+	assert.Len(t, list.(*resource.UntypedList).Items, 1)
+	w, err = lw.Watch(metav1.ListOptions{})
+	require.Nil(t, err)
+	defer w.Stop()
+	client.watch(t, "b").Add(newListerTestObject("b", "three"))
+	select {
+	case e := <-w.ResultChan():
+		assert.Equal(t, watch.Added, e.Type)
+	case <-time.After(time.Second):
+		t.Fatal("expected an added event")
+	}
+
+	// Removing a namespace during the watch also makes it expire
+	assert.True(t, lw.remove("b"))
+	select {
+	case e := <-w.ResultChan():
+		assert.Equal(t, watch.Error, e.Type)
+	case <-time.After(time.Second):
+		t.Fatal("expected an expired error")
+	}
+}
+
+func listerTestKind() resource.Kind {
+	return resource.Kind{
+		Schema: resource.NewSimpleSchema("foo", "v1", &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("Foo")),
+	}
+}
+
+func receiveEvents(t *testing.T, events chan string, n int) []string {
+	received := make([]string, 0, n)
+	for len(received) < n {
+		select {
+		case e := <-events:
+			received = append(received, e)
+		case <-time.After(time.Second):
+			t.Fatalf("expected %d events, got %v", n, received)
+		}
+	}
+	return received
+}
+
+// namespacesTestClient is a ListWatchClient which lists fixed objects for each namespace,
+// and returns a watch.FakeWatcher for each watch request
+type namespacesTestClient struct {
+	objects map[string][]resource.Object
+	mux     sync.Mutex
+	watches map[string]*watch.FakeWatcher
+}
+
+func newNamespacesTestClient(objects map[string][]resource.Object) *namespacesTestClient {
+	return &namespacesTestClient{
+		objects: objects,
+		watches: make(map[string]*watch.FakeWatcher),
+	}
+}
+
+func (c *namespacesTestClient) ListInto(_ context.Context, namespace string, _ resource.ListOptions, into resource.ListObject) error {
+	into.SetItems(c.objects[namespace])
+	into.SetResourceVersion("1")
+	return nil
+}
+
+func (c *namespacesTestClient) Watch(_ context.Context, namespace string, _ resource.WatchOptions) (resource.WatchResponse, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	w := watch.NewFake()
+	c.watches[namespace] = w
+	return &fakeWatchResponse{w}, nil
+}
+
+// watch returns the latest watch for namespace, waiting for it to be started
+func (c *namespacesTestClient) watch(t *testing.T, namespace string) *watch.FakeWatcher {
+	var w *watch.FakeWatcher
+	require.Eventually(t, func() bool {
+		c.mux.Lock()
+		defer c.mux.Unlock()
+		w = c.watches[namespace]
+		return w != nil
+	}, time.Second, 10*time.Millisecond)
+	return w
+}
+
+type fakeWatchResponse struct {
+	watcher *watch.FakeWatcher
+}
+
+func (f *fakeWatchResponse) Stop() {
+	f.watcher.Stop()
+}
+
+func (*fakeWatchResponse) WatchEvents() <-chan resource.WatchEvent {
+	return nil
+}
+
+func (f *fakeWatchResponse) KubernetesWatch() watch.Interface {
+	return f.watcher
+}
@@ -0,0 +1,204 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/grafana/grafana-app-sdk/k8s"
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/metrics"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+const (
+	defaultInformerRestartInitialBackoff = time.Second
+	defaultInformerRestartMaxBackoff     = 5 * time.Minute
+	defaultInformerRestartResetInterval  = 2 * time.Minute
+)
+
+// CredentialRefresher refreshes the credentials used to communicate with the API server.
+// It is implemented by k8s.RefreshableCredentials.
+type CredentialRefresher interface {
+	RefreshCredentials(ctx context.Context) error
+}
+
+var _ CredentialRefresher = &k8s.RefreshableCredentials{}
+
+// InformerRestartOptions configure how a KubernetesBasedInformer restarts its list/watch after terminal errors
+// from the API server (401 Unauthorized, 403 Forbidden, and 410 Gone). The first terminal error restarts the list/watch
+// immediately, and each consecutive terminal error after that waits an exponentially-increasing backoff first.
+// A list/watch rejected by an open client circuit breaker (see k8s.ClientConfig.CircuitBreaker) waits until the breaker
+// allows requests again (and at least InitialBackoff) before it is restarted, so that all informers using the API server
+// back off together while it is failing.
+type InformerRestartOptions struct {
+	// InitialBackoff is the backoff after the second consecutive terminal error. Defaults to one second.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum backoff between restarts. Defaults to five minutes.
+	MaxBackoff time.Duration
+	// ResetInterval is the time without a terminal error after which errors are no longer considered consecutive,
+	// and the backoff is reset. Defaults to two minutes.
+	ResetInterval time.Duration
+	// CredentialRefresher, if non-nil, is used to refresh credentials after a 401 or 403 error, before the list/watch is restarted.
+	CredentialRefresher CredentialRefresher
+	// Metrics are the metrics to record restarts to. If nil, restarts are not recorded as metrics.
+	// A single InformerRestartMetrics should be shared by all informers which use the same prometheus registerer.
+	Metrics *InformerRestartMetrics
+}
+
+// InformerRestartMetrics contains the prometheus metrics for informer restarts after terminal errors.
+// It should be created with NewInformerRestartMetrics.
+type InformerRestartMetrics struct {
+	terminalErrors *prometheus.CounterVec
+	backoff        *prometheus.GaugeVec
+}
+
+// NewInformerRestartMetrics creates a new InformerRestartMetrics using the provided metrics.Config
+func NewInformerRestartMetrics(cfg metrics.Config) *InformerRestartMetrics {
+	return &InformerRestartMetrics{
+		terminalErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Subsystem: "informer",
+			Name:      "terminal_watch_errors_total",
+			Help:      "Total number of terminal list/watch errors (401, 403, 410) after which an informer restarted its list/watch.",
+		}, []string{"kind", "status_code"}),
+		backoff: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: cfg.Namespace,
+			Subsystem: "informer",
+			Name:      "restart_backoff_seconds",
+			Help:      "Current backoff (in seconds) before an informer restarts its list/watch after consecutive terminal errors.",
+		}, []string{"kind"}),
+	}
+}
+
+// PrometheusCollectors returns the prometheus metric collectors used by the InformerRestartMetrics
+func (m *InformerRestartMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{m.terminalErrors, m.backoff}
+}
+
+// terminalErrorBackoff tracks consecutive terminal list/watch errors for a single informer,
+// and blocks for the appropriate backoff before the list/watch is restarted.
+type terminalErrorBackoff struct {
+	kind        string
+	options     InformerRestartOptions
+	mux         sync.Mutex
+	consecutive int
+	lastError   time.Time
+	now         func() time.Time
+	sleep       func(context.Context, time.Duration)
+}
+
+func newTerminalErrorBackoff(kind string, options InformerRestartOptions) *terminalErrorBackoff {
+	if options.InitialBackoff <= 0 {
+		options.InitialBackoff = defaultInformerRestartInitialBackoff
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = defaultInformerRestartMaxBackoff
+	}
+	if options.ResetInterval <= 0 {
+		options.ResetInterval = defaultInformerRestartResetInterval
+	}
+	return &terminalErrorBackoff{
+		kind:    kind,
+		options: options,
+		now:     time.Now,
+		sleep: func(ctx context.Context, d time.Duration) {
+			t := time.NewTimer(d)
+			defer t.Stop()
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+			}
+		},
+	}
+}
+
+// handle returns false if err is not a terminal error or a circuit breaker rejection. Otherwise, it refreshes credentials
+// if applicable, blocks for the current backoff, and returns true.
+func (b *terminalErrorBackoff) handle(ctx context.Context, err error) bool {
+	var openErr *k8s.CircuitOpenError
+	if errors.As(err, &openErr) {
+		b.waitForCircuitBreaker(ctx, openErr)
+		return true
+	}
+	code, ok := terminalStatusCode(err)
+	if !ok {
+		return false
+	}
+	// The lock is only held to update the error count, so credentials are refreshed and the backoff is waited without it
+	b.mux.Lock()
+	if b.now().Sub(b.lastError) > b.options.ResetInterval {
+		b.consecutive = 0
+	}
+	b.consecutive++
+	consecutive := b.consecutive
+	backoff := b.currentBackoff()
+	b.mux.Unlock()
+	logging.FromContext(ctx).Warn("informer list/watch failed with a terminal error, restarting",
+		"kind", b.kind, "statusCode", code, "consecutiveErrors", consecutive, "backoff", backoff, "error", err)
+	if b.options.Metrics != nil {
+		b.options.Metrics.terminalErrors.WithLabelValues(b.kind, strconv.Itoa(code)).Inc()
+		b.options.Metrics.backoff.WithLabelValues(b.kind).Set(backoff.Seconds())
+	}
+	if b.options.CredentialRefresher != nil && (code == http.StatusUnauthorized || code == http.StatusForbidden) {
+		if err := b.options.CredentialRefresher.RefreshCredentials(ctx); err != nil {
+			logging.FromContext(ctx).Error("unable to refresh credentials", "kind", b.kind, "error", err)
+		}
+	}
+	if backoff > 0 {
+		b.sleep(ctx, backoff)
+	}
+	// Measure the reset interval from the end of the backoff, so long backoffs don't reset themselves
+	b.mux.Lock()
+	b.lastError = b.now()
+	b.mux.Unlock()
+	return true
+}
+
+// waitForCircuitBreaker blocks until the circuit breaker which rejected the list/watch allows requests again.
+// This doesn't count as a consecutive terminal error, as no request reached the API server.
+func (b *terminalErrorBackoff) waitForCircuitBreaker(ctx context.Context, err *k8s.CircuitOpenError) {
+	backoff := max(err.RetryAfter, b.options.InitialBackoff)
+	logging.FromContext(ctx).Info("informer list/watch rejected by an open circuit breaker, waiting before restarting",
+		"kind", b.kind, "groupVersion", err.GroupVersion.String(), "backoff", backoff)
+	if b.options.Metrics != nil {
+		b.options.Metrics.backoff.WithLabelValues(b.kind).Set(backoff.Seconds())
+	}
+	b.sleep(ctx, backoff)
+}
+
+func (b *terminalErrorBackoff) currentBackoff() time.Duration {
+	if b.consecutive <= 1 {
+		return 0
+	}
+	backoff := b.options.InitialBackoff
+	for i := 2; i < b.consecutive && backoff < b.options.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, b.options.MaxBackoff)
+}
+
+// terminalStatusCode returns the status code of err and true if err is a terminal list/watch error
+func terminalStatusCode(err error) (int, bool) {
+	code := 0
+	var respErr resource.APIServerResponseError
+	var statusErr apierrors.APIStatus
+	switch {
+	case errors.As(err, &respErr):
+		code = respErr.StatusCode()
+	case errors.As(err, &statusErr):
+		code = int(statusErr.Status().Code)
+	}
+	switch code {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusGone:
+		return code, true
+	default:
+		return code, false
+	}
+}
@@ -0,0 +1,123 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana-app-sdk/k8s"
+	"github.com/grafana/grafana-app-sdk/metrics"
+)
+
+func TestTerminalErrorBackoff_handle(t *testing.T) {
+	newBackoff := func(refresher CredentialRefresher) (*terminalErrorBackoff, *[]time.Duration, *time.Time) {
+		m := NewInformerRestartMetrics(metrics.DefaultConfig(""))
+		b := newTerminalErrorBackoff("foo", InformerRestartOptions{
+			InitialBackoff:      time.Second,
+			MaxBackoff:          5 * time.Second,
+			ResetInterval:       time.Minute,
+			CredentialRefresher: refresher,
+			Metrics:             m,
+		})
+		now := time.Now()
+		sleeps := make([]time.Duration, 0)
+		b.now = func() time.Time { return now }
+		b.sleep = func(_ context.Context, d time.Duration) {
+			sleeps = append(sleeps, d)
+			now = now.Add(d)
+		}
+		return b, &sleeps, &now
+	}
+
+	t.Run("non-terminal error", func(t *testing.T) {
+		b, sleeps, _ := newBackoff(nil)
+		assert.False(t, b.handle(context.Background(), errors.New("connection refused")))
+		assert.False(t, b.handle(context.Background(), k8s.NewServerResponseError(errors.New("oops"), http.StatusInternalServerError)))
+		assert.Empty(t, *sleeps)
+	})
+
+	t.Run("exponential backoff", func(t *testing.T) {
+		b, sleeps, _ := newBackoff(nil)
+		for i := 0; i < 6; i++ {
+			assert.True(t, b.handle(context.Background(), apierrors.NewGone("too old resource version")))
+		}
+		// The first error restarts immediately
+		assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}, *sleeps)
+		assert.Equal(t, float64(6), testutil.ToFloat64(b.options.Metrics.terminalErrors.WithLabelValues("foo", "410")))
+		assert.Equal(t, float64(5), testutil.ToFloat64(b.options.Metrics.backoff.WithLabelValues("foo")))
+	})
+
+	t.Run("reset after interval", func(t *testing.T) {
+		b, sleeps, now := newBackoff(nil)
+		err := k8s.NewServerResponseError(errors.New("forbidden"), http.StatusForbidden)
+		assert.True(t, b.handle(context.Background(), err))
+		assert.True(t, b.handle(context.Background(), err))
+		*now = now.Add(2 * time.Minute)
+		assert.True(t, b.handle(context.Background(), err))
+		assert.Equal(t, []time.Duration{time.Second}, *sleeps)
+		assert.Equal(t, float64(0), testutil.ToFloat64(b.options.Metrics.backoff.WithLabelValues("foo")))
+	})
+
+	t.Run("refresh credentials", func(t *testing.T) {
+		refresher := &testCredentialRefresher{}
+		b, _, _ := newBackoff(refresher)
+		assert.True(t, b.handle(context.Background(), apierrors.NewUnauthorized("unauthorized")))
+		assert.True(t, b.handle(context.Background(), apierrors.NewForbidden(schema.GroupResource{}, "foo", errors.New("forbidden"))))
+		assert.Equal(t, 2, refresher.calls)
+		// Credentials are not refreshed for 410 Gone
+		assert.True(t, b.handle(context.Background(), apierrors.NewGone("gone")))
+		assert.Equal(t, 2, refresher.calls)
+	})
+
+	t.Run("lock not held while waiting", func(t *testing.T) {
+		refresher := &testCredentialRefresher{}
+		b, _, _ := newBackoff(refresher)
+		sleep := b.sleep
+		b.sleep = func(ctx context.Context, d time.Duration) {
+			assert.True(t, b.mux.TryLock(), "lock held while sleeping")
+			b.mux.Unlock()
+			sleep(ctx, d)
+		}
+		refresher.onRefresh = func() {
+			assert.True(t, b.mux.TryLock(), "lock held while refreshing credentials")
+			b.mux.Unlock()
+		}
+		assert.True(t, b.handle(context.Background(), apierrors.NewUnauthorized("unauthorized")))
+		assert.True(t, b.handle(context.Background(), apierrors.NewUnauthorized("unauthorized")))
+		assert.Equal(t, 2, refresher.calls)
+	})
+
+	t.Run("circuit breaker open", func(t *testing.T) {
+		b, sleeps, _ := newBackoff(nil)
+		gv := schema.GroupVersion{Group: "foo.grafana.app", Version: "v1"}
+		assert.True(t, b.handle(context.Background(), &k8s.CircuitOpenError{GroupVersion: gv, RetryAfter: 20 * time.Second}))
+		// Half-open rejections wait at least the initial backoff
+		assert.True(t, b.handle(context.Background(), fmt.Errorf("list failed: %w", &k8s.CircuitOpenError{GroupVersion: gv})))
+		assert.Equal(t, []time.Duration{20 * time.Second, time.Second}, *sleeps)
+		assert.Equal(t, float64(1), testutil.ToFloat64(b.options.Metrics.backoff.WithLabelValues("foo")))
+		// Rejections are not consecutive terminal errors
+		assert.True(t, b.handle(context.Background(), apierrors.NewGone("gone")))
+		assert.Len(t, *sleeps, 2)
+	})
+}
+
+type testCredentialRefresher struct {
+	calls     int
+	onRefresh func()
+}
+
+func (r *testCredentialRefresher) RefreshCredentials(context.Context) error {
+	r.calls++
+	if r.onRefresh != nil {
+		r.onRefresh()
+	}
+	return nil
+}
@@ -0,0 +1,379 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+var (
+	_ ScopeAwareInformer = &SharedInformer{}
+	_ Lister             = &SharedInformer{}
+)
+
+// SharedInformerFactory creates informers which share a single list/watch (and cache) with every other informer
+// from the factory for the same kind, namespace, label filters, field selectors, and cache resync interval,
+// so that controllers which watch the same objects don't each open a watch stream to the API server.
+// Runner creates a SharedInformerFactory for the apps it runs, which is passed to them in app.Config
+// (see SharedInformerFactoryFromConfig).
+type SharedInformerFactory struct {
+	mux       sync.Mutex
+	informers map[string]*sharedInformer
+}
+
+// sharedInformer is a KubernetesBasedInformer shared by one or more SharedInformers
+type sharedInformer struct {
+	key      string
+	informer *KubernetesBasedInformer
+	// kind, client, and restartOptions are the ones the informer was created with, which all of its SharedInformers must use
+	kind           resource.Kind
+	client         ListWatchClient
+	restartOptions InformerRestartOptions
+	// done is closed when the informer stops running, after err is set to the error it stopped with (if any)
+	done chan struct{}
+	err  error
+	// running is the number of SharedInformers which are running, and cancel stops the informer once it drops to zero.
+	// Both are guarded by the factory's mux.
+	running int
+	cancel  context.CancelFunc
+	// stopped is true once the informer has been stopped, as a cache.SharedIndexInformer cannot be run again
+	stopped bool
+}
+
+// NewSharedInformerFactory creates a new, empty SharedInformerFactory
+func NewSharedInformerFactory() *SharedInformerFactory {
+	return &SharedInformerFactory{
+		informers: make(map[string]*sharedInformer),
+	}
+}
+
+// SharedInformerFactoryFromConfig returns the SharedInformerFactory provided by Runner in cfg.InformerFactory,
+// or nil if cfg does not have one
+func SharedInformerFactoryFromConfig(cfg app.Config) *SharedInformerFactory {
+	factory, _ := cfg.InformerFactory.(*SharedInformerFactory)
+	return factory
+}
+
+// SharedWatches returns the key of each list/watch currently shared by the factory's informers, sorted.
+// A key is made up of the kind, namespace, label filters, field selectors, and cache resync interval of the list/watch.
+func (f *SharedInformerFactory) SharedWatches() []string {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	keys := make([]string, 0, len(f.informers))
+	for key := range f.informers {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Informer returns a SharedInformer for kind with options, which shares its list/watch with any other SharedInformer
+// from the factory with the same kind (group, version, and kind), namespace, label filters, field selectors,
+// and cache resync interval. The client and RestartOptions of the first informer created for a list/watch are used
+// for all of the informers which share it, so an informer with a different type of client, or different RestartOptions,
+// is rejected. So is an informer for a kind with a different Go type of object or different types of codecs
+// (such as the same kind from another app's generated code), as the objects in the shared cache are of the first kind's type.
+// Clients of the same type are assumed to be equivalent (such as clients for the kind from the same
+// resource.ClientGenerator), and RestartOptions.Metrics are not compared, as each app has its own:
+// restarts are recorded in the metrics of the first informer.
+// Informers which watch a set of namespaces (with options.Namespaces) cannot be shared, as their namespaces can change.
+func (f *SharedInformerFactory) Informer(kind resource.Kind, client ListWatchClient, options KubernetesBasedInformerOptions) (*SharedInformer, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+	if options.Namespaces != nil {
+		return nil, fmt.Errorf("informers with Namespaces cannot be shared")
+	}
+	inf := &SharedInformer{
+		factory: f,
+		key:     sharedInformerKey(kind, options),
+		kind:    kind,
+		client:  client,
+		options: options,
+	}
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	shared, err := f.get(inf)
+	if err != nil {
+		return nil, err
+	}
+	inf.shared = shared
+	return inf, nil
+}
+
+// get returns the current shared informer for inf, creating it if it does not exist. The caller must hold f.mux.
+func (f *SharedInformerFactory) get(inf *SharedInformer) (*sharedInformer, error) {
+	if shared, ok := f.informers[inf.key]; ok {
+		if err := sameKindTypes(shared.kind, inf.kind); err != nil {
+			return nil, err
+		}
+		if reflect.TypeOf(shared.client) != reflect.TypeOf(inf.client) {
+			return nil, fmt.Errorf("client of type %T does not match the client of type %T of the shared informer",
+				inf.client, shared.client)
+		}
+		if !sameRestartOptions(shared.restartOptions, inf.options.RestartOptions) {
+			return nil, errors.New("RestartOptions do not match the RestartOptions of the shared informer")
+		}
+		return shared, nil
+	}
+	informer, err := NewKubernetesBasedInformer(inf.kind, inf.client, inf.options)
+	if err != nil {
+		return nil, err
+	}
+	shared := &sharedInformer{
+		key:            inf.key,
+		informer:       informer,
+		kind:           inf.kind,
+		client:         inf.client,
+		restartOptions: inf.options.RestartOptions,
+		done:           make(chan struct{}),
+	}
+	f.informers[inf.key] = shared
+	return shared, nil
+}
+
+// start starts the shared informer of inf if it is not already running, replacing it first if it has been stopped,
+// and returns it. The shared informer runs until it is stopped by the last SharedInformer running it,
+// with the values (but not the cancellation) of ctx.
+func (f *SharedInformerFactory) start(ctx context.Context, inf *SharedInformer) (*sharedInformer, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	inf.mux.Lock()
+	defer inf.mux.Unlock()
+	if inf.shared.stopped {
+		shared, err := f.get(inf)
+		if err != nil {
+			return nil, err
+		}
+		inf.shared = shared
+	}
+	shared := inf.shared
+	shared.running++
+	if shared.running == 1 {
+		runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		shared.cancel = cancel
+		go f.run(runCtx, shared)
+	}
+	return shared, nil
+}
+
+// run runs shared until ctx is canceled. If it stops before then, it is removed from the factory, so that the next
+// SharedInformer to run replaces it, and the error is returned from the Run of each SharedInformer running it.
+func (f *SharedInformerFactory) run(ctx context.Context, shared *sharedInformer) {
+	err := shared.informer.Run(ctx)
+	if err == nil && ctx.Err() == nil {
+		err = errors.New("shared informer stopped unexpectedly")
+	}
+	if err != nil {
+		f.mux.Lock()
+		shared.stopped = true
+		if f.informers[shared.key] == shared {
+			delete(f.informers, shared.key)
+		}
+		f.mux.Unlock()
+	}
+	shared.err = err
+	close(shared.done)
+}
+
+// stop stops shared once no SharedInformers are running it
+func (f *SharedInformerFactory) stop(shared *sharedInformer) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	shared.running--
+	if shared.running > 0 {
+		return
+	}
+	shared.cancel()
+	shared.stopped = true
+	if f.informers[shared.key] == shared {
+		delete(f.informers, shared.key)
+	}
+}
+
+// sameKindTypes returns an error if b's objects or codecs are of different types than the objects or codecs of a
+func sameKindTypes(a, b resource.Kind) error {
+	if at, bt := reflect.TypeOf(a.ZeroValue()), reflect.TypeOf(b.ZeroValue()); at != bt {
+		return fmt.Errorf("kind objects of type %v do not match the objects of type %v of the shared informer", bt, at)
+	}
+	for _, codecs := range []map[resource.KindEncoding]resource.Codec{a.Codecs, b.Codecs} {
+		for encoding := range codecs {
+			if reflect.TypeOf(a.Codecs[encoding]) != reflect.TypeOf(b.Codecs[encoding]) {
+				return fmt.Errorf("kind codec of type %T for encoding '%s' does not match the codec of type %T of the shared informer",
+					b.Codecs[encoding], encoding, a.Codecs[encoding])
+			}
+		}
+	}
+	return nil
+}
+
+// sameRestartOptions returns true if a and b are the same InformerRestartOptions, other than their Metrics
+func sameRestartOptions(a, b InformerRestartOptions) bool {
+	if a.InitialBackoff != b.InitialBackoff || a.MaxBackoff != b.MaxBackoff || a.ResetInterval != b.ResetInterval {
+		return false
+	}
+	if a.CredentialRefresher == nil || b.CredentialRefresher == nil {
+		return a.CredentialRefresher == nil && b.CredentialRefresher == nil
+	}
+	// CredentialRefreshers of a type which cannot be compared (such as a func type) are never the same
+	return reflect.TypeOf(a.CredentialRefresher) == reflect.TypeOf(b.CredentialRefresher) &&
+		reflect.TypeOf(a.CredentialRefresher).Comparable() && a.CredentialRefresher == b.CredentialRefresher
+}
+
+// sharedInformerKey returns the key of the list/watch for kind and options, which informers with the same key share
+func sharedInformerKey(kind resource.Kind, options KubernetesBasedInformerOptions) string {
+	labelFilters := slices.Clone(options.ListWatchOptions.LabelFilters)
+	slices.Sort(labelFilters)
+	fieldSelectors := slices.Clone(options.ListWatchOptions.FieldSelectors)
+	slices.Sort(fieldSelectors)
+	return strings.Join([]string{
+		kind.GroupVersionKind().String(),
+		options.ListWatchOptions.Namespace,
+		strings.Join(labelFilters, ","),
+		strings.Join(fieldSelectors, ","),
+		options.CacheResyncInterval.String(),
+	}, "|")
+}
+
+// SharedInformer is an Informer which shares its list/watch and cache with other informers from a SharedInformerFactory.
+// The shared list/watch is started when the first of its SharedInformers is run, and stopped when the last one stops.
+// Event handlers added with AddEventHandler only receive events while the SharedInformer is running,
+// starting with an add event for each object already in the cache.
+type SharedInformer struct {
+	// ErrorReporter, if non-nil, is sent a report of each error the informer encounters while processing an event
+	// for its event handlers. If nil, DefaultErrorHandler is called with the error instead.
+	ErrorReporter app.ErrorReporter
+	factory       *SharedInformerFactory
+	key           string
+	kind          resource.Kind
+	client        ListWatchClient
+	options       KubernetesBasedInformerOptions
+	// mux guards shared, handlers, registrations, and runContext
+	mux           sync.Mutex
+	shared        *sharedInformer
+	handlers      []ResourceWatcher
+	registrations []cache.ResourceEventHandlerRegistration
+	runContext    context.Context
+}
+
+// AddEventHandler adds a ResourceWatcher as an event handler for watch events from the informer.
+// If the informer is running, the handler first receives an add event for each object in the cache.
+func (s *SharedInformer) AddEventHandler(handler ResourceWatcher) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.handlers = append(s.handlers, handler)
+	if s.runContext != nil {
+		return s.register(handler)
+	}
+	return nil
+}
+
+// Run starts the shared list/watch if it is not already running, and sends its events to the informer's event handlers
+// until ctx is canceled. The shared list/watch is stopped if no other SharedInformer is running it.
+// If the shared list/watch stops with an error before then, Run returns the error.
+func (s *SharedInformer) Run(ctx context.Context) error {
+	shared, err := s.factory.start(ctx, s)
+	if err != nil {
+		return err
+	}
+	defer s.factory.stop(shared)
+
+	s.mux.Lock()
+	s.runContext = ctx
+	for _, handler := range s.handlers {
+		if err = s.register(handler); err != nil {
+			break
+		}
+	}
+	s.mux.Unlock()
+	if err == nil {
+		select {
+		case <-ctx.Done():
+		case <-shared.done:
+			err = shared.err
+		}
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for _, registration := range s.registrations {
+		err = errors.Join(err, shared.informer.SharedIndexInformer.RemoveEventHandler(registration))
+	}
+	s.registrations = nil
+	s.runContext = nil
+	return err
+}
+
+// register registers handler with the shared informer. The caller must hold s.mux.
+func (s *SharedInformer) register(handler ResourceWatcher) error {
+	informer := s.shared.informer
+	ctx := s.runContext
+	registration, err := informer.SharedIndexInformer.AddEventHandler(toResourceEventHandlerFuncs(handler, informer.toResourceObject, s.errorHandler, func() context.Context {
+		return ctx
+	}))
+	if err != nil {
+		return err
+	}
+	s.registrations = append(s.registrations, registration)
+	return nil
+}
+
+// HasSynced returns true if the shared informer has synced all events from the initial list request,
+// and they have been sent to the informer's event handlers.
+func (s *SharedInformer) HasSynced() bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if !s.shared.informer.HasSynced() {
+		return false
+	}
+	for _, registration := range s.registrations {
+		if !registration.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// Schema returns the resource.Schema this informer is set up for
+func (s *SharedInformer) Schema() resource.Schema {
+	return s.kind
+}
+
+// ListWatchOptions returns the ListWatchOptions the informer was created with
+func (s *SharedInformer) ListWatchOptions() ListWatchOptions {
+	return s.options.ListWatchOptions
+}
+
+// Get returns the object with the provided identifier from the shared cache (see KubernetesBasedInformer.Get)
+func (s *SharedInformer) Get(ctx context.Context, identifier resource.Identifier) (resource.Object, error) {
+	return s.informer().Get(ctx, identifier)
+}
+
+// List returns all objects in the shared cache in the provided namespace which match all labelFilters
+// (see KubernetesBasedInformer.List)
+func (s *SharedInformer) List(ctx context.Context, namespace string, labelFilters ...string) ([]resource.Object, error) {
+	return s.informer().List(ctx, namespace, labelFilters...)
+}
+
+func (s *SharedInformer) informer() *KubernetesBasedInformer {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.shared.informer
+}
+
+func (s *SharedInformer) errorHandler(ctx context.Context, err error, object resource.Object) {
+	report := objectErrorReport(err, app.ErrorClassInformer, "SharedInformer", object)
+	if report.Kind == "" {
+		report.Kind = s.kind.Kind()
+	}
+	reportError(ctx, s.ErrorReporter, DefaultErrorHandler, report)
+}
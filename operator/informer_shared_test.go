@@ -0,0 +1,193 @@
+package operator
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestSharedInformerFactory_Informer(t *testing.T) {
+	factory := NewSharedInformerFactory()
+	_, err := factory.Informer(listerTestKind(), nil, KubernetesBasedInformerOptions{})
+	assert.EqualError(t, err, "client cannot be nil")
+	_, err = factory.Informer(listerTestKind(), &nopListWatchClient{}, KubernetesBasedInformerOptions{Namespaces: []string{"a"}})
+	assert.EqualError(t, err, "informers with Namespaces cannot be shared")
+
+	opts := func(namespace string, labelFilters ...string) KubernetesBasedInformerOptions {
+		return KubernetesBasedInformerOptions{
+			ListWatchOptions: ListWatchOptions{Namespace: namespace, LabelFilters: labelFilters},
+		}
+	}
+	a, err := factory.Informer(listerTestKind(), &nopListWatchClient{}, opts("a", "foo=bar", "bar=foo"))
+	require.Nil(t, err)
+	// The order of label filters doesn't matter
+	b, err := factory.Informer(listerTestKind(), &nopListWatchClient{}, opts("a", "bar=foo", "foo=bar"))
+	require.Nil(t, err)
+	assert.Same(t, a.shared, b.shared)
+	assert.Equal(t, opts("a", "bar=foo", "foo=bar").ListWatchOptions, b.ListWatchOptions())
+
+	c, err := factory.Informer(listerTestKind(), &nopListWatchClient{}, opts("b", "foo=bar", "bar=foo"))
+	require.Nil(t, err)
+	assert.NotSame(t, a.shared, c.shared)
+	d, err := factory.Informer(listerTestKind(), &nopListWatchClient{}, opts("a", "foo=bar"))
+	require.Nil(t, err)
+	assert.NotSame(t, a.shared, d.shared)
+	e, err := factory.Informer(listerTestKind(), &nopListWatchClient{}, KubernetesBasedInformerOptions{
+		ListWatchOptions:    opts("a", "foo=bar", "bar=foo").ListWatchOptions,
+		CacheResyncInterval: time.Minute,
+	})
+	require.Nil(t, err)
+	assert.NotSame(t, a.shared, e.shared)
+	otherKind := resource.Kind{
+		Schema: resource.NewSimpleSchema("foo", "v2", &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("Foo")),
+	}
+	f, err := factory.Informer(otherKind, &nopListWatchClient{}, opts("a", "foo=bar", "bar=foo"))
+	require.Nil(t, err)
+	assert.NotSame(t, a.shared, f.shared)
+	assert.Len(t, factory.SharedWatches(), 5)
+
+	// Informers which would use a different client or RestartOptions than the shared informer are rejected
+	_, err = factory.Informer(listerTestKind(), &countingListWatchClient{}, opts("a", "foo=bar", "bar=foo"))
+	assert.ErrorContains(t, err, "does not match the client")
+	_, err = factory.Informer(listerTestKind(), &nopListWatchClient{}, KubernetesBasedInformerOptions{
+		ListWatchOptions: opts("a", "foo=bar", "bar=foo").ListWatchOptions,
+		RestartOptions:   InformerRestartOptions{MaxBackoff: time.Minute},
+	})
+	assert.EqualError(t, err, "RestartOptions do not match the RestartOptions of the shared informer")
+
+	// So are informers for the same kind with a different object type or codecs, such as from another app
+	typedKind := resource.Kind{
+		Schema: resource.NewSimpleSchema("foo", "v1", &resource.TypedSpecObject[string]{}, &resource.TypedList[*resource.TypedSpecObject[string]]{}, resource.WithKind("Foo")),
+	}
+	_, err = factory.Informer(typedKind, &nopListWatchClient{}, opts("a", "foo=bar", "bar=foo"))
+	assert.EqualError(t, err, "kind objects of type *resource.TypedSpecObject[string] do not match the objects of type *resource.UntypedObject of the shared informer")
+	codecKind := listerTestKind()
+	codecKind.Codecs = map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()}
+	_, err = factory.Informer(codecKind, &nopListWatchClient{}, opts("a", "foo=bar", "bar=foo"))
+	assert.EqualError(t, err, "kind codec of type *resource.JSONCodec for encoding 'application/json' does not match the codec of type <nil> of the shared informer")
+}
+
+func TestSharedInformer_Run(t *testing.T) {
+	client := &countingListWatchClient{
+		namespacesTestClient: newNamespacesTestClient(map[string][]resource.Object{
+			"a": {newListerTestObject("a", "one")},
+		}),
+	}
+	factory := NewSharedInformerFactory()
+	newInformer := func(name string, events chan string) *SharedInformer {
+		inf, err := factory.Informer(listerTestKind(), client, KubernetesBasedInformerOptions{
+			ListWatchOptions: ListWatchOptions{Namespace: "a"},
+		})
+		require.Nil(t, err)
+		require.Nil(t, inf.AddEventHandler(&SimpleWatcher{
+			AddFunc: func(_ context.Context, obj resource.Object) error {
+				events <- name + ":add:" + obj.GetName()
+				return nil
+			},
+		}))
+		return inf
+	}
+	firstEvents := make(chan string, 10)
+	secondEvents := make(chan string, 10)
+	first := newInformer("first", firstEvents)
+	second := newInformer("second", secondEvents)
+
+	firstCtx, firstCancel := context.WithCancel(context.Background())
+	defer firstCancel()
+	firstDone := make(chan struct{})
+	go func() {
+		assert.Nil(t, first.Run(firstCtx))
+		close(firstDone)
+	}()
+	assert.Equal(t, []string{"first:add:one"}, receiveEvents(t, firstEvents, 1))
+	assert.Eventually(t, first.HasSynced, time.Second, 10*time.Millisecond)
+	watcher := client.watch(t, "a")
+
+	// The second informer shares the running list/watch, and receives the objects already in the cache
+	secondCtx, secondCancel := context.WithCancel(context.Background())
+	defer secondCancel()
+	secondDone := make(chan struct{})
+	go func() {
+		assert.Nil(t, second.Run(secondCtx))
+		close(secondDone)
+	}()
+	assert.Equal(t, []string{"second:add:one"}, receiveEvents(t, secondEvents, 1))
+	watcher.Add(newListerTestObject("a", "two"))
+	assert.Equal(t, []string{"first:add:two"}, receiveEvents(t, firstEvents, 1))
+	assert.Equal(t, []string{"second:add:two"}, receiveEvents(t, secondEvents, 1))
+	assert.Equal(t, int32(1), client.watches.Load())
+	objs, err := second.List(context.Background(), "a")
+	require.Nil(t, err)
+	assert.Len(t, objs, 2)
+
+	// Stopping the first informer stops its events, but not the shared watch
+	firstCancel()
+	<-firstDone
+	watcher.Add(newListerTestObject("a", "three"))
+	assert.Equal(t, []string{"second:add:three"}, receiveEvents(t, secondEvents, 1))
+	select {
+	case e := <-firstEvents:
+		t.Fatalf("unexpected event %s", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.False(t, watcher.IsStopped())
+
+	// Stopping the last informer stops the shared watch
+	secondCancel()
+	<-secondDone
+	assert.Eventually(t, watcher.IsStopped, time.Second, 10*time.Millisecond)
+
+	// Running an informer again starts a new list/watch
+	restartCtx, restartCancel := context.WithCancel(context.Background())
+	defer restartCancel()
+	go first.Run(restartCtx) //nolint:errcheck
+	assert.Equal(t, []string{"first:add:one"}, receiveEvents(t, firstEvents, 1))
+	assert.Eventually(t, func() bool {
+		return client.watches.Load() == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSharedInformer_Run_SharedInformerStops(t *testing.T) {
+	factory := NewSharedInformerFactory()
+	client := newNamespacesTestClient(map[string][]resource.Object{"a": {newListerTestObject("a", "one")}})
+	inf, err := factory.Informer(listerTestKind(), client, KubernetesBasedInformerOptions{
+		ListWatchOptions: ListWatchOptions{Namespace: "a"},
+	})
+	require.Nil(t, err)
+	// A cache.SharedIndexInformer can only be run once, so running the shared informer elsewhere first
+	// makes it stop as soon as the factory runs it
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go inf.shared.informer.SharedIndexInformer.Run(ctx.Done())
+	assert.Eventually(t, inf.shared.informer.SharedIndexInformer.HasSynced, time.Second, 10*time.Millisecond)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- inf.Run(ctx)
+	}()
+	select {
+	case err = <-errs:
+		assert.EqualError(t, err, "shared informer stopped unexpectedly")
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Run to return")
+	}
+	// The stopped shared informer is replaced by the next informer from the factory
+	assert.Empty(t, factory.SharedWatches())
+}
+
+// countingListWatchClient is a namespacesTestClient which counts its watch requests
+type countingListWatchClient struct {
+	*namespacesTestClient
+	watches atomic.Int32
+}
+
+func (c *countingListWatchClient) Watch(ctx context.Context, namespace string, options resource.WatchOptions) (resource.WatchResponse, error) {
+	c.watches.Add(1)
+	return c.namespacesTestClient.Watch(ctx, namespace, options)
+}
@@ -0,0 +1,280 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+var (
+	_ Informer = &StaticInformer{}
+	_ Lister   = &StaticInformer{}
+)
+
+// StaticInformer is an in-memory Informer for tests, which is seeded with objects and has its events fired programmatically
+// with FireAdd, FireUpdate, and FireDelete, instead of watching an API server.
+// It can be added to an InformerController (or used by any other consumer of an Informer) in place of a KubernetesBasedInformer,
+// to test watchers and reconcilers without a kind-specific mock informer.
+//
+// When the StaticInformer is run, each event handler receives an add event for each object in its cache,
+// like the initial list of a KubernetesBasedInformer, and HasSynced returns true once they have been sent.
+// Fired events are sent to the event handlers whether or not the informer is running.
+type StaticInformer struct {
+	// mux guards objects, handlers, running, and synced. It is not held while calling handlers.
+	mux      sync.RWMutex
+	objects  map[string]resource.Object
+	handlers []ResourceWatcher
+	running  bool
+	synced   bool
+}
+
+// NewStaticInformer creates a new StaticInformer with objects in its cache
+func NewStaticInformer(objects ...resource.Object) *StaticInformer {
+	s := &StaticInformer{
+		objects: make(map[string]resource.Object),
+	}
+	for _, obj := range objects {
+		s.objects[staticInformerKey(obj.GetNamespace(), obj.GetName())] = obj
+	}
+	return s
+}
+
+// NewStaticInformerFromFS creates a new StaticInformer with the objects of kind read from the JSON files (with a .json extension)
+// and YAML files (with a .yaml or .yml extension) in dir of fsys, and of its subdirectories, such as test fixtures
+// in an embed.FS or os.DirFS. Each JSON file contains one object, and each YAML file contains one or more objects,
+// as separate documents. YAML objects are converted to JSON and read with the kind's JSON codec.
+func NewStaticInformerFromFS(kind resource.Kind, fsys fs.FS, dir string) (*StaticInformer, error) {
+	objects := make([]resource.Object, 0)
+	err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := path.Ext(p)
+		if d.IsDir() || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+			return nil
+		}
+		contents, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		docs := [][]byte{contents}
+		if ext != ".json" {
+			if docs, err = yamlDocumentsToJSON(contents); err != nil {
+				return fmt.Errorf("unable to parse %s: %w", p, err)
+			}
+		}
+		for _, doc := range docs {
+			obj, err := kind.Read(bytes.NewReader(doc), resource.KindEncodingJSON)
+			if err != nil {
+				return fmt.Errorf("unable to read %s: %w", p, err)
+			}
+			objects = append(objects, obj)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewStaticInformer(objects...), nil
+}
+
+// yamlDocumentsToJSON returns the JSON encoding of each non-empty document in contents
+func yamlDocumentsToJSON(contents []byte) ([][]byte, error) {
+	docs := make([][]byte, 0)
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	for {
+		var doc map[string]any
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			continue
+		}
+		encoded, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, encoded)
+	}
+}
+
+// AddEventHandler adds a ResourceWatcher as an event handler for events from the informer.
+// If the informer is running, the handler first receives an add event for each object in the cache.
+func (s *StaticInformer) AddEventHandler(handler ResourceWatcher) error {
+	s.mux.Lock()
+	s.handlers = append(s.handlers, handler)
+	running := s.running
+	objects := s.list()
+	s.mux.Unlock()
+	if !running {
+		return nil
+	}
+	for _, obj := range objects {
+		// Errors from the handler for the initial objects are ignored, as they would be by a KubernetesBasedInformer
+		//nolint:errcheck
+		handler.Add(context.Background(), obj)
+	}
+	return nil
+}
+
+// Run sends an add event for each object in the cache to the informer's event handlers, then blocks until ctx is canceled
+func (s *StaticInformer) Run(ctx context.Context) error {
+	s.mux.Lock()
+	s.running = true
+	handlers := append([]ResourceWatcher{}, s.handlers...)
+	objects := s.list()
+	s.mux.Unlock()
+	for _, obj := range objects {
+		for _, handler := range handlers {
+			//nolint:errcheck
+			handler.Add(ctx, obj)
+		}
+	}
+	s.mux.Lock()
+	s.synced = true
+	s.mux.Unlock()
+
+	<-ctx.Done()
+	s.mux.Lock()
+	s.running = false
+	s.mux.Unlock()
+	return nil
+}
+
+// HasSynced returns true once the informer has run and sent the objects it was seeded with to its event handlers
+func (s *StaticInformer) HasSynced() bool {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	return s.synced
+}
+
+// FireAdd adds obj to the cache, replacing any object with the same namespace and name,
+// and sends an add event for it to each event handler. It returns the errors returned by the handlers, joined.
+func (s *StaticInformer) FireAdd(ctx context.Context, obj resource.Object) error {
+	s.mux.Lock()
+	s.objects[staticInformerKey(obj.GetNamespace(), obj.GetName())] = obj
+	handlers := append([]ResourceWatcher{}, s.handlers...)
+	s.mux.Unlock()
+	var err error
+	for _, handler := range handlers {
+		err = errors.Join(err, handler.Add(ctx, obj))
+	}
+	return err
+}
+
+// FireUpdate replaces the object in the cache with the namespace and name of obj, and sends an update event
+// from the cached object to obj to each event handler. It returns the errors returned by the handlers, joined,
+// or an error wrapping ErrNotInCache if the cache has no object with the namespace and name of obj.
+func (s *StaticInformer) FireUpdate(ctx context.Context, obj resource.Object) error {
+	key := staticInformerKey(obj.GetNamespace(), obj.GetName())
+	s.mux.Lock()
+	old, ok := s.objects[key]
+	if !ok {
+		s.mux.Unlock()
+		return fmt.Errorf("%w: %s", ErrNotInCache, key)
+	}
+	s.objects[key] = obj
+	handlers := append([]ResourceWatcher{}, s.handlers...)
+	s.mux.Unlock()
+	var err error
+	for _, handler := range handlers {
+		err = errors.Join(err, handler.Update(ctx, old, obj))
+	}
+	return err
+}
+
+// FireDelete removes the object with identifier from the cache, and sends a delete event for it to each event handler.
+// It returns the errors returned by the handlers, joined, or an error wrapping ErrNotInCache if the cache has no object
+// with identifier.
+func (s *StaticInformer) FireDelete(ctx context.Context, identifier resource.Identifier) error {
+	key := staticInformerKey(identifier.Namespace, identifier.Name)
+	s.mux.Lock()
+	obj, ok := s.objects[key]
+	if !ok {
+		s.mux.Unlock()
+		return fmt.Errorf("%w: %s", ErrNotInCache, key)
+	}
+	delete(s.objects, key)
+	handlers := append([]ResourceWatcher{}, s.handlers...)
+	s.mux.Unlock()
+	var err error
+	for _, handler := range handlers {
+		err = errors.Join(err, handler.Delete(ctx, obj))
+	}
+	return err
+}
+
+// Get returns the object with identifier from the cache, or an error wrapping ErrNotInCache if it does not exist
+func (s *StaticInformer) Get(_ context.Context, identifier resource.Identifier) (resource.Object, error) {
+	key := staticInformerKey(identifier.Namespace, identifier.Name)
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotInCache, key)
+	}
+	return obj, nil
+}
+
+// List returns all objects in the cache in the provided namespace which match all labelFilters, sorted by namespace and name.
+// An empty namespace (resource.NamespaceAll) lists objects in all namespaces.
+func (s *StaticInformer) List(_ context.Context, namespace string, labelFilters ...string) ([]resource.Object, error) {
+	selector := labels.Everything()
+	if len(labelFilters) > 0 {
+		var err error
+		selector, err = labels.Parse(strings.Join(labelFilters, ","))
+		if err != nil {
+			return nil, fmt.Errorf("invalid label filters: %w", err)
+		}
+	}
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	objects := make([]resource.Object, 0)
+	for _, obj := range s.list() {
+		if namespace != resource.NamespaceAll && obj.GetNamespace() != namespace {
+			continue
+		}
+		if selector.Matches(labels.Set(obj.GetLabels())) {
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
+
+// list returns the objects in the cache, sorted by namespace and name. The caller must hold s.mux.
+func (s *StaticInformer) list() []resource.Object {
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	objects := make([]resource.Object, 0, len(keys))
+	for _, key := range keys {
+		objects = append(objects, s.objects[key])
+	}
+	return objects
+}
+
+func staticInformerKey(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
@@ -0,0 +1,129 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestStaticInformer(t *testing.T) {
+	inf := NewStaticInformer(newListerTestObject("a", "one", "foo", "bar"), newListerTestObject("b", "two"))
+	events := make(chan string, 10)
+	handlerErr := errors.New("I AM ERROR")
+	require.Nil(t, inf.AddEventHandler(&SimpleWatcher{
+		AddFunc: func(_ context.Context, obj resource.Object) error {
+			events <- "add:" + obj.GetName()
+			return nil
+		},
+		UpdateFunc: func(_ context.Context, src, tgt resource.Object) error {
+			events <- "update:" + src.GetLabels()["foo"] + ":" + tgt.GetLabels()["foo"]
+			return handlerErr
+		},
+		DeleteFunc: func(_ context.Context, obj resource.Object) error {
+			events <- "delete:" + obj.GetName()
+			return nil
+		},
+	}))
+	assert.False(t, inf.HasSynced())
+
+	// Running the informer sends the seeded objects to its handlers
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go inf.Run(ctx) //nolint:errcheck
+	assert.Equal(t, []string{"add:one", "add:two"}, receiveEvents(t, events, 2))
+	assert.Eventually(t, inf.HasSynced, time.Second, 10*time.Millisecond)
+
+	require.Nil(t, inf.FireAdd(ctx, newListerTestObject("a", "three")))
+	assert.Equal(t, []string{"add:three"}, receiveEvents(t, events, 1))
+	// Handler errors are returned to the caller
+	assert.ErrorIs(t, inf.FireUpdate(ctx, newListerTestObject("a", "one", "foo", "baz")), handlerErr)
+	assert.Equal(t, []string{"update:bar:baz"}, receiveEvents(t, events, 1))
+	require.Nil(t, inf.FireDelete(ctx, resource.Identifier{Namespace: "b", Name: "two"}))
+	assert.Equal(t, []string{"delete:two"}, receiveEvents(t, events, 1))
+
+	// Updates and deletes of objects which aren't in the cache aren't sent
+	assert.ErrorIs(t, inf.FireUpdate(ctx, newListerTestObject("b", "two")), ErrNotInCache)
+	assert.ErrorIs(t, inf.FireDelete(ctx, resource.Identifier{Namespace: "b", Name: "two"}), ErrNotInCache)
+
+	// The cache reflects the fired events
+	obj, err := inf.Get(ctx, resource.Identifier{Namespace: "a", Name: "one"})
+	require.Nil(t, err)
+	assert.Equal(t, "baz", obj.GetLabels()["foo"])
+	_, err = inf.Get(ctx, resource.Identifier{Namespace: "b", Name: "two"})
+	assert.ErrorIs(t, err, ErrNotInCache)
+	objs, err := inf.List(ctx, resource.NamespaceAll)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"one", "three"}, listedNames(objs))
+	objs, err = inf.List(ctx, "a", "foo=baz")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"one"}, listedNames(objs))
+
+	// Handlers added while the informer is running receive the objects in the cache
+	late := make(chan string, 10)
+	require.Nil(t, inf.AddEventHandler(&SimpleWatcher{
+		AddFunc: func(_ context.Context, obj resource.Object) error {
+			late <- "add:" + obj.GetName()
+			return nil
+		},
+	}))
+	assert.Equal(t, []string{"add:one", "add:three"}, receiveEvents(t, late, 2))
+}
+
+func TestNewStaticInformerFromFS(t *testing.T) {
+	kind := listerTestKind()
+	kind.Codecs = map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()}
+	fsys := fstest.MapFS{
+		"fixtures/one.json": &fstest.MapFile{Data: []byte(`{"apiVersion":"foo/v1","kind":"Foo","metadata":{"name":"one","namespace":"a"},"spec":{}}`)},
+		"fixtures/more/objects.yaml": &fstest.MapFile{Data: []byte(`apiVersion: foo/v1
+kind: Foo
+metadata:
+  name: two
+  namespace: a
+spec: {}
+---
+apiVersion: foo/v1
+kind: Foo
+metadata:
+  name: three
+  namespace: b
+spec: {}
+`)},
+		"fixtures/README.md": &fstest.MapFile{Data: []byte(`not an object`)},
+	}
+	inf, err := NewStaticInformerFromFS(kind, fsys, "fixtures")
+	require.Nil(t, err)
+	objs, err := inf.List(context.Background(), resource.NamespaceAll)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"one", "two", "three"}, listedNames(objs))
+
+	fsys["fixtures/bad.yml"] = &fstest.MapFile{Data: []byte(`metadata: [`)}
+	_, err = NewStaticInformerFromFS(kind, fsys, "fixtures")
+	assert.ErrorContains(t, err, "unable to parse fixtures/bad.yml")
+}
+
+func TestStaticInformer_InformerController(t *testing.T) {
+	inf := NewStaticInformer(newListerTestObject("a", "one"))
+	c := NewInformerController(InformerControllerConfig{})
+	reconciled := make(chan string, 10)
+	require.Nil(t, c.AddReconciler(&SimpleReconciler{
+		ReconcileFunc: func(_ context.Context, request ReconcileRequest) (ReconcileResult, error) {
+			reconciled <- string(ResourceActionFromReconcileAction(request.Action)) + ":" + request.Object.GetName()
+			return ReconcileResult{}, nil
+		},
+	}, "foo"))
+	require.Nil(t, c.AddInformer(inf, "foo"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx) //nolint:errcheck
+	assert.Equal(t, []string{string(ResourceActionCreate) + ":one"}, receiveEvents(t, reconciled, 1))
+	require.Nil(t, inf.FireUpdate(ctx, newListerTestObject("a", "one", "foo", "bar")))
+	assert.Equal(t, []string{string(ResourceActionUpdate) + ":one"}, receiveEvents(t, reconciled, 1))
+}
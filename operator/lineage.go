@@ -0,0 +1,47 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// LineageReconciler wraps a Reconciler, and adds a resource.Lineage for the object in each ReconcileRequest
+// to the context passed to the wrapped Reconciler (see resource.WithLineage).
+// Objects the wrapped Reconciler creates or updates using that context with a resource.Store, resource.TypedStore,
+// or a client from resource.NewLineageClient are annotated with the reconciled object as their source,
+// and Controller as the identity of what created them. Lineage chains can be followed with resource.TraceLineage.
+// It should be instantiated with NewLineageReconciler.
+type LineageReconciler struct {
+	Reconciler Reconciler
+	// Controller is the identity recorded in resource.Lineage.Controller, such as "<app name>/<controller name>"
+	Controller string
+}
+
+// NewLineageReconciler returns a new LineageReconciler which wraps reconciler, recording controller as its identity
+func NewLineageReconciler(reconciler Reconciler, controller string) (*LineageReconciler, error) {
+	if reconciler == nil {
+		return nil, fmt.Errorf("reconciler cannot be nil")
+	}
+	return &LineageReconciler{
+		Reconciler: reconciler,
+		Controller: controller,
+	}, nil
+}
+
+// Reconcile adds the lineage of the request's object to ctx, and then calls the wrapped Reconciler
+func (l *LineageReconciler) Reconcile(ctx context.Context, req ReconcileRequest) (ReconcileResult, error) {
+	if req.Object != nil {
+		ctx = resource.WithLineage(ctx, resource.NewLineage(req.Object, l.Controller))
+	}
+	return l.Reconciler.Reconcile(ctx, req)
+}
+
+// Wrap replaces the wrapped Reconciler with reconciler
+func (l *LineageReconciler) Wrap(reconciler Reconciler) {
+	l.Reconciler = reconciler
+}
+
+// Compile-time interface compliance check
+var _ Reconciler = &LineageReconciler{}
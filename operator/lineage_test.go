@@ -0,0 +1,41 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestLineageReconciler_Reconcile(t *testing.T) {
+	_, err := NewLineageReconciler(nil, "app/foo-reconciler")
+	assert.NotNil(t, err)
+
+	var lineage resource.Lineage
+	var ok bool
+	r, err := NewLineageReconciler(&SimpleReconciler{
+		ReconcileFunc: func(ctx context.Context, _ ReconcileRequest) (ReconcileResult, error) {
+			lineage, ok = resource.LineageFromContext(ctx)
+			return ReconcileResult{}, nil
+		},
+	}, "app/foo-reconciler")
+	require.Nil(t, err)
+
+	t.Run("no object", func(t *testing.T) {
+		_, err := r.Reconcile(context.Background(), ReconcileRequest{})
+		require.Nil(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("object", func(t *testing.T) {
+		obj := newOwnerTestObject("a", "uid-a")
+		obj.SetResourceVersion("3")
+		_, err := r.Reconcile(context.Background(), ReconcileRequest{Object: obj})
+		require.Nil(t, err)
+		require.True(t, ok)
+		assert.Equal(t, resource.NewLineage(obj, "app/foo-reconciler"), lineage)
+	})
+}
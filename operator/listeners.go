@@ -0,0 +1,68 @@
+package operator
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// systemdListenFDsStart is the first file descriptor passed by systemd socket activation
+	systemdListenFDsStart = 3
+	// systemdUnknownFDName is the name systemd gives sockets without a FileDescriptorName
+	systemdUnknownFDName = "unknown"
+)
+
+// SystemdListeners returns the listeners for the sockets passed to the process by systemd socket activation,
+// keyed by the FileDescriptorName of each socket (see systemd.socket(5)), which can be used as the
+// RunnerWebhookConfig.Listener or RunnerMetricsConfig.Listener.
+// Sockets without a FileDescriptorName are keyed "unknown", so if more than one socket is passed,
+// each must be named. If the process was not socket-activated, SystemdListeners returns an empty map.
+// The LISTEN_PID, LISTEN_FDS, and LISTEN_FDNAMES environment variables are unset, so that they
+// are not inherited by child processes.
+func SystemdListeners() (map[string]net.Listener, error) {
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+	listeners := make(map[string]net.Listener)
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		// The sockets (if any) were not passed to this process
+		return listeners, nil
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return listeners, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	for i := 0; i < count; i++ {
+		name := systemdUnknownFDName
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		if _, ok := listeners[name]; ok {
+			closeListeners(listeners)
+			return nil, fmt.Errorf("more than one socket named '%s' was passed, set a distinct FileDescriptorName for each socket", name)
+		}
+		file := os.NewFile(uintptr(systemdListenFDsStart+i), name)
+		// FileListener duplicates the file descriptor, so the original can be closed
+		listener, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			closeListeners(listeners)
+			return nil, fmt.Errorf("unable to listen on socket '%s': %w", name, err)
+		}
+		listeners[name] = listener
+	}
+	return listeners, nil
+}
+
+func closeListeners(listeners map[string]net.Listener) {
+	for _, l := range listeners {
+		l.Close()
+	}
+}
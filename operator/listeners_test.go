@@ -0,0 +1,40 @@
+package operator
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemdListeners(t *testing.T) {
+	t.Run("not socket-activated", func(t *testing.T) {
+		t.Setenv("LISTEN_PID", "")
+		t.Setenv("LISTEN_FDS", "")
+		listeners, err := SystemdListeners()
+		require.Nil(t, err)
+		assert.Empty(t, listeners)
+	})
+
+	t.Run("sockets passed to another process", func(t *testing.T) {
+		t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+		t.Setenv("LISTEN_FDS", "1")
+		t.Setenv("LISTEN_FDNAMES", "metrics")
+		listeners, err := SystemdListeners()
+		require.Nil(t, err)
+		assert.Empty(t, listeners)
+		// The environment is unset either way
+		_, ok := os.LookupEnv("LISTEN_FDS")
+		assert.False(t, ok)
+	})
+
+	t.Run("no sockets", func(t *testing.T) {
+		t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+		t.Setenv("LISTEN_FDS", "0")
+		listeners, err := SystemdListeners()
+		require.Nil(t, err)
+		assert.Empty(t, listeners)
+	})
+}
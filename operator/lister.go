@@ -0,0 +1,60 @@
+package operator
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// Lister reads objects of a kind from a local cache, such as the cache of a KubernetesBasedInformer.
+// Reads from a Lister do not make requests to the API server, so results may lag slightly behind the API server's state.
+// Objects returned by a Lister are shared with the cache, and must not be modified; use Copy() to get a mutable object.
+type Lister interface {
+	ObjectGetter
+	// List returns all objects in the cache in the provided namespace which match all of the provided label filters
+	// (in kubernetes label selector syntax). Use resource.NamespaceAll to list objects in all namespaces.
+	List(ctx context.Context, namespace string, labelFilters ...string) ([]resource.Object, error)
+}
+
+// multiLister is a Lister which reads from several Listers, such as those for informers partitioned by namespace or labels.
+type multiLister []Lister
+
+func (m multiLister) Get(ctx context.Context, identifier resource.Identifier) (resource.Object, error) {
+	var err error
+	for _, lister := range m {
+		var obj resource.Object
+		obj, err = lister.Get(ctx, identifier)
+		if err == nil {
+			return obj, nil
+		}
+		if !errors.Is(err, ErrNotInCache) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+func (m multiLister) List(ctx context.Context, namespace string, labelFilters ...string) ([]resource.Object, error) {
+	if len(m) == 1 {
+		return m[0].List(ctx, namespace, labelFilters...)
+	}
+	objects := make([]resource.Object, 0)
+	// Partitions of the same kind may overlap, so de-duplicate results
+	seen := make(map[resource.Identifier]struct{})
+	for _, lister := range m {
+		list, err := lister.List(ctx, namespace, labelFilters...)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range list {
+			id := obj.GetStaticMetadata().Identifier()
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
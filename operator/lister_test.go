@@ -0,0 +1,84 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestKubernetesBasedInformer_List(t *testing.T) {
+	inf := newTestListerInformer(t, newListerTestObject("bar", "a", "env", "dev"), newListerTestObject("bar", "b", "env", "prod"), newListerTestObject("baz", "c", "env", "dev"))
+
+	t.Run("namespace", func(t *testing.T) {
+		objs, err := inf.List(context.Background(), "bar")
+		require.Nil(t, err)
+		assert.ElementsMatch(t, []string{"a", "b"}, listedNames(objs))
+	})
+
+	t.Run("all namespaces with label filters", func(t *testing.T) {
+		objs, err := inf.List(context.Background(), resource.NamespaceAll, "env=dev")
+		require.Nil(t, err)
+		assert.ElementsMatch(t, []string{"a", "c"}, listedNames(objs))
+	})
+
+	t.Run("invalid label filters", func(t *testing.T) {
+		_, err := inf.List(context.Background(), resource.NamespaceAll, "env in dev")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestInformerController_Lister(t *testing.T) {
+	c := NewInformerController(InformerControllerConfig{})
+	_, err := c.Lister("foo")
+	assert.Equal(t, "no informer which supports cache reads has been added for resource kind 'foo'", err.Error())
+
+	// Overlapping partitions of the same kind
+	require.Nil(t, c.AddInformer(newTestListerInformer(t, newListerTestObject("bar", "a", "env", "dev")), "foo"))
+	require.Nil(t, c.AddInformer(newTestListerInformer(t, newListerTestObject("bar", "a", "env", "dev"), newListerTestObject("baz", "b", "env", "dev")), "foo"))
+	require.Nil(t, c.AddInformer(&testInformer{}, "foo"))
+	lister, err := c.Lister("foo")
+	require.Nil(t, err)
+
+	objs, err := lister.List(context.Background(), resource.NamespaceAll, "env=dev")
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []string{"a", "b"}, listedNames(objs))
+
+	obj, err := lister.Get(context.Background(), resource.Identifier{Namespace: "baz", Name: "b"})
+	require.Nil(t, err)
+	assert.Equal(t, "b", obj.GetName())
+	_, err = lister.Get(context.Background(), resource.Identifier{Namespace: "baz", Name: "c"})
+	assert.ErrorIs(t, err, ErrNotInCache)
+}
+
+func newTestListerInformer(t *testing.T, objs ...resource.Object) *KubernetesBasedInformer {
+	inf, err := NewKubernetesBasedInformer(listerTestKind(), &nopListWatchClient{}, KubernetesBasedInformerOptions{})
+	require.Nil(t, err)
+	for _, obj := range objs {
+		require.Nil(t, inf.SharedIndexInformer.GetStore().Add(obj))
+	}
+	return inf
+}
+
+func newListerTestObject(namespace, name string, labels ...string) resource.Object {
+	obj := &resource.UntypedObject{}
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	l := make(map[string]string)
+	for i := 0; i+1 < len(labels); i += 2 {
+		l[labels[i]] = labels[i+1]
+	}
+	obj.SetLabels(l)
+	return obj
+}
+
+func listedNames(objs []resource.Object) []string {
+	names := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		names = append(names, obj.GetName())
+	}
+	return names
+}
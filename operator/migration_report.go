@@ -0,0 +1,348 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/metrics"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+const (
+	// MigrationReportKindName is the kind name of MigrationReport objects
+	MigrationReportKindName = "MigrationReport"
+	// MigrationReportVersionUnknown is the version objects are counted under in MigrationReportKindStatus.ObjectsByLastWriteVersion
+	// when the version they were last written with cannot be determined from their managed fields.
+	MigrationReportVersionUnknown = "unknown"
+
+	defaultMigrationReportPageSize = 100
+)
+
+// MigrationReport is a cluster-scoped object which contains a MigrationReportSpec, written by a MigrationReporter
+type MigrationReport = resource.TypedSpecObject[MigrationReportSpec]
+
+// MigrationReportKind returns the kind of MigrationReport objects in group. The kind's CRD must be installed
+// (for example, with k8s.ResourceManager.RegisterSchema) before a MigrationReporter can write reports.
+func MigrationReportKind(group string) resource.Kind {
+	return resource.Kind{
+		Schema: resource.NewSimpleSchema(group, "v1", &MigrationReport{}, &resource.TypedList[*MigrationReport]{},
+			resource.WithKind(MigrationReportKindName), resource.WithPlural("migrationreports"), resource.WithScope(resource.ClusterScope)),
+		Codecs: map[resource.KindEncoding]resource.Codec{
+			resource.KindEncodingJSON: resource.NewJSONCodec(),
+		},
+	}
+}
+
+// MigrationReportSpec is the version migration status of each kind in an app
+type MigrationReportSpec struct {
+	// App is the name of the app the report is for
+	App string `json:"app"`
+	// Kinds contains the status of each kind in the app's manifest
+	Kinds []MigrationReportKindStatus `json:"kinds"`
+	// GeneratedAt is the time the report was generated
+	GeneratedAt metav1.Time `json:"generatedAt"`
+}
+
+// MigrationReportKindStatus is the version migration status of a single kind
+type MigrationReportKindStatus struct {
+	// Kind is the name of the kind
+	Kind string `json:"kind"`
+	// CRD is the name of the kind's CRD
+	CRD string `json:"crd"`
+	// ServedVersions are the versions served by the API server
+	ServedVersions []string `json:"servedVersions"`
+	// PreferredVersion is the served version with the highest priority, which clients use by default
+	PreferredVersion string `json:"preferredVersion"`
+	// StorageVersion is the version new and updated objects are stored in
+	StorageVersion string `json:"storageVersion"`
+	// StoredVersions are the versions objects may still be stored in, from the CRD's status
+	StoredVersions []string `json:"storedVersions"`
+	// DeprecatedVersions are the served versions which are marked as deprecated in the CRD
+	DeprecatedVersions []string `json:"deprecatedVersions,omitempty"`
+	// Conversion is true if the CRD uses a conversion webhook, rather than the API server's default conversion
+	Conversion bool `json:"conversion"`
+	// ObjectsByLastWriteVersion is the number of objects by the API version of their most recent write,
+	// according to their managed fields, which shows the versions clients are still writing with.
+	// It does not show the version objects are stored in: every write stores the object in the StorageVersion
+	// at the time of the write, whatever version the client wrote with.
+	ObjectsByLastWriteVersion map[string]int64 `json:"objectsByLastWriteVersion"`
+	// MigrationComplete is true if StoredVersions only contains the StorageVersion. The API server only adds to
+	// StoredVersions, so this requires a storage version migration (rewriting every object, and then removing
+	// the old versions from the CRD's status.storedVersions), which the MigrationReporter does not perform.
+	MigrationComplete bool `json:"migrationComplete"`
+}
+
+// MigrationReporterConfig is the configuration for a MigrationReporter
+type MigrationReporterConfig struct {
+	// Interval is the interval at which to generate a report. If zero, a report is only generated once, on startup.
+	Interval time.Duration
+	// ReportClient is a client for MigrationReportKind objects, which is used to write each report into a MigrationReport
+	// named after the app. If nil, reports are only logged and recorded as metrics.
+	ReportClient resource.Client
+	// PageSize is the number of objects to request in each list page when counting objects. Defaults to 100.
+	PageSize int
+	// Plurals is a map of kind name to the plural used for the kind's CRD name.
+	// Kinds which are not present in the map use the default lowercase "<kind>s" plural.
+	Plurals       map[string]string
+	MetricsConfig metrics.Config
+}
+
+// MigrationReporter reports the version migration status of each kind in an app manifest: the versions served,
+// preferred, and deprecated, the versions objects are stored in, whether a conversion webhook is used,
+// a count of objects by the version they were last written with (from a scan of every object),
+// and whether the migration is complete (according to the CRD's stored versions).
+// Each report is recorded as metrics, and can be written to a MigrationReport object, to give platform owners
+// a live view of version migration progress.
+// MigrationReporter implements app.Runnable and metrics.Provider.
+type MigrationReporter struct {
+	crds           CustomResourceDefinitionClient
+	clients        resource.ClientGenerator
+	manifest       app.ManifestData
+	config         MigrationReporterConfig
+	objects        *prometheus.GaugeVec
+	storedVersions *prometheus.GaugeVec
+	complete       *prometheus.GaugeVec
+}
+
+// NewMigrationReporter creates a new MigrationReporter for the kinds in the provided manifest.
+// crdClient is used to get the CRD of each kind, and clientGenerator to list the objects of each kind.
+func NewMigrationReporter(crdClient CustomResourceDefinitionClient, clientGenerator resource.ClientGenerator, manifest app.ManifestData, cfg MigrationReporterConfig) (*MigrationReporter, error) {
+	if crdClient == nil {
+		return nil, fmt.Errorf("crdClient cannot be nil")
+	}
+	if clientGenerator == nil {
+		return nil, fmt.Errorf("clientGenerator cannot be nil")
+	}
+	if cfg.PageSize <= 0 {
+		cfg.PageSize = defaultMigrationReportPageSize
+	}
+	return &MigrationReporter{
+		crds:     crdClient,
+		clients:  clientGenerator,
+		manifest: manifest,
+		config:   cfg,
+		objects: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: cfg.MetricsConfig.Namespace,
+			Subsystem: "migration",
+			Name:      "objects",
+			Help:      "Number of objects of a kind by the API version of their most recent write.",
+		}, []string{"kind", "version"}),
+		storedVersions: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: cfg.MetricsConfig.Namespace,
+			Subsystem: "migration",
+			Name:      "stored_version",
+			Help:      "Whether objects of a kind may be stored in a version, according to the CRD's stored versions (1 if stored, 0 if not).",
+		}, []string{"kind", "version"}),
+		complete: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: cfg.MetricsConfig.Namespace,
+			Subsystem: "migration",
+			Name:      "complete",
+			Help:      "Whether the storage version is the only stored version of a kind's CRD (1 if complete, 0 if not).",
+		}, []string{"kind"}),
+	}, nil
+}
+
+// Run generates a report on startup, and then again every Interval (if non-zero) until ctx is canceled.
+// Errors from individual reports are logged rather than returned, so that a transient API server error does not stop the app.
+func (m *MigrationReporter) Run(ctx context.Context) error {
+	m.reportAndLog(ctx)
+	if m.config.Interval <= 0 {
+		<-ctx.Done()
+		return nil
+	}
+	t := time.NewTicker(m.config.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			m.reportAndLog(ctx)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// PrometheusCollectors returns the prometheus metric collectors used by the MigrationReporter
+func (m *MigrationReporter) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{m.objects, m.storedVersions, m.complete}
+}
+
+// Report generates a report of the migration status of each kind in the manifest, and records it as metrics.
+// If ReportClient is set, the report is also written to the MigrationReport named after the app.
+// If the status of a kind cannot be determined, the report contains every other kind, and an error is returned.
+func (m *MigrationReporter) Report(ctx context.Context) (*MigrationReportSpec, error) {
+	ctx, span := GetTracer().Start(ctx, "migration-report")
+	defer span.End()
+	report := &MigrationReportSpec{
+		App:         m.manifest.AppName,
+		Kinds:       make([]MigrationReportKindStatus, 0, len(m.manifest.Kinds)),
+		GeneratedAt: metav1.NewTime(time.Now()),
+	}
+	errs := make([]error, 0)
+	for _, kind := range m.manifest.Kinds {
+		status, err := m.kindStatus(ctx, kind)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		m.record(status)
+		report.Kinds = append(report.Kinds, status)
+	}
+	if m.config.ReportClient != nil {
+		if err := m.write(ctx, report); err != nil {
+			errs = append(errs, fmt.Errorf("unable to write migration report: %w", err))
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+func (m *MigrationReporter) reportAndLog(ctx context.Context) {
+	logger := logging.FromContext(ctx)
+	report, err := m.Report(ctx)
+	for _, k := range report.Kinds {
+		logger.Info("Kind version migration status", "kind", k.Kind, "storageVersion", k.StorageVersion,
+			"storedVersions", k.StoredVersions, "objectsByLastWriteVersion", k.ObjectsByLastWriteVersion, "migrationComplete", k.MigrationComplete)
+	}
+	if err != nil {
+		logger.Error("error generating migration report", "error", err)
+	}
+}
+
+func (m *MigrationReporter) kindStatus(ctx context.Context, kind app.ManifestKind) (MigrationReportKindStatus, error) {
+	plural := kindPlural(m.config.Plurals, kind.Kind)
+	name := fmt.Sprintf("%s.%s", plural, m.manifest.Group)
+	crd, err := m.crds.GetCustomResourceDefinition(ctx, name)
+	if err != nil {
+		return MigrationReportKindStatus{}, fmt.Errorf("unable to get CRD %s: %w", name, err)
+	}
+	status := MigrationReportKindStatus{
+		Kind:                      kind.Kind,
+		CRD:                       name,
+		ServedVersions:            make([]string, 0),
+		StoredVersions:            make([]string, 0),
+		Conversion:                crd.Spec.Conversion != nil && crd.Spec.Conversion.Strategy == "Webhook",
+		ObjectsByLastWriteVersion: make(map[string]int64),
+	}
+	if crd.Status != nil {
+		status.StoredVersions = append(status.StoredVersions, crd.Status.StoredVersions...)
+	}
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			status.StorageVersion = v.Name
+		}
+		if !v.Served {
+			continue
+		}
+		status.ServedVersions = append(status.ServedVersions, v.Name)
+		if v.Deprecated {
+			status.DeprecatedVersions = append(status.DeprecatedVersions, v.Name)
+		}
+		if status.PreferredVersion == "" || version.CompareKubeAwareVersionStrings(v.Name, status.PreferredVersion) > 0 {
+			status.PreferredVersion = v.Name
+		}
+	}
+	if status.PreferredVersion == "" {
+		return status, fmt.Errorf("CRD %s has no served versions", name)
+	}
+
+	scope := resource.NamespacedScope
+	if kind.Scope == string(resource.ClusterScope) {
+		scope = resource.ClusterScope
+	}
+	client, err := m.clients.ClientFor(resource.Kind{
+		Schema: resource.NewSimpleSchema(m.manifest.Group, status.PreferredVersion, &resource.UntypedObject{}, &resource.UntypedList{},
+			resource.WithKind(kind.Kind), resource.WithPlural(plural), resource.WithScope(scope)),
+		Codecs: map[resource.KindEncoding]resource.Codec{
+			resource.KindEncodingJSON: resource.NewJSONCodec(),
+		},
+	})
+	if err != nil {
+		return status, fmt.Errorf("unable to get client for %s: %w", name, err)
+	}
+	_, err = resource.ForEach(ctx, client, resource.NamespaceAll, resource.ListOptions{Limit: m.config.PageSize}, func(obj resource.Object) error {
+		status.ObjectsByLastWriteVersion[lastWriteVersion(obj)]++
+		return nil
+	})
+	if err != nil {
+		return status, fmt.Errorf("unable to list objects for %s: %w", name, err)
+	}
+
+	// Only the CRD's stored versions say which versions objects may be stored in
+	status.MigrationComplete = slices.Equal(status.StoredVersions, []string{status.StorageVersion})
+	return status, nil
+}
+
+func (m *MigrationReporter) record(status MigrationReportKindStatus) {
+	// Remove the kind's previous gauges, so that versions which no longer have any objects are no longer reported
+	m.objects.DeletePartialMatch(prometheus.Labels{"kind": status.Kind})
+	m.storedVersions.DeletePartialMatch(prometheus.Labels{"kind": status.Kind})
+	for v, count := range status.ObjectsByLastWriteVersion {
+		m.objects.WithLabelValues(status.Kind, v).Set(float64(count))
+	}
+	for _, v := range status.ServedVersions {
+		m.storedVersions.WithLabelValues(status.Kind, v).Set(0)
+	}
+	for _, v := range status.StoredVersions {
+		m.storedVersions.WithLabelValues(status.Kind, v).Set(1)
+	}
+	complete := 0.0
+	if status.MigrationComplete {
+		complete = 1
+	}
+	m.complete.WithLabelValues(status.Kind).Set(complete)
+}
+
+func (m *MigrationReporter) write(ctx context.Context, spec *MigrationReportSpec) error {
+	identifier := resource.Identifier{
+		Name: m.manifest.AppName,
+	}
+	existing, err := m.config.ReportClient.Get(ctx, identifier)
+	if err != nil && !resource.IsNotFound(err) {
+		return err
+	}
+	report := &MigrationReport{
+		Spec: *spec,
+	}
+	report.SetName(m.manifest.AppName)
+	if err != nil {
+		_, err = m.config.ReportClient.Create(ctx, identifier, report, resource.CreateOptions{})
+		return err
+	}
+	report.SetResourceVersion(existing.GetResourceVersion())
+	_, err = m.config.ReportClient.Update(ctx, identifier, report, resource.UpdateOptions{
+		ResourceVersion: existing.GetResourceVersion(),
+	})
+	return err
+}
+
+// lastWriteVersion returns the version from the API version of obj's most recent managed fields entry
+func lastWriteVersion(obj resource.Object) string {
+	var (
+		latest     *metav1.Time
+		apiVersion string
+	)
+	for _, f := range obj.GetManagedFields() {
+		if f.Time == nil || (latest != nil && f.Time.Before(latest)) {
+			continue
+		}
+		latest = f.Time
+		apiVersion = f.APIVersion
+	}
+	if apiVersion == "" {
+		return MigrationReportVersionUnknown
+	}
+	if idx := strings.LastIndex(apiVersion, "/"); idx >= 0 {
+		return apiVersion[idx+1:]
+	}
+	return apiVersion
+}
@@ -0,0 +1,220 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/k8s"
+	"github.com/grafana/grafana-app-sdk/resource"
+	"github.com/grafana/grafana-app-sdk/resource/fake"
+)
+
+func TestNewMigrationReporter(t *testing.T) {
+	_, err := NewMigrationReporter(nil, &testMigrationClientGenerator{}, app.ManifestData{}, MigrationReporterConfig{})
+	assert.Equal(t, errors.New("crdClient cannot be nil"), err)
+	_, err = NewMigrationReporter(newTestCRDClient(t), nil, app.ManifestData{}, MigrationReporterConfig{})
+	assert.Equal(t, errors.New("clientGenerator cannot be nil"), err)
+	r, err := NewMigrationReporter(newTestCRDClient(t), &testMigrationClientGenerator{}, app.ManifestData{}, MigrationReporterConfig{})
+	require.Nil(t, err)
+	assert.Equal(t, defaultMigrationReportPageSize, r.config.PageSize)
+}
+
+func TestMigrationReporter_Report(t *testing.T) {
+	manifest := app.ManifestData{
+		AppName: "test-app",
+		Group:   "test.grafana.app",
+		Kinds: []app.ManifestKind{{
+			Kind:  "Foo",
+			Scope: "Namespaced",
+		}, {
+			Kind:  "Bar",
+			Scope: "Cluster",
+		}},
+	}
+	crds := newTestCRDClient(t, &k8s.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "foos.test.grafana.app"},
+		Spec: k8s.CustomResourceDefinitionSpec{
+			Versions: []k8s.CustomResourceDefinitionSpecVersion{
+				{Name: "v1alpha1", Served: false},
+				{Name: "v1", Served: true, Deprecated: true},
+				{Name: "v2", Served: true, Storage: true},
+				{Name: "v2beta1", Served: true},
+			},
+			Conversion: &k8s.CustomResourceDefinitionSpecConversion{
+				Strategy: "Webhook",
+			},
+		},
+		Status: &k8s.CustomResourceDefinitionStatus{
+			StoredVersions: []string{"v1", "v2"},
+		},
+	}, &k8s.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "bars.test.grafana.app"},
+		Spec: k8s.CustomResourceDefinitionSpec{
+			Versions: []k8s.CustomResourceDefinitionSpecVersion{
+				{Name: "v1", Served: true, Storage: true},
+			},
+		},
+		Status: &k8s.CustomResourceDefinitionStatus{
+			StoredVersions: []string{"v1"},
+		},
+	})
+	bars := []resource.Object{
+		newMigrationTestObject("a", "test.grafana.app/v1"),
+		// Last written with a version which is not stored, which does not affect whether the migration is complete
+		newMigrationTestObject("b", "test.grafana.app/v1beta1"),
+	}
+	for _, bar := range bars {
+		bar.SetNamespace("")
+		bar.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{Group: "test.grafana.app", Version: "v1", Kind: "Bar"})
+	}
+	foos := []resource.Object{
+		newMigrationTestObject("a", "test.grafana.app/v1"),
+		newMigrationTestObject("b", "test.grafana.app/v2", "test.grafana.app/v1"),
+		newMigrationTestObject("c", "test.grafana.app/v1", "test.grafana.app/v2"),
+		newMigrationTestObject("d"),
+	}
+	for _, foo := range foos {
+		// Objects are listed with the preferred version, which is the only one they are stored with in the tracker
+		foo.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{Group: "test.grafana.app", Version: "v2", Kind: "Foo"})
+	}
+	tracker, err := fake.NewTracker(append(foos, bars...)...)
+	require.Nil(t, err)
+	generator := &testMigrationClientGenerator{ClientGenerator: fake.NewClientGenerator(tracker)}
+	reports := newTestFakeClient(t, MigrationReportKind("test.grafana.app"))
+	r, err := NewMigrationReporter(crds, generator, manifest, MigrationReporterConfig{
+		ReportClient: reports,
+		PageSize:     2,
+	})
+	require.Nil(t, err)
+
+	report, err := r.Report(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, "test-app", report.App)
+	require.Len(t, report.Kinds, 2)
+	assert.Equal(t, MigrationReportKindStatus{
+		Kind:                      "Foo",
+		CRD:                       "foos.test.grafana.app",
+		ServedVersions:            []string{"v1", "v2", "v2beta1"},
+		PreferredVersion:          "v2",
+		StorageVersion:            "v2",
+		StoredVersions:            []string{"v1", "v2"},
+		DeprecatedVersions:        []string{"v1"},
+		Conversion:                true,
+		ObjectsByLastWriteVersion: map[string]int64{"v1": 2, "v2": 1, MigrationReportVersionUnknown: 1},
+		MigrationComplete:         false,
+	}, report.Kinds[0])
+	assert.Equal(t, MigrationReportKindStatus{
+		Kind:                      "Bar",
+		CRD:                       "bars.test.grafana.app",
+		ServedVersions:            []string{"v1"},
+		PreferredVersion:          "v1",
+		StorageVersion:            "v1",
+		StoredVersions:            []string{"v1"},
+		ObjectsByLastWriteVersion: map[string]int64{"v1": 1, "v1beta1": 1},
+		MigrationComplete:         true,
+	}, report.Kinds[1])
+	assert.Equal(t, resource.ClusterScope, generator.scopes["Bar"])
+	limits := make([]int, 0)
+	for _, action := range generator.clients["Foo"].Actions() {
+		if action.Verb == fake.VerbList {
+			limits = append(limits, action.Options.(resource.ListOptions).Limit)
+		}
+	}
+	assert.Equal(t, []int{2, 2}, limits)
+
+	t.Run("metrics", func(t *testing.T) {
+		assert.Equal(t, float64(2), testutil.ToFloat64(r.objects.WithLabelValues("Foo", "v1")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(r.storedVersions.WithLabelValues("Foo", "v1")))
+		assert.Equal(t, float64(0), testutil.ToFloat64(r.storedVersions.WithLabelValues("Foo", "v2beta1")))
+		assert.Equal(t, float64(0), testutil.ToFloat64(r.complete.WithLabelValues("Foo")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(r.complete.WithLabelValues("Bar")))
+	})
+
+	t.Run("report object", func(t *testing.T) {
+		identifier := resource.Identifier{Name: "test-app"}
+		created := getTestObject(t, reports, identifier)
+		spec := created.(*MigrationReport).Spec
+		assert.Equal(t, "test-app", spec.App)
+		assert.Equal(t, report.Kinds, spec.Kinds)
+		// The stored time is truncated to seconds
+		assert.WithinDuration(t, report.GeneratedAt.Time, spec.GeneratedAt.Time, time.Second)
+
+		reports.ClearActions()
+		_, err := r.Report(context.Background())
+		require.Nil(t, err)
+		actions := reports.Actions()
+		require.Len(t, actions, 2)
+		assert.Equal(t, fake.VerbUpdate, actions[1].Verb)
+		assert.Equal(t, created.GetResourceVersion(), actions[1].Options.(resource.UpdateOptions).ResourceVersion)
+	})
+
+	t.Run("missing CRD", func(t *testing.T) {
+		require.Nil(t, crds.Delete(context.Background(), resource.Identifier{Name: "bars.test.grafana.app"}, resource.DeleteOptions{}))
+		report, err := r.Report(context.Background())
+		assert.True(t, resource.IsNotFound(err), err)
+		require.Len(t, report.Kinds, 1)
+		assert.Equal(t, "Foo", report.Kinds[0].Kind)
+	})
+}
+
+func TestLastWriteVersion(t *testing.T) {
+	assert.Equal(t, "v2", lastWriteVersion(newMigrationTestObject("a", "test.grafana.app/v1", "test.grafana.app/v2")))
+	assert.Equal(t, "v1", lastWriteVersion(newMigrationTestObject("a", "v1")))
+	assert.Equal(t, MigrationReportVersionUnknown, lastWriteVersion(newMigrationTestObject("a")))
+}
+
+// newMigrationTestObject returns an object with a managed fields entry for each of apiVersions, in order of time
+func newMigrationTestObject(name string, apiVersions ...string) *resource.UntypedObject {
+	obj := &resource.UntypedObject{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      name,
+		},
+	}
+	now := time.Now()
+	fields := make([]metav1.ManagedFieldsEntry, len(apiVersions))
+	for i, v := range apiVersions {
+		ts := metav1.NewTime(now.Add(time.Duration(i) * time.Minute))
+		fields[i] = metav1.ManagedFieldsEntry{
+			Manager:    "test",
+			APIVersion: v,
+			Time:       &ts,
+		}
+	}
+	// Reverse the order, so that the most recent entry isn't always the last one
+	for i, j := 0, len(fields)-1; i < j; i, j = i+1, j-1 {
+		fields[i], fields[j] = fields[j], fields[i]
+	}
+	obj.SetManagedFields(fields)
+	return obj
+}
+
+// testMigrationClientGenerator is a fake.ClientGenerator which records the kind each client was requested for
+type testMigrationClientGenerator struct {
+	*fake.ClientGenerator
+	clients map[string]*fake.Client
+	scopes  map[string]resource.SchemaScope
+}
+
+func (g *testMigrationClientGenerator) ClientFor(kind resource.Kind) (resource.Client, error) {
+	if g.clients == nil {
+		g.clients = make(map[string]*fake.Client)
+		g.scopes = make(map[string]resource.SchemaScope)
+	}
+	client, err := g.Client(kind)
+	if err != nil {
+		return nil, err
+	}
+	g.clients[kind.Kind()] = client
+	g.scopes[kind.Kind()] = kind.Scope()
+	return client, nil
+}
@@ -14,6 +14,8 @@ import (
 )
 
 // PatchClient is a Client capable of making PatchInto requests. This is used by OpinionatedWatch to update finalizers.
+// OpinionatedReconciler makes server-side apply patches with a PatchClient, and falls back to JSON patches
+// if the PatchClient rejects them with a 415 Unsupported Media Type error (as the API server does for unsupported patch types).
 type PatchClient interface {
 	PatchInto(context.Context, resource.Identifier, resource.PatchRequest, resource.PatchOptions, resource.Object) error
 }
@@ -0,0 +1,100 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// ObjectMapper maps an object to the identifiers of objects (of another kind) which should be reconciled when it changes,
+// such as the owners of the object.
+type ObjectMapper interface {
+	Map(ctx context.Context, object resource.Object) ([]resource.Identifier, error)
+}
+
+// ObjectMapperFunc is a function which implements ObjectMapper
+type ObjectMapperFunc func(ctx context.Context, object resource.Object) ([]resource.Identifier, error)
+
+// Map calls the function
+func (f ObjectMapperFunc) Map(ctx context.Context, object resource.Object) ([]resource.Identifier, error) {
+	return f(ctx, object)
+}
+
+// OwnerReferenceMapper returns an ObjectMapper which maps an object to its owners of the owner schema's group and kind,
+// using the object's owner references. If controllerOnly is true, only the controller owner reference is used.
+// Owners of a namespaced schema are identified in the namespace of the object, and owners of a cluster-scoped schema
+// are identified without a namespace.
+func OwnerReferenceMapper(owner resource.Schema, controllerOnly bool) ObjectMapper {
+	return ObjectMapperFunc(func(_ context.Context, object resource.Object) ([]resource.Identifier, error) {
+		ids := make([]resource.Identifier, 0)
+		for _, ref := range object.GetOwnerReferences() {
+			if controllerOnly && (ref.Controller == nil || !*ref.Controller) {
+				continue
+			}
+			gv, err := schema.ParseGroupVersion(ref.APIVersion)
+			if err != nil {
+				return ids, fmt.Errorf("invalid owner reference apiVersion '%s': %w", ref.APIVersion, err)
+			}
+			if gv.Group != owner.Group() || ref.Kind != owner.Kind() {
+				continue
+			}
+			id := resource.Identifier{
+				Name: ref.Name,
+			}
+			if owner.Scope() != resource.ClusterScope {
+				id.Namespace = object.GetNamespace()
+			}
+			ids = append(ids, id)
+		}
+		return ids, nil
+	})
+}
+
+// SetOwnerReference adds an owner reference to owner to the owner references of object,
+// or updates the existing reference if object already has one for owner (matched by UID).
+// If controller is true, the reference is marked as the controller reference, and an error is returned if
+// object already has a controller reference to a different owner. If controller is false, an existing reference
+// keeps its controller flag. It does not update object in the API server.
+func SetOwnerReference(object, owner resource.Object, controller bool) error {
+	if owner.GetUID() == "" {
+		return fmt.Errorf("owner '%s' has no UID", owner.GetName())
+	}
+	gvk := owner.GroupVersionKind()
+	ref := metav1.OwnerReference{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Name:       owner.GetName(),
+		UID:        owner.GetUID(),
+	}
+	if controller {
+		isController := true
+		ref.Controller = &isController
+	}
+	refs := object.GetOwnerReferences()
+	idx := -1
+	for i, existing := range refs {
+		if existing.UID == owner.GetUID() {
+			idx = i
+			continue
+		}
+		if controller && existing.Controller != nil && *existing.Controller {
+			return fmt.Errorf("object already has a controller owner reference to %s '%s'", existing.Kind, existing.Name)
+		}
+	}
+	updated := append(make([]metav1.OwnerReference, 0, len(refs)+1), refs...)
+	if idx >= 0 {
+		ref.BlockOwnerDeletion = refs[idx].BlockOwnerDeletion
+		if !controller {
+			ref.Controller = refs[idx].Controller
+		}
+		updated[idx] = ref
+	} else {
+		updated = append(updated, ref)
+	}
+	object.SetOwnerReferences(updated)
+	return nil
+}
@@ -0,0 +1,227 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestOwnerReferenceMapper(t *testing.T) {
+	owner := resource.NewSimpleSchema("foo", "v1", &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("Foo"))
+	obj := newOwnedTestObject("ns", "child",
+		metav1.OwnerReference{APIVersion: "foo/v1", Kind: "Foo", Name: "a", Controller: boolPtr(true)},
+		metav1.OwnerReference{APIVersion: "foo/v2", Kind: "Foo", Name: "b"},
+		metav1.OwnerReference{APIVersion: "bar/v1", Kind: "Foo", Name: "c"},
+		metav1.OwnerReference{APIVersion: "foo/v1", Kind: "Bar", Name: "d"},
+	)
+
+	t.Run("all owners", func(t *testing.T) {
+		ids, err := OwnerReferenceMapper(owner, false).Map(context.Background(), obj)
+		require.Nil(t, err)
+		assert.Equal(t, []resource.Identifier{{Namespace: "ns", Name: "a"}, {Namespace: "ns", Name: "b"}}, ids)
+	})
+
+	t.Run("controller only", func(t *testing.T) {
+		ids, err := OwnerReferenceMapper(owner, true).Map(context.Background(), obj)
+		require.Nil(t, err)
+		assert.Equal(t, []resource.Identifier{{Namespace: "ns", Name: "a"}}, ids)
+	})
+
+	t.Run("cluster-scoped owner", func(t *testing.T) {
+		clusterOwner := resource.NewSimpleSchema("foo", "v1", &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("Foo"), resource.WithScope(resource.ClusterScope))
+		ids, err := OwnerReferenceMapper(clusterOwner, true).Map(context.Background(), obj)
+		require.Nil(t, err)
+		assert.Equal(t, []resource.Identifier{{Name: "a"}}, ids)
+	})
+
+	t.Run("invalid apiVersion", func(t *testing.T) {
+		_, err := OwnerReferenceMapper(owner, false).Map(context.Background(), newOwnedTestObject("ns", "child",
+			metav1.OwnerReference{APIVersion: "a/b/c", Kind: "Foo", Name: "a"}))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestSetOwnerReference(t *testing.T) {
+	owner := newOwnerTestObject("a", "uid-a")
+	obj := &resource.UntypedObject{}
+
+	t.Run("no UID", func(t *testing.T) {
+		assert.NotNil(t, SetOwnerReference(obj, &resource.UntypedObject{}, false))
+	})
+
+	t.Run("add and update", func(t *testing.T) {
+		require.Nil(t, SetOwnerReference(obj, owner, true))
+		require.Len(t, obj.GetOwnerReferences(), 1)
+		ref := obj.GetOwnerReferences()[0]
+		assert.Equal(t, "foo/v1", ref.APIVersion)
+		assert.Equal(t, "Foo", ref.Kind)
+		assert.Equal(t, "a", ref.Name)
+		assert.Equal(t, types.UID("uid-a"), ref.UID)
+		assert.True(t, *ref.Controller)
+
+		// Setting it again updates the existing reference, and keeps the controller flag
+		require.Nil(t, SetOwnerReference(obj, owner, false))
+		require.Len(t, obj.GetOwnerReferences(), 1)
+		assert.True(t, *obj.GetOwnerReferences()[0].Controller)
+	})
+
+	t.Run("second controller", func(t *testing.T) {
+		other := newOwnerTestObject("b", "uid-b")
+		assert.NotNil(t, SetOwnerReference(obj, other, true))
+		require.Nil(t, SetOwnerReference(obj, other, false))
+		assert.Len(t, obj.GetOwnerReferences(), 2)
+	})
+}
+
+func TestInformerController_AddMappedInformer(t *testing.T) {
+	ownerSchema := resource.NewSimpleSchema("foo", "v1", &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("Foo"))
+	mapper := OwnerReferenceMapper(ownerSchema, true)
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		c := NewInformerController(InformerControllerConfig{})
+		assert.NotNil(t, c.AddMappedInformer(nil, "bar", "foo", mapper))
+		assert.NotNil(t, c.AddMappedInformer(&testInformer{}, "", "foo", mapper))
+		assert.NotNil(t, c.AddMappedInformer(&testInformer{}, "bar", "foo", nil))
+	})
+
+	t.Run("reconciles owners", func(t *testing.T) {
+		ownerA := newOwnerTestObject("a", "uid-a")
+		ownerA.SetNamespace("ns")
+		deleting := newOwnerTestObject("deleting", "uid-deleting")
+		deleting.SetNamespace("ns")
+		deleting.SetDeletionTimestamp(&metav1.Time{})
+
+		c := NewInformerController(InformerControllerConfig{})
+		require.Nil(t, c.AddInformer(newTestListerInformer(t, ownerA, deleting), "foo"))
+		requests := make([]ReconcileRequest, 0)
+		require.Nil(t, c.AddReconciler(&SimpleReconciler{
+			ReconcileFunc: func(_ context.Context, req ReconcileRequest) (ReconcileResult, error) {
+				requests = append(requests, req)
+				return ReconcileResult{}, nil
+			},
+		}, "foo", NewPredicateFunc(func(resource.Object) bool { return false })))
+		owned := &testInformer{}
+		require.Nil(t, c.AddMappedInformer(owned, "bar", "foo", mapper))
+
+		controllerRef := func(name string) metav1.OwnerReference {
+			return metav1.OwnerReference{APIVersion: "foo/v1", Kind: "Foo", Name: name, Controller: boolPtr(true)}
+		}
+		childA := newOwnedTestObject("ns", "child", controllerRef("a"))
+		owned.FireAdd(context.Background(), childA)
+		owned.FireUpdate(context.Background(), childA, childA)
+		owned.FireDelete(context.Background(), childA)
+		// Not in the cache, being deleted, and not a controller reference
+		owned.FireAdd(context.Background(), newOwnedTestObject("ns", "child", controllerRef("missing")))
+		owned.FireAdd(context.Background(), newOwnedTestObject("ns", "child", controllerRef("deleting")))
+		owned.FireAdd(context.Background(), newOwnedTestObject("ns", "child", metav1.OwnerReference{APIVersion: "foo/v1", Kind: "Foo", Name: "a"}))
+
+		require.Len(t, requests, 3)
+		for _, req := range requests {
+			assert.Equal(t, ReconcileActionResynced, req.Action)
+			assert.Equal(t, "a", req.Object.GetName())
+			// The request must not contain the object from the cache
+			assert.NotSame(t, ownerA, req.Object)
+		}
+		assert.Equal(t, 1, c.informers.KeySize("bar"))
+	})
+}
+
+func newOwnerTestObject(name string, uid types.UID) *resource.UntypedObject {
+	obj := &resource.UntypedObject{}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{Group: "foo", Version: "v1", Kind: "Foo"})
+	obj.SetName(name)
+	obj.SetUID(uid)
+	return obj
+}
+
+func newOwnedTestObject(namespace, name string, refs ...metav1.OwnerReference) *resource.UntypedObject {
+	obj := &resource.UntypedObject{}
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetOwnerReferences(refs)
+	return obj
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestInformerController_Enqueue(t *testing.T) {
+	t.Run("no lister", func(t *testing.T) {
+		c := NewInformerController(InformerControllerConfig{})
+		assert.NotNil(t, c.Enqueue(context.Background(), "foo", resource.Identifier{Name: "a"}))
+	})
+
+	t.Run("reconciles cached objects", func(t *testing.T) {
+		ownerA := newOwnerTestObject("a", "uid-a")
+		ownerA.SetNamespace("ns")
+		c := NewInformerController(InformerControllerConfig{})
+		require.Nil(t, c.AddInformer(newTestListerInformer(t, ownerA), "foo"))
+		requests := make([]ReconcileRequest, 0)
+		require.Nil(t, c.AddReconciler(&SimpleReconciler{
+			ReconcileFunc: func(_ context.Context, req ReconcileRequest) (ReconcileResult, error) {
+				requests = append(requests, req)
+				return ReconcileResult{}, nil
+			},
+		}, "foo"))
+		require.Nil(t, c.Enqueue(context.Background(), "foo", resource.Identifier{Namespace: "ns", Name: "a"}, resource.Identifier{Namespace: "ns", Name: "missing"}))
+		require.Len(t, requests, 1)
+		assert.Equal(t, ReconcileActionResynced, requests[0].Action)
+		assert.Equal(t, "a", requests[0].Object.GetName())
+	})
+}
+
+func TestInformerController_AddEventMapper(t *testing.T) {
+	mapper := ObjectMapperFunc(func(_ context.Context, obj resource.Object) ([]resource.Identifier, error) {
+		return []resource.Identifier{{Namespace: obj.GetNamespace(), Name: obj.GetAnnotations()["foo"]}}, nil
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		c := NewInformerController(InformerControllerConfig{})
+		assert.NotNil(t, c.AddEventMapper("", "foo", mapper))
+		assert.NotNil(t, c.AddEventMapper("bar", "", mapper))
+		assert.NotNil(t, c.AddEventMapper("bar", "foo", nil))
+	})
+
+	t.Run("maps events alongside watchers", func(t *testing.T) {
+		ownerA := newOwnerTestObject("a", "uid-a")
+		ownerA.SetNamespace("ns")
+		c := NewInformerController(InformerControllerConfig{})
+		require.Nil(t, c.AddInformer(newTestListerInformer(t, ownerA), "foo"))
+		reconciled := 0
+		require.Nil(t, c.AddReconciler(&SimpleReconciler{
+			ReconcileFunc: func(context.Context, ReconcileRequest) (ReconcileResult, error) {
+				reconciled++
+				return ReconcileResult{}, nil
+			},
+		}, "foo"))
+		watched := 0
+		require.Nil(t, c.AddWatcher(&SimpleWatcher{
+			AddFunc: func(context.Context, resource.Object) error {
+				watched++
+				return nil
+			},
+		}, "bar"))
+		require.Nil(t, c.AddEventMapper("bar", "foo", mapper, NewPredicateFunc(func(obj resource.Object) bool {
+			return obj.GetName() != "ignored"
+		})))
+		inf := &testInformer{}
+		require.Nil(t, c.AddInformer(inf, "bar"))
+
+		referencing := newOwnedTestObject("ns", "config")
+		referencing.SetAnnotations(map[string]string{"foo": "a"})
+		inf.FireAdd(context.Background(), referencing)
+		ignored := newOwnedTestObject("ns", "ignored")
+		ignored.SetAnnotations(map[string]string{"foo": "a"})
+		inf.FireAdd(context.Background(), ignored)
+		assert.Equal(t, 1, reconciled)
+		assert.Equal(t, 2, watched)
+	})
+}
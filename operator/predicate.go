@@ -0,0 +1,213 @@
+package operator
+
+import (
+	"maps"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// Predicate filters the events an InformerController passes to a ResourceWatcher or Reconciler.
+// Predicates are attached when the watcher or reconciler is added with AddWatcher, AddReconciler,
+// or AddReconcilerWithOptions (as ReconcilerOptions.Predicates). An event is only passed on if every attached
+// Predicate accepts it. An event which is filtered out has no side effects: it does not dequeue pending retries
+// for the object, and it is not counted in watcher or reconciler metrics.
+//
+// For each event type, the corresponding function field is called to decide whether to pass on the event.
+// A nil function field accepts all events of that type.
+type Predicate struct {
+	// CreateFunc is called for add events
+	CreateFunc func(obj resource.Object) bool
+	// UpdateFunc is called for update events with the old and new state of the object
+	UpdateFunc func(oldObj, newObj resource.Object) bool
+	// DeleteFunc is called for delete events
+	DeleteFunc func(obj resource.Object) bool
+}
+
+// Create returns true if the Predicate accepts an add event for obj
+func (p Predicate) Create(obj resource.Object) bool {
+	if p.CreateFunc != nil {
+		return p.CreateFunc(obj)
+	}
+	return true
+}
+
+// Update returns true if the Predicate accepts an update event from oldObj to newObj
+func (p Predicate) Update(oldObj, newObj resource.Object) bool {
+	if p.UpdateFunc != nil {
+		return p.UpdateFunc(oldObj, newObj)
+	}
+	return true
+}
+
+// Delete returns true if the Predicate accepts a delete event for obj
+func (p Predicate) Delete(obj resource.Object) bool {
+	if p.DeleteFunc != nil {
+		return p.DeleteFunc(obj)
+	}
+	return true
+}
+
+// NewPredicateFunc returns a Predicate which calls filter with the object of each event.
+// For update events, filter is called with the new state of the object.
+func NewPredicateFunc(filter func(obj resource.Object) bool) Predicate {
+	return Predicate{
+		CreateFunc: filter,
+		UpdateFunc: func(_, newObj resource.Object) bool {
+			return filter(newObj)
+		},
+		DeleteFunc: filter,
+	}
+}
+
+// LabelSelectorPredicate returns a Predicate which accepts events for objects whose labels match selector.
+// For update events, the new state of the object is matched.
+func LabelSelectorPredicate(selector labels.Selector) Predicate {
+	return NewPredicateFunc(func(obj resource.Object) bool {
+		return selector.Matches(labels.Set(obj.GetLabels()))
+	})
+}
+
+// AnnotationPredicate returns a Predicate which accepts events for objects with the annotation key.
+// If value is non-empty, the annotation must also have that value. For update events, the new state of the object is checked.
+func AnnotationPredicate(key, value string) Predicate {
+	return NewPredicateFunc(func(obj resource.Object) bool {
+		v, ok := obj.GetAnnotations()[key]
+		return ok && (value == "" || v == value)
+	})
+}
+
+// GenerationChangedPredicate returns a Predicate which filters out update events where the generation of
+// the object did not change, such as updates to only the metadata or subresources (like status) of the object.
+// Add and delete events are always accepted.
+// Kinds which do not track generation (where it is always zero) will have all update events filtered out.
+func GenerationChangedPredicate() Predicate {
+	return Predicate{
+		UpdateFunc: func(oldObj, newObj resource.Object) bool {
+			if oldObj == nil {
+				return true
+			}
+			return oldObj.GetGeneration() != newObj.GetGeneration()
+		},
+	}
+}
+
+// LabelsChangedPredicate returns a Predicate which filters out update events where the labels of the object did not change.
+// Add and delete events are always accepted.
+func LabelsChangedPredicate() Predicate {
+	return Predicate{
+		UpdateFunc: func(oldObj, newObj resource.Object) bool {
+			if oldObj == nil {
+				return true
+			}
+			return !maps.Equal(oldObj.GetLabels(), newObj.GetLabels())
+		},
+	}
+}
+
+// AnnotationsChangedPredicate returns a Predicate which filters out update events where the annotations of the object did not change.
+// Add and delete events are always accepted.
+func AnnotationsChangedPredicate() Predicate {
+	return Predicate{
+		UpdateFunc: func(oldObj, newObj resource.Object) bool {
+			if oldObj == nil {
+				return true
+			}
+			return !maps.Equal(oldObj.GetAnnotations(), newObj.GetAnnotations())
+		},
+	}
+}
+
+// AnyPredicate returns a Predicate which accepts an event if any of predicates accepts it.
+// If predicates is empty, all events are accepted.
+func AnyPredicate(predicates ...Predicate) Predicate {
+	if len(predicates) == 0 {
+		return Predicate{}
+	}
+	return Predicate{
+		CreateFunc: func(obj resource.Object) bool {
+			for _, p := range predicates {
+				if p.Create(obj) {
+					return true
+				}
+			}
+			return false
+		},
+		UpdateFunc: func(oldObj, newObj resource.Object) bool {
+			for _, p := range predicates {
+				if p.Update(oldObj, newObj) {
+					return true
+				}
+			}
+			return false
+		},
+		DeleteFunc: func(obj resource.Object) bool {
+			for _, p := range predicates {
+				if p.Delete(obj) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// predicates is a list of Predicates which all must accept an event
+type predicates []Predicate
+
+func (p predicates) create(obj resource.Object) bool {
+	for _, pred := range p {
+		if !pred.Create(obj) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p predicates) update(oldObj, newObj resource.Object) bool {
+	for _, pred := range p {
+		if !pred.Update(oldObj, newObj) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p predicates) delete(obj resource.Object) bool {
+	for _, pred := range p {
+		if !pred.Delete(obj) {
+			return false
+		}
+	}
+	return true
+}
+
+// predicateWatcher is a ResourceWatcher added to an InformerController with Predicates.
+type predicateWatcher struct {
+	ResourceWatcher
+	predicates predicates
+}
+
+// predicateReconciler is a Reconciler added to an InformerController with Predicates.
+// The wrapped Reconciler may be a queuedReconciler.
+type predicateReconciler struct {
+	Reconciler
+	predicates predicates
+}
+
+// unwrapWatcher returns the ResourceWatcher wrapped by a predicateWatcher, or watcher if it is not one
+func unwrapWatcher(watcher ResourceWatcher) ResourceWatcher {
+	if cast, ok := watcher.(*predicateWatcher); ok {
+		return cast.ResourceWatcher
+	}
+	return watcher
+}
+
+// unwrapReconciler returns the Reconciler wrapped by a predicateReconciler, or reconciler if it is not one
+func unwrapReconciler(reconciler Reconciler) Reconciler {
+	if cast, ok := reconciler.(*predicateReconciler); ok {
+		return cast.Reconciler
+	}
+	return reconciler
+}
@@ -0,0 +1,194 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestPredicate(t *testing.T) {
+	obj := &resource.UntypedObject{}
+	t.Run("nil funcs accept", func(t *testing.T) {
+		p := Predicate{}
+		assert.True(t, p.Create(obj))
+		assert.True(t, p.Update(obj, obj))
+		assert.True(t, p.Delete(obj))
+	})
+
+	t.Run("NewPredicateFunc uses new object for update", func(t *testing.T) {
+		match := &resource.UntypedObject{ObjectMeta: metav1.ObjectMeta{Name: "match"}}
+		p := NewPredicateFunc(func(o resource.Object) bool {
+			return o.GetName() == "match"
+		})
+		assert.True(t, p.Create(match))
+		assert.False(t, p.Create(obj))
+		assert.True(t, p.Update(obj, match))
+		assert.False(t, p.Update(match, obj))
+		assert.True(t, p.Delete(match))
+	})
+
+	t.Run("LabelSelectorPredicate", func(t *testing.T) {
+		sel, err := labels.Parse("foo=bar")
+		require.Nil(t, err)
+		p := LabelSelectorPredicate(sel)
+		assert.True(t, p.Create(&resource.UntypedObject{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"foo": "bar"}}}))
+		assert.False(t, p.Create(&resource.UntypedObject{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"foo": "baz"}}}))
+		assert.False(t, p.Delete(obj))
+	})
+
+	t.Run("AnnotationPredicate", func(t *testing.T) {
+		annotated := &resource.UntypedObject{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"a": "b"}}}
+		assert.True(t, AnnotationPredicate("a", "").Create(annotated))
+		assert.True(t, AnnotationPredicate("a", "b").Create(annotated))
+		assert.False(t, AnnotationPredicate("a", "c").Create(annotated))
+		assert.False(t, AnnotationPredicate("a", "").Create(obj))
+	})
+
+	t.Run("GenerationChangedPredicate", func(t *testing.T) {
+		p := GenerationChangedPredicate()
+		gen1 := &resource.UntypedObject{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+		gen2 := &resource.UntypedObject{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+		assert.True(t, p.Create(gen1))
+		assert.True(t, p.Update(gen1, gen2))
+		assert.False(t, p.Update(gen1, gen1.Copy()))
+		assert.True(t, p.Update(nil, gen1))
+		assert.True(t, p.Delete(gen1))
+	})
+
+	t.Run("LabelsChangedPredicate and AnnotationsChangedPredicate", func(t *testing.T) {
+		a := &resource.UntypedObject{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"a": "b"}, Annotations: map[string]string{"a": "b"}}}
+		b := &resource.UntypedObject{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"a": "c"}, Annotations: map[string]string{"a": "b"}}}
+		assert.True(t, LabelsChangedPredicate().Update(a, b))
+		assert.False(t, LabelsChangedPredicate().Update(a, a.Copy()))
+		assert.False(t, AnnotationsChangedPredicate().Update(a, b))
+	})
+
+	t.Run("AnyPredicate", func(t *testing.T) {
+		reject := NewPredicateFunc(func(resource.Object) bool { return false })
+		assert.False(t, AnyPredicate(reject, reject).Create(obj))
+		assert.True(t, AnyPredicate(reject, Predicate{}).Create(obj))
+		assert.True(t, AnyPredicate().Update(obj, obj))
+	})
+}
+
+func TestInformerController_Predicates(t *testing.T) {
+	reject := NewPredicateFunc(func(resource.Object) bool { return false })
+	gen1 := &resource.UntypedObject{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+	gen2 := &resource.UntypedObject{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+
+	t.Run("filtered watcher", func(t *testing.T) {
+		kind := "foo"
+		calls := make(map[string]int)
+		inf := &testInformer{}
+		c := NewInformerController(InformerControllerConfig{})
+		w := &SimpleWatcher{
+			AddFunc: func(context.Context, resource.Object) error {
+				calls["add"]++
+				return nil
+			},
+			UpdateFunc: func(context.Context, resource.Object, resource.Object) error {
+				calls["update"]++
+				return nil
+			},
+			DeleteFunc: func(context.Context, resource.Object) error {
+				calls["delete"]++
+				return nil
+			},
+		}
+		require.Nil(t, c.AddWatcher(w, kind, GenerationChangedPredicate()))
+		require.Nil(t, c.AddInformer(inf, kind))
+		inf.FireAdd(context.Background(), gen1)
+		inf.FireUpdate(context.Background(), gen1, gen1)
+		inf.FireUpdate(context.Background(), gen1, gen2)
+		inf.FireDelete(context.Background(), gen2)
+		assert.Equal(t, map[string]int{"add": 1, "update": 1, "delete": 1}, calls)
+
+		c.RemoveWatcher(w, kind)
+		assert.Equal(t, 0, c.watchers.KeySize(kind))
+	})
+
+	t.Run("filtered reconciler", func(t *testing.T) {
+		kind := "foo"
+		actions := make([]ReconcileAction, 0)
+		inf := &testInformer{}
+		c := NewInformerController(InformerControllerConfig{})
+		r := &SimpleReconciler{
+			ReconcileFunc: func(_ context.Context, req ReconcileRequest) (ReconcileResult, error) {
+				actions = append(actions, req.Action)
+				return ReconcileResult{}, nil
+			},
+		}
+		require.Nil(t, c.AddReconciler(r, kind, GenerationChangedPredicate()))
+		require.Nil(t, c.AddInformer(inf, kind))
+		inf.FireAdd(context.Background(), gen1)
+		inf.FireUpdate(context.Background(), gen1, gen1)
+		inf.FireUpdate(context.Background(), gen1, gen2)
+		assert.Equal(t, []ReconcileAction{ReconcileActionCreated, ReconcileActionUpdated}, actions)
+
+		c.RemoveReconciler(r, kind)
+		assert.Equal(t, 0, c.reconcilers.KeySize(kind))
+	})
+
+	t.Run("all predicates must accept", func(t *testing.T) {
+		kind := "foo"
+		calls := 0
+		inf := &testInformer{}
+		c := NewInformerController(InformerControllerConfig{})
+		require.Nil(t, c.AddWatcher(&SimpleWatcher{
+			AddFunc: func(context.Context, resource.Object) error {
+				calls++
+				return nil
+			},
+		}, kind, Predicate{}, reject))
+		require.Nil(t, c.AddInformer(inf, kind))
+		inf.FireAdd(context.Background(), gen1)
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("filtered event does not dequeue retries", func(t *testing.T) {
+		kind := "foo"
+		inf := &testInformer{}
+		c := NewInformerController(InformerControllerConfig{})
+		c.RetryPolicy = func(error, int) (bool, time.Duration) {
+			return true, time.Hour
+		}
+		require.Nil(t, c.AddReconciler(&SimpleReconciler{
+			ReconcileFunc: func(context.Context, ReconcileRequest) (ReconcileResult, error) {
+				return ReconcileResult{}, errors.New("fail")
+			},
+		}, kind, GenerationChangedPredicate()))
+		require.Nil(t, c.AddInformer(inf, kind))
+		inf.FireAdd(context.Background(), gen1)
+		retries := 0
+		c.toRetry.RangeAll(func(string, int, retryInfo) { retries++ })
+		assert.Equal(t, 1, retries)
+		inf.FireUpdate(context.Background(), gen1, gen1)
+		retries = 0
+		c.toRetry.RangeAll(func(string, int, retryInfo) { retries++ })
+		assert.Equal(t, 1, retries)
+	})
+
+	t.Run("filtered queued reconciler", func(t *testing.T) {
+		kind := "foo"
+		c := NewInformerController(InformerControllerConfig{})
+		r := &SimpleReconciler{}
+		require.Nil(t, c.AddReconcilerWithOptions(r, kind, ReconcilerOptions{
+			MaxConcurrentReconciles: 2,
+			Predicates:              []Predicate{reject},
+		}))
+		item, ok := c.reconcilers.ItemAt(kind, 0)
+		require.True(t, ok)
+		_, ok = unwrapReconciler(item).(*queuedReconciler)
+		assert.True(t, ok)
+		c.RemoveAllReconcilersForResource(kind)
+		assert.Equal(t, 0, c.reconcilers.KeySize(kind))
+	})
+}
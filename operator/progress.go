@@ -0,0 +1,232 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+const (
+	// ProgressStatusField is the key in an object's status subresource which contains its ReconcileProgress
+	ProgressStatusField = "progress"
+
+	defaultProgressMinInterval = 5 * time.Second
+)
+
+// ReconcileProgress is the progress of a long-running reconcile, stored in an object's status.progress.
+// Kinds with `reportsProgress: true` in their CUE definition have this block added to their status schema,
+// and "Phase" and "Progress" printer columns added to their CRD.
+type ReconcileProgress struct {
+	// Phase is a short, machine-readable description of the current stage of the reconcile, such as "Provisioning"
+	Phase string `json:"phase"`
+	// Percent is the completion percentage, from 0 to 100
+	Percent int `json:"percent"`
+	// Message is an optional human-readable message describing the current progress
+	Message string `json:"message,omitempty"`
+	// LastUpdateTime is the time the progress was last reported
+	LastUpdateTime time.Time `json:"lastUpdateTime"`
+}
+
+// GetProgress returns the ReconcileProgress stored in the status of obj, or nil if there is none
+func GetProgress(obj resource.Object) (*ReconcileProgress, error) {
+	status, err := statusAsMap(obj)
+	if err != nil || status[ProgressStatusField] == nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(status[ProgressStatusField])
+	if err != nil {
+		return nil, err
+	}
+	progress := &ReconcileProgress{}
+	if err = json.Unmarshal(raw, progress); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// SetProgress sets the status.progress of obj to progress, leaving the rest of the status unchanged.
+// The status subresource of obj must be JSON-compatible with a status containing a "progress" field.
+func SetProgress(obj resource.Object, progress ReconcileProgress) error {
+	status, err := statusAsMap(obj)
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		status = make(map[string]any)
+	}
+	status[ProgressStatusField] = progress
+	raw, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	// Unmarshal into a new value of the same type as the existing status, so SetSubresource accepts it
+	var target reflect.Value
+	if existing, ok := obj.GetSubresource(string(resource.SubresourceStatus)); ok && existing != nil {
+		target = reflect.New(reflect.TypeOf(existing))
+	} else {
+		target = reflect.New(reflect.TypeOf(json.RawMessage{}))
+	}
+	if err = json.Unmarshal(raw, target.Interface()); err != nil {
+		return fmt.Errorf("unable to set progress in status: %w", err)
+	}
+	return obj.SetSubresource(string(resource.SubresourceStatus), target.Elem().Interface())
+}
+
+func statusAsMap(obj resource.Object) (map[string]any, error) {
+	existing, ok := obj.GetSubresource(string(resource.SubresourceStatus))
+	if !ok || existing == nil {
+		return nil, nil
+	}
+	raw, ok := existing.(json.RawMessage)
+	if !ok {
+		var err error
+		raw, err = json.Marshal(existing)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	status := make(map[string]any)
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// ProgressReporterConfig is the configuration for a ProgressReporter
+type ProgressReporterConfig struct {
+	// MinInterval is the minimum time between status writes. Reports made sooner than MinInterval after the last write
+	// are held until the next write, unless the phase changes or the progress reaches 100 percent. Defaults to 5 seconds.
+	MinInterval time.Duration
+}
+
+// ProgressReporter reports the progress of a long-running reconcile of an object into its status.progress,
+// throttling writes to the API server. A ProgressReporter is intended to be used for a single reconcile
+// of a single object, and is safe for concurrent use. It should be instantiated with NewProgressReporter,
+// and closed with Close when the reconcile is done, so that the last progress it held is written.
+type ProgressReporter struct {
+	client     resource.Client
+	obj        resource.Object
+	config     ProgressReporterConfig
+	mux        sync.Mutex
+	lastWrite  time.Time
+	lastPhase  string
+	pending    *ReconcileProgress
+	now        func() time.Time
+	hasWritten bool
+	closed     bool
+}
+
+// ErrProgressReporterClosed is returned by ProgressReporter.Report after the ProgressReporter is closed
+var ErrProgressReporterClosed = errors.New("progress reporter is closed")
+
+// NewProgressReporter returns a new ProgressReporter which writes progress for obj using client
+func NewProgressReporter(client resource.Client, obj resource.Object, cfg ProgressReporterConfig) (*ProgressReporter, error) {
+	if client == nil {
+		return nil, errors.New("client cannot be nil")
+	}
+	if obj == nil {
+		return nil, errors.New("obj cannot be nil")
+	}
+	if cfg.MinInterval <= 0 {
+		cfg.MinInterval = defaultProgressMinInterval
+	}
+	return &ProgressReporter{
+		client: client,
+		obj:    obj.Copy(),
+		config: cfg,
+		now:    time.Now,
+	}, nil
+}
+
+// Report records the current progress. The progress is written immediately if it is the first report,
+// the phase has changed, percent is 100, or MinInterval has passed since the last write.
+// Otherwise, it is held, and written by a later Report or Flush call.
+func (p *ProgressReporter) Report(ctx context.Context, phase string, percent int, message string) error {
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.closed {
+		return ErrProgressReporterClosed
+	}
+	now := p.now()
+	p.pending = &ReconcileProgress{
+		Phase:          phase,
+		Percent:        percent,
+		Message:        message,
+		LastUpdateTime: now.UTC(),
+	}
+	if p.hasWritten && phase == p.lastPhase && percent < 100 && now.Sub(p.lastWrite) < p.config.MinInterval {
+		return nil
+	}
+	return p.write(ctx)
+}
+
+// Flush writes any progress held by Report
+func (p *ProgressReporter) Flush(ctx context.Context) error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.pending == nil {
+		return nil
+	}
+	return p.write(ctx)
+}
+
+// Close writes any progress held by Report, and closes the ProgressReporter, so that later calls to Report
+// return ErrProgressReporterClosed. It should be called when the reconcile is done (such as with defer),
+// so that progress held by Report is not lost. Closing a closed ProgressReporter does nothing.
+func (p *ProgressReporter) Close(ctx context.Context) error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	if p.pending == nil {
+		return nil
+	}
+	return p.write(ctx)
+}
+
+// Object returns the object as of the latest progress write
+func (p *ProgressReporter) Object() resource.Object {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return p.obj.Copy()
+}
+
+func (p *ProgressReporter) write(ctx context.Context) error {
+	ctx, span := GetTracer().Start(ctx, "report-reconcile-progress")
+	defer span.End()
+	progress := *p.pending
+	if err := SetProgress(p.obj, progress); err != nil {
+		return err
+	}
+	updated, err := p.client.Update(ctx, p.obj.GetStaticMetadata().Identifier(), p.obj, resource.UpdateOptions{
+		Subresource:     string(resource.SubresourceStatus),
+		ResourceVersion: p.obj.GetResourceVersion(),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to write reconcile progress: %w", err)
+	}
+	if updated != nil {
+		p.obj = updated
+	}
+	p.pending = nil
+	p.hasWritten = true
+	p.lastPhase = progress.Phase
+	p.lastWrite = p.now()
+	return nil
+}
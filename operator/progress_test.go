@@ -0,0 +1,141 @@
+package operator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+	"github.com/grafana/grafana-app-sdk/resource/fake"
+)
+
+type testProgressStatus struct {
+	State    string             `json:"state"`
+	Progress *ReconcileProgress `json:"progress,omitempty"`
+}
+
+func TestSetProgress(t *testing.T) {
+	t.Run("typed status", func(t *testing.T) {
+		obj := &resource.TypedSpecStatusObject[string, testProgressStatus]{Status: testProgressStatus{State: "ok"}}
+		require.Nil(t, SetProgress(obj, ReconcileProgress{Phase: "Provisioning", Percent: 50}))
+		assert.Equal(t, "ok", obj.Status.State)
+		require.NotNil(t, obj.Status.Progress)
+		assert.Equal(t, "Provisioning", obj.Status.Progress.Phase)
+		assert.Equal(t, 50, obj.Status.Progress.Percent)
+	})
+
+	t.Run("untyped status", func(t *testing.T) {
+		obj := &resource.UntypedObject{}
+		got, err := GetProgress(obj)
+		require.Nil(t, err)
+		assert.Nil(t, got)
+		require.Nil(t, SetProgress(obj, ReconcileProgress{Phase: "Provisioning", Percent: 10, Message: "creating volume"}))
+		got, err = GetProgress(obj)
+		require.Nil(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, "Provisioning", got.Phase)
+		assert.Equal(t, 10, got.Percent)
+		assert.Equal(t, "creating volume", got.Message)
+	})
+}
+
+func TestProgressReporter_Report(t *testing.T) {
+	client, obj := newTestProgressClient(t)
+	reporter, err := NewProgressReporter(client, obj, ProgressReporterConfig{MinInterval: time.Minute})
+	require.Nil(t, err)
+	now := time.Now()
+	reporter.now = func() time.Time { return now }
+
+	// The first report is always written
+	require.Nil(t, reporter.Report(context.Background(), "Provisioning", 10, ""))
+	assert.Len(t, testProgressUpdates(client), 1)
+	// Reports in the same phase inside MinInterval are held
+	require.Nil(t, reporter.Report(context.Background(), "Provisioning", 20, ""))
+	require.Nil(t, reporter.Report(context.Background(), "Provisioning", 30, ""))
+	assert.Len(t, testProgressUpdates(client), 1)
+	// A phase change is written immediately
+	require.Nil(t, reporter.Report(context.Background(), "Configuring", 40, ""))
+	assert.Len(t, testProgressUpdates(client), 2)
+	// Reports after MinInterval are written
+	now = now.Add(time.Minute)
+	require.Nil(t, reporter.Report(context.Background(), "Configuring", 60, ""))
+	assert.Len(t, testProgressUpdates(client), 3)
+	// Completion is written immediately
+	require.Nil(t, reporter.Report(context.Background(), "Configuring", 100, "done"))
+	require.Len(t, testProgressUpdates(client), 4)
+
+	last := testProgressUpdates(client)[3].Options.(resource.UpdateOptions)
+	assert.Equal(t, string(resource.SubresourceStatus), last.Subresource)
+	assert.Equal(t, "4", last.ResourceVersion)
+	progress, err := GetProgress(reporter.Object())
+	require.Nil(t, err)
+	assert.Equal(t, &ReconcileProgress{Phase: "Configuring", Percent: 100, Message: "done", LastUpdateTime: now.UTC()}, progress)
+}
+
+func TestProgressReporter_Flush(t *testing.T) {
+	client, obj := newTestProgressClient(t)
+	reporter, err := NewProgressReporter(client, obj, ProgressReporterConfig{MinInterval: time.Minute})
+	require.Nil(t, err)
+
+	// Nothing is written if nothing is pending
+	require.Nil(t, reporter.Flush(context.Background()))
+	assert.Len(t, testProgressUpdates(client), 0)
+	require.Nil(t, reporter.Report(context.Background(), "Provisioning", 10, ""))
+	require.Nil(t, reporter.Report(context.Background(), "Provisioning", 20, ""))
+	assert.Len(t, testProgressUpdates(client), 1)
+	require.Nil(t, reporter.Flush(context.Background()))
+	require.Len(t, testProgressUpdates(client), 2)
+	progress, err := GetProgress(testProgressUpdates(client)[1].Object)
+	require.Nil(t, err)
+	assert.Equal(t, 20, progress.Percent)
+	require.Nil(t, reporter.Flush(context.Background()))
+	assert.Len(t, testProgressUpdates(client), 2)
+}
+
+func TestProgressReporter_Close(t *testing.T) {
+	client, obj := newTestProgressClient(t)
+	reporter, err := NewProgressReporter(client, obj, ProgressReporterConfig{MinInterval: time.Minute})
+	require.Nil(t, err)
+
+	require.Nil(t, reporter.Report(context.Background(), "Provisioning", 10, ""))
+	require.Nil(t, reporter.Report(context.Background(), "Provisioning", 90, ""))
+	assert.Len(t, testProgressUpdates(client), 1)
+	// Close writes the held progress
+	require.Nil(t, reporter.Close(context.Background()))
+	require.Len(t, testProgressUpdates(client), 2)
+	progress, err := GetProgress(testProgressUpdates(client)[1].Object)
+	require.Nil(t, err)
+	assert.Equal(t, 90, progress.Percent)
+	// Reports after Close are rejected, and closing again does nothing
+	assert.ErrorIs(t, reporter.Report(context.Background(), "Provisioning", 95, ""), ErrProgressReporterClosed)
+	require.Nil(t, reporter.Close(context.Background()))
+	assert.Len(t, testProgressUpdates(client), 2)
+}
+
+// newTestProgressClient returns a fake.Client for objects with a testProgressStatus, and the object "foo" stored in it
+func newTestProgressClient(t *testing.T) (*fake.Client, resource.Object) {
+	t.Helper()
+	kind := resource.Kind{
+		Schema: resource.NewSimpleSchema("test.grafana.app", "v1", &resource.TypedSpecStatusObject[string, testProgressStatus]{},
+			&resource.TypedList[*resource.TypedSpecStatusObject[string, testProgressStatus]]{}, resource.WithKind("Foo"), resource.WithScope(resource.ClusterScope)),
+		Codecs: map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()},
+	}
+	obj := &resource.TypedSpecStatusObject[string, testProgressStatus]{}
+	obj.SetName("foo")
+	client := newTestFakeClient(t, kind, obj)
+	return client, getTestObject(t, client, resource.Identifier{Name: "foo"})
+}
+
+// testProgressUpdates returns the update actions made to client
+func testProgressUpdates(client *fake.Client) []fake.Action {
+	updates := make([]fake.Action, 0)
+	for _, action := range client.Actions() {
+		if action.Verb == fake.VerbUpdate {
+			updates = append(updates, action)
+		}
+	}
+	return updates
+}
@@ -0,0 +1,554 @@
+package operator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/metrics"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+const (
+	// ProvisioningImporterLabel is the label set on objects imported by a ProvisioningImporter, with the importer's name as the value
+	ProvisioningImporterLabel = "provisioning.grafana.app/importer"
+	// ProvisioningSourceAnnotation is the annotation set on objects imported by a ProvisioningImporter,
+	// with the provisioning file and index of the entry the object was imported from, such as "datasources.yaml[0]"
+	ProvisioningSourceAnnotation = "provisioning.grafana.app/source"
+	// ProvisioningHashAnnotation is the annotation set on objects imported by a ProvisioningImporter,
+	// with a hash of the spec the object was imported with, which is used to tell changes to the provisioning files
+	// apart from changes made to the object in the API server.
+	ProvisioningHashAnnotation = "provisioning.grafana.app/hash"
+
+	// provisioningFileVersion is the apiVersion of classic Grafana provisioning files
+	provisioningFileVersion = 1
+)
+
+var _ app.Runnable = &ProvisioningImporter{}
+
+// ProvisioningEntry is a single entry in a list in a classic Grafana provisioning file
+type ProvisioningEntry struct {
+	// File is the path of the file the entry was read from, relative to the provisioning directory
+	File string
+	// Index is the index of the entry in the file's list
+	Index int
+	// Values are the decoded contents of the entry
+	Values map[string]any
+}
+
+// Source returns the file and index of the entry, in the format used by ProvisioningSourceAnnotation
+func (e ProvisioningEntry) Source() string {
+	return fmt.Sprintf("%s[%d]", e.File, e.Index)
+}
+
+// ProvisioningTransformFunc maps a classic provisioning entry to an object of the importer's kind.
+// The returned object must have a name, and a namespace if the kind is namespaced.
+// A nil object (and nil error) skips the entry.
+type ProvisioningTransformFunc func(ctx context.Context, entry ProvisioningEntry) (resource.Object, error)
+
+// ProvisioningExportFunc maps an object to a classic provisioning entry, for ExportProvisioning.
+// A nil entry (and nil error) leaves the object out of the export.
+type ProvisioningExportFunc func(ctx context.Context, object resource.Object) (map[string]any, error)
+
+// ReadProvisioningEntries reads the entries in the list listKey (such as "datasources") of every YAML file
+// (with a .yaml or .yml extension) in dir of fsys, and of its subdirectories.
+// Files are read in lexical order, and files which don't contain listKey are ignored.
+func ReadProvisioningEntries(fsys fs.FS, dir, listKey string) ([]ProvisioningEntry, error) {
+	entries := make([]ProvisioningEntry, 0)
+	err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ext := path.Ext(p); d.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			return nil
+		}
+		contents, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		file := make(map[string]any)
+		if err = yaml.Unmarshal(contents, &file); err != nil {
+			return fmt.Errorf("unable to parse provisioning file %s: %w", p, err)
+		}
+		list, ok := file[listKey]
+		if !ok || list == nil {
+			return nil
+		}
+		items, ok := list.([]any)
+		if !ok {
+			return fmt.Errorf("provisioning file %s: %s must be a list", p, listKey)
+		}
+		rel := p
+		if dir != "." {
+			rel = p[len(dir)+1:]
+		}
+		for i, item := range items {
+			values, ok := item.(map[string]any)
+			if !ok {
+				return fmt.Errorf("provisioning file %s: %s[%d] must be an object", p, listKey, i)
+			}
+			entries = append(entries, ProvisioningEntry{
+				File:   rel,
+				Index:  i,
+				Values: values,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ExportProvisioning lists the objects in namespace with client, and returns a classic Grafana provisioning file
+// (as YAML) with an entry in the list listKey for each object, as returned by export.
+// Use resource.NamespaceAll to export the objects in every namespace.
+func ExportProvisioning(ctx context.Context, client resource.Client, namespace, listKey string, export ProvisioningExportFunc) ([]byte, error) {
+	if export == nil {
+		return nil, fmt.Errorf("export cannot be nil")
+	}
+	items := make([]map[string]any, 0)
+	iter := resource.NewListIterator(client, namespace, resource.ListOptions{})
+	for iter.Next(ctx) {
+		entry, err := export(ctx, iter.Object())
+		if err != nil {
+			return nil, fmt.Errorf("unable to export %s/%s: %w", iter.Object().GetNamespace(), iter.Object().GetName(), err)
+		}
+		if entry != nil {
+			items = append(items, entry)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(map[string]any{
+		"apiVersion": provisioningFileVersion,
+		listKey:      items,
+	})
+}
+
+// ProvisioningDriftPolicy determines how a ProvisioningImporter handles imported objects which were changed in the API server
+type ProvisioningDriftPolicy string
+
+const (
+	// ProvisioningDriftPolicyOverwrite updates drifted objects to match their provisioning entry
+	ProvisioningDriftPolicyOverwrite = ProvisioningDriftPolicy("overwrite")
+	// ProvisioningDriftPolicyReport only reports drifted objects, leaving them as they are in the API server
+	ProvisioningDriftPolicyReport = ProvisioningDriftPolicy("report")
+)
+
+// ProvisioningResult is the result of a ProvisioningImporter sync for a single object
+type ProvisioningResult string
+
+const (
+	// ProvisioningResultCreated means the object did not exist and was created
+	ProvisioningResultCreated = ProvisioningResult("created")
+	// ProvisioningResultUpdated means the object was updated, because its provisioning entry changed,
+	// or the object drifted and the drift policy is ProvisioningDriftPolicyOverwrite
+	ProvisioningResultUpdated = ProvisioningResult("updated")
+	// ProvisioningResultUnchanged means the object already matched its provisioning entry
+	ProvisioningResultUnchanged = ProvisioningResult("unchanged")
+	// ProvisioningResultDrifted means the object was changed in the API server, and was left as-is
+	// because the drift policy is ProvisioningDriftPolicyReport
+	ProvisioningResultDrifted = ProvisioningResult("drifted")
+	// ProvisioningResultConflict means an object with the same identifier exists, but was not imported by the importer,
+	// so it was left as-is
+	ProvisioningResultConflict = ProvisioningResult("conflict")
+	// ProvisioningResultRemoved means the object was imported, but its provisioning entry no longer exists,
+	// and it was left as-is because ProvisioningImporterConfig.DeleteRemoved is false
+	ProvisioningResultRemoved = ProvisioningResult("removed")
+	// ProvisioningResultDeleted means the object was imported, but its provisioning entry no longer exists, so it was deleted
+	ProvisioningResultDeleted = ProvisioningResult("deleted")
+	// ProvisioningResultSkipped means the transform skipped the provisioning entry
+	ProvisioningResultSkipped = ProvisioningResult("skipped")
+	// ProvisioningResultFailed means the entry could not be transformed, or the object could not be written
+	ProvisioningResultFailed = ProvisioningResult("failed")
+)
+
+// ProvisioningObjectResult is the result of a ProvisioningImporter sync for a single provisioning entry or imported object
+type ProvisioningObjectResult struct {
+	// Source is the source of the provisioning entry (see ProvisioningEntry.Source), or the ProvisioningSourceAnnotation
+	// of the object for removed and deleted objects
+	Source     string
+	Identifier resource.Identifier
+	Result     ProvisioningResult
+	// Drifted is true if the object was changed in the API server since it was imported
+	Drifted bool
+	// Error is the error for a ProvisioningResultFailed result
+	Error error
+}
+
+// ProvisioningReport is the report of a single ProvisioningImporter sync
+type ProvisioningReport struct {
+	// Time is the time the sync started
+	Time time.Time
+	// Objects are the results for each provisioning entry, in the order they were read,
+	// followed by the results for removed objects.
+	Objects []ProvisioningObjectResult
+}
+
+// Count returns the number of objects in the report with result
+func (r *ProvisioningReport) Count(result ProvisioningResult) int {
+	count := 0
+	for _, obj := range r.Objects {
+		if obj.Result == result {
+			count++
+		}
+	}
+	return count
+}
+
+// Drifted returns the results for objects which were changed in the API server since they were imported
+func (r *ProvisioningReport) Drifted() []ProvisioningObjectResult {
+	drifted := make([]ProvisioningObjectResult, 0)
+	for _, obj := range r.Objects {
+		if obj.Drifted {
+			drifted = append(drifted, obj)
+		}
+	}
+	return drifted
+}
+
+// ProvisioningImporterConfig is the configuration for a ProvisioningImporter
+type ProvisioningImporterConfig struct {
+	// Name uniquely identifies the importer. It is used as the value of the ProvisioningImporterLabel on imported objects,
+	// so it must be a valid label value of 63 characters or fewer.
+	Name string
+	// Filesystem contains the provisioning files. If nil, it defaults to os.DirFS(".").
+	Filesystem fs.FS
+	// Path is the directory of the provisioning files in Filesystem. Defaults to ".".
+	Path string
+	// ListKey is the key of the list of entries to import in each provisioning file, such as "datasources"
+	ListKey string
+	// Transform maps each provisioning entry to an object
+	Transform ProvisioningTransformFunc
+	// Interval is how often the provisioning files are read again and synced. If zero, the importer syncs once.
+	Interval time.Duration
+	// DriftPolicy determines how objects which were changed in the API server are handled.
+	// Defaults to ProvisioningDriftPolicyOverwrite.
+	DriftPolicy ProvisioningDriftPolicy
+	// DeleteRemoved deletes imported objects whose provisioning entries no longer exist.
+	// If false, they are only reported as ProvisioningResultRemoved. Objects imported from a file with an entry
+	// which failed to import are never removed, as the failed entry may be theirs.
+	DeleteRemoved bool
+	// MetricsConfig is the configuration for the importer's metrics
+	MetricsConfig metrics.Config
+}
+
+// ProvisioningImporter imports objects from classic Grafana provisioning files (YAML files on disk), to ease the
+// migration of an app's configuration to its kinds. Each entry in the configured list of each file is mapped
+// to an object with a ProvisioningTransformFunc, which is created or updated to match the entry.
+// The import is one-way: the provisioning files are the source of truth, and imported objects which are changed
+// in the API server are reported as drifted, and overwritten or left as-is according to the ProvisioningDriftPolicy.
+// Drift is detected by comparing the spec of the object with the spec from the transform,
+// so the transform should set any fields which the API server would default.
+//
+// ProvisioningImporter implements app.Runnable, so it can be run alongside an app's other runners.
+// ProvisioningImporter contains unexported fields, and must be created with NewProvisioningImporter.
+type ProvisioningImporter struct {
+	config     ProvisioningImporterConfig
+	client     resource.Client
+	lastReport *ProvisioningReport
+	reportMux  sync.RWMutex
+	objects    *prometheus.CounterVec
+}
+
+// NewProvisioningImporter creates a new ProvisioningImporter which writes imported objects with client
+func NewProvisioningImporter(client resource.Client, cfg ProvisioningImporterConfig) (*ProvisioningImporter, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+	if cfg.Transform == nil {
+		return nil, fmt.Errorf("transform cannot be nil")
+	}
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("name cannot be empty")
+	}
+	if len(cfg.Name) > 63 {
+		return nil, fmt.Errorf("name length cannot exceed 63 chars: %s", cfg.Name)
+	}
+	if cfg.ListKey == "" {
+		return nil, fmt.Errorf("list key cannot be empty")
+	}
+	if cfg.Filesystem == nil {
+		cfg.Filesystem = os.DirFS(".")
+	}
+	if cfg.Path == "" {
+		cfg.Path = "."
+	}
+	switch cfg.DriftPolicy {
+	case "":
+		cfg.DriftPolicy = ProvisioningDriftPolicyOverwrite
+	case ProvisioningDriftPolicyOverwrite, ProvisioningDriftPolicyReport:
+	default:
+		return nil, fmt.Errorf("invalid drift policy: %s", cfg.DriftPolicy)
+	}
+	return &ProvisioningImporter{
+		config: cfg,
+		client: client,
+		objects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.MetricsConfig.Namespace,
+			Subsystem: "provisioning",
+			Name:      "objects_total",
+			Help:      "Total number of provisioning entries and imported objects handled by a provisioning importer sync, by result.",
+		}, []string{"importer", "result"}),
+	}, nil
+}
+
+// Run syncs the provisioning files, and then syncs them again every ProvisioningImporterConfig.Interval until ctx is canceled.
+// If Interval is zero, Run returns after the first sync, with an error if it failed or any entry failed to import.
+// Otherwise, errors are logged and the next sync is attempted at the next interval.
+func (p *ProvisioningImporter) Run(ctx context.Context) error {
+	logger := logging.FromContext(ctx).With("component", "ProvisioningImporter", "importer", p.config.Name)
+	report, err := p.Sync(ctx)
+	if p.config.Interval <= 0 {
+		if err == nil && report.Count(ProvisioningResultFailed) > 0 {
+			err = fmt.Errorf("provisioning importer '%s' failed to import %d entries", p.config.Name, report.Count(ProvisioningResultFailed))
+		}
+		return err
+	}
+	if err != nil {
+		logger.Error("Provisioning sync failed", "error", err)
+	}
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if _, err = p.Sync(ctx); err != nil && ctx.Err() == nil {
+				logger.Error("Provisioning sync failed", "error", err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Sync reads the provisioning files, and creates, updates, or deletes objects to match them.
+// It returns an error if the provisioning files can't be read or the imported objects can't be listed.
+// Errors for individual entries are reported as ProvisioningResultFailed results in the returned ProvisioningReport.
+func (p *ProvisioningImporter) Sync(ctx context.Context) (*ProvisioningReport, error) {
+	logger := logging.FromContext(ctx).With("component", "ProvisioningImporter", "importer", p.config.Name)
+	report := &ProvisioningReport{
+		Time:    time.Now(),
+		Objects: make([]ProvisioningObjectResult, 0),
+	}
+	entries, err := ReadProvisioningEntries(p.config.Filesystem, p.config.Path, p.config.ListKey)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[resource.Identifier]struct{})
+	// failedFiles contains the files with an entry which couldn't be imported
+	failedFiles := make(map[string]struct{})
+	for _, entry := range entries {
+		res := p.importEntry(ctx, entry, seen)
+		if res.Result == ProvisioningResultFailed {
+			failedFiles[entry.File] = struct{}{}
+			logger.Error("Unable to import provisioning entry", "source", res.Source, "error", res.Error)
+		} else if res.Drifted {
+			logger.Warn("Imported object was changed since it was imported", "source", res.Source,
+				"namespace", res.Identifier.Namespace, "name", res.Identifier.Name, "result", res.Result)
+		}
+		report.Objects = append(report.Objects, res)
+	}
+
+	// Find imported objects whose entries have been removed
+	iter := resource.NewListIterator(p.client, resource.NamespaceAll, resource.ListOptions{
+		LabelFilters: []string{fmt.Sprintf("%s=%s", ProvisioningImporterLabel, p.config.Name)},
+	})
+	for iter.Next(ctx) {
+		obj := iter.Object()
+		identifier := resource.Identifier{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+		if _, ok := seen[identifier]; ok {
+			continue
+		}
+		if _, ok := failedFiles[provisioningSourceFile(obj.GetAnnotations()[ProvisioningSourceAnnotation])]; ok {
+			// The object's entry may still exist but have failed to import. Entries can move within a file,
+			// so the index in the source annotation can't tell which entry failed, and no object from the file is removed.
+			continue
+		}
+		res := ProvisioningObjectResult{
+			Source:     obj.GetAnnotations()[ProvisioningSourceAnnotation],
+			Identifier: identifier,
+			Result:     ProvisioningResultRemoved,
+		}
+		if p.config.DeleteRemoved {
+			res.Result = ProvisioningResultDeleted
+			if err = p.client.Delete(ctx, identifier, resource.DeleteOptions{}); err != nil && !resource.IsNotFound(err) {
+				res.Result = ProvisioningResultFailed
+				res.Error = err
+				logger.Error("Unable to delete object removed from provisioning", "namespace", identifier.Namespace, "name", identifier.Name, "error", err)
+			}
+		}
+		report.Objects = append(report.Objects, res)
+	}
+	if err = iter.Err(); err != nil {
+		return nil, fmt.Errorf("unable to list imported objects: %w", err)
+	}
+
+	for _, res := range report.Objects {
+		p.objects.WithLabelValues(p.config.Name, string(res.Result)).Inc()
+	}
+	p.reportMux.Lock()
+	p.lastReport = report
+	p.reportMux.Unlock()
+	return report, nil
+}
+
+// LastReport returns the report of the most recent successful Sync, or nil if there hasn't been one
+func (p *ProvisioningImporter) LastReport() *ProvisioningReport {
+	p.reportMux.RLock()
+	defer p.reportMux.RUnlock()
+	return p.lastReport
+}
+
+// PrometheusCollectors returns the prometheus metric collectors used by the importer
+func (p *ProvisioningImporter) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{p.objects}
+}
+
+// importEntry transforms entry and creates or updates the resulting object
+//
+//nolint:funlen
+func (p *ProvisioningImporter) importEntry(ctx context.Context, entry ProvisioningEntry, seen map[resource.Identifier]struct{}) ProvisioningObjectResult {
+	res := ProvisioningObjectResult{
+		Source: entry.Source(),
+	}
+	fail := func(err error) ProvisioningObjectResult {
+		res.Result = ProvisioningResultFailed
+		res.Error = err
+		return res
+	}
+	obj, err := p.config.Transform(ctx, entry)
+	if err != nil {
+		return fail(fmt.Errorf("transform failed: %w", err))
+	}
+	if obj == nil {
+		res.Result = ProvisioningResultSkipped
+		return res
+	}
+	if obj.GetName() == "" {
+		return fail(errors.New("transformed object has no name"))
+	}
+	res.Identifier = resource.Identifier{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	if _, ok := seen[res.Identifier]; ok {
+		return fail(fmt.Errorf("another provisioning entry was transformed into %s/%s", res.Identifier.Namespace, res.Identifier.Name))
+	}
+	seen[res.Identifier] = struct{}{}
+	hash, err := specHash(obj)
+	if err != nil {
+		return fail(err)
+	}
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[ProvisioningImporterLabel] = p.config.Name
+	obj.SetLabels(labels)
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[ProvisioningSourceAnnotation] = res.Source
+	annotations[ProvisioningHashAnnotation] = hash
+	obj.SetAnnotations(annotations)
+
+	existing, err := p.client.Get(ctx, res.Identifier)
+	if err != nil && !resource.IsNotFound(err) {
+		return fail(err)
+	}
+	if err != nil {
+		if _, err = p.client.Create(ctx, res.Identifier, obj, resource.CreateOptions{}); err != nil {
+			return fail(err)
+		}
+		res.Result = ProvisioningResultCreated
+		return res
+	}
+	if existing.GetLabels()[ProvisioningImporterLabel] != p.config.Name {
+		res.Result = ProvisioningResultConflict
+		return res
+	}
+	existingHash, err := specHash(existing)
+	if err != nil {
+		return fail(err)
+	}
+	// If the spec doesn't match the hash it was imported with, the object was changed in the API server
+	res.Drifted = existingHash != existing.GetAnnotations()[ProvisioningHashAnnotation]
+	switch {
+	case existingHash == hash && existing.GetAnnotations()[ProvisioningHashAnnotation] == hash:
+		res.Result = ProvisioningResultUnchanged
+	case res.Drifted && p.config.DriftPolicy == ProvisioningDriftPolicyReport:
+		res.Result = ProvisioningResultDrifted
+	}
+	if res.Result != "" {
+		// The object isn't updated, but its entry may have moved, so keep its source annotation up to date
+		if existing.GetAnnotations()[ProvisioningSourceAnnotation] != res.Source {
+			if err = p.setSource(ctx, res.Identifier, res.Source); err != nil {
+				return fail(err)
+			}
+		}
+		return res
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	if _, err = p.client.Update(ctx, res.Identifier, obj, resource.UpdateOptions{
+		ResourceVersion: existing.GetResourceVersion(),
+	}); err != nil {
+		return fail(err)
+	}
+	res.Result = ProvisioningResultUpdated
+	return res
+}
+
+// setSource sets the ProvisioningSourceAnnotation of the object with identifier to source, with a merge patch
+func (p *ProvisioningImporter) setSource(ctx context.Context, identifier resource.Identifier, source string) error {
+	body, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{
+				ProvisioningSourceAnnotation: source,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err = p.client.Patch(ctx, identifier, resource.PatchRequest{
+		Type: resource.PatchTypeMergePatch,
+		Body: body,
+	}, resource.PatchOptions{}); err != nil {
+		return fmt.Errorf("unable to update source annotation: %w", err)
+	}
+	return nil
+}
+
+// provisioningSourceFile returns the file of a ProvisioningSourceAnnotation value
+func provisioningSourceFile(source string) string {
+	if idx := strings.LastIndex(source, "["); idx >= 0 {
+		return source[:idx]
+	}
+	return source
+}
+
+// specHash returns a hash of the JSON encoding of the spec of obj
+func specHash(obj resource.Object) (string, error) {
+	spec, err := json.Marshal(obj.GetSpec())
+	if err != nil {
+		return "", fmt.Errorf("unable to encode spec: %w", err)
+	}
+	sum := sha256.Sum256(spec)
+	return hex.EncodeToString(sum[:16]), nil
+}
@@ -0,0 +1,237 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestReadProvisioningEntries(t *testing.T) {
+	fsys := fstest.MapFS{
+		"provisioning/datasources/a.yaml": &fstest.MapFile{Data: []byte(`apiVersion: 1
+datasources:
+  - name: foo
+    type: prometheus
+  - name: bar
+deleteDatasources:
+  - name: old`)},
+		"provisioning/datasources/nested/b.yml": &fstest.MapFile{Data: []byte(`apiVersion: 1
+datasources:
+  - name: foobar`)},
+		"provisioning/datasources/c.yaml":    &fstest.MapFile{Data: []byte(`apiVersion: 1`)},
+		"provisioning/datasources/README.md": &fstest.MapFile{Data: []byte(`not yaml: [`)},
+	}
+	entries, err := ReadProvisioningEntries(fsys, "provisioning/datasources", "datasources")
+	require.Nil(t, err)
+	assert.Equal(t, []ProvisioningEntry{{
+		File:   "a.yaml",
+		Index:  0,
+		Values: map[string]any{"name": "foo", "type": "prometheus"},
+	}, {
+		File:   "a.yaml",
+		Index:  1,
+		Values: map[string]any{"name": "bar"},
+	}, {
+		File:   "nested/b.yml",
+		Index:  0,
+		Values: map[string]any{"name": "foobar"},
+	}}, entries)
+	assert.Equal(t, "nested/b.yml[0]", entries[2].Source())
+
+	t.Run("invalid list", func(t *testing.T) {
+		_, err := ReadProvisioningEntries(fstest.MapFS{
+			"a.yaml": &fstest.MapFile{Data: []byte(`datasources: foo`)},
+		}, ".", "datasources")
+		assert.Equal(t, errors.New("provisioning file a.yaml: datasources must be a list"), err)
+	})
+}
+
+func TestNewProvisioningImporter(t *testing.T) {
+	transform := func(context.Context, ProvisioningEntry) (resource.Object, error) {
+		return nil, nil
+	}
+	_, err := NewProvisioningImporter(nil, ProvisioningImporterConfig{})
+	assert.Equal(t, errors.New("client cannot be nil"), err)
+	_, err = NewProvisioningImporter(newTestFakeClient(t, newTestProvisioningKind(resource.NamespacedScope)), ProvisioningImporterConfig{})
+	assert.Equal(t, errors.New("transform cannot be nil"), err)
+	_, err = NewProvisioningImporter(newTestFakeClient(t, newTestProvisioningKind(resource.NamespacedScope)), ProvisioningImporterConfig{Transform: transform})
+	assert.Equal(t, errors.New("name cannot be empty"), err)
+	_, err = NewProvisioningImporter(newTestFakeClient(t, newTestProvisioningKind(resource.NamespacedScope)), ProvisioningImporterConfig{Transform: transform, Name: "foo"})
+	assert.Equal(t, errors.New("list key cannot be empty"), err)
+	_, err = NewProvisioningImporter(newTestFakeClient(t, newTestProvisioningKind(resource.NamespacedScope)), ProvisioningImporterConfig{
+		Transform:   transform,
+		Name:        "foo",
+		ListKey:     "datasources",
+		DriftPolicy: "foo",
+	})
+	assert.Equal(t, errors.New("invalid drift policy: foo"), err)
+}
+
+func TestProvisioningImporter_Sync(t *testing.T) {
+	fsys := fstest.MapFS{
+		"datasources.yaml": &fstest.MapFile{Data: []byte(`apiVersion: 1
+datasources:
+  - name: foo
+    url: http://foo
+  - name: bar
+    url: http://bar
+  - name: skipped
+  - name: bad`)},
+	}
+	transform := func(_ context.Context, entry ProvisioningEntry) (resource.Object, error) {
+		name, _ := entry.Values["name"].(string)
+		switch name {
+		case "skipped":
+			return nil, nil
+		case "bad":
+			return nil, errors.New("I AM ERROR")
+		}
+		obj := &resource.TypedSpecObject[map[string]any]{
+			Spec: map[string]any{"url": entry.Values["url"]},
+		}
+		obj.SetNamespace("default")
+		obj.SetName(name)
+		return obj, nil
+	}
+	// An object with the same name, which was not imported
+	existing := &resource.TypedSpecObject[map[string]any]{Spec: map[string]any{"url": "http://other"}}
+	existing.SetNamespace("default")
+	existing.SetName("bar")
+	client := newTestFakeClient(t, newTestProvisioningKind(resource.NamespacedScope), existing)
+	fooIdentifier := resource.Identifier{Namespace: "default", Name: "foo"}
+
+	importer, err := NewProvisioningImporter(client, ProvisioningImporterConfig{
+		Name:          "datasources",
+		Filesystem:    fsys,
+		ListKey:       "datasources",
+		Transform:     transform,
+		DriftPolicy:   ProvisioningDriftPolicyReport,
+		DeleteRemoved: true,
+	})
+	require.Nil(t, err)
+
+	report, err := importer.Sync(context.Background())
+	require.Nil(t, err)
+	require.Len(t, report.Objects, 4)
+	assert.Equal(t, ProvisioningResultCreated, report.Objects[0].Result)
+	assert.Equal(t, "datasources.yaml[0]", report.Objects[0].Source)
+	assert.Equal(t, ProvisioningResultConflict, report.Objects[1].Result)
+	assert.Equal(t, ProvisioningResultSkipped, report.Objects[2].Result)
+	assert.Equal(t, ProvisioningResultFailed, report.Objects[3].Result)
+	assert.Equal(t, report, importer.LastReport())
+	foo := getTestObject(t, client, fooIdentifier)
+	assert.Equal(t, "datasources", foo.GetLabels()[ProvisioningImporterLabel])
+	assert.Equal(t, "datasources.yaml[0]", foo.GetAnnotations()[ProvisioningSourceAnnotation])
+
+	// Syncing again doesn't change anything
+	report, err = importer.Sync(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, ProvisioningResultUnchanged, report.Objects[0].Result)
+	assert.Empty(t, report.Drifted())
+
+	// A change in the API server is reported as drift, and left as-is with the report policy
+	foo.GetSpec().(map[string]any)["url"] = "http://changed"
+	_, err = client.Update(context.Background(), fooIdentifier, foo, resource.UpdateOptions{})
+	require.Nil(t, err)
+	report, err = importer.Sync(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, ProvisioningResultDrifted, report.Objects[0].Result)
+	assert.Equal(t, []ProvisioningObjectResult{report.Objects[0]}, report.Drifted())
+	assert.Equal(t, "http://changed", getTestObject(t, client, fooIdentifier).GetSpec().(map[string]any)["url"])
+
+	// With the overwrite policy, the drift is corrected
+	importer.config.DriftPolicy = ProvisioningDriftPolicyOverwrite
+	report, err = importer.Sync(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, ProvisioningResultUpdated, report.Objects[0].Result)
+	assert.True(t, report.Objects[0].Drifted)
+	assert.Equal(t, "http://foo", getTestObject(t, client, fooIdentifier).GetSpec().(map[string]any)["url"])
+
+	// A change to the provisioning file updates the object, without drift
+	fsys["datasources.yaml"].Data = []byte(`apiVersion: 1
+datasources:
+  - name: foo
+    url: http://foo2
+  - name: bad`)
+	report, err = importer.Sync(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, ProvisioningResultUpdated, report.Objects[0].Result)
+	assert.False(t, report.Objects[0].Drifted)
+
+	// Moving the entry within the file updates the object's source
+	fsys["datasources.yaml"].Data = []byte(`apiVersion: 1
+datasources:
+  - name: bad
+  - name: foo
+    url: http://foo2`)
+	report, err = importer.Sync(context.Background())
+	require.Nil(t, err)
+	require.Len(t, report.Objects, 2)
+	assert.Equal(t, ProvisioningResultUnchanged, report.Objects[1].Result)
+	assert.Equal(t, "datasources.yaml[1]", getTestObject(t, client, fooIdentifier).GetAnnotations()[ProvisioningSourceAnnotation])
+
+	// An object whose entry fails to import isn't deleted, even if the entry moved
+	fsys["datasources.yaml"].Data = []byte(`apiVersion: 1
+datasources:
+  - name: bad`)
+	report, err = importer.Sync(context.Background())
+	require.Nil(t, err)
+	require.Len(t, report.Objects, 1)
+	assert.Equal(t, ProvisioningResultFailed, report.Objects[0].Result)
+	_, err = client.Get(context.Background(), fooIdentifier)
+	assert.Nil(t, err)
+
+	// Removing the entry deletes the object
+	fsys["datasources.yaml"].Data = []byte(`apiVersion: 1
+datasources: []`)
+	report, err = importer.Sync(context.Background())
+	require.Nil(t, err)
+	require.Len(t, report.Objects, 1)
+	assert.Equal(t, ProvisioningResultDeleted, report.Objects[0].Result)
+	assert.Equal(t, "datasources.yaml[1]", report.Objects[0].Source)
+	_, err = client.Get(context.Background(), fooIdentifier)
+	assert.True(t, resource.IsNotFound(err))
+	// The object which was not imported is never deleted
+	_, err = client.Get(context.Background(), resource.Identifier{Namespace: "default", Name: "bar"})
+	assert.Nil(t, err)
+}
+
+func TestExportProvisioning(t *testing.T) {
+	objects := make([]resource.Object, 0, 2)
+	for _, name := range []string{"foo", "bar"} {
+		obj := &resource.TypedSpecObject[map[string]any]{Spec: map[string]any{"url": "http://" + name}}
+		obj.SetName(name)
+		objects = append(objects, obj)
+	}
+	client := newTestFakeClient(t, newTestProvisioningKind(resource.ClusterScope), objects...)
+	out, err := ExportProvisioning(context.Background(), client, resource.NamespaceAll, "datasources", func(_ context.Context, obj resource.Object) (map[string]any, error) {
+		return map[string]any{
+			"name": obj.GetName(),
+			"url":  obj.GetSpec().(map[string]any)["url"],
+		}, nil
+	})
+	require.Nil(t, err)
+	file := make(map[string]any)
+	require.Nil(t, yaml.Unmarshal(out, &file))
+	assert.Equal(t, 1, file["apiVersion"])
+	assert.ElementsMatch(t, []any{
+		map[string]any{"name": "foo", "url": "http://foo"},
+		map[string]any{"name": "bar", "url": "http://bar"},
+	}, file["datasources"])
+}
+
+// newTestProvisioningKind returns a kind of objects with untyped specs, like those used by the tests' transforms
+func newTestProvisioningKind(scope resource.SchemaScope) resource.Kind {
+	return resource.Kind{
+		Schema: resource.NewSimpleSchema("test.grafana.app", "v1", &resource.TypedSpecObject[map[string]any]{},
+			&resource.TypedList[*resource.TypedSpecObject[map[string]any]]{}, resource.WithKind("Datasource"), resource.WithScope(scope)),
+		Codecs: map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()},
+	}
+}
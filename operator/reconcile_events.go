@@ -0,0 +1,209 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ReconcileOutcomeSuccess is the outcome of a reconcile which returned no error and did not request a requeue
+	ReconcileOutcomeSuccess = "success"
+	// ReconcileOutcomeRequeue is the outcome of a reconcile which returned a ReconcileResult with RequeueAfter set
+	ReconcileOutcomeRequeue = "requeue"
+	// ReconcileOutcomeError is the outcome of a reconcile which returned an error
+	ReconcileOutcomeError = "error"
+)
+
+// ReconcileEvent describes a single call to a Reconciler made by an InformerController,
+// including calls for retries and requeues.
+type ReconcileEvent struct {
+	// Time is the time the reconcile started
+	Time      time.Time `json:"time" yaml:"time"`
+	Kind      string    `json:"kind" yaml:"kind"`
+	Namespace string    `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Name      string    `json:"name" yaml:"name"`
+	// Action is the action which triggered the reconcile, such as "CREATE" or "RESYNC"
+	Action string `json:"action" yaml:"action"`
+	// Retry is true if the reconcile was a retry or requeue of an earlier reconcile
+	Retry bool `json:"retry,omitempty" yaml:"retry,omitempty"`
+	// Outcome is one of ReconcileOutcomeSuccess, ReconcileOutcomeRequeue, or ReconcileOutcomeError
+	Outcome      string         `json:"outcome" yaml:"outcome"`
+	Duration     time.Duration  `json:"duration" yaml:"duration"`
+	Error        string         `json:"error,omitempty" yaml:"error,omitempty"`
+	RequeueAfter *time.Duration `json:"requeueAfter,omitempty" yaml:"requeueAfter,omitempty"`
+}
+
+// ReconcileEventFilter filters the ReconcileEvents received by a subscriber of a ReconcileEventStream.
+// Empty fields match all events.
+type ReconcileEventFilter struct {
+	// Kind matches the kind name of the event, ignoring case
+	Kind      string
+	Namespace string
+	Name      string
+	// ErrorsOnly only matches events with the ReconcileOutcomeError outcome
+	ErrorsOnly bool
+}
+
+// Matches returns true if the filter matches evt
+func (f ReconcileEventFilter) Matches(evt ReconcileEvent) bool {
+	if f.Kind != "" && !strings.EqualFold(f.Kind, evt.Kind) {
+		return false
+	}
+	if f.Namespace != "" && f.Namespace != evt.Namespace {
+		return false
+	}
+	if f.Name != "" && f.Name != evt.Name {
+		return false
+	}
+	if f.ErrorsOnly && evt.Outcome != ReconcileOutcomeError {
+		return false
+	}
+	return true
+}
+
+// ReconcileEventStream broadcasts ReconcileEvents to any number of subscribers, for live debugging of an operator.
+// Events are not stored, so subscribers only receive events published while they are subscribed.
+// Publishing never blocks: events are dropped for subscribers which don't keep up.
+// ReconcileEventStream should be created with NewReconcileEventStream.
+type ReconcileEventStream struct {
+	mux         sync.RWMutex
+	subscribers map[*reconcileEventSubscriber]struct{}
+}
+
+type reconcileEventSubscriber struct {
+	filter ReconcileEventFilter
+	events chan ReconcileEvent
+}
+
+// NewReconcileEventStream returns a new ReconcileEventStream with no subscribers
+func NewReconcileEventStream() *ReconcileEventStream {
+	return &ReconcileEventStream{
+		subscribers: make(map[*reconcileEventSubscriber]struct{}),
+	}
+}
+
+var defaultReconcileEventStream = NewReconcileEventStream()
+
+// DefaultReconcileEventStream returns the ReconcileEventStream used by InformerControllers
+// which don't set InformerControllerConfig.ReconcileEvents, and served by a Runner with RunnerDebugConfig.Enabled.
+func DefaultReconcileEventStream() *ReconcileEventStream {
+	return defaultReconcileEventStream
+}
+
+// Publish sends evt to every subscriber whose filter matches it
+func (s *ReconcileEventStream) Publish(evt ReconcileEvent) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	for sub := range s.subscribers {
+		if !sub.filter.Matches(evt) {
+			continue
+		}
+		select {
+		case sub.events <- evt:
+		default:
+			// Drop the event rather than block the reconcile
+		}
+	}
+}
+
+// Subscribe returns a channel which receives published events matching filter, until ctx is canceled,
+// at which point the channel is closed. bufferSize is the number of events which can be held for the subscriber
+// before events are dropped. If bufferSize is less than 1, it defaults to 100.
+func (s *ReconcileEventStream) Subscribe(ctx context.Context, filter ReconcileEventFilter, bufferSize int) <-chan ReconcileEvent {
+	if bufferSize < 1 {
+		bufferSize = 100
+	}
+	sub := &reconcileEventSubscriber{
+		filter: filter,
+		events: make(chan ReconcileEvent, bufferSize),
+	}
+	s.mux.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.mux.Unlock()
+	go func() {
+		<-ctx.Done()
+		s.mux.Lock()
+		delete(s.subscribers, sub)
+		s.mux.Unlock()
+		close(sub.events)
+	}()
+	return sub.events
+}
+
+// ServeHTTP streams published events to the client as newline-delimited JSON until the request is canceled.
+// Events are filtered by the "kind", "namespace", "name", and "errorsOnly" query parameters (see ReconcileEventFilter).
+func (s *ReconcileEventStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	filter := ReconcileEventFilter{
+		Kind:      query.Get("kind"),
+		Namespace: query.Get("namespace"),
+		Name:      query.Get("name"),
+	}
+	if errorsOnly := query.Get("errorsOnly"); errorsOnly != "" {
+		var err error
+		if filter.ErrorsOnly, err = strconv.ParseBool(errorsOnly); err != nil {
+			http.Error(w, "invalid errorsOnly value: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	if flusher != nil {
+		flusher.Flush()
+	}
+	enc := json.NewEncoder(w)
+	for evt := range s.Subscribe(r.Context(), filter, 0) {
+		if err := enc.Encode(evt); err != nil {
+			// The client has disconnected, wait for the request context to be canceled
+			continue
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// newReconcileEvent creates a ReconcileEvent for a call to a Reconciler with req, which started at start
+func newReconcileEvent(req ReconcileRequest, start time.Time, res ReconcileResult, err error, retry bool) ReconcileEvent {
+	evt := ReconcileEvent{
+		Time:         start,
+		Action:       reconcileActionName(req.Action),
+		Retry:        retry,
+		Outcome:      ReconcileOutcomeSuccess,
+		Duration:     time.Since(start),
+		RequeueAfter: res.RequeueAfter,
+	}
+	if req.Object != nil {
+		evt.Kind = req.Object.GetStaticMetadata().Kind
+		evt.Namespace = req.Object.GetNamespace()
+		evt.Name = req.Object.GetName()
+	}
+	if res.RequeueAfter != nil {
+		evt.Outcome = ReconcileOutcomeRequeue
+	}
+	if err != nil {
+		evt.Outcome = ReconcileOutcomeError
+		evt.Error = err.Error()
+	}
+	return evt
+}
+
+func reconcileActionName(action ReconcileAction) string {
+	if action == ReconcileActionResynced {
+		return "RESYNC"
+	}
+	if resourceAction := ResourceActionFromReconcileAction(action); resourceAction != "" {
+		return string(resourceAction)
+	}
+	return "UNKNOWN"
+}
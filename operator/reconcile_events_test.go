@@ -0,0 +1,148 @@
+package operator
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestReconcileEventFilter_Matches(t *testing.T) {
+	evt := ReconcileEvent{
+		Kind:      "Foo",
+		Namespace: "bar",
+		Name:      "foobar",
+		Outcome:   ReconcileOutcomeSuccess,
+	}
+	assert.True(t, ReconcileEventFilter{}.Matches(evt))
+	assert.True(t, ReconcileEventFilter{Kind: "foo", Namespace: "bar", Name: "foobar"}.Matches(evt))
+	assert.False(t, ReconcileEventFilter{Kind: "Bar"}.Matches(evt))
+	assert.False(t, ReconcileEventFilter{Namespace: "foo"}.Matches(evt))
+	assert.False(t, ReconcileEventFilter{Name: "foo"}.Matches(evt))
+	assert.False(t, ReconcileEventFilter{ErrorsOnly: true}.Matches(evt))
+	evt.Outcome = ReconcileOutcomeError
+	assert.True(t, ReconcileEventFilter{ErrorsOnly: true}.Matches(evt))
+}
+
+func TestReconcileEventStream_Subscribe(t *testing.T) {
+	stream := NewReconcileEventStream()
+	ctx, cancel := context.WithCancel(context.Background())
+	all := stream.Subscribe(ctx, ReconcileEventFilter{}, 1)
+	bar := stream.Subscribe(ctx, ReconcileEventFilter{Namespace: "bar"}, 10)
+
+	stream.Publish(ReconcileEvent{Name: "a", Namespace: "foo"})
+	// Dropped for all, as its buffer is full
+	stream.Publish(ReconcileEvent{Name: "b", Namespace: "bar"})
+	assert.Equal(t, "a", (<-all).Name)
+	assert.Equal(t, "b", (<-bar).Name)
+
+	cancel()
+	// Channels are closed once the context is canceled
+	for range all {
+		assert.Fail(t, "unexpected event")
+	}
+	for range bar {
+		assert.Fail(t, "unexpected event")
+	}
+	// Publishing with no subscribers doesn't block
+	stream.Publish(ReconcileEvent{Name: "c"})
+}
+
+func TestReconcileEventStream_ServeHTTP(t *testing.T) {
+	stream := NewReconcileEventStream()
+	server := httptest.NewServer(stream)
+	defer server.Close()
+
+	t.Run("bad request", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "?errorsOnly=foo")
+		require.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"?kind=Foo&errorsOnly=true", nil)
+		require.Nil(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+		// The response headers are flushed after subscribing, so events published now are received
+		stream.Publish(ReconcileEvent{Kind: "Foo", Name: "ok", Outcome: ReconcileOutcomeSuccess})
+		stream.Publish(ReconcileEvent{Kind: "Bar", Name: "other", Outcome: ReconcileOutcomeError})
+		stream.Publish(ReconcileEvent{Kind: "Foo", Name: "failed", Outcome: ReconcileOutcomeError, Error: "I AM ERROR"})
+		scanner := bufio.NewScanner(resp.Body)
+		require.True(t, scanner.Scan())
+		evt := ReconcileEvent{}
+		require.Nil(t, json.Unmarshal(scanner.Bytes(), &evt))
+		assert.Equal(t, "failed", evt.Name)
+		assert.Equal(t, "I AM ERROR", evt.Error)
+	})
+}
+
+func TestInformerController_ReconcileEvents(t *testing.T) {
+	stream := NewReconcileEventStream()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := stream.Subscribe(ctx, ReconcileEventFilter{}, 10)
+
+	inf := &testInformer{}
+	c := NewInformerController(InformerControllerConfig{
+		ReconcileEvents: stream,
+	})
+	c.RetryPolicy = func(err error, attempt int) (bool, time.Duration) {
+		return attempt < 1, time.Millisecond * 50
+	}
+	c.retryTickerInterval = time.Millisecond * 50
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	calls := 0
+	c.AddReconciler(&SimpleReconciler{
+		ReconcileFunc: func(context.Context, ReconcileRequest) (ReconcileResult, error) {
+			defer wg.Done()
+			calls++
+			if calls == 1 {
+				return ReconcileResult{}, errors.New("I AM ERROR")
+			}
+			return ReconcileResult{}, nil
+		},
+	}, "foo")
+	c.AddInformer(inf, "foo")
+	go c.Run(ctx)
+
+	obj := &resource.TypedSpecObject[string]{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "bar",
+			Name:      "foobar",
+		},
+	}
+	obj.SetGroupVersionKind(obj.GroupVersionKind().GroupVersion().WithKind("Foo"))
+	inf.FireAdd(context.Background(), obj)
+	wg.Wait()
+
+	evt := <-events
+	assert.Equal(t, "Foo", evt.Kind)
+	assert.Equal(t, "bar", evt.Namespace)
+	assert.Equal(t, "foobar", evt.Name)
+	assert.Equal(t, "CREATE", evt.Action)
+	assert.Equal(t, ReconcileOutcomeError, evt.Outcome)
+	assert.Equal(t, "I AM ERROR", evt.Error)
+	assert.False(t, evt.Retry)
+	evt = <-events
+	assert.Equal(t, ReconcileOutcomeSuccess, evt.Outcome)
+	assert.True(t, evt.Retry)
+}
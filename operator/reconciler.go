@@ -2,11 +2,15 @@ package operator
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/utils/strings/slices"
 
 	"github.com/grafana/grafana-app-sdk/logging"
@@ -59,6 +63,9 @@ type ReconcileRequest struct {
 	// and will only be non-nil if a prior Reconcile call with this ReconcileRequest returned a State
 	// in its ReconcileResult alongside either a RequeueAfter or an error.
 	State map[string]any
+	// Related contains objects related to Object (such as its owners or referenced objects), keyed by the resolver
+	// which resolved them. It is only populated when the Reconciler is wrapped by an EnrichingReconciler.
+	Related map[string][]resource.Object
 }
 
 // ReconcileResult is the status of a successful Reconcile action.
@@ -118,7 +125,10 @@ func ResourceActionFromReconcileAction(action ReconcileAction) ResourceAction {
 
 // NewOpinionatedReconciler creates a new OpinionatedReconciler.
 // To have the new OpinionatedReconciler wrap an existing reconciler,
-// set the `OpinionatedReconciler.Reconciler` value or use `OpinionatedReconciler.Wrap()`
+// set the `OpinionatedReconciler.Reconciler` value or use `OpinionatedReconciler.Wrap()`.
+// The returned OpinionatedReconciler uses the finalizer as its FieldManager.
+// The client should support server-side apply patches. If it rejects them with a 415 Unsupported Media Type error,
+// the finalizer is added and removed with JSON patches instead.
 func NewOpinionatedReconciler(client PatchClient, finalizer string) (*OpinionatedReconciler, error) {
 	if client == nil {
 		return nil, fmt.Errorf("client cannot be nil")
@@ -130,18 +140,25 @@ func NewOpinionatedReconciler(client PatchClient, finalizer string) (*Opinionate
 		return nil, fmt.Errorf("finalizer length cannot exceed 63 chars: %s", finalizer)
 	}
 	return &OpinionatedReconciler{
-		finalizer: finalizer,
-		client:    client,
+		FieldManager: finalizer,
+		finalizer:    finalizer,
+		client:       client,
 	}, nil
 }
 
 // OpinionatedReconciler wraps an ordinary Reconciler with finalizer-based logic to convert "Created" events into
 // "resync" events on start-up when the reconciler has handled the "created" event on a previous run,
 // and ensures that "delete" events are not missed during reconciler down-time by using the finalizer.
+//
+// The finalizer is added and removed with server-side apply patches which only contain the finalizer,
+// so concurrent changes to the object's metadata by other controllers (including their own finalizers) are preserved.
 type OpinionatedReconciler struct {
 	Reconciler Reconciler
-	finalizer  string
-	client     PatchClient
+	// FieldManager is the field manager used for the server-side apply patches which add and remove the finalizer.
+	// It should be unique to this reconciler, as the finalizer is owned by the field manager. If empty, the finalizer is used.
+	FieldManager string
+	finalizer    string
+	client       PatchClient
 }
 
 const (
@@ -185,13 +202,7 @@ func (o *OpinionatedReconciler) Reconcile(ctx context.Context, request Reconcile
 
 		// Attach the finalizer on success
 		logger.Debug("Downstream reconcile succeeded, adding finalizer", "finalizer", o.finalizer)
-		patchErr := o.client.PatchInto(ctx, request.Object.GetStaticMetadata().Identifier(), resource.PatchRequest{
-			Operations: []resource.PatchOperation{{
-				Operation: resource.PatchOpAdd,
-				Path:      "/metadata/finalizers",
-				Value:     []string{o.finalizer},
-			}},
-		}, resource.PatchOptions{}, request.Object)
+		patchErr := o.addFinalizer(ctx, request.Object)
 		if patchErr != nil {
 			span.SetStatus(codes.Error, fmt.Sprintf("error adding finalizer: %s", patchErr.Error()))
 			if resp.State == nil {
@@ -229,14 +240,9 @@ func (o *OpinionatedReconciler) Reconcile(ctx context.Context, request Reconcile
 			logger.Debug("Retry of an update which added a deletionTimestamp, downstream reconciler already successfully processed delete, need to retry removing the finalizer", "patchError", request.State[opinionatedReconcilerPatchRemoveStateKey])
 		}
 		logger.Debug("Removing finalizer from object", "finalizer", o.finalizer)
-		patchErr := o.client.PatchInto(ctx, request.Object.GetStaticMetadata().Identifier(), resource.PatchRequest{
-			Operations: []resource.PatchOperation{{
-				Operation: resource.PatchOpRemove,
-				Path:      fmt.Sprintf("/metadata/finalizers/%d", slices.Index(request.Object.GetFinalizers(), o.finalizer)),
-			}},
-		}, resource.PatchOptions{}, request.Object)
+		patchErr := o.removeFinalizer(ctx, request.Object)
 		if patchErr != nil {
-			span.SetStatus(codes.Error, fmt.Sprintf("error adding finalizer: %s", patchErr.Error()))
+			span.SetStatus(codes.Error, fmt.Sprintf("error removing finalizer: %s", patchErr.Error()))
 			if res.State == nil {
 				res.State = make(map[string]any)
 			}
@@ -251,18 +257,124 @@ func (o *OpinionatedReconciler) Reconcile(ctx context.Context, request Reconcile
 	if request.Action == ReconcileActionUpdated && !slices.Contains(request.Object.GetFinalizers(), o.finalizer) {
 		// Add the finalizer, don't delegate, let the reconcile action for adding the finalizer propagate down to avoid confusing extra reconciliations
 		logger.Debug("Missing finalizer in object, adding (this will trigger a new reconcile event)", "finalizer", o.finalizer)
-		patchErr := o.client.PatchInto(ctx, request.Object.GetStaticMetadata().Identifier(), resource.PatchRequest{
-			Operations: []resource.PatchOperation{{
-				Operation: resource.PatchOpAdd,
-				Path:      "/metadata/finalizers",
-				Value:     []string{o.finalizer},
-			}},
-		}, resource.PatchOptions{}, request.Object)
+		patchErr := o.addFinalizer(ctx, request.Object)
 		return ReconcileResult{}, patchErr
 	}
 	return o.wrappedReconcile(ctx, request)
 }
 
+// addFinalizer adds the finalizer to the object with a server-side apply patch, updating object in-place.
+// If the client doesn't support apply patches, the finalizer is added with a JSON patch instead.
+func (o *OpinionatedReconciler) addFinalizer(ctx context.Context, object resource.Object) error {
+	err := o.applyFinalizers(ctx, object, []string{o.finalizer})
+	if !isUnsupportedPatchError(err) {
+		return err
+	}
+	logging.FromContext(ctx).Debug("Client does not support apply patches, adding the finalizer with a JSON patch", "finalizer", o.finalizer, "error", err)
+	op := resource.PatchOperation{
+		Operation: resource.PatchOpAdd,
+		Path:      "/metadata/finalizers/-",
+		Value:     o.finalizer,
+	}
+	if len(object.GetFinalizers()) == 0 {
+		op = resource.PatchOperation{
+			Operation: resource.PatchOpAdd,
+			Path:      "/metadata/finalizers",
+			Value:     []string{o.finalizer},
+		}
+	}
+	return o.client.PatchInto(ctx, object.GetStaticMetadata().Identifier(), resource.PatchRequest{
+		Operations: []resource.PatchOperation{op},
+	}, resource.PatchOptions{}, object)
+}
+
+// removeFinalizer removes the finalizer from the object, updating object in-place.
+// Applying a patch without the finalizer only removes it if it is owned by the field manager, so if the finalizer
+// is still present after the apply (because it was added by another field manager, or with a non-apply patch),
+// it is removed with a JSON patch which first tests the finalizer's index, so it fails rather than removing
+// the wrong finalizer if the list has been concurrently changed. If the client doesn't support apply patches,
+// only the JSON patch is used.
+func (o *OpinionatedReconciler) removeFinalizer(ctx context.Context, object resource.Object) error {
+	err := o.applyFinalizers(ctx, object, nil)
+	if err != nil && !isUnsupportedPatchError(err) {
+		return err
+	}
+	idx := slices.Index(object.GetFinalizers(), o.finalizer)
+	if idx < 0 {
+		return nil
+	}
+	if err != nil {
+		logging.FromContext(ctx).Debug("Client does not support apply patches, removing the finalizer with a JSON patch", "finalizer", o.finalizer, "error", err)
+	} else {
+		logging.FromContext(ctx).Debug("Finalizer is not owned by the field manager, removing it with a JSON patch", "finalizer", o.finalizer, "fieldManager", o.fieldManager())
+	}
+	path := fmt.Sprintf("/metadata/finalizers/%d", idx)
+	return o.client.PatchInto(ctx, object.GetStaticMetadata().Identifier(), resource.PatchRequest{
+		Operations: []resource.PatchOperation{{
+			Operation: resource.PatchOpTest,
+			Path:      path,
+			Value:     o.finalizer,
+		}, {
+			Operation: resource.PatchOpRemove,
+			Path:      path,
+		}},
+	}, resource.PatchOptions{}, object)
+}
+
+// applyFinalizers makes a server-side apply patch which sets the finalizers owned by the field manager to finalizers
+func (o *OpinionatedReconciler) applyFinalizers(ctx context.Context, object resource.Object, finalizers []string) error {
+	gvk := object.GroupVersionKind()
+	body, err := json.Marshal(finalizerApplyConfiguration{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Metadata: finalizerApplyMetadata{
+			Name:       object.GetName(),
+			Namespace:  object.GetNamespace(),
+			Finalizers: finalizers,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return o.client.PatchInto(ctx, object.GetStaticMetadata().Identifier(), resource.PatchRequest{
+		Type: resource.PatchTypeApply,
+		Body: body,
+	}, resource.PatchOptions{
+		FieldManager: o.fieldManager(),
+	}, object)
+}
+
+// isUnsupportedPatchError returns true if err is a 415 Unsupported Media Type error,
+// which is returned by the API server (and should be returned by other clients) for unsupported patch types
+func isUnsupportedPatchError(err error) bool {
+	var respErr resource.APIServerResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode() == http.StatusUnsupportedMediaType
+	}
+	return apierrors.IsUnsupportedMediaType(err)
+}
+
+func (o *OpinionatedReconciler) fieldManager() string {
+	if o.FieldManager != "" {
+		return o.FieldManager
+	}
+	return o.finalizer
+}
+
+// finalizerApplyConfiguration is the server-side apply configuration used by OpinionatedReconciler,
+// which contains only the identifying fields of the object and the finalizers owned by the reconciler
+type finalizerApplyConfiguration struct {
+	APIVersion string                 `json:"apiVersion"`
+	Kind       string                 `json:"kind"`
+	Metadata   finalizerApplyMetadata `json:"metadata"`
+}
+
+type finalizerApplyMetadata struct {
+	Name       string   `json:"name"`
+	Namespace  string   `json:"namespace,omitempty"`
+	Finalizers []string `json:"finalizers,omitempty"`
+}
+
 func (o *OpinionatedReconciler) wrappedReconcile(ctx context.Context, request ReconcileRequest) (ReconcileResult, error) {
 	if o.Reconciler != nil {
 		return o.Reconciler.Reconcile(ctx, request)
@@ -307,6 +419,9 @@ type TypedReconcileRequest[T resource.Object] struct {
 	// and will only be non-nil if a prior Reconcile call with this TypedReconcileRequest returned a State
 	// in its ReconcileResult alongside either a RequeueAfter or an error.
 	State map[string]any
+	// Related contains objects related to Object (such as its owners or referenced objects), keyed by the resolver
+	// which resolved them. It is only populated when the Reconciler is wrapped by an EnrichingReconciler.
+	Related map[string][]resource.Object
 }
 
 // TypedReconciler is a variant of SimpleReconciler in which a user can specify the underlying type of the resource.Object
@@ -318,7 +433,7 @@ type TypedReconciler[T resource.Object] struct {
 }
 
 // Reconcile tries to cast the Object in ReconcileRequest into the T-typed resource.Object,
-// then creates a TypedReconcileRequest with the cast object and the same Action and State,
+// then creates a TypedReconcileRequest with the cast object and the same Action, State, and Related objects,
 // which is passed to ReconcileFunc. If the Object cannot be cast, it returns an empty
 // ReconcileResult with an error of type *CannotCastError. If ReconcileFunc is nil,
 // it returns an empty ReconcileResult with a nil error.
@@ -331,9 +446,10 @@ func (t *TypedReconciler[T]) Reconcile(ctx context.Context, request ReconcileReq
 		return ReconcileResult{}, NewCannotCastError(request.Object.GetStaticMetadata())
 	}
 	return t.ReconcileFunc(ctx, TypedReconcileRequest[T]{
-		Action: request.Action,
-		Object: cast,
-		State:  request.State,
+		Action:  request.Action,
+		Object:  cast,
+		State:   request.State,
+		Related: request.Related,
 	})
 }
 
@@ -2,15 +2,21 @@ package operator
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"net/http"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-app-sdk/k8s"
 	"github.com/grafana/grafana-app-sdk/resource"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/strings/slices"
 )
 
 func TestNewOpinionatedReconciler(t *testing.T) {
@@ -61,13 +67,8 @@ func TestOpinionatedReconciler_Reconcile(t *testing.T) {
 		op, err := NewOpinionatedReconciler(&mockPatchClient{
 			PatchIntoFunc: func(c context.Context, identifier resource.Identifier, request resource.PatchRequest, options resource.PatchOptions, object resource.Object) error {
 				assert.Equal(t, req.Object.GetStaticMetadata().Identifier(), identifier)
-				assert.Equal(t, resource.PatchRequest{
-					Operations: []resource.PatchOperation{{
-						Path:      "/metadata/finalizers",
-						Operation: resource.PatchOpAdd,
-						Value:     []string{finalizer},
-					}},
-				}, request)
+				assert.Equal(t, testFinalizerApplyPatch(t, req.Object, finalizer), request)
+				assert.Equal(t, resource.PatchOptions{FieldManager: finalizer}, options)
 				patchCalled = true
 				return nil
 			},
@@ -138,13 +139,8 @@ func TestOpinionatedReconciler_Reconcile(t *testing.T) {
 		op, err := NewOpinionatedReconciler(&mockPatchClient{
 			PatchIntoFunc: func(c context.Context, identifier resource.Identifier, request resource.PatchRequest, options resource.PatchOptions, object resource.Object) error {
 				assert.Equal(t, req.Object.GetStaticMetadata().Identifier(), identifier)
-				assert.Equal(t, resource.PatchRequest{
-					Operations: []resource.PatchOperation{{
-						Path:      "/metadata/finalizers",
-						Operation: resource.PatchOpAdd,
-						Value:     []string{finalizer},
-					}},
-				}, request)
+				assert.Equal(t, testFinalizerApplyPatch(t, req.Object, finalizer), request)
+				assert.Equal(t, resource.PatchOptions{FieldManager: finalizer}, options)
 				patchCalled = true
 				return patchErr
 			},
@@ -183,13 +179,8 @@ func TestOpinionatedReconciler_Reconcile(t *testing.T) {
 		op, err := NewOpinionatedReconciler(&mockPatchClient{
 			PatchIntoFunc: func(c context.Context, identifier resource.Identifier, request resource.PatchRequest, options resource.PatchOptions, object resource.Object) error {
 				assert.Equal(t, req.Object.GetStaticMetadata().Identifier(), identifier)
-				assert.Equal(t, resource.PatchRequest{
-					Operations: []resource.PatchOperation{{
-						Path:      "/metadata/finalizers",
-						Operation: resource.PatchOpAdd,
-						Value:     []string{finalizer},
-					}},
-				}, request)
+				assert.Equal(t, testFinalizerApplyPatch(t, req.Object, finalizer), request)
+				assert.Equal(t, resource.PatchOptions{FieldManager: finalizer}, options)
 				patchCalled = true
 				return nil
 			},
@@ -294,13 +285,8 @@ func TestOpinionatedReconciler_Reconcile(t *testing.T) {
 		op, err := NewOpinionatedReconciler(&mockPatchClient{
 			PatchIntoFunc: func(c context.Context, identifier resource.Identifier, request resource.PatchRequest, options resource.PatchOptions, object resource.Object) error {
 				assert.Equal(t, req.Object.GetStaticMetadata().Identifier(), identifier)
-				assert.Equal(t, resource.PatchRequest{
-					Operations: []resource.PatchOperation{{
-						Path:      "/metadata/finalizers",
-						Operation: resource.PatchOpAdd,
-						Value:     []string{finalizer},
-					}},
-				}, request)
+				assert.Equal(t, testFinalizerApplyPatch(t, req.Object, finalizer), request)
+				assert.Equal(t, resource.PatchOptions{FieldManager: finalizer}, options)
 				patchCalled = true
 				return nil
 			},
@@ -337,13 +323,10 @@ func TestOpinionatedReconciler_Reconcile(t *testing.T) {
 		op, err := NewOpinionatedReconciler(&mockPatchClient{
 			PatchIntoFunc: func(c context.Context, identifier resource.Identifier, request resource.PatchRequest, options resource.PatchOptions, object resource.Object) error {
 				assert.Equal(t, req.Object.GetStaticMetadata().Identifier(), identifier)
-				assert.Equal(t, resource.PatchRequest{
-					Operations: []resource.PatchOperation{{
-						Path:      "/metadata/finalizers/0",
-						Operation: resource.PatchOpRemove,
-						Value:     nil,
-					}},
-				}, request)
+				assert.Equal(t, testFinalizerApplyPatch(t, req.Object), request)
+				assert.Equal(t, resource.PatchOptions{FieldManager: finalizer}, options)
+				// The finalizer is owned by the field manager, so the apply patch removes it
+				object.SetFinalizers(nil)
 				patchCalled = true
 				return nil
 			},
@@ -422,13 +405,7 @@ func TestOpinionatedReconciler_Reconcile(t *testing.T) {
 		op, err := NewOpinionatedReconciler(&mockPatchClient{
 			PatchIntoFunc: func(c context.Context, identifier resource.Identifier, request resource.PatchRequest, options resource.PatchOptions, object resource.Object) error {
 				assert.Equal(t, req.Object.GetStaticMetadata().Identifier(), identifier)
-				assert.Equal(t, resource.PatchRequest{
-					Operations: []resource.PatchOperation{{
-						Path:      "/metadata/finalizers/0",
-						Operation: resource.PatchOpRemove,
-						Value:     nil,
-					}},
-				}, request)
+				assert.Equal(t, testFinalizerApplyPatch(t, req.Object), request)
 				return patchErr
 			},
 		}, finalizer)
@@ -508,6 +485,158 @@ func TestOpinionatedReconciler_Reconcile(t *testing.T) {
 	})
 }
 
+func TestOpinionatedReconciler_FieldManagers(t *testing.T) {
+	finalizer := "finalizer"
+	deleted := metav1.NewTime(time.Now())
+	gvk := schema.GroupVersionKind{Group: "foo.bar", Version: "v1", Kind: "Foo"}
+	newObject := func(finalizers []string, deletionTimestamp *metav1.Time) resource.Object {
+		obj := &resource.TypedSpecObject[int]{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "foo",
+				Namespace:         "default",
+				Finalizers:        finalizers,
+				DeletionTimestamp: deletionTimestamp,
+			},
+		}
+		obj.SetGroupVersionKind(gvk)
+		return obj
+	}
+
+	t.Run("add and remove alongside other field managers", func(t *testing.T) {
+		client := newTestApplyClient()
+		// Another controller adds its own finalizer and an annotation after our (now stale) copy of the object was received
+		client.update("other-controller", func(meta *testApplyObjectMeta) {
+			meta.finalizers = append(meta.finalizers, "other")
+			meta.annotations["other"] = "value"
+		})
+		op, err := NewOpinionatedReconciler(client, finalizer)
+		require.Nil(t, err)
+		req := ReconcileRequest{
+			Action: ReconcileActionCreated,
+			Object: newObject(nil, nil),
+		}
+		_, err = op.Reconcile(context.Background(), req)
+		require.Nil(t, err)
+		assert.Equal(t, []string{"other", finalizer}, client.meta.finalizers)
+		assert.Equal(t, map[string]string{"other": "value"}, client.meta.annotations)
+		assert.Equal(t, []string{"other", finalizer}, req.Object.GetFinalizers())
+
+		req = ReconcileRequest{
+			Action: ReconcileActionUpdated,
+			Object: newObject([]string{"other", finalizer}, &deleted),
+		}
+		_, err = op.Reconcile(context.Background(), req)
+		require.Nil(t, err)
+		assert.Equal(t, []string{"other"}, client.meta.finalizers)
+		assert.Equal(t, map[string]string{"other": "value"}, client.meta.annotations)
+		assert.Equal(t, map[string][]string{"other": {"other-controller"}}, client.meta.owners)
+		assert.Equal(t, []string{"apply:finalizer", "apply:finalizer"}, client.calls)
+	})
+
+	t.Run("finalizer shared with another field manager", func(t *testing.T) {
+		client := newTestApplyClient()
+		op, err := NewOpinionatedReconciler(client, finalizer)
+		require.Nil(t, err)
+		req := ReconcileRequest{
+			Action: ReconcileActionUpdated,
+			Object: newObject(nil, nil),
+		}
+		_, err = op.Reconcile(context.Background(), req)
+		require.Nil(t, err)
+		// Another applier also claims our finalizer, which should not remove it from our ownership
+		client.update("other-controller", func(meta *testApplyObjectMeta) {
+			meta.annotations["other"] = "value"
+		})
+		client.meta.owners[finalizer] = append(client.meta.owners[finalizer], "other-controller")
+
+		req = ReconcileRequest{
+			Action: ReconcileActionUpdated,
+			Object: newObject([]string{finalizer}, &deleted),
+		}
+		_, err = op.Reconcile(context.Background(), req)
+		require.Nil(t, err)
+		// The apply only releases our ownership, so the finalizer is removed with a guarded JSON patch
+		assert.Equal(t, []string{}, client.meta.finalizers)
+		assert.Equal(t, map[string]string{"other": "value"}, client.meta.annotations)
+		assert.Equal(t, []string{"apply:finalizer", "apply:finalizer", "json"}, client.calls)
+	})
+
+	t.Run("finalizer added without apply", func(t *testing.T) {
+		client := newTestApplyClient()
+		client.update("before-upgrade", func(meta *testApplyObjectMeta) {
+			meta.finalizers = append(meta.finalizers, "other", finalizer)
+		})
+		op, err := NewOpinionatedReconciler(client, finalizer)
+		require.Nil(t, err)
+		req := ReconcileRequest{
+			Action: ReconcileActionUpdated,
+			Object: newObject([]string{"other", finalizer}, &deleted),
+		}
+		_, err = op.Reconcile(context.Background(), req)
+		require.Nil(t, err)
+		assert.Equal(t, []string{"other"}, client.meta.finalizers)
+		assert.Equal(t, []string{"apply:finalizer", "json"}, client.calls)
+	})
+
+	t.Run("custom field manager", func(t *testing.T) {
+		client := newTestApplyClient()
+		op, err := NewOpinionatedReconciler(client, finalizer)
+		require.Nil(t, err)
+		op.FieldManager = "my-operator"
+		_, err = op.Reconcile(context.Background(), ReconcileRequest{
+			Action: ReconcileActionUpdated,
+			Object: newObject(nil, nil),
+		})
+		require.Nil(t, err)
+		assert.Equal(t, map[string][]string{finalizer: {"my-operator"}}, client.meta.owners)
+		assert.Equal(t, []string{"apply:my-operator"}, client.calls)
+	})
+
+	t.Run("client without apply support", func(t *testing.T) {
+		client := newTestApplyClient()
+		client.jsonOnly = true
+		client.update("other-controller", func(meta *testApplyObjectMeta) {
+			meta.finalizers = append(meta.finalizers, "other")
+		})
+		op, err := NewOpinionatedReconciler(client, finalizer)
+		require.Nil(t, err)
+		req := ReconcileRequest{
+			Action: ReconcileActionUpdated,
+			Object: newObject([]string{"other"}, nil),
+		}
+		_, err = op.Reconcile(context.Background(), req)
+		require.Nil(t, err)
+		assert.Equal(t, []string{"other", finalizer}, client.meta.finalizers)
+		assert.Equal(t, []string{"other", finalizer}, req.Object.GetFinalizers())
+
+		req = ReconcileRequest{
+			Action: ReconcileActionUpdated,
+			Object: newObject([]string{"other", finalizer}, &deleted),
+		}
+		_, err = op.Reconcile(context.Background(), req)
+		require.Nil(t, err)
+		assert.Equal(t, []string{"other"}, client.meta.finalizers)
+		assert.Equal(t, []string{"apply:finalizer", "json", "apply:finalizer", "json"}, client.calls)
+	})
+
+	t.Run("apply error", func(t *testing.T) {
+		op, err := NewOpinionatedReconciler(&mockPatchClient{
+			PatchIntoFunc: func(context.Context, resource.Identifier, resource.PatchRequest, resource.PatchOptions, resource.Object) error {
+				return k8s.NewServerResponseError(errors.New("conflict"), http.StatusConflict)
+			},
+		}, finalizer)
+		require.Nil(t, err)
+		_, err = op.Reconcile(context.Background(), ReconcileRequest{
+			Action: ReconcileActionUpdated,
+			Object: newObject(nil, nil),
+		})
+		// Only unsupported patch type errors fall back to JSON patches
+		var respErr resource.APIServerResponseError
+		require.ErrorAs(t, err, &respErr)
+		assert.Equal(t, http.StatusConflict, respErr.StatusCode())
+	})
+}
+
 func TestOpinionatedReconciler_Wrap(t *testing.T) {
 	rr := ReconcileResult{
 		State: map[string]any{
@@ -579,6 +708,37 @@ func TestTypedReconciler_Reconcile(t *testing.T) {
 		assert.Equal(t, result, res)
 	})
 
+	t.Run("related objects", func(t *testing.T) {
+		owner := &resource.TypedSpecObject[string]{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "owner",
+				Namespace: "bar",
+			},
+		}
+		called := false
+		r := &TypedReconciler[*resource.TypedSpecObject[string]]{
+			ReconcileFunc: func(_ context.Context, request TypedReconcileRequest[*resource.TypedSpecObject[string]]) (ReconcileResult, error) {
+				called = true
+				assert.Equal(t, map[string][]resource.Object{
+					RelatedOwners: {owner},
+				}, request.Related)
+				return ReconcileResult{}, nil
+			},
+		}
+		enriching, err := NewEnrichingReconciler(r, map[string]RelatedObjectResolver{
+			RelatedOwners: RelatedObjectResolverFunc(func(context.Context, resource.Object) ([]resource.Object, error) {
+				return []resource.Object{owner}, nil
+			}),
+		})
+		require.Nil(t, err)
+		_, err = enriching.Reconcile(context.Background(), ReconcileRequest{
+			Action: ReconcileActionCreated,
+			Object: &resource.TypedSpecObject[string]{},
+		})
+		assert.Nil(t, err)
+		assert.True(t, called)
+	})
+
 	t.Run("wrong type", func(t *testing.T) {
 		r := TypedReconciler[*resource.TypedSpecObject[string]]{}
 		obj := &resource.TypedSpecObject[int]{
@@ -651,3 +811,129 @@ func TestSimpleReconciler_Reconcile(t *testing.T) {
 		assert.Equal(t, result, res)
 	})
 }
+
+func testFinalizerApplyPatch(t *testing.T, obj resource.Object, finalizers ...string) resource.PatchRequest {
+	body, err := json.Marshal(finalizerApplyConfiguration{
+		APIVersion: obj.GroupVersionKind().GroupVersion().String(),
+		Kind:       obj.GroupVersionKind().Kind,
+		Metadata: finalizerApplyMetadata{
+			Name:       obj.GetName(),
+			Namespace:  obj.GetNamespace(),
+			Finalizers: finalizers,
+		},
+	})
+	require.Nil(t, err)
+	return resource.PatchRequest{
+		Type: resource.PatchTypeApply,
+		Body: body,
+	}
+}
+
+type testApplyObjectMeta struct {
+	finalizers  []string
+	annotations map[string]string
+	// owners are the field managers which own each finalizer
+	owners map[string][]string
+}
+
+// testApplyClient is a PatchClient for a single object, which handles server-side apply patches of finalizers
+// the way the API server does: each field manager owns the finalizers it applied, and a finalizer is only removed when
+// it is omitted from an apply by a field manager which owns it, and no other field manager also owns it.
+// If jsonOnly is true, apply patches are rejected the way the API server rejects unsupported patch types.
+type testApplyClient struct {
+	meta     testApplyObjectMeta
+	calls    []string
+	jsonOnly bool
+}
+
+func newTestApplyClient() *testApplyClient {
+	return &testApplyClient{
+		meta: testApplyObjectMeta{
+			finalizers:  make([]string, 0),
+			annotations: make(map[string]string),
+			owners:      make(map[string][]string),
+		},
+	}
+}
+
+// update makes a non-apply change to the object as the manager, which owns any finalizers it adds
+func (c *testApplyClient) update(manager string, fn func(meta *testApplyObjectMeta)) {
+	before := append([]string{}, c.meta.finalizers...)
+	fn(&c.meta)
+	for _, f := range c.meta.finalizers {
+		if !slices.Contains(before, f) {
+			c.meta.owners[f] = append(c.meta.owners[f], manager)
+		}
+	}
+}
+
+func (c *testApplyClient) PatchInto(_ context.Context, _ resource.Identifier, patch resource.PatchRequest, options resource.PatchOptions, into resource.Object) error {
+	switch patch.Type {
+	case resource.PatchTypeApply:
+		c.calls = append(c.calls, "apply:"+options.FieldManager)
+		if c.jsonOnly {
+			return k8s.NewServerResponseError(errors.New("unsupported patch type"), http.StatusUnsupportedMediaType)
+		}
+		cfg := finalizerApplyConfiguration{}
+		if err := json.Unmarshal(patch.Body, &cfg); err != nil {
+			return err
+		}
+		for f, owners := range c.meta.owners {
+			if !slices.Contains(owners, options.FieldManager) || slices.Contains(cfg.Metadata.Finalizers, f) {
+				continue
+			}
+			owners = slices.Filter(nil, owners, func(o string) bool { return o != options.FieldManager })
+			if len(owners) > 0 {
+				c.meta.owners[f] = owners
+				continue
+			}
+			delete(c.meta.owners, f)
+			c.meta.finalizers = slices.Filter(nil, c.meta.finalizers, func(o string) bool { return o != f })
+		}
+		for _, f := range cfg.Metadata.Finalizers {
+			if !slices.Contains(c.meta.finalizers, f) {
+				c.meta.finalizers = append(c.meta.finalizers, f)
+			}
+			if !slices.Contains(c.meta.owners[f], options.FieldManager) {
+				c.meta.owners[f] = append(c.meta.owners[f], options.FieldManager)
+			}
+		}
+	case "", resource.PatchTypeJSONPatch:
+		c.calls = append(c.calls, "json")
+		updated := append([]string{}, c.meta.finalizers...)
+		for _, op := range patch.Operations {
+			if op.Operation == resource.PatchOpAdd {
+				switch op.Path {
+				case "/metadata/finalizers":
+					updated = append([]string{}, op.Value.([]string)...)
+				case "/metadata/finalizers/-":
+					updated = append(updated, op.Value.(string))
+				default:
+					return fmt.Errorf("invalid path %s", op.Path)
+				}
+				continue
+			}
+			idx := -1
+			if _, err := fmt.Sscanf(op.Path, "/metadata/finalizers/%d", &idx); err != nil || idx >= len(updated) {
+				return fmt.Errorf("invalid path %s", op.Path)
+			}
+			switch op.Operation {
+			case resource.PatchOpTest:
+				if updated[idx] != op.Value {
+					return fmt.Errorf("test failed for %s", op.Path)
+				}
+			case resource.PatchOpRemove:
+				delete(c.meta.owners, updated[idx])
+				updated = append(updated[:idx], updated[idx+1:]...)
+			default:
+				return fmt.Errorf("unsupported operation %s", op.Operation)
+			}
+		}
+		c.meta.finalizers = updated
+	default:
+		return fmt.Errorf("unsupported patch type %s", patch.Type)
+	}
+	into.SetFinalizers(append([]string{}, c.meta.finalizers...))
+	into.SetAnnotations(maps.Clone(c.meta.annotations))
+	return nil
+}
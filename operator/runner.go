@@ -6,16 +6,24 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"net"
+	"net/http"
 	"os"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubeversion "k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
 
 	"github.com/grafana/grafana-app-sdk/app"
 	"github.com/grafana/grafana-app-sdk/k8s"
+	"github.com/grafana/grafana-app-sdk/logging"
 	"github.com/grafana/grafana-app-sdk/metrics"
 	"github.com/grafana/grafana-app-sdk/resource"
 )
@@ -32,6 +40,11 @@ type Runner struct {
 	startMux      sync.Mutex
 	running       bool
 	runningWG     sync.WaitGroup
+	// informers is the factory for the informers of the runner and the apps it runs, so that they share their watches
+	informers *SharedInformerFactory
+	// appRoutes is the app being run and its group, whose app-level routes are served by the /kinds and /openapi endpoints
+	// once Run is called
+	appRoutes atomic.Pointer[runnerAppRoutes]
 }
 
 // NewRunner creates a new, properly-initialized instance of a Runner
@@ -44,25 +57,89 @@ func NewRunner(cfg RunnerConfig) (*Runner, error) {
 	// }
 
 	op := Runner{
-		config: cfg,
+		config:    cfg,
+		informers: NewSharedInformerFactory(),
 	}
 
-	if cfg.WebhookConfig.TLSConfig.CertPath != "" {
+	if cfg.WebhookConfig.TLSConfig.CertPath != "" || cfg.WebhookConfig.TLSConfig.CertificateProvider != nil {
 		ws, err := k8s.NewWebhookServer(k8s.WebhookServerConfig{
-			Port: cfg.WebhookConfig.Port,
-			TLSConfig: k8s.TLSConfig{
-				CertPath: cfg.WebhookConfig.TLSConfig.CertPath,
-				KeyPath:  cfg.WebhookConfig.TLSConfig.KeyPath,
-			},
+			Port:            cfg.WebhookConfig.Port,
+			Listener:        cfg.WebhookConfig.Listener,
+			TLSConfig:       cfg.WebhookConfig.TLSConfig,
+			MetricsConfig:   metrics.DefaultConfig(cfg.MetricsConfig.Namespace),
+			Paths:           cfg.WebhookConfig.Paths,
+			DeadlineReserve: cfg.WebhookConfig.DeadlineReserve,
 		})
 		if err != nil {
 			return nil, err
 		}
 		op.webhookServer = newWebhookServerRunner(ws)
 	}
+	if cfg.DebugConfig.Enabled && !cfg.MetricsConfig.Enabled {
+		return nil, errors.New("DebugConfig.Enabled requires MetricsConfig.Enabled, as debug endpoints are served by the metrics server")
+	}
+	if cfg.MetricsConfig.DisableEndpoint {
+		if len(cfg.MetricsConfig.PushExporters) == 0 {
+			return nil, errors.New("MetricsConfig.DisableEndpoint requires MetricsConfig.PushExporters, as metrics would not be exported")
+		}
+		if cfg.DebugConfig.Enabled {
+			return nil, errors.New("DebugConfig.Enabled cannot be used with MetricsConfig.DisableEndpoint, as debug endpoints are served by the metrics server")
+		}
+	}
 	if cfg.MetricsConfig.Enabled {
-		exporter := metrics.NewExporter(cfg.MetricsConfig.ExporterConfig)
-		op.metricsServer = newMetricsServerRunner(exporter)
+		exporterConfig := cfg.MetricsConfig.ExporterConfig
+		var certificates *k8s.CertificateWatcher
+		if cfg.MetricsConfig.TLS.CertPath != "" || cfg.MetricsConfig.TLS.CertificateProvider != nil {
+			var err error
+			certificates, err = k8s.NewCertificateWatcher(cfg.MetricsConfig.TLS)
+			if err != nil {
+				return nil, fmt.Errorf("invalid MetricsConfig.TLS: %w", err)
+			}
+			exporterConfig.TLSConfig = certificates.TLSConfig()
+		}
+		exporter := metrics.NewExporter(exporterConfig)
+		pushers := make([]*metrics.Pusher, 0, len(cfg.MetricsConfig.PushExporters))
+		for _, pushExporter := range cfg.MetricsConfig.PushExporters {
+			pusher, err := metrics.NewPusher(metrics.PusherConfig{
+				Gatherer: exporter.Gatherer,
+				Exporter: pushExporter,
+				Interval: cfg.MetricsConfig.PushInterval,
+			})
+			if err != nil {
+				return nil, err
+			}
+			pushers = append(pushers, pusher)
+		}
+		op.metricsServer = newMetricsServerRunner(exporter, certificates, pushers, !cfg.MetricsConfig.DisableEndpoint)
+		// Build info is the same for every Runner in the process, so it only needs to be registered once
+		err := exporter.RegisterCollectors(app.NewBuildInfoCollector(metrics.DefaultConfig(cfg.MetricsConfig.Namespace)))
+		if err != nil && !errors.As(err, &prometheus.AlreadyRegisteredError{}) {
+			return nil, err
+		}
+		if err := exporter.Handle("/version", app.BuildInfoHandler()); err != nil {
+			return nil, err
+		}
+		if err := exporter.Handle(KindsPath, op.serveAppRoute(app.KindsRoutePath)); err != nil {
+			return nil, err
+		}
+		if err := exporter.Handle(CustomRoutesOpenAPIPath, op.serveAppRoute(app.CustomRoutesOpenAPIRoutePath)); err != nil {
+			return nil, err
+		}
+		if cfg.DebugConfig.Enabled {
+			stream := cfg.DebugConfig.ReconcileEvents
+			if stream == nil {
+				stream = DefaultReconcileEventStream()
+			}
+			if err := exporter.Handle(ReconcileEventsPath, stream); err != nil {
+				return nil, err
+			}
+		}
+		if op.webhookServer != nil {
+			// Register webhook server metrics here rather than in Run, as Run may be called more than once
+			if err := exporter.RegisterCollectors(op.webhookServer.server.PrometheusCollectors()...); err != nil {
+				return nil, err
+			}
+		}
 	}
 	return &op, nil
 }
@@ -78,26 +155,146 @@ type RunnerConfig struct {
 	// Filesystem is an fs.FS that can be used in lieu of the OS filesystem.
 	// if empty, it defaults to os.DirFS(".")
 	Filesystem fs.FS
+	// CRDDriftConfig contains the configuration for checking the app's CRDs for presentation drift from the manifest.
+	CRDDriftConfig RunnerCRDDriftConfig
+	// AdmissionPolicyConfig contains the configuration for evaluating admission policy bundles referenced in the manifest.
+	AdmissionPolicyConfig RunnerAdmissionPolicyConfig
+	// DependencyConfig contains the configuration for checking that kinds the app requires from other apps are served before it starts.
+	DependencyConfig RunnerDependencyConfig
+	// DebugConfig contains the configuration for exposing debug endpoints on the metrics server.
+	DebugConfig RunnerDebugConfig
+	// EventRecorder is added to the context the app is run with (see WithEventRecorder), so that its watchers and
+	// reconcilers can record Kubernetes Events with EventRecorderFromContext. If nil, a KubernetesEventRecorder
+	// for KubeConfig is used, with the app name as the source component, and shut down when Run returns.
+	// The app must have permission to create and patch events, which `grafana-app-sdk generate rbac` grants.
+	EventRecorder EventRecorder
+}
+
+// ReconcileEventsPath is the path of the metrics server endpoint which streams reconcile events
+// when RunnerDebugConfig.Enabled is true (see ReconcileEventStream.ServeHTTP).
+const ReconcileEventsPath = "/debug/reconciles"
+
+// RunnerDebugConfig contains configuration information for debug endpoints, which are served by the metrics server,
+// and so require RunnerMetricsConfig.Enabled. Debug endpoints expose the names of reconciled objects and reconcile errors,
+// so the metrics server should not be reachable from outside the cluster when they are enabled.
+type RunnerDebugConfig struct {
+	// Enabled, if true, streams reconcile events at ReconcileEventsPath on the metrics server.
+	// This endpoint is used by the `grafana-app-sdk debug tail` command.
+	Enabled bool
+	// ReconcileEvents is the stream of reconcile events to serve. If nil, DefaultReconcileEventStream is used,
+	// which every InformerController publishes to unless configured otherwise.
+	ReconcileEvents *ReconcileEventStream
+}
+
+// RunnerDependencyConfig contains configuration information for checking the kinds marked as required
+// in the app manifest's ExtraPermissions before the app is started (see app.KindPermission).
+type RunnerDependencyConfig struct {
+	// Wait, if true, waits with an exponential backoff for required kinds to be served.
+	// If false, Run returns an error if any required kind is not served on startup.
+	Wait bool
+	// Timeout is the maximum time to wait for required kinds to be served. If zero, Run waits until its context is canceled.
+	Timeout time.Duration
+}
+
+// RunnerAdmissionPolicyConfig contains configuration information for admission policy bundles
+// referenced in the app manifest (see app.AdmissionPolicyReference).
+// Bundles are loaded from RunnerConfig.Filesystem.
+type RunnerAdmissionPolicyConfig struct {
+	// Engines are additional AdmissionPolicyEngines, by name, which policy references can use.
+	// The built-in AdmissionPolicyEngineCEL engine is always available, unless overridden here.
+	Engines map[string]AdmissionPolicyEngine
+	// Timeout is the maximum time a policy may take to evaluate a single request. Defaults to one second.
+	Timeout time.Duration
+	// MaxConcurrentEvaluations is the maximum number of requests each policy evaluates at once. Defaults to 32.
+	MaxConcurrentEvaluations int
+	// ReloadInterval is the interval at which policy bundles are checked for changes. Defaults to 30 seconds.
+	ReloadInterval time.Duration
 }
 
-// RunnerMetricsConfig contains configuration information for exposing prometheus metrics
+// RunnerCRDDriftConfig contains configuration information for detecting drift between the presentation fields
+// (categories, shortNames, printer columns, and selectable fields) of the app's CRDs and its manifest.
+type RunnerCRDDriftConfig struct {
+	Enabled bool
+	// Interval is the interval at which to check the CRDs. If zero, they are only checked on startup.
+	Interval time.Duration
+	// AutoRepair, if true, patches drifted CRDs to match the manifest.
+	AutoRepair bool
+}
+
+// RunnerMetricsConfig contains configuration information for exposing prometheus metrics, and pushing them to PushExporters.
+// To serve metrics on a Unix socket or a socket passed by systemd (see SystemdListeners), rather than on a TCP port,
+// set ExporterConfig.Listener. As with RunnerWebhookConfig.Listener, it is closed once all Run calls return.
 type RunnerMetricsConfig struct {
 	metrics.ExporterConfig
 	Enabled   bool
 	Namespace string
+	// TLS, if it has a CertPath or CertificateProvider, makes the metrics server serve HTTPS with the certificate.
+	// Like the webhook server's certificate, it is renewed before it expires and reloaded when its files change.
+	// It takes precedence over ExporterConfig.TLSConfig.
+	TLS k8s.TLSConfig
+	// PushExporters are sent the Runner's metrics every PushInterval, alongside the metrics server.
+	// Use a metrics.OTLPExporter to push metrics to an OpenTelemetry collector, rather than having them scraped.
+	PushExporters []metrics.PushExporter
+	// PushInterval is how often metrics are sent to PushExporters. Defaults to 30 seconds.
+	PushInterval time.Duration
+	// DisableEndpoint, if true, doesn't run the metrics server, so metrics are only exported by PushExporters.
+	// The /version and debug endpoints are served by the metrics server, so they are also unavailable.
+	DisableEndpoint bool
 }
 
 type RunnerWebhookConfig struct {
-	// Port is the port to open the webhook server on
+	// Port is the port to open the webhook server on. It is ignored if Listener is non-nil.
 	Port int
-	// TLSConfig is the TLS Cert and Key to use for the HTTPS endpoints exposed for webhooks
+	// Listener, if non-nil, is the listener the webhook server accepts connections on, instead of opening Port.
+	// Use it to serve webhooks on a Unix socket, or on a socket passed by systemd (see SystemdListeners).
+	// The Listener is closed once all Run calls return, so the Runner cannot be run again afterward.
+	Listener net.Listener
+	// TLSConfig is the TLS Cert and Key to use for the HTTPS endpoints exposed for webhooks.
+	// The cert and key are reloaded when the files change (see k8s.TLSConfig.WatchInterval), so rotated certificates
+	// (such as a Secret volume renewed by cert-manager) are served without a restart.
+	// To get certificates from an external issuer (such as Vault PKI) and renew them automatically,
+	// set TLSConfig.CertificateProvider instead of the cert and key paths.
 	TLSConfig k8s.TLSConfig
+	// Paths are the base paths admission and conversion webhooks are served on, defaulting to k8s.DefaultWebhookPaths.
+	// Requests for each kind version can also be routed by per-kind paths under the base paths, such as
+	// /validate/<group>/<version>/<plural>, which are used by webhook configurations from `grafana-app-sdk generate webhooks`.
+	Paths k8s.WebhookPaths
+	// Converters handle conversion requests for their kinds, instead of the app's Convert method.
+	// Each app.VersionConverter chains the conversions registered with it to convert between any two versions.
+	// Conversion webhooks are only served for kinds with conversion enabled in the app manifest.
+	Converters []*app.VersionConverter
+	// ValidateSchemas, if true, validates objects in validating admission requests against the schema of their kind version
+	// in the app manifest (see app.SchemaValidator), before any other validation is run.
+	// Objects are only validated for kind versions which have a validation capability or admission policy.
+	ValidateSchemas bool
+	// DeadlineReserve is the part of the API server's admission webhook timeout which is reserved for writing the response,
+	// so that downstream calls made while handling the request end before the API server stops waiting.
+	// See k8s.WebhookServerConfig.DeadlineReserve.
+	DeadlineReserve time.Duration
 }
 
 type capabilities struct {
 	conversion bool
 	mutation   bool
+	defaulting bool
 	validation bool
+	configKind bool
+	policy     *app.AdmissionPolicyReference
+	schema     *app.SchemaValidator
+	limits     *app.SizeLimitEnforcer
+}
+
+// validateManifest validates the request with the size limit enforcer and schema validator, if they are set
+func (c capabilities) validateManifest(ctx context.Context, request *resource.AdmissionRequest) error {
+	if c.limits != nil {
+		if err := c.limits.Validate(ctx, request); err != nil {
+			return err
+		}
+	}
+	if c.schema == nil {
+		return nil
+	}
+	return c.schema.Validate(ctx, request)
 }
 
 // Run runs the Runner for the app built from the provided app.AppProvider, until the provided context.Context is closed,
@@ -115,10 +312,30 @@ func (s *Runner) Run(ctx context.Context, provider app.Provider) error {
 	if err != nil {
 		return fmt.Errorf("unable to get app manifest capabilities: %w", err)
 	}
+	if err = s.waitForDependencies(ctx, *manifestData); err != nil {
+		return err
+	}
+	recorder := s.config.EventRecorder
+	if recorder == nil {
+		kubeRecorder, err := NewKubernetesEventRecorderForConfig(s.config.KubeConfig, manifestData.AppName)
+		if err != nil {
+			return err
+		}
+		defer kubeRecorder.Shutdown()
+		recorder = kubeRecorder
+	}
 	appConfig := app.Config{
-		KubeConfig:     s.config.KubeConfig,
-		ManifestData:   *manifestData,
-		SpecificConfig: provider.SpecificConfig(),
+		KubeConfig:      s.config.KubeConfig,
+		ManifestData:    *manifestData,
+		SpecificConfig:  provider.SpecificConfig(),
+		InformerFactory: s.informers,
+	}
+	configKind, err := getConfigKind(*manifestData)
+	if err != nil {
+		return err
+	}
+	if configKind != nil {
+		appConfig.ConfigKind = app.NewConfigKindValue()
 	}
 
 	// Create the app
@@ -126,6 +343,10 @@ func (s *Runner) Run(ctx context.Context, provider app.Provider) error {
 	if err != nil {
 		return err
 	}
+	s.appRoutes.Store(&runnerAppRoutes{
+		app:   a,
+		group: manifestData.Group,
+	})
 
 	s.runningWG.Add(1)
 	defer s.runningWG.Done()
@@ -154,6 +375,22 @@ func (s *Runner) Run(ctx context.Context, provider app.Provider) error {
 	vkCapabilities := make(map[string]capabilities)
 	for _, kind := range manifestData.Kinds {
 		for _, version := range kind.Versions {
+			if kind.ConfigKind {
+				// Config kinds always require validation, to ensure that only one instance exists
+				anyWebhooks = true
+				c := capabilities{
+					conversion: kind.Conversion,
+					mutation:   version.Admission != nil && version.Admission.SupportsAnyMutation(),
+					defaulting: version.Admission != nil && version.Admission.SupportsAnyDefaulting(),
+					validation: true,
+					configKind: true,
+				}
+				if version.Admission != nil {
+					c.policy = version.Admission.Policy
+				}
+				vkCapabilities[fmt.Sprintf("%s/%s", kind.Kind, version.Name)] = c
+				continue
+			}
 			if version.Admission == nil {
 				if kind.Conversion {
 					anyWebhooks = true
@@ -166,39 +403,158 @@ func (s *Runner) Run(ctx context.Context, provider app.Provider) error {
 			vkCapabilities[fmt.Sprintf("%s/%s", kind.Kind, version.Name)] = capabilities{
 				conversion: kind.Conversion,
 				mutation:   version.Admission.SupportsAnyMutation(),
+				defaulting: version.Admission.SupportsAnyDefaulting(),
 				validation: version.Admission.SupportsAnyValidation(),
+				policy:     version.Admission.Policy,
 			}
-			if kind.Conversion || version.Admission.SupportsAnyMutation() || version.Admission.SupportsAnyValidation() {
+			if kind.Conversion || version.Admission.SupportsAnyMutation() || version.Admission.SupportsAnyDefaulting() ||
+				version.Admission.SupportsAnyValidation() || version.Admission.Policy != nil {
 				anyWebhooks = true
 			}
 		}
 	}
+	// Size limits are enforced for every kind version which has them, whether or not it has other validation
+	var sizeLimits *app.SizeLimitEnforcer
+	for _, kind := range manifestData.Kinds {
+		for _, version := range kind.Versions {
+			if version.SizeLimits == nil {
+				continue
+			}
+			if sizeLimits == nil {
+				sizeLimits, err = app.NewSizeLimitEnforcer(*manifestData, metrics.DefaultConfig(s.config.MetricsConfig.Namespace))
+				if err != nil {
+					return err
+				}
+			}
+			key := fmt.Sprintf("%s/%s", kind.Kind, version.Name)
+			c, ok := vkCapabilities[key]
+			if !ok {
+				c.conversion = kind.Conversion
+			}
+			c.validation = true
+			c.limits = sizeLimits
+			vkCapabilities[key] = c
+			anyWebhooks = true
+		}
+	}
+	if s.config.WebhookConfig.ValidateSchemas {
+		for _, kind := range manifestData.Kinds {
+			for _, version := range kind.Versions {
+				key := fmt.Sprintf("%s/%s", kind.Kind, version.Name)
+				c, ok := vkCapabilities[key]
+				if !ok || (!c.validation && c.policy == nil) || version.Schema == nil {
+					continue
+				}
+				if c.schema, err = app.NewSchemaValidator(version.Schema); err != nil {
+					return fmt.Errorf("unable to load schema for %s: %w", key, err)
+				}
+				vkCapabilities[key] = c
+			}
+		}
+	}
 	if anyWebhooks {
 		if s.webhookServer == nil {
 			return errors.New("app has capabilities that require webhooks, but webhook server was not provided TLS config")
 		}
+		converters := make(map[schema.GroupKind]converter)
+		for _, conv := range s.config.WebhookConfig.Converters {
+			converters[conv.GroupKind()] = conv
+		}
 		for _, kind := range a.ManagedKinds() {
 			c, ok := vkCapabilities[fmt.Sprintf("%s/%s", kind.Kind(), kind.Version())]
 			if !ok {
 				continue
 			}
-			if c.validation {
+			if c.policy != nil {
+				policy, err := s.newPolicyAdmissionController(*c.policy)
+				if err != nil {
+					return fmt.Errorf("unable to load admission policy for %s/%s: %w", kind.Kind(), kind.Version(), err)
+				}
+				runner.AddRunnable(policy)
+				s.webhookServer.AddValidatingAdmissionController(&resource.SimpleValidatingAdmissionController{
+					ValidateFunc: func(ctx context.Context, request *resource.AdmissionRequest) error {
+						if err := c.validateManifest(ctx, request); err != nil {
+							return err
+						}
+						if err := policy.Validate(ctx, request); err != nil {
+							return err
+						}
+						if !c.validation && !c.configKind {
+							return nil
+						}
+						req := s.translateAdmissionRequest(request)
+						if c.configKind {
+							if err := app.ValidateConfigKindAdmission(appConfig.ConfigKind, req); err != nil {
+								return err
+							}
+						}
+						if err := a.Validate(ctx, req); err != nil && !errors.Is(err, app.ErrNotImplemented) {
+							return err
+						}
+						return nil
+					},
+				}, kind)
+				s.webhookServer.AddMutatingAdmissionController(&resource.SimpleMutatingAdmissionController{
+					MutateFunc: func(ctx context.Context, request *resource.AdmissionRequest) (*resource.MutatingResponse, error) {
+						resp, err := policy.Mutate(ctx, request)
+						if err != nil || (!c.mutation && !c.defaulting) {
+							return resp, err
+						}
+						// The app defaults and mutates the object as mutated by the policy
+						mutated := *request
+						mutated.Object = resp.UpdatedObject
+						appResp, err := s.mutate(ctx, a, c, &mutated)
+						if err == nil && appResp == nil {
+							return resp, nil
+						}
+						return appResp, err
+					},
+				}, kind)
+			} else if c.validation && c.configKind {
 				s.webhookServer.AddValidatingAdmissionController(&resource.SimpleValidatingAdmissionController{
 					ValidateFunc: func(ctx context.Context, request *resource.AdmissionRequest) error {
-						return a.Validate(ctx, s.translateAdmissionRequest(request))
+						if err := c.validateManifest(ctx, request); err != nil {
+							return err
+						}
+						req := s.translateAdmissionRequest(request)
+						if err := app.ValidateConfigKindAdmission(appConfig.ConfigKind, req); err != nil {
+							return err
+						}
+						// The app may not have its own validation for the config kind
+						if err := a.Validate(ctx, req); err != nil && !errors.Is(err, app.ErrNotImplemented) {
+							return err
+						}
+						return nil
+					},
+				}, kind)
+			} else if c.validation {
+				s.webhookServer.AddValidatingAdmissionController(&resource.SimpleValidatingAdmissionController{
+					ValidateFunc: func(ctx context.Context, request *resource.AdmissionRequest) error {
+						if err := c.validateManifest(ctx, request); err != nil {
+							return err
+						}
+						err := a.Validate(ctx, s.translateAdmissionRequest(request))
+						// The app may not have its own validation for a kind version with size limits
+						if c.limits != nil && errors.Is(err, app.ErrNotImplemented) {
+							return nil
+						}
+						return err
 					},
 				}, kind)
 			}
-			if c.mutation {
+			if (c.mutation || c.defaulting) && c.policy == nil {
 				s.webhookServer.AddMutatingAdmissionController(&resource.SimpleMutatingAdmissionController{
 					MutateFunc: func(ctx context.Context, request *resource.AdmissionRequest) (*resource.MutatingResponse, error) {
-						resp, err := a.Mutate(ctx, s.translateAdmissionRequest(request))
-						return s.translateMutatingResponse(resp), err
+						return s.mutate(ctx, a, c, request)
 					},
 				}, kind)
 			}
 			if c.conversion {
-				s.webhookServer.AddConverter(toWebhookConverter(a), metav1.GroupKind{
+				var conv converter = a
+				if vc, ok := converters[schema.GroupKind{Group: kind.Group(), Kind: kind.Kind()}]; ok {
+					conv = vc
+				}
+				s.webhookServer.AddConverter(toWebhookConverter(conv), metav1.GroupKind{
 					Group: kind.Group(),
 					Kind:  kind.Kind(),
 				})
@@ -213,16 +569,102 @@ func (s *Runner) Run(ctx context.Context, provider app.Provider) error {
 		runner.AddRunnable(r)
 	}
 
+	// Config kind watch
+	if configKind != nil {
+		inf, err := s.newConfigKindInformer(a, *configKind, appConfig.ConfigKind)
+		if err != nil {
+			return err
+		}
+		runner.AddRunnable(inf)
+	}
+
+	// CRD presentation drift detection
+	if s.config.CRDDriftConfig.Enabled {
+		manager, err := k8s.NewManager(s.config.KubeConfig)
+		if err != nil {
+			return fmt.Errorf("unable to create CRD client for drift detection: %w", err)
+		}
+		plurals := make(map[string]string)
+		for _, kind := range a.ManagedKinds() {
+			plurals[kind.Kind()] = kind.Plural()
+		}
+		detector, err := NewCRDDriftDetector(manager, *manifestData, CRDDriftDetectorConfig{
+			Interval:      s.config.CRDDriftConfig.Interval,
+			AutoRepair:    s.config.CRDDriftConfig.AutoRepair,
+			Plurals:       plurals,
+			MetricsConfig: metrics.DefaultConfig(s.config.MetricsConfig.Namespace),
+		})
+		if err != nil {
+			return err
+		}
+		runner.AddRunnable(detector)
+	}
+
 	// Metrics
 	if s.metricsServer != nil {
-		err = s.metricsServer.RegisterCollectors(runner.PrometheusCollectors()...)
+		collectors := runner.PrometheusCollectors()
+		if sizeLimits != nil {
+			collectors = append(collectors, sizeLimits.PrometheusCollectors()...)
+		}
+		err = s.metricsServer.RegisterCollectors(collectors...)
 		if err != nil {
 			return err
 		}
 		runner.AddRunnable(s.metricsServer)
 	}
 
-	return runner.Run(ctx)
+	return runner.Run(WithEventRecorder(ctx, recorder))
+}
+
+// KindsPath is the path of the metrics server endpoint which returns the kinds of the app being run,
+// with their maturity, owner, and documentation URL. Requests are passed to the app's app.KindsRoutePath route.
+const KindsPath = "/kinds"
+
+// CustomRoutesOpenAPIPath is the path of the metrics server endpoint which returns the OpenAPI document of the custom routes
+// of the app being run. Requests are passed to the app's app.CustomRoutesOpenAPIRoutePath route.
+const CustomRoutesOpenAPIPath = "/openapi"
+
+// runnerAppRoutes is the app being run, and the group of its manifest
+type runnerAppRoutes struct {
+	app   app.App
+	group string
+}
+
+// serveAppRoute returns a handler which serves the app-level route at path of the app being run, or responds with
+// 503 Service Unavailable if Run has not been called yet. It responds with 404 Not Found if the app does not serve the route.
+func (s *Runner) serveAppRoute(path string) http.HandlerFunc {
+	return func(writer http.ResponseWriter, req *http.Request) {
+		routes := s.appRoutes.Load()
+		if routes == nil {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		resp, err := routes.app.CallResourceCustomRoute(req.Context(), &app.ResourceCustomRouteRequest{
+			ResourceIdentifier: resource.FullIdentifier{Group: routes.group},
+			SubresourcePath:    path,
+			Method:             req.Method,
+			Headers:            req.Header,
+		})
+		switch {
+		case errors.Is(err, app.ErrCustomRouteNotFound), errors.Is(err, app.ErrNotImplemented):
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		case err != nil:
+			logging.FromContext(req.Context()).Error("error calling app route", "path", path, "error", err)
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		for key, values := range resp.Headers {
+			for _, value := range values {
+				writer.Header().Add(key, value)
+			}
+		}
+		if resp.StatusCode != 0 {
+			writer.WriteHeader(resp.StatusCode)
+		}
+		//nolint:errcheck
+		writer.Write(resp.Body)
+	}
 }
 
 func (s *Runner) getManifestData(provider app.Provider) (*app.ManifestData, error) {
@@ -254,9 +696,142 @@ func (s *Runner) getManifestData(provider app.Provider) (*app.ManifestData, erro
 		// TODO: fetch from API server
 		return nil, fmt.Errorf("apiserver location not supported yet")
 	}
+	if err := data.ValidateSelectableFields(); err != nil {
+		return nil, fmt.Errorf("invalid selectable fields in manifest: %w", err)
+	}
 	return &data, nil
 }
 
+// newPolicyAdmissionController loads the admission policy bundle referenced by ref
+func (s *Runner) newPolicyAdmissionController(ref app.AdmissionPolicyReference) (*PolicyAdmissionController, error) {
+	engineName := ref.Engine
+	if engineName == "" {
+		engineName = AdmissionPolicyEngineCEL
+	}
+	engine, ok := s.config.AdmissionPolicyConfig.Engines[engineName]
+	if !ok && engineName == AdmissionPolicyEngineCEL {
+		engine, ok = CELAdmissionPolicyEngine(), true
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown admission policy engine '%s'", engineName)
+	}
+	dir := s.config.Filesystem
+	if dir == nil {
+		dir = os.DirFS(".")
+	}
+	return NewPolicyAdmissionController(PolicyAdmissionControllerConfig{
+		Filesystem:               dir,
+		Path:                     ref.Path,
+		Engine:                   engine,
+		Timeout:                  s.config.AdmissionPolicyConfig.Timeout,
+		ReloadInterval:           s.config.AdmissionPolicyConfig.ReloadInterval,
+		MaxConcurrentEvaluations: s.config.AdmissionPolicyConfig.MaxConcurrentEvaluations,
+	})
+}
+
+// waitForDependencies blocks until the kinds required by the app (from its manifest's ExtraPermissions) are served,
+// as configured by RunnerConfig.DependencyConfig.
+func (s *Runner) waitForDependencies(ctx context.Context, manifestData app.ManifestData) error {
+	if manifestData.ExtraPermissions == nil || !slices.ContainsFunc(manifestData.ExtraPermissions.AccessKinds, func(p app.KindPermission) bool {
+		return p.Required
+	}) {
+		return nil
+	}
+	disc, err := discovery.NewDiscoveryClientForConfig(&s.config.KubeConfig)
+	if err != nil {
+		return fmt.Errorf("unable to create discovery client: %w", err)
+	}
+	gate, err := NewDependencyGate(NewDiscoveryServedKindChecker(disc), manifestData.ExtraPermissions, DependencyGateConfig{
+		Wait:    s.config.DependencyConfig.Wait,
+		Timeout: s.config.DependencyConfig.Timeout,
+	})
+	if err != nil {
+		return err
+	}
+	return gate.Wait(ctx)
+}
+
+// newConfigKindInformer returns an informer for the preferred version of the app's config kind which keeps value up to date
+func (s *Runner) newConfigKindInformer(a app.App, configKind app.ManifestKind, value *app.ConfigKindValue) (Informer, error) {
+	preferred := preferredKindVersion(configKind)
+	if preferred == "" {
+		return nil, fmt.Errorf("config kind %s has no versions", configKind.Kind)
+	}
+	var kind *resource.Kind
+	for _, k := range a.ManagedKinds() {
+		if k.Kind() == configKind.Kind && k.Version() == preferred {
+			kind = &k
+			break
+		}
+	}
+	if kind == nil {
+		return nil, fmt.Errorf("preferred version %s of config kind %s is not managed by the app", preferred, configKind.Kind)
+	}
+	client, err := k8s.NewClientRegistry(s.config.KubeConfig, k8s.DefaultClientConfig()).ClientFor(*kind)
+	if err != nil {
+		return nil, err
+	}
+	inf, err := s.informers.Informer(*kind, client, KubernetesBasedInformerOptions{
+		ListWatchOptions: AllNamespaces(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = inf.AddEventHandler(&SimpleWatcher{
+		AddFunc: func(ctx context.Context, object resource.Object) error {
+			value.Set(ctx, object)
+			return nil
+		},
+		UpdateFunc: func(ctx context.Context, _ resource.Object, object resource.Object) error {
+			value.Set(ctx, object)
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, object resource.Object) error {
+			// Only clear the value if the deleted object is the current instance
+			if current := value.Get(); current != nil && current.GetName() == object.GetName() {
+				value.Set(ctx, nil)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inf, nil
+}
+
+// preferredKindVersion returns the version of kind with the highest kube-aware priority (such as v2 over v2beta1),
+// which is the version the API server prefers when the kind's versions are served as a CRD.
+// It returns an empty string if kind has no versions.
+func preferredKindVersion(kind app.ManifestKind) string {
+	preferred := ""
+	for _, v := range kind.Versions {
+		if preferred == "" || kubeversion.CompareKubeAwareVersionStrings(v.Name, preferred) > 0 {
+			preferred = v.Name
+		}
+	}
+	return preferred
+}
+
+// getConfigKind returns the config kind in the manifest, or nil if there is none.
+// It returns an error if more than one kind is a config kind, or the config kind is not cluster-scoped.
+func getConfigKind(manifest app.ManifestData) (*app.ManifestKind, error) {
+	var configKind *app.ManifestKind
+	for i, kind := range manifest.Kinds {
+		if !kind.ConfigKind {
+			continue
+		}
+		if configKind != nil {
+			return nil, fmt.Errorf("manifest has multiple config kinds (%s, %s), only one is allowed", configKind.Kind, kind.Kind)
+		}
+		if kind.Scope != string(resource.ClusterScope) {
+			return nil, fmt.Errorf("config kind %s must be %s-scoped", kind.Kind, resource.ClusterScope)
+		}
+		configKind = &manifest.Kinds[i]
+	}
+	return configKind, nil
+}
+
 func (*Runner) translateAdmissionRequest(request *resource.AdmissionRequest) *app.AdmissionRequest {
 	if request == nil {
 		return nil
@@ -266,6 +841,35 @@ func (*Runner) translateAdmissionRequest(request *resource.AdmissionRequest) *ap
 	return &req
 }
 
+// mutate runs the app's defaulting on the request's object if c.defaulting is true, then the app's mutation
+// on the defaulted object if c.mutation is true. It returns nil if neither updated the object.
+func (s *Runner) mutate(ctx context.Context, a app.App, c capabilities, request *resource.AdmissionRequest) (*resource.MutatingResponse, error) {
+	var resp *resource.MutatingResponse
+	if c.defaulting {
+		defaulted, err := a.Default(ctx, s.translateAdmissionRequest(request))
+		if err != nil && !errors.Is(err, app.ErrNotImplemented) {
+			return nil, err
+		}
+		if err == nil && defaulted != nil && defaulted.UpdatedObject != nil {
+			resp = s.translateMutatingResponse(defaulted)
+			withDefaults := *request
+			withDefaults.Object = defaulted.UpdatedObject
+			request = &withDefaults
+		}
+	}
+	if !c.mutation {
+		return resp, nil
+	}
+	mutated, err := a.Mutate(ctx, s.translateAdmissionRequest(request))
+	if errors.Is(err, app.ErrNotImplemented) {
+		return resp, nil
+	}
+	if err != nil || mutated == nil || mutated.UpdatedObject == nil {
+		return resp, err
+	}
+	return s.translateMutatingResponse(mutated), nil
+}
+
 func (*Runner) translateMutatingResponse(response *app.MutatingResponse) *resource.MutatingResponse {
 	if response == nil {
 		return nil
@@ -275,10 +879,15 @@ func (*Runner) translateMutatingResponse(response *app.MutatingResponse) *resour
 	return &resp
 }
 
-func toWebhookConverter(a app.App) k8s.Converter {
+// converter is the conversion method of app.App, which is also implemented by app.VersionConverter
+type converter interface {
+	Convert(ctx context.Context, req app.ConversionRequest) (*app.RawObject, error)
+}
+
+func toWebhookConverter(a converter) k8s.Converter {
 	return &simpleK8sConverter{
-		convertFunc: func(obj k8s.RawKind, targetAPIVersion string) ([]byte, error) {
-			converted, err := a.Convert(context.Background(), app.ConversionRequest{
+		convertFunc: func(ctx context.Context, obj k8s.RawKind, targetAPIVersion string) ([]byte, error) {
+			converted, err := a.Convert(ctx, app.ConversionRequest{
 				SourceGVK: schema.FromAPIVersionAndKind(obj.APIVersion, obj.Kind),
 				TargetGVK: schema.FromAPIVersionAndKind(targetAPIVersion, obj.Kind),
 				Raw: app.RawObject{
@@ -295,11 +904,15 @@ func toWebhookConverter(a app.App) k8s.Converter {
 }
 
 type simpleK8sConverter struct {
-	convertFunc func(obj k8s.RawKind, targetAPIVersion string) ([]byte, error)
+	convertFunc func(ctx context.Context, obj k8s.RawKind, targetAPIVersion string) ([]byte, error)
 }
 
 func (s *simpleK8sConverter) Convert(obj k8s.RawKind, targetAPIVersion string) ([]byte, error) {
-	return s.convertFunc(obj, targetAPIVersion)
+	return s.convertFunc(context.Background(), obj, targetAPIVersion)
+}
+
+func (s *simpleK8sConverter) ConvertWithContext(ctx context.Context, obj k8s.RawKind, targetAPIVersion string) ([]byte, error) {
+	return s.convertFunc(ctx, obj, targetAPIVersion)
 }
 
 func newWebhookServerRunner(ws *k8s.WebhookServer) *webhookServerRunner {
@@ -332,12 +945,36 @@ func (s *webhookServerRunner) AddConverter(converter k8s.Converter, groupKind me
 	s.server.AddConverter(converter, groupKind)
 }
 
-func newMetricsServerRunner(exporter *metrics.Exporter) *metricsServerRunner {
+func newMetricsServerRunner(
+	exporter *metrics.Exporter, certificates *k8s.CertificateWatcher, pushers []*metrics.Pusher, serve bool,
+) *metricsServerRunner {
+	runnables := make([]app.Runnable, 0, len(pushers)+1)
+	if serve {
+		var server app.Runnable = &k8sRunnable{
+			runner: exporter,
+		}
+		if certificates != nil {
+			server = &tlsRunnable{
+				certificates: certificates,
+				runnable:     server,
+			}
+		}
+		runnables = append(runnables, server)
+	}
+	for _, pusher := range pushers {
+		runnables = append(runnables, pusher)
+	}
+	var runnable app.Runnable
+	if len(runnables) == 1 {
+		runnable = runnables[0]
+	} else {
+		multi := app.NewMultiRunner()
+		multi.Runners = runnables
+		runnable = multi
+	}
 	return &metricsServerRunner{
 		server: exporter,
-		runner: app.NewSingletonRunner(&k8sRunnable{
-			runner: exporter,
-		}, false),
+		runner: app.NewSingletonRunner(runnable, false),
 	}
 }
 
@@ -354,6 +991,22 @@ func (m *metricsServerRunner) RegisterCollectors(collectors ...prometheus.Collec
 	return m.server.RegisterCollectors(collectors...)
 }
 
+// tlsRunnable loads the certificate served by the HTTPS server of a runnable before running it,
+// and keeps the certificate up-to-date until the runnable stops
+type tlsRunnable struct {
+	certificates *k8s.CertificateWatcher
+	runnable     app.Runnable
+}
+
+func (t *tlsRunnable) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if err := t.certificates.Start(ctx); err != nil {
+		return fmt.Errorf("unable to load TLS certificate: %w", err)
+	}
+	return t.runnable.Run(ctx)
+}
+
 type k8sRunner interface {
 	Run(<-chan struct{}) error
 }
@@ -0,0 +1,214 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestRunner_mutate(t *testing.T) {
+	newRequest := func() *resource.AdmissionRequest {
+		return &resource.AdmissionRequest{
+			Action: resource.AdmissionActionCreate,
+			Object: &resource.UntypedObject{
+				Spec: map[string]any{
+					"foo": "bar",
+				},
+			},
+		}
+	}
+	defaulter := func(_ context.Context, req *app.AdmissionRequest) (*app.MutatingResponse, error) {
+		obj := req.Object.Copy().(*resource.UntypedObject)
+		obj.Spec["defaulted"] = true
+		return &app.MutatingResponse{UpdatedObject: obj}, nil
+	}
+	mutator := func(_ context.Context, req *app.AdmissionRequest) (*app.MutatingResponse, error) {
+		obj := req.Object.Copy().(*resource.UntypedObject)
+		obj.Spec["mutated"] = true
+		return &app.MutatingResponse{UpdatedObject: obj}, nil
+	}
+	r := &Runner{}
+
+	t.Run("defaulting then mutation", func(t *testing.T) {
+		a := &testMutatingApp{defaultFunc: defaulter, mutateFunc: mutator}
+		resp, err := r.mutate(context.Background(), a, capabilities{defaulting: true, mutation: true}, newRequest())
+		require.Nil(t, err)
+		assert.Equal(t, map[string]any{"foo": "bar", "defaulted": true, "mutated": true}, resp.UpdatedObject.(*resource.UntypedObject).Spec)
+	})
+
+	t.Run("defaulting only", func(t *testing.T) {
+		a := &testMutatingApp{defaultFunc: defaulter, mutateFunc: mutator}
+		resp, err := r.mutate(context.Background(), a, capabilities{defaulting: true}, newRequest())
+		require.Nil(t, err)
+		assert.Equal(t, map[string]any{"foo": "bar", "defaulted": true}, resp.UpdatedObject.(*resource.UntypedObject).Spec)
+	})
+
+	t.Run("defaulting not implemented", func(t *testing.T) {
+		a := &testMutatingApp{mutateFunc: mutator}
+		resp, err := r.mutate(context.Background(), a, capabilities{defaulting: true, mutation: true}, newRequest())
+		require.Nil(t, err)
+		assert.Equal(t, map[string]any{"foo": "bar", "mutated": true}, resp.UpdatedObject.(*resource.UntypedObject).Spec)
+	})
+
+	t.Run("mutation without changes keeps defaults", func(t *testing.T) {
+		a := &testMutatingApp{
+			defaultFunc: defaulter,
+			mutateFunc: func(context.Context, *app.AdmissionRequest) (*app.MutatingResponse, error) {
+				return nil, nil
+			},
+		}
+		resp, err := r.mutate(context.Background(), a, capabilities{defaulting: true, mutation: true}, newRequest())
+		require.Nil(t, err)
+		assert.Equal(t, map[string]any{"foo": "bar", "defaulted": true}, resp.UpdatedObject.(*resource.UntypedObject).Spec)
+	})
+
+	t.Run("defaulting error", func(t *testing.T) {
+		a := &testMutatingApp{
+			defaultFunc: func(context.Context, *app.AdmissionRequest) (*app.MutatingResponse, error) {
+				return nil, errors.New("I AM ERROR")
+			},
+			mutateFunc: mutator,
+		}
+		_, err := r.mutate(context.Background(), a, capabilities{defaulting: true, mutation: true}, newRequest())
+		assert.Equal(t, errors.New("I AM ERROR"), err)
+	})
+}
+
+type testMutatingApp struct {
+	app.App
+	defaultFunc func(context.Context, *app.AdmissionRequest) (*app.MutatingResponse, error)
+	mutateFunc  func(context.Context, *app.AdmissionRequest) (*app.MutatingResponse, error)
+}
+
+func (a *testMutatingApp) Default(ctx context.Context, req *app.AdmissionRequest) (*app.MutatingResponse, error) {
+	if a.defaultFunc == nil {
+		return nil, app.ErrNotImplemented
+	}
+	return a.defaultFunc(ctx, req)
+}
+
+func (a *testMutatingApp) Mutate(ctx context.Context, req *app.AdmissionRequest) (*app.MutatingResponse, error) {
+	if a.mutateFunc == nil {
+		return nil, app.ErrNotImplemented
+	}
+	return a.mutateFunc(ctx, req)
+}
+
+func TestNewRunner_DebugConfig(t *testing.T) {
+	_, err := NewRunner(RunnerConfig{
+		DebugConfig: RunnerDebugConfig{Enabled: true},
+	})
+	assert.Equal(t, errors.New("DebugConfig.Enabled requires MetricsConfig.Enabled, as debug endpoints are served by the metrics server"), err)
+}
+
+func TestRunner_newConfigKindInformer(t *testing.T) {
+	newKind := func(version string) resource.Kind {
+		return resource.Kind{
+			Schema: resource.NewSimpleSchema("test.ext.grafana.com", version, &resource.UntypedObject{}, &resource.UntypedList{},
+				resource.WithKind("Config"), resource.WithScope(resource.ClusterScope)),
+			Codecs: map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()},
+		}
+	}
+	// The versions are not listed in priority order, so the last one is not the preferred version
+	configKind := app.ManifestKind{
+		Kind:       "Config",
+		Scope:      string(resource.ClusterScope),
+		ConfigKind: true,
+		Versions:   []app.ManifestKindVersion{{Name: "v1"}, {Name: "v2"}, {Name: "v2beta1"}},
+	}
+	runner, err := NewRunner(RunnerConfig{})
+	require.Nil(t, err)
+
+	inf, err := runner.newConfigKindInformer(&embeddedTestApp{
+		kinds: []resource.Kind{newKind("v1"), newKind("v2"), newKind("v2beta1")},
+	}, configKind, app.NewConfigKindValue())
+	require.Nil(t, err)
+	require.IsType(t, &SharedInformer{}, inf)
+	assert.Equal(t, "v2", inf.(*SharedInformer).Schema().Version())
+
+	_, err = runner.newConfigKindInformer(&embeddedTestApp{
+		kinds: []resource.Kind{newKind("v1"), newKind("v2beta1")},
+	}, configKind, app.NewConfigKindValue())
+	assert.EqualError(t, err, "preferred version v2 of config kind Config is not managed by the app")
+
+	_, err = runner.newConfigKindInformer(&embeddedTestApp{}, app.ManifestKind{Kind: "Config"}, app.NewConfigKindValue())
+	assert.EqualError(t, err, "config kind Config has no versions")
+}
+
+func TestNewRunner_MetricsDisableEndpoint(t *testing.T) {
+	_, err := NewRunner(RunnerConfig{
+		MetricsConfig: RunnerMetricsConfig{Enabled: true, DisableEndpoint: true},
+	})
+	assert.Equal(t, errors.New("MetricsConfig.DisableEndpoint requires MetricsConfig.PushExporters, as metrics would not be exported"), err)
+}
+
+func TestRunner_serveAppRoute(t *testing.T) {
+	runner := &Runner{}
+	rec := httptest.NewRecorder()
+	runner.serveAppRoute(app.KindsRoutePath)(rec, httptest.NewRequest(http.MethodGet, KindsPath, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	manifest := app.ManifestData{
+		AppName: "foo",
+		Group:   "foo.grafana.app",
+		Kinds: []app.ManifestKind{{
+			Kind:     "Bar",
+			Scope:    "Namespaced",
+			Versions: []app.ManifestKindVersion{{Name: "v1"}},
+			Maturity: app.KindMaturityAlpha,
+			Owner:    "foo-team",
+		}},
+	}
+	route, err := app.NewKindsRouteHandler(manifest)
+	require.Nil(t, err)
+	runner.appRoutes.Store(&runnerAppRoutes{
+		app: &testCustomRouteApp{
+			route: func(ctx context.Context, req *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error) {
+				if req.ResourceIdentifier.Group != manifest.Group || req.SubresourcePath != app.KindsRoutePath {
+					return nil, app.ErrCustomRouteNotFound
+				}
+				return route(ctx, req)
+			},
+		},
+		group: manifest.Group,
+	})
+	rec = httptest.NewRecorder()
+	runner.serveAppRoute(app.KindsRoutePath)(rec, httptest.NewRequest(http.MethodGet, KindsPath, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"appName":"foo","group":"foo.grafana.app","kinds":[{"kind":"Bar","scope":"Namespaced","versions":["v1"],"maturity":"alpha","owner":"foo-team"}]}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	runner.serveAppRoute(app.KindsRoutePath)(rec, httptest.NewRequest(http.MethodPost, KindsPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	// Apps which do not serve the route respond with not found
+	runner.appRoutes.Store(&runnerAppRoutes{
+		app: &testCustomRouteApp{
+			route: func(context.Context, *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error) {
+				return nil, app.ErrCustomRouteNotFound
+			},
+		},
+		group: manifest.Group,
+	})
+	rec = httptest.NewRecorder()
+	runner.serveAppRoute(app.KindsRoutePath)(rec, httptest.NewRequest(http.MethodGet, KindsPath, nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+type testCustomRouteApp struct {
+	app.App
+	route func(context.Context, *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error)
+}
+
+func (a *testCustomRouteApp) CallResourceCustomRoute(ctx context.Context, req *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error) {
+	return a.route(ctx, req)
+}
@@ -0,0 +1,70 @@
+package operator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// ErrNamespaceScopeMismatch indicates that the namespace in a ListWatchOptions is not valid for the scope of the kind
+// it is being used with (for example, a non-empty namespace for a cluster-scoped kind).
+var ErrNamespaceScopeMismatch = errors.New("namespace is not valid for the scope of the kind")
+
+// ScopeAwareInformer is an Informer which exposes the schema it watches and the ListWatchOptions it uses to watch it.
+// InformerController.AddInformer will validate the ListWatchOptions of any ScopeAwareInformer against
+// the scope of its schema, and return an error without adding the informer if they are incompatible.
+type ScopeAwareInformer interface {
+	Informer
+	Schema() resource.Schema
+	ListWatchOptions() ListWatchOptions
+}
+
+// AllNamespaces returns ListWatchOptions which watch resources in all namespaces, using the provided label filters.
+// This is the only valid namespace option for cluster-scoped kinds.
+func AllNamespaces(labelFilters ...string) ListWatchOptions {
+	return ListWatchOptions{
+		Namespace:    resource.NamespaceAll,
+		LabelFilters: labelFilters,
+	}
+}
+
+// IsAllNamespaces returns true if the ListWatchOptions watch resources in all namespaces
+func (o ListWatchOptions) IsAllNamespaces() bool {
+	return o.Namespace == resource.NamespaceAll
+}
+
+// ForNamespaces returns a copy of the ListWatchOptions for each provided namespace, retaining the label and field filters.
+// Duplicate namespaces are ignored. If no namespaces are provided, it returns a slice containing only the original ListWatchOptions.
+// Each returned ListWatchOptions can be used for a distinct informer, and all informers added to an InformerController
+// with the same resourceKind to fan their events in to the same watchers and reconcilers.
+func (o ListWatchOptions) ForNamespaces(namespaces ...string) []ListWatchOptions {
+	if len(namespaces) == 0 {
+		return []ListWatchOptions{o}
+	}
+	opts := make([]ListWatchOptions, 0, len(namespaces))
+	seen := make(map[string]struct{})
+	for _, ns := range namespaces {
+		if _, ok := seen[ns]; ok {
+			continue
+		}
+		seen[ns] = struct{}{}
+		cpy := o
+		cpy.Namespace = ns
+		opts = append(opts, cpy)
+	}
+	return opts
+}
+
+// ValidateListWatchOptions checks that the namespace in the provided ListWatchOptions is valid for the scope of sch.
+// Cluster-scoped kinds must use resource.NamespaceAll. It returns an error wrapping ErrNamespaceScopeMismatch if the check fails.
+func ValidateListWatchOptions(sch resource.Schema, opts ListWatchOptions) error {
+	if sch == nil {
+		return fmt.Errorf("schema cannot be nil")
+	}
+	if sch.Scope() == resource.ClusterScope && !opts.IsAllNamespaces() {
+		return fmt.Errorf("%w: kind %s is %s-scoped, but namespace '%s' was provided",
+			ErrNamespaceScopeMismatch, sch.Kind(), resource.ClusterScope, opts.Namespace)
+	}
+	return nil
+}
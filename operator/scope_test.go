@@ -0,0 +1,92 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestListWatchOptions_ForNamespaces(t *testing.T) {
+	base := ListWatchOptions{
+		LabelFilters:   []string{"foo=bar"},
+		FieldSelectors: []string{"spec.foo=bar"},
+	}
+
+	t.Run("no namespaces", func(t *testing.T) {
+		opts := base.ForNamespaces()
+		require.Len(t, opts, 1)
+		assert.Equal(t, base, opts[0])
+		assert.True(t, opts[0].IsAllNamespaces())
+	})
+
+	t.Run("multiple namespaces with duplicates", func(t *testing.T) {
+		opts := base.ForNamespaces("a", "b", "a")
+		require.Len(t, opts, 2)
+		assert.Equal(t, "a", opts[0].Namespace)
+		assert.Equal(t, "b", opts[1].Namespace)
+		for _, o := range opts {
+			assert.Equal(t, base.LabelFilters, o.LabelFilters)
+			assert.Equal(t, base.FieldSelectors, o.FieldSelectors)
+		}
+	})
+}
+
+func TestValidateListWatchOptions(t *testing.T) {
+	namespaced := resource.NewSimpleSchema("foo", "v1", &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("Foo"))
+	cluster := resource.NewSimpleSchema("foo", "v1", &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("Bar"), resource.WithScope(resource.ClusterScope))
+
+	t.Run("nil schema", func(t *testing.T) {
+		assert.NotNil(t, ValidateListWatchOptions(nil, AllNamespaces()))
+	})
+
+	t.Run("namespaced kind", func(t *testing.T) {
+		assert.Nil(t, ValidateListWatchOptions(namespaced, AllNamespaces()))
+		assert.Nil(t, ValidateListWatchOptions(namespaced, ListWatchOptions{Namespace: "foo"}))
+	})
+
+	t.Run("cluster kind", func(t *testing.T) {
+		assert.Nil(t, ValidateListWatchOptions(cluster, AllNamespaces()))
+		assert.ErrorIs(t, ValidateListWatchOptions(cluster, ListWatchOptions{Namespace: "foo"}), ErrNamespaceScopeMismatch)
+	})
+}
+
+func TestInformerController_AddInformer_ScopeValidation(t *testing.T) {
+	kind := resource.Kind{
+		Schema: resource.NewSimpleSchema("foo", "v1", &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("Bar"), resource.WithScope(resource.ClusterScope)),
+	}
+
+	t.Run("cluster-scoped with namespace", func(t *testing.T) {
+		inf, err := NewKubernetesBasedInformer(kind, &nopListWatchClient{}, KubernetesBasedInformerOptions{
+			ListWatchOptions: ListWatchOptions{Namespace: "foo"},
+		})
+		require.Nil(t, err)
+		c := NewInformerController(InformerControllerConfig{})
+		err = c.AddInformer(inf, "foo")
+		assert.ErrorIs(t, err, ErrNamespaceScopeMismatch)
+		assert.Equal(t, 0, c.informers.KeySize("foo"))
+	})
+
+	t.Run("cluster-scoped with all namespaces", func(t *testing.T) {
+		inf, err := NewKubernetesBasedInformer(kind, &nopListWatchClient{}, KubernetesBasedInformerOptions{
+			ListWatchOptions: AllNamespaces(),
+		})
+		require.Nil(t, err)
+		c := NewInformerController(InformerControllerConfig{})
+		assert.Nil(t, c.AddInformer(inf, "foo"))
+		assert.Equal(t, 1, c.informers.KeySize("foo"))
+	})
+}
+
+type nopListWatchClient struct{}
+
+func (*nopListWatchClient) ListInto(context.Context, string, resource.ListOptions, resource.ListObject) error {
+	return nil
+}
+
+func (*nopListWatchClient) Watch(context.Context, string, resource.WatchOptions) (resource.WatchResponse, error) {
+	return nil, nil
+}
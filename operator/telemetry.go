@@ -1,10 +1,16 @@
 package operator
 
 import (
+	"context"
+	"strings"
 	"sync"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana-app-sdk/resource"
 )
 
 var (
@@ -29,3 +35,43 @@ func GetTracer() trace.Tracer {
 	}
 	return tracer
 }
+
+// setObjectSpanAttributes sets the kind, namespace, and name of obj and the action of the event as attributes of span,
+// using the same attribute keys as the informer event spans
+func setObjectSpanAttributes(span trace.Span, action string, obj resource.Object) {
+	span.SetAttributes(attribute.String("action", action))
+	if obj == nil {
+		return
+	}
+	gvk := obj.GroupVersionKind()
+	span.SetAttributes(
+		attribute.String("kind.name", gvk.Kind),
+		attribute.String("kind.group", gvk.Group),
+		attribute.String("kind.version", gvk.Version),
+		attribute.String("namespace", obj.GetNamespace()),
+		attribute.String("name", obj.GetName()),
+	)
+}
+
+// startWatcherSpan starts the span for a call to a watcher for an event of eventType.
+// The kind, namespace, and name of the object are attributes of the parent event span.
+func startWatcherSpan(ctx context.Context, eventType, kind string) (context.Context, trace.Span) {
+	ctx, span := GetTracer().Start(ctx, "watcher-"+strings.ToLower(eventType))
+	span.SetAttributes(attribute.String("action", eventType), attribute.String("kind.name", kind))
+	return ctx, span
+}
+
+// startReconcilerSpan starts the span for a call to a reconciler with req
+func startReconcilerSpan(ctx context.Context, req ReconcileRequest, retry bool) (context.Context, trace.Span) {
+	ctx, span := GetTracer().Start(ctx, "reconciler-reconcile")
+	setObjectSpanAttributes(span, reconcileActionName(req.Action), req.Object)
+	span.SetAttributes(attribute.Bool("retry", retry))
+	return ctx, span
+}
+
+// setSpanError sets the status of span to an error if err is non-nil
+func setSpanError(span trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
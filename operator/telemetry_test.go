@@ -0,0 +1,95 @@
+package operator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/grafana/grafana-app-sdk/k8s"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestInformerController_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	SetTracer(provider.Tracer("operator"))
+	k8s.SetTracer(provider.Tracer("k8s"))
+	defer SetTracer(nil)
+	defer k8s.SetTracer(nil)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	kind := resource.Kind{
+		Schema: resource.NewSimpleSchema("foo.grafana.app", "v1", &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("Foo")),
+		Codecs: map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()},
+	}
+	client, err := k8s.NewClientRegistry(rest.Config{Host: srv.URL, APIPath: "/apis"}, k8s.ClientConfig{}).ClientFor(kind)
+	require.Nil(t, err)
+
+	inf := &testInformer{}
+	c := NewInformerController(InformerControllerConfig{})
+	require.Nil(t, c.AddWatcher(&SimpleWatcher{
+		AddFunc: func(context.Context, resource.Object) error {
+			return nil
+		},
+	}, "foo"))
+	require.Nil(t, c.AddReconciler(&SimpleReconciler{
+		ReconcileFunc: func(ctx context.Context, req ReconcileRequest) (ReconcileResult, error) {
+			// Client requests join the trace of the reconcile
+			_, err := client.Get(ctx, resource.Identifier{Namespace: req.Object.GetNamespace(), Name: "bar"})
+			return ReconcileResult{}, err
+		},
+	}, "foo"))
+	require.Nil(t, c.AddInformer(inf, "foo"))
+
+	obj := &resource.UntypedObject{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "foo.grafana.app/v1", Kind: "Foo"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"},
+	}
+	inf.FireAdd(context.Background(), obj)
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	require.Contains(t, spans, "controller-event-add")
+	require.Contains(t, spans, "watcher-create")
+	require.Contains(t, spans, "reconciler-reconcile")
+	require.Contains(t, spans, "kubernetes-get")
+	event := spans["controller-event-add"]
+	assert.Subset(t, event.Attributes(), []attribute.KeyValue{
+		attribute.String("action", "CREATE"),
+		attribute.String("kind.name", "Foo"),
+		attribute.String("kind.group", "foo.grafana.app"),
+		attribute.String("kind.version", "v1"),
+		attribute.String("namespace", "ns"),
+		attribute.String("name", "foo"),
+	})
+
+	// The watcher and reconciler spans are children of the event span, and the client span is a child of the reconciler span
+	assert.Equal(t, event.SpanContext().SpanID(), spans["watcher-create"].Parent().SpanID())
+	reconcile := spans["reconciler-reconcile"]
+	assert.Equal(t, event.SpanContext().TraceID(), reconcile.SpanContext().TraceID())
+	assert.Equal(t, reconcile.SpanContext().SpanID(), spans["kubernetes-get"].Parent().SpanID())
+	assert.Subset(t, reconcile.Attributes(), []attribute.KeyValue{
+		attribute.String("action", "CREATE"),
+		attribute.String("kind.name", "Foo"),
+		attribute.String("namespace", "ns"),
+		attribute.String("name", "foo"),
+		attribute.Bool("retry", false),
+	})
+	assert.Equal(t, codes.Error, reconcile.Status().Code)
+	assert.NotEmpty(t, reconcile.Status().Description)
+}
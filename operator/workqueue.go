@@ -0,0 +1,234 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// reconcileQueue is a work queue for a single Reconciler, which runs queued work with a fixed number of workers.
+// Work is queued by key (the object's retry key), and work for the same key is run sequentially in the order it was queued,
+// so that events for an object are never reconciled concurrently or out of order, while distinct objects are
+// reconciled in parallel. It must be run with Run before any queued work is processed.
+//
+// Keys with work ready to run are grouped into flows, and workers take keys from each flow in turn (weighted round-robin).
+// Without fair queueing, all work is in one flow, so keys are run in the order they became ready.
+// With fair queueing, each namespace (and priority) is its own flow, so a namespace with many queued keys
+// only gets its share of the workers.
+type reconcileQueue struct {
+	workers        int
+	requeueLimiter *rate.Limiter
+	fairness       *FairQueueing
+	mux            sync.Mutex
+	cond           *sync.Cond
+	// pending is the queued work, by key
+	pending map[string][]queuedWork
+	// flows is the flows with keys which have pending work and are not currently being processed
+	flows map[string]*workFlow
+	// order is the order the flows in flows take turns in
+	order []string
+	// turns is the number of keys left for order[0] to run in its current turn
+	turns int
+	// active is the set of keys currently being processed by a worker
+	active map[string]struct{}
+	size   int
+}
+
+type queuedWork struct {
+	work   func()
+	flow   string
+	weight int
+}
+
+// workFlow is the keys of a flow which are ready to run, in the order they became ready
+type workFlow struct {
+	ready  []string
+	weight int
+}
+
+func newReconcileQueue(workers int, requeueLimiter *rate.Limiter, fairness *FairQueueing) *reconcileQueue {
+	q := &reconcileQueue{
+		workers:        workers,
+		requeueLimiter: requeueLimiter,
+		fairness:       fairness,
+		pending:        make(map[string][]queuedWork),
+		flows:          make(map[string]*workFlow),
+		active:         make(map[string]struct{}),
+	}
+	q.cond = sync.NewCond(&q.mux)
+	return q
+}
+
+// add queues the work for key, which is for obj
+func (q *reconcileQueue) add(key string, obj resource.Object, work func()) {
+	flow, weight := q.fairness.flow(obj)
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	q.pending[key] = append(q.pending[key], queuedWork{
+		work:   work,
+		flow:   flow,
+		weight: weight,
+	})
+	q.size++
+	if _, ok := q.active[key]; !ok && len(q.pending[key]) == 1 {
+		q.ready(key)
+	}
+}
+
+// ready adds key, which has pending work, to the flow of its next work. The caller must hold q.mux.
+func (q *reconcileQueue) ready(key string) {
+	next := q.pending[key][0]
+	flow, ok := q.flows[next.flow]
+	if !ok {
+		flow = &workFlow{}
+		q.flows[next.flow] = flow
+		q.order = append(q.order, next.flow)
+	}
+	flow.ready = append(flow.ready, key)
+	flow.weight = next.weight
+	q.cond.Signal()
+}
+
+// next removes and returns the next key to run, from the flow whose turn it is. The caller must hold q.mux,
+// and there must be a ready key.
+func (q *reconcileQueue) next() string {
+	name := q.order[0]
+	flow := q.flows[name]
+	if q.turns <= 0 {
+		q.turns = flow.weight
+	}
+	key := flow.ready[0]
+	flow.ready = flow.ready[1:]
+	q.turns--
+	switch {
+	case len(flow.ready) == 0:
+		delete(q.flows, name)
+		q.order = q.order[1:]
+		q.turns = 0
+	case q.turns == 0:
+		// The flow's turn is over, so it goes to the back of the order
+		q.order = append(q.order[1:], name)
+	}
+	return key
+}
+
+// allowRequeue returns true if a retry can be queued now, according to the requeue rate limit
+func (q *reconcileQueue) allowRequeue() bool {
+	return q.requeueLimiter == nil || q.requeueLimiter.Allow()
+}
+
+// len returns the number of queued items of work which have not yet started
+func (q *reconcileQueue) len() int {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	return q.size
+}
+
+// Run runs the queue's workers until ctx is canceled. Work still queued when ctx is canceled is not run.
+func (q *reconcileQueue) Run(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() {
+		q.mux.Lock()
+		defer q.mux.Unlock()
+		q.cond.Broadcast()
+	})
+	defer stop()
+	wg := sync.WaitGroup{}
+	for i := 0; i < q.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if !q.processNext(ctx) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// processNext waits for work, and runs the next item. It returns false once ctx is canceled.
+func (q *reconcileQueue) processNext(ctx context.Context) bool {
+	q.mux.Lock()
+	for len(q.order) == 0 && ctx.Err() == nil {
+		q.cond.Wait()
+	}
+	if ctx.Err() != nil {
+		q.mux.Unlock()
+		return false
+	}
+	key := q.next()
+	work := q.pending[key][0]
+	q.pending[key] = q.pending[key][1:]
+	q.size--
+	q.active[key] = struct{}{}
+	q.mux.Unlock()
+
+	work.work()
+
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	delete(q.active, key)
+	if len(q.pending[key]) > 0 {
+		q.ready(key)
+	} else {
+		delete(q.pending, key)
+	}
+	return true
+}
+
+// FairQueueing configures a Reconciler's work queue to share its workers between namespaces, so that a namespace
+// with many queued events (such as one with a very large number of objects) cannot delay the reconciles of other namespaces.
+// Queued work is grouped into a flow for each namespace and priority, and workers take work from each flow in turn.
+// Each turn runs up to the weight of the flow's priority, so higher priority flows get a larger share of the workers.
+// Events for each object are still reconciled one at a time, in the order they were received.
+type FairQueueing struct {
+	// PriorityLabel is the label of an object which sets the priority of its reconciles.
+	// If empty, every namespace has the same priority, and workers take turns between namespaces (round-robin).
+	PriorityLabel string
+	// PriorityWeights are the weights of values of PriorityLabel, which are the number of reconciles
+	// a flow with the priority runs in each turn. Objects without the label, or with a value which is not in
+	// PriorityWeights, have a weight of 1. Weights must be positive.
+	PriorityWeights map[string]int
+}
+
+// validate returns an error if f has a weight which is not positive
+func (f *FairQueueing) validate() error {
+	if f == nil {
+		return nil
+	}
+	for value, weight := range f.PriorityWeights {
+		if weight <= 0 {
+			return fmt.Errorf("weight of priority '%s' must be positive", value)
+		}
+	}
+	return nil
+}
+
+// flow returns the flow of work for obj, and its weight. If f is nil, all work is in a single flow.
+func (f *FairQueueing) flow(obj resource.Object) (string, int) {
+	if f == nil || obj == nil {
+		return "", 1
+	}
+	priority := ""
+	weight := 1
+	if f.PriorityLabel != "" {
+		priority = obj.GetLabels()[f.PriorityLabel]
+		if w, ok := f.PriorityWeights[priority]; ok {
+			weight = w
+		}
+	}
+	return priority + "/" + obj.GetNamespace(), weight
+}
+
+// queuedReconciler is a Reconciler added to an InformerController with a reconcileQueue.
+// Its reconcile calls (and their retries) are run by the queue, rather than in the informer's event handler.
+type queuedReconciler struct {
+	Reconciler
+	queue *reconcileQueue
+}
@@ -0,0 +1,191 @@
+package operator
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestReconcileQueue(t *testing.T) {
+	t.Run("same key is run sequentially in order", func(t *testing.T) {
+		q := newReconcileQueue(4, nil, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go q.Run(ctx)
+
+		mux := sync.Mutex{}
+		order := make([]int, 0)
+		running := atomic.Int32{}
+		overlapped := atomic.Bool{}
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			q.add("foo", nil, func() {
+				defer wg.Done()
+				if running.Add(1) > 1 {
+					overlapped.Store(true)
+				}
+				time.Sleep(time.Millisecond)
+				mux.Lock()
+				order = append(order, i)
+				mux.Unlock()
+				running.Add(-1)
+			})
+		}
+		wg.Wait()
+		assert.False(t, overlapped.Load())
+		assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, order)
+		assert.Equal(t, 0, q.len())
+	})
+
+	t.Run("different keys are run in parallel", func(t *testing.T) {
+		q := newReconcileQueue(2, nil, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go q.Run(ctx)
+
+		// Each item waits for the other to start, so this only completes if both run at once
+		started := sync.WaitGroup{}
+		started.Add(2)
+		done := make(chan struct{}, 2)
+		for _, key := range []string{"foo", "bar"} {
+			q.add(key, nil, func() {
+				started.Done()
+				started.Wait()
+				done <- struct{}{}
+			})
+		}
+		for i := 0; i < 2; i++ {
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for parallel work")
+			}
+		}
+	})
+
+	t.Run("stops on cancel", func(t *testing.T) {
+		q := newReconcileQueue(2, nil, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- q.Run(ctx)
+		}()
+		cancel()
+		select {
+		case err := <-errCh:
+			assert.Nil(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for Run to return")
+		}
+	})
+
+	t.Run("requeue rate limit", func(t *testing.T) {
+		assert.True(t, newReconcileQueue(1, nil, nil).allowRequeue())
+		q := newReconcileQueue(1, rate.NewLimiter(rate.Every(time.Hour), 1), nil)
+		assert.True(t, q.allowRequeue())
+		assert.False(t, q.allowRequeue())
+	})
+	t.Run("fair queueing", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			fairness *FairQueueing
+			objects  []resource.Object
+			expected []string
+		}{{
+			name: "no fair queueing",
+			objects: []resource.Object{
+				newListerTestObject("a", "1"), newListerTestObject("a", "2"), newListerTestObject("a", "3"),
+				newListerTestObject("b", "1"), newListerTestObject("c", "1"),
+			},
+			expected: []string{"a/1", "a/2", "a/3", "b/1", "c/1"},
+		}, {
+			name:     "round-robin",
+			fairness: &FairQueueing{},
+			objects: []resource.Object{
+				newListerTestObject("a", "1"), newListerTestObject("a", "2"), newListerTestObject("a", "3"),
+				newListerTestObject("a", "4"), newListerTestObject("b", "1"), newListerTestObject("b", "2"),
+				newListerTestObject("c", "1"),
+			},
+			expected: []string{"a/1", "b/1", "c/1", "a/2", "b/2", "a/3", "a/4"},
+		}, {
+			name: "weighted",
+			fairness: &FairQueueing{
+				PriorityLabel:   "priority",
+				PriorityWeights: map[string]int{"high": 3},
+			},
+			objects: []resource.Object{
+				newListerTestObject("a", "1"), newListerTestObject("a", "2"), newListerTestObject("a", "3", "priority", "unknown"),
+				newListerTestObject("b", "1", "priority", "high"), newListerTestObject("b", "2", "priority", "high"),
+				newListerTestObject("b", "3", "priority", "high"), newListerTestObject("b", "4", "priority", "high"),
+			},
+			// a/3 has a different priority from a/1 and a/2, so it is in its own flow, with a weight of 1
+			expected: []string{"a/1", "a/3", "b/1", "b/2", "b/3", "a/2", "b/4"},
+		}}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				q := newReconcileQueue(1, nil, test.fairness)
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				// Block the single worker until all the work is queued
+				gate := make(chan struct{})
+				q.add("gate", nil, func() {
+					<-gate
+				})
+				mux := sync.Mutex{}
+				order := make([]string, 0)
+				wg := sync.WaitGroup{}
+				for _, obj := range test.objects {
+					wg.Add(1)
+					key := obj.GetNamespace() + "/" + obj.GetName()
+					q.add(key, obj, func() {
+						defer wg.Done()
+						mux.Lock()
+						defer mux.Unlock()
+						order = append(order, key)
+					})
+				}
+				go q.Run(ctx)
+				close(gate)
+				wg.Wait()
+				assert.Equal(t, test.expected, order)
+				assert.Equal(t, 0, q.len())
+			})
+		}
+	})
+
+	t.Run("fair queueing runs each key in order", func(t *testing.T) {
+		q := newReconcileQueue(2, nil, &FairQueueing{})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go q.Run(ctx)
+
+		mux := sync.Mutex{}
+		order := make(map[string][]int)
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			for _, ns := range []string{"a", "b"} {
+				wg.Add(1)
+				q.add(ns+"/foo", newListerTestObject(ns, "foo"), func() {
+					defer wg.Done()
+					mux.Lock()
+					defer mux.Unlock()
+					order[ns] = append(order[ns], i)
+				})
+			}
+		}
+		wg.Wait()
+		assert.Equal(t, map[string][]int{
+			"a": {0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+			"b": {0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		}, order)
+	})
+}
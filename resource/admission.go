@@ -41,6 +41,48 @@ type AdmissionUserInfo struct {
 	Extra map[string]any
 }
 
+// AdmissionContext contains information about an admission or conversion request which is not part of the object(s)
+// in the request, such as whether it is a dry run and the options it was made with.
+// It is attached to the context passed to admission controllers and converters, and can be retrieved with AdmissionContextFromContext.
+type AdmissionContext struct {
+	// RequestUID is the unique ID of the request, which is the same across any retries of the same request
+	RequestUID string
+	// Action is the type of request being checked for admission. It is empty for conversion requests.
+	Action AdmissionAction
+	// UserInfo is user information about the user making the request. It is empty for conversion requests.
+	UserInfo AdmissionUserInfo
+	// DryRun is true if the request is a dry run, in which case the request will not be persisted,
+	// and admission controllers should not make any external changes (side effects) when handling it
+	DryRun bool
+	// SubResource is the subresource being requested, if any (such as "status")
+	SubResource string
+	// Options are the options the request was made with
+	Options AdmissionOptions
+}
+
+// AdmissionOptions are the options of the request an AdmissionContext is for.
+// Not every type of request has every option, so unset options are zero-valued.
+type AdmissionOptions struct {
+	// FieldManager is the field manager of the create, update, or patch request
+	FieldManager string
+	// Force is true if the request is a server-side apply patch which forces conflicts
+	Force bool
+}
+
+type admissionContextKey struct{}
+
+// WithAdmissionContext returns a copy of ctx carrying admissionContext
+func WithAdmissionContext(ctx context.Context, admissionContext AdmissionContext) context.Context {
+	return context.WithValue(ctx, admissionContextKey{}, admissionContext)
+}
+
+// AdmissionContextFromContext returns the AdmissionContext carried by ctx, if any.
+// It is set on the context passed to admission controllers and converters by the webhook server.
+func AdmissionContextFromContext(ctx context.Context) (AdmissionContext, bool) {
+	admissionContext, ok := ctx.Value(admissionContextKey{}).(AdmissionContext)
+	return admissionContext, ok
+}
+
 // AdmissionError is an interface which extends error to add more details for admission request rejections
 type AdmissionError interface {
 	error
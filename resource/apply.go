@@ -0,0 +1,250 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ApplyOutcome describes what happened to a single object during a Store.ApplyAll call
+type ApplyOutcome string
+
+const (
+	// ApplyOutcomeCreated means the object did not exist and was created
+	ApplyOutcomeCreated = ApplyOutcome("created")
+	// ApplyOutcomeUpdated means the object already existed and was updated
+	ApplyOutcomeUpdated = ApplyOutcome("updated")
+	// ApplyOutcomeFailed means applying the object failed, which caused the rollback of the set
+	ApplyOutcomeFailed = ApplyOutcome("failed")
+	// ApplyOutcomeSkipped means the object was not applied because an earlier object failed
+	ApplyOutcomeSkipped = ApplyOutcome("skipped")
+	// ApplyOutcomeRolledBack means the object was applied, and then restored to its prior state
+	// (deleted if it was created, or updated to its prior state, including its status, if it was updated) after a later object failed
+	ApplyOutcomeRolledBack = ApplyOutcome("rolled back")
+	// ApplyOutcomeRollbackFailed means the object was applied, but could not be restored to its prior state
+	ApplyOutcomeRollbackFailed = ApplyOutcome("rollback failed")
+)
+
+// ErrApplyDependencyCycle is returned by Store.ApplyAll when the owner references of the provided objects form a cycle
+var ErrApplyDependencyCycle = errors.New("owner references of objects form a cycle")
+
+// ApplyAllOptions are options for Store.ApplyAll
+type ApplyAllOptions struct {
+	// KindOrder is an optional list of kinds in the order they should be applied.
+	// Kinds not present in KindOrder are applied after all listed kinds.
+	// Regardless of KindOrder, an object is always applied after any other object in the set referenced in its owner references.
+	KindOrder []string
+}
+
+// ApplyObjectResult is the result of applying a single object in a Store.ApplyAll call
+type ApplyObjectResult struct {
+	Kind       string
+	Identifier Identifier
+	// Outcome is the final outcome for the object
+	Outcome ApplyOutcome
+	// Object is the object returned by the storage system when it was applied.
+	// It is nil if the object was not applied.
+	Object Object
+	// Error is the error encountered while applying or rolling back the object, if any
+	Error error
+}
+
+// ApplyResult is the result of a Store.ApplyAll call
+type ApplyResult struct {
+	// Objects contains the result for each object, in the order they were applied
+	Objects []ApplyObjectResult
+	// RolledBack is true if an object failed to apply, and a rollback was attempted
+	RolledBack bool
+}
+
+// ApplyAll applies the provided objects (of any kind registered with the Store) as a unit.
+// Objects are created if they do not exist, or updated to match the provided object if they do.
+// They are applied in dependency order (see ApplyAllOptions.KindOrder), and the prior state of each object is recorded
+// before it is applied. If any object fails to apply, all objects already applied are rolled back in reverse order:
+// created objects are deleted, and updated objects (and their status subresources) are updated back to their prior state.
+// Rollback is best-effort, as other writers may change objects while ApplyAll is running.
+// The returned ApplyResult contains the outcome for every object, and is returned even when an error is returned.
+func (s *Store) ApplyAll(ctx context.Context, objects []Object, options ApplyAllOptions) (*ApplyResult, error) {
+	for _, obj := range objects {
+		if obj.GetStaticMetadata().Kind == "" {
+			return nil, fmt.Errorf("obj.GetStaticMetadata().Kind must not be empty")
+		}
+		if obj.GetName() == "" {
+			return nil, fmt.Errorf("obj.GetName() must not be empty")
+		}
+		// Resolve all clients before applying anything, so an unregistered kind doesn't leave a partial apply
+		if _, err := s.getClient(obj.GetStaticMetadata().Kind); err != nil {
+			return nil, err
+		}
+	}
+	ordered, err := orderForApply(objects, options.KindOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ApplyResult{
+		Objects: make([]ApplyObjectResult, len(ordered)),
+	}
+	priors := make([]Object, len(ordered))
+	for i, obj := range ordered {
+		result.Objects[i] = ApplyObjectResult{
+			Kind:       obj.GetStaticMetadata().Kind,
+			Identifier: obj.GetStaticMetadata().Identifier(),
+			Outcome:    ApplyOutcomeSkipped,
+		}
+	}
+	for i, obj := range ordered {
+		res := &result.Objects[i]
+		client, _ := s.getClient(res.Kind)
+		prior, applied, err := applyObject(ctx, client, res.Identifier, obj)
+		if err != nil {
+			res.Outcome = ApplyOutcomeFailed
+			res.Error = err
+			result.RolledBack = true
+			rollbackApplied(ctx, s, result.Objects[:i], priors[:i])
+			return result, fmt.Errorf("unable to apply %s '%s': %w", res.Kind, res.Identifier.Name, err)
+		}
+		priors[i] = prior
+		res.Object = applied
+		res.Outcome = ApplyOutcomeUpdated
+		if prior == nil {
+			res.Outcome = ApplyOutcomeCreated
+		}
+	}
+	return result, nil
+}
+
+// applyObject creates or updates obj, returning the prior state of the object (nil if it did not exist)
+func applyObject(ctx context.Context, client Client, identifier Identifier, obj Object) (Object, Object, error) {
+	prior, err := client.Get(ctx, identifier)
+	if err != nil {
+		if !IsNotFound(err) {
+			return nil, nil, err
+		}
+		created, err := client.Create(ctx, identifier, obj, CreateOptions{})
+		return nil, created, err
+	}
+	toUpdate := obj.Copy()
+	toUpdate.SetResourceVersion(prior.GetResourceVersion())
+	updated, err := client.Update(ctx, identifier, toUpdate, UpdateOptions{
+		ResourceVersion: prior.GetResourceVersion(),
+	})
+	return prior, updated, err
+}
+
+// rollbackApplied restores each applied object in results to its prior state, in reverse order
+func rollbackApplied(ctx context.Context, s *Store, results []ApplyObjectResult, priors []Object) {
+	for i := len(results) - 1; i >= 0; i-- {
+		res := &results[i]
+		client, _ := s.getClient(res.Kind)
+		var err error
+		if priors[i] == nil {
+			err = client.Delete(ctx, res.Identifier, DeleteOptions{})
+			if IsNotFound(err) {
+				err = nil
+			}
+		} else {
+			err = restorePrior(ctx, client, res.Identifier, priors[i])
+		}
+		if err != nil {
+			res.Outcome = ApplyOutcomeRollbackFailed
+			res.Error = err
+			continue
+		}
+		res.Outcome = ApplyOutcomeRolledBack
+	}
+}
+
+// restorePrior updates the object to prior, and then updates its status subresource to the status of prior,
+// as the status is not changed by updates of the object when the status subresource is enabled
+func restorePrior(ctx context.Context, client Client, identifier Identifier, prior Object) error {
+	current, err := client.Get(ctx, identifier)
+	if err != nil {
+		return err
+	}
+	toUpdate := prior.Copy()
+	toUpdate.SetResourceVersion(current.GetResourceVersion())
+	updated, err := client.Update(ctx, identifier, toUpdate, UpdateOptions{
+		ResourceVersion: current.GetResourceVersion(),
+	})
+	if err != nil {
+		return err
+	}
+	if status, ok := prior.GetSubresource(string(SubresourceStatus)); !ok || status == nil {
+		return nil
+	}
+	toUpdate = prior.Copy()
+	toUpdate.SetResourceVersion(updated.GetResourceVersion())
+	_, err = client.Update(ctx, identifier, toUpdate, UpdateOptions{
+		ResourceVersion: updated.GetResourceVersion(),
+		Subresource:     string(SubresourceStatus),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to restore status: %w", err)
+	}
+	return nil
+}
+
+// orderForApply stably sorts objects by kindOrder, and then moves each object after any owners which are also in objects
+func orderForApply(objects []Object, kindOrder []string) ([]Object, error) {
+	kindIndex := make(map[string]int, len(kindOrder))
+	for i, k := range kindOrder {
+		kindIndex[k] = i
+	}
+	rank := func(obj Object) int {
+		if i, ok := kindIndex[obj.GetStaticMetadata().Kind]; ok {
+			return i
+		}
+		return len(kindOrder)
+	}
+	sorted := make([]Object, len(objects))
+	copy(sorted, objects)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return rank(sorted[i]) < rank(sorted[j])
+	})
+
+	type key struct {
+		kind      string
+		namespace string
+		name      string
+	}
+	byKey := make(map[key]int, len(sorted))
+	for i, obj := range sorted {
+		byKey[key{obj.GetStaticMetadata().Kind, obj.GetNamespace(), obj.GetName()}] = i
+	}
+	// Depth-first topological sort over owner references, visiting objects in their sorted order
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make([]int, len(sorted))
+	ordered := make([]Object, 0, len(sorted))
+	var visit func(i int) error
+	visit = func(i int) error {
+		obj := sorted[i]
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w: %s '%s'", ErrApplyDependencyCycle, obj.GetStaticMetadata().Kind, obj.GetName())
+		}
+		state[i] = visiting
+		for _, ref := range obj.GetOwnerReferences() {
+			if owner, ok := byKey[key{ref.Kind, obj.GetNamespace(), ref.Name}]; ok {
+				if err := visit(owner); err != nil {
+					return err
+				}
+			}
+		}
+		state[i] = visited
+		ordered = append(ordered, obj)
+		return nil
+	}
+	for i := range sorted {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,196 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStore_ApplyAll(t *testing.T) {
+	parentKind := Kind{NewSimpleSchema("g1", "v1", &UntypedObject{}, &UntypedList{}, WithKind("Parent")), map[KindEncoding]Codec{KindEncodingJSON: &JSONCodec{}}}
+	childKind := Kind{NewSimpleSchema("g1", "v1", &UntypedObject{}, &UntypedList{}, WithKind("Child")), map[KindEncoding]Codec{KindEncodingJSON: &JSONCodec{}}}
+	newObj := func(kind, name string, owner string) *UntypedObject {
+		obj := &UntypedObject{}
+		obj.Kind = kind
+		obj.SetName(name)
+		obj.SetNamespace("ns")
+		if owner != "" {
+			obj.SetOwnerReferences([]metav1.OwnerReference{{Kind: "Parent", Name: owner}})
+		}
+		return obj
+	}
+	setup := func() (*Store, map[string]*applyTestClient) {
+		clients := map[string]*applyTestClient{
+			"Parent": {objects: make(map[Identifier]Object)},
+			"Child":  {objects: make(map[Identifier]Object)},
+		}
+		store := NewStore(&mockClientGenerator{
+			ClientForFunc: func(kind Kind) (Client, error) {
+				return clients[kind.Kind()], nil
+			},
+		})
+		store.Register(parentKind)
+		store.Register(childKind)
+		return store, clients
+	}
+
+	t.Run("applies in dependency order", func(t *testing.T) {
+		store, clients := setup()
+		existing := newObj("Parent", "p", "")
+		existing.SetResourceVersion("5")
+		clients["Parent"].objects[Identifier{Namespace: "ns", Name: "p"}] = existing
+
+		res, err := store.ApplyAll(context.Background(), []Object{
+			newObj("Child", "c", "p"),
+			newObj("Parent", "p", ""),
+		}, ApplyAllOptions{})
+		require.Nil(t, err)
+		require.Len(t, res.Objects, 2)
+		assert.Equal(t, "Parent", res.Objects[0].Kind)
+		assert.Equal(t, ApplyOutcomeUpdated, res.Objects[0].Outcome)
+		assert.Equal(t, "Child", res.Objects[1].Kind)
+		assert.Equal(t, ApplyOutcomeCreated, res.Objects[1].Outcome)
+		assert.False(t, res.RolledBack)
+	})
+
+	t.Run("rolls back on failure", func(t *testing.T) {
+		store, clients := setup()
+		existing := newObj("Parent", "p", "")
+		existing.SetResourceVersion("5")
+		existing.SetLabels(map[string]string{"version": "old"})
+		clients["Parent"].objects[Identifier{Namespace: "ns", Name: "p"}] = existing
+		createErr := errors.New("I AM ERROR")
+		clients["Child"].createErr = map[string]error{"c2": createErr}
+
+		updated := newObj("Parent", "p", "")
+		updated.SetLabels(map[string]string{"version": "new"})
+		res, err := store.ApplyAll(context.Background(), []Object{
+			updated,
+			newObj("Child", "c1", ""),
+			newObj("Child", "c2", ""),
+			newObj("Child", "c3", ""),
+		}, ApplyAllOptions{KindOrder: []string{"Parent", "Child"}})
+		assert.ErrorIs(t, err, createErr)
+		require.NotNil(t, res)
+		assert.True(t, res.RolledBack)
+		outcomes := make([]ApplyOutcome, 0)
+		for _, o := range res.Objects {
+			outcomes = append(outcomes, o.Outcome)
+		}
+		assert.Equal(t, []ApplyOutcome{ApplyOutcomeRolledBack, ApplyOutcomeRolledBack, ApplyOutcomeFailed, ApplyOutcomeSkipped}, outcomes)
+		assert.Empty(t, clients["Child"].objects)
+		assert.Equal(t, "old", clients["Parent"].objects[Identifier{Namespace: "ns", Name: "p"}].GetLabels()["version"])
+	})
+
+	t.Run("rollback restores status", func(t *testing.T) {
+		store, clients := setup()
+		existing := newObj("Parent", "p", "")
+		existing.SetResourceVersion("5")
+		existing.Subresources = map[string]json.RawMessage{"status": json.RawMessage(`{"state":"old"}`)}
+		clients["Parent"].objects[Identifier{Namespace: "ns", Name: "p"}] = existing
+		clients["Child"].createErr = map[string]error{"c": errors.New("I AM ERROR")}
+
+		// Change the parent's status after it is applied, the way a reconciler would
+		clients["Child"].onCreate = func() {
+			parent := clients["Parent"].objects[Identifier{Namespace: "ns", Name: "p"}]
+			require.Nil(t, parent.SetSubresource("status", json.RawMessage(`{"state":"new"}`)))
+		}
+		res, err := store.ApplyAll(context.Background(), []Object{
+			newObj("Parent", "p", ""),
+			newObj("Child", "c", ""),
+		}, ApplyAllOptions{KindOrder: []string{"Parent", "Child"}})
+		assert.NotNil(t, err)
+		require.NotNil(t, res)
+		assert.Equal(t, ApplyOutcomeRolledBack, res.Objects[0].Outcome)
+		status, ok := clients["Parent"].objects[Identifier{Namespace: "ns", Name: "p"}].GetSubresource("status")
+		require.True(t, ok)
+		assert.JSONEq(t, `{"state":"old"}`, string(status.(json.RawMessage)))
+	})
+
+	t.Run("unregistered kind", func(t *testing.T) {
+		store, clients := setup()
+		_, err := store.ApplyAll(context.Background(), []Object{
+			newObj("Parent", "p", ""),
+			newObj("Other", "o", ""),
+		}, ApplyAllOptions{})
+		assert.NotNil(t, err)
+		assert.Empty(t, clients["Parent"].objects)
+	})
+
+	t.Run("dependency cycle", func(t *testing.T) {
+		store, _ := setup()
+		_, err := store.ApplyAll(context.Background(), []Object{
+			newObj("Parent", "a", "b"),
+			newObj("Parent", "b", "a"),
+		}, ApplyAllOptions{})
+		assert.ErrorIs(t, err, ErrApplyDependencyCycle)
+	})
+}
+
+// applyTestClient is an in-memory Client which implements only the methods used by Store.ApplyAll
+type applyTestClient struct {
+	Client
+	objects   map[Identifier]Object
+	createErr map[string]error
+	// onCreate, if set, is called before each object is created
+	onCreate func()
+	rv       int
+}
+
+func (c *applyTestClient) Get(_ context.Context, identifier Identifier) (Object, error) {
+	obj, ok := c.objects[identifier]
+	if !ok {
+		return nil, &testAPIError{err: fmt.Errorf("not found"), statusCode: http.StatusNotFound}
+	}
+	return obj.Copy(), nil
+}
+
+func (c *applyTestClient) Create(_ context.Context, identifier Identifier, obj Object, _ CreateOptions) (Object, error) {
+	if c.onCreate != nil {
+		c.onCreate()
+	}
+	if err := c.createErr[identifier.Name]; err != nil {
+		return nil, err
+	}
+	c.rv++
+	obj.SetResourceVersion(strconv.Itoa(c.rv))
+	c.objects[identifier] = obj
+	return obj, nil
+}
+
+func (c *applyTestClient) Update(_ context.Context, identifier Identifier, obj Object, options UpdateOptions) (Object, error) {
+	current := c.objects[identifier]
+	if current.GetResourceVersion() != options.ResourceVersion {
+		return nil, &testAPIError{err: fmt.Errorf("conflict"), statusCode: http.StatusConflict}
+	}
+	// Like the API server with the status subresource enabled, updates of the object keep the current status,
+	// and updates of the status change only the status
+	obj = obj.Copy()
+	status, ok := current.GetSubresource(string(SubresourceStatus))
+	if options.Subresource == string(SubresourceStatus) {
+		status, ok = obj.GetSubresource(string(SubresourceStatus))
+		obj = current.Copy()
+	}
+	if ok {
+		if err := obj.SetSubresource(string(SubresourceStatus), status); err != nil {
+			return nil, err
+		}
+	}
+	c.rv++
+	obj.SetResourceVersion(strconv.Itoa(c.rv))
+	c.objects[identifier] = obj
+	return obj, nil
+}
+
+func (c *applyTestClient) Delete(_ context.Context, identifier Identifier, _ DeleteOptions) error {
+	delete(c.objects, identifier)
+	return nil
+}
@@ -0,0 +1,126 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/runtime/serializer/cbor/direct"
+)
+
+// selfDescribedCBOR is the encoded head of the "self-described CBOR" tag (55799), which the kubernetes
+// CBOR serializer prefixes to every object it encodes, and uses to recognize CBOR-encoded data.
+var selfDescribedCBOR = []byte{0xd9, 0xd9, 0xf7}
+
+// NewCBORCodec returns a pointer to a new CBORCodec instance.
+// The opts are used to configure the JSONCodec that the CBORCodec transcodes through.
+func NewCBORCodec(opts ...JSONCodecOption) *CBORCodec {
+	return &CBORCodec{
+		json: NewJSONCodec(opts...),
+	}
+}
+
+// CBORCodec is a Codec-implementing struct that reads and writes kubernetes-formatted CBOR bytes,
+// as used by the CBOR wire encoding supported by kubernetes 1.32+.
+// Objects are transcoded to and from JSON with a JSONCodec, so they encode identically to their JSON representation.
+type CBORCodec struct {
+	json *JSONCodec
+}
+
+// Read reads CBOR bytes from in, and unmarshals them into out
+func (c *CBORCodec) Read(in io.Reader, out Object) error {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		// Match the JSONCodec behavior for empty input
+		return io.EOF
+	}
+	j, err := CBORToJSON(data)
+	if err != nil {
+		return err
+	}
+	return c.json.Read(bytes.NewReader(j), out)
+}
+
+// Write marshals the provided Object into kubernetes-formatted CBOR bytes.
+func (c *CBORCodec) Write(out io.Writer, in Object) error {
+	buf := bytes.Buffer{}
+	if err := c.json.Write(&buf, in); err != nil {
+		return err
+	}
+	b, err := JSONToCBOR(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(b)
+	return err
+}
+
+// JSONToCBOR transcodes JSON bytes to CBOR bytes, prefixed with the self-described CBOR tag.
+func JSONToCBOR(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	v, err := fromJSONNumbers(v)
+	if err != nil {
+		return nil, err
+	}
+	b, err := direct.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(make([]byte, 0, len(selfDescribedCBOR)+len(b)), selfDescribedCBOR...), b...), nil
+}
+
+// CBORToJSON transcodes CBOR bytes, with or without the self-described CBOR tag prefix, to JSON bytes.
+func CBORToJSON(data []byte) ([]byte, error) {
+	var v any
+	if err := direct.Unmarshal(bytes.TrimPrefix(data, selfDescribedCBOR), &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// IsCBOR returns true if data begins with the self-described CBOR tag.
+func IsCBOR(data []byte) bool {
+	return bytes.HasPrefix(data, selfDescribedCBOR)
+}
+
+// fromJSONNumbers replaces json.Number values (recursively) with int64 if they are an integer,
+// and float64 otherwise, so that integers remain integers in the CBOR encoding.
+func fromJSONNumbers(v any) (any, error) {
+	switch cast := v.(type) {
+	case json.Number:
+		if i, err := cast.Int64(); err == nil {
+			return i, nil
+		}
+		f, err := cast.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert number '%s': %w", cast, err)
+		}
+		return f, nil
+	case map[string]any:
+		for k, val := range cast {
+			conv, err := fromJSONNumbers(val)
+			if err != nil {
+				return nil, err
+			}
+			cast[k] = conv
+		}
+	case []any:
+		for i, val := range cast {
+			conv, err := fromJSONNumbers(val)
+			if err != nil {
+				return nil, err
+			}
+			cast[i] = conv
+		}
+	}
+	return v, nil
+}
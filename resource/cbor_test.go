@@ -0,0 +1,79 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCBORCodec(t *testing.T) {
+	obj := &UntypedObject{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "foo.bar/v1",
+			Kind:       "Foo",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test",
+			Namespace:       "default",
+			ResourceVersion: "12345",
+			Generation:      3,
+			Labels:          map[string]string{"a": "b"},
+		},
+		Spec: map[string]any{
+			"int":    int64(42),
+			"float":  1.5,
+			"string": "foo",
+			"list":   []any{"a", int64(1)},
+		},
+		Subresources: map[string]json.RawMessage{
+			"status": []byte(`{"state":"ok"}`),
+		},
+	}
+
+	codec := NewCBORCodec()
+	buf := bytes.Buffer{}
+	require.Nil(t, codec.Write(&buf, obj))
+	assert.True(t, IsCBOR(buf.Bytes()))
+
+	// The CBOR must transcode to the same JSON the JSONCodec writes
+	jsonBuf := bytes.Buffer{}
+	require.Nil(t, NewJSONCodec().Write(&jsonBuf, obj))
+	transcoded, err := CBORToJSON(buf.Bytes())
+	require.Nil(t, err)
+	assert.JSONEq(t, jsonBuf.String(), string(transcoded))
+
+	read := &UntypedObject{}
+	require.Nil(t, codec.Read(&buf, read))
+	assert.Equal(t, obj.ObjectMeta, read.ObjectMeta)
+	assert.Equal(t, obj.TypeMeta, read.TypeMeta)
+	assert.Equal(t, "foo", read.Spec["string"])
+	assert.Equal(t, 1.5, read.Spec["float"])
+	assert.JSONEq(t, `{"state":"ok"}`, string(read.Subresources["status"]))
+}
+
+func TestCBORCodec_Read(t *testing.T) {
+	t.Run("empty input", func(t *testing.T) {
+		assert.Equal(t, io.EOF, NewCBORCodec().Read(&bytes.Buffer{}, &UntypedObject{}))
+	})
+
+	t.Run("invalid CBOR", func(t *testing.T) {
+		assert.NotNil(t, NewCBORCodec().Read(bytes.NewReader([]byte{0xff, 0x00}), &UntypedObject{}))
+	})
+}
+
+func TestJSONToCBOR(t *testing.T) {
+	b, err := JSONToCBOR([]byte(`{"a":1,"b":[1.5,"c"],"d":null}`))
+	require.Nil(t, err)
+	assert.True(t, IsCBOR(b))
+	j, err := CBORToJSON(b)
+	require.Nil(t, err)
+	assert.JSONEq(t, `{"a":1,"b":[1.5,"c"],"d":null}`, string(j))
+
+	_, err = JSONToCBOR([]byte(`{`))
+	assert.NotNil(t, err)
+}
@@ -41,8 +41,15 @@ type UpdateOptions struct {
 
 // ListOptions are the options passed to a Client.List call
 type ListOptions struct {
-	// ResourceVersion to list at
+	// ResourceVersion to list at. How it is interpreted depends on ResourceVersionMatch.
+	// With no ResourceVersionMatch, "0" returns any available version (which may be served from the API server's cache,
+	// avoiding a quorum read), and an empty ResourceVersion returns the most recent version (a consistent read).
 	ResourceVersion string
+	// ResourceVersionMatch determines how ResourceVersion is applied. It may be ResourceVersionMatchNotOlderThan,
+	// ResourceVersionMatchExact, or empty (the legacy semantics). It must not be set if ResourceVersion is empty.
+	ResourceVersionMatch string
+	// TimeoutSeconds limits the duration of the list call on the server, when >0.
+	TimeoutSeconds int64
 	// LabelFilters are a set of label filter strings to use when listing
 	LabelFilters []string
 	// FieldSelectors are a set of field selector strings to use when listing
@@ -55,13 +62,47 @@ type ListOptions struct {
 	Continue string
 }
 
-// PatchRequest represents a JSON patch request, which can contain multiple operations.
+// ResourceVersionMatch values for ListOptions.ResourceVersionMatch and WatchOptions.ResourceVersionMatch
+const (
+	// ResourceVersionMatchNotOlderThan returns data at least as new as the provided ResourceVersion.
+	// With a ResourceVersion of "0", the list may be served from the API server's cache.
+	ResourceVersionMatchNotOlderThan = "NotOlderThan"
+	// ResourceVersionMatchExact returns data at the exact provided ResourceVersion, for consistent snapshot reads.
+	// It is only valid for list requests.
+	ResourceVersionMatchExact = "Exact"
+)
+
+// PatchRequest represents a patch request. By default, it is a JSON patch request, which can contain multiple operations.
 // Patch request operations are expected to adhere to the JSON Patch specification laid out by RFC6902,
 // which can be found at https://www.rfc-editor.org/rfc/rfc6902
+// If Type is set to a type other than PatchTypeJSONPatch, the patch body is instead the encoded Object (or Body, if Object is nil).
+// PatchBuilder can be used to construct a PatchRequest of any type.
 type PatchRequest struct {
 	Operations []PatchOperation
+	// Type is the type of the patch. If empty, it defaults to PatchTypeJSONPatch.
+	Type PatchType
+	// Object is the (partial) object to use as the body of a merge, strategic merge, or apply patch.
+	// It is encoded by the client using the kind's codec.
+	Object Object
+	// Body is a pre-encoded body for a merge, strategic merge, or apply patch, used if Object is nil.
+	Body []byte
 }
 
+// PatchType is the type of patch in a PatchRequest
+type PatchType string
+
+const (
+	// PatchTypeJSONPatch is an RFC6902 JSON Patch, made up of PatchRequest.Operations
+	PatchTypeJSONPatch = PatchType("json")
+	// PatchTypeMergePatch is an RFC7386 JSON Merge Patch
+	PatchTypeMergePatch = PatchType("merge")
+	// PatchTypeStrategicMergePatch is a kubernetes strategic merge patch.
+	// It is not supported by custom resources in kubernetes, and should only be used with built-in kinds.
+	PatchTypeStrategicMergePatch = PatchType("strategic")
+	// PatchTypeApply is a kubernetes server-side apply patch. It requires PatchOptions.FieldManager to be set.
+	PatchTypeApply = PatchType("apply")
+)
+
 // PatchOp represents an RFC6902 Patch "op" value
 type PatchOp string
 
@@ -81,11 +122,18 @@ type PatchOperation struct {
 	Path      string  `json:"path"`
 	Operation PatchOp `json:"op"`
 	Value     any     `json:"value,omitempty"`
+	// From is the source path for PatchOpMove and PatchOpCopy operations
+	From string `json:"from,omitempty"`
 }
 
 // PatchOptions are the options passed to a Client.Patch call
 type PatchOptions struct {
-	// TODO: nothing at-present
+	// FieldManager is the name of the actor making the change, used to track field ownership.
+	// It is required for PatchTypeApply patches.
+	FieldManager string
+	// Force, if true, makes a PatchTypeApply patch take ownership of fields owned by other field managers
+	// instead of returning a conflict error. It is only valid for PatchTypeApply patches.
+	Force bool
 }
 
 type DeleteOptionsPropagationPolicy string
@@ -97,6 +145,15 @@ const (
 	DeleteOptionsPropagationPolicyDefault    DeleteOptionsPropagationPolicy = ""
 )
 
+// ApplyOptions are the options passed to a server-side apply call, such as Store.Apply
+type ApplyOptions struct {
+	// FieldManager is the name of the actor applying the object, which takes ownership of all fields in the applied object.
+	// It is required.
+	FieldManager string
+	// ForceConflicts makes the apply take ownership of fields owned by other field managers, rather than failing with a conflict
+	ForceConflicts bool
+}
+
 // DeleteOptions are the options passed to a Client.Delete call
 type DeleteOptions struct {
 	// Preconditions describes any conditions that must be true for the delete request to be processed
@@ -117,6 +174,9 @@ type WatchOptions struct {
 	ResourceVersion string
 	// ResourceVersionMatch is the way to match against the resource version
 	ResourceVersionMatch string
+	// TimeoutSeconds limits the duration of the watch on the server, when >0.
+	// Once it elapses, the server ends the watch, closing the event channel.
+	TimeoutSeconds int64
 	// EventBufferSize determines the size of the watch event buffer (typically implemented as the channel buffer size)
 	// Only nonzero positive values are accepted, implementations will use the default value for cases where
 	// EventBufferSize <= 0
@@ -0,0 +1,163 @@
+// Package conditions contains helpers for working with standard kubernetes status conditions (metav1.Condition)
+// in the status of app kinds. Kinds with `reportsConditions: true` in their CUE definition have a conditions list
+// added to the status of each version, which can be read and written with GetObjectConditions and SetObjectCondition.
+package conditions
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+const (
+	// StatusField is the key in an object's status subresource which contains its conditions
+	StatusField = "conditions"
+	// TypeReady is the conventional condition type which indicates that an object is fully reconciled and available
+	TypeReady = "Ready"
+)
+
+// SetCondition sets the condition of condition.Type in conditions to condition, and returns true if conditions changed.
+// If the status of the condition changed (or the condition is new), its LastTransitionTime is set to
+// condition.LastTransitionTime, or to the current time if that is zero. Otherwise, the existing LastTransitionTime is kept.
+func SetCondition(conditions *[]metav1.Condition, condition metav1.Condition) bool {
+	return meta.SetStatusCondition(conditions, condition)
+}
+
+// GetCondition returns the condition of conditionType in conditions, or nil if there is none
+func GetCondition(conditions []metav1.Condition, conditionType string) *metav1.Condition {
+	return meta.FindStatusCondition(conditions, conditionType)
+}
+
+// RemoveCondition removes the condition of conditionType from conditions, and returns true if it was present
+func RemoveCondition(conditions *[]metav1.Condition, conditionType string) bool {
+	return meta.RemoveStatusCondition(conditions, conditionType)
+}
+
+// IsTrue returns true if conditions has a condition of conditionType with the status metav1.ConditionTrue
+func IsTrue(conditions []metav1.Condition, conditionType string) bool {
+	return meta.IsStatusConditionTrue(conditions, conditionType)
+}
+
+// IsFalse returns true if conditions has a condition of conditionType with the status metav1.ConditionFalse
+func IsFalse(conditions []metav1.Condition, conditionType string) bool {
+	return meta.IsStatusConditionFalse(conditions, conditionType)
+}
+
+// IsUnknown returns true if conditions has no condition of conditionType, or has one with the status metav1.ConditionUnknown
+func IsUnknown(conditions []metav1.Condition, conditionType string) bool {
+	condition := GetCondition(conditions, conditionType)
+	return condition == nil || condition.Status == metav1.ConditionUnknown
+}
+
+// IsCurrent returns true if conditions has a condition of conditionType which was observed for generation
+// (or a later generation). A condition which is not current describes an older spec of the object,
+// and its status should not be relied upon.
+func IsCurrent(conditions []metav1.Condition, conditionType string, generation int64) bool {
+	condition := GetCondition(conditions, conditionType)
+	return condition != nil && condition.ObservedGeneration >= generation
+}
+
+// GetObjectConditions returns the conditions in the status of obj (in status.conditions).
+// If obj has no status, or its status has no conditions, an empty list is returned.
+func GetObjectConditions(obj resource.Object) ([]metav1.Condition, error) {
+	status, err := statusAsMap(obj)
+	if err != nil {
+		return nil, err
+	}
+	conditions := make([]metav1.Condition, 0)
+	if status[StatusField] == nil {
+		return conditions, nil
+	}
+	raw, err := json.Marshal(status[StatusField])
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(raw, &conditions); err != nil {
+		return nil, fmt.Errorf("invalid conditions in status: %w", err)
+	}
+	return conditions, nil
+}
+
+// SetObjectConditions sets the status.conditions of obj to conditions, leaving the rest of the status unchanged.
+// The status subresource of obj must be JSON-compatible with a status containing a "conditions" field.
+// It does not update obj in the API server.
+func SetObjectConditions(obj resource.Object, conditions []metav1.Condition) error {
+	status, err := statusAsMap(obj)
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		status = make(map[string]any)
+	}
+	status[StatusField] = conditions
+	raw, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	// Unmarshal into a new value of the same type as the existing status, so SetSubresource accepts it
+	var target reflect.Value
+	if existing, ok := obj.GetSubresource(string(resource.SubresourceStatus)); ok && existing != nil {
+		target = reflect.New(reflect.TypeOf(existing))
+	} else {
+		target = reflect.New(reflect.TypeOf(json.RawMessage{}))
+	}
+	if err = json.Unmarshal(raw, target.Interface()); err != nil {
+		return fmt.Errorf("unable to set conditions in status: %w", err)
+	}
+	return obj.SetSubresource(string(resource.SubresourceStatus), target.Elem().Interface())
+}
+
+// SetObjectCondition sets condition in the status.conditions of obj (see SetCondition), and returns true
+// if the conditions changed. If condition.ObservedGeneration is zero, it is set to the current generation of obj.
+// It does not update obj in the API server; if it returns true, the status subresource of obj should be updated.
+func SetObjectCondition(obj resource.Object, condition metav1.Condition) (bool, error) {
+	conditions, err := GetObjectConditions(obj)
+	if err != nil {
+		return false, err
+	}
+	if condition.ObservedGeneration == 0 {
+		condition.ObservedGeneration = obj.GetGeneration()
+	}
+	if !SetCondition(&conditions, condition) {
+		return false, nil
+	}
+	return true, SetObjectConditions(obj, conditions)
+}
+
+// IsObjectConditionTrue returns true if obj has a condition of conditionType with the status metav1.ConditionTrue
+// which is current for the generation of obj (see IsCurrent).
+func IsObjectConditionTrue(obj resource.Object, conditionType string) (bool, error) {
+	conditions, err := GetObjectConditions(obj)
+	if err != nil {
+		return false, err
+	}
+	return IsTrue(conditions, conditionType) && IsCurrent(conditions, conditionType, obj.GetGeneration()), nil
+}
+
+func statusAsMap(obj resource.Object) (map[string]any, error) {
+	existing, ok := obj.GetSubresource(string(resource.SubresourceStatus))
+	if !ok || existing == nil {
+		return nil, nil
+	}
+	raw, ok := existing.(json.RawMessage)
+	if !ok {
+		var err error
+		raw, err = json.Marshal(existing)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	status := make(map[string]any)
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
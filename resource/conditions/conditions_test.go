@@ -0,0 +1,115 @@
+package conditions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// testCondition has the same JSON shape as the conditions in generated status types
+type testCondition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	ObservedGeneration *int64    `json:"observedGeneration,omitempty"`
+	LastTransitionTime time.Time `json:"lastTransitionTime"`
+	Reason             string    `json:"reason"`
+	Message            string    `json:"message"`
+}
+
+type testStatus struct {
+	State      string          `json:"state,omitempty"`
+	Conditions []testCondition `json:"conditions,omitempty"`
+}
+
+func TestSetCondition(t *testing.T) {
+	conditions := make([]metav1.Condition, 0)
+	transition := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.True(t, SetCondition(&conditions, metav1.Condition{
+		Type:               TypeReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Provisioning",
+		LastTransitionTime: transition,
+	}))
+	require.Len(t, conditions, 1)
+	assert.True(t, IsFalse(conditions, TypeReady))
+	assert.False(t, IsUnknown(conditions, TypeReady))
+	assert.True(t, IsUnknown(conditions, "Other"))
+
+	// Same status keeps the transition time
+	assert.True(t, SetCondition(&conditions, metav1.Condition{Type: TypeReady, Status: metav1.ConditionFalse, Reason: "Waiting"}))
+	assert.Equal(t, transition, GetCondition(conditions, TypeReady).LastTransitionTime)
+	assert.False(t, SetCondition(&conditions, metav1.Condition{Type: TypeReady, Status: metav1.ConditionFalse, Reason: "Waiting"}))
+
+	// A new status updates it
+	assert.True(t, SetCondition(&conditions, metav1.Condition{Type: TypeReady, Status: metav1.ConditionTrue, Reason: "Ready"}))
+	assert.True(t, IsTrue(conditions, TypeReady))
+	assert.True(t, GetCondition(conditions, TypeReady).LastTransitionTime.After(transition.Time))
+
+	assert.True(t, RemoveCondition(&conditions, TypeReady))
+	assert.Nil(t, GetCondition(conditions, TypeReady))
+}
+
+func TestIsCurrent(t *testing.T) {
+	conditions := []metav1.Condition{{Type: TypeReady, Status: metav1.ConditionTrue, ObservedGeneration: 2}}
+	assert.True(t, IsCurrent(conditions, TypeReady, 1))
+	assert.True(t, IsCurrent(conditions, TypeReady, 2))
+	assert.False(t, IsCurrent(conditions, TypeReady, 3))
+	assert.False(t, IsCurrent(conditions, "Other", 0))
+}
+
+func TestObjectConditions(t *testing.T) {
+	t.Run("typed status", func(t *testing.T) {
+		obj := &resource.TypedSpecStatusObject[string, testStatus]{Status: testStatus{State: "ok"}}
+		obj.SetGeneration(3)
+
+		conditions, err := GetObjectConditions(obj)
+		require.Nil(t, err)
+		assert.Empty(t, conditions)
+
+		changed, err := SetObjectCondition(obj, metav1.Condition{Type: TypeReady, Status: metav1.ConditionTrue, Reason: "Ready", Message: "all good"})
+		require.Nil(t, err)
+		assert.True(t, changed)
+		// The rest of the status is unchanged
+		assert.Equal(t, "ok", obj.Status.State)
+		require.Len(t, obj.Status.Conditions, 1)
+		assert.Equal(t, "True", obj.Status.Conditions[0].Status)
+		require.NotNil(t, obj.Status.Conditions[0].ObservedGeneration)
+		assert.Equal(t, int64(3), *obj.Status.Conditions[0].ObservedGeneration)
+
+		ready, err := IsObjectConditionTrue(obj, TypeReady)
+		require.Nil(t, err)
+		assert.True(t, ready)
+		// Once the spec changes, the condition is no longer current
+		obj.SetGeneration(4)
+		ready, err = IsObjectConditionTrue(obj, TypeReady)
+		require.Nil(t, err)
+		assert.False(t, ready)
+
+		changed, err = SetObjectCondition(obj, metav1.Condition{Type: TypeReady, Status: metav1.ConditionTrue, Reason: "Ready", Message: "all good", ObservedGeneration: 3})
+		require.Nil(t, err)
+		assert.False(t, changed)
+	})
+
+	t.Run("untyped status", func(t *testing.T) {
+		obj := &resource.UntypedObject{}
+		changed, err := SetObjectCondition(obj, metav1.Condition{Type: TypeReady, Status: metav1.ConditionUnknown, Reason: "Pending"})
+		require.Nil(t, err)
+		assert.True(t, changed)
+		conditions, err := GetObjectConditions(obj)
+		require.Nil(t, err)
+		require.Len(t, conditions, 1)
+		assert.Equal(t, "Pending", conditions[0].Reason)
+	})
+
+	t.Run("invalid conditions", func(t *testing.T) {
+		obj := &resource.UntypedObject{}
+		require.Nil(t, obj.SetSubresource(string(resource.SubresourceStatus), map[string]any{StatusField: "invalid"}))
+		_, err := GetObjectConditions(obj)
+		assert.NotNil(t, err)
+	})
+}
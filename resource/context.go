@@ -0,0 +1,40 @@
+package resource
+
+import (
+	"context"
+	"time"
+)
+
+// WithOperationTimeout returns a copy of ctx which is canceled after timeout, and the context.CancelFunc to release it.
+// If timeout is zero or negative, ctx has no timeout applied. As with context.WithTimeout, an earlier deadline on ctx
+// is preserved. It is used to apply the operation timeouts in config and options structs throughout the SDK,
+// and the returned cancel func should always be called once the operation is complete.
+func WithOperationTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// WithDeadlineBudget returns a copy of ctx whose deadline is reserve earlier than the deadline of ctx, and the
+// context.CancelFunc to release it. Work done with the returned context, such as requests to downstream clients,
+// then ends with at least reserve left for the caller to finish, such as by writing a response or reporting an error.
+// If ctx has no deadline, or reserve is zero or negative, no deadline is applied. If less than reserve is left
+// before the deadline of ctx, the returned context is already expired.
+func WithDeadlineBudget(ctx context.Context, reserve time.Duration) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || reserve <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline.Add(-reserve))
+}
+
+// RemainingBudget returns the time left before the deadline of ctx, which is zero or negative if the deadline has passed,
+// and false if ctx has no deadline
+func RemainingBudget(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
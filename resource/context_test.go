@@ -0,0 +1,85 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithOperationTimeout(t *testing.T) {
+	t.Run("no timeout", func(t *testing.T) {
+		ctx, cancel := WithOperationTimeout(context.Background(), 0)
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+		cancel()
+		assert.Equal(t, context.Canceled, ctx.Err())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := WithOperationTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		<-ctx.Done()
+		assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+	})
+
+	t.Run("earlier parent deadline", func(t *testing.T) {
+		parent, parentCancel := context.WithTimeout(context.Background(), time.Minute)
+		defer parentCancel()
+		expected, _ := parent.Deadline()
+		ctx, cancel := WithOperationTimeout(parent, time.Hour)
+		defer cancel()
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.Equal(t, expected, deadline)
+	})
+}
+
+func TestWithDeadlineBudget(t *testing.T) {
+	t.Run("no deadline", func(t *testing.T) {
+		ctx, cancel := WithDeadlineBudget(context.Background(), time.Second)
+		defer cancel()
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+		_, ok = RemainingBudget(ctx)
+		assert.False(t, ok)
+	})
+
+	t.Run("no reserve", func(t *testing.T) {
+		parent, parentCancel := context.WithTimeout(context.Background(), time.Minute)
+		defer parentCancel()
+		expected, _ := parent.Deadline()
+		ctx, cancel := WithDeadlineBudget(parent, 0)
+		defer cancel()
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.Equal(t, expected, deadline)
+	})
+
+	t.Run("reserve", func(t *testing.T) {
+		parent, parentCancel := context.WithTimeout(context.Background(), time.Minute)
+		defer parentCancel()
+		expected, _ := parent.Deadline()
+		ctx, cancel := WithDeadlineBudget(parent, 10*time.Second)
+		defer cancel()
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.Equal(t, expected.Add(-10*time.Second), deadline)
+		remaining, ok := RemainingBudget(ctx)
+		assert.True(t, ok)
+		assert.InDelta(t, 50*time.Second, remaining, float64(time.Second))
+	})
+
+	t.Run("reserve exceeds remaining time", func(t *testing.T) {
+		parent, parentCancel := context.WithTimeout(context.Background(), time.Second)
+		defer parentCancel()
+		ctx, cancel := WithDeadlineBudget(parent, time.Minute)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+		assert.Nil(t, parent.Err())
+		remaining, ok := RemainingBudget(ctx)
+		assert.True(t, ok)
+		assert.LessOrEqual(t, remaining, time.Duration(0))
+	})
+}
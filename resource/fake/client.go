@@ -0,0 +1,751 @@
+// Package fake contains an in-memory implementation of resource.Client and resource.ClientGenerator for tests.
+// Clients from a ClientGenerator share a Tracker, which stores objects with API server semantics for resource versions,
+// generations, finalizers, subresources, label and field selectors, pagination, and watches, so that code which uses
+// a resource.Client (or a resource.Store, or an informer) can be tested without an API server or a hand-written mock client.
+// Reactors added to a Client can inject errors or responses for specific requests, and the requests made to a Client
+// are recorded as Actions.
+package fake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+var (
+	_ resource.Client          = &Client{}
+	_ resource.ClientGenerator = &ClientGenerator{}
+)
+
+// Verbs of the Actions made to a Client
+const (
+	VerbGet    = "get"
+	VerbList   = "list"
+	VerbCreate = "create"
+	VerbUpdate = "update"
+	VerbPatch  = "patch"
+	VerbDelete = "delete"
+	VerbWatch  = "watch"
+	// VerbAll matches actions with any verb when used with Client.AddReactor
+	VerbAll = "*"
+)
+
+// Action is a request made to a Client
+type Action struct {
+	// Verb is the verb of the request, such as VerbGet
+	Verb string
+	// Identifier is the identifier of the object the request is for. For list and watch requests, only Namespace is set.
+	Identifier resource.Identifier
+	// Object is the object passed to a create or update request
+	Object resource.Object
+	// Patch is the patch passed to a patch request
+	Patch resource.PatchRequest
+	// Options are the options passed to the request, such as a resource.ListOptions for a list request
+	Options any
+}
+
+// ReactionFunc is a reactor for Actions made to a Client, which can inject an error or response for the action.
+// If handled is false, the action is passed to the next reactor, or handled by the Tracker if there are none.
+// If handled is true, err is returned to the caller if it is non-nil. Otherwise, ret (which may be nil) is returned
+// as the response for the action: a resource.Object for get, create, update, and patch requests,
+// and a resource.ListObject for list requests. A handled watch request returns a watch which receives no events.
+type ReactionFunc func(ctx context.Context, action Action) (handled bool, ret runtime.Object, err error)
+
+type reactor struct {
+	verb     string
+	reaction ReactionFunc
+}
+
+// Client is a fake resource.Client for a kind, which stores objects in a Tracker
+type Client struct {
+	kind    resource.Kind
+	tracker *Tracker
+	codec   resource.Codec
+	// mux guards reactors and actions
+	mux      sync.Mutex
+	reactors []reactor
+	actions  []Action
+}
+
+// NewClient creates a new Client for kind, which stores objects in tracker. The kind must have a JSON codec,
+// which is used to copy objects in and out of the tracker, and to apply patches.
+func NewClient(kind resource.Kind, tracker *Tracker) (*Client, error) {
+	if kind.Schema == nil {
+		return nil, fmt.Errorf("kind must have a schema")
+	}
+	if tracker == nil {
+		return nil, fmt.Errorf("tracker cannot be nil")
+	}
+	codec := kind.Codec(resource.KindEncodingJSON)
+	if codec == nil {
+		return nil, fmt.Errorf("kind must have a JSON codec")
+	}
+	return &Client{
+		kind:    kind,
+		tracker: tracker,
+		codec:   codec,
+	}, nil
+}
+
+// AddReactor adds a reactor which is called for each action with verb (or every action, with VerbAll).
+// Reactors are called in the order they were added, until one handles the action.
+func (c *Client) AddReactor(verb string, reaction ReactionFunc) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.reactors = append(c.reactors, reactor{verb: verb, reaction: reaction})
+}
+
+// Actions returns the actions made to the client, in the order they were made
+func (c *Client) Actions() []Action {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return append([]Action{}, c.actions...)
+}
+
+// ClearActions clears the recorded actions of the client
+func (c *Client) ClearActions() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.actions = nil
+}
+
+// react records action, and calls the reactors for it
+func (c *Client) react(ctx context.Context, action Action) (bool, runtime.Object, error) {
+	c.mux.Lock()
+	c.actions = append(c.actions, action)
+	reactors := append([]reactor{}, c.reactors...)
+	c.mux.Unlock()
+	for _, r := range reactors {
+		if r.verb != VerbAll && r.verb != action.Verb {
+			continue
+		}
+		if handled, ret, err := r.reaction(ctx, action); handled {
+			return true, ret, err
+		}
+	}
+	return false, nil, nil
+}
+
+// Get gets the object with identifier
+func (c *Client) Get(ctx context.Context, identifier resource.Identifier) (resource.Object, error) {
+	into := c.kind.ZeroValue()
+	if err := c.GetInto(ctx, identifier, into); err != nil {
+		return nil, err
+	}
+	return into, nil
+}
+
+// GetInto gets the object with identifier, and copies it into `into`
+func (c *Client) GetInto(ctx context.Context, identifier resource.Identifier, into resource.Object) error {
+	if into == nil {
+		return fmt.Errorf("into cannot be nil")
+	}
+	if handled, ret, err := c.react(ctx, Action{Verb: VerbGet, Identifier: identifier}); handled {
+		return c.reaction(ret, err, into)
+	}
+	stored, ok := c.tracker.get(c.kind.GroupVersionKind(), identifier.Namespace, identifier.Name)
+	if !ok {
+		return c.notFound(identifier.Name)
+	}
+	return c.copyInto(stored, into)
+}
+
+// Create creates obj with identifier, and returns the created object
+func (c *Client) Create(ctx context.Context, identifier resource.Identifier, obj resource.Object, options resource.CreateOptions) (resource.Object, error) {
+	into := c.kind.ZeroValue()
+	if err := c.CreateInto(ctx, identifier, obj, options, into); err != nil {
+		return nil, err
+	}
+	return into, nil
+}
+
+// CreateInto creates obj with identifier, and copies the created object into `into`.
+// The created object is given a UID, creation timestamp, resource version, and a generation of 1.
+func (c *Client) CreateInto(ctx context.Context, identifier resource.Identifier, obj resource.Object, options resource.CreateOptions, into resource.Object) error {
+	if obj == nil {
+		return fmt.Errorf("obj cannot be nil")
+	}
+	if into == nil {
+		return fmt.Errorf("into cannot be nil")
+	}
+	if err := c.validateNamespace(identifier.Namespace, true); err != nil {
+		return err
+	}
+	if handled, ret, err := c.react(ctx, Action{Verb: VerbCreate, Identifier: identifier, Object: obj, Options: options}); handled {
+		return c.reaction(ret, err, into)
+	}
+	if identifier.Name == "" {
+		return newStatusError(apierrors.NewBadRequest("name is required"))
+	}
+	if obj.GetResourceVersion() != "" {
+		return newStatusError(apierrors.NewBadRequest("resourceVersion should not be set on objects to be created"))
+	}
+	created, err := c.convert(obj)
+	if err != nil {
+		return err
+	}
+	c.setStaticMetadata(created, identifier)
+	c.resetServerFields(created)
+	stored, err := c.tracker.create(c.kind.GroupVersionKind(), c.groupResource(), created)
+	if err != nil {
+		return err
+	}
+	return c.copyInto(stored, into)
+}
+
+// Update updates the object with identifier to obj, and returns the updated object
+func (c *Client) Update(ctx context.Context, identifier resource.Identifier, obj resource.Object, options resource.UpdateOptions) (resource.Object, error) {
+	into := c.kind.ZeroValue()
+	if err := c.UpdateInto(ctx, identifier, obj, options, into); err != nil {
+		return nil, err
+	}
+	return into, nil
+}
+
+// UpdateInto updates the object with identifier to obj, and copies the updated object into `into`.
+// If options.ResourceVersion is set, the update fails with a Conflict error if the object has a different resource version.
+// An update of the main object leaves its subresources (such as status) unchanged, and an update of a subresource
+// (with options.Subresource) only changes that subresource. The generation of the object is incremented when its spec changes.
+// An update which removes the last finalizer of an object which is being deleted deletes the object.
+func (c *Client) UpdateInto(ctx context.Context, identifier resource.Identifier, obj resource.Object, options resource.UpdateOptions, into resource.Object) error {
+	if obj == nil {
+		return fmt.Errorf("obj cannot be nil")
+	}
+	if into == nil {
+		return fmt.Errorf("into cannot be nil")
+	}
+	if handled, ret, err := c.react(ctx, Action{Verb: VerbUpdate, Identifier: identifier, Object: obj, Options: options}); handled {
+		return c.reaction(ret, err, into)
+	}
+	existing, err := c.getExisting(identifier)
+	if err != nil {
+		return err
+	}
+	expectedResourceVersion := options.ResourceVersion
+	if expectedResourceVersion == "" {
+		expectedResourceVersion = existing.GetResourceVersion()
+	}
+	updated, err := c.convert(obj)
+	if err != nil {
+		return err
+	}
+	c.setStaticMetadata(updated, identifier)
+	if options.Subresource != "" {
+		val, ok := updated.GetSubresource(options.Subresource)
+		if !ok {
+			return c.notFound(identifier.Name + "/" + options.Subresource)
+		}
+		if err = existing.SetSubresource(options.Subresource, val); err != nil {
+			return err
+		}
+		updated = existing
+	} else if err = c.mergeUpdate(existing, updated); err != nil {
+		return err
+	}
+	stored, err := c.tracker.replace(c.kind.GroupVersionKind(), c.groupResource(), updated, expectedResourceVersion)
+	if err != nil {
+		return err
+	}
+	return c.copyInto(stored, into)
+}
+
+// Patch patches the object with identifier, and returns the patched object
+func (c *Client) Patch(ctx context.Context, identifier resource.Identifier, patch resource.PatchRequest, options resource.PatchOptions) (resource.Object, error) {
+	into := c.kind.ZeroValue()
+	if err := c.PatchInto(ctx, identifier, patch, options, into); err != nil {
+		return nil, err
+	}
+	return into, nil
+}
+
+// PatchInto patches the object with identifier, and copies the patched object into `into`.
+// JSON patches and merge patches are supported, and apply patches are handled as merge patches which create
+// the object if it does not exist. Strategic merge patches are rejected, as they are for custom resources.
+// Like an update of the main object, a patch leaves the object's subresources unchanged.
+// If the patched object has a different resource version, the patch fails with a Conflict error.
+func (c *Client) PatchInto(ctx context.Context, identifier resource.Identifier, patch resource.PatchRequest, options resource.PatchOptions, into resource.Object) error {
+	if into == nil {
+		return fmt.Errorf("into cannot be nil")
+	}
+	if handled, ret, err := c.react(ctx, Action{Verb: VerbPatch, Identifier: identifier, Patch: patch, Options: options}); handled {
+		return c.reaction(ret, err, into)
+	}
+	if patch.Type == resource.PatchTypeApply && options.FieldManager == "" {
+		return newStatusError(apierrors.NewBadRequest("PatchOptions.fieldManager is required for apply requests"))
+	}
+	existing, err := c.getExisting(identifier)
+	if err != nil {
+		if patch.Type == resource.PatchTypeApply && apierrors.IsNotFound(err) {
+			return c.applyCreate(identifier, patch, into)
+		}
+		return err
+	}
+	doc, err := c.toJSONValue(existing)
+	if err != nil {
+		return err
+	}
+	switch patch.Type {
+	case "", resource.PatchTypeJSONPatch:
+		if doc, err = applyJSONPatch(doc, patch.Operations); err != nil {
+			return NewStatusError(http.StatusUnprocessableEntity, err.Error())
+		}
+	case resource.PatchTypeMergePatch, resource.PatchTypeApply:
+		body, err := c.patchBody(patch)
+		if err != nil {
+			return err
+		}
+		doc = applyMergePatch(doc, body)
+	case resource.PatchTypeStrategicMergePatch:
+		return NewStatusError(http.StatusUnsupportedMediaType, "strategic merge patch is not supported for custom resources")
+	default:
+		return fmt.Errorf("unknown patch type '%s'", patch.Type)
+	}
+	patched, err := c.fromJSONValue(doc)
+	if err != nil {
+		return NewStatusError(http.StatusUnprocessableEntity, err.Error())
+	}
+	if rv := patched.GetResourceVersion(); rv != "" && rv != existing.GetResourceVersion() {
+		return newStatusError(apierrors.NewConflict(c.groupResource(), identifier.Name,
+			fmt.Errorf("the object has been modified; please apply your changes to the latest version and try again")))
+	}
+	c.setStaticMetadata(patched, identifier)
+	if err = c.mergeUpdate(existing, patched); err != nil {
+		return err
+	}
+	stored, err := c.tracker.replace(c.kind.GroupVersionKind(), c.groupResource(), patched, existing.GetResourceVersion())
+	if err != nil {
+		return err
+	}
+	return c.copyInto(stored, into)
+}
+
+// applyCreate creates the object with identifier from the body of the apply patch
+func (c *Client) applyCreate(identifier resource.Identifier, patch resource.PatchRequest, into resource.Object) error {
+	if err := c.validateNamespace(identifier.Namespace, true); err != nil {
+		return err
+	}
+	body, err := c.patchBody(patch)
+	if err != nil {
+		return err
+	}
+	created, err := c.fromJSONValue(applyMergePatch(nil, body))
+	if err != nil {
+		return NewStatusError(http.StatusUnprocessableEntity, err.Error())
+	}
+	c.setStaticMetadata(created, identifier)
+	c.resetServerFields(created)
+	stored, err := c.tracker.create(c.kind.GroupVersionKind(), c.groupResource(), created)
+	if err != nil {
+		return err
+	}
+	return c.copyInto(stored, into)
+}
+
+// patchBody returns the decoded body of a merge or apply patch
+func (c *Client) patchBody(patch resource.PatchRequest) (any, error) {
+	if patch.Object != nil {
+		return c.toJSONValue(patch.Object)
+	}
+	if len(patch.Body) == 0 {
+		return nil, fmt.Errorf("%s patch must have an object or body", patch.Type)
+	}
+	var body any
+	if err := json.Unmarshal(patch.Body, &body); err != nil {
+		return nil, newStatusError(apierrors.NewBadRequest(fmt.Sprintf("invalid patch body: %s", err.Error())))
+	}
+	return body, nil
+}
+
+// Delete deletes the object with identifier. If the object has finalizers, it is marked for deletion with
+// a deletion timestamp instead, and is deleted once an update removes its last finalizer.
+func (c *Client) Delete(ctx context.Context, identifier resource.Identifier, options resource.DeleteOptions) error {
+	if handled, _, err := c.react(ctx, Action{Verb: VerbDelete, Identifier: identifier, Options: options}); handled {
+		return err
+	}
+	return c.tracker.delete(c.kind.GroupVersionKind(), c.groupResource(), identifier.Namespace, identifier.Name, options.Preconditions)
+}
+
+// List lists the objects in namespace which match options, and returns them in a list of the kind's ZeroListValue
+func (c *Client) List(ctx context.Context, namespace string, options resource.ListOptions) (resource.ListObject, error) {
+	into := c.kind.ZeroListValue()
+	if err := c.ListInto(ctx, namespace, options, into); err != nil {
+		return nil, err
+	}
+	return into, nil
+}
+
+// ListInto lists the objects in namespace which match options, and sets them as the items of `into`.
+// Objects are listed in order of namespace and name. If options.Limit is set and there are more objects,
+// the list has a continue token, which is passed as options.Continue to list the next page.
+func (c *Client) ListInto(ctx context.Context, namespace string, options resource.ListOptions, into resource.ListObject) error {
+	if into == nil {
+		return fmt.Errorf("into cannot be nil")
+	}
+	if err := c.validateNamespace(namespace, false); err != nil {
+		return err
+	}
+	matches, err := c.matcher(namespace, options.LabelFilters, options.FieldSelectors)
+	if err != nil {
+		return err
+	}
+	handled, ret, err := c.react(ctx, Action{Verb: VerbList, Identifier: resource.Identifier{Namespace: namespace}, Options: options})
+	if handled {
+		if err != nil || ret == nil {
+			return err
+		}
+		list, ok := ret.(resource.ListObject)
+		if !ok {
+			return fmt.Errorf("reactor returned a %T, not a resource.ListObject", ret)
+		}
+		return c.copyListInto(list.GetItems(), list, into)
+	}
+	keys, objects, resourceVersion := c.tracker.list(c.kind.GroupVersionKind())
+	items := make([]resource.Object, 0)
+	remaining := int64(0)
+	continueKey := ""
+	for i, obj := range objects {
+		if options.Continue != "" && keys[i] <= options.Continue {
+			continue
+		}
+		converted, err := c.convert(obj)
+		if err != nil {
+			return err
+		}
+		if !matches(converted) {
+			continue
+		}
+		if options.Limit > 0 && len(items) >= options.Limit {
+			remaining++
+			continue
+		}
+		items = append(items, converted)
+		continueKey = keys[i]
+	}
+	into.SetItems(items)
+	into.SetResourceVersion(resourceVersion)
+	into.SetContinue("")
+	into.SetRemainingItemCount(nil)
+	if remaining > 0 {
+		into.SetContinue(continueKey)
+		into.SetRemainingItemCount(&remaining)
+	}
+	return nil
+}
+
+// Watch watches the objects in namespace which match options. Events are sent for each change to a matching object
+// made after options.ResourceVersion (or after the watch starts, if it is empty or "0"), until the watch is stopped,
+// ctx is canceled, or options.TimeoutSeconds elapses. If the changes after options.ResourceVersion are no longer
+// kept by the Tracker, a 410 Gone (Expired) error is returned.
+func (c *Client) Watch(ctx context.Context, namespace string, options resource.WatchOptions) (resource.WatchResponse, error) {
+	if err := c.validateNamespace(namespace, false); err != nil {
+		return nil, err
+	}
+	matches, err := c.matcher(namespace, options.LabelFilters, options.FieldSelectors)
+	if err != nil {
+		return nil, err
+	}
+	cancel := context.CancelFunc(func() {})
+	if options.TimeoutSeconds > 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(options.TimeoutSeconds)*time.Second)
+	}
+	if handled, _, err := c.react(ctx, Action{Verb: VerbWatch, Identifier: resource.Identifier{Namespace: namespace}, Options: options}); handled {
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		return newWatcher(ctx, options.EventBufferSize, func(resource.Object) (resource.Object, bool) {
+			return nil, false
+		}, func(*watcher) {
+			cancel()
+		}), nil
+	}
+	gvk := c.kind.GroupVersionKind()
+	w := newWatcher(ctx, options.EventBufferSize, func(obj resource.Object) (resource.Object, bool) {
+		converted, err := c.convert(obj)
+		if err != nil || !matches(converted) {
+			return nil, false
+		}
+		return converted, true
+	}, func(w *watcher) {
+		cancel()
+		c.tracker.unwatch(gvk, w)
+	})
+	if err := c.tracker.watch(gvk, w, options.ResourceVersion); err != nil {
+		w.Stop()
+		return nil, err
+	}
+	return w, nil
+}
+
+// matcher returns a function which returns true for objects in namespace which match labelFilters and fieldSelectors
+func (c *Client) matcher(namespace string, labelFilters, fieldSelectors []string) (func(resource.Object) bool, error) {
+	labelSelector := labels.Everything()
+	if len(labelFilters) > 0 {
+		var err error
+		labelSelector, err = labels.Parse(strings.Join(labelFilters, ","))
+		if err != nil {
+			return nil, newStatusError(apierrors.NewBadRequest(fmt.Sprintf("invalid label filters: %s", err.Error())))
+		}
+	}
+	fieldSelector := fields.Everything()
+	if len(fieldSelectors) > 0 {
+		if err := resource.ValidateFieldSelectors(c.kind, fieldSelectors); err != nil {
+			return nil, newStatusError(apierrors.NewBadRequest(err.Error()))
+		}
+		var err error
+		fieldSelector, err = fields.ParseSelector(strings.Join(fieldSelectors, ","))
+		if err != nil {
+			return nil, newStatusError(apierrors.NewBadRequest(fmt.Sprintf("invalid field selectors: %s", err.Error())))
+		}
+	}
+	return func(obj resource.Object) bool {
+		if namespace != resource.NamespaceAll && obj.GetNamespace() != namespace {
+			return false
+		}
+		if !labelSelector.Matches(labels.Set(obj.GetLabels())) {
+			return false
+		}
+		if fieldSelector.Empty() {
+			return true
+		}
+		set := fields.Set{
+			"metadata.name":      obj.GetName(),
+			"metadata.namespace": obj.GetNamespace(),
+		}
+		for _, field := range c.kind.SelectableFields() {
+			if field.FieldValueFunc == nil {
+				continue
+			}
+			if val, err := field.FieldValueFunc(obj); err == nil {
+				set[strings.TrimPrefix(field.FieldSelector, ".")] = val
+			}
+		}
+		return fieldSelector.Matches(set)
+	}, nil
+}
+
+// reaction returns err if it is non-nil, and otherwise copies ret (if it is non-nil) into `into`
+func (c *Client) reaction(ret runtime.Object, err error, into resource.Object) error {
+	if err != nil || ret == nil {
+		return err
+	}
+	obj, ok := ret.(resource.Object)
+	if !ok {
+		return fmt.Errorf("reactor returned a %T, not a resource.Object", ret)
+	}
+	return c.copyInto(obj, into)
+}
+
+// getExisting returns a copy of the stored object with identifier, converted to the client's kind
+func (c *Client) getExisting(identifier resource.Identifier) (resource.Object, error) {
+	stored, ok := c.tracker.get(c.kind.GroupVersionKind(), identifier.Namespace, identifier.Name)
+	if !ok {
+		return nil, c.notFound(identifier.Name)
+	}
+	return c.convert(stored)
+}
+
+// mergeUpdate updates updated with the fields of existing which can't be changed by an update of the main object:
+// its UID, creation and deletion timestamps, generation (which is incremented if the spec changed), and subresources
+func (c *Client) mergeUpdate(existing, updated resource.Object) error {
+	updated.SetUID(existing.GetUID())
+	updated.SetCreationTimestamp(existing.GetCreationTimestamp())
+	updated.SetDeletionTimestamp(existing.GetDeletionTimestamp())
+	updated.SetGeneration(existing.GetGeneration())
+	existingSpec, err := json.Marshal(existing.GetSpec())
+	if err != nil {
+		return err
+	}
+	updatedSpec, err := json.Marshal(updated.GetSpec())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(existingSpec, updatedSpec) {
+		updated.SetGeneration(existing.GetGeneration() + 1)
+	}
+	zero := c.kind.ZeroValue()
+	for key := range updated.GetSubresources() {
+		val, ok := existing.GetSubresource(key)
+		if !ok || isNil(val) {
+			val, _ = zero.GetSubresource(key)
+		}
+		if err = updated.SetSubresource(key, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isNil(val any) bool {
+	if val == nil {
+		return true
+	}
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Map, reflect.Pointer, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// setStaticMetadata sets the group, version, kind, namespace, and name of obj from the client's kind and identifier
+func (c *Client) setStaticMetadata(obj resource.Object, identifier resource.Identifier) {
+	obj.SetStaticMetadata(resource.StaticMetadata{
+		Namespace: identifier.Namespace,
+		Name:      identifier.Name,
+		Group:     c.kind.Group(),
+		Version:   c.kind.Version(),
+		Kind:      c.kind.Kind(),
+	})
+}
+
+// resetServerFields clears the fields of obj which are set by the tracker when it is created
+func (*Client) resetServerFields(obj resource.Object) {
+	obj.SetUID("")
+	obj.SetResourceVersion("")
+	obj.SetGeneration(0)
+	obj.SetCreationTimestamp(metav1.Time{})
+	obj.SetDeletionTimestamp(nil)
+}
+
+func (c *Client) validateNamespace(namespace string, requireNamespace bool) error {
+	if c.kind.Scope() == resource.ClusterScope && namespace != resource.NamespaceAll {
+		return fmt.Errorf("cannot use namespace \"%s\" with a resource with schema scope \"%s\", must be NamespaceAll (\"%s\")",
+			namespace, resource.ClusterScope, resource.NamespaceAll)
+	}
+	if requireNamespace && c.kind.Scope() != resource.ClusterScope && namespace == resource.NamespaceAll {
+		return fmt.Errorf("cannot create a resource with schema scope \"%s\" in NamespaceAll (\"%s\")", resource.NamespacedScope, resource.NamespaceAll)
+	}
+	return nil
+}
+
+func (c *Client) groupResource() schema.GroupResource {
+	return schema.GroupResource{Group: c.kind.Group(), Resource: c.kind.Plural()}
+}
+
+func (c *Client) notFound(name string) error {
+	return newStatusError(apierrors.NewNotFound(c.groupResource(), name))
+}
+
+// convert returns a copy of obj as an object of the client's kind
+func (c *Client) convert(obj resource.Object) (resource.Object, error) {
+	into := c.kind.ZeroValue()
+	if err := c.copyInto(obj, into); err != nil {
+		return nil, err
+	}
+	return into, nil
+}
+
+// copyInto copies obj into `into` by encoding and decoding it with the kind's JSON codec
+func (c *Client) copyInto(obj resource.Object, into resource.Object) error {
+	buf := &bytes.Buffer{}
+	if err := c.codec.Write(buf, obj); err != nil {
+		return err
+	}
+	return c.codec.Read(buf, into)
+}
+
+// copyListInto converts items to the client's kind, and sets them and the list metadata of list as the items and metadata of `into`
+func (c *Client) copyListInto(items []resource.Object, list resource.ListObject, into resource.ListObject) error {
+	converted := make([]resource.Object, 0, len(items))
+	for _, item := range items {
+		obj, err := c.convert(item)
+		if err != nil {
+			return err
+		}
+		converted = append(converted, obj)
+	}
+	into.SetItems(converted)
+	into.SetResourceVersion(list.GetResourceVersion())
+	into.SetContinue(list.GetContinue())
+	into.SetRemainingItemCount(list.GetRemainingItemCount())
+	return nil
+}
+
+// toJSONValue returns obj encoded with the kind's JSON codec, decoded to generic JSON values
+func (c *Client) toJSONValue(obj resource.Object) (any, error) {
+	buf := &bytes.Buffer{}
+	if err := c.codec.Write(buf, obj); err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// fromJSONValue returns the object of the client's kind decoded from the generic JSON values of doc
+func (c *Client) fromJSONValue(doc any) (resource.Object, error) {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	into := c.kind.ZeroValue()
+	if err = c.codec.Read(bytes.NewReader(raw), into); err != nil {
+		return nil, err
+	}
+	return into, nil
+}
+
+// ClientGenerator is a fake resource.ClientGenerator, which returns a Client for each kind that stores objects in its Tracker.
+// The same Client is returned for each call for a kind, so reactors added to it apply to all of its users.
+type ClientGenerator struct {
+	tracker *Tracker
+	mux     sync.Mutex
+	clients map[schema.GroupVersionKind]*Client
+}
+
+// NewClientGenerator creates a new ClientGenerator whose Clients store objects in tracker.
+// If tracker is nil, a new, empty Tracker is used.
+func NewClientGenerator(tracker *Tracker) *ClientGenerator {
+	if tracker == nil {
+		tracker, _ = NewTracker()
+	}
+	return &ClientGenerator{
+		tracker: tracker,
+		clients: make(map[schema.GroupVersionKind]*Client),
+	}
+}
+
+// Tracker returns the Tracker used by the generator's Clients
+func (g *ClientGenerator) Tracker() *Tracker {
+	return g.tracker
+}
+
+// ClientFor returns the Client for kind (see Client)
+func (g *ClientGenerator) ClientFor(kind resource.Kind) (resource.Client, error) {
+	return g.Client(kind)
+}
+
+// Client returns the fake Client for kind, creating it if it does not exist
+func (g *ClientGenerator) Client(kind resource.Kind) (*Client, error) {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	gvk := kind.GroupVersionKind()
+	if client, ok := g.clients[gvk]; ok {
+		return client, nil
+	}
+	client, err := NewClient(kind, g.tracker)
+	if err != nil {
+		return nil, err
+	}
+	g.clients[gvk] = client
+	return client, nil
+}
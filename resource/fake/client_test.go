@@ -0,0 +1,523 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+type testSpec struct {
+	Foo string `json:"foo"`
+}
+
+type testStatus struct {
+	State string `json:"state"`
+}
+
+type testObject = resource.TypedSpecStatusObject[testSpec, testStatus]
+
+var testKind = resource.Kind{
+	Schema: resource.NewSimpleSchema("test.grafana.app", "v1", &testObject{}, &resource.TypedList[*testObject]{},
+		resource.WithKind("Test"), resource.WithPlural("tests"),
+		resource.WithSelectableFields([]resource.SelectableField{{
+			FieldSelector: ".spec.foo",
+			FieldValueFunc: func(obj resource.Object) (string, error) {
+				cast, ok := obj.(*testObject)
+				if !ok {
+					return "", errors.New("object is not a *testObject")
+				}
+				return cast.Spec.Foo, nil
+			},
+		}})),
+	Codecs: map[resource.KindEncoding]resource.Codec{resource.KindEncodingJSON: resource.NewJSONCodec()},
+}
+
+func newTestObject(namespace, name, foo string) *testObject {
+	return &testObject{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       testSpec{Foo: foo},
+	}
+}
+
+func newTestClient(t *testing.T, objects ...resource.Object) *Client {
+	t.Helper()
+	for _, obj := range objects {
+		obj.SetStaticMetadata(resource.StaticMetadata{
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+			Group:     testKind.Group(),
+			Version:   testKind.Version(),
+			Kind:      testKind.Kind(),
+		})
+	}
+	tracker, err := NewTracker(objects...)
+	require.NoError(t, err)
+	client, err := NewClient(testKind, tracker)
+	require.NoError(t, err)
+	return client
+}
+
+func TestNewClient(t *testing.T) {
+	tracker, err := NewTracker()
+	require.NoError(t, err)
+
+	_, err = NewClient(testKind, nil)
+	assert.Equal(t, errors.New("tracker cannot be nil"), err)
+
+	_, err = NewClient(resource.Kind{Schema: testKind.Schema}, tracker)
+	assert.Equal(t, errors.New("kind must have a JSON codec"), err)
+}
+
+func TestClient_CRUD(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	id := resource.Identifier{Namespace: "ns", Name: "foo"}
+
+	t.Run("get not found", func(t *testing.T) {
+		_, err := client.Get(ctx, id)
+		require.Error(t, err)
+		assert.True(t, apierrors.IsNotFound(err))
+		var serr resource.APIServerResponseError
+		require.True(t, errors.As(err, &serr))
+		assert.Equal(t, http.StatusNotFound, serr.StatusCode())
+	})
+
+	var created resource.Object
+	t.Run("create", func(t *testing.T) {
+		var err error
+		created, err = client.Create(ctx, id, newTestObject("", "", "bar"), resource.CreateOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "foo", created.GetName())
+		assert.Equal(t, "ns", created.GetNamespace())
+		assert.Equal(t, testKind.GroupVersionKind(), created.GroupVersionKind())
+		assert.NotEmpty(t, created.GetUID())
+		assert.NotEmpty(t, created.GetResourceVersion())
+		assert.Equal(t, int64(1), created.GetGeneration())
+		ts := created.GetCreationTimestamp()
+		assert.False(t, ts.IsZero())
+
+		_, err = client.Create(ctx, id, newTestObject("", "", "bar"), resource.CreateOptions{})
+		assert.True(t, apierrors.IsAlreadyExists(err))
+
+		withRV := newTestObject("", "", "bar")
+		withRV.ResourceVersion = "1"
+		_, err = client.Create(ctx, resource.Identifier{Namespace: "ns", Name: "rv"}, withRV, resource.CreateOptions{})
+		assert.True(t, apierrors.IsBadRequest(err))
+	})
+
+	t.Run("get", func(t *testing.T) {
+		got, err := client.Get(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, created, got)
+		// The returned object is a copy, and modifying it must not modify the stored object
+		got.(*testObject).Spec.Foo = "modified"
+		again, err := client.Get(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "bar", again.(*testObject).Spec.Foo)
+	})
+
+	var updated resource.Object
+	t.Run("update", func(t *testing.T) {
+		obj := created.Copy().(*testObject)
+		obj.Spec.Foo = "baz"
+		obj.Status.State = "ignored"
+		var err error
+		updated, err = client.Update(ctx, id, obj, resource.UpdateOptions{ResourceVersion: created.GetResourceVersion()})
+		require.NoError(t, err)
+		assert.Equal(t, "baz", updated.(*testObject).Spec.Foo)
+		assert.Equal(t, "", updated.(*testObject).Status.State, "updates of the main object must not change subresources")
+		assert.Equal(t, int64(2), updated.GetGeneration())
+		assert.Equal(t, created.GetUID(), updated.GetUID())
+		assert.NotEqual(t, created.GetResourceVersion(), updated.GetResourceVersion())
+
+		_, err = client.Update(ctx, id, obj, resource.UpdateOptions{ResourceVersion: created.GetResourceVersion()})
+		assert.True(t, apierrors.IsConflict(err))
+		assert.True(t, resource.IsConflict(err))
+
+		_, err = client.Update(ctx, resource.Identifier{Namespace: "ns", Name: "missing"}, obj, resource.UpdateOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+
+	t.Run("update status", func(t *testing.T) {
+		obj := updated.Copy().(*testObject)
+		obj.Spec.Foo = "ignored"
+		obj.Status.State = "ready"
+		res, err := client.Update(ctx, id, obj, resource.UpdateOptions{Subresource: string(resource.SubresourceStatus)})
+		require.NoError(t, err)
+		assert.Equal(t, "baz", res.(*testObject).Spec.Foo, "updates of a subresource must not change the main object")
+		assert.Equal(t, "ready", res.(*testObject).Status.State)
+		assert.Equal(t, int64(2), res.GetGeneration())
+		updated = res
+	})
+
+	t.Run("unchanged spec keeps generation", func(t *testing.T) {
+		obj := updated.Copy().(*testObject)
+		obj.Labels = map[string]string{"a": "b"}
+		res, err := client.Update(ctx, id, obj, resource.UpdateOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), res.GetGeneration())
+		assert.Equal(t, "ready", res.(*testObject).Status.State)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		err := client.Delete(ctx, id, resource.DeleteOptions{Preconditions: resource.DeleteOptionsPreconditions{UID: "wrong"}})
+		assert.True(t, apierrors.IsConflict(err))
+		require.NoError(t, client.Delete(ctx, id, resource.DeleteOptions{}))
+		_, err = client.Get(ctx, id)
+		assert.True(t, apierrors.IsNotFound(err))
+		err = client.Delete(ctx, id, resource.DeleteOptions{})
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+}
+
+func TestClient_Finalizers(t *testing.T) {
+	ctx := context.Background()
+	obj := newTestObject("ns", "foo", "bar")
+	obj.Finalizers = []string{"test"}
+	client := newTestClient(t, obj)
+	id := resource.Identifier{Namespace: "ns", Name: "foo"}
+
+	require.NoError(t, client.Delete(ctx, id, resource.DeleteOptions{}))
+	deleting, err := client.Get(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, deleting.GetDeletionTimestamp())
+
+	// Deleting an object which is already being deleted is a no-op
+	require.NoError(t, client.Delete(ctx, id, resource.DeleteOptions{}))
+	again, err := client.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, deleting.GetResourceVersion(), again.GetResourceVersion())
+
+	deleting.SetFinalizers(nil)
+	_, err = client.Update(ctx, id, deleting, resource.UpdateOptions{})
+	require.NoError(t, err)
+	_, err = client.Get(ctx, id)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestClient_Patch(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, newTestObject("ns", "foo", "bar"))
+	id := resource.Identifier{Namespace: "ns", Name: "foo"}
+
+	t.Run("json patch", func(t *testing.T) {
+		res, err := client.Patch(ctx, id, resource.PatchRequest{
+			Operations: []resource.PatchOperation{{
+				Path:      "/spec/foo",
+				Operation: resource.PatchOpReplace,
+				Value:     "patched",
+			}, {
+				Path:      "/metadata/labels",
+				Operation: resource.PatchOpAdd,
+				Value:     map[string]string{"a": "b"},
+			}},
+		}, resource.PatchOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "patched", res.(*testObject).Spec.Foo)
+		assert.Equal(t, map[string]string{"a": "b"}, res.GetLabels())
+		assert.Equal(t, int64(2), res.GetGeneration())
+	})
+
+	t.Run("failed json patch", func(t *testing.T) {
+		_, err := client.Patch(ctx, id, resource.PatchRequest{
+			Operations: []resource.PatchOperation{{
+				Path:      "/spec/foo",
+				Operation: resource.PatchOpTest,
+				Value:     "wrong",
+			}},
+		}, resource.PatchOptions{})
+		assert.True(t, apierrors.IsInvalid(err))
+	})
+
+	t.Run("merge patch", func(t *testing.T) {
+		res, err := client.Patch(ctx, id, resource.PatchRequest{
+			Type: resource.PatchTypeMergePatch,
+			Body: []byte(`{"metadata":{"labels":{"a":null,"c":"d"}},"spec":{"foo":"merged"}}`),
+		}, resource.PatchOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "merged", res.(*testObject).Spec.Foo)
+		assert.Equal(t, map[string]string{"c": "d"}, res.GetLabels())
+	})
+
+	t.Run("resource version conflict", func(t *testing.T) {
+		_, err := client.Patch(ctx, id, resource.PatchRequest{
+			Type: resource.PatchTypeMergePatch,
+			Body: []byte(`{"metadata":{"resourceVersion":"1"}}`),
+		}, resource.PatchOptions{})
+		assert.True(t, apierrors.IsConflict(err))
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		newID := resource.Identifier{Namespace: "ns", Name: "applied"}
+		_, err := client.Patch(ctx, newID, resource.PatchRequest{
+			Type:   resource.PatchTypeApply,
+			Object: newTestObject("ns", "applied", "one"),
+		}, resource.PatchOptions{})
+		assert.True(t, apierrors.IsBadRequest(err))
+
+		res, err := client.Patch(ctx, newID, resource.PatchRequest{
+			Type:   resource.PatchTypeApply,
+			Object: newTestObject("ns", "applied", "one"),
+		}, resource.PatchOptions{FieldManager: "test"})
+		require.NoError(t, err)
+		assert.Equal(t, "one", res.(*testObject).Spec.Foo)
+		assert.NotEmpty(t, res.GetUID())
+
+		res, err = client.Patch(ctx, newID, resource.PatchRequest{
+			Type:   resource.PatchTypeApply,
+			Object: newTestObject("ns", "applied", "two"),
+		}, resource.PatchOptions{FieldManager: "test"})
+		require.NoError(t, err)
+		assert.Equal(t, "two", res.(*testObject).Spec.Foo)
+		assert.Equal(t, int64(2), res.GetGeneration())
+	})
+
+	t.Run("strategic merge patch", func(t *testing.T) {
+		_, err := client.Patch(ctx, id, resource.PatchRequest{
+			Type: resource.PatchTypeStrategicMergePatch,
+			Body: []byte(`{}`),
+		}, resource.PatchOptions{})
+		assert.True(t, apierrors.IsUnsupportedMediaType(err))
+	})
+}
+
+func TestClient_List(t *testing.T) {
+	ctx := context.Background()
+	a := newTestObject("ns1", "a", "x")
+	a.Labels = map[string]string{"app": "one"}
+	b := newTestObject("ns1", "b", "y")
+	b.Labels = map[string]string{"app": "two"}
+	c := newTestObject("ns2", "c", "x")
+	c.Labels = map[string]string{"app": "one"}
+	client := newTestClient(t, c, b, a)
+
+	names := func(list resource.ListObject) []string {
+		res := make([]string, 0)
+		for _, item := range list.GetItems() {
+			res = append(res, item.GetName())
+		}
+		return res
+	}
+
+	list, err := client.List(ctx, resource.NamespaceAll, resource.ListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, names(list))
+	assert.NotEmpty(t, list.GetResourceVersion())
+
+	list, err = client.List(ctx, "ns1", resource.ListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, names(list))
+
+	list, err = client.List(ctx, resource.NamespaceAll, resource.ListOptions{LabelFilters: []string{"app=one"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, names(list))
+
+	list, err = client.List(ctx, resource.NamespaceAll, resource.ListOptions{FieldSelectors: []string{"spec.foo=x", "metadata.namespace=ns2"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, names(list))
+
+	_, err = client.List(ctx, resource.NamespaceAll, resource.ListOptions{FieldSelectors: []string{"spec.bar=x"}})
+	assert.True(t, apierrors.IsBadRequest(err))
+
+	t.Run("pagination", func(t *testing.T) {
+		list, err := client.List(ctx, resource.NamespaceAll, resource.ListOptions{Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, names(list))
+		require.NotEmpty(t, list.GetContinue())
+		require.NotNil(t, list.GetRemainingItemCount())
+		assert.Equal(t, int64(1), *list.GetRemainingItemCount())
+
+		list, err = client.List(ctx, resource.NamespaceAll, resource.ListOptions{Limit: 2, Continue: list.GetContinue()})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"c"}, names(list))
+		assert.Empty(t, list.GetContinue())
+		assert.Nil(t, list.GetRemainingItemCount())
+	})
+}
+
+func TestClient_Watch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := newTestClient(t)
+
+	all, err := client.Watch(ctx, resource.NamespaceAll, resource.WatchOptions{})
+	require.NoError(t, err)
+	filtered, err := client.Watch(ctx, "ns", resource.WatchOptions{LabelFilters: []string{"app=one"}})
+	require.NoError(t, err)
+
+	obj := newTestObject("", "", "bar")
+	obj.Labels = map[string]string{"app": "one"}
+	created, err := client.Create(ctx, resource.Identifier{Namespace: "ns", Name: "foo"}, obj, resource.CreateOptions{})
+	require.NoError(t, err)
+	_, err = client.Create(ctx, resource.Identifier{Namespace: "other", Name: "foo"}, obj, resource.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, client.Delete(ctx, resource.Identifier{Namespace: "ns", Name: "foo"}, resource.DeleteOptions{}))
+
+	receive := func(w resource.WatchResponse) resource.WatchEvent {
+		select {
+		case evt := <-w.WatchEvents():
+			return evt
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for watch event")
+			return resource.WatchEvent{}
+		}
+	}
+
+	evt := receive(all)
+	assert.Equal(t, string(watch.Added), evt.EventType)
+	assert.Equal(t, created, evt.Object)
+	assert.Equal(t, string(watch.Added), receive(all).EventType)
+	assert.Equal(t, string(watch.Deleted), receive(all).EventType)
+
+	evt = receive(filtered)
+	assert.Equal(t, string(watch.Added), evt.EventType)
+	assert.Equal(t, "ns", evt.Object.GetNamespace())
+	assert.Equal(t, string(watch.Deleted), receive(filtered).EventType)
+
+	all.Stop()
+	_, open := <-all.WatchEvents()
+	assert.False(t, open)
+	cancel()
+	for range filtered.WatchEvents() {
+		t.Fatal("unexpected event after the watch context was canceled")
+	}
+}
+
+func TestClient_Watch_ResourceVersion(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := newTestClient(t, newTestObject("ns", "a", "bar"))
+	receive := func(w resource.WatchResponse) resource.WatchEvent {
+		select {
+		case evt := <-w.WatchEvents():
+			return evt
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for watch event")
+			return resource.WatchEvent{}
+		}
+	}
+
+	list, err := client.List(ctx, resource.NamespaceAll, resource.ListOptions{})
+	require.NoError(t, err)
+	created, err := client.Create(ctx, resource.Identifier{Namespace: "ns", Name: "b"}, newTestObject("", "", "bar"), resource.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, client.Delete(ctx, resource.Identifier{Namespace: "ns", Name: "a"}, resource.DeleteOptions{}))
+
+	// A watch from the list's resource version gets the changes made since the list
+	w, err := client.Watch(ctx, resource.NamespaceAll, resource.WatchOptions{ResourceVersion: list.GetResourceVersion()})
+	require.NoError(t, err)
+	evt := receive(w)
+	assert.Equal(t, string(watch.Added), evt.EventType)
+	assert.Equal(t, created, evt.Object)
+	evt = receive(w)
+	assert.Equal(t, string(watch.Deleted), evt.EventType)
+	assert.Equal(t, "a", evt.Object.GetName())
+	w.Stop()
+
+	// A watch from an event's resource version only gets the changes after it
+	w, err = client.Watch(ctx, resource.NamespaceAll, resource.WatchOptions{ResourceVersion: created.GetResourceVersion()})
+	require.NoError(t, err)
+	assert.Equal(t, string(watch.Deleted), receive(w).EventType)
+	w.Stop()
+
+	_, err = client.Watch(ctx, resource.NamespaceAll, resource.WatchOptions{ResourceVersion: "foo"})
+	assert.True(t, apierrors.IsBadRequest(err))
+
+	// Once the events after a resource version have been dropped, watching from it fails with 410 Gone
+	client.tracker.eventLogSize = 1
+	_, err = client.Create(ctx, resource.Identifier{Namespace: "ns", Name: "c"}, newTestObject("", "", "bar"), resource.CreateOptions{})
+	require.NoError(t, err)
+	_, err = client.Watch(ctx, resource.NamespaceAll, resource.WatchOptions{ResourceVersion: list.GetResourceVersion()})
+	require.Error(t, err)
+	assert.True(t, apierrors.IsResourceExpired(err))
+	var cast resource.APIServerResponseError
+	require.ErrorAs(t, err, &cast)
+	assert.Equal(t, http.StatusGone, cast.StatusCode())
+}
+
+func TestClient_Reactors(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, newTestObject("ns", "foo", "bar"))
+	id := resource.Identifier{Namespace: "ns", Name: "foo"}
+
+	client.AddReactor(VerbGet, func(_ context.Context, action Action) (bool, runtime.Object, error) {
+		if action.Identifier.Name == "error" {
+			return true, nil, NewStatusError(http.StatusServiceUnavailable, "injected")
+		}
+		if action.Identifier.Name == "static" {
+			return true, newTestObject("ns", "static", "reactor"), nil
+		}
+		return false, nil, nil
+	})
+	client.AddReactor(VerbAll, func(_ context.Context, action Action) (bool, runtime.Object, error) {
+		if action.Verb == VerbUpdate {
+			return true, nil, errors.New("update error")
+		}
+		return false, nil, nil
+	})
+
+	_, err := client.Get(ctx, resource.Identifier{Namespace: "ns", Name: "error"})
+	var serr resource.APIServerResponseError
+	require.True(t, errors.As(err, &serr))
+	assert.Equal(t, http.StatusServiceUnavailable, serr.StatusCode())
+	assert.True(t, apierrors.IsServiceUnavailable(err))
+
+	obj, err := client.Get(ctx, resource.Identifier{Namespace: "ns", Name: "static"})
+	require.NoError(t, err)
+	assert.Equal(t, "reactor", obj.(*testObject).Spec.Foo)
+
+	obj, err = client.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", obj.(*testObject).Spec.Foo)
+
+	_, err = client.Update(ctx, id, obj, resource.UpdateOptions{})
+	assert.Equal(t, errors.New("update error"), err)
+
+	actions := client.Actions()
+	require.Len(t, actions, 4)
+	assert.Equal(t, VerbGet, actions[0].Verb)
+	assert.Equal(t, VerbUpdate, actions[3].Verb)
+	assert.Equal(t, id, actions[3].Identifier)
+	assert.Equal(t, obj, actions[3].Object)
+	client.ClearActions()
+	assert.Empty(t, client.Actions())
+}
+
+func TestClientGenerator(t *testing.T) {
+	gen := NewClientGenerator(nil)
+	first, err := gen.ClientFor(testKind)
+	require.NoError(t, err)
+	second, err := gen.ClientFor(testKind)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	ctx := context.Background()
+	id := resource.Identifier{Namespace: "ns", Name: "foo"}
+	_, err = first.Create(ctx, id, newTestObject("", "", "bar"), resource.CreateOptions{})
+	require.NoError(t, err)
+
+	// Clients from a different generator with the same tracker share objects
+	other, err := NewClientGenerator(gen.Tracker()).ClientFor(testKind)
+	require.NoError(t, err)
+	obj, err := other.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", obj.(*testObject).Spec.Foo)
+
+	// Stores can use the generator in place of a k8s.ClientRegistry
+	store, err := resource.NewTypedStore[*testObject](testKind, gen)
+	require.NoError(t, err)
+	typed, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", typed.Spec.Foo)
+}
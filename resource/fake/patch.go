@@ -0,0 +1,251 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// jsonPatchOperation is a resource.PatchOperation with its value decoded to generic JSON values
+type jsonPatchOperation struct {
+	Operation resource.PatchOp `json:"op"`
+	Path      string           `json:"path"`
+	From      string           `json:"from"`
+	Value     any              `json:"value"`
+}
+
+// applyJSONPatch applies the RFC6902 JSON Patch operations to doc, and returns the patched document
+func applyJSONPatch(doc any, operations []resource.PatchOperation) (any, error) {
+	// Round-trip the operations through JSON, so that their values are generic JSON values like doc
+	raw, err := json.Marshal(operations)
+	if err != nil {
+		return nil, err
+	}
+	ops := make([]jsonPatchOperation, 0, len(operations))
+	if err = json.Unmarshal(raw, &ops); err != nil {
+		return nil, err
+	}
+	for _, op := range ops {
+		path, err := parsePointer(op.Path)
+		if err != nil {
+			return nil, err
+		}
+		switch op.Operation {
+		case resource.PatchOpAdd:
+			doc, err = addValue(doc, path, op.Value)
+		case resource.PatchOpRemove:
+			doc, err = removeValue(doc, path)
+		case resource.PatchOpReplace:
+			if _, err = getValue(doc, path); err == nil {
+				if doc, err = removeValue(doc, path); err == nil {
+					doc, err = addValue(doc, path, op.Value)
+				}
+			}
+		case resource.PatchOpMove, resource.PatchOpCopy:
+			var from []string
+			var val any
+			if from, err = parsePointer(op.From); err != nil {
+				return nil, err
+			}
+			if val, err = getValue(doc, from); err == nil && op.Operation == resource.PatchOpMove {
+				doc, err = removeValue(doc, from)
+			}
+			if err == nil {
+				doc, err = addValue(doc, path, deepCopyJSON(val))
+			}
+		case resource.PatchOpTest:
+			var val any
+			if val, err = getValue(doc, path); err == nil && !reflect.DeepEqual(val, op.Value) {
+				err = fmt.Errorf("test failed: value at '%s' does not match", op.Path)
+			}
+		default:
+			err = fmt.Errorf("unknown operation '%s'", op.Operation)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to apply %s operation at '%s': %w", op.Operation, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+// applyMergePatch applies the RFC7386 JSON Merge Patch patch to doc, and returns the patched document
+func applyMergePatch(doc, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	d, ok := doc.(map[string]any)
+	if !ok {
+		d = make(map[string]any)
+	}
+	for key, val := range p {
+		if val == nil {
+			delete(d, key)
+			continue
+		}
+		d[key] = applyMergePatch(d[key], val)
+	}
+	return d
+}
+
+// parsePointer parses an RFC6901 JSON pointer into its unescaped segments
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path '%s': must be a JSON pointer", pointer)
+	}
+	segments := strings.Split(pointer[1:], "/")
+	for i, s := range segments {
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(s, "~1", "/"), "~0", "~")
+	}
+	return segments, nil
+}
+
+func getValue(doc any, path []string) (any, error) {
+	for _, key := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			val, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("key '%s' does not exist", key)
+			}
+			doc = val
+		case []any:
+			i, err := arrayIndex(key, len(node))
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("cannot get '%s' of a non-container value", key)
+		}
+	}
+	return doc, nil
+}
+
+// addValue adds val at path in doc, and returns the updated doc. An array index in the last segment of path
+// inserts val before the item at the index, and an index of "-" appends val to the array.
+func addValue(doc any, path []string, val any) (any, error) {
+	if len(path) == 0 {
+		return val, nil
+	}
+	key := path[0]
+	switch node := doc.(type) {
+	case map[string]any:
+		if len(path) == 1 {
+			node[key] = val
+			return node, nil
+		}
+		child, ok := node[key]
+		if !ok {
+			return nil, fmt.Errorf("key '%s' does not exist", key)
+		}
+		updated, err := addValue(child, path[1:], val)
+		if err != nil {
+			return nil, err
+		}
+		node[key] = updated
+		return node, nil
+	case []any:
+		if len(path) == 1 {
+			if key == "-" {
+				return append(node, val), nil
+			}
+			i, err := arrayIndex(key, len(node)+1)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = val
+			return node, nil
+		}
+		i, err := arrayIndex(key, len(node))
+		if err != nil {
+			return nil, err
+		}
+		updated, err := addValue(node[i], path[1:], val)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = updated
+		return node, nil
+	default:
+		return nil, fmt.Errorf("cannot add '%s' to a non-container value", key)
+	}
+}
+
+// removeValue removes the value at path from doc, and returns the updated doc
+func removeValue(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+	key := path[0]
+	switch node := doc.(type) {
+	case map[string]any:
+		child, ok := node[key]
+		if !ok {
+			return nil, fmt.Errorf("key '%s' does not exist", key)
+		}
+		if len(path) == 1 {
+			delete(node, key)
+			return node, nil
+		}
+		updated, err := removeValue(child, path[1:])
+		if err != nil {
+			return nil, err
+		}
+		node[key] = updated
+		return node, nil
+	case []any:
+		i, err := arrayIndex(key, len(node))
+		if err != nil {
+			return nil, err
+		}
+		if len(path) == 1 {
+			return append(node[:i], node[i+1:]...), nil
+		}
+		updated, err := removeValue(node[i], path[1:])
+		if err != nil {
+			return nil, err
+		}
+		node[i] = updated
+		return node, nil
+	default:
+		return nil, fmt.Errorf("cannot remove '%s' from a non-container value", key)
+	}
+}
+
+// arrayIndex parses key as an index of an array, which must be less than length
+func arrayIndex(key string, length int) (int, error) {
+	i, err := strconv.Atoi(key)
+	if err != nil || i < 0 || i >= length || (len(key) > 1 && key[0] == '0') {
+		return 0, fmt.Errorf("invalid array index '%s'", key)
+	}
+	return i, nil
+}
+
+func deepCopyJSON(val any) any {
+	switch v := val.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(v))
+		for key, item := range v {
+			c[key] = deepCopyJSON(item)
+		}
+		return c
+	case []any:
+		c := make([]any, len(v))
+		for i, item := range v {
+			c[i] = deepCopyJSON(item)
+		}
+		return c
+	default:
+		return v
+	}
+}
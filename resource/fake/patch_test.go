@@ -0,0 +1,81 @@
+package fake
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		doc         string
+		ops         []resource.PatchOperation
+		expected    string
+		expectedErr string
+	}{{
+		name:     "add to object and array",
+		doc:      `{"a":{"b":1},"list":[1,3]}`,
+		ops:      []resource.PatchOperation{{Operation: resource.PatchOpAdd, Path: "/a/c", Value: 2}, {Operation: resource.PatchOpAdd, Path: "/list/1", Value: 2}, {Operation: resource.PatchOpAdd, Path: "/list/-", Value: 4}},
+		expected: `{"a":{"b":1,"c":2},"list":[1,2,3,4]}`,
+	}, {
+		name:     "remove",
+		doc:      `{"a":{"b":1},"list":[1,2,3]}`,
+		ops:      []resource.PatchOperation{{Operation: resource.PatchOpRemove, Path: "/a/b"}, {Operation: resource.PatchOpRemove, Path: "/list/0"}},
+		expected: `{"a":{},"list":[2,3]}`,
+	}, {
+		name:     "move and copy",
+		doc:      `{"a":{"b":[1]},"c":{}}`,
+		ops:      []resource.PatchOperation{{Operation: resource.PatchOpCopy, From: "/a/b", Path: "/c/b"}, {Operation: resource.PatchOpMove, From: "/a", Path: "/d"}},
+		expected: `{"c":{"b":[1]},"d":{"b":[1]}}`,
+	}, {
+		name:     "escaped pointer",
+		doc:      `{"a/b":{"c~d":1}}`,
+		ops:      []resource.PatchOperation{{Operation: resource.PatchOpReplace, Path: "/a~1b/c~0d", Value: 2}},
+		expected: `{"a/b":{"c~d":2}}`,
+	}, {
+		name:        "replace missing key",
+		doc:         `{}`,
+		ops:         []resource.PatchOperation{{Operation: resource.PatchOpReplace, Path: "/a", Value: 1}},
+		expectedErr: "unable to apply replace operation at '/a': key 'a' does not exist",
+	}, {
+		name:        "array index out of range",
+		doc:         `{"list":[1]}`,
+		ops:         []resource.PatchOperation{{Operation: resource.PatchOpRemove, Path: "/list/1"}},
+		expectedErr: "unable to apply remove operation at '/list/1': invalid array index '1'",
+	}, {
+		name:     "test",
+		doc:      `{"a":{"b":"c"}}`,
+		ops:      []resource.PatchOperation{{Operation: resource.PatchOpTest, Path: "/a", Value: map[string]string{"b": "c"}}},
+		expected: `{"a":{"b":"c"}}`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var doc any
+			require.NoError(t, json.Unmarshal([]byte(test.doc), &doc))
+			res, err := applyJSONPatch(doc, test.ops)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			encoded, err := json.Marshal(res)
+			require.NoError(t, err)
+			assert.JSONEq(t, test.expected, string(encoded))
+		})
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	var doc, patch any
+	require.NoError(t, json.Unmarshal([]byte(`{"a":{"b":1,"c":2},"d":[1,2]}`), &doc))
+	require.NoError(t, json.Unmarshal([]byte(`{"a":{"b":null,"e":3},"d":[3]}`), &patch))
+	encoded, err := json.Marshal(applyMergePatch(doc, patch))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a":{"c":2,"e":3},"d":[3]}`, string(encoded))
+}
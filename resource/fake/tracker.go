@@ -0,0 +1,326 @@
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+// defaultEventLogSize is the number of events of each kind the Tracker keeps for watches which start from a resource version
+const defaultEventLogSize = 1000
+
+// Tracker is an in-memory object store shared by the fake Clients of a ClientGenerator, which tracks objects of every kind.
+// Each write to the Tracker is given a new resource version, from a counter shared by all kinds (as in an API server),
+// and is sent to the matching watches of the kind.
+// The most recent 1000 events of each kind are kept, so that a watch can start from the resource version of an earlier
+// list or event, as with an API server's watch cache. A watch from a resource version older than the kept events
+// fails with a 410 Gone (Expired) error.
+// Objects of each version of a kind are stored separately, as the Tracker does not convert between versions.
+type Tracker struct {
+	// mux guards objects, resourceVersion, watchers, events, and compacted
+	mux             sync.Mutex
+	objects         map[schema.GroupVersionKind]map[string]resource.Object
+	resourceVersion uint64
+	watchers        map[schema.GroupVersionKind][]*watcher
+	// events is the log of the most recent eventLogSize events of each kind, in order of resource version
+	events       map[schema.GroupVersionKind][]trackerEvent
+	eventLogSize int
+	// compacted is the resource version of the most recent event of each kind which was dropped from events
+	compacted map[schema.GroupVersionKind]uint64
+}
+
+// trackerEvent is an event in the Tracker's event log
+type trackerEvent struct {
+	resourceVersion uint64
+	eventType       watch.EventType
+	obj             resource.Object
+}
+
+// NewTracker creates a new Tracker, seeded with objects (see Tracker.Add)
+func NewTracker(objects ...resource.Object) (*Tracker, error) {
+	t := &Tracker{
+		objects:      make(map[schema.GroupVersionKind]map[string]resource.Object),
+		watchers:     make(map[schema.GroupVersionKind][]*watcher),
+		events:       make(map[schema.GroupVersionKind][]trackerEvent),
+		eventLogSize: defaultEventLogSize,
+		compacted:    make(map[schema.GroupVersionKind]uint64),
+	}
+	if err := t.Add(objects...); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Add adds objects to the tracker, as if they had been created. Each object must have its group, version, and kind set
+// (in its apiVersion and kind), and a name. Objects are given a new resource version, and a UID, creation timestamp,
+// and generation if they don't have one. It returns an error if an object already exists.
+func (t *Tracker) Add(objects ...resource.Object) error {
+	for _, obj := range objects {
+		md := obj.GetStaticMetadata()
+		if md.Version == "" || md.Kind == "" {
+			return fmt.Errorf("object %s must have a version and kind", md.Identifier().Name)
+		}
+		if md.Name == "" {
+			return fmt.Errorf("object must have a name")
+		}
+		gvk := schema.GroupVersionKind{Group: md.Group, Version: md.Version, Kind: md.Kind}
+		if _, err := t.create(gvk, schema.GroupResource{Group: md.Group, Resource: md.Kind}, obj.Copy()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// get returns the stored object with namespace and name. The returned object must not be modified.
+func (t *Tracker) get(gvk schema.GroupVersionKind, namespace, name string) (resource.Object, bool) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	obj, ok := t.objects[gvk][objectKey(namespace, name)]
+	return obj, ok
+}
+
+// list returns the stored objects of gvk, sorted by namespace and name, and the current resource version.
+// The returned objects must not be modified.
+func (t *Tracker) list(gvk schema.GroupVersionKind) ([]string, []resource.Object, string) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	keys := make([]string, 0, len(t.objects[gvk]))
+	for key := range t.objects[gvk] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	objects := make([]resource.Object, 0, len(keys))
+	for _, key := range keys {
+		objects = append(objects, t.objects[gvk][key])
+	}
+	return keys, objects, strconv.FormatUint(t.resourceVersion, 10)
+}
+
+// create stores obj, which the tracker takes ownership of, and sends an added event for it.
+// It returns an AlreadyExists error if an object with the same namespace and name exists.
+func (t *Tracker) create(gvk schema.GroupVersionKind, gr schema.GroupResource, obj resource.Object) (resource.Object, error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	key := objectKey(obj.GetNamespace(), obj.GetName())
+	if _, ok := t.objects[gvk][key]; ok {
+		return nil, newStatusError(apierrors.NewAlreadyExists(gr, obj.GetName()))
+	}
+	if obj.GetUID() == "" {
+		obj.SetUID(uuid.NewUUID())
+	}
+	if ts := obj.GetCreationTimestamp(); ts.IsZero() {
+		obj.SetCreationTimestamp(metav1.Now())
+	}
+	if obj.GetGeneration() == 0 {
+		obj.SetGeneration(1)
+	}
+	t.resourceVersion++
+	obj.SetResourceVersion(strconv.FormatUint(t.resourceVersion, 10))
+	if t.objects[gvk] == nil {
+		t.objects[gvk] = make(map[string]resource.Object)
+	}
+	t.objects[gvk][key] = obj
+	t.send(gvk, watch.Added, obj)
+	return obj, nil
+}
+
+// replace replaces the stored object with the namespace and name of obj, which the tracker takes ownership of,
+// if its resource version is expectedResourceVersion, and sends a modified event for it. If obj is being deleted
+// and has no finalizers left, it is removed instead, and a deleted event is sent.
+// It returns a NotFound error if the object does not exist, and a Conflict error if its resource version has changed.
+func (t *Tracker) replace(gvk schema.GroupVersionKind, gr schema.GroupResource, obj resource.Object, expectedResourceVersion string) (resource.Object, error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	key := objectKey(obj.GetNamespace(), obj.GetName())
+	existing, ok := t.objects[gvk][key]
+	if !ok {
+		return nil, newStatusError(apierrors.NewNotFound(gr, obj.GetName()))
+	}
+	if existing.GetResourceVersion() != expectedResourceVersion {
+		return nil, newStatusError(apierrors.NewConflict(gr, obj.GetName(),
+			fmt.Errorf("the object has been modified; please apply your changes to the latest version and try again")))
+	}
+	t.resourceVersion++
+	obj.SetResourceVersion(strconv.FormatUint(t.resourceVersion, 10))
+	if obj.GetDeletionTimestamp() != nil && len(obj.GetFinalizers()) == 0 {
+		delete(t.objects[gvk], key)
+		t.send(gvk, watch.Deleted, obj)
+		return obj, nil
+	}
+	t.objects[gvk][key] = obj
+	t.send(gvk, watch.Modified, obj)
+	return obj, nil
+}
+
+// delete deletes the object with namespace and name, and sends a deleted event for it.
+// If the object has finalizers, it is only marked for deletion with a deletion timestamp, and a modified event is sent.
+func (t *Tracker) delete(gvk schema.GroupVersionKind, gr schema.GroupResource, namespace, name string, preconditions resource.DeleteOptionsPreconditions) error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	key := objectKey(namespace, name)
+	existing, ok := t.objects[gvk][key]
+	if !ok {
+		return newStatusError(apierrors.NewNotFound(gr, name))
+	}
+	if preconditions.ResourceVersion != "" && preconditions.ResourceVersion != existing.GetResourceVersion() {
+		return newStatusError(apierrors.NewConflict(gr, name, fmt.Errorf("the ResourceVersion in the precondition (%s) does not match the ResourceVersion in record (%s)",
+			preconditions.ResourceVersion, existing.GetResourceVersion())))
+	}
+	if preconditions.UID != "" && preconditions.UID != string(existing.GetUID()) {
+		return newStatusError(apierrors.NewConflict(gr, name, fmt.Errorf("the UID in the precondition (%s) does not match the UID in record (%s)",
+			preconditions.UID, existing.GetUID())))
+	}
+	if len(existing.GetFinalizers()) > 0 {
+		if existing.GetDeletionTimestamp() != nil {
+			return nil
+		}
+		t.resourceVersion++
+		obj := existing.Copy()
+		now := metav1.Now()
+		obj.SetDeletionTimestamp(&now)
+		obj.SetResourceVersion(strconv.FormatUint(t.resourceVersion, 10))
+		t.objects[gvk][key] = obj
+		t.send(gvk, watch.Modified, obj)
+		return nil
+	}
+	t.resourceVersion++
+	delete(t.objects[gvk], key)
+	t.send(gvk, watch.Deleted, existing)
+	return nil
+}
+
+// watch adds w to the watchers of gvk, which is sent each event for gvk until it is stopped.
+// If resourceVersion is set (and not "0"), w is first sent each logged event for gvk after resourceVersion.
+// It returns an Expired error if events after resourceVersion have been dropped from the log,
+// and a BadRequest error if resourceVersion is not a valid resource version.
+func (t *Tracker) watch(gvk schema.GroupVersionKind, w *watcher, resourceVersion string) error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if resourceVersion != "" && resourceVersion != "0" {
+		rv, err := strconv.ParseUint(resourceVersion, 10, 64)
+		if err != nil {
+			return newStatusError(apierrors.NewBadRequest(fmt.Sprintf("invalid resource version '%s'", resourceVersion)))
+		}
+		if rv < t.compacted[gvk] {
+			return newStatusError(apierrors.NewResourceExpired(fmt.Sprintf("too old resource version: %d (%d)", rv, t.compacted[gvk]+1)))
+		}
+		for _, evt := range t.events[gvk] {
+			if evt.resourceVersion > rv {
+				w.enqueue(string(evt.eventType), evt.obj)
+			}
+		}
+	}
+	t.watchers[gvk] = append(t.watchers[gvk], w)
+	return nil
+}
+
+// unwatch removes w from the watchers of gvk
+func (t *Tracker) unwatch(gvk schema.GroupVersionKind, w *watcher) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	watchers := t.watchers[gvk]
+	for i, cur := range watchers {
+		if cur == w {
+			t.watchers[gvk] = append(watchers[:i:i], watchers[i+1:]...)
+			return
+		}
+	}
+}
+
+// send logs an event for obj at the current resource version, and sends it to the watchers of gvk.
+// The caller must hold t.mux.
+func (t *Tracker) send(gvk schema.GroupVersionKind, eventType watch.EventType, obj resource.Object) {
+	events := append(t.events[gvk], trackerEvent{
+		resourceVersion: t.resourceVersion,
+		eventType:       eventType,
+		obj:             obj,
+	})
+	if len(events) > t.eventLogSize {
+		dropped := len(events) - t.eventLogSize
+		t.compacted[gvk] = events[dropped-1].resourceVersion
+		events = slices.Clone(events[dropped:])
+	}
+	t.events[gvk] = events
+	for _, w := range t.watchers[gvk] {
+		w.enqueue(string(eventType), obj)
+	}
+}
+
+func objectKey(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
+
+// StatusError is a kubernetes API status error returned by a fake Client, such as a NotFound or Conflict error.
+// It implements resource.APIServerResponseError, and can be checked with the functions of
+// k8s.io/apimachinery/pkg/api/errors (such as IsNotFound), like the errors returned by a k8s.Client.
+type StatusError struct {
+	*apierrors.StatusError
+}
+
+func newStatusError(err *apierrors.StatusError) *StatusError {
+	return &StatusError{StatusError: err}
+}
+
+// NewStatusError returns a StatusError with the HTTP status code and message, for use by reactors which inject errors
+func NewStatusError(statusCode int, message string) *StatusError {
+	return newStatusError(&apierrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    int32(statusCode), //nolint:gosec
+		Reason:  reasonForStatusCode(statusCode),
+		Message: message,
+	}})
+}
+
+// StatusCode returns the HTTP status code of the error
+func (e *StatusError) StatusCode() int {
+	return int(e.ErrStatus.Code)
+}
+
+// Unwrap returns the underlying *apierrors.StatusError
+func (e *StatusError) Unwrap() error {
+	return e.StatusError
+}
+
+func reasonForStatusCode(statusCode int) metav1.StatusReason {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return metav1.StatusReasonBadRequest
+	case http.StatusUnauthorized:
+		return metav1.StatusReasonUnauthorized
+	case http.StatusForbidden:
+		return metav1.StatusReasonForbidden
+	case http.StatusNotFound:
+		return metav1.StatusReasonNotFound
+	case http.StatusConflict:
+		return metav1.StatusReasonConflict
+	case http.StatusUnsupportedMediaType:
+		return metav1.StatusReasonUnsupportedMediaType
+	case http.StatusUnprocessableEntity:
+		return metav1.StatusReasonInvalid
+	case http.StatusTooManyRequests:
+		return metav1.StatusReasonTooManyRequests
+	case http.StatusServiceUnavailable:
+		return metav1.StatusReasonServiceUnavailable
+	case http.StatusGatewayTimeout:
+		return metav1.StatusReasonTimeout
+	default:
+		if statusCode >= 500 {
+			return metav1.StatusReasonInternalError
+		}
+		return metav1.StatusReasonUnknown
+	}
+}
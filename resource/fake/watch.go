@@ -0,0 +1,108 @@
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+var _ resource.WatchResponse = &watcher{}
+
+// defaultWatchEventBufferSize is the buffer size of a watch's event channel when WatchOptions.EventBufferSize is not set
+const defaultWatchEventBufferSize = 100
+
+// watcher is a resource.WatchResponse for a fake Client watch request. Events are queued by the Tracker without blocking,
+// and sent to the event channel in order by a separate goroutine, so that a slow consumer doesn't block writes to the Tracker.
+type watcher struct {
+	// convert converts a stored object to the client's kind, and returns false if the object doesn't match the watch
+	convert func(resource.Object) (resource.Object, bool)
+	events  chan resource.WatchEvent
+	stop    func()
+	// mux guards queue and closed, and ready is signaled when an event is queued or the watch is stopped
+	mux    sync.Mutex
+	ready  *sync.Cond
+	queue  []resource.WatchEvent
+	closed bool
+}
+
+func newWatcher(ctx context.Context, bufferSize int, convert func(resource.Object) (resource.Object, bool), unwatch func(*watcher)) *watcher {
+	if bufferSize <= 0 {
+		bufferSize = defaultWatchEventBufferSize
+	}
+	w := &watcher{
+		convert: convert,
+		events:  make(chan resource.WatchEvent, bufferSize),
+	}
+	w.ready = sync.NewCond(&w.mux)
+	done := make(chan struct{})
+	once := sync.Once{}
+	w.stop = func() {
+		once.Do(func() {
+			unwatch(w)
+			w.mux.Lock()
+			w.closed = true
+			w.mux.Unlock()
+			w.ready.Broadcast()
+			close(done)
+		})
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			w.stop()
+		case <-done:
+		}
+	}()
+	go w.run(done)
+	return w
+}
+
+// enqueue queues an event for obj, which must not be modified, to be sent to the event channel
+func (w *watcher) enqueue(eventType string, obj resource.Object) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if w.closed {
+		return
+	}
+	w.queue = append(w.queue, resource.WatchEvent{EventType: eventType, Object: obj})
+	w.ready.Signal()
+}
+
+// run sends queued events to the event channel until the watch is stopped, then closes it
+func (w *watcher) run(done <-chan struct{}) {
+	defer close(w.events)
+	for {
+		w.mux.Lock()
+		for len(w.queue) == 0 && !w.closed {
+			w.ready.Wait()
+		}
+		if w.closed {
+			w.mux.Unlock()
+			return
+		}
+		evt := w.queue[0]
+		w.queue = w.queue[1:]
+		w.mux.Unlock()
+
+		obj, ok := w.convert(evt.Object)
+		if !ok {
+			continue
+		}
+		select {
+		case w.events <- resource.WatchEvent{EventType: evt.EventType, Object: obj}:
+		case <-done:
+			return
+		}
+	}
+}
+
+// Stop stops the watch, and closes the channel returned by WatchEvents
+func (w *watcher) Stop() {
+	w.stop()
+}
+
+// WatchEvents returns the channel which receives events from the watch
+func (w *watcher) WatchEvents() <-chan resource.WatchEvent {
+	return w.events
+}
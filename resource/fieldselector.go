@@ -0,0 +1,66 @@
+package resource
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// defaultSelectableFields are the field selectors which are supported for every kind, in addition to the kind's SelectableFields
+var defaultSelectableFields = []string{"metadata.name", "metadata.namespace"}
+
+// InvalidFieldSelectorError is returned by ValidateFieldSelectors when a field selector uses a field which is not
+// selectable for the schema
+type InvalidFieldSelectorError struct {
+	// Kind is the kind of the schema the field selector was validated against
+	Kind string
+	// Field is the requested field which is not selectable
+	Field string
+	// Allowed is the list of selectable fields for the schema
+	Allowed []string
+}
+
+func (e *InvalidFieldSelectorError) Error() string {
+	return fmt.Sprintf("field '%s' is not selectable for kind '%s', selectable fields are: %s", e.Field, e.Kind, strings.Join(e.Allowed, ", "))
+}
+
+// SelectableFieldNames returns the fields which can be used in field selectors for the schema:
+// metadata.name and metadata.namespace, which are selectable for every kind, followed by the schema's SelectableFields.
+// Field names do not have a leading '.', and can be used as-is in a field selector, such as "spec.foo=bar".
+func SelectableFieldNames(sch Schema) []string {
+	names := append([]string{}, defaultSelectableFields...)
+	for _, field := range sch.SelectableFields() {
+		name := strings.TrimPrefix(strings.TrimSpace(field.FieldSelector), ".")
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ValidateFieldSelectors checks that every field used by selectors (such as the FieldSelectors in ListOptions or WatchOptions)
+// is selectable for the schema, according to SelectableFieldNames.
+// If a field is not selectable, it returns an *InvalidFieldSelectorError, which lists the selectable fields.
+// It returns an error if a selector cannot be parsed.
+func ValidateFieldSelectors(sch Schema, selectors []string) error {
+	if len(selectors) == 0 {
+		return nil
+	}
+	selector, err := fields.ParseSelector(strings.Join(selectors, ","))
+	if err != nil {
+		return fmt.Errorf("invalid field selector: %w", err)
+	}
+	allowed := SelectableFieldNames(sch)
+	for _, req := range selector.Requirements() {
+		if !slices.Contains(allowed, req.Field) {
+			return &InvalidFieldSelectorError{
+				Kind:    sch.Kind(),
+				Field:   req.Field,
+				Allowed: allowed,
+			}
+		}
+	}
+	return nil
+}
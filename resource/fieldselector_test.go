@@ -0,0 +1,60 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectableFieldNames(t *testing.T) {
+	sch := NewSimpleSchema("g", "v", &TypedSpecObject[string]{}, &TypedList[*TypedSpecObject[string]]{}, WithSelectableFields([]SelectableField{{
+		FieldSelector: ".spec.foo",
+	}, {
+		FieldSelector: "metadata.name",
+	}, {
+		FieldSelector: "status.bar",
+	}}))
+	assert.Equal(t, []string{"metadata.name", "metadata.namespace", "spec.foo", "status.bar"}, SelectableFieldNames(sch))
+}
+
+func TestValidateFieldSelectors(t *testing.T) {
+	sch := NewSimpleSchema("g", "v", &TypedSpecObject[string]{}, &TypedList[*TypedSpecObject[string]]{}, WithKind("Foo"), WithSelectableFields([]SelectableField{{
+		FieldSelector: ".spec.foo",
+	}}))
+
+	tests := []struct {
+		name      string
+		selectors []string
+		err       string
+	}{{
+		name: "no selectors",
+	}, {
+		name:      "valid selectors",
+		selectors: []string{"metadata.name=foo", "spec.foo!=bar,metadata.namespace==default"},
+	}, {
+		name:      "not selectable",
+		selectors: []string{"spec.foo=bar", "spec.bar=foo"},
+		err:       "field 'spec.bar' is not selectable for kind 'Foo', selectable fields are: metadata.name, metadata.namespace, spec.foo",
+	}, {
+		name:      "unparseable",
+		selectors: []string{"spec.foo"},
+		err:       "invalid field selector: invalid selector: 'spec.foo'; can't understand 'spec.foo'",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateFieldSelectors(sch, test.selectors)
+			if test.err == "" {
+				assert.Nil(t, err)
+				return
+			}
+			require.NotNil(t, err)
+			assert.Equal(t, test.err, err.Error())
+		})
+	}
+
+	var cast *InvalidFieldSelectorError
+	require.ErrorAs(t, ValidateFieldSelectors(sch, []string{"spec.bar=foo"}), &cast)
+	assert.Equal(t, "spec.bar", cast.Field)
+	assert.Equal(t, "Foo", cast.Kind)
+}
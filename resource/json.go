@@ -0,0 +1,73 @@
+package resource
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// JSONImplementation is an implementation of JSON marshaling and unmarshaling which can be used in place of encoding/json
+// by JSONCodec and the serializers in the k8s package.
+// Implementations must produce output equivalent to encoding/json, including honoring struct tags,
+// json.Marshaler, and json.Unmarshaler. For example, github.com/json-iterator/go's ConfigCompatibleWithStandardLibrary
+// implements JSONImplementation, and JSONImplementationFuncs can wrap package-level functions such as those
+// in github.com/segmentio/encoding/json.
+type JSONImplementation interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONImplementationFuncs implements JSONImplementation with a marshal and unmarshal function
+type JSONImplementationFuncs struct {
+	MarshalFunc   func(v any) ([]byte, error)
+	UnmarshalFunc func(data []byte, v any) error
+}
+
+// Marshal calls MarshalFunc
+func (f JSONImplementationFuncs) Marshal(v any) ([]byte, error) {
+	return f.MarshalFunc(v)
+}
+
+// Unmarshal calls UnmarshalFunc
+func (f JSONImplementationFuncs) Unmarshal(data []byte, v any) error {
+	return f.UnmarshalFunc(data, v)
+}
+
+// StandardJSON is the JSONImplementation which uses encoding/json. It is the default JSONImplementation.
+var StandardJSON JSONImplementation = standardJSON{}
+
+type standardJSON struct{}
+
+func (standardJSON) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (standardJSON) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+type jsonImplementationHolder struct {
+	impl JSONImplementation
+}
+
+var defaultJSONImplementation atomic.Pointer[jsonImplementationHolder]
+
+// DefaultJSONImplementation returns the JSONImplementation used by JSONCodecs which were not created with
+// WithJSONImplementation, and by the k8s package's serializers. It is StandardJSON unless changed by
+// SetDefaultJSONImplementation.
+func DefaultJSONImplementation() JSONImplementation {
+	if h := defaultJSONImplementation.Load(); h != nil {
+		return h.impl
+	}
+	return StandardJSON
+}
+
+// SetDefaultJSONImplementation sets the JSONImplementation returned by DefaultJSONImplementation.
+// A nil impl resets the default to StandardJSON. As the default is read each time an object is encoded or decoded,
+// it should be set once, before any clients or informers are created.
+func SetDefaultJSONImplementation(impl JSONImplementation) {
+	if impl == nil {
+		defaultJSONImplementation.Store(nil)
+		return
+	}
+	defaultJSONImplementation.Store(&jsonImplementationHolder{impl: impl})
+}
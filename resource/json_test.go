@@ -0,0 +1,116 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type jsonConformanceSpec struct {
+	Title    string               `json:"title"`
+	Count    int                  `json:"count,omitempty"`
+	Tags     []string             `json:"tags"`
+	Extra    map[string]any       `json:"extra,omitempty"`
+	HTML     string               `json:"html"`
+	Nested   *jsonConformanceSpec `json:"nested,omitempty"`
+	internal string
+}
+
+func jsonConformanceObjects() map[string]Object {
+	typed := &TypedSpecObject[jsonConformanceSpec]{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "foo",
+			Namespace:         "ns",
+			ResourceVersion:   "12345",
+			Generation:        3,
+			CreationTimestamp: metav1.NewTime(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+			Labels:            map[string]string{"b": "2", "a": "1"},
+			Finalizers:        []string{"finalizer"},
+		},
+		Spec: jsonConformanceSpec{
+			Title:  "title",
+			Count:  4,
+			Tags:   []string{"x", "y"},
+			Extra:  map[string]any{"z": 1.5, "a": []any{"b", true, nil}},
+			HTML:   "<a href=\"foo\">&</a>  ",
+			Nested: &jsonConformanceSpec{Title: "nested"},
+		},
+	}
+	typed.SetGroupVersionKind(schema.GroupVersionKind{Group: "foo.bar", Version: "v1", Kind: "Foo"})
+	untyped := &UntypedObject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bar",
+			Namespace: "ns",
+		},
+		Spec: map[string]any{"foo": map[string]any{"inner": "bar"}, "list": []any{1.0, "two"}},
+		Subresources: map[string]json.RawMessage{
+			"status": []byte(`{"bar":"foo"}`),
+		},
+	}
+	untyped.SetGroupVersionKind(schema.GroupVersionKind{Group: "foo.bar", Version: "v1", Kind: "Bar"})
+	return map[string]Object{
+		"typed":   typed,
+		"untyped": untyped,
+	}
+}
+
+// TestJSONCodec_JSONImplementationConformance checks that a JSONCodec using an alternative JSONImplementation
+// reads and writes the same JSON as one using encoding/json.
+func TestJSONCodec_JSONImplementationConformance(t *testing.T) {
+	implementations := map[string]JSONImplementation{
+		"jsoniter": jsoniter.ConfigCompatibleWithStandardLibrary,
+		"funcs":    JSONImplementationFuncs{MarshalFunc: json.Marshal, UnmarshalFunc: json.Unmarshal},
+	}
+	standard := NewJSONCodec(WithJSONImplementation(StandardJSON))
+
+	for implName, impl := range implementations {
+		codec := NewJSONCodec(WithJSONImplementation(impl))
+		for objName, obj := range jsonConformanceObjects() {
+			t.Run(implName+"/"+objName, func(t *testing.T) {
+				expected := &bytes.Buffer{}
+				require.Nil(t, standard.Write(expected, obj))
+				actual := &bytes.Buffer{}
+				require.Nil(t, codec.Write(actual, obj))
+				assert.Equal(t, expected.String(), actual.String())
+
+				expectedObj := obj.Copy()
+				require.Nil(t, standard.Read(bytes.NewReader(expected.Bytes()), expectedObj))
+				actualObj := obj.Copy()
+				require.Nil(t, codec.Read(bytes.NewReader(expected.Bytes()), actualObj))
+				assert.Equal(t, expectedObj, actualObj)
+			})
+		}
+		t.Run(implName+"/empty", func(t *testing.T) {
+			assert.Equal(t, io.EOF, codec.Read(&bytes.Buffer{}, &UntypedObject{}))
+		})
+	}
+}
+
+func TestSetDefaultJSONImplementation(t *testing.T) {
+	defer SetDefaultJSONImplementation(nil)
+
+	calls := 0
+	SetDefaultJSONImplementation(JSONImplementationFuncs{
+		MarshalFunc: func(v any) ([]byte, error) {
+			calls++
+			return json.Marshal(v)
+		},
+		UnmarshalFunc: json.Unmarshal,
+	})
+	require.Nil(t, NewJSONCodec().Write(&bytes.Buffer{}, &UntypedObject{}))
+	assert.Equal(t, 1, calls)
+	// A per-codec implementation takes precedence over the default
+	require.Nil(t, NewJSONCodec(WithJSONImplementation(StandardJSON)).Write(&bytes.Buffer{}, &UntypedObject{}))
+	assert.Equal(t, 1, calls)
+
+	SetDefaultJSONImplementation(nil)
+	assert.Equal(t, StandardJSON, DefaultJSONImplementation())
+}
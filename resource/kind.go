@@ -1,6 +1,7 @@
 package resource
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -17,6 +18,7 @@ type KindEncoding string
 const (
 	KindEncodingJSON    KindEncoding = "application/json"
 	KindEncodingYAML    KindEncoding = "application/yaml"
+	KindEncodingCBOR    KindEncoding = "application/cbor"
 	KindEncodingUnknown KindEncoding = ""
 )
 
@@ -95,23 +97,84 @@ func (k *Kind) GroupVersionResource() schema.GroupVersionResource {
 	}
 }
 
+// JSONCodecOption is an options function that can be passed to NewJSONCodec to modify the resulting JSONCodec
+type JSONCodecOption func(*JSONCodec)
+
+// WithJSONImplementation returns a JSONCodecOption that makes the JSONCodec use impl to marshal and unmarshal JSON,
+// rather than DefaultJSONImplementation(). This allows the JSON implementation to be set per-Kind.
+func WithJSONImplementation(impl JSONImplementation) JSONCodecOption {
+	return func(c *JSONCodec) {
+		c.impl = impl
+	}
+}
+
+// WithStrictDecoding returns a JSONCodecOption that makes the JSONCodec return a *StrictDecodingError from Read
+// if the JSON has fields which are not kept in the Object, like a StrictCodec.
+func WithStrictDecoding() JSONCodecOption {
+	return func(c *JSONCodec) {
+		c.strict = true
+	}
+}
+
 // NewJSONCodec returns a pointer to a new JSONCodec instance
-func NewJSONCodec() *JSONCodec {
-	return &JSONCodec{}
+func NewJSONCodec(opts ...JSONCodecOption) *JSONCodec {
+	c := &JSONCodec{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // JSONCodec is a Codec-implementing struct that reads and writes kubernetes-formatted JSON bytes.
-type JSONCodec struct{}
+type JSONCodec struct {
+	impl   JSONImplementation
+	strict bool
+}
+
+// JSONImplementation returns the JSONImplementation used by the JSONCodec
+func (c *JSONCodec) JSONImplementation() JSONImplementation {
+	if c.impl != nil {
+		return c.impl
+	}
+	return DefaultJSONImplementation()
+}
 
 // Read is a simple wrapper for the json package unmarshal into the object.
+// If the JSONCodec was created with WithStrictDecoding, it returns a *StrictDecodingError if the JSON has unknown fields.
 // TODO: expect kubernetes-formatted bytes on input?
-func (*JSONCodec) Read(in io.Reader, out Object) error {
-	// TODO: make this work similar to Write, where the shape of the golang object shouldn't have to match the kubernetes JSON
-	return json.NewDecoder(in).Decode(&out)
+func (c *JSONCodec) Read(in io.Reader, out Object) error {
+	if c.strict {
+		data, err := io.ReadAll(in)
+		if err != nil {
+			return err
+		}
+		if err = c.read(bytes.NewReader(data), out); err != nil {
+			return err
+		}
+		return checkUnknownFields(data, out, c)
+	}
+	return c.read(in, out)
+}
+
+func (c *JSONCodec) read(in io.Reader, out Object) error {
+	impl := c.JSONImplementation()
+	if impl == StandardJSON {
+		// TODO: make this work similar to Write, where the shape of the golang object shouldn't have to match the kubernetes JSON
+		return json.NewDecoder(in).Decode(&out)
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		// Match the json.Decoder behavior for empty input
+		return io.EOF
+	}
+	return impl.Unmarshal(data, &out)
 }
 
 // Write marshals the provided Object into kubernetes-formatted JSON bytes.
-func (*JSONCodec) Write(out io.Writer, in Object) error {
+func (c *JSONCodec) Write(out io.Writer, in Object) error {
 	m := make(map[string]any)
 	m["apiVersion"], m["kind"] = in.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
 	m["metadata"] = metav1.ObjectMeta{
@@ -135,7 +198,13 @@ func (*JSONCodec) Write(out io.Writer, in Object) error {
 	for k, v := range in.GetSubresources() {
 		m[k] = v
 	}
-	return json.NewEncoder(out).Encode(m)
+	b, err := c.JSONImplementation().Marshal(m)
+	if err != nil {
+		return err
+	}
+	// Terminate with a newline like json.Encoder
+	_, err = out.Write(append(b, '\n'))
+	return err
 }
 
 type TypedList[T Object] struct {
@@ -0,0 +1,232 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// AnnotationLineage is the annotation which records the Lineage of an object: the source object it was created
+	// or last updated from, and the controller which did so. Its value is the JSON encoding of a Lineage.
+	AnnotationLineage = AnnotationPrefix + "lineage"
+	// defaultLineageMaxDepth is the maximum number of sources TraceLineage follows if maxDepth is not positive
+	defaultLineageMaxDepth = 10
+)
+
+// LineageSource identifies the version of the source object an object was derived from
+type LineageSource struct {
+	Group           string `json:"group"`
+	Version         string `json:"version"`
+	Kind            string `json:"kind"`
+	Namespace       string `json:"namespace,omitempty"`
+	Name            string `json:"name"`
+	UID             string `json:"uid,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// Identifier returns the Identifier of the source object
+func (s LineageSource) Identifier() Identifier {
+	return Identifier{
+		Namespace: s.Namespace,
+		Name:      s.Name,
+	}
+}
+
+// isObject returns true if obj is the source object, compared by UID if both have one, or by group, kind, namespace, and name
+func (s LineageSource) isObject(obj Object) bool {
+	if s.UID != "" && obj.GetUID() != "" {
+		return s.UID == string(obj.GetUID())
+	}
+	gvk := obj.GroupVersionKind()
+	return s.Group == gvk.Group && s.Kind == gvk.Kind && s.Namespace == obj.GetNamespace() && s.Name == obj.GetName()
+}
+
+// Lineage is the record of which source object, and which controller, created or last updated an object.
+// It is kept in the AnnotationLineage annotation of the object.
+type Lineage struct {
+	// Source is the object the annotated object was derived from
+	Source LineageSource `json:"source"`
+	// Controller is the identity of the app and controller which created or updated the object, such as "my-app/foo-reconciler"
+	Controller string `json:"controller,omitempty"`
+}
+
+// NewLineage returns a Lineage with source as its Source (at its current resource version), and the controller identity.
+func NewLineage(source Object, controller string) Lineage {
+	gvk := source.GroupVersionKind()
+	return Lineage{
+		Source: LineageSource{
+			Group:           gvk.Group,
+			Version:         gvk.Version,
+			Kind:            gvk.Kind,
+			Namespace:       source.GetNamespace(),
+			Name:            source.GetName(),
+			UID:             string(source.GetUID()),
+			ResourceVersion: source.GetResourceVersion(),
+		},
+		Controller: controller,
+	}
+}
+
+// SetLineage sets the AnnotationLineage annotation of obj to lineage, replacing any existing Lineage.
+func SetLineage(obj Object, lineage Lineage) error {
+	val, err := json.Marshal(lineage)
+	if err != nil {
+		return err
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[AnnotationLineage] = string(val)
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// GetLineage returns the Lineage recorded in the AnnotationLineage annotation of obj.
+// If obj has no AnnotationLineage annotation, ErrAnnotationMissing is returned.
+func GetLineage(obj Object) (Lineage, error) {
+	lineage := Lineage{}
+	val, ok := obj.GetAnnotations()[AnnotationLineage]
+	if !ok {
+		return lineage, ErrAnnotationMissing
+	}
+	if err := json.Unmarshal([]byte(val), &lineage); err != nil {
+		return lineage, fmt.Errorf("invalid %s annotation: %w", AnnotationLineage, err)
+	}
+	return lineage, nil
+}
+
+type lineageContextKey struct{}
+
+// WithLineage returns a copy of ctx carrying lineage. Objects created or updated using ctx with a client returned by
+// NewLineageClient (including the clients used by Store and TypedStore) have lineage recorded in their annotations.
+func WithLineage(ctx context.Context, lineage Lineage) context.Context {
+	return context.WithValue(ctx, lineageContextKey{}, lineage)
+}
+
+// LineageFromContext returns the Lineage carried by ctx, if any
+func LineageFromContext(ctx context.Context) (Lineage, bool) {
+	lineage, ok := ctx.Value(lineageContextKey{}).(Lineage)
+	return lineage, ok
+}
+
+// NewLineageClient returns a Client which wraps client, and sets the Lineage carried by the context (see WithLineage)
+// on objects before they are created, updated, or applied with a server-side apply patch.
+// Subresource updates and other patch types are passed through unchanged, and the source object itself is never annotated.
+// If the context carries no Lineage, all calls are passed through unchanged.
+func NewLineageClient(client Client) Client {
+	if _, ok := client.(*lineageClient); ok {
+		return client
+	}
+	return &lineageClient{client}
+}
+
+type lineageClient struct {
+	Client
+}
+
+func (c *lineageClient) Create(ctx context.Context, identifier Identifier, obj Object, options CreateOptions) (Object, error) {
+	if err := setLineageFromContext(ctx, obj); err != nil {
+		return nil, err
+	}
+	return c.Client.Create(ctx, identifier, obj, options)
+}
+
+func (c *lineageClient) CreateInto(ctx context.Context, identifier Identifier, obj Object, options CreateOptions, into Object) error {
+	if err := setLineageFromContext(ctx, obj); err != nil {
+		return err
+	}
+	return c.Client.CreateInto(ctx, identifier, obj, options, into)
+}
+
+func (c *lineageClient) Update(ctx context.Context, identifier Identifier, obj Object, options UpdateOptions) (Object, error) {
+	if options.Subresource == "" {
+		if err := setLineageFromContext(ctx, obj); err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Update(ctx, identifier, obj, options)
+}
+
+func (c *lineageClient) UpdateInto(ctx context.Context, identifier Identifier, obj Object, options UpdateOptions, into Object) error {
+	if options.Subresource == "" {
+		if err := setLineageFromContext(ctx, obj); err != nil {
+			return err
+		}
+	}
+	return c.Client.UpdateInto(ctx, identifier, obj, options, into)
+}
+
+func (c *lineageClient) Patch(ctx context.Context, identifier Identifier, patch PatchRequest, options PatchOptions) (Object, error) {
+	if patch.Type == PatchTypeApply && patch.Object != nil {
+		if err := setLineageFromContext(ctx, patch.Object); err != nil {
+			return nil, err
+		}
+	}
+	return c.Client.Patch(ctx, identifier, patch, options)
+}
+
+func (c *lineageClient) PatchInto(ctx context.Context, identifier Identifier, patch PatchRequest, options PatchOptions, into Object) error {
+	if patch.Type == PatchTypeApply && patch.Object != nil {
+		if err := setLineageFromContext(ctx, patch.Object); err != nil {
+			return err
+		}
+	}
+	return c.Client.PatchInto(ctx, identifier, patch, options, into)
+}
+
+func setLineageFromContext(ctx context.Context, obj Object) error {
+	lineage, ok := LineageFromContext(ctx)
+	if !ok || obj == nil {
+		return nil
+	}
+	// An object is not its own source (such as when a reconciler updates the object it is reconciling)
+	if lineage.Source.isObject(obj) {
+		return nil
+	}
+	return SetLineage(obj, lineage)
+}
+
+// LineageGetter gets the current state of the source object of a Lineage
+type LineageGetter func(ctx context.Context, source LineageSource) (Object, error)
+
+// StoreLineageGetter returns a LineageGetter which gets source objects from store, by their kind.
+// Kinds of sources which are not registered with store cannot be traced.
+func StoreLineageGetter(store *Store) LineageGetter {
+	return func(ctx context.Context, source LineageSource) (Object, error) {
+		return store.Get(ctx, source.Kind, source.Identifier())
+	}
+}
+
+// TraceLineage returns the chain of Lineage records for obj, nearest first: the Lineage of obj,
+// then the Lineage of its source (retrieved with getter), and so on. The chain ends when an object has no Lineage,
+// a source no longer exists, or maxDepth records have been returned (if maxDepth <= 0, the chain is limited to 10).
+// A source which no longer exists still appears in the chain, as the Source of the last record.
+// If getter returns an error other than a 404, the chain so far is returned with the error.
+func TraceLineage(ctx context.Context, obj Object, getter LineageGetter, maxDepth int) ([]Lineage, error) {
+	if maxDepth <= 0 {
+		maxDepth = defaultLineageMaxDepth
+	}
+	chain := make([]Lineage, 0)
+	current := obj
+	for len(chain) < maxDepth {
+		lineage, err := GetLineage(current)
+		if errors.Is(err, ErrAnnotationMissing) {
+			break
+		}
+		if err != nil {
+			return chain, err
+		}
+		chain = append(chain, lineage)
+		current, err = getter(ctx, lineage.Source)
+		if err != nil {
+			if IsNotFound(err) {
+				break
+			}
+			return chain, fmt.Errorf("unable to get lineage source %s '%s': %w", lineage.Source.Kind, lineage.Source.Name, err)
+		}
+	}
+	return chain, nil
+}
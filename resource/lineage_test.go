@@ -0,0 +1,194 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestLineage_SetGet(t *testing.T) {
+	source := newLineageTestObject("Foo", "a", "uid-a")
+	source.SetResourceVersion("12")
+	lineage := NewLineage(source, "app/foo-reconciler")
+	assert.Equal(t, LineageSource{
+		Group:           "foo.grafana.com",
+		Version:         "v1",
+		Kind:            "Foo",
+		Namespace:       "ns",
+		Name:            "a",
+		UID:             "uid-a",
+		ResourceVersion: "12",
+	}, lineage.Source)
+
+	obj := &UntypedObject{}
+	_, err := GetLineage(obj)
+	assert.ErrorIs(t, err, ErrAnnotationMissing)
+
+	require.Nil(t, SetLineage(obj, lineage))
+	got, err := GetLineage(obj)
+	require.Nil(t, err)
+	assert.Equal(t, lineage, got)
+
+	obj.SetAnnotations(map[string]string{AnnotationLineage: "{"})
+	_, err = GetLineage(obj)
+	assert.NotNil(t, err)
+	assert.NotErrorIs(t, err, ErrAnnotationMissing)
+}
+
+func TestLineageClient(t *testing.T) {
+	source := newLineageTestObject("Foo", "a", "uid-a")
+	lineage := NewLineage(source, "app/foo-reconciler")
+	ctx := WithLineage(context.Background(), lineage)
+	received := make([]Object, 0)
+	client := NewLineageClient(&mockClient{
+		CreateFunc: func(_ context.Context, _ Identifier, obj Object, _ CreateOptions) (Object, error) {
+			received = append(received, obj)
+			return obj, nil
+		},
+		UpdateFunc: func(_ context.Context, _ Identifier, obj Object, _ UpdateOptions) (Object, error) {
+			received = append(received, obj)
+			return obj, nil
+		},
+		PatchFunc: func(_ context.Context, _ Identifier, patch PatchRequest, _ PatchOptions) (Object, error) {
+			received = append(received, patch.Object)
+			return patch.Object, nil
+		},
+	})
+	assert.Same(t, client, NewLineageClient(client))
+
+	t.Run("no lineage in context", func(t *testing.T) {
+		obj := newLineageTestObject("Bar", "b", "uid-b")
+		_, err := client.Create(context.Background(), Identifier{}, obj, CreateOptions{})
+		require.Nil(t, err)
+		assert.NotContains(t, obj.GetAnnotations(), AnnotationLineage)
+	})
+
+	t.Run("create, update, and apply", func(t *testing.T) {
+		received = received[:0]
+		_, err := client.Create(ctx, Identifier{}, newLineageTestObject("Bar", "b", ""), CreateOptions{})
+		require.Nil(t, err)
+		_, err = client.Update(ctx, Identifier{}, newLineageTestObject("Bar", "b", "uid-b"), UpdateOptions{})
+		require.Nil(t, err)
+		_, err = client.Patch(ctx, Identifier{}, PatchRequest{Type: PatchTypeApply, Object: newLineageTestObject("Bar", "b", "uid-b")}, PatchOptions{})
+		require.Nil(t, err)
+		require.Len(t, received, 3)
+		for _, obj := range received {
+			got, err := GetLineage(obj)
+			require.Nil(t, err)
+			assert.Equal(t, lineage, got)
+		}
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		received = received[:0]
+		// Subresource updates, the source object itself, and non-apply patches
+		_, err := client.Update(ctx, Identifier{}, newLineageTestObject("Bar", "b", "uid-b"), UpdateOptions{Subresource: "status"})
+		require.Nil(t, err)
+		_, err = client.Update(ctx, Identifier{}, source.Copy(), UpdateOptions{})
+		require.Nil(t, err)
+		_, err = client.Patch(ctx, Identifier{}, PatchRequest{Type: PatchTypeApply, Object: newLineageTestObject("Foo", "a", "")}, PatchOptions{})
+		require.Nil(t, err)
+		_, err = client.Patch(ctx, Identifier{}, PatchRequest{Type: PatchTypeMergePatch, Object: newLineageTestObject("Bar", "b", "uid-b")}, PatchOptions{})
+		require.Nil(t, err)
+		require.Len(t, received, 4)
+		for _, obj := range received {
+			assert.NotContains(t, obj.GetAnnotations(), AnnotationLineage)
+		}
+	})
+}
+
+func TestTraceLineage(t *testing.T) {
+	root := newLineageTestObject("Foo", "root", "uid-root")
+	middle := newLineageTestObject("Bar", "middle", "uid-middle")
+	require.Nil(t, SetLineage(middle, NewLineage(root, "app/foo-reconciler")))
+	leaf := newLineageTestObject("Baz", "leaf", "uid-leaf")
+	require.Nil(t, SetLineage(leaf, NewLineage(middle, "app/bar-reconciler")))
+	objects := map[string]Object{
+		"root":   root,
+		"middle": middle,
+	}
+	getter := func(_ context.Context, source LineageSource) (Object, error) {
+		if obj, ok := objects[source.Name]; ok {
+			return obj, nil
+		}
+		return nil, &testAPIError{err: errors.New("not found"), statusCode: http.StatusNotFound}
+	}
+
+	t.Run("full chain", func(t *testing.T) {
+		chain, err := TraceLineage(context.Background(), leaf, getter, 0)
+		require.Nil(t, err)
+		require.Len(t, chain, 2)
+		assert.Equal(t, "middle", chain[0].Source.Name)
+		assert.Equal(t, "app/bar-reconciler", chain[0].Controller)
+		assert.Equal(t, "root", chain[1].Source.Name)
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		chain, err := TraceLineage(context.Background(), leaf, getter, 1)
+		require.Nil(t, err)
+		assert.Len(t, chain, 1)
+	})
+
+	t.Run("deleted source", func(t *testing.T) {
+		delete(objects, "middle")
+		defer func() { objects["middle"] = middle }()
+		chain, err := TraceLineage(context.Background(), leaf, getter, 0)
+		require.Nil(t, err)
+		require.Len(t, chain, 1)
+		assert.Equal(t, "middle", chain[0].Source.Name)
+	})
+
+	t.Run("getter error", func(t *testing.T) {
+		chain, err := TraceLineage(context.Background(), leaf, func(context.Context, LineageSource) (Object, error) {
+			return nil, errors.New("I AM ERROR")
+		}, 0)
+		assert.NotNil(t, err)
+		assert.Len(t, chain, 1)
+	})
+}
+
+func TestStore_Lineage(t *testing.T) {
+	kind := Kind{
+		Schema: NewSimpleSchema("bar.grafana.com", "v1", &UntypedObject{}, &UntypedList{}, WithKind("Bar")),
+		Codecs: map[KindEncoding]Codec{KindEncodingJSON: &JSONCodec{}},
+	}
+	var created Object
+	store := NewStore(&mockClientGenerator{
+		ClientForFunc: func(Kind) (Client, error) {
+			return &mockClient{
+				CreateFunc: func(_ context.Context, _ Identifier, obj Object, _ CreateOptions) (Object, error) {
+					created = obj
+					return obj, nil
+				},
+			}, nil
+		},
+	})
+	store.Register(kind)
+	source := newLineageTestObject("Foo", "a", "uid-a")
+	ctx := WithLineage(context.Background(), NewLineage(source, "app/foo-reconciler"))
+	_, err := store.Add(ctx, newLineageTestObject("Bar", "b", ""))
+	require.Nil(t, err)
+	require.NotNil(t, created)
+	lineage, err := GetLineage(created)
+	require.Nil(t, err)
+	assert.Equal(t, "a", lineage.Source.Name)
+}
+
+func newLineageTestObject(kind, name string, uid string) *UntypedObject {
+	obj := &UntypedObject{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      name,
+			UID:       types.UID(uid),
+		},
+	}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{Group: "foo.grafana.com", Version: "v1", Kind: kind})
+	return obj
+}
@@ -0,0 +1,115 @@
+package resource
+
+import (
+	"context"
+)
+
+// ListIterator iterates over every object returned by listing a Client, fetching pages as they are needed
+// by following the continue token of each page. The page size is set by the Limit in the ListOptions
+// the iterator is created with (a Limit of 0 lists everything in a single request).
+//
+// Typical usage is:
+//
+//	iter := resource.NewListIterator(client, namespace, resource.ListOptions{Limit: 100})
+//	for iter.Next(ctx) {
+//		obj := iter.Object()
+//		// ...
+//	}
+//	if err := iter.Err(); err != nil {
+//		// ...
+//	}
+//
+// ListIterator contains unexported fields, and must be created with NewListIterator.
+// A ListIterator is not safe for concurrent use.
+type ListIterator struct {
+	client          Client
+	namespace       string
+	options         ListOptions
+	page            []Object
+	current         Object
+	resourceVersion string
+	err             error
+	done            bool
+}
+
+// NewListIterator creates a new ListIterator which lists objects in namespace from client using options.
+// If options.Continue is set, listing begins at that page.
+// options.ResourceVersion and options.ResourceVersionMatch apply to the first page only, as later pages are read
+// at the ResourceVersion of the first.
+// No request is made until the first call to Next.
+func NewListIterator(client Client, namespace string, options ListOptions) *ListIterator {
+	return &ListIterator{
+		client:    client,
+		namespace: namespace,
+		options:   options,
+	}
+}
+
+// Next advances the iterator to the next object, fetching the next page if the current one has been exhausted.
+// It returns false when there are no more objects, or when an error occurred (including ctx being canceled),
+// after which Err should be checked.
+func (l *ListIterator) Next(ctx context.Context) bool {
+	l.current = nil
+	if l.err != nil {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		l.err = err
+		return false
+	}
+	// Pages may be empty but still have a continue token, so keep fetching until we have an item or run out of pages
+	for len(l.page) == 0 {
+		if l.done {
+			return false
+		}
+		resp, err := l.client.List(ctx, l.namespace, l.options)
+		if err != nil {
+			l.err = err
+			return false
+		}
+		l.page = resp.GetItems()
+		l.resourceVersion = resp.GetResourceVersion()
+		l.options.Continue = resp.GetContinue()
+		// Subsequent pages are read at the ResourceVersion of the continue token,
+		// and the API server rejects requests which set both
+		l.options.ResourceVersion = ""
+		l.options.ResourceVersionMatch = ""
+		l.done = l.options.Continue == ""
+	}
+	l.current = l.page[0]
+	l.page = l.page[1:]
+	return true
+}
+
+// Object returns the current object. It is only valid after a call to Next which returned true.
+func (l *ListIterator) Object() Object {
+	return l.current
+}
+
+// Err returns the error which stopped iteration, if any
+func (l *ListIterator) Err() error {
+	return l.err
+}
+
+// ResourceVersion returns the ResourceVersion of the most recently fetched page.
+// Once Next has returned false without an error, this is the ResourceVersion of the final page,
+// which can be used to start a watch from the listed state.
+func (l *ListIterator) ResourceVersion() string {
+	return l.resourceVersion
+}
+
+// ForEach lists every object in namespace from client, following continue tokens to fetch pages of options.Limit size,
+// and calls fn with each object. If fn returns an error, no further objects are listed, and the error is returned.
+// On success, it returns the ResourceVersion of the final page.
+func ForEach(ctx context.Context, client Client, namespace string, options ListOptions, fn func(Object) error) (string, error) {
+	iter := NewListIterator(client, namespace, options)
+	for iter.Next(ctx) {
+		if err := fn(iter.Object()); err != nil {
+			return "", err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return "", err
+	}
+	return iter.ResourceVersion(), nil
+}
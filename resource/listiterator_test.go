@@ -0,0 +1,173 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getListIteratorTestClient(t *testing.T, limit int) *mockClient {
+	// Pages are "" -> "b" -> "c", with an empty page in the middle
+	pages := map[string]*UntypedList{
+		"": {
+			ListMeta: metav1.ListMeta{Continue: "b", ResourceVersion: "1"},
+			Items:    []Object{&UntypedObject{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}, &UntypedObject{ObjectMeta: metav1.ObjectMeta{Name: "bar"}}},
+		},
+		"b": {
+			ListMeta: metav1.ListMeta{Continue: "c", ResourceVersion: "2"},
+			Items:    []Object{},
+		},
+		"c": {
+			ListMeta: metav1.ListMeta{ResourceVersion: "3"},
+			Items:    []Object{&UntypedObject{ObjectMeta: metav1.ObjectMeta{Name: "baz"}}},
+		},
+	}
+	return &mockClient{
+		ListFunc: func(ctx context.Context, namespace string, options ListOptions) (ListObject, error) {
+			assert.Equal(t, "ns", namespace)
+			assert.Equal(t, limit, options.Limit)
+			page, ok := pages[options.Continue]
+			if !ok {
+				return nil, fmt.Errorf("unknown continue token '%s'", options.Continue)
+			}
+			return page, nil
+		},
+	}
+}
+
+func TestListIterator(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("all pages", func(t *testing.T) {
+		client := getListIteratorTestClient(t, 2)
+		iter := NewListIterator(client, "ns", ListOptions{Limit: 2})
+		names := make([]string, 0)
+		for iter.Next(ctx) {
+			names = append(names, iter.Object().GetName())
+		}
+		require.Nil(t, iter.Err())
+		assert.Equal(t, []string{"foo", "bar", "baz"}, names)
+		assert.Equal(t, "3", iter.ResourceVersion())
+		assert.Nil(t, iter.Object())
+		// Further calls keep returning false without listing again
+		client.ListFunc = func(ctx context.Context, namespace string, options ListOptions) (ListObject, error) {
+			assert.Fail(t, "list should not be called")
+			return nil, nil
+		}
+		assert.False(t, iter.Next(ctx))
+	})
+
+	t.Run("start from continue", func(t *testing.T) {
+		iter := NewListIterator(getListIteratorTestClient(t, 0), "ns", ListOptions{Continue: "c"})
+		require.True(t, iter.Next(ctx))
+		assert.Equal(t, "baz", iter.Object().GetName())
+		assert.False(t, iter.Next(ctx))
+		assert.Nil(t, iter.Err())
+	})
+
+	t.Run("resource version only on first page", func(t *testing.T) {
+		client := getListIteratorTestClient(t, 2)
+		list := client.ListFunc
+		client.ListFunc = func(ctx context.Context, namespace string, options ListOptions) (ListObject, error) {
+			if options.Continue == "" {
+				assert.Equal(t, "0", options.ResourceVersion)
+				assert.Equal(t, ResourceVersionMatchNotOlderThan, options.ResourceVersionMatch)
+			} else {
+				assert.Empty(t, options.ResourceVersion)
+				assert.Empty(t, options.ResourceVersionMatch)
+			}
+			return list(ctx, namespace, options)
+		}
+		iter := NewListIterator(client, "ns", ListOptions{
+			Limit:                2,
+			ResourceVersion:      "0",
+			ResourceVersionMatch: ResourceVersionMatchNotOlderThan,
+		})
+		count := 0
+		for iter.Next(ctx) {
+			count++
+		}
+		require.Nil(t, iter.Err())
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("list error", func(t *testing.T) {
+		lerr := fmt.Errorf("I AM ERROR")
+		client := &mockClient{
+			ListFunc: func(ctx context.Context, namespace string, options ListOptions) (ListObject, error) {
+				return nil, lerr
+			},
+		}
+		iter := NewListIterator(client, "ns", ListOptions{})
+		assert.False(t, iter.Next(ctx))
+		assert.Equal(t, lerr, iter.Err())
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		cctx, cancel := context.WithCancel(ctx)
+		iter := NewListIterator(getListIteratorTestClient(t, 2), "ns", ListOptions{Limit: 2})
+		require.True(t, iter.Next(cctx))
+		cancel()
+		assert.False(t, iter.Next(cctx))
+		assert.Equal(t, context.Canceled, iter.Err())
+	})
+}
+
+func TestForEach(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("success", func(t *testing.T) {
+		names := make([]string, 0)
+		rv, err := ForEach(ctx, getListIteratorTestClient(t, 2), "ns", ListOptions{Limit: 2}, func(obj Object) error {
+			names = append(names, obj.GetName())
+			return nil
+		})
+		require.Nil(t, err)
+		assert.Equal(t, []string{"foo", "bar", "baz"}, names)
+		assert.Equal(t, "3", rv)
+	})
+
+	t.Run("fn error", func(t *testing.T) {
+		ferr := fmt.Errorf("stop")
+		calls := 0
+		rv, err := ForEach(ctx, getListIteratorTestClient(t, 2), "ns", ListOptions{Limit: 2}, func(obj Object) error {
+			calls++
+			return ferr
+		})
+		assert.Equal(t, ferr, err)
+		assert.Equal(t, "", rv)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestStore_ForEach(t *testing.T) {
+	client := getListIteratorTestClient(t, 2)
+	store := NewStore(&mockClientGenerator{
+		ClientForFunc: func(kind Kind) (Client, error) {
+			return client, nil
+		},
+	})
+	kind := Kind{NewSimpleSchema("g1", "v1", &UntypedObject{}, &UntypedList{}, WithKind("test")), map[KindEncoding]Codec{KindEncodingJSON: &JSONCodec{}}}
+	store.Register(kind)
+	ctx := context.TODO()
+
+	t.Run("unregistered kind", func(t *testing.T) {
+		_, err := store.ForEach(ctx, "foo", StoreListOptions{}, func(Object) error { return nil })
+		assert.Equal(t, fmt.Errorf("resource kind 'foo' is not registered in store"), err)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		count := 0
+		rv, err := store.ForEach(ctx, kind.Kind(), StoreListOptions{Namespace: "ns", PerPage: 2}, func(Object) error {
+			count++
+			return nil
+		})
+		require.Nil(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, "3", rv)
+	})
+}
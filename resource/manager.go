@@ -1,6 +1,9 @@
 package resource
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // RegisterSchemaOptions are the options passed to a Manager.RegisterSchema call.
 type RegisterSchemaOptions struct {
@@ -15,6 +18,10 @@ type RegisterSchemaOptions struct {
 	// or until the context is canceled, after the rest of the Schema registration logic is complete.
 	// This may be a no-op for implementations.
 	WaitForAvailability bool
+	// Timeout is the maximum duration of the RegisterSchema call, including waiting for availability.
+	// When it is exceeded, any in-flight request is aborted and RegisterSchema returns a context deadline exceeded error.
+	// If zero, the call is only bounded by the context passed to RegisterSchema.
+	Timeout time.Duration
 }
 
 // Manager is an interface allowing in-code management of Schemas.
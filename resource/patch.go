@@ -0,0 +1,187 @@
+package resource
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"gomodules.xyz/jsonpatch/v2"
+)
+
+// PatchBuilder builds a PatchRequest and its PatchOptions for a Client.Patch call.
+// By default, it builds a JSON Patch from the operations added with Add, Remove, Replace, Move, Copy, Test, or Diff.
+// MergePatch, StrategicMergePatch, and Apply instead build a patch from a (partial) typed object.
+// PatchBuilder methods can be chained, and any error is returned by Build.
+//
+//	req, opts, err := resource.NewPatchBuilder().Replace("/spec/title", "foo").Build()
+//	req, opts, err = resource.NewPatchBuilder().Apply(obj).FieldManager("my-operator").ForceConflicts().Build()
+type PatchBuilder struct {
+	patchType  PatchType
+	operations []PatchOperation
+	object     Object
+	options    PatchOptions
+	err        error
+}
+
+// NewPatchBuilder returns a new, empty PatchBuilder
+func NewPatchBuilder() *PatchBuilder {
+	return &PatchBuilder{
+		operations: make([]PatchOperation, 0),
+	}
+}
+
+// Add adds a JSON Patch "add" operation
+func (b *PatchBuilder) Add(path string, value any) *PatchBuilder {
+	return b.addOperation(PatchOperation{Path: path, Operation: PatchOpAdd, Value: value})
+}
+
+// Remove adds a JSON Patch "remove" operation
+func (b *PatchBuilder) Remove(path string) *PatchBuilder {
+	return b.addOperation(PatchOperation{Path: path, Operation: PatchOpRemove})
+}
+
+// Replace adds a JSON Patch "replace" operation
+func (b *PatchBuilder) Replace(path string, value any) *PatchBuilder {
+	return b.addOperation(PatchOperation{Path: path, Operation: PatchOpReplace, Value: value})
+}
+
+// Move adds a JSON Patch "move" operation, which moves the value at `from` to `path`
+func (b *PatchBuilder) Move(from, path string) *PatchBuilder {
+	return b.addOperation(PatchOperation{Path: path, Operation: PatchOpMove, From: from})
+}
+
+// Copy adds a JSON Patch "copy" operation, which copies the value at `from` to `path`
+func (b *PatchBuilder) Copy(from, path string) *PatchBuilder {
+	return b.addOperation(PatchOperation{Path: path, Operation: PatchOpCopy, From: from})
+}
+
+// Test adds a JSON Patch "test" operation, which causes the patch to fail if the value at `path` is not equal to `value`
+func (b *PatchBuilder) Test(path string, value any) *PatchBuilder {
+	return b.addOperation(PatchOperation{Path: path, Operation: PatchOpTest, Value: value})
+}
+
+// Diff adds the JSON Patch operations required to turn original into modified.
+// Both objects are compared in their kubernetes JSON form (using JSONCodec),
+// so custom metadata fields are diffed as annotations.
+func (b *PatchBuilder) Diff(original, modified Object) *PatchBuilder {
+	if original == nil || modified == nil {
+		b.setErr(errors.New("original and modified objects cannot be nil"))
+		return b
+	}
+	codec := NewJSONCodec()
+	origBytes := &bytes.Buffer{}
+	if err := codec.Write(origBytes, original); err != nil {
+		b.setErr(fmt.Errorf("unable to marshal original object: %w", err))
+		return b
+	}
+	modBytes := &bytes.Buffer{}
+	if err := codec.Write(modBytes, modified); err != nil {
+		b.setErr(fmt.Errorf("unable to marshal modified object: %w", err))
+		return b
+	}
+	ops, err := jsonpatch.CreatePatch(origBytes.Bytes(), modBytes.Bytes())
+	if err != nil {
+		b.setErr(fmt.Errorf("unable to diff objects: %w", err))
+		return b
+	}
+	for _, op := range ops {
+		b.addOperation(PatchOperation{Path: op.Path, Operation: PatchOp(op.Operation), Value: op.Value})
+	}
+	return b
+}
+
+// MergePatch makes the builder produce an RFC7386 JSON Merge Patch, using obj as the patch.
+// Fields which are set in obj are set on the patched object. obj should only contain the fields to be changed,
+// as zero-valued fields which are not omitted when encoded are also set.
+func (b *PatchBuilder) MergePatch(obj Object) *PatchBuilder {
+	return b.setObject(PatchTypeMergePatch, obj)
+}
+
+// StrategicMergePatch makes the builder produce a kubernetes strategic merge patch, using obj as the patch.
+// Strategic merge patches are not supported for custom resources.
+func (b *PatchBuilder) StrategicMergePatch(obj Object) *PatchBuilder {
+	return b.setObject(PatchTypeStrategicMergePatch, obj)
+}
+
+// Apply makes the builder produce a kubernetes server-side apply patch, using obj as the applied configuration.
+// The field manager (set with FieldManager) takes ownership of all fields present in obj.
+// If obj does not have a group, version, and kind set, the client will set them from the kind being patched.
+func (b *PatchBuilder) Apply(obj Object) *PatchBuilder {
+	return b.setObject(PatchTypeApply, obj)
+}
+
+// FieldManager sets the field manager for the patch. It is required for Apply patches.
+func (b *PatchBuilder) FieldManager(manager string) *PatchBuilder {
+	b.options.FieldManager = manager
+	return b
+}
+
+// ForceConflicts makes an Apply patch take ownership of fields owned by other field managers, rather than failing with a conflict.
+func (b *PatchBuilder) ForceConflicts() *PatchBuilder {
+	b.options.Force = true
+	return b
+}
+
+// Build returns the PatchRequest and PatchOptions to use in a Client.Patch call,
+// or an error if any builder method failed or the combination of methods called is invalid.
+func (b *PatchBuilder) Build() (PatchRequest, PatchOptions, error) {
+	if b.err != nil {
+		return PatchRequest{}, PatchOptions{}, b.err
+	}
+	switch b.patchType {
+	case "", PatchTypeJSONPatch:
+		if len(b.operations) == 0 {
+			return PatchRequest{}, PatchOptions{}, errors.New("patch must contain at least one operation")
+		}
+		if b.options.Force {
+			return PatchRequest{}, PatchOptions{}, errors.New("force conflicts is only valid for apply patches")
+		}
+		return PatchRequest{
+			Type:       PatchTypeJSONPatch,
+			Operations: b.operations,
+		}, b.options, nil
+	case PatchTypeApply:
+		if b.options.FieldManager == "" {
+			return PatchRequest{}, PatchOptions{}, errors.New("apply patches require a field manager")
+		}
+	default:
+		if b.options.Force {
+			return PatchRequest{}, PatchOptions{}, errors.New("force conflicts is only valid for apply patches")
+		}
+	}
+	return PatchRequest{
+		Type:   b.patchType,
+		Object: b.object,
+	}, b.options, nil
+}
+
+func (b *PatchBuilder) addOperation(op PatchOperation) *PatchBuilder {
+	if b.patchType != "" && b.patchType != PatchTypeJSONPatch {
+		b.setErr(fmt.Errorf("cannot add JSON Patch operations to a %s patch", b.patchType))
+		return b
+	}
+	b.patchType = PatchTypeJSONPatch
+	b.operations = append(b.operations, op)
+	return b
+}
+
+func (b *PatchBuilder) setObject(patchType PatchType, obj Object) *PatchBuilder {
+	if obj == nil {
+		b.setErr(errors.New("patch object cannot be nil"))
+		return b
+	}
+	if b.patchType != "" {
+		b.setErr(fmt.Errorf("cannot make a %s patch into a %s patch", b.patchType, patchType))
+		return b
+	}
+	b.patchType = patchType
+	b.object = obj
+	return b
+}
+
+// setErr sets the builder error, keeping the first error encountered
+func (b *PatchBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
@@ -0,0 +1,86 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPatchBuilder_Build(t *testing.T) {
+	t.Run("json patch", func(t *testing.T) {
+		patch, opts, err := NewPatchBuilder().
+			Add("/spec/foo", "bar").
+			Remove("/spec/baz").
+			Replace("/spec/num", 1).
+			Move("/spec/a", "/spec/b").
+			Copy("/spec/c", "/spec/d").
+			Test("/metadata/resourceVersion", "123").
+			FieldManager("foo").
+			Build()
+		require.Nil(t, err)
+		assert.Equal(t, PatchOptions{FieldManager: "foo"}, opts)
+		assert.Equal(t, PatchRequest{
+			Type: PatchTypeJSONPatch,
+			Operations: []PatchOperation{
+				{Path: "/spec/foo", Operation: PatchOpAdd, Value: "bar"},
+				{Path: "/spec/baz", Operation: PatchOpRemove},
+				{Path: "/spec/num", Operation: PatchOpReplace, Value: 1},
+				{Path: "/spec/b", Operation: PatchOpMove, From: "/spec/a"},
+				{Path: "/spec/d", Operation: PatchOpCopy, From: "/spec/c"},
+				{Path: "/metadata/resourceVersion", Operation: PatchOpTest, Value: "123"},
+			},
+		}, patch)
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		original := &TypedSpecObject[map[string]any]{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+			Spec:       map[string]any{"a": "b", "c": "d"},
+		}
+		modified := original.Copy().(*TypedSpecObject[map[string]any])
+		modified.Spec = map[string]any{"a": "x"}
+		modified.SetLabels(map[string]string{"foo": "bar"})
+		patch, _, err := NewPatchBuilder().Diff(original, modified).Build()
+		require.Nil(t, err)
+		assert.ElementsMatch(t, []PatchOperation{
+			{Path: "/spec/a", Operation: PatchOpReplace, Value: "x"},
+			{Path: "/spec/c", Operation: PatchOpRemove},
+			{Path: "/metadata/labels", Operation: PatchOpAdd, Value: map[string]any{"foo": "bar"}},
+		}, patch.Operations)
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		obj := &TypedSpecObject[string]{Spec: "foo"}
+		patch, opts, err := NewPatchBuilder().Apply(obj).FieldManager("foo").ForceConflicts().Build()
+		require.Nil(t, err)
+		assert.Equal(t, PatchRequest{Type: PatchTypeApply, Object: obj}, patch)
+		assert.Equal(t, PatchOptions{FieldManager: "foo", Force: true}, opts)
+	})
+
+	t.Run("merge patch", func(t *testing.T) {
+		obj := &TypedSpecObject[string]{Spec: "foo"}
+		patch, _, err := NewPatchBuilder().MergePatch(obj).Build()
+		require.Nil(t, err)
+		assert.Equal(t, PatchRequest{Type: PatchTypeMergePatch, Object: obj}, patch)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		obj := &TypedSpecObject[string]{Spec: "foo"}
+		_, _, err := NewPatchBuilder().Build()
+		assert.EqualError(t, err, "patch must contain at least one operation")
+		_, _, err = NewPatchBuilder().Apply(obj).Build()
+		assert.EqualError(t, err, "apply patches require a field manager")
+		_, _, err = NewPatchBuilder().MergePatch(obj).ForceConflicts().Build()
+		assert.EqualError(t, err, "force conflicts is only valid for apply patches")
+		_, _, err = NewPatchBuilder().Replace("/spec", "foo").ForceConflicts().Build()
+		assert.EqualError(t, err, "force conflicts is only valid for apply patches")
+		_, _, err = NewPatchBuilder().MergePatch(obj).Replace("/spec", "foo").Build()
+		assert.EqualError(t, err, "cannot add JSON Patch operations to a merge patch")
+		_, _, err = NewPatchBuilder().Replace("/spec", "foo").Apply(obj).Build()
+		assert.EqualError(t, err, "cannot make a json patch into a apply patch")
+		_, _, err = NewPatchBuilder().MergePatch(nil).Build()
+		assert.EqualError(t, err, "patch object cannot be nil")
+	})
+}
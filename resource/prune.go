@@ -0,0 +1,181 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Pruner removes fields which are not present in a kind's schema from objects, mirroring the structural pruning
+// the kubernetes API server performs for custom resources. Pruning client-side means objects built from
+// loosely-typed sources (such as UI payloads) don't persist unknown fields to storage which doesn't prune,
+// and don't produce spurious differences in apply patches.
+// It should be created with NewPruner.
+type Pruner struct {
+	root *pruneSchema
+}
+
+// NewPruner creates a new Pruner from a kind version's schema. The schema must be a map of top-level field names
+// (such as "spec" and "status") to structural OpenAPI v3 schemas, as in the properties of a CRD's openAPIV3Schema,
+// or as returned by app.VersionSchema.AsMap for a CRD-style schema.
+// The schema keywords used for pruning are "properties", "additionalProperties", "items",
+// "x-kubernetes-preserve-unknown-fields", and "x-kubernetes-embedded-resource". "$ref" is not supported.
+func NewPruner(schema map[string]any) (*Pruner, error) {
+	root := &pruneSchema{
+		properties: make(map[string]*pruneSchema),
+		embedded:   true,
+	}
+	for field, s := range schema {
+		cast, ok := s.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("schema for '%s' must be an object", field)
+		}
+		parsed, err := parsePruneSchema(cast, field)
+		if err != nil {
+			return nil, err
+		}
+		root.properties[field] = parsed
+	}
+	return &Pruner{
+		root: root,
+	}, nil
+}
+
+// Prune removes fields not present in the schema from obj, which should be the unstructured kubernetes JSON form
+// of an object. It returns the paths of all pruned fields (such as "spec.foo" or "spec.items[0].bar"), sorted.
+// The apiVersion, kind, and metadata of the object are never pruned.
+func (p *Pruner) Prune(obj map[string]any) []string {
+	paths := make([]string, 0)
+	p.root.pruneObject(obj, "", &paths)
+	sort.Strings(paths)
+	return paths
+}
+
+// PruneJSON removes fields not present in the schema from the kubernetes JSON bytes of an object.
+// It returns the pruned JSON and the paths of all pruned fields. If no fields are pruned, data is returned unmodified.
+func (p *Pruner) PruneJSON(data []byte) ([]byte, []string, error) {
+	obj := make(map[string]any)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// Don't lose precision for large integers by decoding them as float64
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil {
+		return nil, nil, err
+	}
+	paths := p.Prune(obj)
+	if len(paths) == 0 {
+		return data, paths, nil
+	}
+	pruned, err := json.Marshal(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pruned, paths, nil
+}
+
+type pruneSchema struct {
+	properties map[string]*pruneSchema
+	// additionalProperties is the schema for object fields not in properties, if additionalProperties is set
+	additionalProperties *pruneSchema
+	items                *pruneSchema
+	preserveUnknown      bool
+	embedded             bool
+}
+
+func parsePruneSchema(schema map[string]any, path string) (*pruneSchema, error) {
+	if _, ok := schema["$ref"]; ok {
+		return nil, fmt.Errorf("schema for '%s' uses $ref, which is not supported for pruning", path)
+	}
+	s := &pruneSchema{}
+	if preserve, ok := schema["x-kubernetes-preserve-unknown-fields"].(bool); ok {
+		s.preserveUnknown = preserve
+	}
+	if embedded, ok := schema["x-kubernetes-embedded-resource"].(bool); ok {
+		s.embedded = embedded
+	}
+	if props, ok := schema["properties"]; ok {
+		cast, ok := props.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("properties for '%s' must be an object", path)
+		}
+		s.properties = make(map[string]*pruneSchema, len(cast))
+		for field, prop := range cast {
+			propSchema, ok := prop.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("schema for '%s.%s' must be an object", path, field)
+			}
+			parsed, err := parsePruneSchema(propSchema, path+"."+field)
+			if err != nil {
+				return nil, err
+			}
+			s.properties[field] = parsed
+		}
+	}
+	switch additional := schema["additionalProperties"].(type) {
+	case bool:
+		if additional {
+			s.additionalProperties = &pruneSchema{preserveUnknown: true}
+		}
+	case map[string]any:
+		parsed, err := parsePruneSchema(additional, path+".*")
+		if err != nil {
+			return nil, err
+		}
+		s.additionalProperties = parsed
+	case nil:
+	default:
+		return nil, fmt.Errorf("additionalProperties for '%s' must be a boolean or an object", path)
+	}
+	if items, ok := schema["items"]; ok {
+		cast, ok := items.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("items for '%s' must be an object", path)
+		}
+		parsed, err := parsePruneSchema(cast, path+"[*]")
+		if err != nil {
+			return nil, err
+		}
+		s.items = parsed
+	}
+	return s, nil
+}
+
+func (s *pruneSchema) prune(value any, path string, paths *[]string) {
+	switch cast := value.(type) {
+	case map[string]any:
+		s.pruneObject(cast, path, paths)
+	case []any:
+		if s.items == nil {
+			return
+		}
+		for i, item := range cast {
+			s.items.prune(item, path+"["+strconv.Itoa(i)+"]", paths)
+		}
+	}
+}
+
+func (s *pruneSchema) pruneObject(obj map[string]any, path string, paths *[]string) {
+	for field, value := range obj {
+		fieldPath := field
+		if path != "" {
+			fieldPath = path + "." + field
+		}
+		if s.embedded && (field == "apiVersion" || field == "kind" || field == "metadata") {
+			continue
+		}
+		if prop, ok := s.properties[field]; ok {
+			prop.prune(value, fieldPath, paths)
+			continue
+		}
+		if s.additionalProperties != nil {
+			s.additionalProperties.prune(value, fieldPath, paths)
+			continue
+		}
+		if s.preserveUnknown {
+			continue
+		}
+		delete(obj, field)
+		*paths = append(*paths, fieldPath)
+	}
+}
@@ -0,0 +1,151 @@
+package resource
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruner_Prune(t *testing.T) {
+	pruner, err := NewPruner(map[string]any{
+		"spec": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"title": map[string]any{"type": "string"},
+				"items": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"name": map[string]any{"type": "string"},
+						},
+					},
+				},
+				"labels": map[string]any{
+					"type":                 "object",
+					"additionalProperties": map[string]any{"type": "string"},
+				},
+				"config": map[string]any{
+					"type":                                 "object",
+					"x-kubernetes-preserve-unknown-fields": true,
+				},
+				"template": map[string]any{
+					"type":                           "object",
+					"x-kubernetes-embedded-resource": true,
+					"properties": map[string]any{
+						"spec": map[string]any{"type": "object"},
+					},
+				},
+			},
+		},
+	})
+	require.Nil(t, err)
+
+	obj := map[string]any{
+		"apiVersion": "foo.bar/v1",
+		"kind":       "Foo",
+		"metadata":   map[string]any{"name": "foo", "unknown": "kept"},
+		"spec": map[string]any{
+			"title":   "foo",
+			"unknown": true,
+			"items": []any{
+				map[string]any{"name": "a", "extra": 1},
+				map[string]any{"name": "b"},
+			},
+			"labels": map[string]any{"a": "b"},
+			"config": map[string]any{"anything": map[string]any{"goes": true}},
+			"template": map[string]any{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]any{"name": "bar"},
+				"spec":       map[string]any{"pruned": true},
+				"data":       map[string]any{},
+			},
+		},
+		"status": map[string]any{"state": "ok"},
+	}
+	paths := pruner.Prune(obj)
+	assert.Equal(t, []string{
+		"spec.items[0].extra",
+		"spec.template.data",
+		"spec.template.spec.pruned",
+		"spec.unknown",
+		"status",
+	}, paths)
+	assert.Equal(t, map[string]any{
+		"apiVersion": "foo.bar/v1",
+		"kind":       "Foo",
+		"metadata":   map[string]any{"name": "foo", "unknown": "kept"},
+		"spec": map[string]any{
+			"title": "foo",
+			"items": []any{
+				map[string]any{"name": "a"},
+				map[string]any{"name": "b"},
+			},
+			"labels": map[string]any{"a": "b"},
+			"config": map[string]any{"anything": map[string]any{"goes": true}},
+			"template": map[string]any{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]any{"name": "bar"},
+				"spec":       map[string]any{},
+			},
+		},
+	}, obj)
+}
+
+func TestPruner_PruneJSON(t *testing.T) {
+	pruner, err := NewPruner(map[string]any{
+		"spec": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"count": map[string]any{"type": "integer"},
+			},
+		},
+	})
+	require.Nil(t, err)
+
+	t.Run("nothing pruned", func(t *testing.T) {
+		data := []byte(`{"kind":"Foo","spec":{"count":1}}`)
+		pruned, paths, err := pruner.PruneJSON(data)
+		require.Nil(t, err)
+		assert.Empty(t, paths)
+		assert.Equal(t, data, pruned)
+	})
+
+	t.Run("pruned", func(t *testing.T) {
+		pruned, paths, err := pruner.PruneJSON([]byte(`{"kind":"Foo","spec":{"count":9007199254740993,"extra":"x"}}`))
+		require.Nil(t, err)
+		assert.Equal(t, []string{"spec.extra"}, paths)
+		// Integers are not converted to floats
+		assert.JSONEq(t, `{"kind":"Foo","spec":{"count":9007199254740993}}`, string(pruned))
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, _, err := pruner.PruneJSON([]byte(`{`))
+		assert.Equal(t, io.ErrUnexpectedEOF, err)
+	})
+}
+
+func TestNewPruner_Errors(t *testing.T) {
+	_, err := NewPruner(map[string]any{
+		"spec": "string",
+	})
+	assert.EqualError(t, err, "schema for 'spec' must be an object")
+	_, err = NewPruner(map[string]any{
+		"spec": map[string]any{
+			"properties": map[string]any{
+				"foo": map[string]any{"$ref": "#/components/schemas/foo"},
+			},
+		},
+	})
+	assert.EqualError(t, err, "schema for 'spec.foo' uses $ref, which is not supported for pruning")
+	_, err = NewPruner(map[string]any{
+		"spec": map[string]any{
+			"additionalProperties": "yes",
+		},
+	})
+	assert.EqualError(t, err, "additionalProperties for 'spec' must be a boolean or an object")
+}
@@ -0,0 +1,110 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana-app-sdk/metrics"
+)
+
+const (
+	defaultUpdateRetryMaxAttempts    = 5
+	defaultUpdateRetryInitialBackoff = 10 * time.Millisecond
+	defaultUpdateRetryMaxBackoff     = time.Second
+)
+
+// UpdateRetryOptions are options for Store.UpdateWithRetry and TypedStore.UpdateWithRetry
+type UpdateRetryOptions struct {
+	// MaxAttempts is the maximum number of update attempts, including the first. Defaults to 5.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry after a conflict, which doubles for each subsequent retry.
+	// Each wait is jittered to between half of and the full backoff, so that competing writers do not retry in lockstep.
+	// Defaults to 10ms.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum wait between attempts. Defaults to one second.
+	MaxBackoff time.Duration
+	// Metrics are the metrics to record conflicts to. If nil, conflicts are not recorded as metrics.
+	// A single UpdateConflictMetrics should be shared by all stores which use the same prometheus registerer.
+	Metrics *UpdateConflictMetrics
+}
+
+// UpdateConflictMetrics contains the prometheus metrics for update conflicts in UpdateWithRetry calls.
+// It should be created with NewUpdateConflictMetrics.
+type UpdateConflictMetrics struct {
+	conflicts *prometheus.CounterVec
+	exhausted *prometheus.CounterVec
+}
+
+// NewUpdateConflictMetrics creates a new UpdateConflictMetrics using the provided metrics.Config
+func NewUpdateConflictMetrics(cfg metrics.Config) *UpdateConflictMetrics {
+	return &UpdateConflictMetrics{
+		conflicts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Subsystem: "store",
+			Name:      "update_conflicts_total",
+			Help:      "Total number of update attempts which failed with a conflict in UpdateWithRetry.",
+		}, []string{"kind"}),
+		exhausted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: cfg.Namespace,
+			Subsystem: "store",
+			Name:      "update_conflict_retries_exhausted_total",
+			Help:      "Total number of UpdateWithRetry calls which still failed with a conflict after the maximum number of attempts.",
+		}, []string{"kind"}),
+	}
+}
+
+// PrometheusCollectors returns the prometheus metric collectors used by the UpdateConflictMetrics
+func (m *UpdateConflictMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{m.conflicts, m.exhausted}
+}
+
+// IsConflict returns true if err is an APIServerResponseError with a 409 Conflict status code
+func IsConflict(err error) bool {
+	var cast APIServerResponseError
+	return errors.As(err, &cast) && cast.StatusCode() == http.StatusConflict
+}
+
+// updateWithRetry calls attempt until it succeeds, returns a non-conflict error, or options.MaxAttempts is reached,
+// waiting a jittered exponential backoff between attempts.
+func updateWithRetry[T any](ctx context.Context, kind string, options UpdateRetryOptions, attempt func() (T, error)) (T, error) {
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = defaultUpdateRetryMaxAttempts
+	}
+	if options.InitialBackoff <= 0 {
+		options.InitialBackoff = defaultUpdateRetryInitialBackoff
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = defaultUpdateRetryMaxBackoff
+	}
+	backoff := options.InitialBackoff
+	for i := 1; ; i++ {
+		ret, err := attempt()
+		if err == nil || !IsConflict(err) {
+			return ret, err
+		}
+		if options.Metrics != nil {
+			options.Metrics.conflicts.WithLabelValues(kind).Inc()
+		}
+		if i >= options.MaxAttempts {
+			if options.Metrics != nil {
+				options.Metrics.exhausted.WithLabelValues(kind).Inc()
+			}
+			return ret, fmt.Errorf("update still conflicted after %d attempts: %w", i, err)
+		}
+		wait := backoff/2 + rand.N(backoff/2+1)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ret, ctx.Err()
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, options.MaxBackoff)
+	}
+}
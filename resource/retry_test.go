@@ -0,0 +1,171 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana-app-sdk/metrics"
+)
+
+func TestIsConflict(t *testing.T) {
+	assert.True(t, IsConflict(&testAPIError{err: errors.New("conflict"), statusCode: http.StatusConflict}))
+	assert.True(t, IsConflict(fmt.Errorf("wrapped: %w", &testAPIError{err: errors.New("conflict"), statusCode: http.StatusConflict})))
+	assert.False(t, IsConflict(&testAPIError{err: errors.New("not found"), statusCode: http.StatusNotFound}))
+	assert.False(t, IsConflict(errors.New("conflict")))
+	assert.False(t, IsConflict(nil))
+}
+
+func TestStore_UpdateWithRetry(t *testing.T) {
+	client := &mockClient{}
+	generator := &mockClientGenerator{
+		ClientForFunc: func(kind Kind) (Client, error) {
+			return client, nil
+		},
+	}
+	store := NewStore(generator)
+	kind := Kind{NewSimpleSchema("g1", "v1", &TypedSpecObject[string]{}, &TypedList[*TypedSpecObject[string]]{}, WithKind("test")), map[KindEncoding]Codec{KindEncodingJSON: &JSONCodec{}}}
+	store.Register(kind)
+	ctx := context.TODO()
+	id := Identifier{Namespace: "ns", Name: "test"}
+	conflictErr := &testAPIError{err: errors.New("conflict"), statusCode: http.StatusConflict}
+	options := UpdateRetryOptions{
+		InitialBackoff: time.Millisecond,
+	}
+
+	// The stored version is incremented on each get, simulating a competing writer
+	version := 0
+	client.GetFunc = func(ctx context.Context, identifier Identifier) (Object, error) {
+		version++
+		return &TypedSpecObject[string]{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       identifier.Namespace,
+				Name:            identifier.Name,
+				ResourceVersion: fmt.Sprint(version),
+			},
+		}, nil
+	}
+
+	t.Run("unregistered kind", func(t *testing.T) {
+		_, err := store.UpdateWithRetry(ctx, "foo", id, func(Object) error { return nil }, options)
+		assert.Equal(t, fmt.Errorf("resource kind 'foo' is not registered in store"), err)
+	})
+
+	t.Run("retries conflicts", func(t *testing.T) {
+		m := NewUpdateConflictMetrics(metrics.Config{})
+		opts := options
+		opts.Metrics = m
+		mutations := 0
+		updates := 0
+		client.UpdateFunc = func(ctx context.Context, identifier Identifier, obj Object, options UpdateOptions) (Object, error) {
+			updates++
+			// Each attempt updates the freshly-read version with the mutation re-applied
+			assert.Equal(t, "mutated", obj.(*TypedSpecObject[string]).Spec)
+			assert.Equal(t, obj.GetResourceVersion(), options.ResourceVersion)
+			if updates < 3 {
+				return nil, conflictErr
+			}
+			return obj, nil
+		}
+		ret, err := store.UpdateWithRetry(ctx, kind.Kind(), id, func(obj Object) error {
+			mutations++
+			obj.(*TypedSpecObject[string]).Spec = "mutated"
+			return nil
+		}, opts)
+		require.Nil(t, err)
+		assert.Equal(t, 3, mutations)
+		assert.Equal(t, 3, updates)
+		assert.Equal(t, "mutated", ret.(*TypedSpecObject[string]).Spec)
+		assert.Equal(t, float64(2), testutil.ToFloat64(m.conflicts.WithLabelValues(kind.Kind())))
+		assert.Equal(t, float64(0), testutil.ToFloat64(m.exhausted.WithLabelValues(kind.Kind())))
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		m := NewUpdateConflictMetrics(metrics.Config{})
+		opts := options
+		opts.MaxAttempts = 3
+		opts.Metrics = m
+		updates := 0
+		client.UpdateFunc = func(ctx context.Context, identifier Identifier, obj Object, options UpdateOptions) (Object, error) {
+			updates++
+			return nil, conflictErr
+		}
+		_, err := store.UpdateWithRetry(ctx, kind.Kind(), id, func(Object) error { return nil }, opts)
+		assert.True(t, IsConflict(err))
+		assert.Equal(t, 3, updates)
+		assert.Equal(t, float64(3), testutil.ToFloat64(m.conflicts.WithLabelValues(kind.Kind())))
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.exhausted.WithLabelValues(kind.Kind())))
+	})
+
+	t.Run("non-conflict error", func(t *testing.T) {
+		updateErr := &testAPIError{err: errors.New("bad request"), statusCode: http.StatusBadRequest}
+		updates := 0
+		client.UpdateFunc = func(ctx context.Context, identifier Identifier, obj Object, options UpdateOptions) (Object, error) {
+			updates++
+			return nil, updateErr
+		}
+		_, err := store.UpdateWithRetry(ctx, kind.Kind(), id, func(Object) error { return nil }, options)
+		assert.Equal(t, updateErr, err)
+		assert.Equal(t, 1, updates)
+	})
+
+	t.Run("mutate error", func(t *testing.T) {
+		mutateErr := errors.New("I AM ERROR")
+		client.UpdateFunc = func(ctx context.Context, identifier Identifier, obj Object, options UpdateOptions) (Object, error) {
+			assert.Fail(t, "update should not be called")
+			return nil, nil
+		}
+		_, err := store.UpdateWithRetry(ctx, kind.Kind(), id, func(Object) error { return mutateErr }, options)
+		assert.Equal(t, mutateErr, err)
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		cctx, cancel := context.WithCancel(ctx)
+		client.UpdateFunc = func(ctx context.Context, identifier Identifier, obj Object, options UpdateOptions) (Object, error) {
+			cancel()
+			return nil, conflictErr
+		}
+		opts := options
+		opts.InitialBackoff = time.Hour
+		opts.MaxBackoff = time.Hour
+		_, err := store.UpdateWithRetry(cctx, kind.Kind(), id, func(Object) error { return nil }, opts)
+		assert.Equal(t, context.Canceled, err)
+	})
+}
+
+func TestTypedStore_UpdateWithRetry(t *testing.T) {
+	store, client := getTypedStoreTestSetup()
+	ctx := context.TODO()
+	id := Identifier{Namespace: "ns", Name: "test"}
+	client.GetFunc = func(ctx context.Context, identifier Identifier) (Object, error) {
+		return &TypedSpecStatusObject[string, string]{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: identifier.Namespace,
+				Name:      identifier.Name,
+			},
+		}, nil
+	}
+	updates := 0
+	client.UpdateFunc = func(ctx context.Context, identifier Identifier, obj Object, options UpdateOptions) (Object, error) {
+		updates++
+		if updates == 1 {
+			return nil, &testAPIError{err: errors.New("conflict"), statusCode: http.StatusConflict}
+		}
+		return obj, nil
+	}
+	ret, err := store.UpdateWithRetry(ctx, id, func(obj *TypedSpecStatusObject[string, string]) error {
+		obj.Spec = "mutated"
+		return nil
+	}, UpdateRetryOptions{InitialBackoff: time.Millisecond})
+	require.Nil(t, err)
+	assert.Equal(t, 2, updates)
+	assert.Equal(t, "mutated", ret.Spec)
+}
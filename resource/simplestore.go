@@ -127,7 +127,7 @@ func (s *SimpleStore[T]) Add(ctx context.Context, identifier Identifier, obj T,
 	for _, opt := range opts {
 		opt(&object.ObjectMeta)
 	}
-	ret, err := s.client.Create(ctx, identifier, &object, CreateOptions{})
+	ret, err := s.writeClient().Create(ctx, identifier, &object, CreateOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -161,7 +161,7 @@ func (s *SimpleStore[T]) Update(ctx context.Context, identifier Identifier, obj
 	cmd := object.GetCommonMetadata()
 	cmd.UpdateTimestamp = time.Now().UTC()
 	object.SetCommonMetadata(cmd)
-	ret, err := s.client.Update(ctx, identifier, &object, updateOptions)
+	ret, err := s.writeClient().Update(ctx, identifier, &object, updateOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -181,7 +181,7 @@ func (s *SimpleStore[T]) UpdateSubresource(ctx context.Context, identifier Ident
 			string(subresource): obj,
 		},
 	}
-	ret, err := s.client.Update(ctx, identifier, &object, UpdateOptions{
+	ret, err := s.writeClient().Update(ctx, identifier, &object, UpdateOptions{
 		Subresource: string(subresource),
 	})
 	if err != nil {
@@ -197,6 +197,11 @@ func (s *SimpleStore[T]) Delete(ctx context.Context, identifier Identifier) erro
 
 type MapSubresourceCatalog map[string]any
 
+// writeClient returns the underlying Client, wrapped to record lineage from the context
+func (s *SimpleStore[T]) writeClient() Client {
+	return NewLineageClient(s.client)
+}
+
 //nolint:revive
 func (s *SimpleStore[T]) cast(obj Object) (*TypedObject[T, MapSubresourceCatalog], error) {
 	if cast, ok := obj.(*TypedObject[T, MapSubresourceCatalog]); ok {
@@ -3,6 +3,7 @@ package resource
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -28,6 +29,12 @@ type APIServerResponseError interface {
 	StatusCode() int
 }
 
+// IsNotFound returns true if err is, or wraps, an APIServerResponseError with a 404 Not Found status code
+func IsNotFound(err error) bool {
+	var cast APIServerResponseError
+	return errors.As(err, &cast) && cast.StatusCode() == http.StatusNotFound
+}
+
 type KindCollection interface {
 	Kinds() []Kind
 }
@@ -146,6 +153,36 @@ func (s *Store) Update(ctx context.Context, obj Object) (Object, error) {
 	})
 }
 
+// UpdateWithRetry gets the current version of the object with the provided kind and identifier, calls mutate with it,
+// and updates the object with the result. If the update fails with a conflict (because the object was changed since
+// it was read), the object is read again and mutate is re-applied to the new version, up to options.MaxAttempts times.
+// mutate may be called multiple times, and should only make changes to the provided object.
+// If mutate returns an error, UpdateWithRetry returns that error without updating the object.
+// It returns the updated Object from the storage system.
+func (s *Store) UpdateWithRetry(
+	ctx context.Context, kind string, identifier Identifier, mutate func(obj Object) error, options UpdateRetryOptions,
+) (Object, error) {
+	client, err := s.getClient(kind)
+	if err != nil {
+		return nil, err
+	}
+	return updateWithRetry(ctx, kind, options, func() (Object, error) {
+		obj, err := client.Get(ctx, identifier)
+		if err != nil {
+			return nil, err
+		}
+		if err = mutate(obj); err != nil {
+			return nil, err
+		}
+		md := obj.GetCommonMetadata()
+		md.UpdateTimestamp = time.Now().UTC()
+		obj.SetCommonMetadata(md)
+		return client.Update(ctx, identifier, obj, UpdateOptions{
+			ResourceVersion: obj.GetResourceVersion(),
+		})
+	})
+}
+
 // UpdateSubresource updates a subresource of an object.
 // The provided obj parameter should be the subresource object, not the entire object.
 // No checks are made that the provided object matches the subresource's definition.
@@ -226,6 +263,40 @@ func (s *Store) Upsert(ctx context.Context, obj Object) (Object, error) {
 	}, obj, CreateOptions{})
 }
 
+// Apply creates or updates the provided object using server-side apply, with the field manager in options
+// taking ownership of every field present in obj. Unlike Upsert, this is a single request, so it is not subject to
+// races between reading and writing the object, and fields owned by other field managers are preserved.
+// obj should only contain the fields the caller intends to manage, and must have a namespace if its kind is namespaced.
+// It returns the applied Object from the storage system.
+func (s *Store) Apply(ctx context.Context, obj Object, options ApplyOptions) (Object, error) {
+	if obj.GetStaticMetadata().Kind == "" {
+		return nil, fmt.Errorf("obj.GetStaticMetadata().Kind must not be empty")
+	}
+	if obj.GetName() == "" {
+		return nil, fmt.Errorf("obj.GetName() must not be empty")
+	}
+	if options.FieldManager == "" {
+		return nil, fmt.Errorf("options.FieldManager must not be empty")
+	}
+
+	client, err := s.getClient(obj.GetStaticMetadata().Kind)
+	if err != nil {
+		return nil, err
+	}
+	// Only namespaced kinds require a namespace
+	if s.types[obj.GetStaticMetadata().Kind].Scope() != ClusterScope && obj.GetNamespace() == "" {
+		return nil, fmt.Errorf("obj.GetNamespace() must not be empty")
+	}
+
+	return client.Patch(ctx, obj.GetStaticMetadata().Identifier(), PatchRequest{
+		Type:   PatchTypeApply,
+		Object: obj,
+	}, PatchOptions{
+		FieldManager: options.FieldManager,
+		Force:        options.ForceConflicts,
+	})
+}
+
 // Delete deletes a resource with the given Identifier and kind.
 func (s *Store) Delete(ctx context.Context, kind string, identifier Identifier) error {
 	client, err := s.getClient(kind)
@@ -296,16 +367,26 @@ func (s *Store) ListPage(ctx context.Context, kind string, namespace string, opt
 	return client.List(ctx, namespace, options)
 }
 
-// Client returns a Client for the provided kind, if that kind is tracked by the Store
-func (s *Store) Client(kind string) (Client, error) {
+// ForEach lists all resources using the Namespace and Filters provided in options, calling fn with each object
+// as pages are fetched based on options.PerPage. Unlike List, only one page of results is held in memory at a time.
+// If fn returns an error, no further pages are fetched, and the error is returned.
+// On success, it returns the ResourceVersion of the final page. To control iteration directly, use NewListIterator.
+func (s *Store) ForEach(ctx context.Context, kind string, options StoreListOptions, fn func(Object) error) (string, error) {
 	client, err := s.getClient(kind)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	return client, nil
+	return ForEach(ctx, client, options.Namespace, ListOptions{
+		Limit:          options.PerPage,
+		LabelFilters:   options.Filters,
+		FieldSelectors: options.FieldSelectors,
+	}, fn)
 }
 
-func (s *Store) getClient(kind string) (Client, error) {
+// Client returns a Client for the provided kind, if that kind is tracked by the Store.
+// Unlike the Store's own methods, the returned Client does not record lineage from the context (see WithLineage),
+// unless wrapped with NewLineageClient.
+func (s *Store) Client(kind string) (Client, error) {
 	schema, ok := s.types[kind]
 	if !ok {
 		return nil, fmt.Errorf("resource kind '%s' is not registered in store", kind)
@@ -316,3 +397,11 @@ func (s *Store) getClient(kind string) (Client, error) {
 	}
 	return client, nil
 }
+
+func (s *Store) getClient(kind string) (Client, error) {
+	client, err := s.Client(kind)
+	if err != nil {
+		return nil, err
+	}
+	return NewLineageClient(client), nil
+}
@@ -732,6 +732,93 @@ func TestStore_Upsert(t *testing.T) {
 	})
 }
 
+func TestStore_Apply(t *testing.T) {
+	client := &mockClient{}
+	generator := &mockClientGenerator{}
+	store := NewStore(generator)
+	kind := Kind{NewSimpleSchema("g1", "v1", &TypedSpecObject[any]{}, &TypedList[*TypedSpecObject[string]]{}, WithKind("test")), map[KindEncoding]Codec{KindEncodingJSON: &JSONCodec{}}}
+	store.Register(kind)
+	ctx := context.TODO()
+	obj := &TypedSpecObject[any]{
+		TypeMeta: metav1.TypeMeta{
+			Kind: kind.Kind(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "test",
+		},
+	}
+	generator.ClientForFunc = func(kind Kind) (Client, error) {
+		return client, nil
+	}
+
+	t.Run("empty kind", func(t *testing.T) {
+		ret, err := store.Apply(ctx, &TypedSpecObject[any]{}, ApplyOptions{FieldManager: "test"})
+		require.Nil(t, ret)
+		assert.Equal(t, fmt.Errorf("obj.GetStaticMetadata().Kind must not be empty"), err)
+	})
+
+	t.Run("empty field manager", func(t *testing.T) {
+		ret, err := store.Apply(ctx, obj.Copy(), ApplyOptions{})
+		require.Nil(t, ret)
+		assert.Equal(t, fmt.Errorf("options.FieldManager must not be empty"), err)
+	})
+
+	t.Run("empty namespace", func(t *testing.T) {
+		noNamespace := obj.Copy()
+		noNamespace.SetNamespace("")
+		ret, err := store.Apply(ctx, noNamespace, ApplyOptions{FieldManager: "test"})
+		require.Nil(t, ret)
+		assert.Equal(t, fmt.Errorf("obj.GetNamespace() must not be empty"), err)
+	})
+
+	t.Run("cluster-scoped kind", func(t *testing.T) {
+		clusterKind := Kind{NewSimpleSchema("g1", "v1", &TypedSpecObject[any]{}, &TypedList[*TypedSpecObject[string]]{}, WithKind("cluster"), WithScope(ClusterScope)), map[KindEncoding]Codec{KindEncodingJSON: &JSONCodec{}}}
+		store.Register(clusterKind)
+		clusterObj := &TypedSpecObject[any]{
+			TypeMeta: metav1.TypeMeta{
+				Kind: clusterKind.Kind(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+		}
+		resp := &TypedSpecObject[any]{}
+		client.PatchFunc = func(_ context.Context, identifier Identifier, _ PatchRequest, _ PatchOptions) (Object, error) {
+			assert.Equal(t, Identifier{Name: "test"}, identifier)
+			return resp, nil
+		}
+		ret, err := store.Apply(ctx, clusterObj, ApplyOptions{FieldManager: "test"})
+		assert.Nil(t, err)
+		assert.Equal(t, resp, ret)
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		cerr := fmt.Errorf("I AM ERROR")
+		client.PatchFunc = func(ctx context.Context, identifier Identifier, patch PatchRequest, options PatchOptions) (Object, error) {
+			return nil, cerr
+		}
+		ret, err := store.Apply(ctx, obj.Copy(), ApplyOptions{FieldManager: "test"})
+		assert.Nil(t, ret)
+		assert.Equal(t, cerr, err)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		resp := &TypedSpecObject[int]{}
+		client.PatchFunc = func(c context.Context, identifier Identifier, patch PatchRequest, options PatchOptions) (Object, error) {
+			assert.Equal(t, ctx, c)
+			assert.Equal(t, obj.GetStaticMetadata().Identifier(), identifier)
+			assert.Equal(t, PatchTypeApply, patch.Type)
+			assert.Equal(t, obj, patch.Object)
+			assert.Equal(t, PatchOptions{FieldManager: "test", Force: true}, options)
+			return resp, nil
+		}
+		ret, err := store.Apply(ctx, obj.Copy(), ApplyOptions{FieldManager: "test", ForceConflicts: true})
+		assert.Nil(t, err)
+		assert.Equal(t, resp, ret)
+	})
+}
+
 func TestStore_Delete(t *testing.T) {
 	client := &mockClient{}
 	generator := &mockClientGenerator{}
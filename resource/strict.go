@@ -0,0 +1,238 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+var _ Codec = &StrictCodec{}
+
+// StrictDecodingError is returned when reading an object with a strict codec (see NewStrictCodec and WithStrictDecoding)
+// from JSON which has fields that the Object type does not have, and which would otherwise be silently dropped.
+type StrictDecodingError struct {
+	// UnknownFields are the paths of the unknown fields, such as "spec.foo" or "spec.items[0].bar", sorted
+	UnknownFields []string
+}
+
+func (e *StrictDecodingError) Error() string {
+	fields := make([]string, 0, len(e.UnknownFields))
+	for _, f := range e.UnknownFields {
+		fields = append(fields, fmt.Sprintf("unknown field \"%s\"", f))
+	}
+	return "strict decoding error: " + strings.Join(fields, ", ")
+}
+
+// IsStrictDecodingError returns true if err is, or wraps, a *StrictDecodingError
+func IsStrictDecodingError(err error) bool {
+	var cast *StrictDecodingError
+	return errors.As(err, &cast)
+}
+
+// StrictDecodingObjectError is the *StrictDecodingError for an object in a StrictDecodingListError
+type StrictDecodingObjectError struct {
+	Identifier Identifier
+	Err        *StrictDecodingError
+}
+
+// StrictDecodingListError is returned when reading a list of objects with a strict codec, if one or more items
+// have unknown fields. Unlike a *StrictDecodingError for a single object, it does not mean the read failed:
+// every item is still read into the list, so that one object with unknown fields does not fail the whole list,
+// and the error can be reported for each object instead.
+type StrictDecodingListError struct {
+	// Objects are the errors of each item with unknown fields, in the order of the list
+	Objects []StrictDecodingObjectError
+}
+
+func (e *StrictDecodingListError) Error() string {
+	msgs := make([]string, 0, len(e.Objects))
+	for _, obj := range e.Objects {
+		msgs = append(msgs, fmt.Sprintf("%s/%s: %s", obj.Identifier.Namespace, obj.Identifier.Name, obj.Err.Error()))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the *StrictDecodingError of each object, so that IsStrictDecodingError is true for the list error
+func (e *StrictDecodingListError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Objects))
+	for _, obj := range e.Objects {
+		errs = append(errs, obj.Err)
+	}
+	return errs
+}
+
+// StrictCodec is a Codec for JSON which wraps another Codec (such as a generated kind's JSON codec),
+// and returns a *StrictDecodingError from Read if the JSON has fields which the wrapped Codec does not keep in the Object.
+// This makes schema drift between an API server and an app (such as a field added to a kind in a newer version
+// of the app during a rollout) fail loudly, rather than the field being dropped when the object is next written.
+//
+// Unknown fields are found by writing the read Object with the wrapped Codec, and comparing the fields of the output
+// with the input. Fields of the input with null, empty, or zero values are not reported, as fields with those values
+// may be omitted when an Object is written. Reading with a StrictCodec is therefore slower than with the wrapped Codec,
+// and StrictCodec should be used when detecting drift is worth the cost, such as in staging environments or during rollouts.
+type StrictCodec struct {
+	codec Codec
+}
+
+// NewStrictCodec returns a new StrictCodec which wraps codec
+func NewStrictCodec(codec Codec) *StrictCodec {
+	return &StrictCodec{
+		codec: codec,
+	}
+}
+
+// Read reads the JSON from in into out with the wrapped Codec, and returns a *StrictDecodingError if the JSON has unknown fields
+func (s *StrictCodec) Read(in io.Reader, out Object) error {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	if err = s.codec.Read(bytes.NewReader(data), out); err != nil {
+		return err
+	}
+	return checkUnknownFields(data, out, s.codec)
+}
+
+// Write writes in to out with the wrapped Codec
+func (s *StrictCodec) Write(out io.Writer, in Object) error {
+	return s.codec.Write(out, in)
+}
+
+// StrictDecodingKind returns a copy of kind whose JSON codec is wrapped in a StrictCodec, so that clients and webhooks
+// which read objects of the kind return a *StrictDecodingError for objects with unknown fields.
+// If kind has no JSON codec, or its JSON codec is already strict, the copy has the same codecs as kind.
+func StrictDecodingKind(kind Kind) Kind {
+	codec := kind.Codec(KindEncodingJSON)
+	if codec == nil {
+		return kind
+	}
+	switch cast := codec.(type) {
+	case *StrictCodec:
+		return kind
+	case *JSONCodec:
+		if cast.strict {
+			return kind
+		}
+	}
+	codecs := make(map[KindEncoding]Codec, len(kind.Codecs))
+	for encoding, c := range kind.Codecs {
+		codecs[encoding] = c
+	}
+	codecs[KindEncodingJSON] = NewStrictCodec(codec)
+	kind.Codecs = codecs
+	return kind
+}
+
+// LenientDecodingKind returns a copy of kind whose JSON codec does not use strict decoding, if it is a StrictCodec
+// or a JSONCodec created with WithStrictDecoding. It is used to read objects which must be readable even if they have
+// unknown fields, such as the stored object of an update or delete admission request.
+// If kind's JSON codec is not strict, the copy has the same codecs as kind.
+func LenientDecodingKind(kind Kind) Kind {
+	var lenient Codec
+	switch cast := kind.Codec(KindEncodingJSON).(type) {
+	case *StrictCodec:
+		lenient = cast.codec
+	case *JSONCodec:
+		if !cast.strict {
+			return kind
+		}
+		lenient = &JSONCodec{impl: cast.impl}
+	default:
+		return kind
+	}
+	codecs := make(map[KindEncoding]Codec, len(kind.Codecs))
+	for encoding, c := range kind.Codecs {
+		codecs[encoding] = c
+	}
+	codecs[KindEncodingJSON] = lenient
+	kind.Codecs = codecs
+	return kind
+}
+
+// checkUnknownFields returns a *StrictDecodingError if the JSON data has fields which are not in obj when it is written by codec
+func checkUnknownFields(data []byte, obj Object, codec Codec) error {
+	var input any
+	if err := json.Unmarshal(data, &input); err != nil {
+		// The codec read the data, so don't fail strict decoding on data encoding/json can't parse
+		return nil
+	}
+	buf := &bytes.Buffer{}
+	if err := codec.Write(buf, obj); err != nil {
+		return fmt.Errorf("unable to write object to check for unknown fields: %w", err)
+	}
+	var output any
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		return fmt.Errorf("unable to parse written object to check for unknown fields: %w", err)
+	}
+	unknown := unknownFields(input, output, "", nil)
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return &StrictDecodingError{UnknownFields: unknown}
+}
+
+// unknownFields appends the paths of fields in input which are not in output, and do not have zero values, to unknown
+func unknownFields(input, output any, path string, unknown []string) []string {
+	switch in := input.(type) {
+	case map[string]any:
+		out, ok := output.(map[string]any)
+		if !ok {
+			return unknown
+		}
+		for key, val := range in {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			outVal, ok := out[key]
+			if !ok {
+				if !IsZeroJSONValue(val) {
+					unknown = append(unknown, fieldPath)
+				}
+				continue
+			}
+			unknown = unknownFields(val, outVal, fieldPath, unknown)
+		}
+	case []any:
+		out, ok := output.([]any)
+		if !ok {
+			return unknown
+		}
+		for i, val := range in {
+			if i >= len(out) {
+				break
+			}
+			unknown = unknownFields(val, out[i], fmt.Sprintf("%s[%d]", path, i), unknown)
+		}
+	}
+	return unknown
+}
+
+// IsZeroJSONValue returns whether a value decoded from JSON (with encoding/json, optionally with UseNumber)
+// is null or the zero value of its type, and so may be omitted when the value is encoded with omitempty.
+func IsZeroJSONValue(val any) bool {
+	switch v := val.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case json.Number:
+		f, err := v.Float64()
+		return err == nil && f == 0
+	case map[string]any:
+		return len(v) == 0
+	case []any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,145 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type strictTestSpec struct {
+	Foo   string               `json:"foo"`
+	Bar   int                  `json:"bar,omitempty"`
+	Items []strictTestSpecItem `json:"items,omitempty"`
+}
+
+type strictTestSpecItem struct {
+	Name string `json:"name"`
+}
+
+// structCodec reads and writes objects with encoding/json, like a generated kind's JSON codec
+type structCodec struct{}
+
+func (structCodec) Read(in io.Reader, out Object) error {
+	return json.NewDecoder(in).Decode(out)
+}
+
+func (structCodec) Write(out io.Writer, in Object) error {
+	return json.NewEncoder(out).Encode(in)
+}
+
+func TestStrictDecoding(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		unknownFields []string
+	}{{
+		name:  "no unknown fields",
+		input: `{"apiVersion":"foo.bar/v1","kind":"Foo","metadata":{"name":"a","namespace":"ns","labels":{"a":"b"}},"spec":{"foo":"foo","bar":1,"items":[{"name":"a"}]}}`,
+	}, {
+		name:  "zero values of omitted fields",
+		input: `{"apiVersion":"foo.bar/v1","kind":"Foo","metadata":{"name":"a","creationTimestamp":null},"spec":{"foo":"","bar":0,"items":[]},"status":{}}`,
+	}, {
+		name:          "unknown fields",
+		input:         `{"apiVersion":"foo.bar/v1","kind":"Foo","metadata":{"name":"a"},"spec":{"foo":"foo","baz":true,"items":[{"name":"a"},{"name":"b","extra":"value"}]},"status":{"state":"ok"}}`,
+		unknownFields: []string{"spec.baz", "spec.items[1].extra", "status"},
+	}}
+
+	codecs := map[string]Codec{
+		"JSONCodec":   NewJSONCodec(WithStrictDecoding()),
+		"StrictCodec": NewStrictCodec(structCodec{}),
+	}
+	for codecName, codec := range codecs {
+		for _, test := range tests {
+			t.Run(fmt.Sprintf("%s %s", codecName, test.name), func(t *testing.T) {
+				out := &TypedSpecObject[strictTestSpec]{}
+				err := codec.Read(bytes.NewReader([]byte(test.input)), out)
+				if len(test.unknownFields) == 0 {
+					require.NoError(t, err)
+					return
+				}
+				require.Error(t, err)
+				assert.True(t, IsStrictDecodingError(err))
+				assert.Equal(t, &StrictDecodingError{UnknownFields: test.unknownFields}, err)
+				// The object is still read, so callers can choose to ignore the error
+				assert.Equal(t, "foo", out.Spec.Foo)
+			})
+		}
+	}
+
+	t.Run("non-strict codec", func(t *testing.T) {
+		out := &TypedSpecObject[strictTestSpec]{}
+		err := NewJSONCodec().Read(bytes.NewReader([]byte(tests[2].input)), out)
+		require.NoError(t, err)
+	})
+
+	t.Run("error message", func(t *testing.T) {
+		err := &StrictDecodingError{UnknownFields: []string{"spec.baz", "status"}}
+		assert.EqualError(t, err, `strict decoding error: unknown field "spec.baz", unknown field "status"`)
+		assert.True(t, IsStrictDecodingError(fmt.Errorf("wrapped: %w", err)))
+	})
+}
+
+func TestStrictDecodingKind(t *testing.T) {
+	kind := Kind{
+		Schema: NewSimpleSchema("foo.bar", "v1", &TypedSpecObject[strictTestSpec]{}, &TypedList[*TypedSpecObject[strictTestSpec]]{}, WithKind("Foo")),
+		Codecs: map[KindEncoding]Codec{KindEncodingJSON: structCodec{}},
+	}
+	strict := StrictDecodingKind(kind)
+	assert.IsType(t, &StrictCodec{}, strict.Codec(KindEncodingJSON))
+	// The original kind is unchanged
+	assert.Equal(t, structCodec{}, kind.Codec(KindEncodingJSON))
+	_, err := strict.Read(bytes.NewReader([]byte(`{"apiVersion":"foo.bar/v1","kind":"Foo","metadata":{"name":"a"},"spec":{"unknown":"value"}}`)), KindEncodingJSON)
+	assert.True(t, IsStrictDecodingError(err))
+
+	// Kinds which are already strict, or have no JSON codec, are unchanged
+	again := StrictDecodingKind(strict)
+	assert.Same(t, strict.Codec(KindEncodingJSON), again.Codec(KindEncodingJSON))
+	assert.Nil(t, StrictDecodingKind(Kind{Schema: kind.Schema}).Codecs)
+}
+
+func TestLenientDecodingKind(t *testing.T) {
+	kind := Kind{
+		Schema: NewSimpleSchema("foo.bar", "v1", &TypedSpecObject[strictTestSpec]{}, &TypedList[*TypedSpecObject[strictTestSpec]]{}, WithKind("Foo")),
+		Codecs: map[KindEncoding]Codec{KindEncodingJSON: structCodec{}},
+	}
+	input := []byte(`{"apiVersion":"foo.bar/v1","kind":"Foo","metadata":{"name":"a"},"spec":{"unknown":"value"}}`)
+
+	lenient := LenientDecodingKind(StrictDecodingKind(kind))
+	assert.Equal(t, structCodec{}, lenient.Codec(KindEncodingJSON))
+	_, err := lenient.Read(bytes.NewReader(input), KindEncodingJSON)
+	assert.Nil(t, err)
+
+	strictJSON := Kind{Schema: kind.Schema, Codecs: map[KindEncoding]Codec{KindEncodingJSON: NewJSONCodec(WithStrictDecoding())}}
+	lenientJSON := LenientDecodingKind(strictJSON)
+	_, err = lenientJSON.Read(bytes.NewReader(input), KindEncodingJSON)
+	assert.Nil(t, err)
+	// The original kind is unchanged
+	_, err = strictJSON.Read(bytes.NewReader(input), KindEncodingJSON)
+	assert.True(t, IsStrictDecodingError(err))
+
+	// Kinds which are not strict are unchanged
+	assert.Equal(t, kind.Codecs, LenientDecodingKind(kind).Codecs)
+}
+
+func TestStrictDecodingListError(t *testing.T) {
+	err := &StrictDecodingListError{Objects: []StrictDecodingObjectError{{
+		Identifier: Identifier{Namespace: "ns", Name: "a"},
+		Err:        &StrictDecodingError{UnknownFields: []string{"spec.foo"}},
+	}}}
+	assert.Equal(t, `ns/a: strict decoding error: unknown field "spec.foo"`, err.Error())
+	assert.True(t, IsStrictDecodingError(err))
+}
+
+func TestIsZeroJSONValue(t *testing.T) {
+	for _, zero := range []any{nil, "", false, float64(0), json.Number("0"), map[string]any{}, []any{}} {
+		assert.True(t, IsZeroJSONValue(zero), "%#v", zero)
+	}
+	for _, nonZero := range []any{"a", true, float64(1), json.Number("1.5"), map[string]any{"a": nil}, []any{nil}} {
+		assert.False(t, IsZeroJSONValue(nonZero), "%#v", nonZero)
+	}
+}
@@ -0,0 +1,189 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FieldTransform is a pair of hooks which modify the kubernetes JSON form of an object as it is read or written
+// by a TransformCodec. Each hook is called with the whole object as an unstructured map (with numbers as json.Number),
+// and modifies it in-place. Either hook may be nil.
+type FieldTransform struct {
+	// Read is called with the object before it is decoded into an Object, such as when reading a request or an API response
+	Read func(obj map[string]any) error
+	// Write is called with the object after it is encoded from an Object, such as when writing it to the API server.
+	// Changes made by Write are persisted, so it must not be used to mask or redact values for display.
+	Write func(obj map[string]any) error
+}
+
+// FieldAlias returns a FieldTransform for a field which has been renamed from alias to path, for a transition period
+// where objects may still use the old name. On read, a value at alias is moved to path, unless path is already set
+// (in which case the alias is dropped). On write, only path is written.
+// Both path and alias are dot-separated paths from the root of the object, such as "spec.title".
+func FieldAlias(path, alias string) FieldTransform {
+	return FieldTransform{
+		Read: func(obj map[string]any) error {
+			val, ok := getFieldPath(obj, alias)
+			if !ok {
+				return nil
+			}
+			deleteFieldPath(obj, alias)
+			if _, ok := getFieldPath(obj, path); ok {
+				return nil
+			}
+			return setFieldPath(obj, path, val)
+		},
+		Write: func(obj map[string]any) error {
+			deleteFieldPath(obj, alias)
+			return nil
+		},
+	}
+}
+
+// TransformCodec is a Codec which wraps a JSON Codec, and applies FieldTransforms to the JSON of objects
+// read or written by the wrapped Codec. This allows an app to map fields in both directions of serialization
+// without changing its kind's types. TransformCodec should be created with NewTransformCodec.
+type TransformCodec struct {
+	codec      Codec
+	transforms []FieldTransform
+}
+
+// NewTransformCodec returns a new TransformCodec which wraps codec, which must read and write kubernetes JSON.
+// Read hooks are called in the order the transforms are provided, and Write hooks in reverse order,
+// so each Write hook sees the object as its Read hook would leave it.
+func NewTransformCodec(codec Codec, transforms ...FieldTransform) *TransformCodec {
+	return &TransformCodec{
+		codec:      codec,
+		transforms: transforms,
+	}
+}
+
+// Read reads JSON bytes from in, applies the Read hooks of the transforms, and reads the result into out with the wrapped Codec
+func (c *TransformCodec) Read(in io.Reader, out Object) error {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		// Let the wrapped codec handle empty input
+		return c.codec.Read(bytes.NewReader(data), out)
+	}
+	transformed, err := c.transform(data, true)
+	if err != nil {
+		return err
+	}
+	return c.codec.Read(bytes.NewReader(transformed), out)
+}
+
+// Write writes in with the wrapped Codec, applies the Write hooks of the transforms, and writes the resulting JSON bytes to out
+func (c *TransformCodec) Write(out io.Writer, in Object) error {
+	buf := &bytes.Buffer{}
+	if err := c.codec.Write(buf, in); err != nil {
+		return err
+	}
+	transformed, err := c.transform(buf.Bytes(), false)
+	if err != nil {
+		return err
+	}
+	// Terminate with a newline like JSONCodec
+	_, err = out.Write(append(transformed, '\n'))
+	return err
+}
+
+// transform applies the Read hooks (if read is true) or the Write hooks of the transforms to the JSON object in data
+func (c *TransformCodec) transform(data []byte, read bool) ([]byte, error) {
+	obj := make(map[string]any)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// Don't lose precision for large integers by decoding them as float64
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil {
+		return nil, err
+	}
+	for i := range c.transforms {
+		fn := c.transforms[i].Read
+		if !read {
+			fn = c.transforms[len(c.transforms)-1-i].Write
+		}
+		if fn == nil {
+			continue
+		}
+		if err := fn(obj); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(obj)
+}
+
+// Compile-time interface compliance check
+var _ Codec = &TransformCodec{}
+
+// WithFieldTransforms returns a copy of the Kind with its JSON codec wrapped in a TransformCodec using transforms.
+// Other codecs are unchanged, and the Codecs map of the original Kind is not modified.
+func (k *Kind) WithFieldTransforms(transforms ...FieldTransform) Kind {
+	cpy := *k
+	codecs := make(map[KindEncoding]Codec, len(k.Codecs))
+	for encoding, codec := range k.Codecs {
+		codecs[encoding] = codec
+	}
+	if codec, ok := codecs[KindEncodingJSON]; ok && codec != nil {
+		codecs[KindEncodingJSON] = NewTransformCodec(codec, transforms...)
+	}
+	cpy.Codecs = codecs
+	return cpy
+}
+
+// getFieldPath returns the value at the dot-separated path in obj
+func getFieldPath(obj map[string]any, path string) (any, bool) {
+	parts := strings.Split(path, ".")
+	cur := obj
+	for i, part := range parts {
+		val, ok := cur[part]
+		if !ok {
+			return nil, false
+		}
+		if i == len(parts)-1 {
+			return val, true
+		}
+		if cur, ok = val.(map[string]any); !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// setFieldPath sets the value at the dot-separated path in obj, creating any missing parent objects
+func setFieldPath(obj map[string]any, path string, val any) error {
+	parts := strings.Split(path, ".")
+	cur := obj
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := cur[part]
+		if !ok || next == nil {
+			next = make(map[string]any)
+			cur[part] = next
+		}
+		cast, ok := next.(map[string]any)
+		if !ok {
+			return fmt.Errorf("cannot set '%s': '%s' is not an object", path, part)
+		}
+		cur = cast
+	}
+	cur[parts[len(parts)-1]] = val
+	return nil
+}
+
+// deleteFieldPath removes the value at the dot-separated path in obj, if it exists
+func deleteFieldPath(obj map[string]any, path string) {
+	parts := strings.Split(path, ".")
+	cur := obj
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := cur[part].(map[string]any)
+		if !ok {
+			return
+		}
+		cur = next
+	}
+	delete(cur, parts[len(parts)-1])
+}
@@ -0,0 +1,102 @@
+package resource
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type transformTestSpec struct {
+	Title string `json:"title"`
+	Count int64  `json:"count"`
+}
+
+func TestTransformCodec_Read(t *testing.T) {
+	codec := NewTransformCodec(NewJSONCodec(), FieldAlias("spec.title", "spec.name"))
+
+	t.Run("alias", func(t *testing.T) {
+		obj := &TypedSpecObject[transformTestSpec]{}
+		err := codec.Read(strings.NewReader(`{"apiVersion":"foo/v1","kind":"Foo","metadata":{"name":"foo"},"spec":{"name":"bar","count":9007199254740993}}`), obj)
+		require.Nil(t, err)
+		assert.Equal(t, transformTestSpec{Title: "bar", Count: 9007199254740993}, obj.Spec)
+		assert.Equal(t, "foo", obj.GetName())
+	})
+
+	t.Run("canonical field takes precedence", func(t *testing.T) {
+		obj := &TypedSpecObject[transformTestSpec]{}
+		err := codec.Read(strings.NewReader(`{"metadata":{},"spec":{"name":"bar","title":"foo"}}`), obj)
+		require.Nil(t, err)
+		assert.Equal(t, "foo", obj.Spec.Title)
+	})
+
+	t.Run("transform error", func(t *testing.T) {
+		codec := NewTransformCodec(NewJSONCodec(), FieldTransform{
+			Read: func(map[string]any) error {
+				return errors.New("I AM ERROR")
+			},
+		})
+		err := codec.Read(strings.NewReader(`{"spec":{}}`), &TypedSpecObject[transformTestSpec]{})
+		assert.Equal(t, errors.New("I AM ERROR"), err)
+	})
+}
+
+func TestTransformCodec_Write(t *testing.T) {
+	obj := &TypedSpecObject[transformTestSpec]{
+		Spec: transformTestSpec{
+			Title: "foo",
+		},
+	}
+	obj.Name = "foo"
+
+	t.Run("alias", func(t *testing.T) {
+		codec := NewTransformCodec(NewJSONCodec(), FieldAlias("spec.title", "spec.name"))
+		buf := &bytes.Buffer{}
+		require.Nil(t, codec.Write(buf, obj))
+		assert.JSONEq(t, `{"apiVersion":"","kind":"","metadata":{"name":"foo","creationTimestamp":null},"spec":{"title":"foo","count":0}}`, buf.String())
+	})
+
+	t.Run("hook order", func(t *testing.T) {
+		calls := make([]string, 0)
+		hook := func(name string) func(map[string]any) error {
+			return func(map[string]any) error {
+				calls = append(calls, name)
+				return nil
+			}
+		}
+		codec := NewTransformCodec(NewJSONCodec(),
+			FieldTransform{Read: hook("read1"), Write: hook("write1")},
+			FieldTransform{Write: hook("write2")},
+			FieldTransform{Read: hook("read3"), Write: func(obj map[string]any) error {
+				// Rewrite a field on output
+				obj["spec"].(map[string]any)["title"] = "***"
+				return nil
+			}},
+		)
+		buf := &bytes.Buffer{}
+		require.Nil(t, codec.Write(buf, obj))
+		assert.Contains(t, buf.String(), `"title":"***"`)
+		require.Nil(t, codec.Read(buf, &TypedSpecObject[transformTestSpec]{}))
+		assert.Equal(t, []string{"write2", "write1", "read1", "read3"}, calls)
+	})
+}
+
+func TestKind_WithFieldTransforms(t *testing.T) {
+	jsonCodec := NewJSONCodec()
+	cborCodec := NewCBORCodec()
+	kind := Kind{
+		Codecs: map[KindEncoding]Codec{
+			KindEncodingJSON: jsonCodec,
+			KindEncodingCBOR: cborCodec,
+		},
+	}
+	transformed := kind.WithFieldTransforms(FieldAlias("spec.title", "spec.name"))
+	assert.Equal(t, jsonCodec, kind.Codecs[KindEncodingJSON])
+	assert.Equal(t, cborCodec, transformed.Codecs[KindEncodingCBOR])
+	cast, ok := transformed.Codecs[KindEncodingJSON].(*TransformCodec)
+	require.True(t, ok)
+	assert.Equal(t, jsonCodec, cast.codec)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"sync"
 	"time"
 )
 
@@ -70,7 +71,7 @@ func (t *TypedStore[T]) Add(ctx context.Context, obj T) (T, error) {
 		var n T
 		return n, fmt.Errorf("obj.GetName() must not be empty")
 	}
-	ret, err := t.client.Create(ctx, Identifier{
+	ret, err := t.writeClient().Create(ctx, Identifier{
 		Namespace: obj.GetNamespace(),
 		Name:      obj.GetName(),
 	}, obj, CreateOptions{})
@@ -91,7 +92,7 @@ func (t *TypedStore[T]) Update(ctx context.Context, identifier Identifier, obj T
 	md := obj.GetCommonMetadata()
 	md.UpdateTimestamp = time.Now().UTC()
 	obj.SetCommonMetadata(md)
-	ret, err := t.client.Update(ctx, identifier, obj, UpdateOptions{})
+	ret, err := t.writeClient().Update(ctx, identifier, obj, UpdateOptions{})
 	if err != nil {
 		var n T
 		return n, err
@@ -99,6 +100,26 @@ func (t *TypedStore[T]) Update(ctx context.Context, identifier Identifier, obj T
 	return t.cast(ret)
 }
 
+// UpdateWithRetry gets the current version of the resource, calls mutate with it, and updates the resource with the result.
+// If the update fails with a conflict (because the resource was changed since it was read), the resource is read again
+// and mutate is re-applied to the new version, up to options.MaxAttempts times.
+// mutate may be called multiple times, and should only make changes to the provided object.
+// If mutate returns an error, UpdateWithRetry returns that error without updating the resource.
+// It returns the updated Object from the storage system.
+func (t *TypedStore[T]) UpdateWithRetry(ctx context.Context, identifier Identifier, mutate func(obj T) error, options UpdateRetryOptions) (T, error) {
+	return updateWithRetry(ctx, t.sch.Kind(), options, func() (T, error) {
+		obj, err := t.Get(ctx, identifier)
+		if err != nil {
+			return obj, err
+		}
+		if err = mutate(obj); err != nil {
+			var n T
+			return n, err
+		}
+		return t.Update(ctx, identifier, obj)
+	})
+}
+
 // Upsert updates an existing resource or creates a new one if none exists, and returns the new version.
 // Keep in mind that an Upsert will completely overwrite the object,
 // so nil or missing values will be removed, not ignored.
@@ -123,9 +144,9 @@ func (t *TypedStore[T]) Upsert(ctx context.Context, identifier Identifier, obj T
 		md := obj.GetCommonMetadata()
 		md.UpdateTimestamp = time.Now().UTC()
 		obj.SetCommonMetadata(md)
-		ret, err = t.client.Update(ctx, identifier, obj, UpdateOptions{})
+		ret, err = t.writeClient().Update(ctx, identifier, obj, UpdateOptions{})
 	} else {
-		ret, err = t.client.Create(ctx, Identifier{
+		ret, err = t.writeClient().Create(ctx, Identifier{
 			Namespace: obj.GetNamespace(),
 			Name:      obj.GetName(),
 		}, obj, CreateOptions{})
@@ -138,12 +159,34 @@ func (t *TypedStore[T]) Upsert(ctx context.Context, identifier Identifier, obj T
 	return t.cast(ret)
 }
 
+// Apply creates or updates the resource with the provided identifier using server-side apply, and returns the new version.
+// The field manager in options takes ownership of every field present in obj, and fields owned by other field managers
+// are preserved, so obj should only contain the fields the caller intends to manage.
+func (t *TypedStore[T]) Apply(ctx context.Context, identifier Identifier, obj T, options ApplyOptions) (T, error) {
+	if options.FieldManager == "" {
+		var n T
+		return n, fmt.Errorf("options.FieldManager must not be empty")
+	}
+	ret, err := t.writeClient().Patch(ctx, identifier, PatchRequest{
+		Type:   PatchTypeApply,
+		Object: obj,
+	}, PatchOptions{
+		FieldManager: options.FieldManager,
+		Force:        options.ForceConflicts,
+	})
+	if err != nil {
+		var n T
+		return n, err
+	}
+	return t.cast(ret)
+}
+
 // UpdateSubresource updates a subresource of an object.
 // The provided obj parameter must have the specified subresource,
 // and only that subresource will be updated in the storage system.
 func (t *TypedStore[T]) UpdateSubresource(ctx context.Context, identifier Identifier,
 	subresource SubresourceName, obj Object) (T, error) {
-	ret, err := t.client.Update(ctx, identifier, obj, UpdateOptions{
+	ret, err := t.writeClient().Update(ctx, identifier, obj, UpdateOptions{
 		Subresource: string(subresource),
 	})
 	if err != nil {
@@ -172,31 +215,48 @@ func (t *TypedStore[T]) ForceDelete(ctx context.Context, identifier Identifier)
 // List lists all resources using the Namespace and Filters provided in options. An empty namespace in options is
 // equivalent to NamespaceAll, and an empty or nil Filters slice will be ignored.
 // List will automatically paginate through results, fetching pages based on options.PerPage.
-// To list a single page of results, use ListPage.
+// To list a single page of results, use ListPage, and to handle each page as it is fetched, use ListPages.
 func (t *TypedStore[T]) List(ctx context.Context, options StoreListOptions) (*TypedList[T], error) {
-	resp, err := t.ListPage(ctx, options.Namespace, ListOptions{
-		Limit:          options.PerPage,
-		LabelFilters:   options.Filters,
-		FieldSelectors: options.FieldSelectors,
+	var resp *TypedList[T]
+	err := t.ListPages(ctx, options, func(page *TypedList[T]) error {
+		if resp == nil {
+			resp = page
+			return nil
+		}
+		resp.Continue = page.Continue
+		resp.ResourceVersion = page.ResourceVersion
+		resp.Items = append(resp.Items, page.Items...)
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	for resp.Continue != "" {
-		page, err := t.ListPage(ctx, options.Namespace, ListOptions{
-			Continue:       resp.Continue,
-			Limit:          options.PerPage,
-			LabelFilters:   options.Filters,
-			FieldSelectors: options.FieldSelectors,
-		})
+	return resp, nil
+}
+
+// ListPages lists all resources using the Namespace and Filters provided in options, calling fn with each page of results
+// as it is fetched, based on options.PerPage. Unlike List, only one page of results is held in memory at a time.
+// If fn returns an error, no further pages are fetched, and the error is returned.
+func (t *TypedStore[T]) ListPages(ctx context.Context, options StoreListOptions, fn func(page *TypedList[T]) error) error {
+	listOptions := ListOptions{
+		Limit:          options.PerPage,
+		LabelFilters:   options.Filters,
+		FieldSelectors: options.FieldSelectors,
+	}
+	for {
+		page, err := t.ListPage(ctx, options.Namespace, listOptions)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		// Keep the continue token before calling fn, as fn may modify the page
+		listOptions.Continue = page.Continue
+		if err = fn(page); err != nil {
+			return err
+		}
+		if listOptions.Continue == "" {
+			return nil
 		}
-		resp.Continue = page.Continue
-		resp.ResourceVersion = page.ResourceVersion
-		resp.Items = append(resp.Items, page.Items...)
 	}
-	return resp, nil
 }
 
 // ListPage lists a single page of resources, with no auto-paging logic like List.
@@ -210,11 +270,26 @@ func (t *TypedStore[T]) ListPage(ctx context.Context, namespace string, options
 	return resp, nil
 }
 
+// Watch makes a watch request for resources in the provided namespace, and returns a TypedWatchResponse,
+// which provides events with objects of type T. Use NamespaceAll to watch resources in all namespaces.
+func (t *TypedStore[T]) Watch(ctx context.Context, namespace string, options WatchOptions) (*TypedWatchResponse[T], error) {
+	resp, err := t.client.Watch(ctx, namespace, options)
+	if err != nil {
+		return nil, err
+	}
+	return NewTypedWatchResponse[T](resp, options.EventBufferSize), nil
+}
+
 // Client returns the underlying Client for this store.
 func (t *TypedStore[T]) Client() Client {
 	return t.client
 }
 
+// writeClient returns the underlying Client, wrapped to record lineage from the context
+func (t *TypedStore[T]) writeClient() Client {
+	return NewLineageClient(t.client)
+}
+
 //nolint:revive
 func (t *TypedStore[T]) cast(obj Object) (T, error) {
 	cast, ok := obj.(T)
@@ -224,3 +299,74 @@ func (t *TypedStore[T]) cast(obj Object) (T, error) {
 	}
 	return cast, nil
 }
+
+// TypedWatchEvent is an event returned from a TypedWatchResponse
+type TypedWatchEvent[T Object] struct {
+	// EventType is the type of the event
+	EventType string
+	// Object is the affected object. If the underlying WatchEvent's Object is not of type T
+	// (such as for an error event), Object is the zero value of T.
+	Object T
+}
+
+// TypedWatchResponse wraps a WatchResponse, converting each WatchEvent into a TypedWatchEvent with an object of type T.
+// It is returned by TypedStore.Watch, and can be created from any WatchResponse with NewTypedWatchResponse.
+type TypedWatchResponse[T Object] struct {
+	resp     WatchResponse
+	ch       chan TypedWatchEvent[T]
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewTypedWatchResponse returns a TypedWatchResponse which converts the events of resp, buffering up to bufferSize events
+func NewTypedWatchResponse[T Object](resp WatchResponse, bufferSize int) *TypedWatchResponse[T] {
+	if bufferSize < 0 {
+		bufferSize = 0
+	}
+	w := &TypedWatchResponse[T]{
+		resp:   resp,
+		ch:     make(chan TypedWatchEvent[T], bufferSize),
+		stopCh: make(chan struct{}),
+	}
+	go w.run(resp.WatchEvents())
+	return w
+}
+
+func (w *TypedWatchResponse[T]) run(events <-chan WatchEvent) {
+	defer close(w.ch)
+	for {
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+			typed := TypedWatchEvent[T]{
+				EventType: evt.EventType,
+			}
+			if cast, ok := evt.Object.(T); ok {
+				typed.Object = cast
+			}
+			select {
+			case w.ch <- typed:
+			case <-w.stopCh:
+				return
+			}
+		case <-w.stopCh:
+			return
+		}
+	}
+}
+
+// Stop stops the underlying watch request. The channel returned by WatchEvents is closed once the watch has stopped.
+func (w *TypedWatchResponse[T]) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		w.resp.Stop()
+	})
+}
+
+// WatchEvents returns a channel that receives events from the watch request.
+// All calls to this method return the same channel.
+func (w *TypedWatchResponse[T]) WatchEvents() <-chan TypedWatchEvent[T] {
+	return w.ch
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -495,6 +496,110 @@ func TestTypedStore_List(t *testing.T) {
 	})
 }
 
+func TestTypedStore_ListPages(t *testing.T) {
+	store, client := getTypedStoreTestSetup()
+	ctx := context.TODO()
+	client.ListIntoFunc = func(c context.Context, namespace string, options ListOptions, into ListObject) error {
+		assert.Equal(t, 1, options.Limit)
+		switch options.Continue {
+		case "":
+			into.SetItems([]Object{&TypedSpecStatusObject[string, string]{Spec: "a"}})
+			into.SetContinue("b")
+		case "b":
+			into.SetItems([]Object{&TypedSpecStatusObject[string, string]{Spec: "b"}})
+			into.SetContinue("c")
+		default:
+			into.SetItems([]Object{&TypedSpecStatusObject[string, string]{Spec: "c"}})
+		}
+		return nil
+	}
+
+	t.Run("all pages", func(t *testing.T) {
+		specs := make([]string, 0)
+		err := store.ListPages(ctx, StoreListOptions{PerPage: 1}, func(page *TypedList[*TypedSpecStatusObject[string, string]]) error {
+			for _, item := range page.Items {
+				specs = append(specs, item.Spec)
+			}
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, specs)
+	})
+
+	t.Run("fn error", func(t *testing.T) {
+		ferr := fmt.Errorf("stop")
+		calls := 0
+		err := store.ListPages(ctx, StoreListOptions{PerPage: 1}, func(page *TypedList[*TypedSpecStatusObject[string, string]]) error {
+			calls++
+			return ferr
+		})
+		assert.Equal(t, ferr, err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestTypedStore_Watch(t *testing.T) {
+	store, client := getTypedStoreTestSetup()
+	ctx := context.TODO()
+
+	t.Run("error", func(t *testing.T) {
+		cerr := fmt.Errorf("I AM ERROR")
+		client.WatchFunc = func(ctx context.Context, namespace string, options WatchOptions) (WatchResponse, error) {
+			return nil, cerr
+		}
+		resp, err := store.Watch(ctx, "ns", WatchOptions{})
+		assert.Nil(t, resp)
+		assert.Equal(t, cerr, err)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		watch := &mockWatchResponse{
+			ch: make(chan WatchEvent, 2),
+		}
+		client.WatchFunc = func(c context.Context, namespace string, options WatchOptions) (WatchResponse, error) {
+			assert.Equal(t, ctx, c)
+			assert.Equal(t, "ns", namespace)
+			return watch, nil
+		}
+		resp, err := store.Watch(ctx, "ns", WatchOptions{})
+		require.Nil(t, err)
+		watch.ch <- WatchEvent{
+			EventType: "ADDED",
+			Object:    &TypedSpecStatusObject[string, string]{Spec: "a"},
+		}
+		// Objects of another type are not passed through
+		watch.ch <- WatchEvent{
+			EventType: "ERROR",
+			Object:    &UntypedObject{},
+		}
+		evt := <-resp.WatchEvents()
+		assert.Equal(t, "ADDED", evt.EventType)
+		assert.Equal(t, "a", evt.Object.Spec)
+		evt = <-resp.WatchEvents()
+		assert.Equal(t, "ERROR", evt.EventType)
+		assert.Nil(t, evt.Object)
+
+		resp.Stop()
+		resp.Stop()
+		_, ok := <-resp.WatchEvents()
+		assert.False(t, ok)
+		assert.Equal(t, 1, watch.stopCalls)
+	})
+}
+
+type mockWatchResponse struct {
+	ch        chan WatchEvent
+	stopCalls int
+}
+
+func (m *mockWatchResponse) Stop() {
+	m.stopCalls++
+}
+
+func (m *mockWatchResponse) WatchEvents() <-chan WatchEvent {
+	return m.ch
+}
+
 func getTypedStoreTestSetup() (*TypedStore[*TypedSpecStatusObject[string, string]], *mockClient) {
 	client := &mockClient{}
 	generator := &mockClientGenerator{
@@ -19,6 +19,7 @@ go run ./cmd/grafana-app-sdk/*.go generate -s="${rootdir}/codegen/cuekind/testin
   --defpath="${testdir}/crd" \
   -t="${testdir}/typescript/versioned" \
   --grouping=group \
+  --clientset \
   --manifest="testManifest"
 go run ./cmd/grafana-app-sdk/*.go generate -s="${rootdir}/codegen/cuekind/testing" \
   -g="${testdir}/go/groupbygroup" \
@@ -33,21 +34,50 @@ go run ./cmd/grafana-app-sdk/*.go generate -s="${rootdir}/codegen/cuekind/testin
   --defencoding=yaml \
   -t="${testdir}/typescript/versioned" \
   --grouping=group \
+  --clientset \
   --manifest="testManifest"
 # Move the manifest files
 mv ${testdir}/go/groupbygroup/*.go "${testdir}/manifest/go/"
 mv ${testdir}/crd/test-app-manifest.* "${testdir}/manifest/"
 mv ${testdir}/crd/custom-app-manifest.* "${testdir}/manifest/"
-# Group by kind (only customKind)
+# Group by kind (only customKind), with python and protobuf
+mkdir -p "${testdir}/python/customkind/v0_0" "${testdir}/python/customkind/v1_0"
+mkdir -p "${testdir}/protobuf/customkind/v0_0" "${testdir}/protobuf/customkind/v1_0"
 go run ./cmd/grafana-app-sdk/*.go generate -s="${rootdir}/codegen/cuekind/testing" \
   -g="${testdir}/go/groupbykind" \
   --defencoding="none" \
   -t="${testdir}/typescript/versioned" \
+  --pygenpath="${testdir}/python" \
+  --protogenpath="${testdir}/protobuf" \
+  --tsvalidators=zod \
   --grouping=kind \
+  --clientset \
   --manifest="customManifest"
+# Dashboard
+mkdir -p "${testdir}/dashboard"
+go run ./cmd/grafana-app-sdk/*.go generate dashboards -s="${rootdir}/codegen/cuekind/testing" \
+  --dashboardpath="${testdir}/dashboard" \
+  --manifest="testManifest"
+# Alerts
+mkdir -p "${testdir}/alerts"
+go run ./cmd/grafana-app-sdk/*.go generate alerts -s="${rootdir}/codegen/cuekind/testing" \
+  --alertspath="${testdir}/alerts" \
+  --manifest="testManifest"
+# RBAC
+mkdir -p "${testdir}/rbac"
+go run ./cmd/grafana-app-sdk/*.go generate rbac -s="${rootdir}/codegen/cuekind/testing" \
+  --rbacpath="${testdir}/rbac" \
+  --manifest="testManifest"
+# Webhook configurations
+mkdir -p "${testdir}/webhooks"
+go run ./cmd/grafana-app-sdk/*.go generate webhooks -s="${rootdir}/codegen/cuekind/testing" \
+  --webhookspath="${testdir}/webhooks" \
+  --manifest="testManifest"
 
 # Rename files to append .txt
 find "${testdir}" -depth -name "*.go" -exec sh -c 'mv "$1" "${1}.txt"' _ {} \;
 find "${testdir}" -depth -name "*.ts" -exec sh -c 'mv "$1" "${1}.txt"' _ {} \;
 find "${testdir}" -depth -name "*.json" -exec sh -c 'mv "$1" "${1}.txt"' _ {} \;
-find "${testdir}" -depth -name "*.yaml" -exec sh -c 'mv "$1" "${1}.txt"' _ {} \;
\ No newline at end of file
+find "${testdir}" -depth -name "*.yaml" -exec sh -c 'mv "$1" "${1}.txt"' _ {} \;
+find "${testdir}" -depth -name "*.py" -exec sh -c 'mv "$1" "${1}.txt"' _ {} \;
+find "${testdir}" -depth -name "*.proto" -exec sh -c 'mv "$1" "${1}.txt"' _ {} \;
\ No newline at end of file
@@ -5,14 +5,19 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
 
 	"github.com/grafana/grafana-app-sdk/app"
 	"github.com/grafana/grafana-app-sdk/k8s"
+	"github.com/grafana/grafana-app-sdk/metrics"
 	"github.com/grafana/grafana-app-sdk/operator"
 	"github.com/grafana/grafana-app-sdk/resource"
 )
@@ -61,6 +66,11 @@ type KindMutator interface {
 	Mutate(context.Context, *app.AdmissionRequest) (*app.MutatingResponse, error)
 }
 
+// KindDefaulter is an interface which describes an object which can set default values on a kind, used in AppManagedKind
+type KindDefaulter interface {
+	Default(context.Context, *app.AdmissionRequest) (*app.MutatingResponse, error)
+}
+
 // KindValidator is an interface which describes an object which can validate a kind, used in AppManagedKind
 type KindValidator interface {
 	Validate(context.Context, *app.AdmissionRequest) error
@@ -79,6 +89,27 @@ func (m *Mutator) Mutate(ctx context.Context, req *app.AdmissionRequest) (*app.M
 	return nil, nil
 }
 
+// Defaulter is a simple implementation of KindDefaulter, which calls DefaultFunc with a copy of the request's object
+// when Default is called, and responds with the copy as the updated object
+type Defaulter struct {
+	DefaultFunc func(context.Context, resource.Object) error
+}
+
+// Default calls DefaultFunc with a copy of the request's object, and returns the defaulted copy if DefaultFunc
+// is non-nil (otherwise it returns nil, nil)
+func (d *Defaulter) Default(ctx context.Context, req *app.AdmissionRequest) (*app.MutatingResponse, error) {
+	if d.DefaultFunc == nil || req.Object == nil {
+		return nil, nil
+	}
+	obj := req.Object.Copy()
+	if err := d.DefaultFunc(ctx, obj); err != nil {
+		return nil, err
+	}
+	return &app.MutatingResponse{
+		UpdatedObject: obj,
+	}, nil
+}
+
 // Validator is a simple implementation of KindValidator, which calls ValidateFunc when Validate is called
 type Validator struct {
 	ValidateFunc func(context.Context, *app.AdmissionRequest) error
@@ -102,9 +133,39 @@ type App struct {
 	internalKinds      map[string]resource.Kind
 	cfg                AppConfig
 	converters         map[string]Converter
+	versionConverters  map[string]*app.VersionConverter
 	customRoutes       map[string]AppCustomRouteHandler
+	asyncOperations    *asyncOperations
 	patcher            *k8s.DynamicPatcher
 	collectors         []prometheus.Collector
+	// kindRunner runs the runnables for managed kinds, so they can be started and stopped with AddKind and RemoveKind
+	kindRunner    *app.DynamicMultiRunner
+	registrations map[string]*kindRegistration
+	// unmanagedRegistrations are the kindRegistrations of UnmanagedKinds, which are only used by AddNamespace and RemoveNamespace
+	unmanagedRegistrations map[string]*kindRegistration
+	// appRoutes are the handlers for the app-level routes (app.KindsRoutePath and app.CustomRoutesOpenAPIRoutePath),
+	// keyed by path, which are only served if AppConfig.ManifestData is set
+	appRoutes map[string]AppCustomRouteHandler
+	// kindsMux guards kinds, registrations, customRoutes, converters, and versionConverters,
+	// which can change while the App is running with AddKind and RemoveKind
+	kindsMux sync.RWMutex
+}
+
+// kindRegistration is everything added to the App for a managed kind, so that it can be removed by RemoveKind
+type kindRegistration struct {
+	informers []registeredInformer
+	// namespaceInformers are the informers which watch the kind's ReconcileOptions.Namespaces,
+	// if it uses ReconcileOptions.DynamicNamespaces
+	namespaceInformers []*operator.KubernetesBasedInformer
+	reconcilers        []operator.Reconciler
+	watchers           []operator.ResourceWatcher
+	routes             []string
+	runnables          []app.Runnable
+}
+
+type registeredInformer struct {
+	informer     operator.Informer
+	resourceKind string
 }
 
 // AppConfig is the configuration used by App
@@ -115,19 +176,70 @@ type AppConfig struct {
 	ManagedKinds   []AppManagedKind
 	UnmanagedKinds []AppUnmanagedKind
 	Converters     map[schema.GroupKind]Converter
+	// VersionConverters are used for conversion requests for their kinds, chaining the conversions registered with each
+	// app.VersionConverter as needed. They take precedence over Converters for the same kind.
+	VersionConverters []*app.VersionConverter
 	// DiscoveryRefreshInterval is the interval at which the API discovery cache should be refreshed.
 	// This is primarily used by the DynamicPatcher in the OpinionatedWatcher/OpinionatedReconciler
 	// for sending finalizer add/remove patches to the latest version of the kind.
 	// This defaults to 10 minutes.
 	DiscoveryRefreshInterval time.Duration
+	// AsyncOperations configures how operations for AsyncCustomRoutes of ManagedKinds are run and stored.
+	AsyncOperations AppAsyncOperationsConfig
+	// InformerFactory, if non-nil, is used to create the informers for the App's kinds, so that they share their watches
+	// with any other informers from the factory for the same kind, namespace, and selectors, such as those of the runner
+	// or other apps it runs. Use operator.SharedInformerFactoryFromConfig to get the factory provided by operator.Runner.
+	// Informers for kinds with DynamicNamespaces are not shared.
+	InformerFactory *operator.SharedInformerFactory
+	// DeadlineReserve is the time reserved for writing the response to admission and custom route requests which have a deadline.
+	// Validators, mutators, defaulters, and custom route handlers are called with a context whose deadline is DeadlineReserve
+	// earlier than the request's, so that the calls they make to downstream clients end in time for the App to respond.
+	// If zero, handlers are called with the request's deadline.
+	DeadlineReserve time.Duration
+	// ManifestData is the manifest of the app, such as the app.Config.ManifestData provided by the runner.
+	// If set, the App serves the app.KindsInfo of the manifest at the app-level app.KindsRoutePath custom route,
+	// and the OpenAPI document of the custom routes of its ManagedKinds at the app.CustomRoutesOpenAPIRoutePath route,
+	// which operator.Runner exposes at its /kinds and /openapi endpoints.
+	ManifestData *app.ManifestData
 }
 
 // AppInformerConfig contains configuration for the App's internal operator.InformerController
 type AppInformerConfig struct {
+	// ErrorReporter is sent a report of each error encountered by the App's informers, watchers, and reconcilers.
+	// If nil, and ErrorHandler is also nil, the App creates an app.DefaultErrorReporter, which logs and counts errors,
+	// and exposes it with its other collectors.
+	ErrorReporter app.ErrorReporter
+	// ErrorHandler is called with each error encountered by the App's informers, watchers, and reconcilers,
+	// if ErrorReporter is nil.
+	//
+	// Deprecated: use ErrorReporter.
 	ErrorHandler       func(context.Context, error)
 	RetryPolicy        operator.RetryPolicy
 	RetryDequeuePolicy operator.RetryDequeuePolicy
 	FinalizerSupplier  operator.FinalizerSupplier
+	// DeadLetterHandler is called with each event the App's watchers and reconcilers have stopped retrying.
+	// operator.NewKubernetesEventDeadLetterHandler provides a DeadLetterHandler which emits Kubernetes Events.
+	DeadLetterHandler operator.DeadLetterHandler
+	// EventRecorder is added to the context of every watcher and reconciler call, so they can record Kubernetes Events
+	// with operator.EventRecorderFromContext. If nil, the EventRecorder in the context the App is run with is used,
+	// which operator.Runner provides.
+	EventRecorder operator.EventRecorder
+	// FailureNotifier, if non-nil, is notified of events the App's watchers and reconcilers have stopped retrying,
+	// and of objects whose reconciles have failed consecutively past FailureThreshold.
+	// operator.NewWebhookFailureNotifier provides a FailureNotifier which posts notifications to a webhook.
+	FailureNotifier operator.FailureNotifier
+	// FailureThreshold is the threshold of consecutive failed reconciles at which FailureNotifier is notified of an object.
+	// If empty, FailureNotifier is only notified of events which are no longer retried.
+	FailureThreshold operator.FailureThreshold
+	// RestartOptions configure how informers restart their list/watch after terminal errors from the API server,
+	// such as 401 or 403 responses after credential rotation. If RestartOptions.Metrics is nil,
+	// the App creates an operator.InformerRestartMetrics and exposes it with its other collectors.
+	RestartOptions operator.InformerRestartOptions
+	// CacheResyncInterval is the interval at which the informers for reconciled and watched kinds emit update events
+	// for every object in their cache. If zero, the cache is not periodically resynced.
+	// It can be overridden for each kind with BasicReconcileOptions.CacheResyncInterval and DisableCacheResync.
+	// Reconcilers can skip resyncs of objects which have not drifted with BasicReconcileOptions.DetectDrift.
+	CacheResyncInterval time.Duration
 }
 
 // AppManagedKind is a Kind and associated functionality used by an App.
@@ -146,11 +258,50 @@ type AppManagedKind struct {
 	// Mutator is an optional MutatingAdmissionController for the Kind. It will be run only for mutation
 	// of this specific version.
 	Mutator KindMutator
+	// Defaulter is an optional defaulter for the Kind, which sets default values on objects of this specific version.
+	// It is run as part of mutating admission, before Mutator.
+	Defaulter KindDefaulter
 	// CustomRoutes are an optional map of subresource paths to a route handler.
 	// If supported by the runner, calls to these subresources on this particular version will call this handler.
 	CustomRoutes AppCustomRouteHandlers
+	// AsyncCustomRoutes are an optional map of subresource paths to handlers for long-running work.
+	// A call to one of these routes creates an AsyncOperation, runs the handler in the background, and responds
+	// with 202 Accepted and a Location header of AsyncOperationsPath/{id}. A GET custom route of that path is added,
+	// which returns the operation's state, progress, and result for polling.
+	AsyncCustomRoutes AsyncCustomRouteHandlers
+	// FormDescriptor is an optional descriptor of a create/edit form for the Kind, such as the one returned by the
+	// generated FormDescriptor() function of the kind. If non-nil, a GET custom route with the path FormDescriptorPath
+	// is added, which returns the descriptor as JSON.
+	FormDescriptor *app.FormDescriptor
 	// ReconcileOptions are the options to use for running the Reconciler or Watcher for the Kind, if one exists.
 	ReconcileOptions BasicReconcileOptions
+	// SoftDelete are the options for soft-deleting objects of this Kind. If enabled, a Reconciler is always run
+	// for the Kind (to hold deletion with a finalizer), and a POST custom route with the path SoftDeleteRestorePath is added.
+	// Soft delete cannot be used with a Watcher or ReconcileOptions.UsePlain. Only one version of a Kind should enable it.
+	SoftDelete SoftDeleteOptions
+	// DeletionProtection are the options for protecting objects of this Kind from deletion. If enabled, deletes of
+	// protected objects are rejected by the App's validation (before Validator is called), unless overridden.
+	// Validator is optional: without one, requests which are not rejected by deletion protection are allowed.
+	// The Kind's validation capability in the manifest must include the DELETE operation.
+	DeletionProtection DeletionProtectionOptions
+	// OwnsKinds are kinds whose objects are owned by objects of this Kind. Each kind is watched (in the namespaces of
+	// ReconcileOptions), and when an object with a controller owner reference to an object of this Kind is added,
+	// updated, or deleted, the owner is reconciled by Reconciler with the action operator.ReconcileActionResynced.
+	// Owners are read from the informer cache, and owners which are being deleted are not reconciled.
+	// OwnsKinds requires a Reconciler. Owner references can be set with operator.SetOwnerReference.
+	OwnsKinds []resource.Kind
+	// Watches are kinds whose objects affect objects of this Kind without owning them, such as a ConfigMap referenced
+	// by the spec. Each kind is watched like OwnsKinds, but each added, updated, or deleted object is mapped to
+	// the objects of this Kind to reconcile with the AppWatchedKind's Mapper. Watches requires a Reconciler.
+	Watches []AppWatchedKind
+}
+
+// AppWatchedKind is a kind whose changes should cause objects of an AppManagedKind to be reconciled
+type AppWatchedKind struct {
+	// Kind is the kind to watch
+	Kind resource.Kind
+	// Mapper maps each changed object of Kind to the identifiers of the objects of the AppManagedKind to reconcile
+	Mapper operator.ObjectMapper
 }
 
 // AppUnmanagedKind is a Kind which an App does not manage, but still may want to watch or reconcile as part of app functionality
@@ -163,18 +314,96 @@ type AppUnmanagedKind struct {
 	Watcher operator.ResourceWatcher
 	// ReconcileOptions are the options to use for running the Reconciler or Watcher for the Kind, if one exists.
 	ReconcileOptions BasicReconcileOptions
+	// PauseWhenNotServed, if true, pauses the informer(s) for the Kind while it is not served by the API server
+	// (for example, when the CRD of a kind owned by another app is removed), rather than having the informer continually fail.
+	// See operator.ForeignKindInformer.
+	PauseWhenNotServed bool
+}
+
+// DeletionProtectionOptions are the options for deletion protection of an AppManagedKind.
+// When enabled, deleting an object annotated with k8s.AnnotationDeletionProtected set to "true" (or any object,
+// if ProtectAll is true) is rejected, unless the object also has k8s.AnnotationDeletionProtectionOverride set to "true",
+// or the request is made by one of AllowedUsers or a member of AllowedGroups. See k8s.CheckDeletionProtection.
+type DeletionProtectionOptions struct {
+	Enabled bool
+	// ProtectAll protects every object of the kind which does not have k8s.AnnotationDeletionProtected set to "false"
+	ProtectAll bool
+	// AllowedUsers are the usernames which can delete protected objects without an override annotation
+	AllowedUsers []string
+	// AllowedGroups are the groups whose members can delete protected objects without an override annotation
+	AllowedGroups []string
+}
+
+func (o DeletionProtectionOptions) config() k8s.DeletionProtectionConfig {
+	return k8s.DeletionProtectionConfig{
+		ProtectAll:    o.ProtectAll,
+		AllowedUsers:  o.AllowedUsers,
+		AllowedGroups: o.AllowedGroups,
+	}
 }
 
 // BasicReconcileOptions are settings for the ListWatch and informer setup for a reconciliation loop
 type BasicReconcileOptions struct {
 	// Namespace is the namespace to use in the ListWatch request
 	Namespace string
-	// LabelFilters are any label filters to apply to the ListWatch request
+	// Namespaces is an optional list of namespaces to watch, used instead of Namespace.
+	// A separate informer is created for each namespace, and events from all of them are sent to the same Watcher or Reconciler.
+	// The namespaces are fixed once the App is created, unless DynamicNamespaces is set.
+	// If both Namespace and Namespaces are empty, resources in all namespaces are watched.
+	// Cluster-scoped kinds cannot specify a namespace.
+	Namespaces []string
+	// DynamicNamespaces, if true, watches the Namespaces (which may be empty) with a single informer, which lists and
+	// watches each namespace separately, so that namespaces can be added and removed while the App is running with
+	// App.AddNamespace and App.RemoveNamespace, instead of creating an informer for each namespace.
+	// Either way, the Watcher or Reconciler sees the same events for the Namespaces it starts with.
+	// Namespace must be empty, and the kind must be namespaced.
+	// The informers for OwnsKinds and Watches which are namespaced watch the same namespaces.
+	DynamicNamespaces bool
+	// LabelFilters are label selectors (such as "app.kubernetes.io/managed-by=my-app") applied to the list and watch
+	// requests of the kind's informers, so only matching objects are cached and passed to the Reconciler or Watcher.
+	// An object which is updated to no longer match is seen as deleted. They do not apply to OwnsKinds or Watches.
 	LabelFilters []string
-	// FieldSelectors are any field selector filters to apply to the ListWatch request
+	// FieldSelectors are field selectors (such as "metadata.name=foo") applied to the list and watch requests
+	// of the kind's informers, like LabelFilters. Fields other than metadata.name and metadata.namespace
+	// must be selectable fields of the kind.
 	FieldSelectors []string
 	// UsePlain can be set to true to avoid wrapping the Reconciler or Watcher in its Opinionated variant.
 	UsePlain bool
+	// RelatedObjectResolvers are optional resolvers used to attach related objects to each ReconcileRequest
+	// (in ReconcileRequest.Related, keyed by the map key) before the Reconciler is called. It is ignored for Watchers.
+	RelatedObjectResolvers map[string]operator.RelatedObjectResolver
+	// MaxConcurrentReconciles is the number of objects which can be reconciled by the Reconciler at once.
+	// Events for the same object are still reconciled one at a time. If zero, events are reconciled sequentially
+	// as they are received. It is ignored for Watchers. See operator.ReconcilerOptions.
+	MaxConcurrentReconciles int
+	// FairQueueing, if non-nil, shares the MaxConcurrentReconciles workers between namespaces (and priorities),
+	// so that a namespace with many events cannot starve the others. It is ignored if MaxConcurrentReconciles is zero.
+	// See operator.FairQueueing.
+	FairQueueing *operator.FairQueueing
+	// Predicates filter the events passed to the Reconciler or Watcher, which is only called for events all Predicates accept.
+	// Unless UsePlain is true, events are filtered before they reach the opinionated logic, so filtering out add or
+	// delete events can prevent the finalizer from being added or removed.
+	Predicates []operator.Predicate
+	// TrackLineage, if true, records the reconciled object as the source of objects the Reconciler creates or updates
+	// with a resource.Store or resource.TypedStore using the context it is called with (see operator.LineageReconciler).
+	// The controller identity is "<app name>/<plural>-reconciler". It is ignored for Watchers.
+	TrackLineage bool
+	// DetectDrift, if true, skips reconciling objects which have not drifted since they were last reconciled when the
+	// informer cache is periodically resynced (see AppInformerConfig.CacheResyncInterval and operator.DriftDetectingReconciler).
+	// A hash of the spec and subresources of each successfully reconciled object is stored in the
+	// operator.DesiredStateHashAnnotation annotation, which requires patch access to the kind. It is ignored for Watchers.
+	DetectDrift bool
+	// LogContext, if true, adds a logger to the context passed to the Reconciler or Watcher for each event, with the
+	// event's request ID, the attempt number, the action, and the kind, namespace, and name of the object, so that
+	// logging.FromContext returns a logger with those attributes (see operator.LoggingReconciler and operator.LoggingWatcher).
+	LogContext bool
+	// CacheResyncInterval, if non-zero, is the interval at which the kind's informers resync their cache,
+	// instead of AppInformerConfig.CacheResyncInterval. Giving kinds different intervals keeps their resyncs
+	// from all happening at once.
+	CacheResyncInterval time.Duration
+	// DisableCacheResync, if true, turns off periodic cache resyncs for the kind, regardless of
+	// AppInformerConfig.CacheResyncInterval. CacheResyncInterval must be zero.
+	DisableCacheResync bool
 }
 
 type AppCustomRouteMethod string
@@ -205,16 +434,40 @@ type AppCustomRouteHandlers map[AppCustomRoute]AppCustomRouteHandler
 // Watcher/Reconciler error handling, retry, and dequeue logic can be managed with AppConfig.InformerConfig.
 func NewApp(config AppConfig) (*App, error) {
 	a := &App{
-		informerController: operator.NewInformerController(operator.DefaultInformerControllerConfig()),
-		runner:             app.NewMultiRunner(),
-		clientGenerator:    k8s.NewClientRegistry(config.KubeConfig, k8s.DefaultClientConfig()),
-		kinds:              make(map[string]AppManagedKind),
-		internalKinds:      make(map[string]resource.Kind),
-		converters:         make(map[string]Converter),
-		customRoutes:       make(map[string]AppCustomRouteHandler),
-		cfg:                config,
-		collectors:         make([]prometheus.Collector, 0),
+		runner:            app.NewMultiRunner(),
+		clientGenerator:   k8s.NewClientRegistry(config.KubeConfig, k8s.DefaultClientConfig()),
+		kinds:             make(map[string]AppManagedKind),
+		internalKinds:     make(map[string]resource.Kind),
+		converters:        make(map[string]Converter),
+		versionConverters: make(map[string]*app.VersionConverter),
+		customRoutes:      make(map[string]AppCustomRouteHandler),
+		cfg:               config,
+		collectors:        make([]prometheus.Collector, 0),
+		kindRunner:        app.NewDynamicMultiRunner(),
+		registrations:     make(map[string]*kindRegistration),
+
+		unmanagedRegistrations: make(map[string]*kindRegistration),
+	}
+	if a.cfg.InformerConfig.RestartOptions.Metrics == nil {
+		a.cfg.InformerConfig.RestartOptions.Metrics = operator.NewInformerRestartMetrics(metrics.DefaultConfig(""))
+		a.collectors = append(a.collectors, a.cfg.InformerConfig.RestartOptions.Metrics.PrometheusCollectors()...)
 	}
+	if a.cfg.InformerConfig.ErrorReporter == nil {
+		if a.cfg.InformerConfig.ErrorHandler != nil {
+			a.cfg.InformerConfig.ErrorReporter = app.ErrorHandlerReporter(a.cfg.InformerConfig.ErrorHandler)
+		} else {
+			reporter := app.NewDefaultErrorReporter(metrics.DefaultConfig(""))
+			a.cfg.InformerConfig.ErrorReporter = reporter
+			a.collectors = append(a.collectors, reporter.PrometheusCollectors()...)
+		}
+	}
+	controllerConfig := operator.DefaultInformerControllerConfig()
+	controllerConfig.ErrorReporter = a.cfg.InformerConfig.ErrorReporter
+	controllerConfig.DeadLetterHandler = a.cfg.InformerConfig.DeadLetterHandler
+	controllerConfig.EventRecorder = a.cfg.InformerConfig.EventRecorder
+	controllerConfig.FailureNotifier = a.cfg.InformerConfig.FailureNotifier
+	controllerConfig.FailureThreshold = a.cfg.InformerConfig.FailureThreshold
+	a.informerController = operator.NewInformerController(controllerConfig)
 	discoveryRefresh := config.DiscoveryRefreshInterval
 	if discoveryRefresh == 0 {
 		discoveryRefresh = time.Minute * 10
@@ -224,6 +477,11 @@ func NewApp(config AppConfig) (*App, error) {
 		return nil, err
 	}
 	a.patcher = p
+	if config.ManifestData != nil {
+		if err = a.addAppRoutes(*config.ManifestData); err != nil {
+			return nil, err
+		}
+	}
 	for _, kind := range config.ManagedKinds {
 		err := a.manageKind(kind)
 		if err != nil {
@@ -231,18 +489,46 @@ func NewApp(config AppConfig) (*App, error) {
 		}
 	}
 	for _, kind := range config.UnmanagedKinds {
-		err := a.watchKind(kind)
+		reg := &kindRegistration{}
+		err := a.watchKind(kind, reg)
 		if err != nil {
 			return nil, err
 		}
+		a.unmanagedRegistrations[gvk(kind.Kind.Group(), kind.Kind.Version(), kind.Kind.Kind())] = reg
 	}
 	for gk, converter := range config.Converters {
 		a.RegisterKindConverter(gk, converter)
 	}
+	for _, converter := range config.VersionConverters {
+		a.RegisterVersionConverter(converter)
+	}
 	a.runner.AddRunnable(a.informerController)
+	a.runner.AddRunnable(a.kindRunner)
 	return a, nil
 }
 
+// addAppRoutes adds the handlers for the app-level routes of manifest. The OpenAPI document of the custom routes
+// of the manifest only contains the routes of kinds in the AppConfig's ManagedKinds.
+func (a *App) addAppRoutes(manifest app.ManifestData) error {
+	kindsRoute, err := app.NewKindsRouteHandler(manifest)
+	if err != nil {
+		return err
+	}
+	plurals := make(map[string]string)
+	for _, kind := range a.cfg.ManagedKinds {
+		plurals[kind.Kind.Kind()] = kind.Kind.Plural()
+	}
+	openAPIRoute, err := app.NewCustomRoutesOpenAPIRouteHandler(manifest, plurals)
+	if err != nil {
+		return err
+	}
+	a.appRoutes = map[string]AppCustomRouteHandler{
+		app.KindsRoutePath:               kindsRoute,
+		app.CustomRoutesOpenAPIRoutePath: openAPIRoute,
+	}
+	return nil
+}
+
 // ValidateManifest can be called with app.ManifestData to validate that the current configuration and managed kinds
 // fully cover the kinds and capabilities in the provided app.ManifestData. If the provided app.ManifestData
 // contains a kind or a capability for a kind/version that is not covered by the app's currently managed kinds,
@@ -250,8 +536,13 @@ func NewApp(config AppConfig) (*App, error) {
 // indicates, no error will be returned.
 // This method can be used after initializing an app to verify it matches the loaded app.ManifestData from the app runner.
 func (a *App) ValidateManifest(manifest app.ManifestData) error {
+	a.kindsMux.RLock()
+	defer a.kindsMux.RUnlock()
 	for _, k := range manifest.Kinds {
-		if _, ok := a.converters[schema.GroupKind{Group: manifest.Group, Kind: k.Kind}.String()]; !ok && k.Conversion {
+		gk := schema.GroupKind{Group: manifest.Group, Kind: k.Kind}.String()
+		_, hasConverter := a.converters[gk]
+		_, hasVersionConverter := a.versionConverters[gk]
+		if !hasConverter && !hasVersionConverter && k.Conversion {
 			return fmt.Errorf("kind %s has conversion enabled but no converter is registered", k.Kind)
 		}
 		for _, v := range k.Versions {
@@ -259,12 +550,17 @@ func (a *App) ValidateManifest(manifest app.ManifestData) error {
 			if !ok {
 				return fmt.Errorf("kind %s/%s exists in manifest but is not managed by the app", k.Kind, v.Name)
 			}
-			if v.Admission != nil && v.Admission.SupportsAnyValidation() && kind.Validator == nil {
+			// Config kinds and kinds with size limits have validation supplied by the runner, so a validator is optional
+			if v.Admission != nil && v.Admission.SupportsAnyValidation() && kind.Validator == nil && !k.ConfigKind &&
+				!kind.DeletionProtection.Enabled && v.SizeLimits == nil {
 				return fmt.Errorf("kind %s/%s supports validation but has no validator", k.Kind, v.Name)
 			}
 			if v.Admission != nil && v.Admission.SupportsAnyMutation() && kind.Mutator == nil {
 				return fmt.Errorf("kind %s/%s supports mutation but has no mutator", k.Kind, v.Name)
 			}
+			if v.Admission != nil && v.Admission.SupportsAnyDefaulting() && kind.Defaulter == nil {
+				return fmt.Errorf("kind %s/%s supports defaulting but has no defaulter", k.Kind, v.Name)
+			}
 			// Check for the inverse
 			if kind.Validator != nil && (v.Admission == nil || !v.Admission.SupportsAnyValidation()) {
 				return fmt.Errorf("kind %s/%s does not support validation, but has a validator", k.Kind, v.Name)
@@ -272,6 +568,17 @@ func (a *App) ValidateManifest(manifest app.ManifestData) error {
 			if kind.Mutator != nil && (v.Admission == nil || !v.Admission.SupportsAnyMutation()) {
 				return fmt.Errorf("kind %s/%s does not support mutation, but has a mutator", k.Kind, v.Name)
 			}
+			if kind.Defaulter != nil && (v.Admission == nil || !v.Admission.SupportsAnyDefaulting()) {
+				return fmt.Errorf("kind %s/%s does not support defaulting, but has a defaulter", k.Kind, v.Name)
+			}
+			if kind.DeletionProtection.Enabled && (v.Admission == nil || !v.Admission.SupportsValidation(app.AdmissionOperationDelete)) {
+				return fmt.Errorf("kind %s/%s has deletion protection enabled, but does not support validation of DELETE", k.Kind, v.Name)
+			}
+			for _, route := range v.CustomRoutes {
+				if _, ok := a.customRoutes[a.customRouteHandlerKey(kind.Kind, route.Method, route.Path)]; !ok {
+					return fmt.Errorf("kind %s/%s declares the custom route %s %s, but has no handler for it", k.Kind, v.Name, route.Method, route.Path)
+				}
+			}
 		}
 	}
 	return nil
@@ -279,6 +586,8 @@ func (a *App) ValidateManifest(manifest app.ManifestData) error {
 
 // ManagedKinds returns a slice of all Kinds managed by this App
 func (a *App) ManagedKinds() []resource.Kind {
+	a.kindsMux.RLock()
+	defer a.kindsMux.RUnlock()
 	kinds := make([]resource.Kind, 0)
 	for _, k := range a.kinds {
 		kinds = append(kinds, k.Kind)
@@ -299,9 +608,141 @@ func (a *App) AddRunnable(runner app.Runnable) {
 	a.runner.AddRunnable(runner)
 }
 
-// manageKind introduces a new kind to manage.
+// AddKind adds a kind for the App to manage, in the same way as the kinds in AppConfig.ManagedKinds, but at runtime.
+// This allows kinds to be added without restarting the App, such as when the manifest of a plugin kind appears.
+// If the App is running, the informers for the kind (and for its OwnsKinds and Watches) are started immediately,
+// and admission and custom route requests for the kind are handled by the App once AddKind returns.
+// An operator.Runner only registers admission webhooks for the kinds the App manages when the Runner is run,
+// so kinds added afterwards are not validated, mutated, or defaulted by the Runner's webhook server.
+// Converters for the kind can be added with RegisterKindConverter or RegisterVersionConverter.
+// If the kind cannot be added, anything already added for it is removed again before the error is returned.
+// It returns an error if the App already manages the kind's group, version, and kind.
+func (a *App) AddKind(kind AppManagedKind) error {
+	a.kindsMux.Lock()
+	defer a.kindsMux.Unlock()
+	if _, ok := a.kinds[gvk(kind.Kind.Group(), kind.Kind.Version(), kind.Kind.Kind())]; ok {
+		return fmt.Errorf("kind %s/%s is already managed by the app", kind.Kind.Kind(), kind.Kind.Version())
+	}
+	return a.manageKind(kind)
+}
+
+// RemoveKind stops the App managing a kind added with AddKind or in AppConfig.ManagedKinds.
+// The kind's informers (including those for its OwnsKinds and Watches) are stopped, its watcher or reconciler
+// is removed along with any pending retries, and admission and custom route requests for the kind are no longer handled.
+// Watcher and reconciler calls which are already in progress are not canceled.
+// Finalizers which the App added to objects of the kind are not removed, so objects which are deleted after
+// the kind is removed stay in a terminating state until the kind is added again, or the finalizer is removed.
+// It returns an error if the App does not manage the kind.
+func (a *App) RemoveKind(kind resource.Kind) error {
+	a.kindsMux.Lock()
+	defer a.kindsMux.Unlock()
+	key := gvk(kind.Group(), kind.Version(), kind.Kind())
+	if _, ok := a.kinds[key]; !ok {
+		return fmt.Errorf("kind %s/%s is not managed by the app", kind.Kind(), kind.Version())
+	}
+	a.unregisterKind(key)
+	return nil
+}
+
+// AddNamespace starts watching namespace for a kind with ReconcileOptions.DynamicNamespaces, along with its namespaced
+// OwnsKinds and Watches. Its objects in namespace are passed to its watcher or reconciler as add events.
+// Adding a namespace which is already watched does nothing.
+// It returns an error if the kind is not managed by the App, or does not use DynamicNamespaces.
+func (a *App) AddNamespace(kind resource.Kind, namespace string) error {
+	reg, err := a.namespacesRegistration(kind)
+	if err != nil {
+		return err
+	}
+	for _, inf := range reg.namespaceInformers {
+		if err := inf.AddNamespace(namespace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveNamespace stops watching namespace for a kind with ReconcileOptions.DynamicNamespaces, along with its namespaced
+// OwnsKinds and Watches. Once the informers have listed their remaining namespaces again, the objects in namespace
+// are removed from their caches, and passed to the watcher or reconciler as delete events, as they are for objects
+// which no longer match LabelFilters. Finalizers the App added to them are not removed.
+// Removing a namespace which is not watched does nothing.
+// It returns an error if the kind is not managed by the App, or does not use DynamicNamespaces.
+func (a *App) RemoveNamespace(kind resource.Kind, namespace string) error {
+	reg, err := a.namespacesRegistration(kind)
+	if err != nil {
+		return err
+	}
+	for _, inf := range reg.namespaceInformers {
+		if err := inf.RemoveNamespace(namespace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// namespacesRegistration returns the kindRegistration of kind, which must use ReconcileOptions.DynamicNamespaces
+func (a *App) namespacesRegistration(kind resource.Kind) (*kindRegistration, error) {
+	a.kindsMux.RLock()
+	defer a.kindsMux.RUnlock()
+	key := gvk(kind.Group(), kind.Version(), kind.Kind())
+	reg, ok := a.registrations[key]
+	if !ok {
+		reg, ok = a.unmanagedRegistrations[key]
+	}
+	if !ok {
+		return nil, fmt.Errorf("kind %s/%s is not managed by the app", kind.Kind(), kind.Version())
+	}
+	if len(reg.namespaceInformers) == 0 {
+		return nil, fmt.Errorf("kind %s/%s does not use ReconcileOptions.DynamicNamespaces", kind.Kind(), kind.Version())
+	}
+	return reg, nil
+}
+
+// unregisterKind removes everything in the kindRegistration for the kind with key, and the kind itself.
+// The caller must hold kindsMux.
+func (a *App) unregisterKind(key string) {
+	if reg, ok := a.registrations[key]; ok {
+		// Watchers and reconcilers are added to the controller with the kind's GroupVersionKind, not key
+		kind := a.kinds[key].Kind
+		resourceKind := kind.GroupVersionKind().String()
+		// Stop the informers first, so no new events are received while the watchers and reconcilers are removed
+		for _, inf := range reg.informers {
+			a.informerController.RemoveInformer(inf.informer, inf.resourceKind)
+		}
+		for _, reconciler := range reg.reconcilers {
+			a.informerController.RemoveReconciler(reconciler, resourceKind)
+		}
+		for _, watcher := range reg.watchers {
+			a.informerController.RemoveWatcher(watcher, resourceKind)
+		}
+		for _, route := range reg.routes {
+			delete(a.customRoutes, route)
+		}
+		for _, runnable := range reg.runnables {
+			a.kindRunner.RemoveRunnable(runnable)
+		}
+	}
+	delete(a.registrations, key)
+	delete(a.kinds, key)
+}
+
+// manageKind introduces a new kind to manage. If the kind cannot be managed, anything already added for it is removed.
 func (a *App) manageKind(kind AppManagedKind) error {
-	a.kinds[gvk(kind.Kind.Group(), kind.Kind.Version(), kind.Kind.Kind())] = kind
+	key := gvk(kind.Kind.Group(), kind.Kind.Version(), kind.Kind.Kind())
+	reg := &kindRegistration{}
+	a.kinds[key] = kind
+	a.registrations[key] = reg
+	if err := a.registerKind(kind, reg); err != nil {
+		a.unregisterKind(key)
+		return err
+	}
+	return nil
+}
+
+// registerKind adds the routes, informers, watcher or reconciler, and runnables for kind, and records them in reg
+//
+//nolint:gocognit,funlen
+func (a *App) registerKind(kind AppManagedKind, reg *kindRegistration) error {
 	// If there are custom routes, validate them
 	for route, handler := range kind.CustomRoutes {
 		if route.Method == "" {
@@ -318,19 +759,190 @@ func (a *App) manageKind(kind AppManagedKind) error {
 			return fmt.Errorf("custom route '%s %s' already exists", route.Method, route.Path)
 		}
 		a.customRoutes[key] = handler
+		reg.routes = append(reg.routes, key)
+	}
+	if len(kind.AsyncCustomRoutes) > 0 {
+		if a.asyncOperations == nil {
+			a.asyncOperations = newAsyncOperations(a.cfg.AsyncOperations)
+			a.AddRunnable(a.asyncOperations)
+		}
+		for route, handler := range kind.AsyncCustomRoutes {
+			if route.Method == "" {
+				return fmt.Errorf("async custom route cannot have an empty method")
+			}
+			if route.Path == "" {
+				return fmt.Errorf("async custom route cannot have an empty path")
+			}
+			if handler == nil {
+				return fmt.Errorf("async custom route cannot have a nil handler")
+			}
+			key := a.customRouteHandlerKey(kind.Kind, string(route.Method), route.Path)
+			if _, ok := a.customRoutes[key]; ok {
+				return fmt.Errorf("async custom route '%s %s' already exists", route.Method, route.Path)
+			}
+			a.customRoutes[key] = a.asyncOperations.handler(handler)
+			reg.routes = append(reg.routes, key)
+		}
+		key := a.customRouteHandlerKey(kind.Kind, string(AppCustomRouteMethodGet), AsyncOperationsPath)
+		if _, ok := a.customRoutes[key]; ok {
+			return fmt.Errorf("custom route '%s %s' conflicts with async operations route", AppCustomRouteMethodGet, AsyncOperationsPath)
+		}
+		a.customRoutes[key] = a.asyncOperations.statusHandler
+		reg.routes = append(reg.routes, key)
+	}
+	if kind.FormDescriptor != nil {
+		key := a.customRouteHandlerKey(kind.Kind, string(AppCustomRouteMethodGet), FormDescriptorPath)
+		if _, ok := a.customRoutes[key]; ok {
+			return fmt.Errorf("custom route '%s %s' conflicts with form descriptor route", AppCustomRouteMethodGet, FormDescriptorPath)
+		}
+		handler, err := formDescriptorHandler(kind.FormDescriptor)
+		if err != nil {
+			return err
+		}
+		a.customRoutes[key] = handler
+		reg.routes = append(reg.routes, key)
+	}
+	if kind.SoftDelete.Enabled {
+		if kind.Watcher != nil {
+			return fmt.Errorf("soft delete cannot be used with a Watcher, please use a Reconciler")
+		}
+		if kind.ReconcileOptions.UsePlain {
+			return fmt.Errorf("soft delete cannot be used with UsePlain")
+		}
+		client, err := a.clientGenerator.ClientFor(kind.Kind)
+		if err != nil {
+			return err
+		}
+		sd := newSoftDeleter(kind.Kind, client, kind.SoftDelete)
+		key := a.customRouteHandlerKey(kind.Kind, string(AppCustomRouteMethodPost), SoftDeleteRestorePath)
+		if _, ok := a.customRoutes[key]; ok {
+			return fmt.Errorf("custom route '%s %s' conflicts with soft delete restore route", AppCustomRouteMethodPost, SoftDeleteRestorePath)
+		}
+		a.customRoutes[key] = sd.restoreHandler
+		reg.routes = append(reg.routes, key)
+		kind.Reconciler = sd.wrap(kind.Reconciler)
+		kind.ReconcileOptions.LabelFilters = append(append(make([]string, 0, len(kind.ReconcileOptions.LabelFilters)+1),
+			kind.ReconcileOptions.LabelFilters...), SoftDeleteExcludeLabelFilter)
+		a.kindRunner.AddRunnable(sd)
+		reg.runnables = append(reg.runnables, sd)
+	}
+	if len(kind.OwnsKinds) > 0 && kind.Reconciler == nil {
+		return fmt.Errorf("a reconciler is required to use OwnsKinds")
+	}
+	if len(kind.Watches) > 0 && kind.Reconciler == nil {
+		return fmt.Errorf("a reconciler is required to use Watches")
+	}
+	for _, watched := range kind.Watches {
+		if watched.Mapper == nil {
+			return fmt.Errorf("watched kind %s has no Mapper", watched.Kind.Kind())
+		}
 	}
 	if kind.Reconciler != nil || kind.Watcher != nil {
-		return a.watchKind(AppUnmanagedKind{
+		err := a.watchKind(AppUnmanagedKind{
 			Kind:             kind.Kind,
 			Reconciler:       kind.Reconciler,
 			Watcher:          kind.Watcher,
 			ReconcileOptions: kind.ReconcileOptions,
-		})
+		}, reg)
+		if err != nil {
+			return err
+		}
+	}
+	for _, owned := range kind.OwnsKinds {
+		if err := a.watchMappedKind(kind, owned, operator.OwnerReferenceMapper(kind.Kind, true), reg); err != nil {
+			return err
+		}
+	}
+	for _, watched := range kind.Watches {
+		if err := a.watchMappedKind(kind, watched.Kind, watched.Mapper, reg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchMappedKind adds informers for mapped, which map events for mapped objects with mapper to reconciling objects of kind.Kind
+func (a *App) watchMappedKind(kind AppManagedKind, mapped resource.Kind, mapper operator.ObjectMapper, reg *kindRegistration) error {
+	client, err := a.clientGenerator.ClientFor(mapped)
+	if err != nil {
+		return err
+	}
+	// Mapped objects are watched in the same namespaces as the kind, unless they are cluster-scoped
+	nsOptions := BasicReconcileOptions{}
+	if mapped.Scope() != resource.ClusterScope {
+		nsOptions = BasicReconcileOptions{
+			Namespace:         kind.ReconcileOptions.Namespace,
+			Namespaces:        kind.ReconcileOptions.Namespaces,
+			DynamicNamespaces: kind.ReconcileOptions.DynamicNamespaces,
+		}
+	}
+	for _, infOpts := range informerOptions(operator.ListWatchOptions{}, nsOptions) {
+		infOpts.RestartOptions = a.cfg.InformerConfig.RestartOptions
+		inf, err := a.newInformer(mapped, client, infOpts)
+		if err != nil {
+			return err
+		}
+		err = a.informerController.AddMappedInformer(inf, mapped.GroupVersionKind().String(), kind.Kind.GroupVersionKind().String(), mapper)
+		if err != nil {
+			return fmt.Errorf("could not add informer for kind %s to controller: %w", mapped.Kind(), err)
+		}
+		reg.informers = append(reg.informers, registeredInformer{inf, mapped.GroupVersionKind().String()})
+		if cast, ok := inf.(*operator.KubernetesBasedInformer); ok && infOpts.Namespaces != nil {
+			reg.namespaceInformers = append(reg.namespaceInformers, cast)
+		}
 	}
 	return nil
 }
 
-func (a *App) watchKind(kind AppUnmanagedKind) error {
+// newInformer returns an informer for kind with options, which is shared with any equivalent informer from
+// AppConfig.InformerFactory if the App has one, and the informer does not watch a set of namespaces
+func (a *App) newInformer(kind resource.Kind, client operator.ListWatchClient, options operator.KubernetesBasedInformerOptions) (operator.Informer, error) {
+	if a.cfg.InformerFactory != nil && options.Namespaces == nil {
+		inf, err := a.cfg.InformerFactory.Informer(kind, client, options)
+		if err != nil {
+			return nil, err
+		}
+		inf.ErrorReporter = a.cfg.InformerConfig.ErrorReporter
+		return inf, nil
+	}
+	inf, err := operator.NewKubernetesBasedInformer(kind, client, options)
+	if err != nil {
+		return nil, err
+	}
+	inf.ErrorReporter = a.cfg.InformerConfig.ErrorReporter
+	return inf, nil
+}
+
+// informerOptions returns the options for each informer to create for the namespaces of options, with the filters of base:
+// one informer for all namespaces with DynamicNamespaces, or one informer for each namespace otherwise
+func informerOptions(base operator.ListWatchOptions, options BasicReconcileOptions) []operator.KubernetesBasedInformerOptions {
+	if options.DynamicNamespaces {
+		namespaces := options.Namespaces
+		if namespaces == nil {
+			// A non-nil slice is what makes the informer watch a set of namespaces
+			namespaces = []string{}
+		}
+		return []operator.KubernetesBasedInformerOptions{{
+			ListWatchOptions: base,
+			Namespaces:       namespaces,
+		}}
+	}
+	base.Namespace = options.Namespace
+	opts := make([]operator.KubernetesBasedInformerOptions, 0, max(1, len(options.Namespaces)))
+	for _, nsOpts := range base.ForNamespaces(options.Namespaces...) {
+		opts = append(opts, operator.KubernetesBasedInformerOptions{
+			ListWatchOptions: nsOpts,
+		})
+	}
+	return opts
+}
+
+// watchKind adds informers and the watcher or reconciler for kind, and records them in reg.
+// The watcher or reconciler is added before the informers, so that it receives every event from the informers
+// if they start immediately, because the App is already running.
+//
+//nolint:gocognit,funlen
+func (a *App) watchKind(kind AppUnmanagedKind, reg *kindRegistration) error {
 	if kind.Reconciler != nil && kind.Watcher != nil {
 		return fmt.Errorf("please provide either Watcher or Reconciler, not both")
 	}
@@ -339,34 +951,104 @@ func (a *App) watchKind(kind AppUnmanagedKind) error {
 		if err != nil {
 			return err
 		}
-		inf, err := operator.NewKubernetesBasedInformer(kind.Kind, client, operator.KubernetesBasedInformerOptions{
-			ListWatchOptions: operator.ListWatchOptions{
-				Namespace:      kind.ReconcileOptions.Namespace,
-				LabelFilters:   kind.ReconcileOptions.LabelFilters,
-				FieldSelectors: kind.ReconcileOptions.FieldSelectors,
-			},
-		})
-		if err != nil {
+		if kind.ReconcileOptions.Namespace != "" && len(kind.ReconcileOptions.Namespaces) > 0 {
+			return fmt.Errorf("please provide either Namespace or Namespaces in ReconcileOptions, not both")
+		}
+		if kind.ReconcileOptions.DynamicNamespaces {
+			if kind.ReconcileOptions.Namespace != "" {
+				return fmt.Errorf("please provide either Namespace or DynamicNamespaces in ReconcileOptions, not both")
+			}
+			if kind.PauseWhenNotServed {
+				return fmt.Errorf("DynamicNamespaces cannot be used with PauseWhenNotServed")
+			}
+		}
+		if err := validateSelectors(kind.ReconcileOptions); err != nil {
 			return err
 		}
-		err = a.informerController.AddInformer(inf, kind.Kind.GroupVersionKind().String())
-		if err != nil {
-			return fmt.Errorf("could not add informer to controller: %v", err)
+		if kind.ReconcileOptions.CacheResyncInterval < 0 {
+			return fmt.Errorf("ReconcileOptions.CacheResyncInterval cannot be negative")
+		}
+		if kind.ReconcileOptions.DisableCacheResync && kind.ReconcileOptions.CacheResyncInterval != 0 {
+			return fmt.Errorf("please provide either CacheResyncInterval or DisableCacheResync in ReconcileOptions, not both")
+		}
+		baseOpts := operator.ListWatchOptions{
+			LabelFilters:   kind.ReconcileOptions.LabelFilters,
+			FieldSelectors: kind.ReconcileOptions.FieldSelectors,
+		}
+		informers := make([]operator.Informer, 0)
+		for _, infOpts := range informerOptions(baseOpts, kind.ReconcileOptions) {
+			infOpts.CacheResyncInterval = a.cacheResyncInterval(kind.ReconcileOptions)
+			infOpts.RestartOptions = a.cfg.InformerConfig.RestartOptions
+			newInformer := func() (operator.Informer, error) {
+				return a.newInformer(kind.Kind, client, infOpts)
+			}
+			var inf operator.Informer
+			if kind.PauseWhenNotServed {
+				inf, err = a.newForeignKindInformer(kind.Kind, newInformer)
+			} else {
+				inf, err = newInformer()
+			}
+			if err != nil {
+				return err
+			}
+			if cast, ok := inf.(*operator.KubernetesBasedInformer); ok && infOpts.Namespaces != nil {
+				reg.namespaceInformers = append(reg.namespaceInformers, cast)
+			}
+			informers = append(informers, inf)
 		}
 		if kind.Reconciler != nil {
 			reconciler := kind.Reconciler
+			if len(kind.ReconcileOptions.RelatedObjectResolvers) > 0 {
+				enriching, err := operator.NewEnrichingReconciler(reconciler, kind.ReconcileOptions.RelatedObjectResolvers)
+				if err != nil {
+					return err
+				}
+				reconciler = enriching
+			}
+			if kind.ReconcileOptions.TrackLineage {
+				lineage, err := operator.NewLineageReconciler(reconciler, a.getControllerIdentity(kind.Kind))
+				if err != nil {
+					return err
+				}
+				reconciler = lineage
+			}
+			predicates := kind.ReconcileOptions.Predicates
+			if kind.ReconcileOptions.DetectDrift {
+				drift, err := operator.NewDriftDetectingReconciler(reconciler, client)
+				if err != nil {
+					return err
+				}
+				reconciler = drift
+				predicates = append(append(make([]operator.Predicate, 0, len(predicates)+1), predicates...), drift.Predicate())
+			}
 			if !kind.ReconcileOptions.UsePlain {
 				op, err := operator.NewOpinionatedReconciler(&watchPatcher{a.patcher.ForKind(kind.Kind.GroupVersionKind().GroupKind())}, a.getFinalizer(kind.Kind))
 				if err != nil {
 					return err
 				}
-				op.Wrap(kind.Reconciler)
+				op.Wrap(reconciler)
 				reconciler = op
 			}
-			err = a.informerController.AddReconciler(reconciler, kind.Kind.GroupVersionKind().String())
+			if kind.ReconcileOptions.LogContext {
+				logCtx, err := operator.NewLoggingReconciler(reconciler)
+				if err != nil {
+					return err
+				}
+				reconciler = logCtx
+			}
+			if kind.ReconcileOptions.MaxConcurrentReconciles > 0 {
+				err = a.informerController.AddReconcilerWithOptions(reconciler, kind.Kind.GroupVersionKind().String(), operator.ReconcilerOptions{
+					MaxConcurrentReconciles: kind.ReconcileOptions.MaxConcurrentReconciles,
+					FairQueueing:            kind.ReconcileOptions.FairQueueing,
+					Predicates:              predicates,
+				})
+			} else {
+				err = a.informerController.AddReconciler(reconciler, kind.Kind.GroupVersionKind().String(), predicates...)
+			}
 			if err != nil {
 				return fmt.Errorf("could not add reconciler to controller: %v", err)
 			}
+			reg.reconcilers = append(reg.reconcilers, reconciler)
 		}
 		if kind.Watcher != nil {
 			watcher := kind.Watcher
@@ -383,10 +1065,31 @@ func (a *App) watchKind(kind AppUnmanagedKind) error {
 				}
 				watcher = op
 			}
-			err = a.informerController.AddWatcher(watcher, kind.Kind.GroupVersionKind().String())
+			if kind.ReconcileOptions.LogContext {
+				logCtx, err := operator.NewLoggingWatcher(watcher)
+				if err != nil {
+					return err
+				}
+				watcher = logCtx
+			}
+			err = a.informerController.AddWatcher(watcher, kind.Kind.GroupVersionKind().String(), kind.ReconcileOptions.Predicates...)
 			if err != nil {
 				return fmt.Errorf("could not add watcher to controller: %v", err)
 			}
+			reg.watchers = append(reg.watchers, watcher)
+		}
+		added := len(reg.informers)
+		for _, inf := range informers {
+			err = a.informerController.AddInformer(inf, kind.Kind.GroupVersionKind().String())
+			if err != nil {
+				// Remove the informers already added for the kind's other namespaces, so none of them are left running
+				for _, registered := range reg.informers[added:] {
+					a.informerController.RemoveInformer(registered.informer, registered.resourceKind)
+				}
+				reg.informers = reg.informers[:added]
+				return fmt.Errorf("could not add informer to controller: %w", err)
+			}
+			reg.informers = append(reg.informers, registeredInformer{inf, kind.Kind.GroupVersionKind().String()})
 		}
 	}
 	return nil
@@ -394,9 +1097,32 @@ func (a *App) watchKind(kind AppUnmanagedKind) error {
 
 // RegisterKindConverter adds a converter for a GroupKind, which will then be processed on Convert calls
 func (a *App) RegisterKindConverter(groupKind schema.GroupKind, converter k8s.Converter) {
+	a.kindsMux.Lock()
+	defer a.kindsMux.Unlock()
 	a.converters[groupKind.String()] = converter
 }
 
+// RegisterVersionConverter adds an app.VersionConverter for the converter's GroupKind, which will then be used on
+// Convert calls for the kind instead of any converter added with RegisterKindConverter
+func (a *App) RegisterVersionConverter(converter *app.VersionConverter) {
+	a.kindsMux.Lock()
+	defer a.kindsMux.Unlock()
+	a.versionConverters[converter.GroupKind().String()] = converter
+}
+
+// newForeignKindInformer returns an operator.ForeignKindInformer for kind, which checks whether it is served using discovery
+func (a *App) newForeignKindInformer(kind resource.Kind, newInformer func() (operator.Informer, error)) (operator.Informer, error) {
+	disc, err := discovery.NewDiscoveryClientForConfig(&a.cfg.KubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create discovery client: %w", err)
+	}
+	return operator.NewForeignKindInformer(operator.NewDiscoveryServedKindChecker(disc), operator.ForeignKindInformerConfig{
+		Group:       kind.Group(),
+		Resource:    kind.Plural(),
+		NewInformer: newInformer,
+	})
+}
+
 // PrometheusCollectors implements metrics.Provider and returns prometheus collectors used by the app for exposing metrics
 func (a *App) PrometheusCollectors() []prometheus.Collector {
 	collectors := make([]prometheus.Collector, 0)
@@ -414,20 +1140,31 @@ func (a *App) RegisterMetricsCollectors(collectors ...prometheus.Collector) {
 
 // Validate implements app.App and handles Validating Admission Requests
 func (a *App) Validate(ctx context.Context, req *app.AdmissionRequest) error {
-	k, ok := a.kinds[gvk(req.Group, req.Version, req.Kind)]
+	k, ok := a.managedKind(gvk(req.Group, req.Version, req.Kind))
 	if !ok {
 		// TODO: Default validator instead of ErrNotImplemented?
 		return app.ErrNotImplemented
 	}
+	if k.DeletionProtection.Enabled {
+		if err := k8s.CheckDeletionProtection((*resource.AdmissionRequest)(req), k.DeletionProtection.config()); err != nil {
+			return err
+		}
+	}
 	if k.Validator == nil {
+		// Requests which pass deletion protection are allowed, rather than rejected as unimplemented by the runner
+		if k.DeletionProtection.Enabled {
+			return nil
+		}
 		return app.ErrNotImplemented
 	}
+	ctx, cancel := resource.WithDeadlineBudget(ctx, a.cfg.DeadlineReserve)
+	defer cancel()
 	return k.Validator.Validate(ctx, req)
 }
 
 // Mutate implements app.App and handles Mutating Admission Requests
 func (a *App) Mutate(ctx context.Context, req *app.AdmissionRequest) (*app.MutatingResponse, error) {
-	k, ok := a.kinds[gvk(req.Group, req.Version, req.Kind)]
+	k, ok := a.managedKind(gvk(req.Group, req.Version, req.Kind))
 	if !ok {
 		// TODO: Default mutator instead of ErrNotImplemented?
 		return nil, app.ErrNotImplemented
@@ -435,25 +1172,51 @@ func (a *App) Mutate(ctx context.Context, req *app.AdmissionRequest) (*app.Mutat
 	if k.Mutator == nil {
 		return nil, app.ErrNotImplemented
 	}
+	ctx, cancel := resource.WithDeadlineBudget(ctx, a.cfg.DeadlineReserve)
+	defer cancel()
 	return k.Mutator.Mutate(ctx, req)
 }
 
+// Default implements app.App and handles defaulting for Mutating Admission Requests
+func (a *App) Default(ctx context.Context, req *app.AdmissionRequest) (*app.MutatingResponse, error) {
+	k, ok := a.managedKind(gvk(req.Group, req.Version, req.Kind))
+	if !ok || k.Defaulter == nil {
+		return nil, app.ErrNotImplemented
+	}
+	ctx, cancel := resource.WithDeadlineBudget(ctx, a.cfg.DeadlineReserve)
+	defer cancel()
+	return k.Defaulter.Default(ctx, req)
+}
+
 // Convert implements app.App and handles resource conversion requests
-func (a *App) Convert(_ context.Context, req app.ConversionRequest) (*app.RawObject, error) {
+func (a *App) Convert(ctx context.Context, req app.ConversionRequest) (*app.RawObject, error) {
+	a.kindsMux.RLock()
+	versionConverter, hasVersionConverter := a.versionConverters[req.SourceGVK.GroupKind().String()]
 	converter, ok := a.converters[req.SourceGVK.GroupKind().String()]
+	a.kindsMux.RUnlock()
+	if hasVersionConverter {
+		return versionConverter.Convert(ctx, req)
+	}
 	if !ok {
 		// Default conversion?
 		return nil, app.ErrNotImplemented
 	}
 	srcAPIVersion, _ := req.SourceGVK.ToAPIVersionAndKind()
 	dstAPIVersion, _ := req.TargetGVK.ToAPIVersionAndKind()
-	converted, err := converter.Convert(k8s.RawKind{
+	raw := k8s.RawKind{
 		Kind:       req.SourceGVK.Kind,
 		APIVersion: srcAPIVersion,
 		Group:      req.SourceGVK.Group,
 		Version:    req.SourceGVK.Version,
 		Raw:        req.Raw.Raw,
-	}, dstAPIVersion)
+	}
+	var converted []byte
+	var err error
+	if cc, ok := converter.(k8s.ContextConverter); ok {
+		converted, err = cc.ConvertWithContext(ctx, raw, dstAPIVersion)
+	} else {
+		converted, err = converter.Convert(raw, dstAPIVersion)
+	}
 	return &app.RawObject{
 		Raw: converted,
 	}, err
@@ -461,17 +1224,48 @@ func (a *App) Convert(_ context.Context, req app.ConversionRequest) (*app.RawObj
 
 // CallResourceCustomRoute implements app.App and handles custom resource route requests
 func (a *App) CallResourceCustomRoute(ctx context.Context, req *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error) {
-	k, ok := a.kinds[gvk(req.ResourceIdentifier.Group, req.ResourceIdentifier.Version, req.ResourceIdentifier.Kind)]
+	// App-level routes are requested without a kind
+	if req.ResourceIdentifier.Kind == "" {
+		if handler, ok := a.appRoutes[req.SubresourcePath]; ok && req.ResourceIdentifier.Group == a.cfg.ManifestData.Group {
+			return handler(ctx, req)
+		}
+		return nil, app.ErrCustomRouteNotFound
+	}
+	k, ok := a.managedKind(gvk(req.ResourceIdentifier.Group, req.ResourceIdentifier.Version, req.ResourceIdentifier.Kind))
 	if !ok {
 		// TODO: still return the not found, or just return NotImplemented?
 		return nil, app.ErrCustomRouteNotFound
 	}
-	if handler, ok := a.customRoutes[a.customRouteHandlerKey(k.Kind, req.Method, req.SubresourcePath)]; ok {
+	ctx, cancel := resource.WithDeadlineBudget(ctx, a.cfg.DeadlineReserve)
+	defer cancel()
+	if handler, ok := a.customRoute(a.customRouteHandlerKey(k.Kind, req.Method, req.SubresourcePath)); ok {
 		return handler(ctx, req)
 	}
+	// The async operations route matches any operation ID
+	if id, ok := strings.CutPrefix(req.SubresourcePath, AsyncOperationsPath+"/"); ok && id != "" && !strings.Contains(id, "/") {
+		if handler, ok := a.customRoute(a.customRouteHandlerKey(k.Kind, req.Method, AsyncOperationsPath)); ok {
+			return handler(ctx, req)
+		}
+	}
 	return nil, app.ErrCustomRouteNotFound
 }
 
+// managedKind returns the AppManagedKind with the key (see gvk), and false if the App does not manage it
+func (a *App) managedKind(key string) (AppManagedKind, bool) {
+	a.kindsMux.RLock()
+	defer a.kindsMux.RUnlock()
+	k, ok := a.kinds[key]
+	return k, ok
+}
+
+// customRoute returns the handler for the custom route with the key (see customRouteHandlerKey)
+func (a *App) customRoute(key string) (AppCustomRouteHandler, bool) {
+	a.kindsMux.RLock()
+	defer a.kindsMux.RUnlock()
+	handler, ok := a.customRoutes[key]
+	return handler, ok
+}
+
 func (a *App) getFinalizer(sch resource.Schema) string {
 	if a.cfg.InformerConfig.FinalizerSupplier != nil {
 		return a.cfg.InformerConfig.FinalizerSupplier(sch)
@@ -482,6 +1276,13 @@ func (a *App) getFinalizer(sch resource.Schema) string {
 	return fmt.Sprintf("%s-finalizer", sch.Plural())
 }
 
+func (a *App) getControllerIdentity(sch resource.Schema) string {
+	if a.cfg.Name != "" {
+		return fmt.Sprintf("%s/%s-reconciler", a.cfg.Name, sch.Plural())
+	}
+	return fmt.Sprintf("%s-reconciler", sch.Plural())
+}
+
 func (*App) customRouteHandlerKey(kind resource.Kind, method string, path string) string {
 	return fmt.Sprintf("%s/%s/%s/%s/%s", kind.Group(), kind.Version(), kind.Kind(), strings.ToUpper(method), path)
 }
@@ -491,6 +1292,34 @@ type syncWatcher interface {
 	Sync(ctx context.Context, object resource.Object) error
 }
 
+// cacheResyncInterval returns the cache resync interval for the informers of a kind with options
+func (a *App) cacheResyncInterval(options BasicReconcileOptions) time.Duration {
+	switch {
+	case options.DisableCacheResync:
+		return 0
+	case options.CacheResyncInterval > 0:
+		return options.CacheResyncInterval
+	default:
+		return a.cfg.InformerConfig.CacheResyncInterval
+	}
+}
+
+// validateSelectors returns an error if the LabelFilters or FieldSelectors of options cannot be parsed,
+// as the informers would otherwise fail every list request
+func validateSelectors(options BasicReconcileOptions) error {
+	if len(options.LabelFilters) > 0 {
+		if _, err := labels.Parse(strings.Join(options.LabelFilters, ",")); err != nil {
+			return fmt.Errorf("invalid ReconcileOptions.LabelFilters: %w", err)
+		}
+	}
+	if len(options.FieldSelectors) > 0 {
+		if _, err := fields.ParseSelector(strings.Join(options.FieldSelectors, ",")); err != nil {
+			return fmt.Errorf("invalid ReconcileOptions.FieldSelectors: %w", err)
+		}
+	}
+	return nil
+}
+
 func gvk(group, version, kind string) string {
 	return fmt.Sprintf("%s/%s/%s", group, version, kind)
 }
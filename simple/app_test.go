@@ -2,12 +2,17 @@ package simple
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-app-sdk/app"
 	"github.com/grafana/grafana-app-sdk/k8s"
+	"github.com/grafana/grafana-app-sdk/operator"
 	"github.com/grafana/grafana-app-sdk/resource"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -92,6 +97,56 @@ func TestApp_Convert(t *testing.T) {
 		assert.Nil(t, err)
 		assert.Equal(t, converted, ret.Raw)
 	})
+
+	t.Run("version converter", func(t *testing.T) {
+		kind := func(version string) resource.Kind {
+			return resource.Kind{
+				Schema: resource.NewSimpleSchema("foo", version, &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("baz")),
+				Codecs: map[resource.KindEncoding]resource.Codec{
+					resource.KindEncodingJSON: resource.NewJSONCodec(),
+				},
+			}
+		}
+		vc, err := app.NewVersionConverter(kind("v1"), kind("v2"))
+		require.Nil(t, err)
+		require.Nil(t, vc.AddConversion("v1", "v2", func(_ context.Context, src, dst resource.Object) error {
+			dst.(*resource.UntypedObject).Spec = map[string]any{
+				"name": src.(*resource.UntypedObject).Spec["title"],
+			}
+			return nil
+		}))
+		req := app.ConversionRequest{
+			SourceGVK: schema.GroupVersionKind{
+				Group:   "foo",
+				Version: "v1",
+				Kind:    "baz",
+			},
+			TargetGVK: schema.GroupVersionKind{
+				Group:   "foo",
+				Version: "v2",
+				Kind:    "baz",
+			},
+			Raw: app.RawObject{
+				Raw: []byte(`{"apiVersion":"foo/v1","kind":"baz","metadata":{"name":"a"},"spec":{"title":"bar"}}`),
+			},
+		}
+		a := createTestApp(t, AppConfig{
+			// The VersionConverter takes precedence over the Converter for the same kind
+			Converters: map[schema.GroupKind]Converter{{
+				Group: req.SourceGVK.Group,
+				Kind:  req.SourceGVK.Kind,
+			}: &testConverter{
+				func(obj k8s.RawKind, targetAPIVersion string) ([]byte, error) {
+					return nil, errors.New("I AM ERROR")
+				},
+			}},
+			VersionConverters: []*app.VersionConverter{vc},
+		})
+		ret, err := a.Convert(context.TODO(), req)
+		require.Nil(t, err)
+		assert.Equal(t, map[string]any{"name": "bar"}, ret.Object.(*resource.UntypedObject).Spec)
+		assert.Equal(t, "foo/v2", ret.Object.(*resource.UntypedObject).APIVersion)
+	})
 }
 
 func TestApp_CallResourceCustomRoute(t *testing.T) {
@@ -115,6 +170,81 @@ func TestApp_CallResourceCustomRoute(t *testing.T) {
 		assert.Equal(t, app.ErrCustomRouteNotFound, err)
 	})
 
+	t.Run("kinds route", func(t *testing.T) {
+		manifest := app.ManifestData{
+			AppName: "test-app",
+			Group:   kind.Group(),
+			Kinds: []app.ManifestKind{{
+				Kind:     kind.Kind(),
+				Scope:    "Namespaced",
+				Versions: []app.ManifestKindVersion{{Name: kind.Version()}},
+				Maturity: app.KindMaturityBeta,
+			}},
+		}
+		a := createTestApp(t, AppConfig{ManifestData: &manifest})
+		resp, err := a.CallResourceCustomRoute(context.TODO(), &app.ResourceCustomRouteRequest{
+			ResourceIdentifier: resource.FullIdentifier{Group: kind.Group()},
+			SubresourcePath:    app.KindsRoutePath,
+			Method:             http.MethodGet,
+		})
+		require.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		info := app.KindsInfo{}
+		require.Nil(t, json.Unmarshal(resp.Body, &info))
+		assert.Equal(t, app.NewKindsInfo(manifest), info)
+
+		// The route is only served for the app's group
+		_, err = a.CallResourceCustomRoute(context.TODO(), &app.ResourceCustomRouteRequest{
+			ResourceIdentifier: resource.FullIdentifier{Group: "other.grafana.app"},
+			SubresourcePath:    app.KindsRoutePath,
+			Method:             http.MethodGet,
+		})
+		assert.Equal(t, app.ErrCustomRouteNotFound, err)
+
+		// Without ManifestData, there is no kinds route
+		a = createTestApp(t, AppConfig{})
+		_, err = a.CallResourceCustomRoute(context.TODO(), &app.ResourceCustomRouteRequest{
+			ResourceIdentifier: resource.FullIdentifier{Group: kind.Group()},
+			SubresourcePath:    app.KindsRoutePath,
+			Method:             http.MethodGet,
+		})
+		assert.Equal(t, app.ErrCustomRouteNotFound, err)
+	})
+
+	t.Run("custom routes openapi route", func(t *testing.T) {
+		manifest := app.ManifestData{
+			AppName: "test-app",
+			Group:   kind.Group(),
+			Kinds: []app.ManifestKind{{
+				Kind:  kind.Kind(),
+				Scope: "Namespaced",
+				Versions: []app.ManifestKindVersion{{
+					Name:         kind.Version(),
+					CustomRoutes: []app.CustomRoute{{Path: "reconcile", Method: http.MethodPost, Name: "Reconcile"}},
+				}},
+			}},
+		}
+		a := createTestApp(t, AppConfig{
+			ManifestData: &manifest,
+			ManagedKinds: []AppManagedKind{{
+				Kind: kind,
+			}},
+		})
+		// The declared route must have a handler
+		assert.Equal(t, errors.New("kind Bar/v1 declares the custom route POST reconcile, but has no handler for it"), a.ValidateManifest(manifest))
+
+		resp, err := a.CallResourceCustomRoute(context.TODO(), &app.ResourceCustomRouteRequest{
+			ResourceIdentifier: resource.FullIdentifier{Group: kind.Group()},
+			SubresourcePath:    app.CustomRoutesOpenAPIRoutePath,
+			Method:             http.MethodGet,
+		})
+		require.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		doc := map[string]any{}
+		require.Nil(t, json.Unmarshal(resp.Body, &doc))
+		assert.Contains(t, doc["paths"], fmt.Sprintf("/apis/%s/%s/namespaces/{namespace}/%s/{name}/reconcile", kind.Group(), kind.Version(), kind.Plural()))
+	})
+
 	t.Run("no method", func(t *testing.T) {
 		a, err := NewApp(AppConfig{
 			ManagedKinds: []AppManagedKind{{
@@ -284,6 +414,37 @@ func TestApp_ManagedKinds(t *testing.T) {
 	assert.ElementsMatch(t, kinds, a.ManagedKinds())
 }
 
+func TestApp_OwnsKinds(t *testing.T) {
+	owned := resource.Kind{
+		Schema: resource.NewSimpleSchema("foo", "v1", &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("Baz")),
+		Codecs: map[resource.KindEncoding]resource.Codec{
+			resource.KindEncodingJSON: resource.NewJSONCodec(),
+		},
+	}
+
+	t.Run("no reconciler", func(t *testing.T) {
+		_, err := NewApp(AppConfig{ManagedKinds: []AppManagedKind{{
+			Kind:      testKind(),
+			OwnsKinds: []resource.Kind{owned},
+		}}})
+		assert.Equal(t, "a reconciler is required to use OwnsKinds", err.Error())
+	})
+
+	t.Run("informer per namespace", func(t *testing.T) {
+		a := createTestApp(t, AppConfig{ManagedKinds: []AppManagedKind{{
+			Kind:       testKind(),
+			Reconciler: &operator.SimpleReconciler{},
+			ReconcileOptions: BasicReconcileOptions{
+				Namespaces: []string{"a", "b"},
+			},
+			OwnsKinds: []resource.Kind{owned},
+		}}})
+		// The owned kind can be read from the cache of its informers, but has no reconcilers of its own
+		_, err := a.informerController.Lister(owned.GroupVersionKind().String())
+		assert.Nil(t, err)
+	})
+}
+
 func TestApp_Mutate(t *testing.T) {
 	kind := testKind()
 	req := &app.AdmissionRequest{
@@ -359,6 +520,102 @@ func TestApp_Mutate(t *testing.T) {
 	})
 }
 
+func TestApp_Default(t *testing.T) {
+	kind := testKind()
+	req := &app.AdmissionRequest{
+		Action:   resource.AdmissionActionCreate,
+		Group:    kind.Group(),
+		Version:  kind.Version(),
+		Kind:     kind.Kind(),
+		UserInfo: resource.AdmissionUserInfo{},
+		Object: &resource.UntypedObject{
+			Spec: map[string]any{
+				"foo": "bar",
+			},
+		},
+	}
+	t.Run("no defaulter", func(t *testing.T) {
+		a := createTestApp(t, AppConfig{
+			ManagedKinds: []AppManagedKind{{
+				Kind: kind,
+			}},
+		})
+		ret, err := a.Default(context.TODO(), req)
+		assert.Nil(t, ret)
+		assert.Equal(t, app.ErrNotImplemented, err)
+	})
+
+	t.Run("defaulter error", func(t *testing.T) {
+		expectedErr := errors.New("error")
+		a := createTestApp(t, AppConfig{
+			ManagedKinds: []AppManagedKind{{
+				Kind: kind,
+				Defaulter: &Defaulter{
+					DefaultFunc: func(context.Context, resource.Object) error {
+						return expectedErr
+					},
+				},
+			}},
+		})
+		ret, err := a.Default(context.TODO(), req)
+		assert.Nil(t, ret)
+		assert.Equal(t, expectedErr, err)
+	})
+
+	t.Run("defaulter success", func(t *testing.T) {
+		a := createTestApp(t, AppConfig{
+			ManagedKinds: []AppManagedKind{{
+				Kind: kind,
+				Defaulter: &Defaulter{
+					DefaultFunc: func(_ context.Context, obj resource.Object) error {
+						spec := obj.(*resource.UntypedObject).Spec
+						if _, ok := spec["baz"]; !ok {
+							spec["baz"] = "default"
+						}
+						return nil
+					},
+				},
+			}},
+		})
+		ret, err := a.Default(context.TODO(), req)
+		assert.Nil(t, err)
+		require.NotNil(t, ret)
+		assert.Equal(t, map[string]any{"foo": "bar", "baz": "default"}, ret.UpdatedObject.(*resource.UntypedObject).Spec)
+		// The request's object is not modified
+		assert.Equal(t, map[string]any{"foo": "bar"}, req.Object.(*resource.UntypedObject).Spec)
+	})
+
+	t.Run("manifest must support defaulting", func(t *testing.T) {
+		manifest := app.ManifestData{
+			Group: kind.Group(),
+			Kinds: []app.ManifestKind{{
+				Kind: kind.Kind(),
+				Versions: []app.ManifestKindVersion{{
+					Name: kind.Version(),
+					Admission: &app.AdmissionCapabilities{
+						Defaulting: &app.DefaultingCapability{Operations: []app.AdmissionOperation{app.AdmissionOperationCreate}},
+					},
+				}},
+			}},
+		}
+		a := createTestApp(t, AppConfig{
+			ManagedKinds: []AppManagedKind{{
+				Kind: kind,
+			}},
+		})
+		assert.Equal(t, errors.New("kind Bar/v1 supports defaulting but has no defaulter"), a.ValidateManifest(manifest))
+		a = createTestApp(t, AppConfig{
+			ManagedKinds: []AppManagedKind{{
+				Kind:      kind,
+				Defaulter: &Defaulter{},
+			}},
+		})
+		assert.Nil(t, a.ValidateManifest(manifest))
+		manifest.Kinds[0].Versions[0].Admission = nil
+		assert.Equal(t, errors.New("kind Bar/v1 does not support defaulting, but has a defaulter"), a.ValidateManifest(manifest))
+	})
+}
+
 func TestApp_Validate(t *testing.T) {
 	kind := testKind()
 	req := &app.AdmissionRequest{
@@ -423,6 +680,495 @@ func TestApp_Validate(t *testing.T) {
 	})
 }
 
+func TestApp_DeletionProtection(t *testing.T) {
+	kind := testKind()
+	protected := &resource.UntypedObject{}
+	protected.SetName("singleton")
+	protected.SetAnnotations(map[string]string{k8s.AnnotationDeletionProtected: "true"})
+	deleteReq := func(obj resource.Object, username string) *app.AdmissionRequest {
+		return &app.AdmissionRequest{
+			Action:    resource.AdmissionActionDelete,
+			Group:     kind.Group(),
+			Version:   kind.Version(),
+			Kind:      kind.Kind(),
+			UserInfo:  resource.AdmissionUserInfo{Username: username},
+			OldObject: obj,
+		}
+	}
+	validated := 0
+	a := createTestApp(t, AppConfig{
+		ManagedKinds: []AppManagedKind{{
+			Kind: kind,
+			Validator: &Validator{
+				ValidateFunc: func(context.Context, *app.AdmissionRequest) error {
+					validated++
+					return nil
+				},
+			},
+			DeletionProtection: DeletionProtectionOptions{
+				Enabled:      true,
+				AllowedUsers: []string{"admin"},
+			},
+		}},
+	})
+
+	err := a.Validate(context.TODO(), deleteReq(protected, "user"))
+	var admErr resource.AdmissionError
+	require.ErrorAs(t, err, &admErr)
+	assert.Equal(t, http.StatusForbidden, admErr.StatusCode())
+	assert.Equal(t, k8s.ErrReasonDeletionProtected, admErr.Reason())
+	assert.Equal(t, 0, validated)
+
+	assert.Nil(t, a.Validate(context.TODO(), deleteReq(protected, "admin")))
+	assert.Nil(t, a.Validate(context.TODO(), deleteReq(&resource.UntypedObject{}, "user")))
+	assert.Equal(t, 2, validated)
+
+	t.Run("manifest must validate deletes", func(t *testing.T) {
+		manifest := app.ManifestData{
+			Group: kind.Group(),
+			Kinds: []app.ManifestKind{{
+				Kind: kind.Kind(),
+				Versions: []app.ManifestKindVersion{{
+					Name: kind.Version(),
+					Admission: &app.AdmissionCapabilities{
+						Validation: &app.ValidationCapability{Operations: []app.AdmissionOperation{app.AdmissionOperationCreate}},
+					},
+				}},
+			}},
+		}
+		assert.NotNil(t, a.ValidateManifest(manifest))
+		manifest.Kinds[0].Versions[0].Admission.Validation.Operations = []app.AdmissionOperation{app.AdmissionOperationAny}
+		assert.Nil(t, a.ValidateManifest(manifest))
+	})
+
+	t.Run("without a validator", func(t *testing.T) {
+		a := createTestApp(t, AppConfig{
+			ManagedKinds: []AppManagedKind{{
+				Kind:               kind,
+				DeletionProtection: DeletionProtectionOptions{Enabled: true},
+			}},
+		})
+		require.ErrorAs(t, a.Validate(context.TODO(), deleteReq(protected, "user")), &admErr)
+		assert.Nil(t, a.Validate(context.TODO(), deleteReq(&resource.UntypedObject{}, "user")))
+		for _, action := range []resource.AdmissionAction{resource.AdmissionActionCreate, resource.AdmissionActionUpdate} {
+			assert.Nil(t, a.Validate(context.TODO(), &app.AdmissionRequest{
+				Action:  action,
+				Group:   kind.Group(),
+				Version: kind.Version(),
+				Kind:    kind.Kind(),
+				Object:  protected,
+			}))
+		}
+	})
+}
+
+func TestApp_AddKind(t *testing.T) {
+	kind := testKind()
+	owned := resource.Kind{
+		Schema: resource.NewSimpleSchema("foo", "v1", &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("Baz")),
+		Codecs: map[resource.KindEncoding]resource.Codec{
+			resource.KindEncodingJSON: resource.NewJSONCodec(),
+		},
+	}
+	route := AppCustomRoute{Method: AppCustomRouteMethodGet, Path: "foo"}
+	handler := func(context.Context, *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error) {
+		return &app.ResourceCustomRouteResponse{StatusCode: http.StatusOK}, nil
+	}
+	callRoute := func(a *App) error {
+		_, err := a.CallResourceCustomRoute(context.Background(), &app.ResourceCustomRouteRequest{
+			ResourceIdentifier: resource.FullIdentifier{Group: kind.Group(), Version: kind.Version(), Kind: kind.Kind(), Name: "bar"},
+			SubresourcePath:    route.Path,
+			Method:             string(route.Method),
+		})
+		return err
+	}
+
+	t.Run("add and remove", func(t *testing.T) {
+		a := createTestApp(t, AppConfig{})
+		require.Nil(t, a.AddKind(AppManagedKind{
+			Kind:         kind,
+			Reconciler:   &operator.SimpleReconciler{},
+			OwnsKinds:    []resource.Kind{owned},
+			CustomRoutes: AppCustomRouteHandlers{route: handler},
+		}))
+		assert.Equal(t, []resource.Kind{kind}, a.ManagedKinds())
+		assert.Nil(t, callRoute(a))
+		_, err := a.informerController.Lister(kind.GroupVersionKind().String())
+		assert.Nil(t, err)
+		_, err = a.informerController.Lister(owned.GroupVersionKind().String())
+		assert.Nil(t, err)
+		assert.EqualError(t, a.AddKind(AppManagedKind{Kind: kind}), "kind Bar/v1 is already managed by the app")
+
+		require.Nil(t, a.RemoveKind(kind))
+		assert.Empty(t, a.ManagedKinds())
+		assert.Equal(t, app.ErrCustomRouteNotFound, callRoute(a))
+		_, err = a.informerController.Lister(kind.GroupVersionKind().String())
+		assert.NotNil(t, err)
+		_, err = a.informerController.Lister(owned.GroupVersionKind().String())
+		assert.NotNil(t, err)
+		assert.EqualError(t, a.RemoveKind(kind), "kind Bar/v1 is not managed by the app")
+
+		// The kind can be added again once removed
+		require.Nil(t, a.AddKind(AppManagedKind{Kind: kind, CustomRoutes: AppCustomRouteHandlers{route: handler}}))
+		assert.Nil(t, callRoute(a))
+	})
+
+	t.Run("remove then re-add", func(t *testing.T) {
+		a := createTestApp(t, AppConfig{})
+		reconciled := 0
+		reconciler := &operator.SimpleReconciler{
+			ReconcileFunc: func(context.Context, operator.ReconcileRequest) (operator.ReconcileResult, error) {
+				reconciled++
+				return operator.ReconcileResult{}, nil
+			},
+		}
+		watched := 0
+		watcher := &operator.SimpleWatcher{
+			AddFunc: func(context.Context, resource.Object) error {
+				watched++
+				return nil
+			},
+		}
+		kinds := []AppManagedKind{
+			{Kind: kind, Reconciler: reconciler, ReconcileOptions: BasicReconcileOptions{UsePlain: true}},
+			{Kind: owned, Watcher: watcher, ReconcileOptions: BasicReconcileOptions{UsePlain: true}},
+		}
+		for _, k := range kinds {
+			require.Nil(t, a.AddKind(k))
+			require.Nil(t, a.RemoveKind(k.Kind))
+			require.Nil(t, a.AddKind(k))
+		}
+
+		// Events are only passed to the reconciler and watcher of the kind as added the second time
+		for _, k := range kinds {
+			inf := operator.NewStaticInformer()
+			require.Nil(t, a.informerController.AddInformer(inf, k.Kind.GroupVersionKind().String()))
+			obj := &resource.UntypedObject{}
+			obj.SetName("foo")
+			obj.SetNamespace("bar")
+			require.Nil(t, inf.FireAdd(context.Background(), obj))
+		}
+		assert.Equal(t, 1, reconciled)
+		assert.Equal(t, 1, watched)
+
+		// Removing the kind again leaves no handlers
+		for _, k := range kinds {
+			require.Nil(t, a.RemoveKind(k.Kind))
+		}
+		for _, k := range kinds {
+			inf := operator.NewStaticInformer()
+			require.Nil(t, a.informerController.AddInformer(inf, k.Kind.GroupVersionKind().String()))
+			obj := &resource.UntypedObject{}
+			obj.SetName("foo")
+			obj.SetNamespace("bar")
+			require.Nil(t, inf.FireAdd(context.Background(), obj))
+		}
+		assert.Equal(t, 1, reconciled)
+		assert.Equal(t, 1, watched)
+	})
+
+	t.Run("kind from config", func(t *testing.T) {
+		a := createTestApp(t, AppConfig{ManagedKinds: []AppManagedKind{{
+			Kind:         kind,
+			CustomRoutes: AppCustomRouteHandlers{route: handler},
+		}}})
+		require.Nil(t, a.RemoveKind(kind))
+		assert.Equal(t, app.ErrCustomRouteNotFound, callRoute(a))
+	})
+
+	t.Run("invalid kind is rolled back", func(t *testing.T) {
+		a := createTestApp(t, AppConfig{})
+		assert.EqualError(t, a.AddKind(AppManagedKind{
+			Kind:         kind,
+			CustomRoutes: AppCustomRouteHandlers{route: handler},
+			OwnsKinds:    []resource.Kind{owned},
+		}), "a reconciler is required to use OwnsKinds")
+		assert.Empty(t, a.ManagedKinds())
+		assert.Equal(t, app.ErrCustomRouteNotFound, callRoute(a))
+	})
+
+	t.Run("concurrent requests", func(t *testing.T) {
+		a := createTestApp(t, AppConfig{})
+		wg := sync.WaitGroup{}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				_ = a.AddKind(AppManagedKind{Kind: kind, CustomRoutes: AppCustomRouteHandlers{route: handler}})
+				_ = a.RemoveKind(kind)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				_ = callRoute(a)
+				_ = a.Validate(context.Background(), &app.AdmissionRequest{Group: kind.Group(), Version: kind.Version(), Kind: kind.Kind()})
+			}
+		}()
+		wg.Wait()
+	})
+}
+
+func TestApp_Selectors(t *testing.T) {
+	kind := testKind()
+
+	t.Run("selectors are used by the informers", func(t *testing.T) {
+		a := createTestApp(t, AppConfig{ManagedKinds: []AppManagedKind{{
+			Kind:       kind,
+			Reconciler: &operator.SimpleReconciler{},
+			ReconcileOptions: BasicReconcileOptions{
+				Namespaces:     []string{"a", "b"},
+				LabelFilters:   []string{"app.kubernetes.io/managed-by=my-app"},
+				FieldSelectors: []string{"metadata.name!=foo"},
+			},
+		}}})
+		reg := a.registrations[gvk(kind.Group(), kind.Version(), kind.Kind())]
+		require.Len(t, reg.informers, 2)
+		for i, ns := range []string{"a", "b"} {
+			inf, ok := reg.informers[i].informer.(*operator.KubernetesBasedInformer)
+			require.True(t, ok)
+			assert.Equal(t, operator.ListWatchOptions{
+				Namespace:      ns,
+				LabelFilters:   []string{"app.kubernetes.io/managed-by=my-app"},
+				FieldSelectors: []string{"metadata.name!=foo"},
+			}, inf.ListWatchOptions())
+		}
+	})
+
+	t.Run("invalid label filter", func(t *testing.T) {
+		_, err := NewApp(AppConfig{ManagedKinds: []AppManagedKind{{
+			Kind:       kind,
+			Reconciler: &operator.SimpleReconciler{},
+			ReconcileOptions: BasicReconcileOptions{
+				LabelFilters: []string{"foo in (bar"},
+			},
+		}}})
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), "invalid ReconcileOptions.LabelFilters")
+	})
+
+	t.Run("invalid field selector", func(t *testing.T) {
+		_, err := NewApp(AppConfig{ManagedKinds: []AppManagedKind{{
+			Kind:    kind,
+			Watcher: &operator.SimpleWatcher{},
+			ReconcileOptions: BasicReconcileOptions{
+				FieldSelectors: []string{"metadata.name"},
+			},
+		}}})
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), "invalid ReconcileOptions.FieldSelectors")
+	})
+}
+
+func TestApp_CacheResyncInterval(t *testing.T) {
+	kind := testKind()
+	a := createTestApp(t, AppConfig{InformerConfig: AppInformerConfig{CacheResyncInterval: time.Minute}})
+	assert.Equal(t, time.Minute, a.cacheResyncInterval(BasicReconcileOptions{}))
+	assert.Equal(t, time.Hour, a.cacheResyncInterval(BasicReconcileOptions{CacheResyncInterval: time.Hour}))
+	assert.Equal(t, time.Duration(0), a.cacheResyncInterval(BasicReconcileOptions{DisableCacheResync: true}))
+
+	_, err := NewApp(AppConfig{ManagedKinds: []AppManagedKind{{
+		Kind:       kind,
+		Reconciler: &operator.SimpleReconciler{},
+		ReconcileOptions: BasicReconcileOptions{
+			CacheResyncInterval: time.Hour,
+			DisableCacheResync:  true,
+		},
+	}}})
+	assert.EqualError(t, err, "please provide either CacheResyncInterval or DisableCacheResync in ReconcileOptions, not both")
+	_, err = NewApp(AppConfig{ManagedKinds: []AppManagedKind{{
+		Kind:    kind,
+		Watcher: &operator.SimpleWatcher{},
+		ReconcileOptions: BasicReconcileOptions{
+			CacheResyncInterval: -time.Hour,
+		},
+	}}})
+	assert.EqualError(t, err, "ReconcileOptions.CacheResyncInterval cannot be negative")
+}
+
+func TestApp_DynamicNamespaces(t *testing.T) {
+	kind := testKind()
+	owned := resource.Kind{
+		Schema: resource.NewSimpleSchema("foo", "v1", &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("Baz")),
+		Codecs: map[resource.KindEncoding]resource.Codec{
+			resource.KindEncodingJSON: resource.NewJSONCodec(),
+		},
+	}
+
+	t.Run("namespace and dynamic namespaces", func(t *testing.T) {
+		_, err := NewApp(AppConfig{ManagedKinds: []AppManagedKind{{
+			Kind:       kind,
+			Reconciler: &operator.SimpleReconciler{},
+			ReconcileOptions: BasicReconcileOptions{
+				Namespace:         "a",
+				DynamicNamespaces: true,
+			},
+		}}})
+		assert.EqualError(t, err, "please provide either Namespace or DynamicNamespaces in ReconcileOptions, not both")
+	})
+
+	t.Run("add and remove", func(t *testing.T) {
+		a := createTestApp(t, AppConfig{ManagedKinds: []AppManagedKind{{
+			Kind:       kind,
+			Reconciler: &operator.SimpleReconciler{},
+			ReconcileOptions: BasicReconcileOptions{
+				Namespaces:        []string{"a"},
+				DynamicNamespaces: true,
+			},
+			OwnsKinds: []resource.Kind{owned},
+		}}})
+		namespaces := func() [][]string {
+			ns := make([][]string, 0)
+			for _, inf := range a.registrations[gvk(kind.Group(), kind.Version(), kind.Kind())].namespaceInformers {
+				ns = append(ns, inf.Namespaces())
+			}
+			return ns
+		}
+		// The kind and its owned kind each have one informer for all namespaces
+		assert.Equal(t, [][]string{{"a"}, {"a"}}, namespaces())
+		require.Nil(t, a.AddNamespace(kind, "b"))
+		assert.Equal(t, [][]string{{"a", "b"}, {"a", "b"}}, namespaces())
+		require.Nil(t, a.RemoveNamespace(kind, "a"))
+		assert.Equal(t, [][]string{{"b"}, {"b"}}, namespaces())
+		assert.EqualError(t, a.AddNamespace(owned, "b"), "kind Baz/v1 is not managed by the app")
+	})
+
+	t.Run("static namespaces", func(t *testing.T) {
+		a := createTestApp(t, AppConfig{ManagedKinds: []AppManagedKind{{
+			Kind:       kind,
+			Reconciler: &operator.SimpleReconciler{},
+			ReconcileOptions: BasicReconcileOptions{
+				Namespaces: []string{"a"},
+			},
+		}}})
+		assert.EqualError(t, a.AddNamespace(kind, "b"), "kind Bar/v1 does not use ReconcileOptions.DynamicNamespaces")
+	})
+}
+
+func TestApp_InformerFactory(t *testing.T) {
+	kind := testKind()
+	factory := operator.NewSharedInformerFactory()
+	newApp := func(options BasicReconcileOptions) *App {
+		return createTestApp(t, AppConfig{
+			InformerFactory: factory,
+			ManagedKinds: []AppManagedKind{{
+				Kind:             kind,
+				Reconciler:       &operator.SimpleReconciler{},
+				ReconcileOptions: options,
+			}},
+		})
+	}
+	informers := func(a *App) []operator.Informer {
+		infs := make([]operator.Informer, 0)
+		for _, inf := range a.registrations[gvk(kind.Group(), kind.Version(), kind.Kind())].informers {
+			infs = append(infs, inf.informer)
+		}
+		return infs
+	}
+
+	first := informers(newApp(BasicReconcileOptions{Namespaces: []string{"a", "b"}}))
+	second := informers(newApp(BasicReconcileOptions{Namespace: "a"}))
+	require.Len(t, first, 2)
+	require.Len(t, second, 1)
+	for _, inf := range append(first, second...) {
+		assert.IsType(t, &operator.SharedInformer{}, inf)
+	}
+	assert.Equal(t, operator.ListWatchOptions{Namespace: "a"}, second[0].(*operator.SharedInformer).ListWatchOptions())
+
+	// Informers for a dynamic set of namespaces are not shared
+	dynamic := informers(newApp(BasicReconcileOptions{DynamicNamespaces: true}))
+	require.Len(t, dynamic, 1)
+	assert.IsType(t, &operator.KubernetesBasedInformer{}, dynamic[0])
+}
+
+func TestApp_watchKind(t *testing.T) {
+	t.Run("informer error removes the informers already added", func(t *testing.T) {
+		kind := resource.Kind{
+			Schema: resource.NewSimpleSchema("foo", "v1", &resource.UntypedObject{}, &resource.UntypedList{},
+				resource.WithKind("Baz"), resource.WithScope(resource.ClusterScope)),
+			Codecs: testKind().Codecs,
+		}
+		a := createTestApp(t, AppConfig{})
+		reg := &kindRegistration{}
+		// The informer for all namespaces is added, and then the informer for namespace 'a' is rejected, as the kind is cluster-scoped
+		err := a.watchKind(AppUnmanagedKind{
+			Kind:       kind,
+			Reconciler: &operator.SimpleReconciler{},
+			ReconcileOptions: BasicReconcileOptions{
+				Namespaces: []string{"", "a"},
+			},
+		}, reg)
+		assert.ErrorIs(t, err, operator.ErrNamespaceScopeMismatch)
+		assert.Empty(t, reg.informers)
+		_, err = a.informerController.Lister(kind.GroupVersionKind().String())
+		assert.EqualError(t, err, "no informer which supports cache reads has been added for resource kind 'foo/v1, Kind=Baz'")
+	})
+}
+
+func TestApp_DeadlineReserve(t *testing.T) {
+	kind := testKind()
+	var remaining []time.Duration
+	record := func(ctx context.Context) {
+		r, ok := resource.RemainingBudget(ctx)
+		assert.True(t, ok)
+		remaining = append(remaining, r)
+	}
+	a := createTestApp(t, AppConfig{
+		DeadlineReserve: 2 * time.Second,
+		ManagedKinds: []AppManagedKind{{
+			Kind: kind,
+			Validator: &Validator{
+				ValidateFunc: func(ctx context.Context, _ *app.AdmissionRequest) error {
+					record(ctx)
+					return nil
+				},
+			},
+			Mutator: &Mutator{
+				MutateFunc: func(ctx context.Context, _ *app.AdmissionRequest) (*app.MutatingResponse, error) {
+					record(ctx)
+					return &app.MutatingResponse{}, nil
+				},
+			},
+			CustomRoutes: AppCustomRouteHandlers{
+				AppCustomRoute{
+					Method: AppCustomRouteMethodGet,
+					Path:   "foo",
+				}: func(ctx context.Context, _ *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error) {
+					record(ctx)
+					return &app.ResourceCustomRouteResponse{}, nil
+				},
+			},
+		}},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req := &app.AdmissionRequest{
+		Action:  resource.AdmissionActionCreate,
+		Group:   kind.Group(),
+		Version: kind.Version(),
+		Kind:    kind.Kind(),
+	}
+	require.Nil(t, a.Validate(ctx, req))
+	_, err := a.Mutate(ctx, req)
+	require.Nil(t, err)
+	_, err = a.CallResourceCustomRoute(ctx, &app.ResourceCustomRouteRequest{
+		ResourceIdentifier: resource.FullIdentifier{
+			Group:   kind.Group(),
+			Version: kind.Version(),
+			Kind:    kind.Kind(),
+		},
+		SubresourcePath: "foo",
+		Method:          http.MethodGet,
+	})
+	require.Nil(t, err)
+
+	// Each handler gets the request's deadline, less the reserve
+	require.Len(t, remaining, 3)
+	for _, r := range remaining {
+		assert.InDelta(t, 8*time.Second, r, float64(time.Second))
+	}
+}
+
 func TestApp_Runner(t *testing.T) {
 	// TODO
 }
@@ -453,3 +1199,42 @@ func (c *testConverter) Convert(obj k8s.RawKind, targetAPIVersion string) ([]byt
 	}
 	return nil, nil
 }
+
+func TestApp_Watches(t *testing.T) {
+	watched := resource.Kind{
+		Schema: resource.NewSimpleSchema("foo", "v1", &resource.UntypedObject{}, &resource.UntypedList{}, resource.WithKind("Baz")),
+		Codecs: map[resource.KindEncoding]resource.Codec{
+			resource.KindEncodingJSON: resource.NewJSONCodec(),
+		},
+	}
+	mapper := operator.ObjectMapperFunc(func(context.Context, resource.Object) ([]resource.Identifier, error) {
+		return nil, nil
+	})
+
+	t.Run("no reconciler", func(t *testing.T) {
+		_, err := NewApp(AppConfig{ManagedKinds: []AppManagedKind{{
+			Kind:    testKind(),
+			Watches: []AppWatchedKind{{Kind: watched, Mapper: mapper}},
+		}}})
+		assert.Equal(t, "a reconciler is required to use Watches", err.Error())
+	})
+
+	t.Run("no mapper", func(t *testing.T) {
+		_, err := NewApp(AppConfig{ManagedKinds: []AppManagedKind{{
+			Kind:       testKind(),
+			Reconciler: &operator.SimpleReconciler{},
+			Watches:    []AppWatchedKind{{Kind: watched}},
+		}}})
+		assert.Equal(t, "watched kind Baz has no Mapper", err.Error())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		a := createTestApp(t, AppConfig{ManagedKinds: []AppManagedKind{{
+			Kind:       testKind(),
+			Reconciler: &operator.SimpleReconciler{},
+			Watches:    []AppWatchedKind{{Kind: watched, Mapper: mapper}},
+		}}})
+		_, err := a.informerController.Lister(watched.GroupVersionKind().String())
+		assert.Nil(t, err)
+	})
+}
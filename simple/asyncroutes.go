@@ -0,0 +1,442 @@
+package simple
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+const (
+	// AsyncOperationsPath is the custom route path of async operations. For each kind with AsyncCustomRoutes,
+	// a GET custom route of AsyncOperationsPath/{id} is added, which returns the AsyncOperation with the ID {id}.
+	AsyncOperationsPath = "operations"
+
+	defaultAsyncOperationTTL           = time.Hour
+	defaultAsyncOperationMaxConcurrent = 64
+	// asyncOperationHeartbeat is how often a running operation's UpdatedAt is refreshed
+	asyncOperationHeartbeat = 30 * time.Second
+	// asyncOperationStaleAfter is how long an unfinished operation can go without an update before it is
+	// considered abandoned (such as by a replica which stopped while running it)
+	asyncOperationStaleAfter = 4 * asyncOperationHeartbeat
+)
+
+// ErrAsyncOperationNotFound is returned by an AsyncOperationStore when an operation does not exist
+var ErrAsyncOperationNotFound = errors.New("async operation not found")
+
+// AsyncOperationState is the state of an AsyncOperation
+type AsyncOperationState string
+
+const (
+	AsyncOperationStatePending   AsyncOperationState = "Pending"
+	AsyncOperationStateRunning   AsyncOperationState = "Running"
+	AsyncOperationStateSucceeded AsyncOperationState = "Succeeded"
+	AsyncOperationStateFailed    AsyncOperationState = "Failed"
+)
+
+// AsyncOperation is a tracked call to an async custom route (see AppManagedKind.AsyncCustomRoutes).
+// It is returned as JSON by the AsyncOperationsPath/{id} custom route.
+type AsyncOperation struct {
+	ID string `json:"id"`
+	// Group, Version, Kind, Namespace, and Name identify the object the route was called on
+	Group     string `json:"group"`
+	Version   string `json:"version"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Method and Path are the method and subresource path of the custom route that was called
+	Method string              `json:"method"`
+	Path   string              `json:"path"`
+	State  AsyncOperationState `json:"state"`
+	// Progress is the last progress percentage (0-100) reported by the handler
+	Progress int `json:"progress"`
+	// Message is the last progress message reported by the handler
+	Message string `json:"message,omitempty"`
+	// Result is the JSON-encoded result returned by the handler, if it succeeded
+	Result json.RawMessage `json:"result,omitempty"`
+	// Error is the error returned by the handler, if it failed
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// Done returns true if the operation has succeeded or failed
+func (o *AsyncOperation) Done() bool {
+	return o.State == AsyncOperationStateSucceeded || o.State == AsyncOperationStateFailed
+}
+
+// AsyncOperationStore stores the AsyncOperations of an App.
+// An App uses an InMemoryAsyncOperationStore unless one is provided in AppConfig.AsyncOperations.
+// A store shared between replicas (such as one backed by a database) allows operations to be polled from any replica.
+type AsyncOperationStore interface {
+	// Create stores a new operation
+	Create(ctx context.Context, operation AsyncOperation) error
+	// Get returns the operation with the provided ID, or ErrAsyncOperationNotFound if it does not exist
+	Get(ctx context.Context, id string) (*AsyncOperation, error)
+	// Update replaces the stored operation with the ID of operation
+	Update(ctx context.Context, operation AsyncOperation) error
+}
+
+// InMemoryAsyncOperationStore is an AsyncOperationStore which holds operations in memory.
+// Completed operations are removed once they are older than the TTL.
+// It should be instantiated with NewInMemoryAsyncOperationStore.
+type InMemoryAsyncOperationStore struct {
+	ttl        time.Duration
+	now        func() time.Time
+	mux        sync.RWMutex
+	operations map[string]AsyncOperation
+}
+
+// NewInMemoryAsyncOperationStore returns a new InMemoryAsyncOperationStore which retains completed operations for ttl.
+// If ttl is zero or less, completed operations are retained for one hour.
+func NewInMemoryAsyncOperationStore(ttl time.Duration) *InMemoryAsyncOperationStore {
+	if ttl <= 0 {
+		ttl = defaultAsyncOperationTTL
+	}
+	return &InMemoryAsyncOperationStore{
+		ttl:        ttl,
+		now:        time.Now,
+		operations: make(map[string]AsyncOperation),
+	}
+}
+
+// Create stores a new operation, and removes any expired operations
+func (s *InMemoryAsyncOperationStore) Create(_ context.Context, operation AsyncOperation) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.prune()
+	if _, ok := s.operations[operation.ID]; ok {
+		return fmt.Errorf("async operation %s already exists", operation.ID)
+	}
+	s.operations[operation.ID] = operation
+	return nil
+}
+
+// Get returns the operation with the provided ID, or ErrAsyncOperationNotFound if it does not exist or has expired
+func (s *InMemoryAsyncOperationStore) Get(_ context.Context, id string) (*AsyncOperation, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	operation, ok := s.operations[id]
+	if !ok || s.expired(operation) {
+		return nil, ErrAsyncOperationNotFound
+	}
+	return &operation, nil
+}
+
+// Update replaces the stored operation with the ID of operation
+func (s *InMemoryAsyncOperationStore) Update(_ context.Context, operation AsyncOperation) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if _, ok := s.operations[operation.ID]; !ok {
+		return ErrAsyncOperationNotFound
+	}
+	s.operations[operation.ID] = operation
+	return nil
+}
+
+func (s *InMemoryAsyncOperationStore) expired(operation AsyncOperation) bool {
+	return operation.CompletedAt != nil && s.now().Sub(*operation.CompletedAt) > s.ttl
+}
+
+// prune removes expired operations. The caller must hold the write lock.
+func (s *InMemoryAsyncOperationStore) prune() {
+	for id, operation := range s.operations {
+		if s.expired(operation) {
+			delete(s.operations, id)
+		}
+	}
+}
+
+// AsyncProgressFunc reports the progress of an async operation, as a percentage (0-100) and a message
+type AsyncProgressFunc func(ctx context.Context, progress int, message string)
+
+// AsyncCustomRouteHandler runs the work of an async custom route. It is called in a new goroutine after the
+// route has responded, with a context which is not canceled when the request completes,
+// but is canceled when the App's runner stops.
+// The returned result is encoded as JSON in the AsyncOperation's Result. Progress can be reported with progress.
+type AsyncCustomRouteHandler func(ctx context.Context, req *app.ResourceCustomRouteRequest, progress AsyncProgressFunc) (any, error)
+
+// AsyncCustomRouteHandlers is a map of custom routes to AsyncCustomRouteHandlers
+type AsyncCustomRouteHandlers map[AppCustomRoute]AsyncCustomRouteHandler
+
+// AppAsyncOperationsConfig configures how an App runs and tracks async custom routes
+type AppAsyncOperationsConfig struct {
+	// Store stores the App's operations. If nil, an InMemoryAsyncOperationStore is used, with TTL.
+	Store AsyncOperationStore
+	// TTL is how long completed operations are retained by the default store. Defaults to one hour.
+	// It is ignored if Store is non-nil.
+	TTL time.Duration
+	// Timeout, if non-zero, is the maximum duration of each operation, after which its context is canceled.
+	Timeout time.Duration
+	// MaxConcurrent is the maximum number of operations the App runs at once.
+	// Calls to async custom routes beyond it are rejected with http.StatusTooManyRequests. Defaults to 64.
+	MaxConcurrent int
+}
+
+// asyncOperations runs async custom route handlers and serves the status of their operations.
+// It is run as part of the App's runner, and cancels and waits for all running operations when the runner stops.
+type asyncOperations struct {
+	store      AsyncOperationStore
+	timeout    time.Duration
+	now        func() time.Time
+	heartbeat  time.Duration
+	staleAfter time.Duration
+	// ctx is canceled when the runner stops, canceling all running operations
+	ctx    context.Context
+	cancel context.CancelFunc
+	// sem limits the number of running operations
+	sem chan struct{}
+	// mux guards stopped, and adding to wg
+	mux     sync.Mutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+func newAsyncOperations(cfg AppAsyncOperationsConfig) *asyncOperations {
+	store := cfg.Store
+	if store == nil {
+		store = NewInMemoryAsyncOperationStore(cfg.TTL)
+	}
+	maxConcurrent := cfg.MaxConcurrent
+	if maxConcurrent <= 0 {
+		maxConcurrent = defaultAsyncOperationMaxConcurrent
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &asyncOperations{
+		store:      store,
+		timeout:    cfg.Timeout,
+		now:        time.Now,
+		heartbeat:  asyncOperationHeartbeat,
+		staleAfter: asyncOperationStaleAfter,
+		ctx:        ctx,
+		cancel:     cancel,
+		sem:        make(chan struct{}, maxConcurrent),
+	}
+}
+
+// Run blocks until ctx is canceled, then cancels all running operations, and waits for them to record their results.
+// Calls to async custom routes after ctx is canceled are rejected with http.StatusServiceUnavailable.
+func (a *asyncOperations) Run(ctx context.Context) error {
+	<-ctx.Done()
+	a.mux.Lock()
+	a.stopped = true
+	a.mux.Unlock()
+	a.cancel()
+	a.wg.Wait()
+	return nil
+}
+
+// start reserves a slot for a new operation. It returns a non-nil response if the operation cannot be started.
+func (a *asyncOperations) start() *app.ResourceCustomRouteResponse {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	if a.stopped {
+		return errorResponse(http.StatusServiceUnavailable, "async operations are stopped")
+	}
+	select {
+	case a.sem <- struct{}{}:
+	default:
+		return errorResponse(http.StatusTooManyRequests, "too many running async operations")
+	}
+	a.wg.Add(1)
+	return nil
+}
+
+// finish releases the slot reserved by start
+func (a *asyncOperations) finish() {
+	<-a.sem
+	a.wg.Done()
+}
+
+// handler returns an AppCustomRouteHandler which creates an operation, runs handler for it in the background,
+// and responds with http.StatusAccepted, a Location header pointing to the operation, and the operation as the body.
+func (a *asyncOperations) handler(handler AsyncCustomRouteHandler) AppCustomRouteHandler {
+	return func(ctx context.Context, req *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error) {
+		if resp := a.start(); resp != nil {
+			return resp, nil
+		}
+		started := false
+		defer func() {
+			if !started {
+				a.finish()
+			}
+		}()
+		now := a.now()
+		operation := AsyncOperation{
+			ID:        string(uuid.NewUUID()),
+			Group:     req.ResourceIdentifier.Group,
+			Version:   req.ResourceIdentifier.Version,
+			Kind:      req.ResourceIdentifier.Kind,
+			Namespace: req.ResourceIdentifier.Namespace,
+			Name:      req.ResourceIdentifier.Name,
+			Method:    strings.ToUpper(req.Method),
+			Path:      req.SubresourcePath,
+			State:     AsyncOperationStatePending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := a.store.Create(ctx, operation); err != nil {
+			return nil, fmt.Errorf("unable to create async operation: %w", err)
+		}
+		body, err := json.Marshal(operation)
+		if err != nil {
+			return nil, err
+		}
+		// The operation keeps the request's values, but is canceled when the runner stops rather than when the request completes
+		opCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		stop := context.AfterFunc(a.ctx, cancel)
+		started = true
+		go func() {
+			defer a.finish()
+			defer stop()
+			defer cancel()
+			a.run(opCtx, operation, req, handler)
+		}()
+		headers := make(http.Header)
+		headers.Set("Location", operationLocation(req.SubresourcePath, operation.ID))
+		headers.Set("Content-Type", "application/json")
+		return &app.ResourceCustomRouteResponse{
+			Headers:    headers,
+			StatusCode: http.StatusAccepted,
+			Body:       body,
+		}, nil
+	}
+}
+
+// run calls handler for operation, and records its progress and result in the store
+func (a *asyncOperations) run(ctx context.Context, operation AsyncOperation, req *app.ResourceCustomRouteRequest, handler AsyncCustomRouteHandler) {
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
+	log := logging.FromContext(ctx).With("operation", operation.ID, "kind", operation.Kind,
+		"namespace", operation.Namespace, "name", operation.Name, "path", operation.Path)
+	// Updates are recorded even once ctx is canceled, so that a canceled operation is recorded as failed
+	storeCtx := context.WithoutCancel(ctx)
+	var mux sync.Mutex
+	update := func(modify func(*AsyncOperation)) {
+		mux.Lock()
+		defer mux.Unlock()
+		modify(&operation)
+		operation.UpdatedAt = a.now()
+		if err := a.store.Update(storeCtx, operation); err != nil {
+			log.Error("unable to update async operation", "error", err)
+		}
+	}
+	update(func(o *AsyncOperation) {
+		o.State = AsyncOperationStateRunning
+	})
+	// Refresh UpdatedAt while the handler runs, so that the operation isn't considered abandoned
+	heartbeatDone := make(chan struct{})
+	defer close(heartbeatDone)
+	go func() {
+		ticker := time.NewTicker(a.heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-heartbeatDone:
+				return
+			case <-ticker.C:
+				update(func(*AsyncOperation) {})
+			}
+		}
+	}()
+	result, err := func() (result any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("async custom route handler panicked: %v", r)
+			}
+		}()
+		return handler(ctx, req, func(_ context.Context, progress int, message string) {
+			update(func(o *AsyncOperation) {
+				o.Progress = min(max(progress, 0), 100)
+				o.Message = message
+			})
+		})
+	}()
+	var raw []byte
+	if err == nil && result != nil {
+		raw, err = json.Marshal(result)
+	}
+	update(func(o *AsyncOperation) {
+		completed := a.now()
+		o.CompletedAt = &completed
+		if err != nil {
+			o.State = AsyncOperationStateFailed
+			o.Error = err.Error()
+			return
+		}
+		o.State = AsyncOperationStateSucceeded
+		o.Progress = 100
+		o.Result = raw
+	})
+	if err != nil {
+		log.Error("async operation failed", "error", err)
+	}
+}
+
+// statusHandler is the AppCustomRouteHandler for AsyncOperationsPath/{id}
+func (a *asyncOperations) statusHandler(ctx context.Context, req *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error) {
+	id, _ := strings.CutPrefix(req.SubresourcePath, AsyncOperationsPath+"/")
+	operation, err := a.store.Get(ctx, id)
+	if errors.Is(err, ErrAsyncOperationNotFound) || (err == nil && !operationIsFor(operation, req.ResourceIdentifier)) {
+		return errorResponse(http.StatusNotFound, fmt.Sprintf("async operation %s not found", id)), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !operation.Done() && a.now().Sub(operation.UpdatedAt) > a.staleAfter {
+		// Nothing has updated the operation recently, so whatever was running it stopped before it completed
+		now := a.now()
+		operation.State = AsyncOperationStateFailed
+		operation.Error = "async operation was abandoned before it completed"
+		operation.UpdatedAt = now
+		operation.CompletedAt = &now
+		if err := a.store.Update(ctx, *operation); err != nil {
+			return nil, fmt.Errorf("unable to update abandoned async operation: %w", err)
+		}
+	}
+	body, err := json.Marshal(operation)
+	if err != nil {
+		return nil, err
+	}
+	headers := make(http.Header)
+	headers.Set("Content-Type", "application/json")
+	return &app.ResourceCustomRouteResponse{
+		Headers:    headers,
+		StatusCode: http.StatusOK,
+		Body:       body,
+	}, nil
+}
+
+func errorResponse(statusCode int, message string) *app.ResourceCustomRouteResponse {
+	return &app.ResourceCustomRouteResponse{
+		StatusCode: statusCode,
+		Body:       []byte(fmt.Sprintf(`{"message":%q}`, message)),
+	}
+}
+
+// operationIsFor returns true if operation was created by a call on the object with identifier.
+// The version is not compared, so an operation can be polled through any version of the kind.
+func operationIsFor(operation *AsyncOperation, identifier resource.FullIdentifier) bool {
+	return operation.Group == identifier.Group && operation.Kind == identifier.Kind &&
+		operation.Namespace == identifier.Namespace && operation.Name == identifier.Name
+}
+
+// operationLocation returns the location of the operation with the provided ID, relative to the subresource path
+// of the route which created it
+func operationLocation(subresourcePath string, id string) string {
+	return strings.Repeat("../", strings.Count(strings.Trim(subresourcePath, "/"), "/")) + AsyncOperationsPath + "/" + id
+}
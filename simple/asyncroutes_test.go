@@ -0,0 +1,284 @@
+package simple
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestApp_AsyncCustomRoutes(t *testing.T) {
+	kind := testKind()
+	id := resource.FullIdentifier{
+		Group:     kind.Group(),
+		Version:   kind.Version(),
+		Kind:      kind.Kind(),
+		Namespace: "ns",
+		Name:      "foo",
+	}
+	poll := func(t *testing.T, a *App, identifier resource.FullIdentifier, location string) (*app.ResourceCustomRouteResponse, AsyncOperation) {
+		resp, err := a.CallResourceCustomRoute(context.Background(), &app.ResourceCustomRouteRequest{
+			ResourceIdentifier: identifier,
+			SubresourcePath:    location,
+			Method:             http.MethodGet,
+		})
+		require.Nil(t, err)
+		operation := AsyncOperation{}
+		if resp.StatusCode == http.StatusOK {
+			require.Nil(t, json.Unmarshal(resp.Body, &operation))
+		}
+		return resp, operation
+	}
+
+	t.Run("success", func(t *testing.T) {
+		progressed := make(chan struct{})
+		proceed := make(chan struct{})
+		a := createTestApp(t, AppConfig{
+			ManagedKinds: []AppManagedKind{{
+				Kind: kind,
+				AsyncCustomRoutes: AsyncCustomRouteHandlers{
+					{Method: AppCustomRouteMethodPost, Path: "reindex"}: func(ctx context.Context, req *app.ResourceCustomRouteRequest, progress AsyncProgressFunc) (any, error) {
+						progress(ctx, 50, "halfway")
+						close(progressed)
+						<-proceed
+						return map[string]string{"name": req.ResourceIdentifier.Name}, nil
+					},
+				},
+			}},
+		})
+		resp, err := a.CallResourceCustomRoute(context.Background(), &app.ResourceCustomRouteRequest{
+			ResourceIdentifier: id,
+			SubresourcePath:    "reindex",
+			Method:             http.MethodPost,
+		})
+		require.Nil(t, err)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		location := resp.Headers.Get("Location")
+		require.NotEmpty(t, location)
+
+		<-progressed
+		resp, operation := poll(t, a, id, location)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, AsyncOperationStateRunning, operation.State)
+		assert.Equal(t, 50, operation.Progress)
+		assert.Equal(t, "halfway", operation.Message)
+
+		close(proceed)
+		a.asyncOperations.wg.Wait()
+		_, operation = poll(t, a, id, location)
+		assert.Equal(t, AsyncOperationStateSucceeded, operation.State)
+		assert.Equal(t, 100, operation.Progress)
+		assert.JSONEq(t, `{"name":"foo"}`, string(operation.Result))
+		assert.NotNil(t, operation.CompletedAt)
+
+		// Operations are only visible on the object they were created for
+		other := id
+		other.Name = "bar"
+		resp, _ = poll(t, a, other, location)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp, _ = poll(t, a, id, AsyncOperationsPath+"/nope")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		a := createTestApp(t, AppConfig{
+			ManagedKinds: []AppManagedKind{{
+				Kind: kind,
+				AsyncCustomRoutes: AsyncCustomRouteHandlers{
+					{Method: AppCustomRouteMethodPost, Path: "a/b"}: func(context.Context, *app.ResourceCustomRouteRequest, AsyncProgressFunc) (any, error) {
+						return nil, errors.New("I AM ERROR")
+					},
+				},
+			}},
+		})
+		resp, err := a.CallResourceCustomRoute(context.Background(), &app.ResourceCustomRouteRequest{
+			ResourceIdentifier: id,
+			SubresourcePath:    "a/b",
+			Method:             http.MethodPost,
+		})
+		require.Nil(t, err)
+		location := resp.Headers.Get("Location")
+		assert.Regexp(t, "^\\.\\./"+AsyncOperationsPath+"/", location)
+		a.asyncOperations.wg.Wait()
+		operation := AsyncOperation{}
+		require.Nil(t, json.Unmarshal(resp.Body, &operation))
+		_, operation = poll(t, a, id, AsyncOperationsPath+"/"+operation.ID)
+		assert.Equal(t, AsyncOperationStateFailed, operation.State)
+		assert.Equal(t, "I AM ERROR", operation.Error)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		proceed := make(chan struct{})
+		a := createTestApp(t, AppConfig{
+			AsyncOperations: AppAsyncOperationsConfig{MaxConcurrent: 1},
+			ManagedKinds: []AppManagedKind{{
+				Kind: kind,
+				AsyncCustomRoutes: AsyncCustomRouteHandlers{
+					{Method: AppCustomRouteMethodPost, Path: "reindex"}: func(context.Context, *app.ResourceCustomRouteRequest, AsyncProgressFunc) (any, error) {
+						<-proceed
+						return nil, nil
+					},
+				},
+			}},
+		})
+		call := func() *app.ResourceCustomRouteResponse {
+			resp, err := a.CallResourceCustomRoute(context.Background(), &app.ResourceCustomRouteRequest{
+				ResourceIdentifier: id,
+				SubresourcePath:    "reindex",
+				Method:             http.MethodPost,
+			})
+			require.Nil(t, err)
+			return resp
+		}
+		assert.Equal(t, http.StatusAccepted, call().StatusCode)
+		assert.Equal(t, http.StatusTooManyRequests, call().StatusCode)
+		close(proceed)
+		a.asyncOperations.wg.Wait()
+		assert.Equal(t, http.StatusAccepted, call().StatusCode)
+		a.asyncOperations.wg.Wait()
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		started := make(chan struct{})
+		a := createTestApp(t, AppConfig{
+			ManagedKinds: []AppManagedKind{{
+				Kind: kind,
+				AsyncCustomRoutes: AsyncCustomRouteHandlers{
+					{Method: AppCustomRouteMethodPost, Path: "reindex"}: func(ctx context.Context, _ *app.ResourceCustomRouteRequest, _ AsyncProgressFunc) (any, error) {
+						close(started)
+						<-ctx.Done()
+						return nil, ctx.Err()
+					},
+				},
+			}},
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			assert.Nil(t, a.asyncOperations.Run(ctx))
+		}()
+		req := &app.ResourceCustomRouteRequest{
+			ResourceIdentifier: id,
+			SubresourcePath:    "reindex",
+			Method:             http.MethodPost,
+		}
+		resp, err := a.CallResourceCustomRoute(context.Background(), req)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+		<-started
+		cancel()
+		select {
+		case <-stopped:
+		case <-time.After(5 * time.Second):
+			t.Fatal("async operations did not stop")
+		}
+		// The canceled operation is recorded as failed before Run returns
+		_, operation := poll(t, a, id, resp.Headers.Get("Location"))
+		assert.Equal(t, AsyncOperationStateFailed, operation.State)
+		assert.Equal(t, context.Canceled.Error(), operation.Error)
+		// New operations are rejected once stopped
+		resp, err = a.CallResourceCustomRoute(context.Background(), req)
+		require.Nil(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	})
+
+	t.Run("abandoned", func(t *testing.T) {
+		store := NewInMemoryAsyncOperationStore(0)
+		a := createTestApp(t, AppConfig{
+			AsyncOperations: AppAsyncOperationsConfig{Store: store},
+			ManagedKinds: []AppManagedKind{{
+				Kind: kind,
+				AsyncCustomRoutes: AsyncCustomRouteHandlers{
+					{Method: AppCustomRouteMethodPost, Path: "reindex"}: func(context.Context, *app.ResourceCustomRouteRequest, AsyncProgressFunc) (any, error) {
+						return nil, nil
+					},
+				},
+			}},
+		})
+		now := time.Now()
+		for opID, updated := range map[string]time.Time{"stale": now.Add(-time.Hour), "fresh": now} {
+			require.Nil(t, store.Create(context.Background(), AsyncOperation{
+				ID:        opID,
+				Group:     id.Group,
+				Version:   id.Version,
+				Kind:      id.Kind,
+				Namespace: id.Namespace,
+				Name:      id.Name,
+				State:     AsyncOperationStateRunning,
+				CreatedAt: updated,
+				UpdatedAt: updated,
+			}))
+		}
+		_, operation := poll(t, a, id, AsyncOperationsPath+"/stale")
+		assert.Equal(t, AsyncOperationStateFailed, operation.State)
+		assert.NotEmpty(t, operation.Error)
+		assert.NotNil(t, operation.CompletedAt)
+		stored, err := store.Get(context.Background(), "stale")
+		require.Nil(t, err)
+		assert.Equal(t, AsyncOperationStateFailed, stored.State)
+
+		_, operation = poll(t, a, id, AsyncOperationsPath+"/fresh")
+		assert.Equal(t, AsyncOperationStateRunning, operation.State)
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		handler := func(context.Context, *app.ResourceCustomRouteRequest, AsyncProgressFunc) (any, error) {
+			return nil, nil
+		}
+		_, err := NewApp(AppConfig{
+			ManagedKinds: []AppManagedKind{{
+				Kind: kind,
+				CustomRoutes: AppCustomRouteHandlers{
+					{Method: AppCustomRouteMethodPost, Path: "reindex"}: func(context.Context, *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error) {
+						return nil, nil
+					},
+				},
+				AsyncCustomRoutes: AsyncCustomRouteHandlers{
+					{Method: AppCustomRouteMethodPost, Path: "reindex"}: handler,
+				},
+			}},
+		})
+		assert.NotNil(t, err)
+		_, err = NewApp(AppConfig{
+			ManagedKinds: []AppManagedKind{{
+				Kind: kind,
+				AsyncCustomRoutes: AsyncCustomRouteHandlers{
+					{Method: AppCustomRouteMethodGet, Path: AsyncOperationsPath}: handler,
+				},
+			}},
+		})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestInMemoryAsyncOperationStore(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewInMemoryAsyncOperationStore(time.Minute)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.Nil(t, store.Create(ctx, AsyncOperation{ID: "a", State: AsyncOperationStatePending}))
+	assert.NotNil(t, store.Create(ctx, AsyncOperation{ID: "a"}))
+	assert.ErrorIs(t, store.Update(ctx, AsyncOperation{ID: "b"}), ErrAsyncOperationNotFound)
+
+	completed := now
+	require.Nil(t, store.Update(ctx, AsyncOperation{ID: "a", State: AsyncOperationStateSucceeded, CompletedAt: &completed}))
+	operation, err := store.Get(ctx, "a")
+	require.Nil(t, err)
+	assert.True(t, operation.Done())
+
+	now = now.Add(2 * time.Minute)
+	_, err = store.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrAsyncOperationNotFound)
+	require.Nil(t, store.Create(ctx, AsyncOperation{ID: "c"}))
+	assert.NotContains(t, store.operations, "a")
+}
@@ -0,0 +1,31 @@
+package simple
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana-app-sdk/app"
+)
+
+// FormDescriptorPath is the custom route path, called with GET, which returns the form descriptor of a kind
+// (see AppManagedKind.FormDescriptor)
+const FormDescriptorPath = "form"
+
+// formDescriptorHandler returns an AppCustomRouteHandler which responds with the JSON-encoded descriptor
+func formDescriptorHandler(descriptor *app.FormDescriptor) (AppCustomRouteHandler, error) {
+	body, err := json.Marshal(descriptor)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode form descriptor: %w", err)
+	}
+	return func(context.Context, *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error) {
+		headers := make(http.Header)
+		headers.Set("Content-Type", "application/json")
+		return &app.ResourceCustomRouteResponse{
+			Headers:    headers,
+			StatusCode: http.StatusOK,
+			Body:       body,
+		}, nil
+	}, nil
+}
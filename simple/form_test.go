@@ -0,0 +1,70 @@
+package simple
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+func TestApp_FormDescriptor(t *testing.T) {
+	kind := testKind()
+	descriptor := &app.FormDescriptor{
+		Group:   kind.Group(),
+		Version: kind.Version(),
+		Kind:    kind.Kind(),
+		Title:   kind.Kind(),
+		Fields: []app.FormField{{
+			Path:  "spec.name",
+			Name:  "name",
+			Title: "Name",
+			Type:  app.FormFieldTypeString,
+		}},
+	}
+
+	t.Run("serves descriptor", func(t *testing.T) {
+		a := createTestApp(t, AppConfig{
+			ManagedKinds: []AppManagedKind{{
+				Kind:           kind,
+				FormDescriptor: descriptor,
+			}},
+		})
+		resp, err := a.CallResourceCustomRoute(context.Background(), &app.ResourceCustomRouteRequest{
+			ResourceIdentifier: resource.FullIdentifier{
+				Group:     kind.Group(),
+				Version:   kind.Version(),
+				Kind:      kind.Kind(),
+				Namespace: "ns",
+				Name:      "foo",
+			},
+			SubresourcePath: FormDescriptorPath,
+			Method:          http.MethodGet,
+		})
+		require.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		got := &app.FormDescriptor{}
+		require.Nil(t, json.Unmarshal(resp.Body, got))
+		assert.Equal(t, descriptor, got)
+	})
+
+	t.Run("conflicting route", func(t *testing.T) {
+		_, err := NewApp(AppConfig{
+			ManagedKinds: []AppManagedKind{{
+				Kind:           kind,
+				FormDescriptor: descriptor,
+				CustomRoutes: AppCustomRouteHandlers{
+					{Method: AppCustomRouteMethodGet, Path: FormDescriptorPath}: func(context.Context, *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error) {
+						return nil, nil
+					},
+				},
+			}},
+		})
+		assert.NotNil(t, err)
+	})
+}
@@ -3,6 +3,7 @@ package simple
 import (
 	"context"
 	"fmt"
+	"net"
 	"strings"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 
+	"github.com/grafana/grafana-app-sdk/app"
 	"github.com/grafana/grafana-app-sdk/k8s"
 	"github.com/grafana/grafana-app-sdk/metrics"
 	"github.com/grafana/grafana-app-sdk/operator"
@@ -18,11 +20,17 @@ import (
 
 // OperatorConfig is used to configure an Operator on creation
 type OperatorConfig struct {
-	Name         string
-	KubeConfig   rest.Config
-	Webhooks     WebhookConfig
-	Metrics      MetricsConfig
-	Tracing      TracingConfig
+	Name       string
+	KubeConfig rest.Config
+	Webhooks   WebhookConfig
+	Metrics    MetricsConfig
+	Tracing    TracingConfig
+	// ErrorReporter, if non-nil, is sent a report of each recoverable error encountered in underlying components.
+	ErrorReporter app.ErrorReporter
+	// ErrorHandler, if non-nil, is called with each recoverable error encountered in underlying components
+	// if ErrorReporter is nil.
+	//
+	// Deprecated: use ErrorReporter.
 	ErrorHandler func(ctx context.Context, err error)
 	// FinalizerGenerator consumes a schema and returns a finalizer name to use for opinionated logic.
 	// the finalizer name MUST be 63 chars or fewer, and should be unique to the operator
@@ -38,8 +46,11 @@ type OperatorConfig struct {
 // WebhookConfig is a configuration for exposed kubernetes webhooks for an Operator
 type WebhookConfig struct {
 	Enabled bool
-	// Port is the port to open the webhook server on
+	// Port is the port to open the webhook server on. It is ignored if Listener is non-nil.
 	Port int
+	// Listener, if non-nil, is the listener for the webhook server to accept connections on instead of opening Port,
+	// such as a Unix socket, or a socket passed by systemd (see operator.SystemdListeners).
+	Listener net.Listener
 	// TLSConfig is the TLS Cert and Key to use for the HTTPS endpoints exposed for webhooks
 	TLSConfig k8s.TLSConfig
 	// DefaultValidator is an optional Default ValidatingAdmissionController to use if a specific one for the incoming
@@ -81,6 +92,7 @@ func NewOperator(cfg OperatorConfig) (*Operator, error) {
 		var err error
 		ws, err = k8s.NewWebhookServer(k8s.WebhookServerConfig{
 			Port:                        cfg.Webhooks.Port,
+			Listener:                    cfg.Webhooks.Listener,
 			TLSConfig:                   cfg.Webhooks.TLSConfig,
 			DefaultValidatingController: cfg.Webhooks.DefaultValidator,
 			DefaultMutatingController:   cfg.Webhooks.DefaultMutator,
@@ -128,6 +140,7 @@ func NewOperator(cfg OperatorConfig) (*Operator, error) {
 
 	op := &Operator{
 		Name:                cfg.Name,
+		ErrorReporter:       cfg.ErrorReporter,
 		ErrorHandler:        cfg.ErrorHandler,
 		FinalizerGenerator:  cfg.FinalizerGenerator,
 		clientGen:           cg,
@@ -137,7 +150,8 @@ func NewOperator(cfg OperatorConfig) (*Operator, error) {
 		cacheResyncInterval: cfg.InformerCacheResyncInterval,
 		patcher:             patcher,
 	}
-	op.controller.ErrorHandler = op.ErrorHandler
+	op.controller.ErrorReporter = op.ErrorReporter
+	op.controller.ErrorHandler = op.ErrorHandler //nolint:staticcheck
 	return op, nil
 }
 
@@ -148,8 +162,13 @@ func NewOperator(cfg OperatorConfig) (*Operator, error) {
 // Deprecated: use simple.App in conjunction with operator.Runner instead.
 type Operator struct {
 	Name string
-	// ErrorHandler, if non-nil, is called when a recoverable error is encountered in underlying components.
+	// ErrorReporter, if non-nil, is sent a report of each recoverable error encountered in underlying components.
 	// This is typically used for logging and/or metrics.
+	ErrorReporter app.ErrorReporter
+	// ErrorHandler, if non-nil, is called when a recoverable error is encountered in underlying components,
+	// if ErrorReporter is nil.
+	//
+	// Deprecated: use ErrorReporter.
 	ErrorHandler func(ctx context.Context, err error)
 	// FinalizerGenerator consumes a schema and returns a finalizer name to use for opinionated logic.
 	// the finalizer name MUST be 63 chars or fewer, and should be unique to the operator
@@ -214,7 +233,8 @@ func (o *Operator) WatchKind(kind resource.Kind, watcher SyncWatcher, options op
 	if err != nil {
 		return err
 	}
-	inf.ErrorHandler = o.ErrorHandler
+	inf.ErrorReporter = o.ErrorReporter
+	inf.ErrorHandler = o.ErrorHandler //nolint:staticcheck
 	kindStr := o.label(kind, options)
 	err = o.controller.AddInformer(inf, kindStr)
 	if err != nil {
@@ -251,7 +271,8 @@ func (o *Operator) ReconcileKind(kind resource.Kind, reconciler operator.Reconci
 	if err != nil {
 		return err
 	}
-	inf.ErrorHandler = o.ErrorHandler
+	inf.ErrorReporter = o.ErrorReporter
+	inf.ErrorHandler = o.ErrorHandler //nolint:staticcheck
 	kindStr := o.label(kind, options)
 	err = o.controller.AddInformer(inf, kindStr)
 	if err != nil {
@@ -0,0 +1,238 @@
+package simple
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/grafana/grafana-app-sdk/app"
+	"github.com/grafana/grafana-app-sdk/logging"
+	"github.com/grafana/grafana-app-sdk/operator"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+const (
+	// SoftDeleteLabel is the label set to "true" on tombstone copies of soft-deleted objects
+	SoftDeleteLabel = "grafana.com/soft-deleted"
+	// SoftDeleteOriginalNameAnnotation is the annotation on a tombstone which contains the name of the deleted object
+	SoftDeleteOriginalNameAnnotation = "grafana.com/soft-deleted-name"
+	// SoftDeleteTimestampAnnotation is the annotation on a tombstone which contains the RFC3339 time the object was deleted
+	SoftDeleteTimestampAnnotation = "grafana.com/soft-deleted-at"
+	// SoftDeleteExcludeLabelFilter is a label filter which excludes tombstones. It is added to the ListWatch requests
+	// for kinds with soft-delete enabled, and should be used by other clients which wish to hide tombstones when listing.
+	SoftDeleteExcludeLabelFilter = "!" + SoftDeleteLabel
+	// SoftDeleteRestorePath is the custom route path, called with POST on a tombstone, which restores the deleted object
+	SoftDeleteRestorePath = "restore"
+
+	defaultSoftDeleteTTL = 7 * 24 * time.Hour
+	// tombstoneHashLength is the number of hex characters of the hashed object UID used in tombstone names
+	tombstoneHashLength = 12
+)
+
+// SoftDeleteOptions are the options for soft-delete behavior of an AppManagedKind.
+// When enabled, deleted objects are copied into a tombstone (an object of the same kind labeled with SoftDeleteLabel)
+// before deletion completes, and can be restored by calling the SoftDeleteRestorePath custom route on the tombstone
+// until the tombstone's TTL expires. Tombstones are excluded from the kind's Watcher or Reconciler.
+type SoftDeleteOptions struct {
+	Enabled bool
+	// TTL is how long a tombstone is retained before it is permanently deleted. Defaults to 7 days.
+	TTL time.Duration
+	// PruneInterval is the interval at which expired tombstones are deleted. Defaults to one hour.
+	PruneInterval time.Duration
+}
+
+// softDeleter handles tombstoning, restoring, and pruning for a single kind with soft-delete enabled
+type softDeleter struct {
+	kind          resource.Kind
+	client        resource.Client
+	ttl           time.Duration
+	pruneInterval time.Duration
+	now           func() time.Time
+}
+
+func newSoftDeleter(kind resource.Kind, client resource.Client, opts SoftDeleteOptions) *softDeleter {
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = defaultSoftDeleteTTL
+	}
+	pruneInterval := opts.PruneInterval
+	if pruneInterval <= 0 {
+		pruneInterval = time.Hour
+	}
+	return &softDeleter{
+		kind:          kind,
+		client:        client,
+		ttl:           ttl,
+		pruneInterval: pruneInterval,
+		now:           time.Now,
+	}
+}
+
+// wrap returns a Reconciler which creates a tombstone for deleted objects before delegating to reconciler (which may be nil).
+// It relies on being wrapped by an operator.OpinionatedReconciler, which holds deletion with a finalizer until it succeeds.
+func (s *softDeleter) wrap(reconciler operator.Reconciler) operator.Reconciler {
+	return &operator.SimpleReconciler{
+		ReconcileFunc: func(ctx context.Context, req operator.ReconcileRequest) (operator.ReconcileResult, error) {
+			if req.Action == operator.ReconcileActionDeleted && req.Object.GetLabels()[SoftDeleteLabel] != "true" {
+				if err := s.tombstone(ctx, req.Object); err != nil {
+					return operator.ReconcileResult{}, err
+				}
+			}
+			if reconciler == nil {
+				return operator.ReconcileResult{}, nil
+			}
+			return reconciler.Reconcile(ctx, req)
+		},
+	}
+}
+
+// tombstone creates a tombstone copy of obj. If the tombstone already exists (because a previous attempt
+// created it, but the reconcile failed afterwards), it is left as-is.
+func (s *softDeleter) tombstone(ctx context.Context, obj resource.Object) error {
+	now := s.now()
+	ts := obj.Copy()
+	ts.SetName(tombstoneName(obj))
+	ts.SetResourceVersion("")
+	ts.SetUID("")
+	ts.SetDeletionTimestamp(nil)
+	ts.SetFinalizers(nil)
+	ts.SetOwnerReferences(nil)
+	labels := ts.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[SoftDeleteLabel] = "true"
+	ts.SetLabels(labels)
+	annotations := ts.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[SoftDeleteOriginalNameAnnotation] = obj.GetName()
+	annotations[SoftDeleteTimestampAnnotation] = now.UTC().Format(time.RFC3339)
+	ts.SetAnnotations(annotations)
+	_, err := s.client.Create(ctx, resource.Identifier{Namespace: ts.GetNamespace(), Name: ts.GetName()}, ts, resource.CreateOptions{})
+	if err != nil && !isAlreadyExists(err) {
+		return fmt.Errorf("unable to create tombstone for %s: %w", obj.GetName(), err)
+	}
+	logging.FromContext(ctx).Debug("created tombstone for soft-deleted object", "kind", s.kind.Kind(),
+		"namespace", obj.GetNamespace(), "name", obj.GetName(), "tombstone", ts.GetName())
+	return nil
+}
+
+// tombstoneName returns the name of the tombstone for obj, which is derived from its UID, so that retries use the same name,
+// while a new object with the same name gets a different tombstone. obj's name is truncated as required
+// for the tombstone name to fit within the maximum length of an object name.
+func tombstoneName(obj resource.Object) string {
+	id := string(obj.GetUID())
+	if id == "" {
+		id = obj.GetNamespace() + "/" + obj.GetName()
+	}
+	sum := sha256.Sum256([]byte(id))
+	suffix := "-deleted-" + hex.EncodeToString(sum[:])[:tombstoneHashLength]
+	name := obj.GetName()
+	if maxLength := validation.DNS1123SubdomainMaxLength - len(suffix); len(name) > maxLength {
+		name = strings.TrimRight(name[:maxLength], "-.")
+	}
+	return name + suffix
+}
+
+// isAlreadyExists returns true if err is an AlreadyExists error, or an APIServerResponseError with a 409 status code,
+// which is what the API server returns for a create of an object which already exists
+func isAlreadyExists(err error) bool {
+	return apierrors.IsAlreadyExists(err) || resource.IsConflict(err)
+}
+
+// restore re-creates the original object from the tombstone with the provided identifier, then deletes the tombstone
+func (s *softDeleter) restore(ctx context.Context, identifier resource.Identifier) (resource.Object, error) {
+	ts, err := s.client.Get(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+	if ts.GetLabels()[SoftDeleteLabel] != "true" {
+		return nil, fmt.Errorf("%s is not a soft-deleted object", identifier.Name)
+	}
+	obj := ts.Copy()
+	obj.SetName(ts.GetAnnotations()[SoftDeleteOriginalNameAnnotation])
+	obj.SetResourceVersion("")
+	obj.SetUID("")
+	labels := obj.GetLabels()
+	delete(labels, SoftDeleteLabel)
+	obj.SetLabels(labels)
+	annotations := obj.GetAnnotations()
+	delete(annotations, SoftDeleteOriginalNameAnnotation)
+	delete(annotations, SoftDeleteTimestampAnnotation)
+	obj.SetAnnotations(annotations)
+	restored, err := s.client.Create(ctx, resource.Identifier{Namespace: obj.GetNamespace(), Name: obj.GetName()}, obj, resource.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to restore %s: %w", obj.GetName(), err)
+	}
+	err = s.client.Delete(ctx, identifier, resource.DeleteOptions{})
+	if err != nil {
+		return restored, fmt.Errorf("restored %s, but unable to delete tombstone %s: %w", obj.GetName(), identifier.Name, err)
+	}
+	return restored, nil
+}
+
+// prune deletes all tombstones which are older than the TTL
+func (s *softDeleter) prune(ctx context.Context) error {
+	list, err := s.client.List(ctx, resource.NamespaceAll, resource.ListOptions{
+		LabelFilters: []string{SoftDeleteLabel + "=true"},
+	})
+	if err != nil {
+		return err
+	}
+	for _, ts := range list.GetItems() {
+		deletedAt, err := time.Parse(time.RFC3339, ts.GetAnnotations()[SoftDeleteTimestampAnnotation])
+		if err != nil || s.now().Sub(deletedAt) < s.ttl {
+			continue
+		}
+		err = s.client.Delete(ctx, resource.Identifier{Namespace: ts.GetNamespace(), Name: ts.GetName()}, resource.DeleteOptions{})
+		if err != nil {
+			return fmt.Errorf("unable to delete expired tombstone %s: %w", ts.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// Run prunes expired tombstones every pruneInterval until ctx is canceled
+func (s *softDeleter) Run(ctx context.Context) error {
+	t := time.NewTicker(s.pruneInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if err := s.prune(ctx); err != nil {
+				logging.FromContext(ctx).Error("error pruning soft-deleted objects", "kind", s.kind.Kind(), "error", err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// restoreHandler is the AppCustomRouteHandler for SoftDeleteRestorePath
+func (s *softDeleter) restoreHandler(ctx context.Context, req *app.ResourceCustomRouteRequest) (*app.ResourceCustomRouteResponse, error) {
+	restored, err := s.restore(ctx, resource.Identifier{
+		Namespace: req.ResourceIdentifier.Namespace,
+		Name:      req.ResourceIdentifier.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(restored)
+	if err != nil {
+		return nil, err
+	}
+	return &app.ResourceCustomRouteResponse{
+		StatusCode: http.StatusOK,
+		Body:       body,
+	}, nil
+}
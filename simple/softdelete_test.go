@@ -0,0 +1,175 @@
+package simple
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/grafana/grafana-app-sdk/operator"
+	"github.com/grafana/grafana-app-sdk/resource"
+	"github.com/grafana/grafana-app-sdk/resource/fake"
+)
+
+func TestSoftDeleter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newObj := func() *resource.UntypedObject {
+		obj := &resource.UntypedObject{}
+		obj.SetName("foo")
+		obj.SetUID("fa9b1c1e-5d4e-4d2b-9f5e-1a6c0e0b7f21")
+		obj.SetNamespace("ns")
+		obj.SetResourceVersion("12")
+		obj.SetFinalizers([]string{"finalizer"})
+		obj.SetLabels(map[string]string{"a": "b"})
+		obj.Spec = map[string]any{"foo": "bar"}
+		return obj
+	}
+
+	t.Run("tombstone and restore", func(t *testing.T) {
+		client := newTestSoftDeleteClient(t)
+		sd := newSoftDeleter(testKind(), client, SoftDeleteOptions{Enabled: true})
+		sd.now = func() time.Time { return now }
+		called := false
+		reconciler := sd.wrap(&operator.SimpleReconciler{
+			ReconcileFunc: func(context.Context, operator.ReconcileRequest) (operator.ReconcileResult, error) {
+				called = true
+				return operator.ReconcileResult{}, nil
+			},
+		})
+		obj := newObj()
+		obj.SetDeletionTimestamp(&metav1.Time{Time: now})
+		_, err := reconciler.Reconcile(context.Background(), operator.ReconcileRequest{
+			Action: operator.ReconcileActionDeleted,
+			Object: obj,
+		})
+		require.Nil(t, err)
+		assert.True(t, called)
+
+		tombstoneID := resource.Identifier{Namespace: "ns", Name: tombstoneName(obj)}
+		tombstone, err := client.Get(context.Background(), tombstoneID)
+		require.Nil(t, err)
+		assert.Equal(t, map[string]string{"a": "b", SoftDeleteLabel: "true"}, tombstone.GetLabels())
+		assert.Equal(t, "foo", tombstone.GetAnnotations()[SoftDeleteOriginalNameAnnotation])
+		// The tombstone is created without the resource version of the deleted object
+		actions := client.Actions()
+		require.Equal(t, fake.VerbCreate, actions[0].Verb)
+		assert.Empty(t, actions[0].Object.GetResourceVersion())
+		assert.Empty(t, tombstone.GetFinalizers())
+		assert.Nil(t, tombstone.GetDeletionTimestamp())
+
+		// A retry after a downstream failure reuses the existing tombstone
+		sd.now = func() time.Time { return now.Add(time.Minute) }
+		_, err = reconciler.Reconcile(context.Background(), operator.ReconcileRequest{
+			Action: operator.ReconcileActionDeleted,
+			Object: obj,
+		})
+		require.Nil(t, err)
+		assert.Equal(t, []string{tombstoneID.Name}, listTestSoftDeleteNames(t, client))
+		tombstone, err = client.Get(context.Background(), tombstoneID)
+		require.Nil(t, err)
+		assert.Equal(t, now.Format(time.RFC3339), tombstone.GetAnnotations()[SoftDeleteTimestampAnnotation])
+
+		restored, err := sd.restore(context.Background(), tombstoneID)
+		require.Nil(t, err)
+		assert.Equal(t, "foo", restored.GetName())
+		assert.Equal(t, map[string]string{"a": "b"}, restored.GetLabels())
+		assert.Empty(t, restored.GetAnnotations())
+		assert.Equal(t, obj.Spec, restored.GetSpec())
+		_, err = client.Get(context.Background(), tombstoneID)
+		assert.True(t, resource.IsNotFound(err))
+	})
+
+	t.Run("restore non-tombstone", func(t *testing.T) {
+		obj := newObj()
+		client := newTestSoftDeleteClient(t, obj)
+		sd := newSoftDeleter(testKind(), client, SoftDeleteOptions{Enabled: true})
+		_, err := sd.restore(context.Background(), resource.Identifier{Namespace: "ns", Name: "foo"})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		client := newTestSoftDeleteClient(t)
+		sd := newSoftDeleter(testKind(), client, SoftDeleteOptions{Enabled: true, TTL: time.Hour})
+		sd.now = func() time.Time { return now }
+		require.Nil(t, sd.tombstone(context.Background(), newObj()))
+		sd.now = func() time.Time { return now.Add(time.Minute) }
+		// An object re-created with the same name has a new UID, so it gets a new tombstone
+		recreated := newObj()
+		recreated.SetUID("0c3e8f4a-2b1d-4f6e-8a9c-7d5b3e1f0a42")
+		require.Nil(t, sd.tombstone(context.Background(), recreated))
+		require.Len(t, listTestSoftDeleteNames(t, client), 2)
+
+		sd.now = func() time.Time { return now.Add(time.Hour + time.Second) }
+		require.Nil(t, sd.prune(context.Background()))
+		assert.Equal(t, []string{tombstoneName(recreated)}, listTestSoftDeleteNames(t, client))
+	})
+
+	t.Run("tombstone name", func(t *testing.T) {
+		obj := newObj()
+		name := tombstoneName(obj)
+		assert.Regexp(t, "^foo-deleted-[0-9a-f]{12}$", name)
+		assert.Equal(t, name, tombstoneName(newObj()))
+
+		obj.SetName(strings.Repeat("a", validation.DNS1123SubdomainMaxLength))
+		name = tombstoneName(obj)
+		assert.Len(t, name, validation.DNS1123SubdomainMaxLength)
+		assert.Empty(t, validation.IsDNS1123Subdomain(name))
+	})
+}
+
+func TestApp_SoftDelete(t *testing.T) {
+	t.Run("watcher", func(t *testing.T) {
+		_, err := NewApp(AppConfig{
+			ManagedKinds: []AppManagedKind{{
+				Kind:       testKind(),
+				Watcher:    &Watcher{},
+				SoftDelete: SoftDeleteOptions{Enabled: true},
+			}},
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("adds restore route", func(t *testing.T) {
+		a := createTestApp(t, AppConfig{
+			ManagedKinds: []AppManagedKind{{
+				Kind:       testKind(),
+				SoftDelete: SoftDeleteOptions{Enabled: true},
+			}},
+		})
+		assert.Contains(t, a.customRoutes, a.customRouteHandlerKey(testKind(), "POST", SoftDeleteRestorePath))
+	})
+}
+
+// newTestSoftDeleteClient returns a fake.Client for testKind, seeded with copies of objects
+func newTestSoftDeleteClient(t *testing.T, objects ...resource.Object) *fake.Client {
+	t.Helper()
+	kind := testKind()
+	seed := make([]resource.Object, 0, len(objects))
+	for _, obj := range objects {
+		cpy := obj.Copy()
+		cpy.GetObjectKind().SetGroupVersionKind(kind.GroupVersionKind())
+		seed = append(seed, cpy)
+	}
+	tracker, err := fake.NewTracker(seed...)
+	require.Nil(t, err)
+	client, err := fake.NewClient(kind, tracker)
+	require.Nil(t, err)
+	return client
+}
+
+// listTestSoftDeleteNames returns the names of all objects in client
+func listTestSoftDeleteNames(t *testing.T, client *fake.Client) []string {
+	t.Helper()
+	list, err := client.List(context.Background(), resource.NamespaceAll, resource.ListOptions{})
+	require.Nil(t, err)
+	names := make([]string, 0, len(list.GetItems()))
+	for _, item := range list.GetItems() {
+		names = append(names, item.GetName())
+	}
+	return names
+}
@@ -13,6 +13,8 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/grafana/grafana-app-sdk/metrics"
 )
 
 type OTelConnType string
@@ -27,6 +29,9 @@ type OpenTelemetryConfig struct {
 	Port        int
 	ConnType    OTelConnType
 	ServiceName string
+	// SampleRatio is the fraction of traces to sample, between 0 and 1. Exemplars linking metrics to traces
+	// are only attached for sampled traces. If zero (or out of range), all traces are sampled.
+	SampleRatio float64
 }
 
 // SetTraceProvider creates a trace.TracerProvider and sets it as the global TracerProvider which is used by
@@ -75,9 +80,30 @@ func SetTraceProvider(cfg OpenTelemetryConfig) error {
 		return err
 	}
 
+	sampler := trace.AlwaysSample()
+	if cfg.SampleRatio > 0 && cfg.SampleRatio < 1 {
+		sampler = trace.TraceIDRatioBased(cfg.SampleRatio)
+	}
+
 	otel.SetTracerProvider(trace.NewTracerProvider(
 		trace.WithBatcher(exp),
 		trace.WithResource(r),
+		trace.WithSampler(trace.ParentBased(sampler)),
 	))
 	return nil
 }
+
+// NewOTLPMetricsExporter returns a metrics.OTLPExporter which pushes metrics to the OpenTelemetry collector described by cfg,
+// for use in operator.RunnerMetricsConfig.PushExporters. As with SetTraceProvider, the connection doesn't use TLS.
+func NewOTLPMetricsExporter(cfg OpenTelemetryConfig) (*metrics.OTLPExporter, error) {
+	protocol := metrics.OTLPProtocolGRPC
+	if cfg.ConnType == OTelConnTypeHTTP {
+		protocol = metrics.OTLPProtocolHTTP
+	}
+	return metrics.NewOTLPExporter(metrics.OTLPExporterConfig{
+		Endpoint:    fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Protocol:    protocol,
+		Insecure:    true,
+		ServiceName: cfg.ServiceName,
+	})
+}
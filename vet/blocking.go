@@ -0,0 +1,56 @@
+package vet
+
+import (
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// BlockingAnalyzer reports sleeps in watchers and reconcilers.
+// A sleeping watcher or reconciler holds up every other event for the same object, and ignores context cancellation,
+// so it should instead ask to be called again later.
+var BlockingAnalyzer = &analysis.Analyzer{
+	Name: "appsdkblocking",
+	Doc: `report sleeps in watchers and reconcilers
+
+Watchers and reconcilers which call time.Sleep, or wait on time.After outside a select,
+block the processing of other events for the object, and are not stopped when their context is canceled.
+Reconcilers should return a ReconcileResult with RequeueAfter, and watchers should return an error
+to have the event retried.`,
+	Run: runBlocking,
+}
+
+func runBlocking(pass *analysis.Pass) (any, error) {
+	for _, h := range findHandlers(pass) {
+		advice := "return an error to have the event retried"
+		if h.reconciler {
+			advice = "return a ReconcileResult with RequeueAfter to be called again later"
+		}
+		var inspect func(ast.Node) bool
+		inspect = func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.CommClause:
+				// Waits in a select can be interrupted by its other cases, such as <-ctx.Done()
+				for _, stmt := range node.Body {
+					ast.Inspect(stmt, inspect)
+				}
+				return false
+			case *ast.FuncLit:
+				return false
+			case *ast.CallExpr:
+				if isFunc(pass, node, "time", "Sleep") {
+					pass.Reportf(node.Pos(), "time.Sleep blocks the %s; %s", h, advice)
+				}
+			case *ast.UnaryExpr:
+				if call, ok := node.X.(*ast.CallExpr); ok && node.Op == token.ARROW &&
+					(isFunc(pass, call, "time", "After") || isFunc(pass, call, "time", "Tick")) {
+					pass.Reportf(node.Pos(), "waiting on a timer outside a select blocks the %s, and ignores its context; %s", h, advice)
+				}
+			}
+			return true
+		}
+		ast.Inspect(h.body, inspect)
+	}
+	return nil, nil
+}
@@ -0,0 +1,160 @@
+package vet
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// CacheMutationAnalyzer reports watchers and reconcilers which modify the objects they are passed.
+// Objects from an informer are shared with its cache, so modifying them changes what other watchers, reconcilers,
+// and listers see for the object, until it is next updated.
+var CacheMutationAnalyzer = &analysis.Analyzer{
+	Name: "appsdkcachemutation",
+	Doc: `report watchers and reconcilers which modify the objects they are passed
+
+The objects passed to watchers and reconcilers by an informer are shared with the informer's cache,
+and must not be modified. To change an object, modify a copy from its Copy method.
+This reports calls to Set methods on, and assignments to fields of, the objects passed to
+watchers and the Object of ReconcileRequests, unless the variable is first replaced (such as with obj = obj.Copy()).`,
+	Run: runCacheMutation,
+}
+
+func runCacheMutation(pass *analysis.Pass) (any, error) {
+	for _, h := range findHandlers(pass) {
+		m := newCachedObjects(pass, h)
+		inspectBody(h.body, true, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.AssignStmt:
+				if node.Tok == token.DEFINE {
+					return true
+				}
+				for _, lhs := range node.Lhs {
+					m.checkAssign(lhs)
+				}
+			case *ast.IncDecStmt:
+				m.checkAssign(node.X)
+			case *ast.CallExpr:
+				sel, ok := node.Fun.(*ast.SelectorExpr)
+				if ok && strings.HasPrefix(sel.Sel.Name, "Set") && m.isCached(sel.X) {
+					m.report(node, sel.X)
+				}
+			}
+			return true
+		})
+	}
+	return nil, nil
+}
+
+// cachedObjects tracks the variables of a handler which refer to objects from the informer cache
+type cachedObjects struct {
+	pass    *analysis.Pass
+	handler handler
+	vars    map[types.Object]bool
+	// request is true if the Object of the handler's ReconcileRequest is from the cache
+	request bool
+}
+
+func newCachedObjects(pass *analysis.Pass, h handler) *cachedObjects {
+	m := &cachedObjects{
+		pass:    pass,
+		handler: h,
+		vars:    make(map[types.Object]bool),
+		request: h.request != nil,
+	}
+	for _, obj := range h.objects {
+		m.vars[obj] = true
+	}
+	// Track variables declared with a cached object, such as foo := obj.(*v1.Foo)
+	inspectBody(h.body, true, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || assign.Tok != token.DEFINE || len(assign.Rhs) != 1 || !m.isCached(assign.Rhs[0]) {
+			return true
+		}
+		if ident, ok := assign.Lhs[0].(*ast.Ident); ok && pass.TypesInfo.Defs[ident] != nil {
+			m.vars[pass.TypesInfo.Defs[ident]] = true
+		}
+		return true
+	})
+	// Stop tracking variables which are replaced, such as with obj = obj.Copy()
+	inspectBody(h.body, true, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || assign.Tok != token.ASSIGN {
+			return true
+		}
+		for _, lhs := range assign.Lhs {
+			switch e := ast.Unparen(lhs).(type) {
+			case *ast.Ident:
+				obj := pass.TypesInfo.Uses[e]
+				delete(m.vars, obj)
+				if h.request != nil && obj == h.request {
+					m.request = false
+				}
+			case *ast.SelectorExpr:
+				if m.isRequestObject(e) {
+					m.request = false
+				}
+			}
+		}
+		return true
+	})
+	return m
+}
+
+// isCached returns true if expr is a cached object: a tracked variable, the Object of the ReconcileRequest,
+// or a type assertion of either
+func (m *cachedObjects) isCached(expr ast.Expr) bool {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return m.vars[m.pass.TypesInfo.Uses[e]]
+	case *ast.SelectorExpr:
+		return m.request && m.isRequestObject(e)
+	case *ast.TypeAssertExpr:
+		return m.isCached(e.X)
+	}
+	return false
+}
+
+// isRequestObject returns true if sel is the Object field of the handler's ReconcileRequest
+func (m *cachedObjects) isRequestObject(sel *ast.SelectorExpr) bool {
+	ident, ok := ast.Unparen(sel.X).(*ast.Ident)
+	return ok && m.handler.request != nil && m.pass.TypesInfo.Uses[ident] == m.handler.request && sel.Sel.Name == "Object"
+}
+
+// checkAssign reports lhs if it is a field, element, or map value of a cached object,
+// including the maps returned by getters, such as obj.GetLabels()["foo"]
+func (m *cachedObjects) checkAssign(lhs ast.Expr) {
+	expr := ast.Unparen(lhs)
+	for {
+		switch e := expr.(type) {
+		case *ast.SelectorExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.CallExpr:
+			sel, ok := e.Fun.(*ast.SelectorExpr)
+			if !ok || !strings.HasPrefix(sel.Sel.Name, "Get") {
+				return
+			}
+			expr = sel.X
+		default:
+			return
+		}
+		if m.isCached(expr) {
+			m.report(lhs, expr)
+			return
+		}
+	}
+}
+
+func (m *cachedObjects) report(node ast.Node, object ast.Expr) {
+	m.pass.Reportf(node.Pos(), "%s is shared with the informer cache and must not be modified by the %s; "+
+		"modify a copy from %s.Copy() instead", types.ExprString(object), m.handler, types.ExprString(object))
+}
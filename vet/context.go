@@ -0,0 +1,62 @@
+package vet
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// ContextAnalyzer reports watchers and reconcilers which don't use the context they are passed.
+// The context is canceled when the operation times out or the app stops, and carries the logger, tracing span,
+// and request ID for the event, so calls made by a watcher or reconciler should use it.
+var ContextAnalyzer = &analysis.Analyzer{
+	Name: "appsdkcontext",
+	Doc: `report watchers and reconcilers which ignore their context
+
+Watchers and reconcilers should pass the context they are called with to calls which take a context,
+so that those calls are canceled with the operation, and are logged and traced with the event.
+This reports uses of context.Background and context.TODO in watchers and reconcilers,
+and watchers and reconcilers which make calls with a context without using their own.`,
+	Run: runContext,
+}
+
+func runContext(pass *analysis.Pass) (any, error) {
+	for _, h := range findHandlers(pass) {
+		usesCtx, reported := false, false
+		inspectBody(h.body, true, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok && h.ctx != nil && pass.TypesInfo.Uses[ident] == h.ctx {
+				usesCtx = true
+			}
+			return !usesCtx
+		})
+		var unrelated *ast.CallExpr
+		inspectBody(h.body, false, func(n ast.Node) bool {
+			if node, ok := n.(*ast.CallExpr); ok {
+				for _, name := range []string{"Background", "TODO"} {
+					if isFunc(pass, node, "context", name) {
+						pass.Reportf(node.Pos(), "context.%s discards the context of the %s, so calls made with it "+
+							"are not canceled with the operation; use the %s's context instead", name, h, h)
+						reported = true
+						return true
+					}
+				}
+				if unrelated == nil && takesContext(pass, node) {
+					unrelated = node
+				}
+			}
+			return true
+		})
+		if !usesCtx && !reported && unrelated != nil {
+			pass.Reportf(unrelated.Pos(), "the %s makes calls which take a context without using its own; "+
+				"pass the %s's context so calls are canceled with the operation", h, h)
+		}
+	}
+	return nil, nil
+}
+
+// takesContext returns true if the first parameter of the function called by call is a context.Context
+func takesContext(pass *analysis.Pass, call *ast.CallExpr) bool {
+	sig, ok := pass.TypesInfo.TypeOf(call.Fun).(*types.Signature)
+	return ok && sig.Params().Len() > 0 && isContext(sig.Params().At(0).Type())
+}
@@ -0,0 +1,226 @@
+package vet
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// StatusUpdateAnalyzer reports updates of objects with a modified status which don't update the status subresource.
+// The API server ignores changes to the status of kinds with a status subresource in updates of the object itself,
+// so the status change is silently lost.
+var StatusUpdateAnalyzer = &analysis.Analyzer{
+	Name: "appsdkstatusupdate",
+	Doc: `report updates which discard changes to the status of an object
+
+Kinds with a status subresource ignore the status in updates of the main object, so status changes
+must be written by updating the status subresource, with resource.UpdateOptions{Subresource: "status"}
+or UpdateSubresource. This reports Update calls for an object whose status was modified earlier in the function
+without the status subresource.`,
+	Run: runStatusUpdate,
+}
+
+func runStatusUpdate(pass *analysis.Pass) (any, error) {
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch fn := n.(type) {
+			case *ast.FuncDecl:
+				if fn.Body != nil {
+					checkStatusUpdates(pass, fn.Body)
+				}
+			case *ast.FuncLit:
+				checkStatusUpdates(pass, fn.Body)
+			}
+			return true
+		})
+	}
+	return nil, nil
+}
+
+// checkStatusUpdates checks the updates in body, which doesn't include function literals, as they are checked separately
+func checkStatusUpdates(pass *analysis.Pass, body *ast.BlockStmt) {
+	// modified holds the position of the first status change of each variable
+	modified := make(map[types.Object]token.Pos)
+	modify := func(obj types.Object, pos token.Pos) {
+		if _, ok := modified[obj]; !ok && obj != nil {
+			modified[obj] = pos
+		}
+	}
+	inspectBody(body, false, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			if node.Tok == token.DEFINE {
+				return true
+			}
+			for _, lhs := range node.Lhs {
+				if ident := statusRoot(lhs); ident != nil {
+					modify(pass.TypesInfo.Uses[ident], node.Pos())
+				}
+			}
+		case *ast.IncDecStmt:
+			if ident := statusRoot(node.X); ident != nil {
+				modify(pass.TypesInfo.Uses[ident], node.Pos())
+			}
+		case *ast.CallExpr:
+			if ident := statusSetter(pass, node); ident != nil {
+				modify(pass.TypesInfo.Uses[ident], node.Pos())
+			}
+		}
+		return true
+	})
+	if len(modified) == 0 {
+		return
+	}
+	// Find the updates of modified variables, skipping variables which also have their status subresource updated,
+	// such as by updating the object and then its status
+	type update struct {
+		call   *ast.CallExpr
+		ident  *ast.Ident
+		advice string
+	}
+	updates := make([]update, 0)
+	subresourceUpdated := make(map[types.Object]bool)
+	inspectBody(body, false, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		kind, advice := classifyUpdate(pass, call)
+		if kind == notAnUpdate {
+			return true
+		}
+		for _, arg := range call.Args {
+			ident, ok := ast.Unparen(arg).(*ast.Ident)
+			if !ok {
+				continue
+			}
+			obj := pass.TypesInfo.Uses[ident]
+			if _, ok := modified[obj]; !ok {
+				continue
+			}
+			if kind == subresourceUpdate {
+				subresourceUpdated[obj] = true
+			} else {
+				updates = append(updates, update{call, ident, advice})
+			}
+		}
+		return true
+	})
+	for _, u := range updates {
+		if obj := pass.TypesInfo.Uses[u.ident]; !subresourceUpdated[obj] && modified[obj] < u.call.Pos() {
+			pass.Reportf(u.call.Pos(), "the status of %s is modified, but this update does not update the status "+
+				"subresource, so the status change is discarded; %s", u.ident.Name, u.advice)
+		}
+	}
+}
+
+// statusRoot returns the variable whose status lhs is, or is part of, such as foo in foo.Status.State,
+// or nil if lhs is not part of a status
+func statusRoot(lhs ast.Expr) *ast.Ident {
+	var last *ast.SelectorExpr
+	expr := lhs
+	for {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			if last != nil && last.Sel.Name == "Status" {
+				return e
+			}
+			return nil
+		case *ast.SelectorExpr:
+			last = e
+			expr = e.X
+		case *ast.IndexExpr:
+			last = nil
+			expr = e.X
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.TypeAssertExpr:
+			expr = e.X
+		default:
+			return nil
+		}
+	}
+}
+
+// statusSetter returns the variable whose status is set by call, for calls such as foo.SetStatus(status)
+// and foo.SetSubresource("status", status), or nil if call doesn't set a status
+func statusSetter(pass *analysis.Pass, call *ast.CallExpr) *ast.Ident {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	switch sel.Sel.Name {
+	case "SetStatus":
+	case "SetSubresource":
+		if len(call.Args) == 0 {
+			return nil
+		}
+		name := pass.TypesInfo.Types[call.Args[0]].Value
+		if name == nil || name.Kind() != constant.String || constant.StringVal(name) != "status" {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return rootIdent(sel.X)
+}
+
+type updateKind int
+
+const (
+	notAnUpdate updateKind = iota
+	objectUpdate
+	subresourceUpdate
+)
+
+// classifyUpdate returns whether call is an update of an object, or of a subresource of an object, and for object
+// updates, advice on how to update the status subresource instead. Calls with resource.UpdateOptions which are not
+// a composite literal are assumed to update a subresource, as the analyzer cannot tell.
+func classifyUpdate(pass *analysis.Pass, call *ast.CallExpr) (updateKind, string) {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok {
+		return notAnUpdate, ""
+	}
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil {
+		return notAnUpdate, ""
+	}
+	// resource.Store and resource.TypedStore have separate methods for subresource updates
+	store := isNamed(sig.Recv().Type(), resourcePackage, "Store", "TypedStore")
+	switch {
+	case store && fn.Name() == "UpdateSubresource":
+		return subresourceUpdate, ""
+	case fn.Name() != "Update" && fn.Name() != "UpdateInto":
+		return notAnUpdate, ""
+	}
+	for i := 0; i < sig.Params().Len() && i < len(call.Args); i++ {
+		if !isNamed(sig.Params().At(i).Type(), resourcePackage, "UpdateOptions") {
+			continue
+		}
+		lit, ok := ast.Unparen(call.Args[i]).(*ast.CompositeLit)
+		if !ok {
+			return subresourceUpdate, ""
+		}
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok || fieldName(kv.Key) != "Subresource" {
+				continue
+			}
+			if value := pass.TypesInfo.Types[kv.Value].Value; value == nil || value.Kind() != constant.String ||
+				constant.StringVal(value) != "" {
+				return subresourceUpdate, ""
+			}
+		}
+		return objectUpdate, `update the status with resource.UpdateOptions{Subresource: "status"}`
+	}
+	if store {
+		return objectUpdate, "update the status with UpdateSubresource"
+	}
+	return notAnUpdate, ""
+}
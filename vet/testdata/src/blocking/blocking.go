@@ -0,0 +1,63 @@
+package blocking
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/operator"
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+type reconciler struct{}
+
+func (*reconciler) Reconcile(ctx context.Context, req operator.ReconcileRequest) (operator.ReconcileResult, error) {
+	time.Sleep(time.Second)   // want `time.Sleep blocks the reconciler; return a ReconcileResult with RequeueAfter to be called again later`
+	<-time.After(time.Second) // want `waiting on a timer outside a select blocks the reconciler, and ignores its context`
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		time.Sleep(time.Second) // want `time.Sleep blocks the reconciler`
+	}
+	go func() {
+		// Sleeps in other goroutines don't block the reconciler
+		time.Sleep(time.Second)
+	}()
+	return operator.ReconcileResult{}, nil
+}
+
+type watcher struct{}
+
+func (*watcher) Add(ctx context.Context, obj resource.Object) error {
+	time.Sleep(time.Second) // want `time.Sleep blocks the watcher; return an error to have the event retried`
+	return nil
+}
+
+func (*watcher) Update(ctx context.Context, src, tgt resource.Object) error {
+	return nil
+}
+
+// Delete is not a watcher method, as it has the wrong signature
+func (*watcher) Delete(ctx context.Context, name string) error {
+	time.Sleep(time.Second)
+	return nil
+}
+
+func newHandlers() (*operator.SimpleReconciler, *operator.SimpleWatcher) {
+	time.Sleep(time.Second)
+	w := &operator.SimpleWatcher{
+		AddFunc: func(ctx context.Context, obj resource.Object) error {
+			time.Sleep(time.Second) // want `time.Sleep blocks the watcher`
+			return nil
+		},
+	}
+	w.DeleteFunc = func(ctx context.Context, obj resource.Object) error {
+		<-time.Tick(time.Second) // want `waiting on a timer outside a select blocks the watcher`
+		return nil
+	}
+	return &operator.SimpleReconciler{
+		ReconcileFunc: func(ctx context.Context, req operator.ReconcileRequest) (operator.ReconcileResult, error) {
+			time.Sleep(time.Second) // want `time.Sleep blocks the reconciler`
+			return operator.ReconcileResult{}, nil
+		},
+	}, w
+}
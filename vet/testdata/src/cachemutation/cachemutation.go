@@ -0,0 +1,55 @@
+package cachemutation
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-app-sdk/operator"
+	"github.com/grafana/grafana-app-sdk/resource"
+
+	"kinds"
+)
+
+type reconciler struct{}
+
+func (*reconciler) Reconcile(ctx context.Context, req operator.ReconcileRequest) (operator.ReconcileResult, error) {
+	req.Object.SetLabels(map[string]string{"a": "b"}) // want `req.Object is shared with the informer cache and must not be modified by the reconciler; modify a copy from req.Object.Copy\(\) instead`
+	req.Object.GetLabels()["a"] = "b"                 // want `req.Object is shared with the informer cache`
+	foo := req.Object.(*kinds.Foo)
+	foo.Spec.Replicas = 3 // want `foo is shared with the informer cache`
+	foo.Spec.Replicas++   // want `foo is shared with the informer cache`
+	copied := req.Object.Copy().(*kinds.Foo)
+	copied.Spec.Replicas = 3
+	copied.SetLabels(nil)
+	return operator.ReconcileResult{}, nil
+}
+
+type watcher struct{}
+
+func (*watcher) Add(ctx context.Context, obj resource.Object) error {
+	obj = obj.Copy()
+	obj.SetLabels(nil)
+	return nil
+}
+
+func (*watcher) Update(ctx context.Context, src *kinds.Foo, tgt *kinds.Foo) error {
+	tgt.Status.State = "updated" // want `tgt is shared with the informer cache and must not be modified by the watcher`
+	func() {
+		src.SetLabels(nil) // want `src is shared with the informer cache`
+	}()
+	return nil
+}
+
+func typedReconcile(ctx context.Context, req operator.TypedReconcileRequest[*kinds.Foo]) (operator.ReconcileResult, error) {
+	req.Object.Spec.Replicas = 1 // want `req.Object is shared with the informer cache`
+	return operator.ReconcileResult{}, nil
+}
+
+func copiedRequest(ctx context.Context, req operator.ReconcileRequest) (operator.ReconcileResult, error) {
+	req.Object = req.Object.Copy()
+	req.Object.SetLabels(nil)
+	return operator.ReconcileResult{}, nil
+}
+
+func notAHandler(obj *kinds.Foo) {
+	obj.Spec.Replicas = 1
+}
@@ -0,0 +1,47 @@
+package ctx
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-app-sdk/operator"
+	"github.com/grafana/grafana-app-sdk/resource"
+	"kinds"
+)
+
+type reconciler struct {
+	client resource.Client
+	ctx    context.Context
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req operator.ReconcileRequest) (operator.ReconcileResult, error) {
+	_, err := r.client.Update(context.Background(), resource.Identifier{}, req.Object, resource.UpdateOptions{}) // want `context.Background discards the context of the reconciler, so calls made with it are not canceled with the operation; use the reconciler's context instead`
+	if err != nil {
+		return operator.ReconcileResult{}, err
+	}
+	_, err = r.client.Update(ctx, resource.Identifier{}, req.Object, resource.UpdateOptions{})
+	return operator.ReconcileResult{}, err
+}
+
+func (r *reconciler) Add(_ context.Context, obj resource.Object) error {
+	_, err := r.client.Update(r.ctx, resource.Identifier{}, obj, resource.UpdateOptions{}) // want `the watcher makes calls which take a context without using its own`
+	return err
+}
+
+func (r *reconciler) Update(ctx context.Context, src, tgt resource.Object) error {
+	go func() {
+		// Goroutines may outlive the event
+		_, _ = r.client.Update(context.TODO(), resource.Identifier{}, tgt, resource.UpdateOptions{})
+	}()
+	return nil
+}
+
+func (r *reconciler) Delete(ctx context.Context, obj *kinds.Foo) error {
+	ctx, cancel := context.WithCancel(context.TODO()) // want `context.TODO discards the context of the watcher`
+	defer cancel()
+	_, err := r.client.Update(ctx, resource.Identifier{}, obj, resource.UpdateOptions{})
+	return err
+}
+
+func background() context.Context {
+	return context.Background()
+}
@@ -0,0 +1,31 @@
+// Package operator is a stub of the SDK's operator package, with the types used by the analyzers
+package operator
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+)
+
+type ReconcileRequest struct {
+	Object resource.Object
+}
+
+type TypedReconcileRequest[T resource.Object] struct {
+	Object T
+}
+
+type ReconcileResult struct {
+	RequeueAfter *time.Duration
+}
+
+type SimpleReconciler struct {
+	ReconcileFunc func(context.Context, ReconcileRequest) (ReconcileResult, error)
+}
+
+type SimpleWatcher struct {
+	AddFunc    func(ctx context.Context, object resource.Object) error
+	UpdateFunc func(ctx context.Context, src resource.Object, tgt resource.Object) error
+	DeleteFunc func(ctx context.Context, object resource.Object) error
+}
@@ -0,0 +1,44 @@
+// Package resource is a stub of the SDK's resource package, with the types used by the analyzers
+package resource
+
+import "context"
+
+const SubresourceStatus = "status"
+
+type StaticMetadata struct {
+	Namespace string
+	Name      string
+}
+
+type Object interface {
+	GetStaticMetadata() StaticMetadata
+	GetSubresources() map[string]any
+	SetSubresource(key string, val any) error
+	GetLabels() map[string]string
+	SetLabels(map[string]string)
+	Copy() Object
+}
+
+type Identifier struct {
+	Namespace string
+	Name      string
+}
+
+type UpdateOptions struct {
+	ResourceVersion string
+	Subresource     string
+}
+
+type Client interface {
+	Update(ctx context.Context, identifier Identifier, obj Object, options UpdateOptions) (Object, error)
+}
+
+type Store struct{}
+
+func (*Store) Update(ctx context.Context, obj Object) (Object, error) {
+	return obj, nil
+}
+
+func (*Store) UpdateSubresource(ctx context.Context, identifier Identifier, subresource string, obj any) (Object, error) {
+	return nil, nil
+}
@@ -0,0 +1,26 @@
+// Package kinds contains a typed object, like the types generated for kinds
+package kinds
+
+import "github.com/grafana/grafana-app-sdk/resource"
+
+// Foo is a typed object
+type Foo struct {
+	Spec   FooSpec
+	Status FooStatus
+}
+
+type FooSpec struct {
+	Replicas int
+}
+
+type FooStatus struct {
+	State string
+}
+
+func (*Foo) GetStaticMetadata() resource.StaticMetadata { return resource.StaticMetadata{} }
+func (*Foo) GetSubresources() map[string]any            { return nil }
+func (*Foo) SetSubresource(string, any) error           { return nil }
+func (*Foo) GetLabels() map[string]string               { return nil }
+func (*Foo) SetLabels(map[string]string)                {}
+func (f *Foo) Copy() resource.Object                    { c := *f; return &c }
+func (*Foo) SetStatus(FooStatus)                        {}
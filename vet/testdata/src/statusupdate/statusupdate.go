@@ -0,0 +1,57 @@
+package statusupdate
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-app-sdk/resource"
+
+	"kinds"
+)
+
+func updateStatus(ctx context.Context, client resource.Client, foo *kinds.Foo) error {
+	foo.Status.State = "ready"
+	_, err := client.Update(ctx, resource.Identifier{}, foo, resource.UpdateOptions{}) // want `the status of foo is modified, but this update does not update the status subresource, so the status change is discarded; update the status with resource.UpdateOptions\{Subresource: "status"\}`
+	return err
+}
+
+func updateStatusSubresource(ctx context.Context, client resource.Client, foo *kinds.Foo) error {
+	foo.Status.State = "ready"
+	_, err := client.Update(ctx, resource.Identifier{}, foo, resource.UpdateOptions{Subresource: "status"})
+	return err
+}
+
+func updateSpecThenStatus(ctx context.Context, client resource.Client, foo *kinds.Foo) error {
+	foo.Spec.Replicas = 1
+	foo.SetStatus(kinds.FooStatus{State: "ready"})
+	if _, err := client.Update(ctx, resource.Identifier{}, foo, resource.UpdateOptions{}); err != nil {
+		return err
+	}
+	_, err := client.Update(ctx, resource.Identifier{}, foo, resource.UpdateOptions{Subresource: resource.SubresourceStatus})
+	return err
+}
+
+func updateBeforeStatus(ctx context.Context, client resource.Client, foo *kinds.Foo) error {
+	_, err := client.Update(ctx, resource.Identifier{}, foo, resource.UpdateOptions{})
+	foo.Status.State = "ready"
+	return err
+}
+
+func updateWithOptions(ctx context.Context, client resource.Client, foo *kinds.Foo, opts resource.UpdateOptions) error {
+	foo.Status.State = "ready"
+	_, err := client.Update(ctx, resource.Identifier{}, foo, opts)
+	return err
+}
+
+func updateStore(ctx context.Context, store *resource.Store, obj resource.Object) error {
+	if err := obj.SetSubresource("status", map[string]any{"state": "ready"}); err != nil {
+		return err
+	}
+	_, err := store.Update(ctx, obj) // want `the status of obj is modified, but this update does not update the status subresource, so the status change is discarded; update the status with UpdateSubresource`
+	return err
+}
+
+func updateSpec(ctx context.Context, store *resource.Store, foo *kinds.Foo) error {
+	foo.Spec.Replicas = 2
+	_, err := store.Update(ctx, foo)
+	return err
+}
@@ -0,0 +1,247 @@
+// Package vet contains go/analysis analyzers which report common mistakes in app code, such as watchers and
+// reconcilers which block or mutate the objects they are passed. The analyzers can be run with go vet using
+// the grafana-app-sdk-vet command:
+//
+//	go install github.com/grafana/grafana-app-sdk/cmd/grafana-app-sdk-vet
+//	go vet -vettool=$(which grafana-app-sdk-vet) ./...
+package vet
+
+import (
+	"go/ast"
+	"go/types"
+	"slices"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const (
+	operatorPackage = "github.com/grafana/grafana-app-sdk/operator"
+	resourcePackage = "github.com/grafana/grafana-app-sdk/resource"
+)
+
+// Analyzers are all the analyzers in the package
+var Analyzers = []*analysis.Analyzer{
+	BlockingAnalyzer,
+	ContextAnalyzer,
+	CacheMutationAnalyzer,
+	StatusUpdateAnalyzer,
+}
+
+// watcherFuncFields are the fields of operator.SimpleWatcher and similar types which hold watcher functions
+var watcherFuncFields = []string{"AddFunc", "UpdateFunc", "DeleteFunc", "SyncFunc"}
+
+// watcherMethods are the methods of operator.ResourceWatcher, along with the optional Sync method
+var watcherMethods = []string{"Add", "Update", "Delete", "Sync"}
+
+// handler is a function which is called by an InformerController for an event: a reconciler or a watcher method
+type handler struct {
+	// reconciler is true for reconcilers, and false for watchers
+	reconciler bool
+	typ        *ast.FuncType
+	body       *ast.BlockStmt
+	// ctx is the context parameter of the handler
+	ctx *types.Var
+	// request is the ReconcileRequest parameter of a reconciler
+	request *types.Var
+	// objects are the resource.Object parameters of a watcher
+	objects []*types.Var
+}
+
+// String returns the kind of the handler, for use in diagnostics
+func (h handler) String() string {
+	if h.reconciler {
+		return "reconciler"
+	}
+	return "watcher"
+}
+
+// findHandlers returns all reconcilers and watchers declared in the files of pass.
+// Reconcilers are functions with an operator.ReconcileRequest or operator.TypedReconcileRequest parameter.
+// Watchers are Add, Update, Delete, and Sync methods with a context and resource.Object parameters,
+// and function literals with that signature assigned to the AddFunc, UpdateFunc, DeleteFunc, or SyncFunc fields.
+func findHandlers(pass *analysis.Pass) []handler {
+	handlers := make([]handler, 0)
+	addWatcherFunc := func(key ast.Expr, value ast.Expr) {
+		lit, ok := value.(*ast.FuncLit)
+		if !ok || !slices.Contains(watcherFuncFields, fieldName(key)) {
+			return
+		}
+		if h, ok := newWatcherHandler(pass, lit.Type, lit.Body); ok {
+			handlers = append(handlers, h)
+		}
+	}
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.FuncDecl:
+				if node.Body == nil {
+					return true
+				}
+				if h, ok := newReconcilerHandler(pass, node.Type, node.Body); ok {
+					handlers = append(handlers, h)
+				} else if node.Recv != nil && slices.Contains(watcherMethods, node.Name.Name) {
+					if h, ok := newWatcherHandler(pass, node.Type, node.Body); ok {
+						handlers = append(handlers, h)
+					}
+				}
+			case *ast.FuncLit:
+				if h, ok := newReconcilerHandler(pass, node.Type, node.Body); ok {
+					handlers = append(handlers, h)
+				}
+			case *ast.KeyValueExpr:
+				addWatcherFunc(node.Key, node.Value)
+			case *ast.AssignStmt:
+				if len(node.Lhs) == len(node.Rhs) {
+					for i := range node.Lhs {
+						addWatcherFunc(node.Lhs[i], node.Rhs[i])
+					}
+				}
+			}
+			return true
+		})
+	}
+	return handlers
+}
+
+func newReconcilerHandler(pass *analysis.Pass, typ *ast.FuncType, body *ast.BlockStmt) (handler, bool) {
+	h := handler{
+		reconciler: true,
+		typ:        typ,
+		body:       body,
+	}
+	for _, param := range params(pass, typ) {
+		switch {
+		case isContext(param.Type()):
+			h.ctx = param
+		case isReconcileRequest(param.Type()):
+			h.request = param
+		}
+	}
+	return h, h.request != nil
+}
+
+func newWatcherHandler(pass *analysis.Pass, typ *ast.FuncType, body *ast.BlockStmt) (handler, bool) {
+	h := handler{
+		typ:  typ,
+		body: body,
+	}
+	ps := params(pass, typ)
+	if len(ps) < 2 || !isContext(ps[0].Type()) || typ.Results == nil || len(typ.Results.List) != 1 ||
+		!types.Identical(pass.TypesInfo.TypeOf(typ.Results.List[0].Type), types.Universe.Lookup("error").Type()) {
+		return h, false
+	}
+	h.ctx = ps[0]
+	for _, param := range ps[1:] {
+		if !isObject(param.Type()) {
+			return h, false
+		}
+		h.objects = append(h.objects, param)
+	}
+	return h, true
+}
+
+// params returns the parameters of typ
+func params(pass *analysis.Pass, typ *ast.FuncType) []*types.Var {
+	vars := make([]*types.Var, 0)
+	for _, field := range typ.Params.List {
+		if len(field.Names) == 0 {
+			// Unnamed parameters have no object, so create one for the type
+			vars = append(vars, types.NewVar(field.Pos(), pass.Pkg, "", pass.TypesInfo.TypeOf(field.Type)))
+			continue
+		}
+		for _, name := range field.Names {
+			if v, ok := pass.TypesInfo.Defs[name].(*types.Var); ok {
+				vars = append(vars, v)
+			}
+		}
+	}
+	return vars
+}
+
+// inspectBody calls fn for each node in body, like ast.Inspect.
+// If funcLits is false, the bodies of function literals in body are not inspected, as they may not be called by it.
+func inspectBody(body *ast.BlockStmt, funcLits bool, fn func(ast.Node) bool) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok && !funcLits {
+			return false
+		}
+		return fn(n)
+	})
+}
+
+// fieldName returns the name of the field expr refers to, for a key in a composite literal or a selector
+func fieldName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	}
+	return ""
+}
+
+// isFunc returns true if call is a call to the function name in package pkg
+func isFunc(pass *analysis.Pass, call *ast.CallExpr, pkg, name string) bool {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == pkg && fn.Name() == name
+}
+
+// isNamed returns true if t (or what it points to) is a named type in package pkg with one of the provided names
+func isNamed(t types.Type, pkg string, names ...string) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Origin().Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == pkg && slices.Contains(names, obj.Name())
+}
+
+func isContext(t types.Type) bool {
+	return isNamed(t, "context", "Context")
+}
+
+func isReconcileRequest(t types.Type) bool {
+	return isNamed(t, operatorPackage, "ReconcileRequest", "TypedReconcileRequest")
+}
+
+// isObject returns true if t is resource.Object, or a type which implements it
+func isObject(t types.Type) bool {
+	if isNamed(t, resourcePackage, "Object") {
+		return true
+	}
+	// Check for the methods of resource.Object which are unlikely to be on other types,
+	// rather than looking up the interface, which may not be imported by the package
+	for _, method := range []string{"GetStaticMetadata", "GetSubresources", "Copy"} {
+		if obj, _, _ := types.LookupFieldOrMethod(t, true, nil, method); obj == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// rootIdent returns the identifier at the root of a chain of selectors, indexes, dereferences, and type assertions
+// (such as obj in obj.(*v1.Foo).Spec.Items[0]), or nil if expr is not such a chain
+func rootIdent(expr ast.Expr) *ast.Ident {
+	for {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			return e
+		case *ast.SelectorExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.TypeAssertExpr:
+			expr = e.X
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package vet
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestBlockingAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), BlockingAnalyzer, "blocking")
+}
+
+func TestContextAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), ContextAnalyzer, "ctx")
+}
+
+func TestCacheMutationAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), CacheMutationAnalyzer, "cachemutation")
+}
+
+func TestStatusUpdateAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), StatusUpdateAnalyzer, "statusupdate")
+}